@@ -0,0 +1,139 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AssessmentPhase represents the current phase of an assessment
+// +kubebuilder:validation:Enum=Pending;Completed;Failed
+type AssessmentPhase string
+
+const (
+	// AssessmentPhasePending indicates the assessment has not started
+	AssessmentPhasePending AssessmentPhase = "Pending"
+	// AssessmentPhaseCompleted indicates the scan finished and results are available
+	AssessmentPhaseCompleted AssessmentPhase = "Completed"
+	// AssessmentPhaseFailed indicates the scan could not be completed
+	AssessmentPhaseFailed AssessmentPhase = "Failed"
+)
+
+// AssessmentVerdict is the migratability of a single StatefulSet
+// +kubebuilder:validation:Enum=Migratable;NeedsForce;Blocked
+type AssessmentVerdict string
+
+const (
+	// VerdictMigratable indicates the StatefulSet can be migrated as-is
+	VerdictMigratable AssessmentVerdict = "Migratable"
+	// VerdictNeedsForce indicates the StatefulSet can only be migrated with spec.force
+	VerdictNeedsForce AssessmentVerdict = "NeedsForce"
+	// VerdictBlocked indicates the StatefulSet cannot be migrated by the controller
+	VerdictBlocked AssessmentVerdict = "Blocked"
+)
+
+// MigrationAssessmentSpec defines which StatefulSets to assess
+type MigrationAssessmentSpec struct {
+	// SourceCluster references the kubeconfig for the cluster to scan
+	SourceCluster ContextRef `json:"sourceCluster"`
+
+	// Namespace limits the scan to a single namespace; all namespaces are scanned when empty
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// DestCluster optionally references the intended destination cluster so
+	// destination prerequisites (such as headless services) are checked too
+	// +optional
+	DestCluster *ContextRef `json:"destCluster,omitempty"`
+
+	// DestNamespace is the intended destination namespace; defaults to each
+	// StatefulSet's source namespace
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	DestNamespace string `json:"destNamespace,omitempty"`
+}
+
+// StatefulSetAssessment is the assessment result for a single StatefulSet
+type StatefulSetAssessment struct {
+	// Namespace is the StatefulSet's namespace
+	Namespace string `json:"namespace"`
+
+	// Name is the StatefulSet's name
+	Name string `json:"name"`
+
+	// Replicas is the StatefulSet's desired replica count
+	Replicas int32 `json:"replicas"`
+
+	// Verdict is whether the StatefulSet can be migrated
+	Verdict AssessmentVerdict `json:"verdict"`
+
+	// Reasons explain a NeedsForce or Blocked verdict
+	// +optional
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// AssessmentSummary counts StatefulSets by verdict
+type AssessmentSummary struct {
+	// Total is the number of StatefulSets assessed
+	Total int `json:"total"`
+
+	// Migratable is the number of StatefulSets that can be migrated as-is
+	Migratable int `json:"migratable"`
+
+	// NeedsForce is the number of StatefulSets that need spec.force
+	NeedsForce int `json:"needsForce"`
+
+	// Blocked is the number of StatefulSets that cannot be migrated
+	Blocked int `json:"blocked"`
+}
+
+// MigrationAssessmentStatus defines the observed state of MigrationAssessment
+type MigrationAssessmentStatus struct {
+	// Phase is the current phase of the assessment
+	Phase AssessmentPhase `json:"phase,omitempty"`
+
+	// Summary counts the results by verdict
+	// +optional
+	Summary AssessmentSummary `json:"summary,omitempty"`
+
+	// Results contains one entry per StatefulSet scanned
+	// +optional
+	Results []StatefulSetAssessment `json:"results,omitempty"`
+
+	// LastError contains the error message if Phase is Failed
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// CompletionTime is when the scan finished
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=stsma
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Total",type=integer,JSONPath=`.status.summary.total`
+// +kubebuilder:printcolumn:name="Migratable",type=integer,JSONPath=`.status.summary.migratable`
+// +kubebuilder:printcolumn:name="NeedsForce",type=integer,JSONPath=`.status.summary.needsForce`
+// +kubebuilder:printcolumn:name="Blocked",type=integer,JSONPath=`.status.summary.blocked`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// MigrationAssessment is a read-only scan reporting which StatefulSets can be migrated
+type MigrationAssessment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MigrationAssessmentSpec   `json:"spec,omitempty"`
+	Status MigrationAssessmentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MigrationAssessmentList contains a list of MigrationAssessment
+type MigrationAssessmentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MigrationAssessment `json:"items"`
+}
@@ -0,0 +1,243 @@
+package v1alpha1
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+// crdSchema is the subset of an OpenAPI v3 schema the CRD tests check
+type crdSchema struct {
+	Type                 string               `json:"type"`
+	Required             []string             `json:"required"`
+	Properties           map[string]crdSchema `json:"properties"`
+	Items                *crdSchema           `json:"items"`
+	AdditionalProperties *crdSchema           `json:"additionalProperties"`
+	Enum                 []string             `json:"enum"`
+	Pattern              string               `json:"pattern"`
+	MinLength            *int                 `json:"minLength"`
+	MaxLength            *int                 `json:"maxLength"`
+	Minimum              *float64             `json:"minimum"`
+}
+
+// loadCRDSchema returns the v1alpha1 schema of a CRD in config/crd
+func loadCRDSchema(t *testing.T, file string) crdSchema {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "config", "crd", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var crd struct {
+		Spec struct {
+			Versions []struct {
+				Schema struct {
+					OpenAPIV3Schema crdSchema `json:"openAPIV3Schema"`
+				} `json:"schema"`
+			} `json:"versions"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal(data, &crd); err != nil {
+		t.Fatalf("failed to parse %s: %v", file, err)
+	}
+	return crd.Spec.Versions[0].Schema.OpenAPIV3Schema
+}
+
+// validate checks value against the schema's structural, enum, pattern,
+// length and minimum rules and returns one problem per violation
+func validate(path string, s crdSchema, value any) []string {
+	var problems []string
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				problems = append(problems, path+"."+name+": required")
+			}
+		}
+		for name, field := range v {
+			if prop, ok := s.Properties[name]; ok {
+				problems = append(problems, validate(path+"."+name, prop, field)...)
+			} else if s.AdditionalProperties != nil {
+				problems = append(problems, validate(path+"."+name, *s.AdditionalProperties, field)...)
+			} else if len(s.Properties) > 0 {
+				problems = append(problems, path+"."+name+": unknown field")
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for _, item := range v {
+				problems = append(problems, validate(path+"[]", *s.Items, item)...)
+			}
+		}
+	case string:
+		if len(s.Enum) > 0 && !contains(s.Enum, v) {
+			problems = append(problems, path+": not in enum")
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(v) {
+			problems = append(problems, path+": does not match "+s.Pattern)
+		}
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			problems = append(problems, path+": too short")
+		}
+		if s.MaxLength != nil && len(v) > *s.MaxLength {
+			problems = append(problems, path+": too long")
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			problems = append(problems, path+": below minimum")
+		}
+	}
+	return problems
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSamplesMatchCRDSchema(t *testing.T) {
+	schemas := map[string]crdSchema{
+		"StatefulSetMigration": loadCRDSchema(t, "migration.aqua.io_statefulsetmigrations.yaml"),
+		"MigrationAssessment":  loadCRDSchema(t, "migration.aqua.io_migrationassessments.yaml"),
+		"VolumeMigration":      loadCRDSchema(t, "migration.aqua.io_volumemigrations.yaml"),
+		"MigrationPolicy":      loadCRDSchema(t, "migration.aqua.io_migrationpolicies.yaml"),
+	}
+
+	samples, err := filepath.Glob(filepath.Join("..", "..", "config", "samples", "*.yaml"))
+	if err != nil || len(samples) == 0 {
+		t.Fatalf("no samples found: %v", err)
+	}
+	for _, file := range samples {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, doc := range strings.Split(string(data), "\n---") {
+			var obj map[string]any
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+				t.Fatalf("failed to parse %s: %v", file, err)
+			}
+			s, ok := schemas[stringField(obj, "kind")]
+			if !ok {
+				continue
+			}
+			delete(obj, "metadata")
+			if problems := validate(stringField(obj, "kind"), s, obj); len(problems) > 0 {
+				t.Errorf("%s: %v", filepath.Base(file), problems)
+			}
+		}
+	}
+}
+
+func stringField(obj map[string]any, name string) string {
+	s, _ := obj[name].(string)
+	return s
+}
+
+func TestMigrationSpecValidation(t *testing.T) {
+	spec := loadCRDSchema(t, "migration.aqua.io_statefulsetmigrations.yaml").Properties["spec"]
+
+	valid := map[string]any{
+		"migrationId":     "db-migration-001",
+		"sourceCluster":   map[string]any{"kubeConfigSecret": "cluster-a"},
+		"sourceNamespace": "prod",
+		"statefulSetName": "postgres",
+		"destCluster":     map[string]any{"kubeConfigSecret": "cluster-b"},
+		"destNamespace":   "prod",
+	}
+
+	tests := []struct {
+		name    string
+		field   string
+		value   any
+		wantErr bool
+	}{
+		{name: "valid spec"},
+		{name: "migration ID with spaces", field: "migrationId", value: "db migration", wantErr: true},
+		{name: "empty migration ID", field: "migrationId", value: "", wantErr: true},
+		{name: "migration ID too long", field: "migrationId", value: strings.Repeat("a", 64), wantErr: true},
+		{name: "uppercase namespace", field: "destNamespace", value: "Prod", wantErr: true},
+		{name: "dotted StatefulSet name", field: "statefulSetName", value: "pg.primary"},
+		{name: "duration timeout", field: "volumeDetachTimeout", value: "1m30s"},
+		{name: "timeout without unit", field: "podReadyTimeout", value: "600", wantErr: true},
+		{name: "negative QPS", field: "sourceCluster", value: map[string]any{"kubeConfigSecret": "a", "rateLimit": map[string]any{"qps": float64(-1)}}, wantErr: true},
+		{name: "service account token", field: "destCluster", value: map[string]any{
+			"server":            "https://api.cluster-b.example.com:6443",
+			"tokenSecretRef":    map[string]any{"name": "cluster-b-sa", "key": "token"},
+			"caBundleSecretRef": map[string]any{"name": "cluster-b-sa"},
+		}},
+		{name: "plain HTTP server", field: "destCluster", value: map[string]any{"server": "http://10.0.0.1", "tokenSecretRef": map[string]any{"name": "b"}}, wantErr: true},
+		{name: "token secret without name", field: "destCluster", value: map[string]any{"server": "https://10.0.0.1", "tokenSecretRef": map[string]any{"key": "token"}}, wantErr: true},
+		{name: "aws region", field: "awsConfig", value: map[string]any{"region": "eu-west-1"}},
+		{name: "govcloud region", field: "awsConfig", value: map[string]any{"region": "us-gov-west-1"}},
+		{name: "aws region is an availability zone", field: "awsConfig", value: map[string]any{"region": "eu-west-1a"}, wantErr: true},
+		{name: "node role ARN", field: "destAWS", value: map[string]any{"nodeRoleArn": "arn:aws:iam::123456789012:role/eks-node"}},
+		{name: "node role is not a role ARN", field: "destAWS", value: map[string]any{"nodeRoleArn": "arn:aws:iam::123456789012:user/me"}, wantErr: true},
+		{name: "velero replication", field: "velero", value: map[string]any{"namespace": "velero", "excludedResources": []any{"secrets"}, "timeout": "45m"}},
+		{name: "velero timeout without unit", field: "velero", value: map[string]any{"timeout": "45"}, wantErr: true},
+		{name: "freeze settle delay", field: "freezeSettleDelay", value: "90s"},
+		{name: "freeze settle delay without unit", field: "freezeSettleDelay", value: "90", wantErr: true},
+		{name: "post-migration watch", field: "postMigrationWatch", value: "15m"},
+		{name: "post-migration watch without unit", field: "postMigrationWatch", value: "15", wantErr: true},
+		{name: "cleanup keeps source PVs", field: "cleanup", value: map[string]any{"deleteSourcePVs": false}},
+		{name: "unknown cleanup option", field: "cleanup", value: map[string]any{"deleteSourceSnapshots": true}, wantErr: true},
+		{name: "delete orphaned pods", field: "orphanedPodPolicy", value: "Delete"},
+		{name: "quiesce with custom acknowledgement", field: "quiesce", value: map[string]any{"ackAnnotation": "db.example.com/fenced", "timeout": "2m", "timeoutAction": "Proceed"}},
+		{name: "quiesce acknowledgement is not an annotation key", field: "quiesce", value: map[string]any{"ackAnnotation": "fenced?"}, wantErr: true},
+		{name: "unknown orphaned pod policy", field: "orphanedPodPolicy", value: "Adopt", wantErr: true},
+		{name: "preserve data sources", field: "dataSourcePolicy", value: "Preserve"},
+		{name: "unknown data source policy", field: "dataSourcePolicy", value: "Copy", wantErr: true},
+		{name: "adopt destination PVCs", field: "adoptDestPVCs", value: true},
+		{name: "strict claimRef", field: "strictClaimRef", value: true},
+		{name: "override one check", field: "overrides", value: map[string]any{"ignoreQuotaCheck": true}},
+		{name: "unknown override", field: "overrides", value: map[string]any{"ignoreEverything": true}, wantErr: true},
+		{name: "warn about a missing service", field: "serviceCheck", value: "Warn"},
+		{name: "unknown service check policy", field: "serviceCheck", value: "Ignore", wantErr: true},
+		{name: "scheduled start", field: "schedule", value: map[string]any{"startTime": "2026-11-07T02:00:00Z", "revalidateBefore": "2h"}},
+		{name: "schedule without start time", field: "schedule", value: map[string]any{"revalidateBefore": "2h"}, wantErr: true},
+		{name: "manual gates", field: "manualGates", value: []any{"AfterFreeze", "BeforeFinalize"}},
+		{name: "unknown manual gate", field: "manualGates", value: []any{"BeforeCoffee"}, wantErr: true},
+		{name: "strategy fallback", field: "strategyFallback", value: map[string]any{"strategy": "SnapshotRestore", "on": []any{"ZoneMismatch"}}},
+		{name: "unknown fallback trigger", field: "strategyFallback", value: map[string]any{"on": []any{"DetachSlow"}}, wantErr: true},
+		{name: "provision unbound PVCs", field: "unboundPVCs", value: "Provision"},
+		{name: "skip unbound PVCs", field: "unboundPVCs", value: "Skip", wantErr: true},
+		{name: "missing required field", field: "statefulSetName", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := make(map[string]any, len(valid))
+			for k, v := range valid {
+				obj[k] = v
+			}
+			if tt.field != "" {
+				if tt.value == nil {
+					delete(obj, tt.field)
+				} else {
+					obj[tt.field] = tt.value
+				}
+			}
+			problems := validate("spec", spec, obj)
+			if (len(problems) > 0) != tt.wantErr {
+				t.Errorf("validate() = %v, wantErr %v", problems, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAppliedSpecSchemaMatchesSpec(t *testing.T) {
+	root := loadCRDSchema(t, "migration.aqua.io_statefulsetmigrations.yaml")
+	spec := root.Properties["spec"]
+	applied := root.Properties["status"].Properties["appliedSpec"]
+	if !reflect.DeepEqual(spec.Properties, applied.Properties) {
+		t.Error("status.appliedSpec properties differ from spec; copy the spec schema into status.appliedSpec")
+	}
+}
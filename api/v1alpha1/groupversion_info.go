@@ -21,4 +21,7 @@ var (
 
 func init() {
 	SchemeBuilder.Register(&StatefulSetMigration{}, &StatefulSetMigrationList{})
+	SchemeBuilder.Register(&MigrationAssessment{}, &MigrationAssessmentList{})
+	SchemeBuilder.Register(&VolumeMigration{}, &VolumeMigrationList{})
+	SchemeBuilder.Register(&MigrationPolicy{}, &MigrationPolicyList{})
 }
@@ -0,0 +1,99 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MigrationPolicySpec restricts the clusters and namespaces that migrations
+// in the namespaces it governs may read from and write to
+type MigrationPolicySpec struct {
+	// MigrationNamespaces are the namespaces whose StatefulSetMigrations,
+	// VolumeMigrations and MigrationAssessments the policy governs, as glob
+	// patterns where * matches any run of characters; all when empty
+	// +optional
+	MigrationNamespaces []string `json:"migrationNamespaces,omitempty"`
+
+	// Source restricts where migrations may move workloads from
+	// +optional
+	Source TargetRules `json:"source,omitempty"`
+
+	// Destination restricts where migrations may move workloads to
+	// +optional
+	Destination TargetRules `json:"destination,omitempty"`
+
+	// DestinationLimits cap how many migrations may be active at once into
+	// each destination cluster they match, so many migrations started
+	// together do not exhaust its attach limits, IP space or scheduler.
+	// Migrations over a limit wait in the Queued phase and start in the
+	// order they were created.
+	// +optional
+	DestinationLimits []DestinationLimit `json:"destinationLimits,omitempty"`
+}
+
+// DestinationLimit caps the active migrations into a destination cluster
+type DestinationLimit struct {
+	// Clusters matches the API server URL of the destination cluster, as
+	// glob patterns where * matches any run of characters. Each matching
+	// cluster is limited separately.
+	// +kubebuilder:validation:MinItems=1
+	Clusters []string `json:"clusters"`
+
+	// MaxActiveMigrations is how many StatefulSetMigrations, from any
+	// namespace, may be between pre-flight checks and finalizing at once
+	// with that cluster as their destination
+	// +kubebuilder:validation:Minimum=1
+	MaxActiveMigrations int32 `json:"maxActiveMigrations"`
+}
+
+// TargetRules restricts one side of a migration
+type TargetRules struct {
+	// Clusters matches the API server URL of the cluster, such as
+	// "https://*.gr7.us-east-1.eks.amazonaws.com". For a cluster referenced
+	// through a kubeconfig Secret, the URL of the kubeconfig's current context
+	// is matched.
+	// +optional
+	Clusters PatternList `json:"clusters,omitempty"`
+
+	// Namespaces matches the namespace in that cluster
+	// +optional
+	Namespaces PatternList `json:"namespaces,omitempty"`
+}
+
+// PatternList allows and denies values by glob pattern, where * matches any
+// run of characters. A value passes when it matches no Deny pattern and,
+// when Allow is set, at least one Allow pattern.
+type PatternList struct {
+	// Allow lists the patterns a value must match one of; any value when empty
+	// +optional
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny lists the patterns no value may match
+	// +optional
+	Deny []string `json:"deny,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=migpol
+// +kubebuilder:printcolumn:name="Namespaces",type=string,JSONPath=`.spec.migrationNamespaces`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// MigrationPolicy restricts which clusters and namespaces migrations may
+// target. Every policy governing a migration's namespace must allow its
+// source and destination, as must the controller's own flags. The controller
+// fails a migration that breaks a policy before it connects to either
+// cluster, and its validating webhook, when enabled, rejects it on creation.
+type MigrationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MigrationPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// MigrationPolicyList contains a list of MigrationPolicy
+type MigrationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MigrationPolicy `json:"items"`
+}
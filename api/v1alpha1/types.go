@@ -4,17 +4,25 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // MigrationPhase represents the current phase of the migration
+// +kubebuilder:validation:Enum=Pending;Queued;PreFlightChecks;ReplicatingResources;FreezingSource;MigratingPods;Finalizing;Completed;Degraded;Failed;Aborted
 type MigrationPhase string
 
 const (
 	// PhasePending indicates the migration has been created but not started
 	PhasePending MigrationPhase = "Pending"
+	// PhaseQueued indicates the migration is waiting for a MigrationPolicy's
+	// limit on active migrations into its destination cluster
+	PhaseQueued MigrationPhase = "Queued"
 	// PhasePreFlightChecks indicates pre-flight validation is in progress
 	PhasePreFlightChecks MigrationPhase = "PreFlightChecks"
+	// PhaseReplicatingResources indicates Velero is copying the namespace's other resources
+	PhaseReplicatingResources MigrationPhase = "ReplicatingResources"
 	// PhaseFreezingSource indicates the source cluster is being prepared
 	PhaseFreezingSource MigrationPhase = "FreezingSource"
 	// PhaseMigratingPods indicates pods are being migrated one by one
@@ -23,57 +31,1294 @@ const (
 	PhaseFinalizing MigrationPhase = "Finalizing"
 	// PhaseCompleted indicates the migration completed successfully
 	PhaseCompleted MigrationPhase = "Completed"
+	// PhaseDegraded indicates the migrated workload regressed during the post-migration watch
+	PhaseDegraded MigrationPhase = "Degraded"
 	// PhaseFailed indicates the migration has failed
 	PhaseFailed MigrationPhase = "Failed"
+	// PhaseAborted indicates an operator stopped the migration before it finished moving pods
+	PhaseAborted MigrationPhase = "Aborted"
 )
 
-// ContextRef references a kubeconfig stored in a Secret
+// ContextRef references a cluster, either through a kubeconfig stored in a
+// Secret or through its API server URL and a ServiceAccount token
+// +kubebuilder:validation:XValidation:rule="has(self.kubeConfigSecret) != has(self.server)",message="exactly one of kubeConfigSecret and server must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.server) || has(self.tokenSecretRef)",message="server requires tokenSecretRef"
+// +kubebuilder:validation:XValidation:rule="has(self.server) || (!has(self.tokenSecretRef) && !has(self.caBundleSecretRef))",message="tokenSecretRef and caBundleSecretRef require server"
+// +kubebuilder:validation:XValidation:rule="has(self.kubeConfigSecret) || !has(self.context)",message="context requires kubeConfigSecret"
 type ContextRef struct {
 	// KubeConfigSecret is the name of the Secret containing the kubeconfig
 	// The secret must have a key named "kubeconfig"
-	KubeConfigSecret string `json:"kubeConfigSecret"`
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	// +optional
+	KubeConfigSecret string `json:"kubeConfigSecret,omitempty"`
+
+	// KubeConfigKey is the key in the secret containing the kubeconfig (default: "kubeconfig")
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +kubebuilder:default=kubeconfig
+	// +optional
+	KubeConfigKey string `json:"kubeConfigKey,omitempty"`
+
+	// Context selects the context of the kubeconfig to use (default: its
+	// current-context), for Secrets that bundle several clusters. A context
+	// the kubeconfig does not define fails the connection.
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	Context string `json:"context,omitempty"`
+
+	// Server is the URL of the cluster's API server, for a cluster reached
+	// with a ServiceAccount token instead of a kubeconfig
+	// +kubebuilder:validation:Pattern=`^https://`
+	// +optional
+	Server string `json:"server,omitempty"`
+
+	// CABundleSecretRef selects the PEM CA bundle that verifies the API
+	// server's certificate (default key: "ca.crt"). Without it the
+	// controller's system roots are used.
+	// +optional
+	CABundleSecretRef *SecretKeyRef `json:"caBundleSecretRef,omitempty"`
+
+	// TokenSecretRef selects the bearer token the controller authenticates
+	// to Server with (default key: "token"), such as a ServiceAccount token
+	// Secret's
+	// +optional
+	TokenSecretRef *SecretKeyRef `json:"tokenSecretRef,omitempty"`
+
+	// Impersonate configures user impersonation for all requests made to this cluster,
+	// allowing one kubeconfig to be used with a reduced, audited identity
+	// +optional
+	Impersonate *ImpersonationConfig `json:"impersonate,omitempty"`
+
+	// RateLimit overrides the controller's client-side rate limits for this cluster
+	// +optional
+	RateLimit *RateLimitConfig `json:"rateLimit,omitempty"`
+
+	// UserAgent overrides the user agent the controller sends to this
+	// cluster, so its API Priority and Fairness configuration can match the
+	// controller's requests
+	// +kubebuilder:validation:MaxLength=256
+	// +optional
+	UserAgent string `json:"userAgent,omitempty"`
+
+	// Reader is a second identity on the same cluster that the controller
+	// sends its reads with, leaving this ContextRef's own identity only the
+	// changes, so each can be granted no more than it needs
+	// +optional
+	Reader *ReaderIdentity `json:"reader,omitempty"`
+}
+
+// ReaderIdentity is the identity a ContextRef's reads are sent with. It
+// reaches the ContextRef's API server, with its CA bundle and rate limits;
+// a credential it does not set is the ContextRef's own.
+type ReaderIdentity struct {
+	// KubeConfigSecret is the name of the Secret with the reader's
+	// kubeconfig, for a ContextRef reached with a kubeconfig
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	// +optional
+	KubeConfigSecret string `json:"kubeConfigSecret,omitempty"`
+
+	// KubeConfigKey is the key in the secret containing the kubeconfig (default: "kubeconfig")
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +optional
+	KubeConfigKey string `json:"kubeConfigKey,omitempty"`
+
+	// Context selects the context of the reader's kubeconfig. Without its
+	// own KubeConfigSecret the reader uses another context of the
+	// ContextRef's kubeconfig, or by default the ContextRef's context; with
+	// one, it defaults to that kubeconfig's current-context.
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	Context string `json:"context,omitempty"`
+
+	// TokenSecretRef selects the reader's bearer token (default key:
+	// "token"), for a ContextRef reached with a server and token
+	// +optional
+	TokenSecretRef *SecretKeyRef `json:"tokenSecretRef,omitempty"`
+
+	// Impersonate is the identity the reader impersonates. The ContextRef's
+	// own impersonation does not carry over to the reader.
+	// +optional
+	Impersonate *ImpersonationConfig `json:"impersonate,omitempty"`
+}
+
+// SecretKeyRef selects a key of a Secret in the migration's namespace
+type SecretKeyRef struct {
+	// Name is the name of the Secret
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	Name string `json:"name"`
+
+	// Key is the key in the Secret
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// RateLimitConfig configures client-side rate limiting against a remote API server
+type RateLimitConfig struct {
+	// QPS is the sustained queries per second allowed against the API server
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	QPS int32 `json:"qps,omitempty"`
+
+	// Burst is the maximum burst of queries allowed against the API server
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// ImpersonationConfig describes the identity to impersonate on a remote cluster
+type ImpersonationConfig struct {
+	// User is the username to impersonate
+	// +kubebuilder:validation:MinLength=1
+	User string `json:"user"`
+
+	// Groups are the groups to impersonate
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+}
+
+// StatefulSetMigrationSpec defines the desired state of StatefulSetMigration
+// +kubebuilder:validation:XValidation:rule="(has(self.sourceCluster.server) ? self.sourceCluster.server : self.sourceCluster.kubeConfigSecret + (has(self.sourceCluster.context) ? '/' + self.sourceCluster.context : '/')) != (has(self.destCluster.server) ? self.destCluster.server : self.destCluster.kubeConfigSecret + (has(self.destCluster.context) ? '/' + self.destCluster.context : '/')) || self.sourceNamespace != self.destNamespace",message="source and destination must differ in cluster or namespace"
+// +kubebuilder:validation:XValidation:rule="!has(self.mode) || self.mode != 'VolumesOnly' || !has(self.postMigrationWatch)",message="postMigrationWatch requires mode Full"
+// +kubebuilder:validation:XValidation:rule="!has(self.mode) || self.mode != 'VolumesOnly' || !has(self.migrateMonitoring) || !self.migrateMonitoring",message="migrateMonitoring requires mode Full"
+// +kubebuilder:validation:XValidation:rule="!has(self.strategyFallback) || !has(self.destAWS) || !has(self.destAWS.transferVolumes) || !self.destAWS.transferVolumes",message="strategyFallback cannot be combined with destAWS.transferVolumes"
+// +kubebuilder:validation:XValidation:rule="!has(self.volumeMigrations) || !self.volumeMigrations || (!has(self.strategyFallback) && !(has(self.adoptDestPVCs) && self.adoptDestPVCs) && !(has(self.strictClaimRef) && self.strictClaimRef) && !(has(self.destAWS) && has(self.destAWS.transferVolumes) && self.destAWS.transferVolumes))",message="volumeMigrations cannot be combined with strategyFallback, adoptDestPVCs, strictClaimRef or destAWS.transferVolumes"
+// +kubebuilder:validation:XValidation:rule="!has(self.ordinalRange) || (!has(self.podOrder) && !has(self.velero) && (!has(self.mode) || self.mode != 'VolumesOnly') && !(has(self.migrateJobs) && self.migrateJobs) && !(has(self.migrateAutoscalers) && self.migrateAutoscalers))",message="ordinalRange cannot be combined with podOrder, velero, mode VolumesOnly, migrateJobs or migrateAutoscalers"
+type StatefulSetMigrationSpec struct {
+	// MigrationID is a unique identifier for this migration. It must be a
+	// valid label value so it can be used to select the migration's objects.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`
+	MigrationID string `json:"migrationId"`
+
+	// SourceCluster contains the reference to the source cluster kubeconfig
+	SourceCluster ContextRef `json:"sourceCluster"`
+
+	// SourceNamespace is the namespace of the StatefulSet in the source cluster
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	SourceNamespace string `json:"sourceNamespace"`
+
+	// StatefulSetName is the name of the StatefulSet to migrate
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	StatefulSetName string `json:"statefulSetName"`
+
+	// DestCluster contains the reference to the destination cluster kubeconfig
+	DestCluster ContextRef `json:"destCluster"`
+
+	// DestNamespace is the namespace to migrate to in the destination cluster
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	DestNamespace string `json:"destNamespace"`
+
+	// CreateDestNamespace creates the destination namespace during
+	// pre-flight when it does not exist, instead of failing pre-flight. A
+	// namespace that already exists is left as it is.
+	// +optional
+	CreateDestNamespace *DestNamespaceTemplate `json:"createDestNamespace,omitempty"`
+
+	// Force ignores non-critical pre-flight warnings. Deprecated: set the
+	// overrides for the checks to bypass instead; force turns all of them on.
+	// +kubebuilder:default=false
+	// +optional
+	Force bool `json:"force,omitempty"`
+
+	// Overrides bypass individual pre-flight checks, leaving the others in force
+	// +optional
+	Overrides *OverridesConfig `json:"overrides,omitempty"`
+
+	// ServiceCheck decides what pre-flight does when the StatefulSet's
+	// serviceName has no Service in the destination namespace, for workloads
+	// that name a service on purpose or rely on a service mesh. Error fails
+	// pre-flight, Warn sets the HeadlessServiceMissing condition, Skip does
+	// not look. (default: Error, or Warn when force or
+	// overrides.ignoreMissingService is set)
+	// +optional
+	ServiceCheck ServiceCheckPolicy `json:"serviceCheck,omitempty"`
+
+	// StorageClassMapping maps source StorageClass names to destination StorageClass names
+	// If not specified, the same StorageClass name will be used
+	// +optional
+	StorageClassMapping map[string]string `json:"storageClassMapping,omitempty"`
+
+	// DestPVNameTemplate is a Go template for destination PV names. It can use
+	// {{.Namespace}}, {{.PVCName}}, {{.SourcePVName}} and {{.VolumeID}}; names over
+	// 253 characters are shortened with a hash suffix.
+	// Default: "migrated-{{.Namespace}}-{{.PVCName}}"
+	// +kubebuilder:validation:MaxLength=1024
+	// +optional
+	DestPVNameTemplate string `json:"destPVNameTemplate,omitempty"`
+
+	// MetadataPassthrough copies source PV and PVC annotations and labels,
+	// selected by key prefix, to the destination PV and PVC. By default only
+	// the controller's own migration.aqua.io/ metadata is set.
+	// +optional
+	MetadataPassthrough *MetadataPassthroughConfig `json:"metadataPassthrough,omitempty"`
+
+	// DataSourcePolicy decides what happens to the dataSource and
+	// dataSourceRef of source PVCs created from a snapshot or clone. Strip
+	// drops them and records the origin in the destination PVC's
+	// migration.aqua.io/source-data-source annotation; the data is already on
+	// the volume. Preserve copies them, and pre-flight checks the referenced
+	// object exists in the destination namespace. (default: Strip)
+	// +kubebuilder:default=Strip
+	// +optional
+	DataSourcePolicy DataSourcePolicy `json:"dataSourcePolicy,omitempty"`
+
+	// AdoptDestPVCs binds the migrated volumes to destination PVCs that
+	// already exist, such as ones created by GitOps, instead of creating them.
+	// Each PV is pre-bound to its PVC's UID; pre-flight fails when a PVC could
+	// not bind to it. Missing PVCs are still created.
+	// +kubebuilder:default=false
+	// +optional
+	AdoptDestPVCs bool `json:"adoptDestPVCs,omitempty"`
+
+	// StrictClaimRef creates each destination PVC before its PV, so the PV's
+	// claimRef carries the PVC's UID from the start and no other PVC of the
+	// same name, created by someone else in the meantime, can claim the volume
+	// +kubebuilder:default=false
+	// +optional
+	StrictClaimRef bool `json:"strictClaimRef,omitempty"`
+
+	// VolumeDetachTimeout is the maximum time to wait for a volume to detach (default: 5m)
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('10s')",message="volumeDetachTimeout must be at least 10s"
+	// +optional
+	VolumeDetachTimeout *metav1.Duration `json:"volumeDetachTimeout,omitempty"`
+
+	// VolumePolicy overrides settings for the volumes of individual claim
+	// templates, such as a longer detach timeout for large io2 volumes.
+	// Pre-flight fails on an entry naming a claim template the source
+	// StatefulSet does not have.
+	// +listType=map
+	// +listMapKey=claimTemplate
+	// +optional
+	VolumePolicy []VolumePolicy `json:"volumePolicy,omitempty"`
+
+	// VolumeMigrations moves each replica's volume through an owned
+	// VolumeMigration, named <migration>-<pvc>, instead of inline, so each
+	// move has its own status and events and a failed one can be retried on
+	// its own. It cannot be combined with strategyFallback, adoptDestPVCs,
+	// strictClaimRef or destAWS.transferVolumes.
+	// +kubebuilder:default=false
+	// +optional
+	VolumeMigrations bool `json:"volumeMigrations,omitempty"`
+
+	// PodReadyTimeout is the maximum time to wait for a pod to become ready (default: 10m)
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('10s')",message="podReadyTimeout must be at least 10s"
+	// +optional
+	PodReadyTimeout *metav1.Duration `json:"podReadyTimeout,omitempty"`
+
+	// ForceDetach force-detaches a volume whose instance is stopped, shutting
+	// down or terminated instead of failing the migration. Unflushed writes on
+	// that instance may be lost. A stopping or impaired instance, which may
+	// still write, is never force-detached.
+	// +kubebuilder:default=false
+	// +optional
+	ForceDetach bool `json:"forceDetach,omitempty"`
+
+	// StrategyFallback moves a volume that cannot be reattached by snapshot
+	// and restore instead of failing the migration: a volume in a zone the
+	// destination has no nodes in, found at pre-flight, or one whose detach
+	// is blocked by a node or instance that is gone. The source volume is
+	// kept. Unset fails the migration in both cases.
+	// +optional
+	StrategyFallback *StrategyFallback `json:"strategyFallback,omitempty"`
+
+	// UnboundPVCs is what to do with a replica whose PVC has no PV, such as
+	// an ordinal that never started. Fail, the default, fails pre-flight
+	// naming the PVCs; Provision lets the destination StatefulSet provision
+	// a new, empty volume for the replica from its claim template.
+	// +kubebuilder:default=Fail
+	// +optional
+	UnboundPVCs UnboundPVCPolicy `json:"unboundPVCs,omitempty"`
+
+	// AWSConfig overrides the controller's AWS settings for this migration,
+	// so one controller can migrate volumes in several regions
+	// +optional
+	AWSConfig *AWSConfig `json:"awsConfig,omitempty"`
+
+	// DestAWS describes the AWS identity that attaches volumes in the destination
+	// cluster. When set, pre-flight verifies it can use the KMS key of every
+	// encrypted source volume.
+	// +optional
+	DestAWS *DestAWSConfig `json:"destAWS,omitempty"`
+
+	// FreezeSettleDelay waits this long after the source StatefulSet is
+	// orphaned before the first pod is deleted, giving monitors, service
+	// discovery and paused operators time to settle before downtime begins
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +optional
+	FreezeSettleDelay *metav1.Duration `json:"freezeSettleDelay,omitempty"`
+
+	// PostMigrationWatch keeps checking, for this long after the migration
+	// completes, that the destination pods stay Ready and their PVs stay
+	// Bound. A regression moves the migration to Degraded. Unset disables the watch.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +optional
+	PostMigrationWatch *metav1.Duration `json:"postMigrationWatch,omitempty"`
+
+	// MigrateJobs suspends the CronJobs and Jobs in the source namespace that
+	// mount the StatefulSet's PVCs (backup jobs and the like) before the source
+	// is frozen, and recreates them in the destination once every pod has
+	// moved, so they resume against the migrated claims
+	// +kubebuilder:default=false
+	// +optional
+	MigrateJobs bool `json:"migrateJobs,omitempty"`
+
+	// MigrateAutoscalers recreates the HorizontalPodAutoscalers in the source
+	// namespace that scale the StatefulSet in the destination once every pod
+	// has moved, targeting the destination StatefulSet
+	// +kubebuilder:default=false
+	// +optional
+	MigrateAutoscalers bool `json:"migrateAutoscalers,omitempty"`
+
+	// MigrateMonitoring recreates the Prometheus Operator ServiceMonitors and
+	// PodMonitors in the source namespace that select the StatefulSet's pods
+	// or Services, and the PrometheusRules that refer to it, in the
+	// destination once every pod has moved
+	// +kubebuilder:default=false
+	// +optional
+	MigrateMonitoring bool `json:"migrateMonitoring,omitempty"`
+
+	// Velero replicates the source namespace's other resources (Services,
+	// ConfigMaps, Secrets and so on) to the destination with a Velero backup
+	// and restore before the StatefulSet is moved. Both clusters must run
+	// Velero with a shared backup storage location.
+	// +optional
+	Velero *VeleroConfig `json:"velero,omitempty"`
+
+	// Cleanup selects which source objects are deleted once every pod has
+	// moved (default: PVCs, PVs and leftover pods are all deleted)
+	// +optional
+	Cleanup *CleanupConfig `json:"cleanup,omitempty"`
+
+	// OrphanedPodPolicy decides what happens to source pods still running
+	// without their StatefulSet when a migration that did not complete is
+	// deleted (default: Retain)
+	// +kubebuilder:default=Retain
+	// +optional
+	OrphanedPodPolicy OrphanedPodPolicy `json:"orphanedPodPolicy,omitempty"`
+
+	// Quiesce asks each source pod to flush and fence its application before
+	// the pod is deleted. The controller annotates the pod with
+	// migration.aqua.io/quiesce=true and waits for a sidecar to acknowledge,
+	// so no exec permission on the source cluster is needed.
+	// +optional
+	Quiesce *QuiesceConfig `json:"quiesce,omitempty"`
+
+	// BackupRetag retags each source volume once it is attached in the
+	// destination, so Data Lifecycle Manager policies and AWS Backup plans
+	// that select volumes by tag hand it over to the destination team's.
+	// Pre-flight reports the policies already snapshotting the volumes either way.
+	// +optional
+	BackupRetag *BackupRetagConfig `json:"backupRetag,omitempty"`
+
+	// PodOrder migrates pods by a priority read from each pod instead of by
+	// ordinal, so the least critical replicas move first and a leader last.
+	// Unset migrates pods 0 to N-1.
+	// +optional
+	PodOrder *PodOrderConfig `json:"podOrder,omitempty"`
+
+	// OrdinalRange migrates only these ordinals, such as 3-5, so a large
+	// StatefulSet can move in tranches over several migrations and
+	// maintenance windows. The source StatefulSet is recreated running the
+	// ordinals that remain, and the destination StatefulSet grows by the
+	// range, so no ordinal ever runs in both. The range must start or end
+	// where the source's ordinals do, and border the ordinals an earlier
+	// tranche moved. Unset migrates every pod.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(-[0-9]+)?$`
+	// +optional
+	OrdinalRange string `json:"ordinalRange,omitempty"`
+
+	// Mode selects what the migration creates in the destination. Full
+	// recreates the StatefulSet and moves its pods one by one. VolumesOnly
+	// freezes the source and moves the volumes, creating each destination
+	// PV and PVC, but leaves creating the StatefulSet to the user or GitOps;
+	// the migration completes once every destination PVC is Bound.
+	// (default: Full)
+	// +kubebuilder:default=Full
+	// +optional
+	Mode MigrationMode `json:"mode,omitempty"`
+
+	// Schedule holds the migration in Pending until a maintenance window
+	// opens. Its pre-flight checks run as soon as it is created and again
+	// shortly before the window, with the results in status.preFlight, so
+	// blockers surface ahead of the window rather than at its start.
+	// +optional
+	Schedule *ScheduleConfig `json:"schedule,omitempty"`
+
+	// ManualGates are points where the migration pauses until an operator
+	// acknowledges them by annotating it with migration.aqua.io/acknowledge,
+	// for runbooks that mix automated and manual steps
+	// +listType=set
+	// +optional
+	ManualGates []ManualGate `json:"manualGates,omitempty"`
+
+	// FailurePolicy decides what happens when a pod fails to migrate. Fail
+	// fails the migration at that pod. ContinueRemaining records the pod in
+	// status.failedPods and moves the remaining ones, for sharded systems
+	// where one bad shard should not hold up the rest; the migration fails
+	// once they have moved. ContinueRemaining requires the StatefulSet's
+	// podManagementPolicy to be Parallel. (default: Fail)
+	// +kubebuilder:default=Fail
+	// +optional
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+
+	// MaxParallelPods is how many pods are deleted and moved at once. The
+	// source StatefulSet must have podManagementPolicy Parallel for more
+	// than one, since OrderedReady only allows its pods to stop and start
+	// one at a time. (default: 1)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	MaxParallelPods int32 `json:"maxParallelPods,omitempty"`
+
+	// PauseGitOps annotates the source namespace and StatefulSet with GitOps
+	// suspend annotations before the source is frozen, so Flux or Argo CD
+	// do not recreate the orphan-deleted StatefulSet mid-migration. They are
+	// removed once the migration completes, or when it is deleted.
+	// +optional
+	PauseGitOps *PauseGitOpsConfig `json:"pauseGitOps,omitempty"`
+
+	// VolumeInspection runs a command in a temporary destination pod that
+	// mounts each moved volume read-only, before the destination StatefulSet
+	// is scaled to include its pod. A command that fails stops the migration
+	// before the application touches the volume.
+	// +optional
+	VolumeInspection *VolumeInspectionConfig `json:"volumeInspection,omitempty"`
+
+	// DNSCutover moves the per-pod DNS records external-dns publishes for the
+	// headless service to the destination one pod at a time: a pod's record
+	// points at its destination pod once that pod is Ready, while the pods
+	// still in the source keep theirs.
+	// +optional
+	DNSCutover *DNSCutoverConfig `json:"dnsCutover,omitempty"`
+
+	// ConnectivityProbe checks, once each moved pod is Ready, that a
+	// temporary pod in the source namespace can reach it at its destination
+	// address, for clients that stay in the source cluster for a while. A
+	// probe that fails stops the migration before its next pod, so a
+	// NetworkPolicy, security group or mesh route that blocks the traffic is
+	// found while most pods are still in the source.
+	// +optional
+	ConnectivityProbe *ConnectivityProbeConfig `json:"connectivityProbe,omitempty"`
+
+	// BandwidthProbe measures, at pre-flight, the throughput from the source
+	// cluster to the destination with a temporary server pod in the
+	// destination and a client pod in the source, when any volume is copied
+	// rather than reattached. The throughput refines the estimate of how
+	// long the copies take; an estimate that runs past the end of the
+	// maintenance window is a pre-flight warning.
+	// +optional
+	BandwidthProbe *BandwidthProbeConfig `json:"bandwidthProbe,omitempty"`
+
+	// ImagePrePull pulls the workload's images onto the destination nodes
+	// in the volumes' zones once the source is frozen, with a low-priority
+	// DaemonSet, so a destination pod's start is not spent pulling images
+	// after its volume has already moved. The DaemonSet is deleted once
+	// every pod has moved, or when the migration fails or is aborted.
+	// +optional
+	ImagePrePull *ImagePrePullConfig `json:"imagePrePull,omitempty"`
+
+	// CapacityWait makes the migration wait, instead of failing, when a moved
+	// pod cannot be scheduled in the destination: it watches the destination
+	// nodes in the pod's zone and resumes once the pod is scheduled, such as
+	// after the cluster autoscaler adds a node.
+	// +optional
+	CapacityWait *CapacityWaitConfig `json:"capacityWait,omitempty"`
+
+	// DestinationPlacement restricts the destination pods to a node pool,
+	// node selector or set of zones, merged into the pod template of the
+	// destination StatefulSet. Pre-flight fails when no destination node
+	// matches it, or a listed zone has none.
+	// +optional
+	DestinationPlacement *DestinationPlacement `json:"destinationPlacement,omitempty"`
+}
+
+// DestinationPlacement configures spec.destinationPlacement. Its node
+// selector and zones are required of the destination pods in addition to
+// the template's own node selector and node affinity.
+type DestinationPlacement struct {
+	// NodeSelector is merged into the template's node selector, its values
+	// taking precedence
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Zones the destination pods may run in, required with node affinity
+	// on topology.kubernetes.io/zone. A volume in another zone is restored
+	// in one of them under spec.strategyFallback, or fails pre-flight.
+	// +optional
+	Zones []string `json:"zones,omitempty"`
+
+	// NodePool is the node pool the destination pods run in, selected by
+	// the nodePoolLabel node label
+	// +optional
+	NodePool string `json:"nodePool,omitempty"`
+
+	// NodePoolLabel is the node label naming a node's pool
+	// (default: "karpenter.sh/nodepool"), such as
+	// "eks.amazonaws.com/nodegroup" for managed node groups
+	// +optional
+	NodePoolLabel string `json:"nodePoolLabel,omitempty"`
+
+	// Tolerations are added to the template's, such as for the taints of a
+	// dedicated node pool
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// DestNamespaceTemplate is the destination namespace spec.createDestNamespace
+// creates, with the ResourceQuota and LimitRange to create in it
+type DestNamespaceTemplate struct {
+	// Labels are set on the namespace
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are set on the namespace
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// ResourceQuota, when set, is created in the namespace as
+	// "migration-quota"
+	// +optional
+	ResourceQuota *corev1.ResourceQuotaSpec `json:"resourceQuota,omitempty"`
+
+	// LimitRange, when set, is created in the namespace as
+	// "migration-limits"
+	// +optional
+	LimitRange *corev1.LimitRangeSpec `json:"limitRange,omitempty"`
+}
+
+// CapacityWaitConfig configures spec.capacityWait
+type CapacityWaitConfig struct {
+	// Timeout is how long to wait for the pod to be scheduled before the
+	// migration fails (default: 6h)
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// ImagePrePullConfig configures the DaemonSet of spec.imagePrePull
+type ImagePrePullConfig struct {
+	// PriorityClassName is the priority class of the pre-pull pods, so they
+	// can be preempted by anything else
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// HelperImage provides the static busybox binary each pre-pull container
+	// runs in place of the image's entrypoint, and the pods' sleep
+	// (default: "busybox:1.36")
+	// +optional
+	HelperImage string `json:"helperImage,omitempty"`
+}
+
+// CapacityWaitStatus is a moved pod no destination node could take yet
+type CapacityWaitStatus struct {
+	// Pod is the destination pod waiting to be scheduled
+	Pod string `json:"pod"`
+
+	// Zone is the availability zone of the pod's volume, where a node must
+	// become available
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// Message is why the scheduler could not place the pod
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Since is when the wait began
+	Since metav1.Time `json:"since"`
+}
+
+// DNSCutoverConfig configures the per-pod DNS records of spec.dnsCutover
+type DNSCutoverConfig struct {
+	// Hostname is the domain the pods' records are under, as <pod>.<hostname>;
+	// several are separated by commas. Defaults to the source headless
+	// service's external-dns.alpha.kubernetes.io/hostname annotation.
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// TTL is the records' time to live in seconds (default: external-dns's)
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTL int64 `json:"ttl,omitempty"`
+}
+
+// ConnectivityProbeConfig configures the probe pod of spec.connectivityProbe.
+// The pod gets the address and port to reach in $PROBE_ADDRESS and
+// $PROBE_PORT.
+type ConnectivityProbeConfig struct {
+	// Address is a Go template for the address the source reaches the moved
+	// pod at, such as through a multi-cluster service or load balancer. It
+	// can use {{.PodName}}, {{.PodIP}} and {{.Namespace}}, the destination
+	// namespace. Default: "{{.PodIP}}"
+	// +kubebuilder:validation:MaxLength=1024
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// Port is the TCP port to reach
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// Image runs the probe (default: "busybox:1.36")
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Command replaces the default probe, which retries a TCP connection to
+	// $PROBE_ADDRESS:$PROBE_PORT until it succeeds. It must exit 0 for the
+	// pod to pass.
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// NodeSelector constrains the source nodes the pod runs on, such as to
+	// those the clients run on
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Timeout is the maximum time to wait for the probe to pass (default: 2m)
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1s')",message="timeout must be at least 1s"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// BandwidthProbeConfig configures the pods of spec.bandwidthProbe. The
+// server serves SizeMiB of random data over HTTP, which the client fetches
+// from the server's pod IP.
+type BandwidthProbeConfig struct {
+	// Image runs the server and the client; it needs a shell, dd, httpd,
+	// wget and date (default: "busybox:1.36")
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// SizeMiB is how much data the client fetches (default: 256)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4096
+	// +optional
+	SizeMiB int32 `json:"sizeMiB,omitempty"`
+
+	// Port is the TCP port the server listens on (default: 8080)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// ServerNamespace is the destination namespace the server runs in
+	// (default: spec.destNamespace)
+	// +optional
+	ServerNamespace string `json:"serverNamespace,omitempty"`
+
+	// Timeout is the maximum time for the probe to finish (default: 5m)
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1s')",message="timeout must be at least 1s"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// VolumeInspectionConfig configures the pod that inspects each moved volume
+type VolumeInspectionConfig struct {
+	// Image runs the command (default: "busybox:1.36")
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Command is run with the volume mounted read-only at /data, e.g.
+	// ["sh", "-c", "test -s /data/PG_VERSION"]. It must exit 0 for the
+	// volume to pass.
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+
+	// NodeSelector constrains the nodes the pod runs on
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Timeout is the maximum time to wait for the command to finish (default: 5m)
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1s')",message="timeout must be at least 1s"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// PauseGitOpsConfig selects the annotations that pause GitOps controllers.
+// Annotations already on an object are left as they are.
+type PauseGitOpsConfig struct {
+	// Annotations replaces the default suspend annotations:
+	// fluxcd.io/ignore=true, kustomize.toolkit.fluxcd.io/reconcile=disabled
+	// and argocd.argoproj.io/sync-options=Prune=false,Delete=false
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GitOpsPauseStatus records the annotations spec.pauseGitOps added to the
+// source, so that exactly those are removed again
+type GitOpsPauseStatus struct {
+	// NamespaceAnnotations are the keys added to the source namespace
+	// +optional
+	NamespaceAnnotations []string `json:"namespaceAnnotations,omitempty"`
+
+	// StatefulSetAnnotations are the keys added to the source StatefulSet
+	// +optional
+	StatefulSetAnnotations []string `json:"statefulSetAnnotations,omitempty"`
+}
+
+// MigrationMode is what a migration creates in the destination
+// +kubebuilder:validation:Enum=Full;VolumesOnly
+type MigrationMode string
+
+const (
+	// MigrationModeFull recreates the StatefulSet along with its volumes
+	MigrationModeFull MigrationMode = "Full"
+
+	// MigrationModeVolumesOnly creates the destination PVs and PVCs only
+	MigrationModeVolumesOnly MigrationMode = "VolumesOnly"
+)
+
+// VolumePolicy overrides settings for the volumes of one claim template
+type VolumePolicy struct {
+	// ClaimTemplate is the name of the StatefulSet's volume claim template
+	// +kubebuilder:validation:MinLength=1
+	ClaimTemplate string `json:"claimTemplate"`
+
+	// VolumeDetachTimeout replaces spec.volumeDetachTimeout for these volumes
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('10s')",message="volumeDetachTimeout must be at least 10s"
+	// +optional
+	VolumeDetachTimeout *metav1.Duration `json:"volumeDetachTimeout,omitempty"`
+}
+
+// ManualGate is a point in the migration where it can wait for an operator
+// +kubebuilder:validation:Enum=BeforeFreeze;AfterFreeze;BeforeFinalize
+type ManualGate string
+
+const (
+	// ManualGateBeforeFreeze waits after pre-flight, before the source is touched
+	ManualGateBeforeFreeze ManualGate = "BeforeFreeze"
+
+	// ManualGateAfterFreeze waits once the source is frozen, before the first pod is deleted
+	ManualGateAfterFreeze ManualGate = "AfterFreeze"
+
+	// ManualGateBeforeFinalize waits once every pod has moved, before the source is cleaned up
+	ManualGateBeforeFinalize ManualGate = "BeforeFinalize"
+)
+
+// FailurePolicy is what happens when a pod fails to migrate
+// +kubebuilder:validation:Enum=Fail;ContinueRemaining
+type FailurePolicy string
+
+const (
+	// FailurePolicyFail fails the migration at the pod
+	FailurePolicyFail FailurePolicy = "Fail"
+
+	// FailurePolicyContinueRemaining records the pod as failed and moves the remaining pods
+	FailurePolicyContinueRemaining FailurePolicy = "ContinueRemaining"
+)
+
+// PodOrderConfig names where each pod's migration priority is read from. A
+// priority is an integer; lower priorities move first, and pods without one
+// have priority 0. The destination StatefulSet can only run a contiguous range
+// of ordinals, so at every priority the pods at or below it must be
+// contiguous: a leader can move last only if it is the first or last ordinal.
+// +kubebuilder:validation:XValidation:rule="has(self.priorityLabel) != has(self.priorityAnnotation)",message="exactly one of priorityLabel or priorityAnnotation is required"
+type PodOrderConfig struct {
+	// PriorityLabel is the pod label holding the priority
+	// +optional
+	PriorityLabel string `json:"priorityLabel,omitempty"`
+
+	// PriorityAnnotation is the pod annotation holding the priority
+	// +optional
+	PriorityAnnotation string `json:"priorityAnnotation,omitempty"`
+}
+
+// ScheduleConfig sets when a scheduled migration starts, and when its
+// maintenance window closes
+// +kubebuilder:validation:XValidation:rule="!has(self.endTime) || self.endTime > self.startTime",message="endTime must be after startTime"
+type ScheduleConfig struct {
+	// StartTime is when the maintenance window opens and the migration starts
+	// +kubebuilder:validation:Required
+	StartTime metav1.Time `json:"startTime"`
+
+	// RevalidateBefore is how long before StartTime the pre-flight checks run
+	// again, so anything that changed since they first ran is caught while
+	// there is still time to fix it (default: 1h)
+	// +optional
+	RevalidateBefore *metav1.Duration `json:"revalidateBefore,omitempty"`
+	// EndTime is when the maintenance window closes. A migration still
+	// moving pods then finishes the pods in flight and holds with the
+	// WindowExceeded condition instead of taking downtime outside the
+	// window, until the migration.aqua.io/window-end annotation sets a
+	// later end (optional)
+	// +optional
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+}
+
+// MetadataPassthroughConfig selects the source PV and PVC metadata copied to
+// the destination. A key is copied when it starts with one of the prefixes;
+// "*" matches every key. Keys Kubernetes manages on volumes (pv.kubernetes.io/,
+// volume.kubernetes.io/, volume.beta.kubernetes.io/), kubectl's
+// last-applied-configuration and the controller's migration.aqua.io/ keys are
+// never copied.
+type MetadataPassthroughConfig struct {
+	// AnnotationPrefixes selects the annotations to copy, for example
+	// "ebs.csi.aws.com/" or a backup tool's prefix
+	// +kubebuilder:validation:items:MinLength=1
+	// +optional
+	AnnotationPrefixes []string `json:"annotationPrefixes,omitempty"`
+
+	// LabelPrefixes selects the labels to copy
+	// +kubebuilder:validation:items:MinLength=1
+	// +optional
+	LabelPrefixes []string `json:"labelPrefixes,omitempty"`
+}
+
+// DataSourcePolicy is what happens to the data source of a PVC created from a snapshot or clone
+// +kubebuilder:validation:Enum=Strip;Preserve
+type DataSourcePolicy string
+
+const (
+	// DataSourceStrip drops the data source from the destination PVC
+	DataSourceStrip DataSourcePolicy = "Strip"
+
+	// DataSourcePreserve copies the data source to the destination PVC
+	DataSourcePreserve DataSourcePolicy = "Preserve"
+)
+
+// ServiceCheckPolicy is how pre-flight treats a headless service missing from the destination
+// +kubebuilder:validation:Enum=Error;Warn;Skip
+type ServiceCheckPolicy string
+
+const (
+	// ServiceCheckError fails pre-flight
+	ServiceCheckError ServiceCheckPolicy = "Error"
+
+	// ServiceCheckWarn reports the missing service in a condition and continues
+	ServiceCheckWarn ServiceCheckPolicy = "Warn"
+
+	// ServiceCheckSkip does not check the service
+	ServiceCheckSkip ServiceCheckPolicy = "Skip"
+)
+
+// OrphanedPodPolicy is what happens to orphaned source pods when an unfinished migration is deleted
+// +kubebuilder:validation:Enum=Retain;Delete
+type OrphanedPodPolicy string
+
+const (
+	// OrphanedPodPolicyRetain leaves the pods running; recreating the source
+	// StatefulSet re-adopts them
+	OrphanedPodPolicyRetain OrphanedPodPolicy = "Retain"
+
+	// OrphanedPodPolicyDelete deletes the pods
+	OrphanedPodPolicyDelete OrphanedPodPolicy = "Delete"
+)
+
+// OverridesConfig selects the pre-flight checks a migration bypasses
+type OverridesConfig struct {
+	// IgnoreMissingService only warns when the headless service is missing
+	// from the destination, unless serviceCheck says otherwise
+	// +optional
+	IgnoreMissingService bool `json:"ignoreMissingService,omitempty"`
+
+	// IgnoreIPFamilyMismatch moves the headless service to the destination's
+	// IP family when the destination does not serve the source's
+	// +optional
+	IgnoreIPFamilyMismatch bool `json:"ignoreIPFamilyMismatch,omitempty"`
+
+	// IgnoreUnschedulablePods proceeds when pods would not schedule on any
+	// destination node in their volume's zone
+	// +optional
+	IgnoreUnschedulablePods bool `json:"ignoreUnschedulablePods,omitempty"`
+
+	// AllowStorageClassDowngrade proceeds when storageClassMapping maps to a
+	// slower or unencrypted StorageClass
+	// +optional
+	AllowStorageClassDowngrade bool `json:"allowStorageClassDowngrade,omitempty"`
+
+	// IgnoreAttachLimits proceeds when destination nodes have too few free
+	// volume attachments for the volumes in their zone
+	// +optional
+	IgnoreAttachLimits bool `json:"ignoreAttachLimits,omitempty"`
+
+	// IgnoreQuotaCheck proceeds when the migration would exceed an EBS quota
+	// of the AWS account
+	// +optional
+	IgnoreQuotaCheck bool `json:"ignoreQuotaCheck,omitempty"`
+}
+
+// CleanupConfig selects the source objects deleted in Finalizing. The EBS
+// volumes themselves are never deleted, since their reclaim policy is Retain.
+// +kubebuilder:validation:XValidation:rule="!has(self.deleteSourcePVCs) || self.deleteSourcePVCs || (has(self.deleteSourcePVs) && !self.deleteSourcePVs)",message="deleteSourcePVs must be false when deleteSourcePVCs is false"
+type CleanupConfig struct {
+	// DeleteSourcePVCs deletes the source PVCs (default: true)
+	// +kubebuilder:default=true
+	// +optional
+	DeleteSourcePVCs *bool `json:"deleteSourcePVCs,omitempty"`
+
+	// DeleteSourcePVs deletes the source PV objects (default: true). Keep
+	// them, for example, where they must be archived for compliance. A PV
+	// cannot be deleted while its PVC exists, so this requires deleteSourcePVCs.
+	// +kubebuilder:default=true
+	// +optional
+	DeleteSourcePVs *bool `json:"deleteSourcePVs,omitempty"`
+
+	// DeleteSourceOrphanedPods deletes pods of the StatefulSet still left in
+	// the source, such as ones recreated by hand during the migration (default: true)
+	// +kubebuilder:default=true
+	// +optional
+	DeleteSourceOrphanedPods *bool `json:"deleteSourceOrphanedPods,omitempty"`
+}
+
+// QuiesceConfig configures the quiesce annotation protocol
+type QuiesceConfig struct {
+	// AckAnnotation is the annotation the pod sets to "true" once its
+	// application has quiesced (default: "migration.aqua.io/quiesced")
+	// +kubebuilder:validation:MaxLength=317
+	// +kubebuilder:validation:Pattern=`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`
+	// +kubebuilder:default="migration.aqua.io/quiesced"
+	// +optional
+	AckAnnotation string `json:"ackAnnotation,omitempty"`
+
+	// Timeout is the maximum time to wait for the acknowledgement (default: 5m)
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('1s')",message="timeout must be at least 1s"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// TimeoutAction is what happens when a pod does not acknowledge in time:
+	// Fail fails the migration with the pod still running, Proceed deletes it
+	// anyway (default: Fail)
+	// +kubebuilder:default=Fail
+	// +optional
+	TimeoutAction QuiesceTimeoutAction `json:"timeoutAction,omitempty"`
+}
+
+// QuiesceTimeoutAction is what happens when a pod does not acknowledge a quiesce request in time
+// +kubebuilder:validation:Enum=Fail;Proceed
+type QuiesceTimeoutAction string
+
+const (
+	// QuiesceTimeoutFail fails the migration, leaving the pod running
+	QuiesceTimeoutFail QuiesceTimeoutAction = "Fail"
+
+	// QuiesceTimeoutProceed deletes the pod without an acknowledgement
+	QuiesceTimeoutProceed QuiesceTimeoutAction = "Proceed"
+)
+
+// BackupRetagConfig lists the tag changes made to each migrated volume.
+// Snapshots already taken keep their tags and their policy's retention.
+// +kubebuilder:validation:XValidation:rule="has(self.set) || has(self.remove)",message="set or remove is required"
+type BackupRetagConfig struct {
+	// Set adds these tags to the volume, replacing existing values
+	// +kubebuilder:validation:XValidation:rule="self.all(k, !k.startsWith('aws:'))",message="tags with the aws: prefix are reserved"
+	// +optional
+	Set map[string]string `json:"set,omitempty"`
+
+	// Remove deletes these tag keys from the volume
+	// +kubebuilder:validation:XValidation:rule="self.all(k, !k.startsWith('aws:'))",message="tags with the aws: prefix are reserved"
+	// +optional
+	Remove []string `json:"remove,omitempty"`
+}
+
+// VeleroConfig configures resource replication through an existing Velero installation
+type VeleroConfig struct {
+	// Namespace is the namespace Velero runs in, in both clusters (default: "velero")
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:default=velero
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 
-	// KubeConfigKey is the key in the secret containing the kubeconfig (default: "kubeconfig")
+	// StorageLocation is the BackupStorageLocation to back up to (default: Velero's default location)
 	// +optional
-	KubeConfigKey string `json:"kubeConfigKey,omitempty"`
+	StorageLocation string `json:"storageLocation,omitempty"`
+
+	// IncludedResources limits replication to these resources, e.g. "services" or
+	// "configmaps" (default: all namespaced resources)
+	// +optional
+	IncludedResources []string `json:"includedResources,omitempty"`
+
+	// ExcludedResources are not replicated. The StatefulSet, its pods and its
+	// volumes are always excluded because the controller moves them itself.
+	// +optional
+	ExcludedResources []string `json:"excludedResources,omitempty"`
+
+	// LabelSelector limits replication to resources with matching labels
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// Timeout is the maximum time to wait for the backup and restore together (default: 30m)
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('10s')",message="timeout must be at least 10s"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// AllowPartialFailure continues the migration when Velero reports a
+	// backup or restore as PartiallyFailed instead of failing it
+	// +kubebuilder:default=false
+	// +optional
+	AllowPartialFailure bool `json:"allowPartialFailure,omitempty"`
 }
 
-// StatefulSetMigrationSpec defines the desired state of StatefulSetMigration
-type StatefulSetMigrationSpec struct {
-	// MigrationID is a unique identifier for this migration
-	MigrationID string `json:"migrationId"`
+// VeleroStatus records the Velero backup and restore of a migration
+type VeleroStatus struct {
+	// BackupName is the Velero Backup in the source cluster
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
 
-	// SourceCluster contains the reference to the source cluster kubeconfig
-	SourceCluster ContextRef `json:"sourceCluster"`
+	// BackupPhase is the last observed phase of the backup
+	// +optional
+	BackupPhase string `json:"backupPhase,omitempty"`
 
-	// SourceNamespace is the namespace of the StatefulSet in the source cluster
-	SourceNamespace string `json:"sourceNamespace"`
+	// RestoreName is the Velero Restore in the destination cluster
+	// +optional
+	RestoreName string `json:"restoreName,omitempty"`
 
-	// StatefulSetName is the name of the StatefulSet to migrate
-	StatefulSetName string `json:"statefulSetName"`
+	// RestorePhase is the last observed phase of the restore
+	// +optional
+	RestorePhase string `json:"restorePhase,omitempty"`
 
-	// DestCluster contains the reference to the destination cluster kubeconfig
-	DestCluster ContextRef `json:"destCluster"`
+	// StartedAt is when resource replication started
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+}
 
-	// DestNamespace is the namespace to migrate to in the destination cluster
-	DestNamespace string `json:"destNamespace"`
+// AWSConfig holds per-migration AWS settings
+type AWSConfig struct {
+	// Region is the AWS region of the source volumes; defaults to the
+	// controller's --aws-region
+	// +kubebuilder:validation:Pattern=`^[a-z]{2}(-gov|-iso[a-z]?)?-[a-z]+-[0-9]+$`
+	// +optional
+	Region string `json:"region,omitempty"`
+}
 
-	// Force ignores non-critical pre-flight warnings
+// DestAWSConfig describes the destination cluster's AWS account and identity
+// +kubebuilder:validation:XValidation:rule="!has(self.transferVolumes) || !self.transferVolumes || (has(self.accountId) && has(self.roleArn))",message="transferVolumes requires accountId and roleArn"
+type DestAWSConfig struct {
+	// AccountID is the destination cluster's AWS account ID; defaults to the volume's account
+	// +kubebuilder:validation:Pattern=`^[0-9]{12}$`
 	// +optional
-	Force bool `json:"force,omitempty"`
+	AccountID string `json:"accountId,omitempty"`
 
-	// StorageClassMapping maps source StorageClass names to destination StorageClass names
-	// If not specified, the same StorageClass name will be used
+	// NodeRoleARN is the IAM role the destination nodes (or EBS CSI driver) use to attach volumes
+	// +kubebuilder:validation:Pattern=`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`
 	// +optional
-	StorageClassMapping map[string]string `json:"storageClassMapping,omitempty"`
+	NodeRoleARN string `json:"nodeRoleArn,omitempty"`
 
-	// VolumeDetachTimeout is the maximum time to wait for a volume to detach (default: 5m)
+	// KMSKeyID is the destination key that snapshot-copy strategies re-encrypt volumes with.
+	// When set, the source key only needs to be usable for the copy, not by the destination nodes.
 	// +optional
-	VolumeDetachTimeout *metav1.Duration `json:"volumeDetachTimeout,omitempty"`
+	KMSKeyID string `json:"kmsKeyId,omitempty"`
 
-	// PodReadyTimeout is the maximum time to wait for a pod to become ready (default: 10m)
+	// RoleARN is a role in the destination account that the controller
+	// assumes to copy snapshots and create volumes there
+	// +kubebuilder:validation:Pattern=`^arn:aws[a-z-]*:iam::[0-9]{12}:role/.+$`
 	// +optional
-	PodReadyTimeout *metav1.Duration `json:"podReadyTimeout,omitempty"`
+	RoleARN string `json:"roleArn,omitempty"`
+
+	// TransferVolumes moves each volume into the destination account instead
+	// of reattaching it: a snapshot is shared with the account, copied there
+	// and restored to a new volume in the same zone. The snapshots are deleted
+	// once the volume exists; the source volume is kept.
+	// +kubebuilder:default=false
+	// +optional
+	TransferVolumes bool `json:"transferVolumes,omitempty"`
+}
+
+// UnboundPVCPolicy is what a migration does with a PVC that has no PV
+// +kubebuilder:validation:Enum=Fail;Provision
+type UnboundPVCPolicy string
+
+const (
+	// UnboundPVCFail fails pre-flight
+	UnboundPVCFail UnboundPVCPolicy = "Fail"
+
+	// UnboundPVCProvision moves the replica without a volume: its source pod
+	// is deleted and the destination StatefulSet provisions a new volume.
+	// A StatefulSet cannot leave out an ordinal, so the replica cannot be
+	// skipped.
+	UnboundPVCProvision UnboundPVCPolicy = "Provision"
+)
+
+// UnboundPVCInfo records a replica whose source PVC has no PV
+type UnboundPVCInfo struct {
+	// Index is the StatefulSet pod index
+	Index int `json:"index"`
+
+	// PVCName is the source PVC
+	PVCName string `json:"pvcName"`
+
+	// Phase is the source PVC's phase, usually Pending
+	// +optional
+	Phase string `json:"phase,omitempty"`
+}
+
+// FallbackStrategy is the strategy a volume falls back to
+// +kubebuilder:validation:Enum=SnapshotRestore
+type FallbackStrategy string
+
+const (
+	// FallbackStrategySnapshotRestore snapshots the source volume and
+	// restores the snapshot to a new volume in the same account
+	FallbackStrategySnapshotRestore FallbackStrategy = "SnapshotRestore"
+)
+
+// FallbackTrigger is a deterministic failure of the Reattach strategy
+// +kubebuilder:validation:Enum=ZoneMismatch;DetachBlocked
+type FallbackTrigger string
+
+const (
+	// FallbackTriggerZoneMismatch is a volume in a zone where no destination
+	// node can run its pod, found at pre-flight
+	FallbackTriggerZoneMismatch FallbackTrigger = "ZoneMismatch"
+
+	// FallbackTriggerDetachBlocked is a volume that cannot detach because its
+	// instance is stopped, shutting down or terminated, and forceDetach is
+	// not set
+	FallbackTriggerDetachBlocked FallbackTrigger = "DetachBlocked"
+)
+
+// StrategyFallback configures the fallback from reattaching a volume
+type StrategyFallback struct {
+	// Strategy is the strategy to fall back to (default: SnapshotRestore)
+	// +kubebuilder:default=SnapshotRestore
+	// +optional
+	Strategy FallbackStrategy `json:"strategy,omitempty"`
+
+	// On lists the failures that fall back (default: ZoneMismatch and DetachBlocked)
+	// +listType=set
+	// +optional
+	On []FallbackTrigger `json:"on,omitempty"`
+}
+
+// VolumeStrategy is how a volume moves to the destination
+// +kubebuilder:validation:Enum=Reattach;SnapshotRestore;Transfer
+type VolumeStrategy string
+
+const (
+	// VolumeStrategyReattach detaches the volume and attaches it in the destination
+	VolumeStrategyReattach VolumeStrategy = "Reattach"
+
+	// VolumeStrategySnapshotRestore restores a snapshot of the volume to a new
+	// volume in the same account, with spec.strategyFallback
+	VolumeStrategySnapshotRestore VolumeStrategy = "SnapshotRestore"
+
+	// VolumeStrategyTransfer copies the volume into the destination account,
+	// with spec.destAWS.transferVolumes
+	VolumeStrategyTransfer VolumeStrategy = "Transfer"
+)
+
+// ZoneIntersection compares the zones of the source volumes with the zones
+// of the destination nodes that can run the pods
+type ZoneIntersection struct {
+	// VolumeZones are the zones of the source volumes
+	// +optional
+	VolumeZones []string `json:"volumeZones,omitempty"`
+
+	// NodeZones are the zones of the destination nodes that can run the pods
+	// +optional
+	NodeZones []string `json:"nodeZones,omitempty"`
+
+	// SharedZones are the zones in both, where volumes can be reattached
+	// +optional
+	SharedZones []string `json:"sharedZones,omitempty"`
+}
+
+// VolumeStrategyDecision is the strategy pre-flight selected for a volume
+type VolumeStrategyDecision struct {
+	// VolumeID is the source volume
+	VolumeID string `json:"volumeId"`
+
+	// Zone is the zone of the source volume
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// Strategy is how the volume moves
+	Strategy VolumeStrategy `json:"strategy"`
+
+	// DestZone is the zone the volume is in once it has moved
+	// +optional
+	DestZone string `json:"destZone,omitempty"`
+
+	// Reason explains the selection
+	Reason string `json:"reason"`
+}
+
+// VolumeFallback records a volume moved with the fallback strategy
+type VolumeFallback struct {
+	// VolumeID is the source volume
+	VolumeID string `json:"volumeId"`
+
+	// Trigger is the failure that made the volume fall back
+	Trigger FallbackTrigger `json:"trigger"`
+
+	// Reason explains the failure
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Zone is the availability zone the volume is restored in
+	Zone string `json:"zone"`
+
+	// DestVolumeID is the restored volume, once it has been created
+	// +optional
+	DestVolumeID string `json:"destVolumeId,omitempty"`
+
+	// Time is when the fallback was decided
+	Time metav1.Time `json:"time"`
+}
+
+// VolumeWaitSource is the system a volume wait depends on
+// +kubebuilder:validation:Enum=AWS;Kubernetes
+type VolumeWaitSource string
+
+const (
+	// VolumeWaitSourceAWS is a wait on EBS, such as a detach or snapshot
+	VolumeWaitSourceAWS VolumeWaitSource = "AWS"
+
+	// VolumeWaitSourceKubernetes is a wait on the cluster, such as the kubelet unmounting the volume
+	VolumeWaitSourceKubernetes VolumeWaitSource = "Kubernetes"
+)
+
+// VolumeWait is a wait on one volume that is in progress
+type VolumeWait struct {
+	// VolumeID is the EBS volume waited on
+	VolumeID string `json:"volumeId"`
+
+	// Step is the history step of the wait, such as WaitVolumeDetach
+	Step string `json:"step"`
+
+	// WaitingOn is the system the wait depends on
+	WaitingOn VolumeWaitSource `json:"waitingOn"`
+
+	// State is the volume's live state as last observed, such as
+	// "in-use, detaching for 40s"
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// StartTime is when the wait started
+	StartTime metav1.Time `json:"startTime"`
+
+	// LastProbeTime is when State was last recorded; an unchanged State is
+	// recorded every 30s
+	// +optional
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
 }
 
 // MigratedPodInfo contains information about a migrated pod
@@ -84,11 +1329,118 @@ type MigratedPodInfo struct {
 	// PodName is the name of the pod
 	PodName string `json:"podName"`
 
-	// VolumeID is the EBS volume ID
+	// VolumeID is the EBS volume the destination pod uses; empty for a
+	// replica whose PVC had no PV, given a new volume in the destination
 	VolumeID string `json:"volumeId"`
 
+	// SourceVolumeID is the source volume a transferred volume was copied from
+	// +optional
+	SourceVolumeID string `json:"sourceVolumeId,omitempty"`
+
+	// SourceInstanceID is the EC2 instance the source volume was attached to
+	// before the source pod was deleted. Unset if the pod was already gone.
+	// +optional
+	SourceInstanceID string `json:"sourceInstanceId,omitempty"`
+
+	// DestInstanceID is the EC2 instance the volume was attached to once the
+	// destination pod was ready
+	// +optional
+	DestInstanceID string `json:"destInstanceId,omitempty"`
+
 	// MigratedAt is when this pod was migrated
 	MigratedAt metav1.Time `json:"migratedAt"`
+
+	// StoppedAt is when the source pod was deleted; the pod was down from
+	// StoppedAt until MigratedAt. Unset if the pod was already gone.
+	// +optional
+	StoppedAt *metav1.Time `json:"stoppedAt,omitempty"`
+
+	// StorageBytes is the capacity of the volume the pod moved with, as its
+	// destination PV records it; unset for a replica given a new volume
+	// +optional
+	StorageBytes int64 `json:"storageBytes,omitempty"`
+}
+
+// FailedPodInfo records a pod that failed to migrate under the
+// ContinueRemaining failure policy
+type FailedPodInfo struct {
+	// Index is the StatefulSet pod index
+	Index int `json:"index"`
+
+	// PodName is the name of the pod
+	PodName string `json:"podName"`
+
+	// Reason is the error the pod failed with
+	Reason string `json:"reason"`
+
+	// FailedAt is when the pod was given up on
+	FailedAt metav1.Time `json:"failedAt"`
+}
+
+// JobKind is the kind of a workload migrated with MigrateJobs
+// +kubebuilder:validation:Enum=CronJob;Job
+type JobKind string
+
+const (
+	// JobKindCronJob is a batch/v1 CronJob
+	JobKindCronJob JobKind = "CronJob"
+	// JobKindJob is a batch/v1 Job
+	JobKindJob JobKind = "Job"
+)
+
+// MigratedJobInfo records a CronJob or Job that mounts the StatefulSet's PVCs
+type MigratedJobInfo struct {
+	// Kind is CronJob or Job
+	Kind JobKind `json:"kind"`
+
+	// Name is the name of the CronJob or Job in the source namespace
+	Name string `json:"name"`
+
+	// CronJob is the CronJob that created this Job. Such Jobs are only
+	// suspended; the migrated CronJob schedules new ones.
+	// +optional
+	CronJob string `json:"cronJob,omitempty"`
+
+	// WasSuspended is whether the source was already suspended; the destination copy keeps that state
+	// +optional
+	WasSuspended bool `json:"wasSuspended,omitempty"`
+
+	// Recreated is true once the CronJob or Job exists in the destination
+	// +optional
+	Recreated bool `json:"recreated,omitempty"`
+}
+
+// HistoryResult is the outcome recorded for a history entry
+// +kubebuilder:validation:Enum=Started;Succeeded;Failed
+type HistoryResult string
+
+const (
+	// HistoryResultStarted indicates a step has begun
+	HistoryResultStarted HistoryResult = "Started"
+	// HistoryResultSucceeded indicates a step completed successfully
+	HistoryResultSucceeded HistoryResult = "Succeeded"
+	// HistoryResultFailed indicates a step failed
+	HistoryResultFailed HistoryResult = "Failed"
+)
+
+// HistoryEntry is a single timestamped step in the migration's history
+type HistoryEntry struct {
+	// Time is when the step was recorded
+	Time metav1.Time `json:"time"`
+
+	// Step is a short identifier for what the controller did (e.g. DeletePod, CreatePV)
+	Step string `json:"step"`
+
+	// Object is the object the step acted on, as kind/namespace/name or kind/name
+	// +optional
+	Object string `json:"object,omitempty"`
+
+	// Result is the outcome of the step
+	Result HistoryResult `json:"result"`
+
+	// Message is additional human-readable detail
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // StatefulSetMigrationStatus defines the observed state of StatefulSetMigration
@@ -96,16 +1448,87 @@ type StatefulSetMigrationStatus struct {
 	// Phase is the current phase of the migration
 	Phase MigrationPhase `json:"phase,omitempty"`
 
-	// CurrentIndex is the index of the pod currently being migrated (0-based)
+	// DestinationServer is the API server URL of the destination cluster,
+	// recorded when a MigrationPolicy limits the active migrations into it
+	// +optional
+	DestinationServer string `json:"destinationServer,omitempty"`
+
+	// CurrentIndex is the position in the migration order of the pod
+	// currently being migrated (0-based); it is the pod's ordinal unless
+	// spec.podOrder reorders the pods
 	CurrentIndex int `json:"currentIndex,omitempty"`
 
-	// TotalReplicas is the total number of replicas to migrate
+	// PodOrder lists the ordinals in the order they migrate, when spec.podOrder
+	// or spec.ordinalRange is set
+	// +optional
+	PodOrder []int `json:"podOrder,omitempty"`
+
+	// SourceOrdinals are the ordinals the source StatefulSet keeps running
+	// once spec.ordinalRange has moved, such as 0-2; empty when none remain
+	// +optional
+	SourceOrdinals string `json:"sourceOrdinals,omitempty"`
+
+	// PriorDestOrdinals are the ordinals the destination StatefulSet already
+	// ran when pre-flight checks passed, moved there by earlier migrations of
+	// spec.ordinalRange
+	// +optional
+	PriorDestOrdinals string `json:"priorDestOrdinals,omitempty"`
+
+	// VolumeWaits lists the volumes the controller is waiting on right now,
+	// with their live state, so a migration that sits in MigratingPods shows
+	// whether it is waiting on AWS or on Kubernetes
+	// +optional
+	VolumeWaits []VolumeWait `json:"volumeWaits,omitempty"`
+
+	// VolumeFallbacks lists the volumes moved, or to be moved, with
+	// spec.strategyFallback instead of being reattached
+	// +optional
+	VolumeFallbacks []VolumeFallback `json:"volumeFallbacks,omitempty"`
+
+	// ZoneIntersection is the zone layout pre-flight found
+	// +optional
+	ZoneIntersection *ZoneIntersection `json:"zoneIntersection,omitempty"`
+
+	// VolumeStrategies records the strategy pre-flight selected for each
+	// volume and why
+	// +optional
+	VolumeStrategies []VolumeStrategyDecision `json:"volumeStrategies,omitempty"`
+
+	// TotalReplicas is the number of replicas the migration moves: every
+	// replica, or those of spec.ordinalRange
 	TotalReplicas int `json:"totalReplicas,omitempty"`
 
+	// Progress is how many of the replicas have been through the migration,
+	// moved or skipped, as "<done>/<total>", for kubectl and dashboards that
+	// render printer columns
+	// +optional
+	Progress string `json:"progress,omitempty"`
+
+	// ProgressPercent is the share of the replicas that have been through
+	// the migration, from 0 to 100
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ProgressPercent int `json:"progressPercent,omitempty"`
+
 	// MigratedPods contains information about successfully migrated pods
 	// +optional
 	MigratedPods []MigratedPodInfo `json:"migratedPods,omitempty"`
 
+	// TotalStorageMigrated sums the capacity of the volumes in MigratedPods
+	// +optional
+	TotalStorageMigrated *resource.Quantity `json:"totalStorageMigrated,omitempty"`
+
+	// FailedPods lists the pods that failed to migrate and were skipped
+	// under the ContinueRemaining failure policy
+	// +optional
+	FailedPods []FailedPodInfo `json:"failedPods,omitempty"`
+
+	// UnboundPVCs lists the replicas whose source PVC has no PV, moved
+	// without a volume under spec.unboundPVCs Provision
+	// +optional
+	UnboundPVCs []UnboundPVCInfo `json:"unboundPVCs,omitempty"`
+
 	// Conditions represent the latest available observations of the migration's state
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
@@ -122,20 +1545,211 @@ type StatefulSetMigrationStatus struct {
 	// +optional
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 
+	// VolumeProtectedUntil is when the deletion protection the controller's
+	// --dest-volume-protection puts on the destination PVs and PVCs at
+	// completion lapses; cleared once it has been lifted
+	// +optional
+	VolumeProtectedUntil *metav1.Time `json:"volumeProtectedUntil,omitempty"`
+
+	// FrozenTime is when the source StatefulSet was orphaned
+	// +optional
+	FrozenTime *metav1.Time `json:"frozenTime,omitempty"`
+
 	// SourceStatefulSetUID is the UID of the source StatefulSet (for verification)
 	// +optional
 	SourceStatefulSetUID string `json:"sourceStatefulSetUID,omitempty"`
 
+	// DestRevision is the destination StatefulSet's update revision once its
+	// first migrated pod was Ready. Scaling refuses to run against another one.
+	// +optional
+	DestRevision string `json:"destRevision,omitempty"`
+
+	// NodeOS is the operating system the StatefulSet's pods run on, detected
+	// from its pod template in pre-flight
+	// +kubebuilder:validation:Enum=linux;windows
+	// +optional
+	NodeOS string `json:"nodeOS,omitempty"`
+
+	// ConvertInTreeEBS is set in pre-flight when destination nodes the pods
+	// may run on, such as Bottlerocket nodes, cannot mount legacy in-tree EBS
+	// volumes; their destination PVs use the EBS CSI driver instead
+	// +optional
+	ConvertInTreeEBS bool `json:"convertInTreeEBS,omitempty"`
+
 	// PreservedPVs contains the list of PV names that have been set to Retain
 	// +optional
 	PreservedPVs []string `json:"preservedPVs,omitempty"`
+
+	// OrphanedPods lists the source pods left running without a StatefulSet
+	// when it was orphaned; a pod is removed once the migration deletes it
+	// +optional
+	OrphanedPods []string `json:"orphanedPods,omitempty"`
+
+	// ImagePrePullDaemonSet is the destination DaemonSet pre-pulling the
+	// workload's images for spec.imagePrePull, until it is deleted
+	// +optional
+	ImagePrePullDaemonSet string `json:"imagePrePullDaemonSet,omitempty"`
+
+	// CapacityWait is the pod the migration waits on to be scheduled in the
+	// destination under spec.capacityWait
+	// +optional
+	CapacityWait *CapacityWaitStatus `json:"capacityWait,omitempty"`
+
+	// Jobs lists the CronJobs and Jobs suspended in the source because they
+	// mount the StatefulSet's PVCs, when spec.migrateJobs is set
+	// +optional
+	Jobs []MigratedJobInfo `json:"jobs,omitempty"`
+
+	// GuardLease is the name of the lease preventing concurrent migrations
+	// of the same source StatefulSet while this migration holds it
+	// +optional
+	GuardLease string `json:"guardLease,omitempty"`
+
+	// History is a bounded log of the steps taken by the migration, oldest first.
+	// Only the most recent entries are kept.
+	// +optional
+	History []HistoryEntry `json:"history,omitempty"`
+
+	// Report is the name of the ConfigMap holding the migration report, written
+	// once the migration completes or fails
+	// +optional
+	Report string `json:"report,omitempty"`
+
+	// Archive is the S3 location the migration's source manifests and per-pod
+	// checkpoints are archived under, when archiving is enabled
+	// +optional
+	Archive string `json:"archive,omitempty"`
+
+	// ObservedGeneration is the most recent metadata.generation the controller has seen
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// AppliedSpec is the spec the migration started with. Spec edits made after
+	// the migration leaves Pending are ignored and reported by the
+	// SpecChangeIgnored condition.
+	// +optional
+	AppliedSpec *StatefulSetMigrationSpec `json:"appliedSpec,omitempty"`
+
+	// SpecSnapshotHash is the SHA-256 hash of the normalized spec the
+	// migration started with, as "sha256:<hex>". The SpecSnapshot event
+	// recorded at the start carries the same spec, so an audit can prove which
+	// parameters the migration ran with even after the resource was edited.
+	// +optional
+	SpecSnapshotHash string `json:"specSnapshotHash,omitempty"`
+
+	// ControllerVersion is the version of the controller that started the
+	// migration. A controller of another major or minor version holds the
+	// migration instead of resuming it, since its steps may differ.
+	// +optional
+	ControllerVersion string `json:"controllerVersion,omitempty"`
+
+	// FeatureGates lists the optional controller features enabled when the
+	// migration started, such as VolumeLocks and SignedApprovals
+	// +optional
+	FeatureGates []string `json:"featureGates,omitempty"`
+
+	// Velero records the backup and restore that replicated the namespace's
+	// other resources, when spec.velero is set
+	// +optional
+	Velero *VeleroStatus `json:"velero,omitempty"`
+
+	// GitOpsPause records the annotations added to pause GitOps controllers
+	// while spec.pauseGitOps holds them
+	// +optional
+	GitOpsPause *GitOpsPauseStatus `json:"gitOpsPause,omitempty"`
+
+	// PreFlight is the result of the pre-flight checks last run ahead of
+	// spec.schedule.startTime
+	// +optional
+	PreFlight *PreFlightResult `json:"preFlight,omitempty"`
+
+	// BandwidthProbe is the throughput spec.bandwidthProbe last measured
+	// between the clusters, and the copy time estimated from it
+	// +optional
+	BandwidthProbe *BandwidthProbeResult `json:"bandwidthProbe,omitempty"`
+
+	// AcknowledgedGates lists the spec.manualGates an operator has acknowledged
+	// +optional
+	AcknowledgedGates []ManualGate `json:"acknowledgedGates,omitempty"`
+
+	// Approval records the signed approval the controller verified before
+	// freezing the source, when it requires one
+	// +optional
+	Approval *MigrationApproval `json:"approval,omitempty"`
+}
+
+// MigrationApproval is a verified signature approving a migration's spec
+type MigrationApproval struct {
+	// KeyFingerprint identifies the public key the signature was verified
+	// with, as "SHA256:" and the base64 SHA-256 of the key's DER encoding
+	KeyFingerprint string `json:"keyFingerprint"`
+
+	// SpecSnapshotHash is the spec hash the signature covers
+	SpecSnapshotHash string `json:"specSnapshotHash"`
+
+	// Signature is the verified signature, base64-encoded, so an audit can
+	// verify it again
+	Signature string `json:"signature"`
+
+	// ApprovedTime is when the controller verified the signature
+	ApprovedTime metav1.Time `json:"approvedTime"`
+}
+
+// PreFlightResult is the outcome of pre-flight checks run ahead of a
+// scheduled migration's start. The checks run once more when it starts.
+type PreFlightResult struct {
+	// Time is when the checks ran
+	Time metav1.Time `json:"time"`
+
+	// ObservedGeneration is the metadata.generation the checks ran against;
+	// a spec edit runs them again
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Passed is true when no check of severity Error failed
+	Passed bool `json:"passed"`
+
+	// Message is the failure of the check that blocks the migration
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Warnings lists the failures of checks of severity Warning
+	// +optional
+	Warnings []string `json:"warnings,omitempty"`
+
+	// NextCheck is when the checks run again, before the window opens
+	// +optional
+	NextCheck *metav1.Time `json:"nextCheck,omitempty"`
+}
+
+// BandwidthProbeResult is the outcome of spec.bandwidthProbe
+type BandwidthProbeResult struct {
+	// Time is when the probe ran
+	Time metav1.Time `json:"time"`
+
+	// LatencyMilliseconds is how long the client took to fetch an empty
+	// file from the server
+	LatencyMilliseconds int64 `json:"latencyMilliseconds"`
+
+	// BytesPerSecond is the throughput of the client's fetch of the data
+	BytesPerSecond int64 `json:"bytesPerSecond"`
+
+	// CopyGiB is the size of the volumes that are copied rather than
+	// reattached
+	CopyGiB int64 `json:"copyGiB"`
+
+	// EstimatedCopyDuration is how long copying CopyGiB takes at
+	// BytesPerSecond
+	EstimatedCopyDuration metav1.Duration `json:"estimatedCopyDuration"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
-// +kubebuilder:printcolumn:name="Progress",type=string,JSONPath=`.status.currentIndex`
-// +kubebuilder:printcolumn:name="Total",type=string,JSONPath=`.status.totalReplicas`
+// +kubebuilder:printcolumn:name="Progress",type=string,JSONPath=`.status.progress`
+// +kubebuilder:printcolumn:name="Percent",type=integer,JSONPath=`.status.progressPercent`
+// +kubebuilder:printcolumn:name="Waiting On",type=string,JSONPath=`.status.volumeWaits[0].waitingOn`
+// +kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.status.totalStorageMigrated`,priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // StatefulSetMigration is the Schema for the statefulsetmigrations API
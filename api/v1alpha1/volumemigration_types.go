@@ -0,0 +1,168 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VolumeMigrationPhase represents the current phase of a volume migration
+// +kubebuilder:validation:Enum=Pending;StoppingConsumers;DetachingVolume;CreatingResources;Completed;Failed
+type VolumeMigrationPhase string
+
+const (
+	// VolumePhasePending indicates the volume migration has not started
+	VolumePhasePending VolumeMigrationPhase = "Pending"
+	// VolumePhaseStoppingConsumers indicates the pods mounting the PVC are being deleted
+	VolumePhaseStoppingConsumers VolumeMigrationPhase = "StoppingConsumers"
+	// VolumePhaseDetachingVolume indicates the volume is detaching from its source node
+	VolumePhaseDetachingVolume VolumeMigrationPhase = "DetachingVolume"
+	// VolumePhaseCreatingResources indicates the destination PV and PVC are being created
+	VolumePhaseCreatingResources VolumeMigrationPhase = "CreatingResources"
+	// VolumePhaseCompleted indicates the destination PVC exists and references the volume
+	VolumePhaseCompleted VolumeMigrationPhase = "Completed"
+	// VolumePhaseFailed indicates the volume migration failed
+	VolumePhaseFailed VolumeMigrationPhase = "Failed"
+)
+
+// VolumeMigrationSpec defines the PVC to move and where to move it
+type VolumeMigrationSpec struct {
+	// SourceCluster references the kubeconfig for the source cluster
+	SourceCluster ContextRef `json:"sourceCluster"`
+
+	// SourceNamespace is the namespace of the source PVC
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	SourceNamespace string `json:"sourceNamespace"`
+
+	// PVCName is the name of the source PVC; the destination PVC has the same name
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	PVCName string `json:"pvcName"`
+
+	// DestCluster references the kubeconfig for the destination cluster
+	DestCluster ContextRef `json:"destCluster"`
+
+	// DestNamespace is the namespace to create the PVC in (default: same as source)
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	DestNamespace string `json:"destNamespace,omitempty"`
+
+	// StorageClassMapping maps source StorageClass names to destination StorageClass names
+	// +optional
+	StorageClassMapping map[string]string `json:"storageClassMapping,omitempty"`
+
+	// DestPVNameTemplate is a Go template for the destination PV name, as in
+	// StatefulSetMigration (default: "migrated-{{.Namespace}}-{{.PVCName}}")
+	// +kubebuilder:validation:MaxLength=1024
+	// +optional
+	DestPVNameTemplate string `json:"destPVNameTemplate,omitempty"`
+
+	// MetadataPassthrough copies source PV and PVC annotations and labels,
+	// selected by key prefix, to the destination PV and PVC
+	// +optional
+	MetadataPassthrough *MetadataPassthroughConfig `json:"metadataPassthrough,omitempty"`
+
+	// DataSourcePolicy decides what happens to the dataSource and
+	// dataSourceRef of a source PVC created from a snapshot or clone (default: Strip)
+	// +kubebuilder:default=Strip
+	// +optional
+	DataSourcePolicy DataSourcePolicy `json:"dataSourcePolicy,omitempty"`
+
+	// NodeOS is the operating system of the nodes the volume is attached to
+	// in the destination; set by StatefulSetMigration from its detected node OS
+	// +kubebuilder:validation:Enum=linux;windows
+	// +optional
+	NodeOS string `json:"nodeOS,omitempty"`
+
+	// ConvertInTreeEBS gives a legacy in-tree EBS volume an EBS CSI source in
+	// the destination; set by StatefulSetMigration when its destination nodes
+	// cannot mount in-tree volumes
+	// +optional
+	ConvertInTreeEBS bool `json:"convertInTreeEBS,omitempty"`
+
+	// VolumeDetachTimeout is the maximum time to wait for the volume to detach (default: 5m)
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	// +kubebuilder:validation:XValidation:rule="duration(self) >= duration('10s')",message="volumeDetachTimeout must be at least 10s"
+	// +optional
+	VolumeDetachTimeout *metav1.Duration `json:"volumeDetachTimeout,omitempty"`
+
+	// ForceDetach force-detaches the volume when its instance is stopped,
+	// shutting down or terminated. Unflushed writes on that instance may be
+	// lost. A stopping or impaired instance is never force-detached.
+	// +kubebuilder:default=false
+	// +optional
+	ForceDetach bool `json:"forceDetach,omitempty"`
+
+	// AWSConfig overrides the controller's AWS settings for this volume
+	// +optional
+	AWSConfig *AWSConfig `json:"awsConfig,omitempty"`
+}
+
+// VolumeMigrationStatus defines the observed state of VolumeMigration
+type VolumeMigrationStatus struct {
+	// Phase is the current phase of the volume migration
+	Phase VolumeMigrationPhase `json:"phase,omitempty"`
+
+	// VolumeID is the EBS volume being moved
+	// +optional
+	VolumeID string `json:"volumeId,omitempty"`
+
+	// SourcePVName is the PV the source PVC was bound to
+	// +optional
+	SourcePVName string `json:"sourcePVName,omitempty"`
+
+	// DestPVName is the PV created, or adopted, in the destination
+	// +optional
+	DestPVName string `json:"destPVName,omitempty"`
+
+	// StoppedPods are the source pods deleted because they mounted the PVC
+	// +optional
+	StoppedPods []string `json:"stoppedPods,omitempty"`
+
+	// StartTime is when the volume migration started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the volume migration completed or failed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// LastError contains the error message if Phase is Failed
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// Conditions represent the latest observations of the volume migration's state
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=volm
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="PVC",type=string,JSONPath=`.spec.pvcName`
+// +kubebuilder:printcolumn:name="Volume",type=string,JSONPath=`.status.volumeId`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// VolumeMigration moves the EBS volume of a single PVC to another cluster:
+// it stops the PVC's consumers, waits for the volume to detach, and creates
+// the PV and PVC in the destination. StatefulSetMigration creates one per
+// replica with spec.volumeMigrations; it can also be created on its own.
+type VolumeMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   VolumeMigrationSpec   `json:"spec,omitempty"`
+	Status VolumeMigrationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// VolumeMigrationList contains a list of VolumeMigration
+type VolumeMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VolumeMigration `json:"items"`
+}
@@ -5,43 +5,1479 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSConfig) DeepCopyInto(out *AWSConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSConfig.
+func (in *AWSConfig) DeepCopy() *AWSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssessmentSummary) DeepCopyInto(out *AssessmentSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssessmentSummary.
+func (in *AssessmentSummary) DeepCopy() *AssessmentSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(AssessmentSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetagConfig) DeepCopyInto(out *BackupRetagConfig) {
+	*out = *in
+	if in.Set != nil {
+		in, out := &in.Set, &out.Set
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Remove != nil {
+		in, out := &in.Remove, &out.Remove
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetagConfig.
+func (in *BackupRetagConfig) DeepCopy() *BackupRetagConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetagConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthProbeConfig) DeepCopyInto(out *BandwidthProbeConfig) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthProbeConfig.
+func (in *BandwidthProbeConfig) DeepCopy() *BandwidthProbeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthProbeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthProbeResult) DeepCopyInto(out *BandwidthProbeResult) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	out.EstimatedCopyDuration = in.EstimatedCopyDuration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthProbeResult.
+func (in *BandwidthProbeResult) DeepCopy() *BandwidthProbeResult {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthProbeResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityWaitConfig) DeepCopyInto(out *CapacityWaitConfig) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityWaitConfig.
+func (in *CapacityWaitConfig) DeepCopy() *CapacityWaitConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityWaitConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityWaitStatus) DeepCopyInto(out *CapacityWaitStatus) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityWaitStatus.
+func (in *CapacityWaitStatus) DeepCopy() *CapacityWaitStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityWaitStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleanupConfig) DeepCopyInto(out *CleanupConfig) {
+	*out = *in
+	if in.DeleteSourcePVCs != nil {
+		in, out := &in.DeleteSourcePVCs, &out.DeleteSourcePVCs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeleteSourcePVs != nil {
+		in, out := &in.DeleteSourcePVs, &out.DeleteSourcePVs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeleteSourceOrphanedPods != nil {
+		in, out := &in.DeleteSourceOrphanedPods, &out.DeleteSourceOrphanedPods
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleanupConfig.
+func (in *CleanupConfig) DeepCopy() *CleanupConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CleanupConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectivityProbeConfig) DeepCopyInto(out *ConnectivityProbeConfig) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectivityProbeConfig.
+func (in *ConnectivityProbeConfig) DeepCopy() *ConnectivityProbeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectivityProbeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContextRef) DeepCopyInto(out *ContextRef) {
 	*out = *in
+	if in.CABundleSecretRef != nil {
+		in, out := &in.CABundleSecretRef, &out.CABundleSecretRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+	if in.TokenSecretRef != nil {
+		in, out := &in.TokenSecretRef, &out.TokenSecretRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+	if in.Impersonate != nil {
+		in, out := &in.Impersonate, &out.Impersonate
+		*out = new(ImpersonationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimitConfig)
+		**out = **in
+	}
+	if in.Reader != nil {
+		in, out := &in.Reader, &out.Reader
+		*out = new(ReaderIdentity)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContextRef.
+func (in *ContextRef) DeepCopy() *ContextRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ContextRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSCutoverConfig) DeepCopyInto(out *DNSCutoverConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSCutoverConfig.
+func (in *DNSCutoverConfig) DeepCopy() *DNSCutoverConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSCutoverConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DestAWSConfig) DeepCopyInto(out *DestAWSConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DestAWSConfig.
+func (in *DestAWSConfig) DeepCopy() *DestAWSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DestAWSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DestNamespaceTemplate) DeepCopyInto(out *DestNamespaceTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResourceQuota != nil {
+		in, out := &in.ResourceQuota, &out.ResourceQuota
+		*out = new(corev1.ResourceQuotaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LimitRange != nil {
+		in, out := &in.LimitRange, &out.LimitRange
+		*out = new(corev1.LimitRangeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DestNamespaceTemplate.
+func (in *DestNamespaceTemplate) DeepCopy() *DestNamespaceTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(DestNamespaceTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DestinationLimit) DeepCopyInto(out *DestinationLimit) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DestinationLimit.
+func (in *DestinationLimit) DeepCopy() *DestinationLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(DestinationLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DestinationPlacement) DeepCopyInto(out *DestinationPlacement) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DestinationPlacement.
+func (in *DestinationPlacement) DeepCopy() *DestinationPlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(DestinationPlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailedPodInfo) DeepCopyInto(out *FailedPodInfo) {
+	*out = *in
+	in.FailedAt.DeepCopyInto(&out.FailedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailedPodInfo.
+func (in *FailedPodInfo) DeepCopy() *FailedPodInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(FailedPodInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitOpsPauseStatus) DeepCopyInto(out *GitOpsPauseStatus) {
+	*out = *in
+	if in.NamespaceAnnotations != nil {
+		in, out := &in.NamespaceAnnotations, &out.NamespaceAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StatefulSetAnnotations != nil {
+		in, out := &in.StatefulSetAnnotations, &out.StatefulSetAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsPauseStatus.
+func (in *GitOpsPauseStatus) DeepCopy() *GitOpsPauseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GitOpsPauseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HistoryEntry) DeepCopyInto(out *HistoryEntry) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HistoryEntry.
+func (in *HistoryEntry) DeepCopy() *HistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(HistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePrePullConfig) DeepCopyInto(out *ImagePrePullConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePrePullConfig.
+func (in *ImagePrePullConfig) DeepCopy() *ImagePrePullConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePrePullConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImpersonationConfig) DeepCopyInto(out *ImpersonationConfig) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImpersonationConfig.
+func (in *ImpersonationConfig) DeepCopy() *ImpersonationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ImpersonationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataPassthroughConfig) DeepCopyInto(out *MetadataPassthroughConfig) {
+	*out = *in
+	if in.AnnotationPrefixes != nil {
+		in, out := &in.AnnotationPrefixes, &out.AnnotationPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelPrefixes != nil {
+		in, out := &in.LabelPrefixes, &out.LabelPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataPassthroughConfig.
+func (in *MetadataPassthroughConfig) DeepCopy() *MetadataPassthroughConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataPassthroughConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigratedPodInfo) DeepCopyInto(out *MigratedPodInfo) {
+	*out = *in
+	in.MigratedAt.DeepCopyInto(&out.MigratedAt)
+	if in.StoppedAt != nil {
+		in, out := &in.StoppedAt, &out.StoppedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigratedPodInfo.
+func (in *MigratedPodInfo) DeepCopy() *MigratedPodInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(MigratedPodInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigratedJobInfo) DeepCopyInto(out *MigratedJobInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigratedJobInfo.
+func (in *MigratedJobInfo) DeepCopy() *MigratedJobInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(MigratedJobInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationApproval) DeepCopyInto(out *MigrationApproval) {
+	*out = *in
+	in.ApprovedTime.DeepCopyInto(&out.ApprovedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationApproval.
+func (in *MigrationApproval) DeepCopy() *MigrationApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationAssessment) DeepCopyInto(out *MigrationAssessment) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationAssessment.
+func (in *MigrationAssessment) DeepCopy() *MigrationAssessment {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationAssessment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationAssessment) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationAssessmentList) DeepCopyInto(out *MigrationAssessmentList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MigrationAssessment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationAssessmentList.
+func (in *MigrationAssessmentList) DeepCopy() *MigrationAssessmentList {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationAssessmentList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationAssessmentList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationAssessmentSpec) DeepCopyInto(out *MigrationAssessmentSpec) {
+	*out = *in
+	in.SourceCluster.DeepCopyInto(&out.SourceCluster)
+	if in.DestCluster != nil {
+		in, out := &in.DestCluster, &out.DestCluster
+		*out = new(ContextRef)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationAssessmentSpec.
+func (in *MigrationAssessmentSpec) DeepCopy() *MigrationAssessmentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationAssessmentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationAssessmentStatus) DeepCopyInto(out *MigrationAssessmentStatus) {
+	*out = *in
+	out.Summary = in.Summary
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]StatefulSetAssessment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationAssessmentStatus.
+func (in *MigrationAssessmentStatus) DeepCopy() *MigrationAssessmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationAssessmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationPolicy) DeepCopyInto(out *MigrationPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPolicy.
+func (in *MigrationPolicy) DeepCopy() *MigrationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationPolicyList) DeepCopyInto(out *MigrationPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MigrationPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPolicyList.
+func (in *MigrationPolicyList) DeepCopy() *MigrationPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigrationPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationPolicySpec) DeepCopyInto(out *MigrationPolicySpec) {
+	*out = *in
+	if in.MigrationNamespaces != nil {
+		in, out := &in.MigrationNamespaces, &out.MigrationNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Source.DeepCopyInto(&out.Source)
+	in.Destination.DeepCopyInto(&out.Destination)
+	if in.DestinationLimits != nil {
+		in, out := &in.DestinationLimits, &out.DestinationLimits
+		*out = make([]DestinationLimit, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPolicySpec.
+func (in *MigrationPolicySpec) DeepCopy() *MigrationPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverridesConfig) DeepCopyInto(out *OverridesConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverridesConfig.
+func (in *OverridesConfig) DeepCopy() *OverridesConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OverridesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PauseGitOpsConfig) DeepCopyInto(out *PauseGitOpsConfig) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PauseGitOpsConfig.
+func (in *PauseGitOpsConfig) DeepCopy() *PauseGitOpsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PauseGitOpsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatternList) DeepCopyInto(out *PatternList) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatternList.
+func (in *PatternList) DeepCopy() *PatternList {
+	if in == nil {
+		return nil
+	}
+	out := new(PatternList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodOrderConfig) DeepCopyInto(out *PodOrderConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodOrderConfig.
+func (in *PodOrderConfig) DeepCopy() *PodOrderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PodOrderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreFlightResult) DeepCopyInto(out *PreFlightResult) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NextCheck != nil {
+		in, out := &in.NextCheck, &out.NextCheck
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreFlightResult.
+func (in *PreFlightResult) DeepCopy() *PreFlightResult {
+	if in == nil {
+		return nil
+	}
+	out := new(PreFlightResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuiesceConfig) DeepCopyInto(out *QuiesceConfig) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuiesceConfig.
+func (in *QuiesceConfig) DeepCopy() *QuiesceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(QuiesceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitConfig) DeepCopyInto(out *RateLimitConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitConfig.
+func (in *RateLimitConfig) DeepCopy() *RateLimitConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReaderIdentity) DeepCopyInto(out *ReaderIdentity) {
+	*out = *in
+	if in.TokenSecretRef != nil {
+		in, out := &in.TokenSecretRef, &out.TokenSecretRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+	if in.Impersonate != nil {
+		in, out := &in.Impersonate, &out.Impersonate
+		*out = new(ImpersonationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReaderIdentity.
+func (in *ReaderIdentity) DeepCopy() *ReaderIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(ReaderIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleConfig) DeepCopyInto(out *ScheduleConfig) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.RevalidateBefore != nil {
+		in, out := &in.RevalidateBefore, &out.RevalidateBefore
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.EndTime != nil {
+		in, out := &in.EndTime, &out.EndTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleConfig.
+func (in *ScheduleConfig) DeepCopy() *ScheduleConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRef.
+func (in *SecretKeyRef) DeepCopy() *SecretKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetAssessment) DeepCopyInto(out *StatefulSetAssessment) {
+	*out = *in
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetAssessment.
+func (in *StatefulSetAssessment) DeepCopy() *StatefulSetAssessment {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetAssessment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetMigration) DeepCopyInto(out *StatefulSetMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigration.
+func (in *StatefulSetMigration) DeepCopy() *StatefulSetMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StatefulSetMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetMigrationList) DeepCopyInto(out *StatefulSetMigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StatefulSetMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigrationList.
+func (in *StatefulSetMigrationList) DeepCopy() *StatefulSetMigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetMigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StatefulSetMigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetMigrationSpec) DeepCopyInto(out *StatefulSetMigrationSpec) {
+	*out = *in
+	in.SourceCluster.DeepCopyInto(&out.SourceCluster)
+	in.DestCluster.DeepCopyInto(&out.DestCluster)
+	if in.CreateDestNamespace != nil {
+		in, out := &in.CreateDestNamespace, &out.CreateDestNamespace
+		*out = new(DestNamespaceTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(OverridesConfig)
+		**out = **in
+	}
+	if in.StorageClassMapping != nil {
+		in, out := &in.StorageClassMapping, &out.StorageClassMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MetadataPassthrough != nil {
+		in, out := &in.MetadataPassthrough, &out.MetadataPassthrough
+		*out = new(MetadataPassthroughConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeDetachTimeout != nil {
+		in, out := &in.VolumeDetachTimeout, &out.VolumeDetachTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.VolumePolicy != nil {
+		in, out := &in.VolumePolicy, &out.VolumePolicy
+		*out = make([]VolumePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodReadyTimeout != nil {
+		in, out := &in.PodReadyTimeout, &out.PodReadyTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.AWSConfig != nil {
+		in, out := &in.AWSConfig, &out.AWSConfig
+		*out = new(AWSConfig)
+		**out = **in
+	}
+	if in.StrategyFallback != nil {
+		in, out := &in.StrategyFallback, &out.StrategyFallback
+		*out = new(StrategyFallback)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DestAWS != nil {
+		in, out := &in.DestAWS, &out.DestAWS
+		*out = new(DestAWSConfig)
+		**out = **in
+	}
+	if in.FreezeSettleDelay != nil {
+		in, out := &in.FreezeSettleDelay, &out.FreezeSettleDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.PostMigrationWatch != nil {
+		in, out := &in.PostMigrationWatch, &out.PostMigrationWatch
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Velero != nil {
+		in, out := &in.Velero, &out.Velero
+		*out = new(VeleroConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cleanup != nil {
+		in, out := &in.Cleanup, &out.Cleanup
+		*out = new(CleanupConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quiesce != nil {
+		in, out := &in.Quiesce, &out.Quiesce
+		*out = new(QuiesceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BackupRetag != nil {
+		in, out := &in.BackupRetag, &out.BackupRetag
+		*out = new(BackupRetagConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodOrder != nil {
+		in, out := &in.PodOrder, &out.PodOrder
+		*out = new(PodOrderConfig)
+		**out = **in
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ScheduleConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ManualGates != nil {
+		in, out := &in.ManualGates, &out.ManualGates
+		*out = make([]ManualGate, len(*in))
+		copy(*out, *in)
+	}
+	if in.PauseGitOps != nil {
+		in, out := &in.PauseGitOps, &out.PauseGitOps
+		*out = new(PauseGitOpsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeInspection != nil {
+		in, out := &in.VolumeInspection, &out.VolumeInspection
+		*out = new(VolumeInspectionConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNSCutover != nil {
+		in, out := &in.DNSCutover, &out.DNSCutover
+		*out = new(DNSCutoverConfig)
+		**out = **in
+	}
+	if in.ConnectivityProbe != nil {
+		in, out := &in.ConnectivityProbe, &out.ConnectivityProbe
+		*out = new(ConnectivityProbeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BandwidthProbe != nil {
+		in, out := &in.BandwidthProbe, &out.BandwidthProbe
+		*out = new(BandwidthProbeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePrePull != nil {
+		in, out := &in.ImagePrePull, &out.ImagePrePull
+		*out = new(ImagePrePullConfig)
+		**out = **in
+	}
+	if in.CapacityWait != nil {
+		in, out := &in.CapacityWait, &out.CapacityWait
+		*out = new(CapacityWaitConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DestinationPlacement != nil {
+		in, out := &in.DestinationPlacement, &out.DestinationPlacement
+		*out = new(DestinationPlacement)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigrationSpec.
+func (in *StatefulSetMigrationSpec) DeepCopy() *StatefulSetMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetMigrationStatus) DeepCopyInto(out *StatefulSetMigrationStatus) {
+	*out = *in
+	if in.PodOrder != nil {
+		in, out := &in.PodOrder, &out.PodOrder
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.VolumeWaits != nil {
+		in, out := &in.VolumeWaits, &out.VolumeWaits
+		*out = make([]VolumeWait, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeFallbacks != nil {
+		in, out := &in.VolumeFallbacks, &out.VolumeFallbacks
+		*out = make([]VolumeFallback, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ZoneIntersection != nil {
+		in, out := &in.ZoneIntersection, &out.ZoneIntersection
+		*out = new(ZoneIntersection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeStrategies != nil {
+		in, out := &in.VolumeStrategies, &out.VolumeStrategies
+		*out = make([]VolumeStrategyDecision, len(*in))
+		copy(*out, *in)
+	}
+	if in.MigratedPods != nil {
+		in, out := &in.MigratedPods, &out.MigratedPods
+		*out = make([]MigratedPodInfo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TotalStorageMigrated != nil {
+		in, out := &in.TotalStorageMigrated, &out.TotalStorageMigrated
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.FailedPods != nil {
+		in, out := &in.FailedPods, &out.FailedPods
+		*out = make([]FailedPodInfo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UnboundPVCs != nil {
+		in, out := &in.UnboundPVCs, &out.UnboundPVCs
+		*out = make([]UnboundPVCInfo, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.VolumeProtectedUntil != nil {
+		in, out := &in.VolumeProtectedUntil, &out.VolumeProtectedUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.FrozenTime != nil {
+		in, out := &in.FrozenTime, &out.FrozenTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PreservedPVs != nil {
+		in, out := &in.PreservedPVs, &out.PreservedPVs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OrphanedPods != nil {
+		in, out := &in.OrphanedPods, &out.OrphanedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CapacityWait != nil {
+		in, out := &in.CapacityWait, &out.CapacityWait
+		*out = new(CapacityWaitStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = make([]MigratedJobInfo, len(*in))
+		copy(*out, *in)
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]HistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AppliedSpec != nil {
+		in, out := &in.AppliedSpec, &out.AppliedSpec
+		*out = new(StatefulSetMigrationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Velero != nil {
+		in, out := &in.Velero, &out.Velero
+		*out = new(VeleroStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitOpsPause != nil {
+		in, out := &in.GitOpsPause, &out.GitOpsPause
+		*out = new(GitOpsPauseStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreFlight != nil {
+		in, out := &in.PreFlight, &out.PreFlight
+		*out = new(PreFlightResult)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BandwidthProbe != nil {
+		in, out := &in.BandwidthProbe, &out.BandwidthProbe
+		*out = new(BandwidthProbeResult)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AcknowledgedGates != nil {
+		in, out := &in.AcknowledgedGates, &out.AcknowledgedGates
+		*out = make([]ManualGate, len(*in))
+		copy(*out, *in)
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(MigrationApproval)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigrationStatus.
+func (in *StatefulSetMigrationStatus) DeepCopy() *StatefulSetMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StrategyFallback) DeepCopyInto(out *StrategyFallback) {
+	*out = *in
+	if in.On != nil {
+		in, out := &in.On, &out.On
+		*out = make([]FallbackTrigger, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContextRef.
-func (in *ContextRef) DeepCopy() *ContextRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StrategyFallback.
+func (in *StrategyFallback) DeepCopy() *StrategyFallback {
 	if in == nil {
 		return nil
 	}
-	out := new(ContextRef)
+	out := new(StrategyFallback)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigratedPodInfo) DeepCopyInto(out *MigratedPodInfo) {
+func (in *TargetRules) DeepCopyInto(out *TargetRules) {
 	*out = *in
-	in.MigratedAt.DeepCopyInto(&out.MigratedAt)
+	in.Clusters.DeepCopyInto(&out.Clusters)
+	in.Namespaces.DeepCopyInto(&out.Namespaces)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigratedPodInfo.
-func (in *MigratedPodInfo) DeepCopy() *MigratedPodInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetRules.
+func (in *TargetRules) DeepCopy() *TargetRules {
 	if in == nil {
 		return nil
 	}
-	out := new(MigratedPodInfo)
+	out := new(TargetRules)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StatefulSetMigration) DeepCopyInto(out *StatefulSetMigration) {
+func (in *UnboundPVCInfo) DeepCopyInto(out *UnboundPVCInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnboundPVCInfo.
+func (in *UnboundPVCInfo) DeepCopy() *UnboundPVCInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(UnboundPVCInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VeleroConfig) DeepCopyInto(out *VeleroConfig) {
+	*out = *in
+	if in.IncludedResources != nil {
+		in, out := &in.IncludedResources, &out.IncludedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedResources != nil {
+		in, out := &in.ExcludedResources, &out.ExcludedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VeleroConfig.
+func (in *VeleroConfig) DeepCopy() *VeleroConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VeleroConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VeleroStatus) DeepCopyInto(out *VeleroStatus) {
+	*out = *in
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VeleroStatus.
+func (in *VeleroStatus) DeepCopy() *VeleroStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VeleroStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeFallback) DeepCopyInto(out *VolumeFallback) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeFallback.
+func (in *VolumeFallback) DeepCopy() *VolumeFallback {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeFallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeInspectionConfig) DeepCopyInto(out *VolumeInspectionConfig) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeInspectionConfig.
+func (in *VolumeInspectionConfig) DeepCopy() *VolumeInspectionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeInspectionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeMigration) DeepCopyInto(out *VolumeMigration) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -49,18 +1485,18 @@ func (in *StatefulSetMigration) DeepCopyInto(out *StatefulSetMigration) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigration.
-func (in *StatefulSetMigration) DeepCopy() *StatefulSetMigration {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeMigration.
+func (in *VolumeMigration) DeepCopy() *VolumeMigration {
 	if in == nil {
 		return nil
 	}
-	out := new(StatefulSetMigration)
+	out := new(VolumeMigration)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *StatefulSetMigration) DeepCopyObject() runtime.Object {
+func (in *VolumeMigration) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -68,31 +1504,31 @@ func (in *StatefulSetMigration) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StatefulSetMigrationList) DeepCopyInto(out *StatefulSetMigrationList) {
+func (in *VolumeMigrationList) DeepCopyInto(out *VolumeMigrationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]StatefulSetMigration, len(*in))
+		*out = make([]VolumeMigration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigrationList.
-func (in *StatefulSetMigrationList) DeepCopy() *StatefulSetMigrationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeMigrationList.
+func (in *VolumeMigrationList) DeepCopy() *VolumeMigrationList {
 	if in == nil {
 		return nil
 	}
-	out := new(StatefulSetMigrationList)
+	out := new(VolumeMigrationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *StatefulSetMigrationList) DeepCopyObject() runtime.Object {
+func (in *VolumeMigrationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -100,10 +1536,10 @@ func (in *StatefulSetMigrationList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StatefulSetMigrationSpec) DeepCopyInto(out *StatefulSetMigrationSpec) {
+func (in *VolumeMigrationSpec) DeepCopyInto(out *VolumeMigrationSpec) {
 	*out = *in
-	out.SourceCluster = in.SourceCluster
-	out.DestCluster = in.DestCluster
+	in.SourceCluster.DeepCopyInto(&out.SourceCluster)
+	in.DestCluster.DeepCopyInto(&out.DestCluster)
 	if in.StorageClassMapping != nil {
 		in, out := &in.StorageClassMapping, &out.StorageClassMapping
 		*out = make(map[string]string, len(*in))
@@ -111,37 +1547,48 @@ func (in *StatefulSetMigrationSpec) DeepCopyInto(out *StatefulSetMigrationSpec)
 			(*out)[key] = val
 		}
 	}
+	if in.MetadataPassthrough != nil {
+		in, out := &in.MetadataPassthrough, &out.MetadataPassthrough
+		*out = new(MetadataPassthroughConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.VolumeDetachTimeout != nil {
 		in, out := &in.VolumeDetachTimeout, &out.VolumeDetachTimeout
 		*out = new(v1.Duration)
 		**out = **in
 	}
-	if in.PodReadyTimeout != nil {
-		in, out := &in.PodReadyTimeout, &out.PodReadyTimeout
-		*out = new(v1.Duration)
+	if in.AWSConfig != nil {
+		in, out := &in.AWSConfig, &out.AWSConfig
+		*out = new(AWSConfig)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigrationSpec.
-func (in *StatefulSetMigrationSpec) DeepCopy() *StatefulSetMigrationSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeMigrationSpec.
+func (in *VolumeMigrationSpec) DeepCopy() *VolumeMigrationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(StatefulSetMigrationSpec)
+	out := new(VolumeMigrationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StatefulSetMigrationStatus) DeepCopyInto(out *StatefulSetMigrationStatus) {
+func (in *VolumeMigrationStatus) DeepCopyInto(out *VolumeMigrationStatus) {
 	*out = *in
-	if in.MigratedPods != nil {
-		in, out := &in.MigratedPods, &out.MigratedPods
-		*out = make([]MigratedPodInfo, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.StoppedPods != nil {
+		in, out := &in.StoppedPods, &out.StoppedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -150,27 +1597,99 @@ func (in *StatefulSetMigrationStatus) DeepCopyInto(out *StatefulSetMigrationStat
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.StartTime != nil {
-		in, out := &in.StartTime, &out.StartTime
-		*out = (*in).DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeMigrationStatus.
+func (in *VolumeMigrationStatus) DeepCopy() *VolumeMigrationStatus {
+	if in == nil {
+		return nil
 	}
-	if in.CompletionTime != nil {
-		in, out := &in.CompletionTime, &out.CompletionTime
+	out := new(VolumeMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumePolicy) DeepCopyInto(out *VolumePolicy) {
+	*out = *in
+	if in.VolumeDetachTimeout != nil {
+		in, out := &in.VolumeDetachTimeout, &out.VolumeDetachTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumePolicy.
+func (in *VolumePolicy) DeepCopy() *VolumePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeStrategyDecision) DeepCopyInto(out *VolumeStrategyDecision) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeStrategyDecision.
+func (in *VolumeStrategyDecision) DeepCopy() *VolumeStrategyDecision {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeStrategyDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeWait) DeepCopyInto(out *VolumeWait) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.LastProbeTime != nil {
+		in, out := &in.LastProbeTime, &out.LastProbeTime
 		*out = (*in).DeepCopy()
 	}
-	if in.PreservedPVs != nil {
-		in, out := &in.PreservedPVs, &out.PreservedPVs
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeWait.
+func (in *VolumeWait) DeepCopy() *VolumeWait {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeWait)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZoneIntersection) DeepCopyInto(out *ZoneIntersection) {
+	*out = *in
+	if in.VolumeZones != nil {
+		in, out := &in.VolumeZones, &out.VolumeZones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeZones != nil {
+		in, out := &in.NodeZones, &out.NodeZones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SharedZones != nil {
+		in, out := &in.SharedZones, &out.SharedZones
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetMigrationStatus.
-func (in *StatefulSetMigrationStatus) DeepCopy() *StatefulSetMigrationStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZoneIntersection.
+func (in *ZoneIntersection) DeepCopy() *ZoneIntersection {
 	if in == nil {
 		return nil
 	}
-	out := new(StatefulSetMigrationStatus)
+	out := new(ZoneIntersection)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -4,20 +4,32 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/leaderelection"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
 	"github.com/aqua-io/aqua-service-controller/internal/aws"
 	"github.com/aqua-io/aqua-service-controller/internal/controller"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
 	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/internal/preflight"
+	"github.com/aqua-io/aqua-service-controller/internal/telemetry"
+	"github.com/aqua-io/aqua-service-controller/internal/version"
 )
 
 var (
@@ -28,20 +40,161 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(migrationv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(metrics.Register(ctrlmetrics.Registry))
 }
 
 func main() {
 	var metricsAddr string
 	var probeAddr string
+	var pprofAddr string
 	var enableLeaderElection bool
+	var leaseDuration time.Duration
+	var renewDeadline time.Duration
+	var retryPeriod time.Duration
 	var awsRegion string
+	var awsEndpoint string
+	var awsUseFIPS bool
+	var awsProfile string
+	var awsCredentialSource string
+	var awsCredentialsSecret string
+	var remoteQPS float64
+	var remoteBurst int
+	var remoteUserAgent string
+	var remoteCaches bool
+	var guardNamespace string
+	var volumeLockID string
+	var volumeLockTTL time.Duration
+	var reportBucket string
+	var reportPrefix string
+	var archiveBucket string
+	var archivePrefix string
+	var s3Encryption string
+	var s3KMSKeyID string
+	var ebsLimits aws.ConcurrencyLimits
+	var readOnly bool
+	var maxConcurrentReconciles int
+	var pauseConfigMap string
+	var telemetryEndpoint string
+	var telemetryInterval time.Duration
+	var eventBridgeBus string
+	var cloudWatchNamespace string
+	var preFlightChecksConfig string
+	var eventThrottleWindow time.Duration
+	var allowedClusters, deniedClusters string
+	var allowedNamespaces, deniedNamespaces string
+	var enableWebhooks bool
+	var approvalPublicKeys string
+	var destVolumeProtection time.Duration
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&pprofAddr, "pprof-bind-address", "",
+		"The address the pprof endpoint binds to, e.g. 127.0.0.1:6060. Disabled when empty.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
-	flag.StringVar(&awsRegion, "aws-region", "", "AWS region for EBS operations (defaults to AWS_REGION env var)")
+	flag.DurationVar(&leaseDuration, "leader-elect-lease-duration", 15*time.Second,
+		"How long standby replicas wait after the leader's last renewal before taking over. "+
+			"This bounds how long reconciliation stalls when the leader's node is lost.")
+	flag.DurationVar(&renewDeadline, "leader-elect-renew-deadline", 10*time.Second,
+		"How long the leader keeps retrying to renew its lease before giving up leadership. "+
+			"Must be less than --leader-elect-lease-duration.")
+	flag.DurationVar(&retryPeriod, "leader-elect-retry-period", 2*time.Second,
+		"How often candidates try to acquire or renew the lease.")
+	flag.StringVar(&awsRegion, "aws-region", "",
+		"Default AWS region for EBS operations (defaults to AWS_REGION env var). "+
+			"Migrations in other regions set spec.awsConfig.region.")
+	flag.StringVar(&awsEndpoint, "aws-endpoint", "",
+		"EC2 endpoint URL to use instead of AWS's, e.g. a LocalStack endpoint for local development.")
+	flag.BoolVar(&awsUseFIPS, "aws-use-fips-endpoint", false,
+		"Use the FIPS endpoints of the AWS partition, e.g. in GovCloud. Cannot be combined with --aws-endpoint.")
+	flag.StringVar(&awsProfile, "aws-profile", "", "Shared config profile to load AWS settings and credentials from.")
+	flag.StringVar(&awsCredentialSource, "aws-credential-source", string(aws.CredentialSourceDefault),
+		"Where AWS credentials come from: default (the SDK chain), irsa, pod-identity, static, profile or imds. "+
+			"Any source but default is checked at startup, and the controller exits if it cannot produce credentials.")
+	flag.StringVar(&awsCredentialsSecret, "aws-credentials-secret", "",
+		"<namespace>/<name> of a secret with aws_access_key_id, aws_secret_access_key and optionally "+
+			"aws_session_token keys, for --aws-credential-source=static. Read once at startup.")
+	flag.Float64Var(&remoteQPS, "remote-qps", float64(multicluster.DefaultQPS),
+		"Client-side QPS limit for requests to source and destination clusters.")
+	flag.IntVar(&remoteBurst, "remote-burst", multicluster.DefaultBurst,
+		"Client-side burst limit for requests to source and destination clusters.")
+	flag.StringVar(&remoteUserAgent, "remote-user-agent", multicluster.DefaultUserAgent,
+		"User agent reported to source and destination API servers.")
+	flag.BoolVar(&remoteCaches, "remote-informer-cache", false,
+		"Start informer caches on source and destination clusters while a migration runs, "+
+			"serving wait loops from watches instead of polling the remote API servers.")
+	flag.StringVar(&guardNamespace, "guard-namespace", os.Getenv("POD_NAMESPACE"),
+		"Namespace for the leases that stop two migrations of the same StatefulSet running at once "+
+			"(defaults to POD_NAMESPACE, then "+controller.DefaultGuardNamespace+").")
+	flag.StringVar(&volumeLockID, "volume-lock-id", "",
+		"Identifier for this controller in EBS volume lock tags. When set, volumes are locked with the "+
+			aws.VolumeLockTagKey+" tag while they move, so controllers in other management clusters cannot race for them.")
+	flag.DurationVar(&volumeLockTTL, "volume-lock-ttl", aws.DefaultVolumeLockTTL,
+		"How long an EBS volume lock is honored before another controller may take it over.")
+	flag.StringVar(&reportBucket, "report-s3-bucket", "",
+		"S3 bucket to upload migration reports to when a migration completes or fails, in addition to the report ConfigMap.")
+	flag.StringVar(&reportPrefix, "report-s3-prefix", "",
+		"Prefix for the S3 keys of uploaded migration reports, e.g. migration-reports/.")
+	flag.StringVar(&archiveBucket, "archive-s3-bucket", "",
+		"S3 bucket to archive each migration's source StatefulSet, PVC and PV manifests and per-pod checkpoints to.")
+	flag.StringVar(&archivePrefix, "archive-s3-prefix", "",
+		"Prefix for the S3 keys of archived migration state, e.g. migration-archive/.")
+	flag.StringVar(&s3Encryption, "s3-sse", "AES256",
+		"Server-side encryption for uploaded reports and archives: AES256 or aws:kms.")
+	flag.StringVar(&s3KMSKeyID, "s3-sse-kms-key-id", "",
+		"KMS key for aws:kms server-side encryption (defaults to the bucket's key).")
+	flag.IntVar(&ebsLimits.Snapshots, "max-concurrent-snapshots", aws.DefaultMaxConcurrentSnapshots,
+		"Maximum snapshots and snapshot copies in progress across all migrations; 0 is unlimited.")
+	flag.IntVar(&ebsLimits.Detaches, "max-concurrent-detaches", 0,
+		"Maximum volume detaches in progress across all migrations; 0 is unlimited.")
+	flag.IntVar(&ebsLimits.Describes, "max-concurrent-describes", 0,
+		"Maximum EC2 Describe calls in flight across all migrations; 0 is unlimited.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"StatefulSetMigrations reconciled at once. Raise it when many migrations run together and their "+
+			"reconciles queue behind each other; see make test-load.")
+	flag.BoolVar(&readOnly, "read-only", false,
+		"Hold every migration before any step that changes the clusters or AWS, e.g. during an incident. "+
+			"Status is still reported and pre-flight checks still run.")
+	flag.StringVar(&pauseConfigMap, "pause-configmap", controller.DefaultPauseConfigMap,
+		"ConfigMap in the guard namespace that pauses every running migration and assessment while its "+
+			controller.PauseKey+" key is \"true\", without a restart. Empty disables the switch.")
+	flag.StringVar(&telemetryEndpoint, "telemetry-endpoint", "",
+		"Opt in to sending anonymized statistics of the finished migrations (results, volume strategies, pod counts, "+
+			"durations, failed steps), aggregated over --telemetry-interval, as JSON to this http(s) URL. Nothing is sent when empty.")
+	flag.DurationVar(&telemetryInterval, "telemetry-interval", telemetry.DefaultInterval,
+		"How often the aggregated telemetry is sent; an interval with no finished migration sends nothing.")
+	flag.StringVar(&eventBridgeBus, "eventbridge-bus", "",
+		"Put an event on this EventBridge event bus (name or ARN, e.g. \"default\") for each phase change and "+
+			"Kubernetes event of a migration, with source "+controller.AWSEventSource+". Nothing is put when empty.")
+	flag.StringVar(&cloudWatchNamespace, "cloudwatch-namespace", "",
+		"Put the outcome, duration, downtime and size of each finished migration in this CloudWatch metric "+
+			"namespace. Nothing is put when empty.")
+	flag.StringVar(&preFlightChecksConfig, "preflight-checks-config", "",
+		"YAML file of external pre-flight checks (commands or HTTP endpoints) every migration must pass "+
+			"in addition to the built-in checks.")
+	flag.DurationVar(&eventThrottleWindow, "event-throttle-window", controller.DefaultEventThrottleWindow,
+		"How long an event on a migration suppresses similar ones, which differ only in numbers or durations; "+
+			"the next one after it counts those dropped. 0 records every event.")
+	flag.StringVar(&allowedClusters, "allowed-clusters", "",
+		"Comma-separated API server URL patterns, where * matches anything, of the only clusters migrations may "+
+			"move from or to, in addition to any MigrationPolicies. Any cluster when empty.")
+	flag.StringVar(&deniedClusters, "denied-clusters", "",
+		"Comma-separated API server URL patterns of clusters migrations may not move from or to.")
+	flag.StringVar(&allowedNamespaces, "allowed-namespaces", "",
+		"Comma-separated patterns of the only namespaces migrations may move from or to. Any namespace when empty.")
+	flag.StringVar(&deniedNamespaces, "denied-namespaces", "",
+		"Comma-separated patterns of namespaces migrations may not move from or to, e.g. kube-*.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the validating webhook that rejects migrations and assessments whose targets a MigrationPolicy "+
+			"or the target flags do not allow. Needs the serving certificate of config/webhook.")
+	flag.StringVar(&approvalPublicKeys, "approval-public-keys", "",
+		"PEM public key file, or directory of them such as a mounted Secret, whose Ed25519, ECDSA or RSA keys "+
+			"sign migration approvals. When set, every migration waits before freezing its source until it is "+
+			"annotated with a signature that verifies. Read once at startup.")
+	flag.DurationVar(&destVolumeProtection, "dest-volume-protection", 0,
+		"How long the destination PVs and PVCs of a finished migration keep the "+controller.FinalizerVolumeProtection+
+			" finalizer, so deleting the destination namespace right after cutover cannot release them. 0 disables it.")
 
 	opts := zap.Options{
 		Development: true,
@@ -51,6 +204,13 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	// client-go rejects these at startup, with a less helpful message
+	if enableLeaderElection && (leaseDuration <= renewDeadline || float64(renewDeadline) <= leaderelection.JitterFactor*float64(retryPeriod)) {
+		setupLog.Error(fmt.Errorf("need lease duration > renew deadline > %.1f x retry period", leaderelection.JitterFactor),
+			"invalid leader election timing", "leaseDuration", leaseDuration, "renewDeadline", renewDeadline, "retryPeriod", retryPeriod)
+		os.Exit(1)
+	}
+
 	// Determine AWS region
 	if awsRegion == "" {
 		awsRegion = os.Getenv("AWS_REGION")
@@ -65,51 +225,240 @@ func main() {
 			BindAddress: metricsAddr,
 		},
 		HealthProbeBindAddress: probeAddr,
+		PprofBindAddress:       pprofAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "aqua-service-controller.aqua.io",
+		LeaseDuration:          &leaseDuration,
+		RenewDeadline:          &renewDeadline,
+		RetryPeriod:            &retryPeriod,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	// Create AWS EBS client
+	// Create the AWS EBS client; clients for other regions are derived from it
 	ctx := context.Background()
+	credentialSource, err := aws.ParseCredentialSource(awsCredentialSource)
+	if err != nil {
+		setupLog.Error(err, "invalid AWS credential source")
+		os.Exit(1)
+	}
+	var staticCredentials *aws.StaticCredentials
+	if (credentialSource == aws.CredentialSourceStatic) != (awsCredentialsSecret != "") {
+		setupLog.Error(fmt.Errorf("--aws-credentials-secret is required by, and only used with, --aws-credential-source=static"),
+			"invalid AWS credential source")
+		os.Exit(1)
+	}
+	if awsCredentialsSecret != "" {
+		if staticCredentials, err = loadStaticCredentials(ctx, mgr.GetAPIReader(), awsCredentialsSecret); err != nil {
+			setupLog.Error(err, "unable to load AWS credentials")
+			os.Exit(1)
+		}
+	}
 	ebsClient, err := aws.NewEBSClient(ctx, aws.EBSClientConfig{
-		Region: awsRegion,
+		Region:            awsRegion,
+		Profile:           awsProfile,
+		Endpoint:          awsEndpoint,
+		UseFIPSEndpoint:   awsUseFIPS,
+		CredentialSource:  credentialSource,
+		StaticCredentials: staticCredentials,
+		Limits:            ebsLimits,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to create EBS client")
 		os.Exit(1)
 	}
+	setupLog.Info("Using AWS credentials", "source", credentialSource)
 
 	// Create multi-cluster client manager
-	clientManager := multicluster.NewClientManager(scheme, mgr.GetClient())
+	clientManager := multicluster.NewClientManagerWithSettings(scheme, mgr.GetClient(), multicluster.ClientSettings{
+		QPS:       float32(remoteQPS),
+		Burst:     remoteBurst,
+		UserAgent: remoteUserAgent,
+		Observer:  metrics.RemoteAPIObserver{},
+	})
+	if err := metrics.RegisterClientStats(ctrlmetrics.Registry, clientManager.Stats); err != nil {
+		setupLog.Error(err, "unable to register client metrics")
+		os.Exit(1)
+	}
+
+	var telemetryReporter *telemetry.Reporter
+	if telemetryEndpoint != "" {
+		if telemetryReporter, err = telemetry.NewReporter(telemetryEndpoint, telemetryInterval); err != nil {
+			setupLog.Error(err, "invalid telemetry endpoint")
+			os.Exit(1)
+		}
+		if err := mgr.Add(telemetryReporter); err != nil {
+			setupLog.Error(err, "unable to set up telemetry")
+			os.Exit(1)
+		}
+		setupLog.Info("Sending anonymized migration statistics", "endpoint", telemetryEndpoint, "interval", telemetryInterval)
+	}
+
+	var awsEvents *controller.AWSEvents
+	if eventBridgeBus != "" || cloudWatchNamespace != "" {
+		awsEvents = controller.NewAWSEvents(ebsClient, eventBridgeBus, cloudWatchNamespace)
+		if err := mgr.Add(awsEvents); err != nil {
+			setupLog.Error(err, "unable to set up AWS event publishing")
+			os.Exit(1)
+		}
+		setupLog.Info("Publishing migration events to AWS", "eventBus", eventBridgeBus, "metricNamespace", cloudWatchNamespace)
+	}
+
+	var preFlightChecks []controller.PreFlightCheck
+	if preFlightChecksConfig != "" {
+		checks, err := preflight.LoadConfig(preFlightChecksConfig)
+		if err != nil {
+			setupLog.Error(err, "invalid pre-flight checks config")
+			os.Exit(1)
+		}
+		preFlightChecks = controller.ExternalPreFlightChecks(checks)
+		setupLog.Info("Running external pre-flight checks", "count", len(checks))
+	}
+
+	// The pause switch is read directly, so ConfigMaps need no cluster-wide watch
+	var pause *controller.PauseSwitch
+	if pauseConfigMap != "" {
+		namespace := guardNamespace
+		if namespace == "" {
+			namespace = controller.DefaultGuardNamespace
+		}
+		pause = &controller.PauseSwitch{
+			Reader:    mgr.GetAPIReader(),
+			ConfigMap: types.NamespacedName{Namespace: namespace, Name: pauseConfigMap},
+		}
+		if err := mgr.Add(pause); err != nil {
+			setupLog.Error(err, "unable to set up pause switch")
+			os.Exit(1)
+		}
+		setupLog.Info("Watching pause switch", "configMap", pause.ConfigMap.String())
+	}
+
+	var approvalKeys []controller.ApprovalKey
+	if approvalPublicKeys != "" {
+		if approvalKeys, err = controller.LoadApprovalKeys(approvalPublicKeys); err != nil {
+			setupLog.Error(err, "unable to load approval public keys")
+			os.Exit(1)
+		}
+		for _, key := range approvalKeys {
+			setupLog.Info("Requiring signed approvals", "key", key.Fingerprint)
+		}
+	}
+
+	// Policies are read directly, so one created just before a migration applies to it
+	targetPolicy := &controller.TargetPolicy{
+		Reader: mgr.GetAPIReader(),
+		Rules: migrationv1alpha1.TargetRules{
+			Clusters: migrationv1alpha1.PatternList{
+				Allow: controller.ParsePatterns(allowedClusters),
+				Deny:  controller.ParsePatterns(deniedClusters),
+			},
+			Namespaces: migrationv1alpha1.PatternList{
+				Allow: controller.ParsePatterns(allowedNamespaces),
+				Deny:  controller.ParsePatterns(deniedNamespaces),
+			},
+		},
+	}
 
 	// Set up the reconciler
 	if err = (&controller.StatefulSetMigrationReconciler{
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		ClientManager:           clientManager,
+		EBSClient:               ebsClient,
+		UseRemoteCaches:         remoteCaches,
+		GuardNamespace:          guardNamespace,
+		VolumeLockID:            volumeLockID,
+		VolumeLockTTL:           volumeLockTTL,
+		ReportBucket:            reportBucket,
+		ReportPrefix:            reportPrefix,
+		ArchiveBucket:           archiveBucket,
+		ArchivePrefix:           archivePrefix,
+		S3Encryption:            s3Encryption,
+		S3KMSKeyID:              s3KMSKeyID,
+		DestVolumeProtection:    destVolumeProtection,
+		ReadOnly:                readOnly,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		Pause:                   pause,
+		PreFlightChecks:         preFlightChecks,
+		Recorder:                controller.NewEventThrottle(awsEvents.Recorder(mgr.GetEventRecorderFor("statefulsetmigration-controller")), eventThrottleWindow),
+		Telemetry:               telemetryReporter,
+		AWSEvents:               awsEvents,
+		TargetPolicy:            targetPolicy,
+		ApprovalKeys:            approvalKeys,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "StatefulSetMigration")
+		os.Exit(1)
+	}
+	if err = (&controller.MigrationAssessmentReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ClientManager: clientManager,
+		Pause:         pause,
+		TargetPolicy:  targetPolicy,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MigrationAssessment")
+		os.Exit(1)
+	}
+	if err = (&controller.VolumeMigrationReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
 		ClientManager: clientManager,
 		EBSClient:     ebsClient,
+		VolumeLockID:  volumeLockID,
+		VolumeLockTTL: volumeLockTTL,
+		ReadOnly:      readOnly,
+		Pause:         pause,
+		Recorder:      controller.NewEventThrottle(mgr.GetEventRecorderFor("volumemigration-controller"), eventThrottleWindow),
+		TargetPolicy:  targetPolicy,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "StatefulSetMigration")
+		setupLog.Error(err, "unable to create controller", "controller", "VolumeMigration")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err := (&controller.TargetValidator{Policy: targetPolicy, Migrations: mgr.GetAPIReader()}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "TargetValidator")
+			os.Exit(1)
+		}
+	}
 
 	// Add health checks
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+	// Ready only while the local API server and AWS are reachable, since every
+	// reconcile fails otherwise
+	if err := mgr.AddReadyzCheck("readyz", controller.ReadinessCheck(mgr.GetAPIReader(), ebsClient, nil)); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
 
-	setupLog.Info("starting manager")
+	setupLog.Info("starting manager", "version", version.Get())
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// loadStaticCredentials reads an access key from the secret <namespace>/<name>
+func loadStaticCredentials(ctx context.Context, reader client.Reader, ref string) (*aws.StaticCredentials, error) {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid secret reference %q, want <namespace>/<name>", ref)
+	}
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %w", ref, err)
+	}
+	creds := &aws.StaticCredentials{
+		AccessKeyID:     string(secret.Data["aws_access_key_id"]),
+		SecretAccessKey: string(secret.Data["aws_secret_access_key"]),
+		SessionToken:    string(secret.Data["aws_session_token"]),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("secret %s needs aws_access_key_id and aws_secret_access_key keys", ref)
+	}
+	return creds, nil
+}
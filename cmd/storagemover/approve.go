@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/controller"
+)
+
+// approveCmd signs a started migration's approval payload and annotates the migration with the signature
+func approveCmd() *cobra.Command {
+	var kubeconfig string
+	var namespace string
+	var name string
+	var keyFile string
+	var signature string
+	var payloadOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "approve",
+		Short: "Sign a StatefulSetMigration's approval so the controller freezes its source",
+		Long: `When the controller runs with --approval-public-keys, every migration waits
+before freezing its source until it is annotated with a signature, by one of
+those keys, over its approval payload. The payload names the migration, its
+UID and the hash of the spec it started with (status.specSnapshotHash), so a
+signature approves exactly one migration with exactly that spec.
+
+With --key, the payload is signed with a PEM private key (Ed25519, ECDSA or
+RSA) and the signature is annotated on the migration. To sign elsewhere, such
+as with an HSM, print the payload with --payload, sign it and pass the base64
+signature with --signature. Ed25519 keys sign the payload itself; ECDSA and
+RSA keys sign its SHA-256 digest, as "openssl dgst -sha256 -sign" does.
+
+The payload only exists once the migration has started, which records the
+spec hash.`,
+		Example: `  storagemover approve --migration web-migration -n ops --key approver.pem
+  storagemover approve --migration web-migration -n ops --payload > payload
+  openssl dgst -sha256 -sign approver.pem payload | base64 -w0
+  storagemover approve --migration web-migration -n ops --signature MEUCIQ...`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !payloadOnly && (keyFile == "") == (signature == "") {
+				return fmt.Errorf("exactly one of --key, --signature and --payload is required")
+			}
+
+			ctx := context.Background()
+			c, err := getClient(kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to create client: %w", err)
+			}
+			m := &migrationv1alpha1.StatefulSetMigration{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, m); err != nil {
+				return fmt.Errorf("failed to get migration: %w", err)
+			}
+			if m.Status.SpecSnapshotHash == "" {
+				return fmt.Errorf("migration %s/%s has not started; its spec hash is not recorded yet", namespace, name)
+			}
+			payload := controller.ApprovalPayload(m)
+			if payloadOnly {
+				out.Manifest(payload)
+				return nil
+			}
+
+			spec := m.Status.AppliedSpec
+			if spec == nil {
+				spec = &m.Spec
+			}
+			out.Printf("Approving %s/%s: StatefulSet %s from %s to %s, spec %s\n",
+				namespace, name, spec.StatefulSetName, spec.SourceNamespace, spec.DestNamespace, m.Status.SpecSnapshotHash)
+
+			if keyFile != "" {
+				signer, err := readSigner(keyFile)
+				if err != nil {
+					return err
+				}
+				if signature, err = controller.SignApproval(signer, payload); err != nil {
+					return fmt.Errorf("failed to sign approval: %w", err)
+				}
+			}
+
+			patch := client.MergeFrom(m.DeepCopy())
+			if m.Annotations == nil {
+				m.Annotations = map[string]string{}
+			}
+			m.Annotations[controller.AnnotationApproval] = signature
+			if err := c.Patch(ctx, m, patch); err != nil {
+				return fmt.Errorf("failed to annotate migration: %w", err)
+			}
+			out.Report("approval", fmt.Sprintf("Annotated %s/%s with the approval of spec %s", namespace, name, m.Status.SpecSnapshotHash),
+				"namespace", namespace, "name", name, "specSnapshotHash", m.Status.SpecSnapshotHash)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig of the cluster the controller runs in (default $KUBECONFIG)")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the StatefulSetMigration")
+	cmd.Flags().StringVar(&name, "migration", "", "Name of the StatefulSetMigration")
+	cmd.Flags().StringVar(&keyFile, "key", "", "PEM private key to sign the approval with")
+	cmd.Flags().StringVar(&signature, "signature", "", "Base64 signature of the payload, made elsewhere")
+	cmd.Flags().BoolVar(&payloadOnly, "payload", false, "Print the payload to sign and exit")
+	_ = cmd.MarkFlagRequired("migration")
+	_ = cmd.MarkFlagFilename("kubeconfig")
+	_ = cmd.MarkFlagFilename("key", "pem")
+	cmd.MarkFlagsMutuallyExclusive("key", "signature", "payload")
+
+	return cmd
+}
+
+// readSigner reads a PEM private key: PKCS #8, or SEC 1 EC or PKCS #1 RSA
+func readSigner(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s holds a %s, not a private key", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// supportBundleCmd collects what is needed to debug a stuck or failed migration into a tarball
+func supportBundleCmd() *cobra.Command {
+	var kubeconfig string
+	var namespace string
+	var name string
+	var controllerNamespace string
+	var controllerSelector string
+	var logSince time.Duration
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "support-bundle",
+		Short: "Collect a migration's state, events, logs and volumes into a tarball for a bug report (read-only)",
+		Long: `Collects everything needed to debug a stuck or failed StatefulSetMigration
+into a gzipped tarball:
+
+- the migration, its VolumeMigrations and its report, from the cluster the
+  controller runs in (--kubeconfig)
+- the events on them
+- the controller's logs over --log-since, including the previous container's
+  after a restart
+- the StatefulSet, its pods, PVCs, PVs and VolumeAttachments, and the events
+  on them, in the source and destination clusters, when --source-kubeconfig
+  and --dest-kubeconfig are set
+- the EC2 description and status checks of every volume the migration knows of
+
+Collection is best effort: anything that cannot be read is listed in the
+bundle's bundle.json and the rest is still collected. Secrets are never
+collected, but pod specs, logs and the migration may name hosts, accounts and
+volumes; review the bundle before sharing it outside your organization.`,
+		Example: `  storagemover support-bundle --migration web-migration -n ops \
+    --source-kubeconfig ~/.kube/source.yaml --dest-kubeconfig ~/.kube/dest.yaml --aws-region us-east-1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			now := time.Now().UTC()
+			if output == "" {
+				output = fmt.Sprintf("%s-support-%s.tar.gz", name, now.Format("20060102T150405Z"))
+			}
+
+			scheme, err := getScheme()
+			if err != nil {
+				return err
+			}
+			config, err := getRESTConfig(kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to load kubeconfig: %w", err)
+			}
+			c, err := client.New(config, client.Options{Scheme: scheme})
+			if err != nil {
+				return fmt.Errorf("failed to create client: %w", err)
+			}
+
+			m := &migrationv1alpha1.StatefulSetMigration{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, m); err != nil {
+				return fmt.Errorf("failed to get migration: %w", err)
+			}
+
+			b := &supportBundle{scheme: scheme, volumes: map[string]bool{}}
+			b.Manifest.Migration = namespace + "/" + name
+			b.Manifest.GeneratedAt = metav1.NewTime(now)
+			b.collectMigration(ctx, c, m)
+
+			out.Printf("Collecting controller logs from %s\n", controllerNamespace)
+			if clientset, err := kubernetes.NewForConfig(config); err != nil {
+				b.fail("logs", err)
+			} else {
+				b.collectLogs(ctx, c, clientset, controllerNamespace, controllerSelector, logSince)
+			}
+
+			spec := &m.Spec
+			if m.Status.AppliedSpec != nil {
+				spec = m.Status.AppliedSpec
+			}
+			for _, side := range []struct {
+				dir, kubeconfig, flag, namespace string
+			}{
+				{"source", sourceKubeconfig, "--source-kubeconfig", spec.SourceNamespace},
+				{"dest", destKubeconfig, "--dest-kubeconfig", spec.DestNamespace},
+			} {
+				if side.kubeconfig == "" {
+					b.fail(side.dir, fmt.Errorf("skipped: %s is not set", side.flag))
+					continue
+				}
+				out.Printf("Collecting %s/%s from the %s cluster\n", side.namespace, spec.StatefulSetName, side.dir)
+				cc, err := getClient(side.kubeconfig)
+				if err != nil {
+					b.fail(side.dir, err)
+					continue
+				}
+				b.collectWorkload(ctx, cc, side.dir, side.namespace, spec.StatefulSetName)
+			}
+
+			out.Printf("Describing %d volumes\n", len(b.volumes))
+			b.collectVolumes(ctx)
+
+			if err := b.write(output); err != nil {
+				return err
+			}
+			out.Report("bundle", fmt.Sprintf("Wrote %s (%d files, %d could not be collected)", output, len(b.files), len(b.Manifest.Errors)),
+				"path", output, "files", len(b.files), "errors", len(b.Manifest.Errors))
+			for _, e := range b.Manifest.Errors {
+				out.Warn(fmt.Errorf("%s: %s", e.Section, e.Error))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig of the cluster the controller runs in (default $KUBECONFIG)")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the StatefulSetMigration")
+	cmd.Flags().StringVar(&name, "migration", "", "Name of the StatefulSetMigration")
+	cmd.Flags().StringVar(&controllerNamespace, "controller-namespace", "aqua-system", "Namespace the controller runs in")
+	cmd.Flags().StringVar(&controllerSelector, "controller-selector", "control-plane=controller-manager", "Label selector of the controller pods")
+	cmd.Flags().DurationVar(&logSince, "log-since", 24*time.Hour, "How far back to collect controller logs")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Path of the tarball (default \"<migration>-support-<time>.tar.gz\")")
+	_ = cmd.MarkFlagRequired("migration")
+	_ = cmd.MarkFlagFilename("kubeconfig")
+	_ = cmd.MarkFlagFilename("output", "gz")
+
+	return cmd
+}
+
+// supportBundle accumulates the files of a support bundle
+type supportBundle struct {
+	scheme *runtime.Scheme
+	files  []bundleFile
+
+	// volumes are the EBS volumes to describe
+	volumes map[string]bool
+
+	// Manifest is written to the bundle as bundle.json
+	Manifest struct {
+		Migration   string        `json:"migration"`
+		GeneratedAt metav1.Time   `json:"generatedAt"`
+		Files       []string      `json:"files"`
+		Errors      []bundleError `json:"errors,omitempty"`
+	}
+}
+
+type bundleFile struct {
+	name string
+	data []byte
+}
+
+type bundleError struct {
+	Section string `json:"section"`
+	Error   string `json:"error"`
+}
+
+// ordinalName matches the names of a StatefulSet's pods and PVCs, given a
+// pattern for what comes before the ordinal
+func ordinalName(prefix string) *regexp.Regexp {
+	return regexp.MustCompile("^" + prefix + "-[0-9]+$")
+}
+
+func (b *supportBundle) add(name string, data []byte) {
+	b.files = append(b.files, bundleFile{name: name, data: data})
+	b.Manifest.Files = append(b.Manifest.Files, name)
+}
+
+func (b *supportBundle) fail(section string, err error) {
+	b.Manifest.Errors = append(b.Manifest.Errors, bundleError{Section: section, Error: err.Error()})
+}
+
+// addObjects writes objects as a multi-document YAML file, without managed fields
+func (b *supportBundle) addObjects(name string, objs ...client.Object) {
+	var buf bytes.Buffer
+	for _, obj := range objs {
+		obj = obj.DeepCopyObject().(client.Object)
+		obj.SetManagedFields(nil)
+		if gvk, err := apiutil.GVKForObject(obj, b.scheme); err == nil {
+			obj.GetObjectKind().SetGroupVersionKind(gvk)
+		}
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			b.fail(name, err)
+			return
+		}
+		buf.WriteString("---\n")
+		buf.Write(data)
+	}
+	b.add(name, buf.Bytes())
+}
+
+// addEvents writes the events on the objects with the given UIDs, oldest first
+func (b *supportBundle) addEvents(ctx context.Context, c client.Client, name, namespace string, uids map[types.UID]bool) {
+	events := &corev1.EventList{}
+	if err := c.List(ctx, events, client.InNamespace(namespace)); err != nil {
+		b.fail(name, err)
+		return
+	}
+	var objs []client.Object
+	for i := range events.Items {
+		if uids[events.Items[i].InvolvedObject.UID] {
+			objs = append(objs, &events.Items[i])
+		}
+	}
+	slices.SortFunc(objs, func(x, y client.Object) int {
+		return eventTime(x.(*corev1.Event)).Compare(eventTime(y.(*corev1.Event)))
+	})
+	b.addObjects(name, objs...)
+}
+
+func eventTime(e *corev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	return e.EventTime.Time
+}
+
+// collectMigration adds the migration, its VolumeMigrations, its report and their events
+func (b *supportBundle) collectMigration(ctx context.Context, c client.Client, m *migrationv1alpha1.StatefulSetMigration) {
+	b.addObjects("migration.yaml", m)
+	uids := map[types.UID]bool{m.UID: true}
+
+	for _, p := range m.Status.MigratedPods {
+		b.addVolume(p.VolumeID)
+		b.addVolume(p.SourceVolumeID)
+	}
+	for _, w := range m.Status.VolumeWaits {
+		b.addVolume(w.VolumeID)
+	}
+	for _, f := range m.Status.VolumeFallbacks {
+		b.addVolume(f.VolumeID)
+		b.addVolume(f.DestVolumeID)
+	}
+
+	children := &migrationv1alpha1.VolumeMigrationList{}
+	if err := c.List(ctx, children, client.InNamespace(m.Namespace)); err != nil {
+		b.fail("volumemigrations.yaml", err)
+	} else {
+		var objs []client.Object
+		for i := range children.Items {
+			vm := &children.Items[i]
+			if metav1.IsControlledBy(vm, m) {
+				objs = append(objs, vm)
+				uids[vm.UID] = true
+				b.addVolume(vm.Status.VolumeID)
+			}
+		}
+		if len(objs) > 0 {
+			b.addObjects("volumemigrations.yaml", objs...)
+		}
+	}
+
+	if m.Status.Report != "" {
+		report := &corev1.ConfigMap{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: m.Namespace, Name: m.Status.Report}, report); err != nil {
+			b.fail("report.yaml", err)
+		} else {
+			b.addObjects("report.yaml", report)
+		}
+	}
+
+	b.addEvents(ctx, c, "events.yaml", m.Namespace, uids)
+}
+
+// collectLogs adds the logs of the controller pods' containers
+func (b *supportBundle) collectLogs(ctx context.Context, c client.Client, clientset kubernetes.Interface, namespace, selector string, since time.Duration) {
+	labels, err := metav1.ParseToLabelSelector(selector)
+	if err != nil {
+		b.fail("logs", err)
+		return
+	}
+	sel, err := metav1.LabelSelectorAsSelector(labels)
+	if err != nil {
+		b.fail("logs", err)
+		return
+	}
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: sel}); err != nil {
+		b.fail("logs", err)
+		return
+	}
+	if len(pods.Items) == 0 {
+		b.fail("logs", fmt.Errorf("no pods in %s match %s", namespace, selector))
+		return
+	}
+
+	sinceSeconds := int64(since.Seconds())
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			previous := []bool{false}
+			if status.RestartCount > 0 {
+				previous = append(previous, true)
+			}
+			for _, prev := range previous {
+				name := fmt.Sprintf("logs/%s/%s.log", pod.Name, status.Name)
+				if prev {
+					name = fmt.Sprintf("logs/%s/%s.previous.log", pod.Name, status.Name)
+				}
+				opts := &corev1.PodLogOptions{Container: status.Name, Previous: prev, SinceSeconds: &sinceSeconds, Timestamps: true}
+				data, err := podLogs(ctx, clientset, pod.Namespace, pod.Name, opts)
+				if err != nil {
+					b.fail(name, err)
+					continue
+				}
+				b.add(name, data)
+			}
+		}
+	}
+}
+
+func podLogs(ctx context.Context, clientset kubernetes.Interface, namespace, name string, opts *corev1.PodLogOptions) ([]byte, error) {
+	stream, err := clientset.CoreV1().Pods(namespace).GetLogs(name, opts).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return io.ReadAll(stream)
+}
+
+// collectWorkload adds a StatefulSet, its pods, PVCs, PVs and VolumeAttachments,
+// and the events on them, from one side of the migration
+func (b *supportBundle) collectWorkload(ctx context.Context, c client.Client, dir, namespace, name string) {
+	uids := map[types.UID]bool{}
+
+	sts := &appsv1.StatefulSet{}
+	switch err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, sts); {
+	case err == nil:
+		b.addObjects(dir+"/statefulset.yaml", sts)
+		uids[sts.UID] = true
+	case apierrors.IsNotFound(err):
+		b.fail(dir+"/statefulset.yaml", fmt.Errorf("StatefulSet %s/%s not found", namespace, name))
+	default:
+		b.fail(dir+"/statefulset.yaml", err)
+	}
+
+	podName := ordinalName(regexp.QuoteMeta(name))
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		b.fail(dir+"/pods.yaml", err)
+	} else {
+		var objs []client.Object
+		for i := range pods.Items {
+			if podName.MatchString(pods.Items[i].Name) {
+				objs = append(objs, &pods.Items[i])
+				uids[pods.Items[i].UID] = true
+			}
+		}
+		b.addObjects(dir+"/pods.yaml", objs...)
+	}
+
+	claimName := ordinalName(".+-" + regexp.QuoteMeta(name))
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	var pvNames []string
+	if err := c.List(ctx, pvcs, client.InNamespace(namespace)); err != nil {
+		b.fail(dir+"/pvcs.yaml", err)
+	} else {
+		var objs []client.Object
+		for i := range pvcs.Items {
+			if pvc := &pvcs.Items[i]; claimName.MatchString(pvc.Name) {
+				objs = append(objs, pvc)
+				uids[pvc.UID] = true
+				if pvc.Spec.VolumeName != "" {
+					pvNames = append(pvNames, pvc.Spec.VolumeName)
+				}
+			}
+		}
+		b.addObjects(dir+"/pvcs.yaml", objs...)
+	}
+
+	var pvs []client.Object
+	for _, pvName := range pvNames {
+		pv := &corev1.PersistentVolume{}
+		if err := c.Get(ctx, types.NamespacedName{Name: pvName}, pv); err != nil {
+			b.fail(dir+"/pvs.yaml", err)
+			continue
+		}
+		pvs = append(pvs, pv)
+		if volumeID, err := translate.EBSVolumeID(pv); err == nil {
+			b.addVolume(volumeID)
+		}
+	}
+	b.addObjects(dir+"/pvs.yaml", pvs...)
+
+	attachments := &storagev1.VolumeAttachmentList{}
+	if err := c.List(ctx, attachments); err != nil {
+		b.fail(dir+"/volumeattachments.yaml", err)
+	} else {
+		var objs []client.Object
+		for i := range attachments.Items {
+			if pv := attachments.Items[i].Spec.Source.PersistentVolumeName; pv != nil && slices.Contains(pvNames, *pv) {
+				objs = append(objs, &attachments.Items[i])
+			}
+		}
+		b.addObjects(dir+"/volumeattachments.yaml", objs...)
+	}
+
+	b.addEvents(ctx, c, dir+"/events.yaml", namespace, uids)
+}
+
+func (b *supportBundle) addVolume(volumeID string) {
+	if volumeID != "" {
+		b.volumes[volumeID] = true
+	}
+}
+
+// volumeDescription is a volume's entry in aws/volumes.json
+type volumeDescription struct {
+	Info   *aws.VolumeInfo `json:"info,omitempty"`
+	Status string          `json:"status,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// collectVolumes describes every volume found in EC2
+func (b *supportBundle) collectVolumes(ctx context.Context) {
+	if len(b.volumes) == 0 {
+		return
+	}
+	ebsClient, err := aws.NewEBSClient(ctx, aws.EBSClientConfig{
+		Region:          awsRegion,
+		Endpoint:        awsEndpoint,
+		UseFIPSEndpoint: awsUseFIPS,
+	})
+	if err != nil {
+		b.fail("aws/volumes.json", err)
+		return
+	}
+
+	described := map[string]volumeDescription{}
+	for volumeID := range b.volumes {
+		var d volumeDescription
+		info, err := ebsClient.GetVolumeInfo(ctx, volumeID)
+		if err != nil {
+			d.Error = err.Error()
+		} else {
+			d.Info = info
+			if status, err := ebsClient.GetVolumeStatus(ctx, volumeID); err != nil {
+				d.Error = err.Error()
+			} else {
+				d.Status = status
+			}
+		}
+		described[volumeID] = d
+	}
+	data, err := json.MarshalIndent(described, "", "  ")
+	if err != nil {
+		b.fail("aws/volumes.json", err)
+		return
+	}
+	b.add("aws/volumes.json", data)
+}
+
+// write writes the bundle and its manifest as a gzipped tarball
+func (b *supportBundle) write(path string) error {
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	root := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".gz"), ".tar")
+	modTime := b.Manifest.GeneratedAt.Time
+	for _, file := range append([]bundleFile{{name: "bundle.json", data: manifest}}, b.files...) {
+		header := &tar.Header{Name: root + "/" + file.name, Mode: 0o644, Size: int64(len(file.data)), ModTime: modTime}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,508 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+const (
+	// conformanceLabel marks every object the conformance test creates, so
+	// leftovers of an interrupted run can be found and deleted
+	conformanceLabel = "migration.aqua.io/conformance"
+
+	// conformanceMarkerPath is the file the test writes in the source and
+	// expects to find, unchanged, in the destination
+	conformanceMarkerPath = "/data/marker"
+
+	// conformancePollInterval is how often the test checks on the clusters
+	conformancePollInterval = 5 * time.Second
+)
+
+// conformanceCmd migrates a throwaway StatefulSet between two clusters to
+// check that the pair supports migration before real workloads are moved
+func conformanceCmd() *cobra.Command {
+	var cfg conformanceConfig
+
+	cmd := &cobra.Command{
+		Use:   "conformance",
+		Short: "Migrate a test StatefulSet end to end to check a cluster pair",
+		Long: `Checks that volumes can be migrated between the source and destination clusters:
+
+1. Creates a one-replica StatefulSet with a 1Gi volume in the source, whose pod
+   writes a random marker to the volume
+2. Sets the source PV to Retain and scales the StatefulSet to zero
+3. Waits for the EBS volume to detach
+4. Creates the translated PV and PVC, and the StatefulSet, in the destination
+5. Waits for the destination pod to be Ready, which it only becomes when it
+   reads the same marker from the volume
+
+Every object it creates is labeled migration.aqua.io/conformance and deleted
+at the end, along with the EBS volume, unless --keep is set. The destination
+PV is given the Delete reclaim policy for cleanup, so the EBS CSI driver
+deletes the volume with it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if awsRegion == "" {
+				return fmt.Errorf("AWS region is required (--aws-region or AWS_REGION env var)")
+			}
+			sourceClient, err := getClient(sourceKubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to create source client: %w", err)
+			}
+			destClient, err := getClient(destKubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to create destination client: %w", err)
+			}
+			ebsClient, err := aws.NewEBSClient(ctx, aws.EBSClientConfig{Region: awsRegion, Endpoint: awsEndpoint, UseFIPSEndpoint: awsUseFIPS})
+			if err != nil {
+				return fmt.Errorf("failed to create EBS client: %w", err)
+			}
+
+			suffix, err := randomHex(3)
+			if err != nil {
+				return err
+			}
+			marker, err := randomHex(16)
+			if err != nil {
+				return err
+			}
+			run := &conformanceRun{
+				conformanceConfig: cfg,
+				name:              "storagemover-conformance-" + suffix,
+				marker:            marker,
+				source:            sourceClient,
+				dest:              destClient,
+				ebs:               ebsClient,
+			}
+
+			start := time.Now()
+			err = run.migrate(ctx)
+			if cfg.keep {
+				out.Printf("Keeping the test objects named %s (--keep)\n", run.name)
+			} else if cleanupErr := run.cleanup(ctx); cleanupErr != nil {
+				out.Warn(fmt.Errorf("cleanup incomplete, delete the objects labeled %s=%s: %w", conformanceLabel, run.name, cleanupErr))
+			}
+			if err != nil {
+				return fmt.Errorf("conformance test failed: %w", err)
+			}
+
+			out.Println("\nConformance test passed!")
+			out.Result("conformance", []field{
+				{Key: "passed", Label: "Passed", Value: true},
+				{Key: "volumeID", Label: "Volume ID", Value: run.volumeID},
+				{Key: "durationSeconds", Label: "Duration", Value: time.Since(start).Round(time.Second)},
+			})
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&cfg.sourceNamespace, "source-namespace", "s", "default", "Namespace to create the test StatefulSet in, in the source cluster")
+	cmd.Flags().StringVarP(&cfg.destNamespace, "dest-namespace", "d", "default", "Namespace to migrate the test StatefulSet to, in the destination cluster")
+	cmd.Flags().StringVar(&cfg.storageClass, "storage-class", "", "Source StorageClass for the test volume (defaults to the cluster default)")
+	cmd.Flags().StringVar(&cfg.destStorageClass, "dest-storage-class", "", "Destination StorageClass for the migrated volume (defaults to the source one)")
+	cmd.Flags().StringVar(&cfg.image, "image", "busybox:1.36", "Image of the test pod; it needs sh, grep and sleep")
+	cmd.Flags().DurationVar(&cfg.timeout, "timeout", 10*time.Minute, "Maximum time to wait for each step")
+	cmd.Flags().BoolVar(&cfg.forceDetach, "force-detach", false, "Force-detach the volume if its instance is stopped, shutting down or terminated")
+	cmd.Flags().BoolVar(&cfg.keep, "keep", false, "Leave the test objects and volume in place for debugging")
+	cmd.MarkFlagRequired("source-kubeconfig")
+	cmd.MarkFlagRequired("dest-kubeconfig")
+
+	return cmd
+}
+
+// conformanceConfig holds the conformance command's flags
+type conformanceConfig struct {
+	sourceNamespace  string
+	destNamespace    string
+	storageClass     string
+	destStorageClass string
+	image            string
+	timeout          time.Duration
+	forceDetach      bool
+	keep             bool
+}
+
+// conformanceRun tracks one conformance test and what it has created
+type conformanceRun struct {
+	conformanceConfig
+
+	name   string
+	marker string
+	source client.Client
+	dest   client.Client
+	ebs    *aws.EBSClient
+
+	// sourcePV and volumeID are known once the source pod is Ready
+	sourcePV string
+	volumeID string
+
+	// destPV is set once the translated PV exists in the destination
+	destPV string
+}
+
+// pvcName is the name of the test StatefulSet's only PVC, in both clusters
+func (r *conformanceRun) pvcName() string {
+	return translate.GetPVCNameForStatefulSetPod("data", r.name, 0)
+}
+
+// migrate runs the test up to the destination pod reading the marker
+func (r *conformanceRun) migrate(ctx context.Context) error {
+	out.Printf("Creating StatefulSet %s/%s in source...\n", r.sourceNamespace, r.name)
+	err := r.source.Create(ctx, r.statefulSet(r.sourceNamespace, true))
+	out.Step("CreateSource", err, "statefulSet", r.sourceNamespace+"/"+r.name)
+	if err != nil {
+		return fmt.Errorf("failed to create source StatefulSet: %w", err)
+	}
+
+	out.Printf("Waiting for the source pod to write the marker (timeout: %v)...\n", r.timeout)
+	err = r.waitPodReady(ctx, r.source, r.sourceNamespace)
+	out.Step("WriteMarker", err, "pod", r.sourceNamespace+"/"+r.name+"-0")
+	if err != nil {
+		return fmt.Errorf("source pod did not become Ready: %w", err)
+	}
+
+	if err := r.stopSource(ctx); err != nil {
+		return err
+	}
+
+	sourcePVC := &corev1.PersistentVolumeClaim{}
+	if err := r.source.Get(ctx, types.NamespacedName{Namespace: r.sourceNamespace, Name: r.pvcName()}, sourcePVC); err != nil {
+		return fmt.Errorf("failed to get source PVC: %w", err)
+	}
+	sourcePV := &corev1.PersistentVolume{}
+	if err := r.source.Get(ctx, types.NamespacedName{Name: r.sourcePV}, sourcePV); err != nil {
+		return fmt.Errorf("failed to get source PV: %w", err)
+	}
+	var mapping map[string]string
+	if r.destStorageClass != "" {
+		mapping = map[string]string{sourcePV.Spec.StorageClassName: r.destStorageClass}
+	}
+	result, err := translate.TranslatePV(sourcePV, sourcePVC, translate.PVTranslationConfig{
+		DestNamespace:        r.destNamespace,
+		DestPVCName:          r.pvcName(),
+		StorageClassMapping:  mapping,
+		PreserveNodeAffinity: true,
+	})
+	if err != nil {
+		return fmt.Errorf("translation failed: %w", err)
+	}
+	for _, warning := range result.Warnings {
+		out.Warn(errors.New(warning))
+	}
+	result.PV.Labels[conformanceLabel] = r.name
+	result.PVC.Labels[conformanceLabel] = r.name
+
+	out.Printf("Waiting for volume %s to detach (timeout: %v)...\n", r.volumeID, r.timeout)
+	progress, err := r.ebs.WaitForVolumeDetach(ctx, r.volumeID, aws.WaitForVolumeDetachConfig{
+		Timeout: r.timeout,
+		OnPoll: func(info *aws.VolumeInfo, progress aws.DetachProgress) {
+			out.Printf("  Volume state: %s (%s)\n", aws.VolumeStateString(info.State), progress)
+		},
+		ForceDetach:   r.forceDetach,
+		OnForceDetach: onForceDetach(r.volumeID),
+	})
+	out.Step("DetachVolume", err, append(detachAttrs(err), "volumeID", r.volumeID, "polls", progress.Polls)...)
+	if err != nil {
+		return fmt.Errorf("volume not available: %w", err)
+	}
+
+	out.Printf("Creating PV %s, PVC and StatefulSet in destination...\n", result.PV.Name)
+	err = r.createDestination(ctx, result)
+	out.Step("CreateDestination", err, "pv", result.PV.Name, "statefulSet", r.destNamespace+"/"+r.name)
+	if err != nil {
+		return err
+	}
+
+	out.Printf("Waiting for the destination pod to read the marker (timeout: %v)...\n", r.timeout)
+	err = r.waitPodReady(ctx, r.dest, r.destNamespace)
+	out.Step("VerifyMarker", err, "pod", r.destNamespace+"/"+r.name+"-0")
+	if err != nil {
+		return fmt.Errorf("destination pod did not read the marker written in the source: %w", err)
+	}
+	return nil
+}
+
+// stopSource keeps the source volume when its PVC is deleted and scales the
+// source StatefulSet to zero, recording the volume it leaves behind
+func (r *conformanceRun) stopSource(ctx context.Context) error {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.source.Get(ctx, types.NamespacedName{Namespace: r.sourceNamespace, Name: r.pvcName()}, pvc); err != nil {
+		return fmt.Errorf("failed to get source PVC: %w", err)
+	}
+	pv := &corev1.PersistentVolume{}
+	if err := r.source.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+		return fmt.Errorf("failed to get source PV: %w", err)
+	}
+	if err := translate.ValidatePVForMigration(pv); err != nil {
+		return err
+	}
+	volumeID, err := translate.EBSVolumeID(pv)
+	if err != nil {
+		return err
+	}
+	r.sourcePV, r.volumeID = pv.Name, volumeID
+
+	if err := setReclaimPolicy(ctx, r.source, pv, corev1.PersistentVolumeReclaimRetain); err != nil {
+		return err
+	}
+
+	out.Printf("Scaling source StatefulSet %s/%s to 0...\n", r.sourceNamespace, r.name)
+	err = scaleStatefulSet(ctx, r.source, r.sourceNamespace, r.name, 0)
+	if err == nil {
+		err = r.waitPodGone(ctx, r.source, r.sourceNamespace)
+	}
+	out.Step("StopSource", err, "statefulSet", r.sourceNamespace+"/"+r.name, "volumeID", volumeID)
+	if err != nil {
+		return fmt.Errorf("failed to stop the source pod: %w", err)
+	}
+	return nil
+}
+
+// createDestination creates the translated PV and PVC and the StatefulSet
+// that mounts them in the destination
+func (r *conformanceRun) createDestination(ctx context.Context, result *translate.TranslationResult) error {
+	if err := r.dest.Create(ctx, result.PV); err != nil {
+		return fmt.Errorf("failed to create destination PV: %w", err)
+	}
+	r.destPV = result.PV.Name
+	if err := r.dest.Create(ctx, result.PVC); err != nil {
+		return fmt.Errorf("failed to create destination PVC: %w", err)
+	}
+	if err := r.dest.Create(ctx, r.statefulSet(r.destNamespace, false)); err != nil {
+		return fmt.Errorf("failed to create destination StatefulSet: %w", err)
+	}
+	return nil
+}
+
+// cleanup deletes everything the test created. The EBS volume goes with the
+// destination PV once it exists, and with the source PV until then.
+func (r *conformanceRun) cleanup(ctx context.Context) error {
+	out.Println("Cleaning up...")
+	var errs []error
+
+	if err := r.deleteStatefulSet(ctx, r.dest, r.destNamespace); err != nil {
+		errs = append(errs, fmt.Errorf("destination: %w", err))
+	}
+	if err := r.deleteStatefulSet(ctx, r.source, r.sourceNamespace); err != nil {
+		errs = append(errs, fmt.Errorf("source: %w", err))
+	}
+
+	if r.destPV != "" {
+		errs = append(errs, r.releaseVolume(ctx, r.dest, r.destPV, r.destNamespace))
+		if r.sourcePV != "" {
+			// The source PV is Retain, so deleting it leaves the volume alone
+			pv := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: r.sourcePV}}
+			errs = append(errs, client.IgnoreNotFound(r.source.Delete(ctx, pv)))
+		}
+		errs = append(errs, r.deletePVC(ctx, r.source, r.sourceNamespace))
+	} else {
+		if r.sourcePV != "" {
+			errs = append(errs, r.releaseVolume(ctx, r.source, r.sourcePV, r.sourceNamespace))
+		}
+		errs = append(errs, r.deletePVC(ctx, r.source, r.sourceNamespace))
+	}
+
+	err := errors.Join(errs...)
+	out.Step("Cleanup", err, "name", r.name)
+	return err
+}
+
+// releaseVolume sets a PV to Delete, marked as provisioned by the EBS CSI
+// driver so the driver deletes its volume, then deletes the PVC bound to it
+func (r *conformanceRun) releaseVolume(ctx context.Context, c client.Client, pvName, namespace string) error {
+	pv := &corev1.PersistentVolume{}
+	if err := c.Get(ctx, types.NamespacedName{Name: pvName}, pv); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	patch := client.MergeFrom(pv.DeepCopy())
+	pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimDelete
+	metav1.SetMetaDataAnnotation(&pv.ObjectMeta, "pv.kubernetes.io/provisioned-by", migration.EBSCSIDriver)
+	if err := c.Patch(ctx, pv, patch); err != nil {
+		return fmt.Errorf("failed to set PV %s to Delete: %w", pvName, err)
+	}
+	return r.deletePVC(ctx, c, namespace)
+}
+
+// deletePVC deletes the test PVC in a namespace, if it exists
+func (r *conformanceRun) deletePVC(ctx context.Context, c client.Client, namespace string) error {
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: r.pvcName()}}
+	if err := client.IgnoreNotFound(c.Delete(ctx, pvc)); err != nil {
+		return fmt.Errorf("failed to delete PVC %s/%s: %w", namespace, pvc.Name, err)
+	}
+	return nil
+}
+
+// deleteStatefulSet deletes the test StatefulSet and waits for its pod to go,
+// so its volume is unmounted before the PVC is deleted
+func (r *conformanceRun) deleteStatefulSet(ctx context.Context, c client.Client, namespace string) error {
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: r.name}}
+	if err := c.Delete(ctx, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete StatefulSet %s/%s: %w", namespace, r.name, err)
+	}
+	return r.waitPodGone(ctx, c, namespace)
+}
+
+// waitPodReady waits for the test pod in a namespace to be Ready. Its
+// readiness probe passes only once the marker on its volume matches.
+func (r *conformanceRun) waitPodReady(ctx context.Context, c client.Client, namespace string) error {
+	pod := &corev1.Pod{}
+	key := types.NamespacedName{Namespace: namespace, Name: r.name + "-0"}
+	return pollUntil(ctx, r.timeout, func() (bool, error) {
+		if err := c.Get(ctx, key, pod); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// waitPodGone waits for the test pod in a namespace to be deleted
+func (r *conformanceRun) waitPodGone(ctx context.Context, c client.Client, namespace string) error {
+	key := types.NamespacedName{Namespace: namespace, Name: r.name + "-0"}
+	return pollUntil(ctx, r.timeout, func() (bool, error) {
+		err := c.Get(ctx, key, &corev1.Pod{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+}
+
+// statefulSet returns the test StatefulSet. In the source its pod writes the
+// marker before it becomes Ready; in the destination it only reads it.
+func (r *conformanceRun) statefulSet(namespace string, writeMarker bool) *appsv1.StatefulSet {
+	labels := map[string]string{conformanceLabel: r.name}
+	script := "trap 'exit 0' TERM; sleep 2147483647 & wait"
+	if writeMarker {
+		script = fmt.Sprintf(`echo "$MARKER" > %s && sync && %s`, conformanceMarkerPath, script)
+	}
+
+	claim := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Labels: labels},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+	switch {
+	case !writeMarker && r.destStorageClass != "":
+		claim.Spec.StorageClassName = ptr.To(r.destStorageClass)
+	case r.storageClass != "":
+		claim.Spec.StorageClassName = ptr.To(r.storageClass)
+	}
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: r.name, Namespace: namespace, Labels: labels},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:             ptr.To[int32](1),
+			ServiceName:          r.name,
+			Selector:             &metav1.LabelSelector{MatchLabels: labels},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{claim},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					TerminationGracePeriodSeconds: ptr.To[int64](5),
+					Containers: []corev1.Container{{
+						Name:    "marker",
+						Image:   r.image,
+						Command: []string{"sh", "-c", script},
+						Env:     []corev1.EnvVar{{Name: "MARKER", Value: r.marker}},
+						ReadinessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{
+								Command: []string{"grep", "-qx", r.marker, conformanceMarkerPath},
+							}},
+							PeriodSeconds: 2,
+						},
+						VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// setReclaimPolicy patches a PV's reclaim policy
+func setReclaimPolicy(ctx context.Context, c client.Client, pv *corev1.PersistentVolume, policy corev1.PersistentVolumeReclaimPolicy) error {
+	patch := client.MergeFrom(pv.DeepCopy())
+	pv.Spec.PersistentVolumeReclaimPolicy = policy
+	if err := c.Patch(ctx, pv, patch); err != nil {
+		return fmt.Errorf("failed to set PV %s to %s: %w", pv.Name, policy, err)
+	}
+	return nil
+}
+
+// scaleStatefulSet patches a StatefulSet's replica count
+func scaleStatefulSet(ctx context.Context, c client.Client, namespace, name string, replicas int32) error {
+	sts := &appsv1.StatefulSet{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, sts); err != nil {
+		return fmt.Errorf("failed to get StatefulSet %s/%s: %w", namespace, name, err)
+	}
+	patch := client.MergeFrom(sts.DeepCopy())
+	sts.Spec.Replicas = ptr.To(replicas)
+	if err := c.Patch(ctx, sts, patch); err != nil {
+		return fmt.Errorf("failed to scale StatefulSet %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// pollUntil calls done every conformancePollInterval until it returns true
+// or an error, or the timeout passes
+func pollUntil(ctx context.Context, timeout time.Duration, done func() (bool, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(conformancePollInterval)
+	defer ticker.Stop()
+	for {
+		ok, err := done()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out after %v", timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random data: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/controller"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+)
+
+// detachTableInterval is how often wait-detach prints its progress table for several volumes
+const detachTableInterval = 15 * time.Second
+
+// volumeDetach is the progress of one volume in a multi-volume wait
+type volumeDetach struct {
+	state    string
+	progress aws.DetachProgress
+	result   string
+}
+
+// readVolumeIDs reads volume IDs from a file, one per line. Blank lines and
+// text after # are ignored.
+func readVolumeIDs(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open volume ID file: %w", err)
+	}
+	defer f.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			ids = append(ids, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read volume ID file: %w", err)
+	}
+	return ids, nil
+}
+
+// dedupe removes repeated values, keeping the first occurrence's position
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// writeDetachTable writes the progress table of a multi-volume wait, one
+// row per volume in the order given
+func writeDetachTable(w io.Writer, volumeIDs []string, status map[string]*volumeDetach) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VOLUME\tSTATE\tPROGRESS\tRESULT")
+	for _, id := range volumeIDs {
+		s := status[id]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", id, s.state, s.progress, s.result)
+	}
+	tw.Flush()
+}
+
+// waitDetachAll waits for several volumes to detach concurrently, printing
+// a progress table as they go, and reports each volume's result
+func waitDetachAll(ctx context.Context, ebsClient *aws.EBSClient, volumeIDs []string, timeout time.Duration, forceDetach bool) error {
+	var mu sync.Mutex
+	status := make(map[string]*volumeDetach, len(volumeIDs))
+	for _, id := range volumeIDs {
+		status[id] = &volumeDetach{state: "unknown", result: "waiting"}
+	}
+	printTable := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		var b strings.Builder
+		writeDetachTable(&b, volumeIDs, status)
+		out.Printf("%s\n", b.String())
+	}
+
+	out.Printf("Waiting for %d volumes to become available (timeout: %v)...\n\n", len(volumeIDs), timeout)
+
+	errs := make([]error, len(volumeIDs))
+	var wg sync.WaitGroup
+	for i, volumeID := range volumeIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			detachStart := time.Now()
+			updates, cancel := ebsClient.WatchVolumeDetach(ctx, volumeID, aws.WaitForVolumeDetachConfig{
+				Timeout:       timeout,
+				ForceDetach:   forceDetach,
+				OnForceDetach: onForceDetach(volumeID),
+			})
+			defer cancel()
+
+			var update aws.DetachUpdate
+			for update = range updates {
+				mu.Lock()
+				if update.Info != nil {
+					status[volumeID].state = aws.VolumeStateString(update.Info.State)
+				}
+				status[volumeID].progress = update.Progress
+				mu.Unlock()
+			}
+			progress, err := update.Progress, update.Err
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				status[volumeID].result = "failed"
+				observeStep(controller.StepDetachVolume, err, append(detachAttrs(err), "volumeID", volumeID)...)
+				errs[i] = fmt.Errorf("wait failed: %w", err)
+				return
+			}
+			detachDuration := time.Since(detachStart)
+			status[volumeID].state = "available"
+			status[volumeID].result = fmt.Sprintf("available after %s, %d polls", detachDuration.Round(time.Second), progress.Polls)
+			metrics.ObserveVolumeDetach(detachDuration)
+			observeStep(controller.StepDetachVolume, nil, "volumeID", volumeID, "durationSeconds", detachDuration.Seconds(), "polls", progress.Polls)
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	ticker := time.NewTicker(detachTableInterval)
+	defer ticker.Stop()
+wait:
+	for {
+		select {
+		case <-done:
+			break wait
+		case <-ticker.C:
+			printTable()
+		}
+	}
+	printTable()
+
+	failed := 0
+	for i, volumeID := range volumeIDs {
+		if errs[i] != nil {
+			failed++
+			out.Report("volume", fmt.Sprintf("❌ %s: %v", volumeID, errs[i]), "volumeID", volumeID, "state", status[volumeID].state, "error", errs[i].Error())
+			continue
+		}
+		out.Report("volume", fmt.Sprintf("✅ %s is available", volumeID), "volumeID", volumeID, "state", "available")
+	}
+	out.Report("summary", fmt.Sprintf("Available: %d  Failed: %d", len(volumeIDs)-failed, failed),
+		"total", len(volumeIDs), "available", len(volumeIDs)-failed, "failed", failed)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+func TestWriteDetachTable(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	status := map[string]*volumeDetach{
+		"vol-0123456789abcdef0": {state: "in-use", result: "waiting", progress: aws.DetachProgress{
+			Phase: aws.DetachPhaseDetaching, DetachingAt: start, LastPoll: start.Add(42 * time.Second),
+		}},
+		"vol-1": {state: "unknown", result: "waiting"},
+	}
+
+	var b strings.Builder
+	writeDetachTable(&b, []string{"vol-0123456789abcdef0", "vol-1"}, status)
+	want := "" +
+		"VOLUME                 STATE    PROGRESS           RESULT\n" +
+		"vol-0123456789abcdef0  in-use   detaching for 42s  waiting\n" +
+		"vol-1                  unknown  not polled yet     waiting\n"
+	if b.String() != want {
+		t.Errorf("table =\n%s\nwant\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// getFunc fetches an object, returning a NotFound error when it does not exist
+type getFunc func(ctx context.Context, key types.NamespacedName, obj client.Object) error
+
+// clientGetter fetches objects from a cluster
+func clientGetter(c client.Client) getFunc {
+	return func(ctx context.Context, key types.NamespacedName, obj client.Object) error {
+		return c.Get(ctx, key, obj)
+	}
+}
+
+// archiveGetter reads objects from a copy of a migration's source archive:
+// statefulset.yaml, persistentvolumeclaims/<name>.yaml and persistentvolumes/<name>.yaml
+func archiveGetter(dir string) getFunc {
+	return func(ctx context.Context, key types.NamespacedName, obj client.Object) error {
+		var path string
+		var resource string
+		switch obj.(type) {
+		case *appsv1.StatefulSet:
+			path, resource = "statefulset.yaml", "statefulsets"
+		case *corev1.PersistentVolumeClaim:
+			path, resource = filepath.Join("persistentvolumeclaims", key.Name+".yaml"), "persistentvolumeclaims"
+		case *corev1.PersistentVolume:
+			path, resource = filepath.Join("persistentvolumes", key.Name+".yaml"), "persistentvolumes"
+		default:
+			return fmt.Errorf("unsupported archive object %T", obj)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, path))
+		if errors.Is(err, os.ErrNotExist) {
+			return apierrors.NewNotFound(schema.GroupResource{Resource: resource}, key.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if err := yaml.Unmarshal(data, obj); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if _, ok := obj.(*appsv1.StatefulSet); ok && obj.GetName() != key.Name {
+			return apierrors.NewNotFound(schema.GroupResource{Resource: resource}, key.Name)
+		}
+		return nil
+	}
+}
+
+// diffCmd compares a StatefulSet and its volumes in the source and destination clusters
+func diffCmd() *cobra.Command {
+	var namespace string
+	var name string
+	var destNamespace string
+	var sourceDir string
+	var storageClassMapping map[string]string
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare a StatefulSet and its PVCs and PVs between the source and destination",
+		Long: `Fetches the StatefulSet, and each replica's PVCs and bound PVs, from both
+clusters and prints the fields that differ: capacity, StorageClass, volume
+handle, zone, filesystem type, and the pod template's containers and
+scheduling. StorageClasses renamed by --storage-class-mapping are not
+reported.
+
+After a migration the source objects are usually deleted. Point --source-dir
+at a downloaded copy of the migration's source/ archive to compare against the
+objects as they were before the migration.
+
+The command exits with an error when any field differs, so it can gate a pipeline.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if destNamespace == "" {
+				destNamespace = namespace
+			}
+
+			var source getFunc
+			if sourceDir != "" {
+				source = archiveGetter(sourceDir)
+			} else {
+				c, err := getClient(sourceKubeconfig)
+				if err != nil {
+					return fmt.Errorf("failed to create source client: %w", err)
+				}
+				source = clientGetter(c)
+			}
+			destClient, err := getClient(destKubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to create destination client: %w", err)
+			}
+			dest := clientGetter(destClient)
+			opts := migration.DiffOptions{StorageClassMapping: storageClassMapping}
+
+			count := 0
+			report := func(object string, diffs []migration.Difference) {
+				for _, d := range diffs {
+					count++
+					out.Report("diff", fmt.Sprintf("%s %s: %s -> %s", object, d.Field, d.Source, d.Dest),
+						"object", object, "field", d.Field, "source", d.Source, "dest", d.Dest)
+				}
+			}
+
+			sourceSTS := &appsv1.StatefulSet{}
+			destSTS := &appsv1.StatefulSet{}
+			sourceFound, err := getOptional(ctx, source, types.NamespacedName{Namespace: namespace, Name: name}, sourceSTS)
+			if err != nil {
+				return fmt.Errorf("failed to get source StatefulSet: %w", err)
+			}
+			destFound, err := getOptional(ctx, dest, types.NamespacedName{Namespace: destNamespace, Name: name}, destSTS)
+			if err != nil {
+				return fmt.Errorf("failed to get destination StatefulSet: %w", err)
+			}
+			stsObject := "StatefulSet/" + name
+			switch {
+			case sourceFound && destFound:
+				report(stsObject, migration.DiffStatefulSets(sourceSTS, destSTS, opts))
+			case sourceFound:
+				report(stsObject, []migration.Difference{missing(false)})
+			case destFound:
+				report(stsObject, []migration.Difference{missing(true)})
+				sourceSTS = destSTS
+			default:
+				return fmt.Errorf("StatefulSet %s not found in either cluster", name)
+			}
+
+			// Walk the replicas and volume claim templates the source had
+			replicas := int32(1)
+			if sourceSTS.Spec.Replicas != nil {
+				replicas = *sourceSTS.Spec.Replicas
+			}
+			for i := 0; i < int(replicas); i++ {
+				for _, vct := range sourceSTS.Spec.VolumeClaimTemplates {
+					pvcName := translate.GetPVCNameForStatefulSetPod(vct.Name, name, i)
+					sourcePVC := &corev1.PersistentVolumeClaim{}
+					destPVC := &corev1.PersistentVolumeClaim{}
+					sourceFound, err := getOptional(ctx, source, types.NamespacedName{Namespace: namespace, Name: pvcName}, sourcePVC)
+					if err != nil {
+						return fmt.Errorf("failed to get source PVC %s: %w", pvcName, err)
+					}
+					destFound, err := getOptional(ctx, dest, types.NamespacedName{Namespace: destNamespace, Name: pvcName}, destPVC)
+					if err != nil {
+						return fmt.Errorf("failed to get destination PVC %s: %w", pvcName, err)
+					}
+					pvcObject := "PersistentVolumeClaim/" + pvcName
+					if !sourceFound || !destFound {
+						report(pvcObject, []migration.Difference{missing(!sourceFound)})
+						continue
+					}
+					report(pvcObject, migration.DiffPVCs(sourcePVC, destPVC, opts))
+
+					if sourcePVC.Spec.VolumeName == "" || destPVC.Spec.VolumeName == "" {
+						continue
+					}
+					sourcePV := &corev1.PersistentVolume{}
+					destPV := &corev1.PersistentVolume{}
+					sourceFound, err = getOptional(ctx, source, types.NamespacedName{Name: sourcePVC.Spec.VolumeName}, sourcePV)
+					if err != nil {
+						return fmt.Errorf("failed to get source PV %s: %w", sourcePVC.Spec.VolumeName, err)
+					}
+					destFound, err = getOptional(ctx, dest, types.NamespacedName{Name: destPVC.Spec.VolumeName}, destPV)
+					if err != nil {
+						return fmt.Errorf("failed to get destination PV %s: %w", destPVC.Spec.VolumeName, err)
+					}
+					pvObject := fmt.Sprintf("PersistentVolume/%s (%s)", destPVC.Spec.VolumeName, pvcName)
+					if !sourceFound || !destFound {
+						report(pvObject, []migration.Difference{missing(!sourceFound)})
+						continue
+					}
+					report(pvObject, migration.DiffPVs(sourcePV, destPV, opts))
+				}
+			}
+
+			out.Report("summary", fmt.Sprintf("%d differences", count), "differences", count)
+			if count > 0 {
+				return fmt.Errorf("source and destination differ in %d fields", count)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Source namespace")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the StatefulSet")
+	cmd.Flags().StringVar(&destNamespace, "dest-namespace", "", "Destination namespace (defaults to the source namespace)")
+	cmd.Flags().StringVar(&sourceDir, "source-dir", "", "Read the source objects from a downloaded source/ archive directory instead of the source cluster")
+	cmd.Flags().StringToStringVar(&storageClassMapping, "storage-class-mapping", nil, "StorageClass renames the migration made, e.g. gp2=gp3")
+	cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagDirname("source-dir")
+
+	return cmd
+}
+
+// getOptional fetches an object and reports whether it exists
+func getOptional(ctx context.Context, get getFunc, key types.NamespacedName, obj client.Object) (bool, error) {
+	if err := get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// missing is the difference reported for an object that exists on one side only
+func missing(inSource bool) migration.Difference {
+	if inSource {
+		return migration.Difference{Field: "exists", Source: "missing", Dest: "present"}
+	}
+	return migration.Difference{Field: "exists", Source: "present", Dest: "missing"}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+const (
+	// docsFormatMan generates section 1 man pages
+	docsFormatMan = "man"
+
+	// docsFormatMarkdown generates one markdown file per command
+	docsFormatMarkdown = "markdown"
+)
+
+// genDocsCmd generates man pages or markdown reference docs for every command
+func genDocsCmd() *cobra.Command {
+	var dir string
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "gen-docs",
+		Short: "Generate man pages or markdown docs for storagemover",
+		Long: `Writes one page per command into --dir. Install man pages with, for example:
+
+  storagemover gen-docs --dir /usr/local/share/man/man1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return fmt.Errorf("failed to create docs directory: %w", err)
+			}
+
+			root := cmd.Root()
+			root.DisableAutoGenTag = true
+
+			switch format {
+			case docsFormatMan:
+				header := &doc.GenManHeader{
+					Title:   "STORAGEMOVER",
+					Section: "1",
+					Source:  "Aqua Service Controller",
+				}
+				if err := doc.GenManTree(root, header, dir); err != nil {
+					return fmt.Errorf("failed to generate man pages: %w", err)
+				}
+			case docsFormatMarkdown:
+				if err := doc.GenMarkdownTree(root, dir); err != nil {
+					return fmt.Errorf("failed to generate markdown docs: %w", err)
+				}
+			default:
+				return fmt.Errorf("unsupported --format %q (expected %q or %q)", format, docsFormatMan, docsFormatMarkdown)
+			}
+
+			out.Report("docs", fmt.Sprintf("Wrote %s docs to %s", format, dir), "format", format, "dir", dir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "./docs/storagemover", "Directory to write the generated docs to")
+	cmd.Flags().StringVar(&format, "format", docsFormatMan, "Docs format: man or markdown")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(
+		[]string{docsFormatMan, docsFormatMarkdown}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.MarkFlagDirname("dir")
+
+	return cmd
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/controller"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// estimateDetachCmd predicts how long a volume takes to detach from its history
+func estimateDetachCmd() *cobra.Command {
+	var volumeID string
+	var pvName string
+	var days int
+
+	cmd := &cobra.Command{
+		Use:   "estimate-detach",
+		Short: "Predict how long an EBS volume takes to detach, from its history (read-only)",
+		Long: `Looks up the volume's AttachVolume and DetachVolume calls in CloudTrail's
+event history, pairs each detach with the attach that followed it, and proposes
+a volumeDetachTimeout: the 90th percentile of those moves with 50% headroom,
+rounded up to the minute. The time from a detach call to the next attach bounds
+the detach from above, since Kubernetes only attaches the volume elsewhere once
+EC2 has detached it.
+
+With a source kubeconfig, the node the volume is attached to is looked up by
+its EC2 instance, and its kubelet version and readiness are reported. A node
+that is not Ready adds the six minutes Kubernetes waits for it to unmount the
+volume. The volume's DescribeVolumeStatus result is reported too, since an
+impaired volume can be slow to detach.
+
+CloudTrail keeps 90 days of history and needs cloudtrail:LookupEvents; without
+it the proposal rests on the default timeout.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if awsRegion == "" {
+				return fmt.Errorf("AWS region is required (--aws-region or AWS_REGION env var)")
+			}
+			if days < 1 || days > 90 {
+				return fmt.Errorf("--days must be between 1 and 90")
+			}
+
+			var c client.Client
+			if sourceKubeconfig != "" || os.Getenv("KUBECONFIG") != "" {
+				var err error
+				if c, err = getClient(sourceKubeconfig); err != nil {
+					return fmt.Errorf("failed to create source client: %w", err)
+				}
+			}
+			if pvName != "" {
+				if c == nil {
+					return fmt.Errorf("--pv needs --source-kubeconfig")
+				}
+				pv := &corev1.PersistentVolume{}
+				if err := c.Get(ctx, types.NamespacedName{Name: pvName}, pv); err != nil {
+					return fmt.Errorf("failed to get PV: %w", err)
+				}
+				id, err := translate.EBSVolumeID(pv)
+				if err != nil {
+					return err
+				}
+				volumeID = id
+			}
+
+			ebsClient, err := aws.NewEBSClient(ctx, aws.EBSClientConfig{
+				Region:          awsRegion,
+				Endpoint:        awsEndpoint,
+				UseFIPSEndpoint: awsUseFIPS,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create EBS client: %w", err)
+			}
+
+			info, err := ebsClient.GetVolumeInfo(ctx, volumeID)
+			if err != nil {
+				return fmt.Errorf("failed to get volume info: %w", err)
+			}
+			fields := []field{
+				{Key: "volumeID", Label: "Volume", Value: volumeID},
+				{Key: "state", Label: "State", Value: aws.VolumeStateString(info.State)},
+				{Key: "size", Label: "Size", Value: fmt.Sprintf("%d GiB %s", info.Size, info.VolumeType)},
+			}
+			if status, err := ebsClient.GetVolumeStatus(ctx, volumeID); err != nil {
+				out.Warn(err)
+			} else if status != "" {
+				fields = append(fields, field{Key: "status", Label: "Status", Value: status})
+			}
+
+			nodeNotReady := false
+			if instanceID := info.AttachedInstanceID(); instanceID != "" {
+				fields = append(fields, field{Key: "instanceID", Label: "Attached to", Value: instanceID})
+				if c != nil {
+					node, err := nodeForInstance(ctx, c, instanceID)
+					switch {
+					case err != nil:
+						out.Warn(err)
+					case node != nil:
+						ready := nodeReady(node)
+						nodeNotReady = !ready
+						fields = append(fields,
+							field{Key: "node", Label: "Node", Value: node.Name},
+							field{Key: "kubeletVersion", Label: "Kubelet", Value: node.Status.NodeInfo.KubeletVersion},
+							field{Key: "nodeReady", Label: "Node Ready", Value: ready})
+					}
+				}
+			}
+			out.Result("volume", fields)
+
+			var samples []time.Duration
+			events, err := ebsClient.VolumeAttachmentEvents(ctx, volumeID, time.Now().AddDate(0, 0, -days))
+			if err != nil {
+				out.Warn(fmt.Errorf("no detach history: %w", err))
+			}
+			cycles := aws.DetachCycles(events)
+			if len(cycles) > 0 {
+				out.Println()
+			}
+			for _, cycle := range cycles {
+				forced := ""
+				if cycle.Forced {
+					forced = " (force-detached)"
+				}
+				out.Report("detach", fmt.Sprintf("%s  %s -> %s  %s%s", cycle.DetachedAt.Format(time.RFC3339), cycle.From, cycle.To,
+					cycle.Duration().Round(time.Second), forced),
+					"detachedAt", cycle.DetachedAt, "from", cycle.From, "to", cycle.To,
+					"durationSeconds", cycle.Duration().Seconds(), "forced", cycle.Forced)
+				samples = append(samples, cycle.Duration())
+			}
+
+			estimate := migration.EstimateDetach(samples, nodeNotReady, controller.DefaultVolumeDetachTimeout)
+			proposal := fmt.Sprintf("%s (the default fits)", controller.DefaultVolumeDetachTimeout)
+			if estimate.Timeout != 0 {
+				proposal = estimate.Timeout.String()
+			}
+			out.Println()
+			fields = []field{{Key: "samples", Label: "Past detaches", Value: estimate.Samples}}
+			if estimate.Samples > 0 {
+				fields = append(fields,
+					field{Key: "median", Label: "Median", Value: estimate.Median.Round(time.Second)},
+					field{Key: "p90", Label: "90th percentile", Value: estimate.P90.Round(time.Second)},
+					field{Key: "max", Label: "Slowest", Value: estimate.Max.Round(time.Second)})
+			}
+			fields = append(fields, field{Key: "volumeDetachTimeout", Label: "Proposed volumeDetachTimeout", Value: proposal})
+			if len(estimate.Notes) > 0 {
+				fields = append(fields, field{Key: "notes", Label: "Notes", Value: strings.Join(estimate.Notes, "; ")})
+			}
+			out.Result("estimate", fields)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&volumeID, "volume-id", "", "EBS volume ID (e.g., vol-0123456789abcdef0)")
+	cmd.Flags().StringVar(&pvName, "pv", "", "Source PV whose EBS volume to estimate")
+	cmd.Flags().IntVar(&days, "days", 90, "Days of CloudTrail history to look at (at most 90)")
+	cmd.MarkFlagsOneRequired("volume-id", "pv")
+	cmd.MarkFlagsMutuallyExclusive("volume-id", "pv")
+
+	return cmd
+}
+
+// nodeForInstance returns the node running on an EC2 instance, matched by
+// the instance ID at the end of its providerID, or nil when there is none
+func nodeForInstance(ctx context.Context, c client.Client, instanceID string) (*corev1.Node, error) {
+	nodes := &corev1.NodeList{}
+	if err := c.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	for i := range nodes.Items {
+		if strings.HasSuffix(nodes.Items[i].Spec.ProviderID, "/"+instanceID) {
+			return &nodes.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// nodeReady reports whether a node's Ready condition is True
+func nodeReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
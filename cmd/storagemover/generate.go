@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/controller"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// generateCRCmd drafts a StatefulSetMigration for a StatefulSet in the source cluster
+func generateCRCmd() *cobra.Command {
+	var namespace string
+	var name string
+	var destNamespace string
+	var migrationName string
+	var migrationNamespace string
+	var migrationID string
+	var sourceSecret string
+	var destSecret string
+	var storageClassMapping map[string]string
+	var interactive bool
+
+	cmd := &cobra.Command{
+		Use:   "generate-cr",
+		Short: "Draft a StatefulSetMigration manifest from the source cluster (read-only)",
+		Long: `Inspects a StatefulSet and its PVCs and PVs in the source cluster and prints a
+StatefulSetMigration manifest for it, ready for kubectl apply.
+
+With --dest-kubeconfig set, each StorageClass the PVs use is mapped to a
+destination class that provisions volumes at least as well, preferring one of
+the same name, then the destination's default class. When a volume's zone has
+no schedulable destination node, the manifest sets strategyFallback so the
+volume is snapshotted and restored into a zone the pods can run in.
+
+The volume detach and pod ready timeouts grow with the largest volume, as
+larger volumes take longer to flush on unmount and to check on mount.
+
+With --interactive, each proposal is shown on stderr and can be accepted with
+Enter or replaced. The manifest itself always goes to stdout.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if destNamespace == "" {
+				destNamespace = namespace
+			}
+			if migrationName == "" {
+				migrationName = name + "-migration"
+			}
+			if migrationID == "" {
+				migrationID = fmt.Sprintf("%s-%s", name, time.Now().UTC().Format("20060102"))
+			}
+
+			source, err := getClient(sourceKubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to create source client: %w", err)
+			}
+
+			sts := &appsv1.StatefulSet{}
+			if err := source.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, sts); err != nil {
+				return fmt.Errorf("failed to get StatefulSet: %w", err)
+			}
+			replicas := int32(1)
+			if sts.Spec.Replicas != nil {
+				replicas = *sts.Spec.Replicas
+			}
+			var pvs []corev1.PersistentVolume
+			var unbound []string
+			for i := 0; i < int(replicas); i++ {
+				for _, vct := range sts.Spec.VolumeClaimTemplates {
+					pvcName := translate.GetPVCNameForStatefulSetPod(vct.Name, name, i)
+					pvc := &corev1.PersistentVolumeClaim{}
+					if err := source.Get(ctx, types.NamespacedName{Namespace: namespace, Name: pvcName}, pvc); err != nil {
+						return fmt.Errorf("failed to get PVC %s: %w", pvcName, err)
+					}
+					if pvc.Spec.VolumeName == "" {
+						unbound = append(unbound, pvcName)
+						continue
+					}
+					pv := &corev1.PersistentVolume{}
+					if err := source.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+						return fmt.Errorf("failed to get PV %s: %w", pvc.Spec.VolumeName, err)
+					}
+					pvs = append(pvs, *pv)
+				}
+			}
+
+			m := &migrationv1alpha1.StatefulSetMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: migrationName, Namespace: migrationNamespace},
+				Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+					MigrationID:     migrationID,
+					SourceCluster:   migrationv1alpha1.ContextRef{KubeConfigSecret: sourceSecret},
+					SourceNamespace: namespace,
+					StatefulSetName: name,
+					DestCluster:     migrationv1alpha1.ContextRef{KubeConfigSecret: destSecret},
+					DestNamespace:   destNamespace,
+				},
+			}
+			if len(unbound) > 0 {
+				out.Warn(fmt.Errorf("PVCs %s have no PV, so pre-flight fails; set spec.unboundPVCs to Provision to give their replicas new, empty volumes",
+					strings.Join(unbound, ", ")))
+			}
+
+			p := &prompter{enabled: interactive, in: bufio.NewReader(os.Stdin), out: os.Stderr}
+
+			mapping, err := proposeMapping(ctx, source, pvs, storageClassMapping, p)
+			if err != nil {
+				return err
+			}
+			if len(mapping) > 0 {
+				m.Spec.StorageClassMapping = mapping
+			}
+
+			if destKubeconfig != "" {
+				dest, err := getClient(destKubeconfig)
+				if err != nil {
+					return fmt.Errorf("failed to create destination client: %w", err)
+				}
+				nodes := &corev1.NodeList{}
+				if err := dest.List(ctx, nodes); err != nil {
+					return fmt.Errorf("failed to list destination nodes: %w", err)
+				}
+				if zones := migration.UncoveredZones(pvs, nodes.Items); len(zones) > 0 {
+					fallback, err := p.confirm(fmt.Sprintf("No destination node is in %s; snapshot and restore those volumes into another zone?",
+						strings.Join(zones, ", ")), true)
+					if err != nil {
+						return err
+					}
+					if fallback {
+						m.Spec.StrategyFallback = &migrationv1alpha1.StrategyFallback{}
+					} else {
+						out.Warn(fmt.Errorf("no destination node is in %s, so pods on those volumes cannot be scheduled", strings.Join(zones, ", ")))
+					}
+				}
+			}
+
+			detach, ready := migration.ProposeTimeouts(pvs, controller.DefaultVolumeDetachTimeout, controller.DefaultPodReadyTimeout)
+			if detach, err = p.duration("Volume detach timeout", detach, controller.DefaultVolumeDetachTimeout); err != nil {
+				return err
+			}
+			if ready, err = p.duration("Pod ready timeout", ready, controller.DefaultPodReadyTimeout); err != nil {
+				return err
+			}
+			if detach != 0 && detach != controller.DefaultVolumeDetachTimeout {
+				m.Spec.VolumeDetachTimeout = &metav1.Duration{Duration: detach}
+			}
+			if ready != 0 && ready != controller.DefaultPodReadyTimeout {
+				m.Spec.PodReadyTimeout = &metav1.Duration{Duration: ready}
+			}
+
+			manifest, err := marshalMigration(m)
+			if err != nil {
+				return err
+			}
+			out.Manifest(manifest)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Source namespace")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the StatefulSet")
+	cmd.Flags().StringVar(&destNamespace, "dest-namespace", "", "Destination namespace (defaults to the source namespace)")
+	cmd.Flags().StringVar(&migrationName, "migration-name", "", "Name of the StatefulSetMigration (defaults to <name>-migration)")
+	cmd.Flags().StringVar(&migrationNamespace, "migration-namespace", "default", "Namespace of the StatefulSetMigration in the controller's cluster")
+	cmd.Flags().StringVar(&migrationID, "migration-id", "", "spec.migrationId (defaults to <name>-<today's date>)")
+	cmd.Flags().StringVar(&sourceSecret, "source-secret", "source-cluster-kubeconfig", "Secret holding the source cluster's kubeconfig")
+	cmd.Flags().StringVar(&destSecret, "dest-secret", "dest-cluster-kubeconfig", "Secret holding the destination cluster's kubeconfig")
+	cmd.Flags().StringToStringVar(&storageClassMapping, "storage-class-mapping", nil, "StorageClass mappings to use instead of the proposed ones, e.g. gp2=gp3")
+	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Confirm or change each proposal")
+	cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+// proposeMapping proposes a destination StorageClass for each class the PVs
+// use and returns the entries that rename a class. Overrides win over the
+// proposals. Without --dest-kubeconfig only the overrides are used.
+func proposeMapping(ctx context.Context, source client.Client, pvs []corev1.PersistentVolume, overrides map[string]string, p *prompter) (map[string]string, error) {
+	used := make(map[string]bool)
+	for _, pv := range pvs {
+		if pv.Spec.StorageClassName != "" {
+			used[pv.Spec.StorageClassName] = true
+		}
+	}
+	classes := make([]string, 0, len(used))
+	for class := range used {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	mapping := make(map[string]string)
+	for class, dest := range overrides {
+		mapping[class] = dest
+	}
+	if destKubeconfig == "" {
+		if len(classes) > 0 && len(overrides) == 0 {
+			out.Warn(fmt.Errorf("no --dest-kubeconfig, so StorageClasses %s keep their names", strings.Join(classes, ", ")))
+		}
+		return mapping, nil
+	}
+
+	dest, err := getClient(destKubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination client: %w", err)
+	}
+	sourceClasses, err := listStorageClasses(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source StorageClasses: %w", err)
+	}
+	destClasses, err := listStorageClasses(ctx, dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list destination StorageClasses: %w", err)
+	}
+
+	for _, class := range classes {
+		if _, ok := overrides[class]; ok {
+			continue
+		}
+		sc, ok := sourceClasses[class]
+		if !ok {
+			out.Warn(fmt.Errorf("source StorageClass %s does not exist, so no destination class is proposed for it", class))
+			continue
+		}
+		proposed := migration.ProposeStorageClass(sc, destClasses)
+		if proposed == "" {
+			out.Warn(fmt.Errorf("every destination StorageClass is a downgrade of %s; map it with --storage-class-mapping", class))
+		}
+		chosen, err := p.ask(fmt.Sprintf("Destination StorageClass for %s", class), proposed)
+		if err != nil {
+			return nil, err
+		}
+		if chosen != "" && chosen != class {
+			mapping[class] = chosen
+		}
+	}
+	return mapping, nil
+}
+
+// marshalMigration returns a StatefulSetMigration as a YAML manifest ready
+// for kubectl apply
+func marshalMigration(m *migrationv1alpha1.StatefulSetMigration) ([]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert StatefulSetMigration: %w", err)
+	}
+	content["apiVersion"] = migrationv1alpha1.GroupVersion.String()
+	content["kind"] = "StatefulSetMigration"
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]any); ok {
+		delete(metadata, "creationTimestamp")
+	}
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal StatefulSetMigration: %w", err)
+	}
+	return data, nil
+}
+
+// prompter asks the user to confirm or change a proposal in interactive
+// mode, and accepts every proposal otherwise
+type prompter struct {
+	enabled bool
+	in      *bufio.Reader
+	out     io.Writer
+}
+
+// ask prints a question with its proposed answer and returns the user's
+// answer, or the proposal when they just press Enter
+func (p *prompter) ask(question, proposal string) (string, error) {
+	if !p.enabled {
+		return proposal, nil
+	}
+	fmt.Fprintf(p.out, "%s [%s]: ", question, proposal)
+	line, err := p.in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return proposal, nil
+}
+
+// confirm asks a yes or no question
+func (p *prompter) confirm(question string, proposal bool) (bool, error) {
+	def := "n"
+	if proposal {
+		def = "y"
+	}
+	for {
+		answer, err := p.ask(question+" (y/n)", def)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "Please answer y or n")
+	}
+}
+
+// duration asks for a timeout, proposing def when the proposal is zero
+func (p *prompter) duration(question string, proposal, def time.Duration) (time.Duration, error) {
+	if proposal == 0 {
+		proposal = def
+	}
+	for {
+		answer, err := p.ask(question, proposal.String())
+		if err != nil {
+			return 0, err
+		}
+		d, err := time.ParseDuration(answer)
+		if err == nil && d > 0 {
+			return d, nil
+		}
+		fmt.Fprintf(p.out, "Please enter a positive duration such as %s\n", def)
+	}
+}
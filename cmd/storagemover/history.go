@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/aqua-io/aqua-service-controller/internal/controller"
+)
+
+// historyCmd lists the migrations, past and present, that touched a StatefulSet or volume
+func historyCmd() *cobra.Command {
+	var kubeconfig string
+	var namespace string
+	var statefulSet string
+	var volumeID string
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List the migrations that moved a StatefulSet or EBS volume (read-only)",
+		Long: `Lists every StatefulSetMigration and VolumeMigration in the cluster the
+controller runs in that moved a StatefulSet, from or to --namespace, or an EBS
+volume, oldest first. Migrations that have since been deleted are listed from
+the report ConfigMaps they leave behind, so the history answers questions such
+as when a disk last changed clusters.
+
+Migrations are found by the labels the controller sets on them and on their
+reports, naming the StatefulSets and volumes they moved.`,
+		Example: `  storagemover history --statefulset web -n prod
+  kubectl storagemover history --volume-id vol-0123456789abcdef0`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (statefulSet == "") == (volumeID == "") {
+				return fmt.Errorf("exactly one of --statefulset and --volume-id is required")
+			}
+
+			c, err := getClient(kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to create client: %w", err)
+			}
+			records, err := controller.FindMigrations(context.Background(), c, controller.MigrationQuery{
+				Namespace:   namespace,
+				StatefulSet: statefulSet,
+				VolumeID:    volumeID,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, rec := range records {
+				state := rec.Phase
+				if rec.Deleted {
+					state += ", deleted"
+				}
+				out.Report("migration", fmt.Sprintf("%s  %s %s/%s (%s)  %s %s -> %s %s  %s",
+					formatTime(rec.StartTime), rec.Kind, rec.Namespace, rec.Name, state,
+					rec.SourceCluster, rec.Source, rec.DestCluster, rec.Dest, strings.Join(rec.Volumes, ",")),
+					"kind", rec.Kind, "namespace", rec.Namespace, "name", rec.Name, "uid", rec.UID,
+					"migrationID", rec.MigrationID, "phase", rec.Phase, "deleted", rec.Deleted,
+					"sourceCluster", rec.SourceCluster, "source", rec.Source,
+					"destCluster", rec.DestCluster, "dest", rec.Dest, "volumes", rec.Volumes,
+					"startTime", formatTime(rec.StartTime), "completionTime", formatTime(rec.CompletionTime))
+			}
+			out.Report("summary", fmt.Sprintf("%d migrations", len(records)), "migrations", len(records))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig of the cluster the controller runs in (default $KUBECONFIG)")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the StatefulSet, in the source or destination cluster")
+	cmd.Flags().StringVar(&statefulSet, "statefulset", "", "Name of the StatefulSet")
+	cmd.Flags().StringVar(&volumeID, "volume-id", "", "EBS volume ID (e.g., vol-0123456789abcdef0)")
+	_ = cmd.MarkFlagFilename("kubeconfig")
+	cmd.MarkFlagsMutuallyExclusive("statefulset", "volume-id")
+
+	return cmd
+}
+
+// formatTime formats an optional timestamp, "-" when unset
+func formatTime(t *metav1.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
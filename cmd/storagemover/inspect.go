@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+)
+
+// inspectVolumeCmd runs a command in a temporary pod that mounts a migrated
+// PVC read-only, to check its data before the application starts on it
+func inspectVolumeCmd() *cobra.Command {
+	var namespace string
+	var pvcName string
+	var image string
+	var nodeSelector map[string]string
+	var timeout time.Duration
+	var keep bool
+
+	cmd := &cobra.Command{
+		Use:   "inspect-volume --name PVC -- COMMAND [ARGS...]",
+		Short: "Run a command against a migrated PVC mounted read-only in a temporary pod",
+		Long: `Creates a pod in the destination cluster that mounts the PVC read-only at
+/data and runs the command given after --, then deletes the pod. The command
+must exit 0 for the volume to pass, for example:
+
+  storagemover inspect-volume -n db --name data-postgres-0 -- test -s /data/PG_VERSION
+
+Run it before the destination StatefulSet is scaled up to confirm the data
+arrived intact before the application touches it. spec.volumeInspection runs
+the same check for every volume during a migration.
+
+When the command fails, the tail of its output is printed. On success only
+what the command writes to /dev/termination-log is.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			c, err := getClient(destKubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to create destination client: %w", err)
+			}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: pvcName}, &corev1.PersistentVolumeClaim{}); err != nil {
+				return fmt.Errorf("failed to get PVC: %w", err)
+			}
+
+			pod := migration.InspectionPod(namespace, pvcName, image, args, nodeSelector)
+			if err := c.Create(ctx, pod); err != nil {
+				return fmt.Errorf("failed to create inspection pod: %w", err)
+			}
+			if !keep {
+				defer func() {
+					if err := c.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+						out.Warn(fmt.Errorf("failed to delete inspection pod %s: %w", pod.Name, err))
+					}
+				}()
+			}
+			out.Report("inspection", fmt.Sprintf("Inspecting %s/%s in pod %s", namespace, pvcName, pod.Name),
+				"pvc", namespace+"/"+pvcName, "pod", pod.Name)
+
+			var message string
+			var inspectErr error
+			err = pollUntil(ctx, timeout, func() (bool, error) {
+				current := &corev1.Pod{}
+				if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: pod.Name}, current); err != nil {
+					return false, err
+				}
+				done, msg, err := migration.InspectionResult(current)
+				message, inspectErr = msg, err
+				return done, nil
+			})
+			if err != nil {
+				return fmt.Errorf("inspection pod %s did not finish: %w", pod.Name, err)
+			}
+			if inspectErr != nil {
+				out.Report("inspection", fmt.Sprintf("❌ %s/%s failed inspection: %v", namespace, pvcName, inspectErr),
+					"pvc", namespace+"/"+pvcName, "passed", false, "message", message)
+				return fmt.Errorf("PVC %s/%s failed inspection", namespace, pvcName)
+			}
+
+			line := fmt.Sprintf("✅ %s/%s passed inspection", namespace, pvcName)
+			if message != "" {
+				line += "\n" + message
+			}
+			out.Report("inspection", line, "pvc", namespace+"/"+pvcName, "passed", true, "message", message)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the PVC in the destination cluster")
+	cmd.Flags().StringVar(&pvcName, "name", "", "Name of the PVC")
+	cmd.Flags().StringVar(&image, "image", migration.DefaultInspectionImage, "Image that runs the command")
+	cmd.Flags().StringToStringVar(&nodeSelector, "node-selector", nil, "Node label the pod must run on, e.g. pool=db (repeatable)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Maximum time to wait for the command to finish")
+	cmd.Flags().BoolVar(&keep, "keep", false, "Keep the pod afterwards")
+	cmd.MarkFlagRequired("name")
+
+	return cmd
+}
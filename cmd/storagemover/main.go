@@ -4,26 +4,43 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/assessment"
 	"github.com/aqua-io/aqua-service-controller/internal/aws"
-	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/controller"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
 )
 
 var (
 	sourceKubeconfig string
 	destKubeconfig   string
 	awsRegion        string
+	awsEndpoint      string
+	awsUseFIPS       bool
 	verbose          bool
+	clientQPS        float32
+	clientBurst      int
+	pushgatewayURL   string
+	logFormat        string
+	quiet            bool
 )
 
 func main() {
@@ -35,8 +52,21 @@ of the Aqua Service Controller. It allows you to:
 
 - Inspect PVs and PVCs in source/destination clusters
 - Translate PVs from source to destination format
-- Wait for EBS volume detachment
+- Wait for EBS volume detachment, and attachment in the destination
+- Predict how long a volume takes to detach from its history
 - Create PV/PVC pairs in destination cluster
+- Assess which StatefulSets can be migrated
+- Compare a migrated StatefulSet and its volumes with the source
+- Preview which PVs a StorageClass mapping would change
+- Preview the order pods would migrate in by priority
+- Verify a destination PVC bound to its pre-created PV
+- Inspect a migrated volume read-only in a temporary pod
+- Draft a StatefulSetMigration manifest for a StatefulSet
+- List the migrations that moved a StatefulSet or volume
+- Collect a support bundle for a stuck or failed migration
+- Sign a migration's approval to freeze its source
+- Retry the Failed migrations a label selector matches
+- Watch clusters, StatefulSets and migrations in a terminal dashboard
 
 This tool is intended for testing and debugging the migration process.`,
 	}
@@ -45,19 +75,84 @@ This tool is intended for testing and debugging the migration process.`,
 	rootCmd.PersistentFlags().StringVar(&sourceKubeconfig, "source-kubeconfig", "", "Path to source cluster kubeconfig")
 	rootCmd.PersistentFlags().StringVar(&destKubeconfig, "dest-kubeconfig", "", "Path to destination cluster kubeconfig")
 	rootCmd.PersistentFlags().StringVar(&awsRegion, "aws-region", os.Getenv("AWS_REGION"), "AWS region for EBS operations")
+	rootCmd.PersistentFlags().StringVar(&awsEndpoint, "aws-endpoint", "", "EC2 endpoint URL to use instead of AWS's, e.g. LocalStack's")
+	rootCmd.PersistentFlags().BoolVar(&awsUseFIPS, "aws-use-fips-endpoint", false, "Use the FIPS endpoints of the region's AWS partition")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().Float32Var(&clientQPS, "qps", multicluster.DefaultQPS, "Client-side QPS limit for Kubernetes API requests")
+	rootCmd.PersistentFlags().IntVar(&clientBurst, "burst", multicluster.DefaultBurst, "Client-side burst limit for Kubernetes API requests")
+	rootCmd.PersistentFlags().StringVar(&pushgatewayURL, "pushgateway-url", "", "Prometheus Pushgateway URL to push step and detach metrics to when the command finishes")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logFormatText, "Output format: text or json (one JSON record per step or result)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only print results and errors")
+
+	// Shell completion hints for the global flags
+	_ = rootCmd.MarkPersistentFlagFilename("source-kubeconfig")
+	_ = rootCmd.MarkPersistentFlagFilename("dest-kubeconfig")
+	_ = rootCmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions(
+		[]string{logFormatText, logFormatJSON}, cobra.ShellCompDirectiveNoFileComp))
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := configureOutput(logFormat, quiet); err != nil {
+			return err
+		}
+		// Flags parsed; runtime failures should not print usage into the output
+		cmd.SilenceUsage = true
+		return nil
+	}
+	rootCmd.SilenceErrors = true
 
 	// Add commands
 	rootCmd.AddCommand(inspectPVCmd())
 	rootCmd.AddCommand(inspectPVCCmd())
 	rootCmd.AddCommand(translateCmd())
 	rootCmd.AddCommand(waitDetachCmd())
+	rootCmd.AddCommand(waitAttachCmd())
+	rootCmd.AddCommand(estimateDetachCmd())
 	rootCmd.AddCommand(migrateVolumeCmd())
 	rootCmd.AddCommand(validateCmd())
+	rootCmd.AddCommand(assessCmd())
+	rootCmd.AddCommand(diffCmd())
+	rootCmd.AddCommand(simulateMappingCmd())
+	rootCmd.AddCommand(podOrderCmd())
+	rootCmd.AddCommand(verifyBindCmd())
+	rootCmd.AddCommand(inspectVolumeCmd())
+	rootCmd.AddCommand(conformanceCmd())
+	rootCmd.AddCommand(generateCRCmd())
+	rootCmd.AddCommand(historyCmd())
+	rootCmd.AddCommand(supportBundleCmd())
+	rootCmd.AddCommand(approveCmd())
+	rootCmd.AddCommand(retryCmd())
+	rootCmd.AddCommand(tuiCmd())
+	rootCmd.AddCommand(genDocsCmd())
+
+	err := rootCmd.Execute()
+
+	// Push even when the command failed so failed steps reach the dashboards
+	if pushgatewayURL != "" {
+		if pushErr := metrics.Push(pushgatewayURL, "storagemover"); pushErr != nil {
+			out.Warn(pushErr)
+		}
+	}
+
+	if err != nil {
+		out.Error(err)
+		os.Exit(exitCode(err))
+	}
+}
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+// exitCode maps AWS error kinds to sysexits codes so scripts can tell a
+// throttled call worth retrying from a failure that needs a config fix
+func exitCode(err error) int {
+	switch aws.KindOf(err) {
+	case aws.ErrThrottled:
+		return 75 // EX_TEMPFAIL
+	case aws.ErrUnauthorized:
+		return 77 // EX_NOPERM
+	case aws.ErrNotFound:
+		return 66 // EX_NOINPUT
+	case aws.ErrWrongRegion:
+		return 78 // EX_CONFIG
+	default:
+		return 1
 	}
 }
 
@@ -119,7 +214,7 @@ func inspectPVCCmd() *cobra.Command {
 			if pvc.Spec.VolumeName != "" {
 				pv := &corev1.PersistentVolume{}
 				if err := c.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err == nil {
-					fmt.Println("\nBound PV:")
+					out.Println("\nBound PV:")
 					printPVInfo(pv)
 				}
 			}
@@ -139,30 +234,55 @@ func inspectPVCCmd() *cobra.Command {
 func translateCmd() *cobra.Command {
 	var namespace string
 	var pvcName string
+	var pvName string
 	var destNamespace string
 	var destPVCName string
+	var pvNameTemplate string
+	var passthrough translate.MetadataPassthrough
+	var dataSourcePolicy string
+	var outputFormat string
 
 	cmd := &cobra.Command{
 		Use:   "translate",
 		Short: "Translate a PV/PVC from source to destination format",
-		Long:  "Shows what the destination PV and PVC would look like without creating them",
+		Long: `Shows what the destination PV and PVC would look like without creating them.
+
+Name the source PVC with --name, or, for a statically provisioned PV that was
+pre-created by hand and has no claim yet, the PV with --pv and the destination
+PVC with --dest-pvc-name. The destination PVC then claims all of the PV.
+
+With --output yaml, prints them as a manifest ready for kubectl apply instead.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
+			if outputFormat != "" && outputFormat != outputYAML {
+				return fmt.Errorf("unsupported --output %q (expected %q)", outputFormat, outputYAML)
+			}
 
 			c, err := getClient(sourceKubeconfig)
 			if err != nil {
 				return fmt.Errorf("failed to create client: %w", err)
 			}
 
-			// Get source PVC
-			pvc := &corev1.PersistentVolumeClaim{}
-			if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: pvcName}, pvc); err != nil {
-				return fmt.Errorf("failed to get PVC: %w", err)
+			var pvc *corev1.PersistentVolumeClaim
+			switch {
+			case pvcName != "" && pvName != "":
+				return fmt.Errorf("--name cannot be combined with --pv")
+			case pvName != "" && destPVCName == "":
+				return fmt.Errorf("--pv requires --dest-pvc-name")
+			case pvcName != "":
+				// Get source PVC
+				pvc = &corev1.PersistentVolumeClaim{}
+				if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: pvcName}, pvc); err != nil {
+					return fmt.Errorf("failed to get PVC: %w", err)
+				}
+				pvName = pvc.Spec.VolumeName
+			case pvName == "":
+				return fmt.Errorf("one of --name or --pv is required")
 			}
 
 			// Get source PV
 			pv := &corev1.PersistentVolume{}
-			if err := c.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+			if err := c.Get(ctx, types.NamespacedName{Name: pvName}, pv); err != nil {
 				return fmt.Errorf("failed to get PV: %w", err)
 			}
 
@@ -170,23 +290,41 @@ func translateCmd() *cobra.Command {
 			if destPVCName == "" {
 				destPVCName = pvcName
 			}
-			result, err := migration.TranslatePV(pv, pvc, migration.PVTranslationConfig{
+			result, err := translate.TranslatePV(pv, pvc, translate.PVTranslationConfig{
 				DestNamespace:        destNamespace,
 				DestPVCName:          destPVCName,
 				PreserveNodeAffinity: true,
+				PVNameTemplate:       pvNameTemplate,
+				Passthrough:          passthrough,
+				DataSourcePolicy:     translate.DataSourcePolicy(dataSourcePolicy),
 			})
 			if err != nil {
 				return fmt.Errorf("translation failed: %w", err)
 			}
+			for _, warning := range result.Warnings {
+				out.Warn(errors.New(warning))
+			}
 
-			fmt.Println("=== Translated PV ===")
+			if outputFormat == outputYAML {
+				manifest, err := result.Marshal()
+				if err != nil {
+					return err
+				}
+				out.Manifest(manifest)
+				return nil
+			}
+
+			out.Println("=== Translated PV ===")
 			printPVInfo(result.PV)
 
-			fmt.Println("\n=== Translated PVC ===")
+			out.Println("\n=== Translated PVC ===")
 			printPVCInfo(result.PVC)
 
-			fmt.Printf("\nVolume ID: %s\n", result.VolumeID)
-			fmt.Printf("Availability Zone: %s\n", result.AvailabilityZone)
+			out.Println()
+			out.Result("volume", []field{
+				{Key: "volumeID", Label: "Volume ID", Value: result.VolumeID},
+				{Key: "availabilityZone", Label: "Availability Zone", Value: result.AvailabilityZone},
+			})
 
 			return nil
 		},
@@ -194,76 +332,194 @@ func translateCmd() *cobra.Command {
 
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Source namespace")
 	cmd.Flags().StringVar(&pvcName, "name", "", "Source PVC name")
+	cmd.Flags().StringVar(&pvName, "pv", "", "Source PV name, for a pre-created PV with no claim")
 	cmd.Flags().StringVar(&destNamespace, "dest-namespace", "", "Destination namespace")
 	cmd.Flags().StringVar(&destPVCName, "dest-pvc-name", "", "Destination PVC name (defaults to source name)")
-	cmd.MarkFlagRequired("name")
+	cmd.Flags().StringVar(&pvNameTemplate, "pv-name-template", "", "Go template for the destination PV name (default \"migrated-{{.Namespace}}-{{.PVCName}}\")")
+	cmd.Flags().StringSliceVar(&passthrough.AnnotationPrefixes, "annotation-prefix", nil, "Copy source PV/PVC annotations with this key prefix (repeatable, \"*\" for all)")
+	cmd.Flags().StringSliceVar(&passthrough.LabelPrefixes, "label-prefix", nil, "Copy source PV/PVC labels with this key prefix (repeatable, \"*\" for all)")
+	cmd.Flags().StringVar(&dataSourcePolicy, "data-source-policy", string(translate.DataSourceStrip), "What to do with the source PVC's dataSource: Strip or Preserve")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Print the destination PV and PVC as a manifest: yaml")
 	cmd.MarkFlagRequired("dest-namespace")
 
 	return cmd
 }
 
-// waitDetachCmd waits for an EBS volume to detach
+// waitDetachCmd waits for one or more EBS volumes to detach
 func waitDetachCmd() *cobra.Command {
-	var volumeID string
+	var volumeIDs []string
+	var volumeIDsFile string
 	var timeout time.Duration
+	var forceDetach bool
 
 	cmd := &cobra.Command{
 		Use:   "wait-detach",
-		Short: "Wait for an EBS volume to detach",
+		Short: "Wait for EBS volumes to detach",
+		Long: `Waits for EBS volumes to detach and become available. Repeat --volume-id, or
+list the IDs in --volume-ids-file (one per line, # starts a comment), to wait
+for all of a StatefulSet's volumes at once; they are polled concurrently and
+their progress is printed as a table.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
 			if awsRegion == "" {
 				return fmt.Errorf("AWS region is required (--aws-region or AWS_REGION env var)")
 			}
+			if volumeIDsFile != "" {
+				fromFile, err := readVolumeIDs(volumeIDsFile)
+				if err != nil {
+					return err
+				}
+				volumeIDs = append(volumeIDs, fromFile...)
+			}
+			volumeIDs = dedupe(volumeIDs)
+			if len(volumeIDs) == 0 {
+				return fmt.Errorf("either --volume-id or --volume-ids-file is required")
+			}
 
 			ebsClient, err := aws.NewEBSClient(ctx, aws.EBSClientConfig{
-				Region: awsRegion,
+				Region:          awsRegion,
+				Endpoint:        awsEndpoint,
+				UseFIPSEndpoint: awsUseFIPS,
 			})
 			if err != nil {
 				return fmt.Errorf("failed to create EBS client: %w", err)
 			}
 
+			if len(volumeIDs) > 1 {
+				return waitDetachAll(ctx, ebsClient, volumeIDs, timeout, forceDetach)
+			}
+			volumeID := volumeIDs[0]
+
 			// Get initial state
 			info, err := ebsClient.GetVolumeInfo(ctx, volumeID)
 			if err != nil {
 				return fmt.Errorf("failed to get volume info: %w", err)
 			}
 
-			fmt.Printf("Volume: %s\n", volumeID)
-			fmt.Printf("Initial state: %s\n", aws.VolumeStateString(info.State))
-			fmt.Printf("AZ: %s\n", info.AvailabilityZone)
+			out.Printf("Volume: %s\n", volumeID)
+			out.Printf("Initial state: %s\n", aws.VolumeStateString(info.State))
+			out.Printf("AZ: %s\n", info.AvailabilityZone)
+			if info.MultiAttachEnabled {
+				out.Println("Multi-Attach: enabled (waiting for every attachment to detach)")
+			}
 
 			if len(info.Attachments) > 0 {
-				fmt.Println("Attachments:")
+				out.Println("Attachments:")
 				for _, att := range info.Attachments {
-					fmt.Printf("  - Instance: %s, Device: %s, State: %s\n",
+					out.Printf("  - Instance: %s, Device: %s, State: %s\n",
 						att.InstanceID, att.Device, att.State)
 				}
 			}
 
-			fmt.Printf("\nWaiting for volume to become available (timeout: %v)...\n", timeout)
+			out.Printf("\nWaiting for volume to become available (timeout: %v)...\n", timeout)
+
+			detachStart := time.Now()
+			progress, err := ebsClient.WaitForVolumeDetach(ctx, volumeID, aws.WaitForVolumeDetachConfig{
+				Timeout: timeout,
+				OnPoll: func(info *aws.VolumeInfo, progress aws.DetachProgress) {
+					if verbose {
+						out.Printf("  State: %s (%s)\n", aws.VolumeStateString(info.State), progress)
+					}
+				},
+				ForceDetach:   forceDetach,
+				OnForceDetach: onForceDetach(volumeID),
+			})
+
+			if err != nil {
+				observeStep(controller.StepDetachVolume, err, append(detachAttrs(err), "volumeID", volumeID)...)
+				return fmt.Errorf("wait failed: %w", err)
+			}
+			detachDuration := time.Since(detachStart)
+			metrics.ObserveVolumeDetach(detachDuration)
+			observeStep(controller.StepDetachVolume, nil, "volumeID", volumeID, "durationSeconds", detachDuration.Seconds(), "polls", progress.Polls)
+
+			out.Report("volume", "Volume is now available!", "volumeID", volumeID, "state", "available")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&volumeIDs, "volume-id", nil, "EBS volume ID (e.g., vol-0123456789abcdef0); repeat or comma-separate to wait for several")
+	cmd.Flags().StringVar(&volumeIDsFile, "volume-ids-file", "", "File listing EBS volume IDs, one per line")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Maximum time to wait for each volume")
+	cmd.Flags().BoolVar(&forceDetach, "force-detach", false, "Force-detach the volume if its instance is stopped, shutting down or terminated")
+	_ = cmd.MarkFlagFilename("volume-ids-file")
 
-			err = ebsClient.WaitForVolumeDetach(ctx, volumeID, aws.WaitForVolumeDetachConfig{
+	return cmd
+}
+
+// waitAttachCmd waits for an EBS volume to attach to an instance of the destination cluster
+func waitAttachCmd() *cobra.Command {
+	var volumeID string
+	var clusterName string
+	var instanceTags map[string]string
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "wait-attach",
+		Short: "Wait for an EBS volume to attach to a destination cluster instance",
+		Long: `Polls the EBS volume until it is attached to an EC2 instance belonging to the
+destination cluster, confirming the cutover from the storage side. Instances
+are matched by tag: --cluster-name matches the kubernetes.io/cluster/<name>
+tag EKS puts on every node, and --instance-tag adds tags of your own (an empty
+value matches any value). Attachments to other instances are waited out.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if awsRegion == "" {
+				return fmt.Errorf("AWS region is required (--aws-region or AWS_REGION env var)")
+			}
+			tags := make(map[string]string)
+			for key, value := range instanceTags {
+				tags[key] = value
+			}
+			if clusterName != "" {
+				tags[aws.ClusterTagPrefix+clusterName] = ""
+			}
+			if len(tags) == 0 {
+				return fmt.Errorf("either --cluster-name or --instance-tag is required")
+			}
+
+			ebsClient, err := aws.NewEBSClient(ctx, aws.EBSClientConfig{
+				Region:          awsRegion,
+				Endpoint:        awsEndpoint,
+				UseFIPSEndpoint: awsUseFIPS,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create EBS client: %w", err)
+			}
+
+			out.Printf("Waiting for volume %s to attach to a destination instance (timeout: %v)...\n", volumeID, timeout)
+
+			attachStart := time.Now()
+			att, err := ebsClient.WaitForVolumeAttach(ctx, volumeID, aws.WaitForVolumeAttachConfig{
 				Timeout:      timeout,
 				PollInterval: 5 * time.Second,
+				InstanceTags: tags,
 				OnPoll: func(info *aws.VolumeInfo) {
 					if verbose {
-						fmt.Printf("  State: %s\n", aws.VolumeStateString(info.State))
+						attachments := "none"
+						if len(info.Attachments) > 0 {
+							attachments = aws.FormatAttachments(info.Attachments)
+						}
+						out.Printf("  State: %s, attachments: %s\n", aws.VolumeStateString(info.State), attachments)
 					}
 				},
 			})
-
 			if err != nil {
 				return fmt.Errorf("wait failed: %w", err)
 			}
 
-			fmt.Println("Volume is now available!")
+			out.Report("volume", fmt.Sprintf("Volume is attached to destination instance %s as %s", att.InstanceID, att.Device),
+				"volumeID", volumeID, "state", "in-use", "instanceID", att.InstanceID, "device", att.Device,
+				"durationSeconds", time.Since(attachStart).Seconds())
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&volumeID, "volume-id", "", "EBS volume ID (e.g., vol-0123456789abcdef0)")
+	cmd.Flags().StringVar(&clusterName, "cluster-name", "", "Name of the destination EKS cluster, matched against the kubernetes.io/cluster/<name> instance tag")
+	cmd.Flags().StringToStringVar(&instanceTags, "instance-tag", nil, "Tag a destination instance must carry, e.g. eks:nodegroup-name=db (repeatable)")
 	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Maximum time to wait")
 	cmd.MarkFlagRequired("volume-id")
 
@@ -276,8 +532,14 @@ func migrateVolumeCmd() *cobra.Command {
 	var pvcName string
 	var destNamespace string
 	var destPVCName string
+	var pvNameTemplate string
+	var passthrough translate.MetadataPassthrough
+	var dataSourcePolicy string
+	var adoptPVC bool
+	var strictClaimRef bool
 	var dryRun bool
 	var timeout time.Duration
+	var forceDetach bool
 
 	cmd := &cobra.Command{
 		Use:   "migrate-volume",
@@ -285,7 +547,10 @@ func migrateVolumeCmd() *cobra.Command {
 		Long: `Performs a complete volume migration:
 1. Gets the source PVC and PV
 2. Waits for the EBS volume to be available
-3. Creates the PV and PVC in the destination cluster`,
+3. Creates the PV and PVC in the destination cluster
+
+With --adopt-pvc, a destination PVC that already exists (for example one
+created by GitOps) is kept and the PV is pre-bound to it.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
@@ -304,14 +569,16 @@ func migrateVolumeCmd() *cobra.Command {
 			}
 
 			ebsClient, err := aws.NewEBSClient(ctx, aws.EBSClientConfig{
-				Region: awsRegion,
+				Region:          awsRegion,
+				Endpoint:        awsEndpoint,
+				UseFIPSEndpoint: awsUseFIPS,
 			})
 			if err != nil {
 				return fmt.Errorf("failed to create EBS client: %w", err)
 			}
 
 			// Step 1: Get source PVC and PV
-			fmt.Printf("Getting source PVC %s/%s...\n", sourceNamespace, pvcName)
+			out.Printf("Getting source PVC %s/%s...\n", sourceNamespace, pvcName)
 			sourcePVC := &corev1.PersistentVolumeClaim{}
 			if err := sourceClient.Get(ctx, types.NamespacedName{Namespace: sourceNamespace, Name: pvcName}, sourcePVC); err != nil {
 				return fmt.Errorf("failed to get source PVC: %w", err)
@@ -322,59 +589,128 @@ func migrateVolumeCmd() *cobra.Command {
 				return fmt.Errorf("failed to get source PV: %w", err)
 			}
 
+			// The PV's capacity can lag behind the volume's size
+			volumeID, err := translate.EBSVolumeID(sourcePV)
+			if err != nil {
+				return err
+			}
+			info, err := ebsClient.GetVolumeInfo(ctx, volumeID)
+			if err != nil {
+				return err
+			}
+
 			// Step 2: Translate
 			if destPVCName == "" {
 				destPVCName = pvcName
 			}
-			result, err := migration.TranslatePV(sourcePV, sourcePVC, migration.PVTranslationConfig{
+			var existingPVC *corev1.PersistentVolumeClaim
+			if adoptPVC {
+				existingPVC = &corev1.PersistentVolumeClaim{}
+				if err := destClient.Get(ctx, types.NamespacedName{Namespace: destNamespace, Name: destPVCName}, existingPVC); err != nil {
+					if !apierrors.IsNotFound(err) {
+						return fmt.Errorf("failed to get destination PVC: %w", err)
+					}
+					existingPVC = nil
+				}
+			}
+			result, err := translate.TranslatePV(sourcePV, sourcePVC, translate.PVTranslationConfig{
 				DestNamespace:        destNamespace,
 				DestPVCName:          destPVCName,
 				PreserveNodeAffinity: true,
+				PVNameTemplate:       pvNameTemplate,
+				Passthrough:          passthrough,
+				DataSourcePolicy:     translate.DataSourcePolicy(dataSourcePolicy),
+				ExistingPVC:          existingPVC,
+				VolumeSizeGiB:        info.Size,
 			})
 			if err != nil {
 				return fmt.Errorf("translation failed: %w", err)
 			}
+			for _, warning := range result.Warnings {
+				out.Warn(errors.New(warning))
+			}
 
-			fmt.Printf("Volume ID: %s\n", result.VolumeID)
-			fmt.Printf("AZ: %s\n", result.AvailabilityZone)
+			out.Printf("Volume ID: %s\n", result.VolumeID)
+			out.Printf("AZ: %s\n", result.AvailabilityZone)
+			if err := ebsClient.CheckVolumeRegion(result.VolumeID, result.AvailabilityZone); err != nil {
+				return err
+			}
 
 			// Step 3: Wait for volume to be available
-			fmt.Printf("Waiting for volume to be available (timeout: %v)...\n", timeout)
-			err = ebsClient.WaitForVolumeDetach(ctx, result.VolumeID, aws.WaitForVolumeDetachConfig{
-				Timeout:      timeout,
-				PollInterval: 5 * time.Second,
-				OnPoll: func(info *aws.VolumeInfo) {
-					fmt.Printf("  Volume state: %s\n", aws.VolumeStateString(info.State))
+			out.Printf("Waiting for volume to be available (timeout: %v)...\n", timeout)
+			detachStart := time.Now()
+			progress, err := ebsClient.WaitForVolumeDetach(ctx, result.VolumeID, aws.WaitForVolumeDetachConfig{
+				Timeout: timeout,
+				OnPoll: func(info *aws.VolumeInfo, progress aws.DetachProgress) {
+					out.Printf("  Volume state: %s (%s)\n", aws.VolumeStateString(info.State), progress)
 				},
+				ForceDetach:   forceDetach,
+				OnForceDetach: onForceDetach(result.VolumeID),
 			})
 			if err != nil {
+				observeStep(controller.StepDetachVolume, err, append(detachAttrs(err), "volumeID", result.VolumeID)...)
 				return fmt.Errorf("volume not available: %w", err)
 			}
+			detachDuration := time.Since(detachStart)
+			metrics.ObserveVolumeDetach(detachDuration)
+			observeStep(controller.StepDetachVolume, nil, "volumeID", result.VolumeID, "durationSeconds", detachDuration.Seconds(), "polls", progress.Polls)
 
 			if dryRun {
-				fmt.Println("\n[DRY RUN] Would create the following resources:")
-				fmt.Printf("PV: %s\n", result.PV.Name)
-				fmt.Printf("PVC: %s/%s\n", result.PVC.Namespace, result.PVC.Name)
+				out.Println("\n[DRY RUN] Would create the following resources:")
+				out.Result("migration", []field{
+					{Key: "dryRun", Label: "Dry Run", Value: true},
+					{Key: "pv", Label: "PV", Value: result.PV.Name},
+					{Key: "pvc", Label: "PVC", Value: result.PVC.Namespace + "/" + result.PVC.Name},
+				})
 				return nil
 			}
 
+			// With --strict-claim-ref the PVC comes first, so the PV names its UID
+			strict := strictClaimRef && !result.PVCAdopted
+			if strict {
+				out.Printf("Creating PVC %s/%s in destination...\n", result.PVC.Namespace, result.PVC.Name)
+				err = destClient.Create(ctx, result.PVC)
+				observeStep(controller.StepCreatePVC, err, "pvc", result.PVC.Namespace+"/"+result.PVC.Name)
+				if err != nil {
+					return fmt.Errorf("failed to create destination PVC: %w", err)
+				}
+				result.PV.Spec.ClaimRef.UID = result.PVC.UID
+			}
+
 			// Step 4: Create PV in destination
-			fmt.Printf("Creating PV %s in destination...\n", result.PV.Name)
-			if err := destClient.Create(ctx, result.PV); err != nil {
+			out.Printf("Creating PV %s in destination...\n", result.PV.Name)
+			err = destClient.Create(ctx, result.PV)
+			observeStep(controller.StepCreatePV, err, "pv", result.PV.Name)
+			if err != nil {
+				if strict {
+					// Clean up the PVC waiting for the PV (ignore cleanup error)
+					_ = destClient.Delete(ctx, result.PVC)
+				}
 				return fmt.Errorf("failed to create destination PV: %w", err)
 			}
 
-			// Step 5: Create PVC in destination
-			fmt.Printf("Creating PVC %s/%s in destination...\n", result.PVC.Namespace, result.PVC.Name)
-			if err := destClient.Create(ctx, result.PVC); err != nil {
-				// Clean up PV if PVC creation fails (ignore cleanup error)
-				_ = destClient.Delete(ctx, result.PV)
-				return fmt.Errorf("failed to create destination PVC: %w", err)
+			// Step 5: Create PVC in destination, or keep the adopted one
+			switch {
+			case result.PVCAdopted:
+				out.Printf("Adopted existing PVC %s/%s (UID %s)\n", result.PVC.Namespace, result.PVC.Name, result.PVC.UID)
+				observeStep(controller.StepAdoptPVC, nil, "pvc", result.PVC.Namespace+"/"+result.PVC.Name)
+			case !strict:
+				out.Printf("Creating PVC %s/%s in destination...\n", result.PVC.Namespace, result.PVC.Name)
+				err = destClient.Create(ctx, result.PVC)
+				observeStep(controller.StepCreatePVC, err, "pvc", result.PVC.Namespace+"/"+result.PVC.Name)
+				if err != nil {
+					// Clean up PV if PVC creation fails (ignore cleanup error)
+					_ = destClient.Delete(ctx, result.PV)
+					return fmt.Errorf("failed to create destination PVC: %w", err)
+				}
 			}
 
-			fmt.Println("\nMigration complete!")
-			fmt.Printf("PV: %s\n", result.PV.Name)
-			fmt.Printf("PVC: %s/%s\n", result.PVC.Namespace, result.PVC.Name)
+			out.Println("\nMigration complete!")
+			out.Result("migration", []field{
+				{Key: "volumeID", Label: "Volume ID", Value: result.VolumeID},
+				{Key: "pv", Label: "PV", Value: result.PV.Name},
+				{Key: "pvc", Label: "PVC", Value: result.PVC.Namespace + "/" + result.PVC.Name},
+			})
 
 			return nil
 		},
@@ -384,8 +720,15 @@ func migrateVolumeCmd() *cobra.Command {
 	cmd.Flags().StringVar(&pvcName, "pvc", "", "Source PVC name")
 	cmd.Flags().StringVarP(&destNamespace, "dest-namespace", "d", "", "Destination namespace")
 	cmd.Flags().StringVar(&destPVCName, "dest-pvc-name", "", "Destination PVC name (defaults to source name)")
+	cmd.Flags().StringVar(&pvNameTemplate, "pv-name-template", "", "Go template for the destination PV name (default \"migrated-{{.Namespace}}-{{.PVCName}}\")")
+	cmd.Flags().StringSliceVar(&passthrough.AnnotationPrefixes, "annotation-prefix", nil, "Copy source PV/PVC annotations with this key prefix (repeatable, \"*\" for all)")
+	cmd.Flags().StringSliceVar(&passthrough.LabelPrefixes, "label-prefix", nil, "Copy source PV/PVC labels with this key prefix (repeatable, \"*\" for all)")
+	cmd.Flags().StringVar(&dataSourcePolicy, "data-source-policy", string(translate.DataSourceStrip), "What to do with the source PVC's dataSource: Strip or Preserve")
+	cmd.Flags().BoolVar(&adoptPVC, "adopt-pvc", false, "Pre-bind the PV to the destination PVC if it already exists instead of creating the PVC")
+	cmd.Flags().BoolVar(&strictClaimRef, "strict-claim-ref", false, "Create the PVC before the PV and pre-bind the PV to the PVC's UID")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be created without actually creating")
 	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Timeout for volume detachment")
+	cmd.Flags().BoolVar(&forceDetach, "force-detach", false, "Force-detach the volume if its instance is stopped, shutting down or terminated")
 	cmd.MarkFlagRequired("pvc")
 	cmd.MarkFlagRequired("dest-namespace")
 	cmd.MarkFlagRequired("source-kubeconfig")
@@ -394,52 +737,136 @@ func migrateVolumeCmd() *cobra.Command {
 	return cmd
 }
 
-// validateCmd validates a PV for migration
-func validateCmd() *cobra.Command {
-	var pvName string
+// assessCmd reports which StatefulSets in the source cluster can be migrated
+func assessCmd() *cobra.Command {
+	var namespace string
+	var destNamespace string
+	var allNamespaces bool
 
 	cmd := &cobra.Command{
-		Use:   "validate",
-		Short: "Validate a PV is suitable for migration",
+		Use:   "assess",
+		Short: "Report which StatefulSets can be migrated (read-only)",
+		Long: `Scans a namespace (or the whole cluster) in the source cluster and reports
+which StatefulSets are migratable as-is, which need --force, and which are
+blocked. When --dest-kubeconfig is set, destination prerequisites are checked
+too. Nothing is modified.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
-			c, err := getClient(sourceKubeconfig)
+			if namespace == "" && !allNamespaces {
+				return fmt.Errorf("either --namespace or --all-namespaces is required")
+			}
+			if allNamespaces {
+				namespace = ""
+			}
+
+			source, err := getClient(sourceKubeconfig)
 			if err != nil {
-				return fmt.Errorf("failed to create client: %w", err)
+				return fmt.Errorf("failed to create source client: %w", err)
 			}
 
-			pv := &corev1.PersistentVolume{}
-			if err := c.Get(ctx, types.NamespacedName{Name: pvName}, pv); err != nil {
-				return fmt.Errorf("failed to get PV: %w", err)
+			cfg := assessment.ScanConfig{
+				Namespace:     namespace,
+				DestNamespace: destNamespace,
+			}
+			if destKubeconfig != "" {
+				dest, err := getClient(destKubeconfig)
+				if err != nil {
+					return fmt.Errorf("failed to create destination client: %w", err)
+				}
+				cfg.Dest = dest
 			}
 
-			if err := migration.ValidatePVForMigration(pv); err != nil {
-				fmt.Printf("❌ Validation failed: %v\n", err)
+			results, err := assessment.Scan(ctx, source, cfg)
+			if err != nil {
 				return err
 			}
 
-			fmt.Println("✅ PV is valid for migration")
-
-			// Additional info
-			if pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
-				fmt.Printf("⚠️  Warning: Reclaim policy is %s (should be Retain for safe migration)\n",
-					pv.Spec.PersistentVolumeReclaimPolicy)
+			for _, r := range results {
+				icon := "✅"
+				switch r.Verdict {
+				case migrationv1alpha1.VerdictNeedsForce:
+					icon = "⚠️ "
+				case migrationv1alpha1.VerdictBlocked:
+					icon = "❌"
+				}
+				line := fmt.Sprintf("%s %s/%s (replicas: %d): %s", icon, r.Namespace, r.Name, r.Replicas, r.Verdict)
+				for _, reason := range r.Reasons {
+					line += fmt.Sprintf("\n     - %s", reason)
+				}
+				out.Report("assessment", line,
+					"namespace", r.Namespace, "name", r.Name, "replicas", r.Replicas,
+					"verdict", string(r.Verdict), "reasons", r.Reasons)
 			}
 
+			summary := assessment.Summarize(results)
+			out.Println()
+			out.Report("summary", fmt.Sprintf("Total: %d  Migratable: %d  NeedsForce: %d  Blocked: %d",
+				summary.Total, summary.Migratable, summary.NeedsForce, summary.Blocked),
+				"total", summary.Total, "migratable", summary.Migratable,
+				"needsForce", summary.NeedsForce, "blocked", summary.Blocked)
+
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&pvName, "name", "", "Name of the PV to validate")
-	cmd.MarkFlagRequired("name")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to scan in the source cluster")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Scan all namespaces in the source cluster")
+	cmd.Flags().StringVar(&destNamespace, "dest-namespace", "", "Destination namespace (defaults to each StatefulSet's namespace)")
 
 	return cmd
 }
 
 // Helper functions
 
+// observeStep records a step outcome using the same labels as the controller
+// and emits it as a step record
+func observeStep(step string, err error, attrs ...any) {
+	result := migrationv1alpha1.HistoryResultSucceeded
+	if err != nil {
+		result = migrationv1alpha1.HistoryResultFailed
+	}
+	metrics.ObserveStep(step, string(result))
+	out.Step(step, err, attrs...)
+}
+
+// onForceDetach reports a force-detach before it is issued
+func onForceDetach(volumeID string) func(aws.InstanceHealth) {
+	return func(instance aws.InstanceHealth) {
+		out.Printf("  Instance %s is %s; force-detaching volume\n", instance.InstanceID, instance)
+		observeStep(controller.StepForceDetach, nil, "volumeID", volumeID,
+			"instanceID", instance.InstanceID, "instanceState", instance.String())
+	}
+}
+
+// detachAttrs returns the detach progress carried by a failed wait as step record attrs
+func detachAttrs(err error) []any {
+	var waitErr *aws.DetachWaitError
+	if !errors.As(err, &waitErr) {
+		return nil
+	}
+	return []any{
+		"phase", string(waitErr.Progress.Phase),
+		"inPhaseSeconds", waitErr.Progress.InPhase().Seconds(),
+		"durationSeconds", waitErr.Progress.Elapsed().Seconds(),
+		"remainingAttachments", aws.FormatAttachments(waitErr.Remaining),
+	}
+}
+
 func getClient(kubeconfigPath string) (client.Client, error) {
+	config, err := getRESTConfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	scheme, err := getScheme()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(config, client.Options{Scheme: scheme})
+}
+
+// getRESTConfig loads a kubeconfig, $KUBECONFIG when the path is empty, with the client settings applied
+func getRESTConfig(kubeconfigPath string) (*rest.Config, error) {
 	if kubeconfigPath == "" {
 		kubeconfigPath = os.Getenv("KUBECONFIG")
 	}
@@ -448,57 +875,84 @@ func getClient(kubeconfigPath string) (client.Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	multicluster.ApplyClientSettings(config, multicluster.ClientSettings{
+		QPS:       clientQPS,
+		Burst:     clientBurst,
+		UserAgent: "storagemover",
+	})
+	return config, nil
+}
 
+// getScheme returns the scheme of the objects the CLI reads and writes
+func getScheme() (*runtime.Scheme, error) {
 	scheme := runtime.NewScheme()
 	if err := corev1.AddToScheme(scheme); err != nil {
 		return nil, err
 	}
-
-	return client.New(config, client.Options{Scheme: scheme})
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := storagev1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
 }
 
 func printPVInfo(pv *corev1.PersistentVolume) {
-	fmt.Printf("Name: %s\n", pv.Name)
-	fmt.Printf("Status: %s\n", pv.Status.Phase)
-	fmt.Printf("Capacity: %s\n", pv.Spec.Capacity.Storage().String())
-	fmt.Printf("Access Modes: %v\n", pv.Spec.AccessModes)
-	fmt.Printf("Reclaim Policy: %s\n", pv.Spec.PersistentVolumeReclaimPolicy)
-	fmt.Printf("Storage Class: %s\n", pv.Spec.StorageClassName)
+	fields := []field{
+		{Key: "name", Label: "Name", Value: pv.Name},
+		{Key: "status", Label: "Status", Value: string(pv.Status.Phase)},
+		{Key: "capacity", Label: "Capacity", Value: pv.Spec.Capacity.Storage().String()},
+		{Key: "accessModes", Label: "Access Modes", Value: pv.Spec.AccessModes},
+		{Key: "reclaimPolicy", Label: "Reclaim Policy", Value: string(pv.Spec.PersistentVolumeReclaimPolicy)},
+		{Key: "storageClass", Label: "Storage Class", Value: pv.Spec.StorageClassName},
+	}
 
 	if pv.Spec.ClaimRef != nil {
-		fmt.Printf("Claim: %s/%s\n", pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name)
+		fields = append(fields, field{Key: "claim", Label: "Claim", Value: pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name})
 	}
 
 	if pv.Spec.CSI != nil {
-		fmt.Printf("CSI Driver: %s\n", pv.Spec.CSI.Driver)
-		fmt.Printf("Volume Handle: %s\n", pv.Spec.CSI.VolumeHandle)
+		fields = append(fields,
+			field{Key: "csiDriver", Label: "CSI Driver", Value: pv.Spec.CSI.Driver},
+			field{Key: "volumeHandle", Label: "Volume Handle", Value: pv.Spec.CSI.VolumeHandle},
+		)
 	} else if pv.Spec.AWSElasticBlockStore != nil {
-		fmt.Printf("EBS Volume ID: %s\n", pv.Spec.AWSElasticBlockStore.VolumeID)
+		fields = append(fields, field{Key: "ebsVolumeID", Label: "EBS Volume ID", Value: pv.Spec.AWSElasticBlockStore.VolumeID})
 	}
 
 	if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
 		for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
 			for _, expr := range term.MatchExpressions {
 				if expr.Key == "topology.kubernetes.io/zone" {
-					fmt.Printf("Zone: %v\n", expr.Values)
+					fields = append(fields, field{Key: "zone", Label: "Zone", Value: expr.Values})
 				}
 			}
 		}
 	}
+
+	out.Result("pv", fields)
 }
 
 func printPVCInfo(pvc *corev1.PersistentVolumeClaim) {
-	fmt.Printf("Name: %s\n", pvc.Name)
-	fmt.Printf("Namespace: %s\n", pvc.Namespace)
-	fmt.Printf("Status: %s\n", pvc.Status.Phase)
-	fmt.Printf("Volume: %s\n", pvc.Spec.VolumeName)
-	fmt.Printf("Access Modes: %v\n", pvc.Spec.AccessModes)
+	fields := []field{
+		{Key: "name", Label: "Name", Value: pvc.Name},
+		{Key: "namespace", Label: "Namespace", Value: pvc.Namespace},
+		{Key: "status", Label: "Status", Value: string(pvc.Status.Phase)},
+		{Key: "volume", Label: "Volume", Value: pvc.Spec.VolumeName},
+		{Key: "accessModes", Label: "Access Modes", Value: pvc.Spec.AccessModes},
+	}
 
 	if pvc.Spec.StorageClassName != nil {
-		fmt.Printf("Storage Class: %s\n", *pvc.Spec.StorageClassName)
+		fields = append(fields, field{Key: "storageClass", Label: "Storage Class", Value: *pvc.Spec.StorageClassName})
 	}
 
 	if req, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
-		fmt.Printf("Requested: %s\n", req.String())
+		fields = append(fields, field{Key: "requested", Label: "Requested", Value: req.String()})
 	}
+
+	out.Result("pvc", fields)
 }
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+)
+
+// simulateMappingCmd previews what a StorageClass mapping does to the source cluster's PVs
+func simulateMappingCmd() *cobra.Command {
+	var namespace string
+	var storageClassMapping map[string]string
+
+	cmd := &cobra.Command{
+		Use:   "simulate-mapping",
+		Short: "Preview which PVs a StorageClass mapping would change (read-only)",
+		Long: `Applies a proposed --storage-class-mapping to the PVs in the source cluster,
+without changing anything, and prints every PV whose StorageClass would
+change. For each StorageClass in use it then shows the class it maps to, the
+parameters and settings that differ between the two, and any downgrade in
+volume type, IOPS, throughput or encryption. StorageClasses in use that the
+mapping does not cover, and mapping entries no PV uses, are flagged.
+
+Destination StorageClasses are read from --dest-kubeconfig when it is set and
+from the source cluster otherwise. The command fails when a StorageClass the
+PVs would get does not exist, since their PVCs would never bind.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			source, err := getClient(sourceKubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to create source client: %w", err)
+			}
+			dest := source
+			if destKubeconfig != "" {
+				if dest, err = getClient(destKubeconfig); err != nil {
+					return fmt.Errorf("failed to create destination client: %w", err)
+				}
+			}
+
+			pvList := &corev1.PersistentVolumeList{}
+			if err := source.List(ctx, pvList); err != nil {
+				return fmt.Errorf("failed to list source PVs: %w", err)
+			}
+			pvs := pvList.Items
+			if namespace != "" {
+				pvs = nil
+				for _, pv := range pvList.Items {
+					if pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.Namespace == namespace {
+						pvs = append(pvs, pv)
+					}
+				}
+			}
+			sourceClasses, err := listStorageClasses(ctx, source)
+			if err != nil {
+				return fmt.Errorf("failed to list source StorageClasses: %w", err)
+			}
+			destClasses, err := listStorageClasses(ctx, dest)
+			if err != nil {
+				return fmt.Errorf("failed to list destination StorageClasses: %w", err)
+			}
+
+			sim := migration.SimulateStorageClassMapping(pvs, storageClassMapping, sourceClasses, destClasses)
+
+			for _, v := range sim.Remapped {
+				line := fmt.Sprintf("PersistentVolume/%s: %s -> %s", v.PV, v.SourceClass, v.DestClass)
+				if v.Claim != "" {
+					line = fmt.Sprintf("PersistentVolume/%s (%s): %s -> %s", v.PV, v.Claim, v.SourceClass, v.DestClass)
+				}
+				out.Report("volume", line, "pv", v.PV, "claim", v.Claim, "sourceClass", v.SourceClass, "destClass", v.DestClass)
+			}
+			if len(sim.Remapped) > 0 {
+				out.Println()
+			}
+
+			missingDest := 0
+			for _, c := range sim.Classes {
+				var notes []string
+				if !c.Mapped {
+					notes = append(notes, "not mapped, keeps its name")
+				}
+				if c.SourceMissing {
+					notes = append(notes, "missing in the source cluster")
+				}
+				if c.DestMissing {
+					missingDest++
+					notes = append(notes, "missing in the destination")
+				}
+				line := fmt.Sprintf("StorageClass %s -> %s (%d volumes)", c.Source, c.Dest, c.Volumes)
+				if len(notes) > 0 {
+					line += ": " + strings.Join(notes, ", ")
+				}
+				for _, d := range c.Differences {
+					line += fmt.Sprintf("\n     %s: %s -> %s", d.Field, d.Source, d.Dest)
+				}
+				for _, downgrade := range c.Downgrades {
+					line += fmt.Sprintf("\n     ⚠️  %s", downgrade)
+				}
+				out.Report("class", line,
+					"sourceClass", c.Source, "destClass", c.Dest, "mapped", c.Mapped, "volumes", c.Volumes,
+					"sourceMissing", c.SourceMissing, "destMissing", c.DestMissing,
+					"differences", c.Differences, "downgrades", c.Downgrades)
+			}
+			for _, class := range sim.Unused {
+				out.Report("unused", fmt.Sprintf("Mapping %s=%s matches no PV", class, storageClassMapping[class]),
+					"sourceClass", class, "destClass", storageClassMapping[class])
+			}
+
+			unmapped := sim.Unmapped()
+			out.Println()
+			out.Report("summary", fmt.Sprintf("Volumes: %d  Remapped: %d  Unmapped classes: %d  Missing destination classes: %d",
+				len(pvs), len(sim.Remapped), len(unmapped), missingDest),
+				"volumes", len(pvs), "remapped", len(sim.Remapped), "unmapped", unmapped, "missingDestClasses", missingDest)
+
+			if missingDest > 0 {
+				return fmt.Errorf("%d destination StorageClasses do not exist", missingDest)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Only PVs bound to PVCs in this namespace (defaults to all PVs)")
+	cmd.Flags().StringToStringVar(&storageClassMapping, "storage-class-mapping", nil, "Proposed StorageClass mapping, e.g. gp2=gp3")
+	cmd.MarkFlagRequired("storage-class-mapping")
+
+	return cmd
+}
+
+// listStorageClasses returns a cluster's StorageClasses by name
+func listStorageClasses(ctx context.Context, c client.Client) (map[string]*storagev1.StorageClass, error) {
+	list := &storagev1.StorageClassList{}
+	if err := c.List(ctx, list); err != nil {
+		return nil, err
+	}
+	classes := make(map[string]*storagev1.StorageClass, len(list.Items))
+	for i := range list.Items {
+		classes[list.Items[i].Name] = &list.Items[i]
+	}
+	return classes, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+)
+
+// podOrderCmd previews the order spec.podOrder would migrate a StatefulSet's pods in
+func podOrderCmd() *cobra.Command {
+	var namespace string
+	var name string
+	var priorityLabel string
+	var priorityAnnotation string
+
+	cmd := &cobra.Command{
+		Use:   "pod-order",
+		Short: "Preview the order a StatefulSet's pods would migrate in by priority (read-only)",
+		Long: `Reads each pod's migration priority from --priority-label or
+--priority-annotation, as spec.podOrder does, and prints the order the
+controller would migrate the pods in: lowest priority first, pods without a
+priority at 0, and pods of equal priority by ordinal.
+
+The destination StatefulSet runs the pods moved so far, and a StatefulSet only
+runs a contiguous range of ordinals, so at every priority the pods at or below
+it must be contiguous. The command fails when they are not. An order that does
+not start at ordinal 0 needs a destination cluster running Kubernetes 1.27 or
+later.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			c, err := getClient(sourceKubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to create source client: %w", err)
+			}
+
+			sts := &appsv1.StatefulSet{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, sts); err != nil {
+				return fmt.Errorf("failed to get StatefulSet: %w", err)
+			}
+			selector, err := metav1.LabelSelectorAsSelector(sts.Spec.Selector)
+			if err != nil {
+				return fmt.Errorf("invalid StatefulSet selector: %w", err)
+			}
+			pods := &corev1.PodList{}
+			if err := c.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+				return fmt.Errorf("failed to list pods: %w", err)
+			}
+
+			replicas := 1
+			if sts.Spec.Replicas != nil {
+				replicas = int(*sts.Spec.Replicas)
+			}
+			priorities, err := migration.PodPriorities(pods.Items, name, replicas, priorityLabel, priorityAnnotation)
+			if err != nil {
+				return err
+			}
+			order, err := migration.MigrationOrder(priorities)
+			if err != nil {
+				return err
+			}
+
+			for position, ordinal := range order {
+				pod := fmt.Sprintf("%s-%d", name, ordinal)
+				out.Report("pod", fmt.Sprintf("%d. %s (priority %d)", position+1, pod, priorities[ordinal]),
+					"position", position+1, "pod", pod, "ordinal", ordinal, "priority", priorities[ordinal])
+			}
+			if len(order) > 0 && order[0] != 0 {
+				out.Println()
+				out.Report("note", fmt.Sprintf("The first pod to move is ordinal %d, so the destination must run Kubernetes 1.27 or later", order[0]),
+					"firstOrdinal", order[0])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Source namespace")
+	cmd.Flags().StringVar(&name, "name", "", "Name of the StatefulSet")
+	cmd.Flags().StringVar(&priorityLabel, "priority-label", "", "Pod label holding the migration priority")
+	cmd.Flags().StringVar(&priorityAnnotation, "priority-annotation", "", "Pod annotation holding the migration priority")
+	cmd.MarkFlagRequired("name")
+	cmd.MarkFlagsOneRequired("priority-label", "priority-annotation")
+	cmd.MarkFlagsMutuallyExclusive("priority-label", "priority-annotation")
+
+	return cmd
+}
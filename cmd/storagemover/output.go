@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+const (
+	// logFormatText prints human-readable progress output
+	logFormatText = "text"
+
+	// logFormatJSON prints one JSON record per step or result
+	logFormatJSON = "json"
+
+	// outputYAML prints generated objects as an apply-ready YAML manifest
+	outputYAML = "yaml"
+)
+
+// output writes CLI progress and results either as human-readable text or
+// as JSON records suitable for pipelines
+type output struct {
+	json   bool
+	quiet  bool
+	text   io.Writer
+	logger *slog.Logger
+	errors *slog.Logger
+}
+
+// field is a single labelled value in a result record
+type field struct {
+	// Key is the JSON attribute name
+	Key string
+
+	// Label is the human-readable name printed in text mode
+	Label string
+
+	// Value is the value to print
+	Value any
+}
+
+// out is the CLI's output, configured from the global flags before each command runs
+var out = newOutput(logFormatText, false)
+
+// newOutput creates an output for the given format and quiet setting
+func newOutput(format string, quiet bool) *output {
+	return &output{
+		json:   format == logFormatJSON,
+		quiet:  quiet,
+		text:   os.Stdout,
+		logger: slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		errors: slog.New(slog.NewJSONHandler(os.Stderr, nil)),
+	}
+}
+
+// configureOutput validates the output flags and replaces the CLI's output
+func configureOutput(format string, quiet bool) error {
+	if format != logFormatText && format != logFormatJSON {
+		return fmt.Errorf("unsupported --log-format %q (expected %q or %q)", format, logFormatText, logFormatJSON)
+	}
+	out = newOutput(format, quiet)
+	return nil
+}
+
+// Printf prints free-form progress text. It is suppressed in JSON and quiet modes.
+func (o *output) Printf(format string, args ...any) {
+	if o.json || o.quiet {
+		return
+	}
+	fmt.Fprintf(o.text, format, args...)
+}
+
+// Println prints a line of free-form progress text. It is suppressed in JSON and quiet modes.
+func (o *output) Println(args ...any) {
+	if o.json || o.quiet {
+		return
+	}
+	fmt.Fprintln(o.text, args...)
+}
+
+// Step emits a structured record for a completed step in JSON mode. Text mode
+// already narrates steps through Printf, so nothing extra is printed there.
+func (o *output) Step(step string, err error, attrs ...any) {
+	if !o.json || o.quiet {
+		return
+	}
+	if err != nil {
+		o.logger.Error("step", append([]any{"step", step, "result", "Failed", "error", err.Error()}, attrs...)...)
+		return
+	}
+	o.logger.Info("step", append([]any{"step", step, "result", "Succeeded"}, attrs...)...)
+}
+
+// Result prints a command result. Results are printed in every mode: as
+// "Label: value" lines in text mode and as a single record in JSON mode.
+func (o *output) Result(kind string, fields []field) {
+	if o.json {
+		attrs := make([]any, 0, 2*len(fields))
+		for _, f := range fields {
+			attrs = append(attrs, f.Key, f.Value)
+		}
+		o.logger.Info(kind, attrs...)
+		return
+	}
+	for _, f := range fields {
+		fmt.Fprintf(o.text, "%s: %v\n", f.Label, f.Value)
+	}
+}
+
+// Report prints a one-line command result. Reports are printed in every mode:
+// as the given line in text mode and as a record with attrs in JSON mode.
+func (o *output) Report(kind, line string, attrs ...any) {
+	if o.json {
+		o.logger.Info(kind, attrs...)
+		return
+	}
+	fmt.Fprintln(o.text, line)
+}
+
+// Manifest prints a generated manifest. Manifests are printed in every mode,
+// since they are the command's result.
+func (o *output) Manifest(data []byte) {
+	o.text.Write(data)
+}
+
+// Error reports a command failure on stderr, with a hint for classified AWS errors
+func (o *output) Error(err error) {
+	kind := aws.KindOf(err)
+	if o.json {
+		attrs := []any{"error", err.Error()}
+		if kind != "" {
+			attrs = append(attrs, "errorKind", string(kind))
+		}
+		o.errors.Error("command failed", attrs...)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if hint := awsErrorHint(kind); hint != "" {
+		fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+	}
+}
+
+// awsErrorHint suggests how to fix a classified AWS error
+func awsErrorHint(kind aws.ErrorKind) string {
+	switch kind {
+	case aws.ErrThrottled:
+		return "AWS is rate-limiting requests; retry the command later"
+	case aws.ErrUnauthorized:
+		return "check the AWS credentials and that the IAM policy allows the call"
+	case aws.ErrWrongRegion:
+		return "set --aws-region to the region the volume is in"
+	default:
+		return ""
+	}
+}
+
+// Warn reports a non-fatal problem on stderr
+func (o *output) Warn(err error) {
+	if o.json {
+		o.errors.Warn("warning", "error", err.Error())
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/controller"
+)
+
+// retryCmd annotates the Failed migrations a selector matches to retry them
+func retryCmd() *cobra.Command {
+	var kubeconfig string
+	var namespace string
+	var allNamespaces bool
+	var selector string
+	var errorContains string
+	var reason string
+	var includeAborted bool
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "retry [migration...]",
+		Short: "Retry the Failed StatefulSetMigrations a label selector matches",
+		Long: `Annotates Failed StatefulSetMigrations with migration.aqua.io/retry=true, so
+the controller resumes each where it stopped, keeping its history. Use it once
+the cause of a batch of failures, such as an IAM policy or a quota, has been
+fixed, instead of annotating each migration by hand.
+
+The migrations are those named, or those matching --selector, in --namespace
+or every namespace with --all-namespaces. --error-contains only retries those
+whose status.lastError contains the text, so migrations that failed for
+another reason are left alone. With --include-aborted, Aborted migrations are
+retried too. --reason is recorded in each migration's history with the retry.`,
+		Example: `  storagemover retry -A -l team=payments --error-contains AccessDenied --reason "IAM policy fixed" --dry-run
+  storagemover retry -n ops web-migration db-migration`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && selector == "" {
+				return fmt.Errorf("name the migrations or pass --selector")
+			}
+			if len(args) > 0 && (selector != "" || allNamespaces) {
+				return fmt.Errorf("named migrations cannot be combined with --selector or --all-namespaces")
+			}
+			sel, err := labels.Parse(selector)
+			if err != nil {
+				return fmt.Errorf("invalid --selector: %w", err)
+			}
+			if allNamespaces {
+				namespace = ""
+			}
+
+			ctx := context.Background()
+			c, err := getClient(kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to create client: %w", err)
+			}
+			list := &migrationv1alpha1.StatefulSetMigrationList{}
+			if err := c.List(ctx, list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: sel}); err != nil {
+				return fmt.Errorf("failed to list migrations: %w", err)
+			}
+
+			names := make(map[string]bool, len(args))
+			for _, name := range args {
+				names[name] = true
+			}
+			retried, skipped := 0, 0
+			for i := range list.Items {
+				m := &list.Items[i]
+				if len(names) > 0 && !names[m.Name] {
+					continue
+				}
+				delete(names, m.Name)
+				if skip := retrySkipReason(m, errorContains, includeAborted); skip != "" {
+					if len(args) > 0 {
+						out.Warn(fmt.Errorf("%s/%s: %s", m.Namespace, m.Name, skip))
+					}
+					skipped++
+					continue
+				}
+
+				line := fmt.Sprintf("%s/%s: %s, %s", m.Namespace, m.Name, m.Status.Phase, m.Status.LastError)
+				if dryRun {
+					out.Report("retry", "Would retry "+line, "namespace", m.Namespace, "name", m.Name, "phase", string(m.Status.Phase), "dryRun", true)
+					retried++
+					continue
+				}
+				patch := client.MergeFrom(m.DeepCopy())
+				if m.Annotations == nil {
+					m.Annotations = map[string]string{}
+				}
+				m.Annotations[controller.AnnotationRetry] = "true"
+				if reason != "" {
+					m.Annotations[controller.AnnotationRetryReason] = reason
+				}
+				if err := c.Patch(ctx, m, patch); err != nil {
+					out.Warn(fmt.Errorf("failed to annotate %s/%s: %w", m.Namespace, m.Name, err))
+					continue
+				}
+				out.Report("retry", "Retrying "+line, "namespace", m.Namespace, "name", m.Name, "phase", string(m.Status.Phase))
+				retried++
+			}
+			for name := range names {
+				out.Warn(fmt.Errorf("%s/%s: not found", namespace, name))
+			}
+
+			verb := "Retried"
+			if dryRun {
+				verb = "Would retry"
+			}
+			out.Report("summary", fmt.Sprintf("%s %d migrations, skipped %d", verb, retried, skipped),
+				"retried", retried, "skipped", skipped, "dryRun", dryRun)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig of the cluster the controller runs in (default $KUBECONFIG)")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the StatefulSetMigrations")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Retry matching migrations in every namespace")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Label selector of the migrations to retry, such as team=payments")
+	cmd.Flags().StringVar(&errorContains, "error-contains", "", "Only retry migrations whose status.lastError contains this text")
+	cmd.Flags().StringVar(&reason, "reason", "", "Why the migrations are retried, recorded in their history")
+	cmd.Flags().BoolVar(&includeAborted, "include-aborted", false, "Retry Aborted migrations too")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the migrations that would be retried without annotating them")
+	_ = cmd.MarkFlagFilename("kubeconfig")
+
+	return cmd
+}
+
+// retrySkipReason returns why a migration is not retried, or "" when it is
+func retrySkipReason(m *migrationv1alpha1.StatefulSetMigration, errorContains string, includeAborted bool) string {
+	switch m.Status.Phase {
+	case migrationv1alpha1.PhaseFailed:
+	case migrationv1alpha1.PhaseAborted:
+		if !includeAborted {
+			return "Aborted; pass --include-aborted to retry it"
+		}
+	default:
+		return fmt.Sprintf("%s, not Failed", m.Status.Phase)
+	}
+	if m.Annotations[controller.AnnotationRetry] == "true" {
+		return "already annotated to retry"
+	}
+	if errorContains != "" && !strings.Contains(m.Status.LastError, errorContains) {
+		return fmt.Sprintf("failed with %q", m.Status.LastError)
+	}
+	return ""
+}
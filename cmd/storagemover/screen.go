@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ANSI sequences the TUI draws with
+const (
+	ansiAltScreenOn  = "\x1b[?1049h"
+	ansiAltScreenOff = "\x1b[?1049l"
+	ansiHideCursor   = "\x1b[?25l"
+	ansiShowCursor   = "\x1b[?25h"
+	ansiHome         = "\x1b[H"
+	ansiClearLine    = "\x1b[K"
+	ansiClearBelow   = "\x1b[J"
+	ansiReverse      = "\x1b[7m"
+	ansiBold         = "\x1b[1m"
+	ansiDim          = "\x1b[2m"
+	ansiRed          = "\x1b[31m"
+	ansiGreen        = "\x1b[32m"
+	ansiYellow       = "\x1b[33m"
+	ansiReset        = "\x1b[0m"
+)
+
+// Keys decoded from the terminal other than printable characters
+const (
+	keyUp       = "up"
+	keyDown     = "down"
+	keyTab      = "tab"
+	keyEnter    = "enter"
+	keyEscape   = "esc"
+	keyCtrlC    = "ctrl-c"
+	keyPageUp   = "pgup"
+	keyPageDown = "pgdn"
+)
+
+// screen is the terminal in raw mode on the alternate screen, as the TUI
+// uses it
+type screen struct {
+	in    *os.File
+	out   *os.File
+	state *term.State
+}
+
+// openScreen switches the terminal to raw mode and the alternate screen;
+// close restores it
+func openScreen() (*screen, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, fmt.Errorf("the TUI needs an interactive terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put the terminal in raw mode: %w", err)
+	}
+	s := &screen{in: os.Stdin, out: os.Stdout, state: state}
+	fmt.Fprint(s.out, ansiAltScreenOn+ansiHideCursor)
+	return s, nil
+}
+
+// close leaves the alternate screen and restores the terminal's mode
+func (s *screen) close() {
+	fmt.Fprint(s.out, ansiReset+ansiShowCursor+ansiAltScreenOff)
+	_ = term.Restore(int(s.in.Fd()), s.state)
+}
+
+// size returns the terminal's width and height, or 80x24 when unknown
+func (s *screen) size() (int, int) {
+	width, height, err := term.GetSize(int(s.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// draw replaces the screen with lines, each cut to the terminal's width.
+// Lines may carry ANSI styles, which do not count toward the width.
+func (s *screen) draw(lines []string) {
+	width, height := s.size()
+	var b bytes.Buffer
+	b.WriteString(ansiHome)
+	for i, line := range lines {
+		if i == height {
+			break
+		}
+		b.WriteString(truncateStyled(line, width))
+		b.WriteString(ansiReset + ansiClearLine)
+		if i < height-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString(ansiClearBelow)
+	_, _ = s.out.Write(b.Bytes())
+}
+
+// readKeys sends each key pressed to keys until reading the terminal fails
+func (s *screen) readKeys(keys chan<- string) {
+	buf := make([]byte, 64)
+	for {
+		n, err := s.in.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for _, key := range decodeKeys(buf[:n]) {
+			keys <- key
+		}
+	}
+}
+
+// decodeKeys splits what one read of a raw terminal returned into keys
+func decodeKeys(data []byte) []string {
+	var keys []string
+	for len(data) > 0 {
+		switch {
+		case bytes.HasPrefix(data, []byte("\x1b[A")), bytes.HasPrefix(data, []byte("\x1bOA")):
+			keys, data = append(keys, keyUp), data[3:]
+		case bytes.HasPrefix(data, []byte("\x1b[B")), bytes.HasPrefix(data, []byte("\x1bOB")):
+			keys, data = append(keys, keyDown), data[3:]
+		case bytes.HasPrefix(data, []byte("\x1b[5~")):
+			keys, data = append(keys, keyPageUp), data[4:]
+		case bytes.HasPrefix(data, []byte("\x1b[6~")):
+			keys, data = append(keys, keyPageDown), data[4:]
+		case bytes.HasPrefix(data, []byte("\x1b[")):
+			// Another sequence; skip to its final byte
+			end := bytes.IndexFunc(data[2:], func(r rune) bool { return r >= 0x40 && r <= 0x7e })
+			if end < 0 {
+				return keys
+			}
+			data = data[end+3:]
+		case data[0] == 0x1b:
+			keys, data = append(keys, keyEscape), data[1:]
+		case data[0] == '\t':
+			keys, data = append(keys, keyTab), data[1:]
+		case data[0] == '\r' || data[0] == '\n':
+			keys, data = append(keys, keyEnter), data[1:]
+		case data[0] == 0x03:
+			keys, data = append(keys, keyCtrlC), data[1:]
+		default:
+			r, size := utf8.DecodeRune(data)
+			keys, data = append(keys, string(r)), data[size:]
+		}
+	}
+	return keys
+}
+
+// truncateStyled cuts line to width visible runes, keeping its ANSI styles
+func truncateStyled(line string, width int) string {
+	var b strings.Builder
+	visible := 0
+	for i := 0; i < len(line); {
+		if line[i] == 0x1b {
+			end := strings.IndexByte(line[i:], 'm')
+			if end < 0 {
+				break
+			}
+			b.WriteString(line[i : i+end+1])
+			i += end + 1
+			continue
+		}
+		if visible == width {
+			break
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		b.WriteRune(r)
+		visible++
+		i += size
+	}
+	return b.String()
+}
+
+// padRight pads s with spaces to width runes, cutting it when longer
+func padRight(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		if width <= 1 {
+			return string([]rune(s)[:width])
+		}
+		return string([]rune(s)[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
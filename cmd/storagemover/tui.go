@@ -0,0 +1,723 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/controller"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// tuiRequestTimeout bounds each API request the dashboard makes, so an
+// unreachable cluster shows as such instead of freezing the refresh
+const tuiRequestTimeout = 10 * time.Second
+
+// tuiHelp lists the keys the dashboard responds to
+const tuiHelp = "tab switch pane  ↑/↓ j/k select  v validate  d dry-run  m migrate  a abort  enter details  r refresh  q quit"
+
+// tuiCmd runs a live dashboard of the clusters, StatefulSets and migrations
+func tuiCmd() *cobra.Command {
+	var opts tuiOptions
+
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Live terminal dashboard of the clusters, StatefulSets and migrations",
+		Long: `Shows, and refreshes, the source and destination clusters and the cluster
+the controller runs in, the StatefulSets in the source cluster with their
+volumes, and the StatefulSetMigrations in the controller's cluster.
+
+Keys act on the selected StatefulSet or migration:
+
+  v      run the pre-flight checks for the StatefulSet, as validate does
+  d      create its StatefulSetMigration as a server-side dry run, which
+         checks the manifest against the CRD and any admission webhook
+  m      create its StatefulSetMigration, after confirmation
+  a      abort the selected migration, after confirmation
+  enter  show the selected migration's status and recent history
+
+The StatefulSetMigration is drafted from the flags: for anything more than the
+destination namespace and StorageClass mapping, draft it with generate-cr and
+apply it instead. Migrating and aborting change the controller's cluster;
+nothing else is modified.`,
+		Example: `  storagemover tui --source-kubeconfig source.yaml --dest-kubeconfig dest.yaml -n prod
+  storagemover tui --source-kubeconfig source.yaml --dest-kubeconfig dest.yaml --kubeconfig mgmt.yaml -A`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.allNamespaces {
+				opts.namespace = ""
+			}
+			if opts.refresh < time.Second {
+				return fmt.Errorf("--refresh must be at least 1s")
+			}
+			return runTUI(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.kubeconfig, "kubeconfig", "", "Path to the kubeconfig of the cluster the controller runs in (default $KUBECONFIG)")
+	cmd.Flags().StringVarP(&opts.namespace, "namespace", "n", "default", "Namespace of the StatefulSets in the source cluster")
+	cmd.Flags().BoolVarP(&opts.allNamespaces, "all-namespaces", "A", false, "Show the StatefulSets of every namespace")
+	cmd.Flags().StringVar(&opts.destNamespace, "dest-namespace", "", "Destination namespace of new migrations (defaults to the source namespace)")
+	cmd.Flags().StringVar(&opts.migrationNamespace, "migration-namespace", "default", "Namespace to create StatefulSetMigrations in, in the controller's cluster")
+	cmd.Flags().StringVar(&opts.sourceSecret, "source-secret", "source-cluster-kubeconfig", "Secret holding the source cluster's kubeconfig")
+	cmd.Flags().StringVar(&opts.destSecret, "dest-secret", "dest-cluster-kubeconfig", "Secret holding the destination cluster's kubeconfig")
+	cmd.Flags().StringToStringVar(&opts.storageClassMapping, "storage-class-mapping", nil, "StorageClass mappings of new migrations, e.g. gp2=gp3")
+	cmd.Flags().DurationVar(&opts.refresh, "refresh", 5*time.Second, "How often to refresh the dashboard")
+	_ = cmd.MarkFlagFilename("kubeconfig")
+	cmd.MarkFlagRequired("source-kubeconfig")
+
+	return cmd
+}
+
+// tuiOptions are the flags of the tui command
+type tuiOptions struct {
+	kubeconfig          string
+	namespace           string
+	allNamespaces       bool
+	destNamespace       string
+	migrationNamespace  string
+	sourceSecret        string
+	destSecret          string
+	storageClassMapping map[string]string
+	refresh             time.Duration
+}
+
+// tuiPane is the list the selection keys move through
+type tuiPane int
+
+const (
+	paneStatefulSets tuiPane = iota
+	paneMigrations
+)
+
+// clusterInfo is a cluster's row in the dashboard
+type clusterInfo struct {
+	role    string
+	host    string
+	version string
+	err     error
+}
+
+// workload is a StatefulSet in the source cluster with its volumes
+type workload struct {
+	namespace string
+	name      string
+	replicas  int32
+	ready     int32
+	volumes   []volumeRow
+}
+
+// volumeRow is a replica's volume: its PVC and, once bound, PV and EBS volume
+type volumeRow struct {
+	pvc          string
+	phase        string
+	pv           string
+	volumeID     string
+	zone         string
+	size         string
+	storageClass string
+}
+
+// dashboardData is one refresh of everything the dashboard shows
+type dashboardData struct {
+	clusters      []clusterInfo
+	workloads     []workload
+	workloadsErr  error
+	migrations    []migrationv1alpha1.StatefulSetMigration
+	migrationsErr error
+	refreshed     time.Time
+}
+
+// tuiAction is a keyboard action running, or waiting for confirmation
+type tuiAction struct {
+	description string
+	prompt      string
+	run         func(ctx context.Context) ([]string, error)
+}
+
+// actionResult is what an action printed, or why it failed
+type actionResult struct {
+	description string
+	lines       []string
+	err         error
+}
+
+// tui is the state of the dashboard; only its event loop touches it
+type tui struct {
+	opts     tuiOptions
+	source   client.Client
+	mgmt     client.Client
+	data     dashboardData
+	loading  bool
+	pane     tuiPane
+	selected [2]int
+	output   []string
+	status   string
+	confirm  *tuiAction
+	busy     string
+}
+
+// runTUI connects to the clusters and runs the dashboard until it is quit
+func runTUI(opts tuiOptions) error {
+	source, err := getClient(sourceKubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create source client: %w", err)
+	}
+	mgmt, err := getClient(opts.kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create client for the controller's cluster: %w", err)
+	}
+
+	// Logs and API warnings written to the terminal would tear the screen
+	ctrllog.SetLogger(zap.New(zap.WriteTo(io.Discard)))
+	rest.SetDefaultWarningHandler(rest.NoWarnings{})
+
+	s, err := openScreen()
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	t := &tui{opts: opts, source: source, mgmt: mgmt, status: "Loading..."}
+	return t.run(context.Background(), s)
+}
+
+// run is the dashboard's event loop
+func (t *tui) run(ctx context.Context, s *screen) error {
+	keys := make(chan string, 16)
+	go s.readKeys(keys)
+	loaded := make(chan dashboardData, 1)
+	results := make(chan actionResult, 1)
+	refresh := time.NewTicker(t.opts.refresh)
+	defer refresh.Stop()
+	redraw := time.NewTicker(time.Second)
+	defer redraw.Stop()
+
+	load := func() {
+		if t.loading {
+			return
+		}
+		t.loading = true
+		go func() { loaded <- t.load(ctx) }()
+	}
+	load()
+
+	for {
+		width, height := s.size()
+		s.draw(t.render(width, height))
+
+		select {
+		case key, ok := <-keys:
+			if !ok || key == keyCtrlC || (key == "q" && t.confirm == nil) {
+				return nil
+			}
+			if key == "r" && t.confirm == nil {
+				load()
+				continue
+			}
+			if action := t.handleKey(key); action != nil {
+				t.busy = action.description
+				go func() {
+					lines, err := action.run(ctx)
+					results <- actionResult{description: action.description, lines: lines, err: err}
+				}()
+			}
+		case data := <-loaded:
+			t.loading = false
+			t.data = data
+			t.clampSelection()
+			if t.status == "Loading..." {
+				t.status = ""
+			}
+		case result := <-results:
+			t.busy = ""
+			t.output = result.lines
+			if result.err != nil {
+				t.status = ansiRed + result.description + " failed: " + result.err.Error()
+			} else {
+				t.status = ansiGreen + result.description + " done"
+			}
+			load()
+		case <-refresh.C:
+			load()
+		case <-redraw.C:
+		}
+	}
+}
+
+// handleKey applies a key to the dashboard and returns the action to run, if any
+func (t *tui) handleKey(key string) *tuiAction {
+	if t.confirm != nil {
+		action := t.confirm
+		t.confirm = nil
+		if key == "y" || key == "Y" {
+			return action
+		}
+		t.status = "Cancelled"
+		return nil
+	}
+	if t.busy != "" && (key == "v" || key == "d" || key == "m" || key == "a") {
+		t.status = "Wait for " + t.busy + " to finish"
+		return nil
+	}
+
+	switch key {
+	case keyTab:
+		t.pane = 1 - t.pane
+	case keyUp, "k":
+		t.selected[t.pane]--
+	case keyDown, "j":
+		t.selected[t.pane]++
+	case keyPageUp:
+		t.selected[t.pane] -= 10
+	case keyPageDown:
+		t.selected[t.pane] += 10
+	case "v", "d", "m":
+		w := t.selectedWorkload()
+		if w == nil {
+			t.status = "Select a StatefulSet first"
+			return nil
+		}
+		m := t.draft(w)
+		switch key {
+		case "v":
+			return &tuiAction{description: fmt.Sprintf("Validating %s/%s", w.namespace, w.name), run: func(ctx context.Context) ([]string, error) {
+				return validateLines(ctx, m)
+			}}
+		case "d":
+			return &tuiAction{description: fmt.Sprintf("Dry run of %s/%s", m.Namespace, m.Name), run: func(ctx context.Context) ([]string, error) {
+				return t.createMigration(ctx, m, true)
+			}}
+		default:
+			t.confirm = &tuiAction{
+				description: fmt.Sprintf("Creating %s/%s", m.Namespace, m.Name),
+				prompt: fmt.Sprintf("Create StatefulSetMigration %s/%s to move %s/%s to namespace %s of the destination? [y/N]",
+					m.Namespace, m.Name, w.namespace, w.name, m.Spec.DestNamespace),
+				run: func(ctx context.Context) ([]string, error) { return t.createMigration(ctx, m, false) },
+			}
+		}
+	case "a":
+		m := t.selectedMigration()
+		if m == nil || t.pane != paneMigrations {
+			t.status = "Select a migration first (tab switches to the migrations)"
+			return nil
+		}
+		if migrationFinished(m) {
+			t.status = fmt.Sprintf("%s/%s has already finished (%s)", m.Namespace, m.Name, m.Status.Phase)
+			return nil
+		}
+		key := client.ObjectKeyFromObject(m)
+		t.confirm = &tuiAction{
+			description: fmt.Sprintf("Aborting %s", key),
+			prompt:      fmt.Sprintf("Abort %s in phase %s? Pods already moved stay in the destination. [y/N]", key, m.Status.Phase),
+			run: func(ctx context.Context) ([]string, error) {
+				return t.abortMigration(ctx, key)
+			},
+		}
+	case keyEnter:
+		if m := t.selectedMigration(); m != nil && t.pane == paneMigrations {
+			t.output = migrationDetails(m)
+		}
+	}
+	t.clampSelection()
+	return nil
+}
+
+// load reads the clusters, StatefulSets and migrations
+func (t *tui) load(ctx context.Context) dashboardData {
+	ctx, cancel := context.WithTimeout(ctx, tuiRequestTimeout)
+	defer cancel()
+
+	data := dashboardData{refreshed: time.Now()}
+	for _, cluster := range []struct{ role, kubeconfig string }{
+		{"source", sourceKubeconfig}, {"destination", destKubeconfig}, {"controller", t.opts.kubeconfig},
+	} {
+		if cluster.role == "destination" && cluster.kubeconfig == "" {
+			data.clusters = append(data.clusters, clusterInfo{role: cluster.role, err: fmt.Errorf("--dest-kubeconfig not set")})
+			continue
+		}
+		data.clusters = append(data.clusters, probeCluster(cluster.role, cluster.kubeconfig))
+	}
+	data.workloads, data.workloadsErr = listWorkloads(ctx, t.source, t.opts.namespace)
+
+	migrations := &migrationv1alpha1.StatefulSetMigrationList{}
+	if err := t.mgmt.List(ctx, migrations); err != nil {
+		data.migrationsErr = err
+	} else {
+		data.migrations = migrations.Items
+		sort.SliceStable(data.migrations, func(i, j int) bool {
+			a, b := &data.migrations[i], &data.migrations[j]
+			if migrationFinished(a) != migrationFinished(b) {
+				return !migrationFinished(a)
+			}
+			return a.CreationTimestamp.After(b.CreationTimestamp.Time)
+		})
+	}
+	return data
+}
+
+// probeCluster reads a cluster's API server address and version
+func probeCluster(role, kubeconfig string) clusterInfo {
+	info := clusterInfo{role: role}
+	cfg, err := getRESTConfig(kubeconfig)
+	if err != nil {
+		info.err = err
+		return info
+	}
+	info.host = cfg.Host
+	cfg = rest.CopyConfig(cfg)
+	cfg.Timeout = tuiRequestTimeout
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		info.err = err
+		return info
+	}
+	version, err := dc.ServerVersion()
+	if err != nil {
+		info.err = err
+		return info
+	}
+	info.version = version.GitVersion
+	return info
+}
+
+// listWorkloads lists the StatefulSets of a namespace, or of every namespace
+// when it is empty, with the PVC, PV and EBS volume of each replica
+func listWorkloads(ctx context.Context, c client.Client, namespace string) ([]workload, error) {
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := c.List(ctx, statefulSets, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := c.List(ctx, pvcs, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	pvs := &corev1.PersistentVolumeList{}
+	if err := c.List(ctx, pvs); err != nil {
+		return nil, err
+	}
+	claims := make(map[string]*corev1.PersistentVolumeClaim, len(pvcs.Items))
+	for i := range pvcs.Items {
+		claims[pvcs.Items[i].Namespace+"/"+pvcs.Items[i].Name] = &pvcs.Items[i]
+	}
+	volumes := make(map[string]*corev1.PersistentVolume, len(pvs.Items))
+	for i := range pvs.Items {
+		volumes[pvs.Items[i].Name] = &pvs.Items[i]
+	}
+
+	workloads := make([]workload, 0, len(statefulSets.Items))
+	for _, sts := range statefulSets.Items {
+		w := workload{namespace: sts.Namespace, name: sts.Name, replicas: 1, ready: sts.Status.ReadyReplicas}
+		if sts.Spec.Replicas != nil {
+			w.replicas = *sts.Spec.Replicas
+		}
+		for i := 0; i < int(w.replicas); i++ {
+			for _, vct := range sts.Spec.VolumeClaimTemplates {
+				row := volumeRow{pvc: translate.GetPVCNameForStatefulSetPod(vct.Name, sts.Name, i), phase: "Missing"}
+				if pvc := claims[sts.Namespace+"/"+row.pvc]; pvc != nil {
+					row.phase = string(pvc.Status.Phase)
+					row.pv = pvc.Spec.VolumeName
+				}
+				if pv := volumes[row.pv]; pv != nil {
+					row.volumeID, _ = translate.EBSVolumeID(pv)
+					row.zone = translate.AvailabilityZone(pv)
+					row.size = pv.Spec.Capacity.Storage().String()
+					row.storageClass = pv.Spec.StorageClassName
+				}
+				w.volumes = append(w.volumes, row)
+			}
+		}
+		workloads = append(workloads, w)
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].namespace != workloads[j].namespace {
+			return workloads[i].namespace < workloads[j].namespace
+		}
+		return workloads[i].name < workloads[j].name
+	})
+	return workloads, nil
+}
+
+// draft returns the StatefulSetMigration migrate creates for w
+func (t *tui) draft(w *workload) *migrationv1alpha1.StatefulSetMigration {
+	destNamespace := t.opts.destNamespace
+	if destNamespace == "" {
+		destNamespace = w.namespace
+	}
+	return &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: t.opts.migrationNamespace, Name: w.name + "-migration"},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			MigrationID:         fmt.Sprintf("%s-%s", w.name, time.Now().UTC().Format("20060102")),
+			SourceCluster:       migrationv1alpha1.ContextRef{KubeConfigSecret: t.opts.sourceSecret},
+			SourceNamespace:     w.namespace,
+			StatefulSetName:     w.name,
+			DestCluster:         migrationv1alpha1.ContextRef{KubeConfigSecret: t.opts.destSecret},
+			DestNamespace:       destNamespace,
+			StorageClassMapping: t.opts.storageClassMapping,
+		},
+	}
+}
+
+// validateLines runs the pre-flight checks for m and formats the results
+func validateLines(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) ([]string, error) {
+	report, err := runValidation(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{fmt.Sprintf("%sPre-flight checks of %s/%s: %d passed, %d warnings, %d failed",
+		ansiBold, m.Spec.SourceNamespace, m.Spec.StatefulSetName, report.Passed, report.Warnings, report.Failed)}
+	for _, result := range report.Checks {
+		color := ansiGreen
+		switch result.Outcome {
+		case controller.PreFlightWarn:
+			color = ansiYellow
+		case controller.PreFlightFail:
+			color = ansiRed
+		}
+		lines = append(lines, fmt.Sprintf("%s%s%s %s  %s", color, padRight(strings.ToUpper(string(result.Outcome)), 5), ansiReset,
+			padRight(result.Check, 28), result.Message))
+	}
+	if report.Failed > 0 {
+		return lines, fmt.Errorf("%d of %d pre-flight checks failed", report.Failed, len(report.Checks))
+	}
+	return lines, nil
+}
+
+// createMigration creates m in the controller's cluster, only as a
+// server-side dry run when dryRun is set, and returns its manifest
+func (t *tui) createMigration(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, dryRun bool) ([]string, error) {
+	manifest, err := marshalMigration(m)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimRight(string(manifest), "\n"), "\n")
+	var opts []client.CreateOption
+	verb := "Created"
+	if dryRun {
+		opts = append(opts, client.DryRunAll)
+		verb = "The API server accepted"
+	}
+	if err := t.mgmt.Create(ctx, m.DeepCopy(), opts...); err != nil {
+		return lines, err
+	}
+	return append([]string{fmt.Sprintf("%s%s StatefulSetMigration %s/%s:", ansiBold, verb, m.Namespace, m.Name)}, lines...), nil
+}
+
+// abortMigration annotates a migration to abort
+func (t *tui) abortMigration(ctx context.Context, key client.ObjectKey) ([]string, error) {
+	m := &migrationv1alpha1.StatefulSetMigration{}
+	if err := t.mgmt.Get(ctx, key, m); err != nil {
+		return nil, err
+	}
+	patch := client.MergeFrom(m.DeepCopy())
+	if m.Annotations == nil {
+		m.Annotations = map[string]string{}
+	}
+	m.Annotations[controller.AnnotationAbort] = "true"
+	if err := t.mgmt.Patch(ctx, m, patch); err != nil {
+		return nil, err
+	}
+	return []string{fmt.Sprintf("Annotated %s with %s=true; the controller aborts it at its next step", key, controller.AnnotationAbort)}, nil
+}
+
+// migrationFinished reports whether a migration reached a terminal phase
+func migrationFinished(m *migrationv1alpha1.StatefulSetMigration) bool {
+	switch m.Status.Phase {
+	case migrationv1alpha1.PhaseCompleted, migrationv1alpha1.PhaseFailed, migrationv1alpha1.PhaseAborted:
+		return true
+	}
+	return false
+}
+
+// migrationDetails describes a migration's status and its last steps
+func migrationDetails(m *migrationv1alpha1.StatefulSetMigration) []string {
+	lines := []string{
+		fmt.Sprintf("%s%s/%s: %s, %d of %d pods migrated", ansiBold, m.Namespace, m.Name, m.Status.Phase, len(m.Status.MigratedPods), m.Status.TotalReplicas),
+	}
+	if m.Status.LastError != "" {
+		lines = append(lines, ansiRed+"Last error: "+m.Status.LastError)
+	}
+	for _, cond := range m.Status.Conditions {
+		if cond.Status == metav1.ConditionTrue {
+			lines = append(lines, fmt.Sprintf("Condition %s: %s", cond.Type, cond.Message))
+		}
+	}
+	history := m.Status.History
+	if len(history) > 10 {
+		history = history[len(history)-10:]
+	}
+	for _, entry := range history {
+		lines = append(lines, fmt.Sprintf("%s  %-24s %-10s %s %s", entry.Time.UTC().Format("15:04:05"), entry.Step, entry.Result, entry.Object, entry.Message))
+	}
+	return lines
+}
+
+// selectedWorkload returns the selected StatefulSet, or nil
+func (t *tui) selectedWorkload() *workload {
+	if i := t.selected[paneStatefulSets]; i < len(t.data.workloads) {
+		return &t.data.workloads[i]
+	}
+	return nil
+}
+
+// selectedMigration returns the selected migration, or nil
+func (t *tui) selectedMigration() *migrationv1alpha1.StatefulSetMigration {
+	if i := t.selected[paneMigrations]; i < len(t.data.migrations) {
+		return &t.data.migrations[i]
+	}
+	return nil
+}
+
+// clampSelection keeps each pane's selection within its list
+func (t *tui) clampSelection() {
+	for pane, n := range []int{len(t.data.workloads), len(t.data.migrations)} {
+		t.selected[pane] = max(0, min(t.selected[pane], n-1))
+	}
+}
+
+// render lays the dashboard out on a width by height screen
+func (t *tui) render(width, height int) []string {
+	header := fmt.Sprintf("%sstoragemover%s  ", ansiBold, ansiReset)
+	if t.data.refreshed.IsZero() {
+		header += "loading..."
+	} else {
+		header += "refreshed " + t.data.refreshed.Format("15:04:05")
+	}
+	if t.loading {
+		header += " (refreshing)"
+	}
+	if t.busy != "" {
+		header += "  " + ansiYellow + t.busy + "..."
+	}
+	lines := []string{header}
+	for _, cluster := range t.data.clusters {
+		state := ansiGreen + cluster.version
+		if cluster.err != nil {
+			state = ansiRed + cluster.err.Error()
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s %s", padRight(cluster.role, 12), padRight(cluster.host, 44), state))
+	}
+
+	// Split what is left between the lists, the volumes and the output
+	footer := []string{ansiDim + tuiHelp, t.statusLine()}
+	rows := height - len(lines) - len(footer) - 4
+	workloadRows := max(3, rows*3/10)
+	volumeRows := max(2, rows*2/10)
+	migrationRows := max(3, rows*2/10)
+	outputRows := max(0, rows-workloadRows-volumeRows-migrationRows)
+
+	scope := "namespace " + t.opts.namespace
+	if t.opts.namespace == "" {
+		scope = "all namespaces"
+	}
+	lines = append(lines, t.sectionTitle(fmt.Sprintf("StatefulSets in the source cluster (%s)", scope), paneStatefulSets, width))
+	var workloadLines []string
+	if t.data.workloadsErr != nil {
+		workloadLines = append(workloadLines, ansiRed+t.data.workloadsErr.Error())
+	}
+	for _, w := range t.data.workloads {
+		workloadLines = append(workloadLines, fmt.Sprintf("  %s %s %s", padRight(w.namespace+"/"+w.name, 44),
+			padRight(fmt.Sprintf("%d/%d ready", w.ready, w.replicas), 12), t.migrationOf(&w)))
+	}
+	lines = append(lines, t.window(workloadLines, paneStatefulSets, workloadRows, width)...)
+
+	title, volumeLines := "Volumes", []string(nil)
+	if w := t.selectedWorkload(); w != nil {
+		title = fmt.Sprintf("Volumes of %s/%s", w.namespace, w.name)
+		for _, v := range w.volumes {
+			volumeLines = append(volumeLines, fmt.Sprintf("  %s %s %s %s %s %s %s", padRight(v.pvc, 24), padRight(v.phase, 8),
+				padRight(v.pv, 24), padRight(v.volumeID, 22), padRight(v.zone, 12), padRight(v.size, 8), v.storageClass))
+		}
+	}
+	lines = append(lines, ansiBold+title)
+	lines = append(lines, fit(volumeLines, volumeRows)...)
+
+	lines = append(lines, t.sectionTitle("StatefulSetMigrations in the controller's cluster", paneMigrations, width))
+	var migrationLines []string
+	if t.data.migrationsErr != nil {
+		migrationLines = append(migrationLines, ansiRed+t.data.migrationsErr.Error())
+	}
+	for i := range t.data.migrations {
+		m := &t.data.migrations[i]
+		migrationLines = append(migrationLines, fmt.Sprintf("  %s %s %s %s -> %s  %s", padRight(m.Namespace+"/"+m.Name, 36),
+			padRight(string(m.Status.Phase), 16), padRight(fmt.Sprintf("%d/%d pods", len(m.Status.MigratedPods), m.Status.TotalReplicas), 10),
+			m.Spec.SourceNamespace+"/"+m.Spec.StatefulSetName, m.Spec.DestNamespace, duration.HumanDuration(time.Since(m.CreationTimestamp.Time))))
+	}
+	lines = append(lines, t.window(migrationLines, paneMigrations, migrationRows, width)...)
+
+	lines = append(lines, ansiBold+"Output")
+	lines = append(lines, fit(t.output, outputRows)...)
+	return append(lines, footer...)
+}
+
+// statusLine is the confirmation prompt, or the outcome of the last action
+func (t *tui) statusLine() string {
+	if t.confirm != nil {
+		return ansiYellow + t.confirm.prompt
+	}
+	return t.status
+}
+
+// sectionTitle is a pane's title, highlighted when it has the focus
+func (t *tui) sectionTitle(title string, pane tuiPane, width int) string {
+	if t.pane == pane {
+		return ansiReverse + padRight("▶ "+title, width)
+	}
+	return ansiBold + "  " + title
+}
+
+// window returns the rows of a pane's list that fit, scrolled to keep its
+// selection in view and highlighted
+func (t *tui) window(lines []string, pane tuiPane, rows, width int) []string {
+	selected := t.selected[pane]
+	start := 0
+	if selected >= rows {
+		start = selected - rows + 1
+	}
+	var visible []string
+	for i := start; i < len(lines) && i < start+rows; i++ {
+		line := lines[i]
+		if i == selected && t.pane == pane {
+			line = ansiReverse + padRight(line, width)
+		}
+		visible = append(visible, line)
+	}
+	return fit(visible, rows)
+}
+
+// fit pads or cuts lines to exactly rows lines
+func fit(lines []string, rows int) []string {
+	if len(lines) > rows {
+		return lines[:rows]
+	}
+	for len(lines) < rows {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// migrationOf describes the latest migration of a StatefulSet
+func (t *tui) migrationOf(w *workload) string {
+	for i := range t.data.migrations {
+		m := &t.data.migrations[i]
+		if m.Spec.SourceNamespace == w.namespace && m.Spec.StatefulSetName == w.name {
+			return fmt.Sprintf("%s (%s)", m.Name, m.Status.Phase)
+		}
+	}
+	return ansiDim + "not migrated"
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/controller"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// outputTable prints results as an aligned table
+const outputTable = "table"
+
+// validationReport is the JSON form of validate's results for a StatefulSet
+type validationReport struct {
+	SourceNamespace string                       `json:"sourceNamespace"`
+	DestNamespace   string                       `json:"destNamespace"`
+	StatefulSet     string                       `json:"statefulSet"`
+	Passed          int                          `json:"passed"`
+	Warnings        int                          `json:"warnings"`
+	Failed          int                          `json:"failed"`
+	Checks          []controller.PreFlightResult `json:"checks"`
+}
+
+// validateCmd validates a PV, or a whole StatefulSet, for migration
+func validateCmd() *cobra.Command {
+	var pvName string
+	var allowStatic bool
+	var namespace string
+	var statefulSet string
+	var destNamespace string
+	var filename string
+	var storageClassMapping map[string]string
+	var outputFormat string
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate a PV or StatefulSet is suitable for migration (read-only)",
+		Long: `With --name, checks that a PV in the source cluster can be migrated. Add
+--allow-static to also accept a statically provisioned PV that was
+pre-created by hand and is still Available, waiting for its claim.
+
+With --statefulset, or a StatefulSetMigration manifest given with --filename,
+runs every pre-flight check the controller would run before freezing the
+source against both clusters and AWS: the destination namespace, StatefulSet
+and headless service, the volumes and their StorageClasses, zones, placement
+and encryption keys, whether the pods can schedule, the EBS quotas, and the
+clocks and certificates of both API servers. Unlike the controller, it does not
+stop at the first failure, and prints each check as PASS, WARN or FAIL in a
+table, or as JSON with --output json. It exits with 1 if any check fails.
+
+The checks see only the fields the flags set unless a manifest is given, so
+validate the manifest itself to cover spec options such as strategyFallback.
+Nothing is modified.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			if outputFormat != outputTable && outputFormat != logFormatJSON {
+				return fmt.Errorf("unsupported --output %q (expected %q or %q)", outputFormat, outputTable, logFormatJSON)
+			}
+			switch {
+			case pvName != "" && (statefulSet != "" || filename != ""):
+				return fmt.Errorf("--name cannot be combined with --statefulset or --filename")
+			case pvName != "":
+				return validatePV(ctx, pvName, translate.PVValidationOptions{AllowStatic: allowStatic})
+			case allowStatic:
+				return fmt.Errorf("--allow-static requires --name")
+			case statefulSet != "" && filename != "":
+				return fmt.Errorf("--statefulset cannot be combined with --filename")
+			}
+
+			m := &migrationv1alpha1.StatefulSetMigration{}
+			if filename != "" {
+				data, err := os.ReadFile(filename)
+				if err != nil {
+					return err
+				}
+				if err := yaml.Unmarshal(data, m); err != nil {
+					return fmt.Errorf("failed to parse %s: %w", filename, err)
+				}
+			} else if statefulSet != "" {
+				if destNamespace == "" {
+					destNamespace = namespace
+				}
+				m = &migrationv1alpha1.StatefulSetMigration{
+					ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: statefulSet + "-migration"},
+					Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+						SourceNamespace:     namespace,
+						DestNamespace:       destNamespace,
+						StatefulSetName:     statefulSet,
+						StorageClassMapping: storageClassMapping,
+					},
+				}
+			} else {
+				return fmt.Errorf("one of --name, --statefulset or --filename is required")
+			}
+			return validateStatefulSet(ctx, m, outputFormat)
+		},
+	}
+
+	cmd.Flags().StringVar(&pvName, "name", "", "Name of the PV to validate")
+	cmd.Flags().BoolVar(&allowStatic, "allow-static", false, "Accept a pre-created PV that is Available instead of Bound")
+	cmd.Flags().StringVar(&statefulSet, "statefulset", "", "Name of the StatefulSet to validate")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the StatefulSet in the source cluster")
+	cmd.Flags().StringVar(&destNamespace, "dest-namespace", "", "Destination namespace (defaults to the source namespace)")
+	cmd.Flags().StringToStringVar(&storageClassMapping, "storage-class-mapping", nil, "StorageClass mappings, e.g. gp2=gp3")
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "StatefulSetMigration manifest to validate")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, "Format of the StatefulSet report: table or json")
+	_ = cmd.MarkFlagFilename("filename", "yaml", "yml", "json")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(
+		[]string{outputTable, logFormatJSON}, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+// validatePV checks a single PV in the source cluster
+func validatePV(ctx context.Context, pvName string, opts translate.PVValidationOptions) error {
+	c, err := getClient(sourceKubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	pv := &corev1.PersistentVolume{}
+	if err := c.Get(ctx, types.NamespacedName{Name: pvName}, pv); err != nil {
+		return fmt.Errorf("failed to get PV: %w", err)
+	}
+
+	if err := translate.ValidatePV(pv, opts); err != nil {
+		out.Report("validation", fmt.Sprintf("❌ Validation failed: %v", err),
+			"pv", pv.Name, "valid", false, "reason", err.Error())
+		return err
+	}
+
+	out.Report("validation", "✅ PV is valid for migration",
+		"pv", pv.Name, "valid", true, "reclaimPolicy", string(pv.Spec.PersistentVolumeReclaimPolicy))
+
+	// Additional info
+	if pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+		out.Printf("⚠️  Warning: Reclaim policy is %s (should be Retain for safe migration)\n",
+			pv.Spec.PersistentVolumeReclaimPolicy)
+	}
+
+	return nil
+}
+
+// validateStatefulSet runs the controller's pre-flight checks for m against
+// both clusters and prints the report
+func validateStatefulSet(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, outputFormat string) error {
+	report, err := runValidation(ctx, m)
+	if err != nil {
+		return err
+	}
+	results := report.Checks
+
+	if outputFormat == logFormatJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		out.Manifest(append(data, '\n'))
+	} else {
+		var b strings.Builder
+		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CHECK\tSEVERITY\tRESULT\tMESSAGE")
+		for _, result := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Check, result.Severity, strings.ToUpper(string(result.Outcome)), result.Message)
+		}
+		w.Flush()
+		out.Manifest([]byte(b.String()))
+		out.Report("validation", fmt.Sprintf("\n%d passed, %d warnings, %d failed", report.Passed, report.Warnings, report.Failed),
+			"statefulSet", m.Spec.StatefulSetName, "passed", report.Passed, "warnings", report.Warnings, "failed", report.Failed)
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d pre-flight checks failed", report.Failed, len(results))
+	}
+	return nil
+}
+
+// runValidation runs the controller's pre-flight checks for m against both
+// clusters and counts their outcomes
+func runValidation(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (*validationReport, error) {
+	scheme, err := getScheme()
+	if err != nil {
+		return nil, err
+	}
+	manager := multicluster.NewClientManager(scheme, nil)
+	sourceClient, err := clusterClient(manager, sourceKubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create source client: %w", err)
+	}
+	destClient, err := clusterClient(manager, destKubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination client: %w", err)
+	}
+	ebsClient, err := aws.NewEBSClient(ctx, aws.EBSClientConfig{Region: awsRegion, Endpoint: awsEndpoint, UseFIPSEndpoint: awsUseFIPS})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create EBS client: %w", err)
+	}
+
+	r := &controller.StatefulSetMigrationReconciler{Scheme: scheme, ClientManager: manager, EBSClient: ebsClient}
+	results, err := r.ValidateMigration(ctx, m, sourceClient, destClient)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &validationReport{
+		SourceNamespace: m.Spec.SourceNamespace,
+		DestNamespace:   m.Spec.DestNamespace,
+		StatefulSet:     m.Spec.StatefulSetName,
+		Checks:          results,
+	}
+	for _, result := range results {
+		switch result.Outcome {
+		case controller.PreFlightPass:
+			report.Passed++
+		case controller.PreFlightWarn:
+			report.Warnings++
+		case controller.PreFlightFail:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// clusterClient creates a ClusterClient, as the controller uses, from a kubeconfig
+func clusterClient(manager *multicluster.ClientManager, kubeconfigPath string) (*multicluster.ClusterClient, error) {
+	config, err := getRESTConfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	return manager.GetClientFromRestConfig(config)
+}
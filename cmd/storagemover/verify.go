@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+)
+
+// verifyBindCmd checks that a destination PVC is bound to its pre-created PV
+func verifyBindCmd() *cobra.Command {
+	var namespace string
+	var pvcName string
+	var pvName string
+	var volumeID string
+
+	cmd := &cobra.Command{
+		Use:   "verify-bind",
+		Short: "Check that a destination PVC is Bound to its pre-created PV",
+		Long: `Checks that a PVC in the destination cluster is Bound to the PV created for it,
+that the PV refers to the expected EBS volume, and that its claimRef names the
+PVC and its UID. Each problem found, such as a StorageClass mismatch or a PV
+provisioned dynamically before the pre-created one could bind, is printed with
+a fix.
+
+The PV defaults to the one labeled migration.aqua.io/dest-pvc for the PVC, and
+the volume ID to the PVC's migration.aqua.io/volume-id annotation.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			c, err := getClient(destKubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to create destination client: %w", err)
+			}
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: pvcName}, pvc); err != nil {
+				return fmt.Errorf("failed to get PVC: %w", err)
+			}
+			if volumeID == "" {
+				volumeID = pvc.Annotations[migration.AnnotationVolumeID]
+			}
+
+			if pvName == "" {
+				pvName, err = findPreCreatedPV(ctx, c, pvc)
+				if err != nil {
+					return err
+				}
+			}
+			expected := &corev1.PersistentVolume{}
+			if err := c.Get(ctx, types.NamespacedName{Name: pvName}, expected); err != nil {
+				return fmt.Errorf("failed to get PV %s: %w", pvName, err)
+			}
+
+			var bound *corev1.PersistentVolume
+			if pvc.Spec.VolumeName != "" && pvc.Spec.VolumeName != pvName {
+				bound = &corev1.PersistentVolume{}
+				if err := c.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, bound); err != nil {
+					bound = nil
+				}
+			}
+
+			problems := migration.VerifyBinding(pvc, expected, bound, volumeID)
+			for _, p := range problems {
+				out.Report("binding", fmt.Sprintf("❌ %s\n     Fix: %s", p.Problem, p.Fix),
+					"pvc", pvc.Namespace+"/"+pvc.Name, "pv", pvName, "problem", p.Problem, "fix", p.Fix)
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("PVC %s/%s is not bound to PV %s", pvc.Namespace, pvc.Name, pvName)
+			}
+
+			out.Report("binding", fmt.Sprintf("✅ PVC %s/%s is Bound to PV %s", pvc.Namespace, pvc.Name, pvName),
+				"pvc", pvc.Namespace+"/"+pvc.Name, "pv", pvName, "volumeID", volumeID, "bound", true)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the PVC in the destination cluster")
+	cmd.Flags().StringVar(&pvcName, "name", "", "Name of the PVC")
+	cmd.Flags().StringVar(&pvName, "pv", "", "Name of the PV the PVC should be bound to (defaults to the PV labeled for the PVC)")
+	cmd.Flags().StringVar(&volumeID, "volume-id", "", "EBS volume ID the PV should refer to (defaults to the PVC's volume-id annotation)")
+	cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+// findPreCreatedPV returns the PV the migration created for a PVC, found by its labels
+func findPreCreatedPV(ctx context.Context, c client.Client, pvc *corev1.PersistentVolumeClaim) (string, error) {
+	pvs := &corev1.PersistentVolumeList{}
+	if err := c.List(ctx, pvs, client.MatchingLabels{
+		migration.LabelDestNamespace: pvc.Namespace,
+		migration.LabelDestPVC:       pvc.Name,
+	}); err != nil {
+		return "", fmt.Errorf("failed to list PVs: %w", err)
+	}
+	switch len(pvs.Items) {
+	case 0:
+		return "", fmt.Errorf("no PV is labeled %s=%s; pass --pv", migration.LabelDestPVC, pvc.Name)
+	case 1:
+		return pvs.Items[0].Name, nil
+	default:
+		return "", fmt.Errorf("%d PVs are labeled %s=%s; pass --pv", len(pvs.Items), migration.LabelDestPVC, pvc.Name)
+	}
+}
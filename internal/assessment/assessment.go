@@ -0,0 +1,264 @@
+// Package assessment evaluates whether StatefulSets can be migrated by the controller
+package assessment
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// SupportedClaimTemplate is the only volume claim template name the controller migrates
+const SupportedClaimTemplate = "data"
+
+// Input is a snapshot of a StatefulSet and the storage it uses
+type Input struct {
+	// StatefulSet is the StatefulSet being assessed
+	StatefulSet *appsv1.StatefulSet
+
+	// PVCs are the PVCs in the StatefulSet's namespace, keyed by name
+	PVCs map[string]*corev1.PersistentVolumeClaim
+
+	// PVs are the cluster's PVs, keyed by name
+	PVs map[string]*corev1.PersistentVolume
+
+	// DestServiceMissing is true when a destination was checked and its headless service is absent
+	DestServiceMissing bool
+}
+
+// AssessStatefulSet returns the migratability of a single StatefulSet
+func AssessStatefulSet(in Input) migrationv1alpha1.StatefulSetAssessment {
+	sts := in.StatefulSet
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+
+	result := migrationv1alpha1.StatefulSetAssessment{
+		Namespace: sts.Namespace,
+		Name:      sts.Name,
+		Replicas:  replicas,
+	}
+
+	var blocked []string
+	blocked = append(blocked, checkOwnership(sts)...)
+	blocked = append(blocked, checkPodVolumes(sts)...)
+	blocked = append(blocked, checkClaimTemplates(sts)...)
+
+	// Volume checks are only meaningful when the claim template layout is supported
+	if len(sts.Spec.VolumeClaimTemplates) == 1 && sts.Spec.VolumeClaimTemplates[0].Name == SupportedClaimTemplate {
+		for i := 0; i < int(replicas); i++ {
+			pvcName := translate.GetPVCNameForStatefulSetPod(SupportedClaimTemplate, sts.Name, i)
+			blocked = append(blocked, checkVolume(pvcName, in.PVCs, in.PVs)...)
+		}
+	}
+
+	switch {
+	case len(blocked) > 0:
+		result.Verdict = migrationv1alpha1.VerdictBlocked
+		result.Reasons = blocked
+	case in.DestServiceMissing:
+		result.Verdict = migrationv1alpha1.VerdictNeedsForce
+		result.Reasons = []string{fmt.Sprintf("headless service %q not found in destination", sts.Spec.ServiceName)}
+	default:
+		result.Verdict = migrationv1alpha1.VerdictMigratable
+	}
+
+	return result
+}
+
+// checkOwnership blocks StatefulSets managed by another controller, which would recreate them
+func checkOwnership(sts *appsv1.StatefulSet) []string {
+	for _, ref := range sts.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller {
+			return []string{fmt.Sprintf("managed by %s %s; the owning operator would recreate it", ref.Kind, ref.Name)}
+		}
+	}
+	return nil
+}
+
+// checkPodVolumes blocks pod templates that depend on node-local storage
+func checkPodVolumes(sts *appsv1.StatefulSet) []string {
+	var reasons []string
+	for _, vol := range sts.Spec.Template.Spec.Volumes {
+		if vol.HostPath != nil {
+			reasons = append(reasons, fmt.Sprintf("pod volume %q uses hostPath", vol.Name))
+		}
+	}
+	return reasons
+}
+
+// checkClaimTemplates blocks claim template layouts the controller cannot migrate
+func checkClaimTemplates(sts *appsv1.StatefulSet) []string {
+	templates := sts.Spec.VolumeClaimTemplates
+	if len(templates) == 0 {
+		return []string{"no volume claim templates"}
+	}
+
+	var reasons []string
+	if len(templates) > 1 || templates[0].Name != SupportedClaimTemplate {
+		reasons = append(reasons, fmt.Sprintf("only a single volume claim template named %q is supported", SupportedClaimTemplate))
+	}
+	for _, tmpl := range templates {
+		if mode, ok := sharedAccessMode(tmpl.Spec.AccessModes); ok {
+			reasons = append(reasons, fmt.Sprintf("volume claim template %q requests %s", tmpl.Name, mode))
+		}
+	}
+	return reasons
+}
+
+// checkVolume blocks a replica whose PVC or PV cannot be moved
+func checkVolume(pvcName string, pvcs map[string]*corev1.PersistentVolumeClaim, pvs map[string]*corev1.PersistentVolume) []string {
+	pvc, ok := pvcs[pvcName]
+	if !ok {
+		return []string{fmt.Sprintf("PVC %s not found", pvcName)}
+	}
+	if pvc.Spec.VolumeName == "" {
+		return []string{fmt.Sprintf("PVC %s is not bound", pvcName)}
+	}
+
+	pv, ok := pvs[pvc.Spec.VolumeName]
+	if !ok {
+		return []string{fmt.Sprintf("PV %s for PVC %s not found", pvc.Spec.VolumeName, pvcName)}
+	}
+	if pv.Spec.Local != nil || pv.Spec.HostPath != nil {
+		return []string{fmt.Sprintf("PV %s uses local storage", pv.Name)}
+	}
+	if mode, ok := sharedAccessMode(pv.Spec.AccessModes); ok {
+		return []string{fmt.Sprintf("PV %s is %s", pv.Name, mode)}
+	}
+	if err := translate.ValidatePVForMigration(pv); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+// sharedAccessMode returns the first access mode that allows multi-node attachment
+func sharedAccessMode(modes []corev1.PersistentVolumeAccessMode) (corev1.PersistentVolumeAccessMode, bool) {
+	for _, mode := range modes {
+		if mode == corev1.ReadWriteMany || mode == corev1.ReadOnlyMany {
+			return mode, true
+		}
+	}
+	return "", false
+}
+
+// Summarize counts results by verdict
+func Summarize(results []migrationv1alpha1.StatefulSetAssessment) migrationv1alpha1.AssessmentSummary {
+	summary := migrationv1alpha1.AssessmentSummary{Total: len(results)}
+	for _, r := range results {
+		switch r.Verdict {
+		case migrationv1alpha1.VerdictMigratable:
+			summary.Migratable++
+		case migrationv1alpha1.VerdictNeedsForce:
+			summary.NeedsForce++
+		case migrationv1alpha1.VerdictBlocked:
+			summary.Blocked++
+		}
+	}
+	return summary
+}
+
+// ScanConfig contains configuration for Scan
+type ScanConfig struct {
+	// Namespace limits the scan to a single namespace; all namespaces when empty
+	Namespace string
+
+	// Dest is a reader for the destination cluster (optional)
+	Dest client.Reader
+
+	// DestNamespace is the destination namespace; defaults to each StatefulSet's namespace
+	DestNamespace string
+}
+
+// Scan assesses every StatefulSet in the source cluster (or namespace) without modifying anything
+func Scan(ctx context.Context, source client.Reader, cfg ScanConfig) ([]migrationv1alpha1.StatefulSetAssessment, error) {
+	var listOpts []client.ListOption
+	if cfg.Namespace != "" {
+		listOpts = append(listOpts, client.InNamespace(cfg.Namespace))
+	}
+
+	stsList := &appsv1.StatefulSetList{}
+	if err := source.List(ctx, stsList, listOpts...); err != nil {
+		return nil, fmt.Errorf("failed to list StatefulSets: %w", err)
+	}
+
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := source.List(ctx, pvcList, listOpts...); err != nil {
+		return nil, fmt.Errorf("failed to list PVCs: %w", err)
+	}
+
+	pvList := &corev1.PersistentVolumeList{}
+	if err := source.List(ctx, pvList); err != nil {
+		return nil, fmt.Errorf("failed to list PVs: %w", err)
+	}
+
+	pvcsByNamespace := make(map[string]map[string]*corev1.PersistentVolumeClaim)
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		if pvcsByNamespace[pvc.Namespace] == nil {
+			pvcsByNamespace[pvc.Namespace] = make(map[string]*corev1.PersistentVolumeClaim)
+		}
+		pvcsByNamespace[pvc.Namespace][pvc.Name] = pvc
+	}
+
+	pvs := make(map[string]*corev1.PersistentVolume, len(pvList.Items))
+	for i := range pvList.Items {
+		pvs[pvList.Items[i].Name] = &pvList.Items[i]
+	}
+
+	results := make([]migrationv1alpha1.StatefulSetAssessment, 0, len(stsList.Items))
+	for i := range stsList.Items {
+		sts := &stsList.Items[i]
+
+		destServiceMissing, err := destServiceMissing(ctx, sts, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, AssessStatefulSet(Input{
+			StatefulSet:        sts,
+			PVCs:               pvcsByNamespace[sts.Namespace],
+			PVs:                pvs,
+			DestServiceMissing: destServiceMissing,
+		}))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Namespace != results[j].Namespace {
+			return results[i].Namespace < results[j].Namespace
+		}
+		return results[i].Name < results[j].Name
+	})
+
+	return results, nil
+}
+
+// destServiceMissing reports whether the StatefulSet's headless service is absent in the destination
+func destServiceMissing(ctx context.Context, sts *appsv1.StatefulSet, cfg ScanConfig) (bool, error) {
+	if cfg.Dest == nil || sts.Spec.ServiceName == "" {
+		return false, nil
+	}
+
+	namespace := cfg.DestNamespace
+	if namespace == "" {
+		namespace = sts.Namespace
+	}
+
+	err := cfg.Dest.Get(ctx, types.NamespacedName{Namespace: namespace, Name: sts.Spec.ServiceName}, &corev1.Service{})
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check destination service %s/%s: %w", namespace, sts.Spec.ServiceName, err)
+	}
+	return false, nil
+}
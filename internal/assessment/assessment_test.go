@@ -0,0 +1,223 @@
+package assessment
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func testStatefulSet(replicas int32, templates ...string) *appsv1.StatefulSet {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: "web",
+		},
+	}
+	for _, name := range templates {
+		sts.Spec.VolumeClaimTemplates = append(sts.Spec.VolumeClaimTemplates, corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			},
+		})
+	}
+	return sts
+}
+
+func testPVC(name, volume string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: volume},
+	}
+}
+
+func testEBSPV(name string) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-" + name},
+			},
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+	}
+}
+
+func TestAssessStatefulSet(t *testing.T) {
+	controller := true
+
+	tests := []struct {
+		name        string
+		input       func() Input
+		wantVerdict migrationv1alpha1.AssessmentVerdict
+		wantReasons int
+	}{
+		{
+			name: "EBS backed StatefulSet is migratable",
+			input: func() Input {
+				return Input{
+					StatefulSet: testStatefulSet(2, "data"),
+					PVCs:        map[string]*corev1.PersistentVolumeClaim{"data-web-0": testPVC("data-web-0", "pv-0"), "data-web-1": testPVC("data-web-1", "pv-1")},
+					PVs:         map[string]*corev1.PersistentVolume{"pv-0": testEBSPV("pv-0"), "pv-1": testEBSPV("pv-1")},
+				}
+			},
+			wantVerdict: migrationv1alpha1.VerdictMigratable,
+		},
+		{
+			name: "missing destination service needs force",
+			input: func() Input {
+				return Input{
+					StatefulSet:        testStatefulSet(1, "data"),
+					PVCs:               map[string]*corev1.PersistentVolumeClaim{"data-web-0": testPVC("data-web-0", "pv-0")},
+					PVs:                map[string]*corev1.PersistentVolume{"pv-0": testEBSPV("pv-0")},
+					DestServiceMissing: true,
+				}
+			},
+			wantVerdict: migrationv1alpha1.VerdictNeedsForce,
+			wantReasons: 1,
+		},
+		{
+			name: "non-EBS volume is blocked",
+			input: func() Input {
+				pv := testEBSPV("pv-0")
+				pv.Spec.CSI.Driver = "efs.csi.aws.com"
+				return Input{
+					StatefulSet: testStatefulSet(1, "data"),
+					PVCs:        map[string]*corev1.PersistentVolumeClaim{"data-web-0": testPVC("data-web-0", "pv-0")},
+					PVs:         map[string]*corev1.PersistentVolume{"pv-0": pv},
+				}
+			},
+			wantVerdict: migrationv1alpha1.VerdictBlocked,
+			wantReasons: 1,
+		},
+		{
+			name: "RWX PV is blocked",
+			input: func() Input {
+				pv := testEBSPV("pv-0")
+				pv.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+				return Input{
+					StatefulSet: testStatefulSet(1, "data"),
+					PVCs:        map[string]*corev1.PersistentVolumeClaim{"data-web-0": testPVC("data-web-0", "pv-0")},
+					PVs:         map[string]*corev1.PersistentVolume{"pv-0": pv},
+				}
+			},
+			wantVerdict: migrationv1alpha1.VerdictBlocked,
+			wantReasons: 1,
+		},
+		{
+			name: "local PV is blocked",
+			input: func() Input {
+				pv := testEBSPV("pv-0")
+				pv.Spec.CSI = nil
+				pv.Spec.Local = &corev1.LocalVolumeSource{Path: "/mnt/disks/ssd0"}
+				return Input{
+					StatefulSet: testStatefulSet(1, "data"),
+					PVCs:        map[string]*corev1.PersistentVolumeClaim{"data-web-0": testPVC("data-web-0", "pv-0")},
+					PVs:         map[string]*corev1.PersistentVolume{"pv-0": pv},
+				}
+			},
+			wantVerdict: migrationv1alpha1.VerdictBlocked,
+			wantReasons: 1,
+		},
+		{
+			name: "operator managed StatefulSet is blocked",
+			input: func() Input {
+				sts := testStatefulSet(1, "data")
+				sts.OwnerReferences = []metav1.OwnerReference{{Kind: "Kafka", Name: "events", Controller: &controller}}
+				return Input{
+					StatefulSet: sts,
+					PVCs:        map[string]*corev1.PersistentVolumeClaim{"data-web-0": testPVC("data-web-0", "pv-0")},
+					PVs:         map[string]*corev1.PersistentVolume{"pv-0": testEBSPV("pv-0")},
+				}
+			},
+			wantVerdict: migrationv1alpha1.VerdictBlocked,
+			wantReasons: 1,
+		},
+		{
+			name: "multiple claim templates are blocked",
+			input: func() Input {
+				return Input{StatefulSet: testStatefulSet(1, "data", "logs")}
+			},
+			wantVerdict: migrationv1alpha1.VerdictBlocked,
+			wantReasons: 1,
+		},
+		{
+			name: "no claim templates are blocked",
+			input: func() Input {
+				return Input{StatefulSet: testStatefulSet(1)}
+			},
+			wantVerdict: migrationv1alpha1.VerdictBlocked,
+			wantReasons: 1,
+		},
+		{
+			name: "missing and unbound PVCs are blocked",
+			input: func() Input {
+				return Input{
+					StatefulSet: testStatefulSet(2, "data"),
+					PVCs:        map[string]*corev1.PersistentVolumeClaim{"data-web-0": testPVC("data-web-0", "")},
+				}
+			},
+			wantVerdict: migrationv1alpha1.VerdictBlocked,
+			wantReasons: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AssessStatefulSet(tt.input())
+			if got.Verdict != tt.wantVerdict {
+				t.Errorf("Verdict = %s, want %s (reasons: %v)", got.Verdict, tt.wantVerdict, got.Reasons)
+			}
+			if len(got.Reasons) != tt.wantReasons {
+				t.Errorf("len(Reasons) = %d, want %d: %v", len(got.Reasons), tt.wantReasons, got.Reasons)
+			}
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	migratable := testStatefulSet(1, "data")
+	blocked := testStatefulSet(1)
+	blocked.Name = "cache"
+
+	source := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		migratable, blocked, testPVC("data-web-0", "pv-0"), testEBSPV("pv-0"),
+	).Build()
+	dest := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	results, err := Scan(context.Background(), source, ScanConfig{Namespace: "prod", Dest: dest})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Scan() returned %d results, want 2", len(results))
+	}
+	if results[0].Name != "cache" || results[0].Verdict != migrationv1alpha1.VerdictBlocked {
+		t.Errorf("results[0] = %+v, want blocked cache", results[0])
+	}
+	if results[1].Name != "web" || results[1].Verdict != migrationv1alpha1.VerdictNeedsForce {
+		t.Errorf("results[1] = %+v, want web needing force", results[1])
+	}
+
+	summary := Summarize(results)
+	if summary.Total != 2 || summary.Blocked != 1 || summary.NeedsForce != 1 || summary.Migratable != 0 {
+		t.Errorf("Summarize() = %+v", summary)
+	}
+}
@@ -0,0 +1,169 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// ClusterTagPrefix prefixes the tag EKS and Karpenter put on every node of a
+// cluster: kubernetes.io/cluster/<name> = owned or shared
+const ClusterTagPrefix = "kubernetes.io/cluster/"
+
+// WaitForVolumeAttachConfig contains configuration for WaitForVolumeAttach
+type WaitForVolumeAttachConfig struct {
+	// PollInterval is how often to check the volume's attachments (default: 5s)
+	PollInterval time.Duration
+
+	// Timeout is the maximum time to wait (default: 5m)
+	Timeout time.Duration
+
+	// InstanceTags are the tags an instance must carry to count as a node of
+	// the destination cluster. An empty value matches any value of the tag.
+	InstanceTags map[string]string
+
+	// OnPoll is called each time the volume is polled (optional)
+	OnPoll func(info *VolumeInfo)
+}
+
+// WaitForVolumeAttach blocks until the EBS volume is attached to an instance
+// carrying cfg.InstanceTags, confirming from the storage side that the
+// destination cluster has taken the volume over. Attachments to other
+// instances, such as a source node still detaching, are waited out.
+func (c *EBSClient) WaitForVolumeAttach(ctx context.Context, volumeID string, cfg WaitForVolumeAttachConfig) (*VolumeAttachment, error) {
+	if len(cfg.InstanceTags) == 0 {
+		return nil, fmt.Errorf("no instance tags to identify the destination cluster's instances")
+	}
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Minute
+	}
+
+	timeout := c.clock.NewTimer(cfg.Timeout)
+	defer timeout.Stop()
+
+	ticker := c.clock.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	// Instance tags do not change while we wait, so each instance is described once
+	matches := make(map[string]bool)
+
+	var info *VolumeInfo
+	poll := func() (*VolumeAttachment, error) {
+		polled, err := c.GetVolumeInfo(ctx, volumeID)
+		if err != nil {
+			return nil, err
+		}
+		info = polled
+		if cfg.OnPoll != nil {
+			cfg.OnPoll(info)
+		}
+		if info.State == types.VolumeStateDeleted || info.State == types.VolumeStateDeleting || info.State == types.VolumeStateError {
+			return nil, fmt.Errorf("volume %s is %s", volumeID, info.State)
+		}
+
+		for i, att := range info.Attachments {
+			if att.State != types.VolumeAttachmentStateAttached || att.InstanceID == "" {
+				continue
+			}
+			match, seen := matches[att.InstanceID]
+			if !seen {
+				tags, err := c.GetInstanceTags(ctx, att.InstanceID)
+				if err != nil {
+					return nil, err
+				}
+				match = tagsMatch(tags, cfg.InstanceTags)
+				matches[att.InstanceID] = match
+			}
+			if match {
+				return &info.Attachments[i], nil
+			}
+		}
+		return nil, nil
+	}
+
+	// Check immediately first
+	att, err := poll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check volume attachments: %w", err)
+	}
+	if att != nil {
+		return att, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case <-timeout.C():
+			attachments := "none"
+			if len(info.Attachments) > 0 {
+				attachments = FormatAttachments(info.Attachments)
+			}
+			return nil, fmt.Errorf("timed out after %v waiting for volume %s to attach to a destination instance (attachments: %s)",
+				cfg.Timeout, volumeID, attachments)
+
+		case <-ticker.C():
+			att, err := poll()
+			if Retryable(err) {
+				continue // Throttled; poll again on the next tick
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to check volume attachments: %w", err)
+			}
+			if att != nil {
+				return att, nil
+			}
+		}
+	}
+}
+
+// AttachedInstanceID returns the instance the volume is attached to, or ""
+// when it is attached to none. A Multi-Attach volume reports the first of
+// its instances.
+func (info *VolumeInfo) AttachedInstanceID() string {
+	for _, att := range info.Attachments {
+		if att.State == types.VolumeAttachmentStateAttached && att.InstanceID != "" {
+			return att.InstanceID
+		}
+	}
+	return ""
+}
+
+// GetInstanceTags returns the tags of an EC2 instance
+func (c *EBSClient) GetInstanceTags(ctx context.Context, instanceID string) (map[string]string, error) {
+	resp, err := c.ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: []string{instanceID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe instance %s: %w", instanceID, c.classifyError("DescribeInstances", instanceID, err))
+	}
+
+	tags := make(map[string]string)
+	for _, reservation := range resp.Reservations {
+		for _, instance := range reservation.Instances {
+			for _, tag := range instance.Tags {
+				tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+		}
+	}
+	return tags, nil
+}
+
+// tagsMatch reports whether tags carry every wanted tag; an empty wanted value matches any value
+func tagsMatch(tags, want map[string]string) bool {
+	for key, value := range want {
+		got, ok := tags[key]
+		if !ok || (value != "" && got != value) {
+			return false
+		}
+	}
+	return true
+}
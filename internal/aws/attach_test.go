@@ -0,0 +1,134 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestWaitForVolumeAttach(t *testing.T) {
+	attachedTo := func(instanceID string, state types.VolumeAttachmentState) func() (*ec2.DescribeVolumesOutput, error) {
+		return volumeResponse(types.VolumeStateInUse, types.VolumeAttachment{InstanceId: aws.String(instanceID), State: state})
+	}
+	available := volumeResponse(types.VolumeStateAvailable)
+	instances := map[string]map[string]string{
+		"i-source": {ClusterTagPrefix + "old": "owned"},
+		"i-dest":   {ClusterTagPrefix + "new": "owned", "eks:nodegroup-name": "db"},
+	}
+
+	tests := []struct {
+		name         string
+		responses    []func() (*ec2.DescribeVolumesOutput, error)
+		tags         map[string]string
+		wantInstance string
+		wantErr      string
+	}{
+		{
+			name:         "already attached to the destination",
+			responses:    []func() (*ec2.DescribeVolumesOutput, error){attachedTo("i-dest", types.VolumeAttachmentStateAttached)},
+			tags:         map[string]string{ClusterTagPrefix + "new": ""},
+			wantInstance: "i-dest",
+		},
+		{
+			name: "source detaches, destination attaches",
+			responses: []func() (*ec2.DescribeVolumesOutput, error){
+				attachedTo("i-source", types.VolumeAttachmentStateAttached),
+				attachedTo("i-source", types.VolumeAttachmentStateDetaching),
+				available,
+				attachedTo("i-dest", types.VolumeAttachmentStateAttaching),
+				throttled[ec2.DescribeVolumesOutput](),
+				attachedTo("i-dest", types.VolumeAttachmentStateAttached),
+			},
+			tags:         map[string]string{ClusterTagPrefix + "new": "", "eks:nodegroup-name": "db"},
+			wantInstance: "i-dest",
+		},
+		{
+			name:      "still on the source",
+			responses: []func() (*ec2.DescribeVolumesOutput, error){attachedTo("i-source", types.VolumeAttachmentStateAttached)},
+			tags:      map[string]string{ClusterTagPrefix + "new": ""},
+			wantErr:   "attachments: i-source (attached)",
+		},
+		{
+			name:      "tag value must match",
+			responses: []func() (*ec2.DescribeVolumesOutput, error){attachedTo("i-dest", types.VolumeAttachmentStateAttached)},
+			tags:      map[string]string{"eks:nodegroup-name": "web"},
+			wantErr:   "timed out",
+		},
+		{
+			name:      "no tags",
+			responses: []func() (*ec2.DescribeVolumesOutput, error){available},
+			wantErr:   "no instance tags",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			c := NewEBSClientFromAPI(&fakeEC2{volumes: tt.responses, instances: instances}, clk, "us-east-1")
+
+			var att *VolumeAttachment
+			err := runWithFakeClock(t, clk, time.Minute, func() error {
+				var err error
+				att, err = c.WaitForVolumeAttach(context.Background(), "vol-1", WaitForVolumeAttachConfig{
+					PollInterval: 5 * time.Second,
+					Timeout:      time.Hour,
+					InstanceTags: tt.tags,
+				})
+				return err
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("WaitForVolumeAttach() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WaitForVolumeAttach() error = %v", err)
+			}
+			if att.InstanceID != tt.wantInstance {
+				t.Errorf("attached to %s, want %s", att.InstanceID, tt.wantInstance)
+			}
+		})
+	}
+}
+
+func TestAttachedInstanceID(t *testing.T) {
+	tests := []struct {
+		name        string
+		attachments []VolumeAttachment
+		want        string
+	}{
+		{name: "not attached"},
+		{
+			name:        "attached",
+			attachments: []VolumeAttachment{{InstanceID: "i-dest", State: types.VolumeAttachmentStateAttached}},
+			want:        "i-dest",
+		},
+		{
+			name: "detaching attachment skipped",
+			attachments: []VolumeAttachment{
+				{InstanceID: "i-source", State: types.VolumeAttachmentStateDetaching},
+				{InstanceID: "i-dest", State: types.VolumeAttachmentStateAttached},
+			},
+			want: "i-dest",
+		},
+		{
+			name:        "still attaching",
+			attachments: []VolumeAttachment{{InstanceID: "i-dest", State: types.VolumeAttachmentStateAttaching}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &VolumeInfo{Attachments: tt.attachments}
+			if got := info.AttachedInstanceID(); got != tt.want {
+				t.Errorf("AttachedInstanceID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
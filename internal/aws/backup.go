@@ -0,0 +1,157 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+const (
+	// DLMPolicyTagKey is set by Data Lifecycle Manager on every snapshot a policy creates
+	DLMPolicyTagKey = "aws:dlm:lifecycle-policy-id"
+
+	// BackupSourceTagKey is set by AWS Backup on the EBS snapshots it creates
+	BackupSourceTagKey = "aws:backup:source-resource"
+)
+
+// BackupManager is the AWS service that takes a volume's scheduled snapshots
+type BackupManager string
+
+const (
+	// BackupManagerDLM is Amazon Data Lifecycle Manager
+	BackupManagerDLM BackupManager = "DLM"
+
+	// BackupManagerAWSBackup is AWS Backup
+	BackupManagerAWSBackup BackupManager = "AWS Backup"
+)
+
+// VolumeBackup summarizes the snapshots one backup policy has taken of a volume
+type VolumeBackup struct {
+	// Manager is the service that took the snapshots
+	Manager BackupManager
+
+	// PolicyID is the DLM policy; AWS Backup does not record its plan on the snapshot
+	PolicyID string
+
+	// Snapshots is the number of snapshots the policy still retains
+	Snapshots int
+
+	// Latest is when the newest of them was started
+	Latest time.Time
+}
+
+// String returns a human-readable summary such as "DLM policy policy-0123 (3 snapshots, latest 2024-05-01T02:00:00Z)"
+func (b VolumeBackup) String() string {
+	name := string(b.Manager)
+	if b.PolicyID != "" {
+		name += " policy " + b.PolicyID
+	}
+	return fmt.Sprintf("%s (%d snapshots, latest %s)", name, b.Snapshots, b.Latest.UTC().Format(time.RFC3339))
+}
+
+// GetVolumeBackups returns the DLM policies and AWS Backup plans that take
+// scheduled snapshots of a volume. Both select volumes by tag, and neither
+// can be asked about a volume directly without its own API permissions, so
+// they are found from the tags they put on the snapshots they create. A
+// policy that has not run yet is not found.
+func (c *EBSClient) GetVolumeBackups(ctx context.Context, volumeID string) ([]VolumeBackup, error) {
+	byPolicy := make(map[string]*VolumeBackup)
+	snapshots := ec2.NewDescribeSnapshotsPaginator(c.ec2Client, &ec2.DescribeSnapshotsInput{
+		OwnerIds: []string{"self"},
+		Filters:  []types.Filter{{Name: aws.String("volume-id"), Values: []string{volumeID}}},
+	})
+	for snapshots.HasMorePages() {
+		page, err := snapshots.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe snapshots of volume %s: %w", volumeID, c.classifyError("DescribeSnapshots", volumeID, err))
+		}
+		for _, snap := range page.Snapshots {
+			backup := VolumeBackup{}
+			for _, tag := range snap.Tags {
+				switch aws.ToString(tag.Key) {
+				case DLMPolicyTagKey:
+					backup.Manager, backup.PolicyID = BackupManagerDLM, aws.ToString(tag.Value)
+				case BackupSourceTagKey:
+					backup.Manager = BackupManagerAWSBackup
+				}
+			}
+			if backup.Manager == "" {
+				continue
+			}
+
+			key := string(backup.Manager) + "/" + backup.PolicyID
+			if byPolicy[key] == nil {
+				byPolicy[key] = &backup
+			}
+			byPolicy[key].Snapshots++
+			if start := aws.ToTime(snap.StartTime); start.After(byPolicy[key].Latest) {
+				byPolicy[key].Latest = start
+			}
+		}
+	}
+
+	backups := make([]VolumeBackup, 0, len(byPolicy))
+	for _, backup := range byPolicy {
+		backups = append(backups, *backup)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].Manager != backups[j].Manager {
+			return backups[i].Manager < backups[j].Manager
+		}
+		return backups[i].PolicyID < backups[j].PolicyID
+	})
+	return backups, nil
+}
+
+// RetagVolume sets and removes tags on a volume. Tags with the reserved aws:
+// prefix cannot be changed and are rejected before calling EC2.
+func (c *EBSClient) RetagVolume(ctx context.Context, volumeID string, set map[string]string, remove []string) error {
+	for key := range set {
+		if strings.HasPrefix(key, "aws:") {
+			return fmt.Errorf("tag %s uses the reserved aws: prefix", key)
+		}
+	}
+	for _, key := range remove {
+		if strings.HasPrefix(key, "aws:") {
+			return fmt.Errorf("tag %s uses the reserved aws: prefix", key)
+		}
+	}
+
+	if len(remove) > 0 {
+		tags := make([]types.Tag, 0, len(remove))
+		for _, key := range remove {
+			tags = append(tags, types.Tag{Key: aws.String(key)})
+		}
+		if _, err := c.ec2Client.DeleteTags(ctx, &ec2.DeleteTagsInput{
+			Resources: []string{volumeID},
+			Tags:      tags,
+		}); err != nil {
+			return fmt.Errorf("failed to remove tags from volume %s: %w", volumeID, c.classifyError("DeleteTags", volumeID, err))
+		}
+	}
+
+	if len(set) > 0 {
+		keys := make([]string, 0, len(set))
+		for key := range set {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		tags := make([]types.Tag, 0, len(set))
+		for _, key := range keys {
+			tags = append(tags, types.Tag{Key: aws.String(key), Value: aws.String(set[key])})
+		}
+		if _, err := c.ec2Client.CreateTags(ctx, &ec2.CreateTagsInput{
+			Resources: []string{volumeID},
+			Tags:      tags,
+		}); err != nil {
+			return fmt.Errorf("failed to tag volume %s: %w", volumeID, c.classifyError("CreateTags", volumeID, err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// tagEC2 records the tag calls it receives
+type tagEC2 struct {
+	fakeEC2
+	created []*ec2.CreateTagsInput
+	deleted []*ec2.DeleteTagsInput
+}
+
+func (f *tagEC2) CreateTags(_ context.Context, in *ec2.CreateTagsInput, _ ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	f.created = append(f.created, in)
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func (f *tagEC2) DeleteTags(_ context.Context, in *ec2.DeleteTagsInput, _ ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
+	f.deleted = append(f.deleted, in)
+	return &ec2.DeleteTagsOutput{}, nil
+}
+
+func backupSnapshot(id string, start time.Time, tags ...string) types.Snapshot {
+	snap := types.Snapshot{SnapshotId: aws.String(id), VolumeId: aws.String("vol-1"), StartTime: aws.Time(start)}
+	for i := 0; i+1 < len(tags); i += 2 {
+		snap.Tags = append(snap.Tags, types.Tag{Key: aws.String(tags[i]), Value: aws.String(tags[i+1])})
+	}
+	return snap
+}
+
+func TestGetVolumeBackups(t *testing.T) {
+	day1 := time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	api := &fakeEC2{snapshots: []func() (*ec2.DescribeSnapshotsOutput, error){taggedSnapshots(
+		backupSnapshot("snap-1", day1, DLMPolicyTagKey, "policy-b"),
+		backupSnapshot("snap-2", day2, DLMPolicyTagKey, "policy-b", "team", "payments"),
+		backupSnapshot("snap-3", day1, DLMPolicyTagKey, "policy-a"),
+		backupSnapshot("snap-4", day2, BackupSourceTagKey, "arn:aws:ec2:us-east-1::volume/vol-1"),
+		backupSnapshot("snap-5", day2, "team", "payments"),
+	)}}
+	c := NewEBSClientFromAPI(api, clocktesting.NewFakeClock(time.Now()), "us-east-1")
+
+	got, err := c.GetVolumeBackups(context.Background(), "vol-1")
+	if err != nil {
+		t.Fatalf("GetVolumeBackups() error = %v", err)
+	}
+	want := []VolumeBackup{
+		{Manager: BackupManagerAWSBackup, Snapshots: 1, Latest: day2},
+		{Manager: BackupManagerDLM, PolicyID: "policy-a", Snapshots: 1, Latest: day1},
+		{Manager: BackupManagerDLM, PolicyID: "policy-b", Snapshots: 2, Latest: day2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("GetVolumeBackups() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("GetVolumeBackups()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if s := got[2].String(); s != "DLM policy policy-b (2 snapshots, latest 2024-05-02T02:00:00Z)" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestRetagVolume(t *testing.T) {
+	tests := []struct {
+		name        string
+		set         map[string]string
+		remove      []string
+		wantCreated int
+		wantDeleted int
+		wantErr     string
+	}{
+		{
+			name:        "set and remove",
+			set:         map[string]string{"team": "platform", "backup-plan": "dest-daily"},
+			remove:      []string{"backup-plan-source"},
+			wantCreated: 1,
+			wantDeleted: 1,
+		},
+		{
+			name:        "set only",
+			set:         map[string]string{"team": "platform"},
+			wantCreated: 1,
+		},
+		{
+			name:    "reserved prefix",
+			set:     map[string]string{"aws:backup:source-resource": "x"},
+			wantErr: "reserved aws: prefix",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &tagEC2{}
+			c := NewEBSClientFromAPI(api, clocktesting.NewFakeClock(time.Now()), "us-east-1")
+
+			err := c.RetagVolume(context.Background(), "vol-1", tt.set, tt.remove)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("RetagVolume() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RetagVolume() error = %v", err)
+			}
+			if len(api.created) != tt.wantCreated || len(api.deleted) != tt.wantDeleted {
+				t.Errorf("RetagVolume() made %d CreateTags and %d DeleteTags calls, want %d and %d",
+					len(api.created), len(api.deleted), tt.wantCreated, tt.wantDeleted)
+			}
+			if tt.wantCreated > 0 && len(api.created[0].Tags) != len(tt.set) {
+				t.Errorf("RetagVolume() set %d tags, want %d", len(api.created[0].Tags), len(tt.set))
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// DefaultMaxConcurrentSnapshots matches the default quota of 20 concurrent
+// snapshot copies per destination Region
+const DefaultMaxConcurrentSnapshots = 20
+
+// ConcurrencyLimits caps EBS operations running at once across everything
+// that shares an EBSClient, such as every migration a controller runs. Zero
+// means unlimited.
+type ConcurrencyLimits struct {
+	// Snapshots caps snapshots and snapshot copies in progress
+	Snapshots int
+
+	// Detaches caps volume detach waits in progress
+	Detaches int
+
+	// Describes caps Describe API calls in flight
+	Describes int
+}
+
+// semaphore is a counting semaphore; a nil semaphore never blocks
+type semaphore chan struct{}
+
+func newSemaphore(n int) semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return make(semaphore, n)
+}
+
+// acquire takes a slot, waiting until one is free or ctx is done, and
+// returns the function that gives it back
+func (s semaphore) acquire(ctx context.Context) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	select {
+	case s <- struct{}{}:
+		return func() { <-s }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// concurrencyLimiter holds the semaphores of a ConcurrencyLimits. Clients
+// derived from one another, as with AssumeRole, share it.
+type concurrencyLimiter struct {
+	snapshots semaphore
+	detaches  semaphore
+	describes semaphore
+}
+
+func newConcurrencyLimiter(limits ConcurrencyLimits) *concurrencyLimiter {
+	return &concurrencyLimiter{
+		snapshots: newSemaphore(limits.Snapshots),
+		detaches:  newSemaphore(limits.Detaches),
+		describes: newSemaphore(limits.Describes),
+	}
+}
+
+// AcquireSnapshotSlot waits for a free snapshot slot and returns the function
+// that releases it. Callers hold the slot from creating a snapshot or copy
+// until it completes.
+func (c *EBSClient) AcquireSnapshotSlot(ctx context.Context) (func(), error) {
+	if c.limiter == nil {
+		return func() {}, nil
+	}
+	return c.limiter.snapshots.acquire(ctx)
+}
+
+// acquireDetachSlot waits for a free detach slot
+func (c *EBSClient) acquireDetachSlot(ctx context.Context) (func(), error) {
+	if c.limiter == nil {
+		return func() {}, nil
+	}
+	return c.limiter.detaches.acquire(ctx)
+}
+
+// limitedEC2 holds a describe slot for the duration of every Describe call
+type limitedEC2 struct {
+	EC2API
+	describes semaphore
+}
+
+func limitDescribe[In, Out any](ctx context.Context, s semaphore, call func(context.Context, In, ...func(*ec2.Options)) (Out, error), in In, optFns []func(*ec2.Options)) (Out, error) {
+	release, err := s.acquire(ctx)
+	if err != nil {
+		var zero Out
+		return zero, err
+	}
+	defer release()
+	return call(ctx, in, optFns...)
+}
+
+func (l *limitedEC2) DescribeVolumes(ctx context.Context, in *ec2.DescribeVolumesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	return limitDescribe(ctx, l.describes, l.EC2API.DescribeVolumes, in, optFns)
+}
+
+func (l *limitedEC2) DescribeSnapshots(ctx context.Context, in *ec2.DescribeSnapshotsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+	return limitDescribe(ctx, l.describes, l.EC2API.DescribeSnapshots, in, optFns)
+}
+
+func (l *limitedEC2) DescribeVolumesModifications(ctx context.Context, in *ec2.DescribeVolumesModificationsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesModificationsOutput, error) {
+	return limitDescribe(ctx, l.describes, l.EC2API.DescribeVolumesModifications, in, optFns)
+}
+
+func (l *limitedEC2) DescribeFastSnapshotRestores(ctx context.Context, in *ec2.DescribeFastSnapshotRestoresInput, optFns ...func(*ec2.Options)) (*ec2.DescribeFastSnapshotRestoresOutput, error) {
+	return limitDescribe(ctx, l.describes, l.EC2API.DescribeFastSnapshotRestores, in, optFns)
+}
+
+func (l *limitedEC2) DescribeInstances(ctx context.Context, in *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return limitDescribe(ctx, l.describes, l.EC2API.DescribeInstances, in, optFns)
+}
+
+func (l *limitedEC2) DescribeInstanceStatus(ctx context.Context, in *ec2.DescribeInstanceStatusInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceStatusOutput, error) {
+	return limitDescribe(ctx, l.describes, l.EC2API.DescribeInstanceStatus, in, optFns)
+}
+
+func (l *limitedEC2) DescribeAvailabilityZones(ctx context.Context, in *ec2.DescribeAvailabilityZonesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	return limitDescribe(ctx, l.describes, l.EC2API.DescribeAvailabilityZones, in, optFns)
+}
+
+// limitEC2 wraps an EC2 API so its Describe calls take a describe slot
+func limitEC2(api EC2API, limiter *concurrencyLimiter) EC2API {
+	if limiter == nil || limiter.describes == nil {
+		return api
+	}
+	return &limitedEC2{EC2API: api, describes: limiter.describes}
+}
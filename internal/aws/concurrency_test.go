@@ -0,0 +1,98 @@
+package aws
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestSemaphore(t *testing.T) {
+	s := newSemaphore(1)
+	release, err := s.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.acquire(ctx); err == nil {
+		t.Fatal("acquire() of a full semaphore succeeded, want the context error")
+	}
+
+	release()
+	if _, err := s.acquire(context.Background()); err != nil {
+		t.Errorf("acquire() after release error = %v", err)
+	}
+
+	if _, err := semaphore(nil).acquire(context.Background()); err != nil {
+		t.Errorf("acquire() of an unlimited semaphore error = %v", err)
+	}
+}
+
+// blockingEC2 blocks DescribeVolumes until released and records the peak number of calls in flight
+type blockingEC2 struct {
+	fakeEC2
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	release  chan struct{}
+}
+
+func (f *blockingEC2) DescribeVolumes(context.Context, *ec2.DescribeVolumesInput, ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	f.mu.Lock()
+	f.inFlight++
+	f.peak = max(f.peak, f.inFlight)
+	f.mu.Unlock()
+
+	<-f.release
+
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+	return volumeResponse("available")()
+}
+
+func TestLimitedDescribes(t *testing.T) {
+	api := &blockingEC2{release: make(chan struct{})}
+	limiter := newConcurrencyLimiter(ConcurrencyLimits{Describes: 2})
+	c := NewEBSClientFromAPI(limitEC2(api, limiter), clocktesting.NewFakeClock(time.Now()), "us-east-1")
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = c.GetVolumeInfo(context.Background(), "vol-1")
+		}()
+	}
+	// Release the calls one at a time once the limit is reached, so the peak
+	// shows whether a third call got past the limiter
+	for remaining := 5; remaining > 0; remaining-- {
+		waitForInFlight(t, api, min(2, remaining))
+		api.release <- struct{}{}
+	}
+	wg.Wait()
+
+	if api.peak != 2 {
+		t.Errorf("peak concurrent DescribeVolumes = %d, want 2", api.peak)
+	}
+}
+
+func waitForInFlight(t *testing.T, api *blockingEC2, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		api.mu.Lock()
+		inFlight := api.inFlight
+		api.mu.Unlock()
+		if inFlight >= want {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d DescribeVolumes calls in flight", want)
+}
@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/endpointcreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// CredentialSource selects where an EBSClient gets its AWS credentials
+type CredentialSource string
+
+const (
+	// CredentialSourceDefault searches the SDK's default chain: environment,
+	// shared config, web identity, container endpoint and instance metadata
+	CredentialSourceDefault CredentialSource = "default"
+
+	// CredentialSourceIRSA assumes AWS_ROLE_ARN with the service account token
+	// in AWS_WEB_IDENTITY_TOKEN_FILE (IAM Roles for Service Accounts)
+	CredentialSourceIRSA CredentialSource = "irsa"
+
+	// CredentialSourcePodIdentity fetches credentials from the EKS Pod Identity
+	// agent at AWS_CONTAINER_CREDENTIALS_FULL_URI, authorized with the token in
+	// AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE
+	CredentialSourcePodIdentity CredentialSource = "pod-identity"
+
+	// CredentialSourceStatic uses the access key in EBSClientConfig.StaticCredentials
+	CredentialSourceStatic CredentialSource = "static"
+
+	// CredentialSourceProfile uses EBSClientConfig.Profile from the shared config files
+	CredentialSourceProfile CredentialSource = "profile"
+
+	// CredentialSourceIMDS uses the node's instance profile through IMDSv2,
+	// without falling back to IMDSv1
+	CredentialSourceIMDS CredentialSource = "imds"
+)
+
+// CredentialSources lists every credential source, for flag help and validation
+var CredentialSources = []CredentialSource{
+	CredentialSourceDefault, CredentialSourceIRSA, CredentialSourcePodIdentity,
+	CredentialSourceStatic, CredentialSourceProfile, CredentialSourceIMDS,
+}
+
+// credentialCheckTimeout bounds the credential retrieval NewEBSClient runs to
+// validate an explicitly chosen source
+const credentialCheckTimeout = 30 * time.Second
+
+// StaticCredentials is an access key, e.g. one read from a Kubernetes secret
+type StaticCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// SessionToken is set for temporary credentials
+	SessionToken string
+}
+
+// ParseCredentialSource validates a credential source name; empty is the default chain
+func ParseCredentialSource(s string) (CredentialSource, error) {
+	if s == "" {
+		return CredentialSourceDefault, nil
+	}
+	for _, source := range CredentialSources {
+		if CredentialSource(s) == source {
+			return source, nil
+		}
+	}
+	names := make([]string, len(CredentialSources))
+	for i, source := range CredentialSources {
+		names[i] = string(source)
+	}
+	return "", fmt.Errorf("unknown AWS credential source %q, want one of %s", s, strings.Join(names, ", "))
+}
+
+// credentialsProvider returns the provider for an explicitly chosen source,
+// or nil to keep the default chain LoadDefaultConfig resolved. It fails when
+// the source is missing the settings it needs, before any call is made.
+func credentialsProvider(awsCfg aws.Config, cfg EBSClientConfig) (aws.CredentialsProvider, error) {
+	switch cfg.CredentialSource {
+	case "", CredentialSourceDefault:
+		return nil, nil
+
+	case CredentialSourceIRSA:
+		roleARN, tokenFile := os.Getenv("AWS_ROLE_ARN"), os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+		if roleARN == "" || tokenFile == "" {
+			return nil, fmt.Errorf("credential source %s needs AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE; "+
+				"annotate the service account with eks.amazonaws.com/role-arn", cfg.CredentialSource)
+		}
+		var stsOpts []func(*sts.Options)
+		if cfg.Endpoint != "" {
+			stsOpts = append(stsOpts, func(o *sts.Options) {
+				o.BaseEndpoint = aws.String(cfg.Endpoint)
+			})
+		}
+		return stscreds.NewWebIdentityRoleProvider(sts.NewFromConfig(awsCfg, stsOpts...), roleARN,
+			stscreds.IdentityTokenFile(tokenFile), func(o *stscreds.WebIdentityRoleOptions) {
+				o.RoleSessionName = RoleSessionName
+			}), nil
+
+	case CredentialSourcePodIdentity:
+		uri, tokenFile := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI"), os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE")
+		if uri == "" || tokenFile == "" {
+			return nil, fmt.Errorf("credential source %s needs AWS_CONTAINER_CREDENTIALS_FULL_URI and "+
+				"AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE; create a pod identity association for the service account", cfg.CredentialSource)
+		}
+		return endpointcreds.New(uri, func(o *endpointcreds.Options) {
+			o.AuthorizationTokenProvider = tokenFileProvider(tokenFile)
+		}), nil
+
+	case CredentialSourceStatic:
+		static := cfg.StaticCredentials
+		if static == nil || static.AccessKeyID == "" || static.SecretAccessKey == "" {
+			return nil, fmt.Errorf("credential source %s needs an access key ID and secret access key", cfg.CredentialSource)
+		}
+		return credentials.NewStaticCredentialsProvider(static.AccessKeyID, static.SecretAccessKey, static.SessionToken), nil
+
+	case CredentialSourceProfile:
+		if cfg.Profile == "" {
+			return nil, fmt.Errorf("credential source %s needs a profile", cfg.CredentialSource)
+		}
+		// LoadDefaultConfig already resolved the profile ahead of the environment
+		if awsCfg.Credentials == nil {
+			return nil, fmt.Errorf("profile %s has no credentials", cfg.Profile)
+		}
+		return awsCfg.Credentials, nil
+
+	case CredentialSourceIMDS:
+		client := imds.NewFromConfig(awsCfg, func(o *imds.Options) {
+			o.EnableFallback = aws.FalseTernary
+		})
+		return ec2rolecreds.New(func(o *ec2rolecreds.Options) {
+			o.Client = client
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown AWS credential source %q", cfg.CredentialSource)
+}
+
+// checkCredentials retrieves credentials once, so a source that cannot
+// produce them fails at startup rather than at a migration's first AWS call
+func checkCredentials(ctx context.Context, provider aws.CredentialsProvider, source CredentialSource) error {
+	ctx, cancel := context.WithTimeout(ctx, credentialCheckTimeout)
+	defer cancel()
+	if _, err := provider.Retrieve(ctx); err != nil {
+		return fmt.Errorf("credential source %s cannot produce AWS credentials: %w", source, err)
+	}
+	return nil
+}
+
+// tokenFileProvider reads the Pod Identity authorization token from a file on
+// every request, since the kubelet rotates it
+type tokenFileProvider string
+
+// GetToken implements endpointcreds.AuthTokenProvider
+func (f tokenFileProvider) GetToken() (string, error) {
+	token, err := os.ReadFile(string(f))
+	if err != nil {
+		return "", fmt.Errorf("failed to read authorization token: %w", err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
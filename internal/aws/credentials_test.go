@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCredentialSource(t *testing.T) {
+	if got, err := ParseCredentialSource(""); err != nil || got != CredentialSourceDefault {
+		t.Errorf("ParseCredentialSource(\"\") = %q, %v, want the default chain", got, err)
+	}
+	if got, err := ParseCredentialSource("pod-identity"); err != nil || got != CredentialSourcePodIdentity {
+		t.Errorf("ParseCredentialSource(pod-identity) = %q, %v", got, err)
+	}
+	if _, err := ParseCredentialSource("instance-profile"); err == nil || !strings.Contains(err.Error(), "irsa") {
+		t.Errorf("ParseCredentialSource(instance-profile) error = %v, want the valid sources listed", err)
+	}
+}
+
+func TestNewEBSClientCredentialSource(t *testing.T) {
+	for _, env := range []string{"AWS_ROLE_ARN", "AWS_WEB_IDENTITY_TOKEN_FILE",
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI", "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"} {
+		t.Setenv(env, "")
+	}
+
+	tests := []struct {
+		name    string
+		cfg     EBSClientConfig
+		wantErr string
+	}{
+		{name: "irsa without a role", cfg: EBSClientConfig{CredentialSource: CredentialSourceIRSA}, wantErr: "AWS_ROLE_ARN"},
+		{name: "pod identity without the agent", cfg: EBSClientConfig{CredentialSource: CredentialSourcePodIdentity}, wantErr: "AWS_CONTAINER_CREDENTIALS_FULL_URI"},
+		{name: "static without a key", cfg: EBSClientConfig{CredentialSource: CredentialSourceStatic}, wantErr: "access key ID"},
+		{name: "profile without a name", cfg: EBSClientConfig{CredentialSource: CredentialSourceProfile}, wantErr: "needs a profile"},
+		{name: "static", cfg: EBSClientConfig{CredentialSource: CredentialSourceStatic,
+			StaticCredentials: &StaticCredentials{AccessKeyID: "AKIAEXAMPLE", SecretAccessKey: "secret"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Region = "us-east-1"
+			c, err := NewEBSClient(context.Background(), tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("NewEBSClient() error = %v, want it to mention %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewEBSClient() error = %v", err)
+			}
+			creds, err := c.awsCfg.Credentials.Retrieve(context.Background())
+			if err != nil || creds.AccessKeyID != "AKIAEXAMPLE" {
+				t.Errorf("credentials = %q, %v, want the static access key", creds.AccessKeyID, err)
+			}
+		})
+	}
+}
+
+func TestPodIdentityCredentials(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("pod-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "pod-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"AccessKeyId":     "ASIAPODIDENTITY",
+			"SecretAccessKey": "secret",
+			"Token":           "session",
+			"Expiration":      time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		})
+	}))
+	defer agent.Close()
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", agent.URL)
+	t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE", tokenFile)
+
+	c, err := NewEBSClient(context.Background(), EBSClientConfig{Region: "us-east-1", CredentialSource: CredentialSourcePodIdentity})
+	if err != nil {
+		t.Fatalf("NewEBSClient() error = %v", err)
+	}
+	creds, err := c.awsCfg.Credentials.Retrieve(context.Background())
+	if err != nil || creds.AccessKeyID != "ASIAPODIDENTITY" {
+		t.Errorf("credentials = %q, %v, want the agent's", creds.AccessKeyID, err)
+	}
+}
@@ -0,0 +1,221 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// DetachPhase is the coarse attachment phase of a volume being detached
+type DetachPhase string
+
+const (
+	// DetachPhaseAttached indicates the volume is still attached to an instance
+	DetachPhaseAttached DetachPhase = "attached"
+
+	// DetachPhaseDetaching indicates EC2 is detaching the volume
+	DetachPhaseDetaching DetachPhase = "detaching"
+
+	// DetachPhaseDetached indicates the volume is available
+	DetachPhaseDetached DetachPhase = "detached"
+)
+
+// DetachProgress records the attachment phase transitions observed while
+// waiting for a volume to detach. Transition timestamps are the first poll
+// that observed the phase, so they are accurate to within one poll interval.
+type DetachProgress struct {
+	// Phase is the most recently observed phase
+	Phase DetachPhase
+
+	// Started is when the wait started
+	Started time.Time
+
+	// AttachedAt is when the volume was first observed attached (zero if never)
+	AttachedAt time.Time
+
+	// DetachingAt is when the volume was first observed detaching (zero if never)
+	DetachingAt time.Time
+
+	// DetachedAt is when the volume was first observed available (zero if not yet)
+	DetachedAt time.Time
+
+	// LastPoll is when the volume was last polled
+	LastPoll time.Time
+
+	// Polls is how many times the volume was described, including the
+	// initial check and throttled calls
+	Polls int
+}
+
+// volumeDetached reports whether the volume is available and no longer attached
+// to any instance, which for Multi-Attach volumes means every attachment is gone
+func volumeDetached(info *VolumeInfo) bool {
+	return info.State == types.VolumeStateAvailable && len(remainingAttachments(info)) == 0
+}
+
+// remainingAttachments returns the attachments that have not finished detaching
+func remainingAttachments(info *VolumeInfo) []VolumeAttachment {
+	var remaining []VolumeAttachment
+	for _, att := range info.Attachments {
+		if att.State != types.VolumeAttachmentStateDetached {
+			remaining = append(remaining, att)
+		}
+	}
+	return remaining
+}
+
+// detachPhaseFor maps a volume's state and attachments to a DetachPhase.
+// With several attachments the least detached one determines the phase.
+func detachPhaseFor(info *VolumeInfo) DetachPhase {
+	if volumeDetached(info) {
+		return DetachPhaseDetached
+	}
+	for _, att := range info.Attachments {
+		if att.State == types.VolumeAttachmentStateAttached || att.State == types.VolumeAttachmentStateAttaching {
+			return DetachPhaseAttached
+		}
+	}
+	// In use with only detaching (or already removed) attachments
+	return DetachPhaseDetaching
+}
+
+// observe records a poll result taken at now
+func (p *DetachProgress) observe(info *VolumeInfo, now time.Time) {
+	if p.Started.IsZero() {
+		p.Started = now
+	}
+	p.LastPoll = now
+	p.Phase = detachPhaseFor(info)
+
+	switch p.Phase {
+	case DetachPhaseAttached:
+		if p.AttachedAt.IsZero() {
+			p.AttachedAt = now
+		}
+	case DetachPhaseDetaching:
+		if p.DetachingAt.IsZero() {
+			p.DetachingAt = now
+		}
+	case DetachPhaseDetached:
+		if p.DetachedAt.IsZero() {
+			p.DetachedAt = now
+		}
+	}
+}
+
+// PhaseStarted returns when the current phase was first observed
+func (p DetachProgress) PhaseStarted() time.Time {
+	switch p.Phase {
+	case DetachPhaseAttached:
+		return p.AttachedAt
+	case DetachPhaseDetaching:
+		return p.DetachingAt
+	case DetachPhaseDetached:
+		return p.DetachedAt
+	}
+	return p.Started
+}
+
+// InPhase returns how long the volume has been in its current phase as of the last poll
+func (p DetachProgress) InPhase() time.Duration {
+	return p.LastPoll.Sub(p.PhaseStarted())
+}
+
+// Elapsed returns how long the wait has been running as of the last poll
+func (p DetachProgress) Elapsed() time.Duration {
+	return p.LastPoll.Sub(p.Started)
+}
+
+// String returns a human-readable summary such as "detaching for 3m42s"
+func (p DetachProgress) String() string {
+	if p.Phase == "" {
+		return "not polled yet"
+	}
+	return fmt.Sprintf("%s for %s", p.Phase, p.InPhase().Round(time.Second))
+}
+
+// DetachWaitError is returned when WaitForVolumeDetach gives up; it carries
+// the progress observed so callers can report how far the detach got
+type DetachWaitError struct {
+	VolumeID  string
+	Reason    string
+	Progress  DetachProgress
+	Remaining []VolumeAttachment
+}
+
+// Error implements the error interface
+func (e *DetachWaitError) Error() string {
+	msg := fmt.Sprintf("volume %s did not detach: %s (waited %s, %s)",
+		e.VolumeID, e.Reason, e.Progress.Elapsed().Round(time.Second), e.Progress)
+	if len(e.Remaining) > 0 {
+		msg += "; still attached to " + FormatAttachments(e.Remaining)
+	}
+	return msg
+}
+
+// FormatAttachments returns a list such as "i-0abc (attached), i-0def (detaching)"
+func FormatAttachments(attachments []VolumeAttachment) string {
+	parts := make([]string, 0, len(attachments))
+	for _, att := range attachments {
+		parts = append(parts, fmt.Sprintf("%s (%s)", att.InstanceID, att.State))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DetachUpdate is one update from WatchVolumeDetach
+type DetachUpdate struct {
+	// Info is the volume as of the latest poll; nil in a final update when
+	// the volume was never polled in use
+	Info *VolumeInfo
+
+	// Progress is the attachment phase transitions seen so far
+	Progress DetachProgress
+
+	// Done is set on the final update, after which the channel is closed
+	Done bool
+
+	// Err is why the wait failed, on the final update (nil on success)
+	Err error
+}
+
+// WatchVolumeDetach runs WaitForVolumeDetach in the background and streams
+// its polls as updates, ending with one that has Done set and the wait's
+// error before the channel closes. The wait never blocks on the reader: a
+// reader that falls behind sees only the latest update, and the final update
+// is always delivered. cfg.OnPoll is still called. Calling the returned
+// cancel func stops the wait, which then ends with the context's error;
+// call it once the updates are no longer read.
+func (c *EBSClient) WatchVolumeDetach(ctx context.Context, volumeID string, cfg WaitForVolumeDetachConfig) (<-chan DetachUpdate, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	updates := make(chan DetachUpdate, 1)
+
+	// send replaces an update the reader has not taken yet; it is only
+	// called from the wait's goroutine, so the buffer always has room after
+	send := func(update DetachUpdate) {
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- update
+	}
+
+	var last *VolumeInfo
+	onPoll := cfg.OnPoll
+	cfg.OnPoll = func(info *VolumeInfo, progress DetachProgress) {
+		if onPoll != nil {
+			onPoll(info, progress)
+		}
+		last = info
+		send(DetachUpdate{Info: last, Progress: progress})
+	}
+
+	go func() {
+		defer close(updates)
+		progress, err := c.WaitForVolumeDetach(ctx, volumeID, cfg)
+		send(DetachUpdate{Info: last, Progress: progress, Done: true, Err: err})
+	}()
+	return updates, cancel
+}
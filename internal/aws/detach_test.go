@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestDetachPhaseFor(t *testing.T) {
+	tests := []struct {
+		name string
+		info *VolumeInfo
+		want DetachPhase
+	}{
+		{
+			name: "available",
+			info: &VolumeInfo{State: types.VolumeStateAvailable},
+			want: DetachPhaseDetached,
+		},
+		{
+			name: "attached",
+			info: &VolumeInfo{
+				State:       types.VolumeStateInUse,
+				Attachments: []VolumeAttachment{{InstanceID: "i-1", State: types.VolumeAttachmentStateAttached}},
+			},
+			want: DetachPhaseAttached,
+		},
+		{
+			name: "detaching",
+			info: &VolumeInfo{
+				State:       types.VolumeStateInUse,
+				Attachments: []VolumeAttachment{{InstanceID: "i-1", State: types.VolumeAttachmentStateDetaching}},
+			},
+			want: DetachPhaseDetaching,
+		},
+		{
+			name: "in use without attachments",
+			info: &VolumeInfo{State: types.VolumeStateInUse},
+			want: DetachPhaseDetaching,
+		},
+		{
+			name: "multi-attach with one instance still attached",
+			info: &VolumeInfo{
+				State: types.VolumeStateInUse,
+				Attachments: []VolumeAttachment{
+					{InstanceID: "i-1", State: types.VolumeAttachmentStateDetaching},
+					{InstanceID: "i-2", State: types.VolumeAttachmentStateAttached},
+				},
+			},
+			want: DetachPhaseAttached,
+		},
+		{
+			name: "available with a lingering attachment",
+			info: &VolumeInfo{
+				State:       types.VolumeStateAvailable,
+				Attachments: []VolumeAttachment{{InstanceID: "i-2", State: types.VolumeAttachmentStateDetaching}},
+			},
+			want: DetachPhaseDetaching,
+		},
+		{
+			name: "available with only detached attachments",
+			info: &VolumeInfo{
+				State:       types.VolumeStateAvailable,
+				Attachments: []VolumeAttachment{{InstanceID: "i-1", State: types.VolumeAttachmentStateDetached}},
+			},
+			want: DetachPhaseDetached,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detachPhaseFor(tt.info); got != tt.want {
+				t.Errorf("detachPhaseFor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetachProgress(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	attached := &VolumeInfo{
+		State:       types.VolumeStateInUse,
+		Attachments: []VolumeAttachment{{State: types.VolumeAttachmentStateAttached}},
+	}
+	detaching := &VolumeInfo{
+		State:       types.VolumeStateInUse,
+		Attachments: []VolumeAttachment{{State: types.VolumeAttachmentStateDetaching}},
+	}
+
+	var p DetachProgress
+	if got := p.String(); got != "not polled yet" {
+		t.Errorf("String() = %q before polling", got)
+	}
+
+	p.observe(attached, start)
+	p.observe(attached, start.Add(10*time.Second))
+	p.observe(detaching, start.Add(20*time.Second))
+	p.observe(detaching, start.Add(20*time.Second+3*time.Minute+42*time.Second))
+
+	if !p.Started.Equal(start) || !p.AttachedAt.Equal(start) {
+		t.Errorf("Started = %v, AttachedAt = %v, want %v", p.Started, p.AttachedAt, start)
+	}
+	if !p.DetachingAt.Equal(start.Add(20 * time.Second)) {
+		t.Errorf("DetachingAt = %v, want first detaching poll", p.DetachingAt)
+	}
+	if !p.DetachedAt.IsZero() {
+		t.Errorf("DetachedAt = %v, want zero", p.DetachedAt)
+	}
+	if got := p.String(); got != "detaching for 3m42s" {
+		t.Errorf("String() = %q, want %q", got, "detaching for 3m42s")
+	}
+	if got := p.Elapsed(); got != 4*time.Minute+2*time.Second {
+		t.Errorf("Elapsed() = %v", got)
+	}
+
+	err := &DetachWaitError{VolumeID: "vol-1", Reason: "timed out after 5m0s", Progress: p}
+	want := "volume vol-1 did not detach: timed out after 5m0s (waited 4m2s, detaching for 3m42s)"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	err.Remaining = []VolumeAttachment{
+		{InstanceID: "i-1", State: types.VolumeAttachmentStateDetaching},
+		{InstanceID: "i-2", State: types.VolumeAttachmentStateAttached},
+	}
+	want += "; still attached to i-1 (detaching), i-2 (attached)"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,158 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailtypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+const (
+	// EventAttachVolume and EventDetachVolume are the CloudTrail event names
+	// of the EC2 calls that move a volume between instances
+	EventAttachVolume = "AttachVolume"
+	EventDetachVolume = "DetachVolume"
+)
+
+// AttachmentEvent is a successful AttachVolume or DetachVolume call recorded
+// by CloudTrail
+type AttachmentEvent struct {
+	// Name is EventAttachVolume or EventDetachVolume
+	Name string
+
+	// Time is when the call was made
+	Time time.Time
+
+	// InstanceID is the instance the volume was attached to or detached from
+	InstanceID string
+
+	// Force is set on a force-detach
+	Force bool
+}
+
+// parseAttachmentEvent returns the AttachmentEvent of a LookupEvents event,
+// whose CloudTrailEvent is the event record as a JSON string. Other events,
+// and calls EC2 rejected, are not attachment changes.
+func parseAttachmentEvent(e cloudtrailtypes.Event) (AttachmentEvent, bool) {
+	name := aws.ToString(e.EventName)
+	if name != EventAttachVolume && name != EventDetachVolume {
+		return AttachmentEvent{}, false
+	}
+	var record struct {
+		ErrorCode         string `json:"errorCode"`
+		RequestParameters struct {
+			InstanceID string `json:"instanceId"`
+			Force      bool   `json:"force"`
+		} `json:"requestParameters"`
+	}
+	if err := json.Unmarshal([]byte(aws.ToString(e.CloudTrailEvent)), &record); err != nil || record.ErrorCode != "" {
+		return AttachmentEvent{}, false
+	}
+	return AttachmentEvent{
+		Name:       name,
+		Time:       aws.ToTime(e.EventTime).UTC(),
+		InstanceID: record.RequestParameters.InstanceID,
+		Force:      record.RequestParameters.Force,
+	}, true
+}
+
+// VolumeAttachmentEvents returns the volume's AttachVolume and DetachVolume
+// calls since the given time, oldest first, from CloudTrail's event history.
+// CloudTrail keeps 90 days of management events in the region, and needs
+// cloudtrail:LookupEvents.
+func (c *EBSClient) VolumeAttachmentEvents(ctx context.Context, volumeID string, since time.Time) ([]AttachmentEvent, error) {
+	var events []AttachmentEvent
+	pages := cloudtrail.NewLookupEventsPaginator(c.cloudTrailClient, &cloudtrail.LookupEventsInput{
+		LookupAttributes: []cloudtrailtypes.LookupAttribute{{
+			AttributeKey:   cloudtrailtypes.LookupAttributeKeyResourceName,
+			AttributeValue: aws.String(volumeID),
+		}},
+		StartTime:  aws.Time(since),
+		EndTime:    aws.Time(c.clock.Now()),
+		MaxResults: aws.Int32(50),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up CloudTrail events of volume %s: %w", volumeID, c.classifyError("LookupEvents", volumeID, err))
+		}
+		for _, e := range page.Events {
+			if event, ok := parseAttachmentEvent(e); ok {
+				events = append(events, event)
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events, nil
+}
+
+// DetachCycle is a move of a volume between instances in its CloudTrail
+// history: the first DetachVolume call after an attachment, and the next
+// AttachVolume call
+type DetachCycle struct {
+	// DetachedAt is when the volume was asked to detach
+	DetachedAt time.Time
+
+	// AttachedAt is when it was next asked to attach
+	AttachedAt time.Time
+
+	// From and To are the instances it moved between
+	From, To string
+
+	// Forced is set when the volume had to be force-detached
+	Forced bool
+}
+
+// Duration is the time from the detach call to the next attach call. The
+// attach-detach controller only attaches a single-attach volume elsewhere
+// once EC2 has detached it, so this bounds the detach from above.
+func (d DetachCycle) Duration() time.Duration {
+	return d.AttachedAt.Sub(d.DetachedAt)
+}
+
+// DetachCycles pairs the detach calls in a volume's attachment history,
+// oldest first, with the attach calls that followed them. A repeated detach
+// call, such as a force-detach after a plain one, belongs to the same cycle;
+// a detach with no attach after it is still in progress or was never
+// followed by one, and is left out.
+func DetachCycles(events []AttachmentEvent) []DetachCycle {
+	var cycles []DetachCycle
+	var pending *DetachCycle
+	for _, e := range events {
+		switch e.Name {
+		case EventDetachVolume:
+			if pending == nil {
+				pending = &DetachCycle{DetachedAt: e.Time, From: e.InstanceID}
+			}
+			pending.Forced = pending.Forced || e.Force
+		case EventAttachVolume:
+			if pending != nil {
+				pending.AttachedAt = e.Time
+				pending.To = e.InstanceID
+				cycles = append(cycles, *pending)
+				pending = nil
+			}
+		}
+	}
+	return cycles
+}
+
+// GetVolumeStatus returns the volume's status check result from
+// DescribeVolumeStatus: ok, warning, impaired or insufficient-data. An
+// impaired volume's I/O is disabled or degraded, which can slow its detach.
+func (c *EBSClient) GetVolumeStatus(ctx context.Context, volumeID string) (string, error) {
+	resp, err := c.ec2Client.DescribeVolumeStatus(ctx, &ec2.DescribeVolumeStatusInput{VolumeIds: []string{volumeID}})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe status of volume %s: %w", volumeID, c.classifyError("DescribeVolumeStatus", volumeID, err))
+	}
+	if len(resp.VolumeStatuses) == 0 || resp.VolumeStatuses[0].VolumeStatus == nil {
+		return "", nil
+	}
+	return string(resp.VolumeStatuses[0].VolumeStatus.Status), nil
+}
@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cloudtrailtypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+func TestParseAttachmentEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event cloudtrailtypes.Event
+		want  AttachmentEvent
+		ok    bool
+	}{
+		{
+			name: "detach",
+			event: cloudtrailtypes.Event{EventName: aws.String(EventDetachVolume), EventTime: aws.Time(time.Unix(1700000000, 5e8)),
+				CloudTrailEvent: aws.String(`{"requestParameters":{"volumeId":"vol-1","instanceId":"i-1","force":true}}`)},
+			want: AttachmentEvent{Name: EventDetachVolume, Time: time.Unix(1700000000, 5e8).UTC(), InstanceID: "i-1", Force: true},
+			ok:   true,
+		},
+		{
+			name: "attach",
+			event: cloudtrailtypes.Event{EventName: aws.String(EventAttachVolume), EventTime: aws.Time(time.Unix(1700000060, 0)),
+				CloudTrailEvent: aws.String(`{"requestParameters":{"volumeId":"vol-1","instanceId":"i-2","device":"/dev/xvdba"}}`)},
+			want: AttachmentEvent{Name: EventAttachVolume, Time: time.Unix(1700000060, 0).UTC(), InstanceID: "i-2"},
+			ok:   true,
+		},
+		{
+			name: "rejected call",
+			event: cloudtrailtypes.Event{EventName: aws.String(EventAttachVolume), EventTime: aws.Time(time.Unix(1700000060, 0)),
+				CloudTrailEvent: aws.String(`{"errorCode":"VolumeInUse","requestParameters":{"instanceId":"i-2"}}`)},
+		},
+		{
+			name:  "other event",
+			event: cloudtrailtypes.Event{EventName: aws.String("CreateTags"), EventTime: aws.Time(time.Unix(1700000060, 0)), CloudTrailEvent: aws.String(`{}`)},
+		},
+		{
+			name:  "malformed record",
+			event: cloudtrailtypes.Event{EventName: aws.String(EventDetachVolume), EventTime: aws.Time(time.Unix(1700000060, 0)), CloudTrailEvent: aws.String(`{`)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseAttachmentEvent(tt.event)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseAttachmentEvent() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDetachCycles(t *testing.T) {
+	at := func(m int) time.Time { return time.Date(2026, 1, 1, 0, m, 0, 0, time.UTC) }
+	events := []AttachmentEvent{
+		{Name: EventAttachVolume, Time: at(0), InstanceID: "i-1"},
+		{Name: EventDetachVolume, Time: at(10), InstanceID: "i-1"},
+		{Name: EventAttachVolume, Time: at(12), InstanceID: "i-2"},
+		{Name: EventDetachVolume, Time: at(20), InstanceID: "i-2"},
+		{Name: EventDetachVolume, Time: at(26), InstanceID: "i-2", Force: true},
+		{Name: EventAttachVolume, Time: at(27), InstanceID: "i-3"},
+		{Name: EventDetachVolume, Time: at(40), InstanceID: "i-3"},
+	}
+	cycles := DetachCycles(events)
+	if len(cycles) != 2 {
+		t.Fatalf("DetachCycles() returned %d cycles, want 2: %+v", len(cycles), cycles)
+	}
+	if c := cycles[0]; c.From != "i-1" || c.To != "i-2" || c.Forced || c.Duration() != 2*time.Minute {
+		t.Errorf("first cycle = %+v, want i-1 -> i-2 in 2m", c)
+	}
+	if c := cycles[1]; c.From != "i-2" || c.To != "i-3" || !c.Forced || c.Duration() != 7*time.Minute {
+		t.Errorf("second cycle = %+v, want forced i-2 -> i-3 in 7m", c)
+	}
+}
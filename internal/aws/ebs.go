@@ -8,14 +8,62 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"k8s.io/utils/clock"
 )
 
+// EC2API is the subset of the EC2 API used by EBSClient
+type EC2API interface {
+	DescribeVolumes(ctx context.Context, in *ec2.DescribeVolumesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error)
+	DescribeSnapshots(ctx context.Context, in *ec2.DescribeSnapshotsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error)
+	DescribeVolumeStatus(ctx context.Context, in *ec2.DescribeVolumeStatusInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumeStatusOutput, error)
+	DescribeVolumesModifications(ctx context.Context, in *ec2.DescribeVolumesModificationsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesModificationsOutput, error)
+	DescribeFastSnapshotRestores(ctx context.Context, in *ec2.DescribeFastSnapshotRestoresInput, optFns ...func(*ec2.Options)) (*ec2.DescribeFastSnapshotRestoresOutput, error)
+	EnableFastSnapshotRestores(ctx context.Context, in *ec2.EnableFastSnapshotRestoresInput, optFns ...func(*ec2.Options)) (*ec2.EnableFastSnapshotRestoresOutput, error)
+	DisableFastSnapshotRestores(ctx context.Context, in *ec2.DisableFastSnapshotRestoresInput, optFns ...func(*ec2.Options)) (*ec2.DisableFastSnapshotRestoresOutput, error)
+	DescribeInstances(ctx context.Context, in *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
+	DescribeInstanceStatus(ctx context.Context, in *ec2.DescribeInstanceStatusInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceStatusOutput, error)
+	DescribeAvailabilityZones(ctx context.Context, in *ec2.DescribeAvailabilityZonesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error)
+	CreateSnapshot(ctx context.Context, in *ec2.CreateSnapshotInput, optFns ...func(*ec2.Options)) (*ec2.CreateSnapshotOutput, error)
+	CopySnapshot(ctx context.Context, in *ec2.CopySnapshotInput, optFns ...func(*ec2.Options)) (*ec2.CopySnapshotOutput, error)
+	CreateVolume(ctx context.Context, in *ec2.CreateVolumeInput, optFns ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error)
+	ModifySnapshotAttribute(ctx context.Context, in *ec2.ModifySnapshotAttributeInput, optFns ...func(*ec2.Options)) (*ec2.ModifySnapshotAttributeOutput, error)
+	DeleteSnapshot(ctx context.Context, in *ec2.DeleteSnapshotInput, optFns ...func(*ec2.Options)) (*ec2.DeleteSnapshotOutput, error)
+	DetachVolume(ctx context.Context, in *ec2.DetachVolumeInput, optFns ...func(*ec2.Options)) (*ec2.DetachVolumeOutput, error)
+	CreateTags(ctx context.Context, in *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error)
+	DeleteTags(ctx context.Context, in *ec2.DeleteTagsInput, optFns ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error)
+}
+
 // EBSClient provides operations for AWS EBS volumes
 type EBSClient struct {
-	ec2Client *ec2.Client
-	region    string
+	ec2Client        EC2API
+	kmsClient        *kms.Client
+	quotasClient     *servicequotas.Client
+	cloudTrailClient *cloudtrail.Client
+	eventsClient     eventsAPI
+	cloudWatchClient cloudWatchAPI
+	awsCfg           aws.Config
+	endpoint         string
+	region           string
+
+	// clock drives waits and timeouts; tests substitute a fake clock
+	clock clock.WithTicker
+
+	// limiter caps concurrent operations; nil is unlimited
+	limiter *concurrencyLimiter
+
+	// regions holds the clients derived for other regions by ForRegion
+	regions *regionPool
+
+	// roles holds the clients derived by AssumeRole, shared with the
+	// clients of other regions; nil caches nothing
+	roles *rolePool
 }
 
 // EBSClientConfig contains configuration for creating an EBS client
@@ -26,8 +74,27 @@ type EBSClientConfig struct {
 	// Profile is the AWS profile to use (optional)
 	Profile string
 
+	// CredentialSource restricts credentials to one source instead of the
+	// SDK's default chain (optional). NewEBSClient fails when the chosen
+	// source cannot produce credentials.
+	CredentialSource CredentialSource
+
+	// StaticCredentials is the access key for CredentialSourceStatic
+	StaticCredentials *StaticCredentials
+
 	// Endpoint is a custom endpoint URL (optional, for testing)
 	Endpoint string
+
+	// UseFIPSEndpoint sends requests to the FIPS 140 validated endpoints of
+	// the region's partition (optional). The region must offer them; it
+	// cannot be combined with Endpoint.
+	UseFIPSEndpoint bool
+
+	// Clock drives poll intervals and wait timeouts (optional, for testing)
+	Clock clock.WithTicker
+
+	// Limits caps concurrent EBS operations across all users of the client (optional)
+	Limits ConcurrencyLimits
 }
 
 // VolumeInfo contains information about an EBS volume
@@ -41,13 +108,31 @@ type VolumeInfo struct {
 	// AvailabilityZone is the AZ where the volume resides
 	AvailabilityZone string
 
+	// OutpostARN is the Outpost a volume on AWS Outposts resides on
+	OutpostARN string
+
 	// Size is the volume size in GiB
 	Size int32
 
 	// VolumeType is the EBS volume type (gp2, gp3, io1, etc.)
 	VolumeType types.VolumeType
 
-	// Attachments contains information about current attachments
+	// IOPS is the provisioned IOPS of io1, io2 and gp3 volumes, and the baseline of gp2
+	IOPS int32
+
+	// Throughput is the provisioned throughput of gp3 volumes in MiB/s
+	Throughput int32
+
+	// Encrypted is true when the volume is encrypted
+	Encrypted bool
+
+	// KMSKeyID is the ARN of the KMS key an encrypted volume uses
+	KMSKeyID string
+
+	// MultiAttachEnabled is true for io1/io2 volumes that can attach to several instances at once
+	MultiAttachEnabled bool
+
+	// Attachments contains every current attachment; Multi-Attach volumes may have several
 	Attachments []VolumeAttachment
 
 	// Tags contains the volume's tags
@@ -78,30 +163,127 @@ func NewEBSClient(ctx context.Context, cfg EBSClientConfig) (*EBSClient, error)
 		opts = append(opts, config.WithSharedConfigProfile(cfg.Profile))
 	}
 
+	if cfg.UseFIPSEndpoint {
+		if cfg.Endpoint != "" {
+			return nil, fmt.Errorf("a custom endpoint cannot be combined with FIPS endpoints")
+		}
+		opts = append(opts, config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled))
+	}
+
 	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
+	provider, err := credentialsProvider(awsCfg, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if provider != nil {
+		if _, cached := provider.(*aws.CredentialsCache); !cached {
+			provider = aws.NewCredentialsCache(provider)
+		}
+		awsCfg.Credentials = provider
+		if err := checkCredentials(ctx, provider, cfg.CredentialSource); err != nil {
+			return nil, err
+		}
+	}
+
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.RealClock{}
+	}
+
+	return newEBSClient(awsCfg, cfg.Endpoint, clk, newConcurrencyLimiter(cfg.Limits)), nil
+}
+
+// newEBSClient creates an EBS client whose EC2, KMS, Service Quotas,
+// CloudTrail, EventBridge and CloudWatch clients use the given config,
+// sending requests to endpoint when it is set
+func newEBSClient(awsCfg aws.Config, endpoint string, clk clock.WithTicker, limiter *concurrencyLimiter) *EBSClient {
 	var ec2Opts []func(*ec2.Options)
-	if cfg.Endpoint != "" {
+	var kmsOpts []func(*kms.Options)
+	var quotasOpts []func(*servicequotas.Options)
+	var cloudTrailOpts []func(*cloudtrail.Options)
+	var eventsOpts []func(*eventbridge.Options)
+	var cloudWatchOpts []func(*cloudwatch.Options)
+	if endpoint != "" {
 		ec2Opts = append(ec2Opts, func(o *ec2.Options) {
-			o.BaseEndpoint = aws.String(cfg.Endpoint)
+			o.BaseEndpoint = aws.String(endpoint)
+		})
+		kmsOpts = append(kmsOpts, func(o *kms.Options) {
+			o.BaseEndpoint = aws.String(endpoint)
+		})
+		quotasOpts = append(quotasOpts, func(o *servicequotas.Options) {
+			o.BaseEndpoint = aws.String(endpoint)
+		})
+		cloudTrailOpts = append(cloudTrailOpts, func(o *cloudtrail.Options) {
+			o.BaseEndpoint = aws.String(endpoint)
+		})
+		eventsOpts = append(eventsOpts, func(o *eventbridge.Options) {
+			o.BaseEndpoint = aws.String(endpoint)
+		})
+		cloudWatchOpts = append(cloudWatchOpts, func(o *cloudwatch.Options) {
+			o.BaseEndpoint = aws.String(endpoint)
 		})
 	}
 
-	return &EBSClient{
-		ec2Client: ec2.NewFromConfig(awsCfg, ec2Opts...),
-		region:    cfg.Region,
-	}, nil
+	c := &EBSClient{
+		ec2Client:        limitEC2(ec2.NewFromConfig(awsCfg, ec2Opts...), limiter),
+		kmsClient:        kms.NewFromConfig(awsCfg, kmsOpts...),
+		quotasClient:     servicequotas.NewFromConfig(awsCfg, quotasOpts...),
+		cloudTrailClient: cloudtrail.NewFromConfig(awsCfg, cloudTrailOpts...),
+		eventsClient:     eventbridge.NewFromConfig(awsCfg, eventsOpts...),
+		cloudWatchClient: cloudwatch.NewFromConfig(awsCfg, cloudWatchOpts...),
+		awsCfg:           awsCfg,
+		endpoint:         endpoint,
+		region:           awsCfg.Region,
+		clock:            clk,
+		limiter:          limiter,
+		roles:            newRolePool(),
+	}
+	c.regions = newRegionPool(c, func(region string) *EBSClient {
+		regionCfg := awsCfg.Copy()
+		regionCfg.Region = region
+		return newEBSClient(regionCfg, endpoint, clk, limiter)
+	})
+	return c
 }
 
 // NewEBSClientFromConfig creates a new EBS client from an existing AWS config
 func NewEBSClientFromConfig(awsCfg aws.Config) *EBSClient {
-	return &EBSClient{
-		ec2Client: ec2.NewFromConfig(awsCfg),
-		region:    awsCfg.Region,
+	return newEBSClient(awsCfg, "", clock.RealClock{}, nil)
+}
+
+// NewEBSClientFromAPI creates an EBS client around an EC2 API implementation
+// and clock, so waits can be exercised against fakes without real timeouts.
+// KMS and Service Quotas calls are not available on such a client.
+func NewEBSClientFromAPI(ec2API EC2API, clk clock.WithTicker, region string) *EBSClient {
+	c := &EBSClient{
+		ec2Client: ec2API,
+		region:    region,
+		clock:     clk,
+	}
+	c.regions = newRegionPool(c, func(region string) *EBSClient {
+		return NewEBSClientFromAPI(ec2API, clk, region)
+	})
+	return c
+}
+
+// accessCheckTag is a tag no volume carries, so CheckAccess lists nothing
+const accessCheckTag = "aqua.io/access-check"
+
+// CheckAccess verifies that the client's credentials work and may describe
+// volumes, with a DescribeVolumes call whose filter matches no volume
+func (c *EBSClient) CheckAccess(ctx context.Context) error {
+	_, err := c.ec2Client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{
+		Filters:    []types.Filter{{Name: aws.String("tag-key"), Values: []string{accessCheckTag}}},
+		MaxResults: aws.Int32(5),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe volumes: %w", c.classifyError("DescribeVolumes", "", err))
 	}
+	return nil
 }
 
 // GetVolumeInfo retrieves information about an EBS volume
@@ -110,11 +292,12 @@ func (c *EBSClient) GetVolumeInfo(ctx context.Context, volumeID string) (*Volume
 		VolumeIds: []string{volumeID},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to describe volume %s: %w", volumeID, err)
+		return nil, fmt.Errorf("failed to describe volume %s: %w", volumeID, c.classifyError("DescribeVolumes", volumeID, err))
 	}
 
 	if len(resp.Volumes) == 0 {
-		return nil, fmt.Errorf("volume %s not found", volumeID)
+		return nil, &APIError{Op: "DescribeVolumes", Resource: volumeID, Region: c.region, Kind: ErrNotFound,
+			Err: fmt.Errorf("volume %s not found", volumeID)}
 	}
 
 	vol := resp.Volumes[0]
@@ -122,9 +305,16 @@ func (c *EBSClient) GetVolumeInfo(ctx context.Context, volumeID string) (*Volume
 		VolumeID:         aws.ToString(vol.VolumeId),
 		State:            vol.State,
 		AvailabilityZone: aws.ToString(vol.AvailabilityZone),
+		OutpostARN:       aws.ToString(vol.OutpostArn),
 		Size:             aws.ToInt32(vol.Size),
 		VolumeType:       vol.VolumeType,
+		IOPS:             aws.ToInt32(vol.Iops),
+		Throughput:       aws.ToInt32(vol.Throughput),
 		Tags:             make(map[string]string),
+
+		Encrypted:          aws.ToBool(vol.Encrypted),
+		KMSKeyID:           aws.ToString(vol.KmsKeyId),
+		MultiAttachEnabled: aws.ToBool(vol.MultiAttachEnabled),
 	}
 
 	// Convert attachments
@@ -155,82 +345,169 @@ func (c *EBSClient) IsVolumeAvailable(ctx context.Context, volumeID string) (boo
 
 // WaitForVolumeDetachConfig contains configuration for WaitForVolumeDetach
 type WaitForVolumeDetachConfig struct {
-	// PollInterval is how often to check the volume state (default: 5s)
+	// PollInterval is the delay before the first poll after the initial
+	// check (default: 2s). Each later delay is BackoffFactor times the one
+	// before, up to MaxPollInterval, so a fast detach is seen quickly and a
+	// long one costs few DescribeVolumes calls.
 	PollInterval time.Duration
 
+	// MaxPollInterval caps the delay between polls (default: 30s). Setting
+	// it to PollInterval polls at a fixed interval.
+	MaxPollInterval time.Duration
+
+	// BackoffFactor is how much the delay grows after each poll (default: 1.5;
+	// values below 1 are treated as 1)
+	BackoffFactor float64
+
 	// Timeout is the maximum time to wait (default: 5m)
 	Timeout time.Duration
 
-	// OnPoll is called each time the volume is polled (optional)
-	OnPoll func(info *VolumeInfo)
+	// OnPoll is called each time the volume is polled with the volume's
+	// state and the attachment phase transitions seen so far (optional)
+	OnPoll func(info *VolumeInfo, progress DetachProgress)
+
+	// InstanceCheckInterval is how often the state of the instance the volume
+	// is attached to is checked (default: 30s)
+	InstanceCheckInterval time.Duration
+
+	// ForceDetach force-detaches the volume when its instance is stopped,
+	// shutting down or terminated; otherwise the wait fails with an
+	// *InstanceUnavailableError. A stopping or impaired instance is never
+	// force-detached: the wait fails with a *DetachWaitError instead.
+	ForceDetach bool
+
+	// OnForceDetach is called before the volume is force-detached (optional)
+	OnForceDetach func(instance InstanceHealth)
 }
 
 // DefaultWaitConfig returns the default wait configuration
 func DefaultWaitConfig() WaitForVolumeDetachConfig {
 	return WaitForVolumeDetachConfig{
-		PollInterval: 5 * time.Second,
-		Timeout:      5 * time.Minute,
+		PollInterval:          2 * time.Second,
+		MaxPollInterval:       30 * time.Second,
+		BackoffFactor:         1.5,
+		Timeout:               5 * time.Minute,
+		InstanceCheckInterval: 30 * time.Second,
 	}
 }
 
 // WaitForVolumeDetach blocks until the EBS volume is detached and available
 // This is critical for migration - we must wait for the volume to be detached
 // from the source cluster before it can be attached to the destination cluster.
-func (c *EBSClient) WaitForVolumeDetach(ctx context.Context, volumeID string, cfg WaitForVolumeDetachConfig) error {
+// Multi-Attach volumes must be detached from every instance. When the wait
+// gives up, the returned error is a *DetachWaitError listing the remaining attachments.
+// The returned progress counts the polls made and how long the wait ran.
+func (c *EBSClient) WaitForVolumeDetach(ctx context.Context, volumeID string, cfg WaitForVolumeDetachConfig) (DetachProgress, error) {
 	if cfg.PollInterval == 0 {
-		cfg.PollInterval = 5 * time.Second
+		cfg.PollInterval = 2 * time.Second
+	}
+	if cfg.MaxPollInterval == 0 {
+		cfg.MaxPollInterval = 30 * time.Second
+	}
+	cfg.MaxPollInterval = max(cfg.MaxPollInterval, cfg.PollInterval)
+	if cfg.BackoffFactor == 0 {
+		cfg.BackoffFactor = 1.5
 	}
+	cfg.BackoffFactor = max(cfg.BackoffFactor, 1)
 	if cfg.Timeout == 0 {
 		cfg.Timeout = 5 * time.Minute
 	}
+	if cfg.InstanceCheckInterval == 0 {
+		cfg.InstanceCheckInterval = 30 * time.Second
+	}
 
-	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
-	defer cancel()
+	var progress DetachProgress
+	release, err := c.acquireDetachSlot(ctx)
+	if err != nil {
+		return progress, err
+	}
+	defer release()
+
+	timeout := c.clock.NewTimer(cfg.Timeout)
+	defer timeout.Stop()
 
-	ticker := time.NewTicker(cfg.PollInterval)
-	defer ticker.Stop()
+	delay := cfg.PollInterval
+	poll := c.clock.NewTimer(delay)
+	defer poll.Stop()
+
+	var lastInstanceCheck time.Time
+	forced := make(map[string]bool)
+	// unsettled says why the volume is not force-detached while an instance
+	// is stopping or impaired, for the timeout error
+	var unsettled string
 
 	// Check immediately first
 	info, err := c.GetVolumeInfo(ctx, volumeID)
+	progress.Polls++
 	if err != nil {
-		return fmt.Errorf("failed to get initial volume info: %w", err)
+		return progress, fmt.Errorf("failed to get initial volume info: %w", err)
 	}
-	if info.State == types.VolumeStateAvailable {
-		return nil // Already available
+	progress.observe(info, c.clock.Now())
+	if volumeDetached(info) {
+		return progress, nil // Already available
 	}
 	if cfg.OnPoll != nil {
-		cfg.OnPoll(info)
+		cfg.OnPoll(info, progress)
+	}
+	if unsettled, err = c.checkAttachedInstances(ctx, volumeID, info, cfg, forced); err != nil {
+		return progress, err
 	}
+	lastInstanceCheck = c.clock.Now()
 
 	for {
 		select {
 		case <-ctx.Done():
-			if ctx.Err() == context.DeadlineExceeded {
-				return fmt.Errorf("timeout waiting for volume %s to detach (waited %v)", volumeID, cfg.Timeout)
+			return progress, ctx.Err()
+
+		case <-timeout.C():
+			progress.LastPoll = c.clock.Now()
+			reason := fmt.Sprintf("timed out after %v", cfg.Timeout)
+			if unsettled != "" {
+				reason += "; " + unsettled
 			}
-			return ctx.Err()
+			return progress, &DetachWaitError{VolumeID: volumeID, Reason: reason,
+				Progress: progress, Remaining: remainingAttachments(info)}
+
+		case <-poll.C():
+			delay = nextPollDelay(delay, cfg)
+			poll.Reset(delay)
 
-		case <-ticker.C:
-			info, err := c.GetVolumeInfo(ctx, volumeID)
+			polled, err := c.GetVolumeInfo(ctx, volumeID)
+			progress.Polls++
+			if Retryable(err) {
+				continue // Throttled; poll again after the longer delay
+			}
 			if err != nil {
-				return fmt.Errorf("failed to get volume info: %w", err)
+				return progress, fmt.Errorf("failed to get volume info: %w", err)
 			}
+			info = polled
 
+			progress.observe(info, c.clock.Now())
 			if cfg.OnPoll != nil {
-				cfg.OnPoll(info)
+				cfg.OnPoll(info, progress)
 			}
 
-			if info.State == types.VolumeStateAvailable {
-				return nil // Success - volume is now available
+			if volumeDetached(info) {
+				return progress, nil // Success - volume is now available
 			}
 
 			// Check for error states
 			if info.State == types.VolumeStateError {
-				return fmt.Errorf("volume %s is in error state", volumeID)
+				return progress, &DetachWaitError{VolumeID: volumeID, Reason: "volume is in error state",
+					Progress: progress, Remaining: remainingAttachments(info)}
 			}
 			if info.State == types.VolumeStateDeleted || info.State == types.VolumeStateDeleting {
-				return fmt.Errorf("volume %s is being deleted or already deleted", volumeID)
+				return progress, &DetachWaitError{VolumeID: volumeID, Reason: "volume is being deleted or already deleted",
+					Progress: progress, Remaining: remainingAttachments(info)}
+			}
+
+			// A stopped or terminated instance never releases the volume; a
+			// stopping or impaired one may yet, so the wait goes on
+			if c.clock.Since(lastInstanceCheck) >= cfg.InstanceCheckInterval {
+				if unsettled, err = c.checkAttachedInstances(ctx, volumeID, info, cfg, forced); err != nil {
+					return progress, err
+				}
+				lastInstanceCheck = c.clock.Now()
 			}
 
 			// Still attached or in-use, continue waiting
@@ -238,6 +515,53 @@ func (c *EBSClient) WaitForVolumeDetach(ctx context.Context, volumeID string, cf
 	}
 }
 
+// nextPollDelay returns the delay before the poll after one that followed
+// the given delay, grown by cfg.BackoffFactor and capped at cfg.MaxPollInterval
+func nextPollDelay(delay time.Duration, cfg WaitForVolumeDetachConfig) time.Duration {
+	return min(time.Duration(float64(delay)*cfg.BackoffFactor), cfg.MaxPollInterval)
+}
+
+// checkAttachedInstances fails the wait, or force-detaches the volume when
+// cfg.ForceDetach is set, if an instance the volume is attached to is
+// unavailable. An instance that is stopping or impaired may still write to
+// the volume, so it is neither force-detached nor given up on: it may finish
+// stopping, or recover, on a later check. The wait keeps polling, and the
+// returned reason names the instance for the timeout error.
+// Checks are best-effort: when the instance cannot be described (for example
+// without ec2:DescribeInstances) the wait keeps polling until its timeout.
+func (c *EBSClient) checkAttachedInstances(ctx context.Context, volumeID string, info *VolumeInfo, cfg WaitForVolumeDetachConfig, forced map[string]bool) (string, error) {
+	var unsettled string
+	for _, att := range remainingAttachments(info) {
+		if att.InstanceID == "" || forced[att.InstanceID] {
+			continue
+		}
+
+		health, err := c.GetInstanceHealth(ctx, att.InstanceID)
+		if err != nil {
+			continue
+		}
+		if health.Unsettled() {
+			unsettled = fmt.Sprintf("instance %s is %s and may still write to the volume, so it is not force-detached", att.InstanceID, health)
+			continue
+		}
+		if !health.Unavailable() {
+			continue
+		}
+
+		if !cfg.ForceDetach {
+			return "", &InstanceUnavailableError{VolumeID: volumeID, Instance: *health}
+		}
+		if cfg.OnForceDetach != nil {
+			cfg.OnForceDetach(*health)
+		}
+		if err := c.ForceDetachVolume(ctx, volumeID, att.InstanceID); err != nil {
+			return "", err
+		}
+		forced[att.InstanceID] = true
+	}
+	return unsettled, nil
+}
+
 // DescribeVolumeAttachments returns the current attachment state of a volume
 func (c *EBSClient) DescribeVolumeAttachments(ctx context.Context, volumeID string) ([]VolumeAttachment, error) {
 	info, err := c.GetVolumeInfo(ctx, volumeID)
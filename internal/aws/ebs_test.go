@@ -1,9 +1,18 @@
 package aws
 
 import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 func TestGetVolumeIDFromHandle(t *testing.T) {
@@ -76,11 +85,258 @@ func TestVolumeStateString(t *testing.T) {
 func TestDefaultWaitConfig(t *testing.T) {
 	cfg := DefaultWaitConfig()
 
-	if cfg.PollInterval.Seconds() != 5 {
-		t.Errorf("expected PollInterval of 5s, got %v", cfg.PollInterval)
+	if cfg.PollInterval.Seconds() != 2 {
+		t.Errorf("expected PollInterval of 2s, got %v", cfg.PollInterval)
+	}
+
+	if cfg.MaxPollInterval.Seconds() != 30 || cfg.BackoffFactor != 1.5 {
+		t.Errorf("expected backoff by 1.5 up to 30s, got %v up to %v", cfg.BackoffFactor, cfg.MaxPollInterval)
 	}
 
 	if cfg.Timeout.Minutes() != 5 {
 		t.Errorf("expected Timeout of 5m, got %v", cfg.Timeout)
 	}
 }
+
+// fakeEC2 serves DescribeVolumes, DescribeSnapshots,
+// DescribeVolumesModifications and DescribeFastSnapshotRestores from a
+// script of responses; the last response repeats once the script runs out.
+// DescribeInstances returns the tags in instances.
+type fakeEC2 struct {
+	EC2API
+
+	mu        sync.Mutex
+	volumes   []func() (*ec2.DescribeVolumesOutput, error)
+	snapshots []func() (*ec2.DescribeSnapshotsOutput, error)
+	mods      []func() (*ec2.DescribeVolumesModificationsOutput, error)
+	fsr       []func() (*ec2.DescribeFastSnapshotRestoresOutput, error)
+	instances map[string]map[string]string
+}
+
+func (f *fakeEC2) DescribeVolumes(context.Context, *ec2.DescribeVolumesInput, ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	next := f.volumes[0]
+	if len(f.volumes) > 1 {
+		f.volumes = f.volumes[1:]
+	}
+	return next()
+}
+
+func (f *fakeEC2) DescribeSnapshots(context.Context, *ec2.DescribeSnapshotsInput, ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	next := f.snapshots[0]
+	if len(f.snapshots) > 1 {
+		f.snapshots = f.snapshots[1:]
+	}
+	return next()
+}
+
+func (f *fakeEC2) DescribeVolumesModifications(context.Context, *ec2.DescribeVolumesModificationsInput, ...func(*ec2.Options)) (*ec2.DescribeVolumesModificationsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	next := f.mods[0]
+	if len(f.mods) > 1 {
+		f.mods = f.mods[1:]
+	}
+	return next()
+}
+
+func (f *fakeEC2) DescribeFastSnapshotRestores(context.Context, *ec2.DescribeFastSnapshotRestoresInput, ...func(*ec2.Options)) (*ec2.DescribeFastSnapshotRestoresOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	next := f.fsr[0]
+	if len(f.fsr) > 1 {
+		f.fsr = f.fsr[1:]
+	}
+	return next()
+}
+
+func (f *fakeEC2) DescribeInstances(_ context.Context, in *ec2.DescribeInstancesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	if f.instances == nil {
+		return nil, errors.New("not implemented")
+	}
+	out := &ec2.DescribeInstancesOutput{}
+	for _, id := range in.InstanceIds {
+		instance := types.Instance{InstanceId: aws.String(id)}
+		for key, value := range f.instances[id] {
+			instance.Tags = append(instance.Tags, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+		}
+		out.Reservations = append(out.Reservations, types.Reservation{Instances: []types.Instance{instance}})
+	}
+	return out, nil
+}
+
+func volumeResponse(state types.VolumeState, attachments ...types.VolumeAttachment) func() (*ec2.DescribeVolumesOutput, error) {
+	return func() (*ec2.DescribeVolumesOutput, error) {
+		return &ec2.DescribeVolumesOutput{Volumes: []types.Volume{{
+			VolumeId:    aws.String("vol-1"),
+			State:       state,
+			Attachments: attachments,
+		}}}, nil
+	}
+}
+
+func throttled[T any]() func() (*T, error) {
+	return func() (*T, error) {
+		return nil, &smithy.GenericAPIError{Code: "RequestLimitExceeded"}
+	}
+}
+
+// runWithFakeClock runs fn and advances the fake clock by step whenever fn is
+// blocked on it, so waits finish without sleeping for their real intervals
+func runWithFakeClock(t *testing.T, clk *clocktesting.FakeClock, step time.Duration, fn func() error) error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-deadline:
+			t.Fatal("wait did not finish")
+		case <-time.After(time.Millisecond):
+			if clk.HasWaiters() {
+				clk.Step(step)
+			}
+		}
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	ok := NewEBSClientFromAPI(&fakeEC2{volumes: []func() (*ec2.DescribeVolumesOutput, error){
+		func() (*ec2.DescribeVolumesOutput, error) { return &ec2.DescribeVolumesOutput{}, nil },
+	}}, clocktesting.NewFakeClock(time.Now()), "us-east-1")
+	if err := ok.CheckAccess(context.Background()); err != nil {
+		t.Errorf("CheckAccess() error = %v", err)
+	}
+
+	denied := NewEBSClientFromAPI(&fakeEC2{volumes: []func() (*ec2.DescribeVolumesOutput, error){
+		func() (*ec2.DescribeVolumesOutput, error) {
+			return nil, &smithy.GenericAPIError{Code: "UnauthorizedOperation"}
+		},
+	}}, clocktesting.NewFakeClock(time.Now()), "us-east-1")
+	if err := denied.CheckAccess(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("CheckAccess() error = %v, want %s", err, ErrUnauthorized)
+	}
+}
+
+func TestWaitForVolumeDetach(t *testing.T) {
+	inUse := volumeResponse(types.VolumeStateInUse, types.VolumeAttachment{
+		InstanceId: aws.String("i-1"),
+		State:      types.VolumeAttachmentStateAttached,
+	})
+	available := volumeResponse(types.VolumeStateAvailable)
+
+	tests := []struct {
+		name        string
+		responses   []func() (*ec2.DescribeVolumesOutput, error)
+		wantTimeout bool
+		wantErr     bool
+	}{
+		{name: "already available", responses: []func() (*ec2.DescribeVolumesOutput, error){available}},
+		{name: "detaches after polls", responses: []func() (*ec2.DescribeVolumesOutput, error){inUse, inUse, inUse, available}},
+		{name: "throttled poll keeps waiting", responses: []func() (*ec2.DescribeVolumesOutput, error){inUse, throttled[ec2.DescribeVolumesOutput](), available}},
+		{name: "times out", responses: []func() (*ec2.DescribeVolumesOutput, error){inUse}, wantTimeout: true, wantErr: true},
+		{name: "throttled initial call fails", responses: []func() (*ec2.DescribeVolumesOutput, error){throttled[ec2.DescribeVolumesOutput]()}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			c := NewEBSClientFromAPI(&fakeEC2{volumes: tt.responses}, clk, "us-east-1")
+
+			var progress DetachProgress
+			err := runWithFakeClock(t, clk, time.Minute, func() error {
+				var err error
+				progress, err = c.WaitForVolumeDetach(context.Background(), "vol-1", WaitForVolumeDetachConfig{
+					Timeout: time.Hour,
+				})
+				return err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitForVolumeDetach() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantTimeout && progress.Polls != len(tt.responses) {
+				t.Errorf("Polls = %d, want %d", progress.Polls, len(tt.responses))
+			}
+
+			var waitErr *DetachWaitError
+			if got := errors.As(err, &waitErr); got != tt.wantTimeout {
+				t.Fatalf("DetachWaitError = %v, want %v (err %v)", got, tt.wantTimeout, err)
+			}
+			if tt.wantTimeout && waitErr.Progress.Elapsed() < time.Hour {
+				t.Errorf("timed out after %v, want at least 1h of fake time", waitErr.Progress.Elapsed())
+			}
+		})
+	}
+}
+
+func TestWatchVolumeDetach(t *testing.T) {
+	inUse := volumeResponse(types.VolumeStateInUse, types.VolumeAttachment{
+		InstanceId: aws.String("i-1"),
+		State:      types.VolumeAttachmentStateDetaching,
+	})
+	available := volumeResponse(types.VolumeStateAvailable)
+	clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := NewEBSClientFromAPI(&fakeEC2{volumes: []func() (*ec2.DescribeVolumesOutput, error){inUse, inUse, available}}, clk, "us-east-1")
+
+	var updates []DetachUpdate
+	err := runWithFakeClock(t, clk, time.Minute, func() error {
+		watch, cancel := c.WatchVolumeDetach(context.Background(), "vol-1", WaitForVolumeDetachConfig{Timeout: time.Hour})
+		defer cancel()
+		for update := range watch {
+			updates = append(updates, update)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	final := updates[len(updates)-1]
+	if !final.Done || final.Err != nil || final.Progress.Phase != DetachPhaseDetached || final.Info.State != types.VolumeStateAvailable {
+		t.Errorf("final update = %+v, want done and detached", final)
+	}
+	if final.Progress.DetachingAt.IsZero() {
+		t.Errorf("final progress %+v did not see the volume detaching", final.Progress)
+	}
+	for _, update := range updates[:len(updates)-1] {
+		if update.Done {
+			t.Errorf("update %+v before the final one is done", update)
+		}
+	}
+
+	stuck := NewEBSClientFromAPI(&fakeEC2{volumes: []func() (*ec2.DescribeVolumesOutput, error){inUse}}, clk, "us-east-1")
+	watch, cancel := stuck.WatchVolumeDetach(context.Background(), "vol-1", WaitForVolumeDetachConfig{Timeout: time.Hour})
+	if update := <-watch; update.Done || update.Progress.Phase != DetachPhaseDetaching {
+		t.Errorf("first update = %+v, want the volume detaching", update)
+	}
+	cancel()
+	for update := range watch {
+		final = update
+	}
+	if !final.Done || !errors.Is(final.Err, context.Canceled) {
+		t.Errorf("final update after cancel = %+v, want done with context.Canceled", final)
+	}
+}
+
+func TestNextPollDelay(t *testing.T) {
+	cfg := WaitForVolumeDetachConfig{MaxPollInterval: 30 * time.Second, BackoffFactor: 1.5}
+	var delays []time.Duration
+	for delay := 2 * time.Second; len(delays) < 9; delay = nextPollDelay(delay, cfg) {
+		delays = append(delays, delay)
+	}
+	want := []time.Duration{2 * time.Second, 3 * time.Second, 4500 * time.Millisecond, 6750 * time.Millisecond,
+		10125 * time.Millisecond, 15187500 * time.Microsecond, 22781250 * time.Microsecond, 30 * time.Second, 30 * time.Second}
+	if !slices.Equal(delays, want) {
+		t.Errorf("poll delays = %v, want %v", delays, want)
+	}
+
+	fixed := WaitForVolumeDetachConfig{MaxPollInterval: 5 * time.Second, BackoffFactor: 1}
+	if got := nextPollDelay(5*time.Second, fixed); got != 5*time.Second {
+		t.Errorf("nextPollDelay() with factor 1 = %v, want 5s", got)
+	}
+}
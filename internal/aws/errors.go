@@ -0,0 +1,184 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/smithy-go"
+)
+
+// ErrorKind classifies an AWS API failure so callers can decide whether to
+// retry or abort. Errors returned by EBSClient match a kind with errors.Is.
+type ErrorKind string
+
+const (
+	// ErrNotFound means the volume, snapshot, instance or key does not exist
+	ErrNotFound ErrorKind = "NotFound"
+
+	// ErrThrottled means AWS rate-limited the request; retrying later can succeed
+	ErrThrottled ErrorKind = "Throttled"
+
+	// ErrUnauthorized means the credentials are invalid, expired or lack an IAM permission
+	ErrUnauthorized ErrorKind = "Unauthorized"
+
+	// ErrWrongRegion means the resource is in a different region than the client
+	ErrWrongRegion ErrorKind = "WrongRegion"
+)
+
+// Error implements the error interface
+func (k ErrorKind) Error() string {
+	switch k {
+	case ErrNotFound:
+		return "resource not found"
+	case ErrThrottled:
+		return "request throttled by AWS"
+	case ErrUnauthorized:
+		return "not authorized"
+	case ErrWrongRegion:
+		return "resource is in another region"
+	default:
+		return string(k)
+	}
+}
+
+// errorCodeKinds maps AWS error codes to their kind
+var errorCodeKinds = map[string]ErrorKind{
+	"InvalidVolume.NotFound":     ErrNotFound,
+	"InvalidSnapshot.NotFound":   ErrNotFound,
+	"InvalidInstanceID.NotFound": ErrNotFound,
+	"NotFoundException":          ErrNotFound,
+	"NoSuchResourceException":    ErrNotFound,
+	"ResourceNotFoundException":  ErrNotFound,
+	"NoSuchBucket":               ErrNotFound,
+
+	"RequestLimitExceeded":      ErrThrottled,
+	"Throttling":                ErrThrottled,
+	"ThrottlingException":       ErrThrottled,
+	"ThrottledException":        ErrThrottled,
+	"RequestThrottled":          ErrThrottled,
+	"RequestThrottledException": ErrThrottled,
+	"TooManyRequestsException":  ErrThrottled,
+	"SlowDown":                  ErrThrottled,
+
+	"UnauthorizedOperation":       ErrUnauthorized,
+	"AuthFailure":                 ErrUnauthorized,
+	"AccessDenied":                ErrUnauthorized,
+	"AccessDeniedException":       ErrUnauthorized,
+	"InvalidClientTokenId":        ErrUnauthorized,
+	"UnrecognizedClientException": ErrUnauthorized,
+	"ExpiredToken":                ErrUnauthorized,
+	"ExpiredTokenException":       ErrUnauthorized,
+	"SignatureDoesNotMatch":       ErrUnauthorized,
+	"MissingAuthenticationToken":  ErrUnauthorized,
+
+	// The account has not enabled the client's region
+	"OptInRequired": ErrWrongRegion,
+}
+
+// APIError is a failed AWS API call classified into an ErrorKind
+type APIError struct {
+	// Op is the API operation, e.g. DescribeVolumes
+	Op string
+
+	// Resource is the ID or ARN the call was about (optional)
+	Resource string
+
+	// Region is the region the client sent the request to
+	Region string
+
+	// Code is the AWS error code, if the service returned one
+	Code string
+
+	// Kind is the classification, or "" when the error is not one of the known kinds
+	Kind ErrorKind
+
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error kind and the underlying error, so both match with errors.Is and errors.As
+func (e *APIError) Unwrap() []error {
+	if e.Kind == "" {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// KindOf returns the kind of an AWS error, or "" if it is not classified
+func KindOf(err error) ErrorKind {
+	var kind ErrorKind
+	if errors.As(err, &kind) {
+		return kind
+	}
+	return ""
+}
+
+// Retryable reports whether an AWS error is transient and the operation can be retried
+func Retryable(err error) bool {
+	return errors.Is(err, ErrThrottled)
+}
+
+// classifyError wraps an error from an AWS API call in an *APIError.
+// A NotFound for an ARN in another region is reported as WrongRegion.
+func (c *EBSClient) classifyError(op, resource string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	apiErr := &APIError{Op: op, Resource: resource, Region: c.region, Err: err}
+	var smithyErr smithy.APIError
+	if errors.As(err, &smithyErr) {
+		apiErr.Code = smithyErr.ErrorCode()
+		apiErr.Kind = errorCodeKinds[apiErr.Code]
+	}
+
+	// The SDK's client-side retry budget ran out, which only happens under throttling
+	var quotaErr ratelimit.QuotaExceededError
+	if apiErr.Kind == "" && errors.As(err, &quotaErr) {
+		apiErr.Kind = ErrThrottled
+	}
+
+	if apiErr.Kind == ErrNotFound {
+		if parsed, parseErr := arn.Parse(resource); parseErr == nil && parsed.Region != "" && c.region != "" && parsed.Region != c.region {
+			apiErr.Kind = ErrWrongRegion
+			apiErr.Err = fmt.Errorf("%s is in region %s, not %s: %w", resource, parsed.Region, c.region, err)
+		}
+	}
+
+	return apiErr
+}
+
+// zoneRegionPattern matches the region prefix of an availability zone or
+// Local Zone, e.g. us-east-1 in us-east-1a and us-west-2 in us-west-2-lax-1a
+var zoneRegionPattern = regexp.MustCompile(`^[a-z]{2}(-gov|-iso[a-z]?)?-[a-z]+-\d+`)
+
+// RegionFromZone returns the region of an availability zone, or "" if it cannot be parsed
+func RegionFromZone(zone string) string {
+	return zoneRegionPattern.FindString(zone)
+}
+
+// CheckVolumeRegion returns an ErrWrongRegion error when a volume's availability
+// zone is outside the client's region. EC2 reports such volumes as NotFound, so
+// this tells a misconfigured region apart from a deleted volume. An unknown zone
+// or client region passes.
+func (c *EBSClient) CheckVolumeRegion(volumeID, zone string) error {
+	region := RegionFromZone(zone)
+	if region == "" || c.region == "" || region == c.region {
+		return nil
+	}
+	return &APIError{
+		Op:       "DescribeVolumes",
+		Resource: volumeID,
+		Region:   c.region,
+		Kind:     ErrWrongRegion,
+		Err:      fmt.Errorf("volume %s is in %s but the EBS client uses region %s", volumeID, zone, c.region),
+	}
+}
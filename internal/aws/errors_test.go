@@ -0,0 +1,97 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/smithy-go"
+)
+
+func TestClassifyError(t *testing.T) {
+	c := &EBSClient{region: "us-east-1"}
+
+	tests := []struct {
+		name     string
+		resource string
+		err      error
+		want     ErrorKind
+	}{
+		{name: "volume not found", resource: "vol-abc", err: &smithy.GenericAPIError{Code: "InvalidVolume.NotFound"}, want: ErrNotFound},
+		{name: "EC2 throttling", err: &smithy.GenericAPIError{Code: "RequestLimitExceeded"}, want: ErrThrottled},
+		{name: "KMS throttling", err: &smithy.GenericAPIError{Code: "ThrottlingException"}, want: ErrThrottled},
+		{name: "retry budget exhausted", err: fmt.Errorf("retry quota exceeded: %w", ratelimit.QuotaExceededError{}), want: ErrThrottled},
+		{name: "missing IAM permission", err: &smithy.GenericAPIError{Code: "UnauthorizedOperation"}, want: ErrUnauthorized},
+		{name: "expired credentials", err: &smithy.GenericAPIError{Code: "ExpiredToken"}, want: ErrUnauthorized},
+		{name: "region not enabled", err: &smithy.GenericAPIError{Code: "OptInRequired"}, want: ErrWrongRegion},
+		{
+			name:     "key ARN in another region",
+			resource: "arn:aws:kms:eu-west-1:111122223333:key/abc",
+			err:      &smithy.GenericAPIError{Code: "NotFoundException"},
+			want:     ErrWrongRegion,
+		},
+		{
+			name:     "key ARN in the client region",
+			resource: "arn:aws:kms:us-east-1:111122223333:key/abc",
+			err:      &smithy.GenericAPIError{Code: "NotFoundException"},
+			want:     ErrNotFound,
+		},
+		{name: "unclassified", err: &smithy.GenericAPIError{Code: "InvalidParameterValue"}},
+		{name: "not an API error", err: errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("failed to call AWS: %w", c.classifyError("Op", tt.resource, tt.err))
+			if got := KindOf(err); got != tt.want {
+				t.Errorf("KindOf() = %q, want %q", got, tt.want)
+			}
+			if tt.want != "" && !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(err, %q) = false", tt.want)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("classified error does not wrap the original error")
+			}
+			if got := Retryable(err); got != (tt.want == ErrThrottled) {
+				t.Errorf("Retryable() = %v", got)
+			}
+		})
+	}
+}
+
+func TestRegionFromZone(t *testing.T) {
+	tests := []struct {
+		zone string
+		want string
+	}{
+		{zone: "us-east-1a", want: "us-east-1"},
+		{zone: "ap-southeast-2c", want: "ap-southeast-2"},
+		{zone: "us-gov-west-1b", want: "us-gov-west-1"},
+		{zone: "us-west-2-lax-1a", want: "us-west-2"},
+		{zone: "", want: ""},
+		{zone: "use1-az1", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.zone, func(t *testing.T) {
+			if got := RegionFromZone(tt.zone); got != tt.want {
+				t.Errorf("RegionFromZone(%q) = %q, want %q", tt.zone, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckVolumeRegion(t *testing.T) {
+	c := &EBSClient{region: "us-east-1"}
+
+	if err := c.CheckVolumeRegion("vol-abc", "us-east-1b"); err != nil {
+		t.Errorf("same region: %v", err)
+	}
+	if err := c.CheckVolumeRegion("vol-abc", ""); err != nil {
+		t.Errorf("unknown zone: %v", err)
+	}
+	if err := c.CheckVolumeRegion("vol-abc", "eu-west-1a"); !errors.Is(err, ErrWrongRegion) {
+		t.Errorf("other region: got %v, want ErrWrongRegion", err)
+	}
+}
@@ -0,0 +1,137 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatchtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgetypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+)
+
+const (
+	// MaxBusEventsPerRequest is the most entries one PutEvents call takes
+	MaxBusEventsPerRequest = 10
+
+	// MaxMetricDataPerRequest is the most metrics one PutMetricData call takes
+	MaxMetricDataPerRequest = 1000
+)
+
+// eventsAPI is the subset of the EventBridge API used by EBSClient
+type eventsAPI interface {
+	PutEvents(ctx context.Context, in *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error)
+}
+
+// cloudWatchAPI is the subset of the CloudWatch API used by EBSClient
+type cloudWatchAPI interface {
+	PutMetricData(ctx context.Context, in *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error)
+}
+
+// BusEvent is an event put on an EventBridge event bus
+type BusEvent struct {
+	// EventBus is the name or ARN of the bus; empty is the account's default bus
+	EventBus string
+
+	// Source identifies the application that sent the event, such as "migration.aqua.io"
+	Source string
+
+	// DetailType describes the event, such as "StatefulSetMigration Phase Change"
+	DetailType string
+
+	// Detail is the event's body as a JSON object
+	Detail string
+
+	// Time is when the event happened
+	Time time.Time
+}
+
+// PutEvents puts events on their EventBridge event buses, at most
+// MaxBusEventsPerRequest at a time. It fails when EventBridge rejects any of
+// them, naming the first rejection. Needs events:PutEvents.
+func (c *EBSClient) PutEvents(ctx context.Context, events []BusEvent) error {
+	if len(events) > MaxBusEventsPerRequest {
+		return fmt.Errorf("PutEvents takes at most %d events, got %d", MaxBusEventsPerRequest, len(events))
+	}
+	in := &eventbridge.PutEventsInput{}
+	for _, e := range events {
+		entry := eventbridgetypes.PutEventsRequestEntry{
+			Source:     aws.String(e.Source),
+			DetailType: aws.String(e.DetailType),
+			Detail:     aws.String(e.Detail),
+		}
+		if e.EventBus != "" {
+			entry.EventBusName = aws.String(e.EventBus)
+		}
+		if !e.Time.IsZero() {
+			entry.Time = aws.Time(e.Time)
+		}
+		in.Entries = append(in.Entries, entry)
+	}
+	out, err := c.eventsClient.PutEvents(ctx, in)
+	if err != nil {
+		return fmt.Errorf("failed to put events on EventBridge: %w", c.classifyError("PutEvents", "", err))
+	}
+	if out.FailedEntryCount == 0 {
+		return nil
+	}
+	for _, e := range out.Entries {
+		if e.ErrorCode != nil {
+			return fmt.Errorf("EventBridge rejected %d of %d events: %s %s", out.FailedEntryCount, len(events),
+				aws.ToString(e.ErrorCode), aws.ToString(e.ErrorMessage))
+		}
+	}
+	return fmt.Errorf("EventBridge rejected %d of %d events", out.FailedEntryCount, len(events))
+}
+
+// MetricDatum is one value of a CloudWatch metric
+type MetricDatum struct {
+	// Name is the metric's name
+	Name string
+
+	// Dimensions further identify the metric, by name and value
+	Dimensions map[string]string
+
+	// Value is the value observed
+	Value float64
+
+	// Unit is a CloudWatch unit, such as "Seconds", "Bytes" or "Count"
+	Unit string
+
+	// Time is when the value was observed
+	Time time.Time
+}
+
+// PutMetricData publishes metric values to a CloudWatch namespace, at most
+// MaxMetricDataPerRequest at a time. Needs cloudwatch:PutMetricData.
+func (c *EBSClient) PutMetricData(ctx context.Context, namespace string, data []MetricDatum) error {
+	if len(data) > MaxMetricDataPerRequest {
+		return fmt.Errorf("PutMetricData takes at most %d values, got %d", MaxMetricDataPerRequest, len(data))
+	}
+	in := &cloudwatch.PutMetricDataInput{Namespace: aws.String(namespace)}
+	for _, d := range data {
+		datum := cloudwatchtypes.MetricDatum{
+			MetricName: aws.String(d.Name),
+			Value:      aws.Float64(d.Value),
+			Unit:       cloudwatchtypes.StandardUnit(d.Unit),
+		}
+		if !d.Time.IsZero() {
+			datum.Timestamp = aws.Time(d.Time)
+		}
+		for _, name := range slices.Sorted(maps.Keys(d.Dimensions)) {
+			datum.Dimensions = append(datum.Dimensions, cloudwatchtypes.Dimension{
+				Name:  aws.String(name),
+				Value: aws.String(d.Dimensions[name]),
+			})
+		}
+		in.MetricData = append(in.MetricData, datum)
+	}
+	if _, err := c.cloudWatchClient.PutMetricData(ctx, in); err != nil {
+		return fmt.Errorf("failed to put metrics in CloudWatch namespace %s: %w", namespace, c.classifyError("PutMetricData", namespace, err))
+	}
+	return nil
+}
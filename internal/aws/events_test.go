@@ -0,0 +1,105 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgetypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+)
+
+// fakeEvents records the PutEvents request and returns out
+type fakeEvents struct {
+	in  *eventbridge.PutEventsInput
+	out *eventbridge.PutEventsOutput
+}
+
+func (f *fakeEvents) PutEvents(ctx context.Context, in *eventbridge.PutEventsInput, optFns ...func(*eventbridge.Options)) (*eventbridge.PutEventsOutput, error) {
+	f.in = in
+	return f.out, nil
+}
+
+// fakeCloudWatch records the PutMetricData request
+type fakeCloudWatch struct {
+	in *cloudwatch.PutMetricDataInput
+}
+
+func (f *fakeCloudWatch) PutMetricData(ctx context.Context, in *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error) {
+	f.in = in
+	return &cloudwatch.PutMetricDataOutput{}, nil
+}
+
+func TestPutEvents(t *testing.T) {
+	events := &fakeEvents{out: &eventbridge.PutEventsOutput{Entries: []eventbridgetypes.PutEventsResultEntry{{EventId: aws.String("1")}}}}
+	c := &EBSClient{eventsClient: events, region: "us-east-1"}
+
+	err := c.PutEvents(context.Background(), []BusEvent{{
+		EventBus:   "migrations",
+		Source:     "migration.aqua.io",
+		DetailType: "StatefulSetMigration Phase Change",
+		Detail:     `{"phase":"Completed"}`,
+		Time:       time.Unix(1700000000, 0),
+	}})
+	if err != nil {
+		t.Fatalf("PutEvents() error = %v", err)
+	}
+	if len(events.in.Entries) != 1 {
+		t.Fatalf("Entries = %v, want one", events.in.Entries)
+	}
+	entry := events.in.Entries[0]
+	if aws.ToString(entry.EventBusName) != "migrations" || aws.ToString(entry.Detail) != `{"phase":"Completed"}` ||
+		!aws.ToTime(entry.Time).Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("entry = %+v", entry)
+	}
+}
+
+func TestPutEventsRejected(t *testing.T) {
+	events := &fakeEvents{out: &eventbridge.PutEventsOutput{
+		FailedEntryCount: 1,
+		Entries: []eventbridgetypes.PutEventsResultEntry{{
+			ErrorCode:    aws.String("NotAuthorizedForSourceException"),
+			ErrorMessage: aws.String("Not authorized"),
+		}},
+	}}
+	c := &EBSClient{eventsClient: events, region: "us-east-1"}
+
+	err := c.PutEvents(context.Background(), []BusEvent{{Source: "migration.aqua.io", DetailType: "x", Detail: "{}"}})
+	if err == nil || !strings.Contains(err.Error(), "NotAuthorizedForSourceException") {
+		t.Errorf("PutEvents() error = %v, want the rejection", err)
+	}
+	if events.in.Entries[0].EventBusName != nil || events.in.Entries[0].Time != nil {
+		t.Errorf("entry = %+v, want the default bus and no time", events.in.Entries[0])
+	}
+}
+
+func TestPutMetricData(t *testing.T) {
+	metrics := &fakeCloudWatch{}
+	c := &EBSClient{cloudWatchClient: metrics, region: "us-east-1"}
+
+	err := c.PutMetricData(context.Background(), "AquaMigrations", []MetricDatum{{
+		Name:       "MigrationDuration",
+		Dimensions: map[string]string{"Result": "Completed", "Namespace": "ops"},
+		Value:      42,
+		Unit:       "Seconds",
+		Time:       time.Unix(1700000000, 0),
+	}})
+	if err != nil {
+		t.Fatalf("PutMetricData() error = %v", err)
+	}
+	if aws.ToString(metrics.in.Namespace) != "AquaMigrations" || len(metrics.in.MetricData) != 1 {
+		t.Fatalf("request = %+v", metrics.in)
+	}
+	datum := metrics.in.MetricData[0]
+	var dimensions []string
+	for _, d := range datum.Dimensions {
+		dimensions = append(dimensions, aws.ToString(d.Name)+"="+aws.ToString(d.Value))
+	}
+	if aws.ToString(datum.MetricName) != "MigrationDuration" || aws.ToFloat64(datum.Value) != 42 || datum.Unit != "Seconds" ||
+		strings.Join(dimensions, ",") != "Namespace=ops,Result=Completed" {
+		t.Errorf("datum = %+v, dimensions %v", datum, dimensions)
+	}
+}
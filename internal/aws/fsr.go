@@ -0,0 +1,138 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// WaitForFastSnapshotRestoreConfig contains configuration for WaitForFastSnapshotRestore
+type WaitForFastSnapshotRestoreConfig struct {
+	// PollInterval is how often to check the fast snapshot restore state (default: 30s)
+	PollInterval time.Duration
+
+	// Timeout is the maximum time to wait (default: 2h)
+	Timeout time.Duration
+
+	// OnPoll is called each time the state is polled (optional)
+	OnPoll func(state types.FastSnapshotRestoreStateCode)
+}
+
+// EnableFastSnapshotRestore enables fast snapshot restore for a snapshot in an
+// availability zone. A volume created from the snapshot before the state is
+// enabled is lazily loaded from S3, so every first read of a block is slow;
+// wait with WaitForFastSnapshotRestore before creating it. Fast snapshot
+// restore is billed per snapshot and zone while enabled, so disable it with
+// DisableFastSnapshotRestore once the volume exists.
+func (c *EBSClient) EnableFastSnapshotRestore(ctx context.Context, snapshotID, zone string) error {
+	resp, err := c.ec2Client.EnableFastSnapshotRestores(ctx, &ec2.EnableFastSnapshotRestoresInput{
+		SourceSnapshotIds: []string{snapshotID},
+		AvailabilityZones: []string{zone},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable fast snapshot restore for %s in %s: %w", snapshotID, zone, c.classifyError("EnableFastSnapshotRestores", snapshotID, err))
+	}
+	if problems := fastSnapshotRestoreErrors(resp.Unsuccessful); len(problems) > 0 {
+		return fmt.Errorf("failed to enable fast snapshot restore for %s in %s: %s", snapshotID, zone, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// DisableFastSnapshotRestore disables fast snapshot restore for a snapshot in an availability zone
+func (c *EBSClient) DisableFastSnapshotRestore(ctx context.Context, snapshotID, zone string) error {
+	if _, err := c.ec2Client.DisableFastSnapshotRestores(ctx, &ec2.DisableFastSnapshotRestoresInput{
+		SourceSnapshotIds: []string{snapshotID},
+		AvailabilityZones: []string{zone},
+	}); err != nil {
+		return fmt.Errorf("failed to disable fast snapshot restore for %s in %s: %w", snapshotID, zone, c.classifyError("DisableFastSnapshotRestores", snapshotID, err))
+	}
+	return nil
+}
+
+// GetFastSnapshotRestoreState returns the fast snapshot restore state of a
+// snapshot in an availability zone; a snapshot it was never enabled for is disabled
+func (c *EBSClient) GetFastSnapshotRestoreState(ctx context.Context, snapshotID, zone string) (types.FastSnapshotRestoreStateCode, error) {
+	resp, err := c.ec2Client.DescribeFastSnapshotRestores(ctx, &ec2.DescribeFastSnapshotRestoresInput{
+		Filters: []types.Filter{
+			{Name: aws.String("snapshot-id"), Values: []string{snapshotID}},
+			{Name: aws.String("availability-zone"), Values: []string{zone}},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe fast snapshot restores of %s: %w", snapshotID, c.classifyError("DescribeFastSnapshotRestores", snapshotID, err))
+	}
+	for _, item := range resp.FastSnapshotRestores {
+		if aws.ToString(item.SnapshotId) == snapshotID && aws.ToString(item.AvailabilityZone) == zone {
+			return item.State, nil
+		}
+	}
+	return types.FastSnapshotRestoreStateCodeDisabled, nil
+}
+
+// WaitForFastSnapshotRestore blocks until fast snapshot restore is enabled
+// for the snapshot in the availability zone. EC2 takes about an hour per TiB
+// of snapshot data. The wait fails if fast snapshot restore is being
+// disabled, since it will never be enabled.
+func (c *EBSClient) WaitForFastSnapshotRestore(ctx context.Context, snapshotID, zone string, cfg WaitForFastSnapshotRestoreConfig) error {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = 30 * time.Second
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 2 * time.Hour
+	}
+
+	timeout := c.clock.NewTimer(cfg.Timeout)
+	defer timeout.Stop()
+
+	ticker := c.clock.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	var state types.FastSnapshotRestoreStateCode
+	for {
+		// A throttled poll keeps the last known state and tries again on the next tick
+		polled, err := c.GetFastSnapshotRestoreState(ctx, snapshotID, zone)
+		switch {
+		case err == nil:
+			state = polled
+			if cfg.OnPoll != nil {
+				cfg.OnPoll(state)
+			}
+			switch state {
+			case types.FastSnapshotRestoreStateCodeEnabled:
+				return nil
+			case types.FastSnapshotRestoreStateCodeDisabling, types.FastSnapshotRestoreStateCodeDisabled:
+				return fmt.Errorf("fast snapshot restore for %s in %s is %s", snapshotID, zone, state)
+			}
+		case state == "" || !Retryable(err):
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C():
+			return fmt.Errorf("timeout waiting for fast snapshot restore for %s in %s (waited %v, %s)", snapshotID, zone, cfg.Timeout, state)
+		case <-ticker.C():
+		}
+	}
+}
+
+// fastSnapshotRestoreErrors renders the per-zone failures of an EnableFastSnapshotRestores call
+func fastSnapshotRestoreErrors(items []types.EnableFastSnapshotRestoreErrorItem) []string {
+	var problems []string
+	for _, item := range items {
+		for _, zoneErr := range item.FastSnapshotRestoreStateErrors {
+			if zoneErr.Error == nil {
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("%s: %s (%s)", aws.ToString(zoneErr.AvailabilityZone),
+				aws.ToString(zoneErr.Error.Message), aws.ToString(zoneErr.Error.Code)))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,96 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// fsrEC2 answers EnableFastSnapshotRestores with the given failures
+type fsrEC2 struct {
+	fakeEC2
+	unsuccessful []types.EnableFastSnapshotRestoreErrorItem
+}
+
+func (f *fsrEC2) EnableFastSnapshotRestores(context.Context, *ec2.EnableFastSnapshotRestoresInput, ...func(*ec2.Options)) (*ec2.EnableFastSnapshotRestoresOutput, error) {
+	return &ec2.EnableFastSnapshotRestoresOutput{Unsuccessful: f.unsuccessful}, nil
+}
+
+func fsrResponse(states ...types.FastSnapshotRestoreStateCode) func() (*ec2.DescribeFastSnapshotRestoresOutput, error) {
+	return func() (*ec2.DescribeFastSnapshotRestoresOutput, error) {
+		out := &ec2.DescribeFastSnapshotRestoresOutput{}
+		for _, state := range states {
+			out.FastSnapshotRestores = append(out.FastSnapshotRestores, types.DescribeFastSnapshotRestoreSuccessItem{
+				SnapshotId:       aws.String("snap-1"),
+				AvailabilityZone: aws.String("us-east-1a"),
+				State:            state,
+			})
+		}
+		return out, nil
+	}
+}
+
+func TestEnableFastSnapshotRestore(t *testing.T) {
+	c := NewEBSClientFromAPI(&fsrEC2{}, clocktesting.NewFakeClock(time.Now()), "us-east-1")
+	if err := c.EnableFastSnapshotRestore(context.Background(), "snap-1", "us-east-1a"); err != nil {
+		t.Errorf("EnableFastSnapshotRestore() error = %v", err)
+	}
+
+	c = NewEBSClientFromAPI(&fsrEC2{unsuccessful: []types.EnableFastSnapshotRestoreErrorItem{{
+		SnapshotId: aws.String("snap-1"),
+		FastSnapshotRestoreStateErrors: []types.EnableFastSnapshotRestoreStateErrorItem{{
+			AvailabilityZone: aws.String("us-east-1a"),
+			Error:            &types.EnableFastSnapshotRestoreStateError{Code: aws.String("ConcurrentSnapshotLimitExceeded"), Message: aws.String("limit reached")},
+		}},
+	}}}, clocktesting.NewFakeClock(time.Now()), "us-east-1")
+	err := c.EnableFastSnapshotRestore(context.Background(), "snap-1", "us-east-1a")
+	if err == nil || !strings.Contains(err.Error(), "us-east-1a: limit reached (ConcurrentSnapshotLimitExceeded)") {
+		t.Errorf("EnableFastSnapshotRestore() error = %v, want the zone's failure", err)
+	}
+}
+
+func TestWaitForFastSnapshotRestore(t *testing.T) {
+	enabling := fsrResponse(types.FastSnapshotRestoreStateCodeEnabling)
+	optimizing := fsrResponse(types.FastSnapshotRestoreStateCodeOptimizing)
+
+	tests := []struct {
+		name      string
+		responses []func() (*ec2.DescribeFastSnapshotRestoresOutput, error)
+		wantErr   string
+	}{
+		{name: "enabled", responses: []func() (*ec2.DescribeFastSnapshotRestoresOutput, error){
+			enabling, optimizing, fsrResponse(types.FastSnapshotRestoreStateCodeEnabled)}},
+		{name: "throttled poll keeps waiting", responses: []func() (*ec2.DescribeFastSnapshotRestoresOutput, error){
+			enabling, throttled[ec2.DescribeFastSnapshotRestoresOutput](), fsrResponse(types.FastSnapshotRestoreStateCodeEnabled)}},
+		{name: "never enabled", responses: []func() (*ec2.DescribeFastSnapshotRestoresOutput, error){fsrResponse()}, wantErr: "is disabled"},
+		{name: "being disabled", responses: []func() (*ec2.DescribeFastSnapshotRestoresOutput, error){
+			enabling, fsrResponse(types.FastSnapshotRestoreStateCodeDisabling)}, wantErr: "is disabling"},
+		{name: "times out", responses: []func() (*ec2.DescribeFastSnapshotRestoresOutput, error){optimizing}, wantErr: "optimizing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			c := NewEBSClientFromAPI(&fakeEC2{fsr: tt.responses}, clk, "us-east-1")
+
+			err := runWithFakeClock(t, clk, 10*time.Minute, func() error {
+				return c.WaitForFastSnapshotRestore(context.Background(), "snap-1", "us-east-1a", WaitForFastSnapshotRestoreConfig{})
+			})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("WaitForFastSnapshotRestore() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("WaitForFastSnapshotRestore() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// IdempotencyTagKey is the EBS tag holding the idempotency key a snapshot or
+// volume was created with
+const IdempotencyTagKey = "aqua.io/migration-idempotency-key"
+
+// IdempotencyKey returns the key of one create operation of a migration. The
+// same migration, pod index and operation always give the same key, so a
+// reconcile retried after a controller restart finds the snapshot or volume
+// of the earlier attempt instead of creating a duplicate. The key is 64
+// characters, the longest client token EC2 accepts.
+func IdempotencyKey(migrationUID string, podIndex int, op string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%d/%s", migrationUID, podIndex, op)))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSnapshotConfig contains configuration for CreateSnapshot
+type CreateSnapshotConfig struct {
+	// Description is the snapshot's description (optional)
+	Description string
+
+	// Tags are added to the snapshot (optional)
+	Tags map[string]string
+}
+
+// CopySnapshotConfig contains configuration for CopySnapshot
+type CopySnapshotConfig struct {
+	// SourceRegion is the region of the snapshot to copy (default: the client's region)
+	SourceRegion string
+
+	// Description is the copy's description (optional)
+	Description string
+
+	// KMSKeyID re-encrypts the copy with this key (optional)
+	KMSKeyID string
+
+	// Tags are added to the copy (optional)
+	Tags map[string]string
+}
+
+// CreateVolumeConfig contains configuration for CreateVolume
+type CreateVolumeConfig struct {
+	// SnapshotID is the snapshot to restore the volume from (optional)
+	SnapshotID string
+
+	// AvailabilityZone is the zone to create the volume in
+	AvailabilityZone string
+
+	// VolumeType is the EBS volume type (default: gp3)
+	VolumeType types.VolumeType
+
+	// SizeGiB is the size of the volume; 0 takes the snapshot's size
+	SizeGiB int32
+
+	// IOPS and Throughput are the provisioned performance; 0 is the type's baseline
+	IOPS       int32
+	Throughput int32
+
+	// KMSKeyID encrypts the volume with this key (optional)
+	KMSKeyID string
+
+	// Tags are added to the volume (optional)
+	Tags map[string]string
+}
+
+// CreateSnapshot snapshots a volume, or returns the snapshot an earlier call
+// with the same key created. CreateSnapshot takes no client token, so the key
+// is stored in the IdempotencyTagKey tag, which EC2 applies atomically with
+// the snapshot, and looked up before creating. A snapshot of that key that
+// failed is not reused.
+func (c *EBSClient) CreateSnapshot(ctx context.Context, volumeID, key string, cfg CreateSnapshotConfig) (string, error) {
+	if existing, err := c.findSnapshotByKey(ctx, key); err != nil || existing != "" {
+		return existing, err
+	}
+
+	in := &ec2.CreateSnapshotInput{
+		VolumeId:          aws.String(volumeID),
+		TagSpecifications: idempotentTags(types.ResourceTypeSnapshot, key, cfg.Tags),
+	}
+	if cfg.Description != "" {
+		in.Description = aws.String(cfg.Description)
+	}
+	resp, err := c.ec2Client.CreateSnapshot(ctx, in)
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot of volume %s: %w", volumeID, c.classifyError("CreateSnapshot", volumeID, err))
+	}
+	return aws.ToString(resp.SnapshotId), nil
+}
+
+// CopySnapshot copies a snapshot into the client's region, or returns the
+// copy an earlier call with the same key started. Like CreateSnapshot, it
+// relies on the IdempotencyTagKey tag since CopySnapshot takes no client token.
+func (c *EBSClient) CopySnapshot(ctx context.Context, snapshotID, key string, cfg CopySnapshotConfig) (string, error) {
+	if existing, err := c.findSnapshotByKey(ctx, key); err != nil || existing != "" {
+		return existing, err
+	}
+
+	sourceRegion := cfg.SourceRegion
+	if sourceRegion == "" {
+		sourceRegion = c.region
+	}
+	in := &ec2.CopySnapshotInput{
+		SourceSnapshotId:  aws.String(snapshotID),
+		SourceRegion:      aws.String(sourceRegion),
+		TagSpecifications: idempotentTags(types.ResourceTypeSnapshot, key, cfg.Tags),
+	}
+	if cfg.Description != "" {
+		in.Description = aws.String(cfg.Description)
+	}
+	if cfg.KMSKeyID != "" {
+		in.Encrypted = aws.Bool(true)
+		in.KmsKeyId = aws.String(cfg.KMSKeyID)
+	}
+	resp, err := c.ec2Client.CopySnapshot(ctx, in)
+	if err != nil {
+		return "", fmt.Errorf("failed to copy snapshot %s: %w", snapshotID, c.classifyError("CopySnapshot", snapshotID, err))
+	}
+	return aws.ToString(resp.SnapshotId), nil
+}
+
+// CreateVolume creates a volume, or returns the volume an earlier call with
+// the same key created. The key is passed as the EC2 client token and also
+// stored in the IdempotencyTagKey tag, so the volume is found again after EC2
+// has forgotten the token.
+func (c *EBSClient) CreateVolume(ctx context.Context, key string, cfg CreateVolumeConfig) (string, error) {
+	if err := CheckVolumeConfig(cfg); err != nil {
+		return "", err
+	}
+	if existing, err := c.FindVolumeByKey(ctx, key); err != nil || existing != "" {
+		return existing, err
+	}
+
+	volumeType := cfg.VolumeType
+	if volumeType == "" {
+		volumeType = types.VolumeTypeGp3
+	}
+	in := &ec2.CreateVolumeInput{
+		ClientToken:       aws.String(key),
+		AvailabilityZone:  aws.String(cfg.AvailabilityZone),
+		VolumeType:        volumeType,
+		TagSpecifications: idempotentTags(types.ResourceTypeVolume, key, cfg.Tags),
+	}
+	if cfg.SnapshotID != "" {
+		in.SnapshotId = aws.String(cfg.SnapshotID)
+	}
+	if cfg.SizeGiB > 0 {
+		in.Size = aws.Int32(cfg.SizeGiB)
+	}
+	if cfg.IOPS > 0 {
+		in.Iops = aws.Int32(cfg.IOPS)
+	}
+	if cfg.Throughput > 0 {
+		in.Throughput = aws.Int32(cfg.Throughput)
+	}
+	if cfg.KMSKeyID != "" {
+		in.Encrypted = aws.Bool(true)
+		in.KmsKeyId = aws.String(cfg.KMSKeyID)
+	}
+	resp, err := c.ec2Client.CreateVolume(ctx, in)
+	if err != nil {
+		return "", fmt.Errorf("failed to create volume in %s: %w", cfg.AvailabilityZone, c.classifyError("CreateVolume", cfg.SnapshotID, err))
+	}
+	return aws.ToString(resp.VolumeId), nil
+}
+
+// findSnapshotByKey returns the snapshot created with an idempotency key, or
+// "" if there is none that has not failed
+func (c *EBSClient) findSnapshotByKey(ctx context.Context, key string) (string, error) {
+	resp, err := c.ec2Client.DescribeSnapshots(ctx, &ec2.DescribeSnapshotsInput{
+		OwnerIds: []string{"self"},
+		Filters:  []types.Filter{{Name: aws.String("tag:" + IdempotencyTagKey), Values: []string{key}}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to look up snapshot for idempotency key %s: %w", key, c.classifyError("DescribeSnapshots", "", err))
+	}
+	for _, snap := range resp.Snapshots {
+		if snap.State != types.SnapshotStateError {
+			return aws.ToString(snap.SnapshotId), nil
+		}
+	}
+	return "", nil
+}
+
+// FindVolumeByKey returns the volume created with an idempotency key, or ""
+// if there is none that is usable
+func (c *EBSClient) FindVolumeByKey(ctx context.Context, key string) (string, error) {
+	resp, err := c.ec2Client.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{
+		Filters: []types.Filter{{Name: aws.String("tag:" + IdempotencyTagKey), Values: []string{key}}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to look up volume for idempotency key %s: %w", key, c.classifyError("DescribeVolumes", "", err))
+	}
+	for _, vol := range resp.Volumes {
+		switch vol.State {
+		case types.VolumeStateError, types.VolumeStateDeleting, types.VolumeStateDeleted:
+			continue
+		}
+		return aws.ToString(vol.VolumeId), nil
+	}
+	return "", nil
+}
+
+// idempotentTags returns the tag specification for a created resource: its
+// tags plus the idempotency key
+func idempotentTags(resourceType types.ResourceType, key string, tags map[string]string) []types.TagSpecification {
+	spec := types.TagSpecification{
+		ResourceType: resourceType,
+		Tags:         []types.Tag{{Key: aws.String(IdempotencyTagKey), Value: aws.String(key)}},
+	}
+	for k, v := range tags {
+		spec.Tags = append(spec.Tags, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	return []types.TagSpecification{spec}
+}
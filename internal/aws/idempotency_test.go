@@ -0,0 +1,211 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// createEC2 records the create calls it receives
+type createEC2 struct {
+	fakeEC2
+	snapshots []*ec2.CreateSnapshotInput
+	copies    []*ec2.CopySnapshotInput
+	created   []*ec2.CreateVolumeInput
+}
+
+func (f *createEC2) CreateSnapshot(_ context.Context, in *ec2.CreateSnapshotInput, _ ...func(*ec2.Options)) (*ec2.CreateSnapshotOutput, error) {
+	f.snapshots = append(f.snapshots, in)
+	return &ec2.CreateSnapshotOutput{SnapshotId: aws.String("snap-new")}, nil
+}
+
+func (f *createEC2) CopySnapshot(_ context.Context, in *ec2.CopySnapshotInput, _ ...func(*ec2.Options)) (*ec2.CopySnapshotOutput, error) {
+	f.copies = append(f.copies, in)
+	return &ec2.CopySnapshotOutput{SnapshotId: aws.String("snap-copy")}, nil
+}
+
+func (f *createEC2) CreateVolume(_ context.Context, in *ec2.CreateVolumeInput, _ ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error) {
+	f.created = append(f.created, in)
+	return &ec2.CreateVolumeOutput{VolumeId: aws.String("vol-new")}, nil
+}
+
+func taggedSnapshots(snaps ...types.Snapshot) func() (*ec2.DescribeSnapshotsOutput, error) {
+	return func() (*ec2.DescribeSnapshotsOutput, error) {
+		return &ec2.DescribeSnapshotsOutput{Snapshots: snaps}, nil
+	}
+}
+
+func taggedVolumes(vols ...types.Volume) func() (*ec2.DescribeVolumesOutput, error) {
+	return func() (*ec2.DescribeVolumesOutput, error) {
+		return &ec2.DescribeVolumesOutput{Volumes: vols}, nil
+	}
+}
+
+// hasIdempotencyTag reports whether a tag specification carries the key
+func hasIdempotencyTag(specs []types.TagSpecification, key string) bool {
+	for _, spec := range specs {
+		for _, tag := range spec.Tags {
+			if aws.ToString(tag.Key) == IdempotencyTagKey && aws.ToString(tag.Value) == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	key := IdempotencyKey("uid-1", 0, "CreateSnapshot")
+	if len(key) > 64 {
+		t.Errorf("IdempotencyKey() is %d characters, want at most 64", len(key))
+	}
+	if again := IdempotencyKey("uid-1", 0, "CreateSnapshot"); again != key {
+		t.Errorf("IdempotencyKey() = %s then %s, want the same key", key, again)
+	}
+	for _, other := range []string{
+		IdempotencyKey("uid-2", 0, "CreateSnapshot"),
+		IdempotencyKey("uid-1", 1, "CreateSnapshot"),
+		IdempotencyKey("uid-1", 0, "CreateVolume"),
+	} {
+		if other == key {
+			t.Errorf("IdempotencyKey() = %s for a different operation", other)
+		}
+	}
+}
+
+func TestCreateSnapshot(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []types.Snapshot
+		want     string
+		creates  int
+	}{
+		{
+			name: "no earlier snapshot",
+			want: "snap-new", creates: 1,
+		},
+		{
+			name:     "reuses the earlier snapshot",
+			existing: []types.Snapshot{{SnapshotId: aws.String("snap-old"), State: types.SnapshotStatePending}},
+			want:     "snap-old", creates: 0,
+		},
+		{
+			name:     "earlier snapshot failed",
+			existing: []types.Snapshot{{SnapshotId: aws.String("snap-old"), State: types.SnapshotStateError}},
+			want:     "snap-new", creates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &createEC2{}
+			api.fakeEC2.snapshots = []func() (*ec2.DescribeSnapshotsOutput, error){taggedSnapshots(tt.existing...)}
+			c := NewEBSClientFromAPI(api, clocktesting.NewFakeClock(time.Now()), "us-east-1")
+
+			got, err := c.CreateSnapshot(context.Background(), "vol-1", "key-1", CreateSnapshotConfig{})
+			if err != nil {
+				t.Fatalf("CreateSnapshot() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CreateSnapshot() = %s, want %s", got, tt.want)
+			}
+			if len(api.snapshots) != tt.creates {
+				t.Fatalf("CreateSnapshot() made %d create calls, want %d", len(api.snapshots), tt.creates)
+			}
+			if tt.creates > 0 && !hasIdempotencyTag(api.snapshots[0].TagSpecifications, "key-1") {
+				t.Errorf("CreateSnapshot() did not tag the snapshot with its idempotency key")
+			}
+		})
+	}
+}
+
+func TestCopySnapshot(t *testing.T) {
+	api := &createEC2{}
+	api.fakeEC2.snapshots = []func() (*ec2.DescribeSnapshotsOutput, error){taggedSnapshots()}
+	c := NewEBSClientFromAPI(api, clocktesting.NewFakeClock(time.Now()), "us-west-2")
+
+	got, err := c.CopySnapshot(context.Background(), "snap-1", "key-1", CopySnapshotConfig{SourceRegion: "us-east-1", KMSKeyID: "alias/dest"})
+	if err != nil {
+		t.Fatalf("CopySnapshot() error = %v", err)
+	}
+	if got != "snap-copy" || len(api.copies) != 1 {
+		t.Fatalf("CopySnapshot() = %s with %d copy calls, want snap-copy with 1", got, len(api.copies))
+	}
+	in := api.copies[0]
+	if aws.ToString(in.SourceRegion) != "us-east-1" || !aws.ToBool(in.Encrypted) || aws.ToString(in.KmsKeyId) != "alias/dest" {
+		t.Errorf("CopySnapshot() input = %+v, want source region us-east-1 encrypted with alias/dest", in)
+	}
+	if !hasIdempotencyTag(in.TagSpecifications, "key-1") {
+		t.Errorf("CopySnapshot() did not tag the copy with its idempotency key")
+	}
+
+	api.fakeEC2.snapshots = []func() (*ec2.DescribeSnapshotsOutput, error){
+		taggedSnapshots(types.Snapshot{SnapshotId: aws.String("snap-copy"), State: types.SnapshotStateCompleted}),
+	}
+	if got, err := c.CopySnapshot(context.Background(), "snap-1", "key-1", CopySnapshotConfig{}); err != nil || got != "snap-copy" {
+		t.Errorf("CopySnapshot() retry = %s, %v, want snap-copy", got, err)
+	}
+	if len(api.copies) != 1 {
+		t.Errorf("CopySnapshot() retry made another copy call")
+	}
+}
+
+func TestCreateVolume(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []types.Volume
+		want     string
+		creates  int
+	}{
+		{
+			name: "no earlier volume",
+			want: "vol-new", creates: 1,
+		},
+		{
+			name:     "reuses the earlier volume",
+			existing: []types.Volume{{VolumeId: aws.String("vol-old"), State: types.VolumeStateCreating}},
+			want:     "vol-old", creates: 0,
+		},
+		{
+			name:     "earlier volume deleted",
+			existing: []types.Volume{{VolumeId: aws.String("vol-old"), State: types.VolumeStateDeleting}},
+			want:     "vol-new", creates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &createEC2{}
+			api.fakeEC2.volumes = []func() (*ec2.DescribeVolumesOutput, error){taggedVolumes(tt.existing...)}
+			c := NewEBSClientFromAPI(api, clocktesting.NewFakeClock(time.Now()), "us-east-1")
+
+			got, err := c.CreateVolume(context.Background(), "key-1", CreateVolumeConfig{SnapshotID: "snap-1", AvailabilityZone: "us-east-1a"})
+			if err != nil {
+				t.Fatalf("CreateVolume() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CreateVolume() = %s, want %s", got, tt.want)
+			}
+			if len(api.created) != tt.creates {
+				t.Fatalf("CreateVolume() made %d create calls, want %d", len(api.created), tt.creates)
+			}
+			if tt.creates == 0 {
+				return
+			}
+			in := api.created[0]
+			if aws.ToString(in.ClientToken) != "key-1" {
+				t.Errorf("CreateVolume() client token = %q, want key-1", aws.ToString(in.ClientToken))
+			}
+			if in.VolumeType != types.VolumeTypeGp3 {
+				t.Errorf("CreateVolume() volume type = %s, want gp3", in.VolumeType)
+			}
+			if !hasIdempotencyTag(in.TagSpecifications, "key-1") {
+				t.Errorf("CreateVolume() did not tag the volume with its idempotency key")
+			}
+		})
+	}
+}
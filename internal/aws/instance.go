@@ -0,0 +1,130 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// InstanceHealth describes whether an EC2 instance can still release its volumes
+type InstanceHealth struct {
+	// InstanceID is the EC2 instance ID
+	InstanceID string
+
+	// State is the instance lifecycle state (running, stopped, terminated, ...)
+	State types.InstanceStateName
+
+	// Impaired is true when a running instance fails its instance or system status checks
+	Impaired bool
+}
+
+// Unavailable reports whether the instance is stopped, shutting down or
+// terminated, so it will never finish detaching a volume on its own and
+// cannot write to it once force-detached
+func (h InstanceHealth) Unavailable() bool {
+	switch h.State {
+	case types.InstanceStateNameStopped, types.InstanceStateNameShuttingDown, types.InstanceStateNameTerminated:
+		return true
+	}
+	return false
+}
+
+// Unsettled reports whether the instance is stopping, or running but failing
+// its status checks. It may still be writing to the volume, so force-detaching
+// risks corrupting the filesystem.
+func (h InstanceHealth) Unsettled() bool {
+	return h.State == types.InstanceStateNameStopping ||
+		(h.State == types.InstanceStateNameRunning && h.Impaired)
+}
+
+// String returns a human-readable description such as "stopped" or "running (impaired)"
+func (h InstanceHealth) String() string {
+	if h.State == types.InstanceStateNameRunning && h.Impaired {
+		return "running (impaired)"
+	}
+	return string(h.State)
+}
+
+// InstanceUnavailableError is returned when a volume is attached to an instance
+// that will never release it and force-detach is not enabled
+type InstanceUnavailableError struct {
+	VolumeID string
+	Instance InstanceHealth
+}
+
+// Error implements the error interface
+func (e *InstanceUnavailableError) Error() string {
+	return fmt.Sprintf("volume %s is attached to instance %s which is %s and will not release it; enable force-detach or recover the instance",
+		e.VolumeID, e.Instance.InstanceID, e.Instance)
+}
+
+// GetInstanceHealth returns the lifecycle state of an instance and, for running
+// instances, whether its status checks are impaired
+func (c *EBSClient) GetInstanceHealth(ctx context.Context, instanceID string) (*InstanceHealth, error) {
+	resp, err := c.ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: []string{instanceID},
+	})
+	if err != nil {
+		err = c.classifyError("DescribeInstances", instanceID, err)
+		if errors.Is(err, ErrNotFound) {
+			// Terminated instances eventually disappear from DescribeInstances
+			return &InstanceHealth{InstanceID: instanceID, State: types.InstanceStateNameTerminated}, nil
+		}
+		return nil, fmt.Errorf("failed to describe instance %s: %w", instanceID, err)
+	}
+
+	var instance *types.Instance
+	for _, reservation := range resp.Reservations {
+		if len(reservation.Instances) > 0 {
+			instance = &reservation.Instances[0]
+			break
+		}
+	}
+	if instance == nil || instance.State == nil {
+		// Terminated instances eventually disappear from DescribeInstances
+		return &InstanceHealth{InstanceID: instanceID, State: types.InstanceStateNameTerminated}, nil
+	}
+
+	health := &InstanceHealth{InstanceID: instanceID, State: instance.State.Name}
+	if health.State != types.InstanceStateNameRunning {
+		return health, nil
+	}
+
+	statusResp, err := c.ec2Client.DescribeInstanceStatus(ctx, &ec2.DescribeInstanceStatusInput{
+		InstanceIds: []string{instanceID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe instance status %s: %w", instanceID, c.classifyError("DescribeInstanceStatus", instanceID, err))
+	}
+	for _, status := range statusResp.InstanceStatuses {
+		if statusImpaired(status.InstanceStatus) || statusImpaired(status.SystemStatus) {
+			health.Impaired = true
+		}
+	}
+
+	return health, nil
+}
+
+// statusImpaired reports whether an instance status summary is impaired
+func statusImpaired(summary *types.InstanceStatusSummary) bool {
+	return summary != nil && summary.Status == types.SummaryStatusImpaired
+}
+
+// ForceDetachVolume force-detaches a volume from an instance. Data not yet
+// flushed by the instance may be lost, so this is only used for instances
+// that are stopped, shutting down or terminated.
+func (c *EBSClient) ForceDetachVolume(ctx context.Context, volumeID, instanceID string) error {
+	_, err := c.ec2Client.DetachVolume(ctx, &ec2.DetachVolumeInput{
+		VolumeId:   aws.String(volumeID),
+		InstanceId: aws.String(instanceID),
+		Force:      aws.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to force-detach volume %s from instance %s: %w", volumeID, instanceID, c.classifyError("DetachVolume", volumeID, err))
+	}
+	return nil
+}
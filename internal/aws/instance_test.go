@@ -0,0 +1,176 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestInstanceHealthUnavailable(t *testing.T) {
+	tests := []struct {
+		name          string
+		health        InstanceHealth
+		wantUnavail   bool
+		wantUnsettled bool
+		wantString    string
+	}{
+		{
+			name:       "running",
+			health:     InstanceHealth{State: types.InstanceStateNameRunning},
+			wantString: "running",
+		},
+		{
+			name:          "running but impaired",
+			health:        InstanceHealth{State: types.InstanceStateNameRunning, Impaired: true},
+			wantUnsettled: true,
+			wantString:    "running (impaired)",
+		},
+		{
+			name:       "pending",
+			health:     InstanceHealth{State: types.InstanceStateNamePending},
+			wantString: "pending",
+		},
+		{
+			name:        "stopped",
+			health:      InstanceHealth{State: types.InstanceStateNameStopped},
+			wantUnavail: true,
+			wantString:  "stopped",
+		},
+		{
+			name:          "stopping",
+			health:        InstanceHealth{State: types.InstanceStateNameStopping},
+			wantUnsettled: true,
+			wantString:    "stopping",
+		},
+		{
+			name:        "shutting down",
+			health:      InstanceHealth{State: types.InstanceStateNameShuttingDown},
+			wantUnavail: true,
+			wantString:  "shutting-down",
+		},
+		{
+			name:        "terminated",
+			health:      InstanceHealth{State: types.InstanceStateNameTerminated},
+			wantUnavail: true,
+			wantString:  "terminated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.health.Unavailable(); got != tt.wantUnavail {
+				t.Errorf("Unavailable() = %v, want %v", got, tt.wantUnavail)
+			}
+			if got := tt.health.Unsettled(); got != tt.wantUnsettled {
+				t.Errorf("Unsettled() = %v, want %v", got, tt.wantUnsettled)
+			}
+			if got := tt.health.String(); got != tt.wantString {
+				t.Errorf("String() = %q, want %q", got, tt.wantString)
+			}
+		})
+	}
+}
+
+// instanceEC2 serves a volume attached to instance i-1 in state, impaired or
+// not, and records force-detaches
+type instanceEC2 struct {
+	fakeEC2
+
+	state    types.InstanceStateName
+	impaired bool
+	forced   bool
+}
+
+func (f *instanceEC2) DescribeInstances(context.Context, *ec2.DescribeInstancesInput, ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{{
+		InstanceId: aws.String("i-1"),
+		State:      &types.InstanceState{Name: f.state},
+	}}}}}, nil
+}
+
+func (f *instanceEC2) DescribeInstanceStatus(context.Context, *ec2.DescribeInstanceStatusInput, ...func(*ec2.Options)) (*ec2.DescribeInstanceStatusOutput, error) {
+	status := types.SummaryStatusOk
+	if f.impaired {
+		status = types.SummaryStatusImpaired
+	}
+	return &ec2.DescribeInstanceStatusOutput{InstanceStatuses: []types.InstanceStatus{{
+		InstanceId:     aws.String("i-1"),
+		InstanceStatus: &types.InstanceStatusSummary{Status: status},
+	}}}, nil
+}
+
+func (f *instanceEC2) DetachVolume(context.Context, *ec2.DetachVolumeInput, ...func(*ec2.Options)) (*ec2.DetachVolumeOutput, error) {
+	f.forced = true
+	return &ec2.DetachVolumeOutput{}, nil
+}
+
+func TestWaitForVolumeDetachForcesOnlyStoppedInstances(t *testing.T) {
+	attached := volumeResponse(types.VolumeStateInUse, types.VolumeAttachment{
+		InstanceId: aws.String("i-1"),
+		State:      types.VolumeAttachmentStateAttached,
+	})
+	available := volumeResponse(types.VolumeStateAvailable)
+	tests := []struct {
+		name     string
+		state    types.InstanceStateName
+		impaired bool
+		volumes  []func() (*ec2.DescribeVolumesOutput, error)
+		// wantForced is whether the volume is force-detached, and wantErr
+		// whether the wait times out naming the instance
+		wantForced bool
+		wantErr    bool
+	}{
+		{name: "stopped", state: types.InstanceStateNameStopped, volumes: []func() (*ec2.DescribeVolumesOutput, error){attached, available}, wantForced: true},
+		{name: "stopping, then detached", state: types.InstanceStateNameStopping, volumes: []func() (*ec2.DescribeVolumesOutput, error){attached, attached, attached, available}},
+		{name: "stopping until the timeout", state: types.InstanceStateNameStopping, volumes: []func() (*ec2.DescribeVolumesOutput, error){attached}, wantErr: true},
+		{name: "running but impaired until the timeout", state: types.InstanceStateNameRunning, impaired: true, volumes: []func() (*ec2.DescribeVolumesOutput, error){attached}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &instanceEC2{state: tt.state, impaired: tt.impaired}
+			api.volumes = tt.volumes
+			clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			c := NewEBSClientFromAPI(api, clk, "us-east-1")
+
+			err := runWithFakeClock(t, clk, time.Second, func() error {
+				_, err := c.WaitForVolumeDetach(context.Background(), "vol-1", WaitForVolumeDetachConfig{ForceDetach: true, Timeout: 10 * time.Minute})
+				return err
+			})
+			if api.forced != tt.wantForced {
+				t.Errorf("force-detached = %v, want %v", api.forced, tt.wantForced)
+			}
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("WaitForVolumeDetach() error = %v", err)
+				}
+				return
+			}
+			var waitErr *DetachWaitError
+			if !errors.As(err, &waitErr) || !strings.Contains(waitErr.Reason, "timed out") || !strings.Contains(waitErr.Reason, "may still write to the volume") {
+				t.Fatalf("WaitForVolumeDetach() error = %v, want a DetachWaitError at the timeout naming the instance", err)
+			}
+			if len(waitErr.Remaining) != 1 || waitErr.Progress.Elapsed() < 9*time.Minute {
+				t.Errorf("error = %+v, want it raised at the timeout with the attachment remaining", waitErr)
+			}
+		})
+	}
+}
+
+func TestInstanceUnavailableError(t *testing.T) {
+	err := &InstanceUnavailableError{
+		VolumeID: "vol-1",
+		Instance: InstanceHealth{InstanceID: "i-1", State: types.InstanceStateNameStopped},
+	}
+	want := "volume vol-1 is attached to instance i-1 which is stopped and will not release it; enable force-detach or recover the instance"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,270 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// ebsKeyActions are the KMS actions a principal needs to attach a volume encrypted with a key
+var ebsKeyActions = []string{"kms:Decrypt", "kms:CreateGrant"}
+
+// KeyGrantee identifies who must be able to use a volume's KMS key
+type KeyGrantee struct {
+	// AccountID is the AWS account that will attach the volume (optional)
+	AccountID string
+
+	// RoleARN is the IAM role that will attach the volume (optional)
+	RoleARN string
+}
+
+// KeyAccessError is returned when a grantee cannot use a volume's KMS key
+type KeyAccessError struct {
+	KeyID   string
+	Grantee string
+	Reason  string
+}
+
+// Error implements the error interface
+func (e *KeyAccessError) Error() string {
+	return fmt.Sprintf("KMS key %s cannot be used by %s: %s", e.KeyID, e.Grantee, e.Reason)
+}
+
+// CheckKeyAccess verifies that the grantee can use a KMS key to attach an
+// encrypted volume. The key must be enabled and its key policy or grants must
+// allow kms:Decrypt and kms:CreateGrant for the role or its account. A policy
+// that allows the account root delegates to IAM, which is not evaluated here.
+// Policy conditions are not evaluated either.
+func (c *EBSClient) CheckKeyAccess(ctx context.Context, keyID string, grantee KeyGrantee) error {
+	desc, err := c.kmsClient.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: aws.String(keyID)})
+	if err != nil {
+		return fmt.Errorf("failed to describe KMS key %s: %w", keyID, c.classifyError("DescribeKey", keyID, err))
+	}
+	meta := desc.KeyMetadata
+
+	principal := grantee.RoleARN
+	if principal == "" {
+		principal = "account " + grantee.AccountID
+	}
+
+	if meta.KeyState != kmstypes.KeyStateEnabled {
+		return &KeyAccessError{KeyID: keyID, Grantee: principal, Reason: fmt.Sprintf("key is %s", meta.KeyState)}
+	}
+
+	keyAccount := aws.ToString(meta.AWSAccountId)
+	accountID := grantee.AccountID
+	if accountID == "" {
+		accountID = accountFromARN(grantee.RoleARN)
+	}
+	if accountID == "" {
+		accountID = keyAccount
+	}
+
+	if meta.KeyManager == kmstypes.KeyManagerTypeAws {
+		if accountID != keyAccount {
+			return &KeyAccessError{KeyID: keyID, Grantee: principal,
+				Reason: "AWS managed keys cannot be shared with another account; copy the volume with a destination KMS key instead"}
+		}
+		// AWS managed keys allow every principal in the account that can use EBS
+		return nil
+	}
+
+	policy, err := c.kmsClient.GetKeyPolicy(ctx, &kms.GetKeyPolicyInput{
+		KeyId:      aws.String(keyID),
+		PolicyName: aws.String("default"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get policy for KMS key %s: %w", keyID, c.classifyError("GetKeyPolicy", keyID, err))
+	}
+
+	principals := granteePrincipals(c.Partition(), grantee.RoleARN, accountID)
+	allowed, err := keyPolicyAllows(aws.ToString(policy.Policy), principals, ebsKeyActions)
+	if err != nil {
+		return fmt.Errorf("failed to parse policy for KMS key %s: %w", keyID, err)
+	}
+	if allowed {
+		return nil
+	}
+
+	if grantee.RoleARN != "" {
+		granted, err := c.roleHasKeyGrant(ctx, keyID, grantee.RoleARN)
+		if err != nil {
+			return err
+		}
+		if granted {
+			return nil
+		}
+	}
+
+	return &KeyAccessError{KeyID: keyID, Grantee: principal,
+		Reason: "neither the key policy nor a grant allows kms:Decrypt and kms:CreateGrant"}
+}
+
+// roleHasKeyGrant reports whether a grant gives the role the operations needed to attach a volume
+func (c *EBSClient) roleHasKeyGrant(ctx context.Context, keyID, roleARN string) (bool, error) {
+	paginator := kms.NewListGrantsPaginator(c.kmsClient, &kms.ListGrantsInput{
+		KeyId:            aws.String(keyID),
+		GranteePrincipal: aws.String(roleARN),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return false, fmt.Errorf("failed to list grants for KMS key %s: %w", keyID, c.classifyError("ListGrants", keyID, err))
+		}
+		for _, grant := range page.Grants {
+			if grantAllows(grant.Operations) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// grantAllows reports whether a grant's operations include everything EBS needs
+func grantAllows(operations []kmstypes.GrantOperation) bool {
+	var decrypt, createGrant bool
+	for _, op := range operations {
+		switch op {
+		case kmstypes.GrantOperationDecrypt:
+			decrypt = true
+		case kmstypes.GrantOperationCreateGrant:
+			createGrant = true
+		}
+	}
+	return decrypt && createGrant
+}
+
+// granteePrincipals returns the policy principals that match a role and its
+// account, whose root ARN depends on the partition
+func granteePrincipals(partition, roleARN, accountID string) []string {
+	principals := []string{"*"}
+	if roleARN != "" {
+		principals = append(principals, roleARN)
+	}
+	if accountID != "" {
+		principals = append(principals, accountID, fmt.Sprintf("arn:%s:iam::%s:root", partition, accountID))
+	}
+	return principals
+}
+
+// accountFromARN returns the account ID of an ARN, or "" if it cannot be parsed
+func accountFromARN(s string) string {
+	parsed, err := arn.Parse(s)
+	if err != nil {
+		return ""
+	}
+	return parsed.AccountID
+}
+
+// keyPolicyDocument is the subset of an IAM policy document needed to check key access
+type keyPolicyDocument struct {
+	Statement []keyPolicyStatement `json:"Statement"`
+}
+
+// keyPolicyStatement is a single statement in a key policy
+type keyPolicyStatement struct {
+	Effect    string          `json:"Effect"`
+	Principal json.RawMessage `json:"Principal"`
+	Action    stringOrSlice   `json:"Action"`
+}
+
+// stringOrSlice decodes a policy field that may be a string or a list of strings
+type stringOrSlice []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *stringOrSlice) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = []string{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// awsPrincipals returns the AWS principals of a statement; "*" means everyone
+func (st keyPolicyStatement) awsPrincipals() ([]string, error) {
+	if len(st.Principal) == 0 {
+		return nil, nil
+	}
+	var wildcard string
+	if err := json.Unmarshal(st.Principal, &wildcard); err == nil {
+		return []string{wildcard}, nil
+	}
+	var principal struct {
+		AWS stringOrSlice `json:"AWS"`
+	}
+	if err := json.Unmarshal(st.Principal, &principal); err != nil {
+		return nil, err
+	}
+	return principal.AWS, nil
+}
+
+// keyPolicyAllows reports whether the policy allows every action for any of the
+// principals and no statement explicitly denies one of them
+func keyPolicyAllows(policy string, principals, actions []string) (bool, error) {
+	var doc keyPolicyDocument
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		return false, err
+	}
+
+	allowed := make(map[string]bool, len(actions))
+	for _, st := range doc.Statement {
+		stPrincipals, err := st.awsPrincipals()
+		if err != nil {
+			return false, err
+		}
+		if !principalMatches(stPrincipals, principals) {
+			continue
+		}
+		for _, action := range actions {
+			if !actionMatches(st.Action, action) {
+				continue
+			}
+			if strings.EqualFold(st.Effect, "Deny") {
+				return false, nil
+			}
+			allowed[action] = true
+		}
+	}
+
+	for _, action := range actions {
+		if !allowed[action] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// principalMatches reports whether any statement principal is one of the candidates
+func principalMatches(statement, candidates []string) bool {
+	for _, p := range statement {
+		for _, c := range candidates {
+			if p == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// actionMatches reports whether any of the statement's action patterns match the action
+func actionMatches(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(action)); ok {
+			return true
+		}
+	}
+	return false
+}
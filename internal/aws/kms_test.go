@@ -0,0 +1,120 @@
+package aws
+
+import (
+	"testing"
+
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+func TestKeyPolicyAllows(t *testing.T) {
+	role := "arn:aws:iam::222222222222:role/dest-nodes"
+	principals := granteePrincipals(PartitionAWS, role, "222222222222")
+
+	tests := []struct {
+		name   string
+		policy string
+		want   bool
+	}{
+		{
+			name: "role allowed explicitly",
+			policy: `{"Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::222222222222:role/dest-nodes"},
+				"Action":["kms:Decrypt","kms:CreateGrant","kms:DescribeKey"],"Resource":"*"}]}`,
+			want: true,
+		},
+		{
+			name: "account root with wildcard action",
+			policy: `{"Statement":[{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam::111111111111:root","arn:aws:iam::222222222222:root"]},
+				"Action":"kms:*","Resource":"*"}]}`,
+			want: true,
+		},
+		{
+			name: "actions split across statements",
+			policy: `{"Statement":[
+				{"Effect":"Allow","Principal":{"AWS":"222222222222"},"Action":"kms:Decrypt","Resource":"*"},
+				{"Effect":"Allow","Principal":{"AWS":"222222222222"},"Action":"kms:Create*","Resource":"*"}]}`,
+			want: true,
+		},
+		{
+			name: "missing CreateGrant",
+			policy: `{"Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::222222222222:role/dest-nodes"},
+				"Action":"kms:Decrypt","Resource":"*"}]}`,
+			want: false,
+		},
+		{
+			name: "other account only",
+			policy: `{"Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::111111111111:root"},
+				"Action":"kms:*","Resource":"*"}]}`,
+			want: false,
+		},
+		{
+			name: "explicit deny wins",
+			policy: `{"Statement":[
+				{"Effect":"Allow","Principal":"*","Action":"kms:*","Resource":"*"},
+				{"Effect":"Deny","Principal":{"AWS":"arn:aws:iam::222222222222:role/dest-nodes"},"Action":"kms:Decrypt","Resource":"*"}]}`,
+			want: false,
+		},
+		{
+			name:   "service principal only",
+			policy: `{"Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"kms:*","Resource":"*"}]}`,
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := keyPolicyAllows(tt.policy, principals, ebsKeyActions)
+			if err != nil {
+				t.Fatalf("keyPolicyAllows() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("keyPolicyAllows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyPolicyAllowsInvalid(t *testing.T) {
+	if _, err := keyPolicyAllows("not json", nil, ebsKeyActions); err == nil {
+		t.Error("keyPolicyAllows() expected error for invalid policy")
+	}
+}
+
+func TestGrantAllows(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations []kmstypes.GrantOperation
+		want       bool
+	}{
+		{
+			name:       "decrypt and create grant",
+			operations: []kmstypes.GrantOperation{kmstypes.GrantOperationDecrypt, kmstypes.GrantOperationCreateGrant},
+			want:       true,
+		},
+		{
+			name:       "decrypt only",
+			operations: []kmstypes.GrantOperation{kmstypes.GrantOperationDecrypt},
+			want:       false,
+		},
+		{
+			name: "no operations",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := grantAllows(tt.operations); got != tt.want {
+				t.Errorf("grantAllows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccountFromARN(t *testing.T) {
+	if got := accountFromARN("arn:aws:iam::222222222222:role/dest-nodes"); got != "222222222222" {
+		t.Errorf("accountFromARN() = %q", got)
+	}
+	if got := accountFromARN("not-an-arn"); got != "" {
+		t.Errorf("accountFromARN() = %q, want empty", got)
+	}
+}
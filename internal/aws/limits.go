@@ -0,0 +1,105 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// VolumeLimits are the size and performance bounds EC2 enforces for a volume
+// type. A zero bound means the type has no such setting.
+type VolumeLimits struct {
+	// MinSizeGiB and MaxSizeGiB bound the volume size
+	MinSizeGiB int32
+	MaxSizeGiB int32
+
+	// MinIOPS and MaxIOPS bound provisioned IOPS
+	MinIOPS int32
+	MaxIOPS int32
+
+	// MaxIOPSPerGiB caps provisioned IOPS relative to the volume size; MinIOPS
+	// is allowed at any size
+	MaxIOPSPerGiB int32
+
+	// MinThroughput and MaxThroughput bound provisioned throughput in MiB/s
+	MinThroughput int32
+	MaxThroughput int32
+
+	// MaxThroughputPerIOPS caps provisioned throughput relative to provisioned IOPS
+	MaxThroughputPerIOPS float64
+}
+
+// volumeLimits holds the limits of each EBS volume type. All io2 volumes are
+// Block Express volumes, which is what lifts io2 above io1's 16 TiB and
+// 64,000 IOPS.
+var volumeLimits = map[types.VolumeType]VolumeLimits{
+	types.VolumeTypeGp2: {MinSizeGiB: 1, MaxSizeGiB: 16384},
+	types.VolumeTypeGp3: {
+		MinSizeGiB: 1, MaxSizeGiB: 65536,
+		MinIOPS: 3000, MaxIOPS: 80000, MaxIOPSPerGiB: 500,
+		MinThroughput: 125, MaxThroughput: 2000, MaxThroughputPerIOPS: 0.25,
+	},
+	types.VolumeTypeIo1: {
+		MinSizeGiB: 4, MaxSizeGiB: 16384,
+		MinIOPS: 100, MaxIOPS: 64000, MaxIOPSPerGiB: 50,
+	},
+	types.VolumeTypeIo2: {
+		MinSizeGiB: 4, MaxSizeGiB: 65536,
+		MinIOPS: 100, MaxIOPS: 256000, MaxIOPSPerGiB: 1000,
+	},
+	types.VolumeTypeSt1:      {MinSizeGiB: 125, MaxSizeGiB: 16384},
+	types.VolumeTypeSc1:      {MinSizeGiB: 125, MaxSizeGiB: 16384},
+	types.VolumeTypeStandard: {MinSizeGiB: 1, MaxSizeGiB: 1024},
+}
+
+// CheckVolumeConfig returns an error listing every way a volume of cfg's type,
+// size and performance would be rejected by CreateVolume, so a migration can
+// fail before it starts instead of after the source has been snapshotted. A
+// size of 0, taken from the snapshot, is not checked.
+func CheckVolumeConfig(cfg CreateVolumeConfig) error {
+	volumeType := cfg.VolumeType
+	if volumeType == "" {
+		volumeType = types.VolumeTypeGp3
+	}
+	limits, ok := volumeLimits[volumeType]
+	if !ok {
+		return fmt.Errorf("unknown EBS volume type %q", volumeType)
+	}
+
+	var problems []string
+	if size := cfg.SizeGiB; size > 0 && (size < limits.MinSizeGiB || size > limits.MaxSizeGiB) {
+		problems = append(problems, fmt.Sprintf("size %d GiB is outside %s's %d-%d GiB", size, volumeType, limits.MinSizeGiB, limits.MaxSizeGiB))
+	}
+
+	if iops := cfg.IOPS; iops > 0 {
+		switch {
+		case limits.MaxIOPS == 0:
+			problems = append(problems, fmt.Sprintf("%s volumes do not take provisioned IOPS", volumeType))
+		case iops < limits.MinIOPS || iops > limits.MaxIOPS:
+			problems = append(problems, fmt.Sprintf("%d IOPS is outside %s's %d-%d", iops, volumeType, limits.MinIOPS, limits.MaxIOPS))
+		case cfg.SizeGiB > 0 && iops > max(limits.MinIOPS, limits.MaxIOPSPerGiB*cfg.SizeGiB):
+			problems = append(problems, fmt.Sprintf("%d IOPS exceeds %s's %d IOPS per GiB for %d GiB", iops, volumeType, limits.MaxIOPSPerGiB, cfg.SizeGiB))
+		}
+	}
+
+	if throughput := cfg.Throughput; throughput > 0 {
+		iops := cfg.IOPS
+		if iops == 0 {
+			iops = limits.MinIOPS
+		}
+		switch {
+		case limits.MaxThroughput == 0:
+			problems = append(problems, fmt.Sprintf("%s volumes do not take provisioned throughput", volumeType))
+		case throughput < limits.MinThroughput || throughput > limits.MaxThroughput:
+			problems = append(problems, fmt.Sprintf("%d MiB/s is outside %s's %d-%d MiB/s", throughput, volumeType, limits.MinThroughput, limits.MaxThroughput))
+		case float64(throughput) > limits.MaxThroughputPerIOPS*float64(iops):
+			problems = append(problems, fmt.Sprintf("%d MiB/s exceeds %s's %.2f MiB/s per IOPS for %d IOPS", throughput, volumeType, limits.MaxThroughputPerIOPS, iops))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid %s volume: %s", volumeType, strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestCheckVolumeConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CreateVolumeConfig
+		wantErr string
+	}{
+		{name: "default gp3", cfg: CreateVolumeConfig{SizeGiB: 100}},
+		{name: "size from snapshot", cfg: CreateVolumeConfig{VolumeType: types.VolumeTypeSt1}},
+		{name: "small gp3 at baseline", cfg: CreateVolumeConfig{SizeGiB: 1, IOPS: 3000, Throughput: 125}},
+		{name: "gp3 beyond 16 TiB", cfg: CreateVolumeConfig{SizeGiB: 20000}},
+		{name: "gp3 beyond 64 TiB", cfg: CreateVolumeConfig{SizeGiB: 70000}, wantErr: "size 70000 GiB is outside gp3's 1-65536 GiB"},
+		{name: "gp3 IOPS per GiB", cfg: CreateVolumeConfig{SizeGiB: 10, IOPS: 6000}, wantErr: "6000 IOPS exceeds gp3's 500 IOPS per GiB"},
+		{name: "gp3 throughput per IOPS", cfg: CreateVolumeConfig{SizeGiB: 100, IOPS: 3000, Throughput: 1000}, wantErr: "1000 MiB/s exceeds gp3's 0.25 MiB/s per IOPS"},
+		{name: "gp2 beyond 16 TiB", cfg: CreateVolumeConfig{VolumeType: types.VolumeTypeGp2, SizeGiB: 20000}, wantErr: "outside gp2's 1-16384 GiB"},
+		{name: "gp2 with IOPS", cfg: CreateVolumeConfig{VolumeType: types.VolumeTypeGp2, SizeGiB: 100, IOPS: 300}, wantErr: "gp2 volumes do not take provisioned IOPS"},
+		{name: "io1 beyond 16 TiB", cfg: CreateVolumeConfig{VolumeType: types.VolumeTypeIo1, SizeGiB: 20000, IOPS: 64000}, wantErr: "outside io1's 4-16384 GiB"},
+		{name: "io2 Block Express", cfg: CreateVolumeConfig{VolumeType: types.VolumeTypeIo2, SizeGiB: 20000, IOPS: 256000}},
+		{name: "io2 IOPS per GiB", cfg: CreateVolumeConfig{VolumeType: types.VolumeTypeIo2, SizeGiB: 100, IOPS: 200000}, wantErr: "exceeds io2's 1000 IOPS per GiB"},
+		{name: "io2 throughput", cfg: CreateVolumeConfig{VolumeType: types.VolumeTypeIo2, SizeGiB: 100, IOPS: 1000, Throughput: 500}, wantErr: "io2 volumes do not take provisioned throughput"},
+		{name: "st1 too small", cfg: CreateVolumeConfig{VolumeType: types.VolumeTypeSt1, SizeGiB: 100}, wantErr: "outside st1's 125-16384 GiB"},
+		{name: "unknown type", cfg: CreateVolumeConfig{VolumeType: "gp4", SizeGiB: 100}, wantErr: "unknown EBS volume type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckVolumeConfig(tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckVolumeConfig() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckVolumeConfig() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+const (
+	// VolumeLockTagKey is the EBS tag holding a volume's migration lock
+	VolumeLockTagKey = "aqua.io/migration-lock"
+
+	// DefaultVolumeLockTTL is how long a volume lock is honored without renewal
+	DefaultVolumeLockTTL = time.Hour
+
+	// DefaultVolumeLockSettleDelay is how long to wait before confirming a lock write
+	DefaultVolumeLockSettleDelay = 2 * time.Second
+)
+
+// VolumeLockConfig contains configuration for AcquireVolumeLock
+type VolumeLockConfig struct {
+	// Owner identifies the lock holder; it must be unique per controller and migration
+	Owner string
+
+	// TTL is how long the lock is valid without renewal (default: DefaultVolumeLockTTL)
+	TTL time.Duration
+
+	// SettleDelay is how long to wait after writing the lock tag before
+	// re-reading it to confirm ownership (default: DefaultVolumeLockSettleDelay)
+	SettleDelay time.Duration
+}
+
+// VolumeLockedError is returned when a volume is locked by another owner
+type VolumeLockedError struct {
+	VolumeID string
+	Owner    string
+	Expires  time.Time
+}
+
+// Error implements the error interface
+func (e *VolumeLockedError) Error() string {
+	return fmt.Sprintf("volume %s is locked by %s until %s", e.VolumeID, e.Owner, e.Expires.Format(time.RFC3339))
+}
+
+// AcquireVolumeLock takes (or renews) the migration lock on a volume.
+//
+// EC2 tags have no compare-and-swap, so the lock is written and then read back
+// after SettleDelay; when two owners race, the last write wins and the other
+// owner sees a VolumeLockedError on read-back. Expired locks may be taken over.
+func (c *EBSClient) AcquireVolumeLock(ctx context.Context, volumeID string, cfg VolumeLockConfig) error {
+	if cfg.Owner == "" {
+		return fmt.Errorf("volume lock owner must be set")
+	}
+	if cfg.TTL == 0 {
+		cfg.TTL = DefaultVolumeLockTTL
+	}
+	if cfg.SettleDelay == 0 {
+		cfg.SettleDelay = DefaultVolumeLockSettleDelay
+	}
+	info, err := c.GetVolumeInfo(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+	if err := checkVolumeLock(volumeID, info.Tags, cfg.Owner, c.clock.Now()); err != nil {
+		return err
+	}
+
+	expires := c.clock.Now().Add(cfg.TTL)
+	if _, err := c.ec2Client.CreateTags(ctx, &ec2.CreateTagsInput{
+		Resources: []string{volumeID},
+		Tags: []types.Tag{{
+			Key:   aws.String(VolumeLockTagKey),
+			Value: aws.String(FormatVolumeLock(cfg.Owner, expires)),
+		}},
+	}); err != nil {
+		return fmt.Errorf("failed to tag volume %s with lock: %w", volumeID, c.classifyError("CreateTags", volumeID, err))
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.clock.After(cfg.SettleDelay):
+	}
+
+	info, err = c.GetVolumeInfo(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+	return checkVolumeLock(volumeID, info.Tags, cfg.Owner, c.clock.Now())
+}
+
+// ReleaseVolumeLock removes the migration lock from a volume if owner holds it
+func (c *EBSClient) ReleaseVolumeLock(ctx context.Context, volumeID, owner string) error {
+	info, err := c.GetVolumeInfo(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+
+	value, ok := info.Tags[VolumeLockTagKey]
+	if !ok {
+		return nil
+	}
+	if holder, _, _ := ParseVolumeLock(value); holder != owner {
+		return nil
+	}
+
+	// Deleting with the exact value leaves a lock written concurrently by someone else intact
+	if _, err := c.ec2Client.DeleteTags(ctx, &ec2.DeleteTagsInput{
+		Resources: []string{volumeID},
+		Tags: []types.Tag{{
+			Key:   aws.String(VolumeLockTagKey),
+			Value: aws.String(value),
+		}},
+	}); err != nil {
+		return fmt.Errorf("failed to remove lock tag from volume %s: %w", volumeID, c.classifyError("DeleteTags", volumeID, err))
+	}
+	return nil
+}
+
+// checkVolumeLock returns a VolumeLockedError if the tags hold an unexpired lock for another owner
+func checkVolumeLock(volumeID string, tags map[string]string, owner string, now time.Time) error {
+	value, ok := tags[VolumeLockTagKey]
+	if !ok {
+		return nil
+	}
+	holder, expires, ok := ParseVolumeLock(value)
+	if !ok || holder == owner || !now.Before(expires) {
+		return nil
+	}
+	return &VolumeLockedError{VolumeID: volumeID, Owner: holder, Expires: expires}
+}
+
+// FormatVolumeLock encodes a lock tag value as "<owner>;<expiry RFC3339>"
+func FormatVolumeLock(owner string, expires time.Time) string {
+	return owner + ";" + expires.UTC().Format(time.RFC3339)
+}
+
+// ParseVolumeLock decodes a lock tag value written by FormatVolumeLock
+func ParseVolumeLock(value string) (string, time.Time, bool) {
+	idx := strings.LastIndex(value, ";")
+	if idx <= 0 {
+		return "", time.Time{}, false
+	}
+	expires, err := time.Parse(time.RFC3339, value[idx+1:])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return value[:idx], expires, true
+}
@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseVolumeLock(t *testing.T) {
+	expires := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		value     string
+		wantOwner string
+		wantOK    bool
+	}{
+		{name: "round trip", value: FormatVolumeLock("mgmt-a/uid-1", expires), wantOwner: "mgmt-a/uid-1", wantOK: true},
+		{name: "owner containing separator", value: FormatVolumeLock("a;b", expires), wantOwner: "a;b", wantOK: true},
+		{name: "missing expiry", value: "mgmt-a", wantOK: false},
+		{name: "bad expiry", value: "mgmt-a;tomorrow", wantOK: false},
+		{name: "empty owner", value: ";" + expires.Format(time.RFC3339), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, gotExpires, ok := ParseVolumeLock(tt.value)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseVolumeLock() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if owner != tt.wantOwner {
+				t.Errorf("ParseVolumeLock() owner = %q, want %q", owner, tt.wantOwner)
+			}
+			if !gotExpires.Equal(expires) {
+				t.Errorf("ParseVolumeLock() expires = %v, want %v", gotExpires, expires)
+			}
+		})
+	}
+}
+
+func TestCheckVolumeLock(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		tags       map[string]string
+		wantLocked bool
+	}{
+		{name: "no lock", tags: map[string]string{}, wantLocked: false},
+		{name: "held by us", tags: map[string]string{VolumeLockTagKey: FormatVolumeLock("me", now.Add(time.Hour))}, wantLocked: false},
+		{name: "held by other", tags: map[string]string{VolumeLockTagKey: FormatVolumeLock("other", now.Add(time.Hour))}, wantLocked: true},
+		{name: "expired lock", tags: map[string]string{VolumeLockTagKey: FormatVolumeLock("other", now.Add(-time.Minute))}, wantLocked: false},
+		{name: "unparseable lock", tags: map[string]string{VolumeLockTagKey: "garbage"}, wantLocked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkVolumeLock("vol-1", tt.tags, "me", now)
+			var locked *VolumeLockedError
+			if errors.As(err, &locked) != tt.wantLocked {
+				t.Errorf("checkVolumeLock() error = %v, wantLocked %v", err, tt.wantLocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// VolumeModification is the latest ModifyVolume request of an EBS volume
+type VolumeModification struct {
+	// VolumeID is the EBS volume ID
+	VolumeID string
+
+	// State is the modification state (modifying, optimizing, completed, failed)
+	State types.VolumeModificationState
+
+	// Progress is the completion percentage reported by EC2 (0-100)
+	Progress int64
+
+	// StartTime is when the modification was requested
+	StartTime time.Time
+
+	// StatusMessage explains a failed modification
+	StatusMessage string
+}
+
+// InProgress reports whether the modification still blocks detaching,
+// attaching and snapshotting the volume. An optimizing volume already has its
+// new configuration and is used normally while EC2 finishes in the background.
+func (m *VolumeModification) InProgress() bool {
+	return m != nil && m.State == types.VolumeModificationStateModifying
+}
+
+// WaitForVolumeModificationConfig contains configuration for WaitForVolumeModification
+type WaitForVolumeModificationConfig struct {
+	// PollInterval is how often to check the modification (default: 15s)
+	PollInterval time.Duration
+
+	// Timeout is the maximum time to wait (default: 30m)
+	Timeout time.Duration
+
+	// OnPoll is called each time the modification is polled (optional)
+	OnPoll func(mod *VolumeModification)
+}
+
+// GetVolumeModification returns the latest modification of an EBS volume, or
+// nil if the volume has never been modified
+func (c *EBSClient) GetVolumeModification(ctx context.Context, volumeID string) (*VolumeModification, error) {
+	resp, err := c.ec2Client.DescribeVolumesModifications(ctx, &ec2.DescribeVolumesModificationsInput{
+		VolumeIds: []string{volumeID},
+	})
+	if err != nil {
+		err = c.classifyError("DescribeVolumesModifications", volumeID, err)
+		// EC2 reports a volume that was never modified as a missing modification
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.Code == "InvalidVolumeModification.NotFound" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe modifications of volume %s: %w", volumeID, err)
+	}
+
+	var latest *types.VolumeModification
+	for i := range resp.VolumesModifications {
+		mod := &resp.VolumesModifications[i]
+		if latest == nil || aws.ToTime(mod.StartTime).After(aws.ToTime(latest.StartTime)) {
+			latest = mod
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	return &VolumeModification{
+		VolumeID:      aws.ToString(latest.VolumeId),
+		State:         latest.ModificationState,
+		Progress:      aws.ToInt64(latest.Progress),
+		StartTime:     aws.ToTime(latest.StartTime),
+		StatusMessage: aws.ToString(latest.StatusMessage),
+	}, nil
+}
+
+// WaitForVolumeModification blocks until no modification of the volume is in
+// progress. A failed modification leaves the volume as it was, so it ends the
+// wait as well.
+func (c *EBSClient) WaitForVolumeModification(ctx context.Context, volumeID string, cfg WaitForVolumeModificationConfig) error {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = 15 * time.Second
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Minute
+	}
+
+	timeout := c.clock.NewTimer(cfg.Timeout)
+	defer timeout.Stop()
+
+	ticker := c.clock.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	var mod *VolumeModification
+	polled := false
+	for {
+		// A throttled poll keeps the last known state and tries again on the next tick
+		next, err := c.GetVolumeModification(ctx, volumeID)
+		switch {
+		case err == nil:
+			mod, polled = next, true
+			if cfg.OnPoll != nil && mod != nil {
+				cfg.OnPoll(mod)
+			}
+			if !mod.InProgress() {
+				return nil
+			}
+		case !polled || !Retryable(err):
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C():
+			return fmt.Errorf("timeout waiting for modification of volume %s to finish (waited %v, %d%% done)", volumeID, cfg.Timeout, mod.Progress)
+		case <-ticker.C():
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func modificationResponse(state types.VolumeModificationState, progress int64) func() (*ec2.DescribeVolumesModificationsOutput, error) {
+	return func() (*ec2.DescribeVolumesModificationsOutput, error) {
+		return &ec2.DescribeVolumesModificationsOutput{VolumesModifications: []types.VolumeModification{
+			{
+				VolumeId:          aws.String("vol-1"),
+				ModificationState: types.VolumeModificationStateCompleted,
+				Progress:          aws.Int64(100),
+				StartTime:         aws.Time(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)),
+			},
+			{
+				VolumeId:          aws.String("vol-1"),
+				ModificationState: state,
+				Progress:          aws.Int64(progress),
+				StartTime:         aws.Time(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			},
+		}}, nil
+	}
+}
+
+func neverModified() (*ec2.DescribeVolumesModificationsOutput, error) {
+	return nil, &smithy.GenericAPIError{Code: "InvalidVolumeModification.NotFound"}
+}
+
+func TestGetVolumeModification(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     func() (*ec2.DescribeVolumesModificationsOutput, error)
+		wantNil      bool
+		wantState    types.VolumeModificationState
+		wantProgress bool
+		wantErr      bool
+	}{
+		{name: "never modified", response: neverModified, wantNil: true},
+		{name: "latest modification", response: modificationResponse(types.VolumeModificationStateModifying, 30),
+			wantState: types.VolumeModificationStateModifying, wantProgress: true},
+		{name: "optimizing", response: modificationResponse(types.VolumeModificationStateOptimizing, 60),
+			wantState: types.VolumeModificationStateOptimizing},
+		{name: "throttled", response: throttled[ec2.DescribeVolumesModificationsOutput](), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			c := NewEBSClientFromAPI(&fakeEC2{mods: []func() (*ec2.DescribeVolumesModificationsOutput, error){tt.response}}, clk, "us-east-1")
+			mod, err := c.GetVolumeModification(context.Background(), "vol-1")
+			if tt.wantErr {
+				if !Retryable(err) {
+					t.Errorf("GetVolumeModification() error = %v, want a throttling error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetVolumeModification() error = %v", err)
+			}
+			if tt.wantNil {
+				if mod != nil {
+					t.Errorf("GetVolumeModification() = %+v, want nil", mod)
+				}
+				return
+			}
+			if mod.State != tt.wantState {
+				t.Errorf("State = %s, want %s", mod.State, tt.wantState)
+			}
+			if mod.InProgress() != tt.wantProgress {
+				t.Errorf("InProgress() = %v, want %v", mod.InProgress(), tt.wantProgress)
+			}
+		})
+	}
+}
+
+func TestWaitForVolumeModification(t *testing.T) {
+	modifying := modificationResponse(types.VolumeModificationStateModifying, 30)
+
+	tests := []struct {
+		name      string
+		responses []func() (*ec2.DescribeVolumesModificationsOutput, error)
+		wantErr   string
+	}{
+		{name: "never modified", responses: []func() (*ec2.DescribeVolumesModificationsOutput, error){neverModified}},
+		{name: "reaches optimizing", responses: []func() (*ec2.DescribeVolumesModificationsOutput, error){
+			modifying, modifying, modificationResponse(types.VolumeModificationStateOptimizing, 50)}},
+		{name: "throttled poll keeps waiting", responses: []func() (*ec2.DescribeVolumesModificationsOutput, error){
+			modifying, throttled[ec2.DescribeVolumesModificationsOutput](), modificationResponse(types.VolumeModificationStateCompleted, 100)}},
+		{name: "failed modification ends the wait", responses: []func() (*ec2.DescribeVolumesModificationsOutput, error){
+			modifying, modificationResponse(types.VolumeModificationStateFailed, 0)}},
+		{name: "times out", responses: []func() (*ec2.DescribeVolumesModificationsOutput, error){modifying}, wantErr: "30% done"},
+		{name: "throttled initial call fails", responses: []func() (*ec2.DescribeVolumesModificationsOutput, error){
+			throttled[ec2.DescribeVolumesModificationsOutput]()}, wantErr: "failed to describe modifications"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			c := NewEBSClientFromAPI(&fakeEC2{mods: tt.responses}, clk, "us-east-1")
+
+			err := runWithFakeClock(t, clk, 5*time.Minute, func() error {
+				return c.WaitForVolumeModification(context.Background(), "vol-1", WaitForVolumeModificationConfig{})
+			})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("WaitForVolumeModification() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("WaitForVolumeModification() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// PutObjectOptions configures an S3 upload
+type PutObjectOptions struct {
+	// ContentType is the object's media type
+	ContentType string
+
+	// ServerSideEncryption is AES256 (the default) or aws:kms
+	ServerSideEncryption string
+
+	// SSEKMSKeyID is the key for aws:kms encryption; the bucket's default key is used when empty
+	SSEKMSKeyID string
+}
+
+// PutObject uploads body to an S3 bucket with server-side encryption, for
+// example to keep migration records outside both clusters
+func (c *EBSClient) PutObject(ctx context.Context, bucket, key string, body []byte, opts PutObjectOptions) error {
+	if c.awsCfg.Credentials == nil {
+		return fmt.Errorf("AWS credentials are not configured")
+	}
+
+	var s3Opts []func(*s3.Options)
+	if c.endpoint != "" {
+		s3Opts = append(s3Opts, func(o *s3.Options) {
+			o.BaseEndpoint = aws.String(c.endpoint)
+			o.UsePathStyle = true
+		})
+	}
+
+	in, err := putObjectInput(bucket, key, body, opts)
+	if err != nil {
+		return err
+	}
+
+	resource := fmt.Sprintf("s3://%s/%s", bucket, key)
+	if _, err := s3.NewFromConfig(c.awsCfg, s3Opts...).PutObject(ctx, in); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", resource, c.classifyError("PutObject", resource, err))
+	}
+	return nil
+}
+
+// putObjectInput builds the PutObject request, defaulting to SSE-S3 encryption
+func putObjectInput(bucket, key string, body []byte, opts PutObjectOptions) (*s3.PutObjectInput, error) {
+	in := &s3.PutObjectInput{
+		Bucket:               aws.String(bucket),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader(body),
+		ServerSideEncryption: s3types.ServerSideEncryptionAes256,
+	}
+	if opts.ContentType != "" {
+		in.ContentType = aws.String(opts.ContentType)
+	}
+
+	switch s3types.ServerSideEncryption(opts.ServerSideEncryption) {
+	case "", s3types.ServerSideEncryptionAes256:
+		if opts.SSEKMSKeyID != "" {
+			return nil, fmt.Errorf("an SSE KMS key requires %s encryption", s3types.ServerSideEncryptionAwsKms)
+		}
+	case s3types.ServerSideEncryptionAwsKms:
+		in.ServerSideEncryption = s3types.ServerSideEncryptionAwsKms
+		if opts.SSEKMSKeyID != "" {
+			in.SSEKMSKeyId = aws.String(opts.SSEKMSKeyID)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported server-side encryption %q, want %s or %s",
+			opts.ServerSideEncryption, s3types.ServerSideEncryptionAes256, s3types.ServerSideEncryptionAwsKms)
+	}
+	return in, nil
+}
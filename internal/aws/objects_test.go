@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestPutObjectInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    PutObjectOptions
+		wantSSE s3types.ServerSideEncryption
+		wantKey string
+		wantErr bool
+	}{
+		{name: "defaults to SSE-S3", wantSSE: s3types.ServerSideEncryptionAes256},
+		{name: "KMS with the bucket key", opts: PutObjectOptions{ServerSideEncryption: "aws:kms"}, wantSSE: s3types.ServerSideEncryptionAwsKms},
+		{name: "KMS with a key", opts: PutObjectOptions{ServerSideEncryption: "aws:kms", SSEKMSKeyID: "alias/archive"}, wantSSE: s3types.ServerSideEncryptionAwsKms, wantKey: "alias/archive"},
+		{name: "KMS key without KMS encryption", opts: PutObjectOptions{SSEKMSKeyID: "alias/archive"}, wantErr: true},
+		{name: "unknown encryption", opts: PutObjectOptions{ServerSideEncryption: "none"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := putObjectInput("bucket", "key", nil, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("putObjectInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if in.ServerSideEncryption != tt.wantSSE {
+				t.Errorf("ServerSideEncryption = %q, want %q", in.ServerSideEncryption, tt.wantSSE)
+			}
+			if got := aws.ToString(in.SSEKMSKeyId); got != tt.wantKey {
+				t.Errorf("SSEKMSKeyId = %q, want %q", got, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestPutObject(t *testing.T) {
+	var gotPath, gotSSE, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotSSE, gotBody = r.URL.Path, r.Header.Get("X-Amz-Server-Side-Encryption"), string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := &EBSClient{
+		awsCfg: aws.Config{
+			Region:      "us-east-1",
+			Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		},
+		endpoint: srv.URL,
+		region:   "us-east-1",
+	}
+	err := c.PutObject(context.Background(), "archive", "ops/web/source/statefulset.yaml", []byte("kind: StatefulSet\n"), PutObjectOptions{ContentType: "application/yaml"})
+	if err != nil {
+		t.Fatalf("PutObject() error = %v", err)
+	}
+	if gotPath != "/archive/ops/web/source/statefulset.yaml" {
+		t.Errorf("path = %q, want the path-style bucket and key", gotPath)
+	}
+	if gotSSE != "AES256" {
+		t.Errorf("server-side encryption = %q, want AES256", gotSSE)
+	}
+	if !strings.Contains(gotBody, "kind: StatefulSet") {
+		t.Errorf("body = %q, want the manifest", gotBody)
+	}
+}
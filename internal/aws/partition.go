@@ -0,0 +1,40 @@
+package aws
+
+import "strings"
+
+// Partitions group regions that share endpoints, ARNs and IAM identities.
+// Accounts, roles and snapshots cannot cross a partition.
+const (
+	PartitionAWS      = "aws"
+	PartitionAWSCN    = "aws-cn"
+	PartitionAWSUSGov = "aws-us-gov"
+	PartitionAWSISO   = "aws-iso"
+	PartitionAWSISOB  = "aws-iso-b"
+)
+
+// partitions lists the regions outside the aws partition by name prefix
+var partitions = []struct {
+	regionPrefix string
+	partition    string
+}{
+	{"cn-", PartitionAWSCN},
+	{"us-gov-", PartitionAWSUSGov},
+	{"us-isob-", PartitionAWSISOB},
+	{"us-iso-", PartitionAWSISO},
+}
+
+// PartitionForRegion returns the partition of a region, such as aws-us-gov
+// for us-gov-west-1. Unknown regions are in the aws partition.
+func PartitionForRegion(region string) string {
+	for _, p := range partitions {
+		if strings.HasPrefix(region, p.regionPrefix) {
+			return p.partition
+		}
+	}
+	return PartitionAWS
+}
+
+// Partition returns the partition of the client's region, for building ARNs
+func (c *EBSClient) Partition() string {
+	return PartitionForRegion(c.region)
+}
@@ -0,0 +1,31 @@
+package aws
+
+import "testing"
+
+func TestPartitionForRegion(t *testing.T) {
+	tests := []struct {
+		region string
+		want   string
+	}{
+		{region: "us-east-1", want: PartitionAWS},
+		{region: "eu-west-1", want: PartitionAWS},
+		{region: "", want: PartitionAWS},
+		{region: "us-gov-west-1", want: PartitionAWSUSGov},
+		{region: "cn-northwest-1", want: PartitionAWSCN},
+		{region: "us-iso-east-1", want: PartitionAWSISO},
+		{region: "us-isob-east-1", want: PartitionAWSISOB},
+	}
+	for _, tt := range tests {
+		if got := PartitionForRegion(tt.region); got != tt.want {
+			t.Errorf("PartitionForRegion(%q) = %q, want %q", tt.region, got, tt.want)
+		}
+	}
+}
+
+func TestGranteePrincipalsPartition(t *testing.T) {
+	got := granteePrincipals(PartitionAWSUSGov, "", "222222222222")
+	want := "arn:aws-us-gov:iam::222222222222:root"
+	if got[len(got)-1] != want {
+		t.Errorf("granteePrincipals() = %v, want the account root %s", got, want)
+	}
+}
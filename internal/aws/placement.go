@@ -0,0 +1,85 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// ZoneType is the kind of zone a volume lives in, as DescribeAvailabilityZones reports it
+type ZoneType string
+
+const (
+	// ZoneTypeAvailabilityZone is a regular availability zone
+	ZoneTypeAvailabilityZone ZoneType = "availability-zone"
+
+	// ZoneTypeLocalZone is a Local Zone, an extension of a region in a metro area
+	ZoneTypeLocalZone ZoneType = "local-zone"
+
+	// ZoneTypeWavelengthZone is a Wavelength Zone inside a telecom carrier's network
+	ZoneTypeWavelengthZone ZoneType = "wavelength-zone"
+)
+
+// String returns the zone type as it is written in prose, e.g. "Local Zone"
+func (t ZoneType) String() string {
+	switch t {
+	case ZoneTypeAvailabilityZone:
+		return "availability zone"
+	case ZoneTypeLocalZone:
+		return "Local Zone"
+	case ZoneTypeWavelengthZone:
+		return "Wavelength Zone"
+	}
+	return string(t)
+}
+
+// ZoneInfo describes the zone a volume lives in
+type ZoneInfo struct {
+	// Name is the zone name, e.g. us-west-2-lax-1a
+	Name string
+
+	// Type is the kind of zone
+	Type ZoneType
+
+	// ParentZone is the availability zone a Local or Wavelength Zone hangs off
+	ParentZone string
+}
+
+// GetZoneInfo describes a zone. Regular availability zone names are recognised
+// without an API call, since they make up nearly every migration.
+func (c *EBSClient) GetZoneInfo(ctx context.Context, zone string) (*ZoneInfo, error) {
+	if region := RegionFromZone(zone); region != "" && len(zone) == len(region)+1 {
+		return &ZoneInfo{Name: zone, Type: ZoneTypeAvailabilityZone}, nil
+	}
+
+	resp, err := c.ec2Client.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{
+		ZoneNames:            []string{zone},
+		AllAvailabilityZones: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe zone %s: %w", zone, c.classifyError("DescribeAvailabilityZones", zone, err))
+	}
+	if len(resp.AvailabilityZones) == 0 {
+		return nil, &APIError{Op: "DescribeAvailabilityZones", Resource: zone, Region: c.region, Kind: ErrNotFound,
+			Err: fmt.Errorf("zone %s not found", zone)}
+	}
+
+	az := resp.AvailabilityZones[0]
+	return &ZoneInfo{
+		Name:       aws.ToString(az.ZoneName),
+		Type:       ZoneType(aws.ToString(az.ZoneType)),
+		ParentZone: aws.ToString(az.ParentZoneName),
+	}, nil
+}
+
+// OutpostID returns the Outpost ID (op-...) in an Outpost ARN, which is how
+// the EBS CSI driver labels the nodes running on it
+func OutpostID(outpostARN string) string {
+	if i := strings.LastIndex(outpostARN, "/"); i >= 0 {
+		return outpostARN[i+1:]
+	}
+	return outpostARN
+}
@@ -0,0 +1,90 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// zonesEC2 answers DescribeAvailabilityZones from a fixed list and counts the calls
+type zonesEC2 struct {
+	fakeEC2
+	zones []types.AvailabilityZone
+	calls int
+}
+
+func (f *zonesEC2) DescribeAvailabilityZones(_ context.Context, in *ec2.DescribeAvailabilityZonesInput, _ ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	f.calls++
+	out := &ec2.DescribeAvailabilityZonesOutput{}
+	for _, zone := range f.zones {
+		for _, name := range in.ZoneNames {
+			if aws.ToString(zone.ZoneName) == name {
+				out.AvailabilityZones = append(out.AvailabilityZones, zone)
+			}
+		}
+	}
+	return out, nil
+}
+
+func TestGetZoneInfo(t *testing.T) {
+	api := &zonesEC2{zones: []types.AvailabilityZone{
+		{ZoneName: aws.String("us-west-2-lax-1a"), ZoneType: aws.String("local-zone"), ParentZoneName: aws.String("us-west-2a")},
+		{ZoneName: aws.String("us-west-2-wl1-las-wlz-1"), ZoneType: aws.String("wavelength-zone"), ParentZoneName: aws.String("us-west-2b")},
+	}}
+	c := NewEBSClientFromAPI(api, clocktesting.NewFakeClock(time.Now()), "us-west-2")
+
+	tests := []struct {
+		zone       string
+		want       ZoneType
+		wantParent string
+		wantErr    bool
+	}{
+		{zone: "us-west-2a", want: ZoneTypeAvailabilityZone},
+		{zone: "us-west-2-lax-1a", want: ZoneTypeLocalZone, wantParent: "us-west-2a"},
+		{zone: "us-west-2-wl1-las-wlz-1", want: ZoneTypeWavelengthZone, wantParent: "us-west-2b"},
+		{zone: "us-west-2-den-1a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.zone, func(t *testing.T) {
+			info, err := c.GetZoneInfo(context.Background(), tt.zone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetZoneInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if info.Type != tt.want || info.ParentZone != tt.wantParent {
+				t.Errorf("GetZoneInfo() = %+v, want type %s and parent %q", info, tt.want, tt.wantParent)
+			}
+		})
+	}
+
+	if api.calls != 3 {
+		t.Errorf("got %d DescribeAvailabilityZones calls, want 3 (none for the regular zone)", api.calls)
+	}
+}
+
+func TestOutpostID(t *testing.T) {
+	tests := []struct {
+		arn  string
+		want string
+	}{
+		{arn: "arn:aws:outposts:us-west-2:111111111111:outpost/op-0123456789abcdef0", want: "op-0123456789abcdef0"},
+		{arn: "op-0123456789abcdef0", want: "op-0123456789abcdef0"},
+		{arn: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := OutpostID(tt.arn); got != tt.want {
+				t.Errorf("OutpostID(%q) = %q, want %q", tt.arn, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+)
+
+const (
+	// QuotaSnapshotsPerRegion is the Service Quotas name of the EBS snapshot count limit
+	QuotaSnapshotsPerRegion = "Snapshots per Region"
+
+	// QuotaConcurrentSnapshotCopies is the Service Quotas name of the concurrent snapshot copy limit
+	QuotaConcurrentSnapshotCopies = "Concurrent snapshot copies per destination Region"
+)
+
+// storageQuotaName returns the Service Quotas name of the storage limit for a volume type,
+// e.g. "Storage for General Purpose SSD (gp3) volumes, in TiB"
+func storageQuotaName(quotas map[string]float64, volumeType types.VolumeType) (string, bool) {
+	suffix := fmt.Sprintf("(%s) volumes, in TiB", volumeType)
+	for name := range quotas {
+		if strings.HasPrefix(name, "Storage for ") && strings.HasSuffix(name, suffix) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// GetEBSQuotas returns the account's applied EBS quotas keyed by quota name
+func (c *EBSClient) GetEBSQuotas(ctx context.Context) (map[string]float64, error) {
+	quotas := make(map[string]float64)
+	pages := servicequotas.NewListServiceQuotasPaginator(c.quotasClient, &servicequotas.ListServiceQuotasInput{
+		ServiceCode: aws.String("ebs"),
+		MaxResults:  aws.Int32(100),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list EBS service quotas: %w", c.classifyError("ListServiceQuotas", "", err))
+		}
+		for _, q := range page.Quotas {
+			quotas[aws.ToString(q.QuotaName)] = aws.ToFloat64(q.Value)
+		}
+	}
+	return quotas, nil
+}
+
+// EBSUsage is the account's current use of the quotas a migration consumes
+type EBSUsage struct {
+	// Snapshots is the number of snapshots owned by the account in the region
+	Snapshots int
+
+	// StorageGiB is the provisioned volume storage per volume type
+	StorageGiB map[types.VolumeType]int64
+}
+
+// GetEBSUsage counts the account's snapshots and provisioned storage for the given volume types
+func (c *EBSClient) GetEBSUsage(ctx context.Context, volumeTypes []types.VolumeType) (*EBSUsage, error) {
+	usage := &EBSUsage{StorageGiB: make(map[types.VolumeType]int64)}
+
+	snapshots := ec2.NewDescribeSnapshotsPaginator(c.ec2Client, &ec2.DescribeSnapshotsInput{
+		OwnerIds: []string{"self"},
+	})
+	for snapshots.HasMorePages() {
+		page, err := snapshots.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count snapshots: %w", c.classifyError("DescribeSnapshots", "", err))
+		}
+		usage.Snapshots += len(page.Snapshots)
+	}
+
+	for _, volumeType := range volumeTypes {
+		volumes := ec2.NewDescribeVolumesPaginator(c.ec2Client, &ec2.DescribeVolumesInput{
+			Filters: []types.Filter{{Name: aws.String("volume-type"), Values: []string{string(volumeType)}}},
+		})
+		for volumes.HasMorePages() {
+			page, err := volumes.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to sum %s storage: %w", volumeType, c.classifyError("DescribeVolumes", "", err))
+			}
+			for _, vol := range page.Volumes {
+				usage.StorageGiB[volumeType] += int64(aws.ToInt32(vol.Size))
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// QuotaPlan is what a migration strategy will consume from the account's EBS quotas
+type QuotaPlan struct {
+	// Snapshots is the number of snapshots the migration creates
+	Snapshots int
+
+	// ConcurrentCopies is the number of snapshot copies the migration runs at once
+	ConcurrentCopies int
+
+	// NewVolumeGiB is the storage of new volumes the migration creates, per volume type
+	NewVolumeGiB map[types.VolumeType]int64
+}
+
+// Empty reports whether the plan consumes no AWS quota, as with reattaching volumes
+func (p QuotaPlan) Empty() bool {
+	return p.Snapshots == 0 && p.ConcurrentCopies == 0 && len(p.NewVolumeGiB) == 0
+}
+
+// VolumeTypes returns the types of the new volumes, sorted
+func (p QuotaPlan) VolumeTypes() []types.VolumeType {
+	volumeTypes := make([]types.VolumeType, 0, len(p.NewVolumeGiB))
+	for volumeType := range p.NewVolumeGiB {
+		volumeTypes = append(volumeTypes, volumeType)
+	}
+	sort.Slice(volumeTypes, func(i, j int) bool { return volumeTypes[i] < volumeTypes[j] })
+	return volumeTypes
+}
+
+// CheckQuotas returns one problem, with remediation advice, for each quota the
+// plan would exceed. Quotas missing from the map are not checked.
+func CheckQuotas(plan QuotaPlan, quotas map[string]float64, usage EBSUsage) []string {
+	var problems []string
+
+	if limit, ok := quotas[QuotaSnapshotsPerRegion]; ok && plan.Snapshots > 0 {
+		if total := usage.Snapshots + plan.Snapshots; float64(total) > limit {
+			problems = append(problems, fmt.Sprintf(
+				"%s: %d existing + %d new snapshots exceeds the quota of %.0f; delete unused snapshots or request an increase in Service Quotas",
+				QuotaSnapshotsPerRegion, usage.Snapshots, plan.Snapshots, limit))
+		}
+	}
+
+	if limit, ok := quotas[QuotaConcurrentSnapshotCopies]; ok && float64(plan.ConcurrentCopies) > limit {
+		problems = append(problems, fmt.Sprintf(
+			"%s: %d concurrent copies exceeds the quota of %.0f; lower the migration's parallelism or request an increase in Service Quotas",
+			QuotaConcurrentSnapshotCopies, plan.ConcurrentCopies, limit))
+	}
+
+	for _, volumeType := range plan.VolumeTypes() {
+		name, ok := storageQuotaName(quotas, volumeType)
+		if !ok {
+			continue
+		}
+		limitGiB := quotas[name] * 1024
+		needed := plan.NewVolumeGiB[volumeType]
+		if total := usage.StorageGiB[volumeType] + needed; float64(total) > limitGiB {
+			problems = append(problems, fmt.Sprintf(
+				"%s: %d GiB provisioned + %d GiB new exceeds the quota of %.0f TiB; request an increase in Service Quotas or map to another volume type",
+				name, usage.StorageGiB[volumeType], needed, quotas[name]))
+		}
+	}
+
+	return problems
+}
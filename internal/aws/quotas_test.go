@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestCheckQuotas(t *testing.T) {
+	quotas := map[string]float64{
+		QuotaSnapshotsPerRegion:                                 100000,
+		QuotaConcurrentSnapshotCopies:                           20,
+		"Storage for General Purpose SSD (gp3) volumes, in TiB": 50,
+		"Storage for General Purpose SSD (gp2) volumes, in TiB": 50,
+	}
+
+	tests := []struct {
+		name         string
+		plan         QuotaPlan
+		usage        EBSUsage
+		wantProblems []string
+	}{
+		{
+			name:  "reattach plan consumes nothing",
+			plan:  QuotaPlan{},
+			usage: EBSUsage{Snapshots: 100000},
+		},
+		{
+			name:  "within every quota",
+			plan:  QuotaPlan{Snapshots: 200, ConcurrentCopies: 5, NewVolumeGiB: map[types.VolumeType]int64{types.VolumeTypeGp3: 2000}},
+			usage: EBSUsage{Snapshots: 500, StorageGiB: map[types.VolumeType]int64{types.VolumeTypeGp3: 10000}},
+		},
+		{
+			name:         "snapshot count exceeded",
+			plan:         QuotaPlan{Snapshots: 200},
+			usage:        EBSUsage{Snapshots: 99900},
+			wantProblems: []string{QuotaSnapshotsPerRegion},
+		},
+		{
+			name:         "too many concurrent copies",
+			plan:         QuotaPlan{ConcurrentCopies: 200},
+			wantProblems: []string{QuotaConcurrentSnapshotCopies},
+		},
+		{
+			name:         "gp3 storage exceeded",
+			plan:         QuotaPlan{NewVolumeGiB: map[types.VolumeType]int64{types.VolumeTypeGp3: 2000, types.VolumeTypeIo2: 5000}},
+			usage:        EBSUsage{StorageGiB: map[types.VolumeType]int64{types.VolumeTypeGp3: 50000}},
+			wantProblems: []string{"(gp3)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckQuotas(tt.plan, quotas, tt.usage)
+			if len(got) != len(tt.wantProblems) {
+				t.Fatalf("CheckQuotas() = %v, want %d problems", got, len(tt.wantProblems))
+			}
+			for i, want := range tt.wantProblems {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("problem %d = %q, want it to mention %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestQuotaPlanEmpty(t *testing.T) {
+	if !(QuotaPlan{}).Empty() {
+		t.Error("Empty() = false for zero plan")
+	}
+	if (QuotaPlan{Snapshots: 1}).Empty() {
+		t.Error("Empty() = true for plan with snapshots")
+	}
+}
@@ -0,0 +1,46 @@
+package aws
+
+import "sync"
+
+// regionPool caches the clients derived from one client for other regions,
+// so every migration in a region shares a client and its connection pool
+type regionPool struct {
+	mu        sync.Mutex
+	clients   map[string]*EBSClient
+	newClient func(region string) *EBSClient
+}
+
+// newRegionPool creates a pool holding c for its own region, deriving
+// clients for other regions with newClient
+func newRegionPool(c *EBSClient, newClient func(region string) *EBSClient) *regionPool {
+	return &regionPool{
+		clients:   map[string]*EBSClient{c.region: c},
+		newClient: newClient,
+	}
+}
+
+// ForRegion returns a client for region with the same credentials, endpoint,
+// clock and concurrency limits as c. Clients are created on first use and
+// reused afterwards. An empty region, or c's own region, returns c.
+func (c *EBSClient) ForRegion(region string) *EBSClient {
+	if region == "" || region == c.region || c.regions == nil {
+		return c
+	}
+
+	c.regions.mu.Lock()
+	defer c.regions.mu.Unlock()
+	if client, ok := c.regions.clients[region]; ok {
+		return client
+	}
+	client := c.regions.newClient(region)
+	// Share the pools so clients derived from this one are cached alongside it
+	client.regions = c.regions
+	client.roles = c.roles
+	c.regions.clients[region] = client
+	return client
+}
+
+// Region returns the AWS region the client sends requests to
+func (c *EBSClient) Region() string {
+	return c.region
+}
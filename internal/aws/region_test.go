@@ -0,0 +1,32 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestForRegion(t *testing.T) {
+	base := NewEBSClientFromConfig(aws.Config{Region: "us-east-1"})
+
+	if got := base.ForRegion(""); got != base {
+		t.Error("ForRegion(\"\") returned a new client, want the client itself")
+	}
+	if got := base.ForRegion("us-east-1"); got != base {
+		t.Error("ForRegion() of the client's own region returned a new client")
+	}
+
+	west := base.ForRegion("eu-west-1")
+	if west == base || west.Region() != "eu-west-1" || west.awsCfg.Region != "eu-west-1" {
+		t.Fatalf("ForRegion(eu-west-1) = region %q, want a client for eu-west-1", west.Region())
+	}
+	if base.awsCfg.Region != "us-east-1" {
+		t.Errorf("base client region changed to %q", base.awsCfg.Region)
+	}
+	if got := base.ForRegion("eu-west-1"); got != west {
+		t.Error("ForRegion() created a second client for eu-west-1, want it reused")
+	}
+	if got := west.ForRegion("us-east-1"); got != base {
+		t.Error("derived client's ForRegion() of the base region did not return the base client")
+	}
+}
@@ -0,0 +1,45 @@
+package aws
+
+import (
+	"sync"
+	"time"
+)
+
+// RoleCredentialsExpiryWindow is how long before they expire an assumed
+// role's credentials are refreshed, so a call made just before expiry, or a
+// long wait, does not run with credentials that lapse midway
+const RoleCredentialsExpiryWindow = 5 * time.Minute
+
+// roleKey identifies a client derived by AssumeRole
+type roleKey struct {
+	roleARN string
+	region  string
+}
+
+// rolePool caches the clients derived from one set of credentials by
+// AssumeRole, so every migration acting as a role in a region shares one
+// client and its credentials instead of calling STS on every reconcile
+type rolePool struct {
+	mu      sync.Mutex
+	clients map[roleKey]*EBSClient
+}
+
+func newRolePool() *rolePool {
+	return &rolePool{clients: map[roleKey]*EBSClient{}}
+}
+
+// get returns the client for key, creating it with newClient on first use.
+// A nil pool creates a client every time.
+func (p *rolePool) get(key roleKey, newClient func() *EBSClient) *EBSClient {
+	if p == nil {
+		return newClient()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if client, ok := p.clients[key]; ok {
+		return client
+	}
+	client := newClient()
+	p.clients[key] = client
+	return client
+}
@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestAssumeRoleCache(t *testing.T) {
+	const role = "arn:aws:iam::222222222222:role/migrator"
+	tests := []struct {
+		name      string
+		lifetime  time.Duration
+		wantCalls int32
+	}{
+		{name: "credentials are reused until they near expiry", lifetime: time.Hour, wantCalls: 1},
+		{name: "credentials within the expiry window are refreshed", lifetime: RoleCredentialsExpiryWindow / 2, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.Header().Set("Content-Type", "text/xml")
+				fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult>
+<Credentials><AccessKeyId>ASIAROLE</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>session</SessionToken>
+<Expiration>%s</Expiration></Credentials>
+<AssumedRoleUser><Arn>%s/aqua-service-controller</Arn><AssumedRoleId>AROA:aqua-service-controller</AssumedRoleId></AssumedRoleUser>
+</AssumeRoleResult></AssumeRoleResponse>`, time.Now().Add(tt.lifetime).UTC().Format(time.RFC3339), role)
+			}))
+			defer sts.Close()
+
+			base := newEBSClient(aws.Config{
+				Region:      "us-east-1",
+				Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider("AKIAEXAMPLE", "secret", "")),
+			}, sts.URL, clocktesting.NewFakeClock(time.Now()), nil)
+
+			for range 3 {
+				c, err := base.AssumeRole(role)
+				if err != nil {
+					t.Fatalf("AssumeRole() error = %v", err)
+				}
+				creds, err := c.awsCfg.Credentials.Retrieve(context.Background())
+				if err != nil || creds.AccessKeyID != "ASIAROLE" {
+					t.Fatalf("credentials = %q, %v, want the role's", creds.AccessKeyID, err)
+				}
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("AssumeRole calls to STS = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestAssumeRoleCacheKey(t *testing.T) {
+	base := NewEBSClientFromConfig(aws.Config{Region: "us-east-1", Credentials: aws.AnonymousCredentials{}})
+	role, other := "arn:aws:iam::222222222222:role/migrator", "arn:aws:iam::333333333333:role/migrator"
+
+	first, _ := base.AssumeRole(role)
+	if second, _ := base.AssumeRole(role); second != first {
+		t.Error("AssumeRole() created a second client for the same role, want it reused")
+	}
+	if got, _ := base.AssumeRole(other); got == first {
+		t.Error("AssumeRole() of another role returned the first role's client")
+	}
+
+	west := base.ForRegion("eu-west-1")
+	westRole, _ := west.AssumeRole(role)
+	if westRole == first || westRole.Region() != "eu-west-1" {
+		t.Errorf("AssumeRole() in eu-west-1 = region %q, want a new client for eu-west-1", westRole.Region())
+	}
+	if got, _ := base.ForRegion("eu-west-1").AssumeRole(role); got != westRole {
+		t.Error("AssumeRole() in eu-west-1 created a second client, want it reused")
+	}
+}
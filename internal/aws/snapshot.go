@@ -0,0 +1,170 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// SnapshotInfo contains information about an EBS snapshot
+type SnapshotInfo struct {
+	// SnapshotID is the EBS snapshot ID
+	SnapshotID string
+
+	// VolumeID is the volume the snapshot was taken from
+	VolumeID string
+
+	// State is the snapshot state (pending, completed, error, ...)
+	State types.SnapshotState
+
+	// Percent is the completion percentage reported by EC2 (0-100)
+	Percent int
+
+	// StartTime is when the snapshot was started
+	StartTime time.Time
+
+	// VolumeSize is the size of the source volume in GiB
+	VolumeSize int32
+
+	// StateMessage explains an error state
+	StateMessage string
+}
+
+// SnapshotProgress describes how far a snapshot has progressed
+type SnapshotProgress struct {
+	// Percent is the completion percentage (0-100)
+	Percent int
+
+	// Elapsed is the time since the snapshot started
+	Elapsed time.Duration
+
+	// ETA is the estimated time remaining; zero until progress has been reported
+	ETA time.Duration
+}
+
+// String returns a human-readable summary such as "45% (ETA 12m30s)"
+func (p SnapshotProgress) String() string {
+	if p.ETA == 0 {
+		return fmt.Sprintf("%d%%", p.Percent)
+	}
+	return fmt.Sprintf("%d%% (ETA %s)", p.Percent, p.ETA.Round(time.Second))
+}
+
+// WaitForSnapshotConfig contains configuration for WaitForSnapshotComplete
+type WaitForSnapshotConfig struct {
+	// PollInterval is how often to check the snapshot (default: 15s)
+	PollInterval time.Duration
+
+	// Timeout is the maximum time to wait (default: 2h)
+	Timeout time.Duration
+
+	// OnProgress is called each time the snapshot is polled (optional)
+	OnProgress func(info *SnapshotInfo, progress SnapshotProgress)
+}
+
+// GetSnapshotInfo retrieves information about an EBS snapshot
+func (c *EBSClient) GetSnapshotInfo(ctx context.Context, snapshotID string) (*SnapshotInfo, error) {
+	resp, err := c.ec2Client.DescribeSnapshots(ctx, &ec2.DescribeSnapshotsInput{
+		SnapshotIds: []string{snapshotID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe snapshot %s: %w", snapshotID, c.classifyError("DescribeSnapshots", snapshotID, err))
+	}
+
+	if len(resp.Snapshots) == 0 {
+		return nil, &APIError{Op: "DescribeSnapshots", Resource: snapshotID, Region: c.region, Kind: ErrNotFound,
+			Err: fmt.Errorf("snapshot %s not found", snapshotID)}
+	}
+
+	snap := resp.Snapshots[0]
+	return &SnapshotInfo{
+		SnapshotID:   aws.ToString(snap.SnapshotId),
+		VolumeID:     aws.ToString(snap.VolumeId),
+		State:        snap.State,
+		Percent:      parseSnapshotPercent(aws.ToString(snap.Progress)),
+		StartTime:    aws.ToTime(snap.StartTime),
+		VolumeSize:   aws.ToInt32(snap.VolumeSize),
+		StateMessage: aws.ToString(snap.StateMessage),
+	}, nil
+}
+
+// WaitForSnapshotComplete blocks until the snapshot is completed, reporting
+// progress and an ETA through cfg.OnProgress
+func (c *EBSClient) WaitForSnapshotComplete(ctx context.Context, snapshotID string, cfg WaitForSnapshotConfig) (*SnapshotInfo, error) {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = 15 * time.Second
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 2 * time.Hour
+	}
+
+	timeout := c.clock.NewTimer(cfg.Timeout)
+	defer timeout.Stop()
+
+	ticker := c.clock.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	var info *SnapshotInfo
+	for {
+		// A throttled poll keeps the last known state and tries again on the next tick
+		polled, err := c.GetSnapshotInfo(ctx, snapshotID)
+		switch {
+		case err == nil:
+			info = polled
+			if cfg.OnProgress != nil {
+				cfg.OnProgress(info, snapshotProgress(info, c.clock.Now()))
+			}
+			switch info.State {
+			case types.SnapshotStateCompleted:
+				return info, nil
+			case types.SnapshotStateError:
+				return nil, fmt.Errorf("snapshot %s failed: %s", snapshotID, info.StateMessage)
+			}
+		case info == nil || !Retryable(err):
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C():
+			return nil, fmt.Errorf("timeout waiting for snapshot %s to complete (waited %v, %d%% done)", snapshotID, cfg.Timeout, info.Percent)
+		case <-ticker.C():
+		}
+	}
+}
+
+// parseSnapshotPercent parses EC2's progress string (e.g. "45%"), returning 0 if it is malformed
+func parseSnapshotPercent(progress string) int {
+	percent, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(progress), "%"))
+	if err != nil || percent < 0 {
+		return 0
+	}
+	if percent > 100 {
+		return 100
+	}
+	return percent
+}
+
+// snapshotProgress estimates the time remaining by extrapolating the rate since
+// the snapshot started. Snapshot progress is not linear, so the ETA is a rough guide.
+func snapshotProgress(info *SnapshotInfo, now time.Time) SnapshotProgress {
+	progress := SnapshotProgress{Percent: info.Percent}
+	if !info.StartTime.IsZero() && now.After(info.StartTime) {
+		progress.Elapsed = now.Sub(info.StartTime)
+	}
+	if info.State == types.SnapshotStateCompleted {
+		progress.Percent = 100
+		return progress
+	}
+	if progress.Percent > 0 && progress.Percent < 100 && progress.Elapsed > 0 {
+		progress.ETA = time.Duration(float64(progress.Elapsed) * float64(100-progress.Percent) / float64(progress.Percent))
+	}
+	return progress
+}
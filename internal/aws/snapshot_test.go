@@ -0,0 +1,137 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestParseSnapshotPercent(t *testing.T) {
+	tests := []struct {
+		progress string
+		want     int
+	}{
+		{progress: "45%", want: 45},
+		{progress: "100%", want: 100},
+		{progress: "0%", want: 0},
+		{progress: " 7% ", want: 7},
+		{progress: "", want: 0},
+		{progress: "n/a", want: 0},
+		{progress: "150%", want: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.progress, func(t *testing.T) {
+			if got := parseSnapshotPercent(tt.progress); got != tt.want {
+				t.Errorf("parseSnapshotPercent(%q) = %d, want %d", tt.progress, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotProgress(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		info       *SnapshotInfo
+		now        time.Time
+		wantETA    time.Duration
+		wantString string
+	}{
+		{
+			name:       "no progress yet has no ETA",
+			info:       &SnapshotInfo{State: types.SnapshotStatePending, StartTime: start},
+			now:        start.Add(time.Minute),
+			wantString: "0%",
+		},
+		{
+			name:       "quarter done after 10m",
+			info:       &SnapshotInfo{State: types.SnapshotStatePending, Percent: 25, StartTime: start},
+			now:        start.Add(10 * time.Minute),
+			wantETA:    30 * time.Minute,
+			wantString: "25% (ETA 30m0s)",
+		},
+		{
+			name:       "completed",
+			info:       &SnapshotInfo{State: types.SnapshotStateCompleted, Percent: 99, StartTime: start},
+			now:        start.Add(time.Hour),
+			wantString: "100%",
+		},
+		{
+			name:       "unknown start time",
+			info:       &SnapshotInfo{State: types.SnapshotStatePending, Percent: 50},
+			now:        start,
+			wantString: "50%",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := snapshotProgress(tt.info, tt.now)
+			if got.ETA != tt.wantETA {
+				t.Errorf("ETA = %v, want %v", got.ETA, tt.wantETA)
+			}
+			if got.String() != tt.wantString {
+				t.Errorf("String() = %q, want %q", got.String(), tt.wantString)
+			}
+		})
+	}
+}
+
+func snapshotResponse(state types.SnapshotState, progress string) func() (*ec2.DescribeSnapshotsOutput, error) {
+	return func() (*ec2.DescribeSnapshotsOutput, error) {
+		return &ec2.DescribeSnapshotsOutput{Snapshots: []types.Snapshot{{
+			SnapshotId:   aws.String("snap-1"),
+			State:        state,
+			Progress:     aws.String(progress),
+			StateMessage: aws.String("internal error"),
+		}}}, nil
+	}
+}
+
+func TestWaitForSnapshotComplete(t *testing.T) {
+	pending := snapshotResponse(types.SnapshotStatePending, "40%")
+
+	tests := []struct {
+		name      string
+		responses []func() (*ec2.DescribeSnapshotsOutput, error)
+		wantErr   string
+	}{
+		{name: "completes", responses: []func() (*ec2.DescribeSnapshotsOutput, error){pending, pending, snapshotResponse(types.SnapshotStateCompleted, "100%")}},
+		{name: "throttled poll keeps waiting", responses: []func() (*ec2.DescribeSnapshotsOutput, error){pending, throttled[ec2.DescribeSnapshotsOutput](), snapshotResponse(types.SnapshotStateCompleted, "100%")}},
+		{name: "fails", responses: []func() (*ec2.DescribeSnapshotsOutput, error){pending, snapshotResponse(types.SnapshotStateError, "40%")}, wantErr: "internal error"},
+		{name: "times out", responses: []func() (*ec2.DescribeSnapshotsOutput, error){pending}, wantErr: "40% done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			c := NewEBSClientFromAPI(&fakeEC2{snapshots: tt.responses}, clk, "us-east-1")
+
+			err := runWithFakeClock(t, clk, 5*time.Minute, func() error {
+				_, err := c.WaitForSnapshotComplete(context.Background(), "snap-1", WaitForSnapshotConfig{})
+				return err
+			})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("WaitForSnapshotComplete() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("WaitForSnapshotComplete() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrThrottled) {
+				t.Errorf("error should not be classified as throttled: %v", err)
+			}
+		})
+	}
+}
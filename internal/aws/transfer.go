@@ -0,0 +1,155 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// RoleSessionName names the sessions of roles the controller assumes, so
+// CloudTrail attributes their calls to it
+const RoleSessionName = "aqua-service-controller"
+
+// WaitForVolumeAvailableConfig contains configuration for WaitForVolumeAvailable
+type WaitForVolumeAvailableConfig struct {
+	// PollInterval is how often to check the volume state (default: 5s)
+	PollInterval time.Duration
+
+	// Timeout is the maximum time to wait (default: 10m)
+	Timeout time.Duration
+}
+
+// AssumeRole returns a client in the same region that acts as an IAM role,
+// typically one in another account. The role's credentials are fetched from
+// STS on first use and refreshed RoleCredentialsExpiryWindow before they
+// expire. Clients are cached by role and region, so every caller of a role
+// shares its credentials. The client shares this client's concurrency limits.
+func (c *EBSClient) AssumeRole(roleARN string) (*EBSClient, error) {
+	if c.awsCfg.Credentials == nil {
+		return nil, fmt.Errorf("cannot assume role %s: the client has no AWS credentials", roleARN)
+	}
+
+	return c.roles.get(roleKey{roleARN: roleARN, region: c.region}, func() *EBSClient {
+		var stsOpts []func(*sts.Options)
+		if c.endpoint != "" {
+			stsOpts = append(stsOpts, func(o *sts.Options) {
+				o.BaseEndpoint = aws.String(c.endpoint)
+			})
+		}
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(c.awsCfg, stsOpts...), roleARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = RoleSessionName
+		})
+
+		roleCfg := c.awsCfg.Copy()
+		roleCfg.Credentials = aws.NewCredentialsCache(provider, func(o *aws.CredentialsCacheOptions) {
+			o.ExpiryWindow = RoleCredentialsExpiryWindow
+		})
+		return newEBSClient(roleCfg, c.endpoint, c.clock, c.limiter)
+	}), nil
+}
+
+// ProvisionedPerformance returns the IOPS and throughput to create a copy of
+// the volume with. Only io1, io2 and gp3 take provisioned IOPS, and only gp3
+// takes provisioned throughput; other types get their baseline.
+func (v *VolumeInfo) ProvisionedPerformance() (iops, throughput int32) {
+	switch v.VolumeType {
+	case types.VolumeTypeIo1, types.VolumeTypeIo2:
+		return v.IOPS, 0
+	case types.VolumeTypeGp3:
+		return v.IOPS, v.Throughput
+	}
+	return 0, 0
+}
+
+// ShareSnapshot lets another account create volumes from, and copy, a snapshot.
+// A snapshot encrypted with a customer managed key also needs that key shared
+// with the account; one encrypted with the aws/ebs key cannot be shared.
+func (c *EBSClient) ShareSnapshot(ctx context.Context, snapshotID, accountID string) error {
+	if _, err := c.ec2Client.ModifySnapshotAttribute(ctx, &ec2.ModifySnapshotAttributeInput{
+		SnapshotId: aws.String(snapshotID),
+		Attribute:  types.SnapshotAttributeNameCreateVolumePermission,
+		CreateVolumePermission: &types.CreateVolumePermissionModifications{
+			Add: []types.CreateVolumePermission{{UserId: aws.String(accountID)}},
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to share snapshot %s with account %s: %w", snapshotID, accountID, c.classifyError("ModifySnapshotAttribute", snapshotID, err))
+	}
+	return nil
+}
+
+// UnshareSnapshot withdraws another account's permission to use a snapshot
+func (c *EBSClient) UnshareSnapshot(ctx context.Context, snapshotID, accountID string) error {
+	if _, err := c.ec2Client.ModifySnapshotAttribute(ctx, &ec2.ModifySnapshotAttributeInput{
+		SnapshotId: aws.String(snapshotID),
+		Attribute:  types.SnapshotAttributeNameCreateVolumePermission,
+		CreateVolumePermission: &types.CreateVolumePermissionModifications{
+			Remove: []types.CreateVolumePermission{{UserId: aws.String(accountID)}},
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to unshare snapshot %s from account %s: %w", snapshotID, accountID, c.classifyError("ModifySnapshotAttribute", snapshotID, err))
+	}
+	return nil
+}
+
+// DeleteSnapshot deletes a snapshot; a snapshot that no longer exists is not an error
+func (c *EBSClient) DeleteSnapshot(ctx context.Context, snapshotID string) error {
+	if _, err := c.ec2Client.DeleteSnapshot(ctx, &ec2.DeleteSnapshotInput{
+		SnapshotId: aws.String(snapshotID),
+	}); err != nil {
+		err = c.classifyError("DeleteSnapshot", snapshotID, err)
+		if KindOf(err) == ErrNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to delete snapshot %s: %w", snapshotID, err)
+	}
+	return nil
+}
+
+// WaitForVolumeAvailable blocks until a new volume leaves the creating state
+// and can be attached
+func (c *EBSClient) WaitForVolumeAvailable(ctx context.Context, volumeID string, cfg WaitForVolumeAvailableConfig) error {
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Minute
+	}
+
+	timeout := c.clock.NewTimer(cfg.Timeout)
+	defer timeout.Stop()
+
+	ticker := c.clock.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	var state types.VolumeState
+	for {
+		// A throttled poll keeps the last known state and tries again on the next tick
+		info, err := c.GetVolumeInfo(ctx, volumeID)
+		switch {
+		case err == nil:
+			state = info.State
+			switch state {
+			case types.VolumeStateAvailable, types.VolumeStateInUse:
+				return nil
+			case types.VolumeStateError, types.VolumeStateDeleting, types.VolumeStateDeleted:
+				return fmt.Errorf("volume %s is %s", volumeID, state)
+			}
+		case state == "" || !Retryable(err):
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C():
+			return fmt.Errorf("timeout waiting for volume %s to become available (waited %v, %s)", volumeID, cfg.Timeout, state)
+		case <-ticker.C():
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+// transferEC2 records snapshot permission changes and answers DeleteSnapshot with err
+type transferEC2 struct {
+	fakeEC2
+	permissions []*ec2.ModifySnapshotAttributeInput
+	deleteErr   error
+}
+
+func (f *transferEC2) ModifySnapshotAttribute(_ context.Context, in *ec2.ModifySnapshotAttributeInput, _ ...func(*ec2.Options)) (*ec2.ModifySnapshotAttributeOutput, error) {
+	f.permissions = append(f.permissions, in)
+	return &ec2.ModifySnapshotAttributeOutput{}, nil
+}
+
+func (f *transferEC2) DeleteSnapshot(context.Context, *ec2.DeleteSnapshotInput, ...func(*ec2.Options)) (*ec2.DeleteSnapshotOutput, error) {
+	return &ec2.DeleteSnapshotOutput{}, f.deleteErr
+}
+
+func TestShareSnapshot(t *testing.T) {
+	api := &transferEC2{}
+	c := NewEBSClientFromAPI(api, clocktesting.NewFakeClock(time.Now()), "us-east-1")
+
+	if err := c.ShareSnapshot(context.Background(), "snap-1", "222222222222"); err != nil {
+		t.Fatalf("ShareSnapshot() error = %v", err)
+	}
+	if err := c.UnshareSnapshot(context.Background(), "snap-1", "222222222222"); err != nil {
+		t.Fatalf("UnshareSnapshot() error = %v", err)
+	}
+	if len(api.permissions) != 2 {
+		t.Fatalf("got %d ModifySnapshotAttribute calls, want 2", len(api.permissions))
+	}
+
+	share, unshare := api.permissions[0].CreateVolumePermission, api.permissions[1].CreateVolumePermission
+	if len(share.Add) != 1 || aws.ToString(share.Add[0].UserId) != "222222222222" || len(share.Remove) != 0 {
+		t.Errorf("ShareSnapshot() permission = %+v, want the account added", share)
+	}
+	if len(unshare.Remove) != 1 || aws.ToString(unshare.Remove[0].UserId) != "222222222222" || len(unshare.Add) != 0 {
+		t.Errorf("UnshareSnapshot() permission = %+v, want the account removed", unshare)
+	}
+}
+
+func TestDeleteSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{name: "deleted"},
+		{name: "already gone", err: &smithy.GenericAPIError{Code: "InvalidSnapshot.NotFound"}},
+		{name: "in use", err: &smithy.GenericAPIError{Code: "InvalidSnapshot.InUse"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewEBSClientFromAPI(&transferEC2{deleteErr: tt.err}, clocktesting.NewFakeClock(time.Now()), "us-east-1")
+			if err := c.DeleteSnapshot(context.Background(), "snap-1"); (err != nil) != tt.wantErr {
+				t.Errorf("DeleteSnapshot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWaitForVolumeAvailable(t *testing.T) {
+	creating := volumeResponse(types.VolumeStateCreating)
+	available := volumeResponse(types.VolumeStateAvailable)
+	failed := volumeResponse(types.VolumeStateError)
+
+	tests := []struct {
+		name      string
+		responses []func() (*ec2.DescribeVolumesOutput, error)
+		wantErr   bool
+	}{
+		{name: "already available", responses: []func() (*ec2.DescribeVolumesOutput, error){available}},
+		{name: "created after polls", responses: []func() (*ec2.DescribeVolumesOutput, error){creating, creating, available}},
+		{name: "throttled poll keeps waiting", responses: []func() (*ec2.DescribeVolumesOutput, error){creating, throttled[ec2.DescribeVolumesOutput](), available}},
+		{name: "creation fails", responses: []func() (*ec2.DescribeVolumesOutput, error){creating, failed}, wantErr: true},
+		{name: "times out", responses: []func() (*ec2.DescribeVolumesOutput, error){creating}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			c := NewEBSClientFromAPI(&fakeEC2{volumes: tt.responses}, clk, "us-east-1")
+
+			err := runWithFakeClock(t, clk, time.Minute, func() error {
+				return c.WaitForVolumeAvailable(context.Background(), "vol-1", WaitForVolumeAvailableConfig{
+					PollInterval: 5 * time.Second,
+					Timeout:      time.Hour,
+				})
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WaitForVolumeAvailable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAssumeRoleWithoutCredentials(t *testing.T) {
+	c := NewEBSClientFromAPI(&fakeEC2{}, clocktesting.NewFakeClock(time.Now()), "us-east-1")
+	if _, err := c.AssumeRole("arn:aws:iam::222222222222:role/migrator"); err == nil {
+		t.Error("AssumeRole() error = nil, want an error for a client without credentials")
+	}
+}
+
+func TestProvisionedPerformance(t *testing.T) {
+	tests := []struct {
+		name           string
+		volumeType     types.VolumeType
+		wantIOPS       int32
+		wantThroughput int32
+	}{
+		{name: "gp3 keeps IOPS and throughput", volumeType: types.VolumeTypeGp3, wantIOPS: 4000, wantThroughput: 250},
+		{name: "io2 keeps IOPS", volumeType: types.VolumeTypeIo2, wantIOPS: 4000},
+		{name: "gp2 uses its baseline", volumeType: types.VolumeTypeGp2},
+		{name: "st1 uses its baseline", volumeType: types.VolumeTypeSt1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &VolumeInfo{VolumeType: tt.volumeType, IOPS: 4000, Throughput: 250}
+			iops, throughput := info.ProvisionedPerformance()
+			if iops != tt.wantIOPS || throughput != tt.wantThroughput {
+				t.Errorf("ProvisionedPerformance() = (%d, %d), want (%d, %d)", iops, throughput, tt.wantIOPS, tt.wantThroughput)
+			}
+		})
+	}
+}
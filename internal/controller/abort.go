@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+const (
+	// AnnotationAbort set to "true" on a migration stops it before its next pod
+	AnnotationAbort = "migration.aqua.io/abort"
+
+	// ConditionAborted reports that an operator aborted the migration
+	ConditionAborted = "Aborted"
+)
+
+// abortRequested reports whether the migration is annotated to abort
+func abortRequested(m *migrationv1alpha1.StatefulSetMigration) bool {
+	return m.Annotations[AnnotationAbort] == "true"
+}
+
+// abortable reports whether a migration in phase can still be aborted. Once
+// every pod has moved there is nothing left to stop.
+func abortable(phase migrationv1alpha1.MigrationPhase) bool {
+	switch phase {
+	case migrationv1alpha1.PhasePending, migrationv1alpha1.PhaseQueued, migrationv1alpha1.PhasePreFlightChecks,
+		migrationv1alpha1.PhaseReplicatingResources, migrationv1alpha1.PhaseFreezingSource, migrationv1alpha1.PhaseMigratingPods:
+		return true
+	}
+	return false
+}
+
+// abortMigration stops the migration where it is. A reconcile migrates at
+// most one pod, so the migration is always between pods here. Like a failed
+// migration, one aborted after the source was frozen keeps its guard lease,
+// since the workload is split across the clusters.
+func (r *StatefulSetMigrationReconciler) abortMigration(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	summary := abortSummary(m)
+	logger.Info("Aborting migration", "phase", m.Status.Phase, "summary", summary)
+
+	r.releaseCaches(ctx, m)
+	r.stopImagePrePull(ctx, m)
+	r.capacity.stop(m)
+	if m.Status.FrozenTime == nil {
+		if err := r.releaseGuard(ctx, m); err != nil {
+			logger.Error(err, "Failed to release guard lease")
+		}
+	}
+
+	message := fmt.Sprintf("Aborted in %s: %s", m.Status.Phase, summary)
+	m.Status.Phase = migrationv1alpha1.PhaseAborted
+	r.recordHistory(m, StepPhase, "", migrationv1alpha1.HistoryResultFailed, message)
+	now := metav1.NewTime(r.clock().Now())
+	m.Status.CompletionTime = &now
+	r.setCondition(m, ConditionAborted, metav1.ConditionTrue, "AbortRequested", message)
+	r.setOrphanedPodsCondition(m)
+	r.publishReport(ctx, m)
+
+	if err := r.Status().Update(ctx, m); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// abortSummary says which pods moved, which are still in the source, and
+// which was interrupted
+func abortSummary(m *migrationv1alpha1.StatefulSetMigration) string {
+	if m.Status.FrozenTime == nil {
+		return "the source was not frozen and every pod is still in the source"
+	}
+
+	moved := make([]string, 0, len(m.Status.MigratedPods))
+	for _, p := range m.Status.MigratedPods {
+		moved = append(moved, p.PodName)
+	}
+	parts := []string{fmt.Sprintf("moved to the destination: %s", podList(moved))}
+	if pod := interruptedPod(m); pod != "" {
+		parts = append(parts, fmt.Sprintf("%s was deleted from the source but not started in the destination", pod))
+	}
+	parts = append(parts, fmt.Sprintf("not moved: %s", podList(podsNotMoved(m))))
+	return strings.Join(parts, "; ")
+}
+
+// podsNotMoved returns the pods the migration moves that are not in status.migratedPods
+func podsNotMoved(m *migrationv1alpha1.StatefulSetMigration) []string {
+	var pods []string
+	for _, index := range slices.Sorted(slices.Values(migratingOrdinals(m))) {
+		if !slices.ContainsFunc(m.Status.MigratedPods, func(p migrationv1alpha1.MigratedPodInfo) bool { return p.Index == index }) {
+			pods = append(pods, fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, index))
+		}
+	}
+	return pods
+}
+
+// interruptedPod returns the pod being migrated when the migration stopped if
+// its source pod was already deleted, leaving it running in neither cluster
+func interruptedPod(m *migrationv1alpha1.StatefulSetMigration) string {
+	index := ordinalAt(m, m.Status.CurrentIndex)
+	if m.Status.CurrentIndex >= m.Status.TotalReplicas ||
+		slices.ContainsFunc(m.Status.MigratedPods, func(p migrationv1alpha1.MigratedPodInfo) bool { return p.Index == index }) {
+		return ""
+	}
+	podName := fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, index)
+	object := historyObject("Pod", m.Spec.SourceNamespace, podName)
+	for _, entry := range m.Status.History {
+		if entry.Step == StepDeletePod && entry.Object == object && entry.Result == migrationv1alpha1.HistoryResultSucceeded {
+			return podName
+		}
+	}
+	return ""
+}
+
+// podList joins pod names for a message, or returns "none"
+func podList(pods []string) string {
+	if len(pods) == 0 {
+		return "none"
+	}
+	return strings.Join(pods, ", ")
+}
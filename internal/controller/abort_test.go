@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestReconcileAbort(t *testing.T) {
+	frozen := metav1.Now()
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		readOnly    bool
+		phase       migrationv1alpha1.MigrationPhase
+		wantPhase   migrationv1alpha1.MigrationPhase
+	}{
+		{name: "aborts pod migration", annotations: map[string]string{AnnotationAbort: "true"},
+			phase: migrationv1alpha1.PhaseMigratingPods, wantPhase: migrationv1alpha1.PhaseAborted},
+		{name: "abort overrides read-only", annotations: map[string]string{AnnotationAbort: "true"}, readOnly: true,
+			phase: migrationv1alpha1.PhaseFreezingSource, wantPhase: migrationv1alpha1.PhaseAborted},
+		{name: "failed migration is not aborted", annotations: map[string]string{AnnotationAbort: "true"},
+			phase: migrationv1alpha1.PhaseFailed, wantPhase: migrationv1alpha1.PhaseFailed},
+		{name: "annotation set to false", annotations: map[string]string{AnnotationAbort: "false"}, readOnly: true,
+			phase: migrationv1alpha1.PhaseMigratingPods, wantPhase: migrationv1alpha1.PhaseMigratingPods},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			m := &migrationv1alpha1.StatefulSetMigration{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "ops",
+					Name:        "web",
+					Finalizers:  []string{MigrationFinalizer},
+					Annotations: tt.annotations,
+				},
+				Spec: migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web"},
+			}
+			m.Status = migrationv1alpha1.StatefulSetMigrationStatus{
+				Phase:         tt.phase,
+				AppliedSpec:   m.Spec.DeepCopy(),
+				FrozenTime:    &frozen,
+				TotalReplicas: 2,
+				MigratedPods:  []migrationv1alpha1.MigratedPodInfo{{Index: 0, PodName: "web-0"}},
+				CurrentIndex:  1,
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+			r := &StatefulSetMigrationReconciler{Client: c, ClientManager: multicluster.NewClientManager(scheme, c), ReadOnly: tt.readOnly}
+
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ops", Name: "web"}}); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			got := &migrationv1alpha1.StatefulSetMigration{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "ops", Name: "web"}, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.Phase != tt.wantPhase {
+				t.Errorf("Phase = %s, want %s", got.Status.Phase, tt.wantPhase)
+			}
+			aborted := tt.wantPhase == migrationv1alpha1.PhaseAborted
+			if c := meta.FindStatusCondition(got.Status.Conditions, ConditionAborted); (c != nil) != aborted {
+				t.Errorf("Aborted condition = %+v, want present %v", c, aborted)
+			}
+			if aborted && got.Status.CompletionTime == nil {
+				t.Error("CompletionTime not set")
+			}
+		})
+	}
+}
+
+func TestAbortSummary(t *testing.T) {
+	frozen := metav1.Now()
+	deleted := migrationv1alpha1.HistoryEntry{Step: StepDeletePod, Object: "Pod/prod/web-1", Result: migrationv1alpha1.HistoryResultSucceeded}
+
+	tests := []struct {
+		name    string
+		status  migrationv1alpha1.StatefulSetMigrationStatus
+		want    string
+		notMove []string
+	}{
+		{
+			name:    "before freeze",
+			status:  migrationv1alpha1.StatefulSetMigrationStatus{TotalReplicas: 3},
+			want:    "the source was not frozen and every pod is still in the source",
+			notMove: []string{"web-0", "web-1", "web-2"},
+		},
+		{
+			name: "between pods",
+			status: migrationv1alpha1.StatefulSetMigrationStatus{
+				FrozenTime: &frozen, TotalReplicas: 3, CurrentIndex: 1,
+				MigratedPods: []migrationv1alpha1.MigratedPodInfo{{Index: 0, PodName: "web-0"}},
+			},
+			want:    "moved to the destination: web-0; not moved: web-1, web-2",
+			notMove: []string{"web-1", "web-2"},
+		},
+		{
+			name: "source pod deleted",
+			status: migrationv1alpha1.StatefulSetMigrationStatus{
+				FrozenTime: &frozen, TotalReplicas: 2, CurrentIndex: 1,
+				MigratedPods: []migrationv1alpha1.MigratedPodInfo{{Index: 0, PodName: "web-0"}},
+				History:      []migrationv1alpha1.HistoryEntry{deleted},
+			},
+			want:    "moved to the destination: web-0; web-1 was deleted from the source but not started in the destination; not moved: web-1",
+			notMove: []string{"web-1"},
+		},
+		{
+			name:    "frozen before any pod moved",
+			status:  migrationv1alpha1.StatefulSetMigrationStatus{FrozenTime: &frozen, TotalReplicas: 1},
+			want:    "moved to the destination: none; not moved: web-0",
+			notMove: []string{"web-0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{
+				Spec:   migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web", SourceNamespace: "prod"},
+				Status: tt.status,
+			}
+			if got := abortSummary(m); got != tt.want {
+				t.Errorf("abortSummary() = %q, want %q", got, tt.want)
+			}
+			if got := podsNotMoved(m); strings.Join(got, ",") != strings.Join(tt.notMove, ",") {
+				t.Errorf("podsNotMoved() = %v, want %v", got, tt.notMove)
+			}
+		})
+	}
+}
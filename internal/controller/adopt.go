@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// translationConfig returns the translation settings of a migration for one destination PVC
+func translationConfig(m *migrationv1alpha1.StatefulSetMigration, pvcName string) translate.PVTranslationConfig {
+	var passthrough translate.MetadataPassthrough
+	if p := m.Spec.MetadataPassthrough; p != nil {
+		passthrough = translate.MetadataPassthrough{AnnotationPrefixes: p.AnnotationPrefixes, LabelPrefixes: p.LabelPrefixes}
+	}
+	return translate.PVTranslationConfig{
+		DestNamespace:        m.Spec.DestNamespace,
+		DestPVCName:          pvcName,
+		StorageClassMapping:  m.Spec.StorageClassMapping,
+		PreserveNodeAffinity: true,
+		PVNameTemplate:       m.Spec.DestPVNameTemplate,
+		NodeOS:               corev1.OSName(m.Status.NodeOS),
+		Passthrough:          passthrough,
+		DataSourcePolicy:     translate.DataSourcePolicy(m.Spec.DataSourcePolicy),
+		ConvertInTreeEBS:     m.Status.ConvertInTreeEBS,
+	}
+}
+
+// destPVCToAdopt returns the destination PVC to bind the migrated volume to
+// with spec.adoptDestPVCs, or nil when the PVC is to be created. A PVC the
+// controller created in an earlier attempt is not adopted; creating it again
+// is a no-op.
+func destPVCToAdopt(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient, pvcName string) (*corev1.PersistentVolumeClaim, error) {
+	if !m.Spec.AdoptDestPVCs {
+		return nil, nil
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: pvcName}, pvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get destination PVC %s: %w", pvcName, err)
+	}
+	if pvc.Labels["migration.aqua.io/migrated"] == "true" {
+		return nil, nil
+	}
+	return pvc, nil
+}
+
+// checkDestPVCs translates every replica's volume against the destination PVC
+// it would adopt, so a PVC that could not bind fails pre-flight rather than
+// after the source pod is gone
+func checkDestPVCs(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient, pvcs []*corev1.PersistentVolumeClaim, pvs []*corev1.PersistentVolume) error {
+	var problems []string
+	for i, pv := range pvs {
+		pvcName := translate.GetPVCNameForStatefulSetPod("data", m.Spec.StatefulSetName, i)
+		existing, err := destPVCToAdopt(ctx, m, destCC, pvcName)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			continue
+		}
+		cfg := translationConfig(m, pvcName)
+		cfg.ExistingPVC = existing
+		if _, err := translate.TranslatePV(pv, pvcs[i], cfg); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// createStrictDestPVC creates the destination PVC and returns its UID. A PVC
+// of that name that already exists must be one the controller created for
+// the same volume in an earlier attempt; any other could be someone else's
+// claim, and binding the volume to it is what strict binding prevents.
+func createStrictDestPVC(ctx context.Context, cc *multicluster.ClusterClient, pvc *corev1.PersistentVolumeClaim, volumeID string) (types.UID, error) {
+	err := cc.Client.Create(ctx, pvc)
+	if err == nil {
+		return pvc.UID, nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("failed to create destination PVC: %w", err)
+	}
+
+	existing := &corev1.PersistentVolumeClaim{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: pvc.Namespace, Name: pvc.Name}, existing); err != nil {
+		return "", fmt.Errorf("failed to get destination PVC: %w", err)
+	}
+	if existing.Labels["migration.aqua.io/migrated"] != "true" || existing.Annotations[migration.AnnotationVolumeID] != volumeID {
+		return "", fmt.Errorf("destination PVC %s/%s already exists and was not created for volume %s", pvc.Namespace, pvc.Name, volumeID)
+	}
+	return existing.UID, nil
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestCheckDestPVCs(t *testing.T) {
+	destPVC := func(name, class string, labels map[string]string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "dest", Name: name, UID: types.UID("uid-" + name), Labels: labels},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: ptr.To(class),
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				},
+			},
+		}
+	}
+	sourcePV := func(volumeID string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-" + volumeID},
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				StorageClassName: "gp3",
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: volumeID},
+				},
+			},
+		}
+	}
+	pvcs := []*corev1.PersistentVolumeClaim{{}, {}, {}}
+	pvs := []*corev1.PersistentVolume{sourcePV("vol-0"), sourcePV("vol-1"), sourcePV("vol-2")}
+
+	tests := []struct {
+		name    string
+		adopt   bool
+		objects []*corev1.PersistentVolumeClaim
+		wantErr string
+	}{
+		{name: "adoption off", objects: []*corev1.PersistentVolumeClaim{destPVC("data-web-0", "io2", nil)}},
+		{name: "no existing PVCs", adopt: true},
+		{
+			name:  "compatible PVC, one created by an earlier attempt, one missing",
+			adopt: true,
+			objects: []*corev1.PersistentVolumeClaim{
+				destPVC("data-web-0", "gp3", nil),
+				destPVC("data-web-1", "io2", map[string]string{"migration.aqua.io/migrated": "true"}),
+			},
+		},
+		{
+			name:    "incompatible PVC",
+			adopt:   true,
+			objects: []*corev1.PersistentVolumeClaim{destPVC("data-web-2", "io2", nil)},
+			wantErr: `PVC dest/data-web-2 has StorageClass "io2" but the volume's PV has "gp3"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme)
+			for _, pvc := range tt.objects {
+				builder = builder.WithObjects(pvc)
+			}
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+				StatefulSetName: "web", DestNamespace: "dest", AdoptDestPVCs: tt.adopt,
+			}}
+
+			err := checkDestPVCs(context.Background(), m, &multicluster.ClusterClient{Client: builder.Build()}, pvcs, pvs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkDestPVCs() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkDestPVCs() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateStrictDestPVC(t *testing.T) {
+	pvc := func(labels, annotations map[string]string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "dest", Name: "data-web-0", Labels: labels, Annotations: annotations},
+		}
+	}
+	ours := pvc(map[string]string{"migration.aqua.io/migrated": "true"}, map[string]string{"migration.aqua.io/volume-id": "vol-abc"})
+
+	tests := []struct {
+		name     string
+		existing *corev1.PersistentVolumeClaim
+		wantErr  bool
+	}{
+		{name: "created"},
+		{name: "created by an earlier attempt", existing: ours.DeepCopy()},
+		{name: "someone else's PVC", existing: pvc(nil, nil), wantErr: true},
+		{
+			name:     "created for another volume",
+			existing: pvc(ours.Labels, map[string]string{"migration.aqua.io/volume-id": "vol-def"}),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The API server assigns UIDs; the fake client does not
+			builder := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					obj.SetUID("new-uid")
+					return c.Create(ctx, obj, opts...)
+				},
+			})
+			if tt.existing != nil {
+				tt.existing.UID = "existing-uid"
+				builder = builder.WithObjects(tt.existing)
+			}
+
+			uid, err := createStrictDestPVC(context.Background(), &multicluster.ClusterClient{Client: builder.Build()}, ours.DeepCopy(), "vol-abc")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("createStrictDestPVC() = %q, want error", uid)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("createStrictDestPVC() error = %v", err)
+			}
+			want := types.UID("new-uid")
+			if tt.existing != nil {
+				want = tt.existing.UID
+			}
+			if uid != want {
+				t.Errorf("createStrictDestPVC() = %q, want %q", uid, want)
+			}
+		})
+	}
+}
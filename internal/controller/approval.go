@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+const (
+	// AnnotationApproval holds the base64 signature approving a migration,
+	// over the payload ApprovalPayload returns for it
+	AnnotationApproval = "migration.aqua.io/approval"
+
+	// ConditionWaitingForApproval reports that the migration waits for a
+	// signed approval before freezing the source
+	ConditionWaitingForApproval = "WaitingForApproval"
+
+	// EventApprovalRequired is recorded when the migration starts waiting for an approval
+	EventApprovalRequired = "ApprovalRequired"
+
+	// EventApprovalRejected is recorded when the approval signature does not verify
+	EventApprovalRejected = "ApprovalRejected"
+
+	// EventApproved is recorded when the approval signature verifies
+	EventApproved = "Approved"
+
+	// StepApproval is the history step of the approval
+	StepApproval = "Approval"
+)
+
+// approvalPayloadVersion starts every approval payload, so a signature over
+// anything else is never taken for an approval
+const approvalPayloadVersion = "aqua-service-controller/approval/v1"
+
+// ApprovalKey is a public key that may sign migration approvals
+type ApprovalKey struct {
+	// Fingerprint is "SHA256:" and the base64 SHA-256 of the key's DER encoding
+	Fingerprint string
+
+	// Key is an *ecdsa.PublicKey, ed25519.PublicKey or *rsa.PublicKey
+	Key crypto.PublicKey
+}
+
+// ApprovalPayload returns what an approval of a started migration signs: the
+// migration's namespace, name and UID, which keep the signature from
+// approving a recreated or copied migration, and the hash of the spec it
+// started with, which the controller pins for the rest of the migration
+func ApprovalPayload(m *migrationv1alpha1.StatefulSetMigration) []byte {
+	return []byte(fmt.Sprintf("%s\n%s/%s\n%s\n%s\n", approvalPayloadVersion, m.Namespace, m.Name, m.UID, m.Status.SpecSnapshotHash))
+}
+
+// LoadApprovalKeys reads the PEM public keys ("PUBLIC KEY" blocks) in a file,
+// or in every file of a directory, such as a mounted Secret or ConfigMap
+func LoadApprovalKeys(path string) ([]ApprovalKey, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		files = nil
+		for _, entry := range entries {
+			// Mounted volumes keep their data in hidden directories
+			if strings.HasPrefix(entry.Name(), ".") || entry.IsDir() {
+				continue
+			}
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+
+	var keys []ApprovalKey
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileKeys, err := ParseApprovalKeys(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		keys = append(keys, fileKeys...)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public keys in %s", path)
+	}
+	return keys, nil
+}
+
+// ParseApprovalKeys parses the PEM public keys in data
+func ParseApprovalKeys(data []byte) ([]ApprovalKey, error) {
+	var keys []ApprovalKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return keys, nil
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		switch key.(type) {
+		case *ecdsa.PublicKey, ed25519.PublicKey, *rsa.PublicKey:
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+		sum := sha256.Sum256(block.Bytes)
+		keys = append(keys, ApprovalKey{
+			Fingerprint: "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]),
+			Key:         key,
+		})
+	}
+}
+
+// verifyApproval returns the key that signed payload with the base64
+// signature. Ed25519 keys verify the payload itself; ECDSA (ASN.1) and RSA
+// (PKCS #1 v1.5 or PSS) keys verify its SHA-256 digest, as produced by
+// "openssl dgst -sha256 -sign".
+func verifyApproval(keys []ApprovalKey, payload []byte, signature string) (*ApprovalKey, error) {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return nil, fmt.Errorf("signature is not base64: %w", err)
+	}
+	digest := sha256.Sum256(payload)
+	for i := range keys {
+		var ok bool
+		switch key := keys[i].Key.(type) {
+		case ed25519.PublicKey:
+			ok = ed25519.Verify(key, payload, sig)
+		case *ecdsa.PublicKey:
+			ok = ecdsa.VerifyASN1(key, digest[:], sig)
+		case *rsa.PublicKey:
+			ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil ||
+				rsa.VerifyPSS(key, crypto.SHA256, digest[:], sig, nil) == nil
+		}
+		if ok {
+			return &keys[i], nil
+		}
+	}
+	return nil, errors.New("signature does not verify with any approval key")
+}
+
+// SignApproval signs payload in the format verifyApproval checks and returns
+// the base64 signature
+func SignApproval(signer crypto.Signer, payload []byte) (string, error) {
+	var sig []byte
+	var err error
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		sig, err = signer.Sign(rand.Reader, payload, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(payload)
+		sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// waitForApproval reports whether the migration must wait for a signed
+// approval before the source is frozen: approval keys are configured and no
+// approval has been verified yet. A verified approval is recorded in
+// status.approval, so it holds for retries. While waiting, nothing is
+// requeued, since annotating the migration reconciles it again.
+func (r *StatefulSetMigrationReconciler) waitForApproval(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (bool, error) {
+	if len(r.ApprovalKeys) == 0 || m.Status.Approval != nil {
+		return false, nil
+	}
+	logger := log.FromContext(ctx)
+	before := m.Status.DeepCopy()
+
+	reason, eventType, eventReason := "ApprovalRequired", corev1.EventTypeNormal, EventApprovalRequired
+	result := migrationv1alpha1.HistoryResultStarted
+	message := fmt.Sprintf("Waiting for a signed approval of spec %s; sign the payload from 'storagemover approve' and annotate the migration with %s=<base64 signature>",
+		m.Status.SpecSnapshotHash, AnnotationApproval)
+	if signature := m.Annotations[AnnotationApproval]; signature != "" {
+		key, err := verifyApproval(r.ApprovalKeys, ApprovalPayload(m), signature)
+		if err == nil {
+			logger.Info("Migration approved", "key", key.Fingerprint, "spec", m.Status.SpecSnapshotHash)
+			m.Status.Approval = &migrationv1alpha1.MigrationApproval{
+				KeyFingerprint:   key.Fingerprint,
+				SpecSnapshotHash: m.Status.SpecSnapshotHash,
+				Signature:        strings.TrimSpace(signature),
+				ApprovedTime:     metav1.NewTime(r.clock().Now()),
+			}
+			approved := fmt.Sprintf("Spec %s approved by key %s", m.Status.SpecSnapshotHash, key.Fingerprint)
+			r.recordHistory(m, StepApproval, "", migrationv1alpha1.HistoryResultSucceeded, approved)
+			r.setCondition(m, ConditionWaitingForApproval, metav1.ConditionFalse, "Approved", approved)
+			r.event(m, corev1.EventTypeNormal, EventApproved, approved)
+			return false, r.updateStatus(ctx, m, before)
+		}
+		reason, eventType, eventReason = "InvalidSignature", corev1.EventTypeWarning, EventApprovalRejected
+		result = migrationv1alpha1.HistoryResultFailed
+		message = fmt.Sprintf("Approval rejected: %v. Sign the payload from 'storagemover approve' for spec %s", err, m.Status.SpecSnapshotHash)
+	}
+
+	if c := meta.FindStatusCondition(m.Status.Conditions, ConditionWaitingForApproval); c != nil && c.Status == metav1.ConditionTrue && c.Message == message {
+		return true, nil
+	}
+	logger.Info("Waiting for a signed approval", "reason", reason)
+	r.recordHistory(m, StepApproval, "", result, message)
+	r.setCondition(m, ConditionWaitingForApproval, metav1.ConditionTrue, reason, message)
+	r.event(m, eventType, eventReason, message)
+	return true, r.updateStatus(ctx, m, before)
+}
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+// approvalPublicKey returns the public key of signer as a PEM "PUBLIC KEY" block
+func approvalPublicKey(t *testing.T, signer crypto.Signer) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func TestVerifyApproval(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	signers := []crypto.Signer{edKey, ecKey, rsaKey}
+	var trusted []byte
+	for _, signer := range signers {
+		trusted = append(trusted, approvalPublicKey(t, signer)...)
+	}
+	keys, err := ParseApprovalKeys(trusted)
+	if err != nil || len(keys) != 3 {
+		t.Fatalf("ParseApprovalKeys() = %d keys, %v, want 3", len(keys), err)
+	}
+
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", UID: "uid-1"},
+		Status:     migrationv1alpha1.StatefulSetMigrationStatus{SpecSnapshotHash: "sha256:abc"},
+	}
+	payload := ApprovalPayload(m)
+	for i, signer := range signers {
+		signature, err := SignApproval(signer, payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := verifyApproval(keys, payload, signature)
+		if err != nil || key.Fingerprint != keys[i].Fingerprint {
+			t.Errorf("verifyApproval() with %T = %v, %v, want key %d", signer, key, err, i)
+		}
+
+		// The same signature does not approve a recreated migration
+		recreated := m.DeepCopy()
+		recreated.UID = "uid-2"
+		if _, err := verifyApproval(keys, ApprovalPayload(recreated), signature); err == nil {
+			t.Errorf("signature by %T approved a migration with another UID", signer)
+		}
+	}
+
+	signature, _ := SignApproval(otherKey, payload)
+	if _, err := verifyApproval(keys, payload, signature); err == nil {
+		t.Error("signature by an untrusted key verified")
+	}
+	if _, err := verifyApproval(keys, payload, "not base64!"); err == nil {
+		t.Error("malformed signature verified")
+	}
+}
+
+func TestLoadApprovalKeys(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	public := approvalPublicKey(t, edKey)
+
+	// A mounted Secret links its keys to a hidden directory
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "..data"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "..data", "approver.pem"), public, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "approver.pem"), filepath.Join(dir, "approver.pem")); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadApprovalKeys(dir)
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("LoadApprovalKeys() = %d keys, %v, want 1", len(keys), err)
+	}
+
+	if _, err := LoadApprovalKeys(t.TempDir()); err == nil {
+		t.Error("LoadApprovalKeys() of an empty directory succeeded")
+	}
+}
+
+func TestWaitForApproval(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	public := approvalPublicKey(t, edKey)
+	keys, err := ParseApprovalKeys(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", UID: "uid-1"},
+		Status: migrationv1alpha1.StatefulSetMigrationStatus{
+			Phase:            migrationv1alpha1.PhaseFreezingSource,
+			SpecSnapshotHash: "sha256:abc",
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+	ctx := context.Background()
+
+	// Without keys nothing is required
+	if waiting, err := (&StatefulSetMigrationReconciler{Client: c}).waitForApproval(ctx, m); waiting || err != nil {
+		t.Fatalf("waitForApproval() without keys = %v, %v, want no wait", waiting, err)
+	}
+
+	r := &StatefulSetMigrationReconciler{Client: c, ApprovalKeys: keys}
+	if waiting, err := r.waitForApproval(ctx, m); !waiting || err != nil {
+		t.Fatalf("waitForApproval() = %v, %v, want waiting", waiting, err)
+	}
+	if c := meta.FindStatusCondition(m.Status.Conditions, ConditionWaitingForApproval); c == nil || c.Reason != "ApprovalRequired" {
+		t.Errorf("condition = %+v, want ApprovalRequired", c)
+	}
+
+	// A signature over another spec is rejected
+	other := m.DeepCopy()
+	other.Status.SpecSnapshotHash = "sha256:def"
+	signature, _ := SignApproval(edKey, ApprovalPayload(other))
+	m.Annotations = map[string]string{AnnotationApproval: signature}
+	if waiting, err := r.waitForApproval(ctx, m); !waiting || err != nil {
+		t.Fatalf("waitForApproval() of another spec = %v, %v, want waiting", waiting, err)
+	}
+	if c := meta.FindStatusCondition(m.Status.Conditions, ConditionWaitingForApproval); c == nil || c.Reason != "InvalidSignature" {
+		t.Errorf("condition = %+v, want InvalidSignature", c)
+	}
+
+	signature, _ = SignApproval(edKey, ApprovalPayload(m))
+	m.Annotations = map[string]string{AnnotationApproval: signature}
+	if waiting, err := r.waitForApproval(ctx, m); waiting || err != nil {
+		t.Fatalf("waitForApproval() of the spec = %v, %v, want approved", waiting, err)
+	}
+	if a := m.Status.Approval; a == nil || a.KeyFingerprint != keys[0].Fingerprint || a.SpecSnapshotHash != "sha256:abc" {
+		t.Errorf("Approval = %+v, want the key and spec recorded", a)
+	}
+
+	// The recorded approval holds once the annotation is gone, as on a retry
+	m.Annotations = nil
+	if waiting, err := r.waitForApproval(ctx, m); waiting || err != nil {
+		t.Errorf("waitForApproval() after approval = %v, %v, want no wait", waiting, err)
+	}
+}
@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// podCheckpoint is the archived record of one migrated pod
+type podCheckpoint struct {
+	Pod       migrationv1alpha1.MigratedPodInfo `json:"pod"`
+	SourcePVC *corev1.PersistentVolumeClaim     `json:"sourcePVC"`
+	SourcePV  *corev1.PersistentVolume          `json:"sourcePV"`
+	DestPVC   *corev1.PersistentVolumeClaim     `json:"destPVC"`
+	DestPV    *corev1.PersistentVolume          `json:"destPV"`
+}
+
+// archivePrefix returns the S3 key prefix of a migration's archive. The UID
+// keeps archives of recreated migrations with the same name apart.
+func (r *StatefulSetMigrationReconciler) archivePrefix(m *migrationv1alpha1.StatefulSetMigration) string {
+	return fmt.Sprintf("%s%s/%s/%s/", r.ArchivePrefix, m.Namespace, m.Name, m.UID)
+}
+
+// objectOptions returns the S3 upload options for reports and archives
+func (r *StatefulSetMigrationReconciler) objectOptions(contentType string) aws.PutObjectOptions {
+	return aws.PutObjectOptions{
+		ContentType:          contentType,
+		ServerSideEncryption: r.S3Encryption,
+		SSEKMSKeyID:          r.S3KMSKeyID,
+	}
+}
+
+// archiveSource uploads the source StatefulSet and its PVCs and PVs, as they
+// are before the source is frozen, under the migration's archive prefix
+func (r *StatefulSetMigrationReconciler) archiveSource(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient, sts *appsv1.StatefulSet) error {
+	pvcs, pvs, unbound, err := sourceVolumes(ctx, cc, sts, migration.StatefulSetOrdinals(sts).List())
+	if err != nil {
+		return err
+	}
+	pvcs = append(pvcs, unbound...)
+
+	prefix := r.archivePrefix(m) + "source/"
+	objects := map[string]client.Object{prefix + "statefulset.yaml": sts}
+	for _, pvc := range pvcs {
+		objects[prefix+"persistentvolumeclaims/"+pvc.Name+".yaml"] = pvc
+	}
+	for _, pv := range pvs {
+		objects[prefix+"persistentvolumes/"+pv.Name+".yaml"] = pv
+	}
+
+	for key, obj := range objects {
+		data, err := archiveManifest(obj)
+		if err != nil {
+			return err
+		}
+		if err := r.EBSClient.PutObject(ctx, r.ArchiveBucket, key, data, r.objectOptions("application/yaml")); err != nil {
+			return err
+		}
+	}
+
+	m.Status.Archive = fmt.Sprintf("s3://%s/%s", r.ArchiveBucket, r.archivePrefix(m))
+	r.recordHistory(m, StepArchive, m.Status.Archive, migrationv1alpha1.HistoryResultSucceeded,
+		fmt.Sprintf("Archived the source StatefulSet, %d PVCs and %d PVs", len(pvcs), len(pvs)))
+	return nil
+}
+
+// archiveCheckpoint uploads the record of a migrated pod. The pod has already
+// moved, so a failed upload is recorded in the history rather than failing
+// the migration.
+func (r *StatefulSetMigrationReconciler) archiveCheckpoint(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, checkpoint podCheckpoint) {
+	key := fmt.Sprintf("%scheckpoints/%s.yaml", r.archivePrefix(m), checkpoint.Pod.PodName)
+
+	err := func() error {
+		for _, obj := range []client.Object{checkpoint.SourcePVC, checkpoint.SourcePV, checkpoint.DestPVC, checkpoint.DestPV} {
+			if err := setTypeMeta(obj); err != nil {
+				return err
+			}
+		}
+		data, err := yaml.Marshal(checkpoint)
+		if err != nil {
+			return fmt.Errorf("failed to encode checkpoint: %w", err)
+		}
+		return r.EBSClient.PutObject(ctx, r.ArchiveBucket, key, data, r.objectOptions("application/yaml"))
+	}()
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to archive pod checkpoint", "pod", checkpoint.Pod.PodName)
+		r.recordHistory(m, StepArchive, key, migrationv1alpha1.HistoryResultFailed, err.Error())
+		return
+	}
+	r.recordHistory(m, StepArchive, key, migrationv1alpha1.HistoryResultSucceeded, "")
+}
+
+// serverAnnotations are annotations the API server, controllers and
+// kubectl set, which a re-applied manifest should not carry over
+var serverAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"pv.kubernetes.io/bind-completed",
+	"pv.kubernetes.io/bound-by-controller",
+	"volume.kubernetes.io/selected-node",
+}
+
+// archiveManifest encodes obj as a YAML manifest that can be applied as it
+// is during a rollback. It is encoded from an unstructured copy, since a
+// typed object always carries a status and a creationTimestamp, if only as
+// "status: {}" and "creationTimestamp: null".
+func archiveManifest(obj client.Object) ([]byte, error) {
+	obj = obj.DeepCopyObject().(client.Object)
+	if err := setTypeMeta(obj); err != nil {
+		return nil, err
+	}
+	sanitizeManifest(obj)
+	fields, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s: %w", obj.GetName(), err)
+	}
+	delete(fields, "status")
+	unstructured.RemoveNestedField(fields, "metadata", "creationTimestamp")
+	data, err := yaml.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", obj.GetName(), err)
+	}
+	return data, nil
+}
+
+// setTypeMeta fills in apiVersion and kind, which typed clients leave empty,
+// and drops managedFields, which only add noise to an archived manifest
+func setTypeMeta(obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, clientgoscheme.Scheme)
+	if err != nil {
+		return fmt.Errorf("failed to find the kind of %s: %w", obj.GetName(), err)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetManagedFields(nil)
+	return nil
+}
+
+// sanitizeManifest strips what the API server populates, as kubectl-neat
+// does: identity and bookkeeping metadata, and the server-set
+// annotations. Owner references are dropped too; they name owners by UID, so
+// the garbage collector would delete a re-applied object whose owner is gone.
+// A PV keeps its claimRef by namespace and name, so it binds to the PVC
+// re-applied with it.
+func sanitizeManifest(obj client.Object) {
+	obj.SetUID("")
+	obj.SetResourceVersion("")
+	obj.SetGeneration(0)
+	obj.SetCreationTimestamp(metav1.Time{})
+	obj.SetDeletionTimestamp(nil)
+	obj.SetDeletionGracePeriodSeconds(nil)
+	obj.SetSelfLink("")
+	obj.SetOwnerReferences(nil)
+	if annotations := obj.GetAnnotations(); annotations != nil {
+		for _, key := range serverAnnotations {
+			delete(annotations, key)
+		}
+		if len(annotations) == 0 {
+			obj.SetAnnotations(nil)
+		}
+	}
+
+	if pv, ok := obj.(*corev1.PersistentVolume); ok && pv.Spec.ClaimRef != nil {
+		pv.Spec.ClaimRef.UID = ""
+		pv.Spec.ClaimRef.ResourceVersion = ""
+	}
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestArchiveManifest(t *testing.T) {
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "pv-data-web-0",
+			UID:             "pv-uid",
+			ResourceVersion: "4711",
+			ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "kube-controller-manager"}},
+			Annotations: map[string]string{
+				"pv.kubernetes.io/bound-by-controller": "yes",
+				"pv.kubernetes.io/provisioned-by":      "ebs.csi.aws.com",
+			},
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+			ClaimRef:                      &corev1.ObjectReference{Namespace: "prod", Name: "data-web-0", UID: "pvc-uid", ResourceVersion: "42"},
+		},
+		Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+	}
+
+	data, err := archiveManifest(pv)
+	if err != nil {
+		t.Fatalf("archiveManifest() error = %v", err)
+	}
+	manifest := string(data)
+	for _, want := range []string{"apiVersion: v1", "kind: PersistentVolume", "name: pv-data-web-0", "persistentVolumeReclaimPolicy: Delete",
+		"name: data-web-0", "pv.kubernetes.io/provisioned-by"} {
+		if !strings.Contains(manifest, want) {
+			t.Errorf("manifest missing %q:\n%s", want, manifest)
+		}
+	}
+	for _, unwanted := range []string{"managedFields", "uid:", "resourceVersion", "bound-by-controller", "phase: Bound", "status:", "creationTimestamp"} {
+		if strings.Contains(manifest, unwanted) {
+			t.Errorf("manifest keeps %q:\n%s", unwanted, manifest)
+		}
+	}
+	if len(pv.ManagedFields) == 0 || pv.Kind != "" || pv.UID == "" || pv.Spec.ClaimRef.UID == "" {
+		t.Error("archiveManifest() modified the object it was given")
+	}
+}
+
+func TestArchivePrefix(t *testing.T) {
+	r := &StatefulSetMigrationReconciler{ArchivePrefix: "migrations/"}
+	m := &migrationv1alpha1.StatefulSetMigration{ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", UID: "uid-1"}}
+	if got := r.archivePrefix(m); got != "migrations/ops/web/uid-1/" {
+		t.Errorf("archivePrefix() = %q", got)
+	}
+}
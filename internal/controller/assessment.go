@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/assessment"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// MigrationAssessmentReconciler reconciles a MigrationAssessment object.
+// Each assessment is a one-shot, read-only scan; create a new resource to rescan.
+type MigrationAssessmentReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ClientManager *multicluster.ClientManager
+
+	// Pause holds assessments that have not run yet while its ConfigMap says so (optional)
+	Pause *PauseSwitch
+
+	// TargetPolicy restricts the clusters and namespaces assessments may
+	// scan; every target is allowed when nil
+	TargetPolicy *TargetPolicy
+
+	// Clock stamps completion times (default: the real clock)
+	Clock clock.Clock
+}
+
+// clock returns the clock that stamps completion times
+func (r *MigrationAssessmentReconciler) clock() clock.Clock {
+	if r.Clock == nil {
+		return clock.RealClock{}
+	}
+	return r.Clock
+}
+
+// +kubebuilder:rbac:groups=migration.aqua.io,resources=migrationassessments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=migration.aqua.io,resources=migrationassessments/status,verbs=get;update;patch
+
+// Reconcile runs the scan for a MigrationAssessment that has not completed yet
+func (r *MigrationAssessmentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	a := &migrationv1alpha1.MigrationAssessment{}
+	if err := r.Get(ctx, req.NamespacedName, a); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if a.Status.Phase == migrationv1alpha1.AssessmentPhaseCompleted || a.Status.Phase == migrationv1alpha1.AssessmentPhaseFailed {
+		return ctrl.Result{}, nil
+	}
+
+	// The pause switch reconciles the assessment again when the pause is lifted
+	if reason := r.Pause.Reason(); reason != "" {
+		logger.Info("Holding assessment while paused", "reason", reason)
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("Running migration assessment", "namespace", a.Spec.Namespace)
+
+	results, err := r.scan(ctx, a)
+	now := metav1.NewTime(r.clock().Now())
+	a.Status.CompletionTime = &now
+	if err != nil {
+		a.Status.Phase = migrationv1alpha1.AssessmentPhaseFailed
+		a.Status.LastError = err.Error()
+	} else {
+		a.Status.Phase = migrationv1alpha1.AssessmentPhaseCompleted
+		a.Status.Results = results
+		a.Status.Summary = assessment.Summarize(results)
+	}
+
+	if err := r.Status().Update(ctx, a); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	logger.Info("Migration assessment finished", "phase", a.Status.Phase, "summary", a.Status.Summary)
+	return ctrl.Result{}, nil
+}
+
+// scan connects to the referenced clusters, once the target policy allows
+// them, and assesses their StatefulSets
+func (r *MigrationAssessmentReconciler) scan(ctx context.Context, a *migrationv1alpha1.MigrationAssessment) ([]migrationv1alpha1.StatefulSetAssessment, error) {
+	if err := r.TargetPolicy.Check(ctx, a.Namespace, assessmentTargets(a)); err != nil {
+		return nil, err
+	}
+
+	sourceClient, err := r.ClientManager.GetClient(ctx, contextRefFor(a.Namespace, a.Spec.SourceCluster))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to source cluster: %w", err)
+	}
+
+	cfg := assessment.ScanConfig{
+		Namespace:     a.Spec.Namespace,
+		DestNamespace: a.Spec.DestNamespace,
+	}
+	if a.Spec.DestCluster != nil {
+		destClient, err := r.ClientManager.GetClient(ctx, contextRefFor(a.Namespace, *a.Spec.DestCluster))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to destination cluster: %w", err)
+		}
+		cfg.Dest = destClient.Client
+	}
+
+	return assessment.Scan(ctx, sourceClient.Client, cfg)
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *MigrationAssessmentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&migrationv1alpha1.MigrationAssessment{})
+	if r.Pause != nil {
+		b = b.WatchesRawSource(source.Channel(r.Pause.Subscribe(), enqueueAll(mgr.GetClient(), func() client.ObjectList {
+			return &migrationv1alpha1.MigrationAssessmentList{}
+		})))
+	}
+	return b.Complete(r)
+}
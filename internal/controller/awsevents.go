@@ -0,0 +1,284 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+const (
+	// AWSEventSource is the source of the EventBridge events the controller puts
+	AWSEventSource = "migration.aqua.io"
+
+	// DetailTypePhaseChange is the detail type of the EventBridge event put
+	// when a migration enters a new phase
+	DetailTypePhaseChange = "StatefulSetMigration Phase Change"
+
+	// DetailTypeEvent is the detail type of the EventBridge event put for
+	// each Kubernetes event recorded on a migration
+	DetailTypeEvent = "StatefulSetMigration Event"
+
+	// DefaultAWSEventQueueSize is how many publications wait to be sent
+	// before further ones are dropped
+	DefaultAWSEventQueueSize = 1000
+
+	// awsEventTimeout bounds each EventBridge or CloudWatch request
+	awsEventTimeout = 10 * time.Second
+)
+
+// AWSEventAPI is the part of the AWS client AWSEvents publishes with
+type AWSEventAPI interface {
+	PutEvents(ctx context.Context, events []aws.BusEvent) error
+	PutMetricData(ctx context.Context, namespace string, data []aws.MetricDatum) error
+}
+
+// AWSEvents publishes the lifecycle of StatefulSetMigrations to Amazon
+// EventBridge and CloudWatch, so teams that run on AWS can alarm and automate
+// on migrations without scraping Prometheus. Each phase change and each
+// Kubernetes event recorded on a migration is put on EventBus, and a
+// migration's outcome, duration, downtime and size are put in
+// MetricNamespace once it finishes.
+//
+// Publishing is best effort and never holds up a reconcile: publications are
+// queued and sent by Start, and dropped, with a log line, when the queue is
+// full or AWS rejects them. A nil AWSEvents publishes nothing.
+type AWSEvents struct {
+	// API sends the requests
+	API AWSEventAPI
+
+	// EventBus is the name or ARN of the event bus events are put on, such as
+	// "default"; empty puts no events
+	EventBus string
+
+	// MetricNamespace is the CloudWatch namespace metrics are put in; empty
+	// puts no metrics
+	MetricNamespace string
+
+	// Clock stamps the events and metrics (default: the real clock)
+	Clock clock.Clock
+
+	queue chan awsPublication
+}
+
+// awsPublication is an event or a set of metric values waiting to be sent
+type awsPublication struct {
+	event   *aws.BusEvent
+	metrics []aws.MetricDatum
+}
+
+// NewAWSEvents returns an AWSEvents publishing with api, with a queue of
+// DefaultAWSEventQueueSize
+func NewAWSEvents(api AWSEventAPI, eventBus, metricNamespace string) *AWSEvents {
+	return &AWSEvents{
+		API:             api,
+		EventBus:        eventBus,
+		MetricNamespace: metricNamespace,
+		queue:           make(chan awsPublication, DefaultAWSEventQueueSize),
+	}
+}
+
+func (a *AWSEvents) clock() clock.Clock {
+	if a.Clock == nil {
+		return clock.RealClock{}
+	}
+	return a.Clock
+}
+
+// Start sends queued publications until ctx is done, then sends those
+// still queued. It implements manager.Runnable.
+func (a *AWSEvents) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), awsEventTimeout)
+			defer cancel()
+			for {
+				select {
+				case p := <-a.queue:
+					a.send(flushCtx, a.batch(p))
+				default:
+					return nil
+				}
+			}
+		case p := <-a.queue:
+			a.send(ctx, a.batch(p))
+		}
+	}
+}
+
+// batch returns p with up to a PutEvents request's worth of the
+// publications queued behind it
+func (a *AWSEvents) batch(p awsPublication) []awsPublication {
+	batch := []awsPublication{p}
+	for len(batch) < aws.MaxBusEventsPerRequest {
+		select {
+		case next := <-a.queue:
+			batch = append(batch, next)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// send puts a batch's events in one request and its metrics in another
+func (a *AWSEvents) send(ctx context.Context, batch []awsPublication) {
+	logger := ctrl.Log.WithName("aws-events")
+	var events []aws.BusEvent
+	var metrics []aws.MetricDatum
+	for _, p := range batch {
+		if p.event != nil {
+			events = append(events, *p.event)
+		}
+		metrics = append(metrics, p.metrics...)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, awsEventTimeout)
+	defer cancel()
+	if len(events) > 0 {
+		if err := a.API.PutEvents(ctx, events); err != nil {
+			logger.Error(err, "Failed to publish migration events to EventBridge", "eventBus", a.EventBus, "count", len(events))
+		}
+	}
+	if len(metrics) > 0 {
+		if err := a.API.PutMetricData(ctx, a.MetricNamespace, metrics); err != nil {
+			logger.Error(err, "Failed to publish migration metrics to CloudWatch", "namespace", a.MetricNamespace)
+		}
+	}
+}
+
+// enqueue queues p for Start, dropping it when the queue is full
+func (a *AWSEvents) enqueue(p awsPublication) {
+	select {
+	case a.queue <- p:
+	default:
+		ctrl.Log.WithName("aws-events").Info("Dropping a migration event or metric: the publishing queue is full")
+	}
+}
+
+// putEvent queues an event about m with the given detail type and body
+func (a *AWSEvents) putEvent(m *migrationv1alpha1.StatefulSetMigration, detailType string, detail map[string]any) {
+	if a == nil || a.EventBus == "" {
+		return
+	}
+	body := map[string]any{
+		"namespace":   m.Namespace,
+		"name":        m.Name,
+		"uid":         string(m.UID),
+		"statefulSet": m.Spec.StatefulSetName,
+		"phase":       string(m.Status.Phase),
+	}
+	for k, v := range detail {
+		body[k] = v
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+	a.enqueue(awsPublication{event: &aws.BusEvent{
+		EventBus:   a.EventBus,
+		Source:     AWSEventSource,
+		DetailType: detailType,
+		Detail:     string(data),
+		Time:       a.clock().Now(),
+	}})
+}
+
+// PhaseChanged publishes that m moved from the phase previous into its
+// current one
+func (a *AWSEvents) PhaseChanged(m *migrationv1alpha1.StatefulSetMigration, previous migrationv1alpha1.MigrationPhase) {
+	if a == nil || m.Status.Phase == previous {
+		return
+	}
+	detail := map[string]any{
+		"previousPhase":   string(previous),
+		"sourceNamespace": m.Spec.SourceNamespace,
+		"destNamespace":   m.Spec.DestNamespace,
+		"progress":        m.Status.Progress,
+	}
+	if m.Status.Phase == migrationv1alpha1.PhaseFailed && m.Status.LastError != "" {
+		detail["error"] = m.Status.LastError
+	}
+	a.putEvent(m, DetailTypePhaseChange, detail)
+}
+
+// Finished publishes the metrics of a migration that reached a final phase:
+// MigrationsFinished, and the MigrationDuration, MaxPodDowntime, PodsMoved
+// and StorageMigrated known for it, each with a Result dimension of the phase
+func (a *AWSEvents) Finished(m *migrationv1alpha1.StatefulSetMigration) {
+	if a == nil || a.MetricNamespace == "" {
+		return
+	}
+	stats := telemetryStatistics(m)
+	now := a.clock().Now()
+	dimensions := map[string]string{"Result": stats.Result}
+	datum := func(name string, value float64, unit string) aws.MetricDatum {
+		return aws.MetricDatum{Name: name, Dimensions: dimensions, Value: value, Unit: unit, Time: now}
+	}
+
+	metrics := []aws.MetricDatum{
+		datum("MigrationsFinished", 1, "Count"),
+		datum("PodsMoved", float64(stats.PodsMoved), "Count"),
+	}
+	if stats.DurationSeconds > 0 {
+		metrics = append(metrics, datum("MigrationDuration", stats.DurationSeconds, "Seconds"))
+	}
+	if stats.MaxPodDowntimeSeconds > 0 {
+		metrics = append(metrics, datum("MaxPodDowntime", stats.MaxPodDowntimeSeconds, "Seconds"))
+	}
+	if m.Status.TotalStorageMigrated != nil {
+		metrics = append(metrics, datum("StorageMigrated", float64(m.Status.TotalStorageMigrated.Value()), "Bytes"))
+	}
+	a.enqueue(awsPublication{metrics: metrics})
+}
+
+// Recorder returns an EventRecorder that records to recorder and also
+// publishes each event recorded on a StatefulSetMigration
+func (a *AWSEvents) Recorder(recorder record.EventRecorder) record.EventRecorder {
+	if a == nil || a.EventBus == "" {
+		return recorder
+	}
+	return &awsEventRecorder{EventRecorder: recorder, events: a}
+}
+
+// awsEventRecorder is the EventRecorder AWSEvents.Recorder returns
+type awsEventRecorder struct {
+	record.EventRecorder
+	events *AWSEvents
+}
+
+func (r *awsEventRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	r.EventRecorder.Event(object, eventType, reason, message)
+	r.publish(object, eventType, reason, message)
+}
+
+func (r *awsEventRecorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// AnnotatedEventf carries data, such as the spec snapshot, that is not
+// published
+func (r *awsEventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventType, reason, messageFmt, args...)
+}
+
+func (r *awsEventRecorder) publish(object runtime.Object, eventType, reason, message string) {
+	m, ok := object.(*migrationv1alpha1.StatefulSetMigration)
+	if !ok {
+		return
+	}
+	r.events.putEvent(m, DetailTypeEvent, map[string]any{
+		"type":    eventType,
+		"reason":  reason,
+		"message": message,
+	})
+}
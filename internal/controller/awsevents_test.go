@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+// fakeAWSEventAPI records what AWSEvents publishes
+type fakeAWSEventAPI struct {
+	mu      sync.Mutex
+	events  []aws.BusEvent
+	metrics map[string][]aws.MetricDatum
+}
+
+func (f *fakeAWSEventAPI) PutEvents(ctx context.Context, events []aws.BusEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, events...)
+	return nil
+}
+
+func (f *fakeAWSEventAPI) PutMetricData(ctx context.Context, namespace string, data []aws.MetricDatum) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.metrics == nil {
+		f.metrics = map[string][]aws.MetricDatum{}
+	}
+	f.metrics[namespace] = append(f.metrics[namespace], data...)
+	return nil
+}
+
+func TestAWSEvents(t *testing.T) {
+	start := metav1.NewTime(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(start.Add(10 * time.Minute))
+	storage := resource.MustParse("1Gi")
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", UID: "uid-1"},
+		Spec:       migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web", SourceNamespace: "old", DestNamespace: "new"},
+		Status: migrationv1alpha1.StatefulSetMigrationStatus{
+			Phase:                migrationv1alpha1.PhaseCompleted,
+			StartTime:            &start,
+			CompletionTime:       &end,
+			TotalStorageMigrated: &storage,
+			MigratedPods:         []migrationv1alpha1.MigratedPodInfo{{Index: 0}},
+		},
+	}
+	api := &fakeAWSEventAPI{}
+	a := NewAWSEvents(api, "migrations", "AquaMigrations")
+	recorder := record.NewFakeRecorder(10)
+
+	a.Recorder(recorder).Event(m, corev1.EventTypeNormal, "Completed", "Migration completed")
+	// Only migrations are published
+	a.Recorder(recorder).Event(&corev1.Pod{}, corev1.EventTypeNormal, "Ignored", "not a migration")
+	a.PhaseChanged(m, migrationv1alpha1.PhaseFinalizing)
+	a.PhaseChanged(m, migrationv1alpha1.PhaseCompleted)
+	a.Finished(m)
+	if len(recorder.Events) != 2 {
+		t.Errorf("recorded %d Kubernetes events, want both", len(recorder.Events))
+	}
+
+	// Stopping flushes the queue
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if len(api.events) != 2 {
+		t.Fatalf("published %d events, want the Kubernetes event and one phase change: %+v", len(api.events), api.events)
+	}
+	for i, want := range []string{DetailTypeEvent, DetailTypePhaseChange} {
+		e := api.events[i]
+		if e.EventBus != "migrations" || e.Source != AWSEventSource || e.DetailType != want {
+			t.Errorf("event %d = %+v, want %s on the migrations bus", i, e, want)
+		}
+	}
+	var detail map[string]any
+	if err := json.Unmarshal([]byte(api.events[1].Detail), &detail); err != nil {
+		t.Fatal(err)
+	}
+	if detail["name"] != "web" || detail["phase"] != "Completed" || detail["previousPhase"] != "Finalizing" || detail["destNamespace"] != "new" {
+		t.Errorf("phase change detail = %v", detail)
+	}
+
+	metrics := map[string]float64{}
+	for _, d := range api.metrics["AquaMigrations"] {
+		if d.Dimensions["Result"] != "Completed" {
+			t.Errorf("metric %s dimensions = %v, want Result=Completed", d.Name, d.Dimensions)
+		}
+		metrics[d.Name] = d.Value
+	}
+	want := map[string]float64{"MigrationsFinished": 1, "PodsMoved": 1, "MigrationDuration": 600, "StorageMigrated": 1 << 30}
+	for name, value := range want {
+		if metrics[name] != value {
+			t.Errorf("metric %s = %v, want %v", name, metrics[name], value)
+		}
+	}
+	if _, ok := metrics["MaxPodDowntime"]; ok {
+		t.Error("MaxPodDowntime published without any known downtime")
+	}
+}
+
+func TestAWSEventsDisabled(t *testing.T) {
+	var a *AWSEvents
+	recorder := record.NewFakeRecorder(1)
+	if a.Recorder(recorder) != record.EventRecorder(recorder) {
+		t.Error("a nil AWSEvents wrapped the recorder")
+	}
+	m := &migrationv1alpha1.StatefulSetMigration{Status: migrationv1alpha1.StatefulSetMigrationStatus{Phase: migrationv1alpha1.PhaseFailed}}
+	a.PhaseChanged(m, migrationv1alpha1.PhaseMigratingPods)
+	a.Finished(m)
+
+	// Metrics only
+	api := &fakeAWSEventAPI{}
+	a = NewAWSEvents(api, "", "AquaMigrations")
+	a.PhaseChanged(m, migrationv1alpha1.PhaseMigratingPods)
+	if len(a.queue) != 0 {
+		t.Errorf("queued %d publications without an event bus", len(a.queue))
+	}
+}
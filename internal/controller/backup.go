@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+// ConditionBackupPolicies reports DLM policies and AWS Backup plans that
+// snapshot the source volumes
+const ConditionBackupPolicies = "BackupPolicies"
+
+// checkVolumeBackups sets ConditionBackupPolicies when DLM or AWS Backup
+// takes scheduled snapshots of the source volumes. Those policies select
+// volumes by tag, so they keep snapshotting a volume after it moves to the
+// destination cluster, under the source team's schedule and retention. The
+// check is advisory: when the snapshots cannot be listed it is skipped.
+func (r *StatefulSetMigrationReconciler) checkVolumeBackups(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, pvs []*corev1.PersistentVolume) {
+	logger := log.FromContext(ctx)
+
+	backups := make(map[string][]aws.VolumeBackup)
+	for _, pv := range pvs {
+		volumeID, err := getVolumeIDFromPV(pv)
+		if err != nil {
+			continue
+		}
+		volumeBackups, err := r.ebs(m).GetVolumeBackups(ctx, volumeID)
+		if err != nil {
+			logger.Error(err, "Skipping backup policy check", "volumeId", volumeID)
+			return
+		}
+		if len(volumeBackups) > 0 {
+			backups[volumeID] = volumeBackups
+		}
+	}
+
+	if len(backups) == 0 {
+		return
+	}
+	message := backupPoliciesMessage(backups, m.Spec.BackupRetag != nil)
+	logger.Info("Source volumes are snapshotted by backup policies", "volumes", len(backups))
+	r.setCondition(m, ConditionBackupPolicies, metav1.ConditionTrue, "VolumesBackedUp", message)
+}
+
+// backupPoliciesMessage describes the backup policies of each volume and
+// what happens to them once the volumes move
+func backupPoliciesMessage(backups map[string][]aws.VolumeBackup, retag bool) string {
+	volumeIDs := make([]string, 0, len(backups))
+	for volumeID := range backups {
+		volumeIDs = append(volumeIDs, volumeID)
+	}
+	sort.Strings(volumeIDs)
+
+	var b strings.Builder
+	b.WriteString("Backup policies snapshot the source volumes and will keep doing so after they move: ")
+	for i, volumeID := range volumeIDs {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		policies := make([]string, 0, len(backups[volumeID]))
+		for _, backup := range backups[volumeID] {
+			policies = append(policies, backup.String())
+		}
+		fmt.Fprintf(&b, "%s: %s", volumeID, strings.Join(policies, ", "))
+	}
+	if retag {
+		b.WriteString(". spec.backupRetag retags each volume once it has moved; existing snapshots keep their tags and retention")
+	} else {
+		b.WriteString(". Set spec.backupRetag to retag the volumes for the destination team's policies")
+	}
+	return b.String()
+}
+
+// retagVolume applies spec.backupRetag to a volume that is attached in the
+// destination. The pod has already moved, so a failure is recorded in the
+// history, and so in the report, rather than failing the migration.
+func (r *StatefulSetMigrationReconciler) retagVolume(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, volumeID string) {
+	retag := m.Spec.BackupRetag
+	if retag == nil {
+		return
+	}
+
+	if err := r.ebs(m).RetagVolume(ctx, volumeID, retag.Set, retag.Remove); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to retag volume", "volumeId", volumeID)
+		r.recordHistory(m, StepRetagVolume, volumeID, migrationv1alpha1.HistoryResultFailed, err.Error())
+		return
+	}
+	r.recordHistory(m, StepRetagVolume, volumeID, migrationv1alpha1.HistoryResultSucceeded,
+		fmt.Sprintf("Set %d tags, removed %d", len(retag.Set), len(retag.Remove)))
+}
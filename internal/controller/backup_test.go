@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+func TestBackupPoliciesMessage(t *testing.T) {
+	latest := time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)
+	backups := map[string][]aws.VolumeBackup{
+		"vol-2": {{Manager: aws.BackupManagerAWSBackup, Snapshots: 7, Latest: latest}},
+		"vol-1": {
+			{Manager: aws.BackupManagerDLM, PolicyID: "policy-a", Snapshots: 3, Latest: latest},
+			{Manager: aws.BackupManagerDLM, PolicyID: "policy-b", Snapshots: 1, Latest: latest},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		retag bool
+		want  string
+	}{
+		{
+			name: "without retag",
+			want: "Set spec.backupRetag",
+		},
+		{
+			name:  "with retag",
+			retag: true,
+			want:  "existing snapshots keep their tags and retention",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := backupPoliciesMessage(backups, tt.retag)
+			volumes := "vol-1: DLM policy policy-a (3 snapshots, latest 2024-05-01T02:00:00Z), DLM policy policy-b (1 snapshots, latest 2024-05-01T02:00:00Z); " +
+				"vol-2: AWS Backup (7 snapshots, latest 2024-05-01T02:00:00Z)"
+			if !strings.Contains(msg, volumes) {
+				t.Errorf("backupPoliciesMessage() = %q, want volumes sorted as %q", msg, volumes)
+			}
+			if !strings.Contains(msg, tt.want) {
+				t.Errorf("backupPoliciesMessage() = %q, want it to contain %q", msg, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+const (
+	// DefaultBandwidthProbeTimeout is how long a bandwidth probe may take by
+	// default
+	DefaultBandwidthProbeTimeout = 5 * time.Minute
+
+	// StepProbeBandwidth records measuring the throughput between the clusters
+	StepProbeBandwidth = "ProbeBandwidth"
+)
+
+// copiedVolumes returns the volumes planned to be copied, by a snapshot
+// restore or a transfer, rather than reattached
+func copiedVolumes(m *migrationv1alpha1.StatefulSetMigration) []string {
+	var volumeIDs []string
+	for _, decision := range m.Status.VolumeStrategies {
+		if decision.Strategy != migrationv1alpha1.VolumeStrategyReattach {
+			volumeIDs = append(volumeIDs, decision.VolumeID)
+		}
+	}
+	return volumeIDs
+}
+
+// probeBandwidth runs spec.bandwidthProbe at pre-flight when any volume is
+// copied, records the throughput and the copy time estimated from it in
+// status, and fails when the copies would run past the end of the
+// maintenance window, counted from spec.schedule.startTime or else now
+func (r *StatefulSetMigrationReconciler) probeBandwidth(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient) error {
+	cfg := m.Spec.BandwidthProbe
+	volumeIDs := copiedVolumes(m)
+	if cfg == nil || len(volumeIDs) == 0 {
+		return nil
+	}
+	var gib int64
+	for _, volumeID := range volumeIDs {
+		info, err := r.ebs(m).GetVolumeInfo(ctx, volumeID)
+		if err != nil {
+			return err
+		}
+		gib += int64(info.Size)
+	}
+
+	measured, err := r.runBandwidthProbe(ctx, m, sourceCC, destCC)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.recordHistory(m, StepProbeBandwidth, m.Name, migrationv1alpha1.HistoryResultFailed, err.Error())
+		}
+		return err
+	}
+	bytesPerSecond := measured.BytesPerSecond()
+	estimate := migration.CopyDuration(gib, bytesPerSecond)
+	m.Status.BandwidthProbe = &migrationv1alpha1.BandwidthProbeResult{
+		Time:                  metav1.NewTime(r.clock().Now()),
+		LatencyMilliseconds:   measured.Latency.Milliseconds(),
+		BytesPerSecond:        bytesPerSecond,
+		CopyGiB:               gib,
+		EstimatedCopyDuration: metav1.Duration{Duration: estimate},
+	}
+	throughput := fmt.Sprintf("%.1f MiB/s", float64(bytesPerSecond)/(1<<20))
+	r.recordHistory(m, StepProbeBandwidth, m.Name, migrationv1alpha1.HistoryResultSucceeded,
+		fmt.Sprintf("%s with %s latency from the source to the destination; copying %d GiB takes about %s",
+			throughput, measured.Latency, gib, estimate.Round(time.Minute)))
+
+	end, ok, _ := windowEnd(m)
+	if !ok {
+		return nil
+	}
+	start := r.clock().Now()
+	if s := m.Spec.Schedule; s != nil && s.StartTime.After(start) {
+		start = s.StartTime.Time
+	}
+	if finish := start.Add(estimate); finish.After(end) {
+		return fmt.Errorf("copying %d GiB at %s takes about %s, past the end of the maintenance window at %s",
+			gib, throughput, estimate.Round(time.Minute), end.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// runBandwidthProbe starts the server pod in the destination, waits for it
+// to be Ready and times the client pod's fetch from its pod IP in the
+// source namespace. Both pods are deleted afterwards either way; pods left
+// by an earlier attempt are replaced.
+func (r *StatefulSetMigrationReconciler) runBandwidthProbe(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient) (migration.BandwidthMeasurement, error) {
+	cfg := m.Spec.BandwidthProbe
+	sizeMiB, port := cfg.SizeMiB, cfg.Port
+	if sizeMiB == 0 {
+		sizeMiB = migration.DefaultBandwidthProbeSizeMiB
+	}
+	if port == 0 {
+		port = migration.DefaultBandwidthProbePort
+	}
+	namespace := cfg.ServerNamespace
+	if namespace == "" {
+		namespace = m.Spec.DestNamespace
+	}
+	timeout := DefaultBandwidthProbeTimeout
+	if cfg.Timeout != nil {
+		timeout = cfg.Timeout.Duration
+	}
+
+	server := migration.BandwidthServerPod(namespace, m.Name, cfg.Image, sizeMiB, port)
+	if err := r.replaceTemporaryPod(ctx, destCC, server); err != nil {
+		return migration.BandwidthMeasurement{}, err
+	}
+	defer r.deleteTemporaryPod(ctx, destCC, server)
+	if err := r.waitForPodReady(ctx, destCC, server.Namespace, server.Name, timeout); err != nil {
+		return migration.BandwidthMeasurement{}, fmt.Errorf("bandwidth probe server: %w", err)
+	}
+	if err := destCC.Client.Get(ctx, types.NamespacedName{Namespace: server.Namespace, Name: server.Name}, server); err != nil {
+		return migration.BandwidthMeasurement{}, fmt.Errorf("failed to get bandwidth probe server %s: %w", server.Name, err)
+	}
+
+	client := migration.BandwidthClientPod(m.Spec.SourceNamespace, m.Name, cfg.Image, server.Status.PodIP, sizeMiB, port)
+	log.FromContext(ctx).Info("Probing bandwidth from the source to the destination", "address", server.Status.PodIP, "sizeMiB", sizeMiB)
+	if err := r.replaceTemporaryPod(ctx, sourceCC, client); err != nil {
+		return migration.BandwidthMeasurement{}, err
+	}
+	defer r.deleteTemporaryPod(ctx, sourceCC, client)
+	key := types.NamespacedName{Namespace: client.Namespace, Name: client.Name}
+	message, err := r.waitForCommandPod(ctx, sourceCC, key, timeout, StepProbeBandwidth, migration.BandwidthClientResult)
+	if err != nil {
+		return migration.BandwidthMeasurement{}, err
+	}
+	return migration.ParseBandwidthResult(message)
+}
+
+// replaceTemporaryPod creates a temporary pod, first deleting one of the
+// same name left by an earlier attempt
+func (r *StatefulSetMigrationReconciler) replaceTemporaryPod(ctx context.Context, cc *multicluster.ClusterClient, pod *corev1.Pod) error {
+	key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	if err := cc.Client.Get(ctx, key, &corev1.Pod{}); err == nil {
+		if err := deleteIfExists(ctx, cc, key, &corev1.Pod{}); err != nil {
+			return fmt.Errorf("failed to delete earlier pod %s: %w", pod.Name, err)
+		}
+		if err := r.waitForPodDeletion(ctx, cc, pod.Namespace, pod.Name); err != nil {
+			return err
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err := cc.Client.Create(ctx, pod); err != nil {
+		return fmt.Errorf("failed to create pod %s: %w", pod.Name, err)
+	}
+	return nil
+}
+
+// deleteTemporaryPod deletes a temporary pod, logging a failure
+func (r *StatefulSetMigrationReconciler) deleteTemporaryPod(ctx context.Context, cc *multicluster.ClusterClient, pod *corev1.Pod) {
+	if err := cc.Client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+		log.FromContext(ctx).Error(err, "Failed to delete temporary pod", "pod", pod.Name)
+	}
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestProbeBandwidth(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		// windowEnd is when the maintenance window closes, after now
+		windowEnd time.Duration
+		wantErr   bool
+	}{
+		// 1 GiB/s copies 1024 GiB in about 17m
+		{name: "copies fit the window", windowEnd: time.Hour},
+		{name: "copies run past the window", windowEnd: 10 * time.Minute, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			var probed string
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					pod := obj.(*corev1.Pod)
+					switch pod.Name {
+					case migration.BandwidthServerPodName("web"):
+						pod.Status = corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.1.2.3", Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}
+					case migration.BandwidthClientPodName("web"):
+						for _, env := range pod.Spec.Containers[0].Env {
+							if env.Name == "PROBE_ADDRESS" {
+								probed = env.Value
+							}
+						}
+						pod.Status = corev1.PodStatus{Phase: corev1.PodSucceeded, ContainerStatuses: []corev1.ContainerStatus{{
+							State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "latencyMs=3 bytes=268435456 nanos=250000000"}},
+						}}}
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}).Build()
+			clk := clocktesting.NewFakeClock(now)
+			api := &quotaEC2{sizes: map[string]int32{"vol-a": 512, "vol-b": 512}}
+			r := &StatefulSetMigrationReconciler{Clock: clk, EBSClient: aws.NewEBSClientFromAPI(api, clk, "us-east-1")}
+			m := &migrationv1alpha1.StatefulSetMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+					SourceNamespace: "old",
+					DestNamespace:   "new",
+					BandwidthProbe:  &migrationv1alpha1.BandwidthProbeConfig{},
+					Schedule:        &migrationv1alpha1.ScheduleConfig{StartTime: metav1.NewTime(now), EndTime: &metav1.Time{Time: now.Add(tt.windowEnd)}},
+				},
+			}
+			m.Status.VolumeStrategies = []migrationv1alpha1.VolumeStrategyDecision{
+				{VolumeID: "vol-a", Strategy: migrationv1alpha1.VolumeStrategyTransfer},
+				{VolumeID: "vol-b", Strategy: migrationv1alpha1.VolumeStrategySnapshotRestore},
+				{VolumeID: "vol-c", Strategy: migrationv1alpha1.VolumeStrategyReattach},
+			}
+
+			// One fake cluster stands in for both the source and the destination
+			cc := &multicluster.ClusterClient{Client: c}
+			done := make(chan error, 1)
+			go func() {
+				done <- r.probeBandwidth(ctx, m, cc, cc)
+			}()
+			var err error
+			deadline := time.After(10 * time.Second)
+		wait:
+			for {
+				select {
+				case err = <-done:
+					break wait
+				case <-deadline:
+					t.Fatal("probeBandwidth() did not return on the fake clock")
+				case <-time.After(time.Millisecond):
+					if clk.HasWaiters() {
+						clk.Step(time.Second)
+					}
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("probeBandwidth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "past the end of the maintenance window") {
+				t.Errorf("probeBandwidth() error = %v, want the window exceeded", err)
+			}
+			if probed != "10.1.2.3" {
+				t.Errorf("probed address = %q, want the server pod IP", probed)
+			}
+			got := m.Status.BandwidthProbe
+			if got == nil || got.BytesPerSecond != 1<<30 || got.CopyGiB != 1024 || got.LatencyMilliseconds != 3 || got.EstimatedCopyDuration.Duration != 1024*time.Second {
+				t.Errorf("status.bandwidthProbe = %+v, want 1 GiB/s and 1024 GiB in 17m4s", got)
+			}
+			for _, name := range []string{migration.BandwidthServerPodName("web"), migration.BandwidthClientPodName("web")} {
+				namespace := "new"
+				if name == migration.BandwidthClientPodName("web") {
+					namespace = "old"
+				}
+				err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &corev1.Pod{})
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("pod %s after probeBandwidth(): err = %v, want it deleted", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestProbeBandwidthSkipsReattach(t *testing.T) {
+	m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{BandwidthProbe: &migrationv1alpha1.BandwidthProbeConfig{}}}
+	m.Status.VolumeStrategies = []migrationv1alpha1.VolumeStrategyDecision{{VolumeID: "vol-a", Strategy: migrationv1alpha1.VolumeStrategyReattach}}
+	r := &StatefulSetMigrationReconciler{}
+	if err := r.probeBandwidth(context.Background(), m, nil, nil); err != nil || m.Status.BandwidthProbe != nil {
+		t.Errorf("probeBandwidth() with every volume reattached = %v, %+v, want nothing probed", err, m.Status.BandwidthProbe)
+	}
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// checkCapacity fails pre-flight when the destination has no EBS CSI driver,
+// the destination nodes the pods can run on cannot take the StatefulSet's
+// volumes, or the strategy would exceed the account's EBS quotas. The last
+// two can be overridden with overrides.ignoreAttachLimits and
+// overrides.ignoreQuotaCheck.
+func (r *StatefulSetMigrationReconciler) checkCapacity(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient, podSpec *corev1.PodSpec, pvs []*corev1.PersistentVolume) error {
+	logger := log.FromContext(ctx)
+
+	nodeList := &corev1.NodeList{}
+	if err := destCC.Client.List(ctx, nodeList); err != nil {
+		return fmt.Errorf("failed to list destination nodes: %w", err)
+	}
+	nodes := migration.NodesForPod(nodeList.Items, podSpec)
+	if len(nodes) == 0 && migration.PodOS(podSpec) == corev1.Windows {
+		return fmt.Errorf("the destination has no Windows nodes whose taints the pods tolerate; add a Windows node group")
+	}
+	// Without the CSIDriver object the EBS CSI driver is not installed, and
+	// nothing would attach the volumes
+	if err := destCC.Client.Get(ctx, types.NamespacedName{Name: migration.EBSCSIDriver}, &storagev1.CSIDriver{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("the destination has no CSIDriver %s; install the EBS CSI driver", migration.EBSCSIDriver)
+		}
+		return fmt.Errorf("failed to get destination CSIDriver %s: %w", migration.EBSCSIDriver, err)
+	}
+
+	var problems []string
+	if overrides(m).IgnoreAttachLimits {
+		logger.Info("Skipping the attach limit check because it is overridden")
+	} else {
+		csiNodes := &storagev1.CSINodeList{}
+		if err := destCC.Client.List(ctx, csiNodes); err != nil {
+			return fmt.Errorf("failed to list destination CSINodes: %w", err)
+		}
+		attachments := &storagev1.VolumeAttachmentList{}
+		if err := destCC.Client.List(ctx, attachments); err != nil {
+			return fmt.Errorf("failed to list destination VolumeAttachments: %w", err)
+		}
+		capacity := migration.AttachCapacity(nodes, csiNodes.Items, attachments.Items)
+		problems = migration.CheckAttachCapacity(migration.VolumesByZone(pvs), capacity)
+	}
+
+	if overrides(m).IgnoreQuotaCheck {
+		logger.Info("Skipping the EBS quota check because it is overridden")
+	} else {
+		quotaProblems, err := r.checkQuotas(ctx, m)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, quotaProblems...)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkQuotas returns the EBS quotas the migration would exceed, in the
+// source account and, for a transfer, in the destination account
+func (r *StatefulSetMigrationReconciler) checkQuotas(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) ([]string, error) {
+	sourcePlan, destPlan, err := r.quotaPlans(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	problems, err := quotaProblems(ctx, r.ebs(m), sourcePlan)
+	if err != nil || destPlan.Empty() {
+		return problems, err
+	}
+	dest, err := r.ebs(m).AssumeRole(m.Spec.DestAWS.RoleARN)
+	if err != nil {
+		return nil, err
+	}
+	destProblems, err := quotaProblems(ctx, dest, destPlan)
+	if err != nil {
+		return nil, err
+	}
+	for _, problem := range destProblems {
+		problems = append(problems, fmt.Sprintf("destination account %s: %s", m.Spec.DestAWS.AccountID, problem))
+	}
+	return problems, nil
+}
+
+// quotaProblems checks a plan against an account's quotas and its current
+// usage of them. A plan that consumes nothing skips the lookups.
+func quotaProblems(ctx context.Context, ebs *aws.EBSClient, plan aws.QuotaPlan) ([]string, error) {
+	if plan.Empty() {
+		return nil, nil
+	}
+	quotas, err := ebs.GetEBSQuotas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return checkQuotaUsage(ctx, ebs, plan, quotas)
+}
+
+// checkQuotaUsage counts the account's snapshots and its storage of the
+// volume types the plan creates, and returns the quotas the two together
+// would exceed
+func checkQuotaUsage(ctx context.Context, ebs *aws.EBSClient, plan aws.QuotaPlan, quotas map[string]float64) ([]string, error) {
+	usage, err := ebs.GetEBSUsage(ctx, plan.VolumeTypes())
+	if err != nil {
+		return nil, err
+	}
+	return aws.CheckQuotas(plan, quotas, *usage), nil
+}
+
+// quotaPlans returns the EBS quotas the migration consumes in the source
+// account and, with destAWS.transferVolumes, in the destination account.
+// Reattaching moves a volume, so it creates no snapshots or storage. A
+// volume pre-flight planned to restore takes a snapshot and a new volume of
+// its size. A transfer takes a snapshot in the source account, and a copy
+// and a new volume in the destination, where a copy runs for each pod moving
+// at once. With the DetachBlocked fallback, any reattached volume may be
+// restored instead, so each counts as a restore.
+func (r *StatefulSetMigrationReconciler) quotaPlans(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (aws.QuotaPlan, aws.QuotaPlan, error) {
+	var source, dest aws.QuotaPlan
+	transfers := 0
+	for _, decision := range m.Status.VolumeStrategies {
+		switch decision.Strategy {
+		case migrationv1alpha1.VolumeStrategyReattach:
+			if !fallsBackOn(m, migrationv1alpha1.FallbackTriggerDetachBlocked) && volumeFallback(m, decision.VolumeID) == nil {
+				continue
+			}
+		case migrationv1alpha1.VolumeStrategySnapshotRestore, migrationv1alpha1.VolumeStrategyTransfer:
+		default:
+			continue
+		}
+		info, err := r.ebs(m).GetVolumeInfo(ctx, decision.VolumeID)
+		if err != nil {
+			return source, dest, err
+		}
+		source.Snapshots++
+		if decision.Strategy != migrationv1alpha1.VolumeStrategyTransfer {
+			addNewVolume(&source, info)
+			continue
+		}
+		transfers++
+		dest.Snapshots++
+		addNewVolume(&dest, info)
+	}
+	dest.ConcurrentCopies = min(transfers, maxParallelPods(m))
+	return source, dest, nil
+}
+
+// addNewVolume adds a new volume of the given volume's type and size to a plan
+func addNewVolume(plan *aws.QuotaPlan, info *aws.VolumeInfo) {
+	if plan.NewVolumeGiB == nil {
+		plan.NewVolumeGiB = make(map[ec2types.VolumeType]int64)
+	}
+	plan.NewVolumeGiB[info.VolumeType] += int64(info.Size)
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+// quotaEC2 describes each volume by ID as a gp3 volume of sizes[id] GiB,
+// and answers a volume-type filter with a volume of existingGiB and a
+// snapshot count with snapshots
+type quotaEC2 struct {
+	aws.EC2API
+	sizes       map[string]int32
+	existingGiB int32
+	snapshots   int
+}
+
+func (f *quotaEC2) DescribeVolumes(_ context.Context, in *ec2.DescribeVolumesInput, _ ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	if len(in.VolumeIds) == 0 {
+		return &ec2.DescribeVolumesOutput{Volumes: []ec2types.Volume{{
+			VolumeId: awssdk.String("vol-existing"), VolumeType: ec2types.VolumeTypeGp3, Size: awssdk.Int32(f.existingGiB),
+		}}}, nil
+	}
+	return &ec2.DescribeVolumesOutput{Volumes: []ec2types.Volume{{
+		VolumeId: awssdk.String(in.VolumeIds[0]), VolumeType: ec2types.VolumeTypeGp3, Size: awssdk.Int32(f.sizes[in.VolumeIds[0]]),
+	}}}, nil
+}
+
+func (f *quotaEC2) DescribeSnapshots(context.Context, *ec2.DescribeSnapshotsInput, ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+	return &ec2.DescribeSnapshotsOutput{Snapshots: make([]ec2types.Snapshot, f.snapshots)}, nil
+}
+
+func TestQuotaPlans(t *testing.T) {
+	decisions := []migrationv1alpha1.VolumeStrategyDecision{
+		{VolumeID: "vol-a", Strategy: migrationv1alpha1.VolumeStrategyReattach},
+		{VolumeID: "vol-b", Strategy: migrationv1alpha1.VolumeStrategySnapshotRestore},
+	}
+	transfers := []migrationv1alpha1.VolumeStrategyDecision{
+		{VolumeID: "vol-a", Strategy: migrationv1alpha1.VolumeStrategyTransfer},
+		{VolumeID: "vol-b", Strategy: migrationv1alpha1.VolumeStrategyTransfer},
+		{VolumeID: "vol-c", Strategy: migrationv1alpha1.VolumeStrategyTransfer},
+	}
+	tests := []struct {
+		name       string
+		spec       migrationv1alpha1.StatefulSetMigrationSpec
+		strategies []migrationv1alpha1.VolumeStrategyDecision
+		wantSource aws.QuotaPlan
+		wantDest   aws.QuotaPlan
+	}{
+		{
+			name:       "restore without the DetachBlocked fallback",
+			spec:       migrationv1alpha1.StatefulSetMigrationSpec{StrategyFallback: &migrationv1alpha1.StrategyFallback{On: []migrationv1alpha1.FallbackTrigger{migrationv1alpha1.FallbackTriggerZoneMismatch}}},
+			strategies: decisions,
+			wantSource: aws.QuotaPlan{Snapshots: 1, NewVolumeGiB: map[ec2types.VolumeType]int64{ec2types.VolumeTypeGp3: 200}},
+		},
+		{
+			name:       "reattached volumes may fall back on a blocked detach",
+			spec:       migrationv1alpha1.StatefulSetMigrationSpec{StrategyFallback: &migrationv1alpha1.StrategyFallback{}},
+			strategies: decisions,
+			wantSource: aws.QuotaPlan{Snapshots: 2, NewVolumeGiB: map[ec2types.VolumeType]int64{ec2types.VolumeTypeGp3: 300}},
+		},
+		{
+			name: "transfer copies into the destination account",
+			spec: migrationv1alpha1.StatefulSetMigrationSpec{
+				MaxParallelPods: 2,
+				DestAWS:         &migrationv1alpha1.DestAWSConfig{AccountID: "222222222222", TransferVolumes: true},
+			},
+			strategies: transfers,
+			wantSource: aws.QuotaPlan{Snapshots: 3},
+			wantDest:   aws.QuotaPlan{Snapshots: 3, ConcurrentCopies: 2, NewVolumeGiB: map[ec2types.VolumeType]int64{ec2types.VolumeTypeGp3: 600}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &quotaEC2{sizes: map[string]int32{"vol-a": 100, "vol-b": 200, "vol-c": 300}}
+			r := &StatefulSetMigrationReconciler{EBSClient: aws.NewEBSClientFromAPI(api, clocktesting.NewFakeClock(time.Now()), "us-east-1")}
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: tt.spec}
+			m.Status.VolumeStrategies = tt.strategies
+
+			source, dest, err := r.quotaPlans(context.Background(), m)
+			if err != nil {
+				t.Fatalf("quotaPlans() error = %v", err)
+			}
+			if !equalQuotaPlans(source, tt.wantSource) || !equalQuotaPlans(dest, tt.wantDest) {
+				t.Errorf("quotaPlans() = %+v, %+v, want %+v, %+v", source, dest, tt.wantSource, tt.wantDest)
+			}
+		})
+	}
+}
+
+func TestCheckQuotaUsage(t *testing.T) {
+	quotas := map[string]float64{
+		aws.QuotaSnapshotsPerRegion:                             1000,
+		"Storage for General Purpose SSD (gp3) volumes, in TiB": 50,
+	}
+	plan := aws.QuotaPlan{Snapshots: 1, NewVolumeGiB: map[ec2types.VolumeType]int64{ec2types.VolumeTypeGp3: 1000}}
+
+	// The plan fits the quotas alone, but not with what the account already uses
+	api := &quotaEC2{existingGiB: 50500, snapshots: 1000}
+	problems, err := checkQuotaUsage(context.Background(), aws.NewEBSClientFromAPI(api, clocktesting.NewFakeClock(time.Now()), "us-east-1"), plan, quotas)
+	if err != nil {
+		t.Fatalf("checkQuotaUsage() error = %v", err)
+	}
+	if len(problems) != 2 || !strings.Contains(problems[0], aws.QuotaSnapshotsPerRegion) || !strings.Contains(problems[1], "50500 GiB provisioned") {
+		t.Errorf("checkQuotaUsage() = %v, want the snapshot and gp3 storage quotas exceeded", problems)
+	}
+}
+
+func equalQuotaPlans(a, b aws.QuotaPlan) bool {
+	if a.Snapshots != b.Snapshots || a.ConcurrentCopies != b.ConcurrentCopies || len(a.NewVolumeGiB) != len(b.NewVolumeGiB) {
+		return false
+	}
+	for volumeType, gib := range a.NewVolumeGiB {
+		if b.NewVolumeGiB[volumeType] != gib {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,333 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+const (
+	// ConditionWaitingForCapacity reports that a moved pod waits for a
+	// destination node under spec.capacityWait
+	ConditionWaitingForCapacity = "WaitingForCapacity"
+
+	// EventWaitingForCapacity is recorded when a moved pod cannot be scheduled
+	// and the migration starts waiting for a node
+	EventWaitingForCapacity = "WaitingForCapacity"
+
+	// EventCapacityAvailable is recorded when the waiting pod is scheduled
+	EventCapacityAvailable = "CapacityAvailable"
+
+	// StepCapacityWait is the history step of a wait for destination capacity
+	StepCapacityWait = "CapacityWait"
+
+	// DefaultCapacityWaitTimeout is how long a pod waits to be scheduled by default
+	DefaultCapacityWaitTimeout = 6 * time.Hour
+
+	// capacityRecheckInterval is how often a waiting pod is checked when no
+	// node event arrives, such as when the node watch is unavailable
+	capacityRecheckInterval = time.Minute
+
+	// capacityWatchRetry is the least time between two watches of the same
+	// zone's nodes
+	capacityWatchRetry = 5 * time.Second
+)
+
+// UnschedulableError means a moved pod did not become ready because the
+// destination scheduler could not place it
+type UnschedulableError struct {
+	// Pod is the destination pod
+	Pod string
+
+	// Zone is the availability zone of the pod's volume
+	Zone string
+
+	// Message is the scheduler's reason, as in its FailedScheduling event
+	Message string
+
+	// Err is the error the wait for the pod ended with
+	Err error
+}
+
+func (e *UnschedulableError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Err, e.Message)
+}
+
+func (e *UnschedulableError) Unwrap() error {
+	return e.Err
+}
+
+// capacityWaitTimeout returns how long a pod may wait to be scheduled
+func capacityWaitTimeout(m *migrationv1alpha1.StatefulSetMigration) time.Duration {
+	if m.Spec.CapacityWait != nil && m.Spec.CapacityWait.Timeout != nil {
+		return m.Spec.CapacityWait.Timeout.Duration
+	}
+	return DefaultCapacityWaitTimeout
+}
+
+// unschedulablePod returns an UnschedulableError wrapping err when the
+// destination pod is Pending because the scheduler could not place it, or
+// err as it is
+func unschedulablePod(ctx context.Context, destCC *multicluster.ClusterClient, namespace, name string, err error) error {
+	pod := &corev1.Pod{}
+	if found, getErr := getIfExists(ctx, destCC, types.NamespacedName{Namespace: namespace, Name: name}, pod); getErr != nil || !found {
+		return err
+	}
+	if pod.Spec.NodeName != "" {
+		return err
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+			return &UnschedulableError{Pod: name, Zone: podVolumeZone(ctx, destCC, pod), Message: cond.Message, Err: err}
+		}
+	}
+	return err
+}
+
+// podVolumeZone returns the zone of the first of the pod's volumes that is
+// pinned to one, which is where a node for the pod must be
+func podVolumeZone(ctx context.Context, cc *multicluster.ClusterClient, pod *corev1.Pod) string {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: volume.PersistentVolumeClaim.ClaimName}, pvc); err != nil || pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv := &corev1.PersistentVolume{}
+		if err := cc.Client.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+			continue
+		}
+		if zone := translate.AvailabilityZone(pv); zone != "" {
+			return zone
+		}
+	}
+	return ""
+}
+
+// startCapacityWait records that the migration waits for a destination node
+// for the pod of err, and watches the nodes of its zone
+func (r *StatefulSetMigrationReconciler) startCapacityWait(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, err *UnschedulableError) (ctrl.Result, error) {
+	m.Status.CapacityWait = &migrationv1alpha1.CapacityWaitStatus{
+		Pod:     err.Pod,
+		Zone:    err.Zone,
+		Message: err.Message,
+		Since:   metav1.NewTime(r.clock().Now()),
+	}
+	message := fmt.Sprintf("Waiting up to %s for a destination node for pod %s", capacityWaitTimeout(m), err.Pod)
+	if err.Zone != "" {
+		message += " in " + err.Zone
+	}
+	message += ": " + err.Message
+	log.FromContext(ctx).Info("Destination pod cannot be scheduled, waiting for capacity", "pod", err.Pod, "zone", err.Zone, "reason", err.Message)
+	r.setCondition(m, ConditionWaitingForCapacity, metav1.ConditionTrue, "Unschedulable", message)
+	r.recordHistory(m, StepCapacityWait, historyObject("Pod", m.Spec.DestNamespace, err.Pod), migrationv1alpha1.HistoryResultStarted, message)
+	r.event(m, corev1.EventTypeWarning, EventWaitingForCapacity, message)
+	if destCC, destErr := r.getDestClient(ctx, m); destErr == nil {
+		r.capacity.watch(m, destCC.Clientset, err.Zone)
+	}
+
+	if err := r.Status().Update(ctx, m); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: capacityRecheckInterval}, nil
+}
+
+// waitForCapacity holds the migration while the pod in status.capacityWait
+// is unscheduled, and fails it once spec.capacityWait.timeout has passed.
+// When the pod has been scheduled it waits for every pod of the batch to be
+// Ready, so the move that resumes finds them migrated. It reports whether the
+// migration is still held.
+func (r *StatefulSetMigrationReconciler) waitForCapacity(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, bool, error) {
+	logger := log.FromContext(ctx)
+	wait := m.Status.CapacityWait
+	timeout := capacityWaitTimeout(m)
+	elapsed := r.clock().Since(wait.Since.Time)
+	object := historyObject("Pod", m.Spec.DestNamespace, wait.Pod)
+
+	destClient, err := r.getDestClient(ctx, m)
+	if err != nil {
+		result, err := r.failMigration(ctx, m, fmt.Sprintf("Failed to get destination client: %v", err))
+		return result, true, err
+	}
+
+	pod := &corev1.Pod{}
+	found, err := getIfExists(ctx, destClient, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: wait.Pod}, pod)
+	if err != nil {
+		return ctrl.Result{}, true, err
+	}
+	if !found || pod.Spec.NodeName == "" {
+		if elapsed >= timeout {
+			r.capacity.stop(m)
+			reason := fmt.Sprintf("Pod %s was not scheduled in the destination within %s: %s", wait.Pod, timeout, wait.Message)
+			r.setCondition(m, ConditionWaitingForCapacity, metav1.ConditionFalse, "TimedOut", reason)
+			r.recordHistory(m, StepCapacityWait, object, migrationv1alpha1.HistoryResultFailed, reason)
+			result, err := r.failMigration(ctx, m, reason)
+			return result, true, err
+		}
+		r.capacity.watch(m, destClient.Clientset, wait.Zone)
+		return ctrl.Result{RequeueAfter: min(capacityRecheckInterval, timeout-elapsed)}, true, nil
+	}
+
+	r.capacity.stop(m)
+	waited := elapsed.Round(time.Second)
+	message := fmt.Sprintf("Pod %s scheduled on node %s after %s", wait.Pod, pod.Spec.NodeName, waited)
+	logger.Info("Destination pod scheduled, resuming", "pod", wait.Pod, "node", pod.Spec.NodeName, "waited", waited.String())
+	r.setCondition(m, ConditionWaitingForCapacity, metav1.ConditionFalse, "Scheduled", message)
+	r.recordHistory(m, StepCapacityWait, object, migrationv1alpha1.HistoryResultSucceeded, message)
+	r.event(m, corev1.EventTypeNormal, EventCapacityAvailable, message)
+	m.Status.CapacityWait = nil
+
+	// Every pod of the batch has moved by the time one waits to be scheduled
+	for _, position := range nextPositions(m) {
+		index := ordinalAt(m, position)
+		if podMigrated(m, index) {
+			continue
+		}
+		if err := r.waitForDestPod(ctx, m, destClient, fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, index)); err != nil {
+			var unschedulable *UnschedulableError
+			if errors.As(err, &unschedulable) && m.Spec.CapacityWait != nil {
+				result, err := r.startCapacityWait(ctx, m, unschedulable)
+				return result, true, err
+			}
+			result, err := r.retryOrFail(ctx, m, fmt.Sprintf("Failed to migrate pod %d", index), err)
+			return result, true, err
+		}
+	}
+	return ctrl.Result{}, false, nil
+}
+
+// capacityWatches watches the destination nodes in the zone of each
+// migration waiting for capacity, and enqueues the migration when one of
+// them becomes schedulable, so it resumes without waiting for its recheck
+type capacityWatches struct {
+	clock  clock.Clock
+	events chan event.GenericEvent
+
+	mu      sync.Mutex
+	cancels map[types.UID]context.CancelFunc
+}
+
+// newCapacityWatches returns watches that send their events to a channel of
+// the given size
+func newCapacityWatches(clk clock.Clock, size int) *capacityWatches {
+	return &capacityWatches{
+		clock:   clk,
+		events:  make(chan event.GenericEvent, size),
+		cancels: make(map[types.UID]context.CancelFunc),
+	}
+}
+
+// watch starts watching the nodes in zone for the migration, unless it is
+// watched already. A nil set of watches does nothing.
+func (w *capacityWatches) watch(m *migrationv1alpha1.StatefulSetMigration, clientset kubernetes.Interface, zone string) {
+	if w == nil || clientset == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.cancels[m.UID]; ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancels[m.UID] = cancel
+	target := &migrationv1alpha1.StatefulSetMigration{ObjectMeta: metav1.ObjectMeta{Namespace: m.Namespace, Name: m.Name}}
+	go w.run(ctx, clientset, zone, target)
+}
+
+// stop stops watching for the migration
+func (w *capacityWatches) stop(m *migrationv1alpha1.StatefulSetMigration) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if cancel, ok := w.cancels[m.UID]; ok {
+		cancel()
+		delete(w.cancels, m.UID)
+	}
+}
+
+// run watches the nodes in zone, every zone when it is empty, until ctx
+// ends, sending an event for target whenever a node becomes schedulable.
+// The API server closes watches after a while, so it watches again.
+func (w *capacityWatches) run(ctx context.Context, clientset kubernetes.Interface, zone string, target *migrationv1alpha1.StatefulSetMigration) {
+	opts := metav1.ListOptions{}
+	if zone != "" {
+		opts.LabelSelector = labels.Set{corev1.LabelTopologyZone: zone}.String()
+	}
+	schedulable := make(map[string]bool)
+	for {
+		started := w.clock.Now()
+		if watcher, err := clientset.CoreV1().Nodes().Watch(ctx, opts); err == nil {
+			w.receive(ctx, watcher, schedulable, target)
+			watcher.Stop()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.clock.After(capacityWatchRetry - w.clock.Since(started)):
+		}
+	}
+}
+
+// receive handles the events of one watch until it closes or ctx ends
+func (w *capacityWatches) receive(ctx context.Context, watcher watch.Interface, schedulable map[string]bool, target *migrationv1alpha1.StatefulSetMigration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-watcher.ResultChan():
+			if !ok {
+				return
+			}
+			node, isNode := ev.Object.(*corev1.Node)
+			if !isNode {
+				continue
+			}
+			if ev.Type == watch.Deleted {
+				delete(schedulable, node.Name)
+				continue
+			}
+			now := nodeSchedulable(node)
+			if now && !schedulable[node.Name] {
+				select {
+				case w.events <- event.GenericEvent{Object: target}:
+				default:
+				}
+			}
+			schedulable[node.Name] = now
+		}
+	}
+}
+
+// nodeSchedulable reports whether a node is Ready and not cordoned
+func nodeSchedulable(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestUnschedulablePod(t *testing.T) {
+	ctx := context.Background()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "new", Name: "web-1"},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-web-1"},
+		}}}},
+		Status: corev1.PodStatus{Phase: corev1.PodPending, Conditions: []corev1.PodCondition{{
+			Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable,
+			Message: "0/3 nodes are available: 3 Insufficient cpu.",
+		}}},
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "new", Name: "data-web-1"},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+		Spec: corev1.PersistentVolumeSpec{NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"us-east-1a"}},
+			}}},
+		}}},
+	}
+	dest := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pod, pvc, pv).Build()}
+	timeout := errors.New("timeout waiting for pod web-1 to be ready")
+
+	var unschedulable *UnschedulableError
+	err := unschedulablePod(ctx, dest, "new", "web-1", timeout)
+	if !errors.As(err, &unschedulable) {
+		t.Fatalf("unschedulablePod() = %v, want an UnschedulableError", err)
+	}
+	if unschedulable.Zone != "us-east-1a" || unschedulable.Message != "0/3 nodes are available: 3 Insufficient cpu." || !errors.Is(err, timeout) {
+		t.Errorf("UnschedulableError = %+v", unschedulable)
+	}
+
+	// A pod that was scheduled failed for another reason
+	pod.Spec.NodeName = "node-a"
+	dest = &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pod, pvc, pv).Build()}
+	if err := unschedulablePod(ctx, dest, "new", "web-1", timeout); err != timeout {
+		t.Errorf("unschedulablePod() = %v for a scheduled pod, want the error as it is", err)
+	}
+}
+
+func TestCapacityWatchesReceive(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := newCapacityWatches(clocktesting.NewFakeClock(time.Now()), 8)
+	watcher := watch.NewFake()
+	target := &migrationv1alpha1.StatefulSetMigration{ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web"}}
+	done := make(chan struct{})
+	go func() {
+		w.receive(ctx, watcher, make(map[string]bool), target)
+		close(done)
+	}()
+
+	node := func(ready, cordoned bool) *corev1.Node {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+			Spec:       corev1.NodeSpec{Unschedulable: cordoned},
+			Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}},
+		}
+	}
+	watcher.Add(node(false, false))
+	watcher.Modify(node(true, true))
+	watcher.Modify(node(true, false))
+	watcher.Modify(node(true, false))
+	watcher.Stop()
+	<-done
+
+	if len(w.events) != 1 {
+		t.Fatalf("events = %d, want 1 for the node becoming schedulable", len(w.events))
+	}
+	if ev := <-w.events; ev.Object.GetName() != "web" || ev.Object.GetNamespace() != "ops" {
+		t.Errorf("event for %s/%s, want the migration", ev.Object.GetNamespace(), ev.Object.GetName())
+	}
+}
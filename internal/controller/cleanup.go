@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// cleanupEnabled returns the value of a spec.cleanup option, which defaults to true
+func cleanupEnabled(option *bool) bool {
+	return option == nil || *option
+}
+
+// cleanupSource deletes the source objects spec.cleanup selects: leftover
+// pods first, since a pod keeps its PVC from being deleted, then the PVCs,
+// then the PVs. Because the PVs' reclaim policy is Retain, the EBS volumes,
+// now used by the destination, stay intact. The objects of pods that failed
+// to migrate are kept for recovery. Failures are logged and do not hold up
+// completion. It returns a summary for the history.
+func cleanupSource(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient) string {
+	logger := log.FromContext(ctx)
+	cfg := m.Spec.Cleanup
+	if cfg == nil {
+		cfg = &migrationv1alpha1.CleanupConfig{}
+	}
+
+	// A failed pod's PV is found through its PVC, so look before deleting
+	// any; when one cannot be found, every PV is kept. The same goes for the
+	// pods spec.ordinalRange leaves in the source.
+	keepPVs := make(map[string]bool)
+	pvsKnown := true
+	keep := keptSourceOrdinals(m)
+	for _, p := range m.Status.FailedPods {
+		keep = append(keep, p.Index)
+	}
+	for _, index := range keep {
+		pvcName := translate.GetPVCNameForStatefulSetPod("data", m.Spec.StatefulSetName, index)
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: pvcName}, pvc); err != nil {
+			logger.Error(err, "Failed to get source PVC of a pod that stays", "pvc", pvcName)
+			pvsKnown = false
+			continue
+		}
+		keepPVs[pvc.Spec.VolumeName] = true
+	}
+
+	var deleted, kept []string
+	record := func(enabled bool, what string) {
+		if enabled {
+			deleted = append(deleted, what)
+		} else {
+			kept = append(kept, what)
+		}
+	}
+
+	if cleanupEnabled(cfg.DeleteSourceOrphanedPods) {
+		for _, i := range migratingOrdinals(m) {
+			if podFailed(m, i) {
+				continue
+			}
+			podName := fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, i)
+			if err := deleteIfExists(ctx, cc, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: podName}, &corev1.Pod{}); err != nil {
+				logger.Error(err, "Failed to delete source pod", "pod", podName)
+				continue
+			}
+			forgetOrphanedPod(m, podName)
+		}
+	}
+	record(cleanupEnabled(cfg.DeleteSourceOrphanedPods), "pods")
+
+	if cleanupEnabled(cfg.DeleteSourcePVCs) {
+		for _, i := range migratingOrdinals(m) {
+			if podFailed(m, i) {
+				continue
+			}
+			pvcName := translate.GetPVCNameForStatefulSetPod("data", m.Spec.StatefulSetName, i)
+			if err := deleteIfExists(ctx, cc, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: pvcName}, &corev1.PersistentVolumeClaim{}); err != nil {
+				logger.Error(err, "Failed to delete source PVC", "pvc", pvcName)
+			}
+		}
+	}
+	record(cleanupEnabled(cfg.DeleteSourcePVCs), "PVCs")
+
+	// The CRD rejects deleting PVs while keeping their PVCs; a bound PV would
+	// otherwise wait in Terminating for a PVC that is never deleted
+	deletePVs := cleanupEnabled(cfg.DeleteSourcePVs) && cleanupEnabled(cfg.DeleteSourcePVCs) && pvsKnown
+	if deletePVs {
+		for _, pvName := range m.Status.PreservedPVs {
+			if keepPVs[pvName] {
+				continue
+			}
+			if err := deleteIfExists(ctx, cc, types.NamespacedName{Name: pvName}, &corev1.PersistentVolume{}); err != nil {
+				logger.Error(err, "Failed to delete source PV", "pv", pvName)
+			}
+		}
+	}
+	record(deletePVs, "PVs")
+
+	summary := "Source " + strings.Join(deleted, ", ") + " deleted"
+	switch {
+	case len(deleted) == 0:
+		summary = "Source " + strings.Join(kept, ", ") + " kept"
+	case len(kept) > 0:
+		summary += "; " + strings.Join(kept, ", ") + " kept"
+	}
+	return summary
+}
+
+// deleteIfExists deletes an object, ignoring one that is already gone
+func deleteIfExists(ctx context.Context, cc *multicluster.ClusterClient, key types.NamespacedName, obj client.Object) error {
+	if err := cc.Client.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := cc.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
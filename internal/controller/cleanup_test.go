@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestCleanupSource(t *testing.T) {
+	keep := false
+	tests := []struct {
+		name        string
+		cleanup     *migrationv1alpha1.CleanupConfig
+		wantPod     bool
+		wantPVC     bool
+		wantPV      bool
+		wantSummary string
+	}{
+		{name: "default", wantSummary: "Source pods, PVCs, PVs deleted"},
+		{
+			name:        "keep PVs for archival",
+			cleanup:     &migrationv1alpha1.CleanupConfig{DeleteSourcePVs: &keep},
+			wantPV:      true,
+			wantSummary: "Source pods, PVCs deleted; PVs kept",
+		},
+		{
+			name:        "keep everything",
+			cleanup:     &migrationv1alpha1.CleanupConfig{DeleteSourcePVCs: &keep, DeleteSourcePVs: &keep, DeleteSourceOrphanedPods: &keep},
+			wantPod:     true,
+			wantPVC:     true,
+			wantPV:      true,
+			wantSummary: "Source pods, PVCs, PVs kept",
+		},
+		{
+			name:        "PVs kept with their PVCs",
+			cleanup:     &migrationv1alpha1.CleanupConfig{DeleteSourcePVCs: &keep},
+			wantPVC:     true,
+			wantPV:      true,
+			wantSummary: "Source pods deleted; PVCs, PVs kept",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web-0"}},
+				&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-0"}},
+				&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-0"}},
+			).Build()
+			m := &migrationv1alpha1.StatefulSetMigration{
+				Spec: migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web", SourceNamespace: "prod", Cleanup: tt.cleanup},
+				Status: migrationv1alpha1.StatefulSetMigrationStatus{
+					TotalReplicas: 2,
+					PreservedPVs:  []string{"pv-0"},
+				},
+			}
+
+			if got := cleanupSource(ctx, m, &multicluster.ClusterClient{Client: c}); got != tt.wantSummary {
+				t.Errorf("cleanupSource() = %q, want %q", got, tt.wantSummary)
+			}
+			exists := func(key types.NamespacedName, obj client.Object) bool {
+				err := c.Get(ctx, key, obj)
+				if err != nil && !apierrors.IsNotFound(err) {
+					t.Fatal(err)
+				}
+				return err == nil
+			}
+			if got := exists(types.NamespacedName{Namespace: "prod", Name: "web-0"}, &corev1.Pod{}); got != tt.wantPod {
+				t.Errorf("pod exists = %v, want %v", got, tt.wantPod)
+			}
+			if got := exists(types.NamespacedName{Namespace: "prod", Name: "data-web-0"}, &corev1.PersistentVolumeClaim{}); got != tt.wantPVC {
+				t.Errorf("PVC exists = %v, want %v", got, tt.wantPVC)
+			}
+			if got := exists(types.NamespacedName{Name: "pv-0"}, &corev1.PersistentVolume{}); got != tt.wantPV {
+				t.Errorf("PV exists = %v, want %v", got, tt.wantPV)
+			}
+		})
+	}
+}
+
+func TestCleanupSourceKeepsFailedPods(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web-0"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web-1"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-0"}, Spec: corev1.PersistentVolumeClaimSpec{VolumeName: "pv-0"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-1"}, Spec: corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"}},
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-0"}},
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-1"}},
+	).Build()
+	m := &migrationv1alpha1.StatefulSetMigration{
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web", SourceNamespace: "prod"},
+		Status: migrationv1alpha1.StatefulSetMigrationStatus{
+			TotalReplicas: 2,
+			PreservedPVs:  []string{"pv-0", "pv-1"},
+			FailedPods:    []migrationv1alpha1.FailedPodInfo{{Index: 1, PodName: "web-1"}},
+		},
+	}
+
+	cleanupSource(ctx, m, &multicluster.ClusterClient{Client: c})
+
+	for _, tt := range []struct {
+		key  types.NamespacedName
+		obj  client.Object
+		want bool
+	}{
+		{types.NamespacedName{Namespace: "prod", Name: "web-0"}, &corev1.Pod{}, false},
+		{types.NamespacedName{Namespace: "prod", Name: "data-web-0"}, &corev1.PersistentVolumeClaim{}, false},
+		{types.NamespacedName{Name: "pv-0"}, &corev1.PersistentVolume{}, false},
+		{types.NamespacedName{Namespace: "prod", Name: "web-1"}, &corev1.Pod{}, true},
+		{types.NamespacedName{Namespace: "prod", Name: "data-web-1"}, &corev1.PersistentVolumeClaim{}, true},
+		{types.NamespacedName{Name: "pv-1"}, &corev1.PersistentVolume{}, true},
+	} {
+		err := c.Get(ctx, tt.key, tt.obj)
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Fatal(err)
+		}
+		if got := err == nil; got != tt.want {
+			t.Errorf("%s exists = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"hash/fnv"
+	"math"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+)
+
+// RequeueJitter is the largest fraction of a requeue delay added as jitter,
+// so migrations started together do not poll the API servers in lockstep
+const RequeueJitter = 0.2
+
+// clock returns the clock that drives wait timeouts and poll intervals
+func (r *StatefulSetMigrationReconciler) clock() clock.WithTicker {
+	if r.Clock == nil {
+		return clock.RealClock{}
+	}
+	return r.Clock
+}
+
+// requeueDelay returns base plus up to RequeueJitter of jitter. The jitter is
+// derived from the migration's UID rather than a random source, so one
+// migration always waits the same time while different migrations spread out.
+func requeueDelay(base time.Duration, uid types.UID) time.Duration {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(uid))
+	fraction := float64(h.Sum32()) / math.MaxUint32
+	return base + time.Duration(float64(base)*RequeueJitter*fraction)
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestRequeueDelay(t *testing.T) {
+	base := 10 * time.Second
+	maxDelay := base + time.Duration(float64(base)*RequeueJitter)
+
+	seen := make(map[time.Duration]bool)
+	for _, uid := range []types.UID{"", "a", "b", "3f8a2c1e-0000-4000-8000-000000000001"} {
+		got := requeueDelay(base, uid)
+		if got < base || got > maxDelay {
+			t.Errorf("requeueDelay(%q) = %v, want within [%v, %v]", uid, got, base, maxDelay)
+		}
+		if again := requeueDelay(base, uid); again != got {
+			t.Errorf("requeueDelay(%q) is not deterministic: %v then %v", uid, got, again)
+		}
+		seen[got] = true
+	}
+	if len(seen) < 2 {
+		t.Error("requeueDelay() gave every migration the same delay")
+	}
+}
+
+func TestWaitForPodReadyTimeout(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "web-0"},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+			{Type: corev1.PodReady, Status: corev1.ConditionFalse},
+		}},
+	}
+	cc := &multicluster.ClusterClient{
+		Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pod).Build(),
+	}
+	clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	r := &StatefulSetMigrationReconciler{Clock: clk}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.waitForPodReady(context.Background(), cc, "db", "web-0", 10*time.Minute)
+	}()
+
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case err := <-done:
+			if err == nil || !strings.Contains(err.Error(), "timeout waiting for pod web-0") {
+				t.Fatalf("waitForPodReady() error = %v, want timeout", err)
+			}
+			return
+		case <-deadline:
+			t.Fatal("waitForPodReady() did not time out on the fake clock")
+		case <-time.After(time.Millisecond):
+			if clk.HasWaiters() {
+				clk.Step(time.Minute)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// ConditionClusterDegraded reports a source or destination API server that
+// has failed most requests over multicluster.HealthWindow, the usual reason
+// a migration stops making progress without failing
+const ConditionClusterDegraded = "ClusterDegraded"
+
+// checkClusterHealth sets ConditionClusterDegraded from the API health of the
+// migration's clusters and reports whether the condition changed. Clients
+// that cannot be built are left to the phase handler to report.
+func (r *StatefulSetMigrationReconciler) checkClusterHealth(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) bool {
+	if r.ClientManager == nil || !tracksProgress(m.Status.Phase) {
+		return false
+	}
+
+	var source, dest multicluster.APIHealth
+	if cc, err := r.getSourceClient(ctx, m); err == nil {
+		source = cc.Health()
+	}
+	if cc, err := r.getDestClient(ctx, m); err == nil {
+		dest = cc.Health()
+	}
+	return r.recordClusterHealth(ctx, m, source, dest)
+}
+
+// recordClusterHealth sets ConditionClusterDegraded while either cluster is
+// degraded and clears it once both recover, reporting whether it changed. The
+// message keeps the counts from when the set of degraded clusters last
+// changed, so a migration is not rewritten on every reconcile.
+func (r *StatefulSetMigrationReconciler) recordClusterHealth(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, source, dest multicluster.APIHealth) bool {
+	var degraded []string
+	reason := ""
+	for _, c := range []struct {
+		name   string
+		reason string
+		health multicluster.APIHealth
+	}{
+		{"source", "SourceAPIErrors", source},
+		{"destination", "DestinationAPIErrors", dest},
+	} {
+		if c.health.Degraded() {
+			degraded = append(degraded, fmt.Sprintf("%s API server %s: %s", c.name, c.health.Cluster, c.health))
+			reason = c.reason
+		}
+	}
+	if len(degraded) > 1 {
+		reason = "APIErrors"
+	}
+
+	existing := meta.FindStatusCondition(m.Status.Conditions, ConditionClusterDegraded)
+	if len(degraded) == 0 {
+		if existing == nil || existing.Status != metav1.ConditionTrue {
+			return false
+		}
+		log.FromContext(ctx).Info("Cluster API servers recovered")
+		r.setCondition(m, ConditionClusterDegraded, metav1.ConditionFalse, "Recovered", "Both API servers are answering requests")
+		return true
+	}
+
+	if existing != nil && existing.Status == metav1.ConditionTrue && existing.Reason == reason {
+		return false
+	}
+	log.FromContext(ctx).Info("Cluster API servers are failing requests", "clusters", degraded)
+	r.setCondition(m, ConditionClusterDegraded, metav1.ConditionTrue, reason, strings.Join(degraded, "; "))
+	return true
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestRecordClusterHealth(t *testing.T) {
+	healthy := multicluster.APIHealth{Cluster: "source.example.com", Requests: 100, Errors: 1}
+	failing := multicluster.APIHealth{Cluster: "dest.example.com", Requests: 20, Errors: 18, LastError: "GET /api/v1/pods: 503 Service Unavailable"}
+	otherFailing := multicluster.APIHealth{Cluster: "source.example.com", Requests: 30, Errors: 30}
+
+	tests := []struct {
+		name        string
+		existing    *metav1.Condition
+		source      multicluster.APIHealth
+		dest        multicluster.APIHealth
+		wantChanged bool
+		wantStatus  metav1.ConditionStatus
+		wantReason  string
+	}{
+		{name: "healthy", source: healthy, dest: healthy},
+		{name: "destination failing", source: healthy, dest: failing,
+			wantChanged: true, wantStatus: metav1.ConditionTrue, wantReason: "DestinationAPIErrors"},
+		{name: "still failing", source: healthy, dest: failing,
+			existing:   &metav1.Condition{Type: ConditionClusterDegraded, Status: metav1.ConditionTrue, Reason: "DestinationAPIErrors", Message: "older counts"},
+			wantStatus: metav1.ConditionTrue, wantReason: "DestinationAPIErrors"},
+		{name: "source starts failing too", source: otherFailing, dest: failing,
+			existing:    &metav1.Condition{Type: ConditionClusterDegraded, Status: metav1.ConditionTrue, Reason: "DestinationAPIErrors"},
+			wantChanged: true, wantStatus: metav1.ConditionTrue, wantReason: "APIErrors"},
+		{name: "recovered", source: healthy, dest: healthy,
+			existing:    &metav1.Condition{Type: ConditionClusterDegraded, Status: metav1.ConditionTrue, Reason: "DestinationAPIErrors"},
+			wantChanged: true, wantStatus: metav1.ConditionFalse, wantReason: "Recovered"},
+		{name: "already recovered", source: healthy, dest: healthy,
+			existing:   &metav1.Condition{Type: ConditionClusterDegraded, Status: metav1.ConditionFalse, Reason: "Recovered"},
+			wantStatus: metav1.ConditionFalse, wantReason: "Recovered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{}
+			if tt.existing != nil {
+				m.Status.Conditions = []metav1.Condition{*tt.existing}
+			}
+			r := &StatefulSetMigrationReconciler{}
+
+			if changed := r.recordClusterHealth(context.Background(), m, tt.source, tt.dest); changed != tt.wantChanged {
+				t.Errorf("recordClusterHealth() = %v, want %v", changed, tt.wantChanged)
+			}
+			c := meta.FindStatusCondition(m.Status.Conditions, ConditionClusterDegraded)
+			if tt.wantStatus == "" {
+				if c != nil {
+					t.Errorf("condition = %+v, want none", c)
+				}
+				return
+			}
+			if c == nil || c.Status != tt.wantStatus || c.Reason != tt.wantReason {
+				t.Fatalf("condition = %+v, want %s/%s", c, tt.wantStatus, tt.wantReason)
+			}
+			if tt.wantChanged && c.Status == metav1.ConditionTrue && !strings.Contains(c.Message, "503 Service Unavailable") {
+				t.Errorf("message %q does not name the last error", c.Message)
+			}
+		})
+	}
+}
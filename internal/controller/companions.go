@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// ConditionCompanionsRecreated reports whether the HPAs and monitoring
+// objects tied to the StatefulSet were recreated in the destination
+const ConditionCompanionsRecreated = "CompanionsRecreated"
+
+// migrateCompanions reports whether any companion objects are recreated
+func migrateCompanions(m *migrationv1alpha1.StatefulSetMigration) bool {
+	return m.Spec.MigrateAutoscalers || m.Spec.MigrateMonitoring
+}
+
+// recreateCompanions recreates the HPAs, ServiceMonitors, PodMonitors and
+// PrometheusRules tied to the StatefulSet in the destination, once every pod
+// has moved: an HPA created earlier would scale the destination StatefulSet
+// while the migration is still growing it one pod at a time. Like
+// recreateJobs, failures are recorded in the history and the
+// CompanionsRecreated condition rather than failing the migration, and an
+// object the destination already has, e.g. one restored by Velero, is kept.
+func (r *StatefulSetMigrationReconciler) recreateCompanions(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient) {
+	logger := log.FromContext(ctx)
+
+	objects, err := findCompanions(ctx, m, sourceCC, destCC)
+	if err != nil {
+		logger.Error(err, "Failed to find companion objects")
+		r.recordHistory(m, StepRecreateCompanion, "", migrationv1alpha1.HistoryResultFailed, err.Error())
+		r.setCondition(m, ConditionCompanionsRecreated, metav1.ConditionFalse, "LookupFailed", err.Error())
+		return
+	}
+
+	recreated, failed := 0, 0
+	for _, obj := range objects {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		object := historyObject(kind, m.Spec.DestNamespace, obj.GetName())
+		message := ""
+		if err := destCC.Client.Create(ctx, obj); apierrors.IsAlreadyExists(err) {
+			message = "Already present in the destination"
+		} else if err != nil {
+			logger.Error(err, "Failed to recreate companion in destination", "kind", kind, "name", obj.GetName())
+			r.recordHistory(m, StepRecreateCompanion, object, migrationv1alpha1.HistoryResultFailed, err.Error())
+			failed++
+			continue
+		}
+		recreated++
+		r.recordHistory(m, StepRecreateCompanion, object, migrationv1alpha1.HistoryResultSucceeded, message)
+	}
+
+	if failed > 0 {
+		r.setCondition(m, ConditionCompanionsRecreated, metav1.ConditionFalse, "RecreateFailed",
+			fmt.Sprintf("%d HPAs and monitoring objects could not be recreated in the destination; see status.history", failed))
+		return
+	}
+	r.setCondition(m, ConditionCompanionsRecreated, metav1.ConditionTrue, "Recreated",
+		fmt.Sprintf("%d HPAs and monitoring objects recreated in the destination", recreated))
+}
+
+// findCompanions returns destination copies of the source objects tied to
+// the StatefulSet. The source StatefulSet is gone by now, so its pod labels
+// are read from the destination's. A cluster without the Prometheus
+// Operator's CRDs has no monitoring objects to copy.
+func findCompanions(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient) ([]client.Object, error) {
+	var objects []client.Object
+
+	if m.Spec.MigrateAutoscalers {
+		hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+		if err := sourceCC.Client.List(ctx, hpas, client.InNamespace(m.Spec.SourceNamespace)); err != nil {
+			return nil, fmt.Errorf("failed to list source HorizontalPodAutoscalers: %w", err)
+		}
+		for i := range hpas.Items {
+			if migration.AutoscalerTargets(&hpas.Items[i], m.Spec.StatefulSetName) {
+				hpa := migration.TranslateAutoscaler(&hpas.Items[i], m.Spec.DestNamespace)
+				hpa.SetGroupVersionKind(autoscalingv2.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler"))
+				objects = append(objects, hpa)
+			}
+		}
+	}
+
+	if !m.Spec.MigrateMonitoring {
+		return objects, nil
+	}
+	sts := &appsv1.StatefulSet{}
+	if err := destCC.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: m.Spec.StatefulSetName}, sts); err != nil {
+		return nil, fmt.Errorf("failed to get destination StatefulSet: %w", err)
+	}
+	podLabels := sts.Spec.Template.Labels
+	services := &corev1.ServiceList{}
+	if err := sourceCC.Client.List(ctx, services, client.InNamespace(m.Spec.SourceNamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list source Services: %w", err)
+	}
+	selected := migration.ServicesForPods(services.Items, podLabels)
+
+	for _, gvk := range []schema.GroupVersionKind{migration.ServiceMonitorGVK, migration.PodMonitorGVK, migration.PrometheusRuleGVK} {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := sourceCC.Client.List(ctx, list, client.InNamespace(m.Spec.SourceNamespace)); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list source %ss: %w", gvk.Kind, err)
+		}
+		for i := range list.Items {
+			item := &list.Items[i]
+			tied := false
+			if gvk == migration.PrometheusRuleGVK {
+				tied = migration.RuleReferences(item, m.Spec.StatefulSetName)
+			} else {
+				var err error
+				if tied, err = migration.MonitorSelects(item, selected, podLabels); err != nil {
+					return nil, err
+				}
+			}
+			if tied {
+				objects = append(objects, migration.TranslateMonitoringObject(item, m.Spec.SourceNamespace, m.Spec.DestNamespace))
+			}
+		}
+	}
+	return objects, nil
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestRecreateCompanions(t *testing.T) {
+	ctx := context.Background()
+	hpa := func(namespace, name, target string) *autoscalingv2.HorizontalPodAutoscaler {
+		return &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: target},
+				MaxReplicas:    5,
+			},
+		}
+	}
+	source := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+		WithObjects(hpa("old", "web", "web"), hpa("old", "db", "db")).Build()
+	dest := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+		WithObjects(&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "new", Name: "web"},
+			Spec:       appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}}}},
+		}).Build()
+	m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+		SourceNamespace:    "old",
+		DestNamespace:      "new",
+		StatefulSetName:    "web",
+		MigrateAutoscalers: true,
+		MigrateMonitoring:  true,
+	}}
+	r := &StatefulSetMigrationReconciler{}
+
+	// The fake clusters have no Prometheus Operator CRDs, so only the HPA
+	// scaling the StatefulSet is recreated
+	r.recreateCompanions(ctx, m, &multicluster.ClusterClient{Client: source}, &multicluster.ClusterClient{Client: dest})
+
+	got := &autoscalingv2.HorizontalPodAutoscaler{}
+	if err := dest.Get(ctx, types.NamespacedName{Namespace: "new", Name: "web"}, got); err != nil {
+		t.Fatalf("destination HPA: %v", err)
+	}
+	if got.Spec.ScaleTargetRef.Name != "web" || got.Spec.MaxReplicas != 5 {
+		t.Errorf("destination HPA spec = %+v", got.Spec)
+	}
+	if err := dest.Get(ctx, types.NamespacedName{Namespace: "new", Name: "db"}, &autoscalingv2.HorizontalPodAutoscaler{}); err == nil {
+		t.Error("HPA scaling another StatefulSet was recreated")
+	}
+	cond := meta.FindStatusCondition(m.Status.Conditions, ConditionCompanionsRecreated)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Fatalf("CompanionsRecreated = %+v, want True", cond)
+	}
+
+	// Finalizing again keeps the copy already there
+	r.recreateCompanions(ctx, m, &multicluster.ClusterClient{Client: source}, &multicluster.ClusterClient{Client: dest})
+	if cond := meta.FindStatusCondition(m.Status.Conditions, ConditionCompanionsRecreated); cond.Status != metav1.ConditionTrue {
+		t.Errorf("CompanionsRecreated after a second run = %+v, want True", cond)
+	}
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// ConditionDestinationConflict reports a destination StatefulSet that was
+// changed outside the migration, which the controller refuses to scale
+const ConditionDestinationConflict = "DestinationConflict"
+
+// DestinationConflictError is returned when the destination StatefulSet is
+// not in the state the migration left it in
+type DestinationConflictError struct {
+	// Reason is a CamelCase summary for the condition
+	Reason string
+
+	// Message describes what differs
+	Message string
+}
+
+func (e *DestinationConflictError) Error() string {
+	return fmt.Sprintf("destination StatefulSet changed outside the migration: %s", e.Message)
+}
+
+// checkDestinationScale returns a DestinationConflictError when the
+// destination StatefulSet is not as the migration left it before scaling it
+// from previous to replicas: the previous replicas all Ready except the
+// failed pods skipped under spec.failurePolicy, no rollout in progress, and
+// the update revision the migration recorded. A scale already applied by an
+// earlier attempt of the same step is accepted.
+func checkDestinationScale(sts *appsv1.StatefulSet, previous, replicas, failed int32, revision string) error {
+	current := int32(1)
+	if sts.Spec.Replicas != nil {
+		current = *sts.Spec.Replicas
+	}
+	if current != previous && current != replicas {
+		return &DestinationConflictError{
+			Reason:  "ReplicasChanged",
+			Message: fmt.Sprintf("it has %d replicas, expected %d", current, previous),
+		}
+	}
+	if sts.Status.ObservedGeneration < sts.Generation {
+		return &DestinationConflictError{
+			Reason:  "SpecChanged",
+			Message: fmt.Sprintf("generation %d has not been observed by the StatefulSet controller", sts.Generation),
+		}
+	}
+	if ready := sts.Status.ReadyReplicas; ready < previous-failed || ready > current {
+		return &DestinationConflictError{
+			Reason:  "UnexpectedReadyReplicas",
+			Message: fmt.Sprintf("%d replicas are Ready, expected %d", ready, previous-failed),
+		}
+	}
+	if sts.Status.CurrentRevision != sts.Status.UpdateRevision {
+		return &DestinationConflictError{
+			Reason:  "RolloutInProgress",
+			Message: fmt.Sprintf("it is rolling out revision %s over %s", sts.Status.UpdateRevision, sts.Status.CurrentRevision),
+		}
+	}
+	if revision != "" && sts.Status.UpdateRevision != revision {
+		return &DestinationConflictError{
+			Reason:  "RevisionChanged",
+			Message: fmt.Sprintf("its revision is %s, expected %s", sts.Status.UpdateRevision, revision),
+		}
+	}
+	return nil
+}
+
+// recordDestRevision stores the destination StatefulSet's update revision in
+// status.destRevision the first time a migrated pod is Ready
+func (r *StatefulSetMigrationReconciler) recordDestRevision(ctx context.Context, cc *multicluster.ClusterClient, m *migrationv1alpha1.StatefulSetMigration) error {
+	if m.Status.DestRevision != "" {
+		return nil
+	}
+	sts := &appsv1.StatefulSet{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: m.Spec.StatefulSetName}, sts); err != nil {
+		return fmt.Errorf("failed to get destination StatefulSet: %w", err)
+	}
+	m.Status.DestRevision = sts.Status.UpdateRevision
+	return nil
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestCheckDestinationScale(t *testing.T) {
+	sts := func(replicas, ready int32, generation, observed int64, current, update string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Generation: generation},
+			Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To(replicas)},
+			Status: appsv1.StatefulSetStatus{
+				ObservedGeneration: observed,
+				ReadyReplicas:      ready,
+				CurrentRevision:    current,
+				UpdateRevision:     update,
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		sts        *appsv1.StatefulSet
+		revision   string
+		failed     int32
+		scaleTo    int32
+		wantReason string
+	}{
+		{name: "as left", sts: sts(2, 2, 3, 3, "web-abc", "web-abc"), revision: "web-abc"},
+		{name: "scaled by an earlier attempt", sts: sts(3, 3, 4, 4, "web-abc", "web-abc"), revision: "web-abc"},
+		{name: "no recorded revision", sts: sts(2, 2, 3, 3, "web-def", "web-def")},
+		{name: "batch", sts: sts(2, 2, 3, 3, "web-abc", "web-abc"), revision: "web-abc", scaleTo: 5},
+		{name: "batch scaled by an earlier attempt", sts: sts(5, 3, 4, 4, "web-abc", "web-abc"), revision: "web-abc", scaleTo: 5},
+		{name: "batch scaled elsewhere", sts: sts(4, 2, 4, 4, "web-abc", "web-abc"), revision: "web-abc", scaleTo: 5, wantReason: "ReplicasChanged"},
+		{name: "scaled up", sts: sts(5, 2, 4, 4, "web-abc", "web-abc"), revision: "web-abc", wantReason: "ReplicasChanged"},
+		{name: "scaled down", sts: sts(1, 1, 4, 4, "web-abc", "web-abc"), revision: "web-abc", wantReason: "ReplicasChanged"},
+		{name: "unobserved spec change", sts: sts(2, 2, 4, 3, "web-abc", "web-abc"), revision: "web-abc", wantReason: "SpecChanged"},
+		{name: "replica not ready", sts: sts(2, 1, 3, 3, "web-abc", "web-abc"), revision: "web-abc", wantReason: "UnexpectedReadyReplicas"},
+		{name: "failed pod not ready", sts: sts(2, 1, 3, 3, "web-abc", "web-abc"), revision: "web-abc", failed: 1},
+		{name: "rolling out", sts: sts(2, 2, 4, 4, "web-abc", "web-def"), revision: "web-abc", wantReason: "RolloutInProgress"},
+		{name: "rolled out", sts: sts(2, 2, 4, 4, "web-def", "web-def"), revision: "web-abc", wantReason: "RevisionChanged"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scaleTo := tt.scaleTo
+			if scaleTo == 0 {
+				scaleTo = 3
+			}
+			err := checkDestinationScale(tt.sts, 2, scaleTo, tt.failed, tt.revision)
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("checkDestinationScale() error = %v", err)
+				}
+				return
+			}
+			var conflict *DestinationConflictError
+			if !errors.As(err, &conflict) || conflict.Reason != tt.wantReason {
+				t.Fatalf("checkDestinationScale() error = %v, want reason %s", err, tt.wantReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+const (
+	// ConditionClockSkew reports clocks of the controller and the two API
+	// servers that disagree by more than MaxClockSkew
+	ConditionClockSkew = "ClockSkew"
+
+	// ConditionCertificateExpiry reports API server or client certificates
+	// that expire within CertificateExpiryWarning
+	ConditionCertificateExpiry = "CertificateExpiry"
+
+	// MaxClockSkew is the clock difference pre-flight warns about. Tokens and
+	// certificates are validated against the API server's clock.
+	MaxClockSkew = 30 * time.Second
+
+	// CertificateExpiryWarning is how far ahead pre-flight warns about
+	// certificates expiring, long enough to cover a slow migration
+	CertificateExpiryWarning = 7 * 24 * time.Hour
+)
+
+// checkConnections warns, through ConditionClockSkew and
+// ConditionCertificateExpiry, about clocks and certificates that have failed
+// migrations part way with authentication errors. The check is advisory:
+// when a cluster cannot be inspected it is skipped.
+func (r *StatefulSetMigrationReconciler) checkConnections(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient) {
+	logger := log.FromContext(ctx)
+
+	source, err := multicluster.InspectConnection(ctx, sourceCC, r.clock())
+	if err != nil {
+		logger.Error(err, "Skipping clock and certificate checks for the source cluster")
+		return
+	}
+	dest, err := multicluster.InspectConnection(ctx, destCC, r.clock())
+	if err != nil {
+		logger.Error(err, "Skipping clock and certificate checks for the destination cluster")
+		return
+	}
+
+	if skews := clockSkews(source, dest); len(skews) > 0 {
+		logger.Info("Cluster clocks disagree", "skews", skews)
+		r.setCondition(m, ConditionClockSkew, metav1.ConditionTrue, "ClocksDisagree",
+			fmt.Sprintf("%s; tokens and certificates are validated against each API server's clock, so skew causes authentication failures mid-migration. Sync the clocks with NTP",
+				strings.Join(skews, "; ")))
+	}
+
+	now := r.clock().Now()
+	var expiring []string
+	for _, cert := range []struct {
+		name   string
+		expiry time.Time
+	}{
+		{"source API server certificate", source.ServingCertExpiry},
+		{"source client certificate", source.ClientCertExpiry},
+		{"destination API server certificate", dest.ServingCertExpiry},
+		{"destination client certificate", dest.ClientCertExpiry},
+	} {
+		if message := certificateExpiry(cert.name, cert.expiry, now); message != "" {
+			expiring = append(expiring, message)
+		}
+	}
+	if len(expiring) > 0 {
+		logger.Info("Cluster certificates expire soon", "certificates", expiring)
+		r.setCondition(m, ConditionCertificateExpiry, metav1.ConditionTrue, "ExpiringSoon",
+			fmt.Sprintf("%s; renew them, and the kubeconfig Secrets, before migrating", strings.Join(expiring, "; ")))
+	}
+}
+
+// clockSkews describes the clocks that disagree by more than MaxClockSkew,
+// beyond the uncertainty of measuring them
+func clockSkews(source, dest *multicluster.ConnectionInfo) []string {
+	var skews []string
+	for _, c := range []struct {
+		name        string
+		offset      time.Duration
+		uncertainty time.Duration
+	}{
+		{"source API server vs controller", source.ClockOffset, source.ClockUncertainty},
+		{"destination API server vs controller", dest.ClockOffset, dest.ClockUncertainty},
+		{"destination vs source API server", dest.ClockOffset - source.ClockOffset, dest.ClockUncertainty + source.ClockUncertainty},
+	} {
+		if c.offset.Abs()-c.uncertainty > MaxClockSkew {
+			skews = append(skews, fmt.Sprintf("%s: %s (±%s)", c.name, c.offset.Round(time.Second), c.uncertainty.Round(time.Second)))
+		}
+	}
+	return skews
+}
+
+// certificateExpiry describes a certificate that has expired or expires
+// within CertificateExpiryWarning of now, or returns "" otherwise
+func certificateExpiry(name string, expiry, now time.Time) string {
+	switch left := expiry.Sub(now); {
+	case expiry.IsZero() || left > CertificateExpiryWarning:
+		return ""
+	case left <= 0:
+		return fmt.Sprintf("%s expired at %s", name, expiry.UTC().Format(time.RFC3339))
+	default:
+		return fmt.Sprintf("%s expires at %s, in %s", name, expiry.UTC().Format(time.RFC3339), left.Round(time.Minute))
+	}
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestClockSkews(t *testing.T) {
+	second := time.Second
+	tests := []struct {
+		name   string
+		source time.Duration
+		dest   time.Duration
+		want   []string
+	}{
+		{name: "in sync", source: 2 * second, dest: -3 * second},
+		{name: "within uncertainty", source: 31 * second},
+		{name: "destination ahead", dest: 2 * time.Minute, want: []string{
+			"destination API server vs controller: 2m0s (±1s)",
+			"destination vs source API server: 2m0s (±2s)",
+		}},
+		{name: "clusters apart, controller between", source: -20 * second, dest: 20 * second, want: []string{
+			"destination vs source API server: 40s (±2s)",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := clockSkews(
+				&multicluster.ConnectionInfo{ClockOffset: tt.source, ClockUncertainty: second},
+				&multicluster.ConnectionInfo{ClockOffset: tt.dest, ClockUncertainty: second},
+			)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("clockSkews() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCertificateExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		expiry time.Time
+		want   string
+	}{
+		{name: "unknown"},
+		{name: "far off", expiry: now.Add(30 * 24 * time.Hour)},
+		{name: "soon", expiry: now.Add(50 * time.Hour), want: "client certificate expires at 2026-03-03T14:00:00Z, in 50h0m0s"},
+		{name: "expired", expiry: now.Add(-time.Hour), want: "client certificate expired at 2026-03-01T11:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := certificateExpiry("client certificate", tt.expiry, now); got != tt.want {
+				t.Errorf("certificateExpiry() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
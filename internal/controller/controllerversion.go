@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/version"
+)
+
+const (
+	// AnnotationResumeControllerVersion set to the controller's version lets
+	// it resume a migration an incompatible version started
+	AnnotationResumeControllerVersion = "migration.aqua.io/resume-with-controller-version"
+
+	// ConditionControllerVersionMismatch reports that the migration was
+	// started by another controller version; it is True while the migration
+	// is held because the versions are incompatible
+	ConditionControllerVersionMismatch = "ControllerVersionMismatch"
+
+	// ConditionFeatureGatesChanged reports that the controller's optional
+	// features differ from those the migration started with
+	ConditionFeatureGatesChanged = "FeatureGatesChanged"
+
+	// EventControllerVersionMismatch is recorded when a migration is resumed,
+	// or held, by another controller version
+	EventControllerVersionMismatch = "ControllerVersionMismatch"
+)
+
+// Optional controller features recorded in status.featureGates. Each changes
+// the steps a migration takes, so a migration resumed with another set may
+// skip a step it depends on, such as releasing its volume locks.
+const (
+	FeatureRemoteInformerCache     = "RemoteInformerCache"
+	FeatureVolumeLocks             = "VolumeLocks"
+	FeatureSignedApprovals         = "SignedApprovals"
+	FeatureSourceArchive           = "SourceArchive"
+	FeatureExternalPreFlightChecks = "ExternalPreFlightChecks"
+)
+
+// controllerVersion returns the version stamped on the migrations this
+// controller starts
+func (r *StatefulSetMigrationReconciler) controllerVersion() string {
+	if r.Version != "" {
+		return r.Version
+	}
+	return version.Get()
+}
+
+// featureGates returns the optional features this controller runs with, sorted
+func (r *StatefulSetMigrationReconciler) featureGates() []string {
+	var gates []string
+	if r.UseRemoteCaches {
+		gates = append(gates, FeatureRemoteInformerCache)
+	}
+	if r.VolumeLockID != "" {
+		gates = append(gates, FeatureVolumeLocks)
+	}
+	if len(r.ApprovalKeys) > 0 {
+		gates = append(gates, FeatureSignedApprovals)
+	}
+	if r.ArchiveBucket != "" {
+		gates = append(gates, FeatureSourceArchive)
+	}
+	if len(r.PreFlightChecks) > 0 {
+		gates = append(gates, FeatureExternalPreFlightChecks)
+	}
+	slices.Sort(gates)
+	return gates
+}
+
+// stampControllerVersion records the controller version and features a
+// migration starts with
+func (r *StatefulSetMigrationReconciler) stampControllerVersion(m *migrationv1alpha1.StatefulSetMigration) {
+	m.Status.ControllerVersion = r.controllerVersion()
+	m.Status.FeatureGates = r.featureGates()
+}
+
+// checkControllerVersion compares the controller with the one that started
+// a running migration. It returns why the migration must be held, or "" when
+// it may resume, and whether the status changed and must be written.
+//
+// Patch releases resume each other's migrations, as do builds without a
+// semantic version, with a warning. Another major or minor release may have
+// added, removed or reordered steps, so it holds the migration until it is
+// annotated with AnnotationResumeControllerVersion set to the controller's
+// version, or a compatible controller is rolled back. Changed features only
+// warn.
+func (r *StatefulSetMigrationReconciler) checkControllerVersion(m *migrationv1alpha1.StatefulSetMigration) (string, bool) {
+	// Migrations started before status.controllerVersion existed adopt this
+	// controller's, written with the next status update
+	if m.Status.ControllerVersion == "" {
+		r.stampControllerVersion(m)
+		return "", false
+	}
+
+	changed := r.checkFeatureGates(m)
+	started, current := m.Status.ControllerVersion, r.controllerVersion()
+	message := fmt.Sprintf("Started by controller %s and resumed by %s", started, current)
+
+	switch version.Compare(started, current) {
+	case version.Same:
+		if meta.FindStatusCondition(m.Status.Conditions, ConditionControllerVersionMismatch) != nil {
+			changed = r.setVersionCondition(m, metav1.ConditionFalse, "SameVersion", fmt.Sprintf("Resumed by controller %s, which started it", current)) || changed
+		}
+	case version.Compatible:
+		changed = r.setVersionCondition(m, metav1.ConditionFalse, "CompatibleVersion", message) || changed
+	case version.Unknown:
+		changed = r.setVersionCondition(m, metav1.ConditionFalse, "UnknownVersion", message+"; compatibility is unknown") || changed
+	case version.Incompatible:
+		if m.Annotations[AnnotationResumeControllerVersion] == current {
+			changed = r.setVersionCondition(m, metav1.ConditionFalse, "Acknowledged",
+				fmt.Sprintf("%s, as %s allows", message, AnnotationResumeControllerVersion)) || changed
+			break
+		}
+		reason := fmt.Sprintf("it was started by controller %s, whose steps may differ from %s; roll back to %s.x, or annotate the migration with %s=%s to resume it",
+			started, current, majorMinor(started), AnnotationResumeControllerVersion, current)
+		return reason, changed
+	}
+	return "", changed
+}
+
+// holdControllerVersion leaves the migration where it is and sets
+// ConditionControllerVersionMismatch, writing the status when it or the
+// check changed it. Nothing is requeued: annotating the migration triggers a
+// reconcile, and a rollback restarts the controller.
+func (r *StatefulSetMigrationReconciler) holdControllerVersion(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, reason string, changed bool) (ctrl.Result, error) {
+	log.FromContext(ctx).Info("Holding migration started by another controller version", "phase", m.Status.Phase,
+		"startedBy", m.Status.ControllerVersion, "controllerVersion", r.controllerVersion())
+
+	message := fmt.Sprintf("Holding in %s because %s", m.Status.Phase, reason)
+	if !r.setVersionCondition(m, metav1.ConditionTrue, "IncompatibleVersion", message) && !changed {
+		return ctrl.Result{}, nil
+	}
+	if err := r.Status().Update(ctx, m); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// setVersionCondition sets ConditionControllerVersionMismatch, recording an
+// event when its message changes. It returns whether it changed.
+func (r *StatefulSetMigrationReconciler) setVersionCondition(m *migrationv1alpha1.StatefulSetMigration, status metav1.ConditionStatus, reason, message string) bool {
+	if c := meta.FindStatusCondition(m.Status.Conditions, ConditionControllerVersionMismatch); c != nil && c.Status == status && c.Message == message {
+		return false
+	}
+	r.setCondition(m, ConditionControllerVersionMismatch, status, reason, message)
+	eventType := corev1.EventTypeNormal
+	if reason != "SameVersion" && reason != "CompatibleVersion" {
+		eventType = corev1.EventTypeWarning
+	}
+	r.event(m, eventType, EventControllerVersionMismatch, message)
+	return true
+}
+
+// checkFeatureGates sets ConditionFeatureGatesChanged while the controller's
+// features differ from those the migration started with. It returns whether
+// the status changed.
+func (r *StatefulSetMigrationReconciler) checkFeatureGates(m *migrationv1alpha1.StatefulSetMigration) bool {
+	current := r.featureGates()
+	var added, removed []string
+	for _, gate := range current {
+		if !slices.Contains(m.Status.FeatureGates, gate) {
+			added = append(added, gate)
+		}
+	}
+	for _, gate := range m.Status.FeatureGates {
+		if !slices.Contains(current, gate) {
+			removed = append(removed, gate)
+		}
+	}
+
+	c := meta.FindStatusCondition(m.Status.Conditions, ConditionFeatureGatesChanged)
+	if len(added) == 0 && len(removed) == 0 {
+		if c == nil || c.Status == metav1.ConditionFalse {
+			return false
+		}
+		r.setCondition(m, ConditionFeatureGatesChanged, metav1.ConditionFalse, "FeaturesRestored", "The controller runs with the features the migration started with")
+		return true
+	}
+
+	var changes []string
+	if len(added) > 0 {
+		changes = append(changes, "enabled "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		changes = append(changes, "disabled "+strings.Join(removed, ", "))
+	}
+	message := fmt.Sprintf("The controller has %s since the migration started; its remaining steps run with the features it has now", strings.Join(changes, " and "))
+	if c != nil && c.Status == metav1.ConditionTrue && c.Message == message {
+		return false
+	}
+	r.setCondition(m, ConditionFeatureGatesChanged, metav1.ConditionTrue, "FeaturesChanged", message)
+	r.event(m, corev1.EventTypeWarning, ConditionFeatureGatesChanged, message)
+	return true
+}
+
+// majorMinor returns the major and minor version of a semantic version, as "v1.4"
+func majorMinor(v string) string {
+	parts := strings.SplitN(v, ".", 3)
+	if len(parts) < 2 {
+		return v
+	}
+	return parts[0] + "." + parts[1]
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestCheckControllerVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		started     string
+		startedWith []string
+		annotations map[string]string
+		wantHeld    bool
+		wantReason  string
+		wantChanged bool
+	}{
+		{name: "same version", started: "v1.4.2"},
+		{name: "patch release resumes", started: "v1.4.0", wantReason: "CompatibleVersion", wantChanged: true},
+		{name: "dev build resumes", started: "dev", wantReason: "UnknownVersion", wantChanged: true},
+		{name: "minor release holds", started: "v1.3.9", wantHeld: true},
+		{name: "acknowledged minor release resumes", started: "v1.3.9",
+			annotations: map[string]string{AnnotationResumeControllerVersion: "v1.4.2"}, wantReason: "Acknowledged", wantChanged: true},
+		{name: "acknowledgement of another version holds", started: "v1.3.9",
+			annotations: map[string]string{AnnotationResumeControllerVersion: "v1.4.1"}, wantHeld: true},
+		{name: "unstamped migration adopts the version", started: ""},
+		{name: "changed features warn", started: "v1.4.2", startedWith: []string{FeatureSignedApprovals}, wantChanged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &StatefulSetMigrationReconciler{Version: "v1.4.2", VolumeLockID: "mgmt-a"}
+			m := &migrationv1alpha1.StatefulSetMigration{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			m.Status.ControllerVersion = tt.started
+			m.Status.FeatureGates = []string{FeatureVolumeLocks}
+			if tt.startedWith != nil {
+				m.Status.FeatureGates = tt.startedWith
+			}
+
+			reason, changed := r.checkControllerVersion(m)
+			if (reason != "") != tt.wantHeld {
+				t.Errorf("hold reason = %q, want held %v", reason, tt.wantHeld)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			c := meta.FindStatusCondition(m.Status.Conditions, ConditionControllerVersionMismatch)
+			if tt.wantReason == "" && c != nil {
+				t.Errorf("%s = %+v, want none", ConditionControllerVersionMismatch, c)
+			}
+			if tt.wantReason != "" && (c == nil || c.Reason != tt.wantReason || c.Status != metav1.ConditionFalse) {
+				t.Errorf("%s = %+v, want False with reason %s", ConditionControllerVersionMismatch, c, tt.wantReason)
+			}
+			if tt.started == "" && m.Status.ControllerVersion != "v1.4.2" {
+				t.Errorf("ControllerVersion = %q, want the controller's adopted", m.Status.ControllerVersion)
+			}
+			if changedGates := meta.IsStatusConditionTrue(m.Status.Conditions, ConditionFeatureGatesChanged); changedGates != (tt.startedWith != nil) {
+				t.Errorf("%s = %v, want %v", ConditionFeatureGatesChanged, changedGates, tt.startedWith != nil)
+			}
+
+			// A second check of the same migration changes nothing
+			if _, changed := r.checkControllerVersion(m); changed {
+				t.Error("second check changed the status")
+			}
+		})
+	}
+}
+
+func TestReconcileHoldsIncompatibleVersion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", Finalizers: []string{MigrationFinalizer}},
+		Spec:       migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web"},
+	}
+	m.Status = migrationv1alpha1.StatefulSetMigrationStatus{
+		Phase:             migrationv1alpha1.PhaseMigratingPods,
+		AppliedSpec:       m.Spec.DeepCopy(),
+		ControllerVersion: "v1.3.0",
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+	r := &StatefulSetMigrationReconciler{Client: c, Version: "v1.4.0"}
+	key := types.NamespacedName{Namespace: "ops", Name: "web"}
+
+	for range 2 {
+		result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+		if err != nil {
+			t.Fatalf("Reconcile() error = %v", err)
+		}
+		if !result.IsZero() {
+			t.Errorf("Reconcile() = %+v, want no requeue", result)
+		}
+	}
+
+	got := &migrationv1alpha1.StatefulSetMigration{}
+	if err := c.Get(context.Background(), key, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Phase != migrationv1alpha1.PhaseMigratingPods {
+		t.Errorf("Phase = %s, want MigratingPods", got.Status.Phase)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, ConditionControllerVersionMismatch) {
+		t.Errorf("%s not true, want the migration held", ConditionControllerVersionMismatch)
+	}
+	if got.Status.ControllerVersion != "v1.3.0" {
+		t.Errorf("ControllerVersion = %q, want the starting controller's kept", got.Status.ControllerVersion)
+	}
+}
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+// attachedInstance returns the EC2 instance a volume is attached to, for
+// the chain of custody recorded in MigratedPodInfo. The record is an audit
+// aid rather than a migration step, so a failed lookup is logged and leaves
+// the instance unrecorded.
+func attachedInstance(ctx context.Context, ebs *aws.EBSClient, volumeID string) string {
+	info, err := ebs.GetVolumeInfo(ctx, volumeID)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to look up volume attachment", "volumeId", volumeID)
+		return ""
+	}
+	return info.AttachedInstanceID()
+}
+
+// destInstance returns the EC2 instance the destination volume is attached
+// to. A transferred volume is looked up in the destination account.
+func (r *StatefulSetMigrationReconciler) destInstance(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, volumeID string) string {
+	ebs := r.ebs(m)
+	if transferVolumes(m) {
+		dest, err := r.ebs(m).AssumeRole(m.Spec.DestAWS.RoleARN)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to look up volume attachment", "volumeId", volumeID)
+			return ""
+		}
+		ebs = dest
+	}
+	return attachedInstance(ctx, ebs, volumeID)
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// checkDataSources fails pre-flight when a source PVC's data source cannot be
+// translated. With the Preserve policy the referenced object must also exist
+// in the destination namespace; a destination PVC that names a missing
+// snapshot or PVC leaves populators and provisioners waiting on it.
+func checkDataSources(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient, pvcs []*corev1.PersistentVolumeClaim) error {
+	policy := translate.DataSourcePolicy(m.Spec.DataSourcePolicy)
+
+	var problems []string
+	for _, pvc := range pvcs {
+		if err := translate.ValidateDataSource(pvc, policy); err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		ref := translate.DataSource(pvc)
+		if policy != translate.DataSourcePreserve || ref == nil {
+			continue
+		}
+
+		found, err := dataSourceExists(ctx, destCC, ref, m.Spec.DestNamespace)
+		if err != nil {
+			return fmt.Errorf("failed to look up %s in the destination: %w", translate.DescribeDataSource(ref), err)
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("PVC %s is populated from %s, which does not exist in destination namespace %s",
+				pvc.Name, translate.DescribeDataSource(ref), m.Spec.DestNamespace))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// dataSourceExists reports whether the object a data source refers to exists
+// in the namespace. A kind the destination does not serve does not exist.
+func dataSourceExists(ctx context.Context, cc *multicluster.ClusterClient, ref *corev1.TypedObjectReference, namespace string) (bool, error) {
+	gk := schema.GroupKind{Kind: ref.Kind}
+	if ref.APIGroup != nil {
+		gk.Group = *ref.APIGroup
+	}
+	mapping, err := cc.Client.RESTMapper().RESTMapping(gk)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(mapping.GroupVersionKind)
+	if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestCheckDataSources(t *testing.T) {
+	clone := func(name string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-0"},
+			Spec:       corev1.PersistentVolumeClaimSpec{DataSource: &corev1.TypedLocalObjectReference{Kind: "PersistentVolumeClaim", Name: name}},
+		}
+	}
+	snapshot := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-1"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			DataSourceRef: &corev1.TypedObjectReference{APIGroup: ptr.To("snapshot.storage.k8s.io"), Kind: "VolumeSnapshot", Name: "snap-1"},
+		},
+	}
+	// The destination serves PVCs but not VolumeSnapshots
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"), meta.RESTScopeNamespace)
+	dest := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithRESTMapper(mapper).
+		WithObjects(&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "dest", Name: "seed"}}).Build()}
+
+	tests := []struct {
+		name    string
+		policy  migrationv1alpha1.DataSourcePolicy
+		pvcs    []*corev1.PersistentVolumeClaim
+		wantErr string
+	}{
+		{name: "stripped", pvcs: []*corev1.PersistentVolumeClaim{clone("missing"), snapshot}},
+		{name: "preserved clone source exists", policy: migrationv1alpha1.DataSourcePreserve, pvcs: []*corev1.PersistentVolumeClaim{clone("seed")}},
+		{
+			name:    "preserved clone source missing",
+			policy:  migrationv1alpha1.DataSourcePreserve,
+			pvcs:    []*corev1.PersistentVolumeClaim{clone("missing")},
+			wantErr: "PVC data-web-0 is populated from PersistentVolumeClaim/missing, which does not exist in destination namespace dest",
+		},
+		{
+			name:    "preserved snapshot kind not served",
+			policy:  migrationv1alpha1.DataSourcePreserve,
+			pvcs:    []*corev1.PersistentVolumeClaim{snapshot},
+			wantErr: "VolumeSnapshot.snapshot.storage.k8s.io/snap-1, which does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{DestNamespace: "dest", DataSourcePolicy: tt.policy}}
+			err := checkDataSources(context.Background(), m, dest, tt.pvcs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkDataSources() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkDataSources() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
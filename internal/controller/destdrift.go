@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+const (
+	// SourceStatefulSetKey is the key of the source StatefulSet's manifest in
+	// the ConfigMap sourceStatefulSetName names
+	SourceStatefulSetKey = "statefulset.yaml"
+
+	// EventTemplateDrift is recorded when the destination StatefulSet's pod
+	// template no longer matches the source's at the end of a migration
+	EventTemplateDrift = "TemplateDrift"
+
+	// migrationKeyPrefix prefixes the labels and annotations the migration
+	// sets on the objects it creates, which reconciling drift keeps
+	migrationKeyPrefix = "migration.aqua.io/"
+)
+
+// sourceStatefulSetName returns the name of the ConfigMap holding the source
+// StatefulSet as it was before the source was frozen
+func sourceStatefulSetName(m *migrationv1alpha1.StatefulSetMigration) string {
+	const suffix = "-source-statefulset"
+	name := m.Name
+	if max := 253 - len(suffix); len(name) > max {
+		name = strings.TrimRight(name[:max], "-.")
+	}
+	return name + suffix
+}
+
+// saveSourceStatefulSet keeps the source StatefulSet, sanitized as archived
+// manifests are, in a ConfigMap the migration owns. The source is
+// orphan-deleted when it is frozen, so this copy is what the destination is
+// created from and compared with afterwards. An existing copy is kept: it was
+// taken before anything was changed.
+func (r *StatefulSetMigrationReconciler) saveSourceStatefulSet(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sts *appsv1.StatefulSet) error {
+	key := types.NamespacedName{Namespace: m.Namespace, Name: sourceStatefulSetName(m)}
+	err := r.Get(ctx, key, &corev1.ConfigMap{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get ConfigMap %s: %w", key, err)
+	}
+
+	// The progress annotations were stamped by the migration itself
+	saved := sts.DeepCopy()
+	for k := range progressAnnotations(m) {
+		delete(saved.Annotations, k)
+	}
+	data, err := archiveManifest(saved)
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       key.Namespace,
+			Name:            key.Name,
+			Labels:          lineageLabels(m),
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(m, migrationv1alpha1.GroupVersion.WithKind("StatefulSetMigration"))},
+		},
+		Data: map[string]string{SourceStatefulSetKey: string(data)},
+	}
+	if err := r.Create(ctx, cm); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create ConfigMap %s: %w", key, err)
+	}
+	return nil
+}
+
+// savedSourceStatefulSet returns the source StatefulSet saveSourceStatefulSet
+// kept, or nil when the migration froze its source before copies were kept
+func (r *StatefulSetMigrationReconciler) savedSourceStatefulSet(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (*appsv1.StatefulSet, error) {
+	key := types.NamespacedName{Namespace: m.Namespace, Name: sourceStatefulSetName(m)}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ConfigMap %s: %w", key, err)
+	}
+	sts := &appsv1.StatefulSet{}
+	if err := yaml.Unmarshal([]byte(cm.Data[SourceStatefulSetKey]), sts); err != nil {
+		return nil, fmt.Errorf("failed to decode the source StatefulSet in ConfigMap %s: %w", key, err)
+	}
+	return sts, nil
+}
+
+// reconcileDestDrift makes the destination StatefulSet match the source it
+// was copied from, once every pod has moved. Scaling leaves it with the
+// replicas and ordinals of the last batch, and anyone may have edited it
+// while the migration ran; its labels and annotations were never copied.
+// The replicas, ordinals, update strategy, minReadySeconds,
+// revisionHistoryLimit, PVC retention policy, labels and annotations are set
+// back to the source's, keeping the migration's own migration.aqua.io/ keys.
+// A pod template that differs is only reported, since restoring it would
+// restart every migrated pod. Fields it cannot change, such as the selector
+// and volume claim templates, are left as they are.
+func (r *StatefulSetMigrationReconciler) reconcileDestDrift(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient) error {
+	source, err := r.savedSourceStatefulSet(ctx, m)
+	if err != nil || source == nil {
+		return err
+	}
+	object := historyObject("StatefulSet", m.Spec.DestNamespace, m.Spec.StatefulSetName)
+	dest := &appsv1.StatefulSet{}
+	if err := destCC.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: m.Spec.StatefulSetName}, dest); err != nil {
+		return fmt.Errorf("failed to get destination StatefulSet: %w", err)
+	}
+
+	// Under spec.ordinalRange the destination runs this and earlier
+	// tranches' ordinals, not all of the source's
+	if m.Spec.OrdinalRange != "" {
+		source.Spec.Replicas = ptr.To(destReplicas(m, m.Status.TotalReplicas))
+		source.Spec.Ordinals = destOrdinals(m, m.Status.TotalReplicas)
+	}
+	drifted := statefulSetDrift(source, dest)
+	if len(drifted) > 0 {
+		if err := destCC.Client.Update(ctx, dest); err != nil {
+			return fmt.Errorf("failed to update destination StatefulSet: %w", err)
+		}
+		log.FromContext(ctx).Info("Restored the destination StatefulSet to match the source", "fields", drifted)
+		r.recordHistory(m, StepReconcileSTS, object, migrationv1alpha1.HistoryResultSucceeded,
+			"Restored the source's "+strings.Join(drifted, ", "))
+	}
+
+	// The destination was created with spec.destinationPlacement merged in
+	placeDestTemplate(m, &source.Spec.Template)
+	if sourceHash, destHash := templateHash(source, m.Spec.DestNamespace), templateHash(dest, m.Spec.DestNamespace); sourceHash != destHash {
+		message := fmt.Sprintf("Pod template %s differs from the source's %s; it was left as it is so the pods are not restarted", destHash, sourceHash)
+		r.recordHistory(m, StepReconcileSTS, object, migrationv1alpha1.HistoryResultSucceeded, message)
+		r.event(m, corev1.EventTypeWarning, EventTemplateDrift, message)
+	}
+	return nil
+}
+
+// statefulSetDrift sets the fields of dest that may differ from source back
+// to source's and returns the names of those that did
+func statefulSetDrift(source, dest *appsv1.StatefulSet) []string {
+	var drifted []string
+	restore := func(name string, want, got any, set func()) {
+		if !equality.Semantic.DeepEqual(want, got) {
+			drifted = append(drifted, name)
+			set()
+		}
+	}
+	restore("replicas", source.Spec.Replicas, dest.Spec.Replicas, func() { dest.Spec.Replicas = source.Spec.Replicas })
+	restore("ordinals", source.Spec.Ordinals, dest.Spec.Ordinals, func() { dest.Spec.Ordinals = source.Spec.Ordinals })
+	restore("updateStrategy", source.Spec.UpdateStrategy, dest.Spec.UpdateStrategy, func() { dest.Spec.UpdateStrategy = source.Spec.UpdateStrategy })
+	restore("minReadySeconds", source.Spec.MinReadySeconds, dest.Spec.MinReadySeconds, func() { dest.Spec.MinReadySeconds = source.Spec.MinReadySeconds })
+	restore("revisionHistoryLimit", source.Spec.RevisionHistoryLimit, dest.Spec.RevisionHistoryLimit,
+		func() { dest.Spec.RevisionHistoryLimit = source.Spec.RevisionHistoryLimit })
+	restore("persistentVolumeClaimRetentionPolicy", source.Spec.PersistentVolumeClaimRetentionPolicy, dest.Spec.PersistentVolumeClaimRetentionPolicy,
+		func() {
+			dest.Spec.PersistentVolumeClaimRetentionPolicy = source.Spec.PersistentVolumeClaimRetentionPolicy
+		})
+
+	labels := withMigrationKeys(source.Labels, dest.Labels)
+	restore("labels", labels, dest.Labels, func() { dest.Labels = labels })
+	annotations := withMigrationKeys(source.Annotations, dest.Annotations)
+	restore("annotations", annotations, dest.Annotations, func() { dest.Annotations = annotations })
+	return drifted
+}
+
+// withMigrationKeys returns source's keys that are not the migration's,
+// together with the migration's keys of dest, or nil when there are none
+func withMigrationKeys(source, dest map[string]string) map[string]string {
+	keys := maps.Clone(source)
+	maps.DeleteFunc(keys, func(k, _ string) bool { return strings.HasPrefix(k, migrationKeyPrefix) })
+	for k := range dest {
+		if strings.HasPrefix(k, migrationKeyPrefix) {
+			if keys == nil {
+				keys = map[string]string{}
+			}
+			keys[k] = dest[k]
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return keys
+}
+
+// templateHash returns a short hash of a StatefulSet's pod template, as it
+// would be in namespace
+func templateHash(sts *appsv1.StatefulSet, namespace string) string {
+	template := sts.Spec.Template.DeepCopy()
+	template.Namespace = namespace
+	// A template decoded from JSON always encodes again
+	data, _ := json.Marshal(template)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
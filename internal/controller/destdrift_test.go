@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestReconcileDestDrift(t *testing.T) {
+	source := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "prod",
+			Name:        "db",
+			UID:         "source-uid",
+			Labels:      map[string]string{"app": "db"},
+			Annotations: map[string]string{"team": "storage", AnnotationStatus: "FreezingSource"},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:       ptr.To[int32](3),
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			Template:       corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "db", Image: "postgres:16"}}}},
+		},
+	}
+	tests := []struct {
+		name         string
+		edit         func(*appsv1.StatefulSet)
+		wantRestored string
+		wantEvent    bool
+	}{
+		{name: "matches the source", edit: func(*appsv1.StatefulSet) {}},
+		{name: "restores scaling and annotations", edit: func(sts *appsv1.StatefulSet) {
+			sts.Spec.Replicas = ptr.To[int32](2)
+			sts.Spec.Ordinals = &appsv1.StatefulSetOrdinals{Start: 1}
+			sts.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+			delete(sts.Annotations, "team")
+		}, wantRestored: "replicas, ordinals, updateStrategy, annotations"},
+		{name: "reports a changed template", edit: func(sts *appsv1.StatefulSet) {
+			sts.Spec.Template.Spec.Containers[0].Image = "postgres:17"
+		}, wantEvent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			m := &migrationv1alpha1.StatefulSetMigration{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "db", UID: "uid-1"},
+				Spec:       migrationv1alpha1.StatefulSetMigrationSpec{SourceNamespace: "prod", StatefulSetName: "db", DestNamespace: "prod"},
+			}
+			mgmt := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &StatefulSetMigrationReconciler{Client: mgmt, Recorder: recorder}
+			if err := r.saveSourceStatefulSet(ctx, m, source); err != nil {
+				t.Fatalf("saveSourceStatefulSet() error = %v", err)
+			}
+
+			dest := source.DeepCopy()
+			dest.UID = ""
+			dest.Annotations = map[string]string{"team": "storage", "migration.aqua.io/migrated-from": "prod/db", AnnotationStatus: "Finalizing"}
+			tt.edit(dest)
+			destClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(dest).Build()
+
+			if err := r.reconcileDestDrift(ctx, m, &multicluster.ClusterClient{Client: destClient}); err != nil {
+				t.Fatalf("reconcileDestDrift() error = %v", err)
+			}
+
+			got := &appsv1.StatefulSet{}
+			if err := destClient.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "db"}, got); err != nil {
+				t.Fatal(err)
+			}
+			if *got.Spec.Replicas != 3 || got.Spec.Ordinals != nil || got.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+				t.Errorf("spec = %+v, want the source's replicas, ordinals and update strategy", got.Spec)
+			}
+			if got.Annotations["team"] != "storage" || got.Annotations["migration.aqua.io/migrated-from"] != "prod/db" || got.Annotations[AnnotationStatus] != "Finalizing" {
+				t.Errorf("annotations = %v, want the source's and the migration's", got.Annotations)
+			}
+
+			var restored string
+			for _, entry := range m.Status.History {
+				if after, ok := strings.CutPrefix(entry.Message, "Restored the source's "); ok {
+					restored = after
+				}
+			}
+			if restored != tt.wantRestored {
+				t.Errorf("restored %q, want %q", restored, tt.wantRestored)
+			}
+			if gotEvent := len(recorder.Events) > 0; gotEvent != tt.wantEvent {
+				t.Errorf("template drift event = %v, want %v", gotEvent, tt.wantEvent)
+			}
+			if tt.wantEvent && got.Spec.Template.Spec.Containers[0].Image != "postgres:17" {
+				t.Error("pod template was restored, want it left as it is")
+			}
+		})
+	}
+}
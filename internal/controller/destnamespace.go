@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+const (
+	// AnnotationNamespaceCreatedBy marks a destination namespace created for
+	// spec.createDestNamespace with the UID of the migration that created it
+	AnnotationNamespaceCreatedBy = "migration.aqua.io/namespace-created-by"
+
+	// DestNamespaceQuotaName and DestNamespaceLimitRangeName name the
+	// ResourceQuota and LimitRange created in the namespace
+	DestNamespaceQuotaName      = "migration-quota"
+	DestNamespaceLimitRangeName = "migration-limits"
+)
+
+// ensureDestNamespace creates the destination namespace of
+// spec.createDestNamespace when it does not exist, followed by its
+// ResourceQuota and LimitRange. A namespace another migration or anyone else
+// created is left as it is. Each object that already exists is kept, so the
+// step can run again after a crash.
+func (r *StatefulSetMigrationReconciler) ensureDestNamespace(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient) error {
+	tmpl := m.Spec.CreateDestNamespace
+	if tmpl == nil {
+		return nil
+	}
+	name := m.Spec.DestNamespace
+	object := historyObject("Namespace", "", name)
+
+	ns := &corev1.Namespace{}
+	err := destCC.Client.Get(ctx, types.NamespacedName{Name: name}, ns)
+	if apierrors.IsNotFound(err) {
+		ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      maps.Clone(tmpl.Labels),
+			Annotations: maps.Clone(tmpl.Annotations),
+		}}
+		if ns.Annotations == nil {
+			ns.Annotations = map[string]string{}
+		}
+		ns.Annotations[AnnotationNamespaceCreatedBy] = string(m.UID)
+		if err := destCC.Client.Create(ctx, ns); err != nil {
+			r.recordHistory(m, StepCreateNamespace, object, migrationv1alpha1.HistoryResultFailed, err.Error())
+			return fmt.Errorf("failed to create namespace %s: %w", name, err)
+		}
+		log.FromContext(ctx).Info("Created destination namespace", "namespace", name)
+		r.recordHistory(m, StepCreateNamespace, object, migrationv1alpha1.HistoryResultSucceeded, "Created the destination namespace")
+		r.event(m, corev1.EventTypeNormal, "NamespaceCreated", fmt.Sprintf("Created destination namespace %s", name))
+	} else if err != nil {
+		return fmt.Errorf("failed to get namespace %s: %w", name, err)
+	}
+	if !ns.DeletionTimestamp.IsZero() {
+		return fmt.Errorf("namespace %s is being deleted", name)
+	}
+	if ns.Annotations[AnnotationNamespaceCreatedBy] != string(m.UID) {
+		return nil
+	}
+
+	if tmpl.ResourceQuota != nil {
+		quota := &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Namespace: name, Name: DestNamespaceQuotaName},
+			Spec:       *tmpl.ResourceQuota.DeepCopy(),
+		}
+		if err := r.createDestNamespaceObject(ctx, m, destCC, "ResourceQuota", quota); err != nil {
+			return err
+		}
+	}
+	if tmpl.LimitRange != nil {
+		limits := &corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Namespace: name, Name: DestNamespaceLimitRangeName},
+			Spec:       *tmpl.LimitRange.DeepCopy(),
+		}
+		if err := r.createDestNamespaceObject(ctx, m, destCC, "LimitRange", limits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createDestNamespaceObject creates an object of the created namespace,
+// recording it in the history unless it already exists
+func (r *StatefulSetMigrationReconciler) createDestNamespaceObject(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient, kind string, obj client.Object) error {
+	object := historyObject(kind, obj.GetNamespace(), obj.GetName())
+	err := destCC.Client.Create(ctx, obj)
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	if err != nil {
+		r.recordHistory(m, StepCreateNamespace, object, migrationv1alpha1.HistoryResultFailed, err.Error())
+		return fmt.Errorf("failed to create %s %s/%s: %w", kind, obj.GetNamespace(), obj.GetName(), err)
+	}
+	r.recordHistory(m, StepCreateNamespace, object, migrationv1alpha1.HistoryResultSucceeded, "Created from spec.createDestNamespace")
+	return nil
+}
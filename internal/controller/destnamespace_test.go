@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestEnsureDestNamespace(t *testing.T) {
+	tmpl := &migrationv1alpha1.DestNamespaceTemplate{
+		Labels:        map[string]string{"team": "storage"},
+		Annotations:   map[string]string{"owner": "dba"},
+		ResourceQuota: &corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceRequestsStorage: resource.MustParse("1Ti")}},
+		LimitRange: &corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+			Type:    corev1.LimitTypeContainer,
+			Default: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		}}},
+	}
+	tests := []struct {
+		name        string
+		existing    []client.Object
+		wantCreated bool
+		wantHistory int
+	}{
+		{name: "creates the namespace, quota and limit range", wantCreated: true, wantHistory: 3},
+		{name: "finishes a namespace it created", existing: []client.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "orders", Annotations: map[string]string{AnnotationNamespaceCreatedBy: "uid-1"},
+		}}}, wantCreated: true, wantHistory: 2},
+		{name: "leaves an existing namespace alone", existing: []client.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "orders"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dest := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.existing...).Build()
+			destCC := &multicluster.ClusterClient{Client: dest}
+			m := &migrationv1alpha1.StatefulSetMigration{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "orders", UID: "uid-1"},
+				Spec:       migrationv1alpha1.StatefulSetMigrationSpec{DestNamespace: "orders", CreateDestNamespace: tmpl},
+			}
+			r := &StatefulSetMigrationReconciler{}
+
+			// A second run, as after a crash, must change nothing
+			for range 2 {
+				if err := r.ensureDestNamespace(ctx, m, destCC); err != nil {
+					t.Fatalf("ensureDestNamespace() error = %v", err)
+				}
+			}
+			if len(m.Status.History) != tt.wantHistory {
+				t.Errorf("history = %+v, want %d entries", m.Status.History, tt.wantHistory)
+			}
+
+			ns := &corev1.Namespace{}
+			if err := dest.Get(ctx, types.NamespacedName{Name: "orders"}, ns); err != nil {
+				t.Fatal(err)
+			}
+			if len(tt.existing) == 0 && (ns.Labels["team"] != "storage" || ns.Annotations["owner"] != "dba" || ns.Annotations[AnnotationNamespaceCreatedBy] != "uid-1") {
+				t.Errorf("namespace labels = %v, annotations = %v, want the template's", ns.Labels, ns.Annotations)
+			}
+			quota := &corev1.ResourceQuota{}
+			err := dest.Get(ctx, types.NamespacedName{Namespace: "orders", Name: DestNamespaceQuotaName}, quota)
+			if (err == nil) != tt.wantCreated {
+				t.Errorf("ResourceQuota get error = %v, want it created %v", err, tt.wantCreated)
+			}
+			limits := &corev1.LimitRange{}
+			err = dest.Get(ctx, types.NamespacedName{Namespace: "orders", Name: DestNamespaceLimitRangeName}, limits)
+			if (err == nil) != tt.wantCreated {
+				t.Errorf("LimitRange get error = %v, want it created %v", err, tt.wantCreated)
+			}
+		})
+	}
+}
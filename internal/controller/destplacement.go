@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// DefaultNodePoolLabel is the node label spec.destinationPlacement.nodePool
+// selects on unless nodePoolLabel is set
+const DefaultNodePoolLabel = "karpenter.sh/nodepool"
+
+// destPlacement returns spec.destinationPlacement as a migration.Placement,
+// its node pool folded into the node selector
+func destPlacement(m *migrationv1alpha1.StatefulSetMigration) migration.Placement {
+	p := m.Spec.DestinationPlacement
+	nodeSelector := maps.Clone(p.NodeSelector)
+	if p.NodePool != "" {
+		label := p.NodePoolLabel
+		if label == "" {
+			label = DefaultNodePoolLabel
+		}
+		if nodeSelector == nil {
+			nodeSelector = map[string]string{}
+		}
+		nodeSelector[label] = p.NodePool
+	}
+	return migration.Placement{NodeSelector: nodeSelector, Zones: p.Zones, Tolerations: p.Tolerations}
+}
+
+// destPodSpec returns the pod spec of the destination StatefulSet: the
+// source's, with spec.destinationPlacement merged in
+func destPodSpec(m *migrationv1alpha1.StatefulSetMigration, sourceSpec *corev1.PodSpec) *corev1.PodSpec {
+	if m.Spec.DestinationPlacement == nil {
+		return sourceSpec
+	}
+	return migration.PlacePodSpec(sourceSpec, destPlacement(m))
+}
+
+// placeDestTemplate merges spec.destinationPlacement into a pod template
+// copied from the source
+func placeDestTemplate(m *migrationv1alpha1.StatefulSetMigration, template *corev1.PodTemplateSpec) {
+	template.Spec = *destPodSpec(m, &template.Spec)
+}
+
+// checkDestPlacement fails pre-flight when no destination node would accept
+// a pod placed by spec.destinationPlacement, or one of its zones has none,
+// before the volumes' zones are taken into account
+func checkDestPlacement(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient, podSpec *corev1.PodSpec) error {
+	placement := m.Spec.DestinationPlacement
+	if placement == nil {
+		return nil
+	}
+	nodes := &corev1.NodeList{}
+	if err := destCC.Client.List(ctx, nodes); err != nil {
+		return fmt.Errorf("failed to list destination nodes: %w", err)
+	}
+	accepting := migration.NodesAccepting(podSpec, nodes.Items)
+	if len(accepting) == 0 {
+		return fmt.Errorf("none of the %d destination nodes accepts the pods placed by spec.destinationPlacement%s", len(nodes.Items), describePlacement(destPlacement(m)))
+	}
+
+	var empty []string
+	for _, zone := range placement.Zones {
+		if migration.CountNodes(accepting, corev1.LabelTopologyZone, zone) == 0 {
+			empty = append(empty, zone)
+		}
+	}
+	if len(empty) > 0 {
+		return fmt.Errorf("no destination node accepting the pods is in zones %s of spec.destinationPlacement.zones", strings.Join(empty, ", "))
+	}
+	return nil
+}
+
+// describePlacement summarizes the node selector and zones of a placement
+// for a pre-flight failure, or returns "" when it has neither
+func describePlacement(p migration.Placement) string {
+	var parts []string
+	if len(p.NodeSelector) > 0 {
+		selector := make([]string, 0, len(p.NodeSelector))
+		for k, v := range p.NodeSelector {
+			selector = append(selector, k+"="+v)
+		}
+		slices.Sort(selector)
+		parts = append(parts, "nodeSelector "+strings.Join(selector, ","))
+	}
+	if len(p.Zones) > 0 {
+		parts = append(parts, "zones "+strings.Join(p.Zones, ","))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, "; ") + ")"
+}
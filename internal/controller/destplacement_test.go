@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestCheckDestPlacement(t *testing.T) {
+	node := func(name, zone, pool string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+			corev1.LabelTopologyZone: zone,
+			DefaultNodePoolLabel:     pool,
+		}}}
+	}
+	tainted := node("c1", "us-east-1c", "db")
+	tainted.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "db", Effect: corev1.TaintEffectNoSchedule}}
+	destClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+		WithObjects(node("a1", "us-east-1a", "general"), node("b1", "us-east-1b", "db"), tainted).Build()
+
+	tests := []struct {
+		name      string
+		placement *migrationv1alpha1.DestinationPlacement
+		wantErr   string
+	}{
+		{name: "no placement"},
+		{name: "node pool", placement: &migrationv1alpha1.DestinationPlacement{NodePool: "db"}},
+		{name: "missing node pool", placement: &migrationv1alpha1.DestinationPlacement{NodePool: "gpu", Zones: []string{"us-east-1a"}},
+			wantErr: "none of the 3 destination nodes accepts the pods placed by spec.destinationPlacement (nodeSelector karpenter.sh/nodepool=gpu; zones us-east-1a)"},
+		{name: "zone with untolerated nodes", placement: &migrationv1alpha1.DestinationPlacement{NodePool: "db", Zones: []string{"us-east-1b", "us-east-1c"}},
+			wantErr: "no destination node accepting the pods is in zones us-east-1c of spec.destinationPlacement.zones"},
+		{name: "tolerated zone", placement: &migrationv1alpha1.DestinationPlacement{
+			NodePool:    "db",
+			Zones:       []string{"us-east-1b", "us-east-1c"},
+			Tolerations: []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+		}},
+		{name: "managed node group label", placement: &migrationv1alpha1.DestinationPlacement{NodePool: "db", NodePoolLabel: "eks.amazonaws.com/nodegroup"},
+			wantErr: "none of the 3 destination nodes accepts the pods placed by spec.destinationPlacement (nodeSelector eks.amazonaws.com/nodegroup=db)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{DestinationPlacement: tt.placement}}
+			podSpec := destPodSpec(m, &corev1.PodSpec{Containers: []corev1.Container{{Name: "db", Image: "postgres:16"}}})
+
+			err := checkDestPlacement(context.Background(), m, &multicluster.ClusterClient{Client: destClient}, podSpec)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("checkDestPlacement() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("checkDestPlacement() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
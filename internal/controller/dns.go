@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+const (
+	// ConditionDNSCutover reports whether the DNS records of the pods moved
+	// so far point at the destination
+	ConditionDNSCutover = "DNSCutover"
+
+	// EventDNSCutoverFailed is recorded when a moved pod's DNS record could
+	// not be pointed at the destination
+	EventDNSCutoverFailed = "DNSCutoverFailed"
+)
+
+// dnsEndpointName names the DNSEndpoint in the source namespace that holds
+// the records of the pods moved so far
+func dnsEndpointName(m *migrationv1alpha1.StatefulSetMigration) string {
+	return m.Spec.StatefulSetName + "-dns-cutover"
+}
+
+// dnsCutoverHostnames returns the hostnames the pods' records are under:
+// spec.dnsCutover.hostname, or the external-dns hostname annotation of the
+// source headless service
+func dnsCutoverHostnames(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC *multicluster.ClusterClient, serviceName string) ([]string, error) {
+	if hostnames := migration.DNSHostnames(m.Spec.DNSCutover.Hostname); len(hostnames) > 0 {
+		return hostnames, nil
+	}
+	svc := &corev1.Service{}
+	if err := sourceCC.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: serviceName}, svc); err != nil {
+		return nil, fmt.Errorf("failed to get source service %s: %w", serviceName, err)
+	}
+	hostnames := migration.ServiceDNSHostnames(svc)
+	if len(hostnames) == 0 {
+		return nil, fmt.Errorf("source service %s has no %s annotation; set spec.dnsCutover.hostname", serviceName, migration.AnnotationExternalDNSHostname)
+	}
+	return hostnames, nil
+}
+
+// checkDNSCutover fails when spec.dnsCutover cannot hand the pods' records
+// over: there is no hostname to publish them under, or the source cluster,
+// whose external-dns owns the records, does not serve DNSEndpoints
+func checkDNSCutover(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC *multicluster.ClusterClient, serviceName string) error {
+	if m.Spec.DNSCutover == nil {
+		return nil
+	}
+	if _, err := dnsCutoverHostnames(ctx, m, sourceCC, serviceName); err != nil {
+		return err
+	}
+	if _, err := sourceCC.Client.RESTMapper().RESTMapping(migration.DNSEndpointGVK.GroupKind(), migration.DNSEndpointGVK.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return fmt.Errorf("the source cluster has no %s CRD; external-dns must run there with --source=crd", migration.DNSEndpointGVK.GroupKind())
+		}
+		return fmt.Errorf("failed to look up DNSEndpoints: %w", err)
+	}
+	return nil
+}
+
+// cutoverPodDNS points a moved pod's records at its destination pod, now
+// that it is Ready, through a DNSEndpoint in the source namespace. The
+// source cluster's external-dns keeps owning every record that way: it
+// stopped publishing the pod's record from the headless service when the
+// source pod was deleted, and publishes the destination's address from the
+// DNSEndpoint. Like recreateCompanions, a failure is recorded rather than
+// failing the migration, since the pod itself moved.
+func (r *StatefulSetMigrationReconciler) cutoverPodDNS(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient, podName string) {
+	object := historyObject(migration.DNSEndpointGVK.Kind, m.Spec.SourceNamespace, dnsEndpointName(m))
+	sourceCC, err := r.getSourceClient(ctx, m)
+	message := ""
+	if err == nil {
+		message, err = updatePodDNS(ctx, m, sourceCC, destCC, podName)
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to point pod DNS records at the destination", "pod", podName)
+		r.recordHistory(m, StepCutoverDNS, object, migrationv1alpha1.HistoryResultFailed, fmt.Sprintf("%s: %v", podName, err))
+		r.setCondition(m, ConditionDNSCutover, metav1.ConditionFalse, "CutoverFailed",
+			fmt.Sprintf("The DNS records of pod %s could not be pointed at the destination: %v", podName, err))
+		r.event(m, corev1.EventTypeWarning, EventDNSCutoverFailed, fmt.Sprintf("Pod %s: %v", podName, err))
+		return
+	}
+	r.recordHistory(m, StepCutoverDNS, object, migrationv1alpha1.HistoryResultSucceeded, message)
+	if c := meta.FindStatusCondition(m.Status.Conditions, ConditionDNSCutover); c == nil || c.Reason != "CutoverFailed" {
+		r.setCondition(m, ConditionDNSCutover, metav1.ConditionTrue, "PodsCutOver", "The DNS records of the moved pods point at the destination")
+	}
+}
+
+// updatePodDNS writes a pod's records to the DNSEndpoint, creating it for
+// the first pod, and returns them for the history
+func updatePodDNS(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient, podName string) (string, error) {
+	sts := &appsv1.StatefulSet{}
+	if err := destCC.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: m.Spec.StatefulSetName}, sts); err != nil {
+		return "", fmt.Errorf("failed to get destination StatefulSet: %w", err)
+	}
+	hostnames, err := dnsCutoverHostnames(ctx, m, sourceCC, sts.Spec.ServiceName)
+	if err != nil {
+		return "", err
+	}
+	pod := &corev1.Pod{}
+	if err := destCC.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: podName}, pod); err != nil {
+		return "", fmt.Errorf("failed to get destination pod: %w", err)
+	}
+	endpoints := migration.PodDNSEndpoints(pod, hostnames, m.Spec.DNSCutover.TTL)
+	if len(endpoints) == 0 {
+		return "", fmt.Errorf("destination pod %s has no IP address", podName)
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(migration.DNSEndpointGVK)
+	key := types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: dnsEndpointName(m)}
+	create := false
+	if err := sourceCC.Client.Get(ctx, key, obj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("failed to get DNSEndpoint: %w", err)
+		}
+		create = true
+		obj.SetNamespace(key.Namespace)
+		obj.SetName(key.Name)
+		obj.SetLabels(map[string]string{reportMigrationIDLabel: m.Spec.MigrationID})
+	}
+	if err := migration.SetPodDNSEndpoints(obj, podName, hostnames, endpoints); err != nil {
+		return "", err
+	}
+	if create {
+		err = sourceCC.Client.Create(ctx, obj)
+	} else {
+		err = sourceCC.Client.Update(ctx, obj)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write DNSEndpoint: %w", err)
+	}
+
+	ips := make([]string, 0, len(pod.Status.PodIPs))
+	for _, ip := range pod.Status.PodIPs {
+		ips = append(ips, ip.IP)
+	}
+	if len(ips) == 0 {
+		ips = append(ips, pod.Status.PodIP)
+	}
+	return fmt.Sprintf("%s.%s -> %s", podName, strings.Join(hostnames, ", "), strings.Join(ips, ", ")), nil
+}
+
+// removeDNSCutover deletes the DNSEndpoint once every pod has moved, so the
+// records are left to the destination's external-dns and the destination
+// headless service. A DNSEndpoint that cannot be deleted keeps the records
+// pointing at the destination pods, so the failure is only recorded.
+func (r *StatefulSetMigrationReconciler) removeDNSCutover(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC *multicluster.ClusterClient) {
+	if m.Spec.DNSCutover == nil {
+		return
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(migration.DNSEndpointGVK)
+	obj.SetNamespace(m.Spec.SourceNamespace)
+	obj.SetName(dnsEndpointName(m))
+	object := historyObject(migration.DNSEndpointGVK.Kind, m.Spec.SourceNamespace, obj.GetName())
+	if err := sourceCC.Client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		log.FromContext(ctx).Error(err, "Failed to delete DNSEndpoint", "name", obj.GetName())
+		r.recordHistory(m, StepCutoverDNS, object, migrationv1alpha1.HistoryResultFailed, fmt.Sprintf("Failed to delete: %v", err))
+		return
+	}
+	r.recordHistory(m, StepCutoverDNS, object, migrationv1alpha1.HistoryResultSucceeded, "Deleted; the records are left to the destination")
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// dnsMapper serves the core kinds the DNS cutover reads, and DNSEndpoints
+// when withCRD is set
+func dnsMapper(withCRD bool) meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion, appsv1.SchemeGroupVersion})
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Service"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Pod"), meta.RESTScopeNamespace)
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("StatefulSet"), meta.RESTScopeNamespace)
+	if withCRD {
+		mapper.Add(migration.DNSEndpointGVK, meta.RESTScopeNamespace)
+	}
+	return mapper
+}
+
+func TestCheckDNSCutover(t *testing.T) {
+	annotated := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "prod",
+		Name:        "web",
+		Annotations: map[string]string{migration.AnnotationExternalDNSHostname: "web.example.com"},
+	}}
+	bare := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "bare"}}
+
+	tests := []struct {
+		name    string
+		config  *migrationv1alpha1.DNSCutoverConfig
+		service string
+		withCRD bool
+		wantErr string
+	}{
+		{name: "not configured", service: "bare"},
+		{name: "hostname from the service annotation", config: &migrationv1alpha1.DNSCutoverConfig{}, service: "web", withCRD: true},
+		{name: "hostname from the spec", config: &migrationv1alpha1.DNSCutoverConfig{Hostname: "db.example.com"}, service: "bare", withCRD: true},
+		{
+			name:    "no hostname",
+			config:  &migrationv1alpha1.DNSCutoverConfig{},
+			service: "bare",
+			withCRD: true,
+			wantErr: "source service bare has no external-dns.alpha.kubernetes.io/hostname annotation",
+		},
+		{
+			name:    "no DNSEndpoint CRD",
+			config:  &migrationv1alpha1.DNSCutoverConfig{},
+			service: "web",
+			wantErr: "external-dns must run there with --source=crd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+				WithRESTMapper(dnsMapper(tt.withCRD)).WithObjects(annotated, bare).Build()}
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{SourceNamespace: "prod", DNSCutover: tt.config}}
+			err := checkDNSCutover(context.Background(), m, source, tt.service)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkDNSCutover() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkDNSCutover() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdatePodDNS(t *testing.T) {
+	ctx := context.Background()
+	source := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+		WithRESTMapper(dnsMapper(true)).
+		WithObjects(&corev1.Service{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "prod",
+			Name:        "web",
+			Annotations: map[string]string{migration.AnnotationExternalDNSHostname: "web.example.com"},
+		}}).Build()}
+	pod := func(name, ip string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "dest", Name: name},
+			Status:     corev1.PodStatus{PodIP: ip},
+		}
+	}
+	dest := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+		WithRESTMapper(dnsMapper(false)).
+		WithObjects(
+			&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "dest", Name: "web"}, Spec: appsv1.StatefulSetSpec{ServiceName: "web"}},
+			pod("web-0", "10.1.0.5"), pod("web-1", "10.1.0.6"), pod("web-2", ""),
+		).Build()}
+	m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+		MigrationID:     "mig-1",
+		StatefulSetName: "web",
+		SourceNamespace: "prod",
+		DestNamespace:   "dest",
+		DNSCutover:      &migrationv1alpha1.DNSCutoverConfig{},
+	}}
+
+	message, err := updatePodDNS(ctx, m, source, dest, "web-0")
+	if err != nil {
+		t.Fatalf("updatePodDNS() error = %v", err)
+	}
+	if want := "web-0.web.example.com -> 10.1.0.5"; message != want {
+		t.Errorf("updatePodDNS() = %q, want %q", message, want)
+	}
+	if _, err := updatePodDNS(ctx, m, source, dest, "web-1"); err != nil {
+		t.Fatalf("updatePodDNS() error = %v", err)
+	}
+	if _, err := updatePodDNS(ctx, m, source, dest, "web-2"); err == nil || !strings.Contains(err.Error(), "has no IP address") {
+		t.Errorf("updatePodDNS() for a pod without an IP error = %v", err)
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(migration.DNSEndpointGVK)
+	if err := source.Client.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "web-dns-cutover"}, obj); err != nil {
+		t.Fatalf("failed to get DNSEndpoint: %v", err)
+	}
+	if got := obj.GetLabels()[reportMigrationIDLabel]; got != "mig-1" {
+		t.Errorf("DNSEndpoint migration label = %q, want mig-1", got)
+	}
+	endpoints, _, _ := unstructured.NestedSlice(obj.Object, "spec", "endpoints")
+	var names []string
+	for _, e := range endpoints {
+		names = append(names, e.(map[string]any)["dnsName"].(string))
+	}
+	if want := []string{"web-0.web.example.com", "web-1.web.example.com"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("DNSEndpoint records = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+const (
+	// ConditionDualAttachRisk reports source pods that run an ordinal
+	// already moved to the destination, or mount its volume
+	ConditionDualAttachRisk = "DualAttachRisk"
+
+	// EventDualAttachRisk is recorded when such a source pod is found
+	EventDualAttachRisk = "DualAttachRisk"
+)
+
+// dualRunningPods returns the source pods that stand in for a pod already
+// moved: a pod of the same name, recreated by a person, an autoscaler or an
+// operator after the controller deleted it, or any other pod mounting the
+// moved pod's PVC. Either would use the EBS volume the destination pod runs
+// on, if it can attach, and corrupt its data.
+func dualRunningPods(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC *multicluster.ClusterClient) ([]string, error) {
+	if len(m.Status.MigratedPods) == 0 {
+		return nil, nil
+	}
+	moved := make(map[string]bool, len(m.Status.MigratedPods))
+	claims := make(map[string]string, len(m.Status.MigratedPods))
+	for _, p := range m.Status.MigratedPods {
+		moved[p.PodName] = true
+		claims[translate.GetPVCNameForStatefulSetPod("data", m.Spec.StatefulSetName, p.Index)] = p.PodName
+	}
+
+	pods := &corev1.PodList{}
+	if err := sourceCC.Reader(m.Spec.SourceNamespace).List(ctx, pods, client.InNamespace(m.Spec.SourceNamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list source pods: %w", err)
+	}
+	var found []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if moved[pod.Name] {
+			found = append(found, fmt.Sprintf("pod %s was recreated after it moved", pod.Name))
+			continue
+		}
+		for _, v := range pod.Spec.Volumes {
+			if v.PersistentVolumeClaim == nil {
+				continue
+			}
+			if owner, ok := claims[v.PersistentVolumeClaim.ClaimName]; ok {
+				found = append(found, fmt.Sprintf("pod %s mounts PVC %s of moved pod %s", pod.Name, v.PersistentVolumeClaim.ClaimName, owner))
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+// checkDualRunning sets ConditionDualAttachRisk, with a warning event when
+// what it found changed, and returns the source pods that stand in for a
+// moved pod. The condition turns False once they are gone.
+func (r *StatefulSetMigrationReconciler) checkDualRunning(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC *multicluster.ClusterClient) ([]string, error) {
+	found, err := dualRunningPods(ctx, m, sourceCC)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		if meta.IsStatusConditionTrue(m.Status.Conditions, ConditionDualAttachRisk) {
+			r.setCondition(m, ConditionDualAttachRisk, metav1.ConditionFalse, "Resolved", "No source pod runs an ordinal that moved")
+		}
+		return nil, nil
+	}
+
+	message := fmt.Sprintf("Source and destination pods may share EBS volumes: %s; delete the source pods and keep whatever recreated them from running", strings.Join(found, "; "))
+	if c := meta.FindStatusCondition(m.Status.Conditions, ConditionDualAttachRisk); c == nil || c.Status != metav1.ConditionTrue || c.Message != message {
+		log.FromContext(ctx).Error(nil, "Source pods run alongside their moved copies", "pods", found)
+		r.event(m, corev1.EventTypeWarning, EventDualAttachRisk, message)
+	}
+	r.setCondition(m, ConditionDualAttachRisk, metav1.ConditionTrue, "SourcePodRecreated", message)
+	return found, nil
+}
+
+// dualRunningFailure is the reason a migration fails with for the source
+// pods checkDualRunning found
+func dualRunningFailure(found []string) string {
+	return "Source pods run alongside their moved copies: " + strings.Join(found, "; ")
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestCheckDualRunning(t *testing.T) {
+	ctx := context.Background()
+	pod := func(name, claim string, phase corev1.PodPhase) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: name}, Status: corev1.PodStatus{Phase: phase}}
+		if claim != "" {
+			p.Spec.Volumes = []corev1.Volume{{
+				Name:         "data",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim}},
+			}}
+		}
+		return p
+	}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web", SourceNamespace: "prod"},
+		Status: migrationv1alpha1.StatefulSetMigrationStatus{
+			MigratedPods: []migrationv1alpha1.MigratedPodInfo{{Index: 0, PodName: "web-0"}},
+		},
+	}
+	recorder := record.NewFakeRecorder(10)
+	r := &StatefulSetMigrationReconciler{Recorder: recorder}
+
+	// web-1 has not moved yet, and the backup job that mounted web-0's
+	// volume has finished
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+		WithObjects(pod("web-1", "data-web-1", corev1.PodRunning), pod("backup-x7k", "data-web-0", corev1.PodSucceeded)).Build()
+	found, err := r.checkDualRunning(ctx, m, &multicluster.ClusterClient{Client: c})
+	if err != nil || len(found) != 0 {
+		t.Fatalf("checkDualRunning() = %v, %v, want nothing", found, err)
+	}
+	if meta.FindStatusCondition(m.Status.Conditions, ConditionDualAttachRisk) != nil {
+		t.Error("DualAttachRisk set with no source pod standing in for a moved one")
+	}
+
+	// The StatefulSet was recreated and started web-0 again, and a debug pod mounts its PVC
+	for _, p := range []*corev1.Pod{pod("web-0", "data-web-0", corev1.PodPending), pod("debug", "data-web-0", corev1.PodRunning)} {
+		if err := c.Create(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	found, err = r.checkDualRunning(ctx, m, &multicluster.ClusterClient{Client: c})
+	if err != nil {
+		t.Fatalf("checkDualRunning() error = %v", err)
+	}
+	want := []string{"pod debug mounts PVC data-web-0 of moved pod web-0", "pod web-0 was recreated after it moved"}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("checkDualRunning() = %v, want %v", found, want)
+	}
+	if !meta.IsStatusConditionTrue(m.Status.Conditions, ConditionDualAttachRisk) {
+		t.Error("DualAttachRisk not True")
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("recorded %d events, want 1", len(recorder.Events))
+	}
+
+	// The same finding again records no further event
+	if _, err := r.checkDualRunning(ctx, m, &multicluster.ClusterClient{Client: c}); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("recorded %d events after the same finding, want 1", len(recorder.Events))
+	}
+
+	for _, name := range []string{"web-0", "debug"} {
+		if err := c.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := r.checkDualRunning(ctx, m, &multicluster.ClusterClient{Client: c}); err != nil {
+		t.Fatal(err)
+	}
+	if cond := meta.FindStatusCondition(m.Status.Conditions, ConditionDualAttachRisk); cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Errorf("DualAttachRisk = %+v, want False once the pods are gone", cond)
+	}
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+)
+
+// DefaultEventThrottleWindow is how long an event suppresses repeats of itself
+const DefaultEventThrottleWindow = 10 * time.Minute
+
+// EventThrottle is an EventRecorder that drops repeats of an event on the same
+// object within Window. Wait loops poll every few seconds and may report the
+// same state each time with only a duration or count changed, so events are
+// matched on their object, type, reason and message with numbers ignored.
+// Kubernetes' own correlator only merges identical messages, and only after
+// ten of them. The first event of a run is recorded straight away; the next
+// one after the window is recorded with the number dropped in between.
+//
+// Annotated events carry data, such as the spec snapshot, and are never dropped.
+type EventThrottle struct {
+	Recorder record.EventRecorder
+
+	// Window is how long an event suppresses repeats; 0 disables throttling
+	Window time.Duration
+
+	// Clock measures the window (default: the real clock)
+	Clock clock.Clock
+
+	mu     sync.Mutex
+	recent map[throttleKey]*throttledEvent
+}
+
+type throttleKey struct {
+	object    string
+	eventType string
+	reason    string
+	message   string
+}
+
+type throttledEvent struct {
+	recorded   time.Time
+	suppressed int
+}
+
+// NewEventThrottle returns an EventThrottle recording to recorder
+func NewEventThrottle(recorder record.EventRecorder, window time.Duration) *EventThrottle {
+	return &EventThrottle{Recorder: recorder, Window: window}
+}
+
+var _ record.EventRecorder = &EventThrottle{}
+
+// Event records the event unless a similar one was recorded on the object within the window
+func (t *EventThrottle) Event(object runtime.Object, eventType, reason, message string) {
+	message, ok := t.admit(object, eventType, reason, message)
+	if ok {
+		t.Recorder.Event(object, eventType, reason, message)
+	}
+}
+
+// Eventf is Event with a formatted message
+func (t *EventThrottle) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	t.Event(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// AnnotatedEventf records the event unthrottled
+func (t *EventThrottle) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	t.Recorder.AnnotatedEventf(object, annotations, eventType, reason, messageFmt, args...)
+}
+
+// admit reports whether an event is recorded, and the message to record it
+// with, which counts the similar events dropped since the last one
+func (t *EventThrottle) admit(object runtime.Object, eventType, reason, message string) (string, bool) {
+	if t.Window <= 0 {
+		return message, true
+	}
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return message, true
+	}
+	key := throttleKey{
+		object:    string(accessor.GetUID()),
+		eventType: eventType,
+		reason:    reason,
+		message:   eventPattern(message),
+	}
+	if key.object == "" {
+		key.object = accessor.GetNamespace() + "/" + accessor.GetName()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.clock().Now()
+	if t.recent == nil {
+		t.recent = map[throttleKey]*throttledEvent{}
+	}
+	t.prune(now)
+
+	last, ok := t.recent[key]
+	if ok && now.Sub(last.recorded) < t.Window {
+		last.suppressed++
+		return "", false
+	}
+	if ok && last.suppressed > 0 {
+		message = fmt.Sprintf("%s (%d similar events suppressed since %s)",
+			message, last.suppressed, last.recorded.UTC().Format(time.RFC3339))
+	}
+	t.recent[key] = &throttledEvent{recorded: now}
+	return message, true
+}
+
+// prune forgets events whose window ended long enough ago that a repeat
+// would be news again. Their dropped repeats are not reported.
+func (t *EventThrottle) prune(now time.Time) {
+	for key, e := range t.recent {
+		if now.Sub(e.recorded) >= 2*t.Window {
+			delete(t.recent, key)
+		}
+	}
+}
+
+func (t *EventThrottle) clock() clock.Clock {
+	if t.Clock == nil {
+		return clock.RealClock{}
+	}
+	return t.Clock
+}
+
+// eventPattern replaces each word of an event message that is a number or a
+// duration with "#", so messages that differ only in an elapsed time or a
+// count match. Names with digits, such as pod ordinals, are kept.
+func eventPattern(message string) string {
+	words := strings.Fields(message)
+	for i, word := range words {
+		trimmed := strings.TrimRight(strings.Trim(word, "(),;:"), ".%")
+		if trimmed == "" {
+			continue
+		}
+		if _, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			words[i] = strings.Replace(word, trimmed, "#", 1)
+		} else if _, err := time.ParseDuration(trimmed); err == nil {
+			words[i] = strings.Replace(word, trimmed, "#", 1)
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestEventPattern(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"Volume vol-1 detaching for 3m42s", "Volume vol-1 detaching for 4m2s", true},
+		{"Waited 12 times (40%)", "Waited 13 times (45%)", true},
+		{"Deleted source pod web-0", "Deleted source pod web-1", false},
+		{"Volume vol-1 detaching", "Volume vol-2 detaching", false},
+	}
+	for _, tt := range tests {
+		if got := eventPattern(tt.a) == eventPattern(tt.b); got != tt.same {
+			t.Errorf("eventPattern(%q) == eventPattern(%q) is %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
+
+func TestEventThrottle(t *testing.T) {
+	clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	recorder := record.NewFakeRecorder(100)
+	throttle := &EventThrottle{Recorder: recorder, Window: 10 * time.Minute, Clock: clk}
+	m := &migrationv1alpha1.StatefulSetMigration{ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", UID: "uid-1"}}
+	other := &migrationv1alpha1.StatefulSetMigration{ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "api", UID: "uid-2"}}
+
+	// A poll every 5s for 5 minutes records one event per migration and reason
+	for i := 0; i < 60; i++ {
+		throttle.Eventf(m, corev1.EventTypeNormal, EventVolumeDetaching, "Volume vol-1 detaching for %s", time.Duration(i*5)*time.Second)
+		throttle.Event(other, corev1.EventTypeNormal, EventVolumeDetaching, "Volume vol-1 detaching")
+		clk.Step(5 * time.Second)
+	}
+	throttle.Event(m, corev1.EventTypeWarning, EventVolumeDetachFailed, "Detach failed")
+	throttle.Event(m, corev1.EventTypeNormal, EventVolumeDetaching, "Volume vol-2 detaching for 0s")
+	if got := drainEvents(recorder); len(got) != 4 {
+		t.Fatalf("recorded %d events, want one per migration, reason and volume: %q", len(got), got)
+	}
+
+	// The next one after the window counts those dropped
+	clk.Step(6 * time.Minute)
+	throttle.Event(m, corev1.EventTypeNormal, EventVolumeDetaching, "Volume vol-1 detaching for 11m")
+	got := drainEvents(recorder)
+	if len(got) != 1 || !strings.Contains(got[0], "(59 similar events suppressed since 2024-01-01T00:00:00Z)") {
+		t.Errorf("events after the window = %q, want one counting 59 suppressed", got)
+	}
+
+	throttle.AnnotatedEventf(m, map[string]string{"k": "v"}, corev1.EventTypeNormal, EventSpecSnapshot, "Snapshot")
+	throttle.AnnotatedEventf(m, map[string]string{"k": "v"}, corev1.EventTypeNormal, EventSpecSnapshot, "Snapshot")
+	if got := drainEvents(recorder); len(got) != 2 {
+		t.Errorf("annotated events = %q, want both recorded", got)
+	}
+}
+
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case e := <-recorder.Events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// DefaultVolumeExpansionTimeout is how long a pod's deletion waits for an
+// in-flight expansion of its PVC to finish
+const DefaultVolumeExpansionTimeout = 30 * time.Minute
+
+// pvcExpansion describes an expansion of a PVC that has not finished, or
+// returns "" when there is none. The external resizer sets Resizing while it
+// grows the EBS volume and FileSystemResizePending until the kubelet has
+// grown the filesystem; until then the PVC's capacity is short of its request.
+func pvcExpansion(pvc *corev1.PersistentVolumeClaim) string {
+	var parts []string
+	for _, c := range pvc.Status.Conditions {
+		if (c.Type == corev1.PersistentVolumeClaimResizing || c.Type == corev1.PersistentVolumeClaimFileSystemResizePending) &&
+			c.Status == corev1.ConditionTrue {
+			parts = append(parts, string(c.Type))
+		}
+	}
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+	if len(parts) == 0 && (!ok || requested.Cmp(capacity) <= 0) {
+		return ""
+	}
+	parts = append(parts, fmt.Sprintf("%s of %s", capacity.String(), requested.String()))
+	return strings.Join(parts, ", ")
+}
+
+// checkVolumeExpansions fails when a source PVC is being expanded. A volume
+// moved mid-expansion leaves the destination PVC's capacity out of step with
+// the EBS volume's size, so the migration should start once it has finished.
+func checkVolumeExpansions(pvcs []*corev1.PersistentVolumeClaim) error {
+	var expanding []string
+	for _, pvc := range pvcs {
+		if expansion := pvcExpansion(pvc); expansion != "" {
+			expanding = append(expanding, fmt.Sprintf("%s (%s)", pvc.Name, expansion))
+		}
+	}
+	if len(expanding) > 0 {
+		return fmt.Errorf("PVCs are being expanded: %s; retry once their capacity matches their request", strings.Join(expanding, "; "))
+	}
+	return nil
+}
+
+// waitForVolumeExpansion waits for an in-flight expansion of a replica's
+// source PVC to finish before its pod is deleted. The kubelet only grows the
+// filesystem while the pod mounts the volume, so the wait must come first.
+// It catches expansions started after pre-flight.
+func (r *StatefulSetMigrationReconciler) waitForVolumeExpansion(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient, mv *podMove) error {
+	key := types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: mv.pvcName}
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := cc.Client.Get(ctx, key, pvc); err != nil {
+		return fmt.Errorf("failed to get source PVC %s: %w", mv.pvcName, err)
+	}
+	expansion := pvcExpansion(pvc)
+	if expansion == "" {
+		return nil
+	}
+
+	object := historyObject("PersistentVolumeClaim", m.Spec.SourceNamespace, mv.pvcName)
+	log.FromContext(ctx).Info("Waiting for PVC expansion", "pvc", mv.pvcName, "expansion", expansion)
+	r.recordHistory(m, StepVolumeExpand, object, migrationv1alpha1.HistoryResultStarted, expansion)
+
+	defer metrics.TrackWait(StepVolumeExpand)()
+	r.beginVolumeWait(ctx, m, mv.volumeID, StepVolumeExpand, migrationv1alpha1.VolumeWaitSourceKubernetes, expansionState(expansion))
+	defer endVolumeWait(m, mv.volumeID)
+	timeout := r.clock().NewTimer(DefaultVolumeExpansionTimeout)
+	defer timeout.Stop()
+	ticker := r.clock().NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C():
+			r.recordHistory(m, StepVolumeExpand, object, migrationv1alpha1.HistoryResultFailed, expansion)
+			return fmt.Errorf("timeout waiting for the expansion of PVC %s (%s)", mv.pvcName, expansion)
+		case <-ticker.C():
+		}
+
+		if err := cc.Reader(m.Spec.SourceNamespace).Get(ctx, key, pvc); err != nil {
+			return fmt.Errorf("failed to get source PVC %s: %w", mv.pvcName, err)
+		}
+		if expansion = pvcExpansion(pvc); expansion == "" {
+			r.recordHistory(m, StepVolumeExpand, object, migrationv1alpha1.HistoryResultSucceeded, "")
+			return nil
+		}
+		r.probeVolumeWait(ctx, m, mv.volumeID, expansionState(expansion))
+	}
+}
+
+// expansionState describes an expansion for status.volumeWaits
+func expansionState(expansion string) string {
+	return "expansion " + expansion
+}
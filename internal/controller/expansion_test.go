@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func expandingPVC(name, requested, capacity string, conditions ...corev1.PersistentVolumeClaimConditionType) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: name},
+		Spec: corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(requested)},
+		}},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+		},
+	}
+	for _, c := range conditions {
+		pvc.Status.Conditions = append(pvc.Status.Conditions, corev1.PersistentVolumeClaimCondition{Type: c, Status: corev1.ConditionTrue})
+	}
+	return pvc
+}
+
+func TestPVCExpansion(t *testing.T) {
+	tests := []struct {
+		name string
+		pvc  *corev1.PersistentVolumeClaim
+		want string
+	}{
+		{"settled", expandingPVC("data-web-0", "100Gi", "100Gi"), ""},
+		{"capacity above request", expandingPVC("data-web-0", "100Gi", "128Gi"), ""},
+		{"resizing", expandingPVC("data-web-0", "200Gi", "100Gi", corev1.PersistentVolumeClaimResizing), "Resizing, 100Gi of 200Gi"},
+		{"filesystem resize pending", expandingPVC("data-web-0", "200Gi", "100Gi", corev1.PersistentVolumeClaimFileSystemResizePending),
+			"FileSystemResizePending, 100Gi of 200Gi"},
+		{"request raised before the resizer started", expandingPVC("data-web-0", "200Gi", "100Gi"), "100Gi of 200Gi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pvcExpansion(tt.pvc); got != tt.want {
+				t.Errorf("pvcExpansion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	err := checkVolumeExpansions([]*corev1.PersistentVolumeClaim{
+		expandingPVC("data-web-0", "100Gi", "100Gi"),
+		expandingPVC("data-web-1", "200Gi", "100Gi", corev1.PersistentVolumeClaimResizing),
+	})
+	if err == nil || !strings.Contains(err.Error(), "data-web-1 (Resizing, 100Gi of 200Gi)") || strings.Contains(err.Error(), "data-web-0") {
+		t.Errorf("checkVolumeExpansions() error = %v, want only data-web-1 listed", err)
+	}
+}
+
+func TestWaitForVolumeExpansion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web"},
+		Spec:       migrationv1alpha1.StatefulSetMigrationSpec{SourceNamespace: "db"},
+	}
+	clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	r := &StatefulSetMigrationReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build(),
+		Clock:  clk,
+	}
+	pvc := expandingPVC("data-web-0", "200Gi", "100Gi", corev1.PersistentVolumeClaimFileSystemResizePending)
+	source := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pvc).WithStatusSubresource(pvc).Build()
+	mv := &podMove{index: 0, podName: "web-0", pvcName: "data-web-0", volumeID: "vol-0123"}
+	ctx := context.Background()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.waitForVolumeExpansion(ctx, m, &multicluster.ClusterClient{Client: source}, mv)
+	}()
+
+	// The kubelet grows the filesystem once the wait polls
+	for !clk.HasWaiters() {
+		time.Sleep(time.Millisecond)
+	}
+	pvc.Status.Conditions = nil
+	pvc.Status.Capacity[corev1.ResourceStorage] = resource.MustParse("200Gi")
+	if err := source.Status().Update(ctx, pvc); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("waitForVolumeExpansion() error = %v", err)
+			}
+			var results []migrationv1alpha1.HistoryResult
+			for _, h := range m.Status.History {
+				if h.Step == StepVolumeExpand {
+					results = append(results, h.Result)
+				}
+			}
+			if len(results) != 2 || results[0] != migrationv1alpha1.HistoryResultStarted || results[1] != migrationv1alpha1.HistoryResultSucceeded {
+				t.Errorf("history results = %v, want Started then Succeeded", results)
+			}
+			if len(m.Status.VolumeWaits) != 0 {
+				t.Errorf("volumeWaits = %+v, want none after the wait", m.Status.VolumeWaits)
+			}
+			return
+		case <-deadline:
+			t.Fatal("waitForVolumeExpansion() did not return once the expansion finished")
+		case <-time.After(time.Millisecond):
+			if clk.HasWaiters() {
+				clk.Step(5 * time.Second)
+			}
+		}
+	}
+}
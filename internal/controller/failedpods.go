@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+const (
+	// ConditionPodsFailed reports pods that failed to migrate and were
+	// skipped under spec.failurePolicy ContinueRemaining
+	ConditionPodsFailed = "PodsFailed"
+
+	// LabelFailedPod marks the placeholder destination PVC of a failed pod
+	LabelFailedPod = "migration.aqua.io/failed-pod"
+
+	// failedClaimVolumePrefix prefixes the PV name a placeholder PVC waits
+	// for; no such PV is ever created
+	failedClaimVolumePrefix = "migration-failed-"
+)
+
+// continueRemaining reports whether a pod that fails to migrate is skipped
+// instead of failing the migration
+func continueRemaining(m *migrationv1alpha1.StatefulSetMigration) bool {
+	return m.Spec.FailurePolicy == migrationv1alpha1.FailurePolicyContinueRemaining
+}
+
+// checkFailurePolicy fails pre-flight when ContinueRemaining cannot work for
+// the StatefulSet. A failed pod stays in the destination StatefulSet, since
+// it can only run a contiguous range of ordinals, and with OrderedReady the
+// StatefulSet controller would not start the pods after it.
+func checkFailurePolicy(m *migrationv1alpha1.StatefulSetMigration, sts *appsv1.StatefulSet) error {
+	if !continueRemaining(m) || sts.Spec.PodManagementPolicy == appsv1.ParallelPodManagement {
+		return nil
+	}
+	return fmt.Errorf("failurePolicy ContinueRemaining requires podManagementPolicy Parallel; with %s a failed pod would keep the destination from starting the pods after it",
+		appsv1.OrderedReadyPodManagement)
+}
+
+// podFailed reports whether the pod at index was skipped as failed
+func podFailed(m *migrationv1alpha1.StatefulSetMigration, index int) bool {
+	return slices.ContainsFunc(m.Status.FailedPods, func(p migrationv1alpha1.FailedPodInfo) bool { return p.Index == index })
+}
+
+// failedPodNames returns the names of the pods skipped as failed
+func failedPodNames(m *migrationv1alpha1.StatefulSetMigration) []string {
+	var names []string
+	for _, p := range m.Status.FailedPods {
+		names = append(names, p.PodName)
+	}
+	return names
+}
+
+// recordFailedPod records the pod at index as failed. migratePods has left
+// the destination as if it had moved, so the next pods can: the destination
+// StatefulSet includes its ordinal, with a placeholder PVC that keeps the
+// pod Pending when its volume never arrived. The source pod, PVC and PV are
+// left for an operator to recover.
+func (r *StatefulSetMigrationReconciler) recordFailedPod(m *migrationv1alpha1.StatefulSetMigration, index int, cause error) {
+	podName := fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, index)
+	m.Status.FailedPods = append(m.Status.FailedPods, migrationv1alpha1.FailedPodInfo{
+		Index:    index,
+		PodName:  podName,
+		Reason:   cause.Error(),
+		FailedAt: metav1.NewTime(r.clock().Now()),
+	})
+	r.recordHistory(m, StepSkipPod, historyObject("Pod", m.Spec.SourceNamespace, podName),
+		migrationv1alpha1.HistoryResultFailed, cause.Error())
+	r.setCondition(m, ConditionPodsFailed, metav1.ConditionTrue, "ContinueRemaining",
+		fmt.Sprintf("Failed to migrate and skipped: %s", podList(failedPodNames(m))))
+}
+
+// reserveFailedClaim makes sure the destination has a PVC for a failed pod,
+// so that including its ordinal in the destination StatefulSet does not
+// provision an empty volume from the claim template. A PVC the migration
+// already created is kept. Otherwise the placeholder names a PV that never
+// exists and has no StorageClass, so it stays Pending and so does the pod.
+func reserveFailedClaim(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient, index int) error {
+	pvcName := translate.GetPVCNameForStatefulSetPod("data", m.Spec.StatefulSetName, index)
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pvcName,
+			Namespace: m.Spec.DestNamespace,
+			Labels:    map[string]string{LabelFailedPod: "true"},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: ptr.To(""),
+			VolumeName:       failedClaimVolumePrefix + pvcName,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+			},
+		},
+	}
+	if err := cc.Client.Create(ctx, pvc); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create placeholder PVC %s: %w", pvcName, err)
+	}
+	return nil
+}
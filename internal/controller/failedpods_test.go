@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestCheckFailurePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  migrationv1alpha1.FailurePolicy
+		pods    appsv1.PodManagementPolicyType
+		wantErr bool
+	}{
+		{name: "default", pods: appsv1.OrderedReadyPodManagement},
+		{name: "fail", policy: migrationv1alpha1.FailurePolicyFail, pods: appsv1.OrderedReadyPodManagement},
+		{name: "continue with parallel pods", policy: migrationv1alpha1.FailurePolicyContinueRemaining, pods: appsv1.ParallelPodManagement},
+		{name: "continue with ordered pods", policy: migrationv1alpha1.FailurePolicyContinueRemaining, pods: appsv1.OrderedReadyPodManagement, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{FailurePolicy: tt.policy}}
+			sts := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{PodManagementPolicy: tt.pods}}
+			if err := checkFailurePolicy(m, sts); (err != nil) != tt.wantErr {
+				t.Errorf("checkFailurePolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReserveFailedClaim(t *testing.T) {
+	ctx := context.Background()
+	migrated := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-0"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			VolumeName: "migrated-prod-data-web-0",
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(migrated).Build()
+	cc := &multicluster.ClusterClient{Client: c}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web", DestNamespace: "prod"},
+	}
+
+	for _, index := range []int{0, 1} {
+		if err := reserveFailedClaim(ctx, m, cc, index); err != nil {
+			t.Fatalf("reserveFailedClaim(%d) error = %v", index, err)
+		}
+	}
+
+	kept := &corev1.PersistentVolumeClaim{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "data-web-0"}, kept); err != nil {
+		t.Fatal(err)
+	}
+	if kept.Spec.VolumeName != "migrated-prod-data-web-0" || kept.Labels[LabelFailedPod] != "" {
+		t.Errorf("existing PVC = %s %v, want it kept", kept.Spec.VolumeName, kept.Labels)
+	}
+
+	placeholder := &corev1.PersistentVolumeClaim{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "data-web-1"}, placeholder); err != nil {
+		t.Fatal(err)
+	}
+	if placeholder.Spec.VolumeName != "migration-failed-data-web-1" {
+		t.Errorf("placeholder volumeName = %q, want migration-failed-data-web-1", placeholder.Spec.VolumeName)
+	}
+	if placeholder.Spec.StorageClassName == nil || *placeholder.Spec.StorageClassName != "" {
+		t.Errorf("placeholder storageClassName = %v, want empty so nothing is provisioned", placeholder.Spec.StorageClassName)
+	}
+	if placeholder.Labels[LabelFailedPod] != "true" {
+		t.Errorf("placeholder labels = %v, want %s=true", placeholder.Labels, LabelFailedPod)
+	}
+}
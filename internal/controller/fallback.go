@@ -0,0 +1,246 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// EventStrategyFallback is recorded when a volume is restored from a
+// snapshot instead of being reattached
+const EventStrategyFallback = "StrategyFallback"
+
+// fallsBackOn reports whether spec.strategyFallback falls back on trigger
+func fallsBackOn(m *migrationv1alpha1.StatefulSetMigration, trigger migrationv1alpha1.FallbackTrigger) bool {
+	fallback := m.Spec.StrategyFallback
+	if fallback == nil {
+		return false
+	}
+	return len(fallback.On) == 0 || slices.Contains(fallback.On, trigger)
+}
+
+// volumeFallback returns the fallback recorded for a volume, or nil when it
+// is reattached
+func volumeFallback(m *migrationv1alpha1.StatefulSetMigration, volumeID string) *migrationv1alpha1.VolumeFallback {
+	for i := range m.Status.VolumeFallbacks {
+		if m.Status.VolumeFallbacks[i].VolumeID == volumeID {
+			return &m.Status.VolumeFallbacks[i]
+		}
+	}
+	return nil
+}
+
+// addVolumeFallback records that a volume falls back and returns the record
+func (r *StatefulSetMigrationReconciler) addVolumeFallback(m *migrationv1alpha1.StatefulSetMigration, volumeID string, trigger migrationv1alpha1.FallbackTrigger, zone, reason string) *migrationv1alpha1.VolumeFallback {
+	m.Status.VolumeFallbacks = append(m.Status.VolumeFallbacks, migrationv1alpha1.VolumeFallback{
+		VolumeID: volumeID,
+		Trigger:  trigger,
+		Reason:   reason,
+		Zone:     zone,
+		Time:     metav1.NewTime(r.clock().Now()),
+	})
+	r.recordHistory(m, StepStrategyFallback, volumeID, migrationv1alpha1.HistoryResultStarted,
+		fmt.Sprintf("%s: restoring from a snapshot in %s; %s", trigger, zone, reason))
+	return &m.Status.VolumeFallbacks[len(m.Status.VolumeFallbacks)-1]
+}
+
+// planVolumeStrategies selects, at pre-flight, how each volume moves. It
+// intersects the zones of the volumes with the zones of the destination
+// nodes that can run the pod: a volume in a shared zone is reattached, and
+// one outside it is restored, with the ZoneMismatch fallback, in the zone
+// with the most nodes that can run the pod. The volume's entry in pvs is then
+// replaced by a copy in that zone, so the scheduling and capacity checks that
+// follow count it there. Without the fallback the scheduling check fails such
+// volumes. The zones and each decision with its reason are recorded in status.
+func (r *StatefulSetMigrationReconciler) planVolumeStrategies(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient, podSpec *corev1.PodSpec, pvs []*corev1.PersistentVolume) error {
+	// Pre-flight may run more than once; the plan follows the latest nodes
+	m.Status.VolumeFallbacks = slices.DeleteFunc(m.Status.VolumeFallbacks, func(f migrationv1alpha1.VolumeFallback) bool {
+		return f.Trigger == migrationv1alpha1.FallbackTriggerZoneMismatch
+	})
+	m.Status.VolumeStrategies = nil
+
+	nodeList := &corev1.NodeList{}
+	if err := destCC.Client.List(ctx, nodeList); err != nil {
+		return fmt.Errorf("failed to list destination nodes: %w", err)
+	}
+	// Zones where the pod can run, whatever its volumes, most nodes first
+	var nodeZones []string
+	for _, zone := range zonesByNodes(nodeList.Items) {
+		zonal := translate.WithAvailabilityZone(&corev1.PersistentVolume{}, zone)
+		if len(migration.CheckPodScheduling(podSpec, nodeList.Items, []*corev1.PersistentVolume{zonal})) == 0 {
+			nodeZones = append(nodeZones, zone)
+		}
+	}
+
+	intersection := &migrationv1alpha1.ZoneIntersection{NodeZones: nodeZones}
+	for i, pv := range pvs {
+		volumeID, err := getVolumeIDFromPV(pv)
+		if err != nil {
+			return err
+		}
+		zone := migration.VolumeZone(pv)
+		if zone != "" && !slices.Contains(intersection.VolumeZones, zone) {
+			intersection.VolumeZones = append(intersection.VolumeZones, zone)
+			if slices.Contains(nodeZones, zone) {
+				intersection.SharedZones = append(intersection.SharedZones, zone)
+			}
+		}
+
+		decision := migrationv1alpha1.VolumeStrategyDecision{
+			VolumeID: volumeID,
+			Zone:     zone,
+			Strategy: migrationv1alpha1.VolumeStrategyReattach,
+			DestZone: zone,
+		}
+		schedulable := zone == "" || len(migration.CheckPodScheduling(podSpec, nodeList.Items, pvs[i:i+1])) == 0
+		switch {
+		case transferVolumes(m):
+			decision.Strategy = migrationv1alpha1.VolumeStrategyTransfer
+			decision.Reason = "destAWS.transferVolumes copies every volume into the destination account, in its own zone"
+		case zone == "":
+			decision.Reason = "the PV has no zone affinity"
+		case schedulable:
+			decision.Reason = fmt.Sprintf("destination nodes in %s can run the pod", zone)
+		case !fallsBackOn(m, migrationv1alpha1.FallbackTriggerZoneMismatch):
+			decision.Reason = fmt.Sprintf("no destination node in %s can run the pod; set spec.strategyFallback to restore it in another zone", zone)
+		default:
+			decision.Reason = fmt.Sprintf("no destination node in %s can run the pod, and no other zone has one either", zone)
+			for _, candidate := range nodeZones {
+				restored := translate.WithAvailabilityZone(pv, candidate)
+				if candidate == zone || len(migration.CheckPodScheduling(podSpec, nodeList.Items, []*corev1.PersistentVolume{restored})) > 0 {
+					continue
+				}
+				log.FromContext(ctx).Info("Volume falls back to snapshot and restore", "volumeId", volumeID, "zone", zone, "restoreZone", candidate)
+				reason := fmt.Sprintf("no destination node in %s can run the pod", zone)
+				r.addVolumeFallback(m, volumeID, migrationv1alpha1.FallbackTriggerZoneMismatch, candidate, reason)
+				pvs[i] = restored
+				decision.Strategy = migrationv1alpha1.VolumeStrategySnapshotRestore
+				decision.DestZone = candidate
+				decision.Reason = fmt.Sprintf("%s; %s has the most nodes that can", reason, candidate)
+				break
+			}
+		}
+		m.Status.VolumeStrategies = append(m.Status.VolumeStrategies, decision)
+	}
+	sort.Strings(intersection.VolumeZones)
+	sort.Strings(intersection.SharedZones)
+	m.Status.ZoneIntersection = intersection
+	return nil
+}
+
+// zonesByNodes returns the zones of the schedulable nodes, the zone with
+// the most nodes first
+func zonesByNodes(nodes []corev1.Node) []string {
+	counts := make(map[string]int)
+	for _, node := range nodes {
+		if zone := node.Labels[corev1.LabelTopologyZone]; zone != "" && !node.Spec.Unschedulable {
+			counts[zone]++
+		}
+	}
+	zones := make([]string, 0, len(counts))
+	for zone := range counts {
+		zones = append(zones, zone)
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		if counts[zones[i]] != counts[zones[j]] {
+			return counts[zones[i]] > counts[zones[j]]
+		}
+		return zones[i] < zones[j]
+	})
+	return zones
+}
+
+// detachBlocked reports whether a failed detach is one waiting longer does
+// not fix and snapshotting the volume is safe: its instance is stopped,
+// shutting down or terminated, so nothing writes to it. A node that cannot
+// unmount the volume may still be running with it attached, so it is not.
+func detachBlocked(err error) bool {
+	var instance *aws.InstanceUnavailableError
+	return errors.As(err, &instance)
+}
+
+// restoreVolume moves a volume with the SnapshotRestore fallback and returns
+// the new volume's ID. The volume is snapshotted, attached or not, and the
+// snapshot is restored to a new volume of the same type, size and
+// performance in the fallback's zone, then deleted. As with a transfer,
+// every create call carries an idempotency key, so a retry resumes the
+// earlier attempt.
+func (r *StatefulSetMigrationReconciler) restoreVolume(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, index int, fallback *migrationv1alpha1.VolumeFallback) (string, error) {
+	volumeID := fallback.VolumeID
+	uid := string(m.UID)
+	r.event(m, corev1.EventTypeWarning, EventStrategyFallback,
+		fmt.Sprintf("Restoring volume %s from a snapshot in %s instead of reattaching it (%s): %s", volumeID, fallback.Zone, fallback.Trigger, fallback.Reason))
+
+	volumeKey := aws.IdempotencyKey(uid, index, "RestoreVolume")
+	existing, err := r.ebs(m).FindVolumeByKey(ctx, volumeKey)
+	if err != nil {
+		return "", err
+	}
+	if existing != "" {
+		log.FromContext(ctx).Info("Resuming restore with existing volume", "volumeId", volumeID, "destVolumeId", existing)
+		fallback.DestVolumeID = existing
+		return existing, r.waitForTransferVolume(ctx, m, r.ebs(m), existing)
+	}
+
+	source, err := r.ebs(m).GetVolumeInfo(ctx, volumeID)
+	if err != nil {
+		return "", err
+	}
+	if source.OutpostARN != "" {
+		return "", fmt.Errorf("volume %s is on Outpost %s, where it cannot be restored from a snapshot", volumeID, aws.OutpostID(source.OutpostARN))
+	}
+	if fallback.Zone == "" {
+		fallback.Zone = source.AvailabilityZone
+	}
+
+	release, err := r.ebs(m).AcquireSnapshotSlot(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	snapshotID, err := r.ebs(m).CreateSnapshot(ctx, volumeID, aws.IdempotencyKey(uid, index, "RestoreSnapshot"),
+		aws.CreateSnapshotConfig{Description: fmt.Sprintf("Restore of %s for migration %s", volumeID, m.Spec.MigrationID)})
+	if err != nil {
+		return "", err
+	}
+	r.recordHistory(m, StepSnapshot, snapshotID, migrationv1alpha1.HistoryResultStarted, fmt.Sprintf("Snapshot of %s", volumeID))
+	if err := r.waitForTransferSnapshot(ctx, m, r.ebs(m), StepSnapshot, volumeID, snapshotID); err != nil {
+		return "", err
+	}
+	r.recordHistory(m, StepSnapshot, snapshotID, migrationv1alpha1.HistoryResultSucceeded, "")
+
+	volumeCfg := transferVolumeConfig(m, source)
+	volumeCfg.AvailabilityZone = fallback.Zone
+	volumeCfg.SnapshotID = snapshotID
+	destVolumeID, err := r.ebs(m).CreateVolume(ctx, volumeKey, volumeCfg)
+	if err != nil {
+		return "", err
+	}
+	fallback.DestVolumeID = destVolumeID
+	if err := r.waitForTransferVolume(ctx, m, r.ebs(m), destVolumeID); err != nil {
+		return "", err
+	}
+
+	// The volume exists, so a snapshot left behind only costs storage
+	if err := r.ebs(m).DeleteSnapshot(ctx, snapshotID); err != nil {
+		log.FromContext(ctx).Info("Failed to delete restore snapshot", "snapshotId", snapshotID, "error", err.Error())
+		r.recordHistory(m, StepCleanSnapshot, snapshotID, migrationv1alpha1.HistoryResultFailed, err.Error())
+	} else {
+		r.recordHistory(m, StepCleanSnapshot, snapshotID, migrationv1alpha1.HistoryResultSucceeded, "")
+	}
+	r.recordHistory(m, StepStrategyFallback, volumeID, migrationv1alpha1.HistoryResultSucceeded, fmt.Sprintf("Restored as %s", destVolumeID))
+	return destVolumeID, nil
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestPlanVolumeStrategies(t *testing.T) {
+	node := func(name, zone string) client.Object {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+			corev1.LabelTopologyZone: zone, corev1.LabelOSStable: "linux"}}}
+	}
+	pv := func(volumeID, zone string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-" + volumeID},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{Driver: migration.EBSCSIDriver, VolumeHandle: volumeID},
+				},
+				NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{zone}},
+					}}},
+				}},
+			},
+		}
+	}
+	cc := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+		WithObjects(node("a-1", "us-east-1a"), node("b-1", "us-east-1b"), node("b-2", "us-east-1b")).Build()}
+
+	tests := []struct {
+		name       string
+		fallback   *migrationv1alpha1.StrategyFallback
+		want       []string
+		strategies []string
+	}{
+		{name: "no fallback", strategies: []string{"vol-a Reattach us-east-1a", "vol-c Reattach us-east-1c"}},
+		{name: "detach blocked only", fallback: &migrationv1alpha1.StrategyFallback{
+			On: []migrationv1alpha1.FallbackTrigger{migrationv1alpha1.FallbackTriggerDetachBlocked}},
+			strategies: []string{"vol-a Reattach us-east-1a", "vol-c Reattach us-east-1c"}},
+		{name: "zone mismatch", fallback: &migrationv1alpha1.StrategyFallback{}, want: []string{"vol-c us-east-1b"},
+			strategies: []string{"vol-a Reattach us-east-1a", "vol-c SnapshotRestore us-east-1b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{StrategyFallback: tt.fallback}}
+			// A plan from an earlier pre-flight is replaced
+			m.Status.VolumeFallbacks = []migrationv1alpha1.VolumeFallback{
+				{VolumeID: "vol-old", Trigger: migrationv1alpha1.FallbackTriggerZoneMismatch, Zone: "us-east-1a"},
+			}
+			r := &StatefulSetMigrationReconciler{Clock: clocktesting.NewFakeClock(time.Now())}
+			pvs := []*corev1.PersistentVolume{pv("vol-a", "us-east-1a"), pv("vol-c", "us-east-1c")}
+
+			if err := r.planVolumeStrategies(context.Background(), m, cc, &corev1.PodSpec{}, pvs); err != nil {
+				t.Fatalf("planVolumeStrategies() error = %v", err)
+			}
+			var got []string
+			for _, f := range m.Status.VolumeFallbacks {
+				got = append(got, fmt.Sprintf("%s %s", f.VolumeID, f.Zone))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("volumeFallbacks = %v, want %v", got, tt.want)
+			}
+			got = nil
+			for _, d := range m.Status.VolumeStrategies {
+				if d.Reason == "" {
+					t.Errorf("volume %s has no reason for %s", d.VolumeID, d.Strategy)
+				}
+				got = append(got, fmt.Sprintf("%s %s %s", d.VolumeID, d.Strategy, d.DestZone))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.strategies) {
+				t.Errorf("volumeStrategies = %v, want %v", got, tt.strategies)
+			}
+			if zones := m.Status.ZoneIntersection; zones == nil ||
+				fmt.Sprint(zones.VolumeZones, zones.NodeZones, zones.SharedZones) != "[us-east-1a us-east-1c] [us-east-1b us-east-1a] [us-east-1a]" {
+				t.Errorf("zoneIntersection = %+v", zones)
+			}
+			wantZone := "us-east-1c"
+			if tt.want != nil {
+				wantZone = "us-east-1b"
+			}
+			if zone := migration.VolumeZone(pvs[1]); zone != wantZone {
+				t.Errorf("pvs[1] zone = %s, want %s", zone, wantZone)
+			}
+		})
+	}
+}
+
+func TestDetachBlocked(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "node cannot unmount a volume still attached", err: fmt.Errorf("volume unmount failed: %w", &unmountBlockedError{message: "node gone"})},
+		{name: "instance stopped", err: fmt.Errorf("volume detachment failed: %w", &aws.InstanceUnavailableError{VolumeID: "vol-a"}), want: true},
+		{name: "detach timed out", err: fmt.Errorf("volume detachment failed: %w", &aws.DetachWaitError{VolumeID: "vol-a", Reason: "timed out"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detachBlocked(tt.err); got != tt.want {
+				t.Errorf("detachBlocked(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
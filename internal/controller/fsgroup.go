@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// getFSGroupPolicy returns the fsGroupPolicy of the EBS CSI driver in a cluster
+func getFSGroupPolicy(ctx context.Context, cc *multicluster.ClusterClient) (storagev1.FSGroupPolicy, error) {
+	driver := &storagev1.CSIDriver{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Name: migration.EBSCSIDriver}, driver); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return "", err
+		}
+		driver = nil
+	}
+	return migration.FSGroupPolicy(driver), nil
+}
+
+// checkFSGroups warns when the pods' fsGroup would have the kubelet change
+// the ownership of every file on a volume when it first mounts it in the
+// destination, because the destination's EBS CSI driver applies fsGroup
+// where the source's did not. On a volume with many files this keeps the
+// pod in ContainerCreating for a long time, and with fsGroupChangePolicy
+// Always it happens on every mount.
+func checkFSGroups(ctx context.Context, sourceCC, destCC *multicluster.ClusterClient, spec *corev1.PodSpec, pvs []*corev1.PersistentVolume) error {
+	if spec.SecurityContext == nil || spec.SecurityContext.FSGroup == nil {
+		return nil
+	}
+	sourcePolicy, err := getFSGroupPolicy(ctx, sourceCC)
+	if err != nil {
+		return fmt.Errorf("failed to get source CSIDriver %s: %w", migration.EBSCSIDriver, err)
+	}
+	destPolicy, err := getFSGroupPolicy(ctx, destCC)
+	if err != nil {
+		return fmt.Errorf("failed to get destination CSIDriver %s: %w", migration.EBSCSIDriver, err)
+	}
+
+	var volumes []string
+	for _, pv := range pvs {
+		if !migration.FSGroupChowns(spec, pv, sourcePolicy, destPolicy) {
+			continue
+		}
+		size := pv.Spec.Capacity[corev1.ResourceStorage]
+		volumes = append(volumes, fmt.Sprintf("%s (%s)", pv.Name, size.String()))
+	}
+	if len(volumes) == 0 {
+		return nil
+	}
+
+	when := "on the first mount"
+	if policy := spec.SecurityContext.FSGroupChangePolicy; policy == nil || *policy == corev1.FSGroupChangeAlways {
+		when = "on every mount, as fsGroupChangePolicy is Always"
+	}
+	return fmt.Errorf("the destination %s driver's fsGroupPolicy %s applies fsGroup %d where the source's %s did not, so the kubelet changes the ownership of every file on %s %s, which can delay the pods' start by many minutes; set the destination CSIDriver's fsGroupPolicy to %s or expect the delay",
+		migration.EBSCSIDriver, destPolicy, *spec.SecurityContext.FSGroup, sourcePolicy, strings.Join(volumes, ", "), when, sourcePolicy)
+}
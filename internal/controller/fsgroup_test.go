@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestCheckFSGroups(t *testing.T) {
+	driver := func(policy storagev1.FSGroupPolicy) *storagev1.CSIDriver {
+		return &storagev1.CSIDriver{
+			ObjectMeta: metav1.ObjectMeta{Name: migration.EBSCSIDriver},
+			Spec:       storagev1.CSIDriverSpec{FSGroupPolicy: &policy},
+		}
+	}
+	cluster := func(objs ...client.Object) *multicluster.ClusterClient {
+		return &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objs...).Build()}
+	}
+	pvs := []*corev1.PersistentVolume{{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-web-0"},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:    corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("4Ti")},
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: migration.EBSCSIDriver, VolumeHandle: "vol-0123", FSType: "xfs"},
+			},
+		},
+	}}
+	spec := &corev1.PodSpec{SecurityContext: &corev1.PodSecurityContext{
+		FSGroup:             ptr.To[int64](1000),
+		FSGroupChangePolicy: ptr.To(corev1.FSGroupChangeOnRootMismatch),
+	}}
+	ctx := context.Background()
+
+	// No CSIDriver object in the destination means the default policy
+	err := checkFSGroups(ctx, cluster(driver(storagev1.NoneFSGroupPolicy)), cluster(), spec, pvs)
+	if err == nil || !strings.Contains(err.Error(), "pv-web-0 (4Ti)") || !strings.Contains(err.Error(), "on the first mount") {
+		t.Errorf("checkFSGroups() = %v, want a warning naming pv-web-0 (4Ti) on the first mount", err)
+	}
+	if err := checkFSGroups(ctx, cluster(), cluster(), spec, pvs); err != nil {
+		t.Errorf("checkFSGroups() with the same policy = %v, want nil", err)
+	}
+	if err := checkFSGroups(ctx, cluster(driver(storagev1.NoneFSGroupPolicy)), cluster(), &corev1.PodSpec{}, pvs); err != nil {
+		t.Errorf("checkFSGroups() without fsGroup = %v, want nil", err)
+	}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+const (
+	// AnnotationAcknowledge on a migration acknowledges the spec.manualGates
+	// it lists, comma-separated. A gate may be acknowledged before the
+	// migration reaches it.
+	AnnotationAcknowledge = "migration.aqua.io/acknowledge"
+
+	// ConditionWaitingForAcknowledgement reports that the migration is
+	// paused at a manual gate
+	ConditionWaitingForAcknowledgement = "WaitingForAcknowledgement"
+
+	// EventManualGate is recorded when the migration pauses at a manual gate
+	EventManualGate = "ManualGate"
+)
+
+// acknowledged reports whether the migration's annotation acknowledges gate
+func acknowledged(m *migrationv1alpha1.StatefulSetMigration, gate migrationv1alpha1.ManualGate) bool {
+	for _, value := range strings.Split(m.Annotations[AnnotationAcknowledge], ",") {
+		if strings.TrimSpace(value) == string(gate) {
+			return true
+		}
+	}
+	return false
+}
+
+// waitAtGate reports whether the migration must wait at gate: it is one of
+// spec.manualGates and has not been acknowledged. The acknowledgement is
+// recorded in status.acknowledgedGates, so it holds even if the annotation
+// is later changed to acknowledge the next gate. The status is written
+// here; while waiting, nothing is requeued, since an annotation change
+// reconciles the migration again.
+func (r *StatefulSetMigrationReconciler) waitAtGate(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, gate migrationv1alpha1.ManualGate) (bool, error) {
+	if !slices.Contains(m.Spec.ManualGates, gate) || slices.Contains(m.Status.AcknowledgedGates, gate) {
+		return false, nil
+	}
+	logger := log.FromContext(ctx)
+	before := m.Status.DeepCopy()
+
+	if acknowledged(m, gate) {
+		logger.Info("Manual gate acknowledged", "gate", gate)
+		m.Status.AcknowledgedGates = append(m.Status.AcknowledgedGates, gate)
+		r.recordHistory(m, StepManualGate, "", migrationv1alpha1.HistoryResultSucceeded, fmt.Sprintf("Gate %s acknowledged", gate))
+		r.setCondition(m, ConditionWaitingForAcknowledgement, metav1.ConditionFalse, "Acknowledged", fmt.Sprintf("Gate %s was acknowledged", gate))
+		return false, r.updateStatus(ctx, m, before)
+	}
+
+	message := fmt.Sprintf("Paused at gate %s; annotate the migration with %s=%s to continue", gate, AnnotationAcknowledge, gate)
+	if c := meta.FindStatusCondition(m.Status.Conditions, ConditionWaitingForAcknowledgement); c != nil && c.Status == metav1.ConditionTrue && c.Message == message {
+		return true, nil
+	}
+	logger.Info("Waiting for manual gate to be acknowledged", "gate", gate)
+	r.recordHistory(m, StepManualGate, "", migrationv1alpha1.HistoryResultStarted, fmt.Sprintf("Waiting at gate %s", gate))
+	r.setCondition(m, ConditionWaitingForAcknowledgement, metav1.ConditionTrue, string(gate), message)
+	r.event(m, corev1.EventTypeNormal, EventManualGate, message)
+	return true, r.updateStatus(ctx, m, before)
+}
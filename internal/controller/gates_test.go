@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestWaitAtGate(t *testing.T) {
+	tests := []struct {
+		name         string
+		gates        []migrationv1alpha1.ManualGate
+		acknowledged []migrationv1alpha1.ManualGate
+		annotation   string
+		wantWaiting  bool
+		wantAcked    bool
+	}{
+		{name: "no gates"},
+		{name: "other gate only", gates: []migrationv1alpha1.ManualGate{migrationv1alpha1.ManualGateBeforeFinalize}},
+		{name: "waits", gates: []migrationv1alpha1.ManualGate{migrationv1alpha1.ManualGateAfterFreeze}, wantWaiting: true},
+		{name: "acknowledging another gate", gates: []migrationv1alpha1.ManualGate{migrationv1alpha1.ManualGateAfterFreeze},
+			annotation: "BeforeFreeze", wantWaiting: true},
+		{name: "acknowledged", gates: []migrationv1alpha1.ManualGate{migrationv1alpha1.ManualGateAfterFreeze},
+			annotation: "BeforeFreeze, AfterFreeze", wantAcked: true},
+		{name: "acknowledged earlier", gates: []migrationv1alpha1.ManualGate{migrationv1alpha1.ManualGateAfterFreeze},
+			acknowledged: []migrationv1alpha1.ManualGate{migrationv1alpha1.ManualGateAfterFreeze}, annotation: "BeforeFinalize", wantAcked: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			m := &migrationv1alpha1.StatefulSetMigration{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", Annotations: map[string]string{AnnotationAcknowledge: tt.annotation}},
+				Spec:       migrationv1alpha1.StatefulSetMigrationSpec{ManualGates: tt.gates},
+				Status: migrationv1alpha1.StatefulSetMigrationStatus{
+					Phase:             migrationv1alpha1.PhaseMigratingPods,
+					AcknowledgedGates: tt.acknowledged,
+				},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+			r := &StatefulSetMigrationReconciler{Client: c}
+
+			waiting, err := r.waitAtGate(context.Background(), m, migrationv1alpha1.ManualGateAfterFreeze)
+			if err != nil {
+				t.Fatalf("waitAtGate() error = %v", err)
+			}
+			if waiting != tt.wantWaiting {
+				t.Errorf("waitAtGate() = %v, want %v", waiting, tt.wantWaiting)
+			}
+			if got := slices.Contains(m.Status.AcknowledgedGates, migrationv1alpha1.ManualGateAfterFreeze); got != tt.wantAcked {
+				t.Errorf("AcknowledgedGates = %v, want AfterFreeze acknowledged %v", m.Status.AcknowledgedGates, tt.wantAcked)
+			}
+			if tt.wantWaiting && !meta.IsStatusConditionTrue(m.Status.Conditions, ConditionWaitingForAcknowledgement) {
+				t.Errorf("conditions = %+v, want %s", m.Status.Conditions, ConditionWaitingForAcknowledgement)
+			}
+
+			// Waiting again writes nothing new
+			if tt.wantWaiting {
+				history := len(m.Status.History)
+				if _, err := r.waitAtGate(context.Background(), m, migrationv1alpha1.ManualGateAfterFreeze); err != nil {
+					t.Fatal(err)
+				}
+				if len(m.Status.History) != history {
+					t.Errorf("history grew to %d entries while still waiting", len(m.Status.History))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// defaultGitOpsAnnotations are the annotations Flux and Argo CD honour to
+// leave an object alone
+var defaultGitOpsAnnotations = map[string]string{
+	"fluxcd.io/ignore":                      "true",
+	"kustomize.toolkit.fluxcd.io/reconcile": "disabled",
+	"argocd.argoproj.io/sync-options":       "Prune=false,Delete=false",
+}
+
+// gitOpsAnnotations returns the annotations spec.pauseGitOps adds to the
+// source namespace and StatefulSet
+func gitOpsAnnotations(m *migrationv1alpha1.StatefulSetMigration) map[string]string {
+	if len(m.Spec.PauseGitOps.Annotations) > 0 {
+		return m.Spec.PauseGitOps.Annotations
+	}
+	return defaultGitOpsAnnotations
+}
+
+// pauseGitOps adds the GitOps suspend annotations to the source namespace
+// and StatefulSet before the StatefulSet is orphan-deleted, so a GitOps
+// controller does not recreate it and re-adopt the pods being moved. An
+// annotation the object already has is left alone and not recorded, so
+// resumeGitOps only removes what the migration added.
+func (r *StatefulSetMigrationReconciler) pauseGitOps(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient, sts *appsv1.StatefulSet) error {
+	if m.Spec.PauseGitOps == nil {
+		return nil
+	}
+	if m.Status.GitOpsPause == nil {
+		m.Status.GitOpsPause = &migrationv1alpha1.GitOpsPauseStatus{}
+	}
+	pause := m.Status.GitOpsPause
+	annotations := gitOpsAnnotations(m)
+
+	ns := &corev1.Namespace{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Name: m.Spec.SourceNamespace}, ns); err != nil {
+		return fmt.Errorf("failed to get source namespace: %w", err)
+	}
+	added, err := addAnnotations(ctx, cc, ns, annotations, pause.NamespaceAnnotations)
+	if err != nil {
+		return fmt.Errorf("failed to annotate namespace %s: %w", ns.Name, err)
+	}
+	pause.NamespaceAnnotations = added
+
+	added, err = addAnnotations(ctx, cc, sts, annotations, pause.StatefulSetAnnotations)
+	if err != nil {
+		return fmt.Errorf("failed to annotate StatefulSet %s: %w", sts.Name, err)
+	}
+	pause.StatefulSetAnnotations = added
+
+	r.recordHistory(m, StepPauseGitOps, historyObject("Namespace", "", m.Spec.SourceNamespace),
+		migrationv1alpha1.HistoryResultSucceeded,
+		fmt.Sprintf("Added %d namespace and %d StatefulSet annotations", len(pause.NamespaceAnnotations), len(pause.StatefulSetAnnotations)))
+	return nil
+}
+
+// addAnnotations adds the annotations obj lacks and returns the keys added,
+// including those already recorded by an earlier attempt
+func addAnnotations(ctx context.Context, cc *multicluster.ClusterClient, obj client.Object, annotations map[string]string, recorded []string) ([]string, error) {
+	added := slices.Clone(recorded)
+	current := obj.GetAnnotations()
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	changed := false
+	for _, key := range slices.Sorted(maps.Keys(annotations)) {
+		if _, ok := current[key]; ok {
+			continue
+		}
+		if current == nil {
+			current = map[string]string{}
+		}
+		current[key] = annotations[key]
+		changed = true
+		if !slices.Contains(added, key) {
+			added = append(added, key)
+		}
+	}
+	if !changed {
+		return added, nil
+	}
+	obj.SetAnnotations(current)
+	if err := cc.Client.Patch(ctx, obj, patch); err != nil {
+		return recorded, err
+	}
+	return added, nil
+}
+
+// releaseGitOps removes the GitOps suspend annotations of a migration
+// being deleted, whatever its phase
+func (r *StatefulSetMigrationReconciler) releaseGitOps(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) error {
+	if m.Status.GitOpsPause == nil {
+		return nil
+	}
+	sourceClient, err := r.getSourceClient(ctx, m)
+	if err != nil {
+		return fmt.Errorf("failed to get source client: %w", err)
+	}
+	return r.resumeGitOps(ctx, m, sourceClient)
+}
+
+// resumeGitOps removes the annotations pauseGitOps added. The source
+// StatefulSet is usually gone by then, and a missing object has nothing
+// left to remove.
+func (r *StatefulSetMigrationReconciler) resumeGitOps(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient) error {
+	pause := m.Status.GitOpsPause
+	if pause == nil {
+		return nil
+	}
+
+	if err := removeAnnotations(ctx, cc, types.NamespacedName{Name: m.Spec.SourceNamespace},
+		&corev1.Namespace{}, pause.NamespaceAnnotations); err != nil {
+		return fmt.Errorf("failed to remove namespace annotations: %w", err)
+	}
+	pause.NamespaceAnnotations = nil
+	if err := removeAnnotations(ctx, cc, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: m.Spec.StatefulSetName},
+		&appsv1.StatefulSet{}, pause.StatefulSetAnnotations); err != nil {
+		return fmt.Errorf("failed to remove StatefulSet annotations: %w", err)
+	}
+
+	m.Status.GitOpsPause = nil
+	r.recordHistory(m, StepResumeGitOps, historyObject("Namespace", "", m.Spec.SourceNamespace),
+		migrationv1alpha1.HistoryResultSucceeded, "Removed GitOps suspend annotations")
+	return nil
+}
+
+// removeAnnotations removes keys from the object at key, if it exists
+func removeAnnotations(ctx context.Context, cc *multicluster.ClusterClient, key types.NamespacedName, obj client.Object, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := cc.Client.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := obj.GetAnnotations()
+	changed := false
+	for _, k := range keys {
+		if _, ok := annotations[k]; ok {
+			delete(annotations, k)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	obj.SetAnnotations(annotations)
+	return client.IgnoreNotFound(cc.Client.Patch(ctx, obj, patch))
+}
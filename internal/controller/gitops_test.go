@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestPauseAndResumeGitOps(t *testing.T) {
+	tests := []struct {
+		name       string
+		deleteSTS  bool
+		wantNSKeys []string
+	}{
+		{name: "StatefulSet still present", wantNSKeys: []string{"argocd.argoproj.io/sync-options", "kustomize.toolkit.fluxcd.io/reconcile"}},
+		{name: "StatefulSet orphan-deleted", deleteSTS: true, wantNSKeys: []string{"argocd.argoproj.io/sync-options", "kustomize.toolkit.fluxcd.io/reconcile"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "prod",
+				Annotations: map[string]string{"fluxcd.io/ignore": "false", "team": "data"},
+			}}
+			sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web"}}
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ns, sts).Build()
+			cc := &multicluster.ClusterClient{Client: c}
+			r := &StatefulSetMigrationReconciler{Client: c}
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+				SourceNamespace: "prod",
+				StatefulSetName: "web",
+				PauseGitOps:     &migrationv1alpha1.PauseGitOpsConfig{},
+			}}
+
+			// Freezing again after a requeue keeps the keys the first
+			// attempt recorded
+			for range 2 {
+				if err := r.pauseGitOps(ctx, m, cc, sts); err != nil {
+					t.Fatalf("pauseGitOps() error = %v", err)
+				}
+			}
+			if got := m.Status.GitOpsPause.NamespaceAnnotations; !slices.Equal(got, tt.wantNSKeys) {
+				t.Errorf("namespace annotations recorded = %v, want %v", got, tt.wantNSKeys)
+			}
+			if got := m.Status.GitOpsPause.StatefulSetAnnotations; len(got) != len(defaultGitOpsAnnotations) {
+				t.Errorf("StatefulSet annotations recorded = %v, want all defaults", got)
+			}
+
+			paused := &corev1.Namespace{}
+			if err := c.Get(ctx, types.NamespacedName{Name: "prod"}, paused); err != nil {
+				t.Fatal(err)
+			}
+			if paused.Annotations["fluxcd.io/ignore"] != "false" || paused.Annotations["kustomize.toolkit.fluxcd.io/reconcile"] != "disabled" {
+				t.Errorf("paused namespace annotations = %v", paused.Annotations)
+			}
+
+			if tt.deleteSTS {
+				if err := c.Delete(ctx, sts); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := r.resumeGitOps(ctx, m, cc); err != nil {
+				t.Fatalf("resumeGitOps() error = %v", err)
+			}
+			if m.Status.GitOpsPause != nil {
+				t.Errorf("GitOpsPause = %+v, want cleared", m.Status.GitOpsPause)
+			}
+
+			resumed := &corev1.Namespace{}
+			if err := c.Get(ctx, types.NamespacedName{Name: "prod"}, resumed); err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]string{"fluxcd.io/ignore": "false", "team": "data"}
+			if len(resumed.Annotations) != len(want) || resumed.Annotations["fluxcd.io/ignore"] != "false" || resumed.Annotations["team"] != "data" {
+				t.Errorf("resumed namespace annotations = %v, want %v", resumed.Annotations, want)
+			}
+			if !tt.deleteSTS {
+				got := &appsv1.StatefulSet{}
+				if err := c.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "web"}, got); err != nil {
+					t.Fatal(err)
+				}
+				if len(got.Annotations) != 0 {
+					t.Errorf("resumed StatefulSet annotations = %v, want none", got.Annotations)
+				}
+			}
+		})
+	}
+}
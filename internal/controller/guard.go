@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+const (
+	// DefaultGuardNamespace is the namespace holding duplicate-migration guard leases
+	DefaultGuardNamespace = "aqua-system"
+
+	// guardLabel marks leases created by the duplicate-migration guard
+	guardLabel = "migration.aqua.io/guard"
+
+	// guardTargetAnnotation records the workload a guard lease protects
+	guardTargetAnnotation = "migration.aqua.io/target"
+
+	// guardHolderUIDAnnotation records the UID of the migration holding a guard lease
+	guardHolderUIDAnnotation = "migration.aqua.io/holder-uid"
+
+	// guardVolumesAnnotation records the EBS volumes a guard lease protects,
+	// comma-separated
+	guardVolumesAnnotation = "migration.aqua.io/volumes"
+)
+
+// guardLeaseName returns the lease name guarding a source StatefulSet. The
+// source API server host identifies the cluster so migrations that reach it
+// through different kubeconfig secrets still collide.
+func guardLeaseName(sourceHost, namespace, name string) string {
+	sum := sha256.Sum256([]byte(guardTarget(sourceHost, namespace, name)))
+	return "stsm-guard-" + hex.EncodeToString(sum[:8])
+}
+
+// guardTarget returns a human-readable description of a guarded StatefulSet
+func guardTarget(sourceHost, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", sourceHost, namespace, name)
+}
+
+// guardHolder returns the lease holder identity for a migration
+func guardHolder(m *migrationv1alpha1.StatefulSetMigration) string {
+	return fmt.Sprintf("%s/%s", m.Namespace, m.Name)
+}
+
+// acquireGuard takes the guard lease for the migration's source StatefulSet.
+// It returns the identity of the conflicting migration when another active
+// migration already holds the lease, or an empty string once the lease is held.
+func (r *StatefulSetMigrationReconciler) acquireGuard(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceHost string) (string, error) {
+	name := guardLeaseName(sourceHost, m.Spec.SourceNamespace, m.Spec.StatefulSetName)
+	holder := guardHolder(m)
+	now := metav1.NowMicro()
+
+	lease := &coordinationv1.Lease{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: r.guardNamespace(), Name: name}, lease)
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: r.guardNamespace(),
+				Labels:    map[string]string{guardLabel: "true"},
+				Annotations: map[string]string{
+					guardTargetAnnotation:    guardTarget(sourceHost, m.Spec.SourceNamespace, m.Spec.StatefulSetName),
+					guardHolderUIDAnnotation: string(m.UID),
+				},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity: &holder,
+				AcquireTime:    &now,
+				RenewTime:      &now,
+			},
+		}
+		if err := r.Create(ctx, lease); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return "", fmt.Errorf("guard lease %s was created concurrently, retrying", name)
+			}
+			return "", fmt.Errorf("failed to create guard lease %s: %w", name, err)
+		}
+		m.Status.GuardLease = name
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get guard lease %s: %w", name, err)
+	}
+
+	if lease.Annotations[guardHolderUIDAnnotation] != string(m.UID) {
+		current := ""
+		if lease.Spec.HolderIdentity != nil {
+			current = *lease.Spec.HolderIdentity
+		}
+		active, err := r.guardHolderActive(ctx, current, lease.Annotations[guardHolderUIDAnnotation])
+		if err != nil {
+			return "", err
+		}
+		if active {
+			return current, nil
+		}
+
+		// The previous holder is gone or finished; take the lease over
+		if lease.Annotations == nil {
+			lease.Annotations = map[string]string{}
+		}
+		lease.Annotations[guardHolderUIDAnnotation] = string(m.UID)
+		lease.Spec.HolderIdentity = &holder
+		lease.Spec.AcquireTime = &now
+		lease.Spec.RenewTime = &now
+		if err := r.Update(ctx, lease); err != nil {
+			return "", fmt.Errorf("failed to take over guard lease %s: %w", name, err)
+		}
+	}
+
+	m.Status.GuardLease = name
+	return "", nil
+}
+
+// guardVolumes records the migration's EBS volumes on its guard lease, so
+// that a migration of another StatefulSet that reaches the same volumes,
+// such as through PVs bound in another namespace, is held too. It returns
+// the holder of another active guard lease that lists one of the volumes,
+// and that volume, or empty strings once the volumes are recorded.
+func (r *StatefulSetMigrationReconciler) guardVolumes(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, volumeIDs []string) (string, string, error) {
+	leases := &coordinationv1.LeaseList{}
+	if err := r.List(ctx, leases, client.InNamespace(r.guardNamespace()), client.MatchingLabels{guardLabel: "true"}); err != nil {
+		return "", "", fmt.Errorf("failed to list guard leases: %w", err)
+	}
+	var own *coordinationv1.Lease
+	for i := range leases.Items {
+		lease := &leases.Items[i]
+		if lease.Name == m.Status.GuardLease {
+			own = lease
+			continue
+		}
+		held := strings.Split(lease.Annotations[guardVolumesAnnotation], ",")
+		i := slices.IndexFunc(volumeIDs, func(id string) bool { return slices.Contains(held, id) })
+		if i < 0 {
+			continue
+		}
+		holder := ""
+		if lease.Spec.HolderIdentity != nil {
+			holder = *lease.Spec.HolderIdentity
+		}
+		active, err := r.guardHolderActive(ctx, holder, lease.Annotations[guardHolderUIDAnnotation])
+		if err != nil {
+			return "", "", err
+		}
+		if active {
+			return holder, volumeIDs[i], nil
+		}
+	}
+	if own == nil {
+		return "", "", fmt.Errorf("guard lease %s not found", m.Status.GuardLease)
+	}
+
+	volumes := strings.Join(slices.Sorted(slices.Values(volumeIDs)), ",")
+	if own.Annotations[guardVolumesAnnotation] == volumes {
+		return "", "", nil
+	}
+	if own.Annotations == nil {
+		own.Annotations = map[string]string{}
+	}
+	own.Annotations[guardVolumesAnnotation] = volumes
+	if err := r.Update(ctx, own); err != nil {
+		return "", "", fmt.Errorf("failed to record volumes on guard lease %s: %w", own.Name, err)
+	}
+	return "", "", nil
+}
+
+// guardHolderActive reports whether the migration holding a guard lease still
+// exists and has not completed. Failed migrations keep the lease because the
+// workload is left half-migrated until an operator intervenes.
+func (r *StatefulSetMigrationReconciler) guardHolderActive(ctx context.Context, holder, uid string) (bool, error) {
+	namespace, name, ok := splitHolder(holder)
+	if !ok {
+		return false, nil
+	}
+
+	other := &migrationv1alpha1.StatefulSetMigration{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, other); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get guard holder %s: %w", holder, err)
+	}
+	if string(other.UID) != uid {
+		return false, nil
+	}
+	return holdsGuard(other.Status.Phase), nil
+}
+
+// holdsGuard reports whether a migration in phase still holds its guard. A
+// Degraded migration completed before its workload regressed.
+func holdsGuard(phase migrationv1alpha1.MigrationPhase) bool {
+	return phase != migrationv1alpha1.PhaseCompleted && phase != migrationv1alpha1.PhaseDegraded
+}
+
+// releaseGuard deletes the migration's guard lease if it still holds it
+func (r *StatefulSetMigrationReconciler) releaseGuard(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) error {
+	if m.Status.GuardLease == "" {
+		return nil
+	}
+
+	lease := &coordinationv1.Lease{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: r.guardNamespace(), Name: m.Status.GuardLease}, lease)
+	if apierrors.IsNotFound(err) {
+		m.Status.GuardLease = ""
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get guard lease %s: %w", m.Status.GuardLease, err)
+	}
+
+	if lease.Annotations[guardHolderUIDAnnotation] == string(m.UID) {
+		if err := r.Delete(ctx, lease); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete guard lease %s: %w", lease.Name, err)
+		}
+	}
+	m.Status.GuardLease = ""
+	return nil
+}
+
+// guardNamespace returns the namespace holding guard leases
+func (r *StatefulSetMigrationReconciler) guardNamespace() string {
+	if r.GuardNamespace == "" {
+		return DefaultGuardNamespace
+	}
+	return r.GuardNamespace
+}
+
+// splitHolder splits a namespace/name holder identity
+func splitHolder(holder string) (string, string, bool) {
+	namespace, name, ok := strings.Cut(holder, "/")
+	return namespace, name, ok && namespace != "" && name != ""
+}
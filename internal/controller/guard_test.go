@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestGuardLeaseName(t *testing.T) {
+	name := guardLeaseName("https://source.example.com", "prod", "web")
+
+	if !strings.HasPrefix(name, "stsm-guard-") {
+		t.Errorf("guardLeaseName() = %q, want stsm-guard- prefix", name)
+	}
+	if name != guardLeaseName("https://source.example.com", "prod", "web") {
+		t.Error("guardLeaseName() must be deterministic")
+	}
+
+	others := []string{
+		guardLeaseName("https://other.example.com", "prod", "web"),
+		guardLeaseName("https://source.example.com", "staging", "web"),
+		guardLeaseName("https://source.example.com", "prod", "db"),
+	}
+	for _, other := range others {
+		if other == name {
+			t.Errorf("different targets produced the same lease name %q", name)
+		}
+	}
+}
+
+func TestSplitHolder(t *testing.T) {
+	tests := []struct {
+		holder        string
+		wantNamespace string
+		wantName      string
+		wantOK        bool
+	}{
+		{holder: "default/migrate-web", wantNamespace: "default", wantName: "migrate-web", wantOK: true},
+		{holder: "migrate-web", wantOK: false},
+		{holder: "/migrate-web", wantName: "migrate-web", wantOK: false},
+		{holder: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.holder, func(t *testing.T) {
+			namespace, name, ok := splitHolder(tt.holder)
+			if ok != tt.wantOK || (ok && (namespace != tt.wantNamespace || name != tt.wantName)) {
+				t.Errorf("splitHolder(%q) = %q, %q, %v", tt.holder, namespace, name, ok)
+			}
+		})
+	}
+}
+
+func TestGuardVolumes(t *testing.T) {
+	ctx := context.Background()
+	guardLease := func(name, holder, uid, volumes string) *coordinationv1.Lease {
+		return &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   DefaultGuardNamespace,
+				Name:        name,
+				Labels:      map[string]string{guardLabel: "true"},
+				Annotations: map[string]string{guardHolderUIDAnnotation: uid, guardVolumesAnnotation: volumes},
+			},
+			Spec: coordinationv1.LeaseSpec{HolderIdentity: ptr.To(holder)},
+		}
+	}
+	other := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "db", UID: "uid-db"},
+		Status:     migrationv1alpha1.StatefulSetMigrationStatus{Phase: migrationv1alpha1.PhaseMigratingPods},
+	}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", UID: "uid-web"},
+		Status:     migrationv1alpha1.StatefulSetMigrationStatus{GuardLease: "stsm-guard-web"},
+	}
+	c := fake.NewClientBuilder().WithScheme(volumeMigrationScheme(t)).WithObjects(
+		other,
+		guardLease("stsm-guard-db", "ops/db", "uid-db", "vol-0a,vol-0b"),
+		guardLease("stsm-guard-web", "ops/web", "uid-web", ""),
+	).Build()
+	r := &StatefulSetMigrationReconciler{Client: c}
+
+	holder, volumeID, err := r.guardVolumes(ctx, m, []string{"vol-0c", "vol-0b"})
+	if err != nil || holder != "ops/db" || volumeID != "vol-0b" {
+		t.Errorf("guardVolumes() = %q, %q, %v, want vol-0b held by ops/db", holder, volumeID, err)
+	}
+
+	// Once the other migration completes, the volumes are recorded on our lease
+	other.Status.Phase = migrationv1alpha1.PhaseCompleted
+	if err := c.Update(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	if holder, _, err := r.guardVolumes(ctx, m, []string{"vol-0c", "vol-0b"}); err != nil || holder != "" {
+		t.Fatalf("guardVolumes() = %q, %v, want the volumes recorded", holder, err)
+	}
+	lease := &coordinationv1.Lease{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: DefaultGuardNamespace, Name: "stsm-guard-web"}, lease); err != nil {
+		t.Fatal(err)
+	}
+	if got := lease.Annotations[guardVolumesAnnotation]; got != "vol-0b,vol-0c" {
+		t.Errorf("guard lease volumes = %q, want vol-0b,vol-0c", got)
+	}
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+// ReadinessCheckInterval is how long a readiness result is reused, so
+// frequent probes do not turn into a stream of AWS and API server calls
+const ReadinessCheckInterval = time.Minute
+
+// readinessCheckTimeout bounds each check, below the kubelet's probe timeout
+// of a typical Deployment
+const readinessCheckTimeout = 5 * time.Second
+
+// readinessChecker reports the controller ready only while it can list
+// migrations in its own cluster and its AWS credentials work, since every
+// reconcile would fail otherwise
+type readinessChecker struct {
+	reader client.Reader
+	ebs    *aws.EBSClient
+	clock  clock.PassiveClock
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+// ReadinessCheck returns a readiness checker that verifies access to the
+// local API server through reader, which should bypass the cache, and to AWS
+// through ebs. Results are reused for ReadinessCheckInterval.
+func ReadinessCheck(reader client.Reader, ebs *aws.EBSClient, clk clock.PassiveClock) healthz.Checker {
+	if clk == nil {
+		clk = clock.RealClock{}
+	}
+	c := &readinessChecker{reader: reader, ebs: ebs, clock: clk}
+	return c.check
+}
+
+func (c *readinessChecker) check(req *http.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.checkedAt.IsZero() && c.clock.Since(c.checkedAt) < ReadinessCheckInterval {
+		return c.err
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), readinessCheckTimeout)
+	defer cancel()
+	c.err = c.checkAccess(ctx)
+	c.checkedAt = c.clock.Now()
+	return c.err
+}
+
+func (c *readinessChecker) checkAccess(ctx context.Context) error {
+	if err := c.reader.List(ctx, &migrationv1alpha1.StatefulSetMigrationList{}, client.Limit(1)); err != nil {
+		return fmt.Errorf("cannot list StatefulSetMigrations: %w", err)
+	}
+	if c.ebs != nil {
+		// Throttling shows the credentials work; it is not worth going unready for
+		if err := c.ebs.CheckAccess(ctx); err != nil && !errors.Is(err, aws.ErrThrottled) {
+			return fmt.Errorf("cannot reach AWS: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go"
+	"k8s.io/apimachinery/pkg/runtime"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+// describeVolumesEC2 answers DescribeVolumes with err and counts the calls
+type describeVolumesEC2 struct {
+	aws.EC2API
+	err   error
+	calls int
+}
+
+func (f *describeVolumesEC2) DescribeVolumes(context.Context, *ec2.DescribeVolumesInput, ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ec2.DescribeVolumesOutput{}, nil
+}
+
+func TestReadinessCheck(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	clk := clocktesting.NewFakeClock(time.Now())
+	ec2API := &describeVolumesEC2{err: &smithy.GenericAPIError{Code: "UnauthorizedOperation"}}
+	check := ReadinessCheck(fake.NewClientBuilder().WithScheme(scheme).Build(), aws.NewEBSClientFromAPI(ec2API, clk, "us-east-1"), clk)
+	req := httptest.NewRequest("GET", "/readyz", nil)
+
+	if err := check(req); !errors.Is(err, aws.ErrUnauthorized) {
+		t.Fatalf("check() error = %v, want the AWS credentials reported", err)
+	}
+
+	// The result is reused until the interval passes
+	ec2API.err = nil
+	if err := check(req); err == nil || ec2API.calls != 1 {
+		t.Errorf("check() = %v after %d calls, want the cached failure", err, ec2API.calls)
+	}
+	clk.Step(ReadinessCheckInterval)
+	if err := check(req); err != nil {
+		t.Errorf("check() error = %v, want ready once AWS access works", err)
+	}
+
+	ec2API.err = &smithy.GenericAPIError{Code: "RequestLimitExceeded"}
+	clk.Step(ReadinessCheckInterval)
+	if err := check(req); err != nil {
+		t.Errorf("check() error = %v, want throttling tolerated", err)
+	}
+
+	// A local API server that cannot serve migrations, e.g. without the CRD
+	noCRD := ReadinessCheck(fake.NewClientBuilder().Build(), nil, clk)
+	if err := noCRD(req); err == nil {
+		t.Error("check() = nil, want an error when migrations cannot be listed")
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+)
+
+const (
+	// MaxHistoryEntries is the number of history entries kept in status
+	MaxHistoryEntries = 50
+
+	// maxHistoryMessageLength bounds each history message so status stays small
+	maxHistoryMessageLength = 256
+)
+
+// Steps recorded in status.history
+const (
+	StepPhase             = "Phase"
+	StepPreFlight         = "PreFlightChecks"
+	StepCreateNamespace   = "CreateNamespace"
+	StepRetainPVs         = "RetainPVs"
+	StepOrphanSTS         = "OrphanStatefulSet"
+	StepQuiesce           = "QuiescePod"
+	StepVolumeModify      = "WaitVolumeModification"
+	StepVolumeExpand      = "WaitVolumeExpansion"
+	StepDeletePod         = "DeletePod"
+	StepLockVolume        = "LockVolume"
+	StepUnmountVolume     = "WaitVolumeUnmount"
+	StepDetachVolume      = "WaitVolumeDetach"
+	StepVolumeMigration   = "VolumeMigration"
+	StepForceDetach       = "ForceDetachVolume"
+	StepStrategyFallback  = "StrategyFallback"
+	StepSnapshot          = "CreateSnapshot"
+	StepShareSnapshot     = "ShareSnapshot"
+	StepCopySnapshot      = "CopySnapshot"
+	StepCreateVolume      = "CreateVolume"
+	StepCleanSnapshot     = "DeleteSnapshot"
+	StepCreatePV          = "CreatePV"
+	StepAdoptPV           = "AdoptPV"
+	StepCreatePVC         = "CreatePVC"
+	StepAdoptPVC          = "AdoptPVC"
+	StepInspectVolume     = "InspectVolume"
+	StepAdoptPod          = "AdoptMigratedPod"
+	StepCreateSTS         = "CreateStatefulSet"
+	StepScaleSTS          = "ScaleStatefulSet"
+	StepReconcileSTS      = "ReconcileStatefulSet"
+	StepPodReady          = "WaitPodReady"
+	StepPVCBound          = "WaitPVCBound"
+	StepSkipPod           = "SkipFailedPod"
+	StepProvisionVolume   = "ProvisionVolume"
+	StepRetagVolume       = "RetagVolume"
+	StepCleanup           = "CleanupSource"
+	StepArchive           = "ArchiveState"
+	StepPauseGitOps       = "PauseGitOps"
+	StepResumeGitOps      = "ResumeGitOps"
+	StepVeleroBackup      = "VeleroBackup"
+	StepVeleroRestore     = "VeleroRestore"
+	StepSuspendJob        = "SuspendJob"
+	StepRecreateJob       = "RecreateJob"
+	StepRecreateCompanion = "RecreateCompanion"
+	StepCutoverDNS        = "CutoverDNS"
+	StepImagePrePull      = "ImagePrePull"
+	StepWatch             = "PostMigrationWatch"
+	StepManualGate        = "ManualGate"
+)
+
+// recordHistory appends an entry to status.history, dropping the oldest
+// entries once MaxHistoryEntries is exceeded, and counts the step in metrics
+func (r *StatefulSetMigrationReconciler) recordHistory(m *migrationv1alpha1.StatefulSetMigration, step, object string, result migrationv1alpha1.HistoryResult, message string) {
+	metrics.ObserveStep(step, string(result))
+
+	if len(message) > maxHistoryMessageLength {
+		// Cut on a rune boundary so the message stays valid UTF-8
+		cut := maxHistoryMessageLength - 3
+		for cut > 0 && !utf8.RuneStart(message[cut]) {
+			cut--
+		}
+		message = message[:cut] + "..."
+	}
+
+	m.Status.History = append(m.Status.History, migrationv1alpha1.HistoryEntry{
+		Time:    metav1.NewTime(r.clock().Now()),
+		Step:    step,
+		Object:  object,
+		Result:  result,
+		Message: message,
+	})
+
+	if overflow := len(m.Status.History) - MaxHistoryEntries; overflow > 0 {
+		m.Status.History = append(m.Status.History[:0:0], m.Status.History[overflow:]...)
+	}
+}
+
+// historyObject formats an object reference for a history entry
+func historyObject(kind, namespace, name string) string {
+	if namespace == "" {
+		return fmt.Sprintf("%s/%s", kind, name)
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestRecordHistory(t *testing.T) {
+	r := &StatefulSetMigrationReconciler{}
+	tests := []struct {
+		name      string
+		records   int
+		wantLen   int
+		wantFirst string
+	}{
+		{name: "single entry", records: 1, wantLen: 1, wantFirst: "step-0"},
+		{name: "at capacity", records: MaxHistoryEntries, wantLen: MaxHistoryEntries, wantFirst: "step-0"},
+		{name: "oldest entries dropped", records: MaxHistoryEntries + 5, wantLen: MaxHistoryEntries, wantFirst: "step-5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{}
+			for i := 0; i < tt.records; i++ {
+				r.recordHistory(m, fmt.Sprintf("step-%d", i), "", migrationv1alpha1.HistoryResultSucceeded, "")
+			}
+			if len(m.Status.History) != tt.wantLen {
+				t.Fatalf("len(History) = %d, want %d", len(m.Status.History), tt.wantLen)
+			}
+			if m.Status.History[0].Step != tt.wantFirst {
+				t.Errorf("History[0].Step = %q, want %q", m.Status.History[0].Step, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestRecordHistoryTruncatesMessage(t *testing.T) {
+	r := &StatefulSetMigrationReconciler{}
+	m := &migrationv1alpha1.StatefulSetMigration{}
+	r.recordHistory(m, StepPhase, "", migrationv1alpha1.HistoryResultFailed, strings.Repeat("x", 1000))
+
+	if got := len(m.Status.History[0].Message); got != maxHistoryMessageLength {
+		t.Errorf("message length = %d, want %d", got, maxHistoryMessageLength)
+	}
+
+	// A multi-byte rune straddling the limit is dropped whole
+	r.recordHistory(m, StepPhase, "", migrationv1alpha1.HistoryResultFailed, strings.Repeat("x", maxHistoryMessageLength-4)+strings.Repeat("é", 10))
+	got := m.Status.History[1].Message
+	if !utf8.ValidString(got) || got != strings.Repeat("x", maxHistoryMessageLength-4)+"..." {
+		t.Errorf("message = %q, want valid UTF-8 cut before the split rune", got)
+	}
+}
+
+func TestHistoryObject(t *testing.T) {
+	if got := historyObject("Pod", "default", "web-0"); got != "Pod/default/web-0" {
+		t.Errorf("historyObject() = %q", got)
+	}
+	if got := historyObject("PersistentVolume", "", "pv-1"); got != "PersistentVolume/pv-1" {
+		t.Errorf("historyObject() = %q", got)
+	}
+}
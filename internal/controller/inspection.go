@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// DefaultVolumeInspectionTimeout is how long an inspection command may run by default
+const DefaultVolumeInspectionTimeout = 5 * time.Minute
+
+// VolumeInspectionError is returned when a moved volume did not pass
+// spec.volumeInspection. The volume is left in the destination as it is.
+type VolumeInspectionError struct {
+	// PVC is the destination PVC that was inspected
+	PVC string
+
+	// Err is why the inspection failed
+	Err error
+}
+
+func (e *VolumeInspectionError) Error() string {
+	return fmt.Sprintf("volume inspection of %s failed: %v", e.PVC, e.Err)
+}
+
+func (e *VolumeInspectionError) Unwrap() error {
+	return e.Err
+}
+
+// volumeInspectionTimeout returns the time allowed for an inspection command
+func volumeInspectionTimeout(cfg *migrationv1alpha1.VolumeInspectionConfig) time.Duration {
+	if cfg.Timeout != nil {
+		return cfg.Timeout.Duration
+	}
+	return DefaultVolumeInspectionTimeout
+}
+
+// inspectVolume runs spec.volumeInspection against a moved volume before its
+// pod is included in the destination StatefulSet: a temporary pod mounts the
+// destination PVC read-only and runs the command, which must exit 0. The pod
+// is deleted afterwards either way, so the volume can attach to the
+// StatefulSet's pod. A pod left by an earlier attempt is replaced.
+func (r *StatefulSetMigrationReconciler) inspectVolume(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient, mv *podMove) error {
+	cfg := m.Spec.VolumeInspection
+	if cfg == nil || mv.provision {
+		return nil
+	}
+	logger := log.FromContext(ctx)
+	object := historyObject("PersistentVolumeClaim", m.Spec.DestNamespace, mv.pvcName)
+	fail := func(err error) error {
+		r.recordHistory(m, StepInspectVolume, object, migrationv1alpha1.HistoryResultFailed, err.Error())
+		return &VolumeInspectionError{PVC: mv.pvcName, Err: err}
+	}
+
+	pod := migration.InspectionPod(m.Spec.DestNamespace, mv.pvcName, cfg.Image, cfg.Command, cfg.NodeSelector)
+	key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	if err := cc.Client.Get(ctx, key, &corev1.Pod{}); err == nil {
+		if err := deleteIfExists(ctx, cc, key, &corev1.Pod{}); err != nil {
+			return fmt.Errorf("failed to delete earlier inspection pod %s: %w", pod.Name, err)
+		}
+		if err := r.waitForPodDeletion(ctx, cc, pod.Namespace, pod.Name); err != nil {
+			return err
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	logger.Info("Inspecting volume in destination", "pvc", mv.pvcName, "pod", pod.Name)
+	if err := cc.Client.Create(ctx, pod); err != nil {
+		return fmt.Errorf("failed to create inspection pod %s: %w", pod.Name, err)
+	}
+	defer func() {
+		if err := cc.Client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete inspection pod", "pod", pod.Name)
+		}
+	}()
+
+	start := r.clock().Now()
+	message, err := r.waitForCommandPod(ctx, cc, key, volumeInspectionTimeout(cfg), StepInspectVolume, migration.InspectionResult)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return fail(err)
+	}
+	if message == "" {
+		message = fmt.Sprintf("Passed after %s", r.clock().Since(start).Round(time.Second))
+	}
+	r.recordHistory(m, StepInspectVolume, object, migrationv1alpha1.HistoryResultSucceeded, message)
+	return nil
+}
+
+// waitForCommandPod waits for a pod that runs a command once, such as an
+// inspection or probe pod, to finish, and returns its termination message,
+// or why it failed. result reads the pod's outcome; the wait is tracked as
+// step.
+func (r *StatefulSetMigrationReconciler) waitForCommandPod(ctx context.Context, cc *multicluster.ClusterClient, key types.NamespacedName, timeout time.Duration,
+	step string, result func(*corev1.Pod) (bool, string, error)) (string, error) {
+	defer metrics.TrackWait(step)()
+
+	deadline := r.clock().NewTimer(timeout)
+	defer deadline.Stop()
+
+	reader := cc.Reader(key.Namespace)
+	ticker := r.clock().NewTicker(r.pollInterval(2 * time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-deadline.C():
+			return "", fmt.Errorf("pod %s did not finish within %s", key.Name, timeout)
+		case <-ticker.C():
+			pod := &corev1.Pod{}
+			if err := reader.Get(ctx, key, pod); err != nil {
+				if apierrors.IsNotFound(err) {
+					return "", fmt.Errorf("pod %s was deleted before it finished", key.Name)
+				}
+				return "", err
+			}
+			if done, message, err := result(pod); done {
+				return message, err
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestInspectVolume(t *testing.T) {
+	tests := []struct {
+		name       string
+		inspection *migrationv1alpha1.VolumeInspectionConfig
+		// status is what the inspection pod reports once created
+		status     corev1.PodStatus
+		wantErr    bool
+		wantResult migrationv1alpha1.HistoryResult
+	}{
+		{name: "disabled"},
+		{
+			name:       "passed",
+			inspection: &migrationv1alpha1.VolumeInspectionConfig{Command: []string{"test", "-s", "/data/PG_VERSION"}},
+			status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+			wantResult: migrationv1alpha1.HistoryResultSucceeded,
+		},
+		{
+			name:       "command failed",
+			inspection: &migrationv1alpha1.VolumeInspectionConfig{Command: []string{"test", "-s", "/data/PG_VERSION"}},
+			status: corev1.PodStatus{Phase: corev1.PodFailed, ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}},
+			}}},
+			wantErr:    true,
+			wantResult: migrationv1alpha1.HistoryResultFailed,
+		},
+		{
+			name:       "never finished",
+			inspection: &migrationv1alpha1.VolumeInspectionConfig{Command: []string{"sleep", "3600"}},
+			status:     corev1.PodStatus{Phase: corev1.PodPending},
+			wantErr:    true,
+			wantResult: migrationv1alpha1.HistoryResultFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if pod, ok := obj.(*corev1.Pod); ok {
+						pod.Status = tt.status
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}).Build()
+			clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			r := &StatefulSetMigrationReconciler{Clock: clk}
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+				DestNamespace:    "new",
+				VolumeInspection: tt.inspection,
+			}}
+
+			done := make(chan error, 1)
+			go func() {
+				done <- r.inspectVolume(ctx, m, &multicluster.ClusterClient{Client: c}, &podMove{pvcName: "data-web-0"})
+			}()
+			var err error
+			deadline := time.After(10 * time.Second)
+		wait:
+			for {
+				select {
+				case err = <-done:
+					break wait
+				case <-deadline:
+					t.Fatal("inspectVolume() did not return on the fake clock")
+				case <-time.After(time.Millisecond):
+					if clk.HasWaiters() {
+						clk.Step(time.Minute)
+					}
+				}
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("inspectVolume() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var inspection *VolumeInspectionError
+			if tt.wantErr && !errors.As(err, &inspection) {
+				t.Errorf("inspectVolume() error = %v, want a VolumeInspectionError", err)
+			}
+			if tt.inspection == nil {
+				if len(m.Status.History) != 0 {
+					t.Errorf("history = %+v, want none when inspection is disabled", m.Status.History)
+				}
+				return
+			}
+			if len(m.Status.History) != 1 || m.Status.History[0].Step != StepInspectVolume || m.Status.History[0].Result != tt.wantResult {
+				t.Errorf("history = %+v, want one %s entry with result %s", m.Status.History, StepInspectVolume, tt.wantResult)
+			}
+			err = c.Get(ctx, types.NamespacedName{Namespace: "new", Name: "inspect-data-web-0"}, &corev1.Pod{})
+			if !apierrors.IsNotFound(err) {
+				t.Errorf("inspection pod after inspectVolume(): err = %v, want it deleted", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// checkInTreeEBS decides whether legacy in-tree EBS volumes move with an EBS
+// CSI source. The in-tree plugin waits for device names that some node
+// variants, such as Bottlerocket, never create, so pods placed there would
+// hang in ContainerCreating after their volumes had moved. When any
+// destination node the pods may run on is one of them, the volumes are
+// converted, which needs the EBS CSI driver in the destination.
+func checkInTreeEBS(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient, podSpec *corev1.PodSpec, pvs []*corev1.PersistentVolume) error {
+	m.Status.ConvertInTreeEBS = false
+	var inTree []string
+	for _, pv := range pvs {
+		if migration.IsInTreeEBS(pv) {
+			inTree = append(inTree, pv.Name)
+		}
+	}
+	if len(inTree) == 0 {
+		return nil
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := destCC.Client.List(ctx, nodes); err != nil {
+		return fmt.Errorf("failed to list destination nodes: %w", err)
+	}
+	broken := map[migration.NodeVariant]int{}
+	for _, node := range migration.NodesAccepting(podSpec, nodes.Items) {
+		if variant := migration.NodeVariantOf(&node); migration.InTreeEBSBroken(variant) {
+			broken[variant]++
+		}
+	}
+	if len(broken) == 0 {
+		return nil
+	}
+	variants := make([]string, 0, len(broken))
+	for variant, count := range broken {
+		variants = append(variants, fmt.Sprintf("%d %s", count, variant))
+	}
+	slices.Sort(variants)
+
+	err := destCC.Client.Get(ctx, types.NamespacedName{Name: migration.EBSCSIDriver}, &storagev1.CSIDriver{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("the in-tree EBS plugin cannot mount PVs %s on the %s destination nodes the pods may run on, and the destination has no CSIDriver %s to mount them with; install the EBS CSI driver",
+			strings.Join(inTree, ", "), strings.Join(variants, " and "), migration.EBSCSIDriver)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get destination CSIDriver %s: %w", migration.EBSCSIDriver, err)
+	}
+	m.Status.ConvertInTreeEBS = true
+	log.FromContext(ctx).Info("Moving in-tree EBS volumes with an EBS CSI source", "pvs", inTree, "nodes", variants)
+	return nil
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestCheckInTreeEBS(t *testing.T) {
+	node := func(name, osImage string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{OSImage: osImage}},
+		}
+	}
+	inTree := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-legacy"}, Spec: corev1.PersistentVolumeSpec{
+		PersistentVolumeSource: corev1.PersistentVolumeSource{AWSElasticBlockStore: &corev1.AWSElasticBlockStoreVolumeSource{VolumeID: "vol-1"}},
+	}}
+	csi := &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-csi"}, Spec: corev1.PersistentVolumeSpec{
+		PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{Driver: migration.EBSCSIDriver, VolumeHandle: "vol-2"}},
+	}}
+	driver := &storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{Name: migration.EBSCSIDriver}}
+
+	tests := []struct {
+		name        string
+		objects     []client.Object
+		pvs         []*corev1.PersistentVolume
+		wantConvert bool
+		wantErr     string
+	}{
+		{name: "CSI volumes only", objects: []client.Object{node("br-1", "Bottlerocket OS 1.20.0 (aws-k8s-1.29)")}, pvs: []*corev1.PersistentVolume{csi}},
+		{name: "Amazon Linux nodes", objects: []client.Object{node("al2-1", "Amazon Linux 2"), node("al2023-1", "Amazon Linux 2023.4.20240416")},
+			pvs: []*corev1.PersistentVolume{inTree, csi}},
+		{name: "Bottlerocket nodes", objects: []client.Object{node("al2-1", "Amazon Linux 2"), node("br-1", "Bottlerocket OS 1.20.0 (aws-k8s-1.29)"), driver},
+			pvs: []*corev1.PersistentVolume{inTree, csi}, wantConvert: true},
+		{name: "Bottlerocket nodes without the CSI driver", objects: []client.Object{node("br-1", "Bottlerocket OS 1.20.0 (aws-k8s-1.29)")},
+			pvs:     []*corev1.PersistentVolume{inTree},
+			wantErr: "the in-tree EBS plugin cannot mount PVs pv-legacy on the 1 Bottlerocket destination nodes the pods may run on, and the destination has no CSIDriver ebs.csi.aws.com to mount them with; install the EBS CSI driver"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.objects...).Build()
+			m := &migrationv1alpha1.StatefulSetMigration{Status: migrationv1alpha1.StatefulSetMigrationStatus{ConvertInTreeEBS: true}}
+			podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "db", Image: "postgres:16"}}}
+
+			err := checkInTreeEBS(context.Background(), m, &multicluster.ClusterClient{Client: destClient}, podSpec, tt.pvs)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("checkInTreeEBS() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("checkInTreeEBS() error = %v, want %q", err, tt.wantErr)
+			}
+			if m.Status.ConvertInTreeEBS != tt.wantConvert {
+				t.Errorf("status.convertInTreeEBS = %v, want %v", m.Status.ConvertInTreeEBS, tt.wantConvert)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/internal/velero"
+)
+
+// destIPFamilies returns the IP families the destination cluster serves
+func destIPFamilies(ctx context.Context, cc *multicluster.ClusterClient) ([]corev1.IPFamily, error) {
+	kubernetes := &corev1.Service{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: "default", Name: "kubernetes"}, kubernetes); err != nil {
+		return nil, fmt.Errorf("failed to get the kubernetes service: %w", err)
+	}
+	nodes := &corev1.NodeList{}
+	if err := cc.Client.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	return migration.ClusterIPFamilies(kubernetes, nodes.Items), nil
+}
+
+// sourceService returns the StatefulSet's headless service in the source
+// namespace, or nil when the StatefulSet names none or it does not exist
+func sourceService(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient, serviceName string) (*corev1.Service, error) {
+	if serviceName == "" {
+		return nil, nil
+	}
+	svc := &corev1.Service{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: serviceName}, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get source service: %w", err)
+	}
+	return svc, nil
+}
+
+// checkIPFamilies fails when the destination cluster does not serve the IP
+// families the StatefulSet's headless service needs, unless
+// overrides.ignoreIPFamilyMismatch is set, in which case the service is
+// moved to the destination's family
+func checkIPFamilies(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient, serviceName string) error {
+	svc, err := sourceService(ctx, m, sourceCC, serviceName)
+	if err != nil || svc == nil {
+		return err
+	}
+	dest, err := destIPFamilies(ctx, destCC)
+	if err != nil {
+		return err
+	}
+	if err := migration.CheckServiceIPFamilies(svc, dest); err != nil {
+		if overrides(m).IgnoreIPFamilyMismatch {
+			log.FromContext(ctx).Info("Ignoring IP family mismatch because it is overridden", "reason", err.Error())
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// ensureIPFamilyModifier creates the Velero resource modifier that rewrites
+// the headless service's IP families on restore when the destination serves
+// other families than the source service uses. It returns the modifier's
+// name, or "" when the service can be restored as it is.
+func ensureIPFamilyModifier(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient, serviceName string) (string, error) {
+	svc, err := sourceService(ctx, m, sourceCC, serviceName)
+	if err != nil || svc == nil {
+		return "", err
+	}
+	dest, err := destIPFamilies(ctx, destCC)
+	if err != nil {
+		return "", err
+	}
+	families, policy := migration.TranslateServiceIPFamilies(svc, dest)
+	if slices.Equal(families, svc.Spec.IPFamilies) && svc.Spec.IPFamilyPolicy != nil && policy == *svc.Spec.IPFamilyPolicy {
+		return "", nil
+	}
+
+	cm, err := velero.NewServiceIPFamilyModifier(m.Status.Velero.BackupName+"-ipfamilies", veleroNamespace(m.Spec.Velero),
+		serviceName, families, policy, veleroLabels(m))
+	if err != nil {
+		return "", err
+	}
+	if err := destCC.Client.Create(ctx, cm); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("failed to create resource modifier %s/%s: %w", cm.Namespace, cm.Name, err)
+	}
+	log.FromContext(ctx).Info("Translating headless service IP families on restore", "service", serviceName,
+		"ipFamilies", families, "ipFamilyPolicy", policy)
+	return cm.Name, nil
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/internal/velero"
+)
+
+func clusterWithFamily(clusterIP string, objects ...*corev1.Service) *multicluster.ClusterClient {
+	builder := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kubernetes"},
+		Spec:       corev1.ServiceSpec{ClusterIP: clusterIP, ClusterIPs: []string{clusterIP}},
+	})
+	for _, obj := range objects {
+		builder = builder.WithObjects(obj)
+	}
+	return &multicluster.ClusterClient{Client: builder.Build()}
+}
+
+func TestIPFamilies(t *testing.T) {
+	ctx := context.Background()
+	policy := corev1.IPFamilyPolicySingleStack
+	headless := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:      corev1.ClusterIPNone,
+			IPFamilies:     []corev1.IPFamily{corev1.IPv4Protocol},
+			IPFamilyPolicy: &policy,
+		},
+	}
+	source := clusterWithFamily("10.100.0.1", headless)
+	ipv4Dest := clusterWithFamily("10.100.0.1")
+	ipv6Dest := clusterWithFamily("fd00::1")
+
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", UID: "1234abcd-0000"},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			StatefulSetName: "web",
+			SourceNamespace: "prod",
+			DestNamespace:   "prod",
+			Velero:          &migrationv1alpha1.VeleroConfig{},
+		},
+		Status: migrationv1alpha1.StatefulSetMigrationStatus{
+			Velero: &migrationv1alpha1.VeleroStatus{BackupName: "web-1234abcd"},
+		},
+	}
+
+	if err := checkIPFamilies(ctx, m, source, ipv4Dest, "web"); err != nil {
+		t.Errorf("checkIPFamilies() into IPv4 error = %v", err)
+	}
+	if err := checkIPFamilies(ctx, m, source, ipv6Dest, "web"); err == nil {
+		t.Error("checkIPFamilies() into IPv6-only succeeded, want an error")
+	}
+	if err := checkIPFamilies(ctx, m, source, ipv6Dest, "missing"); err != nil {
+		t.Errorf("checkIPFamilies() without a source service error = %v", err)
+	}
+	m.Spec.Overrides = &migrationv1alpha1.OverridesConfig{IgnoreUnschedulablePods: true}
+	if err := checkIPFamilies(ctx, m, source, ipv6Dest, "web"); err == nil {
+		t.Error("checkIPFamilies() with another override succeeded, want an error")
+	}
+	m.Spec.Overrides.IgnoreIPFamilyMismatch = true
+	if err := checkIPFamilies(ctx, m, source, ipv6Dest, "web"); err != nil {
+		t.Errorf("checkIPFamilies() with ignoreIPFamilyMismatch error = %v", err)
+	}
+	m.Spec.Overrides = nil
+	m.Spec.Force = true
+	if err := checkIPFamilies(ctx, m, source, ipv6Dest, "web"); err != nil {
+		t.Errorf("checkIPFamilies() with force error = %v", err)
+	}
+
+	if name, err := ensureIPFamilyModifier(ctx, m, source, ipv4Dest, "web"); err != nil || name != "" {
+		t.Errorf("ensureIPFamilyModifier() into IPv4 = %q, %v, want no modifier", name, err)
+	}
+	name, err := ensureIPFamilyModifier(ctx, m, source, ipv6Dest, "web")
+	if err != nil {
+		t.Fatalf("ensureIPFamilyModifier() error = %v", err)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := ipv6Dest.Client.Get(ctx, types.NamespacedName{Namespace: velero.DefaultNamespace, Name: name}, cm); err != nil {
+		t.Fatalf("resource modifier %q: %v", name, err)
+	}
+	if cm.Labels[velero.MigrationLabel] != string(m.UID) {
+		t.Errorf("labels = %v, want the migration UID", cm.Labels)
+	}
+
+	// A retried restore reuses the modifier
+	if again, err := ensureIPFamilyModifier(ctx, m, source, ipv6Dest, "web"); err != nil || again != name {
+		t.Errorf("second ensureIPFamilyModifier() = %q, %v, want %q", again, err, name)
+	}
+}
@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+const (
+	// ConditionJobsSuspended reports that the source CronJobs and Jobs using
+	// the StatefulSet's PVCs have been suspended
+	ConditionJobsSuspended = "JobsSuspended"
+
+	// ConditionJobsRecreated reports whether the suspended CronJobs and Jobs
+	// were recreated in the destination
+	ConditionJobsRecreated = "JobsRecreated"
+
+	// jobSuspendedByAnnotation marks CronJobs and Jobs suspended by a
+	// migration, so a retried suspension does not mistake them for ones the
+	// user had suspended
+	jobSuspendedByAnnotation = "migration.aqua.io/suspended-by"
+)
+
+// suspendSourceJobs suspends the CronJobs and unfinished Jobs in the source
+// namespace that mount the StatefulSet's PVCs, so they neither hold a volume
+// the migration needs to detach nor run against a half-migrated StatefulSet.
+// It runs once per migration, before the source is changed in any other way.
+func (r *StatefulSetMigrationReconciler) suspendSourceJobs(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient) error {
+	if !m.Spec.MigrateJobs || meta.FindStatusCondition(m.Status.Conditions, ConditionJobsSuspended) != nil {
+		return nil
+	}
+	logger := log.FromContext(ctx)
+
+	sts := &appsv1.StatefulSet{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: m.Spec.StatefulSetName}, sts); err != nil {
+		return fmt.Errorf("failed to get source StatefulSet: %w", err)
+	}
+	claims := migration.StatefulSetClaimNames(sts)
+	owner := string(m.UID)
+
+	cronJobs := &batchv1.CronJobList{}
+	if err := cc.Client.List(ctx, cronJobs, client.InNamespace(m.Spec.SourceNamespace)); err != nil {
+		return fmt.Errorf("failed to list source CronJobs: %w", err)
+	}
+	jobs := &batchv1.JobList{}
+	if err := cc.Client.List(ctx, jobs, client.InNamespace(m.Spec.SourceNamespace)); err != nil {
+		return fmt.Errorf("failed to list source Jobs: %w", err)
+	}
+
+	var found []migrationv1alpha1.MigratedJobInfo
+	for i := range cronJobs.Items {
+		cronJob := &cronJobs.Items[i]
+		if !migration.UsesClaims(&cronJob.Spec.JobTemplate.Spec.Template.Spec, claims) {
+			continue
+		}
+		info := migrationv1alpha1.MigratedJobInfo{
+			Kind:         migrationv1alpha1.JobKindCronJob,
+			Name:         cronJob.Name,
+			WasSuspended: suspendedByUser(cronJob.Spec.Suspend, cronJob.Annotations, owner),
+		}
+		if !info.WasSuspended {
+			suspend := true
+			cronJob.Spec.Suspend = &suspend
+			metav1.SetMetaDataAnnotation(&cronJob.ObjectMeta, jobSuspendedByAnnotation, owner)
+			if err := cc.Client.Update(ctx, cronJob); err != nil {
+				return fmt.Errorf("failed to suspend CronJob %s: %w", cronJob.Name, err)
+			}
+		}
+		found = append(found, info)
+	}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if migration.JobFinished(job) || !migration.UsesClaims(&job.Spec.Template.Spec, claims) {
+			continue
+		}
+		info := migrationv1alpha1.MigratedJobInfo{
+			Kind:         migrationv1alpha1.JobKindJob,
+			Name:         job.Name,
+			CronJob:      migration.CronJobOwner(job),
+			WasSuspended: suspendedByUser(job.Spec.Suspend, job.Annotations, owner),
+		}
+		// Suspending a Job deletes its active pods, releasing the volume
+		if !info.WasSuspended {
+			suspend := true
+			job.Spec.Suspend = &suspend
+			metav1.SetMetaDataAnnotation(&job.ObjectMeta, jobSuspendedByAnnotation, owner)
+			if err := cc.Client.Update(ctx, job); err != nil {
+				return fmt.Errorf("failed to suspend Job %s: %w", job.Name, err)
+			}
+		}
+		found = append(found, info)
+	}
+
+	for _, info := range found {
+		message := "Suspended"
+		if info.WasSuspended {
+			message = "Already suspended"
+		}
+		r.recordHistory(m, StepSuspendJob, historyObject(string(info.Kind), m.Spec.SourceNamespace, info.Name),
+			migrationv1alpha1.HistoryResultSucceeded, message)
+	}
+	m.Status.Jobs = found
+	r.setCondition(m, ConditionJobsSuspended, metav1.ConditionTrue, "Suspended",
+		fmt.Sprintf("%d CronJobs and Jobs using the StatefulSet's PVCs suspended in the source", len(found)))
+	logger.Info("Suspended source jobs", "count", len(found))
+	return nil
+}
+
+// suspendedByUser reports whether a CronJob or Job was suspended before the migration touched it
+func suspendedByUser(suspend *bool, annotations map[string]string, owner string) bool {
+	return suspend != nil && *suspend && annotations[jobSuspendedByAnnotation] != owner
+}
+
+// recreateJobs recreates the suspended CronJobs and Jobs in the destination
+// namespace, where they mount the migrated PVCs of the same names. Jobs
+// created by a CronJob are not recreated; the migrated CronJob schedules new
+// ones. The pods have already moved, so failures are recorded in the history
+// and the JobsRecreated condition rather than failing the migration. The
+// source copies stay suspended.
+func (r *StatefulSetMigrationReconciler) recreateJobs(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient) {
+	logger := log.FromContext(ctx)
+
+	recreated, failed := 0, 0
+	for i := range m.Status.Jobs {
+		info := &m.Status.Jobs[i]
+		if info.Recreated || info.CronJob != "" {
+			continue
+		}
+		object := historyObject(string(info.Kind), m.Spec.DestNamespace, info.Name)
+		if err := r.recreateJob(ctx, m, sourceCC, destCC, *info); err != nil {
+			logger.Error(err, "Failed to recreate job in destination", "kind", info.Kind, "name", info.Name)
+			r.recordHistory(m, StepRecreateJob, object, migrationv1alpha1.HistoryResultFailed, err.Error())
+			failed++
+			continue
+		}
+		info.Recreated = true
+		recreated++
+		r.recordHistory(m, StepRecreateJob, object, migrationv1alpha1.HistoryResultSucceeded, "")
+	}
+
+	if recreated == 0 && failed == 0 {
+		return
+	}
+	if failed > 0 {
+		r.setCondition(m, ConditionJobsRecreated, metav1.ConditionFalse, "RecreateFailed",
+			fmt.Sprintf("%d CronJobs and Jobs could not be recreated in the destination; see status.history", failed))
+		return
+	}
+	r.setCondition(m, ConditionJobsRecreated, metav1.ConditionTrue, "Recreated",
+		fmt.Sprintf("%d CronJobs and Jobs recreated in the destination", recreated))
+}
+
+// recreateJob copies one suspended CronJob or Job to the destination. An
+// existing copy, e.g. one restored by Velero, gets the source's suspend state.
+func (r *StatefulSetMigrationReconciler) recreateJob(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient, info migrationv1alpha1.MigratedJobInfo) error {
+	key := types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: info.Name}
+	destKey := types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: info.Name}
+
+	var dest, existing client.Object
+	switch info.Kind {
+	case migrationv1alpha1.JobKindCronJob:
+		source := &batchv1.CronJob{}
+		if err := sourceCC.Client.Get(ctx, key, source); err != nil {
+			return fmt.Errorf("failed to get source CronJob %s: %w", key, err)
+		}
+		dest = migration.TranslateCronJob(source, m.Spec.DestNamespace, info.WasSuspended)
+		existing = &batchv1.CronJob{}
+	case migrationv1alpha1.JobKindJob:
+		source := &batchv1.Job{}
+		if err := sourceCC.Client.Get(ctx, key, source); err != nil {
+			return fmt.Errorf("failed to get source Job %s: %w", key, err)
+		}
+		dest = migration.TranslateJob(source, m.Spec.DestNamespace, info.WasSuspended)
+		existing = &batchv1.Job{}
+	default:
+		return fmt.Errorf("unsupported kind %q", info.Kind)
+	}
+	annotations := dest.GetAnnotations()
+	delete(annotations, jobSuspendedByAnnotation)
+	dest.SetAnnotations(annotations)
+
+	err := destCC.Client.Create(ctx, dest)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create %s %s: %w", info.Kind, destKey, err)
+	}
+
+	if err := destCC.Client.Get(ctx, destKey, existing); err != nil {
+		return fmt.Errorf("failed to get existing %s %s: %w", info.Kind, destKey, err)
+	}
+	annotations = existing.GetAnnotations()
+	delete(annotations, jobSuspendedByAnnotation)
+	existing.SetAnnotations(annotations)
+	suspend := info.WasSuspended
+	switch obj := existing.(type) {
+	case *batchv1.CronJob:
+		obj.Spec.Suspend = &suspend
+	case *batchv1.Job:
+		obj.Spec.Suspend = &suspend
+	}
+	if err := destCC.Client.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update existing %s %s: %w", info.Kind, destKey, err)
+	}
+	return nil
+}
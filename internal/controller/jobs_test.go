@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func jobPodSpec(claim string) corev1.PodSpec {
+	return corev1.PodSpec{Volumes: []corev1.Volume{{
+		Name:         "data",
+		VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim}},
+	}}}
+}
+
+func TestSuspendAndRecreateJobs(t *testing.T) {
+	ctx := context.Background()
+	suspended := true
+	replicas := int32(1)
+	controller := true
+
+	objects := []client.Object{
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:             &replicas,
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}},
+			},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "backup"},
+			Spec: batchv1.CronJobSpec{
+				Schedule:    "0 * * * *",
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: jobPodSpec("data-web-0")}}},
+			},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "paused"},
+			Spec: batchv1.CronJobSpec{
+				Schedule:    "0 * * * *",
+				Suspend:     &suspended,
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: jobPodSpec("data-web-0")}}},
+			},
+		},
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "unrelated"},
+			Spec: batchv1.CronJobSpec{
+				Schedule:    "0 * * * *",
+				JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: jobPodSpec("data-other-0")}}},
+			},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "prod",
+				Name:            "backup-123",
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup", UID: "cj", Controller: &controller}},
+			},
+			Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: jobPodSpec("data-web-0")}},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "done"},
+			Spec:       batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: jobPodSpec("data-web-0")}},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+			}},
+		},
+	}
+	source := &multicluster.ClusterClient{
+		Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objects...).Build(),
+	}
+	dest := &multicluster.ClusterClient{
+		Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build(),
+	}
+
+	r := &StatefulSetMigrationReconciler{}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{UID: "uid-1"},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			SourceNamespace: "prod",
+			StatefulSetName: "web",
+			DestNamespace:   "prod-new",
+			MigrateJobs:     true,
+		},
+	}
+
+	if err := r.suspendSourceJobs(ctx, m, source); err != nil {
+		t.Fatalf("suspendSourceJobs() error = %v", err)
+	}
+	want := []migrationv1alpha1.MigratedJobInfo{
+		{Kind: migrationv1alpha1.JobKindCronJob, Name: "backup"},
+		{Kind: migrationv1alpha1.JobKindCronJob, Name: "paused", WasSuspended: true},
+		{Kind: migrationv1alpha1.JobKindJob, Name: "backup-123", CronJob: "backup"},
+	}
+	if len(m.Status.Jobs) != len(want) {
+		t.Fatalf("Jobs = %+v, want %+v", m.Status.Jobs, want)
+	}
+	for i := range want {
+		if m.Status.Jobs[i] != want[i] {
+			t.Errorf("Jobs[%d] = %+v, want %+v", i, m.Status.Jobs[i], want[i])
+		}
+	}
+
+	cronJob := &batchv1.CronJob{}
+	if err := source.Client.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "backup"}, cronJob); err != nil {
+		t.Fatal(err)
+	}
+	if cronJob.Spec.Suspend == nil || !*cronJob.Spec.Suspend {
+		t.Error("source CronJob backup is not suspended")
+	}
+
+	// A retry after a lost status update must not treat its own suspension as the user's
+	m.Status.Jobs = nil
+	meta.RemoveStatusCondition(&m.Status.Conditions, ConditionJobsSuspended)
+	if err := r.suspendSourceJobs(ctx, m, source); err != nil {
+		t.Fatalf("suspendSourceJobs() retry error = %v", err)
+	}
+	if m.Status.Jobs[0].WasSuspended {
+		t.Error("retry recorded the migration's own suspension as the user's")
+	}
+
+	r.recreateJobs(ctx, m, source, dest)
+	if !m.Status.Jobs[0].Recreated || !m.Status.Jobs[1].Recreated || m.Status.Jobs[2].Recreated {
+		t.Errorf("Recreated = %v/%v/%v, want the CronJobs only", m.Status.Jobs[0].Recreated, m.Status.Jobs[1].Recreated, m.Status.Jobs[2].Recreated)
+	}
+	if !meta.IsStatusConditionTrue(m.Status.Conditions, ConditionJobsRecreated) {
+		t.Errorf("conditions = %+v, want JobsRecreated", m.Status.Conditions)
+	}
+
+	for name, wantSuspended := range map[string]bool{"backup": false, "paused": true} {
+		got := &batchv1.CronJob{}
+		if err := dest.Client.Get(ctx, types.NamespacedName{Namespace: "prod-new", Name: name}, got); err != nil {
+			t.Fatalf("destination CronJob %s: %v", name, err)
+		}
+		if got.Spec.Suspend == nil || *got.Spec.Suspend != wantSuspended {
+			t.Errorf("destination CronJob %s suspend = %v, want %v", name, got.Spec.Suspend, wantSuspended)
+		}
+		if _, ok := got.Annotations[jobSuspendedByAnnotation]; ok {
+			t.Errorf("destination CronJob %s keeps the suspended-by annotation", name)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+// checkVolumeKeys verifies that the destination AWS identity can use the KMS
+// key of every encrypted source volume, or the destination key volumes will
+// be re-encrypted with, so un-mountable volumes are caught before any pod moves
+func (r *StatefulSetMigrationReconciler) checkVolumeKeys(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, pvs []*corev1.PersistentVolume) error {
+	dest := m.Spec.DestAWS
+	grantee := aws.KeyGrantee{AccountID: dest.AccountID, RoleARN: dest.NodeRoleARN}
+	checked := make(map[string]bool)
+
+	for _, pv := range pvs {
+		volumeID, err := getVolumeIDFromPV(pv)
+		if err != nil {
+			return err
+		}
+
+		info, err := r.ebs(m).GetVolumeInfo(ctx, volumeID)
+		if err != nil {
+			return err
+		}
+		if !info.Encrypted || info.KMSKeyID == "" {
+			continue
+		}
+
+		// A snapshot copy re-encrypts with the destination key, so only that key must be usable
+		keyID := info.KMSKeyID
+		if dest.KMSKeyID != "" {
+			keyID = dest.KMSKeyID
+		}
+		if checked[keyID] {
+			continue
+		}
+		if err := r.ebs(m).CheckKeyAccess(ctx, keyID, grantee); err != nil {
+			return fmt.Errorf("volume %s: %w", volumeID, err)
+		}
+		checked[keyID] = true
+	}
+
+	return nil
+}
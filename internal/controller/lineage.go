@@ -0,0 +1,330 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+const (
+	// LabelSourceStatefulSet and LabelDestStatefulSet record the StatefulSets
+	// a migration moves from and to, as WorkloadLabelValue
+	LabelSourceStatefulSet = "migration.aqua.io/source-statefulset"
+	LabelDestStatefulSet   = "migration.aqua.io/dest-statefulset"
+
+	// VolumeLabelPrefix followed by an EBS volume ID labels the migrations
+	// that moved the volume
+	VolumeLabelPrefix = "volume.migration.aqua.io/"
+)
+
+// WorkloadLabelValue returns the label value identifying a StatefulSet,
+// "<namespace>.<name>". Namespaces have no dots, so the value is unambiguous;
+// one over 63 characters is shortened with a hash suffix.
+func WorkloadLabelValue(namespace, name string) string {
+	value := namespace + "." + name
+	if len(value) <= validation.LabelValueMaxLength {
+		return value
+	}
+	sum := sha256.Sum256([]byte(value))
+	return strings.TrimRight(value[:52], "-.") + "-" + hex.EncodeToString(sum[:])[:10]
+}
+
+// VolumeLabel returns the label key marking objects that moved a volume
+func VolumeLabel(volumeID string) string {
+	return VolumeLabelPrefix + volumeID
+}
+
+// lineageLabels returns the labels FindMigrations looks a migration up by:
+// its source and destination StatefulSets, and every volume it has moved so
+// far. A volume transferred to a copy is recorded under both IDs.
+func lineageLabels(m *migrationv1alpha1.StatefulSetMigration) map[string]string {
+	spec := &m.Spec
+	if m.Status.AppliedSpec != nil {
+		spec = m.Status.AppliedSpec
+	}
+	labels := workloadLabels(spec)
+	for _, p := range m.Status.MigratedPods {
+		for _, volumeID := range []string{p.VolumeID, p.SourceVolumeID} {
+			if volumeID != "" {
+				labels[VolumeLabel(volumeID)] = "true"
+			}
+		}
+	}
+	return labels
+}
+
+// workloadLabels returns the labels naming the StatefulSets a migration
+// moves from and to
+func workloadLabels(spec *migrationv1alpha1.StatefulSetMigrationSpec) map[string]string {
+	return map[string]string{
+		LabelSourceStatefulSet: WorkloadLabelValue(spec.SourceNamespace, spec.StatefulSetName),
+		LabelDestStatefulSet:   WorkloadLabelValue(spec.DestNamespace, spec.StatefulSetName),
+	}
+}
+
+// labelVolume patches the volume label onto a VolumeMigration once its
+// volume is known
+func (r *VolumeMigrationReconciler) labelVolume(ctx context.Context, vm *migrationv1alpha1.VolumeMigration) error {
+	if vm.Status.VolumeID == "" {
+		return nil
+	}
+	patch := client.MergeFrom(vm.DeepCopy())
+	if !mergeLabels(vm, map[string]string{VolumeLabel(vm.Status.VolumeID): "true"}) {
+		return nil
+	}
+	if err := r.Patch(ctx, vm, patch); err != nil {
+		return fmt.Errorf("failed to label volume migration: %w", err)
+	}
+	return nil
+}
+
+// mergeLabels adds labels to obj and reports whether any was missing or different
+func mergeLabels(obj metav1.Object, labels map[string]string) bool {
+	current := obj.GetLabels()
+	changed := false
+	for k, v := range labels {
+		if current[k] != v {
+			if current == nil {
+				current = map[string]string{}
+			}
+			current[k] = v
+			changed = true
+		}
+	}
+	obj.SetLabels(current)
+	return changed
+}
+
+// labelLineage patches the migration's lineage labels when they changed.
+// Only the labels are patched, so a spec edited since the migration started
+// is left as the user wrote it.
+func (r *StatefulSetMigrationReconciler) labelLineage(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) error {
+	patch := client.MergeFrom(m.DeepCopy())
+	if !mergeLabels(m, lineageLabels(m)) {
+		return nil
+	}
+	if err := r.Patch(ctx, m, patch); err != nil {
+		return fmt.Errorf("failed to label migration: %w", err)
+	}
+	return nil
+}
+
+// MigrationQuery selects migrations by what they touched. Exactly one of
+// StatefulSet and VolumeID is set.
+type MigrationQuery struct {
+	// Namespace and StatefulSet select migrations moving the StatefulSet
+	// from or to that namespace
+	Namespace   string
+	StatefulSet string
+
+	// VolumeID selects migrations that moved the EBS volume
+	VolumeID string
+}
+
+// MigrationRecord is a migration, current or past, returned by FindMigrations
+type MigrationRecord struct {
+	// Kind is StatefulSetMigration or VolumeMigration
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	UID       string `json:"uid"`
+
+	MigrationID string `json:"migrationId,omitempty"`
+	Phase       string `json:"phase"`
+
+	// SourceCluster and DestCluster are the kubeconfig Secret or API server
+	// of each side; Source and Dest are "<namespace>/<StatefulSet or PVC>"
+	SourceCluster string `json:"sourceCluster"`
+	Source        string `json:"source"`
+	DestCluster   string `json:"destCluster"`
+	Dest          string `json:"dest"`
+
+	// Volumes are the EBS volumes moved
+	Volumes []string `json:"volumes,omitempty"`
+
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Deleted is set for a migration that no longer exists and is known
+	// only from its report ConfigMap
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// FindMigrations returns the migrations in any namespace that touched a
+// StatefulSet or an EBS volume, oldest first. Deleted StatefulSetMigrations
+// are found through the report ConfigMaps they leave behind.
+func FindMigrations(ctx context.Context, c client.Reader, q MigrationQuery) ([]MigrationRecord, error) {
+	var selectors []client.MatchingLabels
+	switch {
+	case q.VolumeID != "" && q.StatefulSet == "":
+		selectors = []client.MatchingLabels{{VolumeLabel(q.VolumeID): "true"}}
+	case q.StatefulSet != "" && q.VolumeID == "":
+		workload := WorkloadLabelValue(q.Namespace, q.StatefulSet)
+		selectors = []client.MatchingLabels{{LabelSourceStatefulSet: workload}, {LabelDestStatefulSet: workload}}
+	default:
+		return nil, fmt.Errorf("exactly one of a StatefulSet and a volume ID is required")
+	}
+
+	records := map[string]MigrationRecord{}
+	for _, selector := range selectors {
+		migrations := &migrationv1alpha1.StatefulSetMigrationList{}
+		if err := c.List(ctx, migrations, selector); err != nil {
+			return nil, fmt.Errorf("failed to list migrations: %w", err)
+		}
+		for i := range migrations.Items {
+			rec := migrationRecord(&migrations.Items[i])
+			records[rec.UID] = rec
+		}
+
+		volumeMigrations := &migrationv1alpha1.VolumeMigrationList{}
+		if err := c.List(ctx, volumeMigrations, selector); err != nil {
+			return nil, fmt.Errorf("failed to list volume migrations: %w", err)
+		}
+		for i := range volumeMigrations.Items {
+			rec := volumeMigrationRecord(&volumeMigrations.Items[i])
+			records[rec.UID] = rec
+		}
+	}
+
+	// Reports are read last and only fill in migrations that are gone
+	for _, selector := range selectors {
+		reports := &corev1.ConfigMapList{}
+		selector[reportLabel] = "true"
+		if err := c.List(ctx, reports, selector); err != nil {
+			return nil, fmt.Errorf("failed to list migration reports: %w", err)
+		}
+		for i := range reports.Items {
+			rec, err := reportRecord(&reports.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := records[rec.UID]; !ok {
+				records[rec.UID] = rec
+			}
+		}
+	}
+
+	result := make([]MigrationRecord, 0, len(records))
+	for _, rec := range records {
+		result = append(result, rec)
+	}
+	slices.SortFunc(result, func(a, b MigrationRecord) int {
+		return cmp.Or(compareStart(a.StartTime, b.StartTime), cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+	return result, nil
+}
+
+// compareStart orders start times, with migrations that have not started last
+func compareStart(a, b *metav1.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return a.Time.Compare(b.Time)
+}
+
+// clusterName identifies a cluster reference by its API server, or by its
+// kubeconfig Secret and the context selected in it
+func clusterName(ref migrationv1alpha1.ContextRef) string {
+	if ref.Server != "" {
+		return ref.Server
+	}
+	return kubeConfigName(ref.KubeConfigSecret, ref.Context)
+}
+
+// kubeConfigName names a kubeconfig Secret, with "/<context>" when a context
+// other than its current one is selected
+func kubeConfigName(secret, context string) string {
+	if context == "" {
+		return secret
+	}
+	return secret + "/" + context
+}
+
+func migrationRecord(m *migrationv1alpha1.StatefulSetMigration) MigrationRecord {
+	spec := &m.Spec
+	if m.Status.AppliedSpec != nil {
+		spec = m.Status.AppliedSpec
+	}
+	rec := MigrationRecord{
+		Kind:           "StatefulSetMigration",
+		Namespace:      m.Namespace,
+		Name:           m.Name,
+		UID:            string(m.UID),
+		MigrationID:    spec.MigrationID,
+		Phase:          string(m.Status.Phase),
+		SourceCluster:  clusterName(spec.SourceCluster),
+		Source:         spec.SourceNamespace + "/" + spec.StatefulSetName,
+		DestCluster:    clusterName(spec.DestCluster),
+		Dest:           spec.DestNamespace + "/" + spec.StatefulSetName,
+		StartTime:      m.Status.StartTime,
+		CompletionTime: m.Status.CompletionTime,
+	}
+	for _, p := range m.Status.MigratedPods {
+		if p.VolumeID != "" {
+			rec.Volumes = append(rec.Volumes, p.VolumeID)
+		}
+	}
+	return rec
+}
+
+func volumeMigrationRecord(vm *migrationv1alpha1.VolumeMigration) MigrationRecord {
+	rec := MigrationRecord{
+		Kind:           "VolumeMigration",
+		Namespace:      vm.Namespace,
+		Name:           vm.Name,
+		UID:            string(vm.UID),
+		MigrationID:    vm.Labels[reportMigrationIDLabel],
+		Phase:          string(vm.Status.Phase),
+		SourceCluster:  clusterName(vm.Spec.SourceCluster),
+		Source:         vm.Spec.SourceNamespace + "/" + vm.Spec.PVCName,
+		DestCluster:    clusterName(vm.Spec.DestCluster),
+		Dest:           volumeDestNamespace(vm) + "/" + vm.Spec.PVCName,
+		StartTime:      vm.Status.StartTime,
+		CompletionTime: vm.Status.CompletionTime,
+	}
+	if vm.Status.VolumeID != "" {
+		rec.Volumes = []string{vm.Status.VolumeID}
+	}
+	return rec
+}
+
+func reportRecord(cm *corev1.ConfigMap) (MigrationRecord, error) {
+	var report Report
+	if err := json.Unmarshal([]byte(cm.Data[ReportJSONKey]), &report); err != nil {
+		return MigrationRecord{}, fmt.Errorf("failed to decode report %s/%s: %w", cm.Namespace, cm.Name, err)
+	}
+	namespace, name, _ := strings.Cut(report.Migration, "/")
+	return MigrationRecord{
+		Kind:           "StatefulSetMigration",
+		Namespace:      namespace,
+		Name:           name,
+		UID:            report.UID,
+		MigrationID:    report.MigrationID,
+		Phase:          string(report.Result),
+		SourceCluster:  cmp.Or(report.Source.Server, kubeConfigName(report.Source.KubeConfigSecret, report.Source.Context)),
+		Source:         report.Source.Namespace + "/" + report.Source.StatefulSet,
+		DestCluster:    cmp.Or(report.Destination.Server, kubeConfigName(report.Destination.KubeConfigSecret, report.Destination.Context)),
+		Dest:           report.Destination.Namespace + "/" + report.Destination.StatefulSet,
+		Volumes:        report.VolumesMoved,
+		StartTime:      report.StartTime,
+		CompletionTime: report.CompletionTime,
+		Deleted:        true,
+	}, nil
+}
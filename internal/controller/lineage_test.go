@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestWorkloadLabelValue(t *testing.T) {
+	if got := WorkloadLabelValue("prod", "web"); got != "prod.web" {
+		t.Errorf("WorkloadLabelValue() = %q, want prod.web", got)
+	}
+
+	long := WorkloadLabelValue("prod", strings.Repeat("a", 70))
+	if errs := validation.IsValidLabelValue(long); len(errs) != 0 {
+		t.Errorf("WorkloadLabelValue() = %q, not a label value: %v", long, errs)
+	}
+	if other := WorkloadLabelValue("prod", strings.Repeat("a", 71)); other == long {
+		t.Errorf("WorkloadLabelValue() = %q for two different names", long)
+	}
+}
+
+func TestFindMigrations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	at := func(hour int) *metav1.Time {
+		return &metav1.Time{Time: time.Date(2024, 1, 1, hour, 0, 0, 0, time.UTC)}
+	}
+	migration := func(name, statefulSet string, start *metav1.Time, volumes ...string) *migrationv1alpha1.StatefulSetMigration {
+		m := &migrationv1alpha1.StatefulSetMigration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: name, UID: types.UID("uid-" + name)},
+			Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+				SourceCluster:   migrationv1alpha1.ContextRef{KubeConfigSecret: "east"},
+				SourceNamespace: "prod",
+				StatefulSetName: statefulSet,
+				DestCluster:     migrationv1alpha1.ContextRef{KubeConfigSecret: "west"},
+				DestNamespace:   "prod",
+			},
+			Status: migrationv1alpha1.StatefulSetMigrationStatus{Phase: migrationv1alpha1.PhaseCompleted, StartTime: start},
+		}
+		for i, v := range volumes {
+			m.Status.MigratedPods = append(m.Status.MigratedPods, migrationv1alpha1.MigratedPodInfo{Index: i, VolumeID: v})
+		}
+		return m
+	}
+
+	current := migration("web", "web", at(10), "vol-0")
+	other := migration("api", "api", at(9), "vol-1")
+	child := &migrationv1alpha1.VolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web-data-web-0", UID: "uid-child", Labels: workloadLabels(&current.Spec)},
+		Spec:       migrationv1alpha1.VolumeMigrationSpec{SourceNamespace: "prod", PVCName: "data-web-0"},
+		Status:     migrationv1alpha1.VolumeMigrationStatus{VolumeID: "vol-0", StartTime: at(11)},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(current, other, child).WithStatusSubresource(current, other, child).Build()
+	r := &StatefulSetMigrationReconciler{Client: c, Scheme: scheme}
+	vr := &VolumeMigrationReconciler{Client: c, Scheme: scheme}
+	for _, m := range []*migrationv1alpha1.StatefulSetMigration{current, other} {
+		if err := r.labelLineage(ctx, m); err != nil {
+			t.Fatalf("labelLineage(%s): %v", m.Name, err)
+		}
+	}
+	if err := vr.labelVolume(ctx, child); err != nil {
+		t.Fatalf("labelVolume(): %v", err)
+	}
+
+	// The earlier migration of web is deleted and known only from its report;
+	// the current one has a report too, which its live object supersedes
+	for _, m := range []*migrationv1alpha1.StatefulSetMigration{migration("web-old", "web", at(8), "vol-0"), current} {
+		data, err := json.Marshal(buildReport(m, at(12).Time))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := r.writeReportConfigMap(ctx, m, map[string]string{ReportJSONKey: string(data)}); err != nil {
+			t.Fatalf("writeReportConfigMap(%s): %v", m.Name, err)
+		}
+	}
+
+	names := func(records []MigrationRecord) string {
+		var s []string
+		for _, rec := range records {
+			name := rec.Name
+			if rec.Deleted {
+				name += " (deleted)"
+			}
+			s = append(s, name)
+		}
+		return strings.Join(s, ", ")
+	}
+
+	tests := []struct {
+		name  string
+		query MigrationQuery
+		want  string
+	}{
+		{"statefulset", MigrationQuery{Namespace: "prod", StatefulSet: "web"}, "web-old (deleted), web, web-data-web-0"},
+		{"volume", MigrationQuery{VolumeID: "vol-0"}, "web-old (deleted), web, web-data-web-0"},
+		{"other volume", MigrationQuery{VolumeID: "vol-1"}, "api"},
+		{"unknown statefulset", MigrationQuery{Namespace: "staging", StatefulSet: "web"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := FindMigrations(ctx, c, tt.query)
+			if err != nil {
+				t.Fatalf("FindMigrations() error = %v", err)
+			}
+			if got := names(records); got != tt.want {
+				t.Errorf("FindMigrations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	records, err := FindMigrations(ctx, c, MigrationQuery{VolumeID: "vol-0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old := records[0]; old.SourceCluster != "east" || old.DestCluster != "west" || old.Source != "prod/web" {
+		t.Errorf("deleted migration record = %+v, want prod/web moved from east to west", old)
+	}
+
+	if _, err := FindMigrations(ctx, c, MigrationQuery{}); err == nil {
+		t.Error("FindMigrations() with an empty query succeeded, want an error")
+	}
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// DefaultVolumeModificationTimeout is how long a pod's deletion waits for an
+// in-flight ModifyVolume on its volume to finish
+const DefaultVolumeModificationTimeout = 30 * time.Minute
+
+// checkVolumeModifications fails when a source volume is being modified.
+// Detaching or snapshotting a volume in the modifying state tends to fail
+// partway, so the migration should start once the volumes are optimizing or
+// done.
+func (r *StatefulSetMigrationReconciler) checkVolumeModifications(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, pvs []*corev1.PersistentVolume) error {
+	var modifying []string
+	for _, pv := range pvs {
+		volumeID, err := getVolumeIDFromPV(pv)
+		if err != nil {
+			return err
+		}
+		mod, err := r.ebs(m).GetVolumeModification(ctx, volumeID)
+		if err != nil {
+			return err
+		}
+		if mod.InProgress() {
+			modifying = append(modifying, fmt.Sprintf("%s (%d%% done)", volumeID, mod.Progress))
+		}
+	}
+
+	if len(modifying) > 0 {
+		return fmt.Errorf("volumes are being modified: %s; retry once they are optimizing or completed", strings.Join(modifying, ", "))
+	}
+	return nil
+}
+
+// replicaVolumeID returns the EBS volume of a replica's source PVC
+func replicaVolumeID(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient, index int) (string, error) {
+	pvcName := translate.GetPVCNameForStatefulSetPod("data", m.Spec.StatefulSetName, index)
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: pvcName}, pvc); err != nil {
+		return "", fmt.Errorf("failed to get source PVC %s: %w", pvcName, err)
+	}
+	pv := &corev1.PersistentVolume{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+		return "", fmt.Errorf("failed to get source PV: %w", err)
+	}
+	volumeID, err := getVolumeIDFromPV(pv)
+	if err != nil {
+		return "", fmt.Errorf("failed to get volume ID: %w", err)
+	}
+	return volumeID, nil
+}
+
+// waitForVolumeModification waits for an in-flight ModifyVolume on a
+// replica's volume to finish before its pod is deleted and the volume
+// detaches. It catches modifications started after pre-flight, such as a
+// resize of a replica that has not been migrated yet.
+func (r *StatefulSetMigrationReconciler) waitForVolumeModification(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, volumeID string) error {
+	mod, err := r.ebs(m).GetVolumeModification(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+	if !mod.InProgress() {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Waiting for volume modification", "volumeId", volumeID, "progress", mod.Progress)
+	r.recordHistory(m, StepVolumeModify, volumeID, migrationv1alpha1.HistoryResultStarted,
+		fmt.Sprintf("Modification started at %s is %d%% done", mod.StartTime.UTC().Format(time.RFC3339), mod.Progress))
+
+	defer metrics.TrackWait(StepVolumeModify)()
+	r.beginVolumeWait(ctx, m, volumeID, StepVolumeModify, migrationv1alpha1.VolumeWaitSourceAWS, modificationState(mod))
+	defer endVolumeWait(m, volumeID)
+	if err := r.ebs(m).WaitForVolumeModification(ctx, volumeID, aws.WaitForVolumeModificationConfig{
+		Timeout: DefaultVolumeModificationTimeout,
+		OnPoll: func(mod *aws.VolumeModification) {
+			r.probeVolumeWait(ctx, m, volumeID, modificationState(mod))
+		},
+	}); err != nil {
+		return fmt.Errorf("volume modification wait failed: %w", err)
+	}
+	r.recordHistory(m, StepVolumeModify, volumeID, migrationv1alpha1.HistoryResultSucceeded, "")
+	return nil
+}
+
+// modificationState describes a modification for status.volumeWaits
+func modificationState(mod *aws.VolumeModification) string {
+	return fmt.Sprintf("modification %s, %d%% done", mod.State, mod.Progress)
+}
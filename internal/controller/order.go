@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// minOrdinalsVersion is the first Kubernetes version that enables
+// StatefulSet spec.ordinals by default
+var minOrdinalsVersion = version.MustParseGeneric("1.27")
+
+// ordinalAt returns the ordinal of the pod at position in the migration order
+func ordinalAt(m *migrationv1alpha1.StatefulSetMigration, position int) int {
+	if position < len(m.Status.PodOrder) {
+		return m.Status.PodOrder[position]
+	}
+	return position
+}
+
+// migratingOrdinals returns the ordinals of the pods the migration moves,
+// in the migration order
+func migratingOrdinals(m *migrationv1alpha1.StatefulSetMigration) []int {
+	ordinals := make([]int, m.Status.TotalReplicas)
+	for position := range ordinals {
+		ordinals[position] = ordinalAt(m, position)
+	}
+	return ordinals
+}
+
+// frozenSourceOrdinals returns every ordinal the source StatefulSet ran
+// when it was frozen: the ones the migration moves and those
+// spec.ordinalRange leaves in the source, in ascending order
+func frozenSourceOrdinals(m *migrationv1alpha1.StatefulSetMigration) []int {
+	ordinals := append(migratingOrdinals(m), keptSourceOrdinals(m)...)
+	slices.Sort(ordinals)
+	return ordinals
+}
+
+// keptSourceOrdinals returns the ordinals spec.ordinalRange leaves running
+// in the source, from status.sourceOrdinals
+func keptSourceOrdinals(m *migrationv1alpha1.StatefulSetMigration) []int {
+	kept, err := migration.ParseOrdinals(m.Status.SourceOrdinals)
+	if err != nil {
+		return nil
+	}
+	return kept.List()
+}
+
+// priorDestOrdinals returns the ordinals an earlier tranche left running in
+// the destination, from status.priorDestOrdinals
+func priorDestOrdinals(m *migrationv1alpha1.StatefulSetMigration) migration.Ordinals {
+	prior, err := migration.ParseOrdinals(m.Status.PriorDestOrdinals)
+	if err != nil {
+		return migration.Ordinals{}
+	}
+	return prior
+}
+
+// destReplicas returns the replicas of a destination StatefulSet running
+// the first moved pods of the migration order, and any an earlier tranche moved
+func destReplicas(m *migrationv1alpha1.StatefulSetMigration, moved int) int32 {
+	return int32(priorDestOrdinals(m).Count + moved)
+}
+
+// destOrdinals returns the spec.ordinals of a destination StatefulSet
+// running the first moved pods of the migration order, or nil when they
+// start at ordinal 0
+func destOrdinals(m *migrationv1alpha1.StatefulSetMigration, moved int) *appsv1.StatefulSetOrdinals {
+	if len(m.Status.PodOrder) == 0 {
+		return nil
+	}
+	start, _ := migration.OrdinalRange(m.Status.PodOrder, moved)
+	if prior := priorDestOrdinals(m); prior.Count > 0 && (moved == 0 || int(start) > prior.Start) {
+		start = int32(prior.Start)
+	}
+	if start == 0 {
+		return nil
+	}
+	return &appsv1.StatefulSetOrdinals{Start: start}
+}
+
+// podOrder returns the migration order spec.podOrder gives the source pods,
+// or nil when it is unset. An order that does not start at ordinal 0 needs a
+// destination cluster that supports StatefulSet spec.ordinals.
+func podOrder(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient) ([]int, error) {
+	if m.Spec.PodOrder == nil {
+		return nil, nil
+	}
+	var pods []corev1.Pod
+	for i := range m.Status.TotalReplicas {
+		pod := corev1.Pod{}
+		key := types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, i)}
+		found, err := getIfExists(ctx, sourceCC, key, &pod)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			pods = append(pods, pod)
+		}
+	}
+
+	priorities, err := migration.PodPriorities(pods, m.Spec.StatefulSetName, m.Status.TotalReplicas,
+		m.Spec.PodOrder.PriorityLabel, m.Spec.PodOrder.PriorityAnnotation)
+	if err != nil {
+		return nil, err
+	}
+	order, err := migration.MigrationOrder(priorities)
+	if err != nil || len(order) == 0 || order[0] == 0 {
+		return order, err
+	}
+	if err := checkOrdinalsSupported(destCC, "destination", fmt.Sprintf("the first pod to move is ordinal %d", order[0])); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// checkOrdinalsSupported fails when a cluster's Kubernetes version does not
+// enable StatefulSet spec.ordinals; why says what needs them
+func checkOrdinalsSupported(cc *multicluster.ClusterClient, cluster, why string) error {
+	info, err := cc.Clientset.Discovery().ServerVersion()
+	if err != nil {
+		return fmt.Errorf("failed to get %s server version: %w", cluster, err)
+	}
+	v, err := version.ParseGeneric(info.GitVersion)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s server version %q: %w", cluster, info.GitVersion, err)
+	}
+	if v.LessThan(minOrdinalsVersion) {
+		return fmt.Errorf("%s, which needs StatefulSet spec.ordinals, enabled from Kubernetes %s; the %s runs %s",
+			why, minOrdinalsVersion, cluster, info.GitVersion)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiversion "k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestPodOrder(t *testing.T) {
+	pod := func(name, priority string) client.Object {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: name, Labels: map[string]string{"priority": priority}}}
+	}
+
+	tests := []struct {
+		name        string
+		pods        []client.Object
+		destVersion string
+		want        []int
+		wantErr     string
+	}{
+		{name: "ordinal order", pods: []client.Object{pod("web-0", "0"), pod("web-1", "0"), pod("web-2", "1")}, want: []int{0, 1, 2}},
+		{name: "leader on ordinal 0", pods: []client.Object{pod("web-0", "1"), pod("web-1", "0")}, destVersion: "v1.30.2-eks-1", want: []int{1, 2, 0}},
+		{name: "destination without ordinals", pods: []client.Object{pod("web-0", "1")}, destVersion: "v1.26.9", wantErr: "needs StatefulSet spec.ordinals"},
+		{name: "leader in the middle", pods: []client.Object{pod("web-1", "1")}, wantErr: "not a contiguous range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{
+				Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+					StatefulSetName: "web",
+					SourceNamespace: "prod",
+					PodOrder:        &migrationv1alpha1.PodOrderConfig{PriorityLabel: "priority"},
+				},
+				Status: migrationv1alpha1.StatefulSetMigrationStatus{TotalReplicas: 3},
+			}
+			sourceCC := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.pods...).Build()}
+			clientset := kubefake.NewClientset()
+			clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &apiversion.Info{GitVersion: tt.destVersion}
+			destCC := &multicluster.ClusterClient{Clientset: clientset}
+
+			got, err := podOrder(context.Background(), m, sourceCC, destCC)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("podOrder() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("podOrder() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("podOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDestOrdinals(t *testing.T) {
+	m := &migrationv1alpha1.StatefulSetMigration{Status: migrationv1alpha1.StatefulSetMigrationStatus{PodOrder: []int{1, 2, 0}}}
+	if got := destOrdinals(m, 2); got == nil || got.Start != 1 {
+		t.Errorf("destOrdinals(2) = %+v, want start 1", got)
+	}
+	if got := destOrdinals(m, 3); got != nil {
+		t.Errorf("destOrdinals(3) = %+v, want nil once ordinal 0 has moved", got)
+	}
+	if got := ordinalAt(m, 0); got != 1 {
+		t.Errorf("ordinalAt(0) = %d, want 1", got)
+	}
+	if got := ordinalAt(&migrationv1alpha1.StatefulSetMigration{}, 2); got != 2 {
+		t.Errorf("ordinalAt(2) without an order = %d, want 2", got)
+	}
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// ConditionOrphanedPods reports source pods a failed migration left running
+// without their StatefulSet
+const ConditionOrphanedPods = "OrphanedPods"
+
+// recordOrphanedPods lists the source pods that exist before the StatefulSet
+// is orphaned, so they can be found again if the migration stops part way
+func recordOrphanedPods(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient) error {
+	var pods []string
+	for _, i := range frozenSourceOrdinals(m) {
+		podName := fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, i)
+		if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: podName}, &corev1.Pod{}); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get source pod %s: %w", podName, err)
+		}
+		pods = append(pods, podName)
+	}
+	m.Status.OrphanedPods = pods
+	return nil
+}
+
+// forgetOrphanedPod drops a source pod that no longer exists from status.orphanedPods
+func forgetOrphanedPod(m *migrationv1alpha1.StatefulSetMigration, podName string) {
+	m.Status.OrphanedPods = slices.DeleteFunc(m.Status.OrphanedPods, func(name string) bool {
+		return name == podName
+	})
+	if len(m.Status.OrphanedPods) == 0 {
+		m.Status.OrphanedPods = nil
+	}
+}
+
+// setOrphanedPodsCondition flags the source pods a failed migration leaves
+// running without a StatefulSet
+func (r *StatefulSetMigrationReconciler) setOrphanedPodsCondition(m *migrationv1alpha1.StatefulSetMigration) {
+	if len(m.Status.OrphanedPods) == 0 {
+		return
+	}
+	r.setCondition(m, ConditionOrphanedPods, metav1.ConditionTrue, "SourcePodsUnmanaged",
+		fmt.Sprintf("Source pods %s run without a StatefulSet; recreate the source StatefulSet to re-adopt them, or delete the migration with spec.orphanedPodPolicy Delete",
+			strings.Join(m.Status.OrphanedPods, ", ")))
+}
+
+// releaseOrphanedPods applies spec.orphanedPodPolicy to the source pods a
+// migration that did not complete leaves behind when it is deleted. A
+// completed migration's leftover pods were kept on purpose by spec.cleanup.
+func (r *StatefulSetMigrationReconciler) releaseOrphanedPods(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) error {
+	if len(m.Status.OrphanedPods) == 0 ||
+		m.Status.Phase == migrationv1alpha1.PhaseCompleted || m.Status.Phase == migrationv1alpha1.PhaseDegraded {
+		return nil
+	}
+	logger := log.FromContext(ctx)
+
+	if m.Spec.OrphanedPodPolicy != migrationv1alpha1.OrphanedPodPolicyDelete {
+		logger.Info("Leaving orphaned source pods running; recreate the source StatefulSet to re-adopt them",
+			"pods", m.Status.OrphanedPods)
+		return nil
+	}
+
+	sourceClient, err := r.getSourceClient(ctx, m)
+	if err != nil {
+		return fmt.Errorf("failed to get source client: %w", err)
+	}
+	if err := deleteOrphanedPods(ctx, m, sourceClient); err != nil {
+		return err
+	}
+	logger.Info("Deleted orphaned source pods")
+	return nil
+}
+
+// deleteOrphanedPods deletes the source pods in status.orphanedPods
+func deleteOrphanedPods(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient) error {
+	for _, podName := range slices.Clone(m.Status.OrphanedPods) {
+		if err := deleteIfExists(ctx, cc, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: podName}, &corev1.Pod{}); err != nil {
+			return fmt.Errorf("failed to delete orphaned source pod %s: %w", podName, err)
+		}
+		forgetOrphanedPod(m, podName)
+	}
+	return nil
+}
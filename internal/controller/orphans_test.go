@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestOrphanedPods(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web-0"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web-2"}},
+	).Build()
+	cc := &multicluster.ClusterClient{Client: c}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		Spec:   migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web", SourceNamespace: "prod"},
+		Status: migrationv1alpha1.StatefulSetMigrationStatus{TotalReplicas: 3},
+	}
+
+	if err := recordOrphanedPods(ctx, m, cc); err != nil {
+		t.Fatalf("recordOrphanedPods() error = %v", err)
+	}
+	if want := []string{"web-0", "web-2"}; !slices.Equal(m.Status.OrphanedPods, want) {
+		t.Fatalf("OrphanedPods = %v, want %v", m.Status.OrphanedPods, want)
+	}
+
+	forgetOrphanedPod(m, "web-0")
+	if want := []string{"web-2"}; !slices.Equal(m.Status.OrphanedPods, want) {
+		t.Fatalf("OrphanedPods after migrating web-0 = %v, want %v", m.Status.OrphanedPods, want)
+	}
+
+	r := &StatefulSetMigrationReconciler{}
+	r.setOrphanedPodsCondition(m)
+	if len(m.Status.Conditions) != 1 || !strings.Contains(m.Status.Conditions[0].Message, "web-2") {
+		t.Errorf("conditions = %+v, want an OrphanedPods condition naming web-2", m.Status.Conditions)
+	}
+
+	if err := deleteOrphanedPods(ctx, m, cc); err != nil {
+		t.Fatalf("deleteOrphanedPods() error = %v", err)
+	}
+	if m.Status.OrphanedPods != nil {
+		t.Errorf("OrphanedPods after deletion = %v, want none", m.Status.OrphanedPods)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "web-2"}, &corev1.Pod{}); !apierrors.IsNotFound(err) {
+		t.Errorf("web-2 still exists, error = %v", err)
+	}
+}
+
+func TestReleaseOrphanedPodsKeepsPods(t *testing.T) {
+	tests := []struct {
+		name   string
+		phase  migrationv1alpha1.MigrationPhase
+		policy migrationv1alpha1.OrphanedPodPolicy
+	}{
+		{name: "failed, retained", phase: migrationv1alpha1.PhaseFailed, policy: migrationv1alpha1.OrphanedPodPolicyRetain},
+		{name: "completed, cleanup kept pods", phase: migrationv1alpha1.PhaseCompleted, policy: migrationv1alpha1.OrphanedPodPolicyDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No ClientManager: neither case may reach the source cluster
+			r := &StatefulSetMigrationReconciler{}
+			m := &migrationv1alpha1.StatefulSetMigration{
+				Spec:   migrationv1alpha1.StatefulSetMigrationSpec{OrphanedPodPolicy: tt.policy},
+				Status: migrationv1alpha1.StatefulSetMigrationStatus{Phase: tt.phase, OrphanedPods: []string{"web-1"}},
+			}
+			if err := r.releaseOrphanedPods(context.Background(), m); err != nil {
+				t.Fatalf("releaseOrphanedPods() error = %v", err)
+			}
+			if len(m.Status.OrphanedPods) != 1 {
+				t.Errorf("OrphanedPods = %v, want web-1 kept", m.Status.OrphanedPods)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// maxParallelPods returns how many pods move at once
+func maxParallelPods(m *migrationv1alpha1.StatefulSetMigration) int {
+	if m.Spec.MaxParallelPods < 1 {
+		return 1
+	}
+	return int(m.Spec.MaxParallelPods)
+}
+
+// checkParallelPods fails pre-flight when spec.maxParallelPods would stop
+// several pods of an OrderedReady StatefulSet at once. Its pods expect to
+// stop and start one at a time, and the destination StatefulSet, which
+// keeps the policy, would start the moved pods one at a time anyway.
+func checkParallelPods(m *migrationv1alpha1.StatefulSetMigration, sts *appsv1.StatefulSet) error {
+	if maxParallelPods(m) == 1 || sts.Spec.PodManagementPolicy == appsv1.ParallelPodManagement {
+		return nil
+	}
+	return fmt.Errorf("maxParallelPods %d requires podManagementPolicy Parallel; the StatefulSet is %s, so its pods move one at a time",
+		m.Spec.MaxParallelPods, appsv1.OrderedReadyPodManagement)
+}
+
+// nextPositions returns the positions in the migration order that move
+// together next: up to spec.maxParallelPods from status.currentIndex
+func nextPositions(m *migrationv1alpha1.StatefulSetMigration) []int {
+	end := min(m.Status.CurrentIndex+maxParallelPods(m), m.Status.TotalReplicas)
+	var positions []int
+	for position := m.Status.CurrentIndex; position < end; position++ {
+		positions = append(positions, position)
+	}
+	return positions
+}
+
+// podMove carries one pod through the steps of migratePods
+type podMove struct {
+	index         int
+	podName       string
+	claimTemplate string
+	pvcName       string
+
+	// done is set when the pod needs nothing more: an earlier attempt
+	// moved it, or it failed and was skipped
+	done bool
+
+	// deleted is set once the source pod's deletion was requested
+	deleted bool
+
+	// provision is set when the source PVC has no PV, so the destination
+	// provisions a new volume and there is none to move
+	provision bool
+
+	volumeID         string
+	destVolumeID     string
+	sourceInstanceID string
+	stoppedAt        *metav1.Time
+
+	sourcePVC  *corev1.PersistentVolumeClaim
+	sourcePV   *corev1.PersistentVolume
+	result     *translate.TranslationResult
+	existingPV *corev1.PersistentVolume
+}
+
+// migratePods migrates the pods at positions in the migration order from
+// source to destination. All of them are stopped first and their volumes
+// moved, so their detaches overlap; then the destination StatefulSet is
+// scaled once to include them all and each is waited on to become Ready.
+// A single position is the one-pod-at-a-time migration.
+//
+// Under spec.failurePolicy ContinueRemaining a pod that fails is recorded
+// in status.failedPods and the others carry on: its ordinal is still
+// included in the destination StatefulSet, with a placeholder PVC when its
+// volume never arrived. Destination conflicts, throttling, volumes that
+// fail spec.volumeInspection and pods that fail spec.connectivityProbe stop
+// the batch either way: the pod would otherwise start on a volume the
+// inspection found wrong, and the next pods would be cut off the same way.
+func (r *StatefulSetMigrationReconciler) migratePods(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, positions []int) error {
+	sourceClient, err := r.getSourceClient(ctx, m)
+	if err != nil {
+		return fmt.Errorf("failed to get source client: %w", err)
+	}
+	destClient, err := r.getDestClient(ctx, m)
+	if err != nil {
+		return fmt.Errorf("failed to get destination client: %w", err)
+	}
+	if err := r.acquireCaches(ctx, m, sourceClient, destClient); err != nil {
+		return err
+	}
+
+	moves := make([]*podMove, len(positions))
+	for i, position := range positions {
+		index := ordinalAt(m, position)
+		moves[i] = &podMove{
+			index:   index,
+			podName: fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, index),
+			// For now, assume a single volume claim template named "data"
+			// TODO: Support multiple volume claim templates
+			claimTemplate: "data",
+			pvcName:       translate.GetPVCNameForStatefulSetPod("data", m.Spec.StatefulSetName, index),
+			// A pod recorded by an attempt that stopped later in the batch
+			// is not moved or recorded again
+			done: podMigrated(m, index),
+		}
+	}
+
+	// Pods that fail under ContinueRemaining join status.failedPods only
+	// after the scale below, whose check allows for earlier failures alone
+	var failures []podFailure
+	step := func(mv *podMove, fn func(*podMove) error) error {
+		if mv.done {
+			return nil
+		}
+		err := fn(mv)
+		if err == nil {
+			return nil
+		}
+		if len(moves) > 1 {
+			err = fmt.Errorf("%s: %w", mv.podName, err)
+		}
+		var conflict *DestinationConflictError
+		var inspection *VolumeInspectionError
+		var probe *ConnectivityProbeError
+		var unschedulable *UnschedulableError
+		if !continueRemaining(m) || aws.Retryable(err) || errors.As(err, &conflict) || errors.As(err, &inspection) ||
+			errors.As(err, &probe) || (m.Spec.CapacityWait != nil && errors.As(err, &unschedulable)) {
+			return err
+		}
+		log.FromContext(ctx).Error(err, "Pod failed to migrate, continuing with the remaining pods", "index", mv.index)
+		mv.done = true
+		failures = append(failures, podFailure{move: mv, cause: err})
+		return nil
+	}
+
+	for _, mv := range moves {
+		if err := step(mv, func(mv *podMove) error { return r.stopSourcePod(ctx, m, sourceClient, destClient, mv) }); err != nil {
+			return err
+		}
+	}
+	for _, mv := range moves {
+		if err := step(mv, func(mv *podMove) error { return r.moveVolume(ctx, m, sourceClient, destClient, mv) }); err != nil {
+			return err
+		}
+	}
+	for _, mv := range moves {
+		if err := step(mv, func(mv *podMove) error { return r.inspectVolume(ctx, m, destClient, mv) }); err != nil {
+			return err
+		}
+	}
+
+	// The ordinals of failed pods need a claim before the StatefulSet
+	// includes them, or it provisions an empty volume from the template
+	for _, f := range failures {
+		if err := reserveFailedClaim(ctx, m, destClient, f.move.index); err != nil {
+			return fmt.Errorf("%v; failed to skip it: %w", f.cause, err)
+		}
+	}
+	// In VolumesOnly mode the user creates the StatefulSet once every PVC is bound
+	if !volumesOnly(m) && (slices.ContainsFunc(moves, func(mv *podMove) bool { return !mv.done }) || len(failures) > 0) {
+		if err := r.includeInDestination(ctx, m, sourceClient, destClient, positions); err != nil {
+			return err
+		}
+	}
+
+	for _, mv := range moves {
+		if err := step(mv, func(mv *podMove) error { return r.finishPod(ctx, m, destClient, mv) }); err != nil {
+			return err
+		}
+	}
+	for _, f := range failures {
+		r.recordFailedPod(m, f.move.index, f.cause)
+	}
+	return nil
+}
+
+// podFailure is a pod of the batch that failed under ContinueRemaining
+type podFailure struct {
+	move  *podMove
+	cause error
+}
+
+// podMigrated reports whether the pod at index is in status.migratedPods
+func podMigrated(m *migrationv1alpha1.StatefulSetMigration, index int) bool {
+	return slices.ContainsFunc(m.Status.MigratedPods, func(p migrationv1alpha1.MigratedPodInfo) bool { return p.Index == index })
+}
+
+// includeInDestination creates the destination StatefulSet with the first
+// batch of pods, or scales it to include the batch at positions
+func (r *StatefulSetMigrationReconciler) includeInDestination(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient, positions []int) error {
+	logger := log.FromContext(ctx)
+	replicas := destReplicas(m, positions[len(positions)-1]+1)
+	object := historyObject("StatefulSet", m.Spec.DestNamespace, m.Spec.StatefulSetName)
+
+	// The StatefulSet an earlier tranche created is scaled from the pods it runs
+	if positions[0] == 0 && m.Status.PriorDestOrdinals == "" {
+		// First pods - create the StatefulSet
+		logger.Info("Creating StatefulSet in destination", "replicas", replicas)
+		if err := r.createDestinationStatefulSet(ctx, sourceCC, destCC, m, replicas); err != nil {
+			return fmt.Errorf("failed to create destination StatefulSet: %w", err)
+		}
+		r.recordHistory(m, StepCreateSTS, object, migrationv1alpha1.HistoryResultSucceeded, "")
+		return nil
+	}
+
+	// Subsequent pods - scale up the StatefulSet
+	logger.Info("Scaling StatefulSet in destination", "replicas", replicas)
+	if err := r.scaleDestinationStatefulSet(ctx, destCC, m, destReplicas(m, positions[0]), replicas); err != nil {
+		return fmt.Errorf("failed to scale destination StatefulSet: %w", err)
+	}
+	r.recordHistory(m, StepScaleSTS, object, migrationv1alpha1.HistoryResultSucceeded, fmt.Sprintf("Scaled to %d replicas", replicas))
+	return nil
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"slices"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestCheckParallelPods(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     int32
+		pods    appsv1.PodManagementPolicyType
+		wantErr bool
+	}{
+		{name: "default", pods: appsv1.OrderedReadyPodManagement},
+		{name: "one at a time", max: 1, pods: appsv1.OrderedReadyPodManagement},
+		{name: "parallel pods", max: 3, pods: appsv1.ParallelPodManagement},
+		{name: "ordered pods", max: 3, pods: appsv1.OrderedReadyPodManagement, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{MaxParallelPods: tt.max}}
+			sts := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{PodManagementPolicy: tt.pods}}
+			if err := checkParallelPods(m, sts); (err != nil) != tt.wantErr {
+				t.Errorf("checkParallelPods() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNextPositions(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     int32
+		current int
+		want    []int
+	}{
+		{name: "one at a time", current: 2, want: []int{2}},
+		{name: "full batch", max: 3, current: 0, want: []int{0, 1, 2}},
+		{name: "last batch is short", max: 3, current: 3, want: []int{3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{
+				Spec:   migrationv1alpha1.StatefulSetMigrationSpec{MaxParallelPods: tt.max},
+				Status: migrationv1alpha1.StatefulSetMigrationStatus{CurrentIndex: tt.current, TotalReplicas: 5},
+			}
+			if got := nextPositions(m); !slices.Equal(got, tt.want) {
+				t.Errorf("nextPositions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
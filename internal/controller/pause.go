@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+const (
+	// DefaultPauseConfigMap is the name of the ConfigMap that pauses every
+	// migration, in the controller's namespace
+	DefaultPauseConfigMap = "aqua-service-controller-pause"
+
+	// PauseKey set to "true" in the pause ConfigMap pauses the controller
+	PauseKey = "paused"
+
+	// PauseReasonKey in the pause ConfigMap says why, for the migrations'
+	// conditions (optional)
+	PauseReasonKey = "reason"
+
+	// DefaultPausePollInterval is how often the pause ConfigMap is read
+	DefaultPausePollInterval = 5 * time.Second
+
+	// ConditionPaused reports that the migration is held by the pause ConfigMap
+	ConditionPaused = "Paused"
+)
+
+// PauseSwitch holds every migration while a ConfigMap says so, as an
+// emergency brake during an AWS or network incident that needs no controller
+// restart. It reads the ConfigMap directly rather than through the manager's
+// cache, so the controller needs no watch on every ConfigMap in the cluster,
+// and tells the reconcilers when the pause begins or ends.
+type PauseSwitch struct {
+	// Reader reads the ConfigMap, such as the manager's API reader
+	Reader client.Reader
+
+	// ConfigMap is the pause ConfigMap
+	ConfigMap types.NamespacedName
+
+	// Interval is how often the ConfigMap is read (default: DefaultPausePollInterval)
+	Interval time.Duration
+
+	// Clock drives the polling (default: the real clock)
+	Clock clock.WithTicker
+
+	mu          sync.Mutex
+	reason      string
+	subscribers []chan event.GenericEvent
+}
+
+// Reason returns why the controller is paused, or "" when it is not. A nil
+// switch is never paused.
+func (p *PauseSwitch) Reason() string {
+	if p == nil {
+		return ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.reason
+}
+
+// Subscribe returns a channel that receives an event whenever the pause
+// begins or ends. Events a reconciler has not taken yet are merged.
+func (p *PauseSwitch) Subscribe() <-chan event.GenericEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ch := make(chan event.GenericEvent, 1)
+	p.subscribers = append(p.subscribers, ch)
+	return ch
+}
+
+// Start polls the ConfigMap until ctx ends. A ConfigMap that cannot be read
+// leaves the controller as it was, so an API server outage neither pauses
+// nor releases it.
+func (p *PauseSwitch) Start(ctx context.Context) error {
+	clk := p.Clock
+	if clk == nil {
+		clk = clock.RealClock{}
+	}
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultPausePollInterval
+	}
+	ticker := clk.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C():
+		}
+	}
+}
+
+// poll reads the ConfigMap once and notifies the subscribers of a change
+func (p *PauseSwitch) poll(ctx context.Context) {
+	logger := log.FromContext(ctx).WithValues("configMap", p.ConfigMap.String())
+	reason, err := p.read(ctx)
+	if err != nil {
+		logger.Error(err, "Failed to read the pause ConfigMap")
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if reason == p.reason {
+		return
+	}
+	if reason != "" {
+		logger.Info("Pausing every migration", "reason", reason)
+	} else {
+		logger.Info("Pause lifted, resuming migrations")
+	}
+	p.reason = reason
+	cm := &corev1.ConfigMap{}
+	cm.Namespace, cm.Name = p.ConfigMap.Namespace, p.ConfigMap.Name
+	for _, ch := range p.subscribers {
+		select {
+		case ch <- event.GenericEvent{Object: cm}:
+		default:
+		}
+	}
+}
+
+// read returns the pause reason the ConfigMap gives, or "" when it does not
+// pause the controller
+func (p *PauseSwitch) read(ctx context.Context) (string, error) {
+	cm := &corev1.ConfigMap{}
+	if err := p.Reader.Get(ctx, p.ConfigMap, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if cm.Data[PauseKey] != "true" {
+		return "", nil
+	}
+	reason := fmt.Sprintf("the controller is paused by ConfigMap %s", p.ConfigMap)
+	if why := cm.Data[PauseReasonKey]; why != "" {
+		reason += ": " + why
+	}
+	return reason, nil
+}
+
+// pausable reports whether a pause holds a migration in phase: every phase
+// of a running migration, pre-flight included, since an incident would fail
+// checks that pass once it is over. A finished migration's post-migration
+// watch only reads the destination, and tolerates it being unreachable.
+func pausable(phase migrationv1alpha1.MigrationPhase) bool {
+	return abortable(phase) || phase == migrationv1alpha1.PhaseFinalizing
+}
+
+// holdPaused leaves the migration where it is and sets ConditionPaused.
+// Nothing is requeued: the pause switch reconciles every migration when the
+// pause is lifted.
+func (r *StatefulSetMigrationReconciler) holdPaused(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, reason string) (ctrl.Result, error) {
+	log.FromContext(ctx).Info("Holding paused migration", "phase", m.Status.Phase, "reason", reason)
+
+	message := fmt.Sprintf("Holding in %s because %s", m.Status.Phase, reason)
+	if c := meta.FindStatusCondition(m.Status.Conditions, ConditionPaused); c != nil && c.Status == metav1.ConditionTrue && c.Message == message {
+		return ctrl.Result{}, nil
+	}
+	r.setCondition(m, ConditionPaused, metav1.ConditionTrue, "Held", message)
+	if err := r.Status().Update(ctx, m); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// releasePaused clears ConditionPaused once the pause is lifted; the phase
+// handler writes the status
+func (r *StatefulSetMigrationReconciler) releasePaused(m *migrationv1alpha1.StatefulSetMigration) {
+	if meta.IsStatusConditionTrue(m.Status.Conditions, ConditionPaused) {
+		r.setCondition(m, ConditionPaused, metav1.ConditionFalse, "Released", "The pause was lifted")
+	}
+}
+
+// enqueueAll returns a handler that reconciles every object of the kind
+// newList lists, for the pause switch's events
+func enqueueAll(c client.Client, newList func() client.ObjectList) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, _ client.Object) []reconcile.Request {
+		list := newList()
+		if err := c.List(ctx, list); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list objects to reconcile after a pause change")
+			return nil
+		}
+		var requests []reconcile.Request
+		_ = meta.EachListItem(list, func(obj runtime.Object) error {
+			if o, ok := obj.(client.Object); ok {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(o)})
+			}
+			return nil
+		})
+		return requests
+	})
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestPauseSwitch(t *testing.T) {
+	key := types.NamespacedName{Namespace: "aqua-system", Name: DefaultPauseConfigMap}
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	p := &PauseSwitch{Reader: c, ConfigMap: key}
+	events := p.Subscribe()
+	ctx := context.Background()
+
+	notified := func() bool {
+		select {
+		case <-events:
+			return true
+		default:
+			return false
+		}
+	}
+
+	// No ConfigMap, no pause
+	p.poll(ctx)
+	if reason := p.Reason(); reason != "" || notified() {
+		t.Fatalf("Reason() = %q, want no pause or event without the ConfigMap", reason)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		Data:       map[string]string{PauseKey: "true", PauseReasonKey: "EBS API degraded in us-east-1"},
+	}
+	if err := c.Create(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+	p.poll(ctx)
+	if reason := p.Reason(); !strings.Contains(reason, "aqua-system/"+DefaultPauseConfigMap) || !strings.HasSuffix(reason, ": EBS API degraded in us-east-1") {
+		t.Errorf("Reason() = %q, want the ConfigMap and its reason", reason)
+	}
+	if !notified() {
+		t.Error("no event when the pause began")
+	}
+
+	// An unchanged pause sends nothing
+	p.poll(ctx)
+	if notified() {
+		t.Error("event without a change")
+	}
+
+	cm.Data[PauseKey] = "false"
+	if err := c.Update(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+	p.poll(ctx)
+	if reason := p.Reason(); reason != "" {
+		t.Errorf("Reason() = %q, want the pause lifted", reason)
+	}
+	if !notified() {
+		t.Error("no event when the pause was lifted")
+	}
+}
+
+func TestReconcilePaused(t *testing.T) {
+	tests := []struct {
+		name     string
+		phase    migrationv1alpha1.MigrationPhase
+		wantHeld bool
+	}{
+		{name: "holds pod migration", phase: migrationv1alpha1.PhaseMigratingPods, wantHeld: true},
+		{name: "holds pre-flight", phase: migrationv1alpha1.PhasePreFlightChecks, wantHeld: true},
+		{name: "lets a completed migration be watched", phase: migrationv1alpha1.PhaseCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			m := &migrationv1alpha1.StatefulSetMigration{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", Finalizers: []string{MigrationFinalizer}},
+				Spec:       migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web"},
+			}
+			m.Status = migrationv1alpha1.StatefulSetMigrationStatus{Phase: tt.phase, AppliedSpec: m.Spec.DeepCopy()}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+			r := &StatefulSetMigrationReconciler{Client: c, Pause: &PauseSwitch{reason: "the controller is paused by ConfigMap aqua-system/pause"}}
+
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ops", Name: "web"}})
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if !result.IsZero() {
+				t.Errorf("Reconcile() = %+v, want no requeue", result)
+			}
+
+			got := &migrationv1alpha1.StatefulSetMigration{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "ops", Name: "web"}, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.Phase != tt.phase {
+				t.Errorf("Phase = %s, want %s", got.Status.Phase, tt.phase)
+			}
+			if held := meta.IsStatusConditionTrue(got.Status.Conditions, ConditionPaused); held != tt.wantHeld {
+				t.Errorf("Paused condition = %v, want %v", held, tt.wantHeld)
+			}
+		})
+	}
+}
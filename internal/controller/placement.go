@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// checkVolumePlacement fails pre-flight when a source volume lives on an
+// Outpost, or in a Local or Wavelength Zone, that the destination cluster has
+// no nodes on, or that the migration's strategy cannot move volumes to. Without
+// it such volumes only fail once their pod is stuck Pending in the destination.
+func (r *StatefulSetMigrationReconciler) checkVolumePlacement(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient, podSpec *corev1.PodSpec, pvs []*corev1.PersistentVolume) error {
+	nodeList := &corev1.NodeList{}
+	if err := destCC.Client.List(ctx, nodeList); err != nil {
+		return fmt.Errorf("failed to list destination nodes: %w", err)
+	}
+	nodes := migration.NodesForPod(nodeList.Items, podSpec)
+
+	zones := make(map[string]*aws.ZoneInfo)
+	var problems []string
+	for _, pv := range pvs {
+		volumeID, err := getVolumeIDFromPV(pv)
+		if err != nil {
+			return err
+		}
+		info, err := r.ebs(m).GetVolumeInfo(ctx, volumeID)
+		if err != nil {
+			return err
+		}
+
+		var zone *aws.ZoneInfo
+		if info.OutpostARN == "" {
+			if zone = zones[info.AvailabilityZone]; zone == nil {
+				if zone, err = r.ebs(m).GetZoneInfo(ctx, info.AvailabilityZone); err != nil {
+					return err
+				}
+				zones[info.AvailabilityZone] = zone
+			}
+		}
+		if problem := placementProblem(info, zone, nodes, transferVolumes(m)); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// placementProblem explains why a volume cannot move to the destination
+// nodes, or returns "" when it can. zone is nil for Outpost volumes. Regular
+// availability zones are left to the capacity check.
+func placementProblem(info *aws.VolumeInfo, zone *aws.ZoneInfo, nodes []corev1.Node, transfer bool) string {
+	if info.OutpostARN != "" {
+		outpostID := aws.OutpostID(info.OutpostARN)
+		if transfer {
+			return fmt.Sprintf("volume %s is on Outpost %s; destAWS.transferVolumes cannot recreate volumes on an Outpost, so migrate it by reattaching within the account",
+				info.VolumeID, outpostID)
+		}
+		if migration.CountNodes(nodes, translate.OutpostIDLabel, outpostID) == 0 {
+			return fmt.Sprintf("volume %s is on Outpost %s but the destination has no schedulable nodes on it; add a node group on Outpost %s",
+				info.VolumeID, outpostID, outpostID)
+		}
+		return ""
+	}
+
+	if zone.Type == aws.ZoneTypeAvailabilityZone {
+		return ""
+	}
+	if migration.CountNodes(nodes, corev1.LabelTopologyZone, zone.Name) == 0 {
+		return fmt.Sprintf("volume %s is in %s %s (parent zone %s) and can only attach to instances there, but the destination has no schedulable nodes in it; add a node group in %s",
+			info.VolumeID, zone.Type, zone.Name, zone.ParentZone, zone.Name)
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+func TestPlacementProblem(t *testing.T) {
+	node := func(labels map[string]string) corev1.Node {
+		return corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: labels}}
+	}
+	nodes := []corev1.Node{
+		node(map[string]string{corev1.LabelTopologyZone: "us-west-2a"}),
+		node(map[string]string{corev1.LabelTopologyZone: "us-west-2a", translate.OutpostIDLabel: "op-1"}),
+		node(map[string]string{corev1.LabelTopologyZone: "us-west-2-lax-1a"}),
+	}
+	outpost := func(id string) *aws.VolumeInfo {
+		return &aws.VolumeInfo{VolumeID: "vol-1", OutpostARN: "arn:aws:outposts:us-west-2:111111111111:outpost/" + id}
+	}
+	regular := &aws.ZoneInfo{Name: "us-west-2b", Type: aws.ZoneTypeAvailabilityZone}
+	laLocal := &aws.ZoneInfo{Name: "us-west-2-lax-1a", Type: aws.ZoneTypeLocalZone, ParentZone: "us-west-2a"}
+	denLocal := &aws.ZoneInfo{Name: "us-west-2-den-1a", Type: aws.ZoneTypeLocalZone, ParentZone: "us-west-2a"}
+	wavelength := &aws.ZoneInfo{Name: "us-west-2-wl1-las-wlz-1", Type: aws.ZoneTypeWavelengthZone, ParentZone: "us-west-2b"}
+
+	tests := []struct {
+		name     string
+		info     *aws.VolumeInfo
+		zone     *aws.ZoneInfo
+		transfer bool
+		want     string
+	}{
+		{name: "regular zone is left to the capacity check", info: &aws.VolumeInfo{VolumeID: "vol-1"}, zone: regular},
+		{name: "outpost with nodes", info: outpost("op-1")},
+		{name: "outpost without nodes", info: outpost("op-2"), want: "add a node group on Outpost op-2"},
+		{name: "outpost with transfer", info: outpost("op-1"), transfer: true, want: "cannot recreate volumes on an Outpost"},
+		{name: "local zone with nodes", info: &aws.VolumeInfo{VolumeID: "vol-1"}, zone: laLocal},
+		{name: "local zone without nodes", info: &aws.VolumeInfo{VolumeID: "vol-1"}, zone: denLocal, want: "is in Local Zone us-west-2-den-1a (parent zone us-west-2a)"},
+		{name: "wavelength zone without nodes", info: &aws.VolumeInfo{VolumeID: "vol-1"}, zone: wavelength, want: "is in Wavelength Zone us-west-2-wl1-las-wlz-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := placementProblem(tt.info, tt.zone, nodes, tt.transfer)
+			if tt.want == "" && got != "" {
+				t.Errorf("placementProblem() = %q, want no problem", got)
+			}
+			if tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("placementProblem() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// podWatchTimeout bounds each watch on a single pod. The API server
+	// closes the watch after it and the wait lists and watches again.
+	podWatchTimeout = 5 * time.Minute
+
+	// podWatchRetry is the least time between two watches of the same pod,
+	// so a watch the API server keeps closing is retried at the old poll rate
+	podWatchRetry = 5 * time.Second
+)
+
+var (
+	// errPodWatchUnavailable means the pod cannot be watched, typically for
+	// lack of the watch verb, and the caller should poll instead
+	errPodWatchUnavailable = errors.New("pod watch unavailable")
+
+	// errPodWatchClosed means the watch ended before the pod became ready
+	errPodWatchClosed = errors.New("pod watch closed")
+)
+
+// watchPodReady waits for a pod to become ready on a watch of that pod alone.
+// Each kubelet status update arrives as an event, so readiness is noticed
+// as soon as it is reported, and a wait costs one request per
+// podWatchTimeout instead of one per poll. It returns errPodWatchUnavailable
+// when the pod cannot be listed or watched.
+func (r *StatefulSetMigrationReconciler) watchPodReady(ctx context.Context, clientset kubernetes.Interface, namespace, name string, deadline <-chan time.Time) error {
+	for {
+		started := r.clock().Now()
+		pod, w, err := watchPod(ctx, clientset, namespace, name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%w: %v", errPodWatchUnavailable, err)
+		}
+		if pod != nil && podReady(pod) {
+			w.Stop()
+			return nil
+		}
+
+		err = awaitPodReady(ctx, w, name, deadline)
+		w.Stop()
+		if !errors.Is(err, errPodWatchClosed) {
+			return err
+		}
+
+		if wait := podWatchRetry - r.clock().Since(started); wait > 0 {
+			retry := r.clock().NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				retry.Stop()
+				return ctx.Err()
+			case <-deadline:
+				retry.Stop()
+				return fmt.Errorf("timeout waiting for pod %s to be ready", name)
+			case <-retry.C():
+			}
+		}
+	}
+}
+
+// watchPod lists the named pod and watches it from the list's resource
+// version, so no update between the two is missed. The pod is nil when it
+// does not exist yet.
+func watchPod(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (*corev1.Pod, watch.Interface, error) {
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	list, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pod %s: %w", name, err)
+	}
+	w, err := clientset.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector:   selector,
+		ResourceVersion: list.ResourceVersion,
+		TimeoutSeconds:  ptr.To(int64(podWatchTimeout.Seconds())),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to watch pod %s: %w", name, err)
+	}
+
+	for i := range list.Items {
+		if list.Items[i].Name == name {
+			return &list.Items[i], w, nil
+		}
+	}
+	return nil, w, nil
+}
+
+// awaitPodReady reads events from a pod watch until the pod is ready. It
+// returns errPodWatchClosed when the watch ends or reports an error.
+func awaitPodReady(ctx context.Context, w watch.Interface, name string, deadline <-chan time.Time) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("timeout waiting for pod %s to be ready", name)
+		case event, ok := <-w.ResultChan():
+			if !ok || event.Type == watch.Error {
+				return errPodWatchClosed
+			}
+			pod, ok := event.Object.(*corev1.Pod)
+			if !ok || pod.Name != name || event.Type == watch.Deleted {
+				continue
+			}
+			if podReady(pod) {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func readinessPod(ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "web-0"},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+	}
+}
+
+func TestWaitForPodReadyWatch(t *testing.T) {
+	clientset := k8sfake.NewClientset(readinessPod(false))
+	watching := make(chan struct{})
+	clientset.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w, err := clientset.Tracker().Watch(corev1.SchemeGroupVersion.WithResource("pods"), action.GetNamespace())
+		close(watching)
+		return true, w, err
+	})
+	cc := &multicluster.ClusterClient{Clientset: clientset}
+	r := &StatefulSetMigrationReconciler{}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.waitForPodReady(context.Background(), cc, "db", "web-0", time.Minute)
+	}()
+
+	select {
+	case <-watching:
+	case err := <-done:
+		t.Fatalf("waitForPodReady() returned %v before the pod was ready", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("waitForPodReady() did not watch the pod")
+	}
+	if _, err := clientset.CoreV1().Pods("db").UpdateStatus(context.Background(), readinessPod(true), metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("waitForPodReady() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waitForPodReady() did not notice the ready pod from the watch")
+	}
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "get" {
+			t.Errorf("waitForPodReady() polled the pod while watching it")
+		}
+	}
+}
+
+func TestWaitForPodReadyWatchForbidden(t *testing.T) {
+	clientset := k8sfake.NewClientset()
+	clientset.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", nil)
+	})
+	cc := &multicluster.ClusterClient{
+		Clientset: clientset,
+		Client:    fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(readinessPod(true)).Build(),
+	}
+	clk := clocktesting.NewFakeClock(time.Now())
+	r := &StatefulSetMigrationReconciler{Clock: clk}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.waitForPodReady(context.Background(), cc, "db", "web-0", time.Minute)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("waitForPodReady() error = %v, want the poll to find the ready pod", err)
+			}
+			return
+		case <-deadline:
+			t.Fatal("waitForPodReady() did not fall back to polling")
+		case <-time.After(time.Millisecond):
+			if clk.HasWaiters() {
+				clk.Step(5 * time.Second)
+			}
+		}
+	}
+}
+
+func TestAwaitPodReady(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  []watch.Event
+		close   bool
+		wantErr error
+	}{
+		{
+			name: "ready after an update",
+			events: []watch.Event{
+				{Type: watch.Modified, Object: readinessPod(false)},
+				{Type: watch.Modified, Object: readinessPod(true)},
+			},
+		},
+		{
+			name:    "watch closed",
+			events:  []watch.Event{{Type: watch.Modified, Object: readinessPod(false)}},
+			close:   true,
+			wantErr: errPodWatchClosed,
+		},
+		{
+			name:    "watch error",
+			events:  []watch.Event{{Type: watch.Error, Object: &metav1.Status{Reason: metav1.StatusReasonExpired}}},
+			wantErr: errPodWatchClosed,
+		},
+		{
+			name: "other pods and deletions ignored",
+			events: []watch.Event{
+				{Type: watch.Modified, Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1"}, Status: readinessPod(true).Status}},
+				{Type: watch.Deleted, Object: readinessPod(true)},
+				{Type: watch.Added, Object: readinessPod(true)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := watch.NewFakeWithChanSize(len(tt.events), false)
+			for _, e := range tt.events {
+				w.Action(e.Type, e.Object)
+			}
+			if tt.close {
+				w.Stop()
+			}
+
+			err := awaitPodReady(context.Background(), w, "web-0", make(chan time.Time))
+			if err != tt.wantErr {
+				t.Errorf("awaitPodReady() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	deadline := make(chan time.Time, 1)
+	deadline <- time.Now()
+	err := awaitPodReady(context.Background(), watch.NewFake(), "web-0", deadline)
+	if err == nil || !strings.Contains(err.Error(), "timeout waiting for pod web-0") {
+		t.Errorf("awaitPodReady() error = %v, want timeout", err)
+	}
+}
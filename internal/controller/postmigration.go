@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+const (
+	// PostMigrationCheckInterval is how often the destination workload is
+	// checked during the post-migration watch
+	PostMigrationCheckInterval = 30 * time.Second
+
+	// ConditionWorkloadHealthy reports the result of the post-migration watch
+	ConditionWorkloadHealthy = "WorkloadHealthy"
+)
+
+// reconcileCompleted runs the post-migration watch of a completed migration
+// and releases its destination volumes once their deletion protection lapses
+func (r *StatefulSetMigrationReconciler) reconcileCompleted(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, error) {
+	protected, err := r.reconcileVolumeProtection(ctx, m)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	result, err := r.reconcilePostMigrationWatch(ctx, m)
+	if err == nil && protected > 0 && (result.RequeueAfter == 0 || protected < result.RequeueAfter) {
+		result.RequeueAfter = protected
+	}
+	return result, err
+}
+
+// reconcilePostMigrationWatch runs the post-migration watch of a completed
+// migration. Until spec.postMigrationWatch has passed since completion, the destination
+// pods must stay Ready and their PVCs and PVs Bound. A problem seen on two
+// consecutive checks moves the migration to Degraded; a single failed check
+// is tolerated so a routine pod restart does not count as a regression.
+func (r *StatefulSetMigrationReconciler) reconcilePostMigrationWatch(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, error) {
+	if m.Spec.PostMigrationWatch == nil || m.Status.CompletionTime == nil {
+		return ctrl.Result{}, nil
+	}
+	if c := meta.FindStatusCondition(m.Status.Conditions, ConditionWorkloadHealthy); c != nil && c.Reason == "WatchPassed" {
+		return ctrl.Result{}, nil
+	}
+	logger := log.FromContext(ctx)
+	before := m.Status.DeepCopy()
+
+	remaining := m.Status.CompletionTime.Add(m.Spec.PostMigrationWatch.Duration).Sub(r.clock().Now())
+	if remaining <= 0 {
+		r.setCondition(m, ConditionWorkloadHealthy, metav1.ConditionTrue, "WatchPassed",
+			fmt.Sprintf("Destination workload stayed healthy for %s after the migration", m.Spec.PostMigrationWatch.Duration))
+		r.recordHistory(m, StepWatch, historyObject("StatefulSet", m.Spec.DestNamespace, m.Spec.StatefulSetName),
+			migrationv1alpha1.HistoryResultSucceeded, "Post-migration watch passed")
+		if err := r.Status().Update(ctx, m); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	destClient, err := r.getDestClient(ctx, m)
+	if err != nil {
+		// The watch is advisory; an unreachable destination is retried, not a regression
+		logger.Error(err, "Failed to get destination client for post-migration watch")
+		return ctrl.Result{RequeueAfter: requeueDelay(PostMigrationCheckInterval, m.UID)}, nil
+	}
+	problems, err := checkDestWorkload(ctx, destClient, m)
+	if err != nil {
+		logger.Error(err, "Post-migration check failed")
+		return ctrl.Result{RequeueAfter: requeueDelay(PostMigrationCheckInterval, m.UID)}, nil
+	}
+
+	// A source pod recreated after the migration is reported as it is
+	// found; the source is not the workload being watched
+	if sourceClient, err := r.getSourceClient(ctx, m); err != nil {
+		logger.Error(err, "Failed to get source client for post-migration watch")
+	} else if _, err := r.checkDualRunning(ctx, m, sourceClient); err != nil {
+		logger.Error(err, "Failed to check for recreated source pods")
+	}
+
+	switch {
+	case len(problems) == 0:
+		r.setCondition(m, ConditionWorkloadHealthy, metav1.ConditionTrue, "Watching",
+			fmt.Sprintf("Destination workload healthy; watching until %s", m.Status.CompletionTime.Add(m.Spec.PostMigrationWatch.Duration).UTC().Format(time.RFC3339)))
+	case meta.IsStatusConditionFalse(m.Status.Conditions, ConditionWorkloadHealthy):
+		return r.degradeMigration(ctx, m, problems)
+	default:
+		logger.Info("Destination workload unhealthy, checking again", "problems", problems)
+		r.setCondition(m, ConditionWorkloadHealthy, metav1.ConditionFalse, "Unhealthy", strings.Join(problems, "; "))
+	}
+
+	// A healthy check like the last one changes nothing
+	if err := r.updateStatus(ctx, m, before); err != nil {
+		return ctrl.Result{}, err
+	}
+	next := requeueDelay(PostMigrationCheckInterval, m.UID)
+	if remaining < next {
+		next = remaining
+	}
+	return ctrl.Result{RequeueAfter: next}, nil
+}
+
+// degradeMigration moves a completed migration whose workload regressed to Degraded
+func (r *StatefulSetMigrationReconciler) degradeMigration(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, problems []string) (ctrl.Result, error) {
+	reason := fmt.Sprintf("Destination workload regressed after the migration: %s", strings.Join(problems, "; "))
+	log.FromContext(ctx).Error(nil, "Migration degraded", "problems", problems)
+
+	m.Status.Phase = migrationv1alpha1.PhaseDegraded
+	m.Status.LastError = reason
+	r.setCondition(m, ConditionWorkloadHealthy, metav1.ConditionFalse, "Regressed", strings.Join(problems, "; "))
+	r.setCondition(m, "Degraded", metav1.ConditionTrue, "WorkloadRegressed", reason)
+	r.recordHistory(m, StepWatch, historyObject("StatefulSet", m.Spec.DestNamespace, m.Spec.StatefulSetName),
+		migrationv1alpha1.HistoryResultFailed, reason)
+	r.publishReport(ctx, m)
+
+	if err := r.Status().Update(ctx, m); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// checkDestWorkload returns what is wrong with the migrated workload: a
+// missing StatefulSet, pods that are missing or not Ready, and PVCs or PVs
+// that are not Bound. An error means the destination could not be read.
+func checkDestWorkload(ctx context.Context, cc *multicluster.ClusterClient, m *migrationv1alpha1.StatefulSetMigration) ([]string, error) {
+	namespace := m.Spec.DestNamespace
+	var problems []string
+
+	sts := &appsv1.StatefulSet{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: m.Spec.StatefulSetName}, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return []string{fmt.Sprintf("StatefulSet %s/%s no longer exists", namespace, m.Spec.StatefulSetName)}, nil
+		}
+		return nil, fmt.Errorf("failed to get destination StatefulSet: %w", err)
+	}
+
+	for _, i := range migratingOrdinals(m) {
+		podName := fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, i)
+		pod := &corev1.Pod{}
+		err := cc.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: podName}, pod)
+		switch {
+		case apierrors.IsNotFound(err):
+			problems = append(problems, fmt.Sprintf("pod %s is missing", podName))
+		case err != nil:
+			return nil, fmt.Errorf("failed to get pod %s: %w", podName, err)
+		case !podReady(pod):
+			problems = append(problems, fmt.Sprintf("pod %s is not Ready (%s)", podName, podState(pod)))
+		}
+
+		pvcName := translate.GetPVCNameForStatefulSetPod("data", m.Spec.StatefulSetName, i)
+		pvc := &corev1.PersistentVolumeClaim{}
+		err = cc.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: pvcName}, pvc)
+		switch {
+		case apierrors.IsNotFound(err):
+			problems = append(problems, fmt.Sprintf("PVC %s is missing", pvcName))
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to get PVC %s: %w", pvcName, err)
+		case pvc.Status.Phase != corev1.ClaimBound:
+			problems = append(problems, fmt.Sprintf("PVC %s is %s", pvcName, phaseOrPending(string(pvc.Status.Phase))))
+			continue
+		}
+
+		pv := &corev1.PersistentVolume{}
+		err = cc.Client.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv)
+		switch {
+		case apierrors.IsNotFound(err):
+			problems = append(problems, fmt.Sprintf("PV %s of PVC %s is missing", pvc.Spec.VolumeName, pvcName))
+		case err != nil:
+			return nil, fmt.Errorf("failed to get PV %s: %w", pvc.Spec.VolumeName, err)
+		case pv.Status.Phase != corev1.VolumeBound:
+			problems = append(problems, fmt.Sprintf("PV %s is %s", pv.Name, phaseOrPending(string(pv.Status.Phase))))
+		}
+	}
+	return problems, nil
+}
+
+// podReady reports whether a pod's Ready condition is true
+func podReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// podState describes why a pod is not Ready, from its first waiting or
+// terminated container
+func podState(pod *corev1.Pod) string {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			return fmt.Sprintf("%s: %s, %d restarts", cs.Name, cs.State.Waiting.Reason, cs.RestartCount)
+		}
+		if cs.State.Terminated != nil {
+			return fmt.Sprintf("%s: %s, %d restarts", cs.Name, cs.State.Terminated.Reason, cs.RestartCount)
+		}
+	}
+	return string(pod.Status.Phase)
+}
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func watchedMigration() *migrationv1alpha1.StatefulSetMigration {
+	completed := metav1.NewTime(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	return &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", UID: "web-001"},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			StatefulSetName:    "web",
+			SourceNamespace:    "prod",
+			DestNamespace:      "prod",
+			PostMigrationWatch: &metav1.Duration{Duration: 10 * time.Minute},
+		},
+		Status: migrationv1alpha1.StatefulSetMigrationStatus{
+			Phase:          migrationv1alpha1.PhaseCompleted,
+			TotalReplicas:  2,
+			CompletionTime: &completed,
+		},
+	}
+}
+
+func TestCheckDestWorkload(t *testing.T) {
+	ready := corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}
+	crashing := corev1.PodStatus{
+		Phase: corev1.PodRunning,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:         "web",
+			RestartCount: 3,
+			State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		}},
+	}
+	pod := func(name string, status corev1.PodStatus) client.Object {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: name}, Status: status}
+	}
+	pvc := func(name, volume string, phase corev1.PersistentVolumeClaimPhase) client.Object {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: name},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: volume},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+	pv := func(name string, phase corev1.PersistentVolumePhase) client.Object {
+		return &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: corev1.PersistentVolumeStatus{Phase: phase}}
+	}
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web"}}
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		want    []string
+	}{
+		{
+			name: "healthy",
+			objects: []client.Object{sts,
+				pod("web-0", ready), pvc("data-web-0", "pv-0", corev1.ClaimBound), pv("pv-0", corev1.VolumeBound),
+				pod("web-1", ready), pvc("data-web-1", "pv-1", corev1.ClaimBound), pv("pv-1", corev1.VolumeBound),
+			},
+		},
+		{
+			name:    "statefulset deleted",
+			objects: []client.Object{pod("web-0", ready)},
+			want:    []string{"StatefulSet prod/web no longer exists"},
+		},
+		{
+			name: "crashing pod and released volume",
+			objects: []client.Object{sts,
+				pod("web-0", crashing), pvc("data-web-0", "pv-0", corev1.ClaimBound), pv("pv-0", corev1.VolumeBound),
+				pvc("data-web-1", "pv-1", corev1.ClaimLost),
+			},
+			want: []string{
+				"pod web-0 is not Ready (web: CrashLoopBackOff, 3 restarts)",
+				"pod web-1 is missing",
+				"PVC data-web-1 is Lost",
+			},
+		},
+		{
+			name: "unbound pv",
+			objects: []client.Object{sts,
+				pod("web-0", ready), pvc("data-web-0", "pv-0", corev1.ClaimBound), pv("pv-0", corev1.VolumeReleased),
+				pod("web-1", ready), pvc("data-web-1", "pv-1", corev1.ClaimBound),
+			},
+			want: []string{"PV pv-0 is Released", "PV pv-1 of PVC data-web-1 is missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.objects...).Build()
+			got, err := checkDestWorkload(context.Background(), &multicluster.ClusterClient{Client: c}, watchedMigration())
+			if err != nil {
+				t.Fatalf("checkDestWorkload() error = %v", err)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("checkDestWorkload() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileCompletedWatchPassed(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	m := watchedMigration()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+	r := &StatefulSetMigrationReconciler{
+		Client: c,
+		Clock:  clocktesting.NewFakeClock(time.Date(2024, 1, 1, 12, 11, 0, 0, time.UTC)),
+	}
+
+	result, err := r.reconcileCompleted(context.Background(), m)
+	if err != nil {
+		t.Fatalf("reconcileCompleted() error = %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Errorf("RequeueAfter = %v, want no requeue once the watch has passed", result.RequeueAfter)
+	}
+	cond := meta.FindStatusCondition(m.Status.Conditions, ConditionWorkloadHealthy)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != "WatchPassed" {
+		t.Fatalf("WorkloadHealthy = %+v, want True/WatchPassed", cond)
+	}
+	if m.Status.Phase != migrationv1alpha1.PhaseCompleted {
+		t.Errorf("Phase = %s, want Completed", m.Status.Phase)
+	}
+
+	// A passed watch is final; later reconciles do not check the destination again
+	if _, err := r.reconcileCompleted(context.Background(), m); err != nil {
+		t.Fatalf("second reconcileCompleted() error = %v", err)
+	}
+}
@@ -0,0 +1,397 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/internal/preflight"
+)
+
+// PreFlightInput is what a pre-flight check inspects. Checks may record
+// what they find in the migration's status, which is written once every
+// check has passed.
+type PreFlightInput struct {
+	Migration         *migrationv1alpha1.StatefulSetMigration
+	SourceClient      *multicluster.ClusterClient
+	DestClient        *multicluster.ClusterClient
+	SourceStatefulSet *appsv1.StatefulSet
+	// DestPodSpec is the pod spec of the destination StatefulSet: the
+	// source's with spec.destinationPlacement merged in
+	DestPodSpec *corev1.PodSpec
+	PVCs        []*corev1.PersistentVolumeClaim
+	// PVs are the source PVs; once the Volume strategy check has run, a
+	// volume to be restored in another zone is a copy in that zone
+	PVs []*corev1.PersistentVolume
+	// UnboundPVCs are the source PVCs that have no PV, left out of PVCs
+	UnboundPVCs []*corev1.PersistentVolumeClaim
+}
+
+// connectClusters returns the clients of both clusters once each answers,
+// or the message the migration fails with
+func (r *StatefulSetMigrationReconciler) connectClusters(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (*multicluster.ClusterClient, *multicluster.ClusterClient, string) {
+	sourceClient, err := r.getSourceClient(ctx, m)
+	if err != nil {
+		return nil, nil, fmt.Sprintf("Failed to connect to source cluster: %v", err)
+	}
+	destClient, err := r.getDestClient(ctx, m)
+	if err != nil {
+		return nil, nil, fmt.Sprintf("Failed to connect to destination cluster: %v", err)
+	}
+	if err := r.ClientManager.TestConnection(ctx, sourceClient); err != nil {
+		return nil, nil, fmt.Sprintf("Source cluster connectivity check failed: %v", err)
+	}
+	if err := r.ClientManager.TestConnection(ctx, destClient); err != nil {
+		return nil, nil, fmt.Sprintf("Destination cluster connectivity check failed: %v", err)
+	}
+	return sourceClient, destClient, ""
+}
+
+// preFlightInput reads what the checks inspect from the source, or returns
+// the message the migration fails with
+func (r *StatefulSetMigrationReconciler) preFlightInput(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceClient, destClient *multicluster.ClusterClient) (*PreFlightInput, string) {
+	// Clock skew and expiring certificates fail migrations part way with
+	// authentication errors, so warn about them up front
+	r.checkConnections(ctx, m, sourceClient, destClient)
+
+	sourceSTS := &appsv1.StatefulSet{}
+	if err := sourceClient.Client.Get(ctx, types.NamespacedName{
+		Namespace: m.Spec.SourceNamespace,
+		Name:      m.Spec.StatefulSetName,
+	}, sourceSTS); err != nil {
+		return nil, fmt.Sprintf("Source StatefulSet not found: %v", err)
+	}
+	m.Status.SourceStatefulSetUID = string(sourceSTS.UID)
+	m.Status.TotalReplicas = int(*sourceSTS.Spec.Replicas)
+	if err := planTranche(ctx, m, sourceSTS, destClient); err != nil {
+		return nil, fmt.Sprintf("Ordinal range check failed: %v", err)
+	}
+	setProgress(m)
+
+	pvcs, pvs, unbound, err := sourceVolumes(ctx, sourceClient, sourceSTS, migratingOrdinals(m))
+	if err != nil {
+		return nil, fmt.Sprintf("Failed to read source volumes: %v", err)
+	}
+	return &PreFlightInput{
+		Migration:         m,
+		SourceClient:      sourceClient,
+		DestClient:        destClient,
+		SourceStatefulSet: sourceSTS,
+		DestPodSpec:       destPodSpec(m, &sourceSTS.Spec.Template.Spec),
+		PVCs:              pvcs,
+		PVs:               pvs,
+		UnboundPVCs:       unbound,
+	}, ""
+}
+
+// PreFlightCheck is a gate a migration must pass before the source is
+// frozen. A failed check of severity Error fails the migration with
+// "<name> check failed: <error>"; one of severity Warning is recorded in
+// the history, and so the report, and the migration continues.
+type PreFlightCheck interface {
+	Name() string
+	Severity() preflight.Severity
+	Check(ctx context.Context, in *PreFlightInput) error
+}
+
+// preFlightCheck is a PreFlightCheck built from a function
+type preFlightCheck struct {
+	name     string
+	severity preflight.Severity
+	check    func(ctx context.Context, in *PreFlightInput) error
+}
+
+func (c preFlightCheck) Name() string                 { return c.name }
+func (c preFlightCheck) Severity() preflight.Severity { return c.severity }
+func (c preFlightCheck) Check(ctx context.Context, in *PreFlightInput) error {
+	return c.check(ctx, in)
+}
+
+// preFlightChecks returns the built-in checks in the order they run,
+// followed by the controller's extra checks
+func (r *StatefulSetMigrationReconciler) preFlightChecks() []PreFlightCheck {
+	checks := []PreFlightCheck{
+		// Velero creates the namespace in its restore, and the controller
+		// under spec.createDestNamespace
+		preFlightCheck{"Destination namespace", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			if in.Migration.Spec.Velero != nil {
+				return nil
+			}
+			ns := in.Migration.Spec.DestNamespace
+			if err := in.DestClient.Client.Get(ctx, types.NamespacedName{Name: ns}, &corev1.Namespace{}); err != nil {
+				if apierrors.IsNotFound(err) && in.Migration.Spec.CreateDestNamespace != nil {
+					return nil
+				}
+				if apierrors.IsNotFound(err) {
+					return fmt.Errorf("namespace %q does not exist", ns)
+				}
+				return err
+			}
+			return nil
+		}},
+		// One an earlier tranche created is grown, and was checked when the
+		// tranche was planned
+		preFlightCheck{"Destination StatefulSet", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			if in.Migration.Status.PriorDestOrdinals != "" {
+				return nil
+			}
+			sts := &appsv1.StatefulSet{}
+			err := in.DestClient.Client.Get(ctx, types.NamespacedName{Namespace: in.Migration.Spec.DestNamespace, Name: in.Migration.Spec.StatefulSetName}, sts)
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			return checkExistingDestStatefulSet(in.Migration, sts)
+		}},
+		// The headless service is required for the StatefulSet. With Velero
+		// the restore creates it, so it is checked after the restore.
+		preFlightCheck{"Destination service", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			if in.Migration.Spec.Velero != nil {
+				return nil
+			}
+			return r.checkDestService(ctx, in.Migration, in.DestClient, in.SourceStatefulSet.Spec.ServiceName)
+		}},
+		preFlightCheck{"Velero", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			if in.Migration.Spec.Velero == nil {
+				return nil
+			}
+			for _, cc := range []*multicluster.ClusterClient{in.SourceClient, in.DestClient} {
+				if err := checkVeleroInstalled(ctx, cc, veleroNamespace(in.Migration.Spec.Velero)); err != nil {
+					return fmt.Errorf("%s: %w", cc.RestConfig.Host, err)
+				}
+			}
+			return nil
+		}},
+		// The destination cluster must serve the headless service's IP families
+		preFlightCheck{"IP family", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkIPFamilies(ctx, in.Migration, in.SourceClient, in.DestClient, in.SourceStatefulSet.Spec.ServiceName)
+		}},
+		// The source's external-dns must be able to publish the moved pods' records
+		preFlightCheck{"DNS cutover", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkDNSCutover(ctx, in.Migration, in.SourceClient, in.SourceStatefulSet.Spec.ServiceName)
+		}},
+		preFlightCheck{"Connectivity probe", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkConnectivityProbe(in.Migration)
+		}},
+		// The destination StatefulSet must be able to run the pods moved so far at every step
+		preFlightCheck{"Pod order", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			if in.Migration.Spec.OrdinalRange != "" {
+				return nil
+			}
+			order, err := podOrder(ctx, in.Migration, in.SourceClient, in.DestClient)
+			if err != nil {
+				return err
+			}
+			in.Migration.Status.PodOrder = order
+			return nil
+		}},
+		// Both StatefulSets must be able to run the ordinals a tranche leaves them
+		preFlightCheck{"Ordinal range", preflight.SeverityError, checkOrdinalRange},
+		// A failed pod must be skippable without holding up the rest
+		preFlightCheck{"Failure policy", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkFailurePolicy(in.Migration, in.SourceStatefulSet)
+		}},
+		preFlightCheck{"Parallelism", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkParallelPods(in.Migration, in.SourceStatefulSet)
+		}},
+		preFlightCheck{"Volume policy", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkVolumePolicy(in.Migration, in.SourceStatefulSet)
+		}},
+		// A PVC without a PV, such as one of an ordinal that never started,
+		// has no volume to move
+		preFlightCheck{"Unbound PVCs", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return r.checkUnboundPVCs(in.Migration, in.UnboundPVCs)
+		}},
+		// The volumes' filesystems must suit the OS the pods run on
+		preFlightCheck{"Volume filesystem", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			nodeOS := migration.PodOS(&in.SourceStatefulSet.Spec.Template.Spec)
+			in.Migration.Status.NodeOS = string(nodeOS)
+			for _, pv := range in.PVs {
+				if err := migration.CheckVolumeOS(pv, nodeOS); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		// Legacy in-tree EBS volumes must be mountable on the destination nodes
+		preFlightCheck{"In-tree EBS volumes", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkInTreeEBS(ctx, in.Migration, in.DestClient, in.DestPodSpec, in.PVs)
+		}},
+		// A destination CSI driver that applies fsGroup where the source's did
+		// not changes the ownership of every file on the first mount
+		preFlightCheck{"fsGroup", preflight.SeverityWarning, func(ctx context.Context, in *PreFlightInput) error {
+			return checkFSGroups(ctx, in.SourceClient, in.DestClient, &in.SourceStatefulSet.Spec.Template.Spec, in.PVs)
+		}},
+		// An invalid PV name would otherwise only fail at create time, after
+		// the source has been frozen
+		preFlightCheck{"Destination PV name", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkPVNames(in.Migration, in.PVs)
+		}},
+		// The PVCs' snapshot or clone origins must be strippable or preservable
+		preFlightCheck{"Data source", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkDataSources(ctx, in.Migration, in.DestClient, in.PVCs)
+		}},
+		// Destination PVCs created ahead of the migration must bind
+		preFlightCheck{"Destination PVC", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkDestPVCs(ctx, in.Migration, in.DestClient, in.PVCs, in.PVs)
+		}},
+		// The destination must be able to use the keys of encrypted volumes
+		preFlightCheck{"Encryption key", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			if in.Migration.Spec.DestAWS == nil {
+				return nil
+			}
+			return r.checkVolumeKeys(ctx, in.Migration, in.PVs)
+		}},
+		// EC2 reports volumes in another region as not found, so catch a
+		// misconfigured region up front
+		preFlightCheck{"Volume region", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return r.checkVolumeRegions(in.Migration, in.PVs)
+		}},
+		// The destination must have nodes where spec.destinationPlacement puts the pods
+		preFlightCheck{"Destination placement", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkDestPlacement(ctx, in.Migration, in.DestClient, in.DestPodSpec)
+		}},
+		// Outpost, Local Zone and Wavelength Zone volumes only attach to
+		// instances in the same place
+		preFlightCheck{"Volume placement", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return r.checkVolumePlacement(ctx, in.Migration, in.DestClient, in.DestPodSpec, in.PVs)
+		}},
+		// A volume in a zone where destination nodes can run its pod is
+		// reattached; with spec.strategyFallback, any other is restored in
+		// a zone that can
+		preFlightCheck{"Volume strategy", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return r.planVolumeStrategies(ctx, in.Migration, in.DestClient, in.DestPodSpec, in.PVs)
+		}},
+		// A pod whose volume has moved must find a destination node in its volume's zone
+		preFlightCheck{"Pod scheduling", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkPodScheduling(ctx, in.Migration, in.DestClient, in.DestPodSpec, in.PVs)
+		}},
+		// A transfer recreates each volume, which must fit its type's size
+		// and performance limits
+		preFlightCheck{"Volume transfer", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			if !transferVolumes(in.Migration) {
+				return nil
+			}
+			return r.checkTransferVolumes(ctx, in.Migration, in.PVs)
+		}},
+		// A volume moved mid-expansion leaves the destination PVC's capacity
+		// out of step with the EBS volume's size
+		preFlightCheck{"Volume expansion", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return checkVolumeExpansions(in.PVCs)
+		}},
+		// Detaching a volume while ModifyVolume is still modifying it tends to fail partway
+		preFlightCheck{"Volume modification", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return r.checkVolumeModifications(ctx, in.Migration, in.PVs)
+		}},
+		// DLM and AWS Backup select volumes by tag, so their snapshots follow
+		// the volumes; the check only sets a condition
+		preFlightCheck{"Backup policy", preflight.SeverityWarning, func(ctx context.Context, in *PreFlightInput) error {
+			r.checkVolumeBackups(ctx, in.Migration, in.PVs)
+			return nil
+		}},
+		// Mapping to a slower or unencrypted StorageClass would leave volumes
+		// provisioned later, such as for new replicas, worse than the source's
+		preFlightCheck{"StorageClass", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			downgrades, err := checkStorageClasses(ctx, in.Migration, in.SourceClient, in.DestClient, in.PVs)
+			if err != nil || len(downgrades) == 0 {
+				return err
+			}
+			if !overrides(in.Migration).AllowStorageClassDowngrade {
+				return fmt.Errorf("downgrade: %s; fix spec.storageClassMapping or set spec.overrides.allowStorageClassDowngrade", strings.Join(downgrades, "; "))
+			}
+			log.FromContext(ctx).Info("Proceeding despite StorageClass downgrade because it is overridden", "downgrades", downgrades)
+			r.setCondition(in.Migration, ConditionStorageClassDowngrade, metav1.ConditionTrue, "Forced", strings.Join(downgrades, "; "))
+			return nil
+		}},
+		// The destination nodes and AWS account must have room for the volumes
+		preFlightCheck{"Capacity", preflight.SeverityError, func(ctx context.Context, in *PreFlightInput) error {
+			return r.checkCapacity(ctx, in.Migration, in.DestClient, in.DestPodSpec, in.PVs)
+		}},
+		// Copying a volume takes time in proportion to its size, which the
+		// maintenance window must allow for; the probe runs last, since it
+		// takes longest
+		preFlightCheck{"Bandwidth probe", preflight.SeverityWarning, func(ctx context.Context, in *PreFlightInput) error {
+			return r.probeBandwidth(ctx, in.Migration, in.SourceClient, in.DestClient)
+		}},
+	}
+	return append(checks, r.PreFlightChecks...)
+}
+
+// runPreFlightChecks runs the checks in order. It returns the failures of
+// Warning checks, which are also recorded in the history, and the first
+// Error check to fail, with the message the migration fails with.
+func (r *StatefulSetMigrationReconciler) runPreFlightChecks(ctx context.Context, checks []PreFlightCheck, in *PreFlightInput) ([]string, string, error) {
+	logger := log.FromContext(ctx)
+	var warnings []string
+	for _, check := range checks {
+		err := check.Check(ctx, in)
+		if err == nil {
+			continue
+		}
+		reason := fmt.Sprintf("%s check failed", check.Name())
+		if check.Severity() == preflight.SeverityWarning {
+			logger.Info("Pre-flight check failed, continuing because it only warns", "check", check.Name(), "error", err.Error())
+			warning := fmt.Sprintf("%s: %v", reason, err)
+			r.recordHistory(in.Migration, StepPreFlight, "", migrationv1alpha1.HistoryResultFailed, warning)
+			warnings = append(warnings, warning)
+			continue
+		}
+		return warnings, reason, err
+	}
+	return warnings, "", nil
+}
+
+// externalPreFlightCheck runs an organization's check, as an executable or
+// an HTTP endpoint, against the migration
+type externalPreFlightCheck struct {
+	check preflight.Check
+}
+
+// ExternalPreFlightChecks wraps the checks of the controller's
+// --preflight-checks-config file as PreFlightChecks
+func ExternalPreFlightChecks(checks []preflight.Check) []PreFlightCheck {
+	wrapped := make([]PreFlightCheck, 0, len(checks))
+	for _, check := range checks {
+		wrapped = append(wrapped, externalPreFlightCheck{check: check})
+	}
+	return wrapped
+}
+
+func (c externalPreFlightCheck) Name() string                 { return c.check.Name }
+func (c externalPreFlightCheck) Severity() preflight.Severity { return c.check.Severity }
+
+func (c externalPreFlightCheck) Check(ctx context.Context, in *PreFlightInput) error {
+	m := in.Migration
+	req := preflight.Request{
+		Namespace:       m.Namespace,
+		Name:            m.Name,
+		SourceNamespace: m.Spec.SourceNamespace,
+		DestNamespace:   m.Spec.DestNamespace,
+		StatefulSet:     m.Spec.StatefulSetName,
+		Replicas:        m.Status.TotalReplicas,
+	}
+	if in.SourceClient.RestConfig != nil {
+		req.SourceCluster = in.SourceClient.RestConfig.Host
+	}
+	if in.DestClient.RestConfig != nil {
+		req.DestCluster = in.DestClient.RestConfig.Host
+	}
+	for _, pv := range in.PVs {
+		if volumeID, err := getVolumeIDFromPV(pv); err == nil {
+			req.VolumeIDs = append(req.VolumeIDs, volumeID)
+		}
+	}
+	return c.check.Run(ctx, req)
+}
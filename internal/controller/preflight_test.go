@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/internal/preflight"
+)
+
+func TestRunPreFlightChecks(t *testing.T) {
+	r := &StatefulSetMigrationReconciler{}
+	var ran []string
+	check := func(name string, severity preflight.Severity, err error) PreFlightCheck {
+		return preFlightCheck{name, severity, func(context.Context, *PreFlightInput) error {
+			ran = append(ran, name)
+			return err
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		checks      []PreFlightCheck
+		wantReason  string
+		wantRan     int
+		wantHistory int
+	}{
+		{
+			name:    "all pass",
+			checks:  []PreFlightCheck{check("a", preflight.SeverityError, nil), check("b", preflight.SeverityWarning, nil)},
+			wantRan: 2,
+		},
+		{
+			name: "warning continues",
+			checks: []PreFlightCheck{
+				check("Change freeze", preflight.SeverityWarning, errors.New("change freeze until Monday")),
+				check("b", preflight.SeverityError, nil),
+			},
+			wantRan:     2,
+			wantHistory: 1,
+		},
+		{
+			name: "error stops",
+			checks: []PreFlightCheck{
+				check("CMDB", preflight.SeverityError, errors.New("cluster not approved")),
+				check("b", preflight.SeverityError, nil),
+			},
+			wantReason: "CMDB check failed",
+			wantRan:    1,
+		},
+	}
+	for _, tt := range tests {
+		ran = nil
+		m := &migrationv1alpha1.StatefulSetMigration{}
+		warnings, reason, err := r.runPreFlightChecks(context.Background(), tt.checks, &PreFlightInput{Migration: m})
+		if reason != tt.wantReason || (err != nil) != (tt.wantReason != "") {
+			t.Errorf("%s: runPreFlightChecks() = %q, %v, want reason %q", tt.name, reason, err, tt.wantReason)
+		}
+		if len(ran) != tt.wantRan {
+			t.Errorf("%s: ran %v, want %d checks", tt.name, ran, tt.wantRan)
+		}
+		if len(warnings) != tt.wantHistory || len(m.Status.History) != tt.wantHistory {
+			t.Fatalf("%s: warnings = %v, history = %+v, want %d of each", tt.name, warnings, m.Status.History, tt.wantHistory)
+		}
+		if tt.wantHistory > 0 && m.Status.History[0].Message != "Change freeze check failed: change freeze until Monday" {
+			t.Errorf("%s: history message = %q", tt.name, m.Status.History[0].Message)
+		}
+	}
+}
+
+func TestExternalPreFlightCheck(t *testing.T) {
+	checks := ExternalPreFlightChecks([]preflight.Check{{
+		Name:     "CMDB",
+		Severity: preflight.SeverityWarning,
+		Exec: &preflight.ExecCheck{Command: []string{"sh", "-c",
+			`grep '"destCluster":"https://dest.example.com"' | grep -q '"volumeIDs":\["vol-0123"\]'`}},
+	}})
+	if len(checks) != 1 || checks[0].Name() != "CMDB" || checks[0].Severity() != preflight.SeverityWarning {
+		t.Fatalf("ExternalPreFlightChecks() = %+v, want the check's name and severity", checks)
+	}
+
+	in := &PreFlightInput{
+		Migration: &migrationv1alpha1.StatefulSetMigration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-migration"},
+			Spec:       migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web"},
+		},
+		SourceClient: &multicluster.ClusterClient{RestConfig: &rest.Config{Host: "https://source.example.com"}},
+		DestClient:   &multicluster.ClusterClient{RestConfig: &rest.Config{Host: "https://dest.example.com"}},
+		PVs: []*corev1.PersistentVolume{{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-0"},
+			Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-0123"},
+			}},
+		}},
+	}
+	if err := checks[0].Check(context.Background(), in); err != nil {
+		t.Errorf("Check() error = %v, want the request to carry the clusters and volume IDs", err)
+	}
+}
+
+func TestValidatePreFlight(t *testing.T) {
+	check := func(name string, severity preflight.Severity, err error) PreFlightCheck {
+		return preFlightCheck{name, severity, func(context.Context, *PreFlightInput) error { return err }}
+	}
+	checks := []PreFlightCheck{
+		check("CMDB", preflight.SeverityError, errors.New("cluster not approved")),
+		check("Change freeze", preflight.SeverityWarning, errors.New("change freeze until Monday")),
+		check("Capacity", preflight.SeverityError, nil),
+	}
+
+	m := &migrationv1alpha1.StatefulSetMigration{}
+	results := validatePreFlight(context.Background(), checks, &PreFlightInput{Migration: m})
+	want := []PreFlightResult{
+		{Check: "CMDB", Severity: preflight.SeverityError, Outcome: PreFlightFail, Message: "cluster not approved"},
+		{Check: "Change freeze", Severity: preflight.SeverityWarning, Outcome: PreFlightWarn, Message: "change freeze until Monday"},
+		{Check: "Capacity", Severity: preflight.SeverityError, Outcome: PreFlightPass},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("validatePreFlight() = %+v, want every check run", results)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+	if len(m.Status.History) != 0 {
+		t.Errorf("history = %+v, want nothing recorded", m.Status.History)
+	}
+}
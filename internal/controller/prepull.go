@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// startImagePrePull creates the DaemonSet of spec.imagePrePull in the
+// destination. Pre-pulling only saves time, so a failure is recorded and the
+// migration goes on without it.
+func (r *StatefulSetMigrationReconciler) startImagePrePull(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC *multicluster.ClusterClient, sts *appsv1.StatefulSet) {
+	if m.Spec.ImagePrePull == nil || m.Status.ImagePrePullDaemonSet != "" {
+		return
+	}
+	destCC, err := r.getDestClient(ctx, m)
+	if err == nil {
+		err = r.createImagePrePull(ctx, m, sourceCC, destCC, sts)
+	}
+	if err != nil {
+		name := migration.ImagePrePullName(m.Spec.StatefulSetName)
+		log.FromContext(ctx).Error(err, "Failed to start pre-pulling images", "daemonSet", name)
+		r.recordHistory(m, StepImagePrePull, historyObject("DaemonSet", m.Spec.DestNamespace, name), migrationv1alpha1.HistoryResultFailed, err.Error())
+		r.event(m, corev1.EventTypeWarning, "ImagePrePullFailed", fmt.Sprintf("Images are not pre-pulled: %v", err))
+	}
+}
+
+// createImagePrePull creates a DaemonSet pulling the source StatefulSet's
+// images onto the destination nodes in the zones its volumes will be in, and
+// records it in status.imagePrePullDaemonSet
+func (r *StatefulSetMigrationReconciler) createImagePrePull(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient, sts *appsv1.StatefulSet) error {
+	cfg := m.Spec.ImagePrePull
+	name := migration.ImagePrePullName(m.Spec.StatefulSetName)
+	zones := prePullZones(ctx, m, sourceCC)
+	// The images are needed on the nodes spec.destinationPlacement allows
+	template := sts.Spec.Template.DeepCopy()
+	placeDestTemplate(m, template)
+	ds := migration.ImagePrePullDaemonSet(m.Spec.DestNamespace, name, string(m.UID), template, zones, cfg.HelperImage, cfg.PriorityClassName)
+	if err := destCC.Client.Create(ctx, ds); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	m.Status.ImagePrePullDaemonSet = name
+
+	images := migration.WorkloadImages(&sts.Spec.Template.Spec)
+	message := fmt.Sprintf("Pre-pulling %d images on destination nodes", len(images))
+	if len(zones) > 0 {
+		message += " in " + strings.Join(zones, ", ")
+	}
+	log.FromContext(ctx).Info("Pre-pulling images in the destination", "daemonSet", name, "images", images, "zones", zones)
+	r.recordHistory(m, StepImagePrePull, historyObject("DaemonSet", m.Spec.DestNamespace, name), migrationv1alpha1.HistoryResultStarted, message)
+	return nil
+}
+
+// prePullZones returns the zones the volumes are in once they have moved,
+// as pre-flight selected them, or else the zones of the source volumes
+func prePullZones(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC *multicluster.ClusterClient) []string {
+	seen := make(map[string]bool)
+	for _, decision := range m.Status.VolumeStrategies {
+		zone := decision.DestZone
+		if zone == "" {
+			zone = decision.Zone
+		}
+		if zone != "" {
+			seen[zone] = true
+		}
+	}
+	if len(seen) == 0 {
+		for _, name := range m.Status.PreservedPVs {
+			pv := &corev1.PersistentVolume{}
+			if err := sourceCC.Client.Get(ctx, types.NamespacedName{Name: name}, pv); err != nil {
+				continue
+			}
+			if zone := translate.AvailabilityZone(pv); zone != "" {
+				seen[zone] = true
+			}
+		}
+	}
+	zones := make([]string, 0, len(seen))
+	for zone := range seen {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+// stopImagePrePull deletes the DaemonSet startImagePrePull created. A
+// DaemonSet that cannot be deleted stays in status.imagePrePullDaemonSet,
+// so a later stop, or deleting the migration, tries again.
+func (r *StatefulSetMigrationReconciler) stopImagePrePull(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) {
+	if m.Status.ImagePrePullDaemonSet == "" {
+		return
+	}
+	destCC, err := r.getDestClient(ctx, m)
+	if err == nil {
+		err = r.deleteImagePrePull(ctx, m, destCC)
+	}
+	if err != nil {
+		name := m.Status.ImagePrePullDaemonSet
+		log.FromContext(ctx).Error(err, "Failed to delete image pre-pull DaemonSet", "daemonSet", name)
+		r.recordHistory(m, StepImagePrePull, historyObject("DaemonSet", m.Spec.DestNamespace, name),
+			migrationv1alpha1.HistoryResultFailed, fmt.Sprintf("Failed to delete: %v", err))
+	}
+}
+
+// deleteImagePrePull deletes the DaemonSet in status.imagePrePullDaemonSet
+// and clears it
+func (r *StatefulSetMigrationReconciler) deleteImagePrePull(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient) error {
+	name := m.Status.ImagePrePullDaemonSet
+	if err := deleteIfExists(ctx, destCC, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: name}, &appsv1.DaemonSet{}); err != nil {
+		return err
+	}
+	m.Status.ImagePrePullDaemonSet = ""
+	r.recordHistory(m, StepImagePrePull, historyObject("DaemonSet", m.Spec.DestNamespace, name), migrationv1alpha1.HistoryResultSucceeded, "Deleted")
+	return nil
+}
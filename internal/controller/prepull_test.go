@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestImagePrePull(t *testing.T) {
+	r := &StatefulSetMigrationReconciler{}
+	ctx := context.Background()
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-0"},
+		Spec: corev1.PersistentVolumeSpec{NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"us-east-1b"}},
+			}}},
+		}}},
+	}
+	source := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pv).Build()}
+	dest := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()}
+	sts := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "app", Image: "registry/app:1"}, {Name: "exporter", Image: "registry/exporter:2"}},
+	}}}}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", UID: "uid-1"},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			StatefulSetName: "web",
+			DestNamespace:   "new",
+			ImagePrePull:    &migrationv1alpha1.ImagePrePullConfig{PriorityClassName: "low"},
+		},
+		Status: migrationv1alpha1.StatefulSetMigrationStatus{PreservedPVs: []string{"pv-0"}},
+	}
+
+	if err := r.createImagePrePull(ctx, m, source, dest, sts); err != nil {
+		t.Fatalf("createImagePrePull() error = %v", err)
+	}
+	if m.Status.ImagePrePullDaemonSet != "web-prepull" {
+		t.Errorf("ImagePrePullDaemonSet = %q, want web-prepull", m.Status.ImagePrePullDaemonSet)
+	}
+	key := types.NamespacedName{Namespace: "new", Name: "web-prepull"}
+	ds := &appsv1.DaemonSet{}
+	if err := dest.Client.Get(ctx, key, ds); err != nil {
+		t.Fatalf("DaemonSet not created: %v", err)
+	}
+	if ds.Labels[migration.LabelImagePrePull] != "uid-1" || ds.Spec.Template.Spec.PriorityClassName != "low" {
+		t.Errorf("DaemonSet = %+v", ds.ObjectMeta)
+	}
+	term := ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0]
+	if values := term.MatchExpressions[0].Values; len(values) != 1 || values[0] != "us-east-1b" {
+		t.Errorf("zones = %v, want the source volume's", values)
+	}
+	if h := m.Status.History[len(m.Status.History)-1]; h.Step != StepImagePrePull || h.Message != "Pre-pulling 2 images on destination nodes in us-east-1b" {
+		t.Errorf("history = %+v", h)
+	}
+
+	// A volume moving to another zone is pre-pulled for there
+	m.Status.VolumeStrategies = []migrationv1alpha1.VolumeStrategyDecision{{VolumeID: "vol-0", Zone: "us-east-1b", DestZone: "us-east-1c"}}
+	if zones := prePullZones(ctx, m, source); len(zones) != 1 || zones[0] != "us-east-1c" {
+		t.Errorf("prePullZones() = %v, want the destination zone", zones)
+	}
+
+	if err := r.deleteImagePrePull(ctx, m, dest); err != nil {
+		t.Fatalf("deleteImagePrePull() error = %v", err)
+	}
+	if err := dest.Client.Get(ctx, key, &appsv1.DaemonSet{}); !apierrors.IsNotFound(err) {
+		t.Errorf("DaemonSet still exists: %v", err)
+	}
+	if m.Status.ImagePrePullDaemonSet != "" {
+		t.Errorf("ImagePrePullDaemonSet = %q after deleting it", m.Status.ImagePrePullDaemonSet)
+	}
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+const (
+	// DefaultConnectivityProbeTimeout is how long a connectivity probe may
+	// take to pass by default
+	DefaultConnectivityProbeTimeout = 2 * time.Minute
+
+	// StepProbeConnectivity records probing a moved pod from the source
+	StepProbeConnectivity = "ProbeConnectivity"
+)
+
+// ConnectivityProbeError is returned when the source could not reach a moved
+// pod with spec.connectivityProbe. The pod is left running in the
+// destination.
+type ConnectivityProbeError struct {
+	// Pod is the moved pod that was probed
+	Pod string
+
+	// Err is why the probe failed
+	Err error
+}
+
+func (e *ConnectivityProbeError) Error() string {
+	return fmt.Sprintf("connectivity probe of %s from the source failed: %v", e.Pod, e.Err)
+}
+
+func (e *ConnectivityProbeError) Unwrap() error {
+	return e.Err
+}
+
+// connectivityProbeTimeout returns the time allowed for a probe to pass
+func connectivityProbeTimeout(cfg *migrationv1alpha1.ConnectivityProbeConfig) time.Duration {
+	if cfg.Timeout != nil {
+		return cfg.Timeout.Duration
+	}
+	return DefaultConnectivityProbeTimeout
+}
+
+// checkConnectivityProbe fails pre-flight when spec.connectivityProbe.address
+// does not render for the StatefulSet's pods
+func checkConnectivityProbe(m *migrationv1alpha1.StatefulSetMigration) error {
+	cfg := m.Spec.ConnectivityProbe
+	if cfg == nil {
+		return nil
+	}
+	_, err := migration.RenderProbeAddress(cfg.Address, migration.ProbeData{
+		PodName:   m.Spec.StatefulSetName + "-0",
+		PodIP:     "192.0.2.1",
+		Namespace: m.Spec.DestNamespace,
+	})
+	return err
+}
+
+// probeConnectivity runs spec.connectivityProbe against a moved pod once it
+// is Ready: a temporary pod in the source namespace runs the probe against
+// the pod's destination address, which must pass before the migration moves
+// on. The probe pod is deleted afterwards either way. A pod left by an
+// earlier attempt is replaced.
+func (r *StatefulSetMigrationReconciler) probeConnectivity(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient, podName string) error {
+	cfg := m.Spec.ConnectivityProbe
+	if cfg == nil {
+		return nil
+	}
+	sourceCC, err := r.getSourceClient(ctx, m)
+	if err != nil {
+		return fmt.Errorf("failed to get source client: %w", err)
+	}
+	return r.runConnectivityProbe(ctx, m, sourceCC, destCC, podName)
+}
+
+// runConnectivityProbe probes podName in the destination from a pod created
+// with sourceCC
+func (r *StatefulSetMigrationReconciler) runConnectivityProbe(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient, podName string) error {
+	cfg := m.Spec.ConnectivityProbe
+	logger := log.FromContext(ctx)
+	object := historyObject("Pod", m.Spec.DestNamespace, podName)
+	fail := func(err error) error {
+		r.recordHistory(m, StepProbeConnectivity, object, migrationv1alpha1.HistoryResultFailed, err.Error())
+		return &ConnectivityProbeError{Pod: podName, Err: err}
+	}
+
+	destPod := &corev1.Pod{}
+	if err := destCC.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: podName}, destPod); err != nil {
+		return fmt.Errorf("failed to get destination pod %s: %w", podName, err)
+	}
+	address, err := migration.RenderProbeAddress(cfg.Address, migration.ProbeData{
+		PodName:   podName,
+		PodIP:     destPod.Status.PodIP,
+		Namespace: m.Spec.DestNamespace,
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	pod := migration.ProbePod(m.Spec.SourceNamespace, podName, cfg.Image, cfg.Command, address, cfg.Port, cfg.NodeSelector)
+	key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	if err := sourceCC.Client.Get(ctx, key, &corev1.Pod{}); err == nil {
+		if err := deleteIfExists(ctx, sourceCC, key, &corev1.Pod{}); err != nil {
+			return fmt.Errorf("failed to delete earlier probe pod %s: %w", pod.Name, err)
+		}
+		if err := r.waitForPodDeletion(ctx, sourceCC, pod.Namespace, pod.Name); err != nil {
+			return err
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	target := fmt.Sprintf("%s:%d", address, cfg.Port)
+	logger.Info("Probing moved pod from the source", "pod", podName, "address", target)
+	if err := sourceCC.Client.Create(ctx, pod); err != nil {
+		return fmt.Errorf("failed to create probe pod %s: %w", pod.Name, err)
+	}
+	defer func() {
+		if err := sourceCC.Client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete probe pod", "pod", pod.Name)
+		}
+	}()
+
+	start := r.clock().Now()
+	message, err := r.waitForCommandPod(ctx, sourceCC, key, connectivityProbeTimeout(cfg), StepProbeConnectivity, migration.ProbeResult)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		return fail(fmt.Errorf("%s: %w", target, err))
+	}
+	if message == "" {
+		message = fmt.Sprintf("Reached %s from the source after %s", target, r.clock().Since(start).Round(time.Second))
+	}
+	r.recordHistory(m, StepProbeConnectivity, object, migrationv1alpha1.HistoryResultSucceeded, message)
+	return nil
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestProbeConnectivity(t *testing.T) {
+	tests := []struct {
+		name string
+		// status is what the probe pod reports once created
+		status  corev1.PodStatus
+		wantErr bool
+	}{
+		{name: "reached", status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+		{
+			name: "unreachable",
+			status: corev1.PodStatus{Phase: corev1.PodFailed, ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "nc: 10.1.2.3 (10.1.2.3:5432): Operation timed out"}},
+			}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			destPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "new", Name: "web-0"},
+				Status:     corev1.PodStatus{PodIP: "10.1.2.3"},
+			}
+			var probed string
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(destPod).WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if pod, ok := obj.(*corev1.Pod); ok {
+						pod.Status = tt.status
+						for _, env := range pod.Spec.Containers[0].Env {
+							if env.Name == "PROBE_ADDRESS" {
+								probed = env.Value
+							}
+						}
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}).Build()
+			clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			r := &StatefulSetMigrationReconciler{Clock: clk}
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+				SourceNamespace:   "old",
+				DestNamespace:     "new",
+				ConnectivityProbe: &migrationv1alpha1.ConnectivityProbeConfig{Port: 5432},
+			}}
+
+			// One fake cluster stands in for both the source and the destination
+			cc := &multicluster.ClusterClient{Client: c}
+			done := make(chan error, 1)
+			go func() {
+				done <- r.runConnectivityProbe(ctx, m, cc, cc, "web-0")
+			}()
+			var err error
+			deadline := time.After(10 * time.Second)
+		wait:
+			for {
+				select {
+				case err = <-done:
+					break wait
+				case <-deadline:
+					t.Fatal("runConnectivityProbe() did not return on the fake clock")
+				case <-time.After(time.Millisecond):
+					if clk.HasWaiters() {
+						clk.Step(time.Minute)
+					}
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runConnectivityProbe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var probeErr *ConnectivityProbeError
+			if tt.wantErr && !errors.As(err, &probeErr) {
+				t.Errorf("runConnectivityProbe() error = %v, want a ConnectivityProbeError", err)
+			}
+			if probed != "10.1.2.3" {
+				t.Errorf("probed address = %q, want the destination pod IP", probed)
+			}
+			want := migrationv1alpha1.HistoryResultSucceeded
+			if tt.wantErr {
+				want = migrationv1alpha1.HistoryResultFailed
+			}
+			if len(m.Status.History) != 1 || m.Status.History[0].Step != StepProbeConnectivity || m.Status.History[0].Result != want {
+				t.Errorf("history = %+v, want one %s entry with result %s", m.Status.History, StepProbeConnectivity, want)
+			}
+			err = c.Get(ctx, types.NamespacedName{Namespace: "old", Name: "probe-web-0"}, &corev1.Pod{})
+			if !apierrors.IsNotFound(err) {
+				t.Errorf("probe pod after runConnectivityProbe(): err = %v, want it deleted", err)
+			}
+		})
+	}
+}
+
+func TestCheckConnectivityProbe(t *testing.T) {
+	m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web", DestNamespace: "new"}}
+	if err := checkConnectivityProbe(m); err != nil {
+		t.Errorf("checkConnectivityProbe() without a probe = %v, want nil", err)
+	}
+	m.Spec.ConnectivityProbe = &migrationv1alpha1.ConnectivityProbeConfig{Address: "{{.PodName}}.web.{{.Namespace}}.svc", Port: 80}
+	if err := checkConnectivityProbe(m); err != nil {
+		t.Errorf("checkConnectivityProbe() = %v, want nil", err)
+	}
+	m.Spec.ConnectivityProbe.Address = "{{.Pod}}"
+	if err := checkConnectivityProbe(m); err == nil {
+		t.Error("checkConnectivityProbe() with an unknown field = nil, want an error")
+	}
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// Progress annotations stamped on the source and destination StatefulSets,
+// so teams with access to only the workload's namespace can follow a
+// migration without reading the StatefulSetMigration
+const (
+	// AnnotationStatus is the migration's phase
+	AnnotationStatus = "migration.aqua.io/status"
+
+	// AnnotationCurrentOrdinal is the ordinal being migrated, or the replica
+	// count once every pod has moved
+	AnnotationCurrentOrdinal = "migration.aqua.io/current-ordinal"
+
+	// AnnotationMigrationName is the namespace/name of the StatefulSetMigration
+	AnnotationMigrationName = "migration.aqua.io/migration-name"
+)
+
+// tracksProgress reports whether reconciling a migration in phase may change
+// its progress annotations. Pending and Queued migrations have not checked
+// their clusters yet, and finished ones only change by moving to another
+// phase.
+func tracksProgress(phase migrationv1alpha1.MigrationPhase) bool {
+	switch phase {
+	case migrationv1alpha1.PhasePending, migrationv1alpha1.PhaseQueued, migrationv1alpha1.PhaseCompleted, migrationv1alpha1.PhaseDegraded,
+		migrationv1alpha1.PhaseFailed, migrationv1alpha1.PhaseAborted:
+		return false
+	}
+	return true
+}
+
+// progressAnnotations returns the progress annotations for a migration's current status
+func progressAnnotations(m *migrationv1alpha1.StatefulSetMigration) map[string]string {
+	return map[string]string{
+		AnnotationStatus:         string(m.Status.Phase),
+		AnnotationCurrentOrdinal: strconv.Itoa(ordinalAt(m, m.Status.CurrentIndex)),
+		AnnotationMigrationName:  fmt.Sprintf("%s/%s", m.Namespace, m.Name),
+	}
+}
+
+// setProgress sets status.progress and status.progressPercent from the
+// position in the migration order and the replica count. Every position
+// before CurrentIndex has been moved or skipped.
+func setProgress(m *migrationv1alpha1.StatefulSetMigration) {
+	total := m.Status.TotalReplicas
+	done := min(m.Status.CurrentIndex, total)
+	m.Status.Progress = fmt.Sprintf("%d/%d", done, total)
+	m.Status.ProgressPercent = 0
+	if total > 0 {
+		m.Status.ProgressPercent = done * 100 / total
+	}
+}
+
+// publishProgress stamps the progress annotations on the source StatefulSet,
+// until it is orphaned, and on the destination StatefulSet the migration
+// created. phase is the phase the migration was reconciled in. The
+// annotations are informational, so failures are only logged.
+func (r *StatefulSetMigrationReconciler) publishProgress(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, phase migrationv1alpha1.MigrationPhase) {
+	logger := log.FromContext(ctx)
+	annotations := progressAnnotations(m)
+
+	// A migration waiting on another one's guard must not overwrite its annotations
+	if !meta.IsStatusConditionTrue(m.Status.Conditions, "Blocked") {
+		if sourceClient, err := r.getSourceClient(ctx, m); err != nil {
+			logger.Error(err, "Failed to get source client for progress annotations")
+		} else if err := stampProgress(ctx, sourceClient, m.Spec.SourceNamespace, m.Spec.StatefulSetName, annotations); err != nil {
+			logger.Error(err, "Failed to annotate source StatefulSet with progress")
+		}
+	}
+
+	// Before MigratingPods a destination StatefulSet of the same name is not
+	// this migration's; pre-flight fails on it. A Completed migration only
+	// gets here when the post-migration watch degrades it.
+	switch phase {
+	case migrationv1alpha1.PhaseMigratingPods, migrationv1alpha1.PhaseFinalizing, migrationv1alpha1.PhaseCompleted:
+		if destClient, err := r.getDestClient(ctx, m); err != nil {
+			logger.Error(err, "Failed to get destination client for progress annotations")
+		} else if err := stampProgress(ctx, destClient, m.Spec.DestNamespace, m.Spec.StatefulSetName, annotations); err != nil {
+			logger.Error(err, "Failed to annotate destination StatefulSet with progress")
+		}
+	}
+}
+
+// stampProgress patches the annotations onto a StatefulSet when they differ.
+// A missing StatefulSet is skipped.
+func stampProgress(ctx context.Context, cc *multicluster.ClusterClient, namespace, name string, annotations map[string]string) error {
+	sts := &appsv1.StatefulSet{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get StatefulSet %s/%s: %w", namespace, name, err)
+	}
+
+	changed := false
+	for k, v := range annotations {
+		if sts.Annotations[k] != v {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	patch := client.MergeFrom(sts.DeepCopy())
+	if sts.Annotations == nil {
+		sts.Annotations = make(map[string]string, len(annotations))
+	}
+	for k, v := range annotations {
+		sts.Annotations[k] = v
+	}
+	if err := cc.Client.Patch(ctx, sts, patch); err != nil {
+		return fmt.Errorf("failed to annotate StatefulSet %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
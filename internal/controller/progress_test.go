@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestStampProgress(t *testing.T) {
+	ctx := context.Background()
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web", Annotations: map[string]string{"team": "db"}},
+	}
+	cc := &multicluster.ClusterClient{
+		Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(sts).Build(),
+	}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "migrate-web"},
+		Status:     migrationv1alpha1.StatefulSetMigrationStatus{Phase: migrationv1alpha1.PhaseMigratingPods, CurrentIndex: 2, TotalReplicas: 3},
+	}
+
+	if err := stampProgress(ctx, cc, "prod", "web", progressAnnotations(m)); err != nil {
+		t.Fatalf("stampProgress() error = %v", err)
+	}
+	got := &appsv1.StatefulSet{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "web"}, got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"team":                   "db",
+		AnnotationStatus:         "MigratingPods",
+		AnnotationCurrentOrdinal: "2",
+		AnnotationMigrationName:  "ops/migrate-web",
+	}
+	for k, v := range want {
+		if got.Annotations[k] != v {
+			t.Errorf("annotation %s = %q, want %q", k, got.Annotations[k], v)
+		}
+	}
+
+	// Unchanged progress does not write the StatefulSet again
+	version := got.ResourceVersion
+	if err := stampProgress(ctx, cc, "prod", "web", progressAnnotations(m)); err != nil {
+		t.Fatalf("stampProgress() error = %v", err)
+	}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "web"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ResourceVersion != version {
+		t.Error("stampProgress() patched a StatefulSet whose annotations were current")
+	}
+
+	// An orphaned source StatefulSet is skipped
+	if err := stampProgress(ctx, cc, "prod", "gone", progressAnnotations(m)); err != nil {
+		t.Errorf("stampProgress() on a missing StatefulSet error = %v, want nil", err)
+	}
+}
+
+func TestSetProgress(t *testing.T) {
+	tests := []struct {
+		index, total int
+		want         string
+		wantPercent  int
+	}{
+		{index: 0, total: 0, want: "0/0"},
+		{index: 0, total: 3, want: "0/3"},
+		{index: 2, total: 3, want: "2/3", wantPercent: 66},
+		{index: 3, total: 3, want: "3/3", wantPercent: 100},
+	}
+
+	for _, tt := range tests {
+		m := &migrationv1alpha1.StatefulSetMigration{}
+		m.Status.CurrentIndex, m.Status.TotalReplicas = tt.index, tt.total
+		setProgress(m)
+		if m.Status.Progress != tt.want || m.Status.ProgressPercent != tt.wantPercent {
+			t.Errorf("setProgress(%d of %d) = %q, %d%%, want %q, %d%%", tt.index, tt.total, m.Status.Progress, m.Status.ProgressPercent, tt.want, tt.wantPercent)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+const (
+	// ConditionQueued reports that the migration is waiting for a
+	// MigrationPolicy's limit on active migrations into its destination
+	ConditionQueued = "Queued"
+
+	// EventQueued is recorded when a migration is queued behind others into
+	// the same destination cluster
+	EventQueued = "Queued"
+
+	// StepQueue records waiting for, and getting, a slot in the destination
+	// cluster's limit
+	StepQueue = "DestinationQueue"
+
+	// QueueRecheckInterval is how often a queued migration checks whether
+	// one ahead of it has finished
+	QueueRecheckInterval = 30 * time.Second
+)
+
+// unstarted reports whether a migration in phase has not started yet, so
+// it still runs with its current spec
+func unstarted(phase migrationv1alpha1.MigrationPhase) bool {
+	return phase == migrationv1alpha1.PhasePending || phase == migrationv1alpha1.PhaseQueued
+}
+
+// occupiesDestination reports whether a migration in phase counts against
+// the limits on active migrations into its destination cluster
+func occupiesDestination(phase migrationv1alpha1.MigrationPhase) bool {
+	switch phase {
+	case migrationv1alpha1.PhasePreFlightChecks, migrationv1alpha1.PhaseReplicatingResources, migrationv1alpha1.PhaseFreezingSource,
+		migrationv1alpha1.PhaseMigratingPods, migrationv1alpha1.PhaseFinalizing:
+		return true
+	}
+	return false
+}
+
+// destinationLimit returns the lowest maxActiveMigrations of the
+// destinationLimits, of the policies governing namespace, matching server,
+// or 0 when none does
+func destinationLimit(policies []migrationv1alpha1.MigrationPolicy, namespace, server string) int32 {
+	var limit int32
+	for _, policy := range policies {
+		if len(policy.Spec.MigrationNamespaces) > 0 && !matchesAny(policy.Spec.MigrationNamespaces, namespace) {
+			continue
+		}
+		for _, l := range policy.Spec.DestinationLimits {
+			if matchesAny(l.Clusters, server) && (limit == 0 || l.MaxActiveMigrations < limit) {
+				limit = l.MaxActiveMigrations
+			}
+		}
+	}
+	return limit
+}
+
+// queuedBefore reports whether a was queued ahead of b: created earlier, or
+// at the same time and first by namespace and name
+func queuedBefore(a, b *migrationv1alpha1.StatefulSetMigration) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// destinationQueue returns why m must wait before it starts, or "" when it
+// may: the active migrations into its destination cluster, together with
+// those queued ahead of it, fill the lowest limit a MigrationPolicy governing
+// its namespace sets for that cluster. It records the cluster's server in
+// status.destinationServer, which other migrations are counted by; those
+// started before it was recorded have theirs resolved.
+func (r *StatefulSetMigrationReconciler) destinationQueue(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (string, error) {
+	p := r.TargetPolicy
+	if p == nil {
+		return "", nil
+	}
+	policies := &migrationv1alpha1.MigrationPolicyList{}
+	if err := p.Reader.List(ctx, policies); err != nil {
+		if meta.IsNoMatchError(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to list migration policies: %w", err)
+	}
+	// Without any limit, the destination need not be resolved
+	if !anyDestinationLimit(policies.Items, m.Namespace) {
+		return "", nil
+	}
+
+	server, err := clusterServer(ctx, p.Reader, m.Namespace, m.Spec.DestCluster)
+	if err != nil {
+		// Pre-flight reports a destination it cannot reach
+		log.FromContext(ctx).Info("Not queueing a migration whose destination cannot be resolved", "error", err.Error())
+		return "", nil
+	}
+	m.Status.DestinationServer = server
+	limit := destinationLimit(policies.Items, m.Namespace, server)
+	if limit == 0 {
+		return "", nil
+	}
+
+	migrations := &migrationv1alpha1.StatefulSetMigrationList{}
+	if err := r.List(ctx, migrations); err != nil {
+		return "", fmt.Errorf("failed to list migrations: %w", err)
+	}
+	var active, ahead int32
+	for i := range migrations.Items {
+		other := &migrations.Items[i]
+		if other.UID == m.UID {
+			continue
+		}
+		occupies := occupiesDestination(other.Status.Phase)
+		queued := other.Status.Phase == migrationv1alpha1.PhaseQueued && queuedBefore(other, m)
+		if !occupies && !queued {
+			continue
+		}
+		otherServer := other.Status.DestinationServer
+		if otherServer == "" {
+			spec := &other.Spec
+			if other.Status.AppliedSpec != nil {
+				spec = other.Status.AppliedSpec
+			}
+			if otherServer, err = clusterServer(ctx, p.Reader, other.Namespace, spec.DestCluster); err != nil {
+				continue
+			}
+		}
+		if otherServer != server {
+			continue
+		}
+		if occupies {
+			active++
+		} else {
+			ahead++
+		}
+	}
+	if active+ahead < limit {
+		return "", nil
+	}
+	return fmt.Sprintf("%d of the %d migrations allowed into %s at once are active, and %d more are queued ahead of this one",
+		active, limit, server, ahead), nil
+}
+
+// anyDestinationLimit reports whether a policy governing namespace sets any
+// destination limit
+func anyDestinationLimit(policies []migrationv1alpha1.MigrationPolicy, namespace string) bool {
+	for _, policy := range policies {
+		if len(policy.Spec.MigrationNamespaces) > 0 && !matchesAny(policy.Spec.MigrationNamespaces, namespace) {
+			continue
+		}
+		if len(policy.Spec.DestinationLimits) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// waitInQueue holds a migration about to start in the Queued phase while
+// its destination cluster has no free slot, checking again every
+// QueueRecheckInterval. It returns false once the migration may start, which
+// writes its status.
+func (r *StatefulSetMigrationReconciler) waitInQueue(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, bool, error) {
+	logger := log.FromContext(ctx)
+	before := m.Status.DeepCopy()
+	reason, err := r.destinationQueue(ctx, m)
+	if err != nil {
+		return ctrl.Result{}, true, err
+	}
+	queued := m.Status.Phase == migrationv1alpha1.PhaseQueued
+
+	if reason == "" {
+		if queued {
+			message := "A slot in the destination cluster's limit is free"
+			logger.Info("Leaving the queue", "destination", m.Status.DestinationServer)
+			r.recordHistory(m, StepQueue, "", migrationv1alpha1.HistoryResultSucceeded, message)
+			r.setCondition(m, ConditionQueued, metav1.ConditionFalse, "Started", message)
+		}
+		return ctrl.Result{}, false, nil
+	}
+
+	r.setCondition(m, ConditionQueued, metav1.ConditionTrue, "DestinationLimit", reason)
+	if !queued {
+		logger.Info("Queueing migration", "destination", m.Status.DestinationServer, "reason", reason)
+		m.Status.Phase = migrationv1alpha1.PhaseQueued
+		r.recordHistory(m, StepQueue, "", migrationv1alpha1.HistoryResultStarted, reason)
+		r.event(m, corev1.EventTypeNormal, EventQueued, reason)
+	}
+	if err := r.updateStatus(ctx, m, before); err != nil {
+		return ctrl.Result{}, true, err
+	}
+	return ctrl.Result{RequeueAfter: requeueDelay(QueueRecheckInterval, m.UID)}, true, nil
+}
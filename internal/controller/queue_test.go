@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestWaitInQueue(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	migration := func(name string, age time.Duration, phase migrationv1alpha1.MigrationPhase, server string) *migrationv1alpha1.StatefulSetMigration {
+		return &migrationv1alpha1.StatefulSetMigration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "tenant-a", Name: name, UID: types.UID(name),
+				CreationTimestamp: metav1.NewTime(created.Add(-age)),
+			},
+			Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+				StatefulSetName: name,
+				DestCluster:     migrationv1alpha1.ContextRef{Server: server},
+			},
+			Status: migrationv1alpha1.StatefulSetMigrationStatus{Phase: phase},
+		}
+	}
+	policy := &migrationv1alpha1.MigrationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "limits"},
+		Spec: migrationv1alpha1.MigrationPolicySpec{
+			MigrationNamespaces: []string{"tenant-*"},
+			DestinationLimits: []migrationv1alpha1.DestinationLimit{
+				{Clusters: []string{"https://*.example.com"}, MaxActiveMigrations: 2},
+				{Clusters: []string{"https://small.example.com"}, MaxActiveMigrations: 1},
+			},
+		},
+	}
+	const dest = "https://dest.example.com"
+
+	tests := []struct {
+		name   string
+		others []*migrationv1alpha1.StatefulSetMigration
+		server string
+		queued bool
+	}{
+		{
+			name:   "below the limit",
+			others: []*migrationv1alpha1.StatefulSetMigration{migration("a", time.Hour, migrationv1alpha1.PhaseMigratingPods, dest)},
+			server: dest,
+		},
+		{
+			name: "limit reached",
+			others: []*migrationv1alpha1.StatefulSetMigration{
+				migration("a", time.Hour, migrationv1alpha1.PhaseMigratingPods, dest),
+				migration("b", time.Hour, migrationv1alpha1.PhaseFinalizing, dest),
+			},
+			server: dest,
+			queued: true,
+		},
+		{
+			name: "queued ahead",
+			others: []*migrationv1alpha1.StatefulSetMigration{
+				migration("a", time.Hour, migrationv1alpha1.PhasePreFlightChecks, dest),
+				migration("b", time.Minute, migrationv1alpha1.PhaseQueued, dest),
+			},
+			server: dest,
+			queued: true,
+		},
+		{
+			name: "queued behind, finished and elsewhere",
+			others: []*migrationv1alpha1.StatefulSetMigration{
+				migration("a", -time.Minute, migrationv1alpha1.PhaseQueued, dest),
+				migration("b", time.Hour, migrationv1alpha1.PhaseCompleted, dest),
+				migration("c", time.Hour, migrationv1alpha1.PhaseMigratingPods, "https://other.example.com"),
+			},
+			server: dest,
+		},
+		{
+			name:   "lowest limit applies",
+			others: []*migrationv1alpha1.StatefulSetMigration{migration("a", time.Hour, migrationv1alpha1.PhaseMigratingPods, "https://small.example.com")},
+			server: "https://small.example.com",
+			queued: true,
+		},
+		{
+			name:   "no limit on the cluster",
+			others: []*migrationv1alpha1.StatefulSetMigration{migration("a", time.Hour, migrationv1alpha1.PhaseMigratingPods, "https://prod.internal")},
+			server: "https://prod.internal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := migration("new", 0, migrationv1alpha1.PhasePending, tt.server)
+			objects := []client.Object{policy, m}
+			for _, other := range tt.others {
+				// Migrations from before status.destinationServer resolve theirs
+				if other.Name != "a" {
+					other.Status.DestinationServer = other.Spec.DestCluster.Server
+				}
+				objects = append(objects, other)
+			}
+			c := fake.NewClientBuilder().WithScheme(volumeMigrationScheme(t)).WithObjects(objects...).WithStatusSubresource(m).Build()
+			r := &StatefulSetMigrationReconciler{
+				Client:       c,
+				Recorder:     record.NewFakeRecorder(10),
+				TargetPolicy: &TargetPolicy{Reader: c},
+			}
+
+			_, queued, err := r.waitInQueue(ctx, m)
+			if err != nil {
+				t.Fatalf("waitInQueue() error = %v", err)
+			}
+			if queued != tt.queued {
+				t.Fatalf("waitInQueue() queued = %v, want %v", queued, tt.queued)
+			}
+			if !tt.queued {
+				if m.Status.Phase != migrationv1alpha1.PhasePending {
+					t.Errorf("phase = %s, want Pending", m.Status.Phase)
+				}
+				return
+			}
+			if m.Status.Phase != migrationv1alpha1.PhaseQueued || !meta.IsStatusConditionTrue(m.Status.Conditions, ConditionQueued) {
+				t.Errorf("phase = %s, conditions %v, want Queued", m.Status.Phase, m.Status.Conditions)
+			}
+			if m.Status.DestinationServer != tt.server {
+				t.Errorf("status.destinationServer = %q, want %q", m.Status.DestinationServer, tt.server)
+			}
+
+			// Once the migrations ahead finish, it leaves the queue
+			for _, other := range tt.others {
+				other.Status.Phase = migrationv1alpha1.PhaseCompleted
+				if err := c.Status().Update(ctx, other); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, queued, err := r.waitInQueue(ctx, m); err != nil || queued {
+				t.Fatalf("waitInQueue() after the others finished = %v, %v, want false, nil", queued, err)
+			}
+			if c := meta.FindStatusCondition(m.Status.Conditions, ConditionQueued); c == nil || c.Reason != "Started" {
+				t.Errorf("Queued condition = %v, want reason Started", c)
+			}
+		})
+	}
+}
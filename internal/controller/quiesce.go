@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+const (
+	// AnnotationQuiesce is set to "true" on a source pod to ask its
+	// application to flush and fence itself before the pod is deleted
+	AnnotationQuiesce = "migration.aqua.io/quiesce"
+
+	// DefaultQuiesceAckAnnotation is the annotation a pod sets to "true" to
+	// acknowledge a quiesce request, unless spec.quiesce.ackAnnotation says otherwise
+	DefaultQuiesceAckAnnotation = "migration.aqua.io/quiesced"
+
+	// DefaultQuiesceTimeout is how long to wait for the acknowledgement by default
+	DefaultQuiesceTimeout = 5 * time.Minute
+)
+
+// quiescePod asks a source pod to quiesce and waits for it to acknowledge,
+// when spec.quiesce is set. A pod that does not acknowledge in time fails the
+// pod's migration unless spec.quiesce.timeoutAction is Proceed.
+func (r *StatefulSetMigrationReconciler) quiescePod(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient, pod *corev1.Pod) error {
+	cfg := m.Spec.Quiesce
+	if cfg == nil {
+		return nil
+	}
+	logger := log.FromContext(ctx)
+	object := historyObject("Pod", pod.Namespace, pod.Name)
+
+	ackAnnotation := cfg.AckAnnotation
+	if ackAnnotation == "" {
+		ackAnnotation = DefaultQuiesceAckAnnotation
+	}
+	timeout := DefaultQuiesceTimeout
+	if cfg.Timeout != nil {
+		timeout = cfg.Timeout.Duration
+	}
+
+	if pod.Annotations[AnnotationQuiesce] != "true" {
+		patch := client.MergeFrom(pod.DeepCopy())
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string)
+		}
+		pod.Annotations[AnnotationQuiesce] = "true"
+		if err := cc.Client.Patch(ctx, pod, patch); err != nil {
+			return fmt.Errorf("failed to annotate pod %s for quiesce: %w", pod.Name, err)
+		}
+	}
+	logger.Info("Waiting for pod to quiesce", "pod", pod.Name, "annotation", ackAnnotation)
+
+	start := r.clock().Now()
+	err := r.waitForPodAnnotation(ctx, cc, pod.Namespace, pod.Name, ackAnnotation, timeout)
+	if err == nil {
+		r.recordHistory(m, StepQuiesce, object, migrationv1alpha1.HistoryResultSucceeded,
+			fmt.Sprintf("Acknowledged after %s", r.clock().Since(start).Round(time.Second)))
+		return nil
+	}
+	if ctx.Err() != nil || cfg.TimeoutAction != migrationv1alpha1.QuiesceTimeoutProceed {
+		r.recordHistory(m, StepQuiesce, object, migrationv1alpha1.HistoryResultFailed, err.Error())
+		return err
+	}
+	r.recordHistory(m, StepQuiesce, object, migrationv1alpha1.HistoryResultFailed,
+		fmt.Sprintf("No acknowledgement within %s; deleting the pod anyway", timeout))
+	logger.Info("Pod did not acknowledge quiesce, proceeding", "pod", pod.Name, "timeout", timeout)
+	return nil
+}
+
+// waitForPodAnnotation waits until a pod's annotation is "true". A pod that
+// is deleted while waiting has nothing left to quiesce.
+func (r *StatefulSetMigrationReconciler) waitForPodAnnotation(ctx context.Context, cc *multicluster.ClusterClient, namespace, name, annotation string, timeout time.Duration) error {
+	defer metrics.TrackWait(StepQuiesce)()
+
+	deadline := r.clock().NewTimer(timeout)
+	defer deadline.Stop()
+
+	reader := cc.Reader(namespace)
+	ticker := r.clock().NewTicker(r.pollInterval(2 * time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C():
+			return fmt.Errorf("timeout waiting for pod %s to set %s", name, annotation)
+		case <-ticker.C():
+			pod := &corev1.Pod{}
+			err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pod)
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if pod.Annotations[annotation] == "true" {
+				return nil
+			}
+		}
+	}
+}
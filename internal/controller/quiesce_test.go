@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestQuiescePod(t *testing.T) {
+	tests := []struct {
+		name        string
+		quiesce     *migrationv1alpha1.QuiesceConfig
+		annotations map[string]string
+		wantErr     bool
+		wantResult  migrationv1alpha1.HistoryResult
+	}{
+		{name: "disabled"},
+		{
+			name:        "acknowledged",
+			quiesce:     &migrationv1alpha1.QuiesceConfig{},
+			annotations: map[string]string{DefaultQuiesceAckAnnotation: "true"},
+			wantResult:  migrationv1alpha1.HistoryResultSucceeded,
+		},
+		{
+			name:        "custom acknowledgement annotation",
+			quiesce:     &migrationv1alpha1.QuiesceConfig{AckAnnotation: "db.example.com/fenced"},
+			annotations: map[string]string{"db.example.com/fenced": "true"},
+			wantResult:  migrationv1alpha1.HistoryResultSucceeded,
+		},
+		{
+			name:       "no acknowledgement",
+			quiesce:    &migrationv1alpha1.QuiesceConfig{},
+			wantErr:    true,
+			wantResult: migrationv1alpha1.HistoryResultFailed,
+		},
+		{
+			name:       "no acknowledgement, proceed",
+			quiesce:    &migrationv1alpha1.QuiesceConfig{Timeout: &metav1.Duration{Duration: time.Minute}, TimeoutAction: migrationv1alpha1.QuiesceTimeoutProceed},
+			wantResult: migrationv1alpha1.HistoryResultFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "web-0", Annotations: tt.annotations}}
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(pod).Build()
+			clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			r := &StatefulSetMigrationReconciler{Clock: clk}
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{Quiesce: tt.quiesce}}
+
+			done := make(chan error, 1)
+			go func() {
+				done <- r.quiescePod(ctx, m, &multicluster.ClusterClient{Client: c}, pod.DeepCopy())
+			}()
+			var err error
+			deadline := time.After(10 * time.Second)
+		wait:
+			for {
+				select {
+				case err = <-done:
+					break wait
+				case <-deadline:
+					t.Fatal("quiescePod() did not return on the fake clock")
+				case <-time.After(time.Millisecond):
+					if clk.HasWaiters() {
+						clk.Step(time.Minute)
+					}
+				}
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("quiescePod() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.quiesce == nil {
+				if len(m.Status.History) != 0 {
+					t.Errorf("history = %+v, want none when quiesce is disabled", m.Status.History)
+				}
+				return
+			}
+			got := &corev1.Pod{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: "db", Name: "web-0"}, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Annotations[AnnotationQuiesce] != "true" {
+				t.Errorf("annotations = %v, want %s=true", got.Annotations, AnnotationQuiesce)
+			}
+			if len(m.Status.History) != 1 || m.Status.History[0].Step != StepQuiesce || m.Status.History[0].Result != tt.wantResult {
+				t.Errorf("history = %+v, want one %s entry with result %s", m.Status.History, StepQuiesce, tt.wantResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+const (
+	// AnnotationReadOnly set to "true" on a migration holds it in read-only mode
+	AnnotationReadOnly = "migration.aqua.io/read-only"
+
+	// ConditionReadOnly reports that the migration is held by read-only mode
+	ConditionReadOnly = "ReadOnly"
+)
+
+// readOnlyReason returns why the migration may not change the clusters or
+// AWS, or "" when it may
+func (r *StatefulSetMigrationReconciler) readOnlyReason(m *migrationv1alpha1.StatefulSetMigration) string {
+	if r.ReadOnly {
+		return "the controller runs with --read-only"
+	}
+	if m.Annotations[AnnotationReadOnly] == "true" {
+		return fmt.Sprintf("the migration is annotated %s=true", AnnotationReadOnly)
+	}
+	return ""
+}
+
+// mutatesClusters reports whether a phase's handler changes the source or
+// destination cluster or AWS resources. Pending, pre-flight and the
+// post-migration watch only read them and write the migration's status;
+// pre-flight's one change, creating the destination namespace, waits for
+// FreezingSource in read-only mode.
+func mutatesClusters(phase migrationv1alpha1.MigrationPhase) bool {
+	switch phase {
+	case migrationv1alpha1.PhaseReplicatingResources, migrationv1alpha1.PhaseFreezingSource,
+		migrationv1alpha1.PhaseMigratingPods, migrationv1alpha1.PhaseFinalizing:
+		return true
+	}
+	return false
+}
+
+// holdReadOnly leaves the migration where it is and sets ConditionReadOnly.
+// Nothing is requeued: removing the annotation triggers a reconcile, and
+// --read-only only changes with a controller restart.
+func (r *StatefulSetMigrationReconciler) holdReadOnly(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, reason string) (ctrl.Result, error) {
+	log.FromContext(ctx).Info("Holding migration in read-only mode", "phase", m.Status.Phase, "reason", reason)
+
+	message := fmt.Sprintf("Holding in %s because %s", m.Status.Phase, reason)
+	if c := meta.FindStatusCondition(m.Status.Conditions, ConditionReadOnly); c != nil && c.Status == metav1.ConditionTrue && c.Message == message {
+		return ctrl.Result{}, nil
+	}
+	r.setCondition(m, ConditionReadOnly, metav1.ConditionTrue, "Held", message)
+	if err := r.Status().Update(ctx, m); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// releaseReadOnly clears ConditionReadOnly once the migration may act again;
+// the phase handler writes the status
+func (r *StatefulSetMigrationReconciler) releaseReadOnly(m *migrationv1alpha1.StatefulSetMigration) {
+	if meta.IsStatusConditionTrue(m.Status.Conditions, ConditionReadOnly) {
+		r.setCondition(m, ConditionReadOnly, metav1.ConditionFalse, "Released", "Read-only mode was lifted")
+	}
+}
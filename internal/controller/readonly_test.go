@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestReconcileReadOnly(t *testing.T) {
+	tests := []struct {
+		name        string
+		flag        bool
+		annotations map[string]string
+		phase       migrationv1alpha1.MigrationPhase
+		wantHeld    bool
+	}{
+		{name: "flag holds pod migration", flag: true, phase: migrationv1alpha1.PhaseMigratingPods, wantHeld: true},
+		{name: "annotation holds finalizing", annotations: map[string]string{AnnotationReadOnly: "true"}, phase: migrationv1alpha1.PhaseFinalizing, wantHeld: true},
+		{name: "flag lets a completed migration be watched", flag: true, phase: migrationv1alpha1.PhaseCompleted},
+		{name: "annotation set to false", annotations: map[string]string{AnnotationReadOnly: "false"}, phase: migrationv1alpha1.PhaseCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			m := &migrationv1alpha1.StatefulSetMigration{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "ops",
+					Name:        "web",
+					Finalizers:  []string{MigrationFinalizer},
+					Annotations: tt.annotations,
+				},
+				Spec: migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web"},
+			}
+			m.Status = migrationv1alpha1.StatefulSetMigrationStatus{Phase: tt.phase, AppliedSpec: m.Spec.DeepCopy()}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+			r := &StatefulSetMigrationReconciler{Client: c, ReadOnly: tt.flag}
+
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ops", Name: "web"}})
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if !result.IsZero() {
+				t.Errorf("Reconcile() = %+v, want no requeue", result)
+			}
+
+			got := &migrationv1alpha1.StatefulSetMigration{}
+			if err := c.Get(context.Background(), types.NamespacedName{Namespace: "ops", Name: "web"}, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.Phase != tt.phase {
+				t.Errorf("Phase = %s, want %s", got.Status.Phase, tt.phase)
+			}
+			if held := meta.IsStatusConditionTrue(got.Status.Conditions, ConditionReadOnly); held != tt.wantHeld {
+				t.Errorf("ReadOnly condition = %v, want %v", held, tt.wantHeld)
+			}
+		})
+	}
+}
+
+func TestReleaseReadOnly(t *testing.T) {
+	r := &StatefulSetMigrationReconciler{}
+	m := &migrationv1alpha1.StatefulSetMigration{}
+
+	r.releaseReadOnly(m)
+	if len(m.Status.Conditions) != 0 {
+		t.Fatalf("Conditions = %+v, want none for a migration that was never held", m.Status.Conditions)
+	}
+
+	r.setCondition(m, ConditionReadOnly, metav1.ConditionTrue, "Held", "")
+	r.releaseReadOnly(m)
+	if c := meta.FindStatusCondition(m.Status.Conditions, ConditionReadOnly); c == nil || c.Status != metav1.ConditionFalse {
+		t.Errorf("ReadOnly condition = %+v, want False once released", c)
+	}
+}
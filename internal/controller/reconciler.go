@@ -3,24 +3,35 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
 	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
 	"github.com/aqua-io/aqua-service-controller/internal/migration"
 	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/internal/telemetry"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
 )
 
 const (
@@ -35,6 +46,9 @@ const (
 
 	// DefaultRequeueDelay is the default delay before requeuing
 	DefaultRequeueDelay = 10 * time.Second
+
+	// CachedPollInterval is the poll interval used for remote objects served from an informer cache
+	CachedPollInterval = 500 * time.Millisecond
 )
 
 // StatefulSetMigrationReconciler reconciles a StatefulSetMigration object
@@ -43,6 +57,97 @@ type StatefulSetMigrationReconciler struct {
 	Scheme        *runtime.Scheme
 	ClientManager *multicluster.ClientManager
 	EBSClient     *aws.EBSClient
+
+	// UseRemoteCaches starts informer caches on the source and destination
+	// clusters for the duration of a migration so wait loops read from
+	// watches instead of polling the remote API servers
+	UseRemoteCaches bool
+
+	// GuardNamespace is the namespace holding the leases that prevent two
+	// migrations of the same StatefulSet from running at once (default: DefaultGuardNamespace)
+	GuardNamespace string
+
+	// VolumeLockID identifies this controller instance in EBS volume lock tags.
+	// When set, each volume is locked before it is moved so controllers in
+	// other management clusters cannot attach it elsewhere at the same time.
+	VolumeLockID string
+
+	// VolumeLockTTL is how long a volume lock is honored (default: aws.DefaultVolumeLockTTL)
+	VolumeLockTTL time.Duration
+
+	// Clock drives wait timeouts and poll intervals (default: the real clock)
+	Clock clock.WithTicker
+
+	// ReportBucket is the S3 bucket finished migrations' reports are uploaded
+	// to, in addition to their ConfigMap (optional)
+	ReportBucket string
+
+	// ReportPrefix is prepended to the S3 keys of uploaded reports
+	ReportPrefix string
+
+	// ArchiveBucket is the S3 bucket the source manifests and per-pod
+	// checkpoints of each migration are archived to (optional)
+	ArchiveBucket string
+
+	// ArchivePrefix is prepended to the S3 keys of archived objects
+	ArchivePrefix string
+
+	// S3Encryption is the server-side encryption of uploaded reports and
+	// archives: AES256 (default) or aws:kms
+	S3Encryption string
+
+	// S3KMSKeyID is the KMS key for aws:kms encryption (default: the bucket's key)
+	S3KMSKeyID string
+
+	// DestVolumeProtection is how long the destination PVs and PVCs of a
+	// finished migration keep a finalizer that stops them from being
+	// deleted; 0 disables it
+	DestVolumeProtection time.Duration
+
+	// ReadOnly holds every migration before any step that changes the
+	// clusters or AWS, for freezing the fleet during an incident
+	ReadOnly bool
+
+	// MaxConcurrentReconciles is how many migrations are reconciled at once
+	// (default: 1)
+	MaxConcurrentReconciles int
+
+	// Pause holds every running migration while its ConfigMap says so (optional)
+	Pause *PauseSwitch
+
+	// PreFlightChecks run after the built-in pre-flight checks, such as the
+	// external checks of --preflight-checks-config (optional)
+	PreFlightChecks []PreFlightCheck
+
+	// Recorder records events on migrations (optional)
+	Recorder record.EventRecorder
+
+	// Telemetry receives anonymized statistics of finished migrations (optional)
+	Telemetry *telemetry.Reporter
+
+	// AWSEvents publishes phase changes to EventBridge and the metrics of
+	// finished migrations to CloudWatch (optional)
+	AWSEvents *AWSEvents
+
+	// TargetPolicy restricts the clusters and namespaces migrations may
+	// target; every target is allowed when nil
+	TargetPolicy *TargetPolicy
+
+	// ApprovalKeys, when set, are the public keys one of which must have
+	// signed a migration's AnnotationApproval before its source is frozen
+	ApprovalKeys []ApprovalKey
+
+	// Version is stamped on the migrations the controller starts; one
+	// started by another major or minor version is held (default: version.Get())
+	Version string
+
+	// Replay, when set, runs every step a second time from the status it
+	// started with, to assert the steps are idempotent; for tests only
+	Replay *Replayer
+
+	// capacity watches the destination nodes of migrations waiting for
+	// capacity; set up with the manager
+	capacity *capacityWatches
 }
 
 // +kubebuilder:rbac:groups=migration.aqua.io,resources=statefulsetmigrations,verbs=get;list;watch;create;update;patch;delete
@@ -51,8 +156,19 @@ type StatefulSetMigrationReconciler struct {
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;delete
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;create;delete
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;patch
+// +kubebuilder:rbac:groups="",resources=resourcequotas;limitranges,verbs=create
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=csidrivers;csinodes;volumeattachments,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs;jobs,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;podmonitors;prometheusrules,verbs=get;list;watch;create
 
 // Reconcile handles the reconciliation loop for StatefulSetMigration resources
 func (r *StatefulSetMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -67,8 +183,17 @@ func (r *StatefulSetMigrationReconciler) Reconcile(ctx context.Context, req ctrl
 		return ctrl.Result{}, err
 	}
 
-	// Handle deletion
+	// Handle deletion; its cleanup releases orphaned pods, so read-only mode
+	// and a pause defer it
 	if !migration.DeletionTimestamp.IsZero() {
+		if reason := r.readOnlyReason(migration); reason != "" {
+			logger.Info("Deferring deletion cleanup in read-only mode", "reason", reason)
+			return ctrl.Result{}, nil
+		}
+		if reason := r.Pause.Reason(); reason != "" {
+			logger.Info("Deferring deletion cleanup while paused", "reason", reason)
+			return ctrl.Result{}, nil
+		}
 		return r.handleDeletion(ctx, migration)
 	}
 
@@ -81,25 +206,128 @@ func (r *StatefulSetMigrationReconciler) Reconcile(ctx context.Context, req ctrl
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	// Initialize status if needed
+	// Keep the workload and volume labels FindMigrations queries current
+	if err := r.labelLineage(ctx, migration); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Initialize status if needed; the Pending handler writes it
 	if migration.Status.Phase == "" {
 		migration.Status.Phase = migrationv1alpha1.PhasePending
+	}
+
+	// Once started, the migration runs with the spec it started with
+	if unstarted(migration.Status.Phase) {
+		migration.Status.ObservedGeneration = migration.Generation
+	} else if r.pinSpec(migration) {
 		if err := r.Status().Update(ctx, migration); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	// A migration another controller release started may depend on steps
+	// this one takes differently, so an incompatible release holds it
+	if phase := migration.Status.Phase; !unstarted(phase) && pausable(phase) {
+		reason, changed := r.checkControllerVersion(migration)
+		if reason != "" {
+			return r.holdControllerVersion(ctx, migration, reason, changed)
+		}
+		if changed {
+			if err := r.Status().Update(ctx, migration); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{Requeue: true}, nil
+		}
+	}
+
+	// A pause holds a running migration where it is, aborts and retries
+	// included, until it is lifted
+	if reason := r.Pause.Reason(); reason != "" && pausable(migration.Status.Phase) {
+		return r.holdPaused(ctx, migration, reason)
+	}
+	r.releasePaused(migration)
+
+	// An abort stops the migration before its next pod, read-only or not
+	if abortRequested(migration) {
+		if phase := migration.Status.Phase; abortable(phase) {
+			result, err := r.abortMigration(ctx, migration)
+			if err == nil {
+				r.publishProgress(ctx, migration, phase)
+				r.AWSEvents.PhaseChanged(migration, phase)
+			}
+			return result, err
+		}
+		if migration.Status.Phase == migrationv1alpha1.PhaseFinalizing {
+			logger.Info("Ignoring abort: every pod has already moved")
+		}
+	}
+
+	// A retry only moves the migration back into a phase, which read-only mode then holds
+	if retryRequested(migration) {
+		phase := migration.Status.Phase
+		result, err := r.retryMigration(ctx, migration)
+		if err == nil {
+			r.AWSEvents.PhaseChanged(migration, phase)
+		}
+		return result, err
+	}
+
+	// Read-only mode holds the migration before anything that changes the clusters or AWS
+	if reason := r.readOnlyReason(migration); reason != "" && mutatesClusters(migration.Status.Phase) {
+		return r.holdReadOnly(ctx, migration, reason)
+	}
+	r.releaseReadOnly(migration)
+
+	// A migration a policy does not allow fails before it connects to either
+	// cluster. A retry reruns pre-flight, so it is checked again there.
+	if phase := migration.Status.Phase; unstarted(phase) || phase == migrationv1alpha1.PhasePreFlightChecks {
+		if err := r.TargetPolicy.Check(ctx, migration.Namespace, statefulSetMigrationTargets(migration)); err != nil {
+			if !IsTargetPolicyError(err) {
+				return ctrl.Result{}, err
+			}
+			r.event(migration, corev1.EventTypeWarning, EventTargetDenied, err.Error())
+			phase := migration.Status.Phase
+			result, err := r.failMigration(ctx, migration, err.Error())
+			if err == nil {
+				r.AWSEvents.PhaseChanged(migration, phase)
+			}
+			return result, err
+		}
+	}
+
+	// A phase stuck on a failing API server would not write its status, so
+	// the cluster health condition is written here
+	if r.checkClusterHealth(ctx, migration) {
+		if err := r.Status().Update(ctx, migration); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	// State machine dispatch
 	logger.Info("Reconciling migration", "phase", migration.Status.Phase)
 
+	phase := migration.Status.Phase
+	result, err := r.reconcilePhase(ctx, migration)
+	if err == nil && (tracksProgress(phase) || migration.Status.Phase != phase) {
+		r.publishProgress(ctx, migration, phase)
+		r.AWSEvents.PhaseChanged(migration, phase)
+	}
+	return result, err
+}
+
+// reconcilePhase runs the handler of the migration's current phase
+func (r *StatefulSetMigrationReconciler) reconcilePhase(ctx context.Context, migration *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, error) {
 	switch migration.Status.Phase {
-	case migrationv1alpha1.PhasePending:
+	case migrationv1alpha1.PhasePending, migrationv1alpha1.PhaseQueued:
 		return r.reconcilePending(ctx, migration)
 
 	case migrationv1alpha1.PhasePreFlightChecks:
 		return r.reconcilePreFlightChecks(ctx, migration)
 
+	case migrationv1alpha1.PhaseReplicatingResources:
+		return r.reconcileReplicatingResources(ctx, migration)
+
 	case migrationv1alpha1.PhaseFreezingSource:
 		return r.reconcileFreezingSource(ctx, migration)
 
@@ -110,13 +338,22 @@ func (r *StatefulSetMigrationReconciler) Reconcile(ctx context.Context, req ctrl
 		return r.reconcileFinalizing(ctx, migration)
 
 	case migrationv1alpha1.PhaseCompleted:
-		return ctrl.Result{}, nil // Nothing more to do
+		return r.reconcileCompleted(ctx, migration)
+
+	case migrationv1alpha1.PhaseDegraded:
+		// Manual intervention required; the destination volumes are still
+		// released when their deletion protection lapses
+		protected, err := r.reconcileVolumeProtection(ctx, migration)
+		return ctrl.Result{RequeueAfter: protected}, err
 
 	case migrationv1alpha1.PhaseFailed:
 		return ctrl.Result{}, nil // Manual intervention required
 
+	case migrationv1alpha1.PhaseAborted:
+		return ctrl.Result{}, nil // Manual intervention required
+
 	default:
-		logger.Error(nil, "Unknown migration phase", "phase", migration.Status.Phase)
+		log.FromContext(ctx).Error(nil, "Unknown migration phase", "phase", migration.Status.Phase)
 		return ctrl.Result{}, nil
 	}
 }
@@ -130,6 +367,21 @@ func (r *StatefulSetMigrationReconciler) handleDeletion(ctx context.Context, mig
 
 		// Perform any cleanup if needed
 		// Note: We don't automatically rollback on deletion - that would be dangerous
+		if err := r.releaseOrphanedPods(ctx, migration); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.releaseGitOps(ctx, migration); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.releaseDestVolumes(ctx, migration); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.releaseCaches(ctx, migration)
+		r.stopImagePrePull(ctx, migration)
+		r.capacity.stop(migration)
+		if err := r.releaseGuard(ctx, migration); err != nil {
+			return ctrl.Result{}, err
+		}
 
 		// Remove finalizer
 		controllerutil.RemoveFinalizer(migration, MigrationFinalizer)
@@ -141,104 +393,113 @@ func (r *StatefulSetMigrationReconciler) handleDeletion(ctx context.Context, mig
 	return ctrl.Result{}, nil
 }
 
-// reconcilePending handles the Pending phase
+// reconcilePending handles the Pending and Queued phases
 func (r *StatefulSetMigrationReconciler) reconcilePending(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, error) {
+	// A scheduled migration waits for its window, checking ahead of it
+	if result, waiting, err := r.waitForSchedule(ctx, m); waiting || err != nil {
+		return result, err
+	}
+
+	// Then for a slot when a MigrationPolicy limits the active migrations
+	// into its destination cluster
+	if result, queued, err := r.waitInQueue(ctx, m); queued || err != nil {
+		return result, err
+	}
+
 	logger := log.FromContext(ctx)
 	logger.Info("Starting migration, moving to PreFlightChecks")
 
 	m.Status.Phase = migrationv1alpha1.PhasePreFlightChecks
-	now := metav1.Now()
+	now := metav1.NewTime(r.clock().Now())
 	m.Status.StartTime = &now
+	applySpec(m)
+	r.stampControllerVersion(m)
+	r.recordSpecSnapshot(m)
+	r.recordHistory(m, StepPhase, "", migrationv1alpha1.HistoryResultStarted, fmt.Sprintf("Migration started by controller %s", m.Status.ControllerVersion))
 
-	if err := r.Status().Update(ctx, m); err != nil {
-		return ctrl.Result{}, err
-	}
-
-	return ctrl.Result{Requeue: true}, nil
+	// Pre-flight writes the status, so starting costs no write of its own
+	return r.reconcilePreFlightChecks(ctx, m)
 }
 
 // reconcilePreFlightChecks handles the PreFlightChecks phase
 func (r *StatefulSetMigrationReconciler) reconcilePreFlightChecks(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
+	before := m.Status.DeepCopy()
 	logger.Info("Running pre-flight checks")
 
-	// Get source cluster client
-	sourceClient, err := r.getSourceClient(ctx, m)
-	if err != nil {
-		return r.failMigration(ctx, m, fmt.Sprintf("Failed to connect to source cluster: %v", err))
+	sourceClient, destClient, message := r.connectClusters(ctx, m)
+	if message != "" {
+		return r.failMigration(ctx, m, message)
 	}
 
-	// Get destination cluster client
-	destClient, err := r.getDestClient(ctx, m)
+	// Hold while another migration of the same StatefulSet is active
+	conflict, err := r.acquireGuard(ctx, m, sourceClient.RestConfig.Host)
 	if err != nil {
-		return r.failMigration(ctx, m, fmt.Sprintf("Failed to connect to destination cluster: %v", err))
-	}
-
-	// Test connectivity to both clusters
-	if err := r.ClientManager.TestConnection(ctx, sourceClient); err != nil {
-		return r.failMigration(ctx, m, fmt.Sprintf("Source cluster connectivity check failed: %v", err))
+		return ctrl.Result{}, err
 	}
-	if err := r.ClientManager.TestConnection(ctx, destClient); err != nil {
-		return r.failMigration(ctx, m, fmt.Sprintf("Destination cluster connectivity check failed: %v", err))
+	if conflict != "" {
+		message := fmt.Sprintf("StatefulSet %s/%s is already being migrated by %s", m.Spec.SourceNamespace, m.Spec.StatefulSetName, conflict)
+		logger.Info("Waiting for conflicting migration", "holder", conflict)
+		r.setCondition(m, "Blocked", metav1.ConditionTrue, "DuplicateMigration", message)
+		if err := r.updateStatus(ctx, m, before); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: requeueDelay(DefaultRequeueDelay, m.UID)}, nil
 	}
 
-	// Check source StatefulSet exists
-	sourceSTS := &appsv1.StatefulSet{}
-	if err := sourceClient.Client.Get(ctx, types.NamespacedName{
-		Namespace: m.Spec.SourceNamespace,
-		Name:      m.Spec.StatefulSetName,
-	}, sourceSTS); err != nil {
-		return r.failMigration(ctx, m, fmt.Sprintf("Source StatefulSet not found: %v", err))
+	// Create the namespace of spec.createDestNamespace for the checks to
+	// find; in read-only mode FreezingSource creates it once released
+	if r.readOnlyReason(m) == "" {
+		if err := r.ensureDestNamespace(ctx, m, destClient); err != nil {
+			return r.retryOrFail(ctx, m, "Failed to create the destination namespace", err)
+		}
 	}
 
-	// Store source STS info
-	m.Status.SourceStatefulSetUID = string(sourceSTS.UID)
-	m.Status.TotalReplicas = int(*sourceSTS.Spec.Replicas)
+	in, message := r.preFlightInput(ctx, m, sourceClient, destClient)
+	if message != "" {
+		return r.failMigration(ctx, m, message)
+	}
 
-	// Check destination namespace exists
-	destNS := &corev1.Namespace{}
-	if err := destClient.Client.Get(ctx, types.NamespacedName{Name: m.Spec.DestNamespace}, destNS); err != nil {
-		if apierrors.IsNotFound(err) {
-			return r.failMigration(ctx, m, fmt.Sprintf("Destination namespace %q does not exist", m.Spec.DestNamespace))
+	// Hold too while another migration holds one of the volumes
+	var volumeIDs []string
+	for _, pv := range in.PVs {
+		if volumeID, err := getVolumeIDFromPV(pv); err == nil {
+			volumeIDs = append(volumeIDs, volumeID)
 		}
-		return r.failMigration(ctx, m, fmt.Sprintf("Failed to check destination namespace: %v", err))
 	}
-
-	// Check no conflicting StatefulSet in destination
-	destSTS := &appsv1.StatefulSet{}
-	err = destClient.Client.Get(ctx, types.NamespacedName{
-		Namespace: m.Spec.DestNamespace,
-		Name:      m.Spec.StatefulSetName,
-	}, destSTS)
-	if err == nil {
-		return r.failMigration(ctx, m, fmt.Sprintf("StatefulSet %q already exists in destination namespace %q", m.Spec.StatefulSetName, m.Spec.DestNamespace))
+	conflict, volumeID, err := r.guardVolumes(ctx, m, volumeIDs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if conflict != "" {
+		message := fmt.Sprintf("Volume %s is already being migrated by %s", volumeID, conflict)
+		logger.Info("Waiting for conflicting migration", "holder", conflict, "volumeId", volumeID)
+		r.setCondition(m, "Blocked", metav1.ConditionTrue, "DuplicateMigration", message)
+		if err := r.updateStatus(ctx, m, before); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: requeueDelay(DefaultRequeueDelay, m.UID)}, nil
 	}
-	if !apierrors.IsNotFound(err) {
-		return r.failMigration(ctx, m, fmt.Sprintf("Failed to check destination StatefulSet: %v", err))
+	if meta.IsStatusConditionTrue(m.Status.Conditions, "Blocked") {
+		r.setCondition(m, "Blocked", metav1.ConditionFalse, "GuardAcquired", "No other migration of this StatefulSet or its volumes is active")
 	}
 
-	// Check headless service exists in destination (required for StatefulSet)
-	if sourceSTS.Spec.ServiceName != "" {
-		destService := &corev1.Service{}
-		err = destClient.Client.Get(ctx, types.NamespacedName{
-			Namespace: m.Spec.DestNamespace,
-			Name:      sourceSTS.Spec.ServiceName,
-		}, destService)
-		if err != nil {
-			if apierrors.IsNotFound(err) && !m.Spec.Force {
-				return r.failMigration(ctx, m, fmt.Sprintf("Headless service %q not found in destination namespace (required for StatefulSet)", sourceSTS.Spec.ServiceName))
-			}
-			if !apierrors.IsNotFound(err) {
-				return r.failMigration(ctx, m, fmt.Sprintf("Failed to check destination service: %v", err))
-			}
-		}
+	// Run the built-in checks, then any the organization added
+	_, reason, err := r.runPreFlightChecks(ctx, r.preFlightChecks(), in)
+	if err != nil {
+		return r.retryOrFail(ctx, m, reason, err)
 	}
 
 	logger.Info("Pre-flight checks passed", "replicas", m.Status.TotalReplicas)
 
-	// Move to FreezingSource phase
+	// Move to ReplicatingResources, or straight to FreezingSource without Velero
 	m.Status.Phase = migrationv1alpha1.PhaseFreezingSource
+	if m.Spec.Velero != nil {
+		m.Status.Phase = migrationv1alpha1.PhaseReplicatingResources
+	}
 	r.setCondition(m, "PreFlightChecks", metav1.ConditionTrue, "Passed", "All pre-flight checks passed")
+	r.recordHistory(m, StepPreFlight, historyObject("StatefulSet", m.Spec.SourceNamespace, m.Spec.StatefulSetName),
+		migrationv1alpha1.HistoryResultSucceeded, fmt.Sprintf("%d replicas to migrate", m.Status.TotalReplicas))
 
 	if err := r.Status().Update(ctx, m); err != nil {
 		return ctrl.Result{}, err
@@ -250,6 +511,12 @@ func (r *StatefulSetMigrationReconciler) reconcilePreFlightChecks(ctx context.Co
 // reconcileFreezingSource handles the FreezingSource phase
 func (r *StatefulSetMigrationReconciler) reconcileFreezingSource(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
+	if waiting, err := r.waitAtGate(ctx, m, migrationv1alpha1.ManualGateBeforeFreeze); waiting || err != nil {
+		return ctrl.Result{}, err
+	}
+	if waiting, err := r.waitForApproval(ctx, m); waiting || err != nil {
+		return ctrl.Result{}, err
+	}
 	logger.Info("Freezing source cluster")
 
 	sourceClient, err := r.getSourceClient(ctx, m)
@@ -266,6 +533,42 @@ func (r *StatefulSetMigrationReconciler) reconcileFreezingSource(ctx context.Con
 		return r.failMigration(ctx, m, fmt.Sprintf("Failed to get source StatefulSet: %v", err))
 	}
 
+	// Create the destination namespace if read-only mode kept pre-flight from it
+	if m.Spec.CreateDestNamespace != nil {
+		destClient, err := r.getDestClient(ctx, m)
+		if err != nil {
+			return r.failMigration(ctx, m, fmt.Sprintf("Failed to get destination client: %v", err))
+		}
+		if err := r.ensureDestNamespace(ctx, m, destClient); err != nil {
+			return r.retryOrFail(ctx, m, "Failed to create the destination namespace", err)
+		}
+	}
+
+	// Stop jobs that mount the StatefulSet's volumes (a no-op when
+	// ReplicatingResources already did, before Velero's backup)
+	if err := r.suspendSourceJobs(ctx, m, sourceClient); err != nil {
+		return r.failMigration(ctx, m, fmt.Sprintf("Failed to suspend source jobs: %v", err))
+	}
+
+	// Record the source as it was before anything is changed
+	if r.ArchiveBucket != "" {
+		if err := r.archiveSource(ctx, m, sourceClient, sourceSTS); err != nil {
+			return r.retryOrFail(ctx, m, "Failed to archive source resources", err)
+		}
+	}
+
+	// Keep a copy of the source StatefulSet to create the destination from
+	// once it is orphan-deleted
+	if err := r.saveSourceStatefulSet(ctx, m, sourceSTS); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Keep GitOps controllers from recreating the StatefulSet once it is
+	// orphan-deleted
+	if err := r.pauseGitOps(ctx, m, sourceClient, sourceSTS); err != nil {
+		return r.retryOrFail(ctx, m, "Failed to pause GitOps", err)
+	}
+
 	// Patch all PVs to Retain reclaim policy
 	preservedPVs, err := r.patchPVsToRetain(ctx, sourceClient, m.Spec.SourceNamespace, sourceSTS)
 	if err != nil {
@@ -273,17 +576,36 @@ func (r *StatefulSetMigrationReconciler) reconcileFreezingSource(ctx context.Con
 	}
 	m.Status.PreservedPVs = preservedPVs
 	logger.Info("Patched PVs to Retain", "pvs", preservedPVs)
+	r.recordHistory(m, StepRetainPVs, "", migrationv1alpha1.HistoryResultSucceeded,
+		fmt.Sprintf("%d PVs set to Retain", len(preservedPVs)))
+
+	// Start pulling the images in the destination while the pods move
+	r.startImagePrePull(ctx, m, sourceClient, sourceSTS)
+
+	// Note the pods about to lose their owner before it is deleted
+	if err := recordOrphanedPods(ctx, m, sourceClient); err != nil {
+		return r.retryOrFail(ctx, m, "Failed to list source pods", err)
+	}
 
 	// Delete the StatefulSet with orphan propagation (leaves pods running)
 	if err := r.orphanStatefulSet(ctx, sourceClient, m.Spec.SourceNamespace, m.Spec.StatefulSetName); err != nil {
 		return r.failMigration(ctx, m, fmt.Sprintf("Failed to orphan StatefulSet: %v", err))
 	}
 	logger.Info("Orphaned StatefulSet")
+	r.recordHistory(m, StepOrphanSTS, historyObject("StatefulSet", m.Spec.SourceNamespace, m.Spec.StatefulSetName),
+		migrationv1alpha1.HistoryResultSucceeded, "Deleted with orphan propagation")
 
 	// Move to MigratingPods phase
+	now := metav1.NewTime(r.clock().Now())
+	m.Status.FrozenTime = &now
 	m.Status.Phase = migrationv1alpha1.PhaseMigratingPods
 	m.Status.CurrentIndex = 0
-	r.setCondition(m, "SourceFrozen", metav1.ConditionTrue, "Frozen", "Source cluster prepared for migration")
+	setProgress(m)
+	message := "Source cluster prepared for migration"
+	if m.Spec.FreezeSettleDelay != nil && m.Spec.FreezeSettleDelay.Duration > 0 {
+		message += fmt.Sprintf("; first pod moves after %s", m.Spec.FreezeSettleDelay.Duration)
+	}
+	r.setCondition(m, "SourceFrozen", metav1.ConditionTrue, "Frozen", message)
 
 	if err := r.Status().Update(ctx, m); err != nil {
 		return ctrl.Result{}, err
@@ -300,22 +622,90 @@ func (r *StatefulSetMigrationReconciler) reconcileMigratingPods(ctx context.Cont
 		// All pods migrated, move to finalizing
 		logger.Info("All pods migrated, moving to Finalizing")
 		m.Status.Phase = migrationv1alpha1.PhaseFinalizing
+		r.recordHistory(m, StepPhase, "", migrationv1alpha1.HistoryResultSucceeded, "All pods migrated")
 		if err := r.Status().Update(ctx, m); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	index := m.Status.CurrentIndex
-	logger.Info("Migrating pod", "index", index)
+	// A source pod standing in for one already moved would share its volume
+	// with the destination, so nothing else moves until an operator looks
+	if len(m.Status.MigratedPods) > 0 {
+		sourceClient, err := r.getSourceClient(ctx, m)
+		if err != nil {
+			return r.failMigration(ctx, m, fmt.Sprintf("Failed to get source client: %v", err))
+		}
+		found, err := r.checkDualRunning(ctx, m, sourceClient)
+		if err != nil {
+			return r.failMigration(ctx, m, fmt.Sprintf("Failed to check for recreated source pods: %v", err))
+		}
+		if len(found) > 0 {
+			return r.failMigration(ctx, m, dualRunningFailure(found))
+		}
+	}
+
+	// A pod the destination could not schedule holds the migration until it is
+	if m.Status.CapacityWait != nil {
+		if result, waiting, err := r.waitForCapacity(ctx, m); waiting || err != nil {
+			return result, err
+		}
+	}
 
-	// Migrate the current pod
-	if err := r.migratePod(ctx, m, index); err != nil {
-		return r.failMigration(ctx, m, fmt.Sprintf("Failed to migrate pod %d: %v", index, err))
+	positions := nextPositions(m)
+	if positions[0] == 0 {
+		if waiting, err := r.waitAtGate(ctx, m, migrationv1alpha1.ManualGateAfterFreeze); waiting || err != nil {
+			return ctrl.Result{}, err
+		}
+		if remaining := r.freezeSettleRemaining(m); remaining > 0 {
+			logger.Info("Waiting for the source to settle before the first pod moves", "remaining", remaining)
+			return ctrl.Result{RequeueAfter: remaining}, nil
+		}
+	}
+	ordinals := make([]int, len(positions))
+	for i, position := range positions {
+		ordinals[i] = ordinalAt(m, position)
+	}
+	if m.Spec.Schedule != nil || m.Annotations[AnnotationWindowEnd] != "" {
+		sourceClient, err := r.getSourceClient(ctx, m)
+		if err != nil {
+			return r.failMigration(ctx, m, fmt.Sprintf("Failed to get source client: %v", err))
+		}
+		if held, err := r.holdForWindow(ctx, m, sourceClient, positions); held || err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	logger.Info("Migrating pods", "indexes", ordinals, "positions", positions)
+	reason := fmt.Sprintf("Failed to migrate pod %d", ordinals[0])
+	if len(ordinals) > 1 {
+		reason = fmt.Sprintf("Failed to migrate pods %v", ordinals)
+	}
+
+	// Migrate the next pods
+	if err := r.migratePods(ctx, m, positions); err != nil {
+		var conflict *DestinationConflictError
+		if errors.As(err, &conflict) {
+			r.setCondition(m, ConditionDestinationConflict, metav1.ConditionTrue, conflict.Reason,
+				fmt.Sprintf("%s; restore it and set %s=true to resume", conflict.Message, AnnotationRetry))
+		}
+		var unschedulable *UnschedulableError
+		if m.Spec.CapacityWait != nil && errors.As(err, &unschedulable) {
+			return r.startCapacityWait(ctx, m, unschedulable)
+		}
+		return r.retryOrFail(ctx, m, reason, err)
+	}
+	if meta.IsStatusConditionTrue(m.Status.Conditions, ConditionDestinationConflict) {
+		r.setCondition(m, ConditionDestinationConflict, metav1.ConditionFalse, "Resolved", "Destination StatefulSet scaled as expected")
 	}
 
-	// Update status
-	m.Status.CurrentIndex = index + 1
+	// Update status, moving on to Finalizing with the same write after the last pod
+	m.Status.CurrentIndex = positions[len(positions)-1] + 1
+	setProgress(m)
+	if m.Status.CurrentIndex >= m.Status.TotalReplicas {
+		logger.Info("All pods migrated, moving to Finalizing")
+		m.Status.Phase = migrationv1alpha1.PhaseFinalizing
+		r.recordHistory(m, StepPhase, "", migrationv1alpha1.HistoryResultSucceeded, "All pods migrated")
+	}
 	if err := r.Status().Update(ctx, m); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -324,50 +714,116 @@ func (r *StatefulSetMigrationReconciler) reconcileMigratingPods(ctx context.Cont
 	return ctrl.Result{Requeue: true}, nil
 }
 
-// migratePod migrates a single pod from source to destination
-func (r *StatefulSetMigrationReconciler) migratePod(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, index int) error {
+// freezeSettleRemaining returns how much of spec.freezeSettleDelay is left
+// since the source StatefulSet was orphaned
+func (r *StatefulSetMigrationReconciler) freezeSettleRemaining(m *migrationv1alpha1.StatefulSetMigration) time.Duration {
+	if m.Spec.FreezeSettleDelay == nil || m.Status.FrozenTime == nil {
+		return 0
+	}
+	return m.Status.FrozenTime.Add(m.Spec.FreezeSettleDelay.Duration).Sub(r.clock().Now())
+}
+
+// stopSourcePod deletes the source pod of a move, after quiescing it. A pod
+// an earlier attempt already moved is recorded as it is instead: moving it
+// again would wait for a detach that never comes.
+func (r *StatefulSetMigrationReconciler) stopSourcePod(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceClient, destClient *multicluster.ClusterClient, mv *podMove) error {
 	logger := log.FromContext(ctx)
 
-	sourceClient, err := r.getSourceClient(ctx, m)
+	migrated, err := findMigratedPod(ctx, m, sourceClient, destClient, mv.index)
 	if err != nil {
-		return fmt.Errorf("failed to get source client: %w", err)
+		return err
+	}
+	if migrated != nil {
+		r.adoptMigratedPod(ctx, m, migrated)
+		mv.done = true
+		return nil
 	}
 
-	destClient, err := r.getDestClient(ctx, m)
-	if err != nil {
-		return fmt.Errorf("failed to get destination client: %w", err)
+	// A PVC without a PV has no volume to move
+	if provisioned, err := r.provisionUnboundReplica(ctx, m, sourceClient, mv); provisioned || err != nil {
+		return err
 	}
 
-	podName := fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, index)
+	// Deleting the pod detaches its volume, which must not overlap a ModifyVolume
+	// or an expansion whose filesystem resize the kubelet has yet to finish
+	if mv.volumeID, err = replicaVolumeID(ctx, m, sourceClient, mv.index); err != nil {
+		return err
+	}
+	if err := r.waitForVolumeExpansion(ctx, m, sourceClient, mv); err != nil {
+		return err
+	}
+	if err := r.waitForVolumeModification(ctx, m, mv.volumeID); err != nil {
+		return err
+	}
+
+	// A volume another controller instance holds fails the move while the
+	// pod still runs, before any downtime
+	if r.VolumeLockID != "" {
+		if err := r.ebs(m).AcquireVolumeLock(ctx, mv.volumeID, aws.VolumeLockConfig{
+			Owner: r.volumeLockOwner(m),
+			TTL:   r.VolumeLockTTL,
+		}); err != nil {
+			return fmt.Errorf("failed to lock volume %s: %w", mv.volumeID, err)
+		}
+		r.recordHistory(m, StepLockVolume, mv.volumeID, migrationv1alpha1.HistoryResultSucceeded, "")
+	}
 
 	// Step 1: Delete the pod in source cluster
-	logger.Info("Deleting source pod", "pod", podName)
+	logger.Info("Deleting source pod", "pod", mv.podName)
 	pod := &corev1.Pod{}
 	err = sourceClient.Client.Get(ctx, types.NamespacedName{
 		Namespace: m.Spec.SourceNamespace,
-		Name:      podName,
+		Name:      mv.podName,
 	}, pod)
-	if err == nil {
-		if err := sourceClient.Client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to delete source pod: %w", err)
-		}
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get source pod: %w", err)
+	}
+
+	// The instance the volume leaves is only known while the pod runs
+	mv.sourceInstanceID = attachedInstance(ctx, r.ebs(m), mv.volumeID)
+
+	// Downtime begins once the application is asked to quiesce
+	now := metav1.NewTime(r.clock().Now())
+	mv.stoppedAt = &now
+	if err := r.quiescePod(ctx, m, sourceClient, pod); err != nil {
+		return err
+	}
+	if err := sourceClient.Client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete source pod: %w", err)
+	}
+	mv.deleted = true
+	return nil
+}
+
+// moveVolume waits for the source pod to be gone and its volume detached,
+// then creates the PV and PVC for the volume in the destination
+func (r *StatefulSetMigrationReconciler) moveVolume(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceClient, destClient *multicluster.ClusterClient, mv *podMove) error {
+	logger := log.FromContext(ctx)
+	volumeID := mv.volumeID
+	if mv.provision {
+		return nil
+	}
+
+	if mv.deleted {
 		// Wait for pod to be gone
-		if err := r.waitForPodDeletion(ctx, sourceClient, m.Spec.SourceNamespace, podName); err != nil {
+		if err := r.waitForPodDeletion(ctx, sourceClient, m.Spec.SourceNamespace, mv.podName); err != nil {
 			return fmt.Errorf("failed waiting for pod deletion: %w", err)
 		}
+		r.recordHistory(m, StepDeletePod, historyObject("Pod", m.Spec.SourceNamespace, mv.podName),
+			migrationv1alpha1.HistoryResultSucceeded, "")
 	}
+	forgetOrphanedPod(m, mv.podName)
 
 	// Step 2: Get source PVC and PV
-	// For now, assume a single volume claim template named "data"
-	// TODO: Support multiple volume claim templates
-	pvcName := migration.GetPVCNameForStatefulSetPod("data", m.Spec.StatefulSetName, index)
-
 	sourcePVC := &corev1.PersistentVolumeClaim{}
 	if err := sourceClient.Client.Get(ctx, types.NamespacedName{
 		Namespace: m.Spec.SourceNamespace,
-		Name:      pvcName,
+		Name:      mv.pvcName,
 	}, sourcePVC); err != nil {
-		return fmt.Errorf("failed to get source PVC %s: %w", pvcName, err)
+		return fmt.Errorf("failed to get source PVC %s: %w", mv.pvcName, err)
 	}
 
 	sourcePV := &corev1.PersistentVolume{}
@@ -376,171 +832,535 @@ func (r *StatefulSetMigrationReconciler) migratePod(ctx context.Context, m *migr
 	}, sourcePV); err != nil {
 		return fmt.Errorf("failed to get source PV: %w", err)
 	}
+	mv.sourcePVC, mv.sourcePV = sourcePVC, sourcePV
 
-	// Step 3: Extract volume ID and wait for detachment
-	volumeID, err := getVolumeIDFromPV(sourcePV)
-	if err != nil {
-		return fmt.Errorf("failed to get volume ID: %w", err)
+	// Step 3: Wait for detachment. With spec.volumeMigrations an owned
+	// VolumeMigration moves the volume
+	if m.Spec.VolumeMigrations {
+		return r.moveVolumeByChild(ctx, m, destClient, mv)
 	}
 
-	logger.Info("Waiting for volume detachment", "volumeId", volumeID)
-	timeout := DefaultVolumeDetachTimeout
-	if m.Spec.VolumeDetachTimeout != nil {
-		timeout = m.Spec.VolumeDetachTimeout.Duration
+	// With spec.strategyFallback, a volume that cannot be reattached is
+	// restored from a snapshot instead. A volume restored in another zone
+	// still detaches first, so the kubelet has flushed it; one whose detach
+	// is blocked is snapshotted where it is attached.
+	fallback := volumeFallback(m, volumeID)
+	if fallback == nil || fallback.Trigger != migrationv1alpha1.FallbackTriggerDetachBlocked {
+		if err := r.detachSourceVolume(ctx, m, sourceClient, mv.claimTemplate, sourcePV.Name, volumeID); err != nil {
+			if !detachBlocked(err) || !fallsBackOn(m, migrationv1alpha1.FallbackTriggerDetachBlocked) {
+				return err
+			}
+			logger.Info("Falling back to snapshot and restore", "volumeId", volumeID, "reason", err.Error())
+			if fallback == nil {
+				fallback = r.addVolumeFallback(m, volumeID, migrationv1alpha1.FallbackTriggerDetachBlocked,
+					translate.AvailabilityZone(sourcePV), err.Error())
+			}
+		}
 	}
 
-	if err := r.EBSClient.WaitForVolumeDetach(ctx, volumeID, aws.WaitForVolumeDetachConfig{
-		Timeout:      timeout,
-		PollInterval: 5 * time.Second,
-		OnPoll: func(info *aws.VolumeInfo) {
-			logger.Info("Volume status", "volumeId", volumeID, "state", aws.VolumeStateString(info.State))
-		},
-	}); err != nil {
-		return fmt.Errorf("volume detachment failed: %w", err)
+	// A volume on an Outpost must keep attaching to nodes on that Outpost, and
+	// one expanded beyond its PV's capacity keeps its whole size
+	info, err := r.ebs(m).GetVolumeInfo(ctx, volumeID)
+	if err != nil {
+		return err
 	}
 
+	// With spec.destAWS.transferVolumes the destination gets a copy of the
+	// volume in its own account instead of the volume itself
+	mv.destVolumeID = volumeID
+	switch {
+	case fallback != nil:
+		if mv.destVolumeID, err = r.restoreVolume(ctx, m, mv.index, fallback); err != nil {
+			return fmt.Errorf("volume restore failed: %w", err)
+		}
+	case transferVolumes(m):
+		if mv.destVolumeID, err = r.transferVolume(ctx, m, mv.index, volumeID); err != nil {
+			return fmt.Errorf("volume transfer failed: %w", err)
+		}
+	}
+	destVolumeID := mv.destVolumeID
+
 	// Step 4: Create PV and PVC in destination
+	pvcName := mv.pvcName
 	logger.Info("Creating PV/PVC in destination", "pvc", pvcName)
 
-	result, err := migration.TranslatePV(sourcePV, sourcePVC, migration.PVTranslationConfig{
-		DestNamespace:        m.Spec.DestNamespace,
-		DestPVCName:          pvcName,
-		StorageClassMapping:  m.Spec.StorageClassMapping,
-		PreserveNodeAffinity: true,
-	})
+	cfg := translationConfig(m, pvcName)
+	if destVolumeID != volumeID {
+		cfg.VolumeID = destVolumeID
+	}
+	cfg.OutpostID = aws.OutpostID(info.OutpostARN)
+	cfg.VolumeSizeGiB = info.Size
+	if fallback != nil {
+		cfg.AvailabilityZone = fallback.Zone
+	}
+	cfg.ExistingPVC, err = destPVCToAdopt(ctx, m, destClient, pvcName)
+	if err != nil {
+		return err
+	}
+	result, err := translate.TranslatePV(sourcePV, sourcePVC, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to translate PV/PVC: %w", err)
 	}
+	for _, warning := range result.Warnings {
+		logger.Info("PV translation warning", "pvc", pvcName, "warning", warning)
+	}
+	mv.result = result
+
+	// Reuse a PV left in the destination by an earlier attempt instead of
+	// creating a second PV for the same disk
+	existingPV, err := findExistingDestPV(ctx, destClient, destVolumeID, m.Spec.DestNamespace, pvcName)
+	if err != nil {
+		return err
+	}
+	if existingPV != nil {
+		result.PVC.Spec.VolumeName = existingPV.Name
+	}
+	mv.existingPV = existingPV
+
+	// With spec.strictClaimRef the PVC is created first, so the PV carries
+	// its UID from the start and no other PVC of that name can claim it. The
+	// PVC stays Pending until the PV it names exists.
+	claimUID := result.PV.Spec.ClaimRef.UID
+	strict := m.Spec.StrictClaimRef && !result.PVCAdopted
+	if strict {
+		if claimUID, err = createStrictDestPVC(ctx, destClient, result.PVC, destVolumeID); err != nil {
+			return err
+		}
+		r.recordHistory(m, StepCreatePVC, historyObject("PersistentVolumeClaim", m.Spec.DestNamespace, pvcName),
+			migrationv1alpha1.HistoryResultSucceeded, fmt.Sprintf("Created before its PV; claimRef UID %s", claimUID))
+	}
 
-	// Create PV first
-	if err := destClient.Client.Create(ctx, result.PV); err != nil && !apierrors.IsAlreadyExists(err) {
-		return fmt.Errorf("failed to create destination PV: %w", err)
+	if existingPV != nil {
+		logger.Info("Adopting existing destination PV", "pv", existingPV.Name, "volumeId", destVolumeID)
+		if err := bindExistingDestPV(ctx, destClient, existingPV, m.Spec.DestNamespace, pvcName, claimUID); err != nil {
+			return err
+		}
+		r.recordHistory(m, StepAdoptPV, historyObject("PersistentVolume", "", existingPV.Name),
+			migrationv1alpha1.HistoryResultSucceeded, fmt.Sprintf("Existing PV already references %s", destVolumeID))
+	} else {
+		// Create PV first
+		result.PV.Spec.ClaimRef.UID = claimUID
+		if err := destClient.Client.Create(ctx, result.PV); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create destination PV: %w", err)
+		}
+		r.recordHistory(m, StepCreatePV, historyObject("PersistentVolume", "", result.PV.Name),
+			migrationv1alpha1.HistoryResultSucceeded, "")
+	}
+
+	// Create PVC, unless one created ahead of the migration is adopted or
+	// strict binding created it already
+	switch {
+	case result.PVCAdopted:
+		r.recordHistory(m, StepAdoptPVC, historyObject("PersistentVolumeClaim", m.Spec.DestNamespace, pvcName),
+			migrationv1alpha1.HistoryResultSucceeded, fmt.Sprintf("PV pre-bound to existing PVC %s", result.PVC.UID))
+	case !strict:
+		if err := destClient.Client.Create(ctx, result.PVC); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create destination PVC: %w", err)
+		}
+		r.recordHistory(m, StepCreatePVC, historyObject("PersistentVolumeClaim", m.Spec.DestNamespace, pvcName),
+			migrationv1alpha1.HistoryResultSucceeded, "")
+	}
+	return nil
+}
+
+// detachSourceVolume waits for the source cluster to unmount a volume and
+// for EBS to detach it, within the detach timeout of its claim template
+func (r *StatefulSetMigrationReconciler) detachSourceVolume(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceClient *multicluster.ClusterClient, claimTemplate, pvName, volumeID string) error {
+	logger := log.FromContext(ctx)
+
+	// The kubelet must unmount the volume before it can detach; a node that
+	// cannot is caught here instead of surfacing as a detach timeout
+	detachStart := r.clock().Now()
+	if err := r.waitForSourceUnmount(ctx, m, sourceClient, claimTemplate, pvName, volumeID); err != nil {
+		return fmt.Errorf("volume unmount failed: %w", err)
+	}
+
+	logger.Info("Waiting for volume detachment", "volumeId", volumeID)
+	doneWaiting := metrics.TrackWait(StepDetachVolume)
+	r.beginVolumeWait(ctx, m, volumeID, StepDetachVolume, migrationv1alpha1.VolumeWaitSourceAWS, "detaching")
+	progress, err := r.ebs(m).WaitForVolumeDetach(ctx, volumeID, aws.WaitForVolumeDetachConfig{
+		Timeout: volumeDetachTimeout(m, claimTemplate) - r.clock().Since(detachStart),
+		OnPoll: func(info *aws.VolumeInfo, progress aws.DetachProgress) {
+			logger.Info("Volume status", "volumeId", volumeID, "state", aws.VolumeStateString(info.State),
+				"phase", progress.Phase, "inPhase", progress.InPhase().Round(time.Second).String())
+			r.probeVolumeWait(ctx, m, volumeID, fmt.Sprintf("%s, %s for %s", aws.VolumeStateString(info.State),
+				progress.Phase, progress.InPhase().Round(time.Second)))
+		},
+		ForceDetach: m.Spec.ForceDetach,
+		OnForceDetach: func(instance aws.InstanceHealth) {
+			logger.Info("Force-detaching volume from unavailable instance", "volumeId", volumeID,
+				"instanceId", instance.InstanceID, "instanceState", instance.String())
+			r.recordHistory(m, StepForceDetach, volumeID, migrationv1alpha1.HistoryResultStarted,
+				fmt.Sprintf("Instance %s is %s", instance.InstanceID, instance))
+		},
+	})
+	doneWaiting()
+	endVolumeWait(m, volumeID)
+	if err != nil {
+		return fmt.Errorf("volume detachment failed: %w", err)
 	}
+	metrics.ObserveVolumeDetach(r.clock().Since(detachStart))
+	logger.Info("Volume detached", "volumeId", volumeID, "polls", progress.Polls, "waited", progress.Elapsed().Round(time.Second).String())
+	r.recordHistory(m, StepDetachVolume, volumeID, migrationv1alpha1.HistoryResultSucceeded,
+		fmt.Sprintf("%d polls over %s", progress.Polls, progress.Elapsed().Round(time.Second)))
+	return nil
+}
 
-	// Create PVC
-	if err := destClient.Client.Create(ctx, result.PVC); err != nil && !apierrors.IsAlreadyExists(err) {
-		return fmt.Errorf("failed to create destination PVC: %w", err)
+// finishPod waits for the moved pod to be Ready in the destination and
+// records it as migrated
+func (r *StatefulSetMigrationReconciler) finishPod(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destClient *multicluster.ClusterClient, mv *podMove) error {
+	logger := log.FromContext(ctx)
+	volumeID, destVolumeID := mv.volumeID, mv.destVolumeID
+	if mv.provision {
+		return r.finishProvisionedPod(ctx, m, destClient, mv)
 	}
 
-	// Step 5: Create or scale StatefulSet in destination
-	if index == 0 {
-		// First pod - create the StatefulSet
-		logger.Info("Creating StatefulSet in destination")
-		if err := r.createDestinationStatefulSet(ctx, sourceClient, destClient, m); err != nil {
-			return fmt.Errorf("failed to create destination StatefulSet: %w", err)
+	if volumesOnly(m) {
+		// Step 6: Wait for the PVC to bind; the user starts the pod
+		logger.Info("Waiting for PVC to be bound in destination", "pvc", mv.pvcName)
+		if err := r.waitForPVCBound(ctx, destClient, m.Spec.DestNamespace, mv.pvcName, DefaultPVCBoundTimeout); err != nil {
+			return fmt.Errorf("destination PVC not bound: %w", err)
 		}
+		r.recordHistory(m, StepPVCBound, historyObject("PersistentVolumeClaim", m.Spec.DestNamespace, mv.pvcName),
+			migrationv1alpha1.HistoryResultSucceeded, "")
 	} else {
-		// Subsequent pods - scale up the StatefulSet
-		logger.Info("Scaling StatefulSet in destination", "replicas", index+1)
-		if err := r.scaleDestinationStatefulSet(ctx, destClient, m, int32(index+1)); err != nil {
-			return fmt.Errorf("failed to scale destination StatefulSet: %w", err)
+		// Step 6: Wait for pod to be ready in destination
+		if err := r.waitForDestPod(ctx, m, destClient, mv.podName); err != nil {
+			return err
 		}
 	}
 
-	// Step 6: Wait for pod to be ready in destination
-	logger.Info("Waiting for pod to be ready in destination", "pod", podName)
-	timeout = DefaultPodReadyTimeout
-	if m.Spec.PodReadyTimeout != nil {
-		timeout = m.Spec.PodReadyTimeout.Duration
+	// The volume is now attached in the destination, or bound and left to
+	// the user in VolumesOnly mode; the lock has done its job
+	if r.VolumeLockID != "" {
+		if err := r.ebs(m).ReleaseVolumeLock(ctx, volumeID, r.volumeLockOwner(m)); err != nil {
+			logger.Error(err, "Failed to release volume lock", "volumeId", volumeID)
+		}
 	}
 
-	if err := r.waitForPodReady(ctx, destClient, m.Spec.DestNamespace, podName, timeout); err != nil {
-		return fmt.Errorf("destination pod not ready: %w", err)
+	// Hand the volume over to the destination team's backup policies; a
+	// transferred or restored volume was created with its new tags
+	if destVolumeID == volumeID {
+		r.retagVolume(ctx, m, volumeID)
 	}
 
 	// Record successful migration
-	m.Status.MigratedPods = append(m.Status.MigratedPods, migrationv1alpha1.MigratedPodInfo{
-		Index:      index,
-		PodName:    podName,
-		VolumeID:   volumeID,
-		MigratedAt: metav1.Now(),
-	})
+	destPV := mv.result.PV
+	if mv.existingPV != nil {
+		destPV = mv.existingPV
+	}
+	migrated := &migrationv1alpha1.MigratedPodInfo{
+		Index:            mv.index,
+		PodName:          mv.podName,
+		VolumeID:         destVolumeID,
+		SourceInstanceID: mv.sourceInstanceID,
+		MigratedAt:       metav1.NewTime(r.clock().Now()),
+		StoppedAt:        mv.stoppedAt,
+		StorageBytes:     pvStorageBytes(destPV),
+	}
+	if !volumesOnly(m) {
+		migrated.DestInstanceID = r.destInstance(ctx, m, destVolumeID)
+	}
+	if destVolumeID != volumeID {
+		migrated.SourceVolumeID = volumeID
+	}
+	recordMigratedPod(m, *migrated)
+
+	if r.ArchiveBucket != "" {
+		r.archiveCheckpoint(ctx, m, podCheckpoint{
+			Pod:       *migrated,
+			SourcePVC: mv.sourcePVC.DeepCopy(),
+			SourcePV:  mv.sourcePV.DeepCopy(),
+			DestPVC:   mv.result.PVC.DeepCopy(),
+			DestPV:    destPV.DeepCopy(),
+		})
+	}
 
-	logger.Info("Pod migrated successfully", "pod", podName)
+	logger.Info("Pod migrated successfully", "pod", mv.podName)
 	return nil
 }
 
+// waitForDestPod waits for a migrated pod to be Ready in the destination,
+// then points its DNS records there with spec.dnsCutover and checks the
+// source can reach it with spec.connectivityProbe
+func (r *StatefulSetMigrationReconciler) waitForDestPod(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destClient *multicluster.ClusterClient, podName string) error {
+	log.FromContext(ctx).Info("Waiting for pod to be ready in destination", "pod", podName)
+	timeout := DefaultPodReadyTimeout
+	if m.Spec.PodReadyTimeout != nil {
+		timeout = m.Spec.PodReadyTimeout.Duration
+	}
+
+	if err := r.waitForPodReady(ctx, destClient, m.Spec.DestNamespace, podName, timeout); err != nil {
+		return fmt.Errorf("destination pod not ready: %w", unschedulablePod(ctx, destClient, m.Spec.DestNamespace, podName, err))
+	}
+	r.recordHistory(m, StepPodReady, historyObject("Pod", m.Spec.DestNamespace, podName),
+		migrationv1alpha1.HistoryResultSucceeded, "")
+	if m.Spec.DNSCutover != nil {
+		r.cutoverPodDNS(ctx, m, destClient, podName)
+	}
+	if err := r.probeConnectivity(ctx, m, destClient, podName); err != nil {
+		return err
+	}
+	return r.recordDestRevision(ctx, destClient, m)
+}
+
 // reconcileFinalizing handles the Finalizing phase
 func (r *StatefulSetMigrationReconciler) reconcileFinalizing(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
+	if waiting, err := r.waitAtGate(ctx, m, migrationv1alpha1.ManualGateBeforeFinalize); waiting || err != nil {
+		return ctrl.Result{}, err
+	}
 	logger.Info("Finalizing migration")
 
+	// Every pod has moved, so its images are no longer needed ahead of it
+	r.stopImagePrePull(ctx, m)
+
 	sourceClient, err := r.getSourceClient(ctx, m)
 	if err != nil {
 		return r.failMigration(ctx, m, fmt.Sprintf("Failed to get source client: %v", err))
 	}
 
-	// Clean up source PVCs and PVs
-	// Note: Because we set ReclaimPolicy to Retain, this deletes the K8s objects
-	// but leaves the EBS volumes intact (they're now used by destination cluster)
-	for i := 0; i < m.Status.TotalReplicas; i++ {
-		pvcName := migration.GetPVCNameForStatefulSetPod("data", m.Spec.StatefulSetName, i)
+	// Nothing in the source is cleaned up or resumed while a source pod
+	// stands in for a moved one
+	found, err := r.checkDualRunning(ctx, m, sourceClient)
+	if err != nil {
+		return r.failMigration(ctx, m, fmt.Sprintf("Failed to check for recreated source pods: %v", err))
+	}
+	if len(found) > 0 {
+		return r.failMigration(ctx, m, dualRunningFailure(found))
+	}
 
-		// Delete PVC
-		pvc := &corev1.PersistentVolumeClaim{}
-		err := sourceClient.Client.Get(ctx, types.NamespacedName{
-			Namespace: m.Spec.SourceNamespace,
-			Name:      pvcName,
-		}, pvc)
-		if err == nil {
-			if err := sourceClient.Client.Delete(ctx, pvc); err != nil && !apierrors.IsNotFound(err) {
-				logger.Error(err, "Failed to delete source PVC", "pvc", pvcName)
-			}
+	// Resume the source's jobs in the destination, now that every PVC is
+	// there, and recreate the HPAs and monitoring that go with the
+	// StatefulSet; with failed pods neither is, and the jobs stay suspended
+	if (len(m.Status.Jobs) > 0 || migrateCompanions(m)) && len(m.Status.FailedPods) == 0 {
+		destClient, err := r.getDestClient(ctx, m)
+		if err != nil {
+			return r.failMigration(ctx, m, fmt.Sprintf("Failed to get destination client: %v", err))
+		}
+		r.recreateJobs(ctx, m, sourceClient, destClient)
+		if migrateCompanions(m) {
+			r.recreateCompanions(ctx, m, sourceClient, destClient)
 		}
 	}
 
-	// Delete source PVs
-	for _, pvName := range m.Status.PreservedPVs {
-		pv := &corev1.PersistentVolume{}
-		err := sourceClient.Client.Get(ctx, types.NamespacedName{Name: pvName}, pv)
-		if err == nil {
-			if err := sourceClient.Client.Delete(ctx, pv); err != nil && !apierrors.IsNotFound(err) {
-				logger.Error(err, "Failed to delete source PV", "pv", pvName)
-			}
+	// Undo what scaling changed on the destination StatefulSet. It has every
+	// pod, so failing to does not undo the migration, and a retry runs it again.
+	if len(m.Status.FailedPods) == 0 {
+		if destClient, err := r.getDestClient(ctx, m); err != nil {
+			logger.Error(err, "Failed to get destination client to reconcile the StatefulSet")
+		} else if err := r.reconcileDestDrift(ctx, m, destClient); err != nil {
+			logger.Error(err, "Failed to reconcile the destination StatefulSet with the source")
+			r.recordHistory(m, StepReconcileSTS, historyObject("StatefulSet", m.Spec.DestNamespace, m.Spec.StatefulSetName),
+				migrationv1alpha1.HistoryResultFailed, err.Error())
 		}
 	}
 
+	// Clean up the source objects selected by spec.cleanup
+	summary := cleanupSource(ctx, m, sourceClient)
+
+	// The pods skipped under spec.failurePolicy still need an operator, so
+	// the migration fails once the others have moved, keeping its guard
+	if len(m.Status.FailedPods) > 0 {
+		r.recordHistory(m, StepCleanup, "", migrationv1alpha1.HistoryResultSucceeded, summary)
+		return r.failMigration(ctx, m, fmt.Sprintf("%d of %d pods failed to migrate: %s",
+			len(m.Status.FailedPods), m.Status.TotalReplicas, podList(failedPodNames(m))))
+	}
+
+	// Every pod has moved, so the destination's external-dns can take the
+	// records over
+	r.removeDNSCutover(ctx, m, sourceClient)
+
+	// The pods spec.ordinalRange leaves in the source get their StatefulSet
+	// back. Git still declares every replica, so GitOps stays paused until
+	// the migration is deleted.
+	if m.Status.SourceOrdinals != "" {
+		if err := r.recreateSourceStatefulSet(ctx, m, sourceClient); err != nil {
+			return r.failMigration(ctx, m, fmt.Sprintf("Failed to recreate the source StatefulSet: %v", err))
+		}
+	} else if err := r.resumeGitOps(ctx, m, sourceClient); err != nil {
+		// GitOps may manage the source namespace again; failing to say so
+		// does not undo the migration, and deleting it retries
+		logger.Error(err, "Failed to remove GitOps suspend annotations")
+		r.recordHistory(m, StepResumeGitOps, historyObject("Namespace", "", m.Spec.SourceNamespace),
+			migrationv1alpha1.HistoryResultFailed, err.Error())
+	}
+	r.protectDestVolumes(ctx, m)
+	r.releaseCaches(ctx, m)
+	if err := r.releaseGuard(ctx, m); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recordHistory(m, StepCleanup, "", migrationv1alpha1.HistoryResultSucceeded, summary)
+
 	// Mark as completed
 	m.Status.Phase = migrationv1alpha1.PhaseCompleted
-	now := metav1.Now()
+	now := metav1.NewTime(r.clock().Now())
 	m.Status.CompletionTime = &now
+	r.recordHistory(m, StepPhase, "", migrationv1alpha1.HistoryResultSucceeded, "Migration completed")
 	r.setCondition(m, "Complete", metav1.ConditionTrue, "Completed", "Migration completed successfully")
+	r.publishReport(ctx, m)
 
 	if err := r.Status().Update(ctx, m); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	logger.Info("Migration completed successfully")
+	if m.Spec.PostMigrationWatch != nil {
+		return ctrl.Result{Requeue: true}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
 // Helper functions
 
 func (r *StatefulSetMigrationReconciler) getSourceClient(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (*multicluster.ClusterClient, error) {
-	secretKey := m.Spec.SourceCluster.KubeConfigKey
-	if secretKey == "" {
-		secretKey = "kubeconfig"
-	}
-	return r.ClientManager.GetClientFromSecret(ctx, m.Namespace, m.Spec.SourceCluster.KubeConfigSecret, secretKey)
+	return r.ClientManager.GetClient(ctx, contextRefFor(m.Namespace, m.Spec.SourceCluster))
 }
 
 func (r *StatefulSetMigrationReconciler) getDestClient(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (*multicluster.ClusterClient, error) {
-	secretKey := m.Spec.DestCluster.KubeConfigKey
-	if secretKey == "" {
-		secretKey = "kubeconfig"
+	return r.ClientManager.GetClient(ctx, contextRefFor(m.Namespace, m.Spec.DestCluster))
+}
+
+// contextRefFor converts an API ContextRef into a multicluster ContextRef
+func contextRefFor(namespace string, ref migrationv1alpha1.ContextRef) multicluster.ContextRef {
+	cr := multicluster.ContextRef{
+		SecretNamespace: namespace,
+		SecretName:      ref.KubeConfigSecret,
+		SecretKey:       ref.KubeConfigKey,
+		Context:         ref.Context,
+	}
+	if ref.Server != "" {
+		cr.Server = ref.Server
+		if ref.TokenSecretRef != nil {
+			cr.SecretName = ref.TokenSecretRef.Name
+			cr.SecretKey = ref.TokenSecretRef.Key
+		}
+		if ref.CABundleSecretRef != nil {
+			cr.CASecretName = ref.CABundleSecretRef.Name
+			cr.CASecretKey = ref.CABundleSecretRef.Key
+		}
+	}
+	if ref.Impersonate != nil {
+		cr.ImpersonateUser = ref.Impersonate.User
+		cr.ImpersonateGroups = ref.Impersonate.Groups
+	}
+	if ref.RateLimit != nil {
+		cr.QPS = float32(ref.RateLimit.QPS)
+		cr.Burst = int(ref.RateLimit.Burst)
+	}
+	cr.UserAgent = ref.UserAgent
+	if ref.Reader != nil {
+		cr.Reader = readerRefFor(cr, ref.Reader)
+	}
+	return cr
+}
+
+// readerRefFor returns the multicluster ContextRef of a ContextRef's reader:
+// the ContextRef's cluster and rate limits, with the reader's credential
+// where it sets one and only the reader's impersonation
+func readerRefFor(cr multicluster.ContextRef, reader *migrationv1alpha1.ReaderIdentity) *multicluster.ContextRef {
+	rr := cr
+	rr.ImpersonateUser, rr.ImpersonateGroups = "", nil
+	switch {
+	case cr.Server != "" && reader.TokenSecretRef != nil:
+		rr.SecretName, rr.SecretKey = reader.TokenSecretRef.Name, reader.TokenSecretRef.Key
+	case cr.Server == "" && reader.KubeConfigSecret != "":
+		rr.SecretName, rr.SecretKey, rr.Context = reader.KubeConfigSecret, reader.KubeConfigKey, reader.Context
+	case cr.Server == "" && reader.Context != "":
+		rr.Context = reader.Context
+	}
+	if reader.Impersonate != nil {
+		rr.ImpersonateUser = reader.Impersonate.User
+		rr.ImpersonateGroups = reader.Impersonate.Groups
+	}
+	return &rr
+}
+
+// acquireCaches starts informer caches for the migration's namespaces when enabled
+func (r *StatefulSetMigrationReconciler) acquireCaches(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceClient, destClient *multicluster.ClusterClient) error {
+	if !r.UseRemoteCaches {
+		return nil
+	}
+	owner := string(m.UID)
+	if err := sourceClient.AcquireCache(ctx, owner, m.Spec.SourceNamespace); err != nil {
+		return fmt.Errorf("failed to start source cluster cache: %w", err)
+	}
+	if err := destClient.AcquireCache(ctx, owner, m.Spec.DestNamespace); err != nil {
+		return fmt.Errorf("failed to start destination cluster cache: %w", err)
+	}
+	return nil
+}
+
+// releaseCaches releases the migration's informer caches, if any
+func (r *StatefulSetMigrationReconciler) releaseCaches(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) {
+	if !r.UseRemoteCaches {
+		return
+	}
+	owner := string(m.UID)
+	if sourceClient, err := r.getSourceClient(ctx, m); err == nil {
+		sourceClient.ReleaseCache(owner)
+	}
+	if destClient, err := r.getDestClient(ctx, m); err == nil {
+		destClient.ReleaseCache(owner)
+	}
+}
+
+// volumeLockOwner returns the volume lock owner for a migration. The migration
+// UID keeps the owner stable across controller restarts.
+func (r *StatefulSetMigrationReconciler) volumeLockOwner(m *migrationv1alpha1.StatefulSetMigration) string {
+	return fmt.Sprintf("%s/%s", r.VolumeLockID, m.UID)
+}
+
+// event records an event on the migration when a recorder is configured
+func (r *StatefulSetMigrationReconciler) event(m *migrationv1alpha1.StatefulSetMigration, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(m, eventType, reason, message)
+	}
+}
+
+// pollInterval returns the wait loop interval, tightened when reads are served from a cache
+func (r *StatefulSetMigrationReconciler) pollInterval(interval time.Duration) time.Duration {
+	if r.UseRemoteCaches {
+		return CachedPollInterval
 	}
-	return r.ClientManager.GetClientFromSecret(ctx, m.Namespace, m.Spec.DestCluster.KubeConfigSecret, secretKey)
+	return interval
+}
+
+// retryOrFail requeues the migration when AWS throttled a request, since a
+// later attempt can succeed, and fails it for any other error. NotFound,
+// Unauthorized and WrongRegion errors need the spec or IAM policy fixed first.
+func (r *StatefulSetMigrationReconciler) retryOrFail(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, reason string, err error) (ctrl.Result, error) {
+	if aws.Retryable(err) {
+		log.FromContext(ctx).Info("AWS request throttled, retrying", "reason", reason, "error", err.Error())
+		return ctrl.Result{RequeueAfter: requeueDelay(DefaultRequeueDelay, m.UID)}, nil
+	}
+	return r.failMigration(ctx, m, fmt.Sprintf("%s: %v", reason, err))
 }
 
 func (r *StatefulSetMigrationReconciler) failMigration(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, reason string) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Error(nil, "Migration failed", "reason", reason)
 
+	r.releaseCaches(ctx, m)
+	r.stopImagePrePull(ctx, m)
+	r.capacity.stop(m)
+
+	// Nothing in the source has been touched before it is frozen, so a
+	// migration failing earlier does not need to keep other migrations out
+	if m.Status.Phase == migrationv1alpha1.PhasePreFlightChecks || m.Status.Phase == migrationv1alpha1.PhaseReplicatingResources {
+		if err := r.releaseGuard(ctx, m); err != nil {
+			logger.Error(err, "Failed to release guard lease")
+		}
+	}
+
 	m.Status.Phase = migrationv1alpha1.PhaseFailed
 	m.Status.LastError = reason
-	now := metav1.Now()
+	r.recordHistory(m, StepPhase, "", migrationv1alpha1.HistoryResultFailed, reason)
+	now := metav1.NewTime(r.clock().Now())
 	m.Status.CompletionTime = &now
 	r.setCondition(m, "Failed", metav1.ConditionTrue, "Failed", reason)
+	r.setOrphanedPodsCondition(m)
+	r.publishReport(ctx, m)
 
 	if err := r.Status().Update(ctx, m); err != nil {
 		return ctrl.Result{}, err
@@ -549,23 +1369,18 @@ func (r *StatefulSetMigrationReconciler) failMigration(ctx context.Context, m *m
 	return ctrl.Result{}, nil
 }
 
+// setCondition adds or updates a condition. LastTransitionTime only changes
+// when the condition's status does, so tools watching transitions do not see
+// a new time on every status update.
 func (r *StatefulSetMigrationReconciler) setCondition(m *migrationv1alpha1.StatefulSetMigration, condType string, status metav1.ConditionStatus, reason, message string) {
-	condition := metav1.Condition{
+	meta.SetStatusCondition(&m.Status.Conditions, metav1.Condition{
 		Type:               condType,
 		Status:             status,
 		Reason:             reason,
 		Message:            message,
-		LastTransitionTime: metav1.Now(),
-	}
-
-	// Update or append condition
-	for i, c := range m.Status.Conditions {
-		if c.Type == condType {
-			m.Status.Conditions[i] = condition
-			return
-		}
-	}
-	m.Status.Conditions = append(m.Status.Conditions, condition)
+		ObservedGeneration: m.Generation,
+		LastTransitionTime: metav1.NewTime(r.clock().Now()),
+	})
 }
 
 func (r *StatefulSetMigrationReconciler) patchPVsToRetain(ctx context.Context, cc *multicluster.ClusterClient, namespace string, sts *appsv1.StatefulSet) ([]string, error) {
@@ -621,19 +1436,24 @@ func (r *StatefulSetMigrationReconciler) orphanStatefulSet(ctx context.Context,
 }
 
 func (r *StatefulSetMigrationReconciler) waitForPodDeletion(ctx context.Context, cc *multicluster.ClusterClient, namespace, name string) error {
-	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
-	defer cancel()
+	defer metrics.TrackWait(StepDeletePod)()
+
+	timeout := r.clock().NewTimer(2 * time.Minute)
+	defer timeout.Stop()
 
-	ticker := time.NewTicker(2 * time.Second)
+	reader := cc.Reader(namespace)
+	ticker := r.clock().NewTicker(r.pollInterval(2 * time.Second))
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-ticker.C:
+		case <-timeout.C():
+			return fmt.Errorf("timeout waiting for pod %s to be deleted", name)
+		case <-ticker.C():
 			pod := &corev1.Pod{}
-			err := cc.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pod)
+			err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pod)
 			if apierrors.IsNotFound(err) {
 				return nil
 			}
@@ -646,51 +1466,65 @@ func (r *StatefulSetMigrationReconciler) waitForPodDeletion(ctx context.Context,
 }
 
 func (r *StatefulSetMigrationReconciler) waitForPodReady(ctx context.Context, cc *multicluster.ClusterClient, namespace, name string, timeout time.Duration) error {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+	defer metrics.TrackWait(StepPodReady)()
+
+	deadline := r.clock().NewTimer(timeout)
+	defer deadline.Stop()
 
-	ticker := time.NewTicker(5 * time.Second)
+	// Without an informer cache, a watch on the pod is both faster and
+	// cheaper than polling it
+	if cc.Clientset != nil && !r.UseRemoteCaches {
+		err := r.watchPodReady(ctx, cc.Clientset, namespace, name, deadline.C())
+		if !errors.Is(err, errPodWatchUnavailable) {
+			return err
+		}
+		log.FromContext(ctx).Info("Polling pod readiness instead of watching", "pod", name, "reason", err.Error())
+	}
+
+	reader := cc.Reader(namespace)
+	ticker := r.clock().NewTicker(r.pollInterval(5 * time.Second))
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C():
 			return fmt.Errorf("timeout waiting for pod %s to be ready", name)
-		case <-ticker.C:
+		case <-ticker.C():
 			pod := &corev1.Pod{}
-			if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pod); err != nil {
+			if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pod); err != nil {
 				continue // Pod might not exist yet
 			}
 
-			// Check if pod is ready
-			for _, cond := range pod.Status.Conditions {
-				if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
-					return nil
-				}
+			if podReady(pod) {
+				return nil
 			}
 		}
 	}
 }
 
-func (r *StatefulSetMigrationReconciler) createDestinationStatefulSet(ctx context.Context, sourceCC, destCC *multicluster.ClusterClient, m *migrationv1alpha1.StatefulSetMigration) error {
-	// Get source StatefulSet as template
-	// Note: The STS was deleted with orphan propagation, so we need to reconstruct it
-	// In practice, you might want to store the STS spec in the migration status before deletion
-
-	// For now, we'll create a minimal STS that matches the source
-	// This is a simplified version - in production you'd want to copy more fields
-	sourceSTS := &appsv1.StatefulSet{}
-	// Try to get it (might still exist briefly after orphan delete)
-	err := sourceCC.Client.Get(ctx, types.NamespacedName{
-		Namespace: m.Spec.SourceNamespace,
-		Name:      m.Spec.StatefulSetName,
-	}, sourceSTS)
-
+func (r *StatefulSetMigrationReconciler) createDestinationStatefulSet(ctx context.Context, sourceCC, destCC *multicluster.ClusterClient, m *migrationv1alpha1.StatefulSetMigration, replicas int32) error {
+	// The source StatefulSet was orphan-deleted when it was frozen, so it is
+	// copied from what saveSourceStatefulSet kept, or the source itself while
+	// its deletion is still pending
+	sourceSTS, err := r.savedSourceStatefulSet(ctx, m)
 	if err != nil {
-		return fmt.Errorf("source StatefulSet no longer available for copying spec: %w", err)
+		return err
+	}
+	if sourceSTS == nil {
+		sourceSTS = &appsv1.StatefulSet{}
+		if err := sourceCC.Client.Get(ctx, types.NamespacedName{
+			Namespace: m.Spec.SourceNamespace,
+			Name:      m.Spec.StatefulSetName,
+		}, sourceSTS); err != nil {
+			return fmt.Errorf("source StatefulSet no longer available for copying spec: %w", err)
+		}
 	}
 
-	// Create destination STS with replicas=1
+	// Create destination STS with the first pods only. Its
+	// podManagementPolicy is the source's, so a Parallel StatefulSet starts
+	// the pods of a batch together
 	destSTS := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      m.Spec.StatefulSetName,
@@ -703,17 +1537,34 @@ func (r *StatefulSetMigrationReconciler) createDestinationStatefulSet(ctx contex
 		Spec: *sourceSTS.Spec.DeepCopy(),
 	}
 
-	// Set replicas to 1 for first pod
-	one := int32(1)
-	destSTS.Spec.Replicas = &one
+	// Set replicas for the first pods, starting at the first ordinal when
+	// spec.podOrder moves another pod before ordinal 0
+	destSTS.Spec.Replicas = &replicas
+	if ordinals := destOrdinals(m, int(replicas)); ordinals != nil {
+		destSTS.Spec.Ordinals = ordinals
+	}
 
 	// Update namespace references in pod template if needed
 	destSTS.Spec.Template.Namespace = m.Spec.DestNamespace
+	placeDestTemplate(m, &destSTS.Spec.Template)
 
-	return destCC.Client.Create(ctx, destSTS)
+	err = destCC.Client.Create(ctx, destSTS)
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	// A StatefulSet an earlier attempt created is reused
+	existing := &appsv1.StatefulSet{}
+	if err := destCC.Client.Get(ctx, client.ObjectKeyFromObject(destSTS), existing); err != nil {
+		return err
+	}
+	if existing.Annotations["migration.aqua.io/migrated-from"] != destSTS.Annotations["migration.aqua.io/migrated-from"] {
+		return fmt.Errorf("StatefulSet %s/%s already exists and was not migrated from %s", destSTS.Namespace, destSTS.Name, destSTS.Annotations["migration.aqua.io/migrated-from"])
+	}
+	return nil
 }
 
-func (r *StatefulSetMigrationReconciler) scaleDestinationStatefulSet(ctx context.Context, cc *multicluster.ClusterClient, m *migrationv1alpha1.StatefulSetMigration, replicas int32) error {
+func (r *StatefulSetMigrationReconciler) scaleDestinationStatefulSet(ctx context.Context, cc *multicluster.ClusterClient, m *migrationv1alpha1.StatefulSetMigration, previous, replicas int32) error {
 	sts := &appsv1.StatefulSet{}
 	if err := cc.Client.Get(ctx, types.NamespacedName{
 		Namespace: m.Spec.DestNamespace,
@@ -721,11 +1572,185 @@ func (r *StatefulSetMigrationReconciler) scaleDestinationStatefulSet(ctx context
 	}, sts); err != nil {
 		return err
 	}
+	// Someone else scaling or updating it at the same time would race the
+	// migration for the pods; the Update below catches writes after this read
+	if err := checkDestinationScale(sts, previous, replicas, int32(len(m.Status.FailedPods)), m.Status.DestRevision); err != nil {
+		return err
+	}
 
 	sts.Spec.Replicas = &replicas
+	if len(m.Status.PodOrder) > 0 {
+		sts.Spec.Ordinals = destOrdinals(m, int(replicas))
+	}
 	return cc.Client.Update(ctx, sts)
 }
 
+// findExistingDestPV looks for destination PVs that already reference the
+// volume. It returns nil when there are none, a single PV the destination
+// PVC can adopt, and fails on anything else.
+func findExistingDestPV(ctx context.Context, cc *multicluster.ClusterClient, volumeID, namespace, pvcName string) (*corev1.PersistentVolume, error) {
+	pvList := &corev1.PersistentVolumeList{}
+	if err := cc.Reader(namespace).List(ctx, pvList); err != nil {
+		return nil, fmt.Errorf("failed to list destination PVs: %w", err)
+	}
+
+	matches := migration.FindPVsForVolume(pvList.Items, volumeID)
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		names := make([]string, 0, len(matches))
+		for _, pv := range matches {
+			names = append(names, pv.Name)
+		}
+		return nil, fmt.Errorf("volume %s is referenced by multiple destination PVs %v", volumeID, names)
+	}
+
+	pv := &matches[0]
+	if err := migration.CheckAdoptablePV(pv, namespace, pvcName); err != nil {
+		return nil, fmt.Errorf("volume %s already present in destination: %w", volumeID, err)
+	}
+	return pv, nil
+}
+
+// bindExistingDestPV points an existing PV's claimRef at the destination PVC.
+// claimUID is the PVC's UID when it is known (an adopted PVC, or one created
+// first for strict binding), and empty otherwise.
+func bindExistingDestPV(ctx context.Context, cc *multicluster.ClusterClient, pv *corev1.PersistentVolume, namespace, pvcName string, claimUID types.UID) error {
+	// Replace any UID left over from a PVC that no longer exists so the PV can bind again
+	ref := pv.Spec.ClaimRef
+	if ref == nil || ref.UID != claimUID || ref.ResourceVersion != "" {
+		pv.Spec.ClaimRef = &corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "PersistentVolumeClaim",
+			Namespace:  namespace,
+			Name:       pvcName,
+			UID:        claimUID,
+		}
+		if err := cc.Client.Update(ctx, pv); err != nil {
+			return fmt.Errorf("failed to update claimRef on existing PV %s: %w", pv.Name, err)
+		}
+	}
+	return nil
+}
+
+// sourceVolumes returns the "data" PVC of each of the ordinals and the PV
+// bound to it in the source cluster, and separately the PVCs that have no PV
+func sourceVolumes(ctx context.Context, cc *multicluster.ClusterClient, sts *appsv1.StatefulSet, ordinals []int) ([]*corev1.PersistentVolumeClaim, []*corev1.PersistentVolume, []*corev1.PersistentVolumeClaim, error) {
+	pvcs := make([]*corev1.PersistentVolumeClaim, 0, len(ordinals))
+	pvs := make([]*corev1.PersistentVolume, 0, len(ordinals))
+	var unbound []*corev1.PersistentVolumeClaim
+	for _, i := range ordinals {
+		pvcName := translate.GetPVCNameForStatefulSetPod("data", sts.Name, i)
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: sts.Namespace, Name: pvcName}, pvc); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get PVC %s: %w", pvcName, err)
+		}
+		if pvc.Spec.VolumeName == "" {
+			unbound = append(unbound, pvc)
+			continue
+		}
+		pv := &corev1.PersistentVolume{}
+		if err := cc.Client.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get PV %s for PVC %s: %w", pvc.Spec.VolumeName, pvcName, err)
+		}
+		pvcs = append(pvcs, pvc)
+		pvs = append(pvs, pv)
+	}
+	return pvcs, pvs, unbound, nil
+}
+
+// checkVolumeRegions fails when a source volume's zone is outside the migration's AWS region
+func (r *StatefulSetMigrationReconciler) checkVolumeRegions(m *migrationv1alpha1.StatefulSetMigration, pvs []*corev1.PersistentVolume) error {
+	for _, pv := range pvs {
+		volumeID, err := getVolumeIDFromPV(pv)
+		if err != nil {
+			return err
+		}
+		if err := r.ebs(m).CheckVolumeRegion(volumeID, migration.VolumeZone(pv)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConditionHeadlessServiceMissing reports a StatefulSet serviceName with no
+// Service in the destination namespace, under serviceCheck: Warn
+const ConditionHeadlessServiceMissing = "HeadlessServiceMissing"
+
+// serviceCheckPolicy returns spec.serviceCheck, defaulting to Warn with
+// overrides.ignoreMissingService or in VolumesOnly mode, where the service
+// can come with the StatefulSet the user creates, and Error otherwise
+func serviceCheckPolicy(m *migrationv1alpha1.StatefulSetMigration) migrationv1alpha1.ServiceCheckPolicy {
+	switch {
+	case m.Spec.ServiceCheck != "":
+		return m.Spec.ServiceCheck
+	case overrides(m).IgnoreMissingService, volumesOnly(m):
+		return migrationv1alpha1.ServiceCheckWarn
+	}
+	return migrationv1alpha1.ServiceCheckError
+}
+
+// checkDestService fails when the StatefulSet's headless service is missing
+// from the destination namespace, or only warns about it, as
+// spec.serviceCheck decides
+func (r *StatefulSetMigrationReconciler) checkDestService(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient, serviceName string) error {
+	policy := serviceCheckPolicy(m)
+	if serviceName == "" || policy == migrationv1alpha1.ServiceCheckSkip {
+		return nil
+	}
+	destService := &corev1.Service{}
+	err := destCC.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: serviceName}, destService)
+	if apierrors.IsNotFound(err) {
+		message := fmt.Sprintf("headless service %q not found in destination namespace (required for StatefulSet)", serviceName)
+		if policy == migrationv1alpha1.ServiceCheckWarn {
+			log.FromContext(ctx).Info("Ignoring missing headless service because spec.serviceCheck is Warn", "service", serviceName)
+			r.setCondition(m, ConditionHeadlessServiceMissing, metav1.ConditionTrue, "NotFound",
+				message+"; pods will have no stable DNS names unless the service is created")
+			return nil
+		}
+		return errors.New(message)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check destination service: %w", err)
+	}
+	if meta.IsStatusConditionTrue(m.Status.Conditions, ConditionHeadlessServiceMissing) {
+		r.setCondition(m, ConditionHeadlessServiceMissing, metav1.ConditionFalse, "Found",
+			fmt.Sprintf("Headless service %q exists in the destination", serviceName))
+	}
+	return nil
+}
+
+// checkPVNames renders the destination PV name of every replica, rejecting
+// invalid names and templates that would give two replicas the same PV
+func checkPVNames(m *migrationv1alpha1.StatefulSetMigration, pvs []*corev1.PersistentVolume) error {
+	claims := make(map[string]string, len(pvs))
+	for i, pv := range pvs {
+		pvcName := translate.GetPVCNameForStatefulSetPod("data", m.Spec.StatefulSetName, i)
+		volumeID, err := getVolumeIDFromPV(pv)
+		if err != nil {
+			return err
+		}
+
+		name, err := translate.RenderPVName(m.Spec.DestPVNameTemplate, translate.PVNameData{
+			Namespace:    m.Spec.DestNamespace,
+			PVCName:      pvcName,
+			SourcePVName: pv.Name,
+			VolumeID:     volumeID,
+		})
+		if err != nil {
+			return err
+		}
+		if other, ok := claims[name]; ok {
+			return fmt.Errorf("PVCs %s and %s would both use PV %q; include {{.PVCName}} or {{.VolumeID}} in the template", other, pvcName, name)
+		}
+		claims[name] = pvcName
+	}
+	return nil
+}
+
 func getVolumeIDFromPV(pv *corev1.PersistentVolume) (string, error) {
 	if pv.Spec.CSI != nil && pv.Spec.CSI.Driver == "ebs.csi.aws.com" {
 		return pv.Spec.CSI.VolumeHandle, nil
@@ -738,7 +1763,23 @@ func getVolumeIDFromPV(pv *corev1.PersistentVolume) (string, error) {
 
 // SetupWithManager sets up the controller with the Manager
 func (r *StatefulSetMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&migrationv1alpha1.StatefulSetMigration{}).
-		Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&migrationv1alpha1.StatefulSetMigration{}, builder.WithPredicates(migrationChanged))
+	if r.MaxConcurrentReconciles > 0 {
+		b = b.WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles})
+	}
+	r.capacity = newCapacityWatches(r.clock(), 64)
+	b = b.WatchesRawSource(source.Channel(r.capacity.events, &handler.EnqueueRequestForObject{}))
+	if r.Pause != nil {
+		b = b.WatchesRawSource(source.Channel(r.Pause.Subscribe(), enqueueAll(mgr.GetClient(), func() client.ObjectList {
+			return &migrationv1alpha1.StatefulSetMigrationList{}
+		})))
+	}
+	if r.Replay != nil {
+		if r.Replay.Reader == nil {
+			r.Replay.Reader = mgr.GetAPIReader()
+		}
+		return b.Complete(&replayReconciler{replayer: r.Replay, client: r.Client, inner: r})
+	}
+	return b.Complete(r)
 }
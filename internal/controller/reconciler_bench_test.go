@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+// benchmarkMigrations is how many migrations each benchmark reconciles in turn
+const benchmarkMigrations = 500
+
+// benchmarkReconcile reconciles benchmarkMigrations migrations in the given
+// phase round robin with the reconciler setup builds, and reports the status
+// writes and API requests each reconcile makes alongside time and memory
+func benchmarkReconcile(b *testing.B, phase migrationv1alpha1.MigrationPhase, setup func(*StatefulSetMigrationReconciler, *migrationv1alpha1.StatefulSetMigration)) {
+	scheme := runtime.NewScheme()
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		b.Fatal(err)
+	}
+	r := &StatefulSetMigrationReconciler{}
+	objects := make([]client.Object, 0, benchmarkMigrations)
+	keys := make([]types.NamespacedName, 0, benchmarkMigrations)
+	for i := range benchmarkMigrations {
+		m := &migrationv1alpha1.StatefulSetMigration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: fmt.Sprintf("web-%d", i), Finalizers: []string{MigrationFinalizer}},
+			Spec:       migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: fmt.Sprintf("web-%d", i), SourceNamespace: "old", DestNamespace: "new"},
+		}
+		m.Status = migrationv1alpha1.StatefulSetMigrationStatus{Phase: phase, AppliedSpec: m.Spec.DeepCopy()}
+		setup(r, m)
+		objects = append(objects, m)
+		keys = append(keys, client.ObjectKeyFromObject(m))
+	}
+
+	var requests, statusWrites atomic.Int64
+	count := func(n *atomic.Int64) { n.Add(1); requests.Add(1) }
+	r.Client = interceptor.NewClient(
+		fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(objects...).Build(),
+		interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				requests.Add(1)
+				return c.Get(ctx, key, obj, opts...)
+			},
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				requests.Add(1)
+				return c.List(ctx, list, opts...)
+			},
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				requests.Add(1)
+				return c.Update(ctx, obj, opts...)
+			},
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				requests.Add(1)
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+			SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+				count(&statusWrites)
+				return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			},
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+				count(&statusWrites)
+				return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+			},
+		})
+
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: keys[i%len(keys)]}); err != nil {
+			b.Fatalf("Reconcile() error = %v", err)
+		}
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(statusWrites.Load())/float64(b.N), "status-writes/op")
+	b.ReportMetric(float64(requests.Load())/float64(b.N), "requests/op")
+}
+
+// BenchmarkReconcilePaused measures migrations held by a pause, which are
+// reconciled again whenever anything about them changes
+func BenchmarkReconcilePaused(b *testing.B) {
+	benchmarkReconcile(b, migrationv1alpha1.PhaseMigratingPods, func(r *StatefulSetMigrationReconciler, _ *migrationv1alpha1.StatefulSetMigration) {
+		r.Pause = &PauseSwitch{reason: "the controller is paused by ConfigMap aqua-system/pause"}
+	})
+}
+
+// BenchmarkReconcileReadOnly measures migrations held by read-only mode
+func BenchmarkReconcileReadOnly(b *testing.B) {
+	benchmarkReconcile(b, migrationv1alpha1.PhaseFinalizing, func(r *StatefulSetMigrationReconciler, _ *migrationv1alpha1.StatefulSetMigration) {
+		r.ReadOnly = true
+	})
+}
+
+// BenchmarkReconcileCompleted measures finished migrations, the bulk of a
+// long-running controller's objects, which every resync reconciles
+func BenchmarkReconcileCompleted(b *testing.B) {
+	benchmarkReconcile(b, migrationv1alpha1.PhaseCompleted, func(*StatefulSetMigrationReconciler, *migrationv1alpha1.StatefulSetMigration) {})
+}
@@ -0,0 +1,284 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestSetCondition(t *testing.T) {
+	clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	r := &StatefulSetMigrationReconciler{Clock: clk}
+	m := &migrationv1alpha1.StatefulSetMigration{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+
+	r.setCondition(m, "Blocked", metav1.ConditionTrue, "DuplicateMigration", "waiting for web-1")
+	first := meta.FindStatusCondition(m.Status.Conditions, "Blocked")
+	if first == nil || !first.LastTransitionTime.Time.Equal(clk.Now()) {
+		t.Fatalf("condition = %+v, want a transition time from the reconciler's clock", first)
+	}
+
+	// Backdate the transition so a bump is visible at second resolution
+	old := metav1.NewTime(first.LastTransitionTime.Add(-time.Hour))
+	first.LastTransitionTime = old
+
+	m.Generation = 2
+	r.setCondition(m, "Blocked", metav1.ConditionTrue, "DuplicateMigration", "waiting for web-2")
+	if len(m.Status.Conditions) != 1 {
+		t.Fatalf("len(Conditions) = %d, want 1", len(m.Status.Conditions))
+	}
+	got := m.Status.Conditions[0]
+	if !got.LastTransitionTime.Equal(&old) {
+		t.Errorf("LastTransitionTime changed to %v without a status change", got.LastTransitionTime)
+	}
+	if got.Message != "waiting for web-2" || got.ObservedGeneration != 2 {
+		t.Errorf("condition = %+v, want updated message and observedGeneration 2", got)
+	}
+
+	clk.Step(time.Minute)
+	r.setCondition(m, "Blocked", metav1.ConditionFalse, "GuardAcquired", "")
+	got = m.Status.Conditions[0]
+	if !got.LastTransitionTime.Time.Equal(clk.Now()) {
+		t.Errorf("LastTransitionTime = %v, want it bumped to %v when status changed", got.LastTransitionTime, clk.Now())
+	}
+}
+
+func TestFreezeSettleDelay(t *testing.T) {
+	frozen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		delay *metav1.Duration
+		now   time.Time
+		want  time.Duration
+	}{
+		{name: "no delay", now: frozen},
+		{name: "settling", delay: &metav1.Duration{Duration: 2 * time.Minute}, now: frozen.Add(30 * time.Second), want: 90 * time.Second},
+		{name: "settled", delay: &metav1.Duration{Duration: 2 * time.Minute}, now: frozen.Add(3 * time.Minute), want: -time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &StatefulSetMigrationReconciler{Clock: clocktesting.NewFakeClock(tt.now)}
+			m := &migrationv1alpha1.StatefulSetMigration{
+				Spec: migrationv1alpha1.StatefulSetMigrationSpec{FreezeSettleDelay: tt.delay},
+				Status: migrationv1alpha1.StatefulSetMigrationStatus{
+					Phase:         migrationv1alpha1.PhaseMigratingPods,
+					TotalReplicas: 3,
+					FrozenTime:    &metav1.Time{Time: frozen},
+				},
+			}
+			if got := r.freezeSettleRemaining(m); got != tt.want {
+				t.Errorf("freezeSettleRemaining() = %v, want %v", got, tt.want)
+			}
+			if tt.want <= 0 {
+				return
+			}
+
+			// The first pod must not move before the delay is over
+			result, err := r.reconcileMigratingPods(context.Background(), m)
+			if err != nil {
+				t.Fatalf("reconcileMigratingPods() error = %v", err)
+			}
+			if result.RequeueAfter != tt.want || m.Status.CurrentIndex != 0 {
+				t.Errorf("reconcileMigratingPods() = %+v at index %d, want requeue after %v at index 0", result, m.Status.CurrentIndex, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDestService(t *testing.T) {
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "dest", Name: "web"}}
+
+	tests := []struct {
+		name          string
+		policy        migrationv1alpha1.ServiceCheckPolicy
+		force         bool
+		overrides     *migrationv1alpha1.OverridesConfig
+		exists        bool
+		wantErr       bool
+		wantCondition metav1.ConditionStatus
+	}{
+		{name: "exists", exists: true},
+		{name: "missing", wantErr: true},
+		{name: "missing with force", force: true, wantCondition: metav1.ConditionTrue},
+		{name: "missing with Error and force", policy: migrationv1alpha1.ServiceCheckError, force: true, wantErr: true},
+		{name: "missing with ignoreMissingService", overrides: &migrationv1alpha1.OverridesConfig{IgnoreMissingService: true}, wantCondition: metav1.ConditionTrue},
+		{name: "missing with another override", overrides: &migrationv1alpha1.OverridesConfig{IgnoreQuotaCheck: true}, wantErr: true},
+		{name: "missing with Warn", policy: migrationv1alpha1.ServiceCheckWarn, wantCondition: metav1.ConditionTrue},
+		{name: "missing with Skip", policy: migrationv1alpha1.ServiceCheckSkip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme)
+			if tt.exists {
+				builder = builder.WithObjects(service)
+			}
+			cc := &multicluster.ClusterClient{Client: builder.Build()}
+			m := &migrationv1alpha1.StatefulSetMigration{}
+			m.Spec.DestNamespace = "dest"
+			m.Spec.ServiceCheck = tt.policy
+			m.Spec.Force = tt.force
+			m.Spec.Overrides = tt.overrides
+
+			r := &StatefulSetMigrationReconciler{}
+			err := r.checkDestService(context.Background(), m, cc, "web")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkDestService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got metav1.ConditionStatus
+			if c := meta.FindStatusCondition(m.Status.Conditions, ConditionHeadlessServiceMissing); c != nil {
+				got = c.Status
+			}
+			if got != tt.wantCondition {
+				t.Errorf("%s = %q, want %q", ConditionHeadlessServiceMissing, got, tt.wantCondition)
+			}
+		})
+	}
+}
+
+func TestContextRefForReader(t *testing.T) {
+	tests := []struct {
+		name        string
+		ref         migrationv1alpha1.ContextRef
+		wantSecret  string
+		wantAs      string
+		wantContext string
+	}{
+		{
+			name: "kubeconfig reader",
+			ref: migrationv1alpha1.ContextRef{KubeConfigSecret: "actor", Impersonate: &migrationv1alpha1.ImpersonationConfig{User: "mover"},
+				Reader: &migrationv1alpha1.ReaderIdentity{KubeConfigSecret: "reader"}},
+			wantSecret: "reader",
+		},
+		{
+			name: "token reader",
+			ref: migrationv1alpha1.ContextRef{Server: "https://dest:6443", TokenSecretRef: &migrationv1alpha1.SecretKeyRef{Name: "actor"},
+				Reader: &migrationv1alpha1.ReaderIdentity{TokenSecretRef: &migrationv1alpha1.SecretKeyRef{Name: "reader", Key: "token"}}},
+			wantSecret: "reader",
+		},
+		{
+			name: "impersonating reader",
+			ref: migrationv1alpha1.ContextRef{KubeConfigSecret: "actor", Impersonate: &migrationv1alpha1.ImpersonationConfig{User: "mover"},
+				Reader: &migrationv1alpha1.ReaderIdentity{Impersonate: &migrationv1alpha1.ImpersonationConfig{User: "viewer"}}},
+			wantSecret: "actor",
+			wantAs:     "viewer",
+		},
+		{
+			name: "reader context",
+			ref: migrationv1alpha1.ContextRef{KubeConfigSecret: "actor", Context: "admin",
+				Reader: &migrationv1alpha1.ReaderIdentity{Context: "viewer"}},
+			wantSecret:  "actor",
+			wantContext: "viewer",
+		},
+		{
+			name: "actor context",
+			ref: migrationv1alpha1.ContextRef{KubeConfigSecret: "actor", Context: "admin",
+				Reader: &migrationv1alpha1.ReaderIdentity{Impersonate: &migrationv1alpha1.ImpersonationConfig{User: "viewer"}}},
+			wantSecret:  "actor",
+			wantAs:      "viewer",
+			wantContext: "admin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := contextRefFor("ops", tt.ref)
+			if cr.Reader == nil {
+				t.Fatal("Reader = nil, want the reader's ContextRef")
+			}
+			if cr.Reader.SecretNamespace != "ops" || cr.Reader.SecretName != tt.wantSecret || cr.Reader.Server != cr.Server {
+				t.Errorf("Reader = %+v, want Secret ops/%s on server %q", cr.Reader, tt.wantSecret, cr.Server)
+			}
+			if cr.Reader.ImpersonateUser != tt.wantAs {
+				t.Errorf("Reader impersonates %q, want %q", cr.Reader.ImpersonateUser, tt.wantAs)
+			}
+			if cr.Reader.Context != tt.wantContext {
+				t.Errorf("Reader uses context %q, want %q", cr.Reader.Context, tt.wantContext)
+			}
+			if cr.SecretName != "actor" || cr.Reader.Reader != nil {
+				t.Errorf("actor ref = %+v, want its own Secret", cr)
+			}
+		})
+	}
+}
+
+// lockedEC2 answers DescribeVolumes with a volume another controller
+// instance has locked; any other call than those stopSourcePod makes panics
+type lockedEC2 struct {
+	aws.EC2API
+	tagged bool
+}
+
+func (f *lockedEC2) DescribeVolumes(_ context.Context, in *ec2.DescribeVolumesInput, _ ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	lock := aws.FormatVolumeLock("mgmt-b/uid-2", time.Now().Add(time.Hour))
+	return &ec2.DescribeVolumesOutput{Volumes: []ec2types.Volume{{
+		VolumeId: awssdk.String(in.VolumeIds[0]),
+		State:    ec2types.VolumeStateInUse,
+		Tags:     []ec2types.Tag{{Key: awssdk.String(aws.VolumeLockTagKey), Value: awssdk.String(lock)}},
+	}}}, nil
+}
+
+func (f *lockedEC2) DescribeVolumesModifications(context.Context, *ec2.DescribeVolumesModificationsInput, ...func(*ec2.Options)) (*ec2.DescribeVolumesModificationsOutput, error) {
+	return &ec2.DescribeVolumesModificationsOutput{}, nil
+}
+
+func (f *lockedEC2) CreateTags(context.Context, *ec2.CreateTagsInput, ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	f.tagged = true
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func TestStopSourcePodLockedVolume(t *testing.T) {
+	ctx := context.Background()
+	source := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web-0"}},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-0"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-0"},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-0"},
+			Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-0123456789abcdef0"},
+			}},
+		},
+	).Build()
+	dest := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	api := &lockedEC2{}
+	r := &StatefulSetMigrationReconciler{
+		EBSClient:    aws.NewEBSClientFromAPI(api, clocktesting.NewFakeClock(time.Now()), "us-east-1"),
+		VolumeLockID: "mgmt-a",
+	}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{UID: "uid-1"},
+		Spec:       migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web", SourceNamespace: "prod", DestNamespace: "prod"},
+	}
+	mv := &podMove{index: 0, podName: "web-0", claimTemplate: "data", pvcName: "data-web-0"}
+
+	err := r.stopSourcePod(ctx, m, &multicluster.ClusterClient{Client: source}, &multicluster.ClusterClient{Client: dest}, mv)
+	var locked *aws.VolumeLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("stopSourcePod() error = %v, want a VolumeLockedError", err)
+	}
+	if api.tagged || mv.deleted || mv.stoppedAt != nil {
+		t.Errorf("lock tagged %v, pod deleted %v, stopped at %v; want the move to stop before any of them", api.tagged, mv.deleted, mv.stoppedAt)
+	}
+	if err := source.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "web-0"}, &corev1.Pod{}); err != nil {
+		t.Errorf("source pod web-0 was not left running: %v", err)
+	}
+}
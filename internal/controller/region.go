@@ -0,0 +1,15 @@
+package controller
+
+import (
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+// ebs returns the EBS client for the migration's AWS region: the
+// controller's client, or one derived from it for spec.awsConfig.region
+func (r *StatefulSetMigrationReconciler) ebs(m *migrationv1alpha1.StatefulSetMigration) *aws.EBSClient {
+	if r.EBSClient == nil || m.Spec.AWSConfig == nil {
+		return r.EBSClient
+	}
+	return r.EBSClient.ForRegion(m.Spec.AWSConfig.Region)
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	clocktesting "k8s.io/utils/clock/testing"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+func TestEBSClientForMigration(t *testing.T) {
+	r := &StatefulSetMigrationReconciler{EBSClient: aws.NewEBSClientFromAPI(nil, clocktesting.NewFakeClock(time.Now()), "us-east-1")}
+
+	m := &migrationv1alpha1.StatefulSetMigration{}
+	if got := r.ebs(m); got != r.EBSClient {
+		t.Errorf("ebs() without awsConfig = region %q, want the controller's client", got.Region())
+	}
+
+	m.Spec.AWSConfig = &migrationv1alpha1.AWSConfig{Region: "ap-southeast-2"}
+	got := r.ebs(m)
+	if got.Region() != "ap-southeast-2" {
+		t.Fatalf("ebs() region = %q, want ap-southeast-2", got.Region())
+	}
+	if again := r.ebs(m); again != got {
+		t.Error("ebs() created a second client for ap-southeast-2, want the pooled one")
+	}
+}
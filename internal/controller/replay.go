@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+// replayCacheTimeout bounds the wait for the reconciler's cache to see the
+// status a replay starts from
+const replayCacheTimeout = 30 * time.Second
+
+// Replayer asserts that every step of a migration may run twice, as it does
+// when the controller crashes after a step but before writing the status.
+// After each reconcile that records a step or moves to another phase, it
+// writes the migration's status back to what it was before the reconcile and
+// reconciles again. The replay must not fail, must leave the migration in the
+// same phase, and must leave the objects Inventory lists as the step did.
+//
+// Replaying doubles the work of every step, so it is for tests only.
+type Replayer struct {
+	// Inventory lists the objects a replayed step must neither add to nor
+	// remove from, as "<kind>/<namespace>/<name>" or any other stable key,
+	// such as the PVs and PVCs in both clusters (optional)
+	Inventory func(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) ([]string, error)
+
+	// Reader reads the migration around each step, bypassing the cache
+	// (default: the reconciler's client)
+	Reader client.Reader
+
+	mu         sync.Mutex
+	replays    int
+	violations []string
+}
+
+// Replays returns how many steps were replayed
+func (p *Replayer) Replays() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.replays
+}
+
+// Violations returns a description of each replay that broke the invariant
+func (p *Replayer) Violations() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.violations)
+}
+
+// violate records a broken invariant
+func (p *Replayer) violate(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, format string, args ...any) {
+	message := fmt.Sprintf("%s/%s: ", m.Namespace, m.Name) + fmt.Sprintf(format, args...)
+	log.FromContext(ctx).Error(nil, "Replayed step is not idempotent", "violation", message)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.violations = append(p.violations, message)
+}
+
+// replayReconciler runs inner under a Replayer
+type replayReconciler struct {
+	replayer *Replayer
+	client   client.Client
+	inner    reconcile.Reconciler
+}
+
+// Reconcile reconciles the migration and, when that completed a step,
+// replays the step
+func (r *replayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	p := r.replayer
+	reader := p.Reader
+	if reader == nil {
+		reader = r.client
+	}
+
+	before := &migrationv1alpha1.StatefulSetMigration{}
+	if err := reader.Get(ctx, req.NamespacedName, before); err != nil {
+		return r.inner.Reconcile(ctx, req)
+	}
+	result, err := r.inner.Reconcile(ctx, req)
+	if err != nil || !before.DeletionTimestamp.IsZero() {
+		return result, err
+	}
+	after := &migrationv1alpha1.StatefulSetMigration{}
+	if err := reader.Get(ctx, req.NamespacedName, after); err != nil {
+		return result, client.IgnoreNotFound(err)
+	}
+	if after.Status.Phase == before.Status.Phase && equality.Semantic.DeepEqual(after.Status.History, before.Status.History) {
+		return result, nil
+	}
+	step := stepName(before, after)
+	inventory, err := p.inventory(ctx, after)
+	if err != nil {
+		return result, err
+	}
+
+	// Lose the step's status write, as a crash before it would
+	replayed := after.DeepCopy()
+	replayed.Status = *before.Status.DeepCopy()
+	if err := r.client.Status().Update(ctx, replayed); err != nil {
+		return result, fmt.Errorf("failed to roll back the status to replay %s: %w", step, err)
+	}
+	if err := r.awaitCache(ctx, replayed); err != nil {
+		return result, err
+	}
+	log.FromContext(ctx).Info("Replaying step", "step", step)
+	p.mu.Lock()
+	p.replays++
+	p.mu.Unlock()
+
+	if _, err := r.inner.Reconcile(ctx, req); err != nil {
+		p.violate(ctx, after, "replaying %s failed: %v", step, err)
+		return result, err
+	}
+	final := &migrationv1alpha1.StatefulSetMigration{}
+	if err := reader.Get(ctx, req.NamespacedName, final); err != nil {
+		return result, client.IgnoreNotFound(err)
+	}
+	if final.Status.Phase != after.Status.Phase {
+		p.violate(ctx, after, "replaying %s ended in phase %s, not %s: %s", step, final.Status.Phase, after.Status.Phase, final.Status.LastError)
+	}
+	replayedInventory, err := p.inventory(ctx, final)
+	if err != nil {
+		return result, err
+	}
+	if added, removed := inventoryDiff(inventory, replayedInventory); len(added) > 0 || len(removed) > 0 {
+		p.violate(ctx, after, "replaying %s added %v and removed %v", step, added, removed)
+	}
+	return result, nil
+}
+
+// awaitCache waits until the reconciler's client reads the migration at the
+// resource version of m, so the replay starts from the rolled back status
+func (r *replayReconciler) awaitCache(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) error {
+	return wait.PollUntilContextTimeout(ctx, 50*time.Millisecond, replayCacheTimeout, true, func(ctx context.Context) (bool, error) {
+		cached := &migrationv1alpha1.StatefulSetMigration{}
+		if err := r.client.Get(ctx, client.ObjectKeyFromObject(m), cached); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return cached.ResourceVersion == m.ResourceVersion, nil
+	})
+}
+
+// inventory returns the sorted objects Inventory lists, or none without it
+func (p *Replayer) inventory(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) ([]string, error) {
+	if p.Inventory == nil {
+		return nil, nil
+	}
+	objects, err := p.Inventory(ctx, m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the objects of a replayed step: %w", err)
+	}
+	slices.Sort(objects)
+	return objects, nil
+}
+
+// stepName describes the step a reconcile completed, by the history entries
+// it added, or else its phase change
+func stepName(before, after *migrationv1alpha1.StatefulSetMigration) string {
+	var steps []string
+	for _, entry := range after.Status.History {
+		if !slices.ContainsFunc(before.Status.History, func(e migrationv1alpha1.HistoryEntry) bool {
+			return equality.Semantic.DeepEqual(e, entry)
+		}) {
+			steps = append(steps, entry.Step)
+		}
+	}
+	if len(steps) == 0 {
+		return fmt.Sprintf("%s -> %s", before.Status.Phase, after.Status.Phase)
+	}
+	return strings.Join(steps, ", ")
+}
+
+// inventoryDiff returns the entries of after not in before, and of before
+// not in after
+func inventoryDiff(before, after []string) ([]string, []string) {
+	var added, removed []string
+	for _, object := range after {
+		if !slices.Contains(before, object) {
+			added = append(added, object)
+		}
+	}
+	for _, object := range before {
+		if !slices.Contains(after, object) {
+			removed = append(removed, object)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestReplayReconciler(t *testing.T) {
+	tests := []struct {
+		name string
+		// create creates the step's ConfigMap on the n-th run of the step
+		create        func(ctx context.Context, c client.Client, n int) error
+		wantViolation string
+	}{
+		{name: "idempotent step", create: func(ctx context.Context, c client.Client, n int) error {
+			err := c.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web-state"}})
+			return client.IgnoreAlreadyExists(err)
+		}},
+		{name: "step duplicates objects", create: func(ctx context.Context, c client.Client, n int) error {
+			return c.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: fmt.Sprintf("web-state-%d", n)}})
+		}, wantViolation: "added [ConfigMap/ops/web-state-2]"},
+		{name: "step fails when run twice", create: func(ctx context.Context, c client.Client, n int) error {
+			return c.Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web-state"}})
+		}, wantViolation: "replaying CreateState failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			m := &migrationv1alpha1.StatefulSetMigration{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web"},
+				Status:     migrationv1alpha1.StatefulSetMigrationStatus{Phase: migrationv1alpha1.PhasePending},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+
+			migrations := &StatefulSetMigrationReconciler{}
+			runs := 0
+			inner := reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+				m := &migrationv1alpha1.StatefulSetMigration{}
+				if err := c.Get(ctx, req.NamespacedName, m); err != nil {
+					return ctrl.Result{}, err
+				}
+				if m.Status.Phase != migrationv1alpha1.PhasePending {
+					return ctrl.Result{}, nil
+				}
+				runs++
+				if err := tt.create(ctx, c, runs); err != nil {
+					return ctrl.Result{}, err
+				}
+				migrations.recordHistory(m, "CreateState", historyObject("ConfigMap", "ops", "web-state"), migrationv1alpha1.HistoryResultSucceeded, "")
+				m.Status.Phase = migrationv1alpha1.PhasePreFlightChecks
+				return ctrl.Result{}, c.Status().Update(ctx, m)
+			})
+			replayer := &Replayer{Inventory: func(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) ([]string, error) {
+				list := &corev1.ConfigMapList{}
+				if err := c.List(ctx, list, client.InNamespace(m.Namespace)); err != nil {
+					return nil, err
+				}
+				var objects []string
+				for _, cm := range list.Items {
+					objects = append(objects, historyObject("ConfigMap", cm.Namespace, cm.Name))
+				}
+				return objects, nil
+			}}
+			r := &replayReconciler{replayer: replayer, client: c, inner: inner}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ops", Name: "web"}})
+			// A replay that fails returns its error, for the manager to retry
+			if err != nil && (tt.wantViolation == "" || !apierrors.IsAlreadyExists(err)) {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if runs != 2 || replayer.Replays() != 1 {
+				t.Errorf("step ran %d times with %d replays, want 2 and 1", runs, replayer.Replays())
+			}
+
+			violations := replayer.Violations()
+			switch {
+			case tt.wantViolation == "" && len(violations) > 0:
+				t.Errorf("Violations() = %v, want none", violations)
+			case tt.wantViolation != "" && (len(violations) != 1 || !strings.Contains(violations[0], tt.wantViolation)):
+				t.Errorf("Violations() = %v, want one containing %q", violations, tt.wantViolation)
+			}
+		})
+	}
+}
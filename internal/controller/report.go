@@ -0,0 +1,308 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+const (
+	// reportLabel marks ConfigMaps holding migration reports
+	reportLabel = "migration.aqua.io/report"
+
+	// reportMigrationIDLabel records the migration ID a report belongs to
+	reportMigrationIDLabel = "migration.aqua.io/migration-id"
+
+	// ReportYAMLKey and ReportJSONKey are the report ConfigMap keys
+	ReportYAMLKey = "report.yaml"
+	ReportJSONKey = "report.json"
+)
+
+// Report summarizes a finished migration for change tickets and audits
+type Report struct {
+	// Migration is the namespace/name of the StatefulSetMigration
+	Migration   string `json:"migration"`
+	MigrationID string `json:"migrationId"`
+	UID         string `json:"uid"`
+
+	// Result is the final phase: Completed, Failed, Aborted, or Degraded when
+	// the workload regressed during the post-migration watch
+	Result migrationv1alpha1.MigrationPhase `json:"result"`
+	Error  string                           `json:"error,omitempty"`
+
+	Source      ReportEndpoint `json:"source"`
+	Destination ReportEndpoint `json:"destination"`
+
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	Duration       string       `json:"duration,omitempty"`
+
+	// Replicas is the number of pods the migration set out to move
+	Replicas int `json:"replicas"`
+
+	// Pods lists the pods moved, with how long each was down
+	Pods []ReportPod `json:"pods"`
+
+	// PodsNotMoved lists the pods a failed or aborted migration left in the source
+	PodsNotMoved []string `json:"podsNotMoved,omitempty"`
+
+	// TotalDowntime sums the downtime of the pods whose stop time is known
+	TotalDowntime string `json:"totalDowntime,omitempty"`
+
+	// VolumesMoved lists the EBS volumes now used in the destination
+	VolumesMoved []string `json:"volumesMoved"`
+
+	// TotalStorageMigrated sums the capacity of the volumes moved
+	TotalStorageMigrated string `json:"totalStorageMigrated,omitempty"`
+
+	// Steps counts the operations in the timeline by step and result
+	Steps map[string]map[migrationv1alpha1.HistoryResult]int `json:"steps"`
+
+	// Warnings flags anything a reviewer should look at
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Timeline is status.history at the time the report was generated
+	Timeline []migrationv1alpha1.HistoryEntry `json:"timeline"`
+
+	GeneratedAt metav1.Time `json:"generatedAt"`
+}
+
+// ReportEndpoint identifies one side of a migration
+type ReportEndpoint struct {
+	KubeConfigSecret string `json:"kubeConfigSecret,omitempty"`
+	Context          string `json:"context,omitempty"`
+	Server           string `json:"server,omitempty"`
+	Namespace        string `json:"namespace"`
+	StatefulSet      string `json:"statefulSet"`
+}
+
+// ReportPod is a migrated pod in a report
+type ReportPod struct {
+	Index            int          `json:"index"`
+	Pod              string       `json:"pod"`
+	VolumeID         string       `json:"volumeId"`
+	SourceInstanceID string       `json:"sourceInstanceId,omitempty"`
+	DestInstanceID   string       `json:"destInstanceId,omitempty"`
+	StoppedAt        *metav1.Time `json:"stoppedAt,omitempty"`
+	MigratedAt       metav1.Time  `json:"migratedAt"`
+	Downtime         string       `json:"downtime,omitempty"`
+}
+
+// buildReport summarizes a migration from its spec and status
+func buildReport(m *migrationv1alpha1.StatefulSetMigration, now time.Time) *Report {
+	report := &Report{
+		Migration:   fmt.Sprintf("%s/%s", m.Namespace, m.Name),
+		MigrationID: m.Spec.MigrationID,
+		UID:         string(m.UID),
+		Result:      m.Status.Phase,
+		Error:       m.Status.LastError,
+		Source: ReportEndpoint{
+			KubeConfigSecret: m.Spec.SourceCluster.KubeConfigSecret,
+			Context:          m.Spec.SourceCluster.Context,
+			Server:           m.Spec.SourceCluster.Server,
+			Namespace:        m.Spec.SourceNamespace,
+			StatefulSet:      m.Spec.StatefulSetName,
+		},
+		Destination: ReportEndpoint{
+			KubeConfigSecret: m.Spec.DestCluster.KubeConfigSecret,
+			Context:          m.Spec.DestCluster.Context,
+			Server:           m.Spec.DestCluster.Server,
+			Namespace:        m.Spec.DestNamespace,
+			StatefulSet:      m.Spec.StatefulSetName,
+		},
+		StartTime:      m.Status.StartTime,
+		CompletionTime: m.Status.CompletionTime,
+		Replicas:       m.Status.TotalReplicas,
+		Pods:           []ReportPod{},
+		VolumesMoved:   []string{},
+		Steps:          make(map[string]map[migrationv1alpha1.HistoryResult]int),
+		Timeline:       m.Status.History,
+		GeneratedAt:    metav1.NewTime(now),
+	}
+	if m.Status.StartTime != nil && m.Status.CompletionTime != nil {
+		report.Duration = m.Status.CompletionTime.Sub(m.Status.StartTime.Time).Round(time.Second).String()
+	}
+
+	var downtime time.Duration
+	for _, p := range m.Status.MigratedPods {
+		if unboundReplica(m, p.Index) && p.VolumeID == "" {
+			report.Pods = append(report.Pods, ReportPod{Index: p.Index, Pod: p.PodName, MigratedAt: p.MigratedAt})
+			report.Warnings = append(report.Warnings,
+				fmt.Sprintf("%s had no volume in the source and was given a new, empty one in the destination", p.PodName))
+			continue
+		}
+		pod := ReportPod{
+			Index:            p.Index,
+			Pod:              p.PodName,
+			VolumeID:         p.VolumeID,
+			SourceInstanceID: p.SourceInstanceID,
+			DestInstanceID:   p.DestInstanceID,
+			StoppedAt:        p.StoppedAt,
+			MigratedAt:       p.MigratedAt,
+		}
+		if p.StoppedAt != nil {
+			d := p.MigratedAt.Sub(p.StoppedAt.Time)
+			pod.Downtime = d.Round(time.Second).String()
+			downtime += d
+		} else {
+			report.Warnings = append(report.Warnings,
+				fmt.Sprintf("Downtime of %s is unknown: the source pod was already gone when the migration reached it", p.PodName))
+		}
+		report.Pods = append(report.Pods, pod)
+		report.VolumesMoved = append(report.VolumesMoved, p.VolumeID)
+	}
+	report.PodsNotMoved = podsNotMoved(m)
+	for _, p := range m.Status.FailedPods {
+		report.Warnings = append(report.Warnings,
+			fmt.Sprintf("%s failed to migrate and was skipped: %s", p.PodName, p.Reason))
+	}
+	if pod := interruptedPod(m); pod != "" {
+		report.Warnings = append(report.Warnings,
+			fmt.Sprintf("%s was deleted from the source but not started in the destination", pod))
+	}
+	if downtime > 0 {
+		report.TotalDowntime = downtime.Round(time.Second).String()
+	}
+	if total := m.Status.TotalStorageMigrated; total != nil {
+		report.TotalStorageMigrated = total.String()
+	}
+
+	for _, entry := range m.Status.History {
+		if report.Steps[entry.Step] == nil {
+			report.Steps[entry.Step] = make(map[migrationv1alpha1.HistoryResult]int)
+		}
+		report.Steps[entry.Step][entry.Result]++
+
+		switch {
+		case entry.Step == StepForceDetach:
+			report.Warnings = append(report.Warnings, fmt.Sprintf("Force-detached %s: %s", entry.Object, entry.Message))
+		case entry.Step == StepAdoptPV, entry.Step == StepAdoptPod:
+			report.Warnings = append(report.Warnings, fmt.Sprintf("Adopted %s left by an earlier attempt", entry.Object))
+		case entry.Result == migrationv1alpha1.HistoryResultFailed && entry.Step != StepPhase:
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s failed for %s: %s", entry.Step, entry.Object, entry.Message))
+		}
+	}
+	for _, job := range m.Status.Jobs {
+		if !job.Recreated && job.CronJob == "" && !job.WasSuspended {
+			report.Warnings = append(report.Warnings,
+				fmt.Sprintf("%s %s is suspended in the source and was not recreated in the destination", job.Kind, job.Name))
+		}
+	}
+	if len(m.Status.History) >= MaxHistoryEntries {
+		report.Warnings = append(report.Warnings,
+			fmt.Sprintf("Timeline holds only the last %d steps; earlier steps are not in the report", MaxHistoryEntries))
+	}
+
+	for _, condType := range []string{ConditionSpecChangeIgnored, ConditionStorageClassDowngrade, ConditionBackupPolicies, ConditionClockSkew, ConditionCertificateExpiry, ConditionHeadlessServiceMissing, ConditionOrphanedPods} {
+		if c := meta.FindStatusCondition(m.Status.Conditions, condType); c != nil && c.Status == metav1.ConditionTrue {
+			report.Warnings = append(report.Warnings, c.Message)
+		}
+	}
+
+	return report
+}
+
+// reportName returns the name of a migration's report ConfigMap
+func reportName(m *migrationv1alpha1.StatefulSetMigration) string {
+	const suffix = "-report"
+	name := m.Name
+	if max := 253 - len(suffix); len(name) > max {
+		name = strings.TrimRight(name[:max], "-.")
+	}
+	return name + suffix
+}
+
+// reportObjectKey returns the S3 key of a migration's uploaded report
+func (r *StatefulSetMigrationReconciler) reportObjectKey(m *migrationv1alpha1.StatefulSetMigration) string {
+	return fmt.Sprintf("%s%s/%s/%s.json", r.ReportPrefix, m.Namespace, m.Name, m.UID)
+}
+
+// publishReport writes the migration report to a ConfigMap next to the
+// migration and, when ReportBucket is set, uploads it to S3, then sends
+// telemetry and the CloudWatch metrics. The report is best effort: failures are logged and do not change
+// the migration's outcome.
+func (r *StatefulSetMigrationReconciler) publishReport(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) {
+	logger := log.FromContext(ctx)
+	report := buildReport(m, r.clock().Now())
+
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		logger.Error(err, "Failed to encode migration report")
+		return
+	}
+	yamlData, err := yaml.JSONToYAML(jsonData)
+	if err != nil {
+		logger.Error(err, "Failed to encode migration report")
+		return
+	}
+
+	if err := r.writeReportConfigMap(ctx, m, map[string]string{
+		ReportYAMLKey: string(yamlData),
+		ReportJSONKey: string(jsonData),
+	}); err != nil {
+		logger.Error(err, "Failed to write migration report")
+	} else {
+		m.Status.Report = reportName(m)
+	}
+
+	if r.ReportBucket != "" && r.EBSClient != nil {
+		if err := r.EBSClient.PutObject(ctx, r.ReportBucket, r.reportObjectKey(m), jsonData, r.objectOptions("application/json")); err != nil {
+			logger.Error(err, "Failed to upload migration report", "bucket", r.ReportBucket)
+		}
+	}
+
+	r.recordTelemetry(m)
+	r.AWSEvents.Finished(m)
+}
+
+// writeReportConfigMap creates or replaces the migration's report ConfigMap.
+// The ConfigMap is not owned by the migration so the record outlives it, and
+// carries its lineage labels so FindMigrations still finds it.
+func (r *StatefulSetMigrationReconciler) writeReportConfigMap(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, data map[string]string) error {
+	key := types.NamespacedName{Namespace: m.Namespace, Name: reportName(m)}
+	labels := lineageLabels(m)
+	labels[reportLabel] = "true"
+	if m.Spec.MigrationID != "" {
+		labels[reportMigrationIDLabel] = m.Spec.MigrationID
+	}
+
+	cm := &corev1.ConfigMap{}
+	err := r.Get(ctx, key, cm)
+	if apierrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name, Labels: labels},
+			Data:       data,
+		}
+		if err := r.Create(ctx, cm); err != nil {
+			return fmt.Errorf("failed to create report ConfigMap %s: %w", key, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get report ConfigMap %s: %w", key, err)
+	}
+
+	if cm.Labels == nil {
+		cm.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		cm.Labels[k] = v
+	}
+	cm.Data = data
+	if err := r.Update(ctx, cm); err != nil {
+		return fmt.Errorf("failed to update report ConfigMap %s: %w", key, err)
+	}
+	return nil
+}
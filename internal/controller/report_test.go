@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func reportMigration() *migrationv1alpha1.StatefulSetMigration {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) metav1.Time { return metav1.NewTime(start.Add(d)) }
+	stopped := at(time.Minute)
+
+	return &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", UID: "uid-1"},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			MigrationID:     "web-001",
+			SourceNamespace: "prod",
+			StatefulSetName: "web",
+			DestNamespace:   "prod",
+		},
+		Status: migrationv1alpha1.StatefulSetMigrationStatus{
+			Phase:          migrationv1alpha1.PhaseCompleted,
+			TotalReplicas:  2,
+			StartTime:      &metav1.Time{Time: start},
+			CompletionTime: &metav1.Time{Time: start.Add(10 * time.Minute)},
+			MigratedPods: []migrationv1alpha1.MigratedPodInfo{
+				{Index: 0, PodName: "web-0", VolumeID: "vol-0", SourceInstanceID: "i-source", DestInstanceID: "i-dest",
+					StoppedAt: &stopped, MigratedAt: at(3 * time.Minute), StorageBytes: 100 << 30},
+				{Index: 1, PodName: "web-1", VolumeID: "vol-1", MigratedAt: at(6 * time.Minute), StorageBytes: 50 << 30},
+			},
+			TotalStorageMigrated: resource.NewQuantity(150<<30, resource.BinarySI),
+			History: []migrationv1alpha1.HistoryEntry{
+				{Step: StepDeletePod, Object: "Pod/prod/web-0", Result: migrationv1alpha1.HistoryResultSucceeded},
+				{Step: StepForceDetach, Object: "vol-1", Result: migrationv1alpha1.HistoryResultStarted, Message: "Instance i-1 is stopped"},
+				{Step: StepCreatePV, Object: "PersistentVolume/pv-0", Result: migrationv1alpha1.HistoryResultSucceeded},
+				{Step: StepCreatePV, Object: "PersistentVolume/pv-1", Result: migrationv1alpha1.HistoryResultSucceeded},
+			},
+		},
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	report := buildReport(reportMigration(), time.Now())
+
+	if report.Duration != "10m0s" {
+		t.Errorf("Duration = %q, want 10m0s", report.Duration)
+	}
+	if report.Pods[0].Downtime != "2m0s" || report.Pods[1].Downtime != "" {
+		t.Errorf("pod downtimes = %q, %q, want 2m0s and unknown", report.Pods[0].Downtime, report.Pods[1].Downtime)
+	}
+	if report.Pods[0].SourceInstanceID != "i-source" || report.Pods[0].DestInstanceID != "i-dest" {
+		t.Errorf("pod instances = %q -> %q, want i-source -> i-dest", report.Pods[0].SourceInstanceID, report.Pods[0].DestInstanceID)
+	}
+	if report.TotalDowntime != "2m0s" {
+		t.Errorf("TotalDowntime = %q, want 2m0s", report.TotalDowntime)
+	}
+	if got := strings.Join(report.VolumesMoved, ","); got != "vol-0,vol-1" {
+		t.Errorf("VolumesMoved = %s, want vol-0,vol-1", got)
+	}
+	if report.TotalStorageMigrated != "150Gi" {
+		t.Errorf("TotalStorageMigrated = %q, want 150Gi", report.TotalStorageMigrated)
+	}
+	if got := report.Steps[StepCreatePV][migrationv1alpha1.HistoryResultSucceeded]; got != 2 {
+		t.Errorf("Steps[CreatePV][Succeeded] = %d, want 2", got)
+	}
+
+	warnings := strings.Join(report.Warnings, "\n")
+	for _, want := range []string{"Downtime of web-1 is unknown", "Force-detached vol-1"} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("Warnings = %q, want one mentioning %q", report.Warnings, want)
+		}
+	}
+}
+
+func TestPublishReport(t *testing.T) {
+	m := reportMigration()
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	r := &StatefulSetMigrationReconciler{
+		Client: c,
+		Clock:  clocktesting.NewFakeClock(time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)),
+	}
+
+	r.publishReport(context.Background(), m)
+	if m.Status.Report != "web-report" {
+		t.Fatalf("Status.Report = %q, want web-report", m.Status.Report)
+	}
+
+	// Publishing again, e.g. after a conflict on the status update, replaces the report
+	m.Status.Phase = migrationv1alpha1.PhaseFailed
+	r.publishReport(context.Background(), m)
+
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "ops", Name: "web-report"}, cm); err != nil {
+		t.Fatalf("report ConfigMap: %v", err)
+	}
+	if cm.Labels[reportMigrationIDLabel] != "web-001" {
+		t.Errorf("labels = %v, want migration ID web-001", cm.Labels)
+	}
+	var got Report
+	if err := json.Unmarshal([]byte(cm.Data[ReportJSONKey]), &got); err != nil {
+		t.Fatalf("report.json: %v", err)
+	}
+	if got.Result != migrationv1alpha1.PhaseFailed {
+		t.Errorf("Result = %q, want Failed", got.Result)
+	}
+	if !strings.Contains(cm.Data[ReportYAMLKey], "migrationId: web-001") {
+		t.Errorf("report.yaml = %q, want the migration ID", cm.Data[ReportYAMLKey])
+	}
+}
+
+func TestReportName(t *testing.T) {
+	m := &migrationv1alpha1.StatefulSetMigration{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 250)}}
+	if got := reportName(m); len(got) > 253 || !strings.HasSuffix(got, "-report") {
+		t.Errorf("reportName() = %q (%d chars), want at most 253 ending in -report", got, len(got))
+	}
+}
@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+const (
+	// AnnotationRetry set to "true" on a Failed or Aborted migration resumes
+	// it where it stopped. The controller removes it once the retry has
+	// started.
+	AnnotationRetry = "migration.aqua.io/retry"
+
+	// AnnotationRetryReason says why a migration is retried, such as the
+	// fix to the environment it failed on. It is recorded in the history
+	// and removed with AnnotationRetry.
+	AnnotationRetryReason = "migration.aqua.io/retry-reason"
+)
+
+// retryRequested reports whether a stopped migration is annotated to retry
+func retryRequested(m *migrationv1alpha1.StatefulSetMigration) bool {
+	return m.Annotations[AnnotationRetry] == "true" &&
+		(m.Status.Phase == migrationv1alpha1.PhaseFailed || m.Status.Phase == migrationv1alpha1.PhaseAborted)
+}
+
+// retryPhase returns the phase a stopped migration resumes in. Nothing in the
+// source changes before it is frozen, so an earlier failure reruns pre-flight.
+func retryPhase(m *migrationv1alpha1.StatefulSetMigration) migrationv1alpha1.MigrationPhase {
+	switch {
+	case m.Status.FrozenTime == nil:
+		return migrationv1alpha1.PhasePreFlightChecks
+	case m.Status.CurrentIndex >= m.Status.TotalReplicas:
+		return migrationv1alpha1.PhaseFinalizing
+	}
+	return migrationv1alpha1.PhaseMigratingPods
+}
+
+// retryMigration moves a Failed or Aborted migration back into the phase it
+// stopped in and removes AnnotationRetry and AnnotationRetryReason, and
+// AnnotationAbort, which would otherwise stop it again
+func (r *StatefulSetMigrationReconciler) retryMigration(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, error) {
+	stopped := m.Status.Phase
+	phase := retryPhase(m)
+	log.FromContext(ctx).Info("Retrying migration", "from", stopped, "phase", phase)
+
+	m.Status.Phase = phase
+	m.Status.LastError = ""
+	m.Status.CompletionTime = nil
+	// A pod still waiting for a destination node gets its full timeout again
+	if m.Status.CapacityWait != nil {
+		m.Status.CapacityWait.Since = metav1.NewTime(r.clock().Now())
+	}
+	for _, condType := range []string{"Failed", ConditionAborted} {
+		if meta.IsStatusConditionTrue(m.Status.Conditions, condType) {
+			r.setCondition(m, condType, metav1.ConditionFalse, "Retried", fmt.Sprintf("Retried in %s", phase))
+		}
+	}
+	message := fmt.Sprintf("Retrying %s migration in %s", stopped, phase)
+	if reason := m.Annotations[AnnotationRetryReason]; reason != "" {
+		message += ": " + reason
+	}
+	r.recordHistory(m, StepPhase, "", migrationv1alpha1.HistoryResultStarted, message)
+	if err := r.Status().Update(ctx, m); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Only remove the annotation once the status has moved on, so a
+	// failed update retries rather than leaving the migration stopped
+	delete(m.Annotations, AnnotationRetry)
+	delete(m.Annotations, AnnotationRetryReason)
+	delete(m.Annotations, AnnotationAbort)
+	if err := r.Update(ctx, m); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// findMigratedPod returns the pod at index as migrated when an earlier
+// attempt already moved it: its source pod is gone, and the destination pod
+// is Ready on a destination PVC the controller translated from the same
+// source PVC and bound to a PV of the volume the PVC records. In VolumesOnly
+// mode the bound PVC suffices. It returns nil when the pod still has to be
+// migrated.
+func findMigratedPod(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient, index int) (*migrationv1alpha1.MigratedPodInfo, error) {
+	podName := fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, index)
+	pvcName := translate.GetPVCNameForStatefulSetPod("data", m.Spec.StatefulSetName, index)
+
+	// In VolumesOnly mode no destination pod is started; the bound PVC is the result
+	if !volumesOnly(m) {
+		destPod := &corev1.Pod{}
+		if found, err := getIfExists(ctx, destCC, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: podName}, destPod); err != nil || !found {
+			return nil, err
+		}
+		if !podReady(destPod) || !podUsesClaim(destPod, pvcName) {
+			return nil, nil
+		}
+	}
+	if found, err := getIfExists(ctx, sourceCC, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: podName}, &corev1.Pod{}); err != nil || found {
+		return nil, err
+	}
+
+	destPVC := &corev1.PersistentVolumeClaim{}
+	if found, err := getIfExists(ctx, destCC, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: pvcName}, destPVC); err != nil || !found {
+		return nil, err
+	}
+	if destPVC.Labels["migration.aqua.io/migrated"] != "true" || destPVC.Status.Phase != corev1.ClaimBound {
+		return nil, nil
+	}
+	sourcePVC := &corev1.PersistentVolumeClaim{}
+	if err := sourceCC.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: pvcName}, sourcePVC); err != nil {
+		return nil, fmt.Errorf("failed to get source PVC %s: %w", pvcName, err)
+	}
+	if destPVC.Annotations["migration.aqua.io/source-pvc-uid"] != string(sourcePVC.UID) {
+		return nil, nil
+	}
+
+	destPV := &corev1.PersistentVolume{}
+	if err := destCC.Client.Get(ctx, types.NamespacedName{Name: destPVC.Spec.VolumeName}, destPV); err != nil {
+		return nil, fmt.Errorf("failed to get destination PV %s: %w", destPVC.Spec.VolumeName, err)
+	}
+	destVolumeID, err := getVolumeIDFromPV(destPV)
+	if err != nil || destVolumeID != destPVC.Annotations[migration.AnnotationVolumeID] {
+		return nil, nil
+	}
+
+	sourcePV := &corev1.PersistentVolume{}
+	if err := sourceCC.Client.Get(ctx, types.NamespacedName{Name: sourcePVC.Spec.VolumeName}, sourcePV); err != nil {
+		return nil, fmt.Errorf("failed to get source PV: %w", err)
+	}
+	volumeID, err := getVolumeIDFromPV(sourcePV)
+	if err != nil {
+		return nil, err
+	}
+	if destVolumeID != volumeID && !transferVolumes(m) {
+		return nil, nil
+	}
+
+	migrated := &migrationv1alpha1.MigratedPodInfo{
+		Index:        index,
+		PodName:      podName,
+		VolumeID:     destVolumeID,
+		StorageBytes: pvStorageBytes(destPV),
+	}
+	if destVolumeID != volumeID {
+		migrated.SourceVolumeID = volumeID
+	}
+	return migrated, nil
+}
+
+// podUsesClaim reports whether a pod mounts the named PVC
+func podUsesClaim(pod *corev1.Pod, claimName string) bool {
+	for _, v := range pod.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil && v.PersistentVolumeClaim.ClaimName == claimName {
+			return true
+		}
+	}
+	return false
+}
+
+// getIfExists reads obj and reports whether it exists
+func getIfExists(ctx context.Context, cc *multicluster.ClusterClient, key types.NamespacedName, obj client.Object) (bool, error) {
+	if err := cc.Client.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// adoptMigratedPod records a pod findMigratedPod found already moved, and
+// finishes what the attempt that moved it may not have: releasing the volume
+// lock and retagging the volume. Its downtime is unknown.
+func (r *StatefulSetMigrationReconciler) adoptMigratedPod(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, migrated *migrationv1alpha1.MigratedPodInfo) {
+	logger := log.FromContext(ctx)
+	logger.Info("Pod already migrated by an earlier attempt", "pod", migrated.PodName, "volumeId", migrated.VolumeID)
+
+	sourceVolumeID := migrated.VolumeID
+	if migrated.SourceVolumeID != "" {
+		sourceVolumeID = migrated.SourceVolumeID
+	}
+	if r.VolumeLockID != "" {
+		if err := r.ebs(m).ReleaseVolumeLock(ctx, sourceVolumeID, r.volumeLockOwner(m)); err != nil {
+			logger.Error(err, "Failed to release volume lock", "volumeId", sourceVolumeID)
+		}
+	}
+	if !transferVolumes(m) {
+		r.retagVolume(ctx, m, migrated.VolumeID)
+	}
+
+	migrated.MigratedAt = metav1.NewTime(r.clock().Now())
+	recordMigratedPod(m, *migrated)
+	forgetOrphanedPod(m, migrated.PodName)
+	r.recordHistory(m, StepAdoptPod, historyObject("Pod", m.Spec.DestNamespace, migrated.PodName),
+		migrationv1alpha1.HistoryResultSucceeded, fmt.Sprintf("Already Ready on %s", migrated.VolumeID))
+}
@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestRetryPhase(t *testing.T) {
+	frozen := metav1.Now()
+	tests := []struct {
+		name   string
+		status migrationv1alpha1.StatefulSetMigrationStatus
+		want   migrationv1alpha1.MigrationPhase
+	}{
+		{name: "before freeze", status: migrationv1alpha1.StatefulSetMigrationStatus{TotalReplicas: 3}, want: migrationv1alpha1.PhasePreFlightChecks},
+		{name: "during pods", status: migrationv1alpha1.StatefulSetMigrationStatus{FrozenTime: &frozen, TotalReplicas: 3, CurrentIndex: 1}, want: migrationv1alpha1.PhaseMigratingPods},
+		{name: "after pods", status: migrationv1alpha1.StatefulSetMigrationStatus{FrozenTime: &frozen, TotalReplicas: 3, CurrentIndex: 3}, want: migrationv1alpha1.PhaseFinalizing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryPhase(&migrationv1alpha1.StatefulSetMigration{Status: tt.status}); got != tt.want {
+				t.Errorf("retryPhase() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileRetry(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	frozen := metav1.Now()
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ops",
+			Name:        "web",
+			Finalizers:  []string{MigrationFinalizer},
+			Annotations: map[string]string{AnnotationRetry: "true", AnnotationRetryReason: "IAM policy fixed", AnnotationAbort: "true"},
+		},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web"},
+	}
+	m.Status = migrationv1alpha1.StatefulSetMigrationStatus{
+		Phase:          migrationv1alpha1.PhaseAborted,
+		AppliedSpec:    m.Spec.DeepCopy(),
+		FrozenTime:     &frozen,
+		CompletionTime: &frozen,
+		TotalReplicas:  2,
+		CurrentIndex:   1,
+		Conditions:     []metav1.Condition{{Type: ConditionAborted, Status: metav1.ConditionTrue, Reason: "AbortRequested", LastTransitionTime: frozen}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+	r := &StatefulSetMigrationReconciler{Client: c}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ops", Name: "web"}}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	got := &migrationv1alpha1.StatefulSetMigration{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "ops", Name: "web"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Phase != migrationv1alpha1.PhaseMigratingPods {
+		t.Errorf("Phase = %s, want MigratingPods", got.Status.Phase)
+	}
+	if got.Status.CompletionTime != nil {
+		t.Errorf("CompletionTime = %v, want unset", got.Status.CompletionTime)
+	}
+	if _, ok := got.Annotations[AnnotationRetry]; ok {
+		t.Error("retry annotation not removed")
+	}
+	if _, ok := got.Annotations[AnnotationAbort]; ok {
+		t.Error("abort annotation not removed")
+	}
+	if _, ok := got.Annotations[AnnotationRetryReason]; ok {
+		t.Error("retry reason annotation not removed")
+	}
+	if n := len(got.Status.History); n == 0 || got.Status.History[n-1].Message != "Retrying Aborted migration in MigratingPods: IAM policy fixed" {
+		t.Errorf("History = %+v, want the retry recorded with its reason", got.Status.History)
+	}
+}
+
+func TestFindMigratedPod(t *testing.T) {
+	sourcePVC := func() *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-1", UID: "source-pvc-uid"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-source"},
+		}
+	}
+	ebsPV := func(name, volumeID string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: volumeID},
+			}},
+		}
+	}
+	destPVC := func(volumeID string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "prod-new",
+				Name:        "data-web-1",
+				Labels:      map[string]string{"migration.aqua.io/migrated": "true"},
+				Annotations: map[string]string{"migration.aqua.io/source-pvc-uid": "source-pvc-uid", migration.AnnotationVolumeID: volumeID},
+			},
+			Spec:   corev1.PersistentVolumeClaimSpec{VolumeName: "pv-dest"},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		}
+	}
+	destPod := func(ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod-new", Name: "web-1"},
+			Spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-web-1"},
+			}}}},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		transfer    bool
+		volumesOnly bool
+		source      []client.Object
+		dest        []client.Object
+		wantVolume  string
+		wantSource  string
+	}{
+		{
+			name:       "moved by an earlier attempt",
+			source:     []client.Object{sourcePVC(), ebsPV("pv-source", "vol-1")},
+			dest:       []client.Object{destPod(corev1.ConditionTrue), destPVC("vol-1"), ebsPV("pv-dest", "vol-1")},
+			wantVolume: "vol-1",
+		},
+		{
+			name:       "transferred by an earlier attempt",
+			transfer:   true,
+			source:     []client.Object{sourcePVC(), ebsPV("pv-source", "vol-1")},
+			dest:       []client.Object{destPod(corev1.ConditionTrue), destPVC("vol-2"), ebsPV("pv-dest", "vol-2")},
+			wantVolume: "vol-2",
+			wantSource: "vol-1",
+		},
+		{
+			name:   "destination pod not ready",
+			source: []client.Object{sourcePVC(), ebsPV("pv-source", "vol-1")},
+			dest:   []client.Object{destPod(corev1.ConditionFalse), destPVC("vol-1"), ebsPV("pv-dest", "vol-1")},
+		},
+		{
+			name:        "volumes only, moved by an earlier attempt",
+			volumesOnly: true,
+			source:      []client.Object{sourcePVC(), ebsPV("pv-source", "vol-1")},
+			dest:        []client.Object{destPVC("vol-1"), ebsPV("pv-dest", "vol-1")},
+			wantVolume:  "vol-1",
+		},
+		{
+			name:   "source pod still running",
+			source: []client.Object{&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web-1"}}, sourcePVC(), ebsPV("pv-source", "vol-1")},
+			dest:   []client.Object{destPod(corev1.ConditionTrue), destPVC("vol-1"), ebsPV("pv-dest", "vol-1")},
+		},
+		{
+			name:   "destination PV is another volume",
+			source: []client.Object{sourcePVC(), ebsPV("pv-source", "vol-1")},
+			dest:   []client.Object{destPod(corev1.ConditionTrue), destPVC("vol-1"), ebsPV("pv-dest", "vol-9")},
+		},
+		{
+			name:   "different volume without a transfer",
+			source: []client.Object{sourcePVC(), ebsPV("pv-source", "vol-1")},
+			dest:   []client.Object{destPod(corev1.ConditionTrue), destPVC("vol-2"), ebsPV("pv-dest", "vol-2")},
+		},
+		{
+			name:   "not yet moved",
+			source: []client.Object{&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web-1"}}, sourcePVC(), ebsPV("pv-source", "vol-1")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{
+				Spec: migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "web", SourceNamespace: "prod", DestNamespace: "prod-new"},
+			}
+			if tt.volumesOnly {
+				m.Spec.Mode = migrationv1alpha1.MigrationModeVolumesOnly
+			}
+			if tt.transfer {
+				m.Spec.DestAWS = &migrationv1alpha1.DestAWSConfig{RoleARN: "arn:aws:iam::222222222222:role/migration", TransferVolumes: true}
+			}
+			sourceCC := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.source...).Build()}
+			destCC := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.dest...).Build()}
+
+			got, err := findMigratedPod(context.Background(), m, sourceCC, destCC, 1)
+			if err != nil {
+				t.Fatalf("findMigratedPod() error = %v", err)
+			}
+			if tt.wantVolume == "" {
+				if got != nil {
+					t.Errorf("findMigratedPod() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("findMigratedPod() = nil, want the migrated pod")
+			}
+			if got.PodName != "web-1" || got.VolumeID != tt.wantVolume || got.SourceVolumeID != tt.wantSource {
+				t.Errorf("findMigratedPod() = %+v, want web-1 on %s from %q", got, tt.wantVolume, tt.wantSource)
+			}
+		})
+	}
+}
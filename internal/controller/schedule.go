@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+const (
+	// DefaultRevalidateBefore is how long before a scheduled start the
+	// pre-flight checks run again (spec.schedule.revalidateBefore)
+	DefaultRevalidateBefore = time.Hour
+
+	// PreFlightRecheckInterval is how often pre-flight checks that failed
+	// ahead of a scheduled start run again, so a fix made outside the
+	// migration shows up without editing it
+	PreFlightRecheckInterval = 15 * time.Minute
+
+	// EventPreFlightFailed is recorded when pre-flight checks run ahead of a
+	// scheduled start find a blocker
+	EventPreFlightFailed = "PreFlightFailed"
+)
+
+// revalidateBefore returns how long before the scheduled start the
+// pre-flight checks run again
+func revalidateBefore(m *migrationv1alpha1.StatefulSetMigration) time.Duration {
+	if d := m.Spec.Schedule.RevalidateBefore; d != nil && d.Duration > 0 {
+		return d.Duration
+	}
+	return DefaultRevalidateBefore
+}
+
+// nextPreFlight returns when the checks run after result: at the
+// revalidation time, unless they already ran past it, and then at the start.
+// Failed checks also run every PreFlightRecheckInterval.
+func nextPreFlight(result *migrationv1alpha1.PreFlightResult, revalidateAt, start time.Time) time.Time {
+	next := start
+	if result.Time.Time.Before(revalidateAt) {
+		next = revalidateAt
+	}
+	if !result.Passed {
+		if recheck := result.Time.Add(PreFlightRecheckInterval); recheck.Before(next) {
+			next = recheck
+		}
+	}
+	return next
+}
+
+// waitForSchedule holds a migration with spec.schedule in Pending until its
+// start time. Meanwhile its pre-flight checks run when it is created or its
+// spec is edited, and again at spec.schedule.revalidateBefore ahead of the
+// start, and status.preFlight caches the result. It returns false once the
+// migration may start; the checks then run once more as its first phase.
+func (r *StatefulSetMigrationReconciler) waitForSchedule(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, bool, error) {
+	if m.Spec.Schedule == nil {
+		return ctrl.Result{}, false, nil
+	}
+	now := r.clock().Now()
+	start := m.Spec.Schedule.StartTime.Time
+	if !now.Before(start) {
+		return ctrl.Result{}, false, nil
+	}
+	revalidateAt := start.Add(-revalidateBefore(m))
+
+	before := m.Status.DeepCopy()
+	result := m.Status.PreFlight
+	if result == nil || result.ObservedGeneration != m.Generation || !now.Before(nextPreFlight(result, revalidateAt, start)) {
+		fresh, err := r.preFlightAhead(ctx, m, now)
+		if err != nil {
+			log.FromContext(ctx).Info("AWS request throttled, retrying", "reason", "Scheduled pre-flight checks", "error", err.Error())
+			return ctrl.Result{RequeueAfter: requeueDelay(DefaultRequeueDelay, m.UID)}, true, nil
+		}
+		r.cachePreFlight(ctx, m, fresh, revalidateAt, start)
+		result = fresh
+	}
+	if err := r.updateStatus(ctx, m, before); err != nil {
+		return ctrl.Result{}, true, err
+	}
+	return ctrl.Result{RequeueAfter: nextPreFlight(result, revalidateAt, start).Sub(now)}, true, nil
+}
+
+// preFlightAhead runs the pre-flight checks against a copy of the migration,
+// so their findings do not reach its history before it starts. It returns
+// an error only when AWS throttled the checks.
+func (r *StatefulSetMigrationReconciler) preFlightAhead(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, now time.Time) (*migrationv1alpha1.PreFlightResult, error) {
+	trial := m.DeepCopy()
+	result := &migrationv1alpha1.PreFlightResult{Time: metav1.NewTime(now), ObservedGeneration: m.Generation}
+
+	sourceClient, destClient, message := r.connectClusters(ctx, trial)
+	if message == "" {
+		var in *PreFlightInput
+		in, message = r.preFlightInput(ctx, trial, sourceClient, destClient)
+		if message == "" {
+			warnings, reason, err := r.runPreFlightChecks(ctx, r.preFlightChecks(), in)
+			if aws.Retryable(err) {
+				return nil, err
+			}
+			result.Warnings = warnings
+			if err != nil {
+				message = fmt.Sprintf("%s: %v", reason, err)
+			}
+		}
+	}
+	result.Passed = message == ""
+	result.Message = message
+
+	// Conditions the checks set, such as ClockSkew, are worth knowing ahead of the window too
+	m.Status.Conditions = trial.Status.Conditions
+	return result, nil
+}
+
+// cachePreFlight stores the result of checks run ahead of the start in
+// status.preFlight and the PreFlightChecks condition
+func (r *StatefulSetMigrationReconciler) cachePreFlight(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, result *migrationv1alpha1.PreFlightResult, revalidateAt, start time.Time) {
+	next := metav1.NewTime(nextPreFlight(result, revalidateAt, start))
+	result.NextCheck = &next
+	previous := m.Status.PreFlight
+	m.Status.PreFlight = result
+
+	startsAt := start.UTC().Format(time.RFC3339)
+	if result.Passed {
+		log.FromContext(ctx).Info("Pre-flight checks passed ahead of the scheduled start", "start", startsAt, "warnings", len(result.Warnings))
+		r.setCondition(m, "PreFlightChecks", metav1.ConditionTrue, "PassedAhead",
+			fmt.Sprintf("Pre-flight checks passed ahead of the start at %s; they run again at %s", startsAt, next.UTC().Format(time.RFC3339)))
+		return
+	}
+	log.FromContext(ctx).Info("Pre-flight checks failed ahead of the scheduled start", "start", startsAt, "message", result.Message)
+	r.setCondition(m, "PreFlightChecks", metav1.ConditionFalse, "FailedAhead",
+		fmt.Sprintf("The migration would fail at its start at %s: %s", startsAt, result.Message))
+	if previous == nil || previous.Passed || previous.Message != result.Message {
+		r.event(m, corev1.EventTypeWarning, EventPreFlightFailed, result.Message)
+	}
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestNextPreFlight(t *testing.T) {
+	start := time.Date(2026, 11, 7, 2, 0, 0, 0, time.UTC)
+	revalidateAt := start.Add(-time.Hour)
+	tests := []struct {
+		name   string
+		ranAt  time.Time
+		passed bool
+		want   time.Time
+	}{
+		{name: "passed days ahead", ranAt: start.Add(-72 * time.Hour), passed: true, want: revalidateAt},
+		{name: "passed after revalidating", ranAt: revalidateAt.Add(time.Minute), passed: true, want: start},
+		{name: "failed days ahead", ranAt: start.Add(-72 * time.Hour), want: start.Add(-72 * time.Hour).Add(PreFlightRecheckInterval)},
+		{name: "failed just before the start", ranAt: start.Add(-5 * time.Minute), want: start},
+	}
+	for _, tt := range tests {
+		result := &migrationv1alpha1.PreFlightResult{Time: metav1.NewTime(tt.ranAt), Passed: tt.passed}
+		if got := nextPreFlight(result, revalidateAt, start); !got.Equal(tt.want) {
+			t.Errorf("%s: nextPreFlight() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScheduledMigrationChecksAhead(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 11, 4, 9, 0, 0, 0, time.UTC)
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web", Generation: 1, Finalizers: []string{MigrationFinalizer}},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			StatefulSetName: "web",
+			SourceCluster:   migrationv1alpha1.ContextRef{KubeConfigSecret: "missing"},
+			Schedule:        &migrationv1alpha1.ScheduleConfig{StartTime: metav1.NewTime(now.Add(72 * time.Hour))},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+	r := &StatefulSetMigrationReconciler{Client: c, ClientManager: multicluster.NewClientManager(scheme, c), Clock: clocktesting.NewFakeClock(now)}
+
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ops", Name: "web"}})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result.RequeueAfter != PreFlightRecheckInterval {
+		t.Errorf("RequeueAfter = %s, want failed checks to run again after %s", result.RequeueAfter, PreFlightRecheckInterval)
+	}
+
+	got := &migrationv1alpha1.StatefulSetMigration{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "ops", Name: "web"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Phase != migrationv1alpha1.PhasePending || got.Status.StartTime != nil {
+		t.Errorf("Phase = %s, StartTime = %v, want the migration to wait in Pending", got.Status.Phase, got.Status.StartTime)
+	}
+	pf := got.Status.PreFlight
+	if pf == nil || pf.Passed || !strings.Contains(pf.Message, "Failed to connect to source cluster") || pf.ObservedGeneration != 1 {
+		t.Fatalf("PreFlight = %+v, want the connection failure cached", pf)
+	}
+	if c := meta.FindStatusCondition(got.Status.Conditions, "PreFlightChecks"); c == nil || c.Status != metav1.ConditionFalse || c.Reason != "FailedAhead" {
+		t.Errorf("PreFlightChecks condition = %+v, want False/FailedAhead", c)
+	}
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// checkPodScheduling fails when a migrated pod would not schedule on any
+// destination node with its volume, which would leave it Pending after its
+// volume has moved, unless overrides.ignoreUnschedulablePods is set
+func checkPodScheduling(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient, podSpec *corev1.PodSpec, pvs []*corev1.PersistentVolume) error {
+	nodes := &corev1.NodeList{}
+	if err := destCC.Client.List(ctx, nodes); err != nil {
+		return fmt.Errorf("failed to list destination nodes: %w", err)
+	}
+	unschedulable := migration.CheckPodScheduling(podSpec, nodes.Items, pvs)
+	if len(unschedulable) == 0 {
+		return nil
+	}
+	message := schedulingMessage(m.Spec.StatefulSetName, unschedulable)
+	if overrides(m).IgnoreUnschedulablePods {
+		log.FromContext(ctx).Info("Ignoring unschedulable pods because they are overridden", "reason", message)
+		return nil
+	}
+	return errors.New(message)
+}
+
+// schedulingMessage lists why each unschedulable pod would not schedule,
+// naming pods that fail for the same reasons together
+func schedulingMessage(stsName string, unschedulable []migration.UnschedulablePod) string {
+	var reasons []string
+	pods := make(map[string][]string)
+	for _, u := range unschedulable {
+		reason := u.String()
+		if pods[reason] == nil {
+			reasons = append(reasons, reason)
+		}
+		pods[reason] = append(pods[reason], fmt.Sprintf("%s-%d", stsName, u.Ordinal))
+	}
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%s: %s", strings.Join(pods[reason], ", "), reason)
+	}
+	return "pods would not schedule in the destination: " + strings.Join(parts, "; ")
+}
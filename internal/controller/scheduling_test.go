@@ -0,0 +1,22 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+)
+
+func TestSchedulingMessage(t *testing.T) {
+	zone := map[string]int{"node(s) had volume node affinity conflict": 3}
+	taint := map[string]int{"node(s) had untolerated taint {dedicated: db}": 3}
+	got := schedulingMessage("web", []migration.UnschedulablePod{
+		{Ordinal: 0, Nodes: 3, Reasons: zone},
+		{Ordinal: 1, Nodes: 3, Reasons: taint},
+		{Ordinal: 3, Nodes: 3, Reasons: zone},
+	})
+	want := "pods would not schedule in the destination: web-0, web-3: 0/3 nodes are available: 3 node(s) had volume node affinity conflict; " +
+		"web-1: 0/3 nodes are available: 3 node(s) had untolerated taint {dedicated: db}"
+	if got != want {
+		t.Errorf("schedulingMessage() = %q, want %q", got, want)
+	}
+}
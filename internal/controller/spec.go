@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+// ConditionSpecChangeIgnored reports that the spec was edited after the
+// migration started and the edit is not being applied
+const ConditionSpecChangeIgnored = "SpecChangeIgnored"
+
+const (
+	// EventSpecSnapshot records the spec a migration started with
+	EventSpecSnapshot = "SpecSnapshot"
+
+	// AnnotationSpecSnapshot holds the normalized spec on the SpecSnapshot event
+	AnnotationSpecSnapshot = "migration.aqua.io/spec"
+)
+
+// applySpec records the spec a migration starts with and its hash
+func applySpec(m *migrationv1alpha1.StatefulSetMigration) {
+	m.Status.AppliedSpec = m.Spec.DeepCopy()
+	m.Status.ObservedGeneration = m.Generation
+	_, m.Status.SpecSnapshotHash = specSnapshot(&m.Spec)
+}
+
+// specSnapshot returns the spec normalized to JSON, with fields in
+// declaration order, map keys sorted and empty optional fields left out, and
+// its hash as "sha256:<hex>". Equal specs always give the same hash.
+func specSnapshot(spec *migrationv1alpha1.StatefulSetMigrationSpec) ([]byte, string) {
+	// A spec decoded from JSON always encodes again
+	data, _ := json.Marshal(spec)
+	sum := sha256.Sum256(data)
+	return data, "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// recordSpecSnapshot records an event carrying the spec the migration starts
+// with. Unlike the resource, the event is not changed by later edits, and an
+// audit can check it against status.specSnapshotHash.
+func (r *StatefulSetMigrationReconciler) recordSpecSnapshot(m *migrationv1alpha1.StatefulSetMigration) {
+	if r.Recorder == nil {
+		return
+	}
+	data, hash := specSnapshot(m.Status.AppliedSpec)
+	r.Recorder.AnnotatedEventf(m, map[string]string{AnnotationSpecSnapshot: string(data)}, corev1.EventTypeNormal, EventSpecSnapshot,
+		"Migration started at generation %d with spec %s", m.Generation, hash)
+}
+
+// pinSpec replaces m.Spec with the spec the migration started with, so every
+// ordinal is migrated with the same settings even if the spec is edited
+// mid-migration (for example, a changed storageClassMapping would otherwise
+// give earlier and later ordinals different PVs). A new generation is
+// observed and SpecChangeIgnored is set while the spec differs from the
+// applied one. It returns true when the status changed and must be written.
+//
+// The pinned spec is only for this reconcile; callers must not write the
+// object back with Update.
+func (r *StatefulSetMigrationReconciler) pinSpec(m *migrationv1alpha1.StatefulSetMigration) bool {
+	changed := false
+
+	// Migrations started before AppliedSpec existed adopt their current spec
+	if m.Status.AppliedSpec == nil {
+		applySpec(m)
+		changed = true
+	}
+
+	if m.Status.ObservedGeneration != m.Generation {
+		m.Status.ObservedGeneration = m.Generation
+		changed = true
+
+		if !equality.Semantic.DeepEqual(m.Spec, *m.Status.AppliedSpec) {
+			r.setCondition(m, ConditionSpecChangeIgnored, metav1.ConditionTrue, "MigrationInProgress",
+				fmt.Sprintf("Spec changed at generation %d after the migration started; the migration continues with the spec it started with. Delete and recreate the migration to apply new settings", m.Generation))
+		} else if meta.FindStatusCondition(m.Status.Conditions, ConditionSpecChangeIgnored) != nil {
+			r.setCondition(m, ConditionSpecChangeIgnored, metav1.ConditionFalse, "SpecRestored", "Spec matches the spec the migration started with")
+		}
+	}
+
+	m.Spec = *m.Status.AppliedSpec.DeepCopy()
+	return changed
+}
+
+// overrides returns the pre-flight checks a migration bypasses. The
+// deprecated spec.force bypasses all of them.
+func overrides(m *migrationv1alpha1.StatefulSetMigration) migrationv1alpha1.OverridesConfig {
+	if m.Spec.Force {
+		return migrationv1alpha1.OverridesConfig{
+			IgnoreMissingService:       true,
+			IgnoreIPFamilyMismatch:     true,
+			IgnoreUnschedulablePods:    true,
+			AllowStorageClassDowngrade: true,
+			IgnoreAttachLimits:         true,
+			IgnoreQuotaCheck:           true,
+		}
+	}
+	if m.Spec.Overrides == nil {
+		return migrationv1alpha1.OverridesConfig{}
+	}
+	return *m.Spec.Overrides
+}
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestPinSpec(t *testing.T) {
+	started := migrationv1alpha1.StatefulSetMigrationSpec{
+		StatefulSetName:     "db",
+		StorageClassMapping: map[string]string{"gp2": "gp3"},
+	}
+	edited := *started.DeepCopy()
+	edited.StorageClassMapping["gp2"] = "io2"
+
+	tests := []struct {
+		name          string
+		generation    int64
+		spec          migrationv1alpha1.StatefulSetMigrationSpec
+		conditions    []metav1.Condition
+		wantChanged   bool
+		wantCondition metav1.ConditionStatus
+	}{
+		{
+			name:        "unchanged generation",
+			generation:  2,
+			spec:        started,
+			wantChanged: false,
+		},
+		{
+			name:          "edited spec is ignored",
+			generation:    3,
+			spec:          edited,
+			wantChanged:   true,
+			wantCondition: metav1.ConditionTrue,
+		},
+		{
+			name:        "new generation with the same spec",
+			generation:  3,
+			spec:        started,
+			wantChanged: true,
+		},
+		{
+			name:          "spec restored after an ignored edit",
+			generation:    4,
+			spec:          started,
+			conditions:    []metav1.Condition{{Type: ConditionSpecChangeIgnored, Status: metav1.ConditionTrue}},
+			wantChanged:   true,
+			wantCondition: metav1.ConditionFalse,
+		},
+	}
+
+	r := &StatefulSetMigrationReconciler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{
+				ObjectMeta: metav1.ObjectMeta{Generation: tt.generation},
+				Spec:       *tt.spec.DeepCopy(),
+				Status: migrationv1alpha1.StatefulSetMigrationStatus{
+					Phase:              migrationv1alpha1.PhaseMigratingPods,
+					ObservedGeneration: 2,
+					AppliedSpec:        started.DeepCopy(),
+					Conditions:         tt.conditions,
+				},
+			}
+
+			if got := r.pinSpec(m); got != tt.wantChanged {
+				t.Errorf("pinSpec() = %v, want %v", got, tt.wantChanged)
+			}
+			if m.Status.ObservedGeneration != tt.generation {
+				t.Errorf("ObservedGeneration = %d, want %d", m.Status.ObservedGeneration, tt.generation)
+			}
+			if got := m.Spec.StorageClassMapping["gp2"]; got != "gp3" {
+				t.Errorf("pinned storageClassMapping[gp2] = %q, want gp3", got)
+			}
+
+			var got metav1.ConditionStatus
+			for _, c := range m.Status.Conditions {
+				if c.Type == ConditionSpecChangeIgnored {
+					got = c.Status
+				}
+			}
+			if got != tt.wantCondition {
+				t.Errorf("SpecChangeIgnored = %q, want %q", got, tt.wantCondition)
+			}
+		})
+	}
+}
+
+func TestPinSpecAdoptsRunningMigration(t *testing.T) {
+	r := &StatefulSetMigrationReconciler{}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Generation: 5},
+		Spec:       migrationv1alpha1.StatefulSetMigrationSpec{StatefulSetName: "db"},
+		Status:     migrationv1alpha1.StatefulSetMigrationStatus{Phase: migrationv1alpha1.PhaseMigratingPods},
+	}
+
+	if !r.pinSpec(m) {
+		t.Fatal("pinSpec() = false, want true when AppliedSpec is recorded")
+	}
+	if m.Status.AppliedSpec == nil || m.Status.AppliedSpec.StatefulSetName != "db" {
+		t.Errorf("AppliedSpec = %+v, want the current spec", m.Status.AppliedSpec)
+	}
+	if m.Status.ObservedGeneration != 5 {
+		t.Errorf("ObservedGeneration = %d, want 5", m.Status.ObservedGeneration)
+	}
+	if meta.FindStatusCondition(m.Status.Conditions, ConditionSpecChangeIgnored) != nil {
+		t.Error("SpecChangeIgnored set when adopting the current spec")
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	m := &migrationv1alpha1.StatefulSetMigration{}
+	if got := overrides(m); got != (migrationv1alpha1.OverridesConfig{}) {
+		t.Errorf("overrides() without overrides = %+v, want none", got)
+	}
+
+	m.Spec.Overrides = &migrationv1alpha1.OverridesConfig{IgnoreQuotaCheck: true}
+	if got := overrides(m); got != *m.Spec.Overrides {
+		t.Errorf("overrides() = %+v, want %+v", got, *m.Spec.Overrides)
+	}
+
+	m.Spec.Force = true
+	got := overrides(m)
+	if !got.IgnoreMissingService || !got.IgnoreIPFamilyMismatch || !got.IgnoreUnschedulablePods ||
+		!got.AllowStorageClassDowngrade || !got.IgnoreAttachLimits || !got.IgnoreQuotaCheck {
+		t.Errorf("overrides() with force = %+v, want every override", got)
+	}
+}
+
+func TestRecordSpecSnapshot(t *testing.T) {
+	recorder := record.NewFakeRecorder(1)
+	r := &StatefulSetMigrationReconciler{Recorder: recorder}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			StatefulSetName:     "db",
+			StorageClassMapping: map[string]string{"gp2": "gp3", "io1": "io2"},
+		},
+	}
+	applySpec(m)
+	r.recordSpecSnapshot(m)
+
+	hash := m.Status.SpecSnapshotHash
+	if !strings.HasPrefix(hash, "sha256:") || len(hash) != len("sha256:")+64 {
+		t.Fatalf("SpecSnapshotHash = %q, want sha256:<hex>", hash)
+	}
+	event := <-recorder.Events
+	if !strings.Contains(event, "SpecSnapshot Migration started at generation 3 with spec "+hash) ||
+		!strings.Contains(event, `"storageClassMapping":{"gp2":"gp3","io1":"io2"}`) {
+		t.Errorf("event = %q, want the hash and the normalized spec", event)
+	}
+
+	// Edits change the hash; the same settings always give the same one
+	edited := m.Spec.DeepCopy()
+	edited.StorageClassMapping["gp2"] = "io2"
+	if _, got := specSnapshot(edited); got == hash {
+		t.Error("specSnapshot() hash unchanged after an edit")
+	}
+	if _, got := specSnapshot(m.Spec.DeepCopy()); got != hash {
+		t.Errorf("specSnapshot() = %s for an equal spec, want %s", got, hash)
+	}
+}
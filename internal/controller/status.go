@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+// updateStatus writes the migration's status unless it still equals before,
+// the status as the handler found it. Handlers that poll use it so a poll
+// that finds nothing new costs no write to the management cluster.
+func (r *StatefulSetMigrationReconciler) updateStatus(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, before *migrationv1alpha1.StatefulSetMigrationStatus) error {
+	if equality.Semantic.DeepEqual(before, &m.Status) {
+		return nil
+	}
+	return r.Status().Update(ctx, m)
+}
+
+// migrationChanged passes the updates a migration must be reconciled for: a
+// spec edit or deletion, which change the generation, and the annotations
+// that abort, retry or hold it. The controller's own status writes are left
+// out; a handler that has more to do requeues itself, so reconciling again on
+// its write would only repeat the work.
+var migrationChanged = predicate.Or(
+	predicate.GenerationChangedPredicate{},
+	predicate.AnnotationChangedPredicate{},
+	predicate.Funcs{UpdateFunc: func(e event.UpdateEvent) bool {
+		return e.ObjectOld.GetDeletionTimestamp().IsZero() != e.ObjectNew.GetDeletionTimestamp().IsZero()
+	}},
+)
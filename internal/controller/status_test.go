@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestMigrationChanged(t *testing.T) {
+	now := metav1.Now()
+	tests := []struct {
+		name   string
+		update func(m *migrationv1alpha1.StatefulSetMigration)
+		want   bool
+	}{
+		{name: "status write", update: func(m *migrationv1alpha1.StatefulSetMigration) {
+			m.Status.Phase = migrationv1alpha1.PhaseMigratingPods
+			m.ResourceVersion = "2"
+		}},
+		{name: "finalizer added", update: func(m *migrationv1alpha1.StatefulSetMigration) {
+			m.Finalizers = []string{MigrationFinalizer}
+		}},
+		{name: "spec edit", update: func(m *migrationv1alpha1.StatefulSetMigration) { m.Generation = 2 }, want: true},
+		{name: "abort annotation", update: func(m *migrationv1alpha1.StatefulSetMigration) {
+			m.Annotations = map[string]string{AnnotationAbort: "true"}
+		}, want: true},
+		{name: "deletion", update: func(m *migrationv1alpha1.StatefulSetMigration) { m.DeletionTimestamp = &now }, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := &migrationv1alpha1.StatefulSetMigration{ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: 1, ResourceVersion: "1"}}
+			updated := old.DeepCopy()
+			tt.update(updated)
+			if got := migrationChanged.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}); got != tt.want {
+				t.Errorf("migrationChanged.Update() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	m := &migrationv1alpha1.StatefulSetMigration{ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web"}}
+	writes := 0
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).
+		WithInterceptorFuncs(interceptor.Funcs{SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			writes++
+			return c.SubResource(subResource).Update(ctx, obj, opts...)
+		}}).Build()
+	r := &StatefulSetMigrationReconciler{Client: c}
+
+	got := &migrationv1alpha1.StatefulSetMigration{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "ops", Name: "web"}, got); err != nil {
+		t.Fatal(err)
+	}
+	before := got.Status.DeepCopy()
+	r.setCondition(got, ConditionWorkloadHealthy, metav1.ConditionTrue, "Watching", "healthy")
+	if err := r.updateStatus(context.Background(), got, before); err != nil {
+		t.Fatalf("updateStatus() error = %v", err)
+	}
+
+	before = got.Status.DeepCopy()
+	r.setCondition(got, ConditionWorkloadHealthy, metav1.ConditionTrue, "Watching", "healthy")
+	if err := r.updateStatus(context.Background(), got, before); err != nil {
+		t.Fatalf("updateStatus() error = %v", err)
+	}
+	if writes != 1 {
+		t.Errorf("status writes = %d, want 1: the second poll changed nothing", writes)
+	}
+}
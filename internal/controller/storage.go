@@ -0,0 +1,39 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+)
+
+// recordMigratedPod adds a moved pod to status.migratedPods, and the
+// capacity of its volume to status.totalStorageMigrated and the
+// storage_migrated_bytes_total metric
+func recordMigratedPod(m *migrationv1alpha1.StatefulSetMigration, migrated migrationv1alpha1.MigratedPodInfo) {
+	m.Status.MigratedPods = append(m.Status.MigratedPods, migrated)
+	if migrated.StorageBytes == 0 {
+		return
+	}
+	m.Status.TotalStorageMigrated = totalStorageMigrated(m.Status.MigratedPods)
+	metrics.ObserveStorageMigrated(m.Namespace, migrated.StorageBytes)
+}
+
+// totalStorageMigrated sums the capacity of the moved volumes
+func totalStorageMigrated(pods []migrationv1alpha1.MigratedPodInfo) *resource.Quantity {
+	var total int64
+	for _, p := range pods {
+		total += p.StorageBytes
+	}
+	return resource.NewQuantity(total, resource.BinarySI)
+}
+
+// pvStorageBytes returns the capacity a PV records, or 0 without one
+func pvStorageBytes(pv *corev1.PersistentVolume) int64 {
+	if pv == nil {
+		return 0
+	}
+	capacity := pv.Spec.Capacity[corev1.ResourceStorage]
+	return capacity.Value()
+}
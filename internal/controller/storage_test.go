@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+)
+
+func TestRecordMigratedPod(t *testing.T) {
+	pv := &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{
+		Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+	}}
+	m := &migrationv1alpha1.StatefulSetMigration{ObjectMeta: metav1.ObjectMeta{Namespace: "storage-test", Name: "web"}}
+	counter := metrics.StorageMigratedBytes.WithLabelValues("storage-test")
+	before := testutil.ToFloat64(counter)
+
+	recordMigratedPod(m, migrationv1alpha1.MigratedPodInfo{Index: 0, PodName: "web-0", StorageBytes: pvStorageBytes(pv)})
+	recordMigratedPod(m, migrationv1alpha1.MigratedPodInfo{Index: 1, PodName: "web-1", StorageBytes: pvStorageBytes(nil)})
+	recordMigratedPod(m, migrationv1alpha1.MigratedPodInfo{Index: 2, PodName: "web-2", StorageBytes: 50 << 30})
+
+	if len(m.Status.MigratedPods) != 3 {
+		t.Errorf("MigratedPods = %d, want 3", len(m.Status.MigratedPods))
+	}
+	if total := m.Status.TotalStorageMigrated; total == nil || total.String() != "150Gi" {
+		t.Errorf("TotalStorageMigrated = %v, want 150Gi", total)
+	}
+	if got := testutil.ToFloat64(counter) - before; got != 150<<30 {
+		t.Errorf("storage_migrated_bytes_total grew by %v, want %v", got, 150<<30)
+	}
+}
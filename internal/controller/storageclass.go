@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// ConditionStorageClassDowngrade reports StorageClass downgrades that
+// overrides.allowStorageClassDowngrade let the migration proceed with
+const ConditionStorageClassDowngrade = "StorageClassDowngrade"
+
+// checkStorageClasses compares each StorageClass of the source volumes with
+// the destination StorageClass it maps to and returns the downgrades. A
+// StorageClass missing from either cluster cannot be compared and is skipped.
+func checkStorageClasses(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceCC, destCC *multicluster.ClusterClient, pvs []*corev1.PersistentVolume) ([]string, error) {
+	logger := log.FromContext(ctx)
+
+	classes := make(map[string]bool)
+	for _, pv := range pvs {
+		if pv.Spec.StorageClassName != "" {
+			classes[pv.Spec.StorageClassName] = true
+		}
+	}
+	names := make([]string, 0, len(classes))
+	for name := range classes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		destName := name
+		if mapped, ok := m.Spec.StorageClassMapping[name]; ok {
+			destName = mapped
+		}
+
+		source, err := getStorageClass(ctx, sourceCC, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get source StorageClass %s: %w", name, err)
+		}
+		dest, err := getStorageClass(ctx, destCC, destName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get destination StorageClass %s: %w", destName, err)
+		}
+		if source == nil || dest == nil {
+			logger.Info("Skipping StorageClass comparison, StorageClass not found", "source", name, "destination", destName)
+			continue
+		}
+		problems = append(problems, migration.CompareStorageClasses(source, dest)...)
+	}
+	return problems, nil
+}
+
+// getStorageClass returns a StorageClass, or nil if it does not exist
+func getStorageClass(ctx context.Context, cc *multicluster.ClusterClient, name string) (*storagev1.StorageClass, error) {
+	sc := &storagev1.StorageClass{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Name: name}, sc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return sc, nil
+}
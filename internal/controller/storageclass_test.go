@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestCheckStorageClasses(t *testing.T) {
+	ebsClass := func(name, volumeType string) *storagev1.StorageClass {
+		return &storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			Provisioner: migration.EBSCSIDriver,
+			Parameters:  map[string]string{"type": volumeType},
+		}
+	}
+	pv := func(class string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{StorageClassName: class}}
+	}
+	source := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+		WithObjects(ebsClass("fast", "io2"), ebsClass("gp3", "gp3")).Build()}
+	dest := &multicluster.ClusterClient{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+		WithObjects(ebsClass("fast", "gp3"), ebsClass("premium", "io2"), ebsClass("gp3", "gp3")).Build()}
+	pvs := []*corev1.PersistentVolume{pv("fast"), pv("fast"), pv("gp3"), pv("unknown"), pv("")}
+
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		want    string
+	}{
+		{name: "same name downgraded", want: "StorageClass fast maps to fast, which provisions gp3 instead of io2 volumes"},
+		{name: "mapped to an equivalent class", mapping: map[string]string{"fast": "premium"}},
+		{name: "mapped to a missing class", mapping: map[string]string{"fast": "missing"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{StorageClassMapping: tt.mapping}}
+			got, err := checkStorageClasses(context.Background(), m, source, dest, pvs)
+			if err != nil {
+				t.Fatalf("checkStorageClasses() error = %v", err)
+			}
+			if strings.Join(got, "; ") != tt.want {
+				t.Errorf("checkStorageClasses() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// EventTargetDenied is recorded when a migration targets a cluster or namespace a policy does not allow
+const EventTargetDenied = "TargetDenied"
+
+// +kubebuilder:rbac:groups=migration.aqua.io,resources=migrationpolicies,verbs=get;list;watch
+
+// TargetPolicy checks the clusters and namespaces a migration reads from and
+// writes to against the MigrationPolicies governing its namespace and the
+// rules of the controller's flags. A nil TargetPolicy allows every target.
+type TargetPolicy struct {
+	// Reader reads MigrationPolicies and the kubeconfig Secrets of the
+	// clusters migrations reference, uncached so a new policy applies at once
+	Reader client.Reader
+
+	// Rules apply to both sides of every migration (optional)
+	Rules migrationv1alpha1.TargetRules
+}
+
+// TargetPolicyError lists the rules a migration's targets break
+type TargetPolicyError struct {
+	Violations []string
+}
+
+func (e *TargetPolicyError) Error() string {
+	return "migration target not allowed: " + strings.Join(e.Violations, "; ")
+}
+
+// IsTargetPolicyError reports whether err is, or wraps, a *TargetPolicyError
+func IsTargetPolicyError(err error) bool {
+	var policyErr *TargetPolicyError
+	return errors.As(err, &policyErr)
+}
+
+// migrationTarget is one side of a migration
+type migrationTarget struct {
+	// destination is false for the source side
+	destination bool
+
+	// namespace is the namespace read or written; every namespace when empty
+	namespace string
+
+	cluster migrationv1alpha1.ContextRef
+}
+
+func (t migrationTarget) side() string {
+	if t.destination {
+		return "destination"
+	}
+	return "source"
+}
+
+// statefulSetMigrationTargets returns the source and destination of a
+// migration, from the spec it started with once it has
+func statefulSetMigrationTargets(m *migrationv1alpha1.StatefulSetMigration) []migrationTarget {
+	spec := &m.Spec
+	if m.Status.AppliedSpec != nil {
+		spec = m.Status.AppliedSpec
+	}
+	return []migrationTarget{
+		{namespace: spec.SourceNamespace, cluster: spec.SourceCluster},
+		{destination: true, namespace: spec.DestNamespace, cluster: spec.DestCluster},
+	}
+}
+
+// volumeMigrationTargets returns the source and destination of a volume migration
+func volumeMigrationTargets(vm *migrationv1alpha1.VolumeMigration) []migrationTarget {
+	return []migrationTarget{
+		{namespace: vm.Spec.SourceNamespace, cluster: vm.Spec.SourceCluster},
+		{destination: true, namespace: volumeDestNamespace(vm), cluster: vm.Spec.DestCluster},
+	}
+}
+
+// assessmentTargets returns the clusters an assessment scans. Its destination
+// is only read when it names a destination cluster.
+func assessmentTargets(a *migrationv1alpha1.MigrationAssessment) []migrationTarget {
+	targets := []migrationTarget{{namespace: a.Spec.Namespace, cluster: a.Spec.SourceCluster}}
+	if a.Spec.DestCluster != nil {
+		destNamespace := a.Spec.DestNamespace
+		if destNamespace == "" {
+			destNamespace = a.Spec.Namespace
+		}
+		targets = append(targets, migrationTarget{destination: true, namespace: destNamespace, cluster: *a.Spec.DestCluster})
+	}
+	return targets
+}
+
+// governingRules is a set of rules and where they come from
+type governingRules struct {
+	origin string
+	source migrationv1alpha1.TargetRules
+	dest   migrationv1alpha1.TargetRules
+}
+
+// Check returns a *TargetPolicyError listing every rule the targets of a
+// migration in namespace break, or another error when the policies or the
+// kubeconfig of a cluster cannot be read
+func (p *TargetPolicy) Check(ctx context.Context, namespace string, targets []migrationTarget) error {
+	if p == nil {
+		return nil
+	}
+
+	governing := []governingRules{{origin: "the controller's flags", source: p.Rules, dest: p.Rules}}
+	policies := &migrationv1alpha1.MigrationPolicyList{}
+	// Without the MigrationPolicy CRD, such as before an upgrade installs
+	// it, only the flags apply
+	if err := p.Reader.List(ctx, policies); err != nil && !meta.IsNoMatchError(err) {
+		return fmt.Errorf("failed to list migration policies: %w", err)
+	}
+	for _, policy := range policies.Items {
+		if len(policy.Spec.MigrationNamespaces) > 0 && !matchesAny(policy.Spec.MigrationNamespaces, namespace) {
+			continue
+		}
+		governing = append(governing, governingRules{
+			origin: "MigrationPolicy " + policy.Name,
+			source: policy.Spec.Source,
+			dest:   policy.Spec.Destination,
+		})
+	}
+
+	var violations []string
+	for _, target := range targets {
+		server := ""
+		for _, g := range governing {
+			rules := g.source
+			if target.destination {
+				rules = g.dest
+			}
+
+			if !patternsEmpty(rules.Clusters) {
+				if server == "" {
+					var err error
+					if server, err = clusterServer(ctx, p.Reader, namespace, target.cluster); err != nil {
+						return fmt.Errorf("failed to resolve the %s cluster: %w", target.side(), err)
+					}
+				}
+				if reason := refusePattern(rules.Clusters, server); reason != "" {
+					violations = append(violations, fmt.Sprintf("%s cluster %s %s by %s", target.side(), server, reason, g.origin))
+				}
+			}
+
+			if patternsEmpty(rules.Namespaces) {
+				continue
+			}
+			if target.namespace == "" {
+				violations = append(violations, fmt.Sprintf("%s namespaces are restricted by %s, but every namespace is scanned", target.side(), g.origin))
+			} else if reason := refusePattern(rules.Namespaces, target.namespace); reason != "" {
+				violations = append(violations, fmt.Sprintf("%s namespace %s %s by %s", target.side(), target.namespace, reason, g.origin))
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &TargetPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// clusterServer returns the normalized API server URL of a cluster: its
+// Server, or the server of its context of its kubeconfig Secret, read with reader
+func clusterServer(ctx context.Context, reader client.Reader, namespace string, ref migrationv1alpha1.ContextRef) (string, error) {
+	if ref.Server != "" {
+		return normalizeServer(ref.Server), nil
+	}
+	key := ref.KubeConfigKey
+	if key == "" {
+		key = "kubeconfig"
+	}
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.KubeConfigSecret}, secret); err != nil {
+		return "", fmt.Errorf("failed to get kubeconfig secret %s/%s: %w", namespace, ref.KubeConfigSecret, err)
+	}
+	data, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("kubeconfig secret %s/%s has no key %q", namespace, ref.KubeConfigSecret, key)
+	}
+	config, err := multicluster.RESTConfigForContext(data, ref.Context)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse kubeconfig secret %s/%s: %w", namespace, ref.KubeConfigSecret, err)
+	}
+	return normalizeServer(config.Host), nil
+}
+
+// normalizeServer lowercases a server URL and drops its trailing slash, so
+// the same server written two ways matches the same patterns
+func normalizeServer(server string) string {
+	return strings.TrimRight(strings.ToLower(server), "/")
+}
+
+// patternsEmpty reports whether the list neither allows nor denies anything
+func patternsEmpty(l migrationv1alpha1.PatternList) bool {
+	return len(l.Allow) == 0 && len(l.Deny) == 0
+}
+
+// refusePattern returns why the list does not allow value, "" when it does
+func refusePattern(l migrationv1alpha1.PatternList, value string) string {
+	for _, pattern := range l.Deny {
+		if globMatch(pattern, value) {
+			return fmt.Sprintf("is denied (%s)", pattern)
+		}
+	}
+	if len(l.Allow) > 0 && !matchesAny(l.Allow, value) {
+		return fmt.Sprintf("is not allowed (%s)", strings.Join(l.Allow, ", "))
+	}
+	return ""
+}
+
+// matchesAny reports whether value matches one of patterns
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if globMatch(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch reports whether value matches pattern, where * matches any run
+// of characters, including slashes and dots. Case is ignored, as is a
+// trailing slash in a server URL.
+func globMatch(pattern, value string) bool {
+	parts := strings.Split(normalizeServer(pattern), "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	re := regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+	return re.MatchString(strings.ToLower(value))
+}
+
+// ParsePatterns splits a comma-separated list of patterns from a flag
+func ParsePatterns(value string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
@@ -0,0 +1,253 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://ABC.gr7.us-east-1.eks.amazonaws.com/
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+current-context: prod
+users:
+- name: admin
+  user:
+    token: t
+`
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"kube-*", "kube-system", true},
+		{"kube-*", "my-kube-system", false},
+		{"https://*.eks.amazonaws.com", "https://abc.gr7.us-east-1.eks.amazonaws.com", true},
+		{"https://*.eks.amazonaws.com/", "https://abc.gr7.us-east-1.eks.amazonaws.com", true},
+		{"https://PROD.example.com", "https://prod.example.com", true},
+		{"https://prod.example.com", "https://prodxexample.com", false},
+		{"*", "anything", true},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.value); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTargetPolicyCheck(t *testing.T) {
+	scheme := volumeMigrationScheme(t)
+	ctx := context.Background()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-a", Name: "prod"},
+		Data:       map[string][]byte{"kubeconfig": []byte(testKubeconfig)},
+	}
+	policy := &migrationv1alpha1.MigrationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenants"},
+		Spec: migrationv1alpha1.MigrationPolicySpec{
+			MigrationNamespaces: []string{"tenant-*"},
+			Destination: migrationv1alpha1.TargetRules{
+				Clusters: migrationv1alpha1.PatternList{Deny: []string{"https://*.us-east-1.eks.amazonaws.com"}},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret, policy).Build()
+	p := &TargetPolicy{
+		Reader: c,
+		Rules:  migrationv1alpha1.TargetRules{Namespaces: migrationv1alpha1.PatternList{Deny: []string{"kube-*"}}},
+	}
+
+	staging := migrationv1alpha1.ContextRef{Server: "https://staging.example.com"}
+	prod := migrationv1alpha1.ContextRef{KubeConfigSecret: "prod"}
+	tests := []struct {
+		name      string
+		namespace string
+		targets   []migrationTarget
+		want      []string
+	}{
+		{
+			name:      "allowed",
+			namespace: "tenant-a",
+			targets:   []migrationTarget{{namespace: "app", cluster: prod}, {destination: true, namespace: "app", cluster: staging}},
+		},
+		{
+			name:      "policy denies the cluster of the kubeconfig",
+			namespace: "tenant-a",
+			targets:   []migrationTarget{{namespace: "app", cluster: staging}, {destination: true, namespace: "app", cluster: prod}},
+			want:      []string{"destination cluster https://abc.gr7.us-east-1.eks.amazonaws.com is denied (https://*.us-east-1.eks.amazonaws.com) by MigrationPolicy tenants"},
+		},
+		{
+			name:      "policy does not govern the namespace",
+			namespace: "platform",
+			targets:   []migrationTarget{{namespace: "app", cluster: staging}, {destination: true, namespace: "app", cluster: staging}},
+		},
+		{
+			name:      "flags deny the namespace",
+			namespace: "platform",
+			targets:   []migrationTarget{{namespace: "kube-system", cluster: staging}, {destination: true, namespace: "app", cluster: staging}},
+			want:      []string{"source namespace kube-system is denied (kube-*) by the controller's flags"},
+		},
+		{
+			name:      "every namespace scanned",
+			namespace: "platform",
+			targets:   []migrationTarget{{cluster: staging}},
+			want:      []string{"source namespaces are restricted by the controller's flags, but every namespace is scanned"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Check(ctx, tt.namespace, tt.targets)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Check() error = %v, want none", err)
+				}
+				return
+			}
+			if !IsTargetPolicyError(err) {
+				t.Fatalf("Check() error = %v, want a TargetPolicyError", err)
+			}
+			if got := err.(*TargetPolicyError).Violations; strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Violations = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// A cluster whose kubeconfig cannot be read is not a violation
+	missing := []migrationTarget{{destination: true, namespace: "app", cluster: migrationv1alpha1.ContextRef{KubeConfigSecret: "missing"}}}
+	if err := p.Check(ctx, "tenant-a", missing); err == nil || IsTargetPolicyError(err) {
+		t.Errorf("Check() error = %v, want a lookup error", err)
+	}
+}
+
+func TestTargetValidator(t *testing.T) {
+	scheme := volumeMigrationScheme(t)
+	ctx := context.Background()
+	v := &TargetValidator{Policy: &TargetPolicy{
+		Reader: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Rules:  migrationv1alpha1.TargetRules{Namespaces: migrationv1alpha1.PatternList{Allow: []string{"app-*"}}},
+	}}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web"},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			SourceCluster:   migrationv1alpha1.ContextRef{Server: "https://east.example.com"},
+			SourceNamespace: "app-web",
+			DestCluster:     migrationv1alpha1.ContextRef{Server: "https://west.example.com"},
+			DestNamespace:   "default",
+		},
+	}
+	if _, err := v.ValidateCreate(ctx, m); !IsTargetPolicyError(err) {
+		t.Errorf("ValidateCreate() error = %v, want the destination namespace denied", err)
+	}
+
+	// Annotating a denied migration does not change its targets
+	annotated := m.DeepCopy()
+	annotated.Annotations = map[string]string{AnnotationAbort: "true"}
+	if _, err := v.ValidateUpdate(ctx, m, annotated); err != nil {
+		t.Errorf("ValidateUpdate() of annotations error = %v, want none", err)
+	}
+	moved := m.DeepCopy()
+	moved.Spec.DestNamespace = "kube-system"
+	if _, err := v.ValidateUpdate(ctx, m, moved); !IsTargetPolicyError(err) {
+		t.Errorf("ValidateUpdate() of the destination error = %v, want it denied", err)
+	}
+
+	// A kubeconfig Secret created after the migration is checked by the controller
+	m.Spec.DestNamespace = "app-web"
+	m.Spec.DestCluster = migrationv1alpha1.ContextRef{KubeConfigSecret: "west"}
+	v.Policy.Rules.Clusters.Deny = []string{"https://prod.*"}
+	if warnings, err := v.ValidateCreate(ctx, m); err != nil || len(warnings) != 1 {
+		t.Errorf("ValidateCreate() = %q, %v, want a warning", warnings, err)
+	}
+
+}
+
+func TestTargetValidatorDuplicateMigration(t *testing.T) {
+	ctx := context.Background()
+	source := migrationv1alpha1.ContextRef{Server: "https://east.example.com"}
+	migration := func(name string, cluster migrationv1alpha1.ContextRef, phase migrationv1alpha1.MigrationPhase) *migrationv1alpha1.StatefulSetMigration {
+		return &migrationv1alpha1.StatefulSetMigration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: name},
+			Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+				SourceCluster:   cluster,
+				SourceNamespace: "app-web",
+				StatefulSetName: "web",
+				DestCluster:     migrationv1alpha1.ContextRef{Server: "https://west.example.com"},
+				DestNamespace:   "app-web",
+			},
+			Status: migrationv1alpha1.StatefulSetMigrationStatus{Phase: phase},
+		}
+	}
+	reader := fake.NewClientBuilder().WithScheme(volumeMigrationScheme(t)).WithObjects(
+		migration("web-done", source, migrationv1alpha1.PhaseCompleted),
+		migration("web-elsewhere", migrationv1alpha1.ContextRef{Server: "https://north.example.com"}, migrationv1alpha1.PhaseMigratingPods),
+	).Build()
+	v := &TargetValidator{Policy: &TargetPolicy{Reader: reader}, Migrations: reader}
+
+	if _, err := v.ValidateCreate(ctx, migration("web", migrationv1alpha1.ContextRef{Server: "https://EAST.example.com/"}, "")); err != nil {
+		t.Fatalf("ValidateCreate() error = %v, want no active migration of the StatefulSet", err)
+	}
+
+	if err := reader.Create(ctx, migration("web-first", source, migrationv1alpha1.PhaseFailed)); err != nil {
+		t.Fatal(err)
+	}
+	_, err := v.ValidateCreate(ctx, migration("web", migrationv1alpha1.ContextRef{Server: "https://EAST.example.com/"}, ""))
+	if err == nil || !strings.Contains(err.Error(), "ops/web-first") {
+		t.Errorf("ValidateCreate() error = %v, want it refused for ops/web-first", err)
+	}
+
+	// A source that cannot be resolved yet is left to the guard lease
+	warnings, err := v.ValidateCreate(ctx, migration("web", migrationv1alpha1.ContextRef{KubeConfigSecret: "east"}, ""))
+	if err != nil || len(warnings) != 1 {
+		t.Errorf("ValidateCreate() = %q, %v, want a warning", warnings, err)
+	}
+}
+
+func TestReconcileDeniedVolumeMigration(t *testing.T) {
+	scheme := volumeMigrationScheme(t)
+	vm := &migrationv1alpha1.VolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "db-data-db-0"},
+		Spec: migrationv1alpha1.VolumeMigrationSpec{
+			SourceCluster:   migrationv1alpha1.ContextRef{Server: "https://east.example.com"},
+			SourceNamespace: "kube-system",
+			DestCluster:     migrationv1alpha1.ContextRef{Server: "https://west.example.com"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(vm).WithStatusSubresource(vm).Build()
+	r := &VolumeMigrationReconciler{Client: c, TargetPolicy: &TargetPolicy{
+		Reader: c,
+		Rules:  migrationv1alpha1.TargetRules{Namespaces: migrationv1alpha1.PatternList{Deny: []string{"kube-*"}}},
+	}}
+
+	key := types.NamespacedName{Namespace: "ops", Name: "db-data-db-0"}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	got := &migrationv1alpha1.VolumeMigration{}
+	if err := c.Get(context.Background(), key, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Phase != migrationv1alpha1.VolumePhaseFailed || !strings.Contains(got.Status.LastError, "source namespace kube-system is denied") {
+		t.Errorf("Phase = %s, LastError = %q, want Failed on the source namespace", got.Status.Phase, got.Status.LastError)
+	}
+	if c := meta.FindStatusCondition(got.Status.Conditions, "Failed"); c == nil || c.Reason != string(migrationv1alpha1.VolumePhasePending) {
+		t.Errorf("Failed condition = %+v, want a retry to start over in Pending and check the targets again", c)
+	}
+}
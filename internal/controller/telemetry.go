@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/telemetry"
+)
+
+// recordTelemetry adds a finished migration's anonymized statistics to the
+// summary the reporter sends, when the controller was started with a
+// telemetry endpoint
+func (r *StatefulSetMigrationReconciler) recordTelemetry(m *migrationv1alpha1.StatefulSetMigration) {
+	if r.Telemetry == nil {
+		return
+	}
+	r.Telemetry.Record(telemetryStatistics(m))
+}
+
+// telemetryStatistics summarizes a migration without anything that names
+// the workload, its clusters or its AWS account
+func telemetryStatistics(m *migrationv1alpha1.StatefulSetMigration) *telemetry.Statistics {
+	id := sha256.Sum256([]byte(m.UID))
+	stats := &telemetry.Statistics{
+		ID:               hex.EncodeToString(id[:16]),
+		Result:           string(m.Status.Phase),
+		VolumeStrategies: telemetryVolumeStrategies(m),
+		Features:         telemetryFeatures(m),
+		Replicas:         m.Status.TotalReplicas,
+		PodsMoved:        len(m.Status.MigratedPods),
+	}
+	if m.Status.StartTime != nil && m.Status.CompletionTime != nil {
+		stats.DurationSeconds = m.Status.CompletionTime.Sub(m.Status.StartTime.Time).Seconds()
+	}
+
+	for _, p := range m.Status.MigratedPods {
+		if p.StoppedAt == nil {
+			continue
+		}
+		downtime := p.MigratedAt.Sub(p.StoppedAt.Time).Seconds()
+		stats.TotalPodDowntimeSeconds += downtime
+		stats.MaxPodDowntimeSeconds = max(stats.MaxPodDowntimeSeconds, downtime)
+	}
+
+	// Each history entry is recorded as its step ends, so the gap before it
+	// is roughly the time the step took
+	var previous time.Time
+	if m.Status.StartTime != nil {
+		previous = m.Status.StartTime.Time
+	}
+	var failedStep string
+	for _, entry := range m.Status.History {
+		if entry.Step == StepPhase {
+			previous = entry.Time.Time
+			continue
+		}
+		if !previous.IsZero() && entry.Time.After(previous) {
+			if stats.StepSeconds == nil {
+				stats.StepSeconds = make(map[string]float64)
+			}
+			stats.StepSeconds[entry.Step] += entry.Time.Sub(previous).Seconds()
+		}
+		previous = entry.Time.Time
+		if entry.Result == migrationv1alpha1.HistoryResultFailed {
+			failedStep = entry.Step
+		}
+	}
+
+	if m.Status.Phase == migrationv1alpha1.PhaseFailed || m.Status.Phase == migrationv1alpha1.PhaseAborted {
+		stats.FailedPhase = string(retryPhase(m))
+		stats.FailedStep = failedStep
+	}
+	return stats
+}
+
+// telemetryVolumeStrategies counts the volumes by the strategy they moved
+// with: the one planned at pre-flight, or SnapshotRestore for a volume that
+// fell back after a blocked detach
+func telemetryVolumeStrategies(m *migrationv1alpha1.StatefulSetMigration) map[string]int {
+	if len(m.Status.VolumeStrategies) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, decision := range m.Status.VolumeStrategies {
+		strategy := decision.Strategy
+		if volumeFallback(m, decision.VolumeID) != nil {
+			strategy = migrationv1alpha1.VolumeStrategySnapshotRestore
+		}
+		counts[string(strategy)]++
+	}
+	return counts
+}
+
+// telemetryFeatures lists the optional spec features a migration uses
+func telemetryFeatures(m *migrationv1alpha1.StatefulSetMigration) []string {
+	var features []string
+	for _, f := range []struct {
+		name string
+		used bool
+	}{
+		{"velero", m.Spec.Velero != nil},
+		{"quiesce", m.Spec.Quiesce != nil},
+		{"podOrder", m.Spec.PodOrder != nil},
+		{"migrateJobs", m.Spec.MigrateJobs},
+		{"migrateAutoscalers", m.Spec.MigrateAutoscalers},
+		{"migrateMonitoring", m.Spec.MigrateMonitoring},
+		{"forceDetach", m.Spec.ForceDetach},
+		{"strategyFallback", m.Spec.StrategyFallback != nil},
+		{"provisionUnboundPVCs", unboundPVCPolicy(m) == migrationv1alpha1.UnboundPVCProvision},
+		{"adoptDestPVCs", m.Spec.AdoptDestPVCs},
+		{"strictClaimRef", m.Spec.StrictClaimRef},
+		{"volumeMigrations", m.Spec.VolumeMigrations},
+		{"postMigrationWatch", m.Spec.PostMigrationWatch != nil},
+		{"continueRemaining", continueRemaining(m)},
+		{"maxParallelPods", maxParallelPods(m) > 1},
+		{"pauseGitOps", m.Spec.PauseGitOps != nil},
+		{"volumeInspection", m.Spec.VolumeInspection != nil},
+		{"volumesOnly", volumesOnly(m)},
+		{"awsRegion", m.Spec.AWSConfig != nil && m.Spec.AWSConfig.Region != ""},
+		{"schedule", m.Spec.Schedule != nil},
+		{"manualGates", len(m.Spec.ManualGates) > 0},
+		{"overrides", m.Spec.Force || m.Spec.Overrides != nil},
+	} {
+		if f.used {
+			features = append(features, f.name)
+		}
+	}
+	return features
+}
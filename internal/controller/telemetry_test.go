@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestTelemetryStatistics(t *testing.T) {
+	m := reportMigration()
+	start := m.Status.StartTime.Time
+	m.Spec.ForceDetach = true
+	for i, d := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 4 * time.Minute} {
+		m.Status.History[i].Time = metav1.NewTime(start.Add(d))
+	}
+
+	stats := telemetryStatistics(m)
+	if stats.Result != "Completed" || stats.Replicas != 2 || stats.PodsMoved != 2 {
+		t.Errorf("stats = %+v, want a completed migration of 2 pods", stats)
+	}
+	if stats.DurationSeconds != 600 || stats.MaxPodDowntimeSeconds != 120 || stats.TotalPodDowntimeSeconds != 120 {
+		t.Errorf("durations = %v, %v, %v, want 600, 120, 120", stats.DurationSeconds, stats.MaxPodDowntimeSeconds, stats.TotalPodDowntimeSeconds)
+	}
+	if got := stats.StepSeconds[StepCreatePV]; got != 120 {
+		t.Errorf("StepSeconds[CreatePV] = %v, want 120", got)
+	}
+	if strings.Join(stats.Features, ",") != "forceDetach" {
+		t.Errorf("Features = %v, want [forceDetach]", stats.Features)
+	}
+	if stats.FailedPhase != "" || stats.FailedStep != "" {
+		t.Errorf("failure = %q/%q, want none for a completed migration", stats.FailedPhase, stats.FailedStep)
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, identifying := range []string{"web", "prod", "ops", "vol-", "uid-1", "i-1"} {
+		if strings.Contains(string(data), identifying) {
+			t.Errorf("telemetry %s contains %q", data, identifying)
+		}
+	}
+}
+
+func TestTelemetryStatisticsFailed(t *testing.T) {
+	m := reportMigration()
+	m.Status.Phase = migrationv1alpha1.PhaseFailed
+	m.Status.FrozenTime = m.Status.StartTime
+	m.Status.CurrentIndex = 1
+	m.Status.LastError = "volume vol-1 is still attached to i-1"
+	m.Status.History = append(m.Status.History,
+		migrationv1alpha1.HistoryEntry{Step: StepDetachVolume, Object: "vol-1", Result: migrationv1alpha1.HistoryResultFailed},
+		migrationv1alpha1.HistoryEntry{Step: StepPhase, Result: migrationv1alpha1.HistoryResultFailed, Message: m.Status.LastError},
+	)
+
+	stats := telemetryStatistics(m)
+	if stats.FailedPhase != string(migrationv1alpha1.PhaseMigratingPods) || stats.FailedStep != StepDetachVolume {
+		t.Errorf("failure = %q/%q, want MigratingPods/%s", stats.FailedPhase, stats.FailedStep, StepDetachVolume)
+	}
+}
+
+func TestTelemetryVolumeStrategies(t *testing.T) {
+	m := reportMigration()
+	m.Status.VolumeStrategies = []migrationv1alpha1.VolumeStrategyDecision{
+		{VolumeID: "vol-1", Strategy: migrationv1alpha1.VolumeStrategyReattach},
+		{VolumeID: "vol-2", Strategy: migrationv1alpha1.VolumeStrategyReattach},
+		{VolumeID: "vol-3", Strategy: migrationv1alpha1.VolumeStrategySnapshotRestore},
+	}
+	// vol-2 fell back after its detach was blocked
+	m.Status.VolumeFallbacks = []migrationv1alpha1.VolumeFallback{
+		{VolumeID: "vol-2", Trigger: migrationv1alpha1.FallbackTriggerDetachBlocked},
+		{VolumeID: "vol-3", Trigger: migrationv1alpha1.FallbackTriggerZoneMismatch},
+	}
+
+	got := telemetryStatistics(m).VolumeStrategies
+	if len(got) != 2 || got["Reattach"] != 1 || got["SnapshotRestore"] != 2 {
+		t.Errorf("VolumeStrategies = %v, want 1 Reattach and 2 SnapshotRestore", got)
+	}
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// StepRecreateSourceSTS records the source StatefulSet recreated with the
+// ordinals spec.ordinalRange leaves there
+const StepRecreateSourceSTS = "RecreateSourceStatefulSet"
+
+// planTranche narrows the migration to spec.ordinalRange: status.totalReplicas
+// and status.podOrder cover the range alone, and status.sourceOrdinals and
+// status.priorDestOrdinals record what the source keeps and what earlier
+// tranches moved. A source that no longer starts at ordinal 0 was left by an
+// earlier tranche, so migrating it without a range is refused.
+func planTranche(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceSTS *appsv1.StatefulSet, destCC *multicluster.ClusterClient) error {
+	m.Status.SourceOrdinals = ""
+	m.Status.PriorDestOrdinals = ""
+	source := migration.StatefulSetOrdinals(sourceSTS)
+	if m.Spec.OrdinalRange == "" {
+		if source.Start != 0 {
+			return fmt.Errorf("the source StatefulSet runs ordinals %s, left by an earlier tranche; set spec.ordinalRange to move them", source)
+		}
+		return nil
+	}
+
+	moved, err := migration.ParseOrdinals(m.Spec.OrdinalRange)
+	if err != nil {
+		return err
+	}
+	var dest migration.Ordinals
+	destSTS := &appsv1.StatefulSet{}
+	found, err := getIfExists(ctx, destCC, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: m.Spec.StatefulSetName}, destSTS)
+	if err != nil {
+		return err
+	}
+	if found {
+		from := fmt.Sprintf("%s/%s", m.Spec.SourceNamespace, m.Spec.StatefulSetName)
+		if destSTS.Annotations["migration.aqua.io/migrated-from"] != from {
+			return fmt.Errorf("StatefulSet %q already exists in destination namespace %q and was not migrated from %s",
+				destSTS.Name, destSTS.Namespace, from)
+		}
+		dest = migration.StatefulSetOrdinals(destSTS)
+	}
+
+	tranche, err := migration.PlanTranche(moved, source, dest)
+	if err != nil {
+		return err
+	}
+	m.Status.TotalReplicas = tranche.Moved.Count
+	m.Status.PodOrder = tranche.Order
+	m.Status.SourceOrdinals = tranche.Source.String()
+	m.Status.PriorDestOrdinals = dest.String()
+	return nil
+}
+
+// checkOrdinalRange checks that both clusters can run the StatefulSets
+// spec.ordinalRange leaves: spec.ordinals where a StatefulSet no longer
+// starts at ordinal 0, and no source HorizontalPodAutoscaler that would
+// scale the recreated source back over the ordinals that moved.
+func checkOrdinalRange(ctx context.Context, in *PreFlightInput) error {
+	m := in.Migration
+	if m.Spec.OrdinalRange == "" {
+		return nil
+	}
+	if m.Status.PriorDestOrdinals == "" && len(m.Status.PodOrder) > 0 && m.Status.PodOrder[0] != 0 {
+		if err := checkOrdinalsSupported(in.DestClient, "destination",
+			fmt.Sprintf("the destination StatefulSet would start at ordinal %d", m.Status.PodOrder[0])); err != nil {
+			return err
+		}
+	}
+	if m.Status.SourceOrdinals == "" {
+		return nil
+	}
+	if kept := keptSourceOrdinals(m); kept[0] != 0 {
+		if err := checkOrdinalsSupported(in.SourceClient, "source",
+			fmt.Sprintf("the recreated source StatefulSet would start at ordinal %d", kept[0])); err != nil {
+			return err
+		}
+	}
+
+	hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := in.SourceClient.Client.List(ctx, hpas, client.InNamespace(m.Spec.SourceNamespace)); err != nil {
+		return fmt.Errorf("failed to list source HorizontalPodAutoscalers: %w", err)
+	}
+	for i := range hpas.Items {
+		if migration.AutoscalerTargets(&hpas.Items[i], m.Spec.StatefulSetName) {
+			return fmt.Errorf("HorizontalPodAutoscaler %q scales the StatefulSet and would scale the recreated source over the ordinals that moved; remove it until the last tranche",
+				hpas.Items[i].Name)
+		}
+	}
+	return nil
+}
+
+// recreateSourceStatefulSet recreates the source StatefulSet, orphan-deleted
+// when the source was frozen, running the ordinals spec.ordinalRange leaves
+// there. It is made from the copy saveSourceStatefulSet kept, so the pod
+// template is unchanged and the StatefulSet re-adopts the pods without
+// restarting them. It keeps the GitOps suspend annotations, since git still
+// declares every replica. One an earlier attempt created is accepted if it
+// runs the same ordinals.
+func (r *StatefulSetMigrationReconciler) recreateSourceStatefulSet(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient) error {
+	kept, err := migration.ParseOrdinals(m.Status.SourceOrdinals)
+	if err != nil {
+		return err
+	}
+	saved, err := r.savedSourceStatefulSet(ctx, m)
+	if err != nil {
+		return err
+	}
+	if saved == nil {
+		return fmt.Errorf("no copy of the source StatefulSet was kept when the source was frozen")
+	}
+
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   m.Spec.SourceNamespace,
+			Name:        m.Spec.StatefulSetName,
+			Labels:      saved.Labels,
+			Annotations: saved.Annotations,
+		},
+		Spec: saved.Spec,
+	}
+	if pause := m.Status.GitOpsPause; pause != nil && len(pause.StatefulSetAnnotations) > 0 {
+		if sts.Annotations == nil {
+			sts.Annotations = map[string]string{}
+		}
+		for _, key := range pause.StatefulSetAnnotations {
+			sts.Annotations[key] = gitOpsAnnotations(m)[key]
+		}
+	}
+	sts.Spec.Replicas = ptr.To(int32(kept.Count))
+	sts.Spec.Ordinals = nil
+	if kept.Start != 0 {
+		sts.Spec.Ordinals = &appsv1.StatefulSetOrdinals{Start: int32(kept.Start)}
+	}
+
+	object := historyObject("StatefulSet", m.Spec.SourceNamespace, m.Spec.StatefulSetName)
+	err = cc.Client.Create(ctx, sts)
+	if apierrors.IsAlreadyExists(err) {
+		existing := &appsv1.StatefulSet{}
+		if err := cc.Client.Get(ctx, client.ObjectKeyFromObject(sts), existing); err != nil {
+			return err
+		}
+		if running := migration.StatefulSetOrdinals(existing); running != kept {
+			return fmt.Errorf("source StatefulSet was recreated outside the migration running ordinals %s, expected %s", running, kept)
+		}
+	} else if err != nil {
+		return err
+	}
+
+	for _, ordinal := range kept.List() {
+		forgetOrphanedPod(m, fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, ordinal))
+	}
+	r.recordHistory(m, StepRecreateSourceSTS, object, migrationv1alpha1.HistoryResultSucceeded,
+		fmt.Sprintf("Recreated running ordinals %s", kept))
+	return nil
+}
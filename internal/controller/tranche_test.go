@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestPlanTrancheStatus(t *testing.T) {
+	source := func(start, replicas int32) *appsv1.StatefulSet {
+		sts := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Replicas: ptr.To(replicas)}}
+		if start != 0 {
+			sts.Spec.Ordinals = &appsv1.StatefulSetOrdinals{Start: start}
+		}
+		return sts
+	}
+	dest := func(from string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "new", Name: "db", Annotations: map[string]string{"migration.aqua.io/migrated-from": from}},
+			Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To[int32](3), Ordinals: &appsv1.StatefulSetOrdinals{Start: 3}},
+		}
+	}
+	tests := []struct {
+		name       string
+		ordinals   string
+		source     *appsv1.StatefulSet
+		dest       *appsv1.StatefulSet
+		wantOrder  []int
+		wantSource string
+		wantPrior  string
+		wantErr    string
+	}{
+		{name: "whole StatefulSet", source: source(0, 6)},
+		{name: "first tranche", ordinals: "3-5", source: source(0, 6), wantOrder: []int{3, 4, 5}, wantSource: "0-2"},
+		{name: "last tranche", ordinals: "0-2", source: source(0, 3), dest: dest("prod/db"), wantOrder: []int{2, 1, 0}, wantPrior: "3-5"},
+		{name: "destination of another StatefulSet", ordinals: "0-2", source: source(0, 3), dest: dest("other/db"),
+			wantErr: "was not migrated from prod/db"},
+		{name: "rest of an earlier tranche without a range", source: source(2, 4),
+			wantErr: "runs ordinals 2-5, left by an earlier tranche"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme)
+			if tt.dest != nil {
+				builder = builder.WithObjects(tt.dest)
+			}
+			m := &migrationv1alpha1.StatefulSetMigration{
+				Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+					SourceNamespace: "prod", DestNamespace: "new", StatefulSetName: "db", OrdinalRange: tt.ordinals,
+				},
+				Status: migrationv1alpha1.StatefulSetMigrationStatus{TotalReplicas: int(*tt.source.Spec.Replicas)},
+			}
+
+			err := planTranche(context.Background(), m, tt.source, &multicluster.ClusterClient{Client: builder.Build()})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("planTranche() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("planTranche() error = %v", err)
+			}
+			if tt.ordinals == "" {
+				if m.Status.TotalReplicas != 6 || m.Status.PodOrder != nil {
+					t.Errorf("status = %d replicas, order %v; want all 6 replicas in ordinal order", m.Status.TotalReplicas, m.Status.PodOrder)
+				}
+				return
+			}
+			if !slices.Equal(m.Status.PodOrder, tt.wantOrder) || m.Status.TotalReplicas != len(tt.wantOrder) ||
+				m.Status.SourceOrdinals != tt.wantSource || m.Status.PriorDestOrdinals != tt.wantPrior {
+				t.Errorf("status = order %v, %d replicas, source %q, prior destination %q; want %v, %d, %q, %q",
+					m.Status.PodOrder, m.Status.TotalReplicas, m.Status.SourceOrdinals, m.Status.PriorDestOrdinals,
+					tt.wantOrder, len(tt.wantOrder), tt.wantSource, tt.wantPrior)
+			}
+		})
+	}
+}
+
+func TestTrancheDestOrdinals(t *testing.T) {
+	// The last tranche moves 2, 1 and 0 below the 3-5 the first one moved
+	m := &migrationv1alpha1.StatefulSetMigration{Status: migrationv1alpha1.StatefulSetMigrationStatus{
+		TotalReplicas:     3,
+		PodOrder:          []int{2, 1, 0},
+		PriorDestOrdinals: "3-5",
+	}}
+	for moved, want := range []struct {
+		replicas int32
+		start    int32
+	}{{3, 3}, {4, 2}, {5, 1}, {6, 0}} {
+		start := int32(0)
+		if ordinals := destOrdinals(m, moved); ordinals != nil {
+			start = ordinals.Start
+		}
+		if replicas := destReplicas(m, moved); replicas != want.replicas || start != want.start {
+			t.Errorf("after %d pods: %d replicas from ordinal %d, want %d from %d", moved, replicas, start, want.replicas, want.start)
+		}
+	}
+}
+
+func TestRecreateSourceStatefulSet(t *testing.T) {
+	ctx := context.Background()
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "db", UID: "uid-1"},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			SourceNamespace: "prod", StatefulSetName: "db", DestNamespace: "new", OrdinalRange: "0-1",
+			PauseGitOps: &migrationv1alpha1.PauseGitOpsConfig{},
+		},
+		Status: migrationv1alpha1.StatefulSetMigrationStatus{
+			TotalReplicas:  2,
+			PodOrder:       []int{0, 1},
+			SourceOrdinals: "2-3",
+			OrphanedPods:   []string{"db-2", "db-3"},
+			GitOpsPause:    &migrationv1alpha1.GitOpsPauseStatus{StatefulSetAnnotations: []string{"fluxcd.io/ignore"}},
+		},
+	}
+	saved := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "db", UID: "source-uid", ResourceVersion: "42", Labels: map[string]string{"app": "db"}},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr.To[int32](4),
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "db", Image: "postgres:16"}}}},
+		},
+	}
+	r := &StatefulSetMigrationReconciler{Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()}
+	if err := r.saveSourceStatefulSet(ctx, m, saved); err != nil {
+		t.Fatalf("saveSourceStatefulSet() error = %v", err)
+	}
+	source := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	cc := &multicluster.ClusterClient{Client: source}
+
+	if err := r.recreateSourceStatefulSet(ctx, m, cc); err != nil {
+		t.Fatalf("recreateSourceStatefulSet() error = %v", err)
+	}
+	sts := &appsv1.StatefulSet{}
+	if err := source.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "db"}, sts); err != nil {
+		t.Fatalf("source StatefulSet not recreated: %v", err)
+	}
+	if *sts.Spec.Replicas != 2 || sts.Spec.Ordinals == nil || sts.Spec.Ordinals.Start != 2 {
+		t.Errorf("recreated StatefulSet runs %d replicas from %v, want 2 from ordinal 2", *sts.Spec.Replicas, sts.Spec.Ordinals)
+	}
+	if sts.Annotations["fluxcd.io/ignore"] != "true" {
+		t.Errorf("annotations = %v, want the GitOps suspend annotation kept", sts.Annotations)
+	}
+	if m.Status.OrphanedPods != nil {
+		t.Errorf("OrphanedPods = %v, want the re-adopted pods forgotten", m.Status.OrphanedPods)
+	}
+
+	// A second attempt accepts the StatefulSet the first created, but not
+	// one running other ordinals
+	if err := r.recreateSourceStatefulSet(ctx, m, cc); err != nil {
+		t.Fatalf("recreateSourceStatefulSet() again error = %v", err)
+	}
+	sts.Spec.Replicas = ptr.To[int32](4)
+	if err := source.Update(ctx, sts, &client.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.recreateSourceStatefulSet(ctx, m, cc); err == nil || !strings.Contains(err.Error(), "running ordinals 2-5") {
+		t.Errorf("recreateSourceStatefulSet() with a scaled source error = %v, want it refused", err)
+	}
+}
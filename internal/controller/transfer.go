@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+)
+
+// DefaultTransferSnapshotTimeout is how long a transfer waits for each
+// snapshot and snapshot copy to complete
+const DefaultTransferSnapshotTimeout = 6 * time.Hour
+
+// transferVolumes reports whether volumes move into the destination account
+// by snapshot copy instead of being reattached
+func transferVolumes(m *migrationv1alpha1.StatefulSetMigration) bool {
+	return m.Spec.DestAWS != nil && m.Spec.DestAWS.TransferVolumes
+}
+
+// transferVolume moves a detached source volume into the destination account
+// and returns the new volume's ID. The volume is snapshotted, the snapshot is
+// shared with the destination account and copied there, and the copy is
+// restored to a new volume in the same zone. Every create call carries an
+// idempotency key, so a retry after a failure or controller restart resumes
+// the earlier attempt instead of duplicating snapshots and volumes.
+func (r *StatefulSetMigrationReconciler) transferVolume(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, index int, volumeID string) (string, error) {
+	logger := log.FromContext(ctx)
+	destAWS := m.Spec.DestAWS
+	uid := string(m.UID)
+
+	dest, err := r.ebs(m).AssumeRole(destAWS.RoleARN)
+	if err != nil {
+		return "", err
+	}
+
+	// The snapshots of an attempt that got as far as the volume may already be deleted
+	volumeKey := aws.IdempotencyKey(uid, index, "CreateVolume")
+	existing, err := dest.FindVolumeByKey(ctx, volumeKey)
+	if err != nil {
+		return "", err
+	}
+	if existing != "" {
+		logger.Info("Resuming transfer with existing volume", "volumeId", volumeID, "destVolumeId", existing)
+		return existing, r.waitForTransferVolume(ctx, m, dest, existing)
+	}
+
+	source, err := r.ebs(m).GetVolumeInfo(ctx, volumeID)
+	if err != nil {
+		return "", err
+	}
+	description := fmt.Sprintf("Transfer of %s for migration %s", volumeID, m.Spec.MigrationID)
+
+	// Hold a snapshot slot for the rest of the transfer, so concurrent
+	// migrations stay within the account's snapshot and copy limits
+	release, err := r.ebs(m).AcquireSnapshotSlot(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	// Snapshot the volume in the source account
+	snapshotID, err := r.ebs(m).CreateSnapshot(ctx, volumeID, aws.IdempotencyKey(uid, index, "CreateSnapshot"),
+		aws.CreateSnapshotConfig{Description: description})
+	if err != nil {
+		return "", err
+	}
+	r.recordHistory(m, StepSnapshot, snapshotID, migrationv1alpha1.HistoryResultStarted, fmt.Sprintf("Snapshot of %s", volumeID))
+	if err := r.waitForTransferSnapshot(ctx, m, r.ebs(m), StepSnapshot, volumeID, snapshotID); err != nil {
+		return "", err
+	}
+	r.recordHistory(m, StepSnapshot, snapshotID, migrationv1alpha1.HistoryResultSucceeded, "")
+
+	// Let the destination account copy it
+	if err := r.ebs(m).ShareSnapshot(ctx, snapshotID, destAWS.AccountID); err != nil {
+		return "", err
+	}
+	r.recordHistory(m, StepShareSnapshot, snapshotID, migrationv1alpha1.HistoryResultSucceeded,
+		fmt.Sprintf("Shared with account %s", destAWS.AccountID))
+
+	// Copy it into the destination account, re-encrypting with its key if one is set
+	copyID, err := dest.CopySnapshot(ctx, snapshotID, aws.IdempotencyKey(uid, index, "CopySnapshot"),
+		aws.CopySnapshotConfig{Description: description, KMSKeyID: destAWS.KMSKeyID})
+	if err != nil {
+		return "", err
+	}
+	r.recordHistory(m, StepCopySnapshot, copyID, migrationv1alpha1.HistoryResultStarted, fmt.Sprintf("Copy of %s", snapshotID))
+	if err := r.waitForTransferSnapshot(ctx, m, dest, StepCopySnapshot, volumeID, copyID); err != nil {
+		return "", err
+	}
+	r.recordHistory(m, StepCopySnapshot, copyID, migrationv1alpha1.HistoryResultSucceeded, "")
+
+	// Restore the copy in the zone the PV's node affinity names. Zone names
+	// map to different physical zones in each account, but the destination
+	// cluster's nodes are labelled with the destination account's names.
+	volumeCfg := transferVolumeConfig(m, source)
+	volumeCfg.SnapshotID = copyID
+	destVolumeID, err := dest.CreateVolume(ctx, volumeKey, volumeCfg)
+	if err != nil {
+		return "", err
+	}
+	if err := r.waitForTransferVolume(ctx, m, dest, destVolumeID); err != nil {
+		return "", err
+	}
+
+	r.deleteTransferSnapshots(ctx, m, dest, snapshotID, copyID)
+	return destVolumeID, nil
+}
+
+// checkTransferVolumes fails pre-flight when a source volume could not be
+// recreated in the destination account, so the migration fails before any
+// pod has moved rather than after its volume has been snapshotted and copied
+func (r *StatefulSetMigrationReconciler) checkTransferVolumes(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, pvs []*corev1.PersistentVolume) error {
+	for _, pv := range pvs {
+		volumeID, err := getVolumeIDFromPV(pv)
+		if err != nil {
+			return err
+		}
+		source, err := r.ebs(m).GetVolumeInfo(ctx, volumeID)
+		if err != nil {
+			return err
+		}
+		if err := aws.CheckVolumeConfig(transferVolumeConfig(m, source)); err != nil {
+			return fmt.Errorf("volume %s cannot be transferred: %w", volumeID, err)
+		}
+	}
+	return nil
+}
+
+// transferVolumeConfig returns the configuration of the volume a transfer
+// creates from a source volume: the same zone, type, size and performance
+func transferVolumeConfig(m *migrationv1alpha1.StatefulSetMigration, source *aws.VolumeInfo) aws.CreateVolumeConfig {
+	iops, throughput := source.ProvisionedPerformance()
+	return aws.CreateVolumeConfig{
+		AvailabilityZone: source.AvailabilityZone,
+		VolumeType:       source.VolumeType,
+		SizeGiB:          source.Size,
+		IOPS:             iops,
+		Throughput:       throughput,
+		Tags:             transferTags(source.Tags, m.Spec.BackupRetag),
+	}
+}
+
+// waitForTransferSnapshot waits for a snapshot or snapshot copy of a volume to complete
+func (r *StatefulSetMigrationReconciler) waitForTransferSnapshot(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, ebs *aws.EBSClient, step, volumeID, snapshotID string) error {
+	logger := log.FromContext(ctx)
+	defer metrics.TrackWait(step)()
+	r.beginVolumeWait(ctx, m, volumeID, step, migrationv1alpha1.VolumeWaitSourceAWS, fmt.Sprintf("snapshot %s pending", snapshotID))
+	defer endVolumeWait(m, volumeID)
+	if _, err := ebs.WaitForSnapshotComplete(ctx, snapshotID, aws.WaitForSnapshotConfig{
+		Timeout: DefaultTransferSnapshotTimeout,
+		OnProgress: func(info *aws.SnapshotInfo, progress aws.SnapshotProgress) {
+			logger.Info("Snapshot progress", "snapshotId", snapshotID, "progress", progress.String())
+			r.probeVolumeWait(ctx, m, volumeID, fmt.Sprintf("snapshot %s %s", snapshotID, progress))
+		},
+	}); err != nil {
+		return fmt.Errorf("%s of %s failed: %w", step, snapshotID, err)
+	}
+	return nil
+}
+
+// waitForTransferVolume waits for the transferred volume to become available
+func (r *StatefulSetMigrationReconciler) waitForTransferVolume(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, dest *aws.EBSClient, volumeID string) error {
+	r.recordHistory(m, StepCreateVolume, volumeID, migrationv1alpha1.HistoryResultStarted, "")
+	doneWaiting := metrics.TrackWait(StepCreateVolume)
+	r.beginVolumeWait(ctx, m, volumeID, StepCreateVolume, migrationv1alpha1.VolumeWaitSourceAWS, "creating")
+	err := dest.WaitForVolumeAvailable(ctx, volumeID, aws.WaitForVolumeAvailableConfig{})
+	doneWaiting()
+	endVolumeWait(m, volumeID)
+	if err != nil {
+		return fmt.Errorf("volume %s did not become available: %w", volumeID, err)
+	}
+	r.recordHistory(m, StepCreateVolume, volumeID, migrationv1alpha1.HistoryResultSucceeded, "")
+	return nil
+}
+
+// deleteTransferSnapshots deletes the snapshot and copy a transfer went
+// through. The volume already exists, so a failure only leaves snapshots to
+// clean up by hand; it is recorded in the history, and so in the report.
+func (r *StatefulSetMigrationReconciler) deleteTransferSnapshots(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, dest *aws.EBSClient, snapshotID, copyID string) {
+	logger := log.FromContext(ctx)
+
+	var problems []string
+	if err := dest.DeleteSnapshot(ctx, copyID); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := r.ebs(m).UnshareSnapshot(ctx, snapshotID, m.Spec.DestAWS.AccountID); err != nil && aws.KindOf(err) != aws.ErrNotFound {
+		problems = append(problems, err.Error())
+	}
+	if err := r.ebs(m).DeleteSnapshot(ctx, snapshotID); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	object := snapshotID + "," + copyID
+	if len(problems) > 0 {
+		logger.Info("Failed to delete transfer snapshots", "snapshotId", snapshotID, "copyId", copyID, "problems", problems)
+		r.recordHistory(m, StepCleanSnapshot, object, migrationv1alpha1.HistoryResultFailed, strings.Join(problems, "; "))
+		return
+	}
+	r.recordHistory(m, StepCleanSnapshot, object, migrationv1alpha1.HistoryResultSucceeded, "")
+}
+
+// transferTags returns the tags of a transferred volume: the source volume's
+// tags with spec.backupRetag applied. Tags EC2 or the controller manage, such
+// as aws: tags and the volume lock, are not copied.
+func transferTags(source map[string]string, retag *migrationv1alpha1.BackupRetagConfig) map[string]string {
+	tags := make(map[string]string, len(source))
+	for key, value := range source {
+		if strings.HasPrefix(key, "aws:") || key == aws.VolumeLockTagKey || key == aws.IdempotencyTagKey {
+			continue
+		}
+		tags[key] = value
+	}
+	if retag != nil {
+		for _, key := range retag.Remove {
+			delete(tags, key)
+		}
+		for key, value := range retag.Set {
+			tags[key] = value
+		}
+	}
+	return tags
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+)
+
+func TestTransferTags(t *testing.T) {
+	source := map[string]string{
+		"team":                        "data",
+		"backup":                      "daily",
+		"aws:dlm:lifecycle-policy-id": "policy-1",
+		aws.VolumeLockTagKey:          "migration-1",
+		aws.IdempotencyTagKey:         "key",
+	}
+
+	tests := []struct {
+		name  string
+		retag *migrationv1alpha1.BackupRetagConfig
+		want  map[string]string
+	}{
+		{
+			name: "copies user tags",
+			want: map[string]string{"team": "data", "backup": "daily"},
+		},
+		{
+			name: "applies retag",
+			retag: &migrationv1alpha1.BackupRetagConfig{
+				Set:    map[string]string{"backup": "dest-daily"},
+				Remove: []string{"team"},
+			},
+			want: map[string]string{"backup": "dest-daily"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transferTags(source, tt.retag); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("transferTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransferVolumeConfig(t *testing.T) {
+	m := &migrationv1alpha1.StatefulSetMigration{}
+	source := &aws.VolumeInfo{
+		AvailabilityZone: "us-east-1a",
+		VolumeType:       "gp2",
+		Size:             20000,
+		IOPS:             16000,
+	}
+
+	cfg := transferVolumeConfig(m, source)
+	if cfg.AvailabilityZone != "us-east-1a" || cfg.VolumeType != "gp2" || cfg.SizeGiB != 20000 || cfg.IOPS != 0 {
+		t.Errorf("transferVolumeConfig() = %+v, want the source's zone, type and size without gp2's baseline IOPS", cfg)
+	}
+	if err := aws.CheckVolumeConfig(cfg); err == nil {
+		t.Error("CheckVolumeConfig() error = nil, want a gp2 volume over 16 TiB rejected")
+	}
+}
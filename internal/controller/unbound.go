@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// EventUnboundPVC is recorded when a replica whose source PVC has no PV is
+// given a new volume in the destination
+const EventUnboundPVC = "UnboundPVC"
+
+// unboundPVCPolicy returns spec.unboundPVCs, defaulting to Fail
+func unboundPVCPolicy(m *migrationv1alpha1.StatefulSetMigration) migrationv1alpha1.UnboundPVCPolicy {
+	if m.Spec.UnboundPVCs == "" {
+		return migrationv1alpha1.UnboundPVCFail
+	}
+	return m.Spec.UnboundPVCs
+}
+
+// unboundReplica reports whether the replica at index is in status.unboundPVCs
+func unboundReplica(m *migrationv1alpha1.StatefulSetMigration, index int) bool {
+	return slices.ContainsFunc(m.Status.UnboundPVCs, func(u migrationv1alpha1.UnboundPVCInfo) bool { return u.Index == index })
+}
+
+// claimState names a PVC with its phase
+func claimState(pvc *corev1.PersistentVolumeClaim) string {
+	if pvc.Status.Phase == "" {
+		return pvc.Name
+	}
+	return fmt.Sprintf("%s (%s)", pvc.Name, pvc.Status.Phase)
+}
+
+// checkUnboundPVCs fails pre-flight on source PVCs without a PV, which
+// usually belong to ordinals that never started and so hold no data. Under
+// spec.unboundPVCs Provision they are recorded in status.unboundPVCs
+// instead, so the migration knows up front which replicas get new volumes.
+func (r *StatefulSetMigrationReconciler) checkUnboundPVCs(m *migrationv1alpha1.StatefulSetMigration, pvcs []*corev1.PersistentVolumeClaim) error {
+	m.Status.UnboundPVCs = nil
+	if len(pvcs) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(pvcs))
+	for _, pvc := range pvcs {
+		names = append(names, claimState(pvc))
+	}
+	if unboundPVCPolicy(m) != migrationv1alpha1.UnboundPVCProvision {
+		return fmt.Errorf("PVCs %s have no PV; set spec.unboundPVCs to Provision to give their replicas new, empty volumes in the destination",
+			strings.Join(names, ", "))
+	}
+	for _, pvc := range pvcs {
+		index, err := strconv.Atoi(pvc.Name[strings.LastIndex(pvc.Name, "-")+1:])
+		if err != nil {
+			return fmt.Errorf("PVC %s has no pod index: %w", pvc.Name, err)
+		}
+		m.Status.UnboundPVCs = append(m.Status.UnboundPVCs, migrationv1alpha1.UnboundPVCInfo{
+			Index:   index,
+			PVCName: pvc.Name,
+			Phase:   string(pvc.Status.Phase),
+		})
+	}
+	r.recordHistory(m, StepProvisionVolume, "", migrationv1alpha1.HistoryResultStarted,
+		fmt.Sprintf("No PV, so the destination provisions new volumes: %s", strings.Join(names, ", ")))
+	return nil
+}
+
+// provisionUnboundReplica moves a replica whose source PVC has no PV, and
+// returns false when the PVC has one, so the volume moves as usual. Its
+// source pod cannot have started without the volume, so it is deleted
+// without quiescing, and the destination StatefulSet provisions a new volume
+// from its claim template when it creates the pod. Without spec.unboundPVCs
+// Provision the move fails naming the PVC.
+func (r *StatefulSetMigrationReconciler) provisionUnboundReplica(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceClient *multicluster.ClusterClient, mv *podMove) (bool, error) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := sourceClient.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: mv.pvcName}, pvc); err != nil {
+		return false, fmt.Errorf("failed to get source PVC %s: %w", mv.pvcName, err)
+	}
+	if pvc.Spec.VolumeName != "" {
+		return false, nil
+	}
+	if unboundPVCPolicy(m) != migrationv1alpha1.UnboundPVCProvision {
+		return false, fmt.Errorf("source PVC %s has no PV; set spec.unboundPVCs to Provision to give its replica a new, empty volume in the destination",
+			claimState(pvc))
+	}
+
+	log.FromContext(ctx).Info("Source PVC has no PV, provisioning a new volume in the destination", "pvc", mv.pvcName)
+	if err := deleteIfExists(ctx, sourceClient, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: mv.podName}, &corev1.Pod{}); err != nil {
+		return false, fmt.Errorf("failed to delete source pod: %w", err)
+	}
+	forgetOrphanedPod(m, mv.podName)
+	if !unboundReplica(m, mv.index) {
+		m.Status.UnboundPVCs = append(m.Status.UnboundPVCs, migrationv1alpha1.UnboundPVCInfo{
+			Index:   mv.index,
+			PVCName: mv.pvcName,
+			Phase:   string(pvc.Status.Phase),
+		})
+	}
+	r.event(m, corev1.EventTypeWarning, EventUnboundPVC,
+		fmt.Sprintf("Source PVC %s has no PV; %s gets a new, empty volume in the destination", claimState(pvc), mv.podName))
+	mv.provision = true
+	return true, nil
+}
+
+// finishProvisionedPod waits for the destination pod of a replica moved
+// without a volume, and records it in status.migratedPods with no volume.
+// In VolumesOnly mode there is no pod to wait for: the user's StatefulSet
+// provisions the volume.
+func (r *StatefulSetMigrationReconciler) finishProvisionedPod(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destClient *multicluster.ClusterClient, mv *podMove) error {
+	if !volumesOnly(m) {
+		if err := r.waitForDestPod(ctx, m, destClient, mv.podName); err != nil {
+			return err
+		}
+	}
+	r.recordHistory(m, StepProvisionVolume, historyObject("PersistentVolumeClaim", m.Spec.DestNamespace, mv.pvcName),
+		migrationv1alpha1.HistoryResultSucceeded, "")
+	recordMigratedPod(m, migrationv1alpha1.MigratedPodInfo{
+		Index:      mv.index,
+		PodName:    mv.podName,
+		MigratedAt: metav1.NewTime(r.clock().Now()),
+	})
+	log.FromContext(ctx).Info("Pod migrated with a new volume", "pod", mv.podName)
+	return nil
+}
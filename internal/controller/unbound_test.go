@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestCheckUnboundPVCs(t *testing.T) {
+	r := &StatefulSetMigrationReconciler{}
+	pending := []*corev1.PersistentVolumeClaim{{
+		ObjectMeta: metav1.ObjectMeta{Name: "data-web-2"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}}
+	tests := []struct {
+		name    string
+		policy  migrationv1alpha1.UnboundPVCPolicy
+		pvcs    []*corev1.PersistentVolumeClaim
+		wantErr bool
+		want    []migrationv1alpha1.UnboundPVCInfo
+	}{
+		{name: "all bound"},
+		{name: "default fails", pvcs: pending, wantErr: true},
+		{name: "fail", policy: migrationv1alpha1.UnboundPVCFail, pvcs: pending, wantErr: true},
+		{name: "provision", policy: migrationv1alpha1.UnboundPVCProvision, pvcs: pending,
+			want: []migrationv1alpha1.UnboundPVCInfo{{Index: 2, PVCName: "data-web-2", Phase: "Pending"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{UnboundPVCs: tt.policy}}
+			m.Status.UnboundPVCs = []migrationv1alpha1.UnboundPVCInfo{{Index: 5, PVCName: "data-web-5"}}
+			err := r.checkUnboundPVCs(m, tt.pvcs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkUnboundPVCs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(m.Status.UnboundPVCs) != len(tt.want) || (len(tt.want) > 0 && m.Status.UnboundPVCs[0] != tt.want[0]) {
+				t.Errorf("unboundPVCs = %+v, want %+v", m.Status.UnboundPVCs, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvisionUnboundReplica(t *testing.T) {
+	ctx := context.Background()
+	bound := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-0"},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-web-0"},
+	}
+	unbound := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-1"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "web-1"}}
+
+	for _, policy := range []migrationv1alpha1.UnboundPVCPolicy{"", migrationv1alpha1.UnboundPVCProvision} {
+		t.Run(string(policy), func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(bound, unbound, pod).Build()
+			cc := &multicluster.ClusterClient{Client: c}
+			r := &StatefulSetMigrationReconciler{Recorder: record.NewFakeRecorder(10)}
+			m := &migrationv1alpha1.StatefulSetMigration{
+				Spec: migrationv1alpha1.StatefulSetMigrationSpec{SourceNamespace: "prod", StatefulSetName: "web", UnboundPVCs: policy},
+			}
+
+			mv := &podMove{index: 0, podName: "web-0", pvcName: "data-web-0"}
+			if provisioned, err := r.provisionUnboundReplica(ctx, m, cc, mv); provisioned || err != nil {
+				t.Fatalf("bound PVC: provisioned = %v, error = %v, want the volume moved", provisioned, err)
+			}
+
+			mv = &podMove{index: 1, podName: "web-1", pvcName: "data-web-1"}
+			provisioned, err := r.provisionUnboundReplica(ctx, m, cc, mv)
+			if policy == "" {
+				if err == nil {
+					t.Fatal("unbound PVC without Provision: want an error")
+				}
+				if err := c.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "web-1"}, &corev1.Pod{}); err != nil {
+					t.Errorf("source pod: %v, want it kept", err)
+				}
+				return
+			}
+			if err != nil || !provisioned || !mv.provision {
+				t.Fatalf("provisioned = %v, provision = %v, error = %v", provisioned, mv.provision, err)
+			}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: "prod", Name: "web-1"}, &corev1.Pod{}); !apierrors.IsNotFound(err) {
+				t.Errorf("source pod: %v, want it deleted", err)
+			}
+			if !unboundReplica(m, 1) {
+				t.Errorf("unboundPVCs = %+v, want web-1 recorded", m.Status.UnboundPVCs)
+			}
+		})
+	}
+}
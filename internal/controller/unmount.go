@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// Event reasons for the source VolumeAttachment of a pod being migrated
+const (
+	EventVolumeUnmounting   = "VolumeUnmounting"
+	EventVolumeDetaching    = "VolumeDetaching"
+	EventVolumeDetachFailed = "VolumeDetachFailed"
+	EventCSINodeUnavailable = "CSINodePluginUnavailable"
+)
+
+// attachmentStage is how far the source cluster has got releasing a volume
+type attachmentStage string
+
+const (
+	// stageUnmounting waits for the kubelet to unmount the volume, after
+	// which the attach/detach controller deletes the VolumeAttachment
+	stageUnmounting attachmentStage = "Unmounting"
+
+	// stageDetaching waits for the CSI attacher to detach the volume and
+	// remove the VolumeAttachment's finalizer
+	stageDetaching attachmentStage = "Detaching"
+)
+
+// sourceAttachment returns the source cluster's VolumeAttachment of a PV, or nil when there is none
+func sourceAttachment(ctx context.Context, cc *multicluster.ClusterClient, pvName string) (*storagev1.VolumeAttachment, error) {
+	attachments := &storagev1.VolumeAttachmentList{}
+	if err := cc.Client.List(ctx, attachments); err != nil {
+		return nil, fmt.Errorf("failed to list source VolumeAttachments: %w", err)
+	}
+	for i := range attachments.Items {
+		va := &attachments.Items[i]
+		if va.Spec.Source.PersistentVolumeName != nil && *va.Spec.Source.PersistentVolumeName == pvName {
+			return va, nil
+		}
+	}
+	return nil, nil
+}
+
+// unmountBlockedError is returned when the node a volume is attached to
+// cannot unmount it, which waiting longer does not fix
+type unmountBlockedError struct {
+	message string
+}
+
+func (e *unmountBlockedError) Error() string {
+	return e.message + "; fix the node or set spec.forceDetach"
+}
+
+// attachmentStageOf returns the stage a VolumeAttachment is in
+func attachmentStageOf(va *storagev1.VolumeAttachment) attachmentStage {
+	if va.DeletionTimestamp != nil {
+		return stageDetaching
+	}
+	return stageUnmounting
+}
+
+// unmountProblem returns why the node a VolumeAttachment is on cannot
+// unmount the volume, or "" when its EBS CSI node plugin looks healthy. The
+// kubelet unmounts through the node plugin, so a dead plugin or node
+// otherwise just looks like a slow detach.
+func unmountProblem(ctx context.Context, cc *multicluster.ClusterClient, va *storagev1.VolumeAttachment) (string, error) {
+	nodeName := va.Spec.NodeName
+	node := &corev1.Node{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("node %s no longer exists", nodeName), nil
+		}
+		return "", fmt.Errorf("failed to get source node %s: %w", nodeName, err)
+	}
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady && c.Status != corev1.ConditionTrue {
+			return fmt.Sprintf("node %s is not Ready (%s)", nodeName, c.Reason), nil
+		}
+	}
+
+	// In-tree volumes are unmounted by the kubelet itself
+	if va.Spec.Attacher != migration.EBSCSIDriver {
+		return "", nil
+	}
+	csiNode := &storagev1.CSINode{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Name: nodeName}, csiNode); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Sprintf("node %s has no CSINode, so no CSI node plugin is registered on it", nodeName), nil
+		}
+		return "", fmt.Errorf("failed to get source CSINode %s: %w", nodeName, err)
+	}
+	if !slices.ContainsFunc(csiNode.Spec.Drivers, func(d storagev1.CSINodeDriver) bool { return d.Name == migration.EBSCSIDriver }) {
+		return fmt.Sprintf("the %s node plugin is not registered on node %s", migration.EBSCSIDriver, nodeName), nil
+	}
+	return "", nil
+}
+
+// waitForSourceUnmount waits for the source cluster to remove the PV's
+// VolumeAttachment, which it does once the kubelet has unmounted the volume
+// and the CSI attacher has detached it. Each stage, and any detach error, is
+// reported as an event on the migration. While the volume is unmounting, a
+// node that is gone, NotReady or without the EBS CSI node plugin fails the
+// wait straight away. With spec.forceDetach, or the DetachBlocked fallback,
+// the EBS detach poll takes over instead: it force-detaches, or falls back
+// for, a volume whose instance is down, and otherwise keeps waiting. The
+// wait is bounded by the detach timeout of the volume's claim template.
+func (r *StatefulSetMigrationReconciler) waitForSourceUnmount(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient, claimTemplate, pvName, volumeID string) error {
+	logger := log.FromContext(ctx)
+	object := historyObject("PersistentVolume", "", pvName)
+
+	va, err := sourceAttachment(ctx, cc, pvName)
+	if err != nil || va == nil {
+		return err
+	}
+	r.recordHistory(m, StepUnmountVolume, object, migrationv1alpha1.HistoryResultStarted,
+		fmt.Sprintf("VolumeAttachment %s on node %s", va.Name, va.Spec.NodeName))
+
+	defer metrics.TrackWait(StepUnmountVolume)()
+	r.beginVolumeWait(ctx, m, volumeID, StepUnmountVolume, migrationv1alpha1.VolumeWaitSourceKubernetes, attachmentState(va))
+	defer endVolumeWait(m, volumeID)
+	timeout := r.clock().NewTimer(volumeDetachTimeout(m, claimTemplate))
+	defer timeout.Stop()
+	// VolumeAttachments are cluster-scoped and never cached, so reads are live
+	ticker := r.clock().NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	var stage attachmentStage
+	var detachError string
+	for {
+		r.probeVolumeWait(ctx, m, volumeID, attachmentState(va))
+		if current := attachmentStageOf(va); current != stage {
+			stage = current
+			logger.Info("Waiting for source VolumeAttachment", "pv", pvName, "volumeAttachment", va.Name,
+				"node", va.Spec.NodeName, "stage", stage)
+			switch stage {
+			case stageUnmounting:
+				r.event(m, corev1.EventTypeNormal, EventVolumeUnmounting,
+					fmt.Sprintf("Waiting for node %s to unmount PersistentVolume %s", va.Spec.NodeName, pvName))
+			case stageDetaching:
+				r.event(m, corev1.EventTypeNormal, EventVolumeDetaching,
+					fmt.Sprintf("Node %s unmounted PersistentVolume %s, waiting for it to be detached", va.Spec.NodeName, pvName))
+			}
+		}
+		if va.Status.DetachError != nil && va.Status.DetachError.Message != detachError {
+			detachError = va.Status.DetachError.Message
+			r.event(m, corev1.EventTypeWarning, EventVolumeDetachFailed,
+				fmt.Sprintf("Detaching PersistentVolume %s from node %s failed: %s", pvName, va.Spec.NodeName, detachError))
+		}
+
+		if stage == stageUnmounting {
+			problem, err := unmountProblem(ctx, cc, va)
+			if err != nil {
+				return err
+			}
+			if problem != "" {
+				message := fmt.Sprintf("PersistentVolume %s cannot be unmounted: %s", pvName, problem)
+				r.event(m, corev1.EventTypeWarning, EventCSINodeUnavailable, message)
+				r.recordHistory(m, StepUnmountVolume, object, migrationv1alpha1.HistoryResultFailed, problem)
+				if m.Spec.ForceDetach {
+					logger.Info("Ignoring unmount problem because spec.forceDetach is set", "pv", pvName, "problem", problem)
+					return nil
+				}
+				// The node may still be running with the volume attached, so
+				// only a stopped or terminated instance may fall back
+				if fallsBackOn(m, migrationv1alpha1.FallbackTriggerDetachBlocked) {
+					logger.Info("Waiting on the EBS detach because the instance decides the fallback", "pv", pvName, "problem", problem)
+					return nil
+				}
+				return &unmountBlockedError{message: message}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout.C():
+			return fmt.Errorf("timeout waiting for VolumeAttachment %s of PersistentVolume %s on node %s (%s)",
+				va.Name, pvName, va.Spec.NodeName, stage)
+		case <-ticker.C():
+		}
+
+		if va, err = sourceAttachment(ctx, cc, pvName); err != nil {
+			return err
+		}
+		if va == nil {
+			r.recordHistory(m, StepUnmountVolume, object, migrationv1alpha1.HistoryResultSucceeded, "")
+			return nil
+		}
+	}
+}
+
+// attachmentState describes a source VolumeAttachment for status.volumeWaits
+func attachmentState(va *storagev1.VolumeAttachment) string {
+	switch attachmentStageOf(va) {
+	case stageDetaching:
+		return fmt.Sprintf("node %s unmounted it, VolumeAttachment %s detaching", va.Spec.NodeName, va.Name)
+	default:
+		return fmt.Sprintf("node %s unmounting", va.Spec.NodeName)
+	}
+}
+
+// volumeDetachTimeout returns how long a volume of a claim template may
+// take to detach: its spec.volumePolicy entry's timeout, or the migration's
+func volumeDetachTimeout(m *migrationv1alpha1.StatefulSetMigration, claimTemplate string) time.Duration {
+	for _, p := range m.Spec.VolumePolicy {
+		if p.ClaimTemplate == claimTemplate && p.VolumeDetachTimeout != nil {
+			return p.VolumeDetachTimeout.Duration
+		}
+	}
+	if m.Spec.VolumeDetachTimeout != nil {
+		return m.Spec.VolumeDetachTimeout.Duration
+	}
+	return DefaultVolumeDetachTimeout
+}
+
+// checkVolumePolicy fails on spec.volumePolicy entries naming a claim
+// template the StatefulSet does not have, which would never apply
+func checkVolumePolicy(m *migrationv1alpha1.StatefulSetMigration, sts *appsv1.StatefulSet) error {
+	templates := make(map[string]bool, len(sts.Spec.VolumeClaimTemplates))
+	for _, t := range sts.Spec.VolumeClaimTemplates {
+		templates[t.Name] = true
+	}
+	var unknown []string
+	for _, p := range m.Spec.VolumePolicy {
+		if !templates[p.ClaimTemplate] {
+			unknown = append(unknown, p.ClaimTemplate)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("spec.volumePolicy names claim templates the StatefulSet does not have: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
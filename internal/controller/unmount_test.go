@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestWaitForSourceUnmount(t *testing.T) {
+	node := func(ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: ready, Reason: "KubeletNotReady"},
+			}},
+		}
+	}
+	csiNode := func(drivers ...string) *storagev1.CSINode {
+		n := &storagev1.CSINode{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}}
+		for _, d := range drivers {
+			n.Spec.Drivers = append(n.Spec.Drivers, storagev1.CSINodeDriver{Name: d, NodeID: "i-0a"})
+		}
+		return n
+	}
+	attachment := func(detaching bool, detachError string) *storagev1.VolumeAttachment {
+		va := &storagev1.VolumeAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: "csi-0123"},
+			Spec: storagev1.VolumeAttachmentSpec{
+				Attacher: migration.EBSCSIDriver,
+				NodeName: "node-a",
+				Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: ptr.To("pv-web-0")},
+			},
+		}
+		if detaching {
+			va.DeletionTimestamp = &metav1.Time{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			va.Finalizers = []string{"external-attacher/ebs-csi-aws-com"}
+		}
+		if detachError != "" {
+			va.Status.DetachError = &storagev1.VolumeError{Message: detachError}
+		}
+		return va
+	}
+	other := &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "csi-4567"},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: migration.EBSCSIDriver,
+			NodeName: "node-a",
+			Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: ptr.To("pv-web-1")},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		objects     []client.Object
+		forceDetach bool
+		fallback    *migrationv1alpha1.StrategyFallback
+		wantErr     string
+		wantEvents  []string
+	}{
+		{
+			name:    "no attachment",
+			objects: []client.Object{other, node(corev1.ConditionTrue), csiNode(migration.EBSCSIDriver)},
+		},
+		{
+			name:       "node not ready",
+			objects:    []client.Object{attachment(false, ""), node(corev1.ConditionUnknown), csiNode(migration.EBSCSIDriver)},
+			wantErr:    "node node-a is not Ready (KubeletNotReady); fix the node or set spec.forceDetach",
+			wantEvents: []string{"Normal VolumeUnmounting", "Warning CSINodePluginUnavailable"},
+		},
+		{
+			name:        "node not ready with forceDetach",
+			objects:     []client.Object{attachment(false, ""), node(corev1.ConditionFalse), csiNode(migration.EBSCSIDriver)},
+			forceDetach: true,
+			wantEvents:  []string{"Normal VolumeUnmounting", "Warning CSINodePluginUnavailable"},
+		},
+		{
+			name:       "node not ready with the DetachBlocked fallback",
+			objects:    []client.Object{attachment(false, ""), node(corev1.ConditionFalse), csiNode(migration.EBSCSIDriver)},
+			fallback:   &migrationv1alpha1.StrategyFallback{},
+			wantEvents: []string{"Normal VolumeUnmounting", "Warning CSINodePluginUnavailable"},
+		},
+		{
+			name:       "node not ready with only the ZoneMismatch fallback",
+			objects:    []client.Object{attachment(false, ""), node(corev1.ConditionFalse), csiNode(migration.EBSCSIDriver)},
+			fallback:   &migrationv1alpha1.StrategyFallback{On: []migrationv1alpha1.FallbackTrigger{migrationv1alpha1.FallbackTriggerZoneMismatch}},
+			wantErr:    "node node-a is not Ready",
+			wantEvents: []string{"Normal VolumeUnmounting", "Warning CSINodePluginUnavailable"},
+		},
+		{
+			name:       "node plugin not registered",
+			objects:    []client.Object{attachment(false, ""), node(corev1.ConditionTrue), csiNode("efs.csi.aws.com")},
+			wantErr:    "the ebs.csi.aws.com node plugin is not registered on node node-a",
+			wantEvents: []string{"Normal VolumeUnmounting", "Warning CSINodePluginUnavailable"},
+		},
+		{
+			name:       "no CSINode",
+			objects:    []client.Object{attachment(false, ""), node(corev1.ConditionTrue)},
+			wantErr:    "node node-a has no CSINode",
+			wantEvents: []string{"Normal VolumeUnmounting", "Warning CSINodePluginUnavailable"},
+		},
+		{
+			name:       "node gone",
+			objects:    []client.Object{attachment(false, "")},
+			wantErr:    "node node-a no longer exists",
+			wantEvents: []string{"Normal VolumeUnmounting", "Warning CSINodePluginUnavailable"},
+		},
+		{
+			name:       "stuck unmounting",
+			objects:    []client.Object{attachment(false, ""), node(corev1.ConditionTrue), csiNode(migration.EBSCSIDriver)},
+			wantErr:    "timeout waiting for VolumeAttachment csi-0123 of PersistentVolume pv-web-0 on node node-a (Unmounting)",
+			wantEvents: []string{"Normal VolumeUnmounting"},
+		},
+		{
+			name:       "stuck detaching",
+			objects:    []client.Object{attachment(true, "rpc error: DetachVolume timed out"), node(corev1.ConditionFalse)},
+			wantErr:    "(Detaching)",
+			wantEvents: []string{"Normal VolumeDetaching", "Warning VolumeDetachFailed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := &multicluster.ClusterClient{
+				Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.objects...).Build(),
+			}
+			clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			recorder := record.NewFakeRecorder(10)
+			m := &migrationv1alpha1.StatefulSetMigration{ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web"}}
+			m.Spec.ForceDetach = tt.forceDetach
+			m.Spec.StrategyFallback = tt.fallback
+			scheme := runtime.NewScheme()
+			if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+			r := &StatefulSetMigrationReconciler{Client: c, Clock: clk, Recorder: recorder}
+
+			done := make(chan error, 1)
+			go func() {
+				done <- r.waitForSourceUnmount(context.Background(), m, cc, "data", "pv-web-0", "vol-0123")
+			}()
+
+			var err error
+			deadline := time.After(10 * time.Second)
+		wait:
+			for {
+				select {
+				case err = <-done:
+					break wait
+				case <-deadline:
+					t.Fatal("waitForSourceUnmount() did not return on the fake clock")
+				case <-time.After(time.Millisecond):
+					if clk.HasWaiters() {
+						clk.Step(time.Minute)
+					}
+				}
+			}
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("waitForSourceUnmount() error = %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("waitForSourceUnmount() error = %v, want %q", err, tt.wantErr)
+			}
+
+			close(recorder.Events)
+			var events []string
+			for event := range recorder.Events {
+				fields := strings.Fields(event)
+				events = append(events, fields[0]+" "+fields[1])
+			}
+			if strings.Join(events, ", ") != strings.Join(tt.wantEvents, ", ") {
+				t.Errorf("events = %v, want %v", events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestVolumeDetachTimeout(t *testing.T) {
+	policy := []migrationv1alpha1.VolumePolicy{
+		{ClaimTemplate: "data", VolumeDetachTimeout: &metav1.Duration{Duration: 20 * time.Minute}},
+		{ClaimTemplate: "logs"},
+	}
+	tests := []struct {
+		name          string
+		spec          migrationv1alpha1.StatefulSetMigrationSpec
+		claimTemplate string
+		want          time.Duration
+	}{
+		{name: "default", claimTemplate: "data", want: DefaultVolumeDetachTimeout},
+		{name: "migration timeout", spec: migrationv1alpha1.StatefulSetMigrationSpec{VolumeDetachTimeout: &metav1.Duration{Duration: 8 * time.Minute}},
+			claimTemplate: "data", want: 8 * time.Minute},
+		{name: "policy overrides", spec: migrationv1alpha1.StatefulSetMigrationSpec{VolumeDetachTimeout: &metav1.Duration{Duration: 8 * time.Minute}, VolumePolicy: policy},
+			claimTemplate: "data", want: 20 * time.Minute},
+		{name: "policy without a timeout", spec: migrationv1alpha1.StatefulSetMigrationSpec{VolumeDetachTimeout: &metav1.Duration{Duration: 8 * time.Minute}, VolumePolicy: policy},
+			claimTemplate: "logs", want: 8 * time.Minute},
+		{name: "other template", spec: migrationv1alpha1.StatefulSetMigrationSpec{VolumePolicy: policy},
+			claimTemplate: "cache", want: DefaultVolumeDetachTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: tt.spec}
+			if got := volumeDetachTimeout(m, tt.claimTemplate); got != tt.want {
+				t.Errorf("volumeDetachTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckVolumePolicy(t *testing.T) {
+	sts := &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+	}}}
+	tests := []struct {
+		name    string
+		policy  []migrationv1alpha1.VolumePolicy
+		wantErr string
+	}{
+		{name: "no policy"},
+		{name: "known template", policy: []migrationv1alpha1.VolumePolicy{{ClaimTemplate: "data"}}},
+		{name: "unknown template", policy: []migrationv1alpha1.VolumePolicy{{ClaimTemplate: "data"}, {ClaimTemplate: "logs"}}, wantErr: "does not have: logs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{VolumePolicy: tt.policy}}
+			err := checkVolumePolicy(m, sts)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("checkVolumePolicy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/internal/preflight"
+)
+
+// PreFlightOutcome is how a pre-flight check came out when validating a migration
+type PreFlightOutcome string
+
+const (
+	// PreFlightPass means the check passed
+	PreFlightPass PreFlightOutcome = "Pass"
+
+	// PreFlightWarn means a Warning check failed; the migration would continue
+	PreFlightWarn PreFlightOutcome = "Warn"
+
+	// PreFlightFail means an Error check failed; the migration would fail
+	PreFlightFail PreFlightOutcome = "Fail"
+)
+
+// PreFlightResult is the outcome of one check run by ValidateMigration
+type PreFlightResult struct {
+	Check    string             `json:"check"`
+	Severity preflight.Severity `json:"severity"`
+	Outcome  PreFlightOutcome   `json:"outcome"`
+	Message  string             `json:"message,omitempty"`
+}
+
+// advisoryConditions are the conditions the connection checks warn
+// through, reported as checks of their own
+var advisoryConditions = []struct {
+	check     string
+	condition string
+}{
+	{"Clock skew", ConditionClockSkew},
+	{"Certificate expiry", ConditionCertificateExpiry},
+}
+
+// ValidateMigration runs every pre-flight check the migration would run
+// against the two clusters, without stopping at the first failure, and
+// returns each one's outcome. Only the migration's status is changed. It
+// fails when the source StatefulSet or its volumes cannot be read, as
+// pre-flight does before any check runs.
+func (r *StatefulSetMigrationReconciler) ValidateMigration(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceClient, destClient *multicluster.ClusterClient) ([]PreFlightResult, error) {
+	applySpec(m)
+	in, message := r.preFlightInput(ctx, m, sourceClient, destClient)
+	if message != "" {
+		return nil, errors.New(message)
+	}
+
+	var results []PreFlightResult
+	for _, advisory := range advisoryConditions {
+		result := PreFlightResult{Check: advisory.check, Severity: preflight.SeverityWarning, Outcome: PreFlightPass}
+		if cond := meta.FindStatusCondition(m.Status.Conditions, advisory.condition); cond != nil {
+			result.Outcome, result.Message = PreFlightWarn, cond.Message
+		}
+		results = append(results, result)
+	}
+	return append(results, validatePreFlight(ctx, r.preFlightChecks(), in)...), nil
+}
+
+// validatePreFlight runs every check, unlike runPreFlightChecks, which stops
+// at the first Error check to fail
+func validatePreFlight(ctx context.Context, checks []PreFlightCheck, in *PreFlightInput) []PreFlightResult {
+	results := make([]PreFlightResult, 0, len(checks))
+	for _, check := range checks {
+		result := PreFlightResult{Check: check.Name(), Severity: check.Severity(), Outcome: PreFlightPass}
+		if err := check.Check(ctx, in); err != nil {
+			result.Outcome, result.Message = PreFlightFail, err.Error()
+			if check.Severity() == preflight.SeverityWarning {
+				result.Outcome = PreFlightWarn
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
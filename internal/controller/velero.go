@@ -0,0 +1,287 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/internal/velero"
+)
+
+const (
+	// DefaultVeleroTimeout is the default time allowed for the Velero backup and restore together
+	DefaultVeleroTimeout = 30 * time.Minute
+
+	// ConditionResourcesReplicated reports the progress of Velero resource replication
+	ConditionResourcesReplicated = "ResourcesReplicated"
+)
+
+// veleroName returns the name of a migration's Velero backup and restore.
+// Velero copies the name into a label, so it is kept to 63 characters; the
+// UID prefix keeps recreated migrations with the same name apart.
+func veleroName(m *migrationv1alpha1.StatefulSetMigration) string {
+	uid := string(m.UID)
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+	name := m.Name
+	if max := 63 - len(uid) - 1; len(name) > max {
+		name = strings.TrimRight(name[:max], "-.")
+	}
+	return name + "-" + uid
+}
+
+// veleroNamespace returns the namespace Velero runs in
+func veleroNamespace(cfg *migrationv1alpha1.VeleroConfig) string {
+	if cfg.Namespace != "" {
+		return cfg.Namespace
+	}
+	return velero.DefaultNamespace
+}
+
+// veleroTimeout returns the time allowed for the backup and restore together
+func veleroTimeout(cfg *migrationv1alpha1.VeleroConfig) time.Duration {
+	if cfg.Timeout != nil {
+		return cfg.Timeout.Duration
+	}
+	return DefaultVeleroTimeout
+}
+
+// veleroLabels returns the labels of a migration's Velero backup and restore
+func veleroLabels(m *migrationv1alpha1.StatefulSetMigration) map[string]string {
+	return map[string]string{velero.MigrationLabel: string(m.UID)}
+}
+
+// checkVeleroInstalled fails pre-flight when a cluster has no Velero namespace
+func checkVeleroInstalled(ctx context.Context, cc *multicluster.ClusterClient, namespace string) error {
+	ns := &corev1.Namespace{}
+	if err := cc.Client.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("velero namespace %q does not exist", namespace)
+		}
+		return fmt.Errorf("failed to check velero namespace %q: %w", namespace, err)
+	}
+	return nil
+}
+
+// reconcileReplicatingResources handles the ReplicatingResources phase. The
+// source namespace is backed up with Velero, without the StatefulSet, its
+// pods or its volumes, and restored into the destination namespace so the
+// migrated StatefulSet finds its Services, ConfigMaps and Secrets in place.
+// Each reconcile advances one step and requeues while Velero is working.
+func (r *StatefulSetMigrationReconciler) reconcileReplicatingResources(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	before := m.Status.DeepCopy()
+	cfg := m.Spec.Velero
+	if cfg == nil {
+		return r.failMigration(ctx, m, "Migration is replicating resources but spec.velero is not set")
+	}
+
+	if m.Status.Velero == nil {
+		now := metav1.NewTime(r.clock().Now())
+		m.Status.Velero = &migrationv1alpha1.VeleroStatus{BackupName: veleroName(m), StartedAt: &now}
+	}
+	status := m.Status.Velero
+	if status.StartedAt != nil && r.clock().Since(status.StartedAt.Time) > veleroTimeout(cfg) {
+		return r.failMigration(ctx, m, fmt.Sprintf("Velero did not replicate resources within %s (backup %s, restore %s)",
+			veleroTimeout(cfg), phaseOrPending(status.BackupPhase), phaseOrPending(status.RestorePhase)))
+	}
+
+	sourceClient, err := r.getSourceClient(ctx, m)
+	if err != nil {
+		return r.failMigration(ctx, m, fmt.Sprintf("Failed to get source client: %v", err))
+	}
+	destClient, err := r.getDestClient(ctx, m)
+	if err != nil {
+		return r.failMigration(ctx, m, fmt.Sprintf("Failed to get destination client: %v", err))
+	}
+	namespace := veleroNamespace(cfg)
+
+	// Suspend jobs first so the backup captures them suspended and the
+	// restore does not start them in the destination before their PVCs exist
+	if err := r.suspendSourceJobs(ctx, m, sourceClient); err != nil {
+		return r.failMigration(ctx, m, fmt.Sprintf("Failed to suspend source jobs: %v", err))
+	}
+
+	// Back up the source namespace
+	if velero.OutcomeOf(status.BackupPhase) == velero.OutcomeRunning {
+		backup, err := r.ensureBackup(ctx, m, sourceClient)
+		if err != nil {
+			return r.failMigration(ctx, m, fmt.Sprintf("Failed to create Velero backup: %v", err))
+		}
+		status.BackupPhase = velero.Phase(backup)
+
+		switch velero.OutcomeOf(status.BackupPhase) {
+		case velero.OutcomeRunning:
+			return r.waitForVelero(ctx, m, before, "BackupInProgress", fmt.Sprintf("Waiting for Velero backup %s/%s (%s)", namespace, status.BackupName, phaseOrPending(status.BackupPhase)))
+		case velero.OutcomeFailed:
+			return r.failMigration(ctx, m, fmt.Sprintf("Velero backup %s/%s %s: %s", namespace, status.BackupName, status.BackupPhase, velero.FailureReason(backup)))
+		case velero.OutcomePartiallyFailed:
+			if !cfg.AllowPartialFailure {
+				return r.failMigration(ctx, m, fmt.Sprintf("Velero backup %s/%s partially failed: %s", namespace, status.BackupName, velero.FailureReason(backup)))
+			}
+		}
+		r.recordHistory(m, StepVeleroBackup, historyObject("Backup", namespace, status.BackupName),
+			migrationv1alpha1.HistoryResultSucceeded, veleroMessage(status.BackupPhase, backup))
+		logger.Info("Velero backup finished", "backup", status.BackupName, "phase", status.BackupPhase)
+	}
+
+	sourceSTS := &appsv1.StatefulSet{}
+	if err := sourceClient.Client.Get(ctx, types.NamespacedName{
+		Namespace: m.Spec.SourceNamespace,
+		Name:      m.Spec.StatefulSetName,
+	}, sourceSTS); err != nil {
+		return r.failMigration(ctx, m, fmt.Sprintf("Failed to get source StatefulSet: %v", err))
+	}
+
+	// Restore into the destination namespace once the destination's Velero
+	// has synced the backup from the shared storage location
+	if status.RestoreName == "" {
+		synced := &unstructured.Unstructured{}
+		synced.SetGroupVersionKind(velero.BackupGVK)
+		err := destClient.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: status.BackupName}, synced)
+		if apierrors.IsNotFound(err) {
+			return r.waitForVelero(ctx, m, before, "WaitingForBackupSync", fmt.Sprintf("Waiting for backup %s to sync to the destination cluster's Velero", status.BackupName))
+		}
+		if err != nil {
+			return r.failMigration(ctx, m, fmt.Sprintf("Failed to get Velero backup in destination cluster: %v", err))
+		}
+
+		// A headless service of an IP family the destination does not serve
+		// would fail to restore; Velero rewrites its families instead
+		modifier, err := ensureIPFamilyModifier(ctx, m, sourceClient, destClient, sourceSTS.Spec.ServiceName)
+		if err != nil {
+			return r.failMigration(ctx, m, fmt.Sprintf("Failed to translate service IP families: %v", err))
+		}
+
+		restore := velero.NewRestore(velero.RestoreConfig{
+			Name:              status.BackupName,
+			Namespace:         namespace,
+			BackupName:        status.BackupName,
+			SourceNamespace:   m.Spec.SourceNamespace,
+			DestNamespace:     m.Spec.DestNamespace,
+			ExcludedResources: cfg.ExcludedResources,
+			ResourceModifier:  modifier,
+			Labels:            veleroLabels(m),
+		})
+		if err := destClient.Client.Create(ctx, restore); err != nil && !apierrors.IsAlreadyExists(err) {
+			return r.failMigration(ctx, m, fmt.Sprintf("Failed to create Velero restore: %v", err))
+		}
+		status.RestoreName = restore.GetName()
+		message := fmt.Sprintf("Restoring %s into %s", m.Spec.SourceNamespace, m.Spec.DestNamespace)
+		if modifier != "" {
+			message += fmt.Sprintf(", translating the IP families of service %s", sourceSTS.Spec.ServiceName)
+		}
+		r.recordHistory(m, StepVeleroRestore, historyObject("Restore", namespace, status.RestoreName),
+			migrationv1alpha1.HistoryResultStarted, message)
+	}
+
+	restore := &unstructured.Unstructured{}
+	restore.SetGroupVersionKind(velero.RestoreGVK)
+	if err := destClient.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: status.RestoreName}, restore); err != nil {
+		return r.failMigration(ctx, m, fmt.Sprintf("Failed to get Velero restore: %v", err))
+	}
+	status.RestorePhase = velero.Phase(restore)
+
+	switch velero.OutcomeOf(status.RestorePhase) {
+	case velero.OutcomeRunning:
+		return r.waitForVelero(ctx, m, before, "RestoreInProgress", fmt.Sprintf("Waiting for Velero restore %s/%s (%s)", namespace, status.RestoreName, phaseOrPending(status.RestorePhase)))
+	case velero.OutcomeFailed:
+		return r.failMigration(ctx, m, fmt.Sprintf("Velero restore %s/%s %s: %s", namespace, status.RestoreName, status.RestorePhase, velero.FailureReason(restore)))
+	case velero.OutcomePartiallyFailed:
+		if !cfg.AllowPartialFailure {
+			return r.failMigration(ctx, m, fmt.Sprintf("Velero restore %s/%s partially failed: %s", namespace, status.RestoreName, velero.FailureReason(restore)))
+		}
+	}
+	r.recordHistory(m, StepVeleroRestore, historyObject("Restore", namespace, status.RestoreName),
+		migrationv1alpha1.HistoryResultSucceeded, veleroMessage(status.RestorePhase, restore))
+
+	// Pre-flight skipped the headless service check because the restore creates it
+	if err := r.checkDestService(ctx, m, destClient, sourceSTS.Spec.ServiceName); err != nil {
+		return r.failMigration(ctx, m, fmt.Sprintf("Destination service check failed after Velero restore: %v", err))
+	}
+
+	logger.Info("Resources replicated, moving to FreezingSource", "restore", status.RestoreName)
+	m.Status.Phase = migrationv1alpha1.PhaseFreezingSource
+	r.setCondition(m, ConditionResourcesReplicated, metav1.ConditionTrue, "Restored",
+		fmt.Sprintf("Velero restore %s/%s %s", namespace, status.RestoreName, status.RestorePhase))
+
+	if err := r.Status().Update(ctx, m); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// ensureBackup returns the migration's Velero backup in the source cluster, creating it if needed
+func (r *StatefulSetMigrationReconciler) ensureBackup(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, cc *multicluster.ClusterClient) (*unstructured.Unstructured, error) {
+	cfg := m.Spec.Velero
+	key := types.NamespacedName{Namespace: veleroNamespace(cfg), Name: m.Status.Velero.BackupName}
+
+	backup := &unstructured.Unstructured{}
+	backup.SetGroupVersionKind(velero.BackupGVK)
+	err := cc.Client.Get(ctx, key, backup)
+	if err == nil {
+		return backup, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get backup %s: %w", key, err)
+	}
+
+	backup, err = velero.NewBackup(velero.BackupConfig{
+		Name:              key.Name,
+		Namespace:         key.Namespace,
+		SourceNamespace:   m.Spec.SourceNamespace,
+		IncludedResources: cfg.IncludedResources,
+		ExcludedResources: cfg.ExcludedResources,
+		LabelSelector:     cfg.LabelSelector,
+		StorageLocation:   cfg.StorageLocation,
+		Labels:            veleroLabels(m),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := cc.Client.Create(ctx, backup); err != nil {
+		return nil, fmt.Errorf("failed to create backup %s: %w", key, err)
+	}
+	r.recordHistory(m, StepVeleroBackup, historyObject("Backup", key.Namespace, key.Name),
+		migrationv1alpha1.HistoryResultStarted, fmt.Sprintf("Backing up %s", m.Spec.SourceNamespace))
+	return backup, nil
+}
+
+// waitForVelero records what replication is waiting for and requeues. before
+// is the status the reconcile started with; a poll that changed nothing is not written.
+func (r *StatefulSetMigrationReconciler) waitForVelero(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, before *migrationv1alpha1.StatefulSetMigrationStatus, reason, message string) (ctrl.Result, error) {
+	log.FromContext(ctx).Info(message)
+	r.setCondition(m, ConditionResourcesReplicated, metav1.ConditionFalse, reason, message)
+	if err := r.updateStatus(ctx, m, before); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: requeueDelay(DefaultRequeueDelay, m.UID)}, nil
+}
+
+// veleroMessage describes a finished backup or restore for the history
+func veleroMessage(phase string, obj *unstructured.Unstructured) string {
+	if reason := velero.FailureReason(obj); reason != "" {
+		return fmt.Sprintf("%s: %s", phase, reason)
+	}
+	return phase
+}
+
+func phaseOrPending(phase string) string {
+	if phase == "" {
+		return "Pending"
+	}
+	return phase
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestVeleroName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "web", want: "web-0f8c2d1e"},
+		{name: strings.Repeat("a", 60), want: strings.Repeat("a", 54) + "-0f8c2d1e"},
+		{name: strings.Repeat("a", 53) + "-b", want: strings.Repeat("a", 53) + "-0f8c2d1e"},
+	}
+
+	for _, tt := range tests {
+		m := &migrationv1alpha1.StatefulSetMigration{
+			ObjectMeta: metav1.ObjectMeta{Name: tt.name, UID: "0f8c2d1e-5a6b-4c7d-8e9f-0a1b2c3d4e5f"},
+		}
+		if got := veleroName(m); got != tt.want || len(got) > 63 {
+			t.Errorf("veleroName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
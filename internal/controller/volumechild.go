@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// volumeMigrationName returns the name of the VolumeMigration that moves a
+// replica's PVC with spec.volumeMigrations
+func volumeMigrationName(m *migrationv1alpha1.StatefulSetMigration, pvcName string) string {
+	return m.Name + "-" + pvcName
+}
+
+// volumeMigrationSpec returns the spec of the VolumeMigration that moves a
+// replica's PVC, carrying over the migration's volume settings
+func volumeMigrationSpec(m *migrationv1alpha1.StatefulSetMigration, mv *podMove) migrationv1alpha1.VolumeMigrationSpec {
+	spec := migrationv1alpha1.VolumeMigrationSpec{
+		SourceCluster:       m.Spec.SourceCluster,
+		SourceNamespace:     m.Spec.SourceNamespace,
+		PVCName:             mv.pvcName,
+		DestCluster:         m.Spec.DestCluster,
+		DestNamespace:       m.Spec.DestNamespace,
+		StorageClassMapping: m.Spec.StorageClassMapping,
+		DestPVNameTemplate:  m.Spec.DestPVNameTemplate,
+		MetadataPassthrough: m.Spec.MetadataPassthrough,
+		DataSourcePolicy:    m.Spec.DataSourcePolicy,
+		NodeOS:              m.Status.NodeOS,
+		ConvertInTreeEBS:    m.Status.ConvertInTreeEBS,
+		VolumeDetachTimeout: &metav1.Duration{Duration: volumeDetachTimeout(m, mv.claimTemplate)},
+		ForceDetach:         m.Spec.ForceDetach,
+		AWSConfig:           m.Spec.AWSConfig,
+	}
+	return *spec.DeepCopy()
+}
+
+// moveVolumeByChild moves a replica's volume through an owned VolumeMigration
+// and waits for it to finish. The source pod is already gone, so the child
+// waits for the detach and creates the destination PV and PVC; its status
+// and events show how far the move got. A child that failed in an earlier
+// attempt is retried.
+func (r *StatefulSetMigrationReconciler) moveVolumeByChild(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destClient *multicluster.ClusterClient, mv *podMove) error {
+	logger := log.FromContext(ctx)
+
+	vm, err := r.ensureVolumeMigration(ctx, m, mv)
+	if err != nil {
+		return err
+	}
+	object := historyObject("VolumeMigration", vm.Namespace, vm.Name)
+	logger.Info("Waiting for volume migration", "volumeMigration", vm.Name, "phase", vm.Status.Phase)
+
+	// The child's own detach timeout fails it first; the rest covers
+	// creating the destination PV and PVC
+	vm, err = r.waitForVolumeMigration(ctx, vm, volumeDetachTimeout(m, mv.claimTemplate)+DefaultPVCBoundTimeout)
+	if err != nil {
+		r.recordHistory(m, StepVolumeMigration, object, migrationv1alpha1.HistoryResultFailed, err.Error())
+		return err
+	}
+
+	destPV := &corev1.PersistentVolume{}
+	if err := destClient.Client.Get(ctx, types.NamespacedName{Name: vm.Status.DestPVName}, destPV); err != nil {
+		return fmt.Errorf("failed to get destination PV %s: %w", vm.Status.DestPVName, err)
+	}
+	destPVC := &corev1.PersistentVolumeClaim{}
+	if err := destClient.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.DestNamespace, Name: mv.pvcName}, destPVC); err != nil {
+		return fmt.Errorf("failed to get destination PVC %s: %w", mv.pvcName, err)
+	}
+	mv.destVolumeID = mv.volumeID
+	mv.result = &translate.TranslationResult{PV: destPV, PVC: destPVC, VolumeID: mv.volumeID}
+	r.recordHistory(m, StepVolumeMigration, object, migrationv1alpha1.HistoryResultSucceeded,
+		fmt.Sprintf("Volume %s bound to PV %s", vm.Status.VolumeID, vm.Status.DestPVName))
+	return nil
+}
+
+// ensureVolumeMigration creates the VolumeMigration for a replica's PVC, or
+// returns the existing one, annotating it to retry when it failed
+func (r *StatefulSetMigrationReconciler) ensureVolumeMigration(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, mv *podMove) (*migrationv1alpha1.VolumeMigration, error) {
+	name := volumeMigrationName(m, mv.pvcName)
+	vm := &migrationv1alpha1.VolumeMigration{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: m.Namespace, Name: name}, vm)
+	switch {
+	case err == nil:
+		if vm.Status.Phase == migrationv1alpha1.VolumePhaseFailed && vm.Annotations[AnnotationRetry] != "true" {
+			if vm.Annotations == nil {
+				vm.Annotations = map[string]string{}
+			}
+			vm.Annotations[AnnotationRetry] = "true"
+			if err := r.Update(ctx, vm); err != nil {
+				return nil, fmt.Errorf("failed to retry volume migration %s: %w", name, err)
+			}
+		}
+		return vm, nil
+	case !apierrors.IsNotFound(err):
+		return nil, fmt.Errorf("failed to get volume migration %s: %w", name, err)
+	}
+
+	labels := workloadLabels(&m.Spec)
+	labels[reportMigrationIDLabel] = m.Spec.MigrationID
+	vm = &migrationv1alpha1.VolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: m.Namespace,
+			Labels:    labels,
+		},
+		Spec: volumeMigrationSpec(m, mv),
+	}
+	if err := controllerutil.SetControllerReference(m, vm, r.Scheme); err != nil {
+		return nil, err
+	}
+	if err := r.Create(ctx, vm); err != nil {
+		return nil, fmt.Errorf("failed to create volume migration %s: %w", name, err)
+	}
+	return vm, nil
+}
+
+// waitForVolumeMigration waits for a VolumeMigration to complete. A failed
+// one still annotated to retry has not been picked up yet and is waited on.
+func (r *StatefulSetMigrationReconciler) waitForVolumeMigration(ctx context.Context, vm *migrationv1alpha1.VolumeMigration, timeout time.Duration) (*migrationv1alpha1.VolumeMigration, error) {
+	key := types.NamespacedName{Namespace: vm.Namespace, Name: vm.Name}
+	deadline := r.clock().NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := r.clock().NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		switch {
+		case vm.Status.Phase == migrationv1alpha1.VolumePhaseCompleted:
+			return vm, nil
+		case vm.Status.Phase == migrationv1alpha1.VolumePhaseFailed && vm.Annotations[AnnotationRetry] != "true":
+			return nil, fmt.Errorf("volume migration %s failed: %s", vm.Name, vm.Status.LastError)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline.C():
+			return nil, fmt.Errorf("timeout waiting for volume migration %s, still %s", vm.Name, vm.Status.Phase)
+		case <-ticker.C():
+			vm = &migrationv1alpha1.VolumeMigration{}
+			if err := r.Get(ctx, key, vm); err != nil {
+				return nil, fmt.Errorf("failed to get volume migration %s: %w", key.Name, err)
+			}
+		}
+	}
+}
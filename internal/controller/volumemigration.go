@@ -0,0 +1,602 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+const (
+	// ConditionVolumeDetached reports whether the volume has left its source
+	// node; its transition to False starts the detach timeout
+	ConditionVolumeDetached = "VolumeDetached"
+
+	// ConditionVolumeLocked reports whether the volume migration holds the
+	// volume's EBS lock
+	ConditionVolumeLocked = "VolumeLocked"
+
+	// VolumeMigrationPollInterval is how often a volume migration checks
+	// on consumers being stopped and the volume detaching
+	VolumeMigrationPollInterval = 5 * time.Second
+)
+
+// Event reasons for volume migrations
+const (
+	EventConsumerStopped       = "ConsumerStopped"
+	EventVolumeMigrated        = "VolumeMigrated"
+	EventVolumeMigrationFailed = "VolumeMigrationFailed"
+)
+
+// VolumeMigrationReconciler reconciles a VolumeMigration object. Each phase
+// takes one step and requeues, so a move survives controller restarts and a
+// failed one can be retried on its own with AnnotationRetry.
+type VolumeMigrationReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	ClientManager *multicluster.ClientManager
+	EBSClient     *aws.EBSClient
+
+	// Clock drives detach timeouts (default: the real clock)
+	Clock clock.Clock
+
+	// VolumeLockID identifies this controller instance in EBS volume lock
+	// tags. When set, the volume is locked before its consumers are stopped,
+	// as a StatefulSetMigration locks it before deleting a source pod.
+	VolumeLockID string
+
+	// VolumeLockTTL is how long a volume lock is honored (default: aws.DefaultVolumeLockTTL)
+	VolumeLockTTL time.Duration
+
+	// ReadOnly holds every volume migration before it changes the clusters or AWS
+	ReadOnly bool
+
+	// Pause holds every running volume migration while its ConfigMap says so (optional)
+	Pause *PauseSwitch
+
+	// Recorder records events on volume migrations (optional)
+	Recorder record.EventRecorder
+
+	// TargetPolicy restricts the clusters and namespaces volume migrations
+	// may target; every target is allowed when nil
+	TargetPolicy *TargetPolicy
+}
+
+// +kubebuilder:rbac:groups=migration.aqua.io,resources=volumemigrations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=migration.aqua.io,resources=volumemigrations/status,verbs=get;update;patch
+
+// Reconcile advances a VolumeMigration by one step
+func (r *VolumeMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	vm := &migrationv1alpha1.VolumeMigration{}
+	if err := r.Get(ctx, req.NamespacedName, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// FindMigrations looks volume migrations up by the volume moved
+	if err := r.labelVolume(ctx, vm); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if vm.Annotations[AnnotationRetry] == "true" && vm.Status.Phase == migrationv1alpha1.VolumePhaseFailed {
+		return r.retryVolumeMigration(ctx, vm)
+	}
+	if vm.Status.Phase == migrationv1alpha1.VolumePhaseCompleted || vm.Status.Phase == migrationv1alpha1.VolumePhaseFailed {
+		return ctrl.Result{}, nil
+	}
+
+	// The pause switch reconciles the volume migration again when the pause is lifted
+	if reason := r.Pause.Reason(); reason != "" {
+		logger.Info("Holding volume migration while paused", "phase", vm.Status.Phase, "reason", reason)
+		return ctrl.Result{}, nil
+	}
+	if vm.Status.Phase != "" && vm.Status.Phase != migrationv1alpha1.VolumePhasePending {
+		if reason := r.readOnlyReason(vm); reason != "" {
+			logger.Info("Holding volume migration in read-only mode", "phase", vm.Status.Phase, "reason", reason)
+			return ctrl.Result{}, nil
+		}
+	}
+
+	return r.reconcilePhase(ctx, vm)
+}
+
+// reconcilePhase runs the current phase's step and moves to the next phase once it is done
+func (r *VolumeMigrationReconciler) reconcilePhase(ctx context.Context, vm *migrationv1alpha1.VolumeMigration) (ctrl.Result, error) {
+	phase := vm.Status.Phase
+	if phase == "" || phase == migrationv1alpha1.VolumePhasePending {
+		// A volume migration failed here is retried from Pending, where its
+		// targets are checked again
+		if err := r.TargetPolicy.Check(ctx, vm.Namespace, volumeMigrationTargets(vm)); err != nil {
+			if !IsTargetPolicyError(err) {
+				return ctrl.Result{}, err
+			}
+			vm.Status.Phase = migrationv1alpha1.VolumePhasePending
+			return r.failVolumeMigration(ctx, vm, err)
+		}
+		now := metav1.NewTime(r.clock().Now())
+		vm.Status.StartTime = &now
+		return r.advance(ctx, vm, migrationv1alpha1.VolumePhaseStoppingConsumers)
+	}
+
+	sourceClient, err := r.ClientManager.GetClient(ctx, contextRefFor(vm.Namespace, vm.Spec.SourceCluster))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to connect to source cluster: %w", err)
+	}
+
+	var done bool
+	switch phase {
+	case migrationv1alpha1.VolumePhaseStoppingConsumers:
+		if done, err = r.stopConsumers(ctx, vm, sourceClient); done {
+			r.setCondition(vm, ConditionVolumeDetached, metav1.ConditionFalse, "Detaching",
+				fmt.Sprintf("Waiting for volume %s to detach", vm.Status.VolumeID))
+			return r.advance(ctx, vm, migrationv1alpha1.VolumePhaseDetachingVolume)
+		}
+	case migrationv1alpha1.VolumePhaseDetachingVolume:
+		if done, err = r.detachVolume(ctx, vm, sourceClient); done {
+			return r.advance(ctx, vm, migrationv1alpha1.VolumePhaseCreatingResources)
+		}
+	case migrationv1alpha1.VolumePhaseCreatingResources:
+		destClient, connErr := r.ClientManager.GetClient(ctx, contextRefFor(vm.Namespace, vm.Spec.DestCluster))
+		if connErr != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to connect to destination cluster: %w", connErr)
+		}
+		if err = r.createDestVolume(ctx, vm, sourceClient, destClient); err == nil {
+			return r.completeVolumeMigration(ctx, vm)
+		}
+	default:
+		return ctrl.Result{}, nil
+	}
+
+	if err != nil {
+		if aws.Retryable(err) {
+			log.FromContext(ctx).Info("AWS request throttled, retrying", "phase", phase, "error", err.Error())
+			return ctrl.Result{RequeueAfter: requeueDelay(DefaultRequeueDelay, vm.UID)}, nil
+		}
+		return r.failVolumeMigration(ctx, vm, err)
+	}
+	if err := r.Status().Update(ctx, vm); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: requeueDelay(VolumeMigrationPollInterval, vm.UID)}, nil
+}
+
+// stopConsumers deletes the source pods mounting the PVC and reports whether
+// they are all gone. A consumer created after the volume migration started
+// fails it: something, such as a StatefulSet that was not scaled down or
+// orphaned, recreates the pods and would keep the volume attached.
+func (r *VolumeMigrationReconciler) stopConsumers(ctx context.Context, vm *migrationv1alpha1.VolumeMigration, cc *multicluster.ClusterClient) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	if vm.Status.VolumeID == "" {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := cc.Client.Get(ctx, types.NamespacedName{Namespace: vm.Spec.SourceNamespace, Name: vm.Spec.PVCName}, pvc); err != nil {
+			return false, fmt.Errorf("failed to get source PVC %s: %w", vm.Spec.PVCName, err)
+		}
+		if pvc.Spec.VolumeName == "" {
+			return false, fmt.Errorf("source PVC %s is not bound to a PV", vm.Spec.PVCName)
+		}
+		pv := &corev1.PersistentVolume{}
+		if err := cc.Client.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+			return false, fmt.Errorf("failed to get source PV: %w", err)
+		}
+		volumeID, err := getVolumeIDFromPV(pv)
+		if err != nil {
+			return false, err
+		}
+
+		// Deleting the source PVC later must not delete the volume
+		if pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+			pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+			if err := cc.Client.Update(ctx, pv); err != nil {
+				return false, fmt.Errorf("failed to patch PV %s to Retain: %w", pv.Name, err)
+			}
+		}
+		vm.Status.VolumeID, vm.Status.SourcePVName = volumeID, pv.Name
+	}
+
+	// A volume another controller instance holds fails the move before any
+	// consumer is stopped
+	if err := r.lockVolume(ctx, vm); err != nil {
+		return false, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := cc.Reader(vm.Spec.SourceNamespace).List(ctx, pods, client.InNamespace(vm.Spec.SourceNamespace)); err != nil {
+		return false, fmt.Errorf("failed to list source pods: %w", err)
+	}
+	done := true
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !mountsPVC(pod, vm.Spec.PVCName) {
+			continue
+		}
+		done = false
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if vm.Status.StartTime != nil && pod.CreationTimestamp.After(vm.Status.StartTime.Time) {
+			return false, fmt.Errorf("pod %s mounting PVC %s was created after the volume migration started; scale down or orphan its owner first",
+				pod.Name, vm.Spec.PVCName)
+		}
+		logger.Info("Deleting source pod mounting the PVC", "pod", pod.Name, "pvc", vm.Spec.PVCName)
+		if err := cc.Client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to delete source pod %s: %w", pod.Name, err)
+		}
+		if !slices.Contains(vm.Status.StoppedPods, pod.Name) {
+			vm.Status.StoppedPods = append(vm.Status.StoppedPods, pod.Name)
+		}
+		r.event(vm, corev1.EventTypeNormal, EventConsumerStopped, fmt.Sprintf("Deleted source pod %s", pod.Name))
+	}
+	return done, nil
+}
+
+// mountsPVC reports whether a pod mounts the named PVC
+func mountsPVC(pod *corev1.Pod, pvcName string) bool {
+	return slices.ContainsFunc(pod.Spec.Volumes, func(v corev1.Volume) bool {
+		return v.PersistentVolumeClaim != nil && v.PersistentVolumeClaim.ClaimName == pvcName
+	})
+}
+
+// detachVolume reports whether the source cluster has removed the PV's
+// VolumeAttachment and EBS has detached the volume. While the attachment
+// remains, a node that cannot unmount the volume fails the migration unless
+// spec.forceDetach is set, in which case the EBS wait takes over.
+func (r *VolumeMigrationReconciler) detachVolume(ctx context.Context, vm *migrationv1alpha1.VolumeMigration, cc *multicluster.ClusterClient) (bool, error) {
+	logger := log.FromContext(ctx)
+	volumeID := vm.Status.VolumeID
+
+	remaining := r.detachDeadline(vm).Sub(r.clock().Now())
+	if remaining <= 0 {
+		return false, fmt.Errorf("timeout waiting for volume %s to detach after %s", volumeID, r.detachTimeout(vm))
+	}
+	// A retry resumes here with its lock taken again
+	if err := r.lockVolume(ctx, vm); err != nil {
+		return false, err
+	}
+
+	va, err := sourceAttachment(ctx, cc, vm.Status.SourcePVName)
+	if err != nil {
+		return false, err
+	}
+	if va != nil && !vm.Spec.ForceDetach {
+		if attachmentStageOf(va) == stageUnmounting {
+			problem, err := unmountProblem(ctx, cc, va)
+			if err != nil {
+				return false, err
+			}
+			if problem != "" {
+				return false, &unmountBlockedError{message: problem}
+			}
+		}
+		logger.Info("Waiting for source VolumeAttachment", "volumeId", volumeID, "state", attachmentState(va))
+		return false, nil
+	}
+
+	progress, err := r.ebs(vm).WaitForVolumeDetach(ctx, volumeID, aws.WaitForVolumeDetachConfig{
+		Timeout:     remaining,
+		ForceDetach: vm.Spec.ForceDetach,
+		OnForceDetach: func(instance aws.InstanceHealth) {
+			logger.Info("Force-detaching volume from unavailable instance", "volumeId", volumeID,
+				"instanceId", instance.InstanceID, "instanceState", instance.String())
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("volume detachment failed: %w", err)
+	}
+	if c := meta.FindStatusCondition(vm.Status.Conditions, ConditionVolumeDetached); c != nil {
+		metrics.ObserveVolumeDetach(r.clock().Since(c.LastTransitionTime.Time))
+	}
+	message := fmt.Sprintf("Volume %s detached after %d polls over %s", volumeID, progress.Polls, progress.Elapsed().Round(time.Second))
+	logger.Info("Volume detached", "volumeId", volumeID, "polls", progress.Polls)
+	r.setCondition(vm, ConditionVolumeDetached, metav1.ConditionTrue, "Detached", message)
+	return true, nil
+}
+
+// detachTimeout returns spec.volumeDetachTimeout, or DefaultVolumeDetachTimeout
+func (r *VolumeMigrationReconciler) detachTimeout(vm *migrationv1alpha1.VolumeMigration) time.Duration {
+	if vm.Spec.VolumeDetachTimeout != nil {
+		return vm.Spec.VolumeDetachTimeout.Duration
+	}
+	return DefaultVolumeDetachTimeout
+}
+
+// detachDeadline returns when the detach times out: the detach timeout after
+// ConditionVolumeDetached last became False
+func (r *VolumeMigrationReconciler) detachDeadline(vm *migrationv1alpha1.VolumeMigration) time.Time {
+	start := r.clock().Now()
+	if c := meta.FindStatusCondition(vm.Status.Conditions, ConditionVolumeDetached); c != nil && c.Status == metav1.ConditionFalse {
+		start = c.LastTransitionTime.Time
+	}
+	return start.Add(r.detachTimeout(vm))
+}
+
+// createDestVolume creates the destination PV and PVC for the detached
+// volume, reusing a PV an earlier attempt left for the same volume
+func (r *VolumeMigrationReconciler) createDestVolume(ctx context.Context, vm *migrationv1alpha1.VolumeMigration, sourceClient, destClient *multicluster.ClusterClient) error {
+	logger := log.FromContext(ctx)
+	destNamespace := volumeDestNamespace(vm)
+
+	sourcePVC := &corev1.PersistentVolumeClaim{}
+	if err := sourceClient.Client.Get(ctx, types.NamespacedName{Namespace: vm.Spec.SourceNamespace, Name: vm.Spec.PVCName}, sourcePVC); err != nil {
+		return fmt.Errorf("failed to get source PVC %s: %w", vm.Spec.PVCName, err)
+	}
+	sourcePV := &corev1.PersistentVolume{}
+	if err := sourceClient.Client.Get(ctx, types.NamespacedName{Name: vm.Status.SourcePVName}, sourcePV); err != nil {
+		return fmt.Errorf("failed to get source PV: %w", err)
+	}
+
+	// A volume on an Outpost must keep attaching to nodes on that Outpost, and
+	// one expanded beyond its PV's capacity keeps its whole size
+	info, err := r.ebs(vm).GetVolumeInfo(ctx, vm.Status.VolumeID)
+	if err != nil {
+		return err
+	}
+	cfg := volumeTranslationConfig(vm)
+	cfg.OutpostID = aws.OutpostID(info.OutpostARN)
+	cfg.VolumeSizeGiB = info.Size
+	result, err := translate.TranslatePV(sourcePV, sourcePVC, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to translate PV/PVC: %w", err)
+	}
+	for _, warning := range result.Warnings {
+		logger.Info("PV translation warning", "pvc", vm.Spec.PVCName, "warning", warning)
+	}
+
+	existingPV, err := findExistingDestPV(ctx, destClient, vm.Status.VolumeID, destNamespace, vm.Spec.PVCName)
+	if err != nil {
+		return err
+	}
+	if existingPV != nil {
+		logger.Info("Adopting existing destination PV", "pv", existingPV.Name, "volumeId", vm.Status.VolumeID)
+		if err := bindExistingDestPV(ctx, destClient, existingPV, destNamespace, vm.Spec.PVCName, ""); err != nil {
+			return err
+		}
+		result.PVC.Spec.VolumeName = existingPV.Name
+		vm.Status.DestPVName = existingPV.Name
+	} else {
+		if err := destClient.Client.Create(ctx, result.PV); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create destination PV: %w", err)
+		}
+		vm.Status.DestPVName = result.PV.Name
+	}
+	if err := destClient.Client.Create(ctx, result.PVC); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create destination PVC: %w", err)
+	}
+	return nil
+}
+
+// volumeDestNamespace returns spec.destNamespace, defaulting to the source namespace
+func volumeDestNamespace(vm *migrationv1alpha1.VolumeMigration) string {
+	if vm.Spec.DestNamespace != "" {
+		return vm.Spec.DestNamespace
+	}
+	return vm.Spec.SourceNamespace
+}
+
+// volumeTranslationConfig returns the translation settings of a volume migration
+func volumeTranslationConfig(vm *migrationv1alpha1.VolumeMigration) translate.PVTranslationConfig {
+	var passthrough translate.MetadataPassthrough
+	if p := vm.Spec.MetadataPassthrough; p != nil {
+		passthrough = translate.MetadataPassthrough{AnnotationPrefixes: p.AnnotationPrefixes, LabelPrefixes: p.LabelPrefixes}
+	}
+	return translate.PVTranslationConfig{
+		DestNamespace:        volumeDestNamespace(vm),
+		DestPVCName:          vm.Spec.PVCName,
+		StorageClassMapping:  vm.Spec.StorageClassMapping,
+		PreserveNodeAffinity: true,
+		PVNameTemplate:       vm.Spec.DestPVNameTemplate,
+		NodeOS:               corev1.OSName(vm.Spec.NodeOS),
+		Passthrough:          passthrough,
+		DataSourcePolicy:     translate.DataSourcePolicy(vm.Spec.DataSourcePolicy),
+		ConvertInTreeEBS:     vm.Spec.ConvertInTreeEBS,
+	}
+}
+
+// volumeLockOwner returns the owner written to the volume's lock. A child
+// of a StatefulSetMigration shares its parent's owner, so it renews the lock
+// the parent took before deleting the source pod rather than conflicting
+// with it.
+func (r *VolumeMigrationReconciler) volumeLockOwner(vm *migrationv1alpha1.VolumeMigration) string {
+	if owner := metav1.GetControllerOf(vm); owner != nil && owner.Kind == "StatefulSetMigration" {
+		return fmt.Sprintf("%s/%s", r.VolumeLockID, owner.UID)
+	}
+	return fmt.Sprintf("%s/%s", r.VolumeLockID, vm.UID)
+}
+
+// lockVolume takes the volume's EBS lock unless ConditionVolumeLocked shows
+// the volume migration already holds it
+func (r *VolumeMigrationReconciler) lockVolume(ctx context.Context, vm *migrationv1alpha1.VolumeMigration) error {
+	if r.VolumeLockID == "" || meta.IsStatusConditionTrue(vm.Status.Conditions, ConditionVolumeLocked) {
+		return nil
+	}
+	if err := r.ebs(vm).AcquireVolumeLock(ctx, vm.Status.VolumeID, aws.VolumeLockConfig{
+		Owner: r.volumeLockOwner(vm),
+		TTL:   r.VolumeLockTTL,
+	}); err != nil {
+		return fmt.Errorf("failed to lock volume %s: %w", vm.Status.VolumeID, err)
+	}
+	r.setCondition(vm, ConditionVolumeLocked, metav1.ConditionTrue, "Locked",
+		fmt.Sprintf("Volume %s is locked by %s", vm.Status.VolumeID, r.volumeLockOwner(vm)))
+	return nil
+}
+
+// releaseVolumeLock removes the volume's EBS lock once the move has
+// finished. A child of a StatefulSetMigration leaves it to its parent, which
+// holds it until the destination pod is Ready.
+func (r *VolumeMigrationReconciler) releaseVolumeLock(ctx context.Context, vm *migrationv1alpha1.VolumeMigration) {
+	if !meta.IsStatusConditionTrue(vm.Status.Conditions, ConditionVolumeLocked) {
+		return
+	}
+	if owner := metav1.GetControllerOf(vm); owner != nil && owner.Kind == "StatefulSetMigration" {
+		return
+	}
+	if err := r.ebs(vm).ReleaseVolumeLock(ctx, vm.Status.VolumeID, r.volumeLockOwner(vm)); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to release volume lock", "volumeId", vm.Status.VolumeID)
+		return
+	}
+	r.setCondition(vm, ConditionVolumeLocked, metav1.ConditionFalse, "Released",
+		fmt.Sprintf("Volume %s is bound in the destination", vm.Status.VolumeID))
+}
+
+// advance moves the volume migration to the next phase and requeues it
+func (r *VolumeMigrationReconciler) advance(ctx context.Context, vm *migrationv1alpha1.VolumeMigration, phase migrationv1alpha1.VolumeMigrationPhase) (ctrl.Result, error) {
+	log.FromContext(ctx).Info("Volume migration phase", "from", vm.Status.Phase, "to", phase)
+	vm.Status.Phase = phase
+	if err := r.Status().Update(ctx, vm); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// completeVolumeMigration records that the destination PV and PVC exist
+func (r *VolumeMigrationReconciler) completeVolumeMigration(ctx context.Context, vm *migrationv1alpha1.VolumeMigration) (ctrl.Result, error) {
+	r.releaseVolumeLock(ctx, vm)
+	now := metav1.NewTime(r.clock().Now())
+	vm.Status.Phase = migrationv1alpha1.VolumePhaseCompleted
+	vm.Status.CompletionTime = &now
+	r.event(vm, corev1.EventTypeNormal, EventVolumeMigrated,
+		fmt.Sprintf("Volume %s is bound to PV %s in the destination", vm.Status.VolumeID, vm.Status.DestPVName))
+	if err := r.Status().Update(ctx, vm); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.FromContext(ctx).Info("Volume migrated", "volumeId", vm.Status.VolumeID, "pv", vm.Status.DestPVName)
+	return ctrl.Result{}, nil
+}
+
+// failVolumeMigration marks the volume migration Failed. The Failed
+// condition's reason records the phase it failed in, which a retry resumes.
+func (r *VolumeMigrationReconciler) failVolumeMigration(ctx context.Context, vm *migrationv1alpha1.VolumeMigration, err error) (ctrl.Result, error) {
+	log.FromContext(ctx).Error(err, "Volume migration failed", "phase", vm.Status.Phase)
+
+	now := metav1.NewTime(r.clock().Now())
+	r.setCondition(vm, "Failed", metav1.ConditionTrue, string(vm.Status.Phase), err.Error())
+	vm.Status.Phase = migrationv1alpha1.VolumePhaseFailed
+	vm.Status.LastError = err.Error()
+	vm.Status.CompletionTime = &now
+	r.event(vm, corev1.EventTypeWarning, EventVolumeMigrationFailed, err.Error())
+	if err := r.Status().Update(ctx, vm); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// retryVolumeMigration moves a Failed volume migration back into the phase it
+// failed in and removes AnnotationRetry
+func (r *VolumeMigrationReconciler) retryVolumeMigration(ctx context.Context, vm *migrationv1alpha1.VolumeMigration) (ctrl.Result, error) {
+	phase := migrationv1alpha1.VolumePhasePending
+	if c := meta.FindStatusCondition(vm.Status.Conditions, "Failed"); c != nil && c.Reason != "" {
+		phase = migrationv1alpha1.VolumeMigrationPhase(c.Reason)
+	}
+	log.FromContext(ctx).Info("Retrying volume migration", "phase", phase)
+
+	vm.Status.Phase = phase
+	vm.Status.LastError = ""
+	vm.Status.CompletionTime = nil
+	r.setCondition(vm, "Failed", metav1.ConditionFalse, "Retried", fmt.Sprintf("Retried in %s", phase))
+	// The lock may have lapsed while the volume migration was failed
+	meta.RemoveStatusCondition(&vm.Status.Conditions, ConditionVolumeLocked)
+	if phase == migrationv1alpha1.VolumePhaseStoppingConsumers || phase == migrationv1alpha1.VolumePhasePending {
+		// Only pods created after the retry count as recreated consumers
+		now := metav1.NewTime(r.clock().Now())
+		vm.Status.StartTime = &now
+	}
+	if phase == migrationv1alpha1.VolumePhaseDetachingVolume {
+		// The detach timeout starts over
+		meta.RemoveStatusCondition(&vm.Status.Conditions, ConditionVolumeDetached)
+		r.setCondition(vm, ConditionVolumeDetached, metav1.ConditionFalse, "Detaching",
+			fmt.Sprintf("Waiting for volume %s to detach", vm.Status.VolumeID))
+	}
+	if err := r.Status().Update(ctx, vm); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Only remove the annotation once the status has moved on, so a
+	// failed update retries rather than leaving the volume migration stopped
+	delete(vm.Annotations, AnnotationRetry)
+	if err := r.Update(ctx, vm); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{Requeue: true}, nil
+}
+
+// readOnlyReason returns why the volume migration may not change the clusters
+// or AWS, or "" when it may
+func (r *VolumeMigrationReconciler) readOnlyReason(vm *migrationv1alpha1.VolumeMigration) string {
+	if r.ReadOnly {
+		return "the controller runs with --read-only"
+	}
+	if vm.Annotations[AnnotationReadOnly] == "true" {
+		return fmt.Sprintf("the volume migration is annotated %s=true", AnnotationReadOnly)
+	}
+	return ""
+}
+
+// ebs returns the EBS client for the volume migration's AWS region
+func (r *VolumeMigrationReconciler) ebs(vm *migrationv1alpha1.VolumeMigration) *aws.EBSClient {
+	if r.EBSClient == nil || vm.Spec.AWSConfig == nil {
+		return r.EBSClient
+	}
+	return r.EBSClient.ForRegion(vm.Spec.AWSConfig.Region)
+}
+
+// clock returns the clock that drives detach timeouts
+func (r *VolumeMigrationReconciler) clock() clock.Clock {
+	if r.Clock == nil {
+		return clock.RealClock{}
+	}
+	return r.Clock
+}
+
+func (r *VolumeMigrationReconciler) event(vm *migrationv1alpha1.VolumeMigration, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(vm, eventType, reason, message)
+	}
+}
+
+// setCondition adds or updates a condition. LastTransitionTime is taken from
+// the reconciler's clock, since the detach deadline is measured from it.
+func (r *VolumeMigrationReconciler) setCondition(vm *migrationv1alpha1.VolumeMigration, condType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&vm.Status.Conditions, metav1.Condition{
+		Type:               condType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: vm.Generation,
+		LastTransitionTime: metav1.NewTime(r.clock().Now()),
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *VolumeMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&migrationv1alpha1.VolumeMigration{})
+	if r.Pause != nil {
+		b = b.WatchesRawSource(source.Channel(r.Pause.Subscribe(), enqueueAll(mgr.GetClient(), func() client.ObjectList {
+			return &migrationv1alpha1.VolumeMigrationList{}
+		})))
+	}
+	return b.Complete(r)
+}
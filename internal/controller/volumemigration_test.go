@@ -0,0 +1,271 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func volumeMigrationScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func TestStopConsumers(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-db-0"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-0a"},
+			},
+		},
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "data-db-0"},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-db-0"},
+	}
+	pod := func(name, claim string, created time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+				Name:         "data",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim}},
+			}}},
+		}
+	}
+	newVM := func() *migrationv1alpha1.VolumeMigration {
+		return &migrationv1alpha1.VolumeMigration{
+			Spec:   migrationv1alpha1.VolumeMigrationSpec{SourceNamespace: "db", PVCName: "data-db-0"},
+			Status: migrationv1alpha1.VolumeMigrationStatus{StartTime: &metav1.Time{Time: start}},
+		}
+	}
+
+	t.Run("deletes consumers", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+			pv.DeepCopy(), pvc.DeepCopy(),
+			pod("db-0", "data-db-0", start.Add(-time.Hour)),
+			pod("backup", "data-db-1", start.Add(time.Minute)),
+		).Build()
+		cc := &multicluster.ClusterClient{Client: c}
+		r := &VolumeMigrationReconciler{}
+		vm := newVM()
+
+		done, err := r.stopConsumers(context.Background(), vm, cc)
+		if err != nil || done {
+			t.Fatalf("stopConsumers() = %v, %v, want the consumer deleted and waited on", done, err)
+		}
+		if vm.Status.VolumeID != "vol-0a" || vm.Status.SourcePVName != "pv-db-0" {
+			t.Errorf("VolumeID, SourcePVName = %q, %q, want vol-0a, pv-db-0", vm.Status.VolumeID, vm.Status.SourcePVName)
+		}
+		if len(vm.Status.StoppedPods) != 1 || vm.Status.StoppedPods[0] != "db-0" {
+			t.Errorf("StoppedPods = %v, want [db-0]", vm.Status.StoppedPods)
+		}
+		if err := c.Get(context.Background(), types.NamespacedName{Namespace: "db", Name: "backup"}, &corev1.Pod{}); err != nil {
+			t.Errorf("pod mounting another PVC was touched: %v", err)
+		}
+		got := &corev1.PersistentVolume{}
+		if err := c.Get(context.Background(), types.NamespacedName{Name: "pv-db-0"}, got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+			t.Errorf("reclaim policy = %s, want Retain", got.Spec.PersistentVolumeReclaimPolicy)
+		}
+
+		if done, err := r.stopConsumers(context.Background(), vm, cc); err != nil || !done {
+			t.Errorf("stopConsumers() = %v, %v, want done once the consumer is gone", done, err)
+		}
+	})
+
+	t.Run("recreated consumer", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+			pv.DeepCopy(), pvc.DeepCopy(), pod("db-0", "data-db-0", start.Add(time.Minute)),
+		).Build()
+		r := &VolumeMigrationReconciler{}
+
+		_, err := r.stopConsumers(context.Background(), newVM(), &multicluster.ClusterClient{Client: c})
+		if err == nil || !strings.Contains(err.Error(), "created after the volume migration started") {
+			t.Errorf("stopConsumers() error = %v, want the recreated pod reported", err)
+		}
+	})
+
+	t.Run("volume locked by another controller", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+			pv.DeepCopy(), pvc.DeepCopy(), pod("db-0", "data-db-0", start.Add(-time.Hour)),
+		).Build()
+		api := &lockedEC2{}
+		r := &VolumeMigrationReconciler{
+			EBSClient:    aws.NewEBSClientFromAPI(api, clocktesting.NewFakeClock(start), "us-east-1"),
+			VolumeLockID: "mgmt-a",
+		}
+
+		_, err := r.stopConsumers(context.Background(), newVM(), &multicluster.ClusterClient{Client: c})
+		var locked *aws.VolumeLockedError
+		if !errors.As(err, &locked) {
+			t.Fatalf("stopConsumers() error = %v, want a VolumeLockedError", err)
+		}
+		if api.tagged {
+			t.Error("lock tagged over another controller's")
+		}
+		if err := c.Get(context.Background(), types.NamespacedName{Namespace: "db", Name: "db-0"}, &corev1.Pod{}); err != nil {
+			t.Errorf("consumer db-0 was not left running: %v", err)
+		}
+	})
+
+	t.Run("unbound PVC", func(t *testing.T) {
+		unbound := pvc.DeepCopy()
+		unbound.Spec.VolumeName = ""
+		c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(unbound).Build()
+		r := &VolumeMigrationReconciler{}
+
+		_, err := r.stopConsumers(context.Background(), newVM(), &multicluster.ClusterClient{Client: c})
+		if err == nil || !strings.Contains(err.Error(), "not bound") {
+			t.Errorf("stopConsumers() error = %v, want the unbound PVC reported", err)
+		}
+	})
+}
+
+func TestVolumeLockOwner(t *testing.T) {
+	r := &VolumeMigrationReconciler{VolumeLockID: "mgmt-a"}
+	vm := &migrationv1alpha1.VolumeMigration{ObjectMeta: metav1.ObjectMeta{UID: "vm-uid"}}
+	if got := r.volumeLockOwner(vm); got != "mgmt-a/vm-uid" {
+		t.Errorf("volumeLockOwner() standalone = %q, want mgmt-a/vm-uid", got)
+	}
+	vm.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: migrationv1alpha1.GroupVersion.String(), Kind: "StatefulSetMigration", Name: "web", UID: "sts-uid", Controller: ptr.To(true),
+	}}
+	if got := r.volumeLockOwner(vm); got != "mgmt-a/sts-uid" {
+		t.Errorf("volumeLockOwner() of a child = %q, want its parent's mgmt-a/sts-uid", got)
+	}
+}
+
+func TestRetryVolumeMigration(t *testing.T) {
+	scheme := volumeMigrationScheme(t)
+	failedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	clk := clocktesting.NewFakeClock(failedAt)
+	vm := &migrationv1alpha1.VolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "db-data-db-0", Annotations: map[string]string{AnnotationRetry: "true"}},
+		Spec:       migrationv1alpha1.VolumeMigrationSpec{VolumeDetachTimeout: &metav1.Duration{Duration: 10 * time.Minute}},
+		Status:     migrationv1alpha1.VolumeMigrationStatus{VolumeID: "vol-0a"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(vm).WithStatusSubresource(vm).Build()
+	r := &VolumeMigrationReconciler{Client: c, Clock: clk}
+
+	// Fail while detaching, then retry an hour later
+	vm.Status.Phase = migrationv1alpha1.VolumePhaseDetachingVolume
+	r.setCondition(vm, ConditionVolumeDetached, metav1.ConditionFalse, "Detaching", "")
+	if _, err := r.failVolumeMigration(context.Background(), vm, errors.New("detach timed out")); err != nil {
+		t.Fatal(err)
+	}
+	clk.Step(time.Hour)
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "ops", Name: "db-data-db-0"}}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	got := &migrationv1alpha1.VolumeMigration{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "ops", Name: "db-data-db-0"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Phase != migrationv1alpha1.VolumePhaseDetachingVolume || got.Status.LastError != "" {
+		t.Errorf("Phase = %s, LastError = %q, want DetachingVolume resumed", got.Status.Phase, got.Status.LastError)
+	}
+	if _, ok := got.Annotations[AnnotationRetry]; ok {
+		t.Error("retry annotation was not removed")
+	}
+	if c := meta.FindStatusCondition(got.Status.Conditions, "Failed"); c == nil || c.Status != metav1.ConditionFalse {
+		t.Errorf("Failed condition = %+v, want False", c)
+	}
+	if want := clk.Now().Add(10 * time.Minute); !r.detachDeadline(got).Equal(want) {
+		t.Errorf("detach deadline = %s, want the timeout restarted at %s", r.detachDeadline(got), want)
+	}
+}
+
+func TestEnsureVolumeMigration(t *testing.T) {
+	scheme := volumeMigrationScheme(t)
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "db", UID: "uid-db"},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			MigrationID:      "mig-1",
+			SourceNamespace:  "db",
+			DestNamespace:    "db",
+			VolumeMigrations: true,
+			ForceDetach:      true,
+			VolumePolicy: []migrationv1alpha1.VolumePolicy{
+				{ClaimTemplate: "data", VolumeDetachTimeout: &metav1.Duration{Duration: 20 * time.Minute}},
+			},
+		},
+		Status: migrationv1alpha1.StatefulSetMigrationStatus{NodeOS: "linux"},
+	}
+	mv := &podMove{claimTemplate: "data", pvcName: "data-db-0"}
+
+	t.Run("creates owned child", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m.DeepCopy()).Build()
+		r := &StatefulSetMigrationReconciler{Client: c, Scheme: scheme}
+
+		vm, err := r.ensureVolumeMigration(context.Background(), m, mv)
+		if err != nil {
+			t.Fatalf("ensureVolumeMigration() error = %v", err)
+		}
+		if vm.Name != "db-data-db-0" || vm.Labels[reportMigrationIDLabel] != "mig-1" {
+			t.Errorf("child = %s %v, want db-data-db-0 labeled with the migration ID", vm.Name, vm.Labels)
+		}
+		if owner := metav1.GetControllerOf(vm); owner == nil || owner.UID != "uid-db" {
+			t.Errorf("controller = %+v, want the migration", owner)
+		}
+		spec := vm.Spec
+		if spec.PVCName != "data-db-0" || spec.NodeOS != "linux" || !spec.ForceDetach || spec.VolumeDetachTimeout.Duration != 20*time.Minute {
+			t.Errorf("spec = %+v, want the migration's volume settings and the claim template's detach timeout", spec)
+		}
+	})
+
+	t.Run("retries failed child", func(t *testing.T) {
+		failed := &migrationv1alpha1.VolumeMigration{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "db-data-db-0"},
+			Status:     migrationv1alpha1.VolumeMigrationStatus{Phase: migrationv1alpha1.VolumePhaseFailed, LastError: "detach timed out"},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(failed).Build()
+		r := &StatefulSetMigrationReconciler{Client: c, Scheme: scheme}
+
+		vm, err := r.ensureVolumeMigration(context.Background(), m, mv)
+		if err != nil {
+			t.Fatalf("ensureVolumeMigration() error = %v", err)
+		}
+		if vm.Annotations[AnnotationRetry] != "true" {
+			t.Errorf("annotations = %v, want the failed child annotated to retry", vm.Annotations)
+		}
+		// Still Failed until the child's reconciler picks the retry up
+		if _, err := r.waitForVolumeMigration(context.Background(), vm, 0); err == nil || !strings.Contains(err.Error(), "timeout") {
+			t.Errorf("waitForVolumeMigration() error = %v, want the pending retry waited on", err)
+		}
+
+		delete(vm.Annotations, AnnotationRetry)
+		if _, err := r.waitForVolumeMigration(context.Background(), vm, time.Minute); err == nil || !strings.Contains(err.Error(), "detach timed out") {
+			t.Errorf("waitForVolumeMigration() error = %v, want the child's error", err)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+const (
+	// FinalizerVolumeProtection keeps a migrated destination PV or PVC from
+	// being deleted until the protection lapses
+	FinalizerVolumeProtection = "migration.aqua.io/volume-protection"
+
+	// AnnotationProtectedUntil on a protected destination PV or PVC is when
+	// its protection lapses, as an RFC 3339 time
+	AnnotationProtectedUntil = "migration.aqua.io/protected-until"
+
+	// StepProtectVolumes records protecting, and releasing, the destination
+	// PVs and PVCs
+	StepProtectVolumes = "ProtectVolumes"
+)
+
+// destVolumes returns the destination PVs the migration created or adopted
+// for its moved pods, found by the labels and volume ID annotation the
+// translation gives them
+func destVolumes(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, destCC *multicluster.ClusterClient) ([]*corev1.PersistentVolume, error) {
+	volumeIDs := make(map[string]bool, len(m.Status.MigratedPods))
+	for _, p := range m.Status.MigratedPods {
+		if p.VolumeID != "" {
+			volumeIDs[p.VolumeID] = true
+		}
+	}
+	if len(volumeIDs) == 0 {
+		return nil, nil
+	}
+	pvs := &corev1.PersistentVolumeList{}
+	if err := destCC.Client.List(ctx, pvs, client.MatchingLabels{
+		"migration.aqua.io/migrated": "true",
+		migration.LabelDestNamespace: m.Spec.DestNamespace,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list destination PVs: %w", err)
+	}
+	var found []*corev1.PersistentVolume
+	for i := range pvs.Items {
+		if volumeIDs[pvs.Items[i].Annotations[migration.AnnotationVolumeID]] {
+			found = append(found, &pvs.Items[i])
+		}
+	}
+	return found, nil
+}
+
+// protectDestVolumes puts FinalizerVolumeProtection on the destination PVs
+// and their PVCs for r.DestVolumeProtection once every pod has moved, so
+// deleting the destination namespace right after cutover cannot release the
+// freshly migrated disks. The PVs are Retain, so the disks would survive,
+// but a released PV no longer binds to the PVC the workload would recreate.
+// A failure is recorded without failing the migration, whose pods have
+// already moved.
+func (r *StatefulSetMigrationReconciler) protectDestVolumes(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) {
+	if r.DestVolumeProtection <= 0 {
+		return
+	}
+	until := metav1.NewTime(r.clock().Now().Add(r.DestVolumeProtection).Truncate(time.Second))
+	var pvs []*corev1.PersistentVolume
+	destCC, err := r.getDestClient(ctx, m)
+	if err == nil {
+		pvs, err = destVolumes(ctx, m, destCC)
+	}
+	if err == nil {
+		err = forEachDestVolume(ctx, destCC, pvs, func(obj client.Object) bool {
+			annotations := obj.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[AnnotationProtectedUntil] = until.UTC().Format(time.RFC3339)
+			obj.SetAnnotations(annotations)
+			controllerutil.AddFinalizer(obj, FinalizerVolumeProtection)
+			return true
+		})
+	}
+	if len(pvs) > 0 {
+		// Objects protected before a failure still need releasing
+		m.Status.VolumeProtectedUntil = &until
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to protect the destination volumes from deletion")
+		r.recordHistory(m, StepProtectVolumes, "", migrationv1alpha1.HistoryResultFailed, err.Error())
+		return
+	}
+	if len(pvs) > 0 {
+		r.recordHistory(m, StepProtectVolumes, "", migrationv1alpha1.HistoryResultSucceeded,
+			fmt.Sprintf("Protected %d destination PVs and their PVCs from deletion until %s", len(pvs), until.UTC().Format(time.RFC3339)))
+	}
+}
+
+// releaseDestVolumes removes the protection protectDestVolumes added, once
+// it has lapsed or the migration is deleted, and clears
+// status.volumeProtectedUntil. An object whose finalizer was removed by
+// hand is left as it is.
+func (r *StatefulSetMigrationReconciler) releaseDestVolumes(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) error {
+	if m.Status.VolumeProtectedUntil == nil {
+		return nil
+	}
+	destCC, err := r.getDestClient(ctx, m)
+	if err != nil {
+		return fmt.Errorf("failed to get destination client: %w", err)
+	}
+	pvs, err := destVolumes(ctx, m, destCC)
+	if err != nil {
+		return err
+	}
+	err = forEachDestVolume(ctx, destCC, pvs, func(obj client.Object) bool {
+		annotations := obj.GetAnnotations()
+		_, annotated := annotations[AnnotationProtectedUntil]
+		delete(annotations, AnnotationProtectedUntil)
+		obj.SetAnnotations(annotations)
+		return controllerutil.RemoveFinalizer(obj, FinalizerVolumeProtection) || annotated
+	})
+	if err != nil {
+		return err
+	}
+	m.Status.VolumeProtectedUntil = nil
+	r.recordHistory(m, StepProtectVolumes, "", migrationv1alpha1.HistoryResultSucceeded,
+		fmt.Sprintf("Released the deletion protection of %d destination PVs and their PVCs", len(pvs)))
+	return nil
+}
+
+// reconcileVolumeProtection releases the destination volumes of a completed
+// migration once their protection lapses. It returns how long until then,
+// or 0 when nothing is protected any more.
+func (r *StatefulSetMigrationReconciler) reconcileVolumeProtection(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (time.Duration, error) {
+	if m.Status.VolumeProtectedUntil == nil {
+		return 0, nil
+	}
+	if remaining := m.Status.VolumeProtectedUntil.Sub(r.clock().Now()); remaining > 0 {
+		return remaining, nil
+	}
+	before := m.Status.DeepCopy()
+	if err := r.releaseDestVolumes(ctx, m); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to release the destination volumes' deletion protection")
+		return requeueDelay(DefaultRequeueDelay, m.UID), nil
+	}
+	log.FromContext(ctx).Info("Released the destination volumes' deletion protection")
+	return 0, r.updateStatus(ctx, m, before)
+}
+
+// forEachDestVolume applies edit to each PV and the PVC it is bound to,
+// patching those it reports changed with an optimistic lock, since a merge
+// patch replaces the whole finalizer list
+func forEachDestVolume(ctx context.Context, destCC *multicluster.ClusterClient, pvs []*corev1.PersistentVolume, edit func(client.Object) bool) error {
+	var errs []error
+	for _, pv := range pvs {
+		objects := []client.Object{pv}
+		if ref := pv.Spec.ClaimRef; ref != nil {
+			pvc := &corev1.PersistentVolumeClaim{}
+			err := destCC.Client.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, pvc)
+			switch {
+			case err == nil:
+				objects = append(objects, pvc)
+			case !apierrors.IsNotFound(err):
+				errs = append(errs, fmt.Errorf("failed to get PVC %s/%s: %w", ref.Namespace, ref.Name, err))
+			}
+		}
+		for _, obj := range objects {
+			patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+			if !edit(obj) {
+				continue
+			}
+			if err := destCC.Client.Patch(ctx, obj, patch); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to patch %s: %w", client.ObjectKeyFromObject(obj), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
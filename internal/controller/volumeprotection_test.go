@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestDestVolumes(t *testing.T) {
+	pv := func(name, namespace, volumeID string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Labels:      map[string]string{"migration.aqua.io/migrated": "true", "migration.aqua.io/dest-namespace": namespace},
+				Annotations: map[string]string{"migration.aqua.io/volume-id": volumeID},
+			},
+			Spec: corev1.PersistentVolumeSpec{ClaimRef: &corev1.ObjectReference{Namespace: namespace, Name: "data-" + name}},
+		}
+	}
+	pvc := func(name string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-" + name}}
+	}
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		pv("web-0", "prod", "vol-0"), pvc("web-0"),
+		// Its PVC was deleted already
+		pv("web-1", "prod", "vol-1"),
+		// Another migration's, and another namespace's
+		pv("db-0", "prod", "vol-db"), pv("web-0-staging", "staging", "vol-0"),
+	).Build()
+	cc := &multicluster.ClusterClient{Client: c}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{DestNamespace: "prod"},
+		Status: migrationv1alpha1.StatefulSetMigrationStatus{MigratedPods: []migrationv1alpha1.MigratedPodInfo{
+			{Index: 0, VolumeID: "vol-0"}, {Index: 1, VolumeID: "vol-1"}, {Index: 2},
+		}},
+	}
+	ctx := context.Background()
+
+	pvs, err := destVolumes(ctx, m, cc)
+	if err != nil {
+		t.Fatalf("destVolumes() error = %v", err)
+	}
+	var names []string
+	for _, pv := range pvs {
+		names = append(names, pv.Name)
+	}
+	if len(names) != 2 || names[0] != "web-0" || names[1] != "web-1" {
+		t.Fatalf("destVolumes() = %v, want [web-0 web-1]", names)
+	}
+
+	protect := func(obj client.Object) bool {
+		obj.SetAnnotations(map[string]string{AnnotationProtectedUntil: "2024-01-02T12:00:00Z"})
+		return controllerutil.AddFinalizer(obj, FinalizerVolumeProtection)
+	}
+	if err := forEachDestVolume(ctx, cc, pvs, protect); err != nil {
+		t.Fatalf("forEachDestVolume() error = %v", err)
+	}
+	for _, obj := range []client.Object{&corev1.PersistentVolume{}, &corev1.PersistentVolumeClaim{}} {
+		key := types.NamespacedName{Name: "web-0"}
+		if _, ok := obj.(*corev1.PersistentVolumeClaim); ok {
+			key = types.NamespacedName{Namespace: "prod", Name: "data-web-0"}
+		}
+		if err := c.Get(ctx, key, obj); err != nil {
+			t.Fatal(err)
+		}
+		if !controllerutil.ContainsFinalizer(obj, FinalizerVolumeProtection) || obj.GetAnnotations()[AnnotationProtectedUntil] == "" {
+			t.Errorf("%T %s was not protected: finalizers %v, annotations %v", obj, key, obj.GetFinalizers(), obj.GetAnnotations())
+		}
+	}
+	other := &corev1.PersistentVolume{}
+	if err := c.Get(ctx, types.NamespacedName{Name: "db-0"}, other); err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Finalizers) > 0 {
+		t.Errorf("PV db-0 of another migration was protected")
+	}
+}
+
+func TestReconcileVolumeProtectionNotLapsed(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	until := metav1.NewTime(now.Add(time.Hour))
+	r := &StatefulSetMigrationReconciler{Clock: clocktesting.NewFakeClock(now)}
+
+	m := &migrationv1alpha1.StatefulSetMigration{}
+	if remaining, err := r.reconcileVolumeProtection(context.Background(), m); err != nil || remaining != 0 {
+		t.Errorf("reconcileVolumeProtection() unprotected = %v, %v, want 0, nil", remaining, err)
+	}
+	m.Status.VolumeProtectedUntil = &until
+	remaining, err := r.reconcileVolumeProtection(context.Background(), m)
+	if err != nil || remaining != time.Hour {
+		t.Errorf("reconcileVolumeProtection() = %v, %v, want 1h, nil", remaining, err)
+	}
+	if m.Status.VolumeProtectedUntil == nil {
+		t.Error("protection released before it lapsed")
+	}
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/metrics"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+// DefaultPVCBoundTimeout is how long a destination PVC may take to bind in
+// spec.mode VolumesOnly. Its PV is created for it, so binding is quick.
+const DefaultPVCBoundTimeout = 2 * time.Minute
+
+// volumesOnly reports whether the migration leaves creating the destination
+// StatefulSet to the user, moving only the volumes
+func volumesOnly(m *migrationv1alpha1.StatefulSetMigration) bool {
+	return m.Spec.Mode == migrationv1alpha1.MigrationModeVolumesOnly
+}
+
+// checkExistingDestStatefulSet decides whether pre-flight accepts a
+// StatefulSet already in the destination. A full migration creates its own,
+// so none may exist. In VolumesOnly mode the user or GitOps may have created
+// it ahead of time, but scaled to zero: a running one would provision empty
+// volumes for the PVCs the migration is about to create.
+func checkExistingDestStatefulSet(m *migrationv1alpha1.StatefulSetMigration, sts *appsv1.StatefulSet) error {
+	if !volumesOnly(m) {
+		return fmt.Errorf("StatefulSet %q already exists in destination namespace %q", sts.Name, sts.Namespace)
+	}
+	if sts.Spec.Replicas == nil || *sts.Spec.Replicas > 0 {
+		return fmt.Errorf("StatefulSet %q in destination namespace %q must be scaled to 0 until the volumes have moved", sts.Name, sts.Namespace)
+	}
+	return nil
+}
+
+// waitForPVCBound waits until a destination PVC is Bound
+func (r *StatefulSetMigrationReconciler) waitForPVCBound(ctx context.Context, cc *multicluster.ClusterClient, namespace, name string, timeout time.Duration) error {
+	defer metrics.TrackWait(StepPVCBound)()
+
+	deadline := r.clock().NewTimer(timeout)
+	defer deadline.Stop()
+
+	reader := cc.Reader(namespace)
+	ticker := r.clock().NewTicker(r.pollInterval(2 * time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C():
+			return fmt.Errorf("timeout waiting for PVC %s to be Bound", name)
+		case <-ticker.C():
+			pvc := &corev1.PersistentVolumeClaim{}
+			if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pvc); err != nil {
+				return err
+			}
+			if pvc.Status.Phase == corev1.ClaimBound {
+				return nil
+			}
+		}
+	}
+}
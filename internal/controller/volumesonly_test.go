@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestCheckExistingDestStatefulSet(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     migrationv1alpha1.MigrationMode
+		replicas *int32
+		wantErr  bool
+	}{
+		{name: "full migration", mode: migrationv1alpha1.MigrationModeFull, replicas: ptr.To(int32(0)), wantErr: true},
+		{name: "default mode", replicas: ptr.To(int32(0)), wantErr: true},
+		{name: "volumes only, scaled to zero", mode: migrationv1alpha1.MigrationModeVolumesOnly, replicas: ptr.To(int32(0))},
+		{name: "volumes only, running", mode: migrationv1alpha1.MigrationModeVolumesOnly, replicas: ptr.To(int32(3)), wantErr: true},
+		{name: "volumes only, replicas defaulted to one", mode: migrationv1alpha1.MigrationModeVolumesOnly, wantErr: true},
+	}
+	for _, tt := range tests {
+		m := &migrationv1alpha1.StatefulSetMigration{Spec: migrationv1alpha1.StatefulSetMigrationSpec{Mode: tt.mode}}
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "new", Name: "web"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: tt.replicas},
+		}
+		if err := checkExistingDestStatefulSet(m, sts); (err != nil) != tt.wantErr {
+			t.Errorf("%s: checkExistingDestStatefulSet() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+// VolumeWaitReportInterval is how often the state of a wait that has not
+// changed is written to status.volumeWaits, bounding the status writes of a
+// long wait
+const VolumeWaitReportInterval = 30 * time.Second
+
+// beginVolumeWait lists a wait on a volume in status.volumeWaits. Waits block
+// the reconcile, and with it the reconcile's status write, so the entry is
+// written straight away.
+func (r *StatefulSetMigrationReconciler) beginVolumeWait(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, volumeID, step string, waitingOn migrationv1alpha1.VolumeWaitSource, state string) {
+	before := m.DeepCopy()
+	now := metav1.NewTime(r.clock().Now())
+	endVolumeWait(m, volumeID)
+	m.Status.VolumeWaits = append(m.Status.VolumeWaits, migrationv1alpha1.VolumeWait{
+		VolumeID:      volumeID,
+		Step:          step,
+		WaitingOn:     waitingOn,
+		State:         state,
+		StartTime:     now,
+		LastProbeTime: &now,
+	})
+	r.patchVolumeWaits(ctx, m, before)
+}
+
+// probeVolumeWait records the live state of a volume being waited on. It is
+// written when it changed or VolumeWaitReportInterval has passed.
+func (r *StatefulSetMigrationReconciler) probeVolumeWait(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, volumeID, state string) {
+	i := slices.IndexFunc(m.Status.VolumeWaits, func(w migrationv1alpha1.VolumeWait) bool { return w.VolumeID == volumeID })
+	if i < 0 {
+		return
+	}
+	now := r.clock().Now()
+	wait := m.Status.VolumeWaits[i]
+	if wait.State == state && wait.LastProbeTime != nil && now.Sub(wait.LastProbeTime.Time) < VolumeWaitReportInterval {
+		return
+	}
+
+	before := m.DeepCopy()
+	m.Status.VolumeWaits[i].State = state
+	m.Status.VolumeWaits[i].LastProbeTime = &metav1.Time{Time: now}
+	r.patchVolumeWaits(ctx, m, before)
+}
+
+// endVolumeWait removes the wait on a volume from status.volumeWaits; the
+// reconcile's status write saves the removal
+func endVolumeWait(m *migrationv1alpha1.StatefulSetMigration, volumeID string) {
+	m.Status.VolumeWaits = slices.DeleteFunc(m.Status.VolumeWaits, func(w migrationv1alpha1.VolumeWait) bool {
+		return w.VolumeID == volumeID
+	})
+}
+
+// patchVolumeWaits writes the change to status.volumeWaits since before. The
+// patch goes through a copy, so the rest of the status the reconcile has
+// changed in m is kept for its own write, which the new resourceVersion
+// lets through. A failed write only costs visibility and is logged.
+func (r *StatefulSetMigrationReconciler) patchVolumeWaits(ctx context.Context, m, before *migrationv1alpha1.StatefulSetMigration) {
+	patched := m.DeepCopy()
+	if err := r.Status().Patch(ctx, patched, client.MergeFrom(before)); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to record volume wait")
+		return
+	}
+	m.ResourceVersion = patched.ResourceVersion
+}
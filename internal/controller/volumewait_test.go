@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+func TestVolumeWaits(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "web"},
+		Status:     migrationv1alpha1.StatefulSetMigrationStatus{Phase: migrationv1alpha1.PhaseMigratingPods},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+	clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	r := &StatefulSetMigrationReconciler{Client: c, Clock: clk}
+	ctx := context.Background()
+
+	get := func() *migrationv1alpha1.StatefulSetMigration {
+		t.Helper()
+		var got migrationv1alpha1.StatefulSetMigration
+		if err := c.Get(ctx, client.ObjectKeyFromObject(m), &got); err != nil {
+			t.Fatal(err)
+		}
+		return &got
+	}
+	stored := func() []migrationv1alpha1.VolumeWait { return get().Status.VolumeWaits }
+
+	// Changes the reconcile has not written yet stay out of the patch
+	m.Status.CurrentIndex = 2
+	r.beginVolumeWait(ctx, m, "vol-0123", StepDetachVolume, migrationv1alpha1.VolumeWaitSourceAWS, "detaching")
+	if got := get().Status.CurrentIndex; got != 0 {
+		t.Errorf("currentIndex = %d, want it left to the reconcile's status write", got)
+	}
+	waits := stored()
+	if len(waits) != 1 || waits[0].VolumeID != "vol-0123" || waits[0].WaitingOn != migrationv1alpha1.VolumeWaitSourceAWS ||
+		waits[0].Step != StepDetachVolume || waits[0].State != "detaching" {
+		t.Fatalf("volumeWaits = %+v, want the detach of vol-0123", waits)
+	}
+
+	// An unchanged state is only written once the report interval passed
+	clk.Step(10 * time.Second)
+	r.probeVolumeWait(ctx, m, "vol-0123", "detaching")
+	if got := stored()[0].LastProbeTime; !got.Time.Equal(waits[0].LastProbeTime.Time) {
+		t.Errorf("lastProbeTime = %v, want unchanged within the report interval", got)
+	}
+	clk.Step(VolumeWaitReportInterval)
+	r.probeVolumeWait(ctx, m, "vol-0123", "detaching")
+	if got := stored()[0].LastProbeTime; !got.Time.Equal(clk.Now()) {
+		t.Errorf("lastProbeTime = %v, want %v", got, clk.Now())
+	}
+
+	// A new state is written straight away
+	r.probeVolumeWait(ctx, m, "vol-0123", "available")
+	if got := stored()[0].State; got != "available" {
+		t.Errorf("state = %q, want available", got)
+	}
+
+	// The reconcile's own status write goes through after the patches
+	endVolumeWait(m, "vol-0123")
+	if err := c.Status().Update(ctx, m); err != nil {
+		t.Fatalf("status update after volume wait patches: %v", err)
+	}
+	if waits := stored(); len(waits) != 0 {
+		t.Errorf("volumeWaits = %+v, want none after the wait ended", waits)
+	}
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+)
+
+// +kubebuilder:webhook:path=/validate-migration-aqua-io-v1alpha1-statefulsetmigration,mutating=false,failurePolicy=fail,sideEffects=None,groups=migration.aqua.io,resources=statefulsetmigrations,verbs=create;update,versions=v1alpha1,name=vstatefulsetmigration.migration.aqua.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-migration-aqua-io-v1alpha1-volumemigration,mutating=false,failurePolicy=fail,sideEffects=None,groups=migration.aqua.io,resources=volumemigrations,verbs=create;update,versions=v1alpha1,name=vvolumemigration.migration.aqua.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-migration-aqua-io-v1alpha1-migrationassessment,mutating=false,failurePolicy=fail,sideEffects=None,groups=migration.aqua.io,resources=migrationassessments,verbs=create;update,versions=v1alpha1,name=vmigrationassessment.migration.aqua.io,admissionReviewVersions=v1
+
+// TargetValidator rejects StatefulSetMigrations, VolumeMigrations and
+// MigrationAssessments whose targets the TargetPolicy does not allow, so they
+// are refused on creation instead of failing once reconciled. An update is
+// only checked when it changes the targets, so annotating a denied migration
+// to abort or retry it is not refused.
+//
+// A target that cannot be checked, such as one whose kubeconfig Secret does
+// not exist yet, is admitted with a warning; the controller checks it again
+// before the migration starts.
+//
+// With Migrations set, a new StatefulSetMigration of a StatefulSet another
+// active migration already targets is refused too, rather than held by the
+// guard lease once reconciled.
+type TargetValidator struct {
+	Policy *TargetPolicy
+
+	// Migrations reads the StatefulSetMigrations a new one is checked
+	// against, and the kubeconfig Secrets naming their source clusters (optional)
+	Migrations client.Reader
+}
+
+var _ admission.CustomValidator = &TargetValidator{}
+
+// SetupWebhookWithManager registers the validating webhooks of the three kinds with the manager's webhook server
+func (v *TargetValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	for _, obj := range []runtime.Object{
+		&migrationv1alpha1.StatefulSetMigration{},
+		&migrationv1alpha1.VolumeMigration{},
+		&migrationv1alpha1.MigrationAssessment{},
+	} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(obj).WithValidator(v).Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateCreate checks the targets of a new object, and that no other
+// active migration targets a new StatefulSetMigration's StatefulSet
+func (v *TargetValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	namespace, targets, err := admissionTargets(obj)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := v.validate(ctx, namespace, targets)
+	if err != nil {
+		return warnings, err
+	}
+	if m, ok := obj.(*migrationv1alpha1.StatefulSetMigration); ok && v.Migrations != nil {
+		duplicate, err := v.duplicateMigration(ctx, m)
+		if err != nil {
+			return append(warnings, fmt.Sprintf("Duplicate migrations not checked, the controller holds one before starting: %v", err)), nil
+		}
+		if duplicate != "" {
+			return warnings, fmt.Errorf("StatefulSet %s/%s is already being migrated by %s; wait for it to complete or delete it",
+				m.Spec.SourceNamespace, m.Spec.StatefulSetName, duplicate)
+		}
+	}
+	return warnings, nil
+}
+
+// duplicateMigration returns the namespace/name of an active migration of
+// m's source StatefulSet, or "" when there is none. Source clusters are
+// compared by API server, as the guard lease compares them, so migrations
+// that reach a cluster through different Secrets still collide; one whose
+// source cannot be resolved is skipped.
+func (v *TargetValidator) duplicateMigration(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration) (string, error) {
+	server, err := clusterServer(ctx, v.Migrations, m.Namespace, m.Spec.SourceCluster)
+	if err != nil {
+		return "", err
+	}
+	migrations := &migrationv1alpha1.StatefulSetMigrationList{}
+	if err := v.Migrations.List(ctx, migrations); err != nil {
+		return "", fmt.Errorf("failed to list migrations: %w", err)
+	}
+	for i := range migrations.Items {
+		other := &migrations.Items[i]
+		spec := &other.Spec
+		if other.Status.AppliedSpec != nil {
+			spec = other.Status.AppliedSpec
+		}
+		if other.DeletionTimestamp != nil || !holdsGuard(other.Status.Phase) ||
+			spec.SourceNamespace != m.Spec.SourceNamespace || spec.StatefulSetName != m.Spec.StatefulSetName {
+			continue
+		}
+		if other.Namespace == m.Namespace && other.Name == m.Name {
+			continue
+		}
+		otherServer, err := clusterServer(ctx, v.Migrations, other.Namespace, spec.SourceCluster)
+		if err != nil || otherServer != server {
+			continue
+		}
+		return guardHolder(other), nil
+	}
+	return "", nil
+}
+
+// ValidateUpdate checks the targets of an object when the update changes them
+func (v *TargetValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	_, oldTargets, err := admissionTargets(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	namespace, targets, err := admissionTargets(newObj)
+	if err != nil {
+		return nil, err
+	}
+	if reflect.DeepEqual(oldTargets, targets) {
+		return nil, nil
+	}
+	return v.validate(ctx, namespace, targets)
+}
+
+// ValidateDelete admits every deletion
+func (v *TargetValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *TargetValidator) validate(ctx context.Context, namespace string, targets []migrationTarget) (admission.Warnings, error) {
+	err := v.Policy.Check(ctx, namespace, targets)
+	switch {
+	case err == nil:
+		return nil, nil
+	case IsTargetPolicyError(err):
+		return nil, err
+	}
+	return admission.Warnings{fmt.Sprintf("Targets not checked, the controller checks them before starting: %v", err)}, nil
+}
+
+// admissionTargets returns the namespace and targets of an object the webhook validates
+func admissionTargets(obj runtime.Object) (string, []migrationTarget, error) {
+	switch o := obj.(type) {
+	case *migrationv1alpha1.StatefulSetMigration:
+		return o.Namespace, statefulSetMigrationTargets(o), nil
+	case *migrationv1alpha1.VolumeMigration:
+		return o.Namespace, volumeMigrationTargets(o), nil
+	case *migrationv1alpha1.MigrationAssessment:
+		return o.Namespace, assessmentTargets(o), nil
+	}
+	return "", nil, fmt.Errorf("unexpected object %T", obj)
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+const (
+	// AnnotationWindowEnd on a migration moves the end of its maintenance
+	// window, as an RFC 3339 time, since spec.schedule.endTime cannot be
+	// edited once the migration has started. Setting it to a later time
+	// resumes a migration held because its window closed.
+	AnnotationWindowEnd = "migration.aqua.io/window-end"
+
+	// ConditionWindowExceeded reports that the migration stopped moving pods
+	// because its maintenance window closed
+	ConditionWindowExceeded = "WindowExceeded"
+
+	// EventWindowExceeded is recorded when a migration stops because its
+	// maintenance window closed
+	EventWindowExceeded = "WindowExceeded"
+
+	// StepWindow records holding at, and resuming after, the end of the
+	// maintenance window
+	StepWindow = "MaintenanceWindow"
+)
+
+// windowEnd returns when the migration's maintenance window closes: the
+// migration.aqua.io/window-end annotation, or else spec.schedule.endTime,
+// which an annotation that is not a time also falls back to with an error.
+// ok is false when the window has no end.
+func windowEnd(m *migrationv1alpha1.StatefulSetMigration) (end time.Time, ok bool, err error) {
+	if value := m.Annotations[AnnotationWindowEnd]; value != "" {
+		if end, err = time.Parse(time.RFC3339, value); err == nil {
+			return end, true, nil
+		}
+		err = fmt.Errorf("annotation %s=%q is not an RFC 3339 time", AnnotationWindowEnd, value)
+	}
+	if s := m.Spec.Schedule; s != nil && s.EndTime != nil {
+		return s.EndTime.Time, true, err
+	}
+	return time.Time{}, false, err
+}
+
+// holdForWindow reports whether the migration must stop before the pods at
+// positions because its maintenance window has closed, so no more downtime
+// is taken outside it. Pods whose source pods are already gone are still in
+// flight from an earlier reconcile and finish first. While holding, it sets
+// ConditionWindowExceeded and writes the status; nothing is requeued, since
+// annotating a new window end reconciles the migration again.
+func (r *StatefulSetMigrationReconciler) holdForWindow(ctx context.Context, m *migrationv1alpha1.StatefulSetMigration, sourceClient *multicluster.ClusterClient, positions []int) (bool, error) {
+	logger := log.FromContext(ctx)
+	before := m.Status.DeepCopy()
+	held := meta.IsStatusConditionTrue(m.Status.Conditions, ConditionWindowExceeded)
+
+	end, ok, err := windowEnd(m)
+	if err != nil {
+		logger.Info("Ignoring invalid maintenance window end", "error", err.Error())
+	}
+	now := r.clock().Now()
+	if !ok || now.Before(end) {
+		if held {
+			message := "The maintenance window was extended"
+			if ok {
+				message += " to " + end.UTC().Format(time.RFC3339)
+			}
+			logger.Info("Resuming migration", "reason", message)
+			r.recordHistory(m, StepWindow, "", migrationv1alpha1.HistoryResultSucceeded, message)
+			r.setCondition(m, ConditionWindowExceeded, metav1.ConditionFalse, "Extended", message)
+			return false, r.updateStatus(ctx, m, before)
+		}
+		return false, nil
+	}
+
+	for _, position := range positions {
+		podName := fmt.Sprintf("%s-%d", m.Spec.StatefulSetName, ordinalAt(m, position))
+		err := sourceClient.Client.Get(ctx, types.NamespacedName{Namespace: m.Spec.SourceNamespace, Name: podName}, &corev1.Pod{})
+		if apierrors.IsNotFound(err) {
+			logger.Info("Finishing pod in flight after the maintenance window closed", "pod", podName)
+			return false, nil
+		}
+		if err != nil {
+			return true, fmt.Errorf("failed to get source pod %s: %w", podName, err)
+		}
+	}
+
+	if held {
+		return true, nil
+	}
+	closed := end.UTC().Format(time.RFC3339)
+	message := fmt.Sprintf("Stopped before pod %d because the maintenance window closed at %s; %d of %d pods moved. Annotate the migration with %s set to a later time to resume",
+		ordinalAt(m, positions[0]), closed, m.Status.CurrentIndex, m.Status.TotalReplicas, AnnotationWindowEnd)
+	logger.Info("Holding migration outside its maintenance window", "windowEnd", closed, "moved", m.Status.CurrentIndex)
+	r.recordHistory(m, StepWindow, "", migrationv1alpha1.HistoryResultStarted, message)
+	r.setCondition(m, ConditionWindowExceeded, metav1.ConditionTrue, "Held", message)
+	r.event(m, corev1.EventTypeWarning, EventWindowExceeded, message)
+	return true, r.updateStatus(ctx, m, before)
+}
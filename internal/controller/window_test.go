@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+func TestHoldForWindow(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := migrationv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 11, 4, 6, 0, 0, 0, time.UTC)
+	sourcePod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "db-2"}}
+
+	tests := []struct {
+		name        string
+		end         time.Time
+		annotation  string
+		held        bool
+		podGone     bool
+		wantHeld    bool
+		wantReason  string
+		wantHistory int
+	}{
+		{name: "no window end"},
+		{name: "window open", end: now.Add(time.Minute)},
+		{name: "window closed", end: now.Add(-time.Minute), wantHeld: true, wantReason: "Held", wantHistory: 1},
+		{name: "window closed with the pod in flight", end: now.Add(-time.Minute), podGone: true},
+		{name: "still held", end: now.Add(-time.Minute), held: true, wantHeld: true, wantReason: "Held"},
+		{name: "window extended", end: now.Add(-time.Minute), annotation: now.Add(time.Hour).Format(time.RFC3339), held: true,
+			wantReason: "Extended", wantHistory: 1},
+		{name: "invalid extension", end: now.Add(-time.Minute), annotation: "tomorrow", held: true, wantHeld: true, wantReason: "Held"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &migrationv1alpha1.StatefulSetMigration{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "db", Annotations: map[string]string{}},
+				Spec:       migrationv1alpha1.StatefulSetMigrationSpec{SourceNamespace: "prod", StatefulSetName: "db"},
+				Status:     migrationv1alpha1.StatefulSetMigrationStatus{Phase: migrationv1alpha1.PhaseMigratingPods, CurrentIndex: 2, TotalReplicas: 3},
+			}
+			if !tt.end.IsZero() {
+				m.Spec.Schedule = &migrationv1alpha1.ScheduleConfig{StartTime: metav1.NewTime(now.Add(-4 * time.Hour)), EndTime: &metav1.Time{Time: tt.end}}
+			}
+			if tt.annotation != "" {
+				m.Annotations[AnnotationWindowEnd] = tt.annotation
+			}
+			if tt.held {
+				meta.SetStatusCondition(&m.Status.Conditions, metav1.Condition{Type: ConditionWindowExceeded, Status: metav1.ConditionTrue, Reason: "Held"})
+			}
+			mgmt := fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).WithStatusSubresource(m).Build()
+			var sourceObjects []client.Object
+			if !tt.podGone {
+				sourceObjects = append(sourceObjects, sourcePod)
+			}
+			source := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(sourceObjects...).Build()
+			r := &StatefulSetMigrationReconciler{Client: mgmt, Recorder: record.NewFakeRecorder(10), Clock: clocktesting.NewFakeClock(now)}
+
+			held, err := r.holdForWindow(context.Background(), m, &multicluster.ClusterClient{Client: source}, []int{2})
+			if err != nil {
+				t.Fatalf("holdForWindow() error = %v", err)
+			}
+			if held != tt.wantHeld {
+				t.Errorf("holdForWindow() = %v, want %v", held, tt.wantHeld)
+			}
+			c := meta.FindStatusCondition(m.Status.Conditions, ConditionWindowExceeded)
+			if tt.wantReason == "" && c != nil {
+				t.Errorf("condition = %+v, want none", c)
+			}
+			if tt.wantReason != "" && (c == nil || c.Reason != tt.wantReason) {
+				t.Errorf("condition = %+v, want reason %s", c, tt.wantReason)
+			}
+			if len(m.Status.History) != tt.wantHistory {
+				t.Errorf("history = %+v, want %d entries", m.Status.History, tt.wantHistory)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Package metrics defines the Prometheus metrics shared by the controller and the storagemover CLI
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+const namespace = "aqua_migration"
+
+var (
+	// VolumeDetachDuration tracks how long EBS volumes take to become available after their pod is deleted
+	VolumeDetachDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "volume_detach_duration_seconds",
+		Help:      "Time spent waiting for an EBS volume to detach and become available.",
+		Buckets:   []float64{5, 10, 20, 30, 60, 120, 300, 600},
+	})
+
+	// StepsTotal counts migration steps by step name and result
+	StepsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "steps_total",
+		Help:      "Migration steps performed, by step and result.",
+	}, []string{"step", "result"})
+
+	// ActiveWaits counts the blocking waits in progress inside reconciles, by wait
+	ActiveWaits = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_waits",
+		Help:      "Blocking waits in progress inside reconciles, by wait.",
+	}, []string{"wait"})
+
+	// RemoteAPIRequestDuration tracks the latency of requests to remote API servers, by cluster and status code
+	RemoteAPIRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "remote_api_request_duration_seconds",
+		Help:      "Latency of requests to remote cluster API servers, by cluster and status code.",
+		Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"cluster", "code"})
+
+	// RemoteAPIErrorsTotal counts remote API requests that got no response or a 5xx, by cluster
+	RemoteAPIErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "remote_api_errors_total",
+		Help:      "Requests to remote cluster API servers that got no response or a 5xx, by cluster.",
+	}, []string{"cluster"})
+
+	// RemoteAPIThrottledTotal counts throttled remote API requests, by cluster and source
+	RemoteAPIThrottledTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "remote_api_throttled_total",
+		Help:      "Requests to remote cluster API servers delayed by the client rate limiter or rejected with 429, by cluster and source.",
+	}, []string{"cluster", "source"})
+
+	// StorageMigratedBytes counts the capacity of the volumes moved, by the namespace of the migration
+	StorageMigratedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_migrated_bytes_total",
+		Help:      "Capacity of the volumes moved by migrations, by the namespace of the migration.",
+	}, []string{"namespace"})
+)
+
+// collectors returns every metric defined by this package
+func collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		VolumeDetachDuration,
+		StepsTotal,
+		ActiveWaits,
+		RemoteAPIRequestDuration,
+		RemoteAPIErrorsTotal,
+		RemoteAPIThrottledTotal,
+		StorageMigratedBytes,
+	}
+}
+
+// Register registers the migration metrics with the given registerer
+func Register(reg prometheus.Registerer) error {
+	for _, c := range collectors() {
+		if err := reg.Register(c); err != nil {
+			return fmt.Errorf("failed to register metric: %w", err)
+		}
+	}
+	return nil
+}
+
+// ObserveStep records the outcome of a migration step
+func ObserveStep(step, result string) {
+	StepsTotal.WithLabelValues(step, result).Inc()
+}
+
+// ObserveVolumeDetach records how long a volume took to detach
+func ObserveVolumeDetach(d time.Duration) {
+	VolumeDetachDuration.Observe(d.Seconds())
+}
+
+// ObserveStorageMigrated records a moved volume's capacity
+func ObserveStorageMigrated(namespace string, bytes int64) {
+	StorageMigratedBytes.WithLabelValues(namespace).Add(float64(bytes))
+}
+
+// TrackWait counts a blocking wait as active until the returned function is called
+func TrackWait(wait string) func() {
+	g := ActiveWaits.WithLabelValues(wait)
+	g.Inc()
+	return g.Dec
+}
+
+// RemoteAPIObserver records the requests of remote cluster clients in the
+// remote API metrics
+type RemoteAPIObserver struct{}
+
+// ObserveRequest records a request's latency
+func (RemoteAPIObserver) ObserveRequest(cluster, code string, latency time.Duration) {
+	RemoteAPIRequestDuration.WithLabelValues(cluster, code).Observe(latency.Seconds())
+}
+
+// ObserveError records a failed request
+func (RemoteAPIObserver) ObserveError(cluster string) {
+	RemoteAPIErrorsTotal.WithLabelValues(cluster).Inc()
+}
+
+// ObserveThrottle records a throttled request
+func (RemoteAPIObserver) ObserveThrottle(cluster, source string) {
+	RemoteAPIThrottledTotal.WithLabelValues(cluster, source).Inc()
+}
+
+// RegisterClientStats registers gauges reporting the remote cluster clients
+// and informer caches counted by stats, which is called at scrape time
+func RegisterClientStats(reg prometheus.Registerer, stats func() (clients, caches int)) error {
+	for _, c := range []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "remote_clients",
+			Help:      "Remote cluster clients cached by the controller.",
+		}, func() float64 {
+			clients, _ := stats()
+			return float64(clients)
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "remote_informer_caches",
+			Help:      "Namespace informer caches running against remote clusters.",
+		}, func() float64 {
+			_, caches := stats()
+			return float64(caches)
+		}),
+	} {
+		if err := reg.Register(c); err != nil {
+			return fmt.Errorf("failed to register metric: %w", err)
+		}
+	}
+	return nil
+}
+
+// Push sends the migration metrics to a Prometheus Pushgateway under the given job name
+func Push(url, job string) error {
+	reg := prometheus.NewRegistry()
+	if err := Register(reg); err != nil {
+		return err
+	}
+	if err := push.New(url, job).Gatherer(reg).Add(); err != nil {
+		return fmt.Errorf("failed to push metrics to %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegister(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if err := Register(reg); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := Register(reg); err == nil {
+		t.Error("expected an error registering the same metrics twice")
+	}
+}
+
+func TestObserve(t *testing.T) {
+	before := testutil.ToFloat64(StepsTotal.WithLabelValues("CreatePV", "Succeeded"))
+	ObserveStep("CreatePV", "Succeeded")
+	if got := testutil.ToFloat64(StepsTotal.WithLabelValues("CreatePV", "Succeeded")); got != before+1 {
+		t.Errorf("steps_total = %v, want %v", got, before+1)
+	}
+
+	ObserveStorageMigrated("ops", 100<<30)
+	ObserveStorageMigrated("ops", 50<<30)
+	if got := testutil.ToFloat64(StorageMigratedBytes.WithLabelValues("ops")); got != 150<<30 {
+		t.Errorf("storage_migrated_bytes_total = %v, want %v", got, 150<<30)
+	}
+
+	ObserveVolumeDetach(12 * time.Second)
+	if got := testutil.CollectAndCount(VolumeDetachDuration); got != 1 {
+		t.Errorf("CollectAndCount() = %d, want 1", got)
+	}
+}
+
+func TestTrackWait(t *testing.T) {
+	done := TrackWait("WaitPodReady")
+	if got := testutil.ToFloat64(ActiveWaits.WithLabelValues("WaitPodReady")); got != 1 {
+		t.Errorf("active_waits = %v, want 1", got)
+	}
+	done()
+	if got := testutil.ToFloat64(ActiveWaits.WithLabelValues("WaitPodReady")); got != 0 {
+		t.Errorf("active_waits = %v, want 0", got)
+	}
+}
+
+func TestRemoteAPIObserver(t *testing.T) {
+	var o RemoteAPIObserver
+	o.ObserveRequest("api.example.com", "503", 200*time.Millisecond)
+	o.ObserveError("api.example.com")
+	o.ObserveThrottle("api.example.com", "client")
+
+	if got := testutil.CollectAndCount(RemoteAPIRequestDuration); got != 1 {
+		t.Errorf("CollectAndCount(remote_api_request_duration_seconds) = %d, want 1", got)
+	}
+	if got := testutil.ToFloat64(RemoteAPIErrorsTotal.WithLabelValues("api.example.com")); got != 1 {
+		t.Errorf("remote_api_errors_total = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(RemoteAPIThrottledTotal.WithLabelValues("api.example.com", "client")); got != 1 {
+		t.Errorf("remote_api_throttled_total = %v, want 1", got)
+	}
+}
+
+func TestRegisterClientStats(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if err := RegisterClientStats(reg, func() (int, int) { return 3, 5 }); err != nil {
+		t.Fatalf("RegisterClientStats() error = %v", err)
+	}
+
+	want := `
+# HELP aqua_migration_remote_clients Remote cluster clients cached by the controller.
+# TYPE aqua_migration_remote_clients gauge
+aqua_migration_remote_clients 3
+# HELP aqua_migration_remote_informer_caches Namespace informer caches running against remote clusters.
+# TYPE aqua_migration_remote_informer_caches gauge
+aqua_migration_remote_informer_caches 5
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
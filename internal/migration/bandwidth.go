@@ -0,0 +1,178 @@
+package migration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// LabelBandwidthProbe marks the server and client pods that measure the
+	// throughput between the clusters, with the name of the migration
+	LabelBandwidthProbe = "migration.aqua.io/bandwidth-probe"
+
+	// DefaultBandwidthProbeSizeMiB is how much data the client fetches
+	// unless another size is given
+	DefaultBandwidthProbeSizeMiB = 256
+
+	// DefaultBandwidthProbePort is the port the server listens on unless
+	// another is given
+	DefaultBandwidthProbePort = 8080
+)
+
+// bandwidthServerCommand writes $PROBE_SIZE_MIB of random data, so nothing
+// on the path can compress it, and an empty file to time the round trip,
+// then serves both over HTTP on $PROBE_PORT
+const bandwidthServerCommand = `mkdir -p /www && dd if=/dev/urandom of=/www/data bs=1048576 count="$PROBE_SIZE_MIB" 2>/dev/null && : > /www/ping && exec httpd -f -p "$PROBE_PORT" -h /www`
+
+// bandwidthClientCommand times a fetch of the empty file and then of the
+// data from $PROBE_ADDRESS:$PROBE_PORT, and writes the result for
+// ParseBandwidthResult to its termination message
+const bandwidthClientCommand = `set -e
+url="http://$PROBE_ADDRESS:$PROBE_PORT"
+start=$(date +%s%N)
+wget -q -O /dev/null "$url/ping"
+fetched=$(date +%s%N)
+bytes=$(wget -q -O - "$url/data" | wc -c)
+end=$(date +%s%N)
+if [ "$bytes" -ne $((PROBE_SIZE_MIB * 1048576)) ]; then echo "fetched $bytes of $PROBE_SIZE_MIB MiB from $url"; exit 1; fi
+echo "latencyMs=$(( (fetched - start) / 1000000 )) bytes=$bytes nanos=$((end - fetched))" > /dev/termination-log`
+
+// BandwidthServerPodName returns the name of the pod that serves the data
+// a migration's bandwidth probe fetches
+func BandwidthServerPodName(migrationName string) string {
+	return "bandwidth-server-" + migrationName
+}
+
+// BandwidthClientPodName returns the name of the pod that fetches the data
+// of a migration's bandwidth probe
+func BandwidthClientPodName(migrationName string) string {
+	return "bandwidth-client-" + migrationName
+}
+
+// BandwidthServerPod returns a pod that serves sizeMiB of random data over
+// HTTP on port until it is deleted. It is Ready once the data is written.
+func BandwidthServerPod(namespace, migrationName, image string, sizeMiB, port int32) *corev1.Pod {
+	pod := bandwidthPod(namespace, BandwidthServerPodName(migrationName), migrationName, image, bandwidthServerCommand, sizeMiB, port)
+	container := &pod.Spec.Containers[0]
+	container.Ports = []corev1.ContainerPort{{Name: "http", ContainerPort: port, Protocol: corev1.ProtocolTCP}}
+	container.ReadinessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{Path: "/ping", Port: intstr.FromInt32(port)},
+		},
+		PeriodSeconds: 2,
+	}
+	pod.Spec.Volumes = []corev1.Volume{{Name: "www", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+	container.VolumeMounts = []corev1.VolumeMount{{Name: "www", MountPath: "/www"}}
+	return pod
+}
+
+// BandwidthClientPod returns a pod that fetches the data of a server pod
+// at address once and exits. Like an inspection pod, its termination
+// message falls back to the tail of its log when the fetch fails.
+func BandwidthClientPod(namespace, migrationName, image, address string, sizeMiB, port int32) *corev1.Pod {
+	pod := bandwidthPod(namespace, BandwidthClientPodName(migrationName), migrationName, image, bandwidthClientCommand, sizeMiB, port)
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, corev1.EnvVar{Name: "PROBE_ADDRESS", Value: address})
+	return pod
+}
+
+func bandwidthPod(namespace, name, migrationName, image, command string, sizeMiB, port int32) *corev1.Pod {
+	if image == "" {
+		image = DefaultInspectionImage
+	}
+	labelValue := migrationName
+	if len(labelValue) > 63 {
+		labelValue = strings.TrimRight(labelValue[:63], "-.")
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{LabelBandwidthProbe: labelValue},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			TerminationGracePeriodSeconds: ptr.To(int64(0)),
+			Containers: []corev1.Container{{
+				Name:    "probe",
+				Image:   image,
+				Command: []string{"sh", "-c", command},
+				Env: []corev1.EnvVar{
+					{Name: "PROBE_SIZE_MIB", Value: strconv.Itoa(int(sizeMiB))},
+					{Name: "PROBE_PORT", Value: strconv.Itoa(int(port))},
+				},
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			}},
+		},
+	}
+}
+
+// BandwidthClientResult reports whether a bandwidth client pod has
+// finished, and the reason it failed if it did not succeed
+func BandwidthClientResult(pod *corev1.Pod) (done bool, message string, err error) {
+	return commandResult(pod, "bandwidth probe")
+}
+
+// BandwidthMeasurement is what a bandwidth client pod measured
+type BandwidthMeasurement struct {
+	// Latency is how long fetching the empty file took
+	Latency time.Duration
+
+	// Bytes is how much data was fetched
+	Bytes int64
+
+	// Elapsed is how long fetching the data took
+	Elapsed time.Duration
+}
+
+// BytesPerSecond returns the throughput of the fetch
+func (b BandwidthMeasurement) BytesPerSecond() int64 {
+	if b.Elapsed <= 0 {
+		return 0
+	}
+	return int64(float64(b.Bytes) / b.Elapsed.Seconds())
+}
+
+// ParseBandwidthResult parses the termination message of a bandwidth client
+// pod, "latencyMs=<ms> bytes=<n> nanos=<ns>"
+func ParseBandwidthResult(message string) (BandwidthMeasurement, error) {
+	values := map[string]int64{}
+	for _, field := range strings.Fields(message) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return BandwidthMeasurement{}, fmt.Errorf("bandwidth probe result %q: %s is not a number", message, key)
+		}
+		values[key] = n
+	}
+	for _, key := range []string{"latencyMs", "bytes", "nanos"} {
+		if _, ok := values[key]; !ok {
+			return BandwidthMeasurement{}, fmt.Errorf("bandwidth probe result %q has no %s", message, key)
+		}
+	}
+	if values["nanos"] <= 0 {
+		return BandwidthMeasurement{}, fmt.Errorf("bandwidth probe result %q has no elapsed time", message)
+	}
+	return BandwidthMeasurement{
+		Latency: time.Duration(values["latencyMs"]) * time.Millisecond,
+		Bytes:   values["bytes"],
+		Elapsed: time.Duration(values["nanos"]),
+	}, nil
+}
+
+// CopyDuration estimates how long copying gib GiB takes at bytesPerSecond
+func CopyDuration(gib, bytesPerSecond int64) time.Duration {
+	if bytesPerSecond <= 0 {
+		return 0
+	}
+	return time.Duration(float64(gib<<30) / float64(bytesPerSecond) * float64(time.Second))
+}
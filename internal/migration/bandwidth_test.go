@@ -0,0 +1,54 @@
+package migration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBandwidthPods(t *testing.T) {
+	server := BandwidthServerPod("new", "web", "", 64, 9000)
+	if server.Name != "bandwidth-server-web" || server.Namespace != "new" || server.Labels[LabelBandwidthProbe] != "web" {
+		t.Errorf("server metadata = %+v", server.ObjectMeta)
+	}
+	container := server.Spec.Containers[0]
+	if container.Image != DefaultInspectionImage || container.ReadinessProbe == nil || container.ReadinessProbe.HTTPGet.Port.IntValue() != 9000 {
+		t.Errorf("server container = %+v, want the default image and a readiness probe on 9000", container)
+	}
+
+	client := BandwidthClientPod("old", "web", "tools:1", "10.1.2.3", 64, 9000)
+	env := map[string]string{}
+	for _, e := range client.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	if client.Name != "bandwidth-client-web" || client.Spec.Containers[0].Image != "tools:1" {
+		t.Errorf("client = %s %s", client.Name, client.Spec.Containers[0].Image)
+	}
+	if env["PROBE_ADDRESS"] != "10.1.2.3" || env["PROBE_PORT"] != "9000" || env["PROBE_SIZE_MIB"] != "64" {
+		t.Errorf("client env = %v", env)
+	}
+}
+
+func TestParseBandwidthResult(t *testing.T) {
+	got, err := ParseBandwidthResult("latencyMs=12 bytes=268435456 nanos=2000000000\n")
+	if err != nil {
+		t.Fatalf("ParseBandwidthResult() error = %v", err)
+	}
+	if got.Latency != 12*time.Millisecond || got.Bytes != 268435456 || got.BytesPerSecond() != 134217728 {
+		t.Errorf("ParseBandwidthResult() = %+v, %d B/s", got, got.BytesPerSecond())
+	}
+
+	for _, message := range []string{"", "latencyMs=12 bytes=1", "latencyMs=12 bytes=1 nanos=%N", "latencyMs=12 bytes=1 nanos=0"} {
+		if _, err := ParseBandwidthResult(message); err == nil {
+			t.Errorf("ParseBandwidthResult(%q) error = nil, want an error", message)
+		}
+	}
+}
+
+func TestCopyDuration(t *testing.T) {
+	if got := CopyDuration(1024, 1<<30); got != 1024*time.Second {
+		t.Errorf("CopyDuration(1024 GiB, 1 GiB/s) = %s, want 17m4s", got)
+	}
+	if got := CopyDuration(1024, 0); got != 0 {
+		t.Errorf("CopyDuration() with no throughput = %s, want 0", got)
+	}
+}
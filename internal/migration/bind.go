@@ -0,0 +1,122 @@
+// Package migration provides core migration logic for StatefulSet migrations
+package migration
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+const (
+	// LabelDestPVC and LabelDestNamespace identify the PVC a translated PV was created for
+	LabelDestPVC       = "migration.aqua.io/dest-pvc"
+	LabelDestNamespace = "migration.aqua.io/dest-namespace"
+
+	// AnnotationVolumeID records the EBS volume a translated PV or PVC refers to
+	AnnotationVolumeID = "migration.aqua.io/volume-id"
+
+	// annotationProvisionedBy is set by external provisioners on the PVs they create
+	annotationProvisionedBy = "pv.kubernetes.io/provisioned-by"
+)
+
+// BindProblem is a reason a destination PVC is not bound to its pre-created PV
+type BindProblem struct {
+	// Problem describes what is wrong
+	Problem string
+
+	// Fix describes how to resolve it
+	Fix string
+}
+
+// VerifyBinding checks that a destination PVC is Bound to the PV pre-created
+// for it, and that the PV refers to the expected EBS volume and to this PVC
+// by claimRef UID. bound is the PV the PVC is actually bound to when that is
+// another PV, or nil. An empty volumeID skips the volume handle check.
+func VerifyBinding(pvc *corev1.PersistentVolumeClaim, expected, bound *corev1.PersistentVolume, volumeID string) []BindProblem {
+	var problems []BindProblem
+	add := func(problem, fix string) {
+		problems = append(problems, BindProblem{Problem: problem, Fix: fix})
+	}
+
+	if pvc.Spec.VolumeName != "" && pvc.Spec.VolumeName != expected.Name {
+		if bound != nil && bound.Annotations[annotationProvisionedBy] != "" {
+			add(fmt.Sprintf("PVC is bound to %s, which %s provisioned dynamically before the pre-created PV could bind (immediate binding race)",
+				bound.Name, bound.Annotations[annotationProvisionedBy]),
+				fmt.Sprintf("scale the StatefulSet down, delete the PVC and %s (check its reclaim policy first, as it owns a new, empty volume), then recreate the PVC with spec.volumeName: %s",
+					bound.Name, expected.Name))
+		} else {
+			add(fmt.Sprintf("PVC is bound to %s instead of %s", pvc.Spec.VolumeName, expected.Name),
+				fmt.Sprintf("delete the PVC and recreate it with spec.volumeName: %s", expected.Name))
+		}
+	} else if pvc.Spec.VolumeName == "" {
+		add("PVC does not name a volume in spec.volumeName, so any matching PV or the provisioner can satisfy it",
+			fmt.Sprintf("delete the PVC and recreate it with spec.volumeName: %s", expected.Name))
+	}
+
+	if volumeID != "" {
+		if got, err := translate.EBSVolumeID(expected); err != nil {
+			add(err.Error(), "recreate the PV from the source PV with storagemover translate")
+		} else if got != volumeID {
+			add(fmt.Sprintf("PV %s refers to volume %s, not %s", expected.Name, got, volumeID),
+				"recreate the PV from the source PV with storagemover translate")
+		}
+	}
+
+	ref := expected.Spec.ClaimRef
+	switch {
+	case ref == nil:
+		add(fmt.Sprintf("PV %s has no claimRef, so another PVC can claim it", expected.Name),
+			fmt.Sprintf("set spec.claimRef to namespace %s, name %s on the PV", pvc.Namespace, pvc.Name))
+	case ref.Namespace != pvc.Namespace || ref.Name != pvc.Name:
+		add(fmt.Sprintf("PV %s is reserved for %s/%s", expected.Name, ref.Namespace, ref.Name),
+			fmt.Sprintf("point spec.claimRef at %s/%s, if the other claim does not need the volume", pvc.Namespace, pvc.Name))
+	case ref.UID != "" && ref.UID != pvc.UID:
+		add(fmt.Sprintf("PV %s is reserved for an earlier PVC with UID %s, not this one (%s)", expected.Name, ref.UID, pvc.UID),
+			"remove spec.claimRef.uid and spec.claimRef.resourceVersion from the PV so the recreated PVC can bind")
+	}
+	if expected.Status.Phase == corev1.VolumeReleased || expected.Status.Phase == corev1.VolumeFailed {
+		add(fmt.Sprintf("PV %s is %s", expected.Name, expected.Status.Phase),
+			"remove spec.claimRef.uid and spec.claimRef.resourceVersion from the PV to make it Available again")
+	}
+
+	pvcClass := ""
+	if pvc.Spec.StorageClassName != nil {
+		pvcClass = *pvc.Spec.StorageClassName
+	}
+	if pvcClass != expected.Spec.StorageClassName {
+		add(fmt.Sprintf("PVC requests StorageClass %q but PV %s has %q", pvcClass, expected.Name, expected.Spec.StorageClassName),
+			fmt.Sprintf("recreate the PVC with storageClassName: %q, or map the class with spec.storageClassMapping", expected.Spec.StorageClassName))
+	}
+	if volumeModeOrDefault(pvc.Spec.VolumeMode) != volumeModeOrDefault(expected.Spec.VolumeMode) {
+		add(fmt.Sprintf("PVC requests volumeMode %s but PV %s is %s", volumeModeOrDefault(pvc.Spec.VolumeMode), expected.Name, volumeModeOrDefault(expected.Spec.VolumeMode)),
+			"recreate the PVC with the PV's volumeMode")
+	}
+	for _, mode := range pvc.Spec.AccessModes {
+		if !hasAccessMode(expected.Spec.AccessModes, mode) {
+			add(fmt.Sprintf("PVC requests access mode %s, which PV %s does not offer", mode, expected.Name),
+				"recreate the PVC with the PV's access modes")
+		}
+	}
+	request := pvc.Spec.Resources.Requests.Storage()
+	if capacity := expected.Spec.Capacity.Storage(); request.Cmp(*capacity) > 0 {
+		add(fmt.Sprintf("PVC requests %s but PV %s has %s", request, expected.Name, capacity),
+			"recreate the PVC requesting no more than the PV's capacity")
+	}
+
+	if len(problems) == 0 && pvc.Status.Phase != corev1.ClaimBound {
+		add(fmt.Sprintf("PVC is %s", pvc.Status.Phase),
+			"check the PVC's events; binding can take a few seconds after creation")
+	}
+	return problems
+}
+
+func hasAccessMode(modes []corev1.PersistentVolumeAccessMode, mode corev1.PersistentVolumeAccessMode) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVerifyBinding(t *testing.T) {
+	class := "gp3"
+	pvc := func() *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-0", UID: "pvc-uid"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: &class,
+				VolumeName:       "migrated-prod-data-web-0",
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		}
+	}
+	pv := func() *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "migrated-prod-data-web-0"},
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: "gp3",
+				ClaimRef:         &corev1.ObjectReference{Namespace: "prod", Name: "data-web-0", UID: "pvc-uid"},
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{Driver: EBSCSIDriver, VolumeHandle: "vol-1"},
+				},
+			},
+			Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*corev1.PersistentVolumeClaim, *corev1.PersistentVolume)
+		bound  *corev1.PersistentVolume
+		want   []string
+	}{
+		{name: "bound as expected", modify: func(*corev1.PersistentVolumeClaim, *corev1.PersistentVolume) {}},
+		{
+			name: "immediate binding race",
+			modify: func(c *corev1.PersistentVolumeClaim, _ *corev1.PersistentVolume) {
+				c.Spec.VolumeName = "pvc-1234"
+			},
+			bound: &corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{
+				Name: "pvc-1234", Annotations: map[string]string{"pv.kubernetes.io/provisioned-by": EBSCSIDriver},
+			}},
+			want: []string{"immediate binding race"},
+		},
+		{
+			name: "storage class mismatch",
+			modify: func(c *corev1.PersistentVolumeClaim, v *corev1.PersistentVolume) {
+				c.Status.Phase = corev1.ClaimPending
+				v.Spec.StorageClassName = "gp2"
+			},
+			want: []string{`PVC requests StorageClass "gp3" but PV migrated-prod-data-web-0 has "gp2"`},
+		},
+		{
+			name: "claimRef of an earlier PVC",
+			modify: func(c *corev1.PersistentVolumeClaim, v *corev1.PersistentVolume) {
+				c.Status.Phase = corev1.ClaimPending
+				v.Spec.ClaimRef.UID = "old-uid"
+				v.Status.Phase = corev1.VolumeReleased
+			},
+			want: []string{"earlier PVC with UID old-uid", "is Released"},
+		},
+		{
+			name: "wrong volume",
+			modify: func(_ *corev1.PersistentVolumeClaim, v *corev1.PersistentVolume) {
+				v.Spec.CSI.VolumeHandle = "vol-2"
+			},
+			want: []string{"refers to volume vol-2, not vol-1"},
+		},
+		{
+			name: "still pending",
+			modify: func(c *corev1.PersistentVolumeClaim, _ *corev1.PersistentVolume) {
+				c.Status.Phase = corev1.ClaimPending
+			},
+			want: []string{"PVC is Pending"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, v := pvc(), pv()
+			tt.modify(c, v)
+			got := VerifyBinding(c, v, tt.bound, "vol-1")
+			if len(got) != len(tt.want) {
+				t.Fatalf("VerifyBinding() = %+v, want %d problems", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i].Problem, want) || got[i].Fix == "" {
+					t.Errorf("problem %d = %+v, want it to mention %q with a fix", i, got[i], want)
+				}
+			}
+		})
+	}
+}
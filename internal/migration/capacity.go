@@ -0,0 +1,155 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// EBSCSIDriver is the name of the AWS EBS CSI driver
+const EBSCSIDriver = "ebs.csi.aws.com"
+
+// InTreeEBSAttacher is the attacher of volumes the in-tree EBS plugin attaches
+const InTreeEBSAttacher = "kubernetes.io/aws-ebs"
+
+// ResourceEBSAttachLimit is the node allocatable resource the in-tree EBS
+// plugin reports its attachment limit in
+const ResourceEBSAttachLimit corev1.ResourceName = "attachable-volumes-aws-ebs"
+
+// ZoneCapacity is the EBS attachment capacity of the schedulable nodes in one availability zone
+type ZoneCapacity struct {
+	// Zone is the availability zone
+	Zone string
+
+	// Nodes is the number of schedulable nodes in the zone
+	Nodes int
+
+	// Allocatable is the total EBS attachment slots the CSI driver reports for those nodes
+	Allocatable int
+
+	// Attached is the number of EBS volumes currently attached to those nodes
+	Attached int
+
+	// WithoutDriver is the number of those nodes whose CSINode does not list
+	// the EBS CSI driver, so they cannot attach EBS volumes
+	WithoutDriver int
+
+	// Unlimited is true when a node in the zone does not report an attachment limit
+	Unlimited bool
+}
+
+// Free returns the attachment slots still available in the zone
+func (z ZoneCapacity) Free() int {
+	if free := z.Allocatable - z.Attached; free > 0 {
+		return free
+	}
+	return 0
+}
+
+// AttachCapacity computes per-zone EBS attachment capacity from the destination
+// cluster's nodes, their CSINode limits and current VolumeAttachments. A node
+// whose CSINode reports no limit falls back to the in-tree
+// attachable-volumes-aws-ebs allocatable.
+func AttachCapacity(nodes []corev1.Node, csiNodes []storagev1.CSINode, attachments []storagev1.VolumeAttachment) map[string]*ZoneCapacity {
+	limits := make(map[string]*int32, len(csiNodes))
+	registered := make(map[string]bool, len(csiNodes))
+	for _, csiNode := range csiNodes {
+		registered[csiNode.Name] = false
+		for _, driver := range csiNode.Spec.Drivers {
+			if driver.Name != EBSCSIDriver {
+				continue
+			}
+			registered[csiNode.Name] = true
+			if driver.Allocatable != nil {
+				limits[csiNode.Name] = driver.Allocatable.Count
+			}
+		}
+	}
+
+	attached := make(map[string]int)
+	for _, va := range attachments {
+		if (va.Spec.Attacher == EBSCSIDriver || va.Spec.Attacher == InTreeEBSAttacher) && va.Status.Attached {
+			attached[va.Spec.NodeName]++
+		}
+	}
+
+	capacity := make(map[string]*ZoneCapacity)
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		zone := node.Labels[corev1.LabelTopologyZone]
+		if capacity[zone] == nil {
+			capacity[zone] = &ZoneCapacity{Zone: zone}
+		}
+		zc := capacity[zone]
+		zc.Nodes++
+		zc.Attached += attached[node.Name]
+		inTree, hasInTree := node.Status.Allocatable[ResourceEBSAttachLimit]
+		switch withDriver, hasCSINode := registered[node.Name]; {
+		case limits[node.Name] != nil:
+			zc.Allocatable += int(*limits[node.Name])
+		case hasInTree:
+			zc.Allocatable += int(inTree.Value())
+		case hasCSINode && !withDriver:
+			zc.WithoutDriver++
+		default:
+			zc.Unlimited = true
+		}
+	}
+
+	return capacity
+}
+
+// VolumeZone returns the availability zone in a PV's node affinity, or "" if it has none
+func VolumeZone(pv *corev1.PersistentVolume) string {
+	return translate.AvailabilityZone(pv)
+}
+
+// VolumesByZone counts volumes per availability zone using each PV's node affinity
+func VolumesByZone(pvs []*corev1.PersistentVolume) map[string]int {
+	counts := make(map[string]int)
+	for _, pv := range pvs {
+		counts[VolumeZone(pv)]++
+	}
+	return counts
+}
+
+// CheckAttachCapacity returns one problem, with remediation advice, for each
+// zone whose destination nodes cannot take the volumes that need to attach there.
+// Volumes with an unknown zone are not checked.
+func CheckAttachCapacity(needed map[string]int, capacity map[string]*ZoneCapacity) []string {
+	zones := make([]string, 0, len(needed))
+	for zone := range needed {
+		if zone != "" {
+			zones = append(zones, zone)
+		}
+	}
+	sort.Strings(zones)
+
+	var problems []string
+	for _, zone := range zones {
+		count := needed[zone]
+		zc := capacity[zone]
+		if zc == nil || zc.Nodes == 0 {
+			problems = append(problems, fmt.Sprintf(
+				"%d volumes are in %s but the destination has no schedulable nodes there; add nodes in %s", count, zone, zone))
+			continue
+		}
+		if zc.Unlimited || count <= zc.Free() {
+			continue
+		}
+		withoutDriver := ""
+		if zc.WithoutDriver > 0 {
+			withoutDriver = fmt.Sprintf(", and %d of them do not run the EBS CSI driver", zc.WithoutDriver)
+		}
+		problems = append(problems, fmt.Sprintf(
+			"%d volumes must attach in %s but its %d destination nodes have %d free EBS attachment slots (%d allocatable, %d in use)%s; add nodes in %s or use instance types that support more EBS attachments",
+			count, zone, zc.Nodes, zc.Free(), zc.Allocatable, zc.Attached, withoutDriver, zone))
+	}
+	return problems
+}
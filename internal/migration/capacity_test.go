@@ -0,0 +1,124 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func zoneNode(name, zone string, unschedulable bool) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelTopologyZone: zone}},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+	}
+}
+
+func zoneAffinity(zone string) *corev1.VolumeNodeAffinity {
+	return &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+		NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+			{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{zone}},
+		}}},
+	}}
+}
+
+func ebsCSINode(name string, limit int32) storagev1.CSINode {
+	return storagev1.CSINode{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: storagev1.CSINodeSpec{Drivers: []storagev1.CSINodeDriver{{
+			Name:        EBSCSIDriver,
+			Allocatable: &storagev1.VolumeNodeResources{Count: &limit},
+		}}},
+	}
+}
+
+func ebsAttachment(node string, attached bool) storagev1.VolumeAttachment {
+	return storagev1.VolumeAttachment{
+		Spec:   storagev1.VolumeAttachmentSpec{Attacher: EBSCSIDriver, NodeName: node},
+		Status: storagev1.VolumeAttachmentStatus{Attached: attached},
+	}
+}
+
+func TestAttachCapacity(t *testing.T) {
+	nodes := []corev1.Node{
+		zoneNode("a1", "us-east-1a", false),
+		zoneNode("a2", "us-east-1a", false),
+		zoneNode("a3", "us-east-1a", true),
+		zoneNode("b1", "us-east-1b", false),
+	}
+	csiNodes := []storagev1.CSINode{ebsCSINode("a1", 25), ebsCSINode("a2", 25), ebsCSINode("a3", 25)}
+	attachments := []storagev1.VolumeAttachment{
+		ebsAttachment("a1", true),
+		ebsAttachment("a1", true),
+		ebsAttachment("a2", false),
+		ebsAttachment("a3", true),
+	}
+
+	capacity := AttachCapacity(nodes, csiNodes, attachments)
+
+	a := capacity["us-east-1a"]
+	if a == nil || a.Nodes != 2 || a.Allocatable != 50 || a.Attached != 2 || a.Free() != 48 || a.Unlimited {
+		t.Errorf("us-east-1a = %+v, want 2 nodes, 50 allocatable, 2 attached", a)
+	}
+	b := capacity["us-east-1b"]
+	if b == nil || b.Nodes != 1 || !b.Unlimited {
+		t.Errorf("us-east-1b = %+v, want 1 node without a reported limit", b)
+	}
+}
+
+func TestAttachCapacityWithoutCSILimits(t *testing.T) {
+	inTree := zoneNode("a1", "us-east-1a", false)
+	inTree.Status.Allocatable = corev1.ResourceList{ResourceEBSAttachLimit: resource.MustParse("39")}
+	nodes := []corev1.Node{inTree, zoneNode("a2", "us-east-1a", false)}
+	csiNodes := []storagev1.CSINode{{ObjectMeta: metav1.ObjectMeta{Name: "a2"}}}
+	attachments := []storagev1.VolumeAttachment{
+		{Spec: storagev1.VolumeAttachmentSpec{Attacher: InTreeEBSAttacher, NodeName: "a1"}, Status: storagev1.VolumeAttachmentStatus{Attached: true}},
+		ebsAttachment("a1", true),
+	}
+
+	a := AttachCapacity(nodes, csiNodes, attachments)["us-east-1a"]
+	if a == nil || a.Nodes != 2 || a.Allocatable != 39 || a.Attached != 2 || a.WithoutDriver != 1 || a.Unlimited {
+		t.Fatalf("us-east-1a = %+v, want the in-tree limit of a1 and a2 without the EBS CSI driver", a)
+	}
+	problems := CheckAttachCapacity(map[string]int{"us-east-1a": 40}, map[string]*ZoneCapacity{"us-east-1a": a})
+	if len(problems) != 1 || !strings.Contains(problems[0], "1 of them do not run the EBS CSI driver") {
+		t.Errorf("CheckAttachCapacity() = %v, want the node without the driver named", problems)
+	}
+}
+
+func TestCheckAttachCapacity(t *testing.T) {
+	capacity := map[string]*ZoneCapacity{
+		"us-east-1a": {Zone: "us-east-1a", Nodes: 4, Allocatable: 100, Attached: 10},
+		"us-east-1b": {Zone: "us-east-1b", Nodes: 1, Unlimited: true},
+		"us-east-1c": {Zone: "us-east-1c"},
+	}
+
+	tests := []struct {
+		name         string
+		needed       map[string]int
+		wantProblems []string
+	}{
+		{name: "fits", needed: map[string]int{"us-east-1a": 90}},
+		{name: "unlimited zone", needed: map[string]int{"us-east-1b": 200}},
+		{name: "unknown zone is skipped", needed: map[string]int{"": 200}},
+		{name: "200 volumes exceed free slots", needed: map[string]int{"us-east-1a": 200}, wantProblems: []string{"90 free EBS attachment slots"}},
+		{name: "zone without nodes", needed: map[string]int{"us-east-1c": 1, "us-west-2a": 1}, wantProblems: []string{"us-east-1c", "us-west-2a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckAttachCapacity(tt.needed, capacity)
+			if len(got) != len(tt.wantProblems) {
+				t.Fatalf("CheckAttachCapacity() = %v, want %d problems", got, len(tt.wantProblems))
+			}
+			for i, want := range tt.wantProblems {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("problem %d = %q, want it to mention %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
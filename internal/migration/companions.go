@@ -0,0 +1,169 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Prometheus Operator kinds are handled as unstructured objects, so the
+// operator is not a build dependency
+var (
+	ServiceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+	PodMonitorGVK     = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PodMonitor"}
+	PrometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+)
+
+// AutoscalerTargets reports whether an HPA scales the named StatefulSet
+func AutoscalerTargets(hpa *autoscalingv2.HorizontalPodAutoscaler, stsName string) bool {
+	ref := hpa.Spec.ScaleTargetRef
+	return ref.Kind == "StatefulSet" && ref.Name == stsName && strings.HasPrefix(ref.APIVersion, "apps/")
+}
+
+// TranslateAutoscaler returns a copy of a source HPA for the destination
+// namespace, scaling the destination StatefulSet of the same name
+func TranslateAutoscaler(src *autoscalingv2.HorizontalPodAutoscaler, destNamespace string) *autoscalingv2.HorizontalPodAutoscaler {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: translateObjectMeta(src.ObjectMeta, destNamespace),
+		Spec:       *src.Spec.DeepCopy(),
+	}
+	hpa.Spec.ScaleTargetRef.APIVersion = "apps/v1"
+	return hpa
+}
+
+// ServicesForPods returns the Services whose selector matches the pod labels
+func ServicesForPods(services []corev1.Service, podLabels map[string]string) []corev1.Service {
+	var matched []corev1.Service
+	for _, svc := range services {
+		if len(svc.Spec.Selector) > 0 && labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(podLabels)) {
+			matched = append(matched, svc)
+		}
+	}
+	return matched
+}
+
+// MonitorSelects reports whether a ServiceMonitor selects one of the
+// Services, or a PodMonitor selects the pod labels. Only monitors in the
+// workload's own namespace are considered, so the namespace selector is
+// not consulted.
+func MonitorSelects(monitor *unstructured.Unstructured, services []corev1.Service, podLabels map[string]string) (bool, error) {
+	raw, found, err := unstructured.NestedMap(monitor.Object, "spec", "selector")
+	if err != nil {
+		return false, fmt.Errorf("%s %s has an invalid selector: %w", monitor.GetKind(), monitor.GetName(), err)
+	}
+	labelSelector := &metav1.LabelSelector{}
+	if found {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, labelSelector); err != nil {
+			return false, fmt.Errorf("%s %s has an invalid selector: %w", monitor.GetKind(), monitor.GetName(), err)
+		}
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return false, fmt.Errorf("%s %s has an invalid selector: %w", monitor.GetKind(), monitor.GetName(), err)
+	}
+
+	if monitor.GroupVersionKind().Kind == PodMonitorGVK.Kind {
+		return selector.Matches(labels.Set(podLabels)), nil
+	}
+	for _, svc := range services {
+		if selector.Matches(labels.Set(svc.Labels)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RuleReferences reports whether any expression in a PrometheusRule
+// mentions the StatefulSet by name. Rules carry no selector, so this is
+// the closest a rule comes to being tied to a workload.
+func RuleReferences(rule *unstructured.Unstructured, stsName string) bool {
+	for _, expr := range ruleExpressions(rule) {
+		if strings.Contains(expr, stsName) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleExpressions returns the expr of every rule in every group
+func ruleExpressions(rule *unstructured.Unstructured) []string {
+	groups, _, _ := unstructured.NestedSlice(rule.Object, "spec", "groups")
+	var exprs []string
+	for _, g := range groups {
+		group, ok := g.(map[string]any)
+		if !ok {
+			continue
+		}
+		rules, _, _ := unstructured.NestedSlice(group, "rules")
+		for _, r := range rules {
+			if entry, ok := r.(map[string]any); ok {
+				if expr, ok := entry["expr"].(string); ok {
+					exprs = append(exprs, expr)
+				}
+			}
+		}
+	}
+	return exprs
+}
+
+// TranslateMonitoringObject returns a copy of a ServiceMonitor, PodMonitor
+// or PrometheusRule for the destination namespace. Namespace selectors and
+// namespace="..." matchers in rule expressions naming the source namespace
+// are pointed at the destination.
+func TranslateMonitoringObject(src *unstructured.Unstructured, sourceNamespace, destNamespace string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{}}
+	obj.SetGroupVersionKind(src.GroupVersionKind())
+	obj.SetName(src.GetName())
+	obj.SetNamespace(destNamespace)
+	obj.SetLabels(copyStringMap(src.GetLabels()))
+	annotations := copyStringMap(src.GetAnnotations())
+	delete(annotations, corev1.LastAppliedConfigAnnotation)
+	obj.SetAnnotations(annotations)
+	if spec, ok := src.Object["spec"].(map[string]any); ok {
+		obj.Object["spec"] = runtime.DeepCopyJSONValue(spec)
+	}
+	if sourceNamespace == destNamespace {
+		return obj
+	}
+
+	if names, found, _ := unstructured.NestedStringSlice(obj.Object, "spec", "namespaceSelector", "matchNames"); found {
+		for i, name := range names {
+			if name == sourceNamespace {
+				names[i] = destNamespace
+			}
+		}
+		_ = unstructured.SetNestedStringSlice(obj.Object, names, "spec", "namespaceSelector", "matchNames")
+	}
+
+	groups, _, _ := unstructured.NestedSlice(obj.Object, "spec", "groups")
+	replacer := strings.NewReplacer(
+		fmt.Sprintf(`namespace="%s"`, sourceNamespace), fmt.Sprintf(`namespace="%s"`, destNamespace),
+		fmt.Sprintf(`namespace='%s'`, sourceNamespace), fmt.Sprintf(`namespace='%s'`, destNamespace),
+	)
+	for _, g := range groups {
+		group, ok := g.(map[string]any)
+		if !ok {
+			continue
+		}
+		rules, _, _ := unstructured.NestedSlice(group, "rules")
+		for _, r := range rules {
+			if entry, ok := r.(map[string]any); ok {
+				if expr, ok := entry["expr"].(string); ok {
+					entry["expr"] = replacer.Replace(expr)
+				}
+			}
+		}
+		group["rules"] = rules
+	}
+	if groups != nil {
+		_ = unstructured.SetNestedSlice(obj.Object, groups, "spec", "groups")
+	}
+	return obj
+}
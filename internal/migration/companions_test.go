@@ -0,0 +1,148 @@
+package migration
+
+import (
+	"testing"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestAutoscalerTargets(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  autoscalingv2.CrossVersionObjectReference
+		want bool
+	}{
+		{name: "the StatefulSet", ref: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "web"}, want: true},
+		{name: "another StatefulSet", ref: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db"}},
+		{name: "a Deployment of the same name", ref: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}},
+	}
+	for _, tt := range tests {
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{Spec: autoscalingv2.HorizontalPodAutoscalerSpec{ScaleTargetRef: tt.ref}}
+		if got := AutoscalerTargets(hpa, "web"); got != tt.want {
+			t.Errorf("%s: AutoscalerTargets() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateAutoscaler(t *testing.T) {
+	minReplicas := int32(2)
+	src := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "old", Name: "web", ResourceVersion: "7", UID: "abc"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1beta1", Kind: "StatefulSet", Name: "web"},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    6,
+		},
+	}
+
+	hpa := TranslateAutoscaler(src, "new")
+	if hpa.Namespace != "new" || hpa.ResourceVersion != "" || hpa.UID != "" {
+		t.Errorf("metadata = %+v, want a fresh object in new", hpa.ObjectMeta)
+	}
+	if hpa.Spec.ScaleTargetRef.APIVersion != "apps/v1" || hpa.Spec.ScaleTargetRef.Name != "web" {
+		t.Errorf("scaleTargetRef = %+v, want apps/v1 StatefulSet web", hpa.Spec.ScaleTargetRef)
+	}
+	if *hpa.Spec.MinReplicas != 2 || hpa.Spec.MaxReplicas != 6 {
+		t.Errorf("replicas = %d..%d, want 2..6", *hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas)
+	}
+}
+
+func monitor(kind string, selector map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{}}}
+	obj.SetAPIVersion("monitoring.coreos.com/v1")
+	obj.SetKind(kind)
+	obj.SetName("web")
+	if selector != nil {
+		obj.Object["spec"].(map[string]any)["selector"] = selector
+	}
+	return obj
+}
+
+func TestMonitorSelects(t *testing.T) {
+	podLabels := map[string]string{"app": "web", "tier": "db"}
+	services := ServicesForPods([]corev1.Service{
+		{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"app": "web"}},
+			Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "web"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "other", Labels: map[string]string{"app": "other"}},
+			Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "other"}}},
+	}, podLabels)
+	if len(services) != 1 || services[0].Name != "web" {
+		t.Fatalf("ServicesForPods() = %v, want only web", services)
+	}
+
+	tests := []struct {
+		name    string
+		monitor *unstructured.Unstructured
+		want    bool
+	}{
+		{name: "ServiceMonitor selecting the Service",
+			monitor: monitor("ServiceMonitor", map[string]any{"matchLabels": map[string]any{"app": "web"}}), want: true},
+		{name: "ServiceMonitor selecting another Service",
+			monitor: monitor("ServiceMonitor", map[string]any{"matchLabels": map[string]any{"app": "other"}})},
+		{name: "PodMonitor selecting the pods",
+			monitor: monitor("PodMonitor", map[string]any{"matchExpressions": []any{
+				map[string]any{"key": "tier", "operator": "In", "values": []any{"db"}},
+			}}), want: true},
+		{name: "PodMonitor selecting other pods",
+			monitor: monitor("PodMonitor", map[string]any{"matchLabels": map[string]any{"app": "other"}})},
+		{name: "empty selector selects everything", monitor: monitor("PodMonitor", nil), want: true},
+	}
+	for _, tt := range tests {
+		got, err := MonitorSelects(tt.monitor, services, podLabels)
+		if err != nil {
+			t.Fatalf("%s: MonitorSelects() error = %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: MonitorSelects() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func prometheusRule(exprs ...string) *unstructured.Unstructured {
+	var rules []any
+	for _, expr := range exprs {
+		rules = append(rules, map[string]any{"alert": "Down", "expr": expr})
+	}
+	rule := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"groups": []any{map[string]any{"name": "web", "rules": rules}}},
+	}}
+	rule.SetAPIVersion("monitoring.coreos.com/v1")
+	rule.SetKind("PrometheusRule")
+	rule.SetNamespace("old")
+	rule.SetName("web-alerts")
+	rule.SetResourceVersion("12")
+	return rule
+}
+
+func TestRuleReferences(t *testing.T) {
+	if !RuleReferences(prometheusRule(`up{job="other"} == 0`, `kube_statefulset_status_replicas_ready{statefulset="web"} < 3`), "web") {
+		t.Error("RuleReferences() = false for a rule naming the StatefulSet")
+	}
+	if RuleReferences(prometheusRule(`up{job="other"} == 0`), "web") {
+		t.Error("RuleReferences() = true for a rule that does not name the StatefulSet")
+	}
+}
+
+func TestTranslateMonitoringObject(t *testing.T) {
+	rule := TranslateMonitoringObject(prometheusRule(`up{namespace="old",job="web"} == 0`), "old", "new")
+	if rule.GetNamespace() != "new" || rule.GetResourceVersion() != "" || rule.GetKind() != "PrometheusRule" {
+		t.Errorf("rule metadata = %v, want a fresh PrometheusRule in new", rule.Object["metadata"])
+	}
+	if exprs := ruleExpressions(rule); len(exprs) != 1 || exprs[0] != `up{namespace="new",job="web"} == 0` {
+		t.Errorf("rule expressions = %v, want the namespace matcher rewritten", exprs)
+	}
+
+	sm := monitor("ServiceMonitor", map[string]any{"matchLabels": map[string]any{"app": "web"}})
+	sm.Object["spec"].(map[string]any)["namespaceSelector"] = map[string]any{"matchNames": []any{"old", "shared"}}
+	translated := TranslateMonitoringObject(sm, "old", "new")
+	names, _, _ := unstructured.NestedStringSlice(translated.Object, "spec", "namespaceSelector", "matchNames")
+	if len(names) != 2 || names[0] != "new" || names[1] != "shared" {
+		t.Errorf("matchNames = %v, want [new shared]", names)
+	}
+	if orig, _, _ := unstructured.NestedStringSlice(sm.Object, "spec", "namespaceSelector", "matchNames"); orig[0] != "old" {
+		t.Errorf("source matchNames = %v, want it unchanged", orig)
+	}
+}
@@ -0,0 +1,188 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// Difference is a field that differs between a source object and its
+// migrated counterpart in the destination
+type Difference struct {
+	// Field is the path of the field, e.g. "capacity" or "template.containers[db].image"
+	Field string
+
+	// Source and Dest are the field's values, rendered for display
+	Source string
+	Dest   string
+}
+
+// DiffOptions describes the changes a migration makes on purpose, which are
+// not reported as differences
+type DiffOptions struct {
+	// StorageClassMapping is the migration's spec.storageClassMapping
+	StorageClassMapping map[string]string
+}
+
+// differ collects differences between two objects
+type differ struct {
+	diffs []Difference
+}
+
+// compare records a difference when the two values are not semantically equal
+func (d *differ) compare(field string, source, dest any) {
+	if equality.Semantic.DeepEqual(source, dest) {
+		return
+	}
+	d.diffs = append(d.diffs, Difference{Field: field, Source: renderValue(source), Dest: renderValue(dest)})
+}
+
+// renderValue formats a field value compactly: strings as they are, anything else as JSON
+func renderValue(v any) string {
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+		if rv.String() == "" {
+			return "<none>"
+		}
+		return rv.String()
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return "<none>"
+	}
+	return string(data)
+}
+
+// DiffPVs compares a source PV with the PV migrated from it: capacity, access
+// modes, volume mode, StorageClass (after mapping), EBS volume, zone and
+// filesystem type
+func DiffPVs(source, dest *corev1.PersistentVolume, opts DiffOptions) []Difference {
+	d := &differ{}
+	d.compare("capacity", source.Spec.Capacity.Storage(), dest.Spec.Capacity.Storage())
+	d.compare("accessModes", source.Spec.AccessModes, dest.Spec.AccessModes)
+	d.compare("volumeMode", volumeModeOrDefault(source.Spec.VolumeMode), volumeModeOrDefault(dest.Spec.VolumeMode))
+	d.compare("storageClassName", translate.DestStorageClass(source.Spec.StorageClassName, opts.StorageClassMapping), dest.Spec.StorageClassName)
+
+	sourceID, _ := translate.EBSVolumeID(source)
+	destID, _ := translate.EBSVolumeID(dest)
+	d.compare("volumeHandle", sourceID, destID)
+	d.compare("zone", translate.AvailabilityZone(source), translate.AvailabilityZone(dest))
+	d.compare("fsType", VolumeFSType(source), VolumeFSType(dest))
+	return d.diffs
+}
+
+// DiffPVCs compares a source PVC with its migrated counterpart: requested
+// storage, access modes, volume mode and StorageClass (after mapping)
+func DiffPVCs(source, dest *corev1.PersistentVolumeClaim, opts DiffOptions) []Difference {
+	d := &differ{}
+	d.compare("requests.storage", source.Spec.Resources.Requests.Storage(), dest.Spec.Resources.Requests.Storage())
+	d.compare("accessModes", source.Spec.AccessModes, dest.Spec.AccessModes)
+	d.compare("volumeMode", volumeModeOrDefault(source.Spec.VolumeMode), volumeModeOrDefault(dest.Spec.VolumeMode))
+	d.compare("storageClassName", translate.DestStorageClass(stringValue(source.Spec.StorageClassName), opts.StorageClassMapping),
+		stringValue(dest.Spec.StorageClassName))
+	return d.diffs
+}
+
+// DiffStatefulSets compares a source StatefulSet with its migrated
+// counterpart: replicas, service, policies, volume claim templates and the
+// pod template's scheduling, identity and containers
+func DiffStatefulSets(source, dest *appsv1.StatefulSet, opts DiffOptions) []Difference {
+	d := &differ{}
+	d.compare("replicas", source.Spec.Replicas, dest.Spec.Replicas)
+	d.compare("serviceName", source.Spec.ServiceName, dest.Spec.ServiceName)
+	d.compare("podManagementPolicy", source.Spec.PodManagementPolicy, dest.Spec.PodManagementPolicy)
+	d.compare("updateStrategy", source.Spec.UpdateStrategy, dest.Spec.UpdateStrategy)
+	d.compare("selector", source.Spec.Selector, dest.Spec.Selector)
+
+	destTemplates := make(map[string]corev1.PersistentVolumeClaim)
+	for _, vct := range dest.Spec.VolumeClaimTemplates {
+		destTemplates[vct.Name] = vct
+	}
+	for _, vct := range source.Spec.VolumeClaimTemplates {
+		field := fmt.Sprintf("volumeClaimTemplates[%s]", vct.Name)
+		destVCT, ok := destTemplates[vct.Name]
+		if !ok {
+			d.compare(field, vct.Name, "")
+			continue
+		}
+		for _, diff := range DiffPVCs(&vct, &destVCT, opts) {
+			diff.Field = field + "." + diff.Field
+			d.diffs = append(d.diffs, diff)
+		}
+		delete(destTemplates, vct.Name)
+	}
+	for _, name := range sortedKeys(destTemplates) {
+		d.compare(fmt.Sprintf("volumeClaimTemplates[%s]", name), "", name)
+	}
+
+	src, dst := source.Spec.Template, dest.Spec.Template
+	d.compare("template.labels", src.Labels, dst.Labels)
+	d.compare("template.serviceAccountName", src.Spec.ServiceAccountName, dst.Spec.ServiceAccountName)
+	d.compare("template.nodeSelector", src.Spec.NodeSelector, dst.Spec.NodeSelector)
+	d.compare("template.affinity", src.Spec.Affinity, dst.Spec.Affinity)
+	d.compare("template.tolerations", src.Spec.Tolerations, dst.Spec.Tolerations)
+	d.compare("template.securityContext", src.Spec.SecurityContext, dst.Spec.SecurityContext)
+	d.compare("template.volumes", src.Spec.Volumes, dst.Spec.Volumes)
+	diffContainers(d, "template.initContainers", src.Spec.InitContainers, dst.Spec.InitContainers)
+	diffContainers(d, "template.containers", src.Spec.Containers, dst.Spec.Containers)
+	return d.diffs
+}
+
+// diffContainers compares containers by name
+func diffContainers(d *differ, field string, source, dest []corev1.Container) {
+	destByName := make(map[string]corev1.Container)
+	for _, c := range dest {
+		destByName[c.Name] = c
+	}
+	for _, c := range source {
+		f := fmt.Sprintf("%s[%s]", field, c.Name)
+		destC, ok := destByName[c.Name]
+		if !ok {
+			d.compare(f, c.Name, "")
+			continue
+		}
+		d.compare(f+".image", c.Image, destC.Image)
+		d.compare(f+".command", c.Command, destC.Command)
+		d.compare(f+".args", c.Args, destC.Args)
+		d.compare(f+".env", c.Env, destC.Env)
+		d.compare(f+".resources", c.Resources, destC.Resources)
+		d.compare(f+".volumeMounts", c.VolumeMounts, destC.VolumeMounts)
+		delete(destByName, c.Name)
+	}
+	for _, name := range sortedKeys(destByName) {
+		d.compare(fmt.Sprintf("%s[%s]", field, name), "", name)
+	}
+}
+
+// volumeModeOrDefault returns the volume mode, which defaults to Filesystem
+func volumeModeOrDefault(mode *corev1.PersistentVolumeMode) corev1.PersistentVolumeMode {
+	if mode == nil {
+		return corev1.PersistentVolumeFilesystem
+	}
+	return *mode
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
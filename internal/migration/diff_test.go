@@ -0,0 +1,119 @@
+package migration
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDiffPVs(t *testing.T) {
+	pv := func(size, class, volumeID, zone string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{
+			Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: class,
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: EBSCSIDriver, VolumeHandle: volumeID, FSType: "ext4"},
+			},
+			NodeAffinity: zoneAffinity(zone),
+		}}
+	}
+	source := pv("100Gi", "gp2", "vol-1", "us-east-1a")
+
+	tests := []struct {
+		name string
+		dest *corev1.PersistentVolume
+		opts DiffOptions
+		want []Difference
+	}{
+		{name: "identical", dest: pv("100Gi", "gp2", "vol-1", "us-east-1a")},
+		{name: "equal quantities in other units", dest: pv("102400Mi", "gp2", "vol-1", "us-east-1a")},
+		{name: "mapped storage class", dest: pv("100Gi", "gp3", "vol-1", "us-east-1a"), opts: DiffOptions{StorageClassMapping: map[string]string{"gp2": "gp3"}}},
+		{
+			name: "unmapped storage class",
+			dest: pv("100Gi", "gp3", "vol-1", "us-east-1a"),
+			want: []Difference{{Field: "storageClassName", Source: "gp2", Dest: "gp3"}},
+		},
+		{
+			name: "drifted",
+			dest: pv("50Gi", "gp2", "vol-2", "us-east-1b"),
+			want: []Difference{
+				{Field: "capacity", Source: "100Gi", Dest: "50Gi"},
+				{Field: "volumeHandle", Source: "vol-1", Dest: "vol-2"},
+				{Field: "zone", Source: "us-east-1a", Dest: "us-east-1b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffPVs(source, tt.dest, tt.opts)
+			if len(got) != len(tt.want) {
+				t.Fatalf("DiffPVs() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("difference %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDiffStatefulSets(t *testing.T) {
+	sts := func(image, memory string, replicas int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: "db",
+			Selector:    &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "db"}},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:  "db",
+					Image: image,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)},
+					},
+				}}},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+					},
+				},
+			}},
+		}}
+	}
+	source := sts("postgres:16", "1Gi", 3)
+
+	if got := DiffStatefulSets(source, sts("postgres:16", "1024Mi", 3), DiffOptions{}); len(got) != 0 {
+		t.Errorf("DiffStatefulSets() of equivalent StatefulSets = %+v, want none", got)
+	}
+
+	dest := sts("postgres:17", "1Gi", 2)
+	dest.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("20Gi")
+	dest.Spec.Template.Spec.Containers = append(dest.Spec.Template.Spec.Containers, corev1.Container{Name: "exporter"})
+	want := []string{
+		"replicas",
+		"volumeClaimTemplates[data].requests.storage",
+		"template.containers[db].image",
+		"template.containers[exporter]",
+	}
+	got := DiffStatefulSets(source, dest, DiffOptions{})
+	if len(got) != len(want) {
+		t.Fatalf("DiffStatefulSets() = %+v, want fields %v", got, want)
+	}
+	for i, field := range want {
+		if got[i].Field != field {
+			t.Errorf("difference %d = %+v, want field %s", i, got[i], field)
+		}
+	}
+	if got[0].Source != "3" || got[0].Dest != "2" {
+		t.Errorf("replicas difference = %+v, want 3 and 2", got[0])
+	}
+}
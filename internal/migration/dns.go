@@ -0,0 +1,103 @@
+package migration
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DNSEndpointGVK is external-dns's CRD source kind. It is handled as an
+// unstructured object, so external-dns is not a build dependency.
+var DNSEndpointGVK = schema.GroupVersionKind{Group: "externaldns.k8s.io", Version: "v1alpha1", Kind: "DNSEndpoint"}
+
+// AnnotationExternalDNSHostname on a headless service has external-dns
+// publish a record per pod, <pod>.<hostname>, for each of its hostnames
+const AnnotationExternalDNSHostname = "external-dns.alpha.kubernetes.io/hostname"
+
+// DNSHostnames returns the hostnames of a comma-separated list such as the
+// external-dns hostname annotation, without trailing dots
+func DNSHostnames(list string) []string {
+	var hostnames []string
+	for _, hostname := range strings.Split(list, ",") {
+		if hostname = strings.TrimSuffix(strings.TrimSpace(hostname), "."); hostname != "" {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return hostnames
+}
+
+// ServiceDNSHostnames returns the hostnames external-dns publishes the pods
+// of a headless service under
+func ServiceDNSHostnames(svc *corev1.Service) []string {
+	return DNSHostnames(svc.Annotations[AnnotationExternalDNSHostname])
+}
+
+// PodDNSEndpoints returns the DNSEndpoint endpoints of a pod's records under
+// each hostname: an A record for its IPv4 addresses and an AAAA record for
+// its IPv6 ones, as external-dns publishes for a headless service
+func PodDNSEndpoints(pod *corev1.Pod, hostnames []string, ttl int64) []any {
+	var v4, v6 []any
+	ips := pod.Status.PodIPs
+	if len(ips) == 0 && pod.Status.PodIP != "" {
+		ips = []corev1.PodIP{{IP: pod.Status.PodIP}}
+	}
+	for _, ip := range ips {
+		addr, err := netip.ParseAddr(ip.IP)
+		switch {
+		case err != nil:
+			continue
+		case addr.Is4():
+			v4 = append(v4, addr.String())
+		default:
+			v6 = append(v6, addr.String())
+		}
+	}
+
+	var endpoints []any
+	for _, hostname := range hostnames {
+		for _, record := range []struct {
+			recordType string
+			targets    []any
+		}{{"A", v4}, {"AAAA", v6}} {
+			if len(record.targets) == 0 {
+				continue
+			}
+			endpoint := map[string]any{
+				"dnsName":    pod.Name + "." + hostname,
+				"recordType": record.recordType,
+				"targets":    record.targets,
+			}
+			if ttl > 0 {
+				endpoint["recordTTL"] = ttl
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// SetPodDNSEndpoints replaces a pod's records under hostnames in a
+// DNSEndpoint's spec.endpoints with endpoints, leaving the other pods' alone
+func SetPodDNSEndpoints(obj *unstructured.Unstructured, podName string, hostnames []string, endpoints []any) error {
+	existing, _, err := unstructured.NestedSlice(obj.Object, "spec", "endpoints")
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(hostnames))
+	for i, hostname := range hostnames {
+		names[i] = podName + "." + hostname
+	}
+	existing = slices.DeleteFunc(existing, func(e any) bool {
+		endpoint, ok := e.(map[string]any)
+		if !ok {
+			return false
+		}
+		name, _ := endpoint["dnsName"].(string)
+		return slices.Contains(names, name)
+	})
+	return unstructured.SetNestedSlice(obj.Object, append(existing, endpoints...), "spec", "endpoints")
+}
@@ -0,0 +1,76 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDNSHostnames(t *testing.T) {
+	got := DNSHostnames(" db.example.com., db.internal ,,")
+	if want := []string{"db.example.com", "db.internal"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DNSHostnames() = %v, want %v", got, want)
+	}
+	if got := DNSHostnames(""); got != nil {
+		t.Errorf("DNSHostnames(\"\") = %v, want nil", got)
+	}
+}
+
+func TestPodDNSEndpoints(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0"},
+		Status:     corev1.PodStatus{PodIPs: []corev1.PodIP{{IP: "10.0.1.5"}, {IP: "fd00::5"}}},
+	}
+	got := PodDNSEndpoints(pod, []string{"db.example.com"}, 30)
+	want := []any{
+		map[string]any{"dnsName": "web-0.db.example.com", "recordType": "A", "targets": []any{"10.0.1.5"}, "recordTTL": int64(30)},
+		map[string]any{"dnsName": "web-0.db.example.com", "recordType": "AAAA", "targets": []any{"fd00::5"}, "recordTTL": int64(30)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PodDNSEndpoints() = %v, want %v", got, want)
+	}
+
+	// A pod that only reports status.podIP, and no TTL
+	pod.Status = corev1.PodStatus{PodIP: "10.0.1.6"}
+	got = PodDNSEndpoints(pod, []string{"a.example.com", "b.example.com"}, 0)
+	want = []any{
+		map[string]any{"dnsName": "web-0.a.example.com", "recordType": "A", "targets": []any{"10.0.1.6"}},
+		map[string]any{"dnsName": "web-0.b.example.com", "recordType": "A", "targets": []any{"10.0.1.6"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PodDNSEndpoints() = %v, want %v", got, want)
+	}
+
+	pod.Status = corev1.PodStatus{}
+	if got := PodDNSEndpoints(pod, []string{"db.example.com"}, 0); len(got) != 0 {
+		t.Errorf("PodDNSEndpoints() without an IP = %v, want none", got)
+	}
+}
+
+func TestSetPodDNSEndpoints(t *testing.T) {
+	endpoint := func(name, ip string) any {
+		return map[string]any{"dnsName": name, "recordType": "A", "targets": []any{ip}}
+	}
+	obj := &unstructured.Unstructured{Object: map[string]any{}}
+	hostnames := []string{"db.example.com"}
+
+	if err := SetPodDNSEndpoints(obj, "web-0", hostnames, []any{endpoint("web-0.db.example.com", "10.0.1.5")}); err != nil {
+		t.Fatalf("SetPodDNSEndpoints() error = %v", err)
+	}
+	if err := SetPodDNSEndpoints(obj, "web-1", hostnames, []any{endpoint("web-1.db.example.com", "10.0.1.6")}); err != nil {
+		t.Fatalf("SetPodDNSEndpoints() error = %v", err)
+	}
+	// web-0 moved again, e.g. after a retry
+	if err := SetPodDNSEndpoints(obj, "web-0", hostnames, []any{endpoint("web-0.db.example.com", "10.0.1.7")}); err != nil {
+		t.Fatalf("SetPodDNSEndpoints() error = %v", err)
+	}
+
+	got, _, _ := unstructured.NestedSlice(obj.Object, "spec", "endpoints")
+	want := []any{endpoint("web-1.db.example.com", "10.0.1.6"), endpoint("web-0.db.example.com", "10.0.1.7")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("spec.endpoints = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,42 @@
+package migration
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// FindPVsForVolume returns the PVs that reference the given EBS volume ID,
+// through either the EBS CSI driver or the in-tree AWS EBS source
+func FindPVsForVolume(pvs []corev1.PersistentVolume, volumeID string) []corev1.PersistentVolume {
+	var matches []corev1.PersistentVolume
+	for _, pv := range pvs {
+		id, err := translate.EBSVolumeID(&pv)
+		if err != nil {
+			continue
+		}
+		if id == volumeID {
+			matches = append(matches, pv)
+		}
+	}
+	return matches
+}
+
+// CheckAdoptablePV verifies that an existing destination PV for a migrated
+// volume can be reused for the given destination PVC. A PV is adoptable when
+// it is unclaimed or already claimed by (a previous incarnation of) the same PVC.
+func CheckAdoptablePV(pv *corev1.PersistentVolume, destNamespace, destPVCName string) error {
+	ref := pv.Spec.ClaimRef
+	if ref == nil {
+		if pv.Status.Phase != "" && pv.Status.Phase != corev1.VolumeAvailable {
+			return fmt.Errorf("PV %s is unclaimed but in phase %s", pv.Name, pv.Status.Phase)
+		}
+		return nil
+	}
+	if ref.Namespace != destNamespace || ref.Name != destPVCName {
+		return fmt.Errorf("PV %s already references the volume and is claimed by %s/%s", pv.Name, ref.Namespace, ref.Name)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package migration
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func csiPV(name, volumeID string) corev1.PersistentVolume {
+	return corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{
+					Driver:       "ebs.csi.aws.com",
+					VolumeHandle: volumeID,
+				},
+			},
+		},
+	}
+}
+
+func TestFindPVsForVolume(t *testing.T) {
+	legacy := corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				AWSElasticBlockStore: &corev1.AWSElasticBlockStoreVolumeSource{
+					VolumeID: "aws://us-east-1a/vol-abc",
+				},
+			},
+		},
+	}
+	nfs := corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "nfs"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				NFS: &corev1.NFSVolumeSource{Server: "nfs", Path: "/"},
+			},
+		},
+	}
+	pvs := []corev1.PersistentVolume{csiPV("csi", "vol-abc"), csiPV("other", "vol-def"), legacy, nfs}
+
+	tests := []struct {
+		name     string
+		volumeID string
+		want     []string
+	}{
+		{name: "CSI and legacy matches", volumeID: "vol-abc", want: []string{"csi", "legacy"}},
+		{name: "single match", volumeID: "vol-def", want: []string{"other"}},
+		{name: "no match", volumeID: "vol-missing", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindPVsForVolume(pvs, tt.volumeID)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FindPVsForVolume() returned %d PVs, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].Name != tt.want[i] {
+					t.Errorf("FindPVsForVolume()[%d] = %s, want %s", i, got[i].Name, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckAdoptablePV(t *testing.T) {
+	tests := []struct {
+		name     string
+		claimRef *corev1.ObjectReference
+		phase    corev1.PersistentVolumePhase
+		wantErr  bool
+	}{
+		{name: "unclaimed and available", phase: corev1.VolumeAvailable, wantErr: false},
+		{name: "unclaimed but failed", phase: corev1.VolumeFailed, wantErr: true},
+		{name: "claimed by target PVC", claimRef: &corev1.ObjectReference{Namespace: "prod", Name: "data-web-0"}, phase: corev1.VolumeReleased, wantErr: false},
+		{name: "claimed by another PVC", claimRef: &corev1.ObjectReference{Namespace: "prod", Name: "data-db-0"}, phase: corev1.VolumeBound, wantErr: true},
+		{name: "claimed in another namespace", claimRef: &corev1.ObjectReference{Namespace: "staging", Name: "data-web-0"}, phase: corev1.VolumeBound, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := csiPV("pv", "vol-abc")
+			pv.Spec.ClaimRef = tt.claimRef
+			pv.Status.Phase = tt.phase
+
+			err := CheckAdoptablePV(&pv, "prod", "data-web-0")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckAdoptablePV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
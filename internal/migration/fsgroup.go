@@ -0,0 +1,54 @@
+package migration
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// FSGroupPolicy returns a CSI driver's fsGroupPolicy. A driver without a
+// CSIDriver object, or one that leaves the field unset, gets the kubelet's
+// default, ReadWriteOnceWithFSType.
+func FSGroupPolicy(driver *storagev1.CSIDriver) storagev1.FSGroupPolicy {
+	if driver == nil || driver.Spec.FSGroupPolicy == nil {
+		return storagev1.ReadWriteOnceWithFSTypeFSGroupPolicy
+	}
+	return *driver.Spec.FSGroupPolicy
+}
+
+// FSGroupApplies reports whether the kubelet changes the ownership of a
+// volume's files to the pod's fsGroup when it mounts the volume, under a CSI
+// driver's fsGroupPolicy. File always does, None never does, and
+// ReadWriteOnceWithFSType only does for a ReadWriteOnce filesystem volume
+// with an fsType. Block volumes have no files to change.
+func FSGroupApplies(policy storagev1.FSGroupPolicy, pv *corev1.PersistentVolume) bool {
+	if pv.Spec.VolumeMode != nil && *pv.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		return false
+	}
+	switch policy {
+	case storagev1.FileFSGroupPolicy:
+		return true
+	case storagev1.NoneFSGroupPolicy:
+		return false
+	}
+	var fsType string
+	switch {
+	case pv.Spec.CSI != nil:
+		fsType = pv.Spec.CSI.FSType
+	case pv.Spec.AWSElasticBlockStore != nil:
+		fsType = pv.Spec.AWSElasticBlockStore.FSType
+	}
+	return fsType != "" && len(pv.Spec.AccessModes) == 1 && pv.Spec.AccessModes[0] == corev1.ReadWriteOnce
+}
+
+// FSGroupChowns reports whether the first mount of a volume in the
+// destination recursively changes the ownership of every file on it, which
+// the source never did: the pod sets an fsGroup, and the destination driver's
+// fsGroupPolicy applies it where the source's did not. Where the source
+// applied it too, the files already belong to the fsGroup, and the change
+// costs no more than it did in the source.
+func FSGroupChowns(spec *corev1.PodSpec, pv *corev1.PersistentVolume, sourcePolicy, destPolicy storagev1.FSGroupPolicy) bool {
+	if spec.SecurityContext == nil || spec.SecurityContext.FSGroup == nil {
+		return false
+	}
+	return FSGroupApplies(destPolicy, pv) && !FSGroupApplies(sourcePolicy, pv)
+}
@@ -0,0 +1,59 @@
+package migration
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestFSGroupChowns(t *testing.T) {
+	pv := func(fsType string, mode corev1.PersistentVolumeMode, access ...corev1.PersistentVolumeAccessMode) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{
+			AccessModes: access,
+			VolumeMode:  &mode,
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: EBSCSIDriver, VolumeHandle: "vol-0123", FSType: fsType},
+			},
+		}}
+	}
+	rwo := pv("ext4", corev1.PersistentVolumeFilesystem, corev1.ReadWriteOnce)
+	withFSGroup := &corev1.PodSpec{SecurityContext: &corev1.PodSecurityContext{FSGroup: ptr.To[int64](1000)}}
+
+	tests := []struct {
+		name         string
+		spec         *corev1.PodSpec
+		pv           *corev1.PersistentVolume
+		source, dest storagev1.FSGroupPolicy
+		want         bool
+	}{
+		{name: "no fsGroup", spec: &corev1.PodSpec{}, pv: rwo, source: storagev1.NoneFSGroupPolicy, dest: storagev1.FileFSGroupPolicy},
+		{name: "same policy", spec: withFSGroup, pv: rwo, source: storagev1.ReadWriteOnceWithFSTypeFSGroupPolicy, dest: storagev1.ReadWriteOnceWithFSTypeFSGroupPolicy},
+		{name: "none to default", spec: withFSGroup, pv: rwo, source: storagev1.NoneFSGroupPolicy, dest: storagev1.ReadWriteOnceWithFSTypeFSGroupPolicy, want: true},
+		{name: "none to file", spec: withFSGroup, pv: rwo, source: storagev1.NoneFSGroupPolicy, dest: storagev1.FileFSGroupPolicy, want: true},
+		{name: "default to file without fsType", spec: withFSGroup, pv: pv("", corev1.PersistentVolumeFilesystem, corev1.ReadWriteOnce),
+			source: storagev1.ReadWriteOnceWithFSTypeFSGroupPolicy, dest: storagev1.FileFSGroupPolicy, want: true},
+		{name: "default to file", spec: withFSGroup, pv: rwo, source: storagev1.ReadWriteOnceWithFSTypeFSGroupPolicy, dest: storagev1.FileFSGroupPolicy},
+		{name: "file to none", spec: withFSGroup, pv: rwo, source: storagev1.FileFSGroupPolicy, dest: storagev1.NoneFSGroupPolicy},
+		{name: "block volume", spec: withFSGroup, pv: pv("", corev1.PersistentVolumeBlock, corev1.ReadWriteOnce),
+			source: storagev1.NoneFSGroupPolicy, dest: storagev1.FileFSGroupPolicy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FSGroupChowns(tt.spec, tt.pv, tt.source, tt.dest); got != tt.want {
+				t.Errorf("FSGroupChowns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFSGroupPolicy(t *testing.T) {
+	if got := FSGroupPolicy(nil); got != storagev1.ReadWriteOnceWithFSTypeFSGroupPolicy {
+		t.Errorf("FSGroupPolicy(nil) = %s, want ReadWriteOnceWithFSType", got)
+	}
+	driver := &storagev1.CSIDriver{Spec: storagev1.CSIDriverSpec{FSGroupPolicy: ptr.To(storagev1.FileFSGroupPolicy)}}
+	if got := FSGroupPolicy(driver); got != storagev1.FileFSGroupPolicy {
+		t.Errorf("FSGroupPolicy() = %s, want File", got)
+	}
+}
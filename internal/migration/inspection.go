@@ -0,0 +1,102 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// LabelVolumeInspection marks a pod that inspects a migrated volume,
+	// with the name of the PVC it mounts
+	LabelVolumeInspection = "migration.aqua.io/volume-inspection"
+
+	// InspectionMountPath is where an inspection pod mounts the volume
+	InspectionMountPath = "/data"
+
+	// DefaultInspectionImage runs the inspection command unless another image is given
+	DefaultInspectionImage = "busybox:1.36"
+)
+
+// InspectionPodName returns the name of the pod that inspects a PVC
+func InspectionPodName(pvcName string) string {
+	return "inspect-" + pvcName
+}
+
+// InspectionPod returns a pod that mounts a PVC read-only at /data and runs
+// command once. Its termination message falls back to the tail of its log
+// when the command fails, so the reason can be read from the pod's status
+// without access to its logs.
+func InspectionPod(namespace, pvcName, image string, command []string, nodeSelector map[string]string) *corev1.Pod {
+	if image == "" {
+		image = DefaultInspectionImage
+	}
+	labelValue := pvcName
+	if len(labelValue) > 63 {
+		labelValue = strings.TrimRight(labelValue[:63], "-.")
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      InspectionPodName(pvcName),
+			Namespace: namespace,
+			Labels:    map[string]string{LabelVolumeInspection: labelValue},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			NodeSelector:                  copyStringMap(nodeSelector),
+			TerminationGracePeriodSeconds: ptr.To(int64(0)),
+			Containers: []corev1.Container{{
+				Name:                     "inspect",
+				Image:                    image,
+				Command:                  append([]string(nil), command...),
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				VolumeMounts:             []corev1.VolumeMount{{Name: "data", MountPath: InspectionMountPath, ReadOnly: true}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcName, ReadOnly: true},
+				},
+			}},
+		},
+	}
+}
+
+// InspectionResult reports whether an inspection pod has finished, and the
+// reason it failed if it did not succeed. The message is the container's
+// termination message, if any.
+func InspectionResult(pod *corev1.Pod) (done bool, message string, err error) {
+	return commandResult(pod, "inspection")
+}
+
+// commandResult reports whether a pod that runs a command once has
+// finished, naming it kind in the reason it failed
+func commandResult(pod *corev1.Pod, kind string) (done bool, message string, err error) {
+	var terminated *corev1.ContainerStateTerminated
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil {
+			terminated = status.State.Terminated
+		}
+	}
+	if terminated != nil {
+		message = strings.TrimSpace(terminated.Message)
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return true, message, nil
+	case corev1.PodFailed:
+		if terminated == nil {
+			return true, message, fmt.Errorf("%s pod %s failed: %s", kind, pod.Name, pod.Status.Reason)
+		}
+		err := fmt.Errorf("%s command exited with code %d", kind, terminated.ExitCode)
+		if message != "" {
+			err = fmt.Errorf("%s command exited with code %d: %s", kind, terminated.ExitCode, message)
+		}
+		return true, message, err
+	}
+	return false, "", nil
+}
@@ -0,0 +1,67 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestInspectionPod(t *testing.T) {
+	pod := InspectionPod("new", "data-web-0", "", []string{"sh", "-c", "test -f /data/PG_VERSION"}, map[string]string{"pool": "db"})
+
+	if pod.Name != "inspect-data-web-0" || pod.Namespace != "new" || pod.Labels[LabelVolumeInspection] != "data-web-0" {
+		t.Errorf("metadata = %+v", pod.ObjectMeta)
+	}
+	if pod.Spec.RestartPolicy != corev1.RestartPolicyNever || pod.Spec.NodeSelector["pool"] != "db" {
+		t.Errorf("spec = %+v, want RestartPolicy Never and the node selector", pod.Spec)
+	}
+	container := pod.Spec.Containers[0]
+	if container.Image != DefaultInspectionImage || strings.Join(container.Command, " ") != "sh -c test -f /data/PG_VERSION" {
+		t.Errorf("container = %s %v", container.Image, container.Command)
+	}
+	if mount := container.VolumeMounts[0]; !mount.ReadOnly || mount.MountPath != InspectionMountPath {
+		t.Errorf("volume mount = %+v, want read-only at %s", mount, InspectionMountPath)
+	}
+	if claim := pod.Spec.Volumes[0].PersistentVolumeClaim; claim == nil || claim.ClaimName != "data-web-0" || !claim.ReadOnly {
+		t.Errorf("volume = %+v, want data-web-0 read-only", pod.Spec.Volumes[0])
+	}
+}
+
+func TestInspectionResult(t *testing.T) {
+	terminated := func(code int32, message string) []corev1.ContainerStatus {
+		return []corev1.ContainerStatus{{State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{ExitCode: code, Message: message},
+		}}}
+	}
+	tests := []struct {
+		name     string
+		status   corev1.PodStatus
+		wantDone bool
+		wantErr  string
+	}{
+		{name: "pending", status: corev1.PodStatus{Phase: corev1.PodPending}},
+		{name: "running", status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		{name: "succeeded", status: corev1.PodStatus{Phase: corev1.PodSucceeded, ContainerStatuses: terminated(0, "")}, wantDone: true},
+		{name: "command failed",
+			status:   corev1.PodStatus{Phase: corev1.PodFailed, ContainerStatuses: terminated(1, "PG_VERSION missing\n")},
+			wantDone: true, wantErr: "exited with code 1: PG_VERSION missing"},
+		{name: "pod failed before the command ran",
+			status:   corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"},
+			wantDone: true, wantErr: "failed: Evicted"},
+	}
+	for _, tt := range tests {
+		pod := &corev1.Pod{Status: tt.status}
+		pod.Name = "inspect-data-web-0"
+		done, _, err := InspectionResult(pod)
+		if done != tt.wantDone {
+			t.Errorf("%s: done = %v, want %v", tt.name, done, tt.wantDone)
+		}
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: error = %v, want none", tt.name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: error = %v, want it to contain %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
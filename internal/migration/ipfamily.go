@@ -0,0 +1,127 @@
+package migration
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ClusterIPFamilies returns the IP families a cluster serves, primary first.
+// The primary family is that of the default/kubernetes Service; a second
+// family is only reported when the nodes' pod CIDRs include it, as on a
+// dual-stack cluster. Clusters whose nodes have no pod CIDRs, such as EKS
+// with the VPC CNI, report the primary family alone.
+func ClusterIPFamilies(kubernetes *corev1.Service, nodes []corev1.Node) []corev1.IPFamily {
+	families := ServiceIPFamilies(kubernetes)
+	if len(families) > 1 {
+		families = families[:1]
+	}
+	for _, node := range nodes {
+		cidrs := node.Spec.PodCIDRs
+		if len(cidrs) == 0 && node.Spec.PodCIDR != "" {
+			cidrs = []string{node.Spec.PodCIDR}
+		}
+		for _, cidr := range cidrs {
+			ip, _, err := net.ParseCIDR(cidr)
+			if err != nil {
+				continue
+			}
+			if family := ipFamilyOf(ip); !slices.Contains(families, family) {
+				families = append(families, family)
+			}
+		}
+	}
+	return families
+}
+
+// ServiceIPFamilies returns the IP families of a Service. Objects written
+// before dual-stack support have no spec.ipFamilies, so their families are
+// derived from the cluster IPs.
+func ServiceIPFamilies(svc *corev1.Service) []corev1.IPFamily {
+	if len(svc.Spec.IPFamilies) > 0 {
+		return append([]corev1.IPFamily{}, svc.Spec.IPFamilies...)
+	}
+	var families []corev1.IPFamily
+	for _, clusterIP := range append([]string{svc.Spec.ClusterIP}, svc.Spec.ClusterIPs...) {
+		ip := net.ParseIP(clusterIP)
+		if ip == nil {
+			continue
+		}
+		if family := ipFamilyOf(ip); !slices.Contains(families, family) {
+			families = append(families, family)
+		}
+	}
+	return families
+}
+
+// CheckServiceIPFamilies fails when a Service cannot keep its IP families in
+// a cluster serving dest: a RequireDualStack Service moving to a single-stack
+// cluster, or a Service none of whose families the cluster serves. A
+// PreferDualStack Service losing its secondary family is not an error.
+func CheckServiceIPFamilies(svc *corev1.Service, dest []corev1.IPFamily) error {
+	families := ServiceIPFamilies(svc)
+	if len(families) == 0 || len(dest) == 0 {
+		return nil
+	}
+	if policy := svc.Spec.IPFamilyPolicy; policy != nil && *policy == corev1.IPFamilyPolicyRequireDualStack && len(dest) < 2 {
+		return fmt.Errorf("service %s requires dual-stack but the destination cluster only serves %s", svc.Name, joinFamilies(dest))
+	}
+	for _, family := range families {
+		if slices.Contains(dest, family) {
+			return nil
+		}
+	}
+	return fmt.Errorf("service %s is %s but the destination cluster only serves %s; its pods would get addresses of a family the workload may not listen on",
+		svc.Name, joinFamilies(families), joinFamilies(dest))
+}
+
+// TranslateServiceIPFamilies returns the ipFamilies and ipFamilyPolicy a
+// Service needs in a cluster serving dest. Families the cluster does not
+// serve are dropped, keeping the Service's primary family first when it
+// survives; a Service left with none gets the cluster's primary family.
+// RequireDualStack becomes SingleStack when only one family remains. The
+// result equals the Service's own fields when no translation is needed.
+func TranslateServiceIPFamilies(svc *corev1.Service, dest []corev1.IPFamily) ([]corev1.IPFamily, corev1.IPFamilyPolicy) {
+	families := ServiceIPFamilies(svc)
+	policy := corev1.IPFamilyPolicySingleStack
+	if svc.Spec.IPFamilyPolicy != nil {
+		policy = *svc.Spec.IPFamilyPolicy
+	}
+	if len(dest) == 0 {
+		return families, policy
+	}
+
+	var kept []corev1.IPFamily
+	for _, family := range families {
+		if slices.Contains(dest, family) {
+			kept = append(kept, family)
+		}
+	}
+	if len(kept) == 0 {
+		kept = []corev1.IPFamily{dest[0]}
+	}
+	if len(kept) < 2 && policy == corev1.IPFamilyPolicyRequireDualStack {
+		policy = corev1.IPFamilyPolicySingleStack
+	}
+	return kept, policy
+}
+
+// ipFamilyOf returns the IP family of an address
+func ipFamilyOf(ip net.IP) corev1.IPFamily {
+	if ip.To4() != nil {
+		return corev1.IPv4Protocol
+	}
+	return corev1.IPv6Protocol
+}
+
+// joinFamilies renders families as e.g. "IPv4" or "IPv4/IPv6"
+func joinFamilies(families []corev1.IPFamily) string {
+	names := make([]string, len(families))
+	for i, family := range families {
+		names[i] = string(family)
+	}
+	return strings.Join(names, "/")
+}
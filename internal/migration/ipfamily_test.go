@@ -0,0 +1,147 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	ipv4      = []corev1.IPFamily{corev1.IPv4Protocol}
+	ipv6      = []corev1.IPFamily{corev1.IPv6Protocol}
+	dualStack = []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+)
+
+func headlessService(policy corev1.IPFamilyPolicy, families ...corev1.IPFamily) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:      corev1.ClusterIPNone,
+			ClusterIPs:     []string{corev1.ClusterIPNone},
+			IPFamilies:     families,
+			IPFamilyPolicy: &policy,
+		},
+	}
+}
+
+func TestClusterIPFamilies(t *testing.T) {
+	kubernetes := func(clusterIP string) *corev1.Service {
+		return &corev1.Service{Spec: corev1.ServiceSpec{ClusterIP: clusterIP, ClusterIPs: []string{clusterIP}}}
+	}
+	node := func(cidrs ...string) corev1.Node {
+		return corev1.Node{Spec: corev1.NodeSpec{PodCIDRs: cidrs}}
+	}
+
+	tests := []struct {
+		name       string
+		kubernetes *corev1.Service
+		nodes      []corev1.Node
+		want       []corev1.IPFamily
+	}{
+		{name: "ipv4 without pod CIDRs", kubernetes: kubernetes("10.100.0.1"), nodes: []corev1.Node{node()}, want: ipv4},
+		{name: "ipv6 only", kubernetes: kubernetes("fd00::1"), nodes: []corev1.Node{node("fd01::/80")}, want: ipv6},
+		{name: "dual-stack", kubernetes: kubernetes("10.96.0.1"), nodes: []corev1.Node{node("10.244.0.0/24", "fd01::/80")}, want: dualStack},
+		{
+			name:       "ipv6 primary dual-stack",
+			kubernetes: kubernetes("fd00::1"),
+			nodes:      []corev1.Node{node("fd01::/80", "10.244.0.0/24")},
+			want:       []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol},
+		},
+		{
+			name:       "legacy podCIDR",
+			kubernetes: kubernetes("10.96.0.1"),
+			nodes:      []corev1.Node{{Spec: corev1.NodeSpec{PodCIDR: "10.244.1.0/24"}}},
+			want:       ipv4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClusterIPFamilies(tt.kubernetes, tt.nodes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ClusterIPFamilies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckServiceIPFamilies(t *testing.T) {
+	tests := []struct {
+		name    string
+		svc     *corev1.Service
+		dest    []corev1.IPFamily
+		wantErr bool
+	}{
+		{name: "same family", svc: headlessService(corev1.IPFamilyPolicySingleStack, corev1.IPv4Protocol), dest: ipv4},
+		{name: "ipv4 into dual-stack", svc: headlessService(corev1.IPFamilyPolicySingleStack, corev1.IPv4Protocol), dest: dualStack},
+		{name: "prefer dual-stack into ipv6 only", svc: headlessService(corev1.IPFamilyPolicyPreferDualStack, dualStack...), dest: ipv6},
+		{name: "require dual-stack into dual-stack", svc: headlessService(corev1.IPFamilyPolicyRequireDualStack, dualStack...), dest: dualStack},
+		{name: "require dual-stack into single-stack", svc: headlessService(corev1.IPFamilyPolicyRequireDualStack, dualStack...), dest: ipv4, wantErr: true},
+		{name: "ipv4 into ipv6 only", svc: headlessService(corev1.IPFamilyPolicySingleStack, corev1.IPv4Protocol), dest: ipv6, wantErr: true},
+		{
+			name:    "legacy service without ipFamilies",
+			svc:     &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}, Spec: corev1.ServiceSpec{ClusterIP: "10.100.4.2"}},
+			dest:    ipv6,
+			wantErr: true,
+		},
+		{name: "unknown destination", svc: headlessService(corev1.IPFamilyPolicySingleStack, corev1.IPv4Protocol)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckServiceIPFamilies(tt.svc, tt.dest)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckServiceIPFamilies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTranslateServiceIPFamilies(t *testing.T) {
+	tests := []struct {
+		name         string
+		svc          *corev1.Service
+		dest         []corev1.IPFamily
+		wantFamilies []corev1.IPFamily
+		wantPolicy   corev1.IPFamilyPolicy
+	}{
+		{
+			name:         "unchanged",
+			svc:          headlessService(corev1.IPFamilyPolicySingleStack, corev1.IPv4Protocol),
+			dest:         dualStack,
+			wantFamilies: ipv4,
+			wantPolicy:   corev1.IPFamilyPolicySingleStack,
+		},
+		{
+			name:         "prefer dual-stack drops the missing family",
+			svc:          headlessService(corev1.IPFamilyPolicyPreferDualStack, dualStack...),
+			dest:         ipv6,
+			wantFamilies: ipv6,
+			wantPolicy:   corev1.IPFamilyPolicyPreferDualStack,
+		},
+		{
+			name:         "require dual-stack becomes single-stack",
+			svc:          headlessService(corev1.IPFamilyPolicyRequireDualStack, dualStack...),
+			dest:         ipv4,
+			wantFamilies: ipv4,
+			wantPolicy:   corev1.IPFamilyPolicySingleStack,
+		},
+		{
+			name:         "family switched to the destination's",
+			svc:          headlessService(corev1.IPFamilyPolicySingleStack, corev1.IPv4Protocol),
+			dest:         ipv6,
+			wantFamilies: ipv6,
+			wantPolicy:   corev1.IPFamilyPolicySingleStack,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			families, policy := TranslateServiceIPFamilies(tt.svc, tt.dest)
+			if !reflect.DeepEqual(families, tt.wantFamilies) || policy != tt.wantPolicy {
+				t.Errorf("TranslateServiceIPFamilies() = %v, %s, want %v, %s", families, policy, tt.wantFamilies, tt.wantPolicy)
+			}
+		})
+	}
+}
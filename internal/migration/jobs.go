@@ -0,0 +1,117 @@
+package migration
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// jobControllerLabels are set by the Job controller on a Job's selector and
+// pod template and must not be copied to a new Job
+var jobControllerLabels = []string{
+	"controller-uid",
+	"job-name",
+	batchv1.ControllerUidLabel,
+	batchv1.JobNameLabel,
+}
+
+// StatefulSetClaimNames returns the names of the PVCs of every replica of a StatefulSet
+func StatefulSetClaimNames(sts *appsv1.StatefulSet) map[string]bool {
+	replicas := 1
+	if sts.Spec.Replicas != nil {
+		replicas = int(*sts.Spec.Replicas)
+	}
+	claims := make(map[string]bool)
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		for i := 0; i < replicas; i++ {
+			claims[translate.GetPVCNameForStatefulSetPod(template.Name, sts.Name, i)] = true
+		}
+	}
+	return claims
+}
+
+// UsesClaims reports whether a pod spec mounts any of the given PVCs
+func UsesClaims(spec *corev1.PodSpec, claims map[string]bool) bool {
+	for _, volume := range spec.Volumes {
+		if volume.PersistentVolumeClaim != nil && claims[volume.PersistentVolumeClaim.ClaimName] {
+			return true
+		}
+	}
+	return false
+}
+
+// JobFinished reports whether a Job has completed or failed
+func JobFinished(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// CronJobOwner returns the name of the CronJob that created a Job, if any
+func CronJobOwner(job *batchv1.Job) string {
+	if ref := metav1.GetControllerOf(job); ref != nil && ref.Kind == "CronJob" {
+		return ref.Name
+	}
+	return ""
+}
+
+// TranslateCronJob returns a copy of a source CronJob for the destination
+// namespace. Claim names are kept, since migrated PVCs keep their names.
+func TranslateCronJob(src *batchv1.CronJob, destNamespace string, suspend bool) *batchv1.CronJob {
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: translateObjectMeta(src.ObjectMeta, destNamespace),
+		Spec:       *src.Spec.DeepCopy(),
+	}
+	cronJob.Spec.Suspend = &suspend
+	return cronJob
+}
+
+// TranslateJob returns a copy of a source Job for the destination namespace.
+// The selector and the labels the Job controller generated are dropped so the
+// destination generates its own.
+func TranslateJob(src *batchv1.Job, destNamespace string, suspend bool) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: translateObjectMeta(src.ObjectMeta, destNamespace),
+		Spec:       *src.Spec.DeepCopy(),
+	}
+	job.Spec.Suspend = &suspend
+	if job.Spec.ManualSelector == nil || !*job.Spec.ManualSelector {
+		job.Spec.Selector = nil
+		for _, label := range jobControllerLabels {
+			delete(job.Labels, label)
+			delete(job.Spec.Template.Labels, label)
+		}
+	}
+	return job
+}
+
+// translateObjectMeta keeps the name, labels and annotations of a source
+// object and drops everything the source cluster assigned
+func translateObjectMeta(src metav1.ObjectMeta, destNamespace string) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{
+		Name:        src.Name,
+		Namespace:   destNamespace,
+		Labels:      copyStringMap(src.Labels),
+		Annotations: copyStringMap(src.Annotations),
+	}
+	delete(meta.Annotations, corev1.LastAppliedConfigAnnotation)
+	return meta
+}
+
+// copyStringMap creates a copy of a string map
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
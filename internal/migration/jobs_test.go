@@ -0,0 +1,174 @@
+package migration
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func claimPodSpec(claims ...string) corev1.PodSpec {
+	spec := corev1.PodSpec{Volumes: []corev1.Volume{{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}}
+	for _, claim := range claims {
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name:         claim,
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim}},
+		})
+	}
+	return spec
+}
+
+func TestStatefulSetClaimNames(t *testing.T) {
+	replicas := int32(2)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "logs"}},
+			},
+		},
+	}
+
+	claims := StatefulSetClaimNames(sts)
+	for _, want := range []string{"data-web-0", "data-web-1", "logs-web-0", "logs-web-1"} {
+		if !claims[want] {
+			t.Errorf("claims missing %s: %v", want, claims)
+		}
+	}
+	if len(claims) != 4 {
+		t.Errorf("got %d claims, want 4", len(claims))
+	}
+}
+
+func TestUsesClaims(t *testing.T) {
+	claims := map[string]bool{"data-web-0": true}
+	tests := []struct {
+		name string
+		spec corev1.PodSpec
+		want bool
+	}{
+		{name: "mounts a replica's claim", spec: claimPodSpec("data-web-0"), want: true},
+		{name: "mounts another claim", spec: claimPodSpec("data-other-0")},
+		{name: "no claims", spec: claimPodSpec()},
+	}
+
+	for _, tt := range tests {
+		if got := UsesClaims(&tt.spec, claims); got != tt.want {
+			t.Errorf("%s: UsesClaims() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestJobFinished(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []batchv1.JobCondition
+		want       bool
+	}{
+		{name: "running"},
+		{name: "complete", conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}, want: true},
+		{name: "failed", conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}, want: true},
+		{name: "suspended", conditions: []batchv1.JobCondition{{Type: batchv1.JobSuspended, Status: corev1.ConditionTrue}}},
+	}
+
+	for _, tt := range tests {
+		job := &batchv1.Job{Status: batchv1.JobStatus{Conditions: tt.conditions}}
+		if got := JobFinished(job); got != tt.want {
+			t.Errorf("%s: JobFinished() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateCronJob(t *testing.T) {
+	suspend := true
+	src := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "prod",
+			Name:            "backup",
+			UID:             "uid-1",
+			ResourceVersion: "42",
+			Labels:          map[string]string{"app": "web"},
+			Annotations:     map[string]string{corev1.LastAppliedConfigAnnotation: "{}", "team": "db"},
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "0 * * * *",
+			Suspend:  &suspend,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: claimPodSpec("data-web-0")}},
+			},
+		},
+		Status: batchv1.CronJobStatus{LastScheduleTime: &metav1.Time{}},
+	}
+
+	got := TranslateCronJob(src, "prod-new", false)
+	if got.Namespace != "prod-new" || got.Name != "backup" {
+		t.Errorf("translated to %s/%s, want prod-new/backup", got.Namespace, got.Name)
+	}
+	if got.UID != "" || got.ResourceVersion != "" || got.Status.LastScheduleTime != nil {
+		t.Errorf("translated CronJob keeps source-assigned fields: %+v", got.ObjectMeta)
+	}
+	if got.Spec.Suspend == nil || *got.Spec.Suspend {
+		t.Error("translated CronJob is suspended, want the requested state")
+	}
+	if !*src.Spec.Suspend {
+		t.Error("TranslateCronJob() modified the source")
+	}
+	if _, ok := got.Annotations[corev1.LastAppliedConfigAnnotation]; ok || got.Annotations["team"] != "db" {
+		t.Errorf("annotations = %v, want team kept and last-applied dropped", got.Annotations)
+	}
+	if got.Spec.JobTemplate.Spec.Template.Spec.Volumes[1].PersistentVolumeClaim.ClaimName != "data-web-0" {
+		t.Error("translated CronJob does not mount the migrated claim")
+	}
+}
+
+func TestTranslateJob(t *testing.T) {
+	generated := map[string]string{
+		"app":                      "restore",
+		"controller-uid":           "uid-1",
+		"job-name":                 "restore",
+		batchv1.ControllerUidLabel: "uid-1",
+		batchv1.JobNameLabel:       "restore",
+	}
+	manual := true
+
+	tests := []struct {
+		name           string
+		manualSelector *bool
+		wantSelector   bool
+	}{
+		{name: "generated selector", wantSelector: false},
+		{name: "manual selector", manualSelector: &manual, wantSelector: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "restore", Labels: generated},
+				Spec: batchv1.JobSpec{
+					ManualSelector: tt.manualSelector,
+					Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{batchv1.ControllerUidLabel: "uid-1"}},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: generated},
+						Spec:       claimPodSpec("data-web-0"),
+					},
+				},
+			}
+
+			got := TranslateJob(src, "prod-new", false)
+			if (got.Spec.Selector != nil) != tt.wantSelector {
+				t.Errorf("selector = %v, want kept %v", got.Spec.Selector, tt.wantSelector)
+			}
+			_, kept := got.Spec.Template.Labels[batchv1.ControllerUidLabel]
+			if kept != tt.wantSelector || got.Spec.Template.Labels["app"] != "restore" {
+				t.Errorf("template labels = %v", got.Spec.Template.Labels)
+			}
+			if src.Spec.Selector == nil || src.Spec.Template.Labels[batchv1.ControllerUidLabel] != "uid-1" {
+				t.Error("TranslateJob() modified the source")
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package migration
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// RemappedVolume is a PV whose StorageClass a mapping changes
+type RemappedVolume struct {
+	// PV is the name of the PersistentVolume
+	PV string
+
+	// Claim is the namespace/name of the PVC the PV is bound to, or "" when unbound
+	Claim string
+
+	// SourceClass and DestClass are the PV's StorageClass before and after the mapping
+	SourceClass string
+	DestClass   string
+}
+
+// ClassPreview describes what a mapping does to one source StorageClass in use
+type ClassPreview struct {
+	// Source is the StorageClass of the source PVs
+	Source string
+
+	// Dest is the StorageClass the PVs get in the destination
+	Dest string
+
+	// Mapped is false when the mapping has no entry for Source, so the PVs keep its name
+	Mapped bool
+
+	// Volumes is the number of PVs of the source StorageClass
+	Volumes int
+
+	// SourceMissing and DestMissing are true when the StorageClass does not
+	// exist on that side; its parameters cannot be compared
+	SourceMissing bool
+	DestMissing   bool
+
+	// Differences are the provisioning settings that differ between the two StorageClasses
+	Differences []Difference
+
+	// Downgrades are the ways the destination provisions volumes worse than the source
+	Downgrades []string
+}
+
+// MappingSimulation is the outcome of applying a StorageClass mapping to a set of PVs
+type MappingSimulation struct {
+	// Remapped lists the PVs whose StorageClass changes, sorted by name
+	Remapped []RemappedVolume
+
+	// Classes has one entry per source StorageClass in use, sorted by name
+	Classes []ClassPreview
+
+	// Unused lists the mapping's source StorageClasses that no PV uses
+	Unused []string
+}
+
+// Unmapped returns the source StorageClasses in use that the mapping has no entry for
+func (s MappingSimulation) Unmapped() []string {
+	var names []string
+	for _, c := range s.Classes {
+		if !c.Mapped {
+			names = append(names, c.Source)
+		}
+	}
+	return names
+}
+
+// SimulateStorageClassMapping previews applying a StorageClass mapping to PVs
+// without changing anything. sourceClasses and destClasses hold each side's
+// StorageClasses by name; for a mapping within one cluster, pass the same
+// map for both. PVs without a StorageClass are not affected by a mapping
+// and are skipped.
+func SimulateStorageClassMapping(pvs []corev1.PersistentVolume, mapping map[string]string, sourceClasses, destClasses map[string]*storagev1.StorageClass) MappingSimulation {
+	var sim MappingSimulation
+	counts := make(map[string]int)
+	for _, pv := range pvs {
+		class := pv.Spec.StorageClassName
+		if class == "" {
+			continue
+		}
+		counts[class]++
+
+		dest := translate.DestStorageClass(class, mapping)
+		if dest == class {
+			continue
+		}
+		var claim string
+		if ref := pv.Spec.ClaimRef; ref != nil {
+			claim = ref.Namespace + "/" + ref.Name
+		}
+		sim.Remapped = append(sim.Remapped, RemappedVolume{PV: pv.Name, Claim: claim, SourceClass: class, DestClass: dest})
+	}
+	sort.Slice(sim.Remapped, func(i, j int) bool { return sim.Remapped[i].PV < sim.Remapped[j].PV })
+
+	for class, volumes := range counts {
+		_, mapped := mapping[class]
+		preview := ClassPreview{Source: class, Dest: translate.DestStorageClass(class, mapping), Mapped: mapped, Volumes: volumes}
+
+		source, dest := sourceClasses[class], destClasses[preview.Dest]
+		preview.SourceMissing, preview.DestMissing = source == nil, dest == nil
+		if source != nil && dest != nil {
+			preview.Differences = DiffStorageClasses(source, dest)
+			preview.Downgrades = CompareStorageClasses(source, dest)
+		}
+		sim.Classes = append(sim.Classes, preview)
+	}
+	sort.Slice(sim.Classes, func(i, j int) bool { return sim.Classes[i].Source < sim.Classes[j].Source })
+
+	for class := range mapping {
+		if counts[class] == 0 {
+			sim.Unused = append(sim.Unused, class)
+		}
+	}
+	sort.Strings(sim.Unused)
+	return sim
+}
+
+// DiffStorageClasses returns the provisioning settings that differ between
+// two StorageClasses: the provisioner, each parameter, and the reclaim policy,
+// binding mode, expansion and mount options, with the API defaults applied
+func DiffStorageClasses(source, dest *storagev1.StorageClass) []Difference {
+	d := &differ{}
+	d.compare("provisioner", source.Provisioner, dest.Provisioner)
+
+	keys := make(map[string]bool)
+	for key := range source.Parameters {
+		keys[key] = true
+	}
+	for key := range dest.Parameters {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		d.compare("parameters."+key, source.Parameters[key], dest.Parameters[key])
+	}
+
+	d.compare("reclaimPolicy", ptr.Deref(source.ReclaimPolicy, corev1.PersistentVolumeReclaimDelete),
+		ptr.Deref(dest.ReclaimPolicy, corev1.PersistentVolumeReclaimDelete))
+	d.compare("volumeBindingMode", ptr.Deref(source.VolumeBindingMode, storagev1.VolumeBindingImmediate),
+		ptr.Deref(dest.VolumeBindingMode, storagev1.VolumeBindingImmediate))
+	d.compare("allowVolumeExpansion", ptr.Deref(source.AllowVolumeExpansion, false), ptr.Deref(dest.AllowVolumeExpansion, false))
+	d.compare("mountOptions", strings.Join(source.MountOptions, ","), strings.Join(dest.MountOptions, ","))
+	return d.diffs
+}
@@ -0,0 +1,118 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestSimulateStorageClassMapping(t *testing.T) {
+	pv := func(name, class, claim string) corev1.PersistentVolume {
+		pv := corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: corev1.PersistentVolumeSpec{StorageClassName: class}}
+		if claim != "" {
+			pv.Spec.ClaimRef = &corev1.ObjectReference{Namespace: "prod", Name: claim}
+		}
+		return pv
+	}
+	class := func(name, volumeType string) *storagev1.StorageClass {
+		return &storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			Provisioner: EBSCSIDriver,
+			Parameters:  map[string]string{"type": volumeType},
+		}
+	}
+	pvs := []corev1.PersistentVolume{
+		pv("pv-b", "gp2", "data-web-1"),
+		pv("pv-a", "gp2", "data-web-0"),
+		pv("pv-c", "io2", ""),
+		pv("pv-d", "", "static"),
+	}
+	mapping := map[string]string{"gp2": "gp3", "st1": "sc1"}
+	sourceClasses := map[string]*storagev1.StorageClass{"gp2": class("gp2", "gp2"), "io2": class("io2", "io2")}
+	destClasses := map[string]*storagev1.StorageClass{"gp3": class("gp3", "gp3")}
+
+	sim := SimulateStorageClassMapping(pvs, mapping, sourceClasses, destClasses)
+
+	wantRemapped := []RemappedVolume{
+		{PV: "pv-a", Claim: "prod/data-web-0", SourceClass: "gp2", DestClass: "gp3"},
+		{PV: "pv-b", Claim: "prod/data-web-1", SourceClass: "gp2", DestClass: "gp3"},
+	}
+	if !reflect.DeepEqual(sim.Remapped, wantRemapped) {
+		t.Errorf("Remapped = %+v, want %+v", sim.Remapped, wantRemapped)
+	}
+	wantClasses := []ClassPreview{
+		{
+			Source: "gp2", Dest: "gp3", Mapped: true, Volumes: 2,
+			Differences: []Difference{{Field: "parameters.type", Source: "gp2", Dest: "gp3"}},
+		},
+		{Source: "io2", Dest: "io2", Volumes: 1, DestMissing: true},
+	}
+	if !reflect.DeepEqual(sim.Classes, wantClasses) {
+		t.Errorf("Classes = %+v, want %+v", sim.Classes, wantClasses)
+	}
+	if got := sim.Unmapped(); !reflect.DeepEqual(got, []string{"io2"}) {
+		t.Errorf("Unmapped() = %v, want [io2]", got)
+	}
+	if !reflect.DeepEqual(sim.Unused, []string{"st1"}) {
+		t.Errorf("Unused = %v, want [st1]", sim.Unused)
+	}
+}
+
+func TestDiffStorageClasses(t *testing.T) {
+	source := &storagev1.StorageClass{
+		Provisioner: EBSCSIDriver,
+		Parameters:  map[string]string{"type": "gp3", "encrypted": "true"},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*storagev1.StorageClass)
+		want   []Difference
+	}{
+		{name: "identical"},
+		{
+			name:   "explicit defaults",
+			modify: func(sc *storagev1.StorageClass) { sc.ReclaimPolicy = ptr.To(corev1.PersistentVolumeReclaimDelete) },
+		},
+		{
+			name: "parameters",
+			modify: func(sc *storagev1.StorageClass) {
+				sc.Parameters = map[string]string{"type": "io2", "iops": "4000"}
+			},
+			want: []Difference{
+				{Field: "parameters.encrypted", Source: "true", Dest: "<none>"},
+				{Field: "parameters.iops", Source: "<none>", Dest: "4000"},
+				{Field: "parameters.type", Source: "gp3", Dest: "io2"},
+			},
+		},
+		{
+			name: "class settings",
+			modify: func(sc *storagev1.StorageClass) {
+				sc.ReclaimPolicy = ptr.To(corev1.PersistentVolumeReclaimRetain)
+				sc.VolumeBindingMode = ptr.To(storagev1.VolumeBindingWaitForFirstConsumer)
+				sc.AllowVolumeExpansion = ptr.To(true)
+			},
+			want: []Difference{
+				{Field: "reclaimPolicy", Source: "Delete", Dest: "Retain"},
+				{Field: "volumeBindingMode", Source: "Immediate", Dest: "WaitForFirstConsumer"},
+				{Field: "allowVolumeExpansion", Source: "false", Dest: "true"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := source.DeepCopy()
+			if tt.modify != nil {
+				tt.modify(dest)
+			}
+			if got := DiffStorageClasses(source, dest); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffStorageClasses() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
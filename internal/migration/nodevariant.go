@@ -0,0 +1,55 @@
+package migration
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeVariant is the host OS family of a node, which decides how the EBS
+// volumes attached to it are named and whether the in-tree plugin finds them
+type NodeVariant string
+
+const (
+	// NodeVariantBottlerocket nodes run Bottlerocket, whose containerd host
+	// has none of the udev rules that link the NVMe devices of Nitro
+	// instances to the /dev/xvd* names the in-tree EBS plugin waits for
+	NodeVariantBottlerocket NodeVariant = "Bottlerocket"
+
+	// NodeVariantAL2 nodes run Amazon Linux 2
+	NodeVariantAL2 NodeVariant = "AL2"
+
+	// NodeVariantAL2023 nodes run Amazon Linux 2023
+	NodeVariantAL2023 NodeVariant = "AL2023"
+
+	// NodeVariantOther nodes run any other OS
+	NodeVariantOther NodeVariant = "Other"
+)
+
+// NodeVariantOf returns the host OS family of a node from the OS image its
+// kubelet reports
+func NodeVariantOf(node *corev1.Node) NodeVariant {
+	image := node.Status.NodeInfo.OSImage
+	switch {
+	case strings.HasPrefix(image, "Bottlerocket"):
+		return NodeVariantBottlerocket
+	case strings.HasPrefix(image, "Amazon Linux 2023"):
+		return NodeVariantAL2023
+	case strings.HasPrefix(image, "Amazon Linux 2"):
+		return NodeVariantAL2
+	default:
+		return NodeVariantOther
+	}
+}
+
+// InTreeEBSBroken reports whether the in-tree EBS plugin fails to mount
+// volumes on nodes of a variant, so they must be mounted through the EBS
+// CSI driver instead
+func InTreeEBSBroken(variant NodeVariant) bool {
+	return variant == NodeVariantBottlerocket
+}
+
+// IsInTreeEBS reports whether a PV uses the legacy in-tree EBS source
+func IsInTreeEBS(pv *corev1.PersistentVolume) bool {
+	return pv.Spec.AWSElasticBlockStore != nil
+}
@@ -0,0 +1,114 @@
+package migration
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodPriorities returns the migration priority of each ordinal of a
+// StatefulSet, read from the pod label or, when label is empty, the pod
+// annotation key. A pod that is missing or does not carry the key has
+// priority 0.
+func PodPriorities(pods []corev1.Pod, stsName string, replicas int, label, annotation string) ([]int, error) {
+	priorities := make([]int, replicas)
+	for i := range pods {
+		pod := &pods[i]
+		ordinal, ok := podOrdinal(pod.Name, stsName)
+		if !ok || ordinal >= replicas {
+			continue
+		}
+
+		value, key := pod.Labels[label], label
+		if label == "" {
+			value, key = pod.Annotations[annotation], annotation
+		}
+		if value == "" {
+			continue
+		}
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("pod %s has priority %s=%q, want an integer", pod.Name, key, value)
+		}
+		priorities[ordinal] = priority
+	}
+	return priorities, nil
+}
+
+// podOrdinal returns the ordinal of a StatefulSet pod from its name
+func podOrdinal(podName, stsName string) (int, bool) {
+	suffix, ok := strings.CutPrefix(podName, stsName+"-")
+	if !ok {
+		return 0, false
+	}
+	ordinal, err := strconv.Atoi(suffix)
+	if err != nil || ordinal < 0 {
+		return 0, false
+	}
+	return ordinal, true
+}
+
+// MigrationOrder returns the ordinals in the order they migrate, lowest
+// priority first. The destination StatefulSet runs the pods moved so far,
+// and a StatefulSet only runs a contiguous range of ordinals, so at every
+// priority the pods at or below it must be contiguous. Pods of equal priority
+// move in ordinal order, growing the range downwards, then upwards.
+func MigrationOrder(priorities []int) ([]int, error) {
+	levels := slices.Clone(priorities)
+	slices.Sort(levels)
+	levels = slices.Compact(levels)
+
+	order := make([]int, 0, len(priorities))
+	lo, hi := len(priorities), -1
+	for _, level := range levels {
+		var below, above []int
+		for ordinal, priority := range priorities {
+			switch {
+			case priority != level:
+			case ordinal < lo:
+				below = append(below, ordinal)
+			default:
+				above = append(above, ordinal)
+			}
+		}
+		if len(order) == 0 {
+			// The first pods start the range, so there is nothing below it yet
+			below, above = nil, append(below, above...)
+		}
+		slices.Reverse(below)
+		order = append(order, below...)
+		order = append(order, above...)
+
+		for _, ordinal := range order {
+			lo, hi = min(lo, ordinal), max(hi, ordinal)
+		}
+		if hi-lo+1 != len(order) {
+			return nil, fmt.Errorf("pods with priority %d or lower (ordinals %s) are not a contiguous range of ordinals; a StatefulSet can only run contiguous ordinals, so the pods between them must not have a higher priority",
+				level, formatOrdinals(order))
+		}
+	}
+	return order, nil
+}
+
+// OrdinalRange returns the first ordinal and replica count of the
+// destination StatefulSet once the first moved pods of order have migrated
+func OrdinalRange(order []int, moved int) (start, replicas int32) {
+	if moved == 0 {
+		return 0, 0
+	}
+	return int32(slices.Min(order[:moved])), int32(moved)
+}
+
+// formatOrdinals lists ordinals in ascending order
+func formatOrdinals(ordinals []int) string {
+	sorted := slices.Clone(ordinals)
+	slices.Sort(sorted)
+	parts := make([]string, len(sorted))
+	for i, ordinal := range sorted {
+		parts[i] = strconv.Itoa(ordinal)
+	}
+	return strings.Join(parts, ", ")
+}
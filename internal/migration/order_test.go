@@ -0,0 +1,126 @@
+package migration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodPriorities(t *testing.T) {
+	pod := func(name string, labels, annotations map[string]string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, Annotations: annotations}}
+	}
+
+	tests := []struct {
+		name       string
+		pods       []corev1.Pod
+		label      string
+		annotation string
+		want       []int
+		wantErr    string
+	}{
+		{
+			name:  "label",
+			pods:  []corev1.Pod{pod("web-0", map[string]string{"priority": "10"}, nil), pod("web-1", nil, nil), pod("web-2", map[string]string{"priority": "-1"}, nil)},
+			label: "priority",
+			want:  []int{10, 0, -1},
+		},
+		{
+			name:       "annotation",
+			pods:       []corev1.Pod{pod("web-1", map[string]string{"priority": "3"}, map[string]string{"priority": "5"})},
+			annotation: "priority",
+			want:       []int{0, 5, 0},
+		},
+		{
+			name:  "other pods ignored",
+			pods:  []corev1.Pod{pod("web-canary", map[string]string{"priority": "x"}, nil), pod("web-7", map[string]string{"priority": "x"}, nil), pod("db-0", map[string]string{"priority": "x"}, nil)},
+			label: "priority",
+			want:  []int{0, 0, 0},
+		},
+		{
+			name:    "not an integer",
+			pods:    []corev1.Pod{pod("web-0", map[string]string{"priority": "high"}, nil)},
+			label:   "priority",
+			wantErr: `pod web-0 has priority priority="high"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PodPriorities(tt.pods, "web", 3, tt.label, tt.annotation)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("PodPriorities() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PodPriorities() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PodPriorities() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrationOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		priorities []int
+		want       []int
+		wantErr    string
+	}{
+		{name: "no priorities", priorities: []int{0, 0, 0}, want: []int{0, 1, 2}},
+		{name: "leader on ordinal 0", priorities: []int{1, 0, 0}, want: []int{1, 2, 0}},
+		{name: "leader on last ordinal", priorities: []int{0, 0, 1}, want: []int{0, 1, 2}},
+		{name: "grows downwards then upwards", priorities: []int{1, 1, 0, 1, 2}, want: []int{2, 1, 0, 3, 4}},
+		{name: "negative priorities first", priorities: []int{0, -5, 0}, want: []int{1, 0, 2}},
+		{name: "single replica", priorities: []int{7}, want: []int{0}},
+		{name: "no replicas", priorities: []int{}, want: []int{}},
+		{name: "leader in the middle", priorities: []int{0, 1, 0}, wantErr: "ordinals 0, 2"},
+		{name: "gap at a higher level", priorities: []int{2, 0, 1, 0}, wantErr: "priority 0 or lower (ordinals 1, 3)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MigrationOrder(tt.priorities)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("MigrationOrder() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MigrationOrder() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MigrationOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrdinalRange(t *testing.T) {
+	order := []int{2, 1, 0, 3}
+	tests := []struct {
+		moved        int
+		wantStart    int32
+		wantReplicas int32
+	}{
+		{moved: 0},
+		{moved: 1, wantStart: 2, wantReplicas: 1},
+		{moved: 2, wantStart: 1, wantReplicas: 2},
+		{moved: 4, wantStart: 0, wantReplicas: 4},
+	}
+
+	for _, tt := range tests {
+		start, replicas := OrdinalRange(order, tt.moved)
+		if start != tt.wantStart || replicas != tt.wantReplicas {
+			t.Errorf("OrdinalRange(%d) = %d, %d, want %d, %d", tt.moved, start, replicas, tt.wantStart, tt.wantReplicas)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package migration
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// CountNodes returns how many schedulable nodes carry the label key with the given value
+func CountNodes(nodes []corev1.Node, key, value string) int {
+	count := 0
+	for _, node := range nodes {
+		if !node.Spec.Unschedulable && node.Labels[key] == value {
+			count++
+		}
+	}
+	return count
+}
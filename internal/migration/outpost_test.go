@@ -0,0 +1,27 @@
+package migration
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+func TestCountNodes(t *testing.T) {
+	outpostNode := zoneNode("op1", "us-west-2a", false)
+	outpostNode.Labels[translate.OutpostIDLabel] = "op-1"
+	cordoned := zoneNode("op2", "us-west-2a", true)
+	cordoned.Labels[translate.OutpostIDLabel] = "op-1"
+	nodes := []corev1.Node{outpostNode, cordoned, zoneNode("a1", "us-west-2a", false)}
+
+	if got := CountNodes(nodes, translate.OutpostIDLabel, "op-1"); got != 1 {
+		t.Errorf("CountNodes(op-1) = %d, want 1", got)
+	}
+	if got := CountNodes(nodes, translate.OutpostIDLabel, "op-2"); got != 0 {
+		t.Errorf("CountNodes(op-2) = %d, want 0", got)
+	}
+	if got := CountNodes(nodes, corev1.LabelTopologyZone, "us-west-2a"); got != 2 {
+		t.Errorf("CountNodes(us-west-2a) = %d, want 2", got)
+	}
+}
@@ -0,0 +1,101 @@
+package migration
+
+import (
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+)
+
+// Placement restricts the nodes a pod may run on, on top of its own node
+// selector and node affinity
+type Placement struct {
+	// NodeSelector is merged into the pod's, its values taking precedence
+	NodeSelector map[string]string
+
+	// Zones, when set, are required with node affinity on
+	// topology.kubernetes.io/zone
+	Zones []string
+
+	// Tolerations are added to the pod's
+	Tolerations []corev1.Toleration
+}
+
+// PlacePodSpec returns a copy of spec that only schedules on nodes p allows.
+// The zones are added to every required node affinity term, since the terms
+// are ORed, and tolerations the pod already has are not added again.
+func PlacePodSpec(spec *corev1.PodSpec, p Placement) *corev1.PodSpec {
+	placed := spec.DeepCopy()
+	if len(p.NodeSelector) > 0 {
+		if placed.NodeSelector == nil {
+			placed.NodeSelector = make(map[string]string, len(p.NodeSelector))
+		}
+		maps.Copy(placed.NodeSelector, p.NodeSelector)
+	}
+
+	if len(p.Zones) > 0 {
+		if placed.Affinity == nil {
+			placed.Affinity = &corev1.Affinity{}
+		}
+		if placed.Affinity.NodeAffinity == nil {
+			placed.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+		}
+		required := placed.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		if required == nil {
+			required = &corev1.NodeSelector{}
+		}
+		required.NodeSelectorTerms = requireZones(required.NodeSelectorTerms, p.Zones)
+		placed.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = required
+	}
+
+	for _, toleration := range p.Tolerations {
+		if !hasToleration(placed.Tolerations, toleration) {
+			placed.Tolerations = append(placed.Tolerations, *toleration.DeepCopy())
+		}
+	}
+	return placed
+}
+
+// requireZones adds a requirement for one of zones to every term, or returns
+// a single term requiring it when there are none
+func requireZones(terms []corev1.NodeSelectorTerm, zones []string) []corev1.NodeSelectorTerm {
+	if len(zones) == 0 {
+		return terms
+	}
+	zone := corev1.NodeSelectorRequirement{
+		Key:      corev1.LabelTopologyZone,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   append([]string(nil), zones...),
+	}
+	if len(terms) == 0 {
+		terms = []corev1.NodeSelectorTerm{{}}
+	}
+	for i := range terms {
+		terms[i].MatchExpressions = append(terms[i].MatchExpressions, *zone.DeepCopy())
+	}
+	return terms
+}
+
+// hasToleration reports whether tolerations already include toleration
+func hasToleration(tolerations []corev1.Toleration, toleration corev1.Toleration) bool {
+	for _, t := range tolerations {
+		if equality.Semantic.DeepEqual(t, toleration) {
+			return true
+		}
+	}
+	return false
+}
+
+// NodesAccepting returns the nodes a pod of spec could be scheduled on,
+// regardless of its volumes, with the filters CheckPodScheduling runs
+func NodesAccepting(spec *corev1.PodSpec, nodes []corev1.Node) []corev1.Node {
+	affinity := nodeaffinity.GetRequiredNodeAffinity(&corev1.Pod{Spec: *spec})
+	var accepting []corev1.Node
+	for i := range nodes {
+		if schedulingFilter(spec, affinity, &nodes[i], nil) == "" {
+			accepting = append(accepting, nodes[i])
+		}
+	}
+	return accepting
+}
@@ -0,0 +1,55 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPlacePodSpec(t *testing.T) {
+	dedicated := corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists}
+	source := &corev1.PodSpec{
+		NodeSelector: map[string]string{"pool": "db", "arch": "arm64"},
+		Tolerations:  []corev1.Toleration{dedicated},
+		Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "gen", Operator: corev1.NodeSelectorOpIn, Values: []string{"7"}}}},
+				{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "gen", Operator: corev1.NodeSelectorOpIn, Values: []string{"8"}}}},
+			}},
+		}},
+	}
+	spot := corev1.Toleration{Key: "spot", Operator: corev1.TolerationOpExists}
+
+	got := PlacePodSpec(source, Placement{
+		NodeSelector: map[string]string{"pool": "db-2"},
+		Zones:        []string{"us-east-1a", "us-east-1b"},
+		Tolerations:  []corev1.Toleration{dedicated, spot},
+	})
+
+	if want := map[string]string{"pool": "db-2", "arch": "arm64"}; !reflect.DeepEqual(got.NodeSelector, want) {
+		t.Errorf("NodeSelector = %v, want %v", got.NodeSelector, want)
+	}
+	if want := []corev1.Toleration{dedicated, spot}; !reflect.DeepEqual(got.Tolerations, want) {
+		t.Errorf("Tolerations = %v, want %v", got.Tolerations, want)
+	}
+	terms := got.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) != 2 {
+		t.Fatalf("terms = %v, want the source's two", terms)
+	}
+	for i, term := range terms {
+		if len(term.MatchExpressions) != 2 || term.MatchExpressions[1].Key != corev1.LabelTopologyZone {
+			t.Errorf("term %d = %v, want the zones required too", i, term)
+		}
+	}
+	if source.NodeSelector["pool"] != "db" || len(source.Tolerations) != 1 ||
+		len(source.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions) != 1 {
+		t.Error("the source pod spec was modified")
+	}
+
+	bare := PlacePodSpec(&corev1.PodSpec{}, Placement{Zones: []string{"us-east-1c"}})
+	if terms := bare.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms; len(terms) != 1 ||
+		!reflect.DeepEqual(terms[0].MatchExpressions[0].Values, []string{"us-east-1c"}) {
+		t.Errorf("terms = %v, want one requiring us-east-1c", terms)
+	}
+}
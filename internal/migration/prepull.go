@@ -0,0 +1,153 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// LabelImagePrePull marks a DaemonSet that pre-pulls a workload's images,
+	// and its pods, with the UID of the migration that created it
+	LabelImagePrePull = "migration.aqua.io/image-prepull"
+
+	// DefaultPrePullHelperImage provides the binary each pre-pull container
+	// runs unless another image is given
+	DefaultPrePullHelperImage = "busybox:1.36"
+
+	// prePullDir is where the helper binary is shared with the pre-pull containers
+	prePullDir = "/prepull"
+)
+
+// ImagePrePullName returns the name of the DaemonSet that pre-pulls a StatefulSet's images
+func ImagePrePullName(statefulSetName string) string {
+	if len(statefulSetName) > 52 {
+		statefulSetName = strings.TrimRight(statefulSetName[:52], "-.")
+	}
+	return statefulSetName + "-prepull"
+}
+
+// WorkloadImages returns the images of a pod's init containers and
+// containers, each once, in the order they appear
+func WorkloadImages(spec *corev1.PodSpec) []string {
+	seen := make(map[string]bool)
+	var images []string
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, c := range containers {
+			if c.Image != "" && !seen[c.Image] {
+				seen[c.Image] = true
+				images = append(images, c.Image)
+			}
+		}
+	}
+	return images
+}
+
+// ImagePrePullDaemonSet returns a DaemonSet that pulls the images of a
+// workload's pod template onto the nodes its pods can run on in zones. Each
+// image gets an init container that runs a static helper binary copied from
+// helperImage instead of the image's own entrypoint, so the image only has to
+// be pulled, not able to run anything. The pods then sleep with tiny
+// requests until the DaemonSet is deleted.
+//
+// The pods keep the template's node selector, tolerations, required node
+// affinity and image pull secrets, so they land on the nodes the workload
+// does, and run as nobody with a read-only root filesystem.
+func ImagePrePullDaemonSet(namespace, name, owner string, template *corev1.PodTemplateSpec, zones []string, helperImage, priorityClassName string) *appsv1.DaemonSet {
+	if helperImage == "" {
+		helperImage = DefaultPrePullHelperImage
+	}
+	labels := map[string]string{LabelImagePrePull: owner}
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1m"),
+			corev1.ResourceMemory: resource.MustParse("8Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("32Mi"),
+		},
+	}
+	securityContext := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: ptr.To(false),
+		ReadOnlyRootFilesystem:   ptr.To(true),
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+	mount := []corev1.VolumeMount{{Name: "prepull", MountPath: prePullDir}}
+	container := func(name, image string, command []string) corev1.Container {
+		return corev1.Container{
+			Name:            name,
+			Image:           image,
+			Command:         command,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Resources:       *resources.DeepCopy(),
+			SecurityContext: securityContext.DeepCopy(),
+			VolumeMounts:    append([]corev1.VolumeMount(nil), mount...),
+		}
+	}
+
+	// busybox runs the applet its binary is named after, here "true"
+	initContainers := []corev1.Container{container("helper", helperImage, []string{"cp", "/bin/busybox", prePullDir + "/true"})}
+	for i, image := range WorkloadImages(&template.Spec) {
+		initContainers = append(initContainers, container(fmt.Sprintf("pull-%d", i), image, []string{prePullDir + "/true"}))
+	}
+
+	pullSecrets := append([]corev1.LocalObjectReference(nil), template.Spec.ImagePullSecrets...)
+	tolerations := append([]corev1.Toleration(nil), template.Spec.Tolerations...)
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    copyStringMap(labels),
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: copyStringMap(labels)},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: copyStringMap(labels)},
+				Spec: corev1.PodSpec{
+					InitContainers:                initContainers,
+					Containers:                    []corev1.Container{container("sleep", helperImage, []string{"sleep", "2147483647"})},
+					Volumes:                       []corev1.Volume{{Name: "prepull", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+					NodeSelector:                  copyStringMap(template.Spec.NodeSelector),
+					Tolerations:                   tolerations,
+					Affinity:                      prePullAffinity(template.Spec.Affinity, zones),
+					ImagePullSecrets:              pullSecrets,
+					PriorityClassName:             priorityClassName,
+					AutomountServiceAccountToken:  ptr.To(false),
+					EnableServiceLinks:            ptr.To(false),
+					TerminationGracePeriodSeconds: ptr.To(int64(0)),
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot:   ptr.To(true),
+						RunAsUser:      ptr.To(int64(65534)),
+						RunAsGroup:     ptr.To(int64(65534)),
+						SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+					},
+				},
+			},
+		},
+	}
+}
+
+// prePullAffinity returns the template's required node affinity with every
+// term also requiring one of zones. Pod (anti-)affinity is dropped: it is
+// about the workload's pods, not the nodes their images are needed on.
+func prePullAffinity(affinity *corev1.Affinity, zones []string) *corev1.Affinity {
+	var terms []corev1.NodeSelectorTerm
+	if affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			terms = append(terms, *term.DeepCopy())
+		}
+	}
+	terms = requireZones(terms, zones)
+	if len(terms) == 0 {
+		return nil
+	}
+	return &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: terms},
+	}}
+}
@@ -0,0 +1,80 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestImagePrePullDaemonSet(t *testing.T) {
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "init", Image: "registry/app:1"}},
+		Containers: []corev1.Container{
+			{Name: "app", Image: "registry/app:1"},
+			{Name: "exporter", Image: "registry/exporter:2"},
+		},
+		NodeSelector:     map[string]string{"pool": "db"},
+		Tolerations:      []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+		Affinity: &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "arch", Operator: corev1.NodeSelectorOpIn, Values: []string{"arm64"}}}},
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "arch", Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64"}}}},
+				},
+			}},
+			PodAntiAffinity: &corev1.PodAntiAffinity{},
+		},
+	}}
+
+	ds := ImagePrePullDaemonSet("new", ImagePrePullName("web"), "uid-1", template, []string{"us-east-1a", "us-east-1b"}, "", "low")
+	if ds.Name != "web-prepull" || ds.Namespace != "new" || ds.Spec.Selector.MatchLabels[LabelImagePrePull] != "uid-1" ||
+		ds.Spec.Template.Labels[LabelImagePrePull] != "uid-1" {
+		t.Errorf("metadata = %+v, selector = %+v", ds.ObjectMeta, ds.Spec.Selector)
+	}
+
+	spec := ds.Spec.Template.Spec
+	var images []string
+	for _, c := range spec.InitContainers {
+		images = append(images, c.Image)
+		if c.SecurityContext == nil || !*c.SecurityContext.ReadOnlyRootFilesystem || c.ImagePullPolicy != corev1.PullIfNotPresent {
+			t.Errorf("init container %s = %+v, want a read-only root and IfNotPresent", c.Name, c)
+		}
+	}
+	if got := strings.Join(images, " "); got != DefaultPrePullHelperImage+" registry/app:1 registry/exporter:2" {
+		t.Errorf("init container images = %s, want the helper and each workload image once", got)
+	}
+	if cmd := strings.Join(spec.InitContainers[1].Command, " "); cmd != "/prepull/true" {
+		t.Errorf("pull command = %s, want the helper binary", cmd)
+	}
+	if spec.NodeSelector["pool"] != "db" || len(spec.Tolerations) != 1 || spec.ImagePullSecrets[0].Name != "registry" || spec.PriorityClassName != "low" {
+		t.Errorf("scheduling = %v %v %v %q, want the template's and the priority class", spec.NodeSelector, spec.Tolerations, spec.ImagePullSecrets, spec.PriorityClassName)
+	}
+	if spec.Affinity.PodAntiAffinity != nil {
+		t.Error("pod anti-affinity was kept")
+	}
+	terms := spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) != 2 {
+		t.Fatalf("node selector terms = %d, want the template's 2", len(terms))
+	}
+	for _, term := range terms {
+		if len(term.MatchExpressions) != 2 || term.MatchExpressions[1].Key != corev1.LabelTopologyZone || len(term.MatchExpressions[1].Values) != 2 {
+			t.Errorf("term = %+v, want the zones required", term)
+		}
+	}
+
+	// Without zones or node affinity, the pods run on every node
+	bare := ImagePrePullDaemonSet("new", "web-prepull", "uid-1", &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "app", Image: "registry/app:1"}},
+	}}, nil, "", "")
+	if bare.Spec.Template.Spec.Affinity != nil {
+		t.Errorf("affinity = %+v, want none", bare.Spec.Template.Spec.Affinity)
+	}
+}
+
+func TestImagePrePullName(t *testing.T) {
+	if got := ImagePrePullName(strings.Repeat("a", 70)); len(got) > 60 || !strings.HasSuffix(got, "-prepull") {
+		t.Errorf("ImagePrePullName() = %s, want at most 60 characters", got)
+	}
+}
@@ -0,0 +1,107 @@
+package migration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// LabelConnectivityProbe marks a pod that probes a moved pod from the
+	// source, with the name of the pod it probes
+	LabelConnectivityProbe = "migration.aqua.io/connectivity-probe"
+
+	// DefaultProbeAddress is the address a moved pod is probed at unless
+	// another is given: its destination pod IP
+	DefaultProbeAddress = "{{.PodIP}}"
+)
+
+// DefaultProbeCommand retries a TCP connection to $PROBE_ADDRESS:$PROBE_PORT
+// until it succeeds, printing the last failure so it ends up in the pod's
+// termination message when the probe is given up on
+var DefaultProbeCommand = []string{"sh", "-c",
+	`until nc -z -w 3 "$PROBE_ADDRESS" "$PROBE_PORT" 2>/tmp/err; do cat /tmp/err; sleep 2; done; echo "reached $PROBE_ADDRESS:$PROBE_PORT"`}
+
+// ProbeData are the fields a probe address template can use
+type ProbeData struct {
+	// PodName is the name of the moved pod
+	PodName string
+
+	// PodIP is the moved pod's IP in the destination cluster
+	PodIP string
+
+	// Namespace is the destination namespace
+	Namespace string
+}
+
+// RenderProbeAddress renders the address a moved pod is probed at from a
+// text/template, or from DefaultProbeAddress when tmpl is empty
+func RenderProbeAddress(tmpl string, data ProbeData) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultProbeAddress
+	}
+	t, err := template.New("address").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid probe address template %q: %w", tmpl, err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render probe address template %q: %w", tmpl, err)
+	}
+	address := strings.TrimSpace(b.String())
+	if address == "" {
+		return "", fmt.Errorf("probe address template %q rendered an empty address", tmpl)
+	}
+	return address, nil
+}
+
+// ProbePodName returns the name of the pod that probes a moved pod
+func ProbePodName(podName string) string {
+	return "probe-" + podName
+}
+
+// ProbePod returns a pod that runs command once with the address and port
+// to reach in $PROBE_ADDRESS and $PROBE_PORT, or DefaultProbeCommand when
+// command is empty. Like an inspection pod, its termination message falls
+// back to the tail of its log when the command fails.
+func ProbePod(namespace, podName, image string, command []string, address string, port int32, nodeSelector map[string]string) *corev1.Pod {
+	if image == "" {
+		image = DefaultInspectionImage
+	}
+	if len(command) == 0 {
+		command = DefaultProbeCommand
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ProbePodName(podName),
+			Namespace: namespace,
+			Labels:    map[string]string{LabelConnectivityProbe: podName},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			NodeSelector:                  copyStringMap(nodeSelector),
+			TerminationGracePeriodSeconds: ptr.To(int64(0)),
+			Containers: []corev1.Container{{
+				Name:    "probe",
+				Image:   image,
+				Command: append([]string(nil), command...),
+				Env: []corev1.EnvVar{
+					{Name: "PROBE_ADDRESS", Value: address},
+					{Name: "PROBE_PORT", Value: strconv.Itoa(int(port))},
+				},
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			}},
+		},
+	}
+}
+
+// ProbeResult reports whether a probe pod has finished, and the reason it
+// failed if it did not succeed
+func ProbeResult(pod *corev1.Pod) (done bool, message string, err error) {
+	return commandResult(pod, "probe")
+}
@@ -0,0 +1,55 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestProbePod(t *testing.T) {
+	pod := ProbePod("old", "web-0", "", nil, "10.1.2.3", 5432, map[string]string{"pool": "edge"})
+
+	if pod.Name != "probe-web-0" || pod.Namespace != "old" || pod.Labels[LabelConnectivityProbe] != "web-0" {
+		t.Errorf("metadata = %+v", pod.ObjectMeta)
+	}
+	if pod.Spec.RestartPolicy != corev1.RestartPolicyNever || pod.Spec.NodeSelector["pool"] != "edge" {
+		t.Errorf("spec = %+v, want RestartPolicy Never and the node selector", pod.Spec)
+	}
+	container := pod.Spec.Containers[0]
+	if container.Image != DefaultInspectionImage || strings.Join(container.Command, " ") != strings.Join(DefaultProbeCommand, " ") {
+		t.Errorf("container = %s %v, want the default image and command", container.Image, container.Command)
+	}
+	env := map[string]string{}
+	for _, e := range container.Env {
+		env[e.Name] = e.Value
+	}
+	if env["PROBE_ADDRESS"] != "10.1.2.3" || env["PROBE_PORT"] != "5432" {
+		t.Errorf("env = %v, want PROBE_ADDRESS=10.1.2.3 and PROBE_PORT=5432", env)
+	}
+}
+
+func TestRenderProbeAddress(t *testing.T) {
+	data := ProbeData{PodName: "web-0", PodIP: "10.1.2.3", Namespace: "prod"}
+	tests := []struct {
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{tmpl: "", want: "10.1.2.3"},
+		{tmpl: "{{.PodName}}.web.{{.Namespace}}.svc.cluster.local", want: "web-0.web.prod.svc.cluster.local"},
+		{tmpl: "{{.PodName", wantErr: true},
+		{tmpl: "{{.Zone}}", wantErr: true},
+		{tmpl: " ", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := RenderProbeAddress(tt.tmpl, data)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("RenderProbeAddress(%q) error = %v, wantErr %v", tt.tmpl, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("RenderProbeAddress(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package migration
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// annotationDefaultClass marks a cluster's default StorageClass
+const annotationDefaultClass = "storageclass.kubernetes.io/is-default-class"
+
+// ProposeStorageClass picks the destination StorageClass a source class
+// should map to: one that provisions volumes at least as well, preferring
+// the same name, then the destination's default class, then the first by
+// name. It returns "" when every destination class is a downgrade.
+func ProposeStorageClass(source *storagev1.StorageClass, destClasses map[string]*storagev1.StorageClass) string {
+	names := make([]string, 0, len(destClasses))
+	for name := range destClasses {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		rank := func(name string) int {
+			switch {
+			case name == source.Name:
+				return 0
+			case destClasses[name].Annotations[annotationDefaultClass] == "true":
+				return 1
+			}
+			return 2
+		}
+		if ri, rj := rank(names[i]), rank(names[j]); ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		if len(CompareStorageClasses(source, destClasses[name])) == 0 {
+			return name
+		}
+	}
+	return ""
+}
+
+// UncoveredZones returns the zones of the volumes that no schedulable
+// destination node is in, sorted. A pod on such a volume cannot be
+// reattached in the destination.
+func UncoveredZones(pvs []corev1.PersistentVolume, nodes []corev1.Node) []string {
+	nodeZones := make(map[string]bool)
+	for _, node := range nodes {
+		if zone := node.Labels[corev1.LabelTopologyZone]; zone != "" && !node.Spec.Unschedulable {
+			nodeZones[zone] = true
+		}
+	}
+	seen := make(map[string]bool)
+	var zones []string
+	for i := range pvs {
+		zone := VolumeZone(&pvs[i])
+		if zone == "" || nodeZones[zone] || seen[zone] {
+			continue
+		}
+		seen[zone] = true
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+const (
+	// detachTimeoutPerTiB is added to the detach timeout for each TiB of the
+	// largest volume, as unmounting flushes more dirty data
+	detachTimeoutPerTiB = 2 * time.Minute
+
+	// readyTimeoutPerTiB is added to the pod ready timeout for each TiB of the
+	// largest volume, for filesystem checks and log replay on mount
+	readyTimeoutPerTiB = 5 * time.Minute
+
+	// maxProposedDetachTimeout and maxProposedReadyTimeout cap the proposals
+	maxProposedDetachTimeout = 30 * time.Minute
+	maxProposedReadyTimeout  = time.Hour
+)
+
+// ProposeTimeouts scales the volume detach and pod ready timeouts with the
+// largest volume: on top of the defaults, 2m and 5m for each started TiB
+// beyond the first, up to 30m and 1h. A zero result means the default fits.
+func ProposeTimeouts(pvs []corev1.PersistentVolume, defaultDetach, defaultReady time.Duration) (detach, ready time.Duration) {
+	var largest int64
+	for i := range pvs {
+		if size, ok := pvs[i].Spec.Capacity[corev1.ResourceStorage]; ok {
+			largest = max(largest, size.Value())
+		}
+	}
+	const tib = int64(1) << 40
+	extra := time.Duration(0)
+	if largest > tib {
+		extra = time.Duration((largest - 1) / tib)
+	}
+	if extra == 0 {
+		return 0, 0
+	}
+	return min(defaultDetach+extra*detachTimeoutPerTiB, maxProposedDetachTimeout),
+		min(defaultReady+extra*readyTimeoutPerTiB, maxProposedReadyTimeout)
+}
+
+const (
+	// unhealthyNodeUnmountWait is how long the attach-detach controller
+	// waits for a node that is not Ready to unmount a volume before it
+	// detaches the volume anyway
+	unhealthyNodeUnmountWait = 6 * time.Minute
+
+	// detachHeadroom scales the 90th percentile of past detaches into a timeout
+	detachHeadroom = 1.5
+)
+
+// DetachEstimate is a volume's detach latency predicted from its history
+type DetachEstimate struct {
+	// Samples is the number of past detaches the estimate rests on
+	Samples int
+
+	// Median, P90 and Max summarize the past detaches
+	Median, P90, Max time.Duration
+
+	// Timeout is the proposed volume detach timeout; zero means the default fits
+	Timeout time.Duration
+
+	// Notes explain what the proposal took into account
+	Notes []string
+}
+
+// EstimateDetach proposes a volume detach timeout from the durations of a
+// volume's past detaches: their 90th percentile with 50% headroom, rounded
+// up to the minute, plus the six minutes Kubernetes gives a node that is not
+// Ready to unmount the volume. As with ProposeTimeouts, a zero Timeout means
+// the default fits, and the proposal is capped at 30m.
+func EstimateDetach(samples []time.Duration, nodeNotReady bool, defaultTimeout time.Duration) DetachEstimate {
+	var e DetachEstimate
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	timeout := defaultTimeout
+	if e.Samples = len(sorted); e.Samples > 0 {
+		e.Median = percentile(sorted, 0.5)
+		e.P90 = percentile(sorted, 0.9)
+		e.Max = sorted[len(sorted)-1]
+		timeout = max(timeout, (time.Duration(float64(e.P90)*detachHeadroom) + time.Minute - 1).Truncate(time.Minute))
+	} else {
+		e.Notes = append(e.Notes, "No past detach to learn from; the proposal rests on the default")
+	}
+	if nodeNotReady {
+		timeout += unhealthyNodeUnmountWait
+		e.Notes = append(e.Notes, "The volume's node is not Ready, so Kubernetes waits 6m for it to unmount the volume before detaching it")
+	}
+	if timeout > maxProposedDetachTimeout {
+		timeout = maxProposedDetachTimeout
+		e.Notes = append(e.Notes, "Past detaches would need more than the 30m cap; find out why before migrating")
+	}
+	if timeout > defaultTimeout {
+		e.Timeout = timeout
+	}
+	return e
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
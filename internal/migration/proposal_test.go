@@ -0,0 +1,127 @@
+package migration
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProposeStorageClass(t *testing.T) {
+	class := func(name, volumeType string, isDefault bool) *storagev1.StorageClass {
+		sc := &storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			Provisioner: EBSCSIDriver,
+			Parameters:  map[string]string{"type": volumeType},
+		}
+		if isDefault {
+			sc.Annotations = map[string]string{annotationDefaultClass: "true"}
+		}
+		return sc
+	}
+	source := class("fast", "gp3", false)
+
+	tests := []struct {
+		name string
+		dest []*storagev1.StorageClass
+		want string
+	}{
+		{name: "same name", dest: []*storagev1.StorageClass{class("fast", "gp3", false), class("ebs", "gp3", true)}, want: "fast"},
+		{name: "same name is a downgrade", dest: []*storagev1.StorageClass{class("fast", "gp2", false), class("ebs", "gp3", true)}, want: "ebs"},
+		{name: "first by name", dest: []*storagev1.StorageClass{class("zeta", "io2", false), class("alpha", "gp3", false), class("cold", "sc1", true)}, want: "alpha"},
+		{name: "all downgrades", dest: []*storagev1.StorageClass{class("slow", "gp2", true)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := make(map[string]*storagev1.StorageClass)
+			for _, sc := range tt.dest {
+				dest[sc.Name] = sc
+			}
+			if got := ProposeStorageClass(source, dest); got != tt.want {
+				t.Errorf("ProposeStorageClass() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUncoveredZones(t *testing.T) {
+	pvs := []corev1.PersistentVolume{
+		{Spec: corev1.PersistentVolumeSpec{NodeAffinity: zoneAffinity("us-east-1a")}},
+		{Spec: corev1.PersistentVolumeSpec{NodeAffinity: zoneAffinity("us-east-1c")}},
+		{Spec: corev1.PersistentVolumeSpec{NodeAffinity: zoneAffinity("us-east-1b")}},
+		{Spec: corev1.PersistentVolumeSpec{NodeAffinity: zoneAffinity("us-east-1c")}},
+	}
+	nodes := []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{corev1.LabelTopologyZone: "us-east-1a"}}},
+		{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{corev1.LabelTopologyZone: "us-east-1b"}},
+			Spec: corev1.NodeSpec{Unschedulable: true}},
+	}
+	got := UncoveredZones(pvs, nodes)
+	if len(got) != 2 || got[0] != "us-east-1b" || got[1] != "us-east-1c" {
+		t.Errorf("UncoveredZones() = %v, want [us-east-1b us-east-1c]", got)
+	}
+}
+
+func TestProposeTimeouts(t *testing.T) {
+	pv := func(size string) corev1.PersistentVolume {
+		return corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+		}}
+	}
+	tests := []struct {
+		name                  string
+		pvs                   []corev1.PersistentVolume
+		wantDetach, wantReady time.Duration
+	}{
+		{name: "small volumes", pvs: []corev1.PersistentVolume{pv("100Gi"), pv("1Ti")}},
+		{name: "largest volume counts", pvs: []corev1.PersistentVolume{pv("100Gi"), pv("3Ti")}, wantDetach: 9 * time.Minute, wantReady: 20 * time.Minute},
+		{name: "started TiB counts", pvs: []corev1.PersistentVolume{pv("1100Gi")}, wantDetach: 7 * time.Minute, wantReady: 15 * time.Minute},
+		{name: "capped", pvs: []corev1.PersistentVolume{pv("64Ti")}, wantDetach: 30 * time.Minute, wantReady: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detach, ready := ProposeTimeouts(tt.pvs, 5*time.Minute, 10*time.Minute)
+			if detach != tt.wantDetach || ready != tt.wantReady {
+				t.Errorf("ProposeTimeouts() = %v, %v, want %v, %v", detach, ready, tt.wantDetach, tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestEstimateDetach(t *testing.T) {
+	minutes := func(ms ...int) []time.Duration {
+		var d []time.Duration
+		for _, m := range ms {
+			d = append(d, time.Duration(m)*time.Minute)
+		}
+		return d
+	}
+	tests := []struct {
+		name         string
+		samples      []time.Duration
+		nodeNotReady bool
+		wantTimeout  time.Duration
+		wantP90      time.Duration
+		wantNotes    int
+	}{
+		{name: "no history", wantNotes: 1},
+		{name: "fast detaches", samples: minutes(1, 2, 3), wantP90: 3 * time.Minute},
+		{name: "slow detaches", samples: minutes(10, 1, 2, 3, 4, 5, 6, 7, 8, 9), wantTimeout: 14 * time.Minute, wantP90: 9 * time.Minute},
+		{name: "node not ready", nodeNotReady: true, wantTimeout: 11 * time.Minute, wantNotes: 2},
+		{name: "capped", samples: minutes(25, 30), wantTimeout: 30 * time.Minute, wantP90: 30 * time.Minute, wantNotes: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EstimateDetach(tt.samples, tt.nodeNotReady, 5*time.Minute)
+			if got.Timeout != tt.wantTimeout || got.P90 != tt.wantP90 || len(got.Notes) != tt.wantNotes {
+				t.Errorf("EstimateDetach() = %v, p90 %v, notes %q, want %v, p90 %v, %d notes", got.Timeout, got.P90, got.Notes, tt.wantTimeout, tt.wantP90, tt.wantNotes)
+			}
+			if got.Samples != len(tt.samples) {
+				t.Errorf("Samples = %d, want %d", got.Samples, len(tt.samples))
+			}
+		})
+	}
+}
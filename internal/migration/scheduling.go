@@ -0,0 +1,87 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"k8s.io/component-helpers/storage/volume"
+)
+
+// UnschedulablePod is a migrated pod no destination node would accept
+type UnschedulablePod struct {
+	// Ordinal is the pod's StatefulSet ordinal
+	Ordinal int
+
+	// Nodes is the number of destination nodes considered
+	Nodes int
+
+	// Reasons counts the nodes rejected for each reason
+	Reasons map[string]int
+}
+
+// String describes why no node accepts the pod, the way the scheduler's
+// FailedScheduling event does
+func (u UnschedulablePod) String() string {
+	reasons := make([]string, 0, len(u.Reasons))
+	for reason, count := range u.Reasons {
+		reasons = append(reasons, fmt.Sprintf("%d %s", count, reason))
+	}
+	sort.Strings(reasons)
+	message := fmt.Sprintf("0/%d nodes are available", u.Nodes)
+	if len(reasons) > 0 {
+		message += ": " + strings.Join(reasons, ", ")
+	}
+	return message
+}
+
+// CheckPodScheduling simulates scheduling each pod of a StatefulSet's
+// template onto the destination nodes, pvs[i] being the volume pod i takes
+// along, and returns the pods no node accepts. It runs the scheduler's
+// filters that do not depend on what is running: cordoned nodes, taints, the
+// node selector and required node affinity, and the volume's node affinity,
+// which pins it to its zone. Resources and pod affinity are not simulated.
+func CheckPodScheduling(spec *corev1.PodSpec, nodes []corev1.Node, pvs []*corev1.PersistentVolume) []UnschedulablePod {
+	affinity := nodeaffinity.GetRequiredNodeAffinity(&corev1.Pod{Spec: *spec})
+
+	var unschedulable []UnschedulablePod
+	for ordinal, pv := range pvs {
+		reasons := make(map[string]int)
+		schedulable := false
+		for i := range nodes {
+			reason := schedulingFilter(spec, affinity, &nodes[i], pv)
+			if reason == "" {
+				schedulable = true
+				break
+			}
+			reasons[reason]++
+		}
+		if !schedulable {
+			unschedulable = append(unschedulable, UnschedulablePod{Ordinal: ordinal, Nodes: len(nodes), Reasons: reasons})
+		}
+	}
+	return unschedulable
+}
+
+// schedulingFilter returns why node rejects the pod, in the scheduler's
+// words, or "" when it accepts it. A nil pv skips the volume's node affinity.
+func schedulingFilter(spec *corev1.PodSpec, affinity nodeaffinity.RequiredNodeAffinity, node *corev1.Node, pv *corev1.PersistentVolume) string {
+	if node.Spec.Unschedulable {
+		return "node(s) were unschedulable"
+	}
+	if taint := untoleratedTaint(spec.Tolerations, node.Spec.Taints); taint != nil {
+		return fmt.Sprintf("node(s) had untolerated taint {%s: %s}", taint.Key, taint.Value)
+	}
+	if match, err := affinity.Match(node); err != nil || !match {
+		return "node(s) didn't match Pod's node affinity/selector"
+	}
+	if pv == nil {
+		return ""
+	}
+	if err := volume.CheckNodeAffinity(pv, node.Labels); err != nil {
+		return "node(s) had volume node affinity conflict"
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package migration
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckPodScheduling(t *testing.T) {
+	zonePV := func(zone string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{NodeAffinity: zoneAffinity(zone)}}
+	}
+	node := func(name, zone string, taints ...corev1.Taint) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelTopologyZone: zone, "pool": "db"}},
+			Spec:       corev1.NodeSpec{Taints: taints},
+		}
+	}
+	dedicated := corev1.Taint{Key: "dedicated", Value: "db", Effect: corev1.TaintEffectNoSchedule}
+	cordoned := node("a2", "us-east-1a")
+	cordoned.Spec.Unschedulable = true
+	nodes := []corev1.Node{node("a1", "us-east-1a", dedicated), cordoned, node("b1", "us-east-1b")}
+	pvs := []*corev1.PersistentVolume{zonePV("us-east-1a"), zonePV("us-east-1b"), zonePV("us-east-1c")}
+
+	tests := []struct {
+		name string
+		spec corev1.PodSpec
+		want map[int]string
+	}{
+		{
+			name: "taint and missing zone",
+			want: map[int]string{
+				0: "0/3 nodes are available: 1 node(s) had untolerated taint {dedicated: db}, 1 node(s) had volume node affinity conflict, 1 node(s) were unschedulable",
+				2: "0/3 nodes are available: 1 node(s) had untolerated taint {dedicated: db}, 1 node(s) had volume node affinity conflict, 1 node(s) were unschedulable",
+			},
+		},
+		{
+			name: "toleration",
+			spec: corev1.PodSpec{Tolerations: []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}},
+			want: map[int]string{2: "0/3 nodes are available: 1 node(s) were unschedulable, 2 node(s) had volume node affinity conflict"},
+		},
+		{
+			name: "node selector",
+			spec: corev1.PodSpec{
+				NodeSelector: map[string]string{"pool": "web"},
+				Tolerations:  []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			},
+			want: map[int]string{
+				0: "0/3 nodes are available: 1 node(s) were unschedulable, 2 node(s) didn't match Pod's node affinity/selector",
+				1: "0/3 nodes are available: 1 node(s) were unschedulable, 2 node(s) didn't match Pod's node affinity/selector",
+				2: "0/3 nodes are available: 1 node(s) were unschedulable, 2 node(s) didn't match Pod's node affinity/selector",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckPodScheduling(&tt.spec, nodes, pvs)
+			if len(got) != len(tt.want) {
+				t.Fatalf("CheckPodScheduling() = %v, want pods %v", got, tt.want)
+			}
+			for _, u := range got {
+				if u.String() != tt.want[u.Ordinal] {
+					t.Errorf("pod %d = %q, want %q", u.Ordinal, u.String(), tt.want[u.Ordinal])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package migration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// InTreeEBSProvisioner is the provisioner of legacy in-tree EBS StorageClasses
+const InTreeEBSProvisioner = "kubernetes.io/aws-ebs"
+
+// ebsTypeRank orders EBS volume types by performance; higher is faster
+var ebsTypeRank = map[string]int{
+	"standard": 1,
+	"sc1":      2,
+	"st1":      3,
+	"gp2":      4,
+	"gp3":      5,
+	"io1":      6,
+	"io2":      7,
+}
+
+// EBSClassParameters are the provisioning parameters of an EBS StorageClass
+type EBSClassParameters struct {
+	// Type is the EBS volume type, defaulted as the provisioner does
+	Type string
+
+	// IOPS is the provisioned IOPS, or 0 when the type's baseline applies
+	IOPS int64
+
+	// IOPSPerGB is the IOPS provisioned per GiB of size, or 0 when not set
+	IOPSPerGB int64
+
+	// Throughput is the provisioned throughput in MiB/s, or 0 when not set
+	Throughput int64
+
+	// Encrypted is true when new volumes are encrypted
+	Encrypted bool
+
+	// KMSKeyID is the key new volumes are encrypted with; "" is the account default
+	KMSKeyID string
+}
+
+// ParseEBSClassParameters reads the parameters of an EBS CSI or in-tree EBS
+// StorageClass. Like the CSI driver, it matches parameter names case-insensitively.
+// ok is false for StorageClasses of other provisioners.
+func ParseEBSClassParameters(sc *storagev1.StorageClass) (params EBSClassParameters, ok bool) {
+	switch sc.Provisioner {
+	case EBSCSIDriver:
+		params.Type = "gp3"
+	case InTreeEBSProvisioner:
+		params.Type = "gp2"
+	default:
+		return params, false
+	}
+
+	for key, value := range sc.Parameters {
+		switch strings.ToLower(key) {
+		case "type":
+			params.Type = strings.ToLower(value)
+		case "iops":
+			params.IOPS, _ = strconv.ParseInt(value, 10, 64)
+		case "iopspergb":
+			params.IOPSPerGB, _ = strconv.ParseInt(value, 10, 64)
+		case "throughput":
+			params.Throughput, _ = strconv.ParseInt(value, 10, 64)
+		case "encrypted":
+			params.Encrypted, _ = strconv.ParseBool(value)
+		case "kmskeyid":
+			params.KMSKeyID = value
+		}
+	}
+	return params, true
+}
+
+// CompareStorageClasses returns one problem for each way the destination
+// StorageClass provisions volumes worse than the source: a slower volume
+// type, fewer IOPS or less throughput, or weaker encryption. StorageClasses
+// that are not EBS are not compared.
+func CompareStorageClasses(source, dest *storagev1.StorageClass) []string {
+	src, ok := ParseEBSClassParameters(source)
+	if !ok {
+		return nil
+	}
+	dst, ok := ParseEBSClassParameters(dest)
+	if !ok {
+		return []string{fmt.Sprintf("StorageClass %s maps to %s, which is not provisioned by EBS (%s)", source.Name, dest.Name, dest.Provisioner)}
+	}
+
+	var problems []string
+	downgrade := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("StorageClass %s maps to %s, which ", source.Name, dest.Name)+fmt.Sprintf(format, args...))
+	}
+	if ebsTypeRank[dst.Type] < ebsTypeRank[src.Type] {
+		downgrade("provisions %s instead of %s volumes", dst.Type, src.Type)
+	}
+	if src.IOPS > 0 && dst.IOPS < src.IOPS {
+		downgrade("provisions %s IOPS instead of %d", orBaseline(dst.IOPS), src.IOPS)
+	}
+	if src.IOPSPerGB > 0 && dst.IOPSPerGB < src.IOPSPerGB {
+		downgrade("provisions %s IOPS per GiB instead of %d", orBaseline(dst.IOPSPerGB), src.IOPSPerGB)
+	}
+	if src.Throughput > 0 && dst.Throughput < src.Throughput {
+		downgrade("provisions %s MiB/s instead of %d", orBaseline(dst.Throughput), src.Throughput)
+	}
+	if src.Encrypted && !dst.Encrypted {
+		downgrade("does not encrypt volumes")
+	} else if src.KMSKeyID != "" && dst.KMSKeyID != src.KMSKeyID {
+		downgrade("encrypts with %s instead of %s", orDefaultKey(dst.KMSKeyID), src.KMSKeyID)
+	}
+	return problems
+}
+
+// orBaseline renders a provisioned value, or "baseline" when none is set
+func orBaseline(v int64) string {
+	if v == 0 {
+		return "baseline"
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+// orDefaultKey renders a KMS key, or "the default key" when none is set
+func orDefaultKey(key string) string {
+	if key == "" {
+		return "the default key"
+	}
+	return key
+}
@@ -0,0 +1,91 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func storageClass(name, provisioner string, params map[string]string) *storagev1.StorageClass {
+	return &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}, Provisioner: provisioner, Parameters: params}
+}
+
+func TestParseEBSClassParameters(t *testing.T) {
+	params, ok := ParseEBSClassParameters(storageClass("fast", EBSCSIDriver, map[string]string{
+		"type": "io2", "IOPS": "16000", "encrypted": "true", "kmsKeyId": "arn:aws:kms:us-east-1:123456789012:key/abc",
+	}))
+	if !ok {
+		t.Fatal("ParseEBSClassParameters() ok = false for the EBS CSI driver")
+	}
+	want := EBSClassParameters{Type: "io2", IOPS: 16000, Encrypted: true, KMSKeyID: "arn:aws:kms:us-east-1:123456789012:key/abc"}
+	if params != want {
+		t.Errorf("ParseEBSClassParameters() = %+v, want %+v", params, want)
+	}
+
+	if params, _ := ParseEBSClassParameters(storageClass("legacy", InTreeEBSProvisioner, nil)); params.Type != "gp2" {
+		t.Errorf("in-tree default type = %s, want gp2", params.Type)
+	}
+	if _, ok := ParseEBSClassParameters(storageClass("efs", "efs.csi.aws.com", nil)); ok {
+		t.Error("ParseEBSClassParameters() ok = true for EFS")
+	}
+}
+
+func TestCompareStorageClasses(t *testing.T) {
+	tests := []struct {
+		name   string
+		source map[string]string
+		dest   map[string]string
+		want   []string
+	}{
+		{name: "identical", source: map[string]string{"type": "gp3", "iops": "6000"}, dest: map[string]string{"type": "gp3", "iops": "6000"}},
+		{name: "upgrade", source: map[string]string{"type": "gp2"}, dest: map[string]string{"type": "gp3", "throughput": "500"}},
+		{name: "default gp3 is not a downgrade of gp3", source: map[string]string{"type": "gp3"}, dest: nil},
+		{
+			name:   "high-IOPS workload to throttled class",
+			source: map[string]string{"type": "io2", "iops": "16000"},
+			dest:   map[string]string{"type": "gp3"},
+			want:   []string{"provisions gp3 instead of io2 volumes", "provisions baseline IOPS instead of 16000"},
+		},
+		{
+			name:   "lower throughput",
+			source: map[string]string{"type": "gp3", "throughput": "500"},
+			dest:   map[string]string{"type": "gp3", "throughput": "250"},
+			want:   []string{"provisions 250 MiB/s instead of 500"},
+		},
+		{
+			name:   "encryption dropped",
+			source: map[string]string{"encrypted": "true"},
+			dest:   map[string]string{},
+			want:   []string{"does not encrypt volumes"},
+		},
+		{
+			name:   "other key",
+			source: map[string]string{"encrypted": "true", "kmsKeyId": "key-a"},
+			dest:   map[string]string{"encrypted": "true"},
+			want:   []string{"encrypts with the default key instead of key-a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareStorageClasses(storageClass("src", EBSCSIDriver, tt.source), storageClass("dst", EBSCSIDriver, tt.dest))
+			if len(got) != len(tt.want) {
+				t.Fatalf("CompareStorageClasses() = %q, want %d problems", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.HasSuffix(got[i], want) || !strings.HasPrefix(got[i], "StorageClass src maps to dst") {
+					t.Errorf("problem %d = %q, want it to end with %q", i, got[i], want)
+				}
+			}
+		})
+	}
+
+	if got := CompareStorageClasses(storageClass("src", EBSCSIDriver, nil), storageClass("dst", "efs.csi.aws.com", nil)); len(got) != 1 {
+		t.Errorf("CompareStorageClasses() to EFS = %q, want one problem", got)
+	}
+	if got := CompareStorageClasses(storageClass("src", "efs.csi.aws.com", nil), storageClass("dst", EBSCSIDriver, nil)); len(got) != 0 {
+		t.Errorf("CompareStorageClasses() from EFS = %q, want no comparison", got)
+	}
+}
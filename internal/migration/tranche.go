@@ -0,0 +1,133 @@
+package migration
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// Ordinals is a contiguous range of StatefulSet ordinals, the pods a
+// StatefulSet with spec.ordinals.start Start and Count replicas runs
+type Ordinals struct {
+	Start int
+	Count int
+}
+
+// End returns the last ordinal of the range
+func (o Ordinals) End() int {
+	return o.Start + o.Count - 1
+}
+
+// List returns the ordinals of the range in ascending order
+func (o Ordinals) List() []int {
+	ordinals := make([]int, o.Count)
+	for i := range ordinals {
+		ordinals[i] = o.Start + i
+	}
+	return ordinals
+}
+
+// String returns the range as spec.ordinalRange writes it, such as "3-5" or
+// "3", or "" when it is empty
+func (o Ordinals) String() string {
+	switch o.Count {
+	case 0:
+		return ""
+	case 1:
+		return strconv.Itoa(o.Start)
+	}
+	return fmt.Sprintf("%d-%d", o.Start, o.End())
+}
+
+// ParseOrdinals parses a range such as "3-5", or a single ordinal such as "3"
+func ParseOrdinals(s string) (Ordinals, error) {
+	first, last, isRange := strings.Cut(s, "-")
+	start, err := strconv.Atoi(first)
+	if err != nil || start < 0 {
+		return Ordinals{}, fmt.Errorf("ordinal range %q must be an ordinal or two joined by -, such as 3-5", s)
+	}
+	end := start
+	if isRange {
+		if end, err = strconv.Atoi(last); err != nil || end < start {
+			return Ordinals{}, fmt.Errorf("ordinal range %q must end at or after ordinal %d", s, start)
+		}
+	}
+	return Ordinals{Start: start, Count: end - start + 1}, nil
+}
+
+// StatefulSetOrdinals returns the ordinals a StatefulSet runs
+func StatefulSetOrdinals(sts *appsv1.StatefulSet) Ordinals {
+	o := Ordinals{Count: 1}
+	if sts.Spec.Replicas != nil {
+		o.Count = int(*sts.Spec.Replicas)
+	}
+	if sts.Spec.Ordinals != nil {
+		o.Start = int(sts.Spec.Ordinals.Start)
+	}
+	return o
+}
+
+// Tranche is a migration of some of a StatefulSet's ordinals: the rest keep
+// running in the source, and earlier tranches may already run in the
+// destination
+type Tranche struct {
+	// Moved are the ordinals the tranche migrates
+	Moved Ordinals
+
+	// Order lists Moved in the order the pods migrate
+	Order []int
+
+	// Source are the ordinals the source StatefulSet keeps afterwards
+	Source Ordinals
+
+	// Dest are the ordinals the destination StatefulSet runs beforehand
+	Dest Ordinals
+}
+
+// DestAfter returns the ordinals the destination StatefulSet runs once the
+// tranche has moved
+func (t Tranche) DestAfter() Ordinals {
+	start := t.Moved.Start
+	if t.Dest.Count > 0 {
+		start = min(start, t.Dest.Start)
+	}
+	return Ordinals{Start: start, Count: t.Dest.Count + t.Moved.Count}
+}
+
+// PlanTranche plans migrating the moved ordinals of a source StatefulSet
+// running source to a destination running dest (Count 0 when it does not
+// exist yet). Both StatefulSets can only run contiguous ordinals at every
+// step, so the range must start or end where the source's does, and border
+// the destination's. The pods move in the order that grows the destination's
+// range outwards from the ordinals it already runs.
+func PlanTranche(moved, source, dest Ordinals) (Tranche, error) {
+	t := Tranche{Moved: moved, Order: moved.List(), Dest: dest}
+	if moved.Start < source.Start || moved.End() > source.End() {
+		return Tranche{}, fmt.Errorf("ordinals %s are not all in the source StatefulSet, which runs ordinals %s", moved, source)
+	}
+	switch {
+	case moved.Start == source.Start:
+		t.Source = Ordinals{Start: moved.End() + 1, Count: source.Count - moved.Count}
+	case moved.End() == source.End():
+		t.Source = Ordinals{Start: source.Start, Count: source.Count - moved.Count}
+	default:
+		return Tranche{}, fmt.Errorf("moving ordinals %s would leave the source StatefulSet running ordinals %s and %s; a StatefulSet can only run contiguous ordinals, so the range must start or end where the source's %s does",
+			moved, Ordinals{Start: source.Start, Count: moved.Start - source.Start}, Ordinals{Start: moved.End() + 1, Count: source.End() - moved.End()}, source)
+	}
+	if t.Source.Count == 0 {
+		t.Source = Ordinals{}
+	}
+
+	switch {
+	case dest.Count == 0, moved.Start == dest.End()+1:
+	case moved.End()+1 == dest.Start:
+		slices.Reverse(t.Order)
+	default:
+		return Tranche{}, fmt.Errorf("the destination StatefulSet already runs ordinals %s, so the range must end at ordinal %d or start at ordinal %d",
+			dest, dest.Start-1, dest.End()+1)
+	}
+	return t, nil
+}
@@ -0,0 +1,131 @@
+package migration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOrdinals(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Ordinals
+		wantErr bool
+	}{
+		{in: "3-5", want: Ordinals{Start: 3, Count: 3}},
+		{in: "0", want: Ordinals{Start: 0, Count: 1}},
+		{in: "4-4", want: Ordinals{Start: 4, Count: 1}},
+		{in: "5-3", wantErr: true},
+		{in: "-3", wantErr: true},
+		{in: "3-", wantErr: true},
+		{in: "a-b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseOrdinals(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOrdinals() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseOrdinals() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	for _, o := range []Ordinals{{Start: 3, Count: 3}, {Start: 7, Count: 1}} {
+		if parsed, err := ParseOrdinals(o.String()); err != nil || parsed != o {
+			t.Errorf("ParseOrdinals(%q) = %+v, %v, want %+v", o.String(), parsed, err, o)
+		}
+	}
+}
+
+func TestPlanTranche(t *testing.T) {
+	tests := []struct {
+		name       string
+		moved      string
+		source     Ordinals
+		dest       Ordinals
+		wantOrder  []int
+		wantSource Ordinals
+		wantDest   Ordinals
+		wantErr    string
+	}{
+		{
+			name:       "top of the source first",
+			moved:      "3-5",
+			source:     Ordinals{Start: 0, Count: 6},
+			wantOrder:  []int{3, 4, 5},
+			wantSource: Ordinals{Start: 0, Count: 3},
+			wantDest:   Ordinals{Start: 3, Count: 3},
+		},
+		{
+			name:       "rest below the destination",
+			moved:      "0-2",
+			source:     Ordinals{Start: 0, Count: 3},
+			dest:       Ordinals{Start: 3, Count: 3},
+			wantOrder:  []int{2, 1, 0},
+			wantSource: Ordinals{},
+			wantDest:   Ordinals{Start: 0, Count: 6},
+		},
+		{
+			name:       "bottom of the source first",
+			moved:      "0-1",
+			source:     Ordinals{Start: 0, Count: 6},
+			wantOrder:  []int{0, 1},
+			wantSource: Ordinals{Start: 2, Count: 4},
+			wantDest:   Ordinals{Start: 0, Count: 2},
+		},
+		{
+			name:       "next above the destination",
+			moved:      "2-3",
+			source:     Ordinals{Start: 2, Count: 4},
+			dest:       Ordinals{Start: 0, Count: 2},
+			wantOrder:  []int{2, 3},
+			wantSource: Ordinals{Start: 4, Count: 2},
+			wantDest:   Ordinals{Start: 0, Count: 4},
+		},
+		{
+			name:    "middle of the source",
+			moved:   "2-3",
+			source:  Ordinals{Start: 0, Count: 6},
+			wantErr: "would leave the source StatefulSet running ordinals 0-1 and 4-5",
+		},
+		{
+			name:    "not in the source",
+			moved:   "4-6",
+			source:  Ordinals{Start: 0, Count: 6},
+			wantErr: "not all in the source StatefulSet, which runs ordinals 0-5",
+		},
+		{
+			name:    "apart from the destination",
+			moved:   "0-1",
+			source:  Ordinals{Start: 0, Count: 3},
+			dest:    Ordinals{Start: 3, Count: 3},
+			wantErr: "must end at ordinal 2 or start at ordinal 6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moved, err := ParseOrdinals(tt.moved)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := PlanTranche(moved, tt.source, tt.dest)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("PlanTranche() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanTranche() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Order, tt.wantOrder) || got.Source != tt.wantSource || got.DestAfter() != tt.wantDest {
+				t.Errorf("PlanTranche() = order %v, source %+v, destination %+v; want %v, %+v, %+v",
+					got.Order, got.Source, got.DestAfter(), tt.wantOrder, tt.wantSource, tt.wantDest)
+			}
+		})
+	}
+}
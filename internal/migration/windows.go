@@ -0,0 +1,132 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/aqua-io/aqua-service-controller/pkg/translate"
+)
+
+// windowsTaintKeys are the taint keys commonly used to keep Linux pods off
+// Windows nodes; a toleration for one of them with the value "windows" marks
+// a pod template as meant for Windows
+var windowsTaintKeys = []string{"os", corev1.LabelOSStable}
+
+// PodOS returns the operating system a pod template runs on. spec.os wins,
+// then a kubernetes.io/os node selector or required node affinity, then a
+// toleration of a Windows node taint. Anything else runs on Linux.
+func PodOS(spec *corev1.PodSpec) corev1.OSName {
+	if spec.OS != nil && spec.OS.Name != "" {
+		return spec.OS.Name
+	}
+	if os, ok := spec.NodeSelector[corev1.LabelOSStable]; ok {
+		return corev1.OSName(os)
+	}
+	if affinity := spec.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		if required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+			for _, term := range required.NodeSelectorTerms {
+				for _, expr := range term.MatchExpressions {
+					if expr.Key == corev1.LabelOSStable && expr.Operator == corev1.NodeSelectorOpIn && len(expr.Values) == 1 {
+						return corev1.OSName(expr.Values[0])
+					}
+				}
+			}
+		}
+	}
+	for _, toleration := range spec.Tolerations {
+		for _, key := range windowsTaintKeys {
+			if toleration.Key == key && toleration.Value == string(corev1.Windows) {
+				return corev1.Windows
+			}
+		}
+	}
+	return corev1.Linux
+}
+
+// NodeOS returns the operating system of a node from its kubernetes.io/os
+// label. Unlabeled nodes count as Linux.
+func NodeOS(node *corev1.Node) corev1.OSName {
+	if os := node.Labels[corev1.LabelOSStable]; os != "" {
+		return corev1.OSName(os)
+	}
+	return corev1.Linux
+}
+
+// NodesForPod returns the nodes a pod template's OS lets it run on. Windows
+// node groups are usually tainted to keep Linux pods off, so for Windows pods
+// only nodes whose NoSchedule and NoExecute taints the pod tolerates count.
+func NodesForPod(nodes []corev1.Node, spec *corev1.PodSpec) []corev1.Node {
+	os := PodOS(spec)
+	var eligible []corev1.Node
+	for i := range nodes {
+		node := &nodes[i]
+		if NodeOS(node) != os {
+			continue
+		}
+		if os == corev1.Windows && !toleratesTaints(spec.Tolerations, node.Spec.Taints) {
+			continue
+		}
+		eligible = append(eligible, *node)
+	}
+	return eligible
+}
+
+// toleratesTaints reports whether the tolerations cover every scheduling taint
+func toleratesTaints(tolerations []corev1.Toleration, taints []corev1.Taint) bool {
+	return untoleratedTaint(tolerations, taints) == nil
+}
+
+// untoleratedTaint returns the first NoSchedule or NoExecute taint the
+// tolerations do not cover, or nil when they cover them all
+func untoleratedTaint(tolerations []corev1.Toleration, taints []corev1.Taint) *corev1.Taint {
+	for i := range taints {
+		taint := &taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for _, toleration := range tolerations {
+			if toleration.Effect != "" && toleration.Effect != taint.Effect {
+				continue
+			}
+			if toleration.Key != "" && toleration.Key != taint.Key {
+				continue
+			}
+			if toleration.Operator == corev1.TolerationOpExists || toleration.Value == taint.Value {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return taint
+		}
+	}
+	return nil
+}
+
+// VolumeFSType returns the fsType of an EBS PV, or "" when it does not set one
+func VolumeFSType(pv *corev1.PersistentVolume) string {
+	switch {
+	case pv.Spec.CSI != nil:
+		return pv.Spec.CSI.FSType
+	case pv.Spec.AWSElasticBlockStore != nil:
+		return pv.Spec.AWSElasticBlockStore.FSType
+	}
+	return ""
+}
+
+// CheckVolumeOS fails when pods of the given OS cannot mount a volume's
+// filesystem: NTFS on Linux, or a Linux filesystem on Windows
+func CheckVolumeOS(pv *corev1.PersistentVolume, os corev1.OSName) error {
+	fsType := VolumeFSType(pv)
+	ntfs := strings.EqualFold(fsType, translate.FSTypeNTFS)
+	switch {
+	case os == corev1.Windows && fsType != "" && !ntfs:
+		return fmt.Errorf("PV %s is formatted %s, which Windows nodes cannot mount", pv.Name, fsType)
+	case os != corev1.Windows && ntfs:
+		return fmt.Errorf("PV %s is formatted NTFS but the StatefulSet runs on %s nodes", pv.Name, os)
+	}
+	return nil
+}
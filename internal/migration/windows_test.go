@@ -0,0 +1,127 @@
+package migration
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var windowsTaint = corev1.Taint{Key: "os", Value: "windows", Effect: corev1.TaintEffectNoSchedule}
+
+func TestPodOS(t *testing.T) {
+	tests := []struct {
+		name string
+		spec corev1.PodSpec
+		want corev1.OSName
+	}{
+		{name: "default", want: corev1.Linux},
+		{name: "spec.os", spec: corev1.PodSpec{OS: &corev1.PodOS{Name: corev1.Windows}}, want: corev1.Windows},
+		{name: "node selector", spec: corev1.PodSpec{NodeSelector: map[string]string{"kubernetes.io/os": "windows"}}, want: corev1.Windows},
+		{name: "linux node selector", spec: corev1.PodSpec{NodeSelector: map[string]string{"kubernetes.io/os": "linux"}}, want: corev1.Linux},
+		{
+			name: "node affinity",
+			spec: corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "kubernetes.io/os", Operator: corev1.NodeSelectorOpIn, Values: []string{"windows"}}},
+				}}},
+			}}},
+			want: corev1.Windows,
+		},
+		{
+			name: "windows toleration",
+			spec: corev1.PodSpec{Tolerations: []corev1.Toleration{{Key: "os", Operator: corev1.TolerationOpEqual, Value: "windows", Effect: corev1.TaintEffectNoSchedule}}},
+			want: corev1.Windows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PodOS(&tt.spec); got != tt.want {
+				t.Errorf("PodOS() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodesForPod(t *testing.T) {
+	node := func(name, os string, taints ...corev1.Taint) corev1.Node {
+		n := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: corev1.NodeSpec{Taints: taints}}
+		if os != "" {
+			n.Labels = map[string]string{"kubernetes.io/os": os}
+		}
+		return n
+	}
+	nodes := []corev1.Node{
+		node("linux", "linux"),
+		node("unlabeled", ""),
+		node("win-tainted", "windows", windowsTaint),
+		node("win-gpu", "windows", windowsTaint, corev1.Taint{Key: "gpu", Effect: corev1.TaintEffectNoSchedule}),
+	}
+	windowsSpec := corev1.PodSpec{
+		NodeSelector: map[string]string{"kubernetes.io/os": "windows"},
+		Tolerations:  []corev1.Toleration{{Key: "os", Operator: corev1.TolerationOpEqual, Value: "windows", Effect: corev1.TaintEffectNoSchedule}},
+	}
+	untolerated := corev1.PodSpec{NodeSelector: map[string]string{"kubernetes.io/os": "windows"}}
+
+	tests := []struct {
+		name string
+		spec corev1.PodSpec
+		want []string
+	}{
+		{name: "linux", want: []string{"linux", "unlabeled"}},
+		{name: "windows", spec: windowsSpec, want: []string{"win-tainted"}},
+		{name: "windows without toleration", spec: untolerated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NodesForPod(nodes, &tt.spec)
+			var names []string
+			for _, n := range got {
+				names = append(names, n.Name)
+			}
+			if len(names) != len(tt.want) {
+				t.Fatalf("NodesForPod() = %v, want %v", names, tt.want)
+			}
+			for i := range names {
+				if names[i] != tt.want[i] {
+					t.Errorf("NodesForPod() = %v, want %v", names, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckVolumeOS(t *testing.T) {
+	pv := func(fsType string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-0"},
+			Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: EBSCSIDriver, VolumeHandle: "vol-0", FSType: fsType},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		fsType  string
+		os      corev1.OSName
+		wantErr bool
+	}{
+		{name: "ext4 on linux", fsType: "ext4", os: corev1.Linux},
+		{name: "ntfs on windows", fsType: "ntfs", os: corev1.Windows},
+		{name: "unset on windows", os: corev1.Windows},
+		{name: "ntfs on linux", fsType: "NTFS", os: corev1.Linux, wantErr: true},
+		{name: "xfs on windows", fsType: "xfs", os: corev1.Windows, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckVolumeOS(pv(tt.fsType), tt.os)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckVolumeOS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package multicluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// cachedObjects are the object types watched by per-migration informer caches
+var cachedObjects = []client.Object{
+	&corev1.Pod{},
+	&corev1.PersistentVolumeClaim{},
+	&corev1.PersistentVolume{},
+	&appsv1.StatefulSet{},
+}
+
+// namespaceCache is an informer cache scoped to a single namespace of a remote cluster
+type namespaceCache struct {
+	// cache and cancel are set once the cache has synced
+	cache  cache.Cache
+	cancel context.CancelFunc
+
+	// owners are the migrations currently holding a reference to the cache
+	owners map[string]struct{}
+
+	// synced is closed when the cache has synced or failed to, with err
+	// set on failure
+	synced chan struct{}
+	err    error
+}
+
+// clusterCaches tracks the informer caches started for a remote cluster
+type clusterCaches struct {
+	scheme *runtime.Scheme
+
+	mu     sync.Mutex
+	caches map[string]*namespaceCache
+}
+
+// AcquireCache starts (or reuses) an informer cache for pods, PVCs, PVs and
+// StatefulSets in the given namespace and registers owner as a user of it.
+// It blocks until the cache has synced, without holding up callers of
+// other namespaces; callers of the same namespace wait for the one sync.
+// Acquiring the same cache twice for the same owner is a no-op. A client
+// with a separate reader caches with the reader's identity.
+func (c *ClusterClient) AcquireCache(ctx context.Context, owner, namespace string) error {
+	if c.reader != nil {
+		return c.reader.AcquireCache(ctx, owner, namespace)
+	}
+	if c.caches == nil {
+		return fmt.Errorf("cluster client does not support informer caches")
+	}
+	cc := c.caches
+
+	cc.mu.Lock()
+	if nc, ok := cc.caches[namespace]; ok {
+		nc.owners[owner] = struct{}{}
+		cc.mu.Unlock()
+		select {
+		case <-nc.synced:
+			return nc.err
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for informer cache to sync for namespace %s", namespace)
+		}
+	}
+	nc := &namespaceCache{owners: map[string]struct{}{owner: {}}, synced: make(chan struct{})}
+	cc.caches[namespace] = nc
+	cc.mu.Unlock()
+
+	informerCache, cancel, err := c.startCache(ctx, namespace)
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	defer close(nc.synced)
+
+	if err == nil && cc.caches[namespace] != nc {
+		// Every owner released it, or the client was closed, while it synced
+		cancel()
+		err = fmt.Errorf("informer cache for namespace %s was stopped while it synced", namespace)
+	}
+	if err != nil {
+		if cc.caches[namespace] == nc {
+			delete(cc.caches, namespace)
+		}
+		nc.err = err
+		return err
+	}
+	nc.cache, nc.cancel = informerCache, cancel
+	return nil
+}
+
+// startCache starts an informer cache for the namespace and waits for it
+// to sync. The cache runs until the returned cancel func is called.
+func (c *ClusterClient) startCache(ctx context.Context, namespace string) (cache.Cache, context.CancelFunc, error) {
+	informerCache, err := cache.New(c.RestConfig, cache.Options{
+		HTTPClient:                  c.httpClient,
+		Scheme:                      c.caches.scheme,
+		DefaultNamespaces:           map[string]cache.Config{namespace: {}},
+		ReaderFailOnMissingInformer: true,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create informer cache: %w", err)
+	}
+
+	// The cache outlives the reconcile that started it, so it runs on its own context
+	cacheCtx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_ = informerCache.Start(cacheCtx)
+	}()
+
+	for _, obj := range cachedObjects {
+		if _, err := informerCache.GetInformer(ctx, obj); err != nil {
+			cancel()
+			return nil, nil, fmt.Errorf("failed to start informer for %T: %w", obj, err)
+		}
+	}
+	if !informerCache.WaitForCacheSync(ctx) {
+		cancel()
+		return nil, nil, fmt.Errorf("timed out waiting for informer cache to sync for namespace %s", namespace)
+	}
+	return informerCache, cancel, nil
+}
+
+// ReleaseCache drops every cache reference held by owner, stopping caches
+// that no longer have any owners
+func (c *ClusterClient) ReleaseCache(owner string) {
+	if c.reader != nil {
+		c.reader.ReleaseCache(owner)
+		return
+	}
+	if c.caches == nil {
+		return
+	}
+	cc := c.caches
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	for namespace, nc := range cc.caches {
+		delete(nc.owners, owner)
+		if len(nc.owners) == 0 {
+			if nc.cancel != nil {
+				nc.cancel()
+			}
+			delete(cc.caches, namespace)
+		}
+	}
+}
+
+// Reader returns a reader for objects in the given namespace. Reads are served
+// from the informer cache when one has been acquired for the namespace and
+// has synced, and fall back to the live client otherwise.
+func (c *ClusterClient) Reader(namespace string) client.Reader {
+	if c.reader != nil {
+		return c.reader.Reader(namespace)
+	}
+	if c.caches == nil {
+		return c.Client
+	}
+
+	c.caches.mu.Lock()
+	defer c.caches.mu.Unlock()
+
+	nc, ok := c.caches.caches[namespace]
+	if !ok || nc.cache == nil {
+		return c.Client
+	}
+	return &fallbackReader{cache: nc.cache, live: c.Client}
+}
+
+// cacheCount returns the number of informer caches running for this cluster
+func (c *ClusterClient) cacheCount() int {
+	if c.caches == nil {
+		return 0
+	}
+
+	c.caches.mu.Lock()
+	defer c.caches.mu.Unlock()
+	return len(c.caches.caches)
+}
+
+// stopCaches stops all informer caches regardless of owners
+func (c *ClusterClient) stopCaches() {
+	if c.caches == nil {
+		return
+	}
+
+	c.caches.mu.Lock()
+	defer c.caches.mu.Unlock()
+
+	for namespace, nc := range c.caches.caches {
+		if nc.cancel != nil {
+			nc.cancel()
+		}
+		delete(c.caches.caches, namespace)
+	}
+}
+
+// fallbackReader reads from an informer cache, falling back to the live
+// client for object types the cache does not watch
+type fallbackReader struct {
+	cache client.Reader
+	live  client.Reader
+}
+
+// Get implements client.Reader
+func (r *fallbackReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	err := r.cache.Get(ctx, key, obj, opts...)
+	if isNotCached(err) {
+		return r.live.Get(ctx, key, obj, opts...)
+	}
+	return err
+}
+
+// List implements client.Reader
+func (r *fallbackReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	err := r.cache.List(ctx, list, opts...)
+	if isNotCached(err) {
+		return r.live.List(ctx, list, opts...)
+	}
+	return err
+}
+
+// isNotCached reports whether err means the object type or namespace is not served by the cache
+func isNotCached(err error) bool {
+	if err == nil {
+		return false
+	}
+	var notCached *cache.ErrResourceNotCached
+	return errors.As(err, &notCached)
+}
@@ -4,6 +4,12 @@ package multicluster
 import (
 	"context"
 	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 
 	corev1 "k8s.io/api/core/v1"
@@ -11,14 +17,77 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const (
+	// DefaultQPS is the default client-side QPS limit for remote cluster clients.
+	// client-go defaults to 5, which throttles fan-out across hundreds of PVCs and pods.
+	DefaultQPS float32 = 50
+
+	// DefaultBurst is the default client-side burst limit for remote cluster clients
+	DefaultBurst = 100
+
+	// DefaultUserAgent is the user agent reported to remote API servers
+	DefaultUserAgent = "aqua-service-controller"
+)
+
+// ClientSettings controls client-side rate limiting and identification for cluster clients
+type ClientSettings struct {
+	// QPS is the sustained queries per second allowed against the API server (default: DefaultQPS)
+	QPS float32
+
+	// Burst is the maximum burst of queries allowed against the API server (default: DefaultBurst)
+	Burst int
+
+	// UserAgent is the user agent sent with every request (default: DefaultUserAgent)
+	UserAgent string
+
+	// Observer receives the outcome of every request to a remote cluster (optional)
+	Observer APIObserver
+}
+
+// DefaultClientSettings returns the default client settings
+func DefaultClientSettings() ClientSettings {
+	return ClientSettings{
+		QPS:       DefaultQPS,
+		Burst:     DefaultBurst,
+		UserAgent: DefaultUserAgent,
+	}
+}
+
+// withDefaults fills unset fields from the defaults
+func (s ClientSettings) withDefaults() ClientSettings {
+	if s.QPS <= 0 {
+		s.QPS = DefaultQPS
+	}
+	if s.Burst <= 0 {
+		s.Burst = DefaultBurst
+	}
+	if s.UserAgent == "" {
+		s.UserAgent = DefaultUserAgent
+	}
+	return s
+}
+
+// ApplyClientSettings applies rate limiting and user agent settings to a REST config.
+// Unset fields fall back to the package defaults.
+func ApplyClientSettings(restConfig *rest.Config, settings ClientSettings) {
+	settings = settings.withDefaults()
+	restConfig.QPS = settings.QPS
+	restConfig.Burst = settings.Burst
+	restConfig.UserAgent = settings.UserAgent
+}
+
 // ClientManager manages Kubernetes clients for multiple clusters
 type ClientManager struct {
 	// scheme is the runtime scheme for creating typed clients
 	scheme *runtime.Scheme
 
+	// settings are the default client settings for remote cluster clients
+	settings ClientSettings
+
 	// localClient is the client for the local/management cluster
 	localClient client.Client
 
@@ -35,14 +104,97 @@ type ClusterClient struct {
 	// Clientset is the typed Kubernetes clientset
 	Clientset kubernetes.Interface
 
-	// RestConfig is the REST config for this cluster
+	// RestConfig is the REST config for this cluster, with the credentials
+	// it was created with
 	RestConfig *rest.Config
+
+	// credentials sends the clients' requests and reloads rotated credentials
+	credentials *credentialTransport
+
+	// httpClient is the HTTP client shared by the clients and informer caches
+	httpClient *http.Client
+
+	// caches holds the informer caches acquired for migrations on this cluster
+	caches *clusterCaches
+
+	// health tracks the outcome of the requests sent to this cluster
+	health *apiHealth
+
+	// reader is the client of the identity the reads are sent with, when it
+	// is not this one; its informer caches serve this client's cached reads
+	reader *ClusterClient
+}
+
+// Health summarizes the cluster's API requests over HealthWindow
+func (cc *ClusterClient) Health() APIHealth {
+	if cc.health == nil {
+		return APIHealth{Cluster: clusterName(cc.RestConfig)}
+	}
+	return cc.health.health()
+}
+
+// currentConfig returns the REST config of the credentials the cluster's
+// requests are currently sent with
+func (cc *ClusterClient) currentConfig() *rest.Config {
+	if cc.credentials == nil {
+		return cc.RestConfig
+	}
+	return cc.credentials.restConfig()
+}
+
+// newClusterClient creates a ClusterClient from a fully configured REST
+// config, which it wraps to track the cluster's API health. reload, when
+// set, reads the credentials again after the API server rejects them.
+func (m *ClientManager) newClusterClient(restConfig *rest.Config, reload credentialReloader) (*ClusterClient, error) {
+	health := newAPIHealth(clusterName(restConfig), m.settings.Observer, clock.RealClock{})
+	restConfig.Wrap(health.wrapTransport)
+
+	credentials, err := newCredentialTransport(restConfig, reload, clock.RealClock{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transport: %w", err)
+	}
+	httpClient := &http.Client{Transport: credentials, Timeout: restConfig.Timeout}
+
+	// Create the controller-runtime client
+	c, err := client.New(health.rateLimited(restConfig), client.Options{
+		Scheme:     m.scheme,
+		HTTPClient: httpClient,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	// Create the typed clientset
+	clientset, err := kubernetes.NewForConfigAndClient(health.rateLimited(restConfig), httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	return &ClusterClient{
+		Client:      c,
+		Clientset:   clientset,
+		RestConfig:  restConfig,
+		credentials: credentials,
+		httpClient:  httpClient,
+		caches: &clusterCaches{
+			scheme: m.scheme,
+			caches: make(map[string]*namespaceCache),
+		},
+		health: health,
+	}, nil
 }
 
-// NewClientManager creates a new multi-cluster client manager
+// NewClientManager creates a new multi-cluster client manager with default client settings
 func NewClientManager(scheme *runtime.Scheme, localClient client.Client) *ClientManager {
+	return NewClientManagerWithSettings(scheme, localClient, DefaultClientSettings())
+}
+
+// NewClientManagerWithSettings creates a new multi-cluster client manager whose remote
+// clients use the given rate limiting and user agent settings
+func NewClientManagerWithSettings(scheme *runtime.Scheme, localClient client.Client, settings ClientSettings) *ClientManager {
 	return &ClientManager{
 		scheme:      scheme,
+		settings:    settings.withDefaults(),
 		localClient: localClient,
 		clientCache: make(map[string]*ClusterClient),
 	}
@@ -55,123 +207,126 @@ func (m *ClientManager) GetLocalClient() client.Client {
 
 // GetClientFromSecret retrieves or creates a client for a cluster using kubeconfig from a Secret
 func (m *ClientManager) GetClientFromSecret(ctx context.Context, secretNamespace, secretName, secretKey string) (*ClusterClient, error) {
-	cacheKey := fmt.Sprintf("%s/%s/%s", secretNamespace, secretName, secretKey)
-
-	// Check cache first
-	m.cacheMu.RLock()
-	if cc, ok := m.clientCache[cacheKey]; ok {
-		m.cacheMu.RUnlock()
-		return cc, nil
-	}
-	m.cacheMu.RUnlock()
-
-	// Fetch the secret containing the kubeconfig
-	secret := &corev1.Secret{}
-	if err := m.localClient.Get(ctx, client.ObjectKey{
-		Namespace: secretNamespace,
-		Name:      secretName,
-	}, secret); err != nil {
-		return nil, fmt.Errorf("failed to get kubeconfig secret %s/%s: %w", secretNamespace, secretName, err)
-	}
-
-	// Get the kubeconfig data from the secret
-	kubeconfigData, ok := secret.Data[secretKey]
-	if !ok {
-		return nil, fmt.Errorf("secret %s/%s does not contain key %q", secretNamespace, secretName, secretKey)
-	}
+	return m.GetClient(ctx, ContextRef{
+		SecretNamespace: secretNamespace,
+		SecretName:      secretName,
+		SecretKey:       secretKey,
+	})
+}
 
-	// Create client from kubeconfig
-	cc, err := m.createClientFromKubeconfig(kubeconfigData)
+// GetClientFromKubeconfig creates a client directly from kubeconfig bytes
+func (m *ClientManager) GetClientFromKubeconfig(kubeconfig []byte) (*ClusterClient, error) {
+	restConfig, err := m.restConfigFromKubeconfig(kubeconfig, ContextRef{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create client from kubeconfig: %w", err)
+		return nil, err
 	}
-
-	// Cache the client
-	m.cacheMu.Lock()
-	m.clientCache[cacheKey] = cc
-	m.cacheMu.Unlock()
-
-	return cc, nil
+	return m.newClusterClient(restConfig, nil)
 }
 
-// GetClientFromKubeconfig creates a client directly from kubeconfig bytes
-func (m *ClientManager) GetClientFromKubeconfig(kubeconfig []byte) (*ClusterClient, error) {
-	return m.createClientFromKubeconfig(kubeconfig)
+// restConfigFromKubeconfig creates the REST config for kubeconfig bytes,
+// using the reference's context
+func (m *ClientManager) restConfigFromKubeconfig(kubeconfig []byte, ref ContextRef) (*rest.Config, error) {
+	restConfig, err := RESTConfigForContext(kubeconfig, ref.Context)
+	if err != nil {
+		return nil, err
+	}
+	m.configure(restConfig, ref)
+	return restConfig, nil
 }
 
-// createClientFromKubeconfig creates a ClusterClient from kubeconfig bytes
-func (m *ClientManager) createClientFromKubeconfig(kubeconfig []byte) (*ClusterClient, error) {
+// RESTConfigForContext creates the REST config for a context of kubeconfig
+// bytes, or for its current-context when context is empty. A context the
+// kubeconfig does not define is an error naming those it does, rather than
+// a fallback to the current context.
+func RESTConfigForContext(kubeconfig []byte, context string) (*rest.Config, error) {
 	// Parse the kubeconfig
-	clientConfig, err := clientcmd.NewClientConfigFromBytes(kubeconfig)
+	config, err := clientcmd.Load(kubeconfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
 	}
+	if context != "" {
+		if _, ok := config.Contexts[context]; !ok {
+			return nil, fmt.Errorf("kubeconfig has no context %q (contexts: %s)", context, strings.Join(slices.Sorted(maps.Keys(config.Contexts)), ", "))
+		}
+	}
 
 	// Get the REST config
-	restConfig, err := clientConfig.ClientConfig()
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(*config, context, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create REST config: %w", err)
 	}
+	return restConfig, nil
+}
 
-	// Create the controller-runtime client
-	c, err := client.New(restConfig, client.Options{
-		Scheme: m.scheme,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create client: %w", err)
+// restConfigFromToken creates the REST config for ref.Server that
+// authenticates with the bearer token in the reference's Secret
+func (m *ClientManager) restConfigFromToken(token, caBundle []byte, ref ContextRef) *rest.Config {
+	restConfig := &rest.Config{
+		Host:        ref.Server,
+		BearerToken: strings.TrimSpace(string(token)),
 	}
+	restConfig.TLSClientConfig.CAData = caBundle
+	m.configure(restConfig, ref)
+	return restConfig
+}
 
-	// Create the typed clientset
-	clientset, err := kubernetes.NewForConfig(restConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create clientset: %w", err)
+// configure applies the reference's impersonation, rate limits and user
+// agent to a REST config
+func (m *ClientManager) configure(restConfig *rest.Config, ref ContextRef) {
+	// Apply impersonation on top of whatever identity the kubeconfig provides
+	if impersonate := ref.impersonationConfig(); impersonate != nil {
+		restConfig.Impersonate = *impersonate
 	}
 
-	return &ClusterClient{
-		Client:     c,
-		Clientset:  clientset,
-		RestConfig: restConfig,
-	}, nil
+	// Apply rate limiting and the user agent, honoring per-reference overrides
+	ApplyClientSettings(restConfig, ref.clientSettings(m.settings))
 }
 
-// GetClientFromRestConfig creates a client from a REST config
+// GetClientFromRestConfig creates a client from a REST config.
+// The config is copied and the manager's client settings are applied to the copy.
 func (m *ClientManager) GetClientFromRestConfig(restConfig *rest.Config) (*ClusterClient, error) {
-	// Create the controller-runtime client
-	c, err := client.New(restConfig, client.Options{
-		Scheme: m.scheme,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create client: %w", err)
-	}
+	restConfig = rest.CopyConfig(restConfig)
+	ApplyClientSettings(restConfig, m.settings)
 
-	// Create the typed clientset
-	clientset, err := kubernetes.NewForConfig(restConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create clientset: %w", err)
-	}
-
-	return &ClusterClient{
-		Client:     c,
-		Clientset:  clientset,
-		RestConfig: restConfig,
-	}, nil
+	return m.newClusterClient(restConfig, nil)
 }
 
-// InvalidateCache removes a cached client
+// InvalidateCache removes all cached clients built from the given secret,
+// including any impersonated variants, and stops their informer caches
 func (m *ClientManager) InvalidateCache(secretNamespace, secretName, secretKey string) {
-	cacheKey := fmt.Sprintf("%s/%s/%s", secretNamespace, secretName, secretKey)
+	prefix := fmt.Sprintf("%s/%s/%s", secretNamespace, secretName, secretKey)
 	m.cacheMu.Lock()
-	delete(m.clientCache, cacheKey)
+	for key := range m.clientCache {
+		if key == prefix || strings.HasPrefix(key, prefix+"?") {
+			m.clientCache[key].stopCaches()
+			delete(m.clientCache, key)
+		}
+	}
 	m.cacheMu.Unlock()
 }
 
-// ClearCache removes all cached clients
+// ClearCache removes all cached clients and stops their informer caches
 func (m *ClientManager) ClearCache() {
 	m.cacheMu.Lock()
+	for _, cc := range m.clientCache {
+		cc.stopCaches()
+	}
 	m.clientCache = make(map[string]*ClusterClient)
 	m.cacheMu.Unlock()
 }
 
+// Stats returns the number of cached remote cluster clients and of informer
+// caches running for them
+func (m *ClientManager) Stats() (clients, caches int) {
+	m.cacheMu.RLock()
+	defer m.cacheMu.RUnlock()
+
+	for _, cc := range m.clientCache {
+		caches += cc.cacheCount()
+	}
+	return len(m.clientCache), caches
+}
+
 // TestConnection tests connectivity to a cluster
 func (m *ClientManager) TestConnection(ctx context.Context, cc *ClusterClient) error {
 	// Try to get server version as a connectivity test
@@ -179,6 +334,11 @@ func (m *ClientManager) TestConnection(ctx context.Context, cc *ClusterClient) e
 	if err != nil {
 		return fmt.Errorf("failed to connect to cluster: %w", err)
 	}
+	if cc.reader != nil {
+		if _, err := cc.reader.Clientset.Discovery().ServerVersion(); err != nil {
+			return fmt.Errorf("failed to connect to cluster as the reader: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -187,20 +347,236 @@ type ContextRef struct {
 	// SecretNamespace is the namespace of the kubeconfig secret
 	SecretNamespace string
 
-	// SecretName is the name of the kubeconfig secret
+	// SecretName is the name of the kubeconfig secret, or of the token
+	// secret when Server is set
 	SecretName string
 
-	// SecretKey is the key in the secret containing the kubeconfig (default: "kubeconfig")
+	// SecretKey is the key in the secret containing the kubeconfig (default:
+	// "kubeconfig"), or the token when Server is set (default: "token")
 	SecretKey string
+
+	// Context is the kubeconfig context to use (default: its current-context)
+	Context string
+
+	// Server is the API server URL of a cluster reached with a bearer token
+	// instead of a kubeconfig (optional)
+	Server string
+
+	// CASecretName is the name of the secret holding the PEM CA bundle that
+	// verifies Server. Without it the system roots are used (optional).
+	CASecretName string
+
+	// CASecretKey is the key in the CA secret (default: "ca.crt")
+	CASecretKey string
+
+	// ImpersonateUser is the user to impersonate on the remote cluster (optional)
+	ImpersonateUser string
+
+	// ImpersonateGroups are the groups to impersonate on the remote cluster (optional)
+	ImpersonateGroups []string
+
+	// QPS overrides the manager's QPS limit for this cluster (optional)
+	QPS float32
+
+	// Burst overrides the manager's burst limit for this cluster (optional)
+	Burst int
+
+	// UserAgent overrides the manager's user agent for this cluster (optional)
+	UserAgent string
+
+	// Reader is the identity the cluster's reads are sent with, when
+	// another than this one; the client sends everything else with this
+	// one (optional)
+	Reader *ContextRef
+}
+
+// cacheKey returns the client cache key for the reference. Impersonated
+// identities and rate limit overrides get their own cache entries so they
+// never share a client with the plain kubeconfig identity.
+func (r ContextRef) cacheKey() string {
+	key := fmt.Sprintf("%s/%s/%s", r.SecretNamespace, r.SecretName, r.SecretKey)
+	params := url.Values{}
+	if r.Context != "" {
+		params.Set("context", r.Context)
+	}
+	if r.Server != "" {
+		params.Set("server", r.Server)
+		params.Set("ca", r.CASecretName+"/"+r.CASecretKey)
+	}
+	if r.ImpersonateUser != "" {
+		params.Set("as", r.ImpersonateUser)
+		params.Set("groups", strings.Join(r.ImpersonateGroups, ","))
+	}
+	if r.QPS > 0 {
+		params.Set("qps", strconv.FormatFloat(float64(r.QPS), 'f', -1, 32))
+	}
+	if r.Burst > 0 {
+		params.Set("burst", strconv.Itoa(r.Burst))
+	}
+	if r.UserAgent != "" {
+		params.Set("ua", r.UserAgent)
+	}
+	if len(params) > 0 {
+		key += "?" + params.Encode()
+	}
+	return key
+}
+
+// clientSettings returns the effective client settings for the reference
+func (r ContextRef) clientSettings(defaults ClientSettings) ClientSettings {
+	settings := defaults
+	if r.QPS > 0 {
+		settings.QPS = r.QPS
+	}
+	if r.Burst > 0 {
+		settings.Burst = r.Burst
+	}
+	if r.UserAgent != "" {
+		settings.UserAgent = r.UserAgent
+	}
+	return settings
+}
+
+// impersonationConfig returns the REST impersonation config for the reference, if any
+func (r ContextRef) impersonationConfig() *rest.ImpersonationConfig {
+	if r.ImpersonateUser == "" {
+		return nil
+	}
+	return &rest.ImpersonationConfig{
+		UserName: r.ImpersonateUser,
+		Groups:   append([]string(nil), r.ImpersonateGroups...),
+	}
 }
 
-// GetClient is a convenience method to get a client from a ContextRef
+// GetClient retrieves or creates a client for the cluster described by a ContextRef
 func (m *ClientManager) GetClient(ctx context.Context, ref ContextRef) (*ClusterClient, error) {
-	secretKey := ref.SecretKey
-	if secretKey == "" {
-		secretKey = "kubeconfig"
+	if ref.Reader != nil {
+		return m.getSplitClient(ctx, ref)
+	}
+	switch {
+	case ref.SecretKey != "":
+	case ref.Server != "":
+		ref.SecretKey = "token"
+	default:
+		ref.SecretKey = "kubeconfig"
+	}
+	if ref.CASecretName != "" && ref.CASecretKey == "" {
+		ref.CASecretKey = "ca.crt"
+	}
+	cacheKey := ref.cacheKey()
+
+	// Check cache first
+	m.cacheMu.RLock()
+	if cc, ok := m.clientCache[cacheKey]; ok {
+		m.cacheMu.RUnlock()
+		return cc, nil
+	}
+	m.cacheMu.RUnlock()
+
+	reload := func(ctx context.Context) (*rest.Config, error) {
+		return m.restConfigFromSecrets(ctx, ref)
+	}
+	restConfig, err := reload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cc, err := m.newClusterClient(restConfig, reload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	// Cache the client
+	m.cacheMu.Lock()
+	m.clientCache[cacheKey] = cc
+	m.cacheMu.Unlock()
+
+	return cc, nil
+}
+
+// getSplitClient returns a client that sends its reads with ref.Reader and
+// everything else with ref's own identity. Both identities' clients are
+// cached on their own, so rotating either Secret invalidates it, and the
+// split client wrapping them is cheap to create for each call.
+func (m *ClientManager) getSplitClient(ctx context.Context, ref ContextRef) (*ClusterClient, error) {
+	readerRef := *ref.Reader
+	ref.Reader = nil
+	actor, err := m.GetClient(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := m.GetClient(ctx, readerRef)
+	if err != nil {
+		return nil, fmt.Errorf("reader: %w", err)
+	}
+	return &ClusterClient{
+		Client:      &splitClient{Client: actor.Client, reader: reader.Client},
+		Clientset:   actor.Clientset,
+		RestConfig:  actor.RestConfig,
+		credentials: actor.credentials,
+		httpClient:  actor.httpClient,
+		health:      actor.health,
+		reader:      reader,
+	}, nil
+}
+
+// splitClient sends Get and List with the reader's identity and every
+// write, status and subresource request with the embedded client's
+type splitClient struct {
+	client.Client
+	reader client.Reader
+}
+
+// Get implements client.Reader
+func (c *splitClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return c.reader.Get(ctx, key, obj, opts...)
+}
+
+// List implements client.Reader
+func (c *splitClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.reader.List(ctx, list, opts...)
+}
+
+// restConfigFromSecrets reads the reference's kubeconfig, or its token and
+// CA bundle, from their Secrets and creates the REST config for them. It
+// runs again whenever the API server rejects the credentials.
+func (m *ClientManager) restConfigFromSecrets(ctx context.Context, ref ContextRef) (*rest.Config, error) {
+	if ref.Server == "" {
+		kubeconfigData, err := m.secretData(ctx, "kubeconfig", ref.SecretNamespace, ref.SecretName, ref.SecretKey)
+		if err != nil {
+			return nil, err
+		}
+		restConfig, err := m.restConfigFromKubeconfig(kubeconfigData, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client from kubeconfig: %w", err)
+		}
+		return restConfig, nil
+	}
+
+	token, err := m.secretData(ctx, "token", ref.SecretNamespace, ref.SecretName, ref.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	var caBundle []byte
+	if ref.CASecretName != "" {
+		if caBundle, err = m.secretData(ctx, "CA bundle", ref.SecretNamespace, ref.CASecretName, ref.CASecretKey); err != nil {
+			return nil, err
+		}
+	}
+	return m.restConfigFromToken(token, caBundle, ref), nil
+}
+
+// secretData reads a key of a Secret in the management cluster; what names
+// the data in errors
+func (m *ClientManager) secretData(ctx context.Context, what, namespace, name, key string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := m.localClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get %s secret %s/%s: %w", what, namespace, name, err)
+	}
+	data, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s does not contain key %q", namespace, name, key)
 	}
-	return m.GetClientFromSecret(ctx, ref.SecretNamespace, ref.SecretName, secretKey)
+	return data, nil
 }
 
 // BuildScheme builds a runtime scheme with all necessary types
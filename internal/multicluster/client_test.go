@@ -0,0 +1,367 @@
+package multicluster
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestContextRefCacheKey(t *testing.T) {
+	plain := ContextRef{SecretNamespace: "ns", SecretName: "kc", SecretKey: "kubeconfig"}
+	reader := ContextRef{SecretNamespace: "ns", SecretName: "kc", SecretKey: "kubeconfig", ImpersonateUser: "reader"}
+	writer := ContextRef{SecretNamespace: "ns", SecretName: "kc", SecretKey: "kubeconfig", ImpersonateUser: "writer", ImpersonateGroups: []string{"ops"}}
+
+	if plain.cacheKey() != "ns/kc/kubeconfig" {
+		t.Errorf("unexpected plain cache key %q", plain.cacheKey())
+	}
+	if plain.cacheKey() == reader.cacheKey() || reader.cacheKey() == writer.cacheKey() {
+		t.Error("impersonated references must not share cache keys")
+	}
+	if plain.impersonationConfig() != nil {
+		t.Error("expected no impersonation config without a user")
+	}
+	cfg := writer.impersonationConfig()
+	if cfg == nil || cfg.UserName != "writer" || len(cfg.Groups) != 1 || cfg.Groups[0] != "ops" {
+		t.Errorf("unexpected impersonation config %+v", cfg)
+	}
+}
+
+const twoContextKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com
+- name: staging
+  cluster:
+    server: https://staging.example.com
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+- name: staging
+  context:
+    cluster: staging
+    user: admin
+current-context: prod
+users:
+- name: admin
+  user:
+    token: t
+`
+
+func TestRESTConfigForContext(t *testing.T) {
+	for context, want := range map[string]string{
+		"":        "https://prod.example.com",
+		"prod":    "https://prod.example.com",
+		"staging": "https://staging.example.com",
+	} {
+		cfg, err := RESTConfigForContext([]byte(twoContextKubeconfig), context)
+		if err != nil {
+			t.Fatalf("RESTConfigForContext(%q) error = %v", context, err)
+		}
+		if cfg.Host != want {
+			t.Errorf("RESTConfigForContext(%q) host = %q, want %q", context, cfg.Host, want)
+		}
+	}
+
+	_, err := RESTConfigForContext([]byte(twoContextKubeconfig), "dev")
+	if err == nil || !strings.Contains(err.Error(), `no context "dev" (contexts: prod, staging)`) {
+		t.Errorf("RESTConfigForContext(dev) error = %v, want the contexts listed", err)
+	}
+
+	prod := ContextRef{SecretNamespace: "ns", SecretName: "kc", SecretKey: "kubeconfig"}
+	staging := prod
+	staging.Context = "staging"
+	if prod.cacheKey() == staging.cacheKey() {
+		t.Error("references to different contexts must not share cache keys")
+	}
+}
+
+func TestInvalidateCacheRemovesImpersonatedVariants(t *testing.T) {
+	m := NewClientManager(nil, nil)
+	refs := []ContextRef{
+		{SecretNamespace: "ns", SecretName: "kc", SecretKey: "kubeconfig"},
+		{SecretNamespace: "ns", SecretName: "kc", SecretKey: "kubeconfig", ImpersonateUser: "reader"},
+		{SecretNamespace: "ns", SecretName: "other", SecretKey: "kubeconfig"},
+	}
+	for _, ref := range refs {
+		m.clientCache[ref.cacheKey()] = &ClusterClient{}
+	}
+
+	m.InvalidateCache("ns", "kc", "kubeconfig")
+
+	if len(m.clientCache) != 1 {
+		t.Fatalf("expected 1 cached client to remain, got %d", len(m.clientCache))
+	}
+	if _, ok := m.clientCache[refs[2].cacheKey()]; !ok {
+		t.Error("unrelated client should remain cached")
+	}
+}
+
+func TestStats(t *testing.T) {
+	m := NewClientManager(nil, nil)
+	m.clientCache["plain"] = &ClusterClient{}
+	m.clientCache["cached"] = &ClusterClient{caches: &clusterCaches{caches: map[string]*namespaceCache{"src": {}, "dest": {}}}}
+
+	if clients, caches := m.Stats(); clients != 2 || caches != 2 {
+		t.Errorf("Stats() = %d, %d, want 2, 2", clients, caches)
+	}
+}
+
+func TestClientSettings(t *testing.T) {
+	defaults := ClientSettings{}.withDefaults()
+	if defaults.QPS != DefaultQPS || defaults.Burst != DefaultBurst || defaults.UserAgent != DefaultUserAgent {
+		t.Errorf("unexpected defaults %+v", defaults)
+	}
+
+	ref := ContextRef{SecretNamespace: "ns", SecretName: "kc", SecretKey: "kubeconfig", QPS: 200}
+	got := ref.clientSettings(defaults)
+	if got.QPS != 200 || got.Burst != DefaultBurst {
+		t.Errorf("expected QPS override only, got %+v", got)
+	}
+	plain := ContextRef{SecretNamespace: "ns", SecretName: "kc", SecretKey: "kubeconfig"}
+	if ref.cacheKey() == plain.cacheKey() {
+		t.Error("rate limit overrides must not share a cache key with the default client")
+	}
+
+	agent := ContextRef{SecretNamespace: "ns", SecretName: "kc", SecretKey: "kubeconfig", UserAgent: "aqua-service-controller/bulk"}
+	restConfig := &rest.Config{}
+	ApplyClientSettings(restConfig, agent.clientSettings(defaults))
+	if restConfig.UserAgent != "aqua-service-controller/bulk" || restConfig.QPS != DefaultQPS {
+		t.Errorf("expected the user agent override only, got %q at %v QPS", restConfig.UserAgent, restConfig.QPS)
+	}
+	if agent.cacheKey() == plain.cacheKey() {
+		t.Error("user agent overrides must not share a cache key with the default client")
+	}
+}
+
+func TestGetClientWithToken(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sa-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"30","gitVersion":"v1.30.0"}`))
+	}))
+	defer srv.Close()
+
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	local := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "dest-sa"},
+		Data:       map[string][]byte{"token": []byte("sa-token\n"), "ca.crt": caBundle},
+	}).Build()
+	m := NewClientManager(runtime.NewScheme(), local)
+
+	ref := ContextRef{SecretNamespace: "ns", SecretName: "dest-sa", Server: srv.URL, CASecretName: "dest-sa"}
+	cc, err := m.GetClient(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("GetClient() error = %v", err)
+	}
+	if cc.RestConfig.Host != srv.URL || cc.RestConfig.BearerToken != "sa-token" {
+		t.Errorf("REST config host = %q, token = %q", cc.RestConfig.Host, cc.RestConfig.BearerToken)
+	}
+	version, err := cc.Clientset.Discovery().ServerVersion()
+	if err != nil || version.GitVersion != "v1.30.0" {
+		t.Errorf("ServerVersion() = %v, %v, want v1.30.0 over the CA-verified connection", version, err)
+	}
+
+	kubeconfigRef := ContextRef{SecretNamespace: "ns", SecretName: "dest-sa", SecretKey: "token"}
+	if ref.cacheKey() == kubeconfigRef.cacheKey() {
+		t.Error("token references must not share a cache key with kubeconfig references")
+	}
+
+	// A rate limit override bypasses the cached client
+	ref.CASecretKey = "missing"
+	ref.QPS = 1
+	if _, err := m.GetClient(context.Background(), ref); err == nil {
+		t.Error("expected an error for a missing CA bundle key")
+	}
+}
+
+type stubReader struct {
+	err   error
+	gets  int
+	lists int
+}
+
+func (r *stubReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	r.gets++
+	return r.err
+}
+
+func (r *stubReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	r.lists++
+	return r.err
+}
+
+func TestFallbackReader(t *testing.T) {
+	tests := []struct {
+		name      string
+		cacheErr  error
+		wantLive  int
+		wantError bool
+	}{
+		{name: "served from cache", cacheErr: nil, wantLive: 0},
+		{name: "type not cached", cacheErr: &cache.ErrResourceNotCached{}, wantLive: 1},
+		{name: "not found in cache", cacheErr: apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "web-0"), wantLive: 0, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cached := &stubReader{err: tt.cacheErr}
+			live := &stubReader{}
+			r := &fallbackReader{cache: cached, live: live}
+
+			err := r.Get(context.Background(), client.ObjectKey{Name: "web-0"}, &corev1.Pod{})
+			if (err != nil) != tt.wantError {
+				t.Errorf("Get() error = %v, wantError %v", err, tt.wantError)
+			}
+			if live.gets != tt.wantLive {
+				t.Errorf("live Get calls = %d, want %d", live.gets, tt.wantLive)
+			}
+
+			_ = r.List(context.Background(), &corev1.PodList{})
+			if live.lists != tt.wantLive {
+				t.Errorf("live List calls = %d, want %d", live.lists, tt.wantLive)
+			}
+		})
+	}
+}
+
+func TestReaderWithoutCache(t *testing.T) {
+	live := fake.NewClientBuilder().Build()
+	cc := &ClusterClient{Client: live, caches: &clusterCaches{caches: make(map[string]*namespaceCache)}}
+	if _, cached := cc.Reader("default").(*fallbackReader); cached {
+		t.Error("expected live client when no cache has been acquired")
+	}
+}
+
+func TestAcquireCacheWaitsForSync(t *testing.T) {
+	live := fake.NewClientBuilder().Build()
+	pending := &namespaceCache{owners: map[string]struct{}{"a": {}}, synced: make(chan struct{})}
+	cc := &ClusterClient{Client: live, caches: &clusterCaches{caches: map[string]*namespaceCache{"src": pending}}}
+
+	done := make(chan error)
+	go func() { done <- cc.AcquireCache(context.Background(), "b", "src") }()
+
+	// The sync in progress holds no lock, so other callers go on; reads
+	// stay live until it finishes
+	cc.ReleaseCache("c")
+	if _, cached := cc.Reader("src").(*fallbackReader); cached {
+		t.Error("expected live client while the cache syncs")
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("AcquireCache() = %v before the cache synced", err)
+	default:
+	}
+
+	close(pending.synced)
+	if err := <-done; err != nil {
+		t.Fatalf("AcquireCache() error = %v", err)
+	}
+	if _, ok := pending.owners["b"]; !ok {
+		t.Errorf("owners = %v, want b registered", pending.owners)
+	}
+
+	// A failed sync fails every caller that waited for it
+	failed := &namespaceCache{owners: map[string]struct{}{}, synced: make(chan struct{}), err: errors.New("sync failed")}
+	close(failed.synced)
+	cc.caches.caches["dest"] = failed
+	if err := cc.AcquireCache(context.Background(), "b", "dest"); err == nil || err.Error() != "sync failed" {
+		t.Errorf("AcquireCache() error = %v, want the sync failure", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cc.caches.caches["other"] = &namespaceCache{owners: map[string]struct{}{}, synced: make(chan struct{})}
+	if err := cc.AcquireCache(ctx, "b", "other"); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("AcquireCache() error = %v, want a timeout", err)
+	}
+}
+
+func TestGetSplitClient(t *testing.T) {
+	var tokens []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"30","gitVersion":"v1.30.0"}`))
+	}))
+	defer srv.Close()
+
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	local := fake.NewClientBuilder().WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "actor"},
+			Data:       map[string][]byte{"token": []byte("actor-token"), "ca.crt": caBundle},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "reader"},
+			Data:       map[string][]byte{"token": []byte("reader-token"), "ca.crt": caBundle},
+		},
+	).Build()
+	m := NewClientManager(runtime.NewScheme(), local)
+
+	ref := ContextRef{SecretNamespace: "ns", SecretName: "actor", Server: srv.URL, CASecretName: "actor"}
+	readerRef := ref
+	readerRef.SecretName = "reader"
+	ref.Reader = &readerRef
+	cc, err := m.GetClient(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("GetClient() error = %v", err)
+	}
+	if cc.RestConfig.BearerToken != "actor-token" || cc.reader == nil || cc.reader.RestConfig.BearerToken != "reader-token" {
+		t.Errorf("split client sends changes as %q and reads as %v, want the actor's and the reader's tokens", cc.RestConfig.BearerToken, cc.reader)
+	}
+	if len(m.clientCache) != 2 {
+		t.Errorf("cached %d clients, want the actor's and the reader's", len(m.clientCache))
+	}
+	if err := m.TestConnection(context.Background(), cc); err != nil {
+		t.Fatalf("TestConnection() error = %v", err)
+	}
+	if len(tokens) != 2 || tokens[0] != "Bearer actor-token" || tokens[1] != "Bearer reader-token" {
+		t.Errorf("TestConnection() sent %v, want both identities checked", tokens)
+	}
+
+	// An InvalidateCache of either Secret drops that identity's client
+	m.InvalidateCache("ns", "reader", "token")
+	if len(m.clientCache) != 1 {
+		t.Errorf("cached %d clients after invalidating the reader, want 1", len(m.clientCache))
+	}
+}
+
+func TestSplitClient(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0"}}
+	actor := fake.NewClientBuilder().Build()
+	reader := &stubReader{}
+	c := &splitClient{Client: actor, reader: reader}
+
+	_ = c.Get(context.Background(), client.ObjectKeyFromObject(pod), &corev1.Pod{})
+	_ = c.List(context.Background(), &corev1.PodList{})
+	if reader.gets != 1 || reader.lists != 1 {
+		t.Errorf("reader served %d gets and %d lists, want 1 and 1", reader.gets, reader.lists)
+	}
+	if err := c.Create(context.Background(), pod); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := actor.Get(context.Background(), client.ObjectKeyFromObject(pod), &corev1.Pod{}); err != nil {
+		t.Errorf("pod was not created with the actor's client: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package multicluster
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/clock"
+)
+
+// ConnectionInfo describes the clock and certificates of a cluster's API server
+type ConnectionInfo struct {
+	// ClockOffset is how far the API server's clock is ahead of the local clock
+	ClockOffset time.Duration
+
+	// ClockUncertainty bounds the error of ClockOffset: the Date header has
+	// second resolution and the request takes time to arrive
+	ClockUncertainty time.Duration
+
+	// ServingCertExpiry is the earliest expiry of the certificates the API
+	// server presented, zero without TLS
+	ServingCertExpiry time.Time
+
+	// ClientCertExpiry is when the kubeconfig's client certificate expires,
+	// zero when it authenticates otherwise
+	ClientCertExpiry time.Time
+}
+
+// InspectConnection requests the API server's /version and measures its
+// clock from the response's Date header against clk, and reads the expiry of
+// the serving and client certificates. Any response, even an authorization
+// failure, carries both.
+func InspectConnection(ctx context.Context, cc *ClusterClient, clk clock.PassiveClock) (*ConnectionInfo, error) {
+	httpClient, err := rest.HTTPClientFor(cc.currentConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	u, _, err := rest.DefaultServerUrlFor(cc.currentConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse server URL: %w", err)
+	}
+	u.Path = "/version"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sent := clk.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach API server: %w", err)
+	}
+	received := clk.Now()
+	resp.Body.Close()
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return nil, fmt.Errorf("API server response has no valid Date header: %w", err)
+	}
+	// The server stamped the Date somewhere within its second and the round trip
+	roundTrip := received.Sub(sent)
+	info := &ConnectionInfo{
+		ClockOffset:      date.Add(500 * time.Millisecond).Sub(sent.Add(roundTrip / 2)),
+		ClockUncertainty: 500*time.Millisecond + roundTrip/2,
+	}
+	if resp.TLS != nil {
+		for _, cert := range resp.TLS.PeerCertificates {
+			if info.ServingCertExpiry.IsZero() || cert.NotAfter.Before(info.ServingCertExpiry) {
+				info.ServingCertExpiry = cert.NotAfter
+			}
+		}
+	}
+	if info.ClientCertExpiry, err = clientCertExpiry(cc.currentConfig()); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// clientCertExpiry returns when the config's client certificate expires, or
+// zero when it has none
+func clientCertExpiry(config *rest.Config) (time.Time, error) {
+	config = rest.CopyConfig(config)
+	if err := rest.LoadTLSFiles(config); err != nil {
+		return time.Time{}, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	block, _ := pem.Decode(config.CertData)
+	if block == nil {
+		return time.Time{}, nil
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse client certificate: %w", err)
+	}
+	return cert.NotAfter, nil
+}
@@ -0,0 +1,45 @@
+package multicluster
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestInspectConnection(t *testing.T) {
+	serverTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", serverTime.Format(http.TimeFormat))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	cc := &ClusterClient{RestConfig: &rest.Config{
+		Host:            srv.URL,
+		TLSClientConfig: rest.TLSClientConfig{CAData: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})},
+	}}
+	clk := clocktesting.NewFakePassiveClock(serverTime.Add(-2 * time.Minute))
+
+	info, err := InspectConnection(context.Background(), cc, clk)
+	if err != nil {
+		t.Fatalf("InspectConnection() error = %v", err)
+	}
+	if want := 2*time.Minute + 500*time.Millisecond; info.ClockOffset != want {
+		t.Errorf("ClockOffset = %s, want %s", info.ClockOffset, want)
+	}
+	if info.ClockUncertainty != 500*time.Millisecond {
+		t.Errorf("ClockUncertainty = %s, want 500ms", info.ClockUncertainty)
+	}
+	if !info.ServingCertExpiry.Equal(srv.Certificate().NotAfter) {
+		t.Errorf("ServingCertExpiry = %s, want %s", info.ServingCertExpiry, srv.Certificate().NotAfter)
+	}
+	if !info.ClientCertExpiry.IsZero() {
+		t.Errorf("ClientCertExpiry = %s, want zero without a client certificate", info.ClientCertExpiry)
+	}
+}
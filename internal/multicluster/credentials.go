@@ -0,0 +1,148 @@
+package multicluster
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// credentialRecheckInterval is how long a cluster client waits before
+// reading its Secret again after the Secret still held the credentials the
+// API server rejected, so that credentials that are simply wrong do not turn
+// every request into a Secret read
+const credentialRecheckInterval = 10 * time.Second
+
+// credentialReloader reads a cluster's current credentials from their Secret
+// and returns a REST config for them
+type credentialReloader func(ctx context.Context) (*rest.Config, error)
+
+// credentialTransport sends requests with a cluster's current credentials.
+// When the API server rejects them with 401 Unauthorized, it reads the
+// credentials from their Secret again and, if they changed, rebuilds the
+// transport and retries the request once. Short-lived client certificates
+// and tokens that roll during a multi-hour migration then do not fail it.
+type credentialTransport struct {
+	reload credentialReloader
+	wrap   func(http.RoundTripper) http.RoundTripper
+	clock  clock.PassiveClock
+
+	mu        sync.Mutex
+	config    *rest.Config
+	current   http.RoundTripper
+	unchanged time.Time
+}
+
+// newCredentialTransport builds the transport for restConfig. A nil reload
+// never refreshes the credentials.
+func newCredentialTransport(restConfig *rest.Config, reload credentialReloader, clk clock.PassiveClock) (*credentialTransport, error) {
+	rt, err := rest.TransportFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &credentialTransport{
+		reload:  reload,
+		wrap:    restConfig.WrapTransport,
+		clock:   clk,
+		config:  restConfig,
+		current: rt,
+	}, nil
+}
+
+// restConfig returns the REST config of the current credentials
+func (t *credentialTransport) restConfig() *rest.Config {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.config
+}
+
+func (t *credentialTransport) transport() http.RoundTripper {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.current
+}
+
+func (t *credentialTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt := t.transport()
+	resp, err := rt.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || t.reload == nil {
+		return resp, err
+	}
+	// A body that cannot be read again cannot be sent again
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+	next := t.refresh(req.Context(), rt)
+	if next == nil {
+		return resp, nil
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return next.RoundTrip(retry)
+}
+
+// refresh returns the transport to retry a request rejected through failed
+// with: the current one when another request has already replaced failed,
+// or one built from the credentials in the Secret when they changed. It
+// returns nil when the credentials are unchanged or cannot be read.
+func (t *credentialTransport) refresh(ctx context.Context, failed http.RoundTripper) http.RoundTripper {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.current != failed {
+		return t.current
+	}
+	if !t.unchanged.IsZero() && t.clock.Since(t.unchanged) < credentialRecheckInterval {
+		return nil
+	}
+
+	logger := log.FromContext(ctx).WithValues("cluster", clusterName(t.config))
+	restConfig, err := t.reload(ctx)
+	if err != nil {
+		logger.Error(err, "Failed to reload cluster credentials after 401 Unauthorized")
+		t.unchanged = t.clock.Now()
+		return nil
+	}
+	if sameCredentials(t.config, restConfig) {
+		t.unchanged = t.clock.Now()
+		return nil
+	}
+	restConfig.Wrap(t.wrap)
+	rt, err := rest.TransportFor(restConfig)
+	if err != nil {
+		logger.Error(err, "Failed to build transport for reloaded cluster credentials")
+		t.unchanged = t.clock.Now()
+		return nil
+	}
+	logger.Info("Cluster credentials changed, retrying with the reloaded ones")
+	t.config, t.current, t.unchanged = restConfig, rt, time.Time{}
+	return rt
+}
+
+// sameCredentials reports whether two REST configs authenticate alike
+func sameCredentials(a, b *rest.Config) bool {
+	return a.Host == b.Host &&
+		a.BearerToken == b.BearerToken &&
+		a.BearerTokenFile == b.BearerTokenFile &&
+		a.Username == b.Username &&
+		a.Password == b.Password &&
+		a.CertFile == b.CertFile &&
+		a.KeyFile == b.KeyFile &&
+		bytes.Equal(a.CertData, b.CertData) &&
+		bytes.Equal(a.KeyData, b.KeyData) &&
+		bytes.Equal(a.CAData, b.CAData)
+}
@@ -0,0 +1,84 @@
+package multicluster
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCredentialRotation(t *testing.T) {
+	var accepted atomic.Value
+	accepted.Store("old-token")
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+accepted.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"30","gitVersion":"v1.30.0"}`))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "dest-sa"},
+		Data:       map[string][]byte{"token": []byte("old-token"), "ca.crt": caBundle},
+	}
+	local := fake.NewClientBuilder().WithObjects(secret).Build()
+	m := NewClientManager(runtime.NewScheme(), local)
+	cc, err := m.GetClient(ctx, ContextRef{SecretNamespace: "ns", SecretName: "dest-sa", Server: srv.URL, CASecretName: "dest-sa"})
+	if err != nil {
+		t.Fatalf("GetClient() error = %v", err)
+	}
+	clk := clocktesting.NewFakeClock(time.Now())
+	cc.credentials.clock = clk
+
+	if _, err := cc.Clientset.Discovery().ServerVersion(); err != nil {
+		t.Fatalf("ServerVersion() with the original token: %v", err)
+	}
+
+	// The token rolls: the API server only accepts the new one, which the Secret now holds
+	accepted.Store("new-token")
+	if err := local.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "dest-sa"}, secret); err != nil {
+		t.Fatal(err)
+	}
+	secret.Data["token"] = []byte("new-token")
+	if err := local.Update(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cc.Clientset.Discovery().ServerVersion(); err != nil {
+		t.Fatalf("ServerVersion() after the token rolled: %v, want the request retried with the new token", err)
+	}
+	if got := cc.currentConfig().BearerToken; got != "new-token" {
+		t.Errorf("current token = %q, want new-token", got)
+	}
+
+	// Credentials the Secret still holds are not retried, nor read again until the recheck interval passes
+	accepted.Store("revoked")
+	if _, err := cc.Clientset.Discovery().ServerVersion(); err == nil {
+		t.Fatal("ServerVersion() with a revoked token: want 401")
+	}
+	secret.Data["token"] = []byte("revoked")
+	if err := local.Update(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cc.Clientset.Discovery().ServerVersion(); err == nil {
+		t.Fatal("ServerVersion() within the recheck interval: want 401")
+	}
+	clk.Step(credentialRecheckInterval)
+	if _, err := cc.Clientset.Discovery().ServerVersion(); err != nil {
+		t.Errorf("ServerVersion() after the recheck interval: %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package multicluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// HealthWindow is how far back a cluster's API health is judged
+	HealthWindow = 5 * time.Minute
+
+	// DegradedMinRequests is the fewest requests in HealthWindow a cluster
+	// is judged on, so that a handful of failures on an idle client does not
+	// mark it degraded
+	DegradedMinRequests = 10
+
+	// DegradedErrorRate is the share of failed requests in HealthWindow at
+	// which a cluster is degraded
+	DegradedErrorRate = 0.5
+
+	// ThrottleClient marks requests delayed by the client-side rate limiter
+	ThrottleClient = "client"
+
+	// ThrottleServer marks requests the API server rejected with 429 Too Many Requests
+	ThrottleServer = "server"
+
+	// CodeError is the status code reported for requests that got no response
+	CodeError = "error"
+
+	// clientThrottleThreshold is the rate limiter wait counted as throttling;
+	// shorter waits are the token bucket's own overhead
+	clientThrottleThreshold = 10 * time.Millisecond
+
+	// healthBucketWidth is the resolution of the health window
+	healthBucketWidth = time.Minute
+)
+
+// APIObserver receives the outcome of every request to a remote API server,
+// keyed by the server's host
+type APIObserver interface {
+	// ObserveRequest records a request's status code, or CodeError, and latency
+	ObserveRequest(cluster, code string, latency time.Duration)
+
+	// ObserveError records a request that failed: no response or a 5xx
+	ObserveError(cluster string)
+
+	// ObserveThrottle records a request throttled by ThrottleClient or ThrottleServer
+	ObserveThrottle(cluster, source string)
+}
+
+// APIHealth summarizes a cluster's API requests over HealthWindow
+type APIHealth struct {
+	// Cluster is the API server's host
+	Cluster string
+
+	// Requests is the number of requests sent
+	Requests int
+
+	// Errors is the number of requests that got no response or a 5xx
+	Errors int
+
+	// Throttled is the number of requests throttled by the client or the server
+	Throttled int
+
+	// LastError describes the most recent failed request
+	LastError string
+}
+
+// Degraded reports whether the cluster failed at least DegradedErrorRate of
+// at least DegradedMinRequests requests
+func (h APIHealth) Degraded() bool {
+	return h.Requests >= DegradedMinRequests && float64(h.Errors) >= DegradedErrorRate*float64(h.Requests)
+}
+
+func (h APIHealth) String() string {
+	s := fmt.Sprintf("%d of %d requests failed and %d were throttled in the last %s", h.Errors, h.Requests, h.Throttled, HealthWindow)
+	if h.LastError != "" {
+		s += ", last error: " + h.LastError
+	}
+	return s
+}
+
+// healthBucket counts the requests that started within one healthBucketWidth
+type healthBucket struct {
+	start                       time.Time
+	requests, errors, throttled int
+}
+
+// apiHealth tracks a cluster's API requests, reporting each to an optional
+// observer and keeping HealthWindow of counts for Health
+type apiHealth struct {
+	cluster  string
+	observer APIObserver
+	clock    clock.PassiveClock
+
+	mu        sync.Mutex
+	buckets   []healthBucket
+	lastError string
+}
+
+func newAPIHealth(cluster string, observer APIObserver, clk clock.PassiveClock) *apiHealth {
+	return &apiHealth{cluster: cluster, observer: observer, clock: clk}
+}
+
+// clusterName identifies a cluster in metrics by its API server's host
+func clusterName(restConfig *rest.Config) string {
+	if u, err := url.Parse(restConfig.Host); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return restConfig.Host
+}
+
+// bucket returns the current bucket, dropping those older than HealthWindow.
+// The caller holds mu.
+func (h *apiHealth) bucket() *healthBucket {
+	now := h.clock.Now()
+	start := now.Truncate(healthBucketWidth)
+	kept := h.buckets[:0]
+	for _, b := range h.buckets {
+		if now.Sub(b.start) < HealthWindow {
+			kept = append(kept, b)
+		}
+	}
+	h.buckets = kept
+	if n := len(h.buckets); n == 0 || !h.buckets[n-1].start.Equal(start) {
+		h.buckets = append(h.buckets, healthBucket{start: start})
+	}
+	return &h.buckets[len(h.buckets)-1]
+}
+
+// observeResponse records the outcome of a request. Requests cancelled by
+// their caller say nothing about the cluster and are not recorded.
+func (h *apiHealth) observeResponse(req *http.Request, resp *http.Response, err error, latency time.Duration) {
+	if err != nil && (errors.Is(err, context.Canceled) || req.Context().Err() == context.Canceled) {
+		return
+	}
+
+	code, failure := CodeError, ""
+	switch {
+	case err != nil:
+		failure = fmt.Sprintf("%s %s: %v", req.Method, req.URL.Path, err)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		code = strconv.Itoa(resp.StatusCode)
+		failure = fmt.Sprintf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+	default:
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	throttled := resp != nil && resp.StatusCode == http.StatusTooManyRequests
+
+	h.mu.Lock()
+	b := h.bucket()
+	b.requests++
+	if failure != "" {
+		b.errors++
+		h.lastError = failure
+	}
+	if throttled {
+		b.throttled++
+	}
+	h.mu.Unlock()
+
+	if h.observer == nil {
+		return
+	}
+	h.observer.ObserveRequest(h.cluster, code, latency)
+	if failure != "" {
+		h.observer.ObserveError(h.cluster)
+	}
+	if throttled {
+		h.observer.ObserveThrottle(h.cluster, ThrottleServer)
+	}
+}
+
+// observeClientThrottle records a request the client-side rate limiter delayed
+func (h *apiHealth) observeClientThrottle() {
+	h.mu.Lock()
+	h.bucket().throttled++
+	h.mu.Unlock()
+
+	if h.observer != nil {
+		h.observer.ObserveThrottle(h.cluster, ThrottleClient)
+	}
+}
+
+// health summarizes the requests of the last HealthWindow
+func (h *apiHealth) health() APIHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.bucket()
+	summary := APIHealth{Cluster: h.cluster}
+	for _, b := range h.buckets {
+		summary.Requests += b.requests
+		summary.Errors += b.errors
+		summary.Throttled += b.throttled
+	}
+	if summary.Errors > 0 {
+		summary.LastError = h.lastError
+	}
+	return summary
+}
+
+// wrapTransport observes every request sent through rt
+func (h *apiHealth) wrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &observedTransport{next: rt, health: h}
+}
+
+// rateLimited returns a copy of restConfig whose rate limiter reports
+// throttled requests. Each client needs its own copy: a shared limiter would
+// split the QPS between them.
+func (h *apiHealth) rateLimited(restConfig *rest.Config) *rest.Config {
+	restConfig = rest.CopyConfig(restConfig)
+	if restConfig.RateLimiter == nil && restConfig.QPS > 0 {
+		restConfig.RateLimiter = &observedRateLimiter{
+			RateLimiter: flowcontrol.NewTokenBucketRateLimiter(restConfig.QPS, restConfig.Burst),
+			health:      h,
+		}
+	}
+	return restConfig
+}
+
+// observedTransport records the outcome and latency of each request
+type observedTransport struct {
+	next   http.RoundTripper
+	health *apiHealth
+}
+
+func (t *observedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := t.health.clock.Now()
+	resp, err := t.next.RoundTrip(req)
+	t.health.observeResponse(req, resp, err, t.health.clock.Since(start))
+	return resp, err
+}
+
+// observedRateLimiter records the requests its rate limiter delays
+type observedRateLimiter struct {
+	flowcontrol.RateLimiter
+	health *apiHealth
+}
+
+func (l *observedRateLimiter) Wait(ctx context.Context) error {
+	start := l.health.clock.Now()
+	err := l.RateLimiter.Wait(ctx)
+	if l.health.clock.Since(start) >= clientThrottleThreshold {
+		l.health.observeClientThrottle()
+	}
+	return err
+}
@@ -0,0 +1,186 @@
+package multicluster
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+type recordingObserver struct {
+	codes     []string
+	errors    int
+	throttles []string
+}
+
+func (o *recordingObserver) ObserveRequest(cluster, code string, latency time.Duration) {
+	o.codes = append(o.codes, code)
+}
+
+func (o *recordingObserver) ObserveError(cluster string) { o.errors++ }
+
+func (o *recordingObserver) ObserveThrottle(cluster, source string) {
+	o.throttles = append(o.throttles, source)
+}
+
+func TestAPIHealthDegraded(t *testing.T) {
+	tests := []struct {
+		name     string
+		health   APIHealth
+		degraded bool
+	}{
+		{"idle", APIHealth{}, false},
+		{"too few requests", APIHealth{Requests: 5, Errors: 5}, false},
+		{"mostly failing", APIHealth{Requests: 20, Errors: 12}, true},
+		{"half failing", APIHealth{Requests: 10, Errors: 5}, true},
+		{"occasional errors", APIHealth{Requests: 100, Errors: 10}, false},
+		{"throttled but succeeding", APIHealth{Requests: 100, Throttled: 100}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.health.Degraded(); got != tt.degraded {
+				t.Errorf("Degraded() = %v, want %v", got, tt.degraded)
+			}
+		})
+	}
+}
+
+func TestObservedTransport(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	h := newAPIHealth(clusterName(&rest.Config{Host: server.URL}), observer, clocktesting.NewFakeClock(time.Now()))
+	httpClient := &http.Client{Transport: h.wrapTransport(http.DefaultTransport)}
+
+	for _, status = range []int{http.StatusOK, http.StatusNotFound, http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		resp, err := httpClient.Get(server.URL + "/api/v1/pods")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if _, err := httpClient.Get("http://127.0.0.1:1/api"); err == nil {
+		t.Fatal("request to a closed port succeeded")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := httpClient.Do(req); err == nil {
+		t.Fatal("cancelled request succeeded")
+	}
+
+	if got, want := strings.Join(observer.codes, ","), "200,404,429,503,error"; got != want {
+		t.Errorf("observed codes %s, want %s", got, want)
+	}
+	if observer.errors != 2 {
+		t.Errorf("observed %d errors, want 2 (503 and no response)", observer.errors)
+	}
+	if len(observer.throttles) != 1 || observer.throttles[0] != ThrottleServer {
+		t.Errorf("observed throttles %v, want [%s]", observer.throttles, ThrottleServer)
+	}
+
+	health := h.health()
+	if health.Requests != 5 || health.Errors != 2 || health.Throttled != 1 {
+		t.Errorf("Health() = %+v, want 5 requests, 2 errors, 1 throttled", health)
+	}
+	if !strings.HasPrefix(health.LastError, "GET /api:") {
+		t.Errorf("LastError = %q, want the failed request", health.LastError)
+	}
+	if health.Cluster != strings.TrimPrefix(server.URL, "http://") {
+		t.Errorf("Cluster = %q, want the server's host", health.Cluster)
+	}
+}
+
+func TestAPIHealthWindow(t *testing.T) {
+	clk := clocktesting.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC))
+	h := newAPIHealth("api.example.com", nil, clk)
+	req := httptest.NewRequest(http.MethodGet, "/api", nil)
+
+	for i := 0; i < 10; i++ {
+		h.observeResponse(req, nil, errors.New("connection refused"), time.Millisecond)
+	}
+	if health := h.health(); !health.Degraded() {
+		t.Fatalf("Health() = %+v, want degraded", health)
+	}
+
+	clk.Step(3 * time.Minute)
+	for i := 0; i < 10; i++ {
+		h.observeResponse(req, &http.Response{StatusCode: http.StatusOK}, nil, time.Millisecond)
+	}
+	if health := h.health(); health.Requests != 20 || !health.Degraded() {
+		t.Errorf("Health() = %+v, want 20 requests, still degraded", health)
+	}
+
+	clk.Step(3 * time.Minute)
+	health := h.health()
+	if health.Requests != 10 || health.Errors != 0 || health.Degraded() {
+		t.Errorf("Health() = %+v, want the failures aged out", health)
+	}
+	if health.LastError != "" {
+		t.Errorf("LastError = %q, want none once the failures aged out", health.LastError)
+	}
+}
+
+// slowLimiter is a rate limiter whose Wait advances a fake clock
+type slowLimiter struct {
+	clk  *clocktesting.FakeClock
+	wait time.Duration
+}
+
+func (l *slowLimiter) TryAccept() bool { return true }
+func (l *slowLimiter) Accept()         {}
+func (l *slowLimiter) Stop()           {}
+func (l *slowLimiter) QPS() float32    { return 1 }
+
+func (l *slowLimiter) Wait(ctx context.Context) error {
+	l.clk.Step(l.wait)
+	return nil
+}
+
+func TestObservedRateLimiter(t *testing.T) {
+	clk := clocktesting.NewFakeClock(time.Now())
+	observer := &recordingObserver{}
+	h := newAPIHealth("api.example.com", observer, clk)
+	limiter := &slowLimiter{clk: clk}
+	l := &observedRateLimiter{RateLimiter: limiter, health: h}
+
+	for _, wait := range []time.Duration{0, time.Millisecond, time.Second} {
+		limiter.wait = wait
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(observer.throttles) != 1 || observer.throttles[0] != ThrottleClient {
+		t.Errorf("observed throttles %v, want [%s]", observer.throttles, ThrottleClient)
+	}
+	if health := h.health(); health.Throttled != 1 {
+		t.Errorf("Throttled = %d, want 1", health.Throttled)
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	h := newAPIHealth("api.example.com", nil, clocktesting.NewFakeClock(time.Now()))
+	restConfig := &rest.Config{Host: "https://api.example.com", QPS: 50, Burst: 100}
+
+	a, b := h.rateLimited(restConfig), h.rateLimited(restConfig)
+	if _, ok := a.RateLimiter.(*observedRateLimiter); !ok {
+		t.Fatalf("RateLimiter = %T, want an observed limiter", a.RateLimiter)
+	}
+	if a.RateLimiter == b.RateLimiter {
+		t.Error("clients share a rate limiter, splitting the QPS between them")
+	}
+	if restConfig.RateLimiter != nil {
+		t.Error("rateLimited modified the original config")
+	}
+}
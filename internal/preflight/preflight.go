@@ -0,0 +1,265 @@
+// Package preflight runs pre-flight checks supplied by the organization
+// operating the controller, such as "the CMDB approves the destination
+// cluster", as an executable or an HTTP endpoint.
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultTimeout bounds each external check so a hung one cannot hold up pre-flight
+const DefaultTimeout = 30 * time.Second
+
+// maxOutput is how much of a check's output is kept for its failure message
+const maxOutput = 1024
+
+// Severity decides what a failed check does to the migration
+type Severity string
+
+const (
+	// SeverityError fails the migration
+	SeverityError Severity = "Error"
+
+	// SeverityWarning records the failure in the history and report, and
+	// lets the migration continue
+	SeverityWarning Severity = "Warning"
+)
+
+// Request describes the migration a check is asked about. Exec checks read
+// it as JSON on stdin; HTTP checks receive it as a JSON POST body.
+type Request struct {
+	// Check is the name of the check being run
+	Check string `json:"check"`
+
+	// Namespace and Name identify the StatefulSetMigration
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// SourceCluster and DestCluster are the clusters' API server URLs
+	SourceCluster string `json:"sourceCluster"`
+	DestCluster   string `json:"destCluster"`
+
+	SourceNamespace string `json:"sourceNamespace"`
+	DestNamespace   string `json:"destNamespace"`
+	StatefulSet     string `json:"statefulSet"`
+	Replicas        int    `json:"replicas"`
+
+	// VolumeIDs are the EBS volumes the migration will move
+	VolumeIDs []string `json:"volumeIDs,omitempty"`
+}
+
+// Response is what an HTTP check may answer with. A 2xx response passes
+// unless it is a Response with allowed false.
+type Response struct {
+	// Allowed is false to fail the check
+	Allowed *bool `json:"allowed,omitempty"`
+
+	// Message explains the decision
+	Message string `json:"message,omitempty"`
+}
+
+// Config lists the external checks, as read from the controller's
+// --preflight-checks-config file
+type Config struct {
+	Checks []Check `json:"checks"`
+}
+
+// Check is an external pre-flight check. Exactly one of Exec or HTTP is set.
+type Check struct {
+	// Name identifies the check in history entries and failure messages
+	Name string `json:"name"`
+
+	// Severity is Error (default) or Warning
+	Severity Severity `json:"severity,omitempty"`
+
+	// Exec runs a command, which passes by exiting 0
+	Exec *ExecCheck `json:"exec,omitempty"`
+
+	// HTTP posts to an endpoint, which passes by answering 2xx
+	HTTP *HTTPCheck `json:"http,omitempty"`
+
+	// Timeout bounds the check (default: 30s)
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// ExecCheck runs a command in the controller's container. The Request is
+// written to its stdin; its output becomes the failure message.
+type ExecCheck struct {
+	Command []string `json:"command"`
+}
+
+// HTTPCheck posts the Request to URL. A non-2xx response, or a Response
+// with allowed false, fails the check.
+type HTTPCheck struct {
+	URL string `json:"url"`
+
+	// Headers are added to the request, e.g. an Authorization header
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// LoadConfig reads and validates a YAML or JSON list of external checks
+func LoadConfig(path string) ([]Check, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pre-flight checks config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse pre-flight checks config %s: %w", path, err)
+	}
+	names := map[string]bool{}
+	for i := range cfg.Checks {
+		check := &cfg.Checks[i]
+		if err := check.validate(); err != nil {
+			return nil, fmt.Errorf("pre-flight check %d (%q): %w", i, check.Name, err)
+		}
+		if names[check.Name] {
+			return nil, fmt.Errorf("pre-flight check %q is defined twice", check.Name)
+		}
+		names[check.Name] = true
+		if check.Severity == "" {
+			check.Severity = SeverityError
+		}
+	}
+	return cfg.Checks, nil
+}
+
+// validate reports a check that cannot run
+func (c *Check) validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	switch c.Severity {
+	case "", SeverityError, SeverityWarning:
+	default:
+		return fmt.Errorf("severity %q must be %s or %s", c.Severity, SeverityError, SeverityWarning)
+	}
+	switch {
+	case (c.Exec == nil) == (c.HTTP == nil):
+		return errors.New("exactly one of exec or http is required")
+	case c.Exec != nil && len(c.Exec.Command) == 0:
+		return errors.New("exec.command is required")
+	case c.HTTP != nil:
+		u, err := url.Parse(c.HTTP.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("http.url %q must be an http or https URL", c.HTTP.URL)
+		}
+	}
+	return nil
+}
+
+// timeout returns the time the check may take
+func (c *Check) timeout() time.Duration {
+	if c.Timeout != nil && c.Timeout.Duration > 0 {
+		return c.Timeout.Duration
+	}
+	return DefaultTimeout
+}
+
+// Run runs the check against req and returns why it failed, or nil
+func (c *Check) Run(ctx context.Context, req Request) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	req.Check = c.Name
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	if c.Exec != nil {
+		return c.runExec(ctx, body)
+	}
+	return c.runHTTP(ctx, body)
+}
+
+// runExec runs the command with body on stdin
+func (c *Check) runExec(ctx context.Context, body []byte) error {
+	cmd := exec.CommandContext(ctx, c.Exec.Command[0], c.Exec.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(body)
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("timed out after %s", c.timeout())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if message := truncate(output); message != "" {
+			return fmt.Errorf("exited with code %d: %s", exitErr.ExitCode(), message)
+		}
+		return fmt.Errorf("exited with code %d", exitErr.ExitCode())
+	}
+	return fmt.Errorf("failed to run %s: %w", c.Exec.Command[0], err)
+}
+
+// runHTTP posts body to the endpoint
+func (c *Check) runHTTP(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.HTTP.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range c.HTTP.Headers {
+		req.Header.Set(key, value)
+	}
+	client := &http.Client{Timeout: c.timeout()}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var answer Response
+	decoded := len(bytes.TrimSpace(data)) > 0 && json.Unmarshal(data, &answer) == nil
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := truncate(data)
+		if decoded && answer.Message != "" {
+			message = answer.Message
+		}
+		if message != "" {
+			return fmt.Errorf("endpoint returned %s: %s", resp.Status, message)
+		}
+		return fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	if decoded && answer.Allowed != nil && !*answer.Allowed {
+		if answer.Message != "" {
+			return errors.New(answer.Message)
+		}
+		return errors.New("endpoint did not allow the migration")
+	}
+	return nil
+}
+
+// truncate returns the tail of a check's output, which usually holds its
+// verdict, cut at the start of a character so the message stays valid UTF-8
+func truncate(output []byte) string {
+	message := strings.TrimSpace(string(output))
+	if len(message) > maxOutput {
+		start := len(message) - maxOutput
+		for start < len(message) && !utf8.RuneStart(message[start]) {
+			start++
+		}
+		message = "..." + message[start:]
+	}
+	return message
+}
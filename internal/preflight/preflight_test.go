@@ -0,0 +1,131 @@
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{name: "exec and http checks", config: `
+checks:
+  - name: cmdb-approval
+    http:
+      url: https://cmdb.example.com/approve
+    timeout: 10s
+  - name: change-freeze
+    severity: Warning
+    exec:
+      command: ["/checks/change-freeze.sh"]
+`},
+		{name: "neither exec nor http", config: "checks:\n  - name: empty\n", wantErr: "exactly one of exec or http"},
+		{name: "both exec and http", config: `
+checks:
+  - name: both
+    exec: {command: [/bin/true]}
+    http: {url: https://example.com}
+`, wantErr: "exactly one of exec or http"},
+		{name: "unknown severity", config: "checks:\n  - name: x\n    severity: Fatal\n    exec: {command: [/bin/true]}\n", wantErr: "severity"},
+		{name: "not a URL", config: "checks:\n  - name: x\n    http: {url: cmdb.example.com}\n", wantErr: "http or https URL"},
+		{name: "duplicate name", config: "checks:\n  - name: x\n    exec: {command: [a]}\n  - name: x\n    exec: {command: [b]}\n", wantErr: "defined twice"},
+		{name: "unknown field", config: "checks:\n  - name: x\n    exec: {cmd: [a]}\n", wantErr: "unknown field"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "checks.yaml")
+		if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		checks, err := LoadConfig(path)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: LoadConfig() error = %v, want it to contain %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: LoadConfig() error = %v", tt.name, err)
+		}
+		if len(checks) != 2 || checks[0].Severity != SeverityError || checks[1].Severity != SeverityWarning {
+			t.Errorf("%s: LoadConfig() = %+v, want the first check defaulted to Error", tt.name, checks)
+		}
+	}
+}
+
+func TestRunExec(t *testing.T) {
+	req := Request{Name: "web-migration", DestCluster: "https://prod-east.example.com"}
+	pass := &Check{Name: "approved", Exec: &ExecCheck{Command: []string{"sh", "-c", `grep -q '"destCluster":"https://prod-east.example.com"'`}}}
+	if err := pass.Run(context.Background(), req); err != nil {
+		t.Errorf("Run() error = %v, want the check to read the request on stdin", err)
+	}
+
+	fail := &Check{Name: "denied", Exec: &ExecCheck{Command: []string{"sh", "-c", "echo 'prod-east is not approved in the CMDB'; exit 3"}}}
+	err := fail.Run(context.Background(), req)
+	if err == nil || err.Error() != "exited with code 3: prod-east is not approved in the CMDB" {
+		t.Errorf("Run() error = %v, want the exit code and output", err)
+	}
+}
+
+func TestRunHTTP(t *testing.T) {
+	var got Request
+	status, body := http.StatusOK, ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Authorization = %q, want the configured header", req.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(req.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	check := &Check{Name: "cmdb", HTTP: &HTTPCheck{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}}
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "empty 200", status: http.StatusOK},
+		{name: "allowed", status: http.StatusOK, body: `{"allowed": true}`},
+		{name: "denied", status: http.StatusOK, body: `{"allowed": false, "message": "change CHG-1 is not approved"}`, wantErr: "change CHG-1 is not approved"},
+		{name: "server error", status: http.StatusServiceUnavailable, body: "maintenance", wantErr: "503 Service Unavailable: maintenance"},
+	}
+	for _, tt := range tests {
+		status, body = tt.status, tt.body
+		err := check.Run(context.Background(), Request{Name: "web-migration", StatefulSet: "web"})
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: Run() error = %v, want none", tt.name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: Run() error = %v, want it to contain %q", tt.name, err, tt.wantErr)
+		}
+		if got.Check != "cmdb" || got.StatefulSet != "web" {
+			t.Errorf("%s: endpoint received %+v, want the check name and migration", tt.name, got)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	// Each "é" is two bytes, so the cut falls inside one unless it moves
+	output := "a" + strings.Repeat("é", maxOutput) + "b"
+	got := truncate([]byte(output))
+	if !utf8.ValidString(got) || !strings.HasPrefix(got, "...é") || len(got) > len("...")+maxOutput {
+		t.Errorf("truncate() = %q..., want valid UTF-8 of at most %d bytes after the ellipsis", got[:8], maxOutput)
+	}
+	if got := truncate([]byte("  denied\n")); got != "denied" {
+		t.Errorf("truncate() = %q, want the trimmed output", got)
+	}
+}
@@ -0,0 +1,309 @@
+// Package telemetry sends anonymized statistics about finished migrations to
+// an endpoint the operator opts in to, so platform teams can see how
+// migrations fare across clusters and where they spend their time. The
+// migrations finished in an interval are sent together as one Summary, so
+// no single migration can be picked out of a report.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// DefaultTimeout bounds each report so a slow endpoint cannot hold up
+	// the controller
+	DefaultTimeout = 5 * time.Second
+
+	// DefaultInterval is how often the summary is sent unless another
+	// interval is given
+	DefaultInterval = time.Hour
+)
+
+// Statistics are the anonymized facts about one finished migration. They
+// carry no cluster, namespace, object or volume names, account IDs or error
+// messages, and are only sent merged into a Summary.
+type Statistics struct {
+	// ID is a hash of the migration's UID, so a migration recorded twice in
+	// an interval is counted once
+	ID string `json:"id"`
+
+	// Result is the final phase: Completed, Failed, Aborted or Degraded
+	Result string `json:"result"`
+
+	// VolumeStrategies counts the volumes by the strategy they moved with:
+	// Reattach, SnapshotRestore or Transfer
+	VolumeStrategies map[string]int `json:"volumeStrategies,omitempty"`
+
+	// Features lists the optional spec features in use, such as velero or quiesce
+	Features []string `json:"features,omitempty"`
+
+	// Replicas is the number of pods the migration set out to move
+	Replicas int `json:"replicas"`
+
+	// PodsMoved is the number of pods running in the destination
+	PodsMoved int `json:"podsMoved"`
+
+	// DurationSeconds is the time from start to completion
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+
+	// MaxPodDowntimeSeconds and TotalPodDowntimeSeconds cover the pods whose
+	// downtime is known
+	MaxPodDowntimeSeconds   float64 `json:"maxPodDowntimeSeconds,omitempty"`
+	TotalPodDowntimeSeconds float64 `json:"totalPodDowntimeSeconds,omitempty"`
+
+	// StepSeconds approximates the time spent in each step, from the gap
+	// before each of its history entries
+	StepSeconds map[string]float64 `json:"stepSeconds,omitempty"`
+
+	// FailedPhase and FailedStep locate where a Failed or Aborted migration
+	// stopped, in place of its error message
+	FailedPhase string `json:"failedPhase,omitempty"`
+	FailedStep  string `json:"failedStep,omitempty"`
+}
+
+// Summary aggregates the Statistics of the migrations that finished in an
+// interval. It is what the Reporter sends.
+type Summary struct {
+	// Start and End bound the interval
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// Migrations is the number of migrations that finished
+	Migrations int `json:"migrations"`
+
+	// Results counts the migrations by final phase
+	Results map[string]int `json:"results"`
+
+	// VolumeStrategies counts the volumes by the strategy they moved with
+	VolumeStrategies map[string]int `json:"volumeStrategies,omitempty"`
+
+	// Features counts the migrations using each optional spec feature
+	Features map[string]int `json:"features,omitempty"`
+
+	// Replicas and PodsMoved are summed over the migrations
+	Replicas  int `json:"replicas"`
+	PodsMoved int `json:"podsMoved"`
+
+	// Duration covers the migrations whose start and completion are known
+	Duration Distribution `json:"durationSeconds"`
+
+	// MaxPodDowntime covers each migration's longest pod downtime
+	MaxPodDowntime Distribution `json:"maxPodDowntimeSeconds"`
+
+	// TotalPodDowntimeSeconds sums the downtime of the pods whose downtime
+	// is known
+	TotalPodDowntimeSeconds float64 `json:"totalPodDowntimeSeconds"`
+
+	// StepSeconds sums the approximate time spent in each step
+	StepSeconds map[string]float64 `json:"stepSeconds,omitempty"`
+
+	// Failures counts the Failed and Aborted migrations by the phase and
+	// step they stopped in, "<phase>/<step>"
+	Failures map[string]int `json:"failures,omitempty"`
+}
+
+// Distribution summarizes a number of seconds over several migrations
+type Distribution struct {
+	Count int     `json:"count"`
+	Sum   float64 `json:"sum"`
+	Max   float64 `json:"max"`
+}
+
+// add counts seconds in the distribution
+func (d *Distribution) add(seconds float64) {
+	d.Count++
+	d.Sum += seconds
+	d.Max = max(d.Max, seconds)
+}
+
+// merge adds other's counts to the distribution
+func (d *Distribution) merge(other Distribution) {
+	d.Count += other.Count
+	d.Sum += other.Sum
+	d.Max = max(d.Max, other.Max)
+}
+
+// add merges one migration's statistics into the summary
+func (s *Summary) add(stats *Statistics) {
+	s.Migrations++
+	s.Results[stats.Result]++
+	for strategy, volumes := range stats.VolumeStrategies {
+		s.VolumeStrategies[strategy] += volumes
+	}
+	for _, feature := range stats.Features {
+		s.Features[feature]++
+	}
+	s.Replicas += stats.Replicas
+	s.PodsMoved += stats.PodsMoved
+	if stats.DurationSeconds > 0 {
+		s.Duration.add(stats.DurationSeconds)
+	}
+	if stats.TotalPodDowntimeSeconds > 0 {
+		s.MaxPodDowntime.add(stats.MaxPodDowntimeSeconds)
+		s.TotalPodDowntimeSeconds += stats.TotalPodDowntimeSeconds
+	}
+	for step, seconds := range stats.StepSeconds {
+		s.StepSeconds[step] += seconds
+	}
+	if stats.FailedPhase != "" {
+		s.Failures[stats.FailedPhase+"/"+stats.FailedStep]++
+	}
+}
+
+// merge adds an earlier summary that failed to send into this one
+func (s *Summary) merge(earlier *Summary) {
+	s.Start = earlier.Start
+	s.Migrations += earlier.Migrations
+	for _, counts := range []struct{ into, from map[string]int }{
+		{s.Results, earlier.Results},
+		{s.VolumeStrategies, earlier.VolumeStrategies},
+		{s.Features, earlier.Features},
+		{s.Failures, earlier.Failures},
+	} {
+		for key, n := range counts.from {
+			counts.into[key] += n
+		}
+	}
+	s.Replicas += earlier.Replicas
+	s.PodsMoved += earlier.PodsMoved
+	s.Duration.merge(earlier.Duration)
+	s.MaxPodDowntime.merge(earlier.MaxPodDowntime)
+	s.TotalPodDowntimeSeconds += earlier.TotalPodDowntimeSeconds
+	for step, seconds := range earlier.StepSeconds {
+		s.StepSeconds[step] += seconds
+	}
+}
+
+func newSummary(start time.Time) *Summary {
+	return &Summary{
+		Start:            start,
+		Results:          map[string]int{},
+		VolumeStrategies: map[string]int{},
+		Features:         map[string]int{},
+		StepSeconds:      map[string]float64{},
+		Failures:         map[string]int{},
+	}
+}
+
+// Reporter merges the Statistics recorded by the controller and posts their
+// Summary as JSON to an endpoint every interval. It is a manager Runnable,
+// and sends what is left when it stops.
+type Reporter struct {
+	endpoint string
+	interval time.Duration
+	client   *http.Client
+
+	mu      sync.Mutex
+	pending *Summary
+	seen    map[string]bool
+}
+
+// NewReporter returns a Reporter for an http or https endpoint that sends
+// every interval (default: DefaultInterval)
+func NewReporter(endpoint string, interval time.Duration) (*Reporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse telemetry endpoint: %w", err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("telemetry endpoint %q must be an http or https URL", endpoint)
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Reporter{
+		endpoint: endpoint,
+		interval: interval,
+		client:   &http.Client{Timeout: DefaultTimeout},
+		pending:  newSummary(time.Now()),
+		seen:     map[string]bool{},
+	}, nil
+}
+
+// Record merges a finished migration's statistics into the summary of the
+// current interval
+func (r *Reporter) Record(stats *Statistics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.seen[stats.ID] {
+		return
+	}
+	r.seen[stats.ID] = true
+	r.pending.add(stats)
+}
+
+// Start sends the summary every interval until ctx is done
+func (r *Reporter) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	logger := ctrl.Log.WithName("telemetry")
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
+			defer cancel()
+			if err := r.Flush(flushCtx); err != nil {
+				logger.Error(err, "Failed to send telemetry")
+			}
+			return nil
+		case <-ticker.C:
+			if err := r.Flush(ctx); err != nil {
+				logger.Error(err, "Failed to send telemetry")
+			}
+		}
+	}
+}
+
+// Flush sends the summary of the migrations recorded since the last one,
+// if there are any. A summary that fails to send is merged into the next.
+func (r *Reporter) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	summary := r.pending
+	r.pending, r.seen = newSummary(time.Now()), map[string]bool{}
+	r.mu.Unlock()
+	if summary.Migrations == 0 {
+		return nil
+	}
+	summary.End = time.Now()
+
+	if err := r.send(ctx, summary); err != nil {
+		r.mu.Lock()
+		r.pending.merge(summary)
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// send posts a summary to the endpoint and fails on any response other than 2xx
+func (r *Reporter) send(ctx context.Context, summary *Summary) error {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewReporter(t *testing.T) {
+	for _, tt := range []struct {
+		endpoint string
+		wantErr  bool
+	}{
+		{endpoint: "https://telemetry.example.com/v1/migrations"},
+		{endpoint: "http://collector.monitoring:8080"},
+		{endpoint: "telemetry.example.com", wantErr: true},
+		{endpoint: "ftp://telemetry.example.com", wantErr: true},
+		{endpoint: "https://", wantErr: true},
+	} {
+		if _, err := NewReporter(tt.endpoint, 0); (err != nil) != tt.wantErr {
+			t.Errorf("NewReporter(%q) error = %v, wantErr %v", tt.endpoint, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFlush(t *testing.T) {
+	var got []Summary
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %s, want a JSON POST", req.Method, req.Header.Get("Content-Type"))
+		}
+		var summary Summary
+		if err := json.NewDecoder(req.Body).Decode(&summary); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		got = append(got, summary)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	reporter, err := NewReporter(server.URL, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := reporter.Flush(ctx); err != nil || len(got) != 0 {
+		t.Fatalf("Flush() with nothing recorded = %v, sent %d, want nothing sent", err, len(got))
+	}
+
+	reporter.Record(&Statistics{ID: "a", Result: "Completed", VolumeStrategies: map[string]int{"Reattach": 2}, Replicas: 2, PodsMoved: 2, DurationSeconds: 600})
+	reporter.Record(&Statistics{ID: "a", Result: "Completed", VolumeStrategies: map[string]int{"Reattach": 2}, Replicas: 2, PodsMoved: 2, DurationSeconds: 600})
+	status = http.StatusInternalServerError
+	if err := reporter.Flush(ctx); err == nil {
+		t.Fatal("Flush() succeeded on a 500 response, want an error")
+	}
+
+	// The failed summary is sent with the next one
+	reporter.Record(&Statistics{
+		ID: "b", Result: "Failed", VolumeStrategies: map[string]int{"Reattach": 1, "SnapshotRestore": 1}, Replicas: 3, PodsMoved: 1,
+		DurationSeconds: 300, FailedPhase: "MigratingPods", FailedStep: "DetachVolume",
+	})
+	status = http.StatusAccepted
+	if err := reporter.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	last := got[len(got)-1]
+	if last.Migrations != 2 || last.Results["Completed"] != 1 || last.Results["Failed"] != 1 {
+		t.Errorf("summary results = %d %v, want the duplicate counted once", last.Migrations, last.Results)
+	}
+	if last.VolumeStrategies["Reattach"] != 3 || last.VolumeStrategies["SnapshotRestore"] != 1 || last.Replicas != 5 || last.PodsMoved != 3 {
+		t.Errorf("summary = %+v, want 3 reattached and 1 restored volume of 5 replicas", last)
+	}
+	if last.Duration != (Distribution{Count: 2, Sum: 900, Max: 600}) || last.Failures["MigratingPods/DetachVolume"] != 1 {
+		t.Errorf("summary duration = %+v, failures = %v", last.Duration, last.Failures)
+	}
+
+	if err := reporter.Flush(ctx); err != nil || len(got) != 2 {
+		t.Errorf("Flush() after a sent summary = %v, sent %d, want nothing more sent", err, len(got))
+	}
+}
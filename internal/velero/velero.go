@@ -0,0 +1,290 @@
+// Package velero builds and inspects Velero backups and restores, which the
+// controller uses to replicate a namespace's other resources alongside a
+// StatefulSet. Objects are unstructured so Velero is not a build dependency.
+package velero
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultNamespace is the namespace Velero is installed in by default
+	DefaultNamespace = "velero"
+
+	// MigrationLabel marks backups and restores created for a migration
+	MigrationLabel = "migration.aqua.io/migration-uid"
+)
+
+var (
+	// BackupGVK is the Velero Backup kind
+	BackupGVK = schema.GroupVersionKind{Group: "velero.io", Version: "v1", Kind: "Backup"}
+
+	// RestoreGVK is the Velero Restore kind
+	RestoreGVK = schema.GroupVersionKind{Group: "velero.io", Version: "v1", Kind: "Restore"}
+)
+
+// ControllerManagedResources are always excluded from backups and restores
+// because the controller moves them itself, with the live EBS handoff
+var ControllerManagedResources = []string{
+	"statefulsets.apps",
+	"controllerrevisions.apps",
+	"persistentvolumeclaims",
+	"persistentvolumes",
+	"pods",
+	"volumesnapshots.snapshot.storage.k8s.io",
+}
+
+// BackupConfig describes the backup of a source namespace
+type BackupConfig struct {
+	// Name and Namespace identify the Backup object
+	Name      string
+	Namespace string
+
+	// SourceNamespace is the namespace being migrated
+	SourceNamespace string
+
+	// IncludedResources limits the backup to these resources (default: all)
+	IncludedResources []string
+
+	// ExcludedResources are skipped in addition to ControllerManagedResources
+	ExcludedResources []string
+
+	// LabelSelector limits the backup to matching resources
+	LabelSelector *metav1.LabelSelector
+
+	// StorageLocation is the BackupStorageLocation to write to (default: Velero's default)
+	StorageLocation string
+
+	// Labels are added to the Backup object
+	Labels map[string]string
+}
+
+// NewBackup returns a Velero Backup of a namespace's resources without
+// volume data, which stays on the EBS volumes the controller moves
+func NewBackup(cfg BackupConfig) (*unstructured.Unstructured, error) {
+	spec := map[string]any{
+		"includedNamespaces":       []any{cfg.SourceNamespace},
+		"excludedResources":        toAny(excludedResources(cfg.ExcludedResources)),
+		"snapshotVolumes":          false,
+		"defaultVolumesToFsBackup": false,
+		"includeClusterResources":  false,
+	}
+	if len(cfg.IncludedResources) > 0 {
+		spec["includedResources"] = toAny(cfg.IncludedResources)
+	}
+	if cfg.StorageLocation != "" {
+		spec["storageLocation"] = cfg.StorageLocation
+	}
+	if cfg.LabelSelector != nil {
+		selector, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cfg.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert label selector: %w", err)
+		}
+		spec["labelSelector"] = selector
+	}
+
+	backup := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	backup.SetGroupVersionKind(BackupGVK)
+	backup.SetNamespace(cfg.Namespace)
+	backup.SetName(cfg.Name)
+	backup.SetLabels(cfg.Labels)
+	return backup, nil
+}
+
+// RestoreConfig describes the restore of a backup into the destination namespace
+type RestoreConfig struct {
+	// Name and Namespace identify the Restore object
+	Name      string
+	Namespace string
+
+	// BackupName is the backup to restore
+	BackupName string
+
+	// SourceNamespace and DestNamespace map the backed-up namespace to its new name
+	SourceNamespace string
+	DestNamespace   string
+
+	// ExcludedResources are skipped in addition to ControllerManagedResources
+	ExcludedResources []string
+
+	// ResourceModifier names a ConfigMap of resource modifier rules, in the
+	// Restore's namespace, that Velero applies to restored objects
+	ResourceModifier string
+
+	// Labels are added to the Restore object
+	Labels map[string]string
+}
+
+// NewRestore returns a Velero Restore of a backup into the destination
+// namespace. Existing resources are left as they are.
+func NewRestore(cfg RestoreConfig) *unstructured.Unstructured {
+	spec := map[string]any{
+		"backupName":             cfg.BackupName,
+		"includedNamespaces":     []any{cfg.SourceNamespace},
+		"excludedResources":      toAny(excludedResources(cfg.ExcludedResources)),
+		"restorePVs":             false,
+		"existingResourcePolicy": "none",
+	}
+	if cfg.DestNamespace != cfg.SourceNamespace {
+		spec["namespaceMapping"] = map[string]any{cfg.SourceNamespace: cfg.DestNamespace}
+	}
+	if cfg.ResourceModifier != "" {
+		spec["resourceModifier"] = map[string]any{"kind": "ConfigMap", "name": cfg.ResourceModifier}
+	}
+
+	restore := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	restore.SetGroupVersionKind(RestoreGVK)
+	restore.SetNamespace(cfg.Namespace)
+	restore.SetName(cfg.Name)
+	restore.SetLabels(cfg.Labels)
+	return restore
+}
+
+// ResourceModifierKey is the ConfigMap key holding resource modifier rules
+const ResourceModifierKey = "rules.yaml"
+
+type resourceModifiers struct {
+	Version string                 `json:"version"`
+	Rules   []resourceModifierRule `json:"resourceModifierRules"`
+}
+
+type resourceModifierRule struct {
+	Conditions resourceModifierConditions `json:"conditions"`
+	Patches    []jsonPatch                `json:"patches"`
+}
+
+type resourceModifierConditions struct {
+	GroupResource     string `json:"groupResource"`
+	ResourceNameRegex string `json:"resourceNameRegex"`
+}
+
+// jsonPatch is a JSON patch operation; Velero parses Value as JSON
+type jsonPatch struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Value     string `json:"value"`
+}
+
+// NewServiceIPFamilyModifier returns a ConfigMap of resource modifier rules
+// that set a Service's ipFamilies and ipFamilyPolicy as it is restored, so a
+// Service backed up in a cluster of another IP family is valid in the
+// destination. Velero itself clears the cluster IPs of Services that are not
+// headless. Resource modifiers need Velero 1.12 or later.
+func NewServiceIPFamilyModifier(name, namespace, service string, families []corev1.IPFamily, policy corev1.IPFamilyPolicy, labels map[string]string) (*corev1.ConfigMap, error) {
+	familiesJSON, err := json.Marshal(families)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode IP families: %w", err)
+	}
+	rules, err := yaml.Marshal(resourceModifiers{
+		Version: "v1",
+		Rules: []resourceModifierRule{{
+			Conditions: resourceModifierConditions{
+				GroupResource:     "services",
+				ResourceNameRegex: "^" + regexp.QuoteMeta(service) + "$",
+			},
+			Patches: []jsonPatch{
+				{Operation: "replace", Path: "/spec/ipFamilies", Value: string(familiesJSON)},
+				{Operation: "replace", Path: "/spec/ipFamilyPolicy", Value: string(policy)},
+			},
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource modifier rules: %w", err)
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Data:       map[string]string{ResourceModifierKey: string(rules)},
+	}, nil
+}
+
+// excludedResources returns the user's exclusions plus ControllerManagedResources
+func excludedResources(extra []string) []string {
+	excluded := append([]string{}, ControllerManagedResources...)
+	for _, r := range extra {
+		if !contains(excluded, r) {
+			excluded = append(excluded, r)
+		}
+	}
+	return excluded
+}
+
+// Outcome summarizes a backup or restore phase
+type Outcome int
+
+const (
+	// OutcomeRunning means Velero has not finished
+	OutcomeRunning Outcome = iota
+	// OutcomeCompleted means every item was processed
+	OutcomeCompleted
+	// OutcomePartiallyFailed means Velero finished but some items failed
+	OutcomePartiallyFailed
+	// OutcomeFailed means the backup or restore failed
+	OutcomeFailed
+)
+
+// Phase returns status.phase of a backup or restore
+func Phase(obj *unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	return phase
+}
+
+// OutcomeOf classifies a backup or restore phase. Velero's plugin and
+// finalizing phases still count as running.
+func OutcomeOf(phase string) Outcome {
+	switch phase {
+	case "Completed":
+		return OutcomeCompleted
+	case "PartiallyFailed":
+		return OutcomePartiallyFailed
+	case "Failed", "FailedValidation":
+		return OutcomeFailed
+	default:
+		return OutcomeRunning
+	}
+}
+
+// FailureReason returns Velero's explanation of a failed backup or restore, if any
+func FailureReason(obj *unstructured.Unstructured) string {
+	if reason, _, _ := unstructured.NestedString(obj.Object, "status", "failureReason"); reason != "" {
+		return reason
+	}
+	errs, _, _ := unstructured.NestedStringSlice(obj.Object, "status", "validationErrors")
+	if len(errs) > 0 {
+		return strings.Join(errs, "; ")
+	}
+	var counts []string
+	for _, field := range []string{"errors", "warnings"} {
+		if n, found, _ := unstructured.NestedInt64(obj.Object, "status", field); found && n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, field))
+		}
+	}
+	return strings.Join(counts, ", ")
+}
+
+func toAny(items []string) []any {
+	out := make([]any, len(items))
+	for i, item := range items {
+		out[i] = item
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
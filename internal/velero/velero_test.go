@@ -0,0 +1,149 @@
+package velero
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewBackup(t *testing.T) {
+	backup, err := NewBackup(BackupConfig{
+		Name:              "web-1234abcd",
+		Namespace:         DefaultNamespace,
+		SourceNamespace:   "prod",
+		ExcludedResources: []string{"secrets", "pods"},
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		StorageLocation:   "shared",
+	})
+	if err != nil {
+		t.Fatalf("NewBackup() error = %v", err)
+	}
+
+	if backup.GroupVersionKind() != BackupGVK || backup.GetNamespace() != "velero" {
+		t.Errorf("backup is %s in %q", backup.GroupVersionKind(), backup.GetNamespace())
+	}
+	namespaces, _, _ := unstructured.NestedStringSlice(backup.Object, "spec", "includedNamespaces")
+	if len(namespaces) != 1 || namespaces[0] != "prod" {
+		t.Errorf("includedNamespaces = %v, want [prod]", namespaces)
+	}
+	excluded, _, _ := unstructured.NestedStringSlice(backup.Object, "spec", "excludedResources")
+	if len(excluded) != len(ControllerManagedResources)+1 || excluded[len(excluded)-1] != "secrets" {
+		t.Errorf("excludedResources = %v, want the controller's resources plus secrets once", excluded)
+	}
+	if snapshot, _, _ := unstructured.NestedBool(backup.Object, "spec", "snapshotVolumes"); snapshot {
+		t.Error("snapshotVolumes = true, want volumes left to the controller")
+	}
+	if app, _, _ := unstructured.NestedString(backup.Object, "spec", "labelSelector", "matchLabels", "app"); app != "web" {
+		t.Errorf("labelSelector app = %q, want web", app)
+	}
+	if _, found, _ := unstructured.NestedSlice(backup.Object, "spec", "includedResources"); found {
+		t.Error("includedResources set, want all resources by default")
+	}
+}
+
+func TestNewRestore(t *testing.T) {
+	tests := []struct {
+		name        string
+		dest        string
+		wantMapping bool
+	}{
+		{name: "renamed namespace", dest: "prod-new", wantMapping: true},
+		{name: "same namespace", dest: "prod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restore := NewRestore(RestoreConfig{
+				Name:            "web-1234abcd",
+				Namespace:       DefaultNamespace,
+				BackupName:      "web-1234abcd",
+				SourceNamespace: "prod",
+				DestNamespace:   tt.dest,
+			})
+			mapped, found, _ := unstructured.NestedString(restore.Object, "spec", "namespaceMapping", "prod")
+			if found != tt.wantMapping || (found && mapped != tt.dest) {
+				t.Errorf("namespaceMapping[prod] = %q (found %v), want %q", mapped, found, tt.dest)
+			}
+			if pvs, _, _ := unstructured.NestedBool(restore.Object, "spec", "restorePVs"); pvs {
+				t.Error("restorePVs = true, want volumes left to the controller")
+			}
+			if _, found, _ := unstructured.NestedMap(restore.Object, "spec", "resourceModifier"); found {
+				t.Error("resourceModifier set without one configured")
+			}
+		})
+	}
+}
+
+func TestServiceIPFamilyModifier(t *testing.T) {
+	cm, err := NewServiceIPFamilyModifier("web-1234abcd-ipfamilies", DefaultNamespace, "web.db",
+		[]corev1.IPFamily{corev1.IPv6Protocol}, corev1.IPFamilyPolicySingleStack, nil)
+	if err != nil {
+		t.Fatalf("NewServiceIPFamilyModifier() error = %v", err)
+	}
+	rules := cm.Data[ResourceModifierKey]
+	for _, want := range []string{
+		"version: v1",
+		"groupResource: services",
+		`resourceNameRegex: ^web\.db$`,
+		"path: /spec/ipFamilies",
+		`value: '["IPv6"]'`,
+		"value: SingleStack",
+	} {
+		if !strings.Contains(rules, want) {
+			t.Errorf("rules = %s\nwant them to contain %q", rules, want)
+		}
+	}
+
+	restore := NewRestore(RestoreConfig{Name: "web-1234abcd", BackupName: "web-1234abcd", SourceNamespace: "prod", DestNamespace: "prod", ResourceModifier: cm.Name})
+	if name, _, _ := unstructured.NestedString(restore.Object, "spec", "resourceModifier", "name"); name != cm.Name {
+		t.Errorf("resourceModifier.name = %q, want %s", name, cm.Name)
+	}
+}
+
+func TestOutcomeOf(t *testing.T) {
+	tests := []struct {
+		phase string
+		want  Outcome
+	}{
+		{"", OutcomeRunning},
+		{"New", OutcomeRunning},
+		{"InProgress", OutcomeRunning},
+		{"WaitingForPluginOperations", OutcomeRunning},
+		{"Finalizing", OutcomeRunning},
+		{"Completed", OutcomeCompleted},
+		{"PartiallyFailed", OutcomePartiallyFailed},
+		{"Failed", OutcomeFailed},
+		{"FailedValidation", OutcomeFailed},
+	}
+
+	for _, tt := range tests {
+		if got := OutcomeOf(tt.phase); got != tt.want {
+			t.Errorf("OutcomeOf(%q) = %v, want %v", tt.phase, got, tt.want)
+		}
+	}
+}
+
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		status map[string]any
+		want   string
+	}{
+		{name: "failure reason", status: map[string]any{"failureReason": "bucket not found"}, want: "bucket not found"},
+		{name: "validation errors", status: map[string]any{"validationErrors": []any{"a", "b"}}, want: "a; b"},
+		{name: "item counts", status: map[string]any{"errors": int64(2), "warnings": int64(1)}, want: "2 errors, 1 warnings"},
+		{name: "clean", status: map[string]any{"phase": "Completed"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]any{"status": tt.status}}
+			if got := FailureReason(obj); got != tt.want {
+				t.Errorf("FailureReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
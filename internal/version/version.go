@@ -0,0 +1,72 @@
+// Package version identifies the controller's release, so migrations can
+// record which controller started them and a controller can tell whether it
+// may resume a migration another release started.
+package version
+
+import (
+	"runtime/debug"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// Version is the controller's release, set at build time with
+//
+//	-ldflags "-X github.com/aqua-io/aqua-service-controller/internal/version.Version=v1.2.3"
+//
+// When it is not set, Get falls back to the module version Go recorded.
+var Version string
+
+// Dev is the version of a build whose release is not known
+const Dev = "dev"
+
+// Get returns the controller's version, or Dev when it is not known
+func Get() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return Dev
+}
+
+// Compatibility is whether a migration started by one controller version
+// can be resumed by another
+type Compatibility string
+
+const (
+	// Same versions always resume
+	Same Compatibility = "Same"
+
+	// Compatible versions share a major and minor version; patch releases
+	// do not change the steps a migration takes
+	Compatible Compatibility = "Compatible"
+
+	// Incompatible versions differ in their major or minor version, whose
+	// releases may add, remove or reorder steps
+	Incompatible Compatibility = "Incompatible"
+
+	// Unknown is returned when either version is not a semantic version,
+	// such as Dev, so compatibility cannot be decided
+	Unknown Compatibility = "Unknown"
+)
+
+// Compare returns whether a migration started by controller version started
+// can be resumed by controller version current
+func Compare(started, current string) Compatibility {
+	if started == current {
+		return Same
+	}
+	s, err := version.ParseSemantic(started)
+	if err != nil {
+		return Unknown
+	}
+	c, err := version.ParseSemantic(current)
+	if err != nil {
+		return Unknown
+	}
+	if s.Major() != c.Major() || s.Minor() != c.Minor() {
+		return Incompatible
+	}
+	return Compatible
+}
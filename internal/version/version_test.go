@@ -0,0 +1,27 @@
+package version
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		started, current string
+		want             Compatibility
+	}{
+		{"v1.4.2", "v1.4.2", Same},
+		{"v1.4.2", "v1.4.5", Compatible},
+		{"v1.4.5", "v1.4.2", Compatible},
+		{"v1.4.2", "v1.5.0", Incompatible},
+		{"v1.4.2", "v2.4.2", Incompatible},
+		{"v0.9.0", "v0.10.0", Incompatible},
+		{"v1.4.2-rc.1", "v1.4.2", Compatible},
+		{Dev, "v1.4.2", Unknown},
+		{"v1.4.2", Dev, Unknown},
+		{Dev, Dev, Same},
+	}
+
+	for _, tt := range tests {
+		if got := Compare(tt.started, tt.current); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %s, want %s", tt.started, tt.current, got, tt.want)
+		}
+	}
+}
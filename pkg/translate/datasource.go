@@ -0,0 +1,104 @@
+package translate
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AnnotationSourceDataSource records on a destination PVC the snapshot or
+// volume the source PVC was created from, when its data source is stripped
+const AnnotationSourceDataSource = "migration.aqua.io/source-data-source"
+
+// DataSourcePolicy decides what the translator does with a source PVC's
+// dataSource and dataSourceRef
+type DataSourcePolicy string
+
+const (
+	// DataSourceStrip drops the fields and records the origin in the
+	// AnnotationSourceDataSource annotation. The data is already on the
+	// volume, and the origin usually does not exist in the destination.
+	DataSourceStrip DataSourcePolicy = "Strip"
+
+	// DataSourcePreserve copies the fields, moving a reference to the
+	// source namespace to the destination namespace
+	DataSourcePreserve DataSourcePolicy = "Preserve"
+)
+
+// DataSource returns the object a PVC was populated from, or nil. The
+// dataSourceRef takes precedence, since it is the only one that can name
+// another namespace.
+func DataSource(pvc *corev1.PersistentVolumeClaim) *corev1.TypedObjectReference {
+	if ref := pvc.Spec.DataSourceRef; ref != nil {
+		return ref.DeepCopy()
+	}
+	if ref := pvc.Spec.DataSource; ref != nil {
+		return &corev1.TypedObjectReference{APIGroup: ref.APIGroup, Kind: ref.Kind, Name: ref.Name}
+	}
+	return nil
+}
+
+// DescribeDataSource formats a data source reference as Kind.group/name,
+// followed by its namespace when it names one
+func DescribeDataSource(ref *corev1.TypedObjectReference) string {
+	s := ref.Kind
+	if ref.APIGroup != nil && *ref.APIGroup != "" {
+		s += "." + *ref.APIGroup
+	}
+	s += "/" + ref.Name
+	if ref.Namespace != nil && *ref.Namespace != "" {
+		s += " in namespace " + *ref.Namespace
+	}
+	return s
+}
+
+// ValidateDataSource checks that a PVC's data source can be translated under
+// the policy. The API server rejects a PVC whose dataSource and dataSourceRef
+// disagree, and a data source in a third namespace cannot be preserved,
+// because the destination has no grant to read it.
+func ValidateDataSource(pvc *corev1.PersistentVolumeClaim, policy DataSourcePolicy) error {
+	switch policy {
+	case "", DataSourceStrip, DataSourcePreserve:
+	default:
+		return fmt.Errorf("unknown data source policy %q", policy)
+	}
+
+	ds, ref := pvc.Spec.DataSource, pvc.Spec.DataSourceRef
+	if ds != nil && ref != nil && (ref.Namespace == nil || *ref.Namespace == "") {
+		if ds.Kind != ref.Kind || ds.Name != ref.Name || stringValue(ds.APIGroup) != stringValue(ref.APIGroup) {
+			return fmt.Errorf("PVC %s/%s has a dataSource that does not match its dataSourceRef", pvc.Namespace, pvc.Name)
+		}
+	}
+
+	if policy == DataSourcePreserve && ref != nil && ref.Namespace != nil && *ref.Namespace != "" && *ref.Namespace != pvc.Namespace {
+		return fmt.Errorf("PVC %s/%s is populated from %s, which cannot be preserved; use the Strip data source policy",
+			pvc.Namespace, pvc.Name, DescribeDataSource(ref))
+	}
+	return nil
+}
+
+// translateDataSource applies the policy to the destination PVC
+func translateDataSource(sourcePVC, destPVC *corev1.PersistentVolumeClaim, policy DataSourcePolicy, destNamespace string) {
+	origin := DataSource(sourcePVC)
+	if origin == nil {
+		return
+	}
+
+	if policy != DataSourcePreserve {
+		if destPVC.Annotations == nil {
+			destPVC.Annotations = make(map[string]string)
+		}
+		destPVC.Annotations[AnnotationSourceDataSource] = DescribeDataSource(origin)
+		return
+	}
+
+	if ds := sourcePVC.Spec.DataSource; ds != nil {
+		destPVC.Spec.DataSource = ds.DeepCopy()
+	}
+	if ref := sourcePVC.Spec.DataSourceRef; ref != nil {
+		destPVC.Spec.DataSourceRef = ref.DeepCopy()
+		if ref.Namespace != nil && *ref.Namespace == sourcePVC.Namespace {
+			destPVC.Spec.DataSourceRef.Namespace = &destNamespace
+		}
+	}
+}
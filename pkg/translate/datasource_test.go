@@ -0,0 +1,107 @@
+package translate
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateDataSource(t *testing.T) {
+	snapshot := func(name string) *corev1.TypedLocalObjectReference {
+		return &corev1.TypedLocalObjectReference{APIGroup: ptr.To("snapshot.storage.k8s.io"), Kind: "VolumeSnapshot", Name: name}
+	}
+	snapshotRef := func(name string, namespace *string) *corev1.TypedObjectReference {
+		return &corev1.TypedObjectReference{APIGroup: ptr.To("snapshot.storage.k8s.io"), Kind: "VolumeSnapshot", Name: name, Namespace: namespace}
+	}
+
+	tests := []struct {
+		name    string
+		spec    corev1.PersistentVolumeClaimSpec
+		policy  DataSourcePolicy
+		wantErr string
+	}{
+		{name: "no data source", policy: DataSourcePreserve},
+		{name: "matching fields", spec: corev1.PersistentVolumeClaimSpec{DataSource: snapshot("snap-1"), DataSourceRef: snapshotRef("snap-1", nil)}},
+		{
+			name:    "fields disagree",
+			spec:    corev1.PersistentVolumeClaimSpec{DataSource: snapshot("snap-1"), DataSourceRef: snapshotRef("snap-2", nil)},
+			wantErr: "does not match its dataSourceRef",
+		},
+		{name: "same namespace preserved", spec: corev1.PersistentVolumeClaimSpec{DataSourceRef: snapshotRef("snap-1", ptr.To("prod"))}, policy: DataSourcePreserve},
+		{
+			name:    "other namespace preserved",
+			spec:    corev1.PersistentVolumeClaimSpec{DataSourceRef: snapshotRef("snap-1", ptr.To("backups"))},
+			policy:  DataSourcePreserve,
+			wantErr: "VolumeSnapshot.snapshot.storage.k8s.io/snap-1 in namespace backups, which cannot be preserved",
+		},
+		{name: "other namespace stripped", spec: corev1.PersistentVolumeClaimSpec{DataSourceRef: snapshotRef("snap-1", ptr.To("backups"))}, policy: DataSourceStrip},
+		{name: "unknown policy", policy: "Copy", wantErr: `unknown data source policy "Copy"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-0"}, Spec: tt.spec}
+			err := ValidateDataSource(pvc, tt.policy)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateDataSource() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateDataSource() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTranslateDataSource(t *testing.T) {
+	source := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-0"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			DataSource: &corev1.TypedLocalObjectReference{Kind: "PersistentVolumeClaim", Name: "data-web-seed"},
+			DataSourceRef: &corev1.TypedObjectReference{
+				APIGroup: ptr.To("snapshot.storage.k8s.io"), Kind: "VolumeSnapshot", Name: "snap-1", Namespace: ptr.To("prod"),
+			},
+		},
+	}
+
+	t.Run("strip", func(t *testing.T) {
+		dest := &corev1.PersistentVolumeClaim{}
+		translateDataSource(source, dest, "", "dest")
+		if dest.Spec.DataSource != nil || dest.Spec.DataSourceRef != nil {
+			t.Errorf("data source copied: %+v, %+v", dest.Spec.DataSource, dest.Spec.DataSourceRef)
+		}
+		if got, want := dest.Annotations[AnnotationSourceDataSource], "VolumeSnapshot.snapshot.storage.k8s.io/snap-1 in namespace prod"; got != want {
+			t.Errorf("annotation = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("preserve", func(t *testing.T) {
+		dest := &corev1.PersistentVolumeClaim{}
+		translateDataSource(source, dest, DataSourcePreserve, "dest")
+		if dest.Spec.DataSource == nil || dest.Spec.DataSource.Name != "data-web-seed" {
+			t.Errorf("dataSource = %+v, want data-web-seed", dest.Spec.DataSource)
+		}
+		if ref := dest.Spec.DataSourceRef; ref == nil || ref.Name != "snap-1" || ptr.Deref(ref.Namespace, "") != "dest" {
+			t.Errorf("dataSourceRef = %+v, want snap-1 in namespace dest", ref)
+		}
+		if *source.Spec.DataSourceRef.Namespace != "prod" {
+			t.Error("source PVC modified")
+		}
+		if _, ok := dest.Annotations[AnnotationSourceDataSource]; ok {
+			t.Error("origin annotation set on a preserved data source")
+		}
+	})
+
+	t.Run("no data source", func(t *testing.T) {
+		dest := &corev1.PersistentVolumeClaim{}
+		translateDataSource(&corev1.PersistentVolumeClaim{}, dest, "", "dest")
+		if dest.Annotations != nil {
+			t.Errorf("annotations = %v, want none", dest.Annotations)
+		}
+	})
+}
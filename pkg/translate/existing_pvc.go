@@ -0,0 +1,79 @@
+package translate
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// CheckAdoptablePVC verifies that a destination PVC created ahead of the
+// migration, for example by GitOps, will bind to the translated PV. The PV
+// controller only binds a PVC to a PV of the same StorageClass and volume
+// mode, with the access modes and capacity it requests and the labels its
+// selector asks for; a PVC that fails any of these stays Pending, or is
+// given a freshly provisioned, empty volume.
+func CheckAdoptablePVC(pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume) error {
+	name := pvc.Namespace + "/" + pvc.Name
+	if pvc.DeletionTimestamp != nil {
+		return fmt.Errorf("PVC %s is being deleted", name)
+	}
+	if pvc.Spec.VolumeName != "" && pvc.Spec.VolumeName != pv.Name {
+		return fmt.Errorf("PVC %s is bound to PV %s", name, pvc.Spec.VolumeName)
+	}
+
+	if class := stringValue(pvc.Spec.StorageClassName); class != pv.Spec.StorageClassName {
+		return fmt.Errorf("PVC %s has StorageClass %q but the volume's PV has %q; map the StorageClass with storageClassMapping",
+			name, class, pv.Spec.StorageClassName)
+	}
+	if got, want := volumeModeOrDefault(pvc.Spec.VolumeMode), volumeModeOrDefault(pv.Spec.VolumeMode); got != want {
+		return fmt.Errorf("PVC %s has volumeMode %s but the volume is %s", name, got, want)
+	}
+	for _, mode := range pvc.Spec.AccessModes {
+		if !hasAccessMode(pv.Spec.AccessModes, mode) {
+			return fmt.Errorf("PVC %s requests access mode %s, which the volume's PV does not offer", name, mode)
+		}
+	}
+
+	request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	capacity := pv.Spec.Capacity[corev1.ResourceStorage]
+	if request.Cmp(capacity) > 0 {
+		return fmt.Errorf("PVC %s requests %s but the volume holds %s", name, request.String(), capacity.String())
+	}
+
+	if pvc.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(pvc.Spec.Selector)
+		if err != nil {
+			return fmt.Errorf("PVC %s has an invalid selector: %w", name, err)
+		}
+		if !selector.Matches(labels.Set(pv.Labels)) {
+			return fmt.Errorf("PVC %s selects PVs by %s, which the volume's PV does not match", name, selector)
+		}
+	}
+	return nil
+}
+
+func hasAccessMode(modes []corev1.PersistentVolumeAccessMode, mode corev1.PersistentVolumeAccessMode) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// volumeModeOrDefault returns the volume mode, which defaults to Filesystem
+func volumeModeOrDefault(mode *corev1.PersistentVolumeMode) corev1.PersistentVolumeMode {
+	if mode == nil {
+		return corev1.PersistentVolumeFilesystem
+	}
+	return *mode
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,155 @@
+package translate
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func csiPV(name, volumeID string) corev1.PersistentVolume {
+	return corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{
+					Driver:       "ebs.csi.aws.com",
+					VolumeHandle: volumeID,
+				},
+			},
+		},
+	}
+}
+
+func gitOpsPVC(modify func(*corev1.PersistentVolumeClaim)) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "dest", Name: "data-web-0", UID: "pvc-uid"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: ptr.To("gp3"),
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+		},
+	}
+	if modify != nil {
+		modify(pvc)
+	}
+	return pvc
+}
+
+func TestCheckAdoptablePVC(t *testing.T) {
+	pv := csiPV("migrated-dest-data-web-0", "vol-abc")
+	pv.Labels = map[string]string{"migration.aqua.io/migrated": "true"}
+	pv.Spec.StorageClassName = "gp3"
+	pv.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	pv.Spec.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+
+	tests := []struct {
+		name    string
+		modify  func(*corev1.PersistentVolumeClaim)
+		wantErr string
+	}{
+		{name: "matching PVC"},
+		{name: "pre-bound to the PV", modify: func(pvc *corev1.PersistentVolumeClaim) { pvc.Spec.VolumeName = pv.Name }},
+		{name: "smaller request", modify: func(pvc *corev1.PersistentVolumeClaim) {
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("5Gi")
+		}},
+		{name: "matching selector", modify: func(pvc *corev1.PersistentVolumeClaim) {
+			pvc.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"migration.aqua.io/migrated": "true"}}
+		}},
+		{
+			name:    "being deleted",
+			modify:  func(pvc *corev1.PersistentVolumeClaim) { pvc.DeletionTimestamp = &metav1.Time{} },
+			wantErr: "is being deleted",
+		},
+		{
+			name:    "bound elsewhere",
+			modify:  func(pvc *corev1.PersistentVolumeClaim) { pvc.Spec.VolumeName = "pvc-provisioned" },
+			wantErr: "is bound to PV pvc-provisioned",
+		},
+		{
+			name:    "other StorageClass",
+			modify:  func(pvc *corev1.PersistentVolumeClaim) { pvc.Spec.StorageClassName = ptr.To("io2") },
+			wantErr: `has StorageClass "io2" but the volume's PV has "gp3"`,
+		},
+		{
+			name:    "block volume mode",
+			modify:  func(pvc *corev1.PersistentVolumeClaim) { pvc.Spec.VolumeMode = ptr.To(corev1.PersistentVolumeBlock) },
+			wantErr: "has volumeMode Block but the volume is Filesystem",
+		},
+		{
+			name: "access mode not offered",
+			modify: func(pvc *corev1.PersistentVolumeClaim) {
+				pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod}
+			},
+			wantErr: "requests access mode ReadWriteOncePod",
+		},
+		{
+			name: "larger request",
+			modify: func(pvc *corev1.PersistentVolumeClaim) {
+				pvc.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("20Gi")
+			},
+			wantErr: "requests 20Gi but the volume holds 10Gi",
+		},
+		{
+			name: "selector does not match",
+			modify: func(pvc *corev1.PersistentVolumeClaim) {
+				pvc.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "db"}}
+			},
+			wantErr: "selects PVs by tier=db",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckAdoptablePVC(gitOpsPVC(tt.modify), &pv)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckAdoptablePVC() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckAdoptablePVC() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTranslatePVExistingPVC(t *testing.T) {
+	sourcePV := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-12345"},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: "gp3",
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-abc"},
+			},
+		},
+	}
+	sourcePVC := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-0"}}
+	existing := gitOpsPVC(nil)
+
+	result, err := TranslatePV(sourcePV, sourcePVC, PVTranslationConfig{DestNamespace: "dest", DestPVCName: "data-web-0", ExistingPVC: existing})
+	if err != nil {
+		t.Fatalf("TranslatePV() error = %v", err)
+	}
+	if !result.PVCAdopted {
+		t.Error("PVCAdopted = false, want true")
+	}
+	if result.PVC.UID != existing.UID || result.PVC == existing {
+		t.Errorf("PVC = %p with UID %s, want a copy of the existing PVC", result.PVC, result.PVC.UID)
+	}
+	if ref := result.PV.Spec.ClaimRef; ref.Namespace != "dest" || ref.Name != "data-web-0" || ref.UID != "pvc-uid" {
+		t.Errorf("claimRef = %+v, want dest/data-web-0 with UID pvc-uid", ref)
+	}
+
+	if _, err := TranslatePV(sourcePV, sourcePVC, PVTranslationConfig{DestNamespace: "dest", DestPVCName: "data-web-1", ExistingPVC: existing}); err == nil {
+		t.Error("TranslatePV() with another PVC's name succeeded, want error")
+	}
+}
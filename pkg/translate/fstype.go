@@ -0,0 +1,20 @@
+package translate
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// FSTypeNTFS is the filesystem of EBS volumes formatted on Windows nodes
+const FSTypeNTFS = "ntfs"
+
+// translateFSType returns the fsType of a destination PV. For Windows pods an
+// empty fsType is set to NTFS explicitly, so the destination does not fall
+// back to a Linux default such as ext4.
+func translateFSType(fsType string, os corev1.OSName) string {
+	if os == corev1.Windows && (fsType == "" || strings.EqualFold(fsType, FSTypeNTFS)) {
+		return FSTypeNTFS
+	}
+	return fsType
+}
@@ -0,0 +1,45 @@
+package translate
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTranslatePVWindowsFSType(t *testing.T) {
+	sourcePV := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-0"},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-0123456789abcdef0"},
+			},
+		},
+	}
+	sourcePVC := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data-web-0"}}
+
+	tests := []struct {
+		os   corev1.OSName
+		want string
+	}{
+		{os: corev1.Windows, want: FSTypeNTFS},
+		{os: corev1.Linux, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.os), func(t *testing.T) {
+			result, err := TranslatePV(sourcePV, sourcePVC, PVTranslationConfig{DestNamespace: "prod", DestPVCName: "data-web-0", NodeOS: tt.os})
+			if err != nil {
+				t.Fatalf("TranslatePV() error = %v", err)
+			}
+			if got := result.PV.Spec.CSI.FSType; got != tt.want {
+				t.Errorf("fsType = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if sourcePV.Spec.CSI.FSType != "" {
+		t.Error("TranslatePV() modified the source PV")
+	}
+}
@@ -0,0 +1,45 @@
+package translate
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// EBSCSIDriverName is the CSI driver in-tree EBS volumes are converted to
+	EBSCSIDriverName = "ebs.csi.aws.com"
+
+	// partitionAttribute is the EBS CSI driver's volume attribute for the
+	// partition of the volume to mount
+	partitionAttribute = "partition"
+)
+
+// inTreeToCSI returns the EBS CSI source of an in-tree EBS volume, as the
+// kubelet's CSI migration would mount it
+func inTreeToCSI(ebs *corev1.AWSElasticBlockStoreVolumeSource, volumeID string) *corev1.CSIPersistentVolumeSource {
+	source := &corev1.CSIPersistentVolumeSource{
+		Driver:       EBSCSIDriverName,
+		VolumeHandle: volumeID,
+		FSType:       ebs.FSType,
+		ReadOnly:     ebs.ReadOnly,
+	}
+	if ebs.Partition != 0 {
+		source.VolumeAttributes = map[string]string{partitionAttribute: strconv.Itoa(int(ebs.Partition))}
+	}
+	return source
+}
+
+// convertInTreeEBS replaces destPV's in-tree EBS source with the EBS CSI
+// driver's, requiring its zone with topology.kubernetes.io/zone since the
+// driver's nodes may not carry the deprecated zone label
+func convertInTreeEBS(destPV *corev1.PersistentVolume, volumeID, zone string) string {
+	ebs := destPV.Spec.AWSElasticBlockStore
+	destPV.Spec.AWSElasticBlockStore = nil
+	destPV.Spec.CSI = inTreeToCSI(ebs, volumeID)
+	if zone != "" {
+		destPV.Spec.NodeAffinity = WithAvailabilityZone(destPV, zone).Spec.NodeAffinity
+	}
+	return fmt.Sprintf("in-tree EBS volume %s is mounted through the %s CSI driver in the destination", volumeID, EBSCSIDriverName)
+}
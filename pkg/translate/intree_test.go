@@ -0,0 +1,69 @@
+package translate
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTranslatePVConvertInTreeEBS(t *testing.T) {
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-legacy"},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20Gi")},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				AWSElasticBlockStore: &corev1.AWSElasticBlockStoreVolumeSource{VolumeID: "aws://us-east-1a/vol-legacy123", FSType: "xfs", Partition: 1},
+			},
+			NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelFailureDomainBetaZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"us-east-1a"}}},
+			}}}},
+		},
+	}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "source", Name: "data-db-0"}}
+
+	tests := []struct {
+		name    string
+		convert bool
+	}{
+		{name: "kept in-tree", convert: false},
+		{name: "converted to CSI", convert: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := TranslatePV(pv, pvc, PVTranslationConfig{
+				DestNamespace:        "dest",
+				DestPVCName:          "data-db-0",
+				PreserveNodeAffinity: true,
+				ConvertInTreeEBS:     tt.convert,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			spec := result.PV.Spec
+			if !tt.convert {
+				if spec.AWSElasticBlockStore == nil || spec.CSI != nil {
+					t.Errorf("PV source = %+v, want the in-tree source", spec.PersistentVolumeSource)
+				}
+				return
+			}
+
+			if spec.AWSElasticBlockStore != nil || spec.CSI == nil {
+				t.Fatalf("PV source = %+v, want only a CSI source", spec.PersistentVolumeSource)
+			}
+			csi := spec.CSI
+			if csi.Driver != EBSCSIDriverName || csi.VolumeHandle != "vol-legacy123" || csi.FSType != "xfs" || csi.VolumeAttributes[partitionAttribute] != "1" {
+				t.Errorf("CSI source = %+v, want the in-tree volume's", csi)
+			}
+			expr := spec.NodeAffinity.Required.NodeSelectorTerms[0].MatchExpressions
+			if len(expr) != 1 || expr[0].Key != corev1.LabelTopologyZone || expr[0].Values[0] != "us-east-1a" {
+				t.Errorf("node affinity = %+v, want the zone on %s", expr, corev1.LabelTopologyZone)
+			}
+			if len(result.Warnings) != 1 {
+				t.Errorf("warnings = %v, want the conversion reported", result.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package translate
+
+import (
+	"bytes"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// documentSeparator separates the documents of a multi-document YAML stream
+const documentSeparator = "---\n"
+
+// serverSetMetadata are metadata fields the API server owns. They are left
+// out of manifests so that kubectl apply and GitOps tools create the objects
+// afresh instead of rejecting a stale resourceVersion or UID.
+var serverSetMetadata = []string{
+	"managedFields",
+	"resourceVersion",
+	"uid",
+	"generation",
+	"creationTimestamp",
+	"selfLink",
+}
+
+// Marshal returns the destination PV and PVC as a multi-document YAML
+// manifest ready for kubectl apply. An adopted PVC already exists, so only
+// the PV is included.
+func (r *TranslationResult) Marshal() ([]byte, error) {
+	objects := []runtime.Object{r.PV}
+	if !r.PVCAdopted {
+		objects = append(objects, r.PVC)
+	}
+
+	var b bytes.Buffer
+	for i, obj := range objects {
+		data, err := MarshalManifest(obj)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			b.WriteString(documentSeparator)
+		}
+		b.Write(data)
+	}
+	return b.Bytes(), nil
+}
+
+// MarshalBundle joins the manifests of several translations into one
+// multi-document YAML stream, in order
+func MarshalBundle(results []*TranslationResult) ([]byte, error) {
+	var b bytes.Buffer
+	for i, r := range results {
+		data, err := r.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			b.WriteString(documentSeparator)
+		}
+		b.Write(data)
+	}
+	return b.Bytes(), nil
+}
+
+// MarshalManifest returns a PersistentVolume or PersistentVolumeClaim as
+// YAML with its apiVersion and kind set, and without status or the metadata
+// the API server sets
+func MarshalManifest(obj runtime.Object) ([]byte, error) {
+	var kind string
+	switch obj.(type) {
+	case *corev1.PersistentVolume:
+		kind = "PersistentVolume"
+	case *corev1.PersistentVolumeClaim:
+		kind = "PersistentVolumeClaim"
+	default:
+		return nil, fmt.Errorf("unsupported manifest type %T", obj)
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s: %w", kind, err)
+	}
+	content["apiVersion"] = corev1.SchemeGroupVersion.String()
+	content["kind"] = kind
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]any); ok {
+		for _, field := range serverSetMetadata {
+			delete(metadata, field)
+		}
+	}
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", kind, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,102 @@
+package translate
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func manifestResult(t *testing.T, pvcName string) *TranslationResult {
+	t.Helper()
+	sourcePV := csiPV("pvc-"+pvcName, "vol-"+pvcName)
+	sourcePV.UID = "pv-uid"
+	sourcePV.ResourceVersion = "42"
+	sourcePV.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kube-controller-manager"}}
+	sourcePV.Spec.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	sourcePV.Status.Phase = corev1.VolumeBound
+	sourcePVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: "default", UID: "pvc-uid", ResourceVersion: "43"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+
+	result, err := TranslatePV(&sourcePV, sourcePVC, PVTranslationConfig{DestNamespace: "prod", DestPVCName: pvcName})
+	if err != nil {
+		t.Fatalf("TranslatePV() error = %v", err)
+	}
+	return result
+}
+
+func TestMarshal(t *testing.T) {
+	result := manifestResult(t, "data-web-0")
+	data, err := result.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	docs := strings.Split(string(data), documentSeparator)
+	if len(docs) != 2 {
+		t.Fatalf("Marshal() returned %d documents, want 2:\n%s", len(docs), data)
+	}
+	for i, want := range []string{"PersistentVolume", "PersistentVolumeClaim"} {
+		var obj map[string]any
+		if err := yaml.Unmarshal([]byte(docs[i]), &obj); err != nil {
+			t.Fatalf("document %d is not valid YAML: %v", i, err)
+		}
+		if obj["apiVersion"] != "v1" || obj["kind"] != want {
+			t.Errorf("document %d is %v/%v, want v1/%s", i, obj["apiVersion"], obj["kind"], want)
+		}
+		if _, ok := obj["status"]; ok {
+			t.Errorf("document %d has a status", i)
+		}
+		metadata := obj["metadata"].(map[string]any)
+		for _, field := range serverSetMetadata {
+			if _, ok := metadata[field]; ok {
+				t.Errorf("document %d has metadata.%s", i, field)
+			}
+		}
+	}
+
+	var pvc corev1.PersistentVolumeClaim
+	if err := yaml.Unmarshal([]byte(docs[1]), &pvc); err != nil {
+		t.Fatal(err)
+	}
+	if pvc.Namespace != "prod" || pvc.Spec.VolumeName != result.PV.Name {
+		t.Errorf("PVC = %s/%s bound to %q, want prod/data-web-0 bound to %q", pvc.Namespace, pvc.Name, pvc.Spec.VolumeName, result.PV.Name)
+	}
+}
+
+func TestMarshalAdoptedPVC(t *testing.T) {
+	result := manifestResult(t, "data-web-0")
+	result.PVCAdopted = true
+	data, err := result.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), documentSeparator) || strings.Contains(string(data), "\nkind: PersistentVolumeClaim") {
+		t.Errorf("Marshal() of an adopted PVC = %s, want the PV only", data)
+	}
+}
+
+func TestMarshalBundle(t *testing.T) {
+	data, err := MarshalBundle([]*TranslationResult{manifestResult(t, "data-web-0"), manifestResult(t, "data-web-1")})
+	if err != nil {
+		t.Fatalf("MarshalBundle() error = %v", err)
+	}
+	docs := strings.Split(string(data), documentSeparator)
+	if len(docs) != 4 {
+		t.Fatalf("MarshalBundle() returned %d documents, want 4", len(docs))
+	}
+	if !strings.Contains(docs[2], "name: migrated-prod-data-web-1") || !strings.Contains(docs[3], "name: data-web-1") {
+		t.Errorf("MarshalBundle() did not keep the translations in order:\n%s", data)
+	}
+}
+
+func TestMarshalManifestUnsupported(t *testing.T) {
+	if _, err := MarshalManifest(&corev1.Pod{}); err == nil {
+		t.Error("MarshalManifest(Pod) succeeded, want an error")
+	}
+}
@@ -0,0 +1,54 @@
+package translate
+
+import "strings"
+
+// MetadataPassthrough selects source PV and PVC metadata to copy to the
+// destination objects by key prefix; "*" matches every key
+type MetadataPassthrough struct {
+	// AnnotationPrefixes selects the annotations to copy
+	AnnotationPrefixes []string
+
+	// LabelPrefixes selects the labels to copy
+	LabelPrefixes []string
+}
+
+// reservedMetadataPrefixes are never copied. Kubernetes sets these keys on
+// volumes to track binding and provisioning; copied to a static PV they would
+// make the destination cluster treat it as its own, as would a copied
+// last-applied-configuration for kubectl apply. The controller's own keys
+// describe the destination objects, not the source ones.
+var reservedMetadataPrefixes = []string{
+	"migration.aqua.io/",
+	"pv.kubernetes.io/",
+	"volume.kubernetes.io/",
+	"volume.beta.kubernetes.io/",
+	"kubectl.kubernetes.io/last-applied-configuration",
+}
+
+// passthroughMetadata copies the source keys that match a prefix into dst and
+// returns it. Keys already in dst are kept.
+func passthroughMetadata(dst, src map[string]string, prefixes []string) map[string]string {
+	if len(prefixes) == 0 {
+		return dst
+	}
+	for key, value := range src {
+		if _, ok := dst[key]; ok || !matchesPrefix(key, prefixes) || matchesPrefix(key, reservedMetadataPrefixes) {
+			continue
+		}
+		if dst == nil {
+			dst = make(map[string]string)
+		}
+		dst[key] = value
+	}
+	return dst
+}
+
+// matchesPrefix reports whether key starts with one of the prefixes
+func matchesPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if prefix == "*" || strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
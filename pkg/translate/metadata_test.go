@@ -0,0 +1,116 @@
+package translate
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPassthroughMetadata(t *testing.T) {
+	src := map[string]string{
+		"ebs.csi.aws.com/snapshot-policy":                  "daily",
+		"backup.velero.io/backup-volumes":                  "data",
+		"team":                                             "payments",
+		"pv.kubernetes.io/provisioned-by":                  "ebs.csi.aws.com",
+		"volume.kubernetes.io/selected-node":               "ip-10-0-0-1",
+		"kubectl.kubernetes.io/last-applied-configuration": "{}",
+		"migration.aqua.io/migrated":                       "false",
+	}
+
+	tests := []struct {
+		name     string
+		dst      map[string]string
+		prefixes []string
+		want     map[string]string
+	}{
+		{name: "no prefixes", dst: map[string]string{"migration.aqua.io/migrated": "true"}, want: map[string]string{"migration.aqua.io/migrated": "true"}},
+		{
+			name:     "by prefix",
+			dst:      map[string]string{"migration.aqua.io/migrated": "true"},
+			prefixes: []string{"ebs.csi.aws.com/", "backup.velero.io/"},
+			want: map[string]string{
+				"migration.aqua.io/migrated":      "true",
+				"ebs.csi.aws.com/snapshot-policy": "daily",
+				"backup.velero.io/backup-volumes": "data",
+			},
+		},
+		{
+			name:     "everything but reserved keys",
+			dst:      map[string]string{"migration.aqua.io/migrated": "true"},
+			prefixes: []string{"*"},
+			want: map[string]string{
+				"migration.aqua.io/migrated":      "true",
+				"ebs.csi.aws.com/snapshot-policy": "daily",
+				"backup.velero.io/backup-volumes": "data",
+				"team":                            "payments",
+			},
+		},
+		{name: "reserved prefix asked for", prefixes: []string{"pv.kubernetes.io/"}},
+		{name: "into nil map", prefixes: []string{"team"}, want: map[string]string{"team": "payments"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := passthroughMetadata(tt.dst, src, tt.prefixes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("passthroughMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslatePVPassthrough(t *testing.T) {
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pvc-12345",
+			Labels:      map[string]string{"team": "payments"},
+			Annotations: map[string]string{"ebs.csi.aws.com/snapshot-policy": "daily", "pv.kubernetes.io/provisioned-by": "ebs.csi.aws.com"},
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-0123456789abcdef0"},
+			},
+		},
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "data-web-0",
+			Labels:      map[string]string{"team": "payments"},
+			Annotations: map[string]string{"backup.velero.io/backup-volumes": "data"},
+		},
+	}
+
+	result, err := TranslatePV(pv, pvc, PVTranslationConfig{
+		DestNamespace: "dest",
+		DestPVCName:   "data-web-0",
+		Passthrough: MetadataPassthrough{
+			AnnotationPrefixes: []string{"ebs.csi.aws.com/", "backup.velero.io/", "pv.kubernetes.io/"},
+			LabelPrefixes:      []string{"team"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("TranslatePV() error = %v", err)
+	}
+
+	if got := result.PV.Annotations["ebs.csi.aws.com/snapshot-policy"]; got != "daily" {
+		t.Errorf("PV annotation ebs.csi.aws.com/snapshot-policy = %q, want daily", got)
+	}
+	if _, ok := result.PV.Annotations["pv.kubernetes.io/provisioned-by"]; ok {
+		t.Error("PV annotation pv.kubernetes.io/provisioned-by was copied")
+	}
+	if got := result.PV.Labels["team"]; got != "payments" {
+		t.Errorf("PV label team = %q, want payments", got)
+	}
+	if got := result.PV.Labels["migration.aqua.io/migrated"]; got != "true" {
+		t.Errorf("PV label migration.aqua.io/migrated = %q, want true", got)
+	}
+	if got := result.PVC.Annotations["backup.velero.io/backup-volumes"]; got != "data" {
+		t.Errorf("PVC annotation backup.velero.io/backup-volumes = %q, want data", got)
+	}
+	if got := result.PVC.Labels["team"]; got != "payments" {
+		t.Errorf("PVC label team = %q, want payments", got)
+	}
+}
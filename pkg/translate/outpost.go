@@ -0,0 +1,45 @@
+package translate
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// OutpostIDLabel is the topology label the EBS CSI driver gives nodes running
+// on an Outpost, and the key it puts in the node affinity of Outpost volumes
+const OutpostIDLabel = "topology.ebs.csi.aws.com/outpost-id"
+
+// requireOutpost adds an Outpost requirement to every node selector term of a
+// PV's node affinity that lacks one. A volume on an Outpost can only attach to
+// instances on that Outpost, but its zone is the Outpost's parent availability
+// zone, which regional nodes share.
+func requireOutpost(affinity *corev1.VolumeNodeAffinity, outpostID string) *corev1.VolumeNodeAffinity {
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      OutpostIDLabel,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{outpostID},
+	}
+	if affinity == nil || affinity.Required == nil || len(affinity.Required.NodeSelectorTerms) == 0 {
+		return &corev1.VolumeNodeAffinity{
+			Required: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{requirement},
+				}},
+			},
+		}
+	}
+
+	terms := affinity.Required.NodeSelectorTerms
+	for i := range terms {
+		hasOutpost := false
+		for _, expr := range terms[i].MatchExpressions {
+			if expr.Key == OutpostIDLabel {
+				hasOutpost = true
+				break
+			}
+		}
+		if !hasOutpost {
+			terms[i].MatchExpressions = append(terms[i].MatchExpressions, requirement)
+		}
+	}
+	return affinity
+}
@@ -0,0 +1,36 @@
+package translate
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRequireOutpost(t *testing.T) {
+	zone := corev1.NodeSelectorRequirement{Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{"us-west-2a"}}
+	outpost := corev1.NodeSelectorRequirement{Key: OutpostIDLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{"op-1"}}
+	affinity := func(exprs ...corev1.NodeSelectorRequirement) *corev1.VolumeNodeAffinity {
+		return &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: exprs}},
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		affinity *corev1.VolumeNodeAffinity
+		want     *corev1.VolumeNodeAffinity
+	}{
+		{name: "no affinity", affinity: nil, want: affinity(outpost)},
+		{name: "zone only", affinity: affinity(zone), want: affinity(zone, outpost)},
+		{name: "already required", affinity: affinity(zone, outpost), want: affinity(zone, outpost)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requireOutpost(tt.affinity, "op-1"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("requireOutpost() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
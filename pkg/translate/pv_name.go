@@ -0,0 +1,71 @@
+package translate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DefaultPVNameTemplate is the destination PV name used when no template is configured
+const DefaultPVNameTemplate = "migrated-{{.Namespace}}-{{.PVCName}}"
+
+// pvNameHashLength is the number of hex characters of the hash suffix added to shortened names
+const pvNameHashLength = 8
+
+// PVNameData is the data available to a destination PV name template
+type PVNameData struct {
+	// Namespace is the destination namespace
+	Namespace string
+
+	// PVCName is the destination PVC name, e.g. data-postgres-0
+	PVCName string
+
+	// SourcePVName is the name of the PV in the source cluster
+	SourcePVName string
+
+	// VolumeID is the EBS volume ID
+	VolumeID string
+}
+
+// RenderPVName renders a destination PV name from a text/template, or from
+// DefaultPVNameTemplate when tmpl is empty. Names longer than the 253-character
+// limit are shortened and given a hash suffix so they stay unique; names that
+// are not valid RFC 1123 subdomains are rejected.
+func RenderPVName(tmpl string, data PVNameData) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultPVNameTemplate
+	}
+
+	t, err := template.New("pvName").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid PV name template %q: %w", tmpl, err)
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render PV name template %q: %w", tmpl, err)
+	}
+
+	name := shortenName(b.String(), validation.DNS1123SubdomainMaxLength)
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", fmt.Errorf("PV name %q rendered from template %q is invalid: %s", name, tmpl, strings.Join(errs, "; "))
+	}
+	return name, nil
+}
+
+// shortenName truncates name to maxLen, replacing the tail with a hash of the
+// full name so that different long names do not collide
+func shortenName(name string, maxLen int) string {
+	if len(name) <= maxLen {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(sum[:])[:pvNameHashLength]
+	prefix := strings.TrimRight(name[:maxLen-pvNameHashLength-1], "-.")
+	return prefix + "-" + suffix
+}
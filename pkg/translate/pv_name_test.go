@@ -0,0 +1,61 @@
+package translate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderPVName(t *testing.T) {
+	data := PVNameData{Namespace: "databases", PVCName: "data-postgres-0", SourcePVName: "pvc-1234", VolumeID: "vol-abc"}
+	longNamespace := strings.Repeat("n", 63)
+	longPVC := strings.Repeat("p", 200)
+
+	tests := []struct {
+		name    string
+		tmpl    string
+		data    PVNameData
+		want    string
+		wantLen int
+		wantErr bool
+	}{
+		{name: "default template", data: data, want: "migrated-databases-data-postgres-0"},
+		{name: "custom template", tmpl: "{{.VolumeID}}-{{.PVCName}}", data: data, want: "vol-abc-data-postgres-0"},
+		{name: "source PV name", tmpl: "dst-{{.SourcePVName}}", data: data, want: "dst-pvc-1234"},
+		{name: "long names are shortened", data: PVNameData{Namespace: longNamespace, PVCName: longPVC}, wantLen: 253},
+		{name: "uppercase is rejected", tmpl: "Migrated-{{.PVCName}}", data: data, wantErr: true},
+		{name: "underscore is rejected", tmpl: "migrated_{{.PVCName}}", data: data, wantErr: true},
+		{name: "empty result is rejected", tmpl: "{{if false}}x{{end}}", data: data, wantErr: true},
+		{name: "unknown field is rejected", tmpl: "{{.Cluster}}-{{.PVCName}}", data: data, wantErr: true},
+		{name: "bad syntax is rejected", tmpl: "{{.PVCName", data: data, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderPVName(tt.tmpl, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderPVName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("RenderPVName() = %q, want %q", got, tt.want)
+			}
+			if tt.wantLen != 0 && len(got) != tt.wantLen {
+				t.Errorf("RenderPVName() length = %d, want %d", len(got), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestRenderPVNameShortenedNamesAreUnique(t *testing.T) {
+	prefix := strings.Repeat("p", 250)
+	a, err := RenderPVName("", PVNameData{Namespace: "ns", PVCName: prefix + "-0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := RenderPVName("", PVNameData{Namespace: "ns", PVCName: prefix + "-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Errorf("shortened names collide: %q", a)
+	}
+}
@@ -0,0 +1,69 @@
+package translate
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PVValidationOptions relaxes ValidatePV for volumes that were not
+// provisioned dynamically
+type PVValidationOptions struct {
+	// AllowStatic accepts a statically provisioned PV that an administrator
+	// pre-created and that is still Available: unclaimed, or reserved by its
+	// claimRef for a PVC that does not exist yet. Such a PV may carry any
+	// labels and annotations and no StorageClass, but must name its EBS
+	// volume by ID, since nothing checked the PV when it was written.
+	AllowStatic bool
+}
+
+// ValidatePV performs validation checks on a PV before migration, relaxed
+// by opts
+func ValidatePV(pv *corev1.PersistentVolume, opts PVValidationOptions) error {
+	if pv == nil {
+		return fmt.Errorf("PV is nil")
+	}
+
+	// Check that PV is bound, or a pre-created one waiting for its claim
+	static := opts.AllowStatic && pv.Status.Phase == corev1.VolumeAvailable
+	if pv.Status.Phase != corev1.VolumeBound && !static {
+		return fmt.Errorf("PV %s is not bound (phase: %s)", pv.Name, pv.Status.Phase)
+	}
+
+	// Check that it's an EBS volume
+	if pv.Spec.CSI == nil && pv.Spec.AWSElasticBlockStore == nil {
+		return fmt.Errorf("PV %s is not an EBS volume", pv.Name)
+	}
+
+	if pv.Spec.CSI != nil && pv.Spec.CSI.Driver != EBSCSIDriverName {
+		return fmt.Errorf("PV %s uses unsupported CSI driver: %s", pv.Name, pv.Spec.CSI.Driver)
+	}
+
+	if static {
+		volumeID, err := EBSVolumeID(pv)
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(volumeID, "vol-") {
+			return fmt.Errorf("PV %s names volume %q, which is not an EBS volume ID (vol-...)", pv.Name, volumeID)
+		}
+	}
+	return nil
+}
+
+// unclaimedPVC returns the claim a pre-created PV with no claimRef would be
+// bound by: the PV's access modes and volume mode, requesting all of it. It
+// stands in for the source PVC when translating such a PV.
+func unclaimedPVC(pv *corev1.PersistentVolume) (*corev1.PersistentVolumeClaim, error) {
+	if pv.Spec.ClaimRef != nil {
+		return nil, fmt.Errorf("source PVC cannot be nil for PV %s, which is claimed by %s/%s",
+			pv.Name, pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name)
+	}
+	return &corev1.PersistentVolumeClaim{
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: pv.Spec.AccessModes,
+			VolumeMode:  pv.Spec.VolumeMode,
+		},
+	}, nil
+}
@@ -0,0 +1,85 @@
+package translate
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// staticPV is a PV an administrator wrote by hand: no StorageClass, no
+// provisioner annotations, labels of their own and no claim yet
+func staticPV(volumeID string, phase corev1.PersistentVolumePhase) *corev1.PersistentVolume {
+	pv := csiPV("db-data", volumeID)
+	pv.Labels = map[string]string{"team": "payments"}
+	pv.Spec.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")}
+	pv.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	pv.Status.Phase = phase
+	return &pv
+}
+
+func TestValidatePVStatic(t *testing.T) {
+	reserved := staticPV("vol-0123456789abcdef0", corev1.VolumeAvailable)
+	reserved.Spec.ClaimRef = &corev1.ObjectReference{Namespace: "db", Name: "data-db-0"}
+	inTree := staticPV("", corev1.VolumeAvailable)
+	inTree.Spec.CSI = nil
+	inTree.Spec.AWSElasticBlockStore = &corev1.AWSElasticBlockStoreVolumeSource{VolumeID: "aws://us-east-1a/vol-0123456789abcdef0"}
+
+	tests := []struct {
+		name        string
+		pv          *corev1.PersistentVolume
+		wantErr     bool
+		wantDefault bool
+	}{
+		{name: "unclaimed", pv: staticPV("vol-0123456789abcdef0", corev1.VolumeAvailable)},
+		{name: "reserved for a claim", pv: reserved},
+		{name: "in-tree", pv: inTree},
+		{name: "bound", pv: staticPV("vol-0123456789abcdef0", corev1.VolumeBound), wantDefault: true},
+		{name: "released", pv: staticPV("vol-0123456789abcdef0", corev1.VolumeReleased), wantErr: true},
+		{name: "not a volume ID", pv: staticPV("db-data", corev1.VolumeAvailable), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePV(tt.pv, PVValidationOptions{AllowStatic: true})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := ValidatePVForMigration(tt.pv); (err == nil) != tt.wantDefault {
+				t.Errorf("ValidatePVForMigration() error = %v, want accepted %v", err, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestTranslatePVUnclaimed(t *testing.T) {
+	pv := staticPV("vol-0123456789abcdef0", corev1.VolumeAvailable)
+	block := corev1.PersistentVolumeBlock
+	pv.Spec.VolumeMode = &block
+
+	result, err := TranslatePV(pv, nil, PVTranslationConfig{DestNamespace: "db", DestPVCName: "data-db-0"})
+	if err != nil {
+		t.Fatalf("TranslatePV() error = %v", err)
+	}
+	pvc := result.PVC
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName != "" {
+		t.Errorf("PVC storageClassName = %v, want empty so the default StorageClass is not applied", pvc.Spec.StorageClassName)
+	}
+	if request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; request.String() != "100Gi" {
+		t.Errorf("PVC requests %s, want the whole 100Gi volume", request.String())
+	}
+	if len(pvc.Spec.AccessModes) != 1 || pvc.Spec.AccessModes[0] != corev1.ReadWriteOnce || pvc.Spec.VolumeMode == nil || *pvc.Spec.VolumeMode != block {
+		t.Errorf("PVC access modes %v, volume mode %v, want the PV's", pvc.Spec.AccessModes, pvc.Spec.VolumeMode)
+	}
+	if _, ok := pvc.Labels["migration.aqua.io/source-pvc"]; ok {
+		t.Errorf("PVC labels = %v, want no source PVC", pvc.Labels)
+	}
+	if pvc.Spec.VolumeName != result.PV.Name || result.PV.Spec.ClaimRef.Name != "data-db-0" {
+		t.Errorf("PV %s and PVC %s are not pre-bound to each other", result.PV.Name, pvc.Name)
+	}
+
+	pv.Spec.ClaimRef = &corev1.ObjectReference{Namespace: "db", Name: "data-db-0"}
+	if _, err := TranslatePV(pv, nil, PVTranslationConfig{DestNamespace: "db", DestPVCName: "data-db-0"}); err == nil {
+		t.Error("TranslatePV() of a claimed PV without its PVC succeeded")
+	}
+}
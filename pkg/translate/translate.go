@@ -0,0 +1,470 @@
+// Package translate rewrites a source cluster's EBS-backed PersistentVolume
+// and PersistentVolumeClaim into the pair that binds the same volume in
+// another cluster. It has no dependency on the controller, so backup, restore
+// and disaster recovery tooling can reuse it.
+package translate
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PVTranslationConfig contains configuration for PV/PVC translation
+type PVTranslationConfig struct {
+	// DestNamespace is the target namespace in the destination cluster
+	DestNamespace string
+
+	// DestPVCName is the name of the PVC in the destination cluster
+	// For StatefulSets, this follows the pattern: <volumeClaimTemplate>-<stsName>-<index>
+	DestPVCName string
+
+	// StorageClassMapping maps source StorageClass names to destination names
+	// If empty or key not found, the original StorageClass name is used
+	StorageClassMapping map[string]string
+
+	// PreserveNodeAffinity determines whether to copy node affinity from source PV
+	// This is critical for zone-constrained volumes like EBS
+	PreserveNodeAffinity bool
+
+	// PVNameTemplate is a text/template for the destination PV name
+	// If empty, DefaultPVNameTemplate ("migrated-<namespace>-<pvc>") is used
+	PVNameTemplate string
+
+	// NodeOS is the operating system of the pods that mount the volume
+	// On Windows an empty fsType is translated to NTFS
+	NodeOS corev1.OSName
+
+	// Passthrough selects source annotations and labels to copy to the
+	// destination PV and PVC
+	Passthrough MetadataPassthrough
+
+	// DataSourcePolicy decides what happens to the source PVC's dataSource
+	// and dataSourceRef (default: DataSourceStrip)
+	DataSourcePolicy DataSourcePolicy
+
+	// ExistingPVC is a destination PVC that already exists, such as one
+	// created by GitOps. When set, only the PV is generated, pre-bound to
+	// this PVC by UID, and the PVC is adopted instead of created.
+	ExistingPVC *corev1.PersistentVolumeClaim
+
+	// VolumeID replaces the source PV's volume, for a volume transferred to
+	// another AWS account (optional)
+	VolumeID string
+
+	// OutpostID is the Outpost the volume resides on; the destination PV's
+	// node affinity is made to require it (optional)
+	OutpostID string
+
+	// AvailabilityZone replaces the source PV's zone, for a volume restored
+	// from a snapshot in another zone (optional)
+	AvailabilityZone string
+
+	// VolumeSizeGiB is the volume's size as EBS reports it. When it exceeds
+	// the source PV's capacity, the destination PV's capacity and PVC's
+	// request are set to it (optional)
+	VolumeSizeGiB int32
+
+	// ConvertInTreeEBS gives a legacy in-tree EBS volume an EBS CSI source
+	// in the destination, for nodes the in-tree plugin cannot mount it on
+	ConvertInTreeEBS bool
+}
+
+// TranslationResult contains the translated PV and PVC for the destination cluster
+type TranslationResult struct {
+	// PV is the PersistentVolume to create in the destination cluster
+	PV *corev1.PersistentVolume
+
+	// PVC is the PersistentVolumeClaim to create in the destination cluster,
+	// or the adopted PVC when PVCAdopted is set
+	PVC *corev1.PersistentVolumeClaim
+
+	// PVCAdopted means PVC already exists and must not be created
+	PVCAdopted bool
+
+	// VolumeID is the cloud provider volume ID (e.g., AWS EBS volume ID)
+	VolumeID string
+
+	// AvailabilityZone is the zone where the volume resides
+	AvailabilityZone string
+
+	// Warnings are problems found that do not stop the translation
+	Warnings []string
+}
+
+// TranslatePV takes a source PV and creates the corresponding PV and PVC objects
+// for the destination cluster. This is the core function for storage migration.
+// sourcePVC may be nil for a pre-created PV with no claimRef; the destination
+// PVC then claims all of the PV with its access modes.
+func TranslatePV(sourcePV *corev1.PersistentVolume, sourcePVC *corev1.PersistentVolumeClaim, config PVTranslationConfig) (*TranslationResult, error) {
+	if sourcePV == nil {
+		return nil, fmt.Errorf("source PV cannot be nil")
+	}
+	unclaimed := sourcePVC == nil
+	if unclaimed {
+		var err error
+		if sourcePVC, err = unclaimedPVC(sourcePV); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ValidateDataSource(sourcePVC, config.DataSourcePolicy); err != nil {
+		return nil, err
+	}
+	if config.AvailabilityZone != "" {
+		sourcePV = WithAvailabilityZone(sourcePV, config.AvailabilityZone)
+	}
+
+	// Extract the EBS volume ID from the source PV
+	volumeID, err := EBSVolumeID(sourcePV)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract EBS volume ID: %w", err)
+	}
+	if config.VolumeID != "" {
+		volumeID = config.VolumeID
+	}
+
+	// Extract availability zone from source PV
+	az := AvailabilityZone(sourcePV)
+
+	// Determine the destination StorageClass
+	destStorageClass := DestStorageClass(sourcePV.Spec.StorageClassName, config.StorageClassMapping)
+
+	// Generate a unique PV name for the destination cluster
+	destPVName, err := RenderPVName(config.PVNameTemplate, PVNameData{
+		Namespace:    config.DestNamespace,
+		PVCName:      config.DestPVCName,
+		SourcePVName: sourcePV.Name,
+		VolumeID:     volumeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy the CSI volume source with the same volume handle
+	pvSource, warnings := buildPVSource(sourcePV, volumeID)
+	capacity, grown := volumeCapacity(sourcePV, config.VolumeSizeGiB)
+	if grown {
+		source := sourcePV.Spec.Capacity[corev1.ResourceStorage]
+		warnings = append(warnings, fmt.Sprintf("volume %s is %s, larger than the %s capacity of PV %s; the destination PV and PVC use %s",
+			volumeID, capacity.String(), source.String(), sourcePV.Name, capacity.String()))
+	}
+
+	// Create the destination PV
+	destPV := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: destPVName,
+			Labels: map[string]string{
+				"migration.aqua.io/migrated":       "true",
+				"migration.aqua.io/source-pv":      sourcePV.Name,
+				"migration.aqua.io/dest-namespace": config.DestNamespace,
+				"migration.aqua.io/dest-pvc":       config.DestPVCName,
+			},
+			Annotations: map[string]string{
+				"migration.aqua.io/source-pv-uid": string(sourcePV.UID),
+				"migration.aqua.io/volume-id":     volumeID,
+			},
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			// Copy capacity from source, or the volume's size when larger
+			Capacity: corev1.ResourceList{
+				corev1.ResourceStorage: capacity,
+			},
+			// Copy access modes from source
+			AccessModes: sourcePV.Spec.AccessModes,
+			// Set reclaim policy to Retain for safety during migration
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+			// Set StorageClass
+			StorageClassName: destStorageClass,
+			// Pre-bind to the destination PVC
+			ClaimRef: &corev1.ObjectReference{
+				APIVersion: "v1",
+				Kind:       "PersistentVolumeClaim",
+				Namespace:  config.DestNamespace,
+				Name:       config.DestPVCName,
+			},
+			PersistentVolumeSource: pvSource,
+		},
+	}
+
+	destPV.Labels = passthroughMetadata(destPV.Labels, sourcePV.Labels, config.Passthrough.LabelPrefixes)
+	destPV.Annotations = passthroughMetadata(destPV.Annotations, sourcePV.Annotations, config.Passthrough.AnnotationPrefixes)
+
+	// Copy volume mode if set
+	if sourcePV.Spec.VolumeMode != nil {
+		destPV.Spec.VolumeMode = sourcePV.Spec.VolumeMode
+	}
+
+	// Make the filesystem explicit for Windows pods
+	switch {
+	case destPV.Spec.CSI != nil:
+		destPV.Spec.CSI.FSType = translateFSType(destPV.Spec.CSI.FSType, config.NodeOS)
+	case destPV.Spec.AWSElasticBlockStore != nil:
+		destPV.Spec.AWSElasticBlockStore.FSType = translateFSType(destPV.Spec.AWSElasticBlockStore.FSType, config.NodeOS)
+	}
+
+	// Preserve node affinity for topology-constrained volumes
+	if config.PreserveNodeAffinity && sourcePV.Spec.NodeAffinity != nil {
+		destPV.Spec.NodeAffinity = sourcePV.Spec.NodeAffinity.DeepCopy()
+	} else if az != "" {
+		// If no node affinity but we have AZ info, create node affinity
+		destPV.Spec.NodeAffinity = buildNodeAffinityForZone(az)
+	}
+	if config.ConvertInTreeEBS && destPV.Spec.AWSElasticBlockStore != nil {
+		warnings = append(warnings, convertInTreeEBS(destPV, volumeID, az))
+	}
+	if config.OutpostID != "" {
+		destPV.Spec.NodeAffinity = requireOutpost(destPV.Spec.NodeAffinity, config.OutpostID)
+	}
+
+	// Bind the PV to an existing PVC instead of generating one
+	if existing := config.ExistingPVC; existing != nil {
+		if existing.Namespace != config.DestNamespace || existing.Name != config.DestPVCName {
+			return nil, fmt.Errorf("existing PVC %s/%s is not the destination PVC %s/%s",
+				existing.Namespace, existing.Name, config.DestNamespace, config.DestPVCName)
+		}
+		if err := CheckAdoptablePVC(existing, destPV); err != nil {
+			return nil, err
+		}
+		destPV.Spec.ClaimRef.UID = existing.UID
+		return &TranslationResult{
+			PV:               destPV,
+			PVC:              existing.DeepCopy(),
+			PVCAdopted:       true,
+			VolumeID:         volumeID,
+			AvailabilityZone: az,
+			Warnings:         warnings,
+		}, nil
+	}
+
+	// Request the same storage size, or all of a volume that outgrew its PV
+	request := destPVCRequest(sourcePVC, sourcePV)
+	if grown {
+		request = capacity.DeepCopy()
+	}
+
+	// Create the destination PVC
+	destPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.DestPVCName,
+			Namespace: config.DestNamespace,
+			Labels: map[string]string{
+				"migration.aqua.io/migrated":   "true",
+				"migration.aqua.io/source-pvc": sourcePVC.Name,
+			},
+			Annotations: map[string]string{
+				"migration.aqua.io/source-pvc-uid": string(sourcePVC.UID),
+				"migration.aqua.io/volume-id":      volumeID,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			// Copy access modes from source PVC
+			AccessModes: sourcePVC.Spec.AccessModes,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: request,
+				},
+			},
+			// Pre-bind to the destination PV
+			VolumeName: destPVName,
+		},
+	}
+
+	if unclaimed {
+		delete(destPVC.Labels, "migration.aqua.io/source-pvc")
+		delete(destPVC.Annotations, "migration.aqua.io/source-pvc-uid")
+	}
+	destPVC.Labels = passthroughMetadata(destPVC.Labels, sourcePVC.Labels, config.Passthrough.LabelPrefixes)
+	destPVC.Annotations = passthroughMetadata(destPVC.Annotations, sourcePVC.Annotations, config.Passthrough.AnnotationPrefixes)
+
+	translateDataSource(sourcePVC, destPVC, config.DataSourcePolicy, config.DestNamespace)
+
+	// Set the StorageClass on the PVC, even an empty one: a PVC without one
+	// is given the default StorageClass and never binds a statically
+	// provisioned PV that has none
+	destPVC.Spec.StorageClassName = &destStorageClass
+
+	// Copy volume mode if set
+	if sourcePVC.Spec.VolumeMode != nil {
+		destPVC.Spec.VolumeMode = sourcePVC.Spec.VolumeMode
+	}
+
+	return &TranslationResult{
+		PV:               destPV,
+		PVC:              destPVC,
+		VolumeID:         volumeID,
+		AvailabilityZone: az,
+		Warnings:         warnings,
+	}, nil
+}
+
+// EBSVolumeID returns the EBS volume ID of a CSI or in-tree EBS PV
+func EBSVolumeID(pv *corev1.PersistentVolume) (string, error) {
+	// Check CSI volume source first (modern approach)
+	if pv.Spec.CSI != nil {
+		if pv.Spec.CSI.Driver == "ebs.csi.aws.com" {
+			// The volume handle is the EBS volume ID
+			return pv.Spec.CSI.VolumeHandle, nil
+		}
+		return "", fmt.Errorf("unsupported CSI driver: %s (expected ebs.csi.aws.com)", pv.Spec.CSI.Driver)
+	}
+
+	// Check legacy AWS EBS volume source
+	if pv.Spec.AWSElasticBlockStore != nil {
+		// The VolumeID field contains the full ARN or volume ID
+		volumeID := pv.Spec.AWSElasticBlockStore.VolumeID
+		// Extract just the volume ID if it's a full path (aws://zone/vol-xxx)
+		if strings.Contains(volumeID, "/") {
+			parts := strings.Split(volumeID, "/")
+			volumeID = parts[len(parts)-1]
+		}
+		return volumeID, nil
+	}
+
+	return "", fmt.Errorf("PV %s does not have an EBS volume source (neither CSI nor AWSElasticBlockStore)", pv.Name)
+}
+
+// AvailabilityZone returns the zone a PV's node affinity requires, or ""
+func AvailabilityZone(pv *corev1.PersistentVolume) string {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return ""
+	}
+
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			// Check for the standard topology label
+			if expr.Key == "topology.kubernetes.io/zone" && len(expr.Values) > 0 {
+				return expr.Values[0]
+			}
+			// Check for the legacy label
+			if expr.Key == "failure-domain.beta.kubernetes.io/zone" && len(expr.Values) > 0 {
+				return expr.Values[0]
+			}
+		}
+	}
+
+	return ""
+}
+
+// buildNodeAffinityForZone creates a NodeAffinity that constrains the PV to a specific zone
+func buildNodeAffinityForZone(zone string) *corev1.VolumeNodeAffinity {
+	return &corev1.VolumeNodeAffinity{
+		Required: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{
+							Key:      "topology.kubernetes.io/zone",
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{zone},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// buildPVSource creates the PersistentVolumeSource for the destination PV,
+// with warnings for CSI volumeAttributes that may not be portable
+func buildPVSource(sourcePV *corev1.PersistentVolume, volumeID string) (corev1.PersistentVolumeSource, []string) {
+	// Prefer CSI (modern approach)
+	if sourcePV.Spec.CSI != nil {
+		// Drop cluster-specific volume attributes
+		attrs, warnings := scrubVolumeAttributes(sourcePV.Spec.CSI.VolumeAttributes)
+		return corev1.PersistentVolumeSource{
+			CSI: &corev1.CSIPersistentVolumeSource{
+				Driver:           sourcePV.Spec.CSI.Driver,
+				VolumeHandle:     volumeID,
+				FSType:           sourcePV.Spec.CSI.FSType,
+				ReadOnly:         sourcePV.Spec.CSI.ReadOnly,
+				VolumeAttributes: attrs,
+			},
+		}, warnings
+	}
+
+	// Fallback to legacy AWSElasticBlockStore
+	if sourcePV.Spec.AWSElasticBlockStore != nil {
+		return corev1.PersistentVolumeSource{
+			AWSElasticBlockStore: &corev1.AWSElasticBlockStoreVolumeSource{
+				VolumeID:  volumeID,
+				FSType:    sourcePV.Spec.AWSElasticBlockStore.FSType,
+				Partition: sourcePV.Spec.AWSElasticBlockStore.Partition,
+				ReadOnly:  sourcePV.Spec.AWSElasticBlockStore.ReadOnly,
+			},
+		}, nil
+	}
+
+	// This shouldn't happen if EBSVolumeID succeeded
+	return corev1.PersistentVolumeSource{}, nil
+}
+
+// DestStorageClass returns the StorageClass a mapping gives a source
+// StorageClass, which is unchanged when the mapping leaves it out
+func DestStorageClass(sourceStorageClass string, mapping map[string]string) string {
+	if mapping != nil {
+		if dest, ok := mapping[sourceStorageClass]; ok {
+			return dest
+		}
+	}
+	return sourceStorageClass
+}
+
+// GetPVCNameForStatefulSetPod returns the PVC name for a StatefulSet pod
+// StatefulSet PVC naming convention: <volumeClaimTemplateName>-<stsName>-<index>
+func GetPVCNameForStatefulSetPod(volumeClaimTemplateName, stsName string, index int) string {
+	return fmt.Sprintf("%s-%s-%d", volumeClaimTemplateName, stsName, index)
+}
+
+// ValidatePVForMigration performs validation checks on a bound PV before
+// migration; ValidatePV relaxes them for statically provisioned PVs
+func ValidatePVForMigration(pv *corev1.PersistentVolume) error {
+	return ValidatePV(pv, PVValidationOptions{})
+}
+
+// destPVCRequest returns the storage request for the destination PVC. A PVC
+// only binds to a PV whose capacity covers its request, and the source PVC's
+// request can exceed its PV's capacity: a resize that never completed, or a
+// request in decimal units (10G) against a capacity in binary ones (10Gi) or
+// vice versa. Such a request, or a missing one, becomes the PV's capacity.
+// A request equal to the capacity takes the PV's units, so both sides read
+// the same; a smaller request is kept, since it binds.
+func destPVCRequest(pvc *corev1.PersistentVolumeClaim, pv *corev1.PersistentVolume) resource.Quantity {
+	request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	capacity, ok := pv.Spec.Capacity[corev1.ResourceStorage]
+	if !ok || capacity.IsZero() {
+		return request
+	}
+	if request.IsZero() || request.Cmp(capacity) >= 0 {
+		return capacity.DeepCopy()
+	}
+	return request
+}
+
+// volumeCapacity returns the capacity of the destination PV: the source
+// PV's, or the volume's size from EBS when that is larger, such as after the
+// volume was expanded outside Kubernetes or by a resize the PV never caught
+// up with. The second result reports the latter; the size is then in GiB, as
+// EBS sizes volumes.
+func volumeCapacity(pv *corev1.PersistentVolume, sizeGiB int32) (resource.Quantity, bool) {
+	capacity := pv.Spec.Capacity[corev1.ResourceStorage]
+	if sizeGiB <= 0 {
+		return capacity, false
+	}
+	size := *resource.NewQuantity(int64(sizeGiB)<<30, resource.BinarySI)
+	if size.Cmp(capacity) <= 0 {
+		return capacity, false
+	}
+	return size, true
+}
+
+// CalculateStorageSize returns the storage size from a PV or PVC
+func CalculateStorageSize(pv *corev1.PersistentVolume) resource.Quantity {
+	if pv == nil {
+		return resource.Quantity{}
+	}
+	return pv.Spec.Capacity[corev1.ResourceStorage]
+}
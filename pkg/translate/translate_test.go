@@ -1,4 +1,4 @@
-package migration
+package translate
 
 import (
 	"testing"
@@ -227,7 +227,43 @@ func TestTranslatePV(t *testing.T) {
 			},
 		},
 		{
-			name: "nil PV should error",
+			name: "transferred volume replaces the volume handle",
+			sourcePV: &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pvc-12345"},
+				Spec: corev1.PersistentVolumeSpec{
+					Capacity: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse("10Gi"),
+					},
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{
+							Driver:       "ebs.csi.aws.com",
+							VolumeHandle: "vol-0123456789abcdef0",
+						},
+					},
+				},
+			},
+			sourcePVC: &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "data-web-0", Namespace: "source-ns"},
+			},
+			config: PVTranslationConfig{
+				DestNamespace: "dest-ns",
+				DestPVCName:   "data-web-0",
+				VolumeID:      "vol-0fedcba9876543210",
+			},
+			validate: func(t *testing.T, result *TranslationResult) {
+				if result.PV.Spec.CSI.VolumeHandle != "vol-0fedcba9876543210" {
+					t.Errorf("expected volume handle vol-0fedcba9876543210, got %s", result.PV.Spec.CSI.VolumeHandle)
+				}
+				if result.VolumeID != "vol-0fedcba9876543210" {
+					t.Errorf("expected volume ID vol-0fedcba9876543210, got %s", result.VolumeID)
+				}
+				if got := result.PVC.Annotations["migration.aqua.io/volume-id"]; got != "vol-0fedcba9876543210" {
+					t.Errorf("expected PVC volume-id annotation vol-0fedcba9876543210, got %s", got)
+				}
+			},
+		},
+		{
+			name:     "nil PV should error",
 			sourcePV: nil,
 			sourcePVC: &corev1.PersistentVolumeClaim{
 				ObjectMeta: metav1.ObjectMeta{Name: "test"},
@@ -291,7 +327,7 @@ func TestTranslatePV(t *testing.T) {
 	}
 }
 
-func TestExtractEBSVolumeID(t *testing.T) {
+func TestEBSVolumeID(t *testing.T) {
 	tests := []struct {
 		name    string
 		pv      *corev1.PersistentVolume
@@ -359,19 +395,19 @@ func TestExtractEBSVolumeID(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := extractEBSVolumeID(tt.pv)
+			got, err := EBSVolumeID(tt.pv)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("extractEBSVolumeID() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("EBSVolumeID() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if got != tt.want {
-				t.Errorf("extractEBSVolumeID() = %v, want %v", got, tt.want)
+				t.Errorf("EBSVolumeID() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestExtractAvailabilityZone(t *testing.T) {
+func TestAvailabilityZone(t *testing.T) {
 	tests := []struct {
 		name string
 		pv   *corev1.PersistentVolume
@@ -434,9 +470,9 @@ func TestExtractAvailabilityZone(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := extractAvailabilityZone(tt.pv)
+			got := AvailabilityZone(tt.pv)
 			if got != tt.want {
-				t.Errorf("extractAvailabilityZone() = %v, want %v", got, tt.want)
+				t.Errorf("AvailabilityZone() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -570,3 +606,92 @@ func TestValidatePVForMigration(t *testing.T) {
 		})
 	}
 }
+
+func TestDestPVCRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  string
+		capacity string
+		want     string
+	}{
+		{name: "equal", request: "10Gi", capacity: "10Gi", want: "10Gi"},
+		{name: "equal in other units", request: "10737418240", capacity: "10Gi", want: "10Gi"},
+		{name: "smaller request is kept", request: "8Gi", capacity: "10Gi", want: "8Gi"},
+		{name: "decimal request below binary capacity", request: "10G", capacity: "10Gi", want: "10G"},
+		{name: "binary request above decimal capacity", request: "10Gi", capacity: "10G", want: "10G"},
+		{name: "unfinished resize", request: "20Gi", capacity: "10Gi", want: "10Gi"},
+		{name: "missing request", capacity: "10Gi", want: "10Gi"},
+		{name: "missing capacity", request: "10Gi", want: "10Gi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pvc := &corev1.PersistentVolumeClaim{}
+			if tt.request != "" {
+				pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(tt.request)}
+			}
+			pv := &corev1.PersistentVolume{}
+			if tt.capacity != "" {
+				pv.Spec.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(tt.capacity)}
+			}
+
+			got := destPVCRequest(pvc, pv)
+			if got.String() != tt.want {
+				t.Errorf("destPVCRequest() = %s, want %s", got.String(), tt.want)
+			}
+			if capacity, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok && got.Cmp(capacity) > 0 {
+				t.Errorf("destPVCRequest() = %s exceeds the PV capacity %s, so the PVC would not bind", got.String(), capacity.String())
+			}
+		})
+	}
+}
+
+func TestTranslatePVVolumeSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		sizeGiB     int32
+		wantPV      string
+		wantPVC     string
+		wantWarning bool
+	}{
+		{name: "size unknown", wantPV: "100Gi", wantPVC: "100Gi"},
+		{name: "size matches the PV", sizeGiB: 100, wantPV: "100Gi", wantPVC: "100Gi"},
+		{name: "volume expanded beyond the PV", sizeGiB: 250, wantPV: "250Gi", wantPVC: "250Gi", wantWarning: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pvc-12345"},
+				Spec: corev1.PersistentVolumeSpec{
+					Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-0123456789abcdef0"},
+					},
+				},
+			}
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "source-ns", Name: "data-web-0"},
+				Spec: corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+				}},
+			}
+
+			result, err := TranslatePV(pv, pvc, PVTranslationConfig{DestNamespace: "dest-ns", DestPVCName: "data-web-0", VolumeSizeGiB: tt.sizeGiB})
+			if err != nil {
+				t.Fatal(err)
+			}
+			capacity := result.PV.Spec.Capacity[corev1.ResourceStorage]
+			if capacity.String() != tt.wantPV {
+				t.Errorf("PV capacity = %s, want %s", capacity.String(), tt.wantPV)
+			}
+			request := result.PVC.Spec.Resources.Requests[corev1.ResourceStorage]
+			if request.String() != tt.wantPVC {
+				t.Errorf("PVC request = %s, want %s", request.String(), tt.wantPVC)
+			}
+			if got := len(result.Warnings) > 0; got != tt.wantWarning {
+				t.Errorf("warnings = %v, want a warning: %v", result.Warnings, tt.wantWarning)
+			}
+		})
+	}
+}
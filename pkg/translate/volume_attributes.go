@@ -0,0 +1,45 @@
+package translate
+
+import (
+	"fmt"
+	"sort"
+)
+
+// clusterVolumeAttributes are CSI volumeAttributes that identify the cluster
+// or provisioner instance that created the volume rather than the volume
+// itself. They are dropped from the destination PV.
+var clusterVolumeAttributes = map[string]bool{
+	// external-provisioner records its own identity, which is unique per
+	// cluster and restart; a destination driver has nothing to match it to
+	"storage.kubernetes.io/csiProvisionerIdentity": true,
+}
+
+// portableVolumeAttributes are CSI volumeAttributes known to describe the
+// volume and to mean the same to the destination CSI driver
+var portableVolumeAttributes = map[string]bool{
+	// Set by CSI migration for in-tree EBS volumes on a partition
+	"partition": true,
+}
+
+// scrubVolumeAttributes returns a copy of a source PV's CSI volumeAttributes
+// without the cluster-specific ones, and a warning for each attribute that
+// is copied without being known to be portable
+func scrubVolumeAttributes(attrs map[string]string) (map[string]string, []string) {
+	if attrs == nil {
+		return nil, nil
+	}
+
+	scrubbed := make(map[string]string, len(attrs))
+	var warnings []string
+	for key, value := range attrs {
+		if clusterVolumeAttributes[key] {
+			continue
+		}
+		scrubbed[key] = value
+		if !portableVolumeAttributes[key] {
+			warnings = append(warnings, fmt.Sprintf("CSI volumeAttribute %s=%q is copied to the destination PV unchanged; check that it is not specific to the source cluster", key, value))
+		}
+	}
+	sort.Strings(warnings)
+	return scrubbed, warnings
+}
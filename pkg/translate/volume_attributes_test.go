@@ -0,0 +1,74 @@
+package translate
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestScrubVolumeAttributes(t *testing.T) {
+	tests := []struct {
+		name         string
+		attrs        map[string]string
+		want         map[string]string
+		wantWarnings int
+	}{
+		{name: "none"},
+		{
+			name:  "provisioner identity dropped",
+			attrs: map[string]string{"storage.kubernetes.io/csiProvisionerIdentity": "1690000000000-8081-ebs.csi.aws.com"},
+			want:  map[string]string{},
+		},
+		{
+			name:  "portable attribute copied",
+			attrs: map[string]string{"partition": "1"},
+			want:  map[string]string{"partition": "1"},
+		},
+		{
+			name: "unknown attribute copied with a warning",
+			attrs: map[string]string{
+				"storage.kubernetes.io/csiProvisionerIdentity": "1690000000000-8081-ebs.csi.aws.com",
+				"example.com/cluster":                          "prod-east",
+			},
+			want:         map[string]string{"example.com/cluster": "prod-east"},
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings := scrubVolumeAttributes(tt.attrs)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scrubVolumeAttributes() = %v, want %v", got, tt.want)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("scrubVolumeAttributes() warnings = %q, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestTranslatePVVolumeAttributes(t *testing.T) {
+	sourcePV := csiPV("pvc-12345", "vol-abc")
+	sourcePV.Spec.CSI.VolumeAttributes = map[string]string{
+		"storage.kubernetes.io/csiProvisionerIdentity": "1690000000000-8081-ebs.csi.aws.com",
+		"example.com/cluster":                          "prod-east",
+	}
+	sourcePVC := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "prod", Name: "data-web-0"}}
+
+	result, err := TranslatePV(&sourcePV, sourcePVC, PVTranslationConfig{DestNamespace: "prod", DestPVCName: "data-web-0"})
+	if err != nil {
+		t.Fatalf("TranslatePV() error = %v", err)
+	}
+	if want := map[string]string{"example.com/cluster": "prod-east"}; !reflect.DeepEqual(result.PV.Spec.CSI.VolumeAttributes, want) {
+		t.Errorf("volumeAttributes = %v, want %v", result.PV.Spec.CSI.VolumeAttributes, want)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Warnings = %q, want 1 warning", result.Warnings)
+	}
+	if _, ok := sourcePV.Spec.CSI.VolumeAttributes["storage.kubernetes.io/csiProvisionerIdentity"]; !ok {
+		t.Error("TranslatePV() modified the source PV")
+	}
+}
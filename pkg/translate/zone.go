@@ -0,0 +1,36 @@
+package translate
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// zoneLabels are the node affinity keys that pin an EBS volume to its zone
+var zoneLabels = []string{corev1.LabelTopologyZone, corev1.LabelFailureDomainBetaZone, "topology.ebs.csi.aws.com/zone"}
+
+// WithAvailabilityZone returns a copy of pv whose node affinity requires zone
+// in place of the zone it names, for a volume restored from a snapshot in
+// another zone. Requirements on other labels, such as an Outpost, are kept.
+func WithAvailabilityZone(pv *corev1.PersistentVolume, zone string) *corev1.PersistentVolume {
+	pv = pv.DeepCopy()
+	affinity := pv.Spec.NodeAffinity
+	if affinity == nil || affinity.Required == nil || len(affinity.Required.NodeSelectorTerms) == 0 {
+		pv.Spec.NodeAffinity = buildNodeAffinityForZone(zone)
+		return pv
+	}
+
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      corev1.LabelTopologyZone,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{zone},
+	}
+	terms := affinity.Required.NodeSelectorTerms
+	for i := range terms {
+		terms[i].MatchExpressions = slices.DeleteFunc(terms[i].MatchExpressions, func(expr corev1.NodeSelectorRequirement) bool {
+			return slices.Contains(zoneLabels, expr.Key)
+		})
+		terms[i].MatchExpressions = append(terms[i].MatchExpressions, requirement)
+	}
+	return pv
+}
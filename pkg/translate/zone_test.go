@@ -0,0 +1,50 @@
+package translate
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestWithAvailabilityZone(t *testing.T) {
+	requirement := func(key, value string) corev1.NodeSelectorRequirement {
+		return corev1.NodeSelectorRequirement{Key: key, Operator: corev1.NodeSelectorOpIn, Values: []string{value}}
+	}
+	affinity := func(exprs ...corev1.NodeSelectorRequirement) *corev1.VolumeNodeAffinity {
+		return &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: exprs}},
+		}}
+	}
+	want := requirement(corev1.LabelTopologyZone, "us-west-2b")
+
+	tests := []struct {
+		name     string
+		affinity *corev1.VolumeNodeAffinity
+		want     *corev1.VolumeNodeAffinity
+	}{
+		{name: "no affinity", affinity: nil, want: affinity(want)},
+		{name: "zone", affinity: affinity(requirement(corev1.LabelTopologyZone, "us-west-2a")), want: affinity(want)},
+		{name: "legacy and CSI zone", affinity: affinity(requirement(corev1.LabelFailureDomainBetaZone, "us-west-2a"),
+			requirement("topology.ebs.csi.aws.com/zone", "us-west-2a")), want: affinity(want)},
+		{name: "other labels kept", affinity: affinity(requirement(corev1.LabelTopologyZone, "us-west-2a"), requirement(OutpostIDLabel, "op-1")),
+			want: affinity(requirement(OutpostIDLabel, "op-1"), want)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{NodeAffinity: tt.affinity}}
+			original := pv.DeepCopy()
+			got := WithAvailabilityZone(pv, "us-west-2b")
+			if !reflect.DeepEqual(got.Spec.NodeAffinity, tt.want) {
+				t.Errorf("WithAvailabilityZone() affinity = %+v, want %+v", got.Spec.NodeAffinity, tt.want)
+			}
+			if AvailabilityZone(got) != "us-west-2b" {
+				t.Errorf("AvailabilityZone() = %q, want us-west-2b", AvailabilityZone(got))
+			}
+			if !reflect.DeepEqual(pv, original) {
+				t.Errorf("WithAvailabilityZone() modified its argument")
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+)
+
+const (
+	// virtualNodeName is the node the test pods run on in each cluster
+	virtualNodeName = "aqua-integration-node"
+
+	// virtualNodeTaint keeps everything but the test pods off the virtual node
+	virtualNodeTaint = "migration.aqua.io/integration"
+
+	// heartbeatInterval is how often the virtual node renews its lease
+	heartbeatInterval = 10 * time.Second
+)
+
+// virtualNode stands in for a kubelet with the EBS CSI node plugin, which kind
+// cannot run. It registers a Node and CSINode in the test zone, keeps them
+// alive, reports its pods Running and Ready, and completes their deletion,
+// so the StatefulSet controller and the migration controller see pods come
+// and go as they would on EKS. Volumes are never really mounted.
+type virtualNode struct {
+	client client.Client
+	zone   string
+}
+
+// start registers the node and runs it until ctx is done
+func (n *virtualNode) start(ctx context.Context, t *testing.T) {
+	t.Helper()
+	if err := n.register(ctx); err != nil {
+		t.Fatalf("failed to register virtual node: %v", err)
+	}
+
+	go func() {
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+		pods := time.NewTicker(time.Second)
+		defer pods.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-heartbeat.C:
+				if err := n.heartbeat(ctx); err != nil && ctx.Err() == nil {
+					t.Logf("virtual node heartbeat failed: %v", err)
+				}
+			case <-pods.C:
+				if err := n.syncPods(ctx); err != nil && ctx.Err() == nil {
+					t.Logf("virtual node pod sync failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// register creates the node, its CSINode and the EBS CSIDriver. The driver
+// does not require attachment, so no VolumeAttachments are created for the
+// test volumes.
+func (n *virtualNode) register(ctx context.Context) error {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: virtualNodeName,
+			Labels: map[string]string{
+				corev1.LabelHostname:       virtualNodeName,
+				corev1.LabelOSStable:       string(corev1.Linux),
+				corev1.LabelArchStable:     "amd64",
+				corev1.LabelTopologyZone:   n.zone,
+				corev1.LabelTopologyRegion: n.zone[:len(n.zone)-1],
+			},
+		},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{{Key: virtualNodeTaint, Value: "true", Effect: corev1.TaintEffectNoSchedule}},
+		},
+	}
+	if err := n.client.Create(ctx, node); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create node: %w", err)
+	}
+
+	csiNode := &storagev1.CSINode{
+		ObjectMeta: metav1.ObjectMeta{Name: virtualNodeName},
+		Spec: storagev1.CSINodeSpec{Drivers: []storagev1.CSINodeDriver{{
+			Name:         migration.EBSCSIDriver,
+			NodeID:       "i-" + virtualNodeName,
+			TopologyKeys: []string{corev1.LabelTopologyZone},
+			Allocatable:  &storagev1.VolumeNodeResources{Count: ptr.To[int32](25)},
+		}}},
+	}
+	if err := n.client.Create(ctx, csiNode); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create CSINode: %w", err)
+	}
+
+	driver := &storagev1.CSIDriver{
+		ObjectMeta: metav1.ObjectMeta{Name: migration.EBSCSIDriver},
+		Spec:       storagev1.CSIDriverSpec{AttachRequired: ptr.To(false)},
+	}
+	if err := n.client.Create(ctx, driver); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create CSIDriver: %w", err)
+	}
+
+	return n.heartbeat(ctx)
+}
+
+// heartbeat reports the node Ready and renews its lease, so the node
+// lifecycle controller does not taint it and evict the test pods
+func (n *virtualNode) heartbeat(ctx context.Context) error {
+	now := metav1.Now()
+
+	node := &corev1.Node{}
+	if err := n.client.Get(ctx, types.NamespacedName{Name: virtualNodeName}, node); err != nil {
+		return fmt.Errorf("failed to get node: %w", err)
+	}
+	capacity := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("8"),
+		corev1.ResourceMemory: resource.MustParse("32Gi"),
+		corev1.ResourcePods:   resource.MustParse("110"),
+	}
+	node.Status.Capacity = capacity
+	node.Status.Allocatable = capacity
+	node.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.255.0.1"}}
+	node.Status.Conditions = []corev1.NodeCondition{{
+		Type:               corev1.NodeReady,
+		Status:             corev1.ConditionTrue,
+		Reason:             "KubeletReady",
+		Message:            "virtual node for integration tests",
+		LastHeartbeatTime:  now,
+		LastTransitionTime: now,
+	}}
+	if err := n.client.Status().Update(ctx, node); err != nil {
+		return fmt.Errorf("failed to update node status: %w", err)
+	}
+
+	lease := &coordinationv1.Lease{}
+	key := types.NamespacedName{Namespace: corev1.NamespaceNodeLease, Name: virtualNodeName}
+	err := n.client.Get(ctx, key, lease)
+	switch {
+	case apierrors.IsNotFound(err):
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.To(virtualNodeName),
+				LeaseDurationSeconds: ptr.To[int32](40),
+				RenewTime:            &metav1.MicroTime{Time: now.Time},
+			},
+		}
+		err = n.client.Create(ctx, lease)
+	case err == nil:
+		lease.Spec.RenewTime = &metav1.MicroTime{Time: now.Time}
+		err = n.client.Update(ctx, lease)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to renew node lease: %w", err)
+	}
+	return nil
+}
+
+// syncPods starts the pods scheduled to the node and finishes deleting the
+// ones being deleted, as a kubelet would once their containers stopped
+func (n *virtualNode) syncPods(ctx context.Context) error {
+	pods := &corev1.PodList{}
+	if err := n.client.List(ctx, pods, client.MatchingFields{"spec.nodeName": virtualNodeName}); err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			if err := n.client.Delete(ctx, pod, client.GracePeriodSeconds(0)); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+			continue
+		}
+		if pod.Status.Phase == corev1.PodRunning {
+			continue
+		}
+
+		now := metav1.Now()
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.HostIP = "10.255.0.1"
+		pod.Status.PodIP = fmt.Sprintf("10.255.%d.%d", 1+i/250, 1+i%250)
+		pod.Status.StartTime = &now
+		pod.Status.Conditions = nil
+		for _, condType := range []corev1.PodConditionType{corev1.PodScheduled, corev1.PodInitialized, corev1.ContainersReady, corev1.PodReady} {
+			pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+				Type: condType, Status: corev1.ConditionTrue, LastTransitionTime: now,
+			})
+		}
+		pod.Status.ContainerStatuses = nil
+		for _, c := range pod.Spec.Containers {
+			pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+				Name:    c.Name,
+				Image:   c.Image,
+				Ready:   true,
+				Started: ptr.To(true),
+				State:   corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: now}},
+			})
+		}
+		if err := n.client.Status().Update(ctx, pod); client.IgnoreNotFound(err) != nil && !apierrors.IsConflict(err) {
+			return fmt.Errorf("failed to update pod %s/%s status: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+	return nil
+}
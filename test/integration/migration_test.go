@@ -0,0 +1,426 @@
+//go:build integration
+
+// Package integration runs migrations end to end against two real clusters.
+// The tests are skipped unless SOURCE_KUBECONFIG, DEST_KUBECONFIG and
+// AWS_ENDPOINT_URL are set; make local-env-up and make test-integration-local
+// provide all three with kind and LocalStack.
+package integration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/controller"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+const (
+	// testZone is the availability zone of the test volumes and virtual nodes
+	testZone = "us-east-1a"
+
+	// testStorageClass is the storage class of the test volumes in both clusters
+	testStorageClass = "ebs-integration"
+
+	// testReplicas is the number of pods, and volumes, the test migrates
+	testReplicas = 2
+
+	// migrationTimeout bounds how long a test migration may take
+	migrationTimeout = 10 * time.Minute
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(migrationv1alpha1.AddToScheme(scheme))
+}
+
+// testEnv holds clients for the two clusters and LocalStack
+type testEnv struct {
+	sourceConfig     *rest.Config
+	source, dest     client.Client
+	sourceKubeconfig []byte
+	destKubeconfig   []byte
+	ec2              *ec2.Client
+	endpoint         string
+	namespace        string
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	sourcePath, destPath := os.Getenv("SOURCE_KUBECONFIG"), os.Getenv("DEST_KUBECONFIG")
+	endpoint := os.Getenv("AWS_ENDPOINT_URL")
+	if sourcePath == "" || destPath == "" || endpoint == "" {
+		t.Skip("SOURCE_KUBECONFIG, DEST_KUBECONFIG and AWS_ENDPOINT_URL must be set; see make local-env-up")
+	}
+
+	env := &testEnv{endpoint: endpoint, namespace: fmt.Sprintf("aqua-it-%d", time.Now().Unix())}
+	env.sourceKubeconfig, env.sourceConfig, env.source = loadCluster(t, sourcePath)
+	env.destKubeconfig, _, env.dest = loadCluster(t, destPath)
+
+	awsCfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		t.Fatalf("failed to load AWS config: %v", err)
+	}
+	env.ec2 = ec2.NewFromConfig(awsCfg, func(o *ec2.Options) { o.BaseEndpoint = awssdk.String(endpoint) })
+	return env
+}
+
+// loadCluster reads a kubeconfig and returns it with a client for its cluster
+func loadCluster(t *testing.T, path string) ([]byte, *rest.Config, client.Client) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read kubeconfig %s: %v", path, err)
+	}
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(data)
+	if err != nil {
+		t.Fatalf("failed to parse kubeconfig %s: %v", path, err)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatalf("failed to create client for %s: %v", path, err)
+	}
+	return data, cfg, c
+}
+
+// create creates obj, failing the test on error, and deletes it at cleanup
+func create(ctx context.Context, t *testing.T, c client.Client, obj client.Object) {
+	t.Helper()
+	if err := c.Create(ctx, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+		t.Fatalf("failed to create %T %s: %v", obj, obj.GetName(), err)
+	}
+	t.Cleanup(func() {
+		_ = c.Delete(context.Background(), obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	})
+}
+
+// createVolume creates an EBS volume in LocalStack and deletes it at cleanup
+func (e *testEnv) createVolume(ctx context.Context, t *testing.T) string {
+	t.Helper()
+	out, err := e.ec2.CreateVolume(ctx, &ec2.CreateVolumeInput{
+		AvailabilityZone: awssdk.String(testZone),
+		Size:             awssdk.Int32(1),
+	})
+	if err != nil {
+		t.Fatalf("failed to create volume: %v", err)
+	}
+	volumeID := awssdk.ToString(out.VolumeId)
+	t.Cleanup(func() {
+		_, _ = e.ec2.DeleteVolume(context.Background(), &ec2.DeleteVolumeInput{VolumeId: awssdk.String(volumeID)})
+	})
+	return volumeID
+}
+
+// setUpCluster starts a virtual node in a cluster and creates the test
+// namespace, storage class and headless service in it
+func (e *testEnv) setUpCluster(ctx context.Context, t *testing.T, c client.Client) {
+	t.Helper()
+	(&virtualNode{client: c, zone: testZone}).start(ctx, t)
+	create(ctx, t, c, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: e.namespace}})
+	create(ctx, t, c, &storagev1.StorageClass{
+		ObjectMeta:        metav1.ObjectMeta{Name: testStorageClass},
+		Provisioner:       migration.EBSCSIDriver,
+		VolumeBindingMode: ptr.To(storagev1.VolumeBindingWaitForFirstConsumer),
+	})
+	create(ctx, t, c, &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: e.namespace, Name: "web"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  map[string]string{"app": "web"},
+			Ports:     []corev1.ServicePort{{Name: "http", Port: 80}},
+		},
+	})
+}
+
+// createSourceWorkload creates the StatefulSet to migrate, with each replica's
+// PVC statically bound to a LocalStack volume
+func (e *testEnv) createSourceWorkload(ctx context.Context, t *testing.T) map[string]string {
+	t.Helper()
+	volumes := map[string]string{}
+	for i := 0; i < testReplicas; i++ {
+		pvcName := fmt.Sprintf("data-web-%d", i)
+		volumeID := e.createVolume(ctx, t)
+		volumes[pvcName] = volumeID
+
+		create(ctx, t, e.source, &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%s", e.namespace, pvcName)},
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity:                      corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+				AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+				StorageClassName:              testStorageClass,
+				ClaimRef:                      &corev1.ObjectReference{Namespace: e.namespace, Name: pvcName},
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{Driver: migration.EBSCSIDriver, VolumeHandle: volumeID, FSType: "ext4"},
+				},
+				NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{testZone},
+					}}}},
+				}},
+			},
+		})
+		create(ctx, t, e.source, &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: e.namespace, Name: pvcName, Labels: map[string]string{"app": "web"}},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: ptr.To(testStorageClass),
+				VolumeName:       fmt.Sprintf("%s-%s", e.namespace, pvcName),
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			},
+		})
+	}
+
+	labels := map[string]string{"app": "web"}
+	create(ctx, t, e.source, &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: e.namespace, Name: "web"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    ptr.To[int32](testReplicas),
+			ServiceName: "web",
+			Selector:    &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					NodeSelector: map[string]string{corev1.LabelHostname: virtualNodeName},
+					Tolerations:  []corev1.Toleration{{Key: virtualNodeTaint, Operator: corev1.TolerationOpExists}},
+					Containers: []corev1.Container{{
+						Name:         "web",
+						Image:        "registry.k8s.io/pause:3.10",
+						VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+					}},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "data", Labels: labels},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: ptr.To(testStorageClass),
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			}},
+		},
+	})
+	waitStatefulSetReady(ctx, t, e.source, e.namespace, "web")
+	return volumes
+}
+
+// startController runs the migration controller in-process against the
+// source cluster, which doubles as the management cluster, replaying every
+// step with replay when set
+func (e *testEnv) startController(ctx context.Context, t *testing.T, replay *controller.Replayer) {
+	t.Helper()
+	ctrl.SetLogger(zap.New(zap.UseDevMode(true), zap.WriteTo(testWriter{t})))
+
+	mgr, err := ctrl.NewManager(e.sourceConfig, ctrl.Options{
+		Scheme:                 scheme,
+		Metrics:                metricsserver.Options{BindAddress: "0"},
+		HealthProbeBindAddress: "0",
+	})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	ebsClient, err := aws.NewEBSClient(ctx, aws.EBSClientConfig{Region: os.Getenv("AWS_REGION"), Endpoint: e.endpoint})
+	if err != nil {
+		t.Fatalf("failed to create EBS client: %v", err)
+	}
+	if err := (&controller.StatefulSetMigrationReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		ClientManager: multicluster.NewClientManager(scheme, mgr.GetClient()),
+		EBSClient:     ebsClient,
+		Recorder:      mgr.GetEventRecorderFor("statefulsetmigration-controller"),
+		Replay:        replay,
+	}).SetupWithManager(mgr); err != nil {
+		t.Fatalf("failed to set up controller: %v", err)
+	}
+
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			t.Errorf("manager stopped: %v", err)
+		}
+	}()
+}
+
+// testWriter sends controller logs to the test log
+type testWriter struct{ t *testing.T }
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Log(string(p))
+	return len(p), nil
+}
+
+func waitStatefulSetReady(ctx context.Context, t *testing.T, c client.Client, namespace, name string) {
+	t.Helper()
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, 3*time.Minute, true, func(ctx context.Context) (bool, error) {
+		sts := &appsv1.StatefulSet{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, sts); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return sts.Status.ReadyReplicas == ptr.Deref(sts.Spec.Replicas, 1), nil
+	})
+	if err != nil {
+		t.Fatalf("StatefulSet %s/%s did not become ready: %v", namespace, name, err)
+	}
+}
+
+// TestLocalMigration migrates a two-replica StatefulSet between the clusters
+// and checks that its volumes were re-bound in the destination
+func TestLocalMigration(t *testing.T) {
+	runLocalMigration(t, newTestEnv(t), nil)
+}
+
+// TestLocalMigrationReplay runs the migration of TestLocalMigration with
+// every step replayed, as after a crash before its status was written, and
+// checks that no replay failed, changed the outcome, or added or removed
+// objects in either cluster
+func TestLocalMigrationReplay(t *testing.T) {
+	env := newTestEnv(t)
+	replay := &controller.Replayer{Inventory: env.inventory}
+	runLocalMigration(t, env, replay)
+
+	if replay.Replays() == 0 {
+		t.Error("no step was replayed")
+	}
+	for _, violation := range replay.Violations() {
+		t.Errorf("step is not idempotent: %s", violation)
+	}
+}
+
+// runLocalMigration migrates the test StatefulSet in env and checks that its
+// volumes were re-bound in the destination
+func runLocalMigration(t *testing.T, env *testEnv, replay *controller.Replayer) {
+	ctx, cancel := context.WithTimeout(context.Background(), migrationTimeout+5*time.Minute)
+	t.Cleanup(cancel)
+
+	env.setUpCluster(ctx, t, env.source)
+	env.setUpCluster(ctx, t, env.dest)
+	volumes := env.createSourceWorkload(ctx, t)
+
+	for name, kubeconfig := range map[string][]byte{"source-kubeconfig": env.sourceKubeconfig, "dest-kubeconfig": env.destKubeconfig} {
+		create(ctx, t, env.source, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: env.namespace, Name: name},
+			Data:       map[string][]byte{"kubeconfig": kubeconfig},
+		})
+	}
+	env.startController(ctx, t, replay)
+
+	m := &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: env.namespace, Name: "web"},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			MigrationID:     "integration-web",
+			SourceCluster:   migrationv1alpha1.ContextRef{KubeConfigSecret: "source-kubeconfig"},
+			SourceNamespace: env.namespace,
+			StatefulSetName: "web",
+			DestCluster:     migrationv1alpha1.ContextRef{KubeConfigSecret: "dest-kubeconfig"},
+			DestNamespace:   env.namespace,
+		},
+	}
+	create(ctx, t, env.source, m)
+
+	err := wait.PollUntilContextTimeout(ctx, 5*time.Second, migrationTimeout, true, func(ctx context.Context) (bool, error) {
+		if err := env.source.Get(ctx, client.ObjectKeyFromObject(m), m); err != nil {
+			return false, err
+		}
+		switch m.Status.Phase {
+		case migrationv1alpha1.PhaseCompleted:
+			return true, nil
+		case migrationv1alpha1.PhaseFailed, migrationv1alpha1.PhaseAborted:
+			return false, fmt.Errorf("migration %s: %s", m.Status.Phase, m.Status.LastError)
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("migration did not complete (phase %s): %v", m.Status.Phase, err)
+	}
+
+	for pvcName, volumeID := range volumes {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := env.dest.Get(ctx, types.NamespacedName{Namespace: env.namespace, Name: pvcName}, pvc); err != nil {
+			t.Fatalf("failed to get destination PVC %s: %v", pvcName, err)
+		}
+		if pvc.Status.Phase != corev1.ClaimBound {
+			t.Errorf("destination PVC %s is %s, want Bound", pvcName, pvc.Status.Phase)
+		}
+		pv := &corev1.PersistentVolume{}
+		if err := env.dest.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+			t.Fatalf("failed to get destination PV %s: %v", pvc.Spec.VolumeName, err)
+		}
+		t.Cleanup(func() { _ = env.dest.Delete(context.Background(), pv) })
+		if pv.Spec.CSI == nil || pv.Spec.CSI.VolumeHandle != volumeID {
+			t.Errorf("destination PV %s does not reference volume %s", pv.Name, volumeID)
+		}
+	}
+	waitStatefulSetReady(ctx, t, env.dest, env.namespace, "web")
+}
+
+// inventory lists the objects of the test namespace a migration step may
+// create or delete in either cluster, for replays to compare. Pods are left
+// out, as their StatefulSets recreate them on their own.
+func (e *testEnv) inventory(ctx context.Context, _ *migrationv1alpha1.StatefulSetMigration) ([]string, error) {
+	var objects []string
+	for cluster, c := range map[string]client.Client{"source": e.source, "dest": e.dest} {
+		lists := map[string]client.ObjectList{
+			"StatefulSet":           &appsv1.StatefulSetList{},
+			"PersistentVolumeClaim": &corev1.PersistentVolumeClaimList{},
+			"Service":               &corev1.ServiceList{},
+			"ConfigMap":             &corev1.ConfigMapList{},
+			"Secret":                &corev1.SecretList{},
+		}
+		for kind, list := range lists {
+			if err := c.List(ctx, list, client.InNamespace(e.namespace)); err != nil {
+				return nil, err
+			}
+			if err := meta.EachListItem(list, func(obj runtime.Object) error {
+				objects = append(objects, fmt.Sprintf("%s/%s/%s", cluster, kind, obj.(client.Object).GetName()))
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		pvs := &corev1.PersistentVolumeList{}
+		if err := c.List(ctx, pvs); err != nil {
+			return nil, err
+		}
+		for _, pv := range pvs.Items {
+			if pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.Namespace == e.namespace {
+				objects = append(objects, fmt.Sprintf("%s/PersistentVolume/%s", cluster, pv.Name))
+			}
+		}
+	}
+	return objects, nil
+}
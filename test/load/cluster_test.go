@@ -0,0 +1,318 @@
+//go:build load
+
+package load
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+)
+
+const (
+	// loadNodeName is the node every simulated pod runs on
+	loadNodeName = "aqua-load-node"
+
+	// loadInstanceID is the EC2 instance loadNodeName stands for
+	loadInstanceID = "i-0123456789abcdef0"
+)
+
+// simulatedCluster stands in for the parts of a cluster envtest does not
+// run: the StatefulSet controller, the PV binder and a kubelet. It creates
+// and deletes the pods of each StatefulSet in its namespaces, reports them
+// Running and Ready on one node, finishes their deletion, and binds PVCs to
+// the PVs they name. It does just enough for migrations to move through
+// their phases; volumes are never really attached.
+type simulatedCluster struct {
+	client     client.Client
+	namespaces []string
+}
+
+// start registers the node and syncs the namespaces until ctx is done
+func (s *simulatedCluster) start(ctx context.Context, t *testing.T) {
+	t.Helper()
+	if err := s.register(ctx); err != nil {
+		t.Fatalf("failed to register simulated node: %v", err)
+	}
+	go func() {
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, ns := range s.namespaces {
+					if err := s.sync(ctx, ns); err != nil && ctx.Err() == nil {
+						t.Logf("simulated cluster sync of %s failed: %v", ns, err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// register creates the Ready node, its CSINode and the EBS CSIDriver
+func (s *simulatedCluster) register(ctx context.Context) error {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: loadNodeName,
+			Labels: map[string]string{
+				corev1.LabelHostname:       loadNodeName,
+				corev1.LabelOSStable:       string(corev1.Linux),
+				corev1.LabelArchStable:     "amd64",
+				corev1.LabelTopologyZone:   loadZone,
+				corev1.LabelTopologyRegion: loadRegion,
+			},
+		},
+		Spec: corev1.NodeSpec{ProviderID: fmt.Sprintf("aws:///%s/%s", loadZone, loadInstanceID)},
+	}
+	if err := s.client.Create(ctx, node); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create node: %w", err)
+	}
+	capacity := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1000"),
+		corev1.ResourceMemory: resource.MustParse("4Ti"),
+		corev1.ResourcePods:   resource.MustParse("10000"),
+	}
+	node.Status.Capacity = capacity
+	node.Status.Allocatable = capacity
+	node.Status.Conditions = []corev1.NodeCondition{{
+		Type:               corev1.NodeReady,
+		Status:             corev1.ConditionTrue,
+		Reason:             "KubeletReady",
+		LastHeartbeatTime:  metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+	}}
+	if err := s.client.Status().Update(ctx, node); err != nil {
+		return fmt.Errorf("failed to update node status: %w", err)
+	}
+
+	csiNode := &storagev1.CSINode{
+		ObjectMeta: metav1.ObjectMeta{Name: loadNodeName},
+		Spec: storagev1.CSINodeSpec{Drivers: []storagev1.CSINodeDriver{{
+			Name:         migration.EBSCSIDriver,
+			NodeID:       loadInstanceID,
+			TopologyKeys: []string{corev1.LabelTopologyZone},
+		}}},
+	}
+	if err := s.client.Create(ctx, csiNode); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create CSINode: %w", err)
+	}
+	driver := &storagev1.CSIDriver{
+		ObjectMeta: metav1.ObjectMeta{Name: migration.EBSCSIDriver},
+		Spec:       storagev1.CSIDriverSpec{AttachRequired: ptr.To(false)},
+	}
+	if err := s.client.Create(ctx, driver); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create CSIDriver: %w", err)
+	}
+	return nil
+}
+
+// sync reconciles the StatefulSets, pods and PVCs of one namespace
+func (s *simulatedCluster) sync(ctx context.Context, namespace string) error {
+	pods := &corev1.PodList{}
+	if err := s.client.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	existing := map[string]*corev1.Pod{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			if err := s.client.Delete(ctx, pod, client.GracePeriodSeconds(0)); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete pod %s: %w", pod.Name, err)
+			}
+			continue
+		}
+		existing[pod.Name] = pod
+		if err := s.run(ctx, pod); err != nil {
+			return err
+		}
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := s.client.List(ctx, statefulSets, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list StatefulSets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		if err := s.syncStatefulSet(ctx, &statefulSets.Items[i], existing); err != nil {
+			return err
+		}
+	}
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := s.client.List(ctx, pvcs, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list PVCs: %w", err)
+	}
+	for i := range pvcs.Items {
+		if err := s.bind(ctx, &pvcs.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncStatefulSet creates the pods a StatefulSet is missing, deletes those
+// past its replicas and reports its status, as the StatefulSet controller
+// would, without its ordering guarantees
+func (s *simulatedCluster) syncStatefulSet(ctx context.Context, sts *appsv1.StatefulSet, pods map[string]*corev1.Pod) error {
+	if sts.DeletionTimestamp != nil {
+		return nil
+	}
+	replicas := ptr.Deref(sts.Spec.Replicas, 1)
+	var ready int32
+	for name, pod := range pods {
+		var ordinal int32
+		if _, err := fmt.Sscanf(name, sts.Name+"-%d", &ordinal); err != nil || name != fmt.Sprintf("%s-%d", sts.Name, ordinal) {
+			continue
+		}
+		if ordinal >= replicas && metav1.IsControlledBy(pod, sts) {
+			if err := s.client.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete pod %s: %w", name, err)
+			}
+			continue
+		}
+		if pod.Status.Phase == corev1.PodRunning {
+			ready++
+		}
+	}
+	for ordinal := range replicas {
+		name := fmt.Sprintf("%s-%d", sts.Name, ordinal)
+		if _, ok := pods[name]; ok {
+			continue
+		}
+		if err := s.client.Create(ctx, statefulSetPod(sts, ordinal)); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create pod %s: %w", name, err)
+		}
+	}
+
+	status := appsv1.StatefulSetStatus{
+		ObservedGeneration: sts.Generation,
+		Replicas:           replicas,
+		ReadyReplicas:      ready,
+		CurrentReplicas:    replicas,
+		UpdatedReplicas:    replicas,
+		AvailableReplicas:  ready,
+		CurrentRevision:    sts.Status.CurrentRevision,
+		UpdateRevision:     sts.Status.UpdateRevision,
+	}
+	if equality.Semantic.DeepEqual(sts.Status, status) {
+		return nil
+	}
+	sts.Status = status
+	if err := s.client.Status().Update(ctx, sts); client.IgnoreNotFound(err) != nil && !apierrors.IsConflict(err) {
+		return fmt.Errorf("failed to update StatefulSet %s status: %w", sts.Name, err)
+	}
+	return nil
+}
+
+// statefulSetPod returns the pod with the given ordinal of sts, on the node
+func statefulSetPod(sts *appsv1.StatefulSet, ordinal int32) *corev1.Pod {
+	name := fmt.Sprintf("%s-%d", sts.Name, ordinal)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   sts.Namespace,
+			Name:        name,
+			Labels:      map[string]string{appsv1.StatefulSetPodNameLabel: name},
+			Annotations: sts.Spec.Template.Annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(sts,
+				appsv1.SchemeGroupVersion.WithKind("StatefulSet"))},
+		},
+		Spec: *sts.Spec.Template.Spec.DeepCopy(),
+	}
+	for k, v := range sts.Spec.Template.Labels {
+		pod.Labels[k] = v
+	}
+	pod.Spec.NodeName = loadNodeName
+	pod.Spec.Hostname = name
+	pod.Spec.Subdomain = sts.Spec.ServiceName
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: template.Name,
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: fmt.Sprintf("%s-%s", template.Name, name),
+			}},
+		})
+	}
+	return pod
+}
+
+// run reports a pod Running and Ready, as a kubelet would once its
+// containers started
+func (s *simulatedCluster) run(ctx context.Context, pod *corev1.Pod) error {
+	if pod.Status.Phase == corev1.PodRunning || pod.Spec.NodeName != loadNodeName {
+		return nil
+	}
+	now := metav1.Now()
+	pod.Status.Phase = corev1.PodRunning
+	pod.Status.HostIP = "10.255.0.1"
+	pod.Status.PodIP = "10.255.1.1"
+	pod.Status.StartTime = &now
+	pod.Status.Conditions = nil
+	for _, condType := range []corev1.PodConditionType{corev1.PodScheduled, corev1.PodInitialized, corev1.ContainersReady, corev1.PodReady} {
+		pod.Status.Conditions = append(pod.Status.Conditions, corev1.PodCondition{
+			Type: condType, Status: corev1.ConditionTrue, LastTransitionTime: now,
+		})
+	}
+	pod.Status.ContainerStatuses = nil
+	for _, c := range pod.Spec.Containers {
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+			Name:    c.Name,
+			Image:   c.Image,
+			Ready:   true,
+			Started: ptr.To(true),
+			State:   corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: now}},
+		})
+	}
+	if err := s.client.Status().Update(ctx, pod); client.IgnoreNotFound(err) != nil && !apierrors.IsConflict(err) {
+		return fmt.Errorf("failed to update pod %s status: %w", pod.Name, err)
+	}
+	return nil
+}
+
+// bind marks a PVC that names its PV, and the PV, Bound
+func (s *simulatedCluster) bind(ctx context.Context, pvc *corev1.PersistentVolumeClaim) error {
+	if pvc.Status.Phase == corev1.ClaimBound || pvc.Spec.VolumeName == "" || pvc.DeletionTimestamp != nil {
+		return nil
+	}
+	pv := &corev1.PersistentVolume{}
+	if err := s.client.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if pv.Spec.ClaimRef == nil || pv.Spec.ClaimRef.UID == "" {
+		pv.Spec.ClaimRef = &corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "PersistentVolumeClaim",
+			Namespace:  pvc.Namespace,
+			Name:       pvc.Name,
+			UID:        pvc.UID,
+		}
+		if err := s.client.Update(ctx, pv); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+	pv.Status.Phase = corev1.VolumeBound
+	if err := s.client.Status().Update(ctx, pv); client.IgnoreNotFound(err) != nil && !apierrors.IsConflict(err) {
+		return fmt.Errorf("failed to bind PV %s: %w", pv.Name, err)
+	}
+	pvc.Status.Phase = corev1.ClaimBound
+	pvc.Status.AccessModes = pv.Spec.AccessModes
+	pvc.Status.Capacity = pv.Spec.Capacity
+	if err := s.client.Status().Update(ctx, pvc); client.IgnoreNotFound(err) != nil && !apierrors.IsConflict(err) {
+		return fmt.Errorf("failed to bind PVC %s: %w", pvc.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+//go:build load
+
+package load
+
+import (
+	"context"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// fakeEC2 answers the EC2 API as a region where every volume named in a
+// request exists, unattached and unencrypted, in loadZone and every instance
+// is running there. Calls that change anything succeed without effect, so
+// the load measures the controller and the API server rather than AWS.
+type fakeEC2 struct{}
+
+func (fakeEC2) DescribeVolumes(_ context.Context, in *ec2.DescribeVolumesInput, _ ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	out := &ec2.DescribeVolumesOutput{}
+	for _, id := range in.VolumeIds {
+		out.Volumes = append(out.Volumes, types.Volume{
+			VolumeId:         awssdk.String(id),
+			AvailabilityZone: awssdk.String(loadZone),
+			State:            types.VolumeStateAvailable,
+			Size:             awssdk.Int32(1),
+			VolumeType:       types.VolumeTypeGp3,
+			Encrypted:        awssdk.Bool(false),
+		})
+	}
+	return out, nil
+}
+
+func (fakeEC2) DescribeSnapshots(context.Context, *ec2.DescribeSnapshotsInput, ...func(*ec2.Options)) (*ec2.DescribeSnapshotsOutput, error) {
+	return &ec2.DescribeSnapshotsOutput{}, nil
+}
+
+func (fakeEC2) DescribeVolumeStatus(_ context.Context, in *ec2.DescribeVolumeStatusInput, _ ...func(*ec2.Options)) (*ec2.DescribeVolumeStatusOutput, error) {
+	out := &ec2.DescribeVolumeStatusOutput{}
+	for _, id := range in.VolumeIds {
+		out.VolumeStatuses = append(out.VolumeStatuses, types.VolumeStatusItem{
+			VolumeId:         awssdk.String(id),
+			AvailabilityZone: awssdk.String(loadZone),
+			VolumeStatus:     &types.VolumeStatusInfo{Status: types.VolumeStatusInfoStatusOk},
+		})
+	}
+	return out, nil
+}
+
+func (fakeEC2) DescribeVolumesModifications(context.Context, *ec2.DescribeVolumesModificationsInput, ...func(*ec2.Options)) (*ec2.DescribeVolumesModificationsOutput, error) {
+	return &ec2.DescribeVolumesModificationsOutput{}, nil
+}
+
+func (fakeEC2) DescribeFastSnapshotRestores(context.Context, *ec2.DescribeFastSnapshotRestoresInput, ...func(*ec2.Options)) (*ec2.DescribeFastSnapshotRestoresOutput, error) {
+	return &ec2.DescribeFastSnapshotRestoresOutput{}, nil
+}
+
+func (fakeEC2) EnableFastSnapshotRestores(context.Context, *ec2.EnableFastSnapshotRestoresInput, ...func(*ec2.Options)) (*ec2.EnableFastSnapshotRestoresOutput, error) {
+	return &ec2.EnableFastSnapshotRestoresOutput{}, nil
+}
+
+func (fakeEC2) DisableFastSnapshotRestores(context.Context, *ec2.DisableFastSnapshotRestoresInput, ...func(*ec2.Options)) (*ec2.DisableFastSnapshotRestoresOutput, error) {
+	return &ec2.DisableFastSnapshotRestoresOutput{}, nil
+}
+
+func (fakeEC2) DescribeInstances(_ context.Context, in *ec2.DescribeInstancesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	out := &ec2.DescribeInstancesOutput{}
+	for _, id := range in.InstanceIds {
+		out.Reservations = append(out.Reservations, types.Reservation{Instances: []types.Instance{{
+			InstanceId: awssdk.String(id),
+			Placement:  &types.Placement{AvailabilityZone: awssdk.String(loadZone)},
+			State:      &types.InstanceState{Name: types.InstanceStateNameRunning},
+		}}})
+	}
+	return out, nil
+}
+
+func (fakeEC2) DescribeInstanceStatus(context.Context, *ec2.DescribeInstanceStatusInput, ...func(*ec2.Options)) (*ec2.DescribeInstanceStatusOutput, error) {
+	return &ec2.DescribeInstanceStatusOutput{}, nil
+}
+
+func (fakeEC2) DescribeAvailabilityZones(context.Context, *ec2.DescribeAvailabilityZonesInput, ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	return &ec2.DescribeAvailabilityZonesOutput{AvailabilityZones: []types.AvailabilityZone{{
+		ZoneName:   awssdk.String(loadZone),
+		ZoneId:     awssdk.String("use1-az1"),
+		ZoneType:   awssdk.String("availability-zone"),
+		State:      types.AvailabilityZoneStateAvailable,
+		RegionName: awssdk.String(loadRegion),
+	}}}, nil
+}
+
+func (fakeEC2) CreateSnapshot(context.Context, *ec2.CreateSnapshotInput, ...func(*ec2.Options)) (*ec2.CreateSnapshotOutput, error) {
+	return &ec2.CreateSnapshotOutput{}, nil
+}
+
+func (fakeEC2) CopySnapshot(context.Context, *ec2.CopySnapshotInput, ...func(*ec2.Options)) (*ec2.CopySnapshotOutput, error) {
+	return &ec2.CopySnapshotOutput{}, nil
+}
+
+func (fakeEC2) CreateVolume(context.Context, *ec2.CreateVolumeInput, ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error) {
+	return &ec2.CreateVolumeOutput{}, nil
+}
+
+func (fakeEC2) ModifySnapshotAttribute(context.Context, *ec2.ModifySnapshotAttributeInput, ...func(*ec2.Options)) (*ec2.ModifySnapshotAttributeOutput, error) {
+	return &ec2.ModifySnapshotAttributeOutput{}, nil
+}
+
+func (fakeEC2) DeleteSnapshot(context.Context, *ec2.DeleteSnapshotInput, ...func(*ec2.Options)) (*ec2.DeleteSnapshotOutput, error) {
+	return &ec2.DeleteSnapshotOutput{}, nil
+}
+
+func (fakeEC2) DetachVolume(context.Context, *ec2.DetachVolumeInput, ...func(*ec2.Options)) (*ec2.DetachVolumeOutput, error) {
+	return &ec2.DetachVolumeOutput{}, nil
+}
+
+func (fakeEC2) CreateTags(context.Context, *ec2.CreateTagsInput, ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func (fakeEC2) DeleteTags(context.Context, *ec2.DeleteTagsInput, ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
+	return &ec2.DeleteTagsOutput{}, nil
+}
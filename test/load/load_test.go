@@ -0,0 +1,495 @@
+//go:build load
+
+// Package load runs hundreds of migrations at once through the controller
+// against an envtest API server, with a simulated kubelet and StatefulSet
+// controller and a fake EC2, and reports reconcile throughput, status writes
+// and memory for each --max-concurrent-reconciles setting it tries. The
+// tests are skipped unless KUBEBUILDER_ASSETS points at the envtest
+// binaries; make test-load installs them and runs the tests.
+//
+// LOAD_MIGRATIONS sets how many migrations run together (default 200),
+// LOAD_CONCURRENCY the comma-separated concurrency settings to try (default
+// 1,4,16) and LOAD_DURATION how long each setting may take (default 5m).
+// The controller logs nothing unless LOAD_VERBOSE is set.
+package load
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	migrationv1alpha1 "github.com/aqua-io/aqua-service-controller/api/v1alpha1"
+	"github.com/aqua-io/aqua-service-controller/internal/aws"
+	"github.com/aqua-io/aqua-service-controller/internal/controller"
+	"github.com/aqua-io/aqua-service-controller/internal/migration"
+	"github.com/aqua-io/aqua-service-controller/internal/multicluster"
+)
+
+const (
+	// loadZone and loadRegion are where the fake volumes and the node are
+	loadZone   = "us-east-1a"
+	loadRegion = "us-east-1"
+
+	// loadStorageClass is the StorageClass of the migrated volumes
+	loadStorageClass = "ebs-load"
+
+	// kubeconfigSecret names both clusters, which are the same envtest
+	// API server; migrations differ by namespace
+	kubeconfigSecret = "load-kubeconfig"
+
+	// controllerName labels the reconcile metrics of the migration controller
+	controllerName = "statefulsetmigration"
+)
+
+var scheme = apiruntime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(migrationv1alpha1.AddToScheme(scheme))
+}
+
+// loadSettings are the knobs of a load test, read from the environment
+type loadSettings struct {
+	migrations  int
+	concurrency []int
+	duration    time.Duration
+}
+
+func readSettings(t *testing.T) loadSettings {
+	t.Helper()
+	s := loadSettings{migrations: 200, concurrency: []int{1, 4, 16}, duration: 5 * time.Minute}
+	if v := os.Getenv("LOAD_MIGRATIONS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			t.Fatalf("LOAD_MIGRATIONS=%q is not a positive number", v)
+		}
+		s.migrations = n
+	}
+	if v := os.Getenv("LOAD_CONCURRENCY"); v != "" {
+		s.concurrency = nil
+		for _, field := range strings.Split(v, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || n < 1 {
+				t.Fatalf("LOAD_CONCURRENCY=%q is not a list of positive numbers", v)
+			}
+			s.concurrency = append(s.concurrency, n)
+		}
+	}
+	if v := os.Getenv("LOAD_DURATION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			t.Fatalf("LOAD_DURATION=%q is not a positive duration", v)
+		}
+		s.duration = d
+	}
+	return s
+}
+
+// loadResult is what one run measured
+type loadResult struct {
+	concurrency  int
+	finished     int
+	failed       int
+	elapsed      time.Duration
+	reconciles   float64
+	reconcileSec float64
+	statusWrites int64
+	peakHeap     uint64
+	peakRoutines int
+	phases       map[migrationv1alpha1.MigrationPhase]int
+	errors       []string
+}
+
+// TestLoad runs LOAD_MIGRATIONS migrations at once for each LOAD_CONCURRENCY
+// setting and logs what each run measured, then a table comparing them
+func TestLoad(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS must point at the envtest binaries; see make test-load")
+	}
+	settings := readSettings(t)
+	ctrl.SetLogger(logr.Discard())
+	if os.Getenv("LOAD_VERBOSE") != "" {
+		ctrl.SetLogger(zap.New(zap.WriteTo(os.Stderr)))
+	}
+
+	env := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd")},
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := env.Start()
+	if err != nil {
+		t.Fatalf("failed to start envtest: %v", err)
+	}
+	t.Cleanup(func() { _ = env.Stop() })
+
+	user, err := env.AddUser(envtest.User{Name: "aqua-load", Groups: []string{"system:masters"}}, nil)
+	if err != nil {
+		t.Fatalf("failed to add envtest user: %v", err)
+	}
+	kubeconfig, err := user.KubeConfig()
+	if err != nil {
+		t.Fatalf("failed to write envtest kubeconfig: %v", err)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	var results []loadResult
+	for _, concurrency := range settings.concurrency {
+		t.Run(fmt.Sprintf("concurrency-%d", concurrency), func(t *testing.T) {
+			result := runLoad(t, cfg, c, kubeconfig, settings, concurrency)
+			results = append(results, result)
+			logResult(t, settings, result)
+		})
+	}
+
+	t.Logf("%d migrations:", settings.migrations)
+	t.Logf("%-12s %-10s %-10s %-12s %-14s %-16s %-12s %s", "CONCURRENCY", "FINISHED", "ELAPSED", "RECONCILES/S", "MEAN RECONCILE", "STATUS WRITES/M", "PEAK HEAP", "GOROUTINES")
+	for _, r := range results {
+		t.Logf("%-12d %-10d %-10s %-12.1f %-14s %-16.1f %-12s %d", r.concurrency, r.finished, r.elapsed.Round(time.Second),
+			r.reconciles/r.elapsed.Seconds(), meanReconcile(r), float64(r.statusWrites)/float64(settings.migrations),
+			resource.NewQuantity(int64(r.peakHeap), resource.BinarySI), r.peakRoutines)
+	}
+}
+
+// runLoad creates the migrations in fresh namespaces, runs a controller with
+// the given concurrency until they finish or the duration runs out, and
+// returns what it measured
+func runLoad(t *testing.T, cfg *rest.Config, c client.Client, kubeconfig []byte, settings loadSettings, concurrency int) loadResult {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	sourceNS, destNS := fmt.Sprintf("load-%d-source", concurrency), fmt.Sprintf("load-%d-dest", concurrency)
+	for _, ns := range []string{sourceNS, destNS} {
+		mustCreate(ctx, t, c, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})
+	}
+	mustCreate(ctx, t, c, &storagev1.StorageClass{
+		ObjectMeta:        metav1.ObjectMeta{Name: loadStorageClass},
+		Provisioner:       migration.EBSCSIDriver,
+		VolumeBindingMode: ptr.To(storagev1.VolumeBindingWaitForFirstConsumer),
+	})
+	mustCreate(ctx, t, c, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: sourceNS, Name: kubeconfigSecret},
+		Data:       map[string][]byte{"kubeconfig": kubeconfig},
+	})
+	(&simulatedCluster{client: c, namespaces: []string{sourceNS, destNS}}).start(ctx, t)
+
+	for i := range settings.migrations {
+		createWorkload(ctx, t, c, sourceNS, destNS, concurrency, i)
+	}
+	waitPodsRunning(ctx, t, c, sourceNS, settings.migrations)
+
+	var statusWrites atomic.Int64
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:                 scheme,
+		Metrics:                metricsserver.Options{BindAddress: "0"},
+		HealthProbeBindAddress: "0",
+		Controller:             config.Controller{SkipNameValidation: ptr.To(true)},
+	})
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	if err := (&controller.StatefulSetMigrationReconciler{
+		Client:                  &countingClient{Client: mgr.GetClient(), statusWrites: &statusWrites},
+		Scheme:                  mgr.GetScheme(),
+		ClientManager:           multicluster.NewClientManager(scheme, mgr.GetClient()),
+		EBSClient:               aws.NewEBSClientFromAPI(fakeEC2{}, clock.RealClock{}, loadRegion),
+		Recorder:                mgr.GetEventRecorderFor("statefulsetmigration-controller"),
+		MaxConcurrentReconciles: concurrency,
+	}).SetupWithManager(mgr); err != nil {
+		t.Fatalf("failed to set up controller: %v", err)
+	}
+
+	reconcilesBefore, secondsBefore := reconcileMetrics(t)
+	result := loadResult{concurrency: concurrency}
+	start := time.Now()
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			t.Errorf("manager stopped: %v", err)
+		}
+	}()
+	for i := range settings.migrations {
+		mustCreate(ctx, t, c, loadMigration(sourceNS, destNS, concurrency, i))
+	}
+
+	deadline := time.After(settings.duration)
+	sample := time.NewTicker(time.Second)
+	defer sample.Stop()
+	check := time.NewTicker(5 * time.Second)
+	defer check.Stop()
+	for done := false; !done; {
+		select {
+		case <-deadline:
+			done = true
+		case <-sample.C:
+			var mem runtime.MemStats
+			runtime.ReadMemStats(&mem)
+			result.peakHeap = max(result.peakHeap, mem.HeapInuse)
+			result.peakRoutines = max(result.peakRoutines, runtime.NumGoroutine())
+		case <-check.C:
+			result.phases, result.finished, result.failed, result.errors = migrationPhases(ctx, t, c, sourceNS)
+			done = result.finished == settings.migrations
+		}
+	}
+	result.elapsed = time.Since(start)
+	cancel()
+
+	result.phases, result.finished, result.failed, result.errors = migrationPhases(context.Background(), t, c, sourceNS)
+	reconcilesAfter, secondsAfter := reconcileMetrics(t)
+	result.reconciles = reconcilesAfter - reconcilesBefore
+	result.reconcileSec = secondsAfter - secondsBefore
+	result.statusWrites = statusWrites.Load()
+	return result
+}
+
+// createWorkload creates the source StatefulSet of migration i with one
+// pod and its volume, and the headless service in both namespaces
+func createWorkload(ctx context.Context, t *testing.T, c client.Client, sourceNS, destNS string, run, i int) {
+	name := fmt.Sprintf("web-%03d", i)
+	pvcName := "data-" + name + "-0"
+	pvName := fmt.Sprintf("load-%d-%s", run, pvcName)
+	labels := map[string]string{"app": name}
+	storage := resource.MustParse("1Gi")
+
+	for _, ns := range []string{sourceNS, destNS} {
+		mustCreate(ctx, t, c, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: corev1.ClusterIPNone,
+				Selector:  labels,
+				Ports:     []corev1.ServicePort{{Name: "http", Port: 80}},
+			},
+		})
+	}
+	mustCreate(ctx, t, c, &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: pvName},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:                      corev1.ResourceList{corev1.ResourceStorage: storage},
+			AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+			StorageClassName:              loadStorageClass,
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: migration.EBSCSIDriver, VolumeHandle: fmt.Sprintf("vol-%017x", run<<32|i), FSType: "ext4"},
+			},
+			NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key: corev1.LabelTopologyZone, Operator: corev1.NodeSelectorOpIn, Values: []string{loadZone},
+				}}}},
+			}},
+		},
+	})
+	claim := corev1.PersistentVolumeClaimSpec{
+		AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		StorageClassName: ptr.To(loadStorageClass),
+		Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: storage}},
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: sourceNS, Name: pvcName, Labels: labels},
+		Spec:       *claim.DeepCopy(),
+	}
+	pvc.Spec.VolumeName = pvName
+	mustCreate(ctx, t, c, pvc)
+	mustCreate(ctx, t, c, &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: sourceNS, Name: name},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    ptr.To[int32](1),
+			ServiceName: name,
+			Selector:    &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:         "web",
+					Image:        "registry.k8s.io/pause:3.10",
+					VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+				}}},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "data", Labels: labels},
+				Spec:       claim,
+			}},
+		},
+	})
+}
+
+// loadMigration returns migration i of a run
+func loadMigration(sourceNS, destNS string, run, i int) *migrationv1alpha1.StatefulSetMigration {
+	name := fmt.Sprintf("web-%03d", i)
+	return &migrationv1alpha1.StatefulSetMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: sourceNS, Name: name},
+		Spec: migrationv1alpha1.StatefulSetMigrationSpec{
+			MigrationID:     fmt.Sprintf("load-%d-%s", run, name),
+			SourceCluster:   migrationv1alpha1.ContextRef{KubeConfigSecret: kubeconfigSecret},
+			SourceNamespace: sourceNS,
+			StatefulSetName: name,
+			DestCluster:     migrationv1alpha1.ContextRef{KubeConfigSecret: kubeconfigSecret},
+			DestNamespace:   destNS,
+		},
+	}
+}
+
+// waitPodsRunning waits for the simulated cluster to start every source pod
+func waitPodsRunning(ctx context.Context, t *testing.T, c client.Client, namespace string, want int) {
+	t.Helper()
+	timeout := time.After(2 * time.Minute)
+	for {
+		pods := &corev1.PodList{}
+		if err := c.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+			t.Fatalf("failed to list pods: %v", err)
+		}
+		running := 0
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == corev1.PodRunning {
+				running++
+			}
+		}
+		if running >= want {
+			return
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("%d of %d source pods running after 2m", running, want)
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// migrationPhases counts the migrations in namespace by phase, with how many
+// finished and failed, and the distinct errors of those that failed
+func migrationPhases(ctx context.Context, t *testing.T, c client.Client, namespace string) (map[migrationv1alpha1.MigrationPhase]int, int, int, []string) {
+	t.Helper()
+	list := &migrationv1alpha1.StatefulSetMigrationList{}
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		t.Fatalf("failed to list migrations: %v", err)
+	}
+	phases := map[migrationv1alpha1.MigrationPhase]int{}
+	seen := map[string]bool{}
+	var finished, failed int
+	var errors []string
+	for _, m := range list.Items {
+		phases[m.Status.Phase]++
+		switch m.Status.Phase {
+		case migrationv1alpha1.PhaseCompleted:
+			finished++
+		case migrationv1alpha1.PhaseFailed:
+			finished++
+			failed++
+			if !seen[m.Status.LastError] {
+				seen[m.Status.LastError] = true
+				errors = append(errors, m.Status.LastError)
+			}
+		}
+	}
+	return phases, finished, failed, errors
+}
+
+// reconcileMetrics returns the migration controller's reconciles so far and
+// the seconds they took, from the controller-runtime metrics
+func reconcileMetrics(t *testing.T) (count, seconds float64) {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "controller_runtime_reconcile_time_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "controller" && label.GetValue() == controllerName {
+					count += float64(metric.GetHistogram().GetSampleCount())
+					seconds += metric.GetHistogram().GetSampleSum()
+				}
+			}
+		}
+	}
+	return count, seconds
+}
+
+func meanReconcile(r loadResult) time.Duration {
+	if r.reconciles == 0 {
+		return 0
+	}
+	return time.Duration(r.reconcileSec / r.reconciles * float64(time.Second)).Round(time.Microsecond)
+}
+
+func logResult(t *testing.T, settings loadSettings, r loadResult) {
+	t.Helper()
+	t.Logf("%d of %d migrations finished (%d failed) in %s", r.finished, settings.migrations, r.failed, r.elapsed.Round(time.Second))
+	t.Logf("%.0f reconciles, %.1f/s, %s each on average", r.reconciles, r.reconciles/r.elapsed.Seconds(), meanReconcile(r))
+	t.Logf("%d status writes, %.1f per migration", r.statusWrites, float64(r.statusWrites)/float64(settings.migrations))
+	t.Logf("peak heap in use %s, peak goroutines %d", resource.NewQuantity(int64(r.peakHeap), resource.BinarySI), r.peakRoutines)
+
+	phases := make([]string, 0, len(r.phases))
+	for phase, n := range r.phases {
+		phases = append(phases, fmt.Sprintf("%s=%d", phase, n))
+	}
+	sort.Strings(phases)
+	t.Logf("phases: %s", strings.Join(phases, " "))
+	for _, e := range r.errors {
+		t.Logf("failure: %s", e)
+	}
+}
+
+// mustCreate creates obj, failing the test on error
+func mustCreate(ctx context.Context, t *testing.T, c client.Client, obj client.Object) {
+	t.Helper()
+	if err := c.Create(ctx, obj); client.IgnoreAlreadyExists(err) != nil {
+		t.Fatalf("failed to create %T %s: %v", obj, obj.GetName(), err)
+	}
+}
+
+// countingClient counts the status writes made through it
+type countingClient struct {
+	client.Client
+	statusWrites *atomic.Int64
+}
+
+func (c *countingClient) Status() client.SubResourceWriter {
+	return &countingStatusWriter{SubResourceWriter: c.Client.Status(), writes: c.statusWrites}
+}
+
+// countingStatusWriter is the status writer of countingClient
+type countingStatusWriter struct {
+	client.SubResourceWriter
+	writes *atomic.Int64
+}
+
+func (w *countingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	w.writes.Add(1)
+	return w.SubResourceWriter.Update(ctx, obj, opts...)
+}
+
+func (w *countingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	w.writes.Add(1)
+	return w.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+}